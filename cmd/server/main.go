@@ -4,25 +4,43 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"time"
 
 	"github.com/flexprice/flexprice/internal/api"
+	grpcapi "github.com/flexprice/flexprice/internal/api/grpc"
 	v1 "github.com/flexprice/flexprice/internal/api/v1"
+	"github.com/flexprice/flexprice/internal/audit"
 	"github.com/flexprice/flexprice/internal/clickhouse"
+	"github.com/flexprice/flexprice/internal/clock"
 	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/email"
 	"github.com/flexprice/flexprice/internal/kafka"
 	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/pgqueue"
 	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/ratelimit"
 	"github.com/flexprice/flexprice/internal/repository"
+	"github.com/flexprice/flexprice/internal/scheduler"
 	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/storage"
+	"github.com/flexprice/flexprice/internal/syncqueue"
 	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/usagestream"
+	"github.com/flexprice/flexprice/internal/webhook"
 	"go.uber.org/fx"
+	"google.golang.org/grpc"
 
 	lambdaEvents "github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	ginadapter "github.com/awslabs/aws-lambda-go-api-proxy/gin"
 	_ "github.com/flexprice/flexprice/docs/swagger"
+	auditDomain "github.com/flexprice/flexprice/internal/domain/audit"
 	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/job"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
 	"github.com/gin-gonic/gin"
 )
 
@@ -35,6 +53,10 @@ import (
 // @in header
 // @name Authorization
 // @description Enter your bearer token in the format **Bearer &lt;token&gt;**
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Scoped API key created with POST /secrets/api-keys
 
 func init() {
 	// Set UTC timezone for the entire application
@@ -54,16 +76,23 @@ func main() {
 			// Logger
 			logger.NewLogger,
 
+			// Clock of the billing flows, simulated in sandboxes
+			clock.New,
+
 			// DB
 			postgres.NewDB,
+			postgres.NewTxManager,
 			clickhouse.NewClickHouseStore,
 
-			// Producers and Consumers
-			kafka.NewProducer,
-			kafka.NewConsumer,
+			// Producers and Consumers, of kafka or of the Postgres queue
+			provideProducer,
+			provideConsumerFactory,
+			provideConsumer,
+			provideLagReader,
 
 			// Repositories
 			repository.NewEventRepository,
+			repository.NewUsageRollupRepository,
 			repository.NewMeterRepository,
 			repository.NewUserRepository,
 			repository.NewAuthRepository,
@@ -72,6 +101,61 @@ func main() {
 			repository.NewPlanRepository,
 			repository.NewSubscriptionRepository,
 			repository.NewWalletRepository,
+			repository.NewAutoTopUpRepository,
+			repository.NewCreditBucketRepository,
+			repository.NewExportRepository,
+			repository.NewTaskRepository,
+			repository.NewInvoiceRepository,
+			repository.NewSequenceRepository,
+			repository.NewEnvironmentRepository,
+			repository.NewSecretRepository,
+			repository.NewConnectionRepository,
+			repository.NewWebhookRepository,
+			repository.NewEmailRepository,
+			repository.NewLedgerRepository,
+			repository.NewAnomalyRepository,
+			repository.NewRevenueSnapshotRepository,
+			repository.NewReceivablesSnapshotRepository,
+			repository.NewReportRepository,
+			repository.NewRequestLogRepository,
+			repository.NewCancellationReasonRepository,
+			repository.NewEventSchemaRepository,
+			repository.NewDeadLetterRepository,
+			repository.NewRateCardRepository,
+			repository.NewPriceBookRepository,
+			repository.NewBudgetRepository,
+			repository.NewRetentionRepository,
+			repository.NewFeatureFlagRepository,
+			repository.NewPaymentMethodRepository,
+			repository.NewMandateRepository,
+			repository.NewLegalEntityRepository,
+			repository.NewAuditLogRepository,
+			repository.NewRoleAssignmentRepository,
+			repository.NewSSORepository,
+
+			// Storage
+			storage.NewStore,
+
+			// Integrations
+			provideSyncQueue,
+
+			// Rate limiting
+			provideRateLimiter,
+
+			// Live usage streams
+			provideUsageStreamBroker,
+
+			// Webhooks
+			webhook.NewPublisher,
+			webhook.NewPreHookGate,
+			provideWebhookDispatcher,
+
+			// Audit logs
+			provideAuditPublisher,
+
+			// Emails
+			email.NewProvider,
+			email.NewSender,
 
 			// Services
 			service.NewMeterService,
@@ -81,14 +165,72 @@ func main() {
 			service.NewPriceService,
 			service.NewCustomerService,
 			service.NewPlanService,
+			service.NewPriceCatalogService,
 			service.NewSubscriptionService,
+			service.NewSubscriptionReader,
+			service.NewSubscriptionLineItemService,
 			service.NewWalletService,
+			service.NewExportService,
+			service.NewTaskService,
+			service.NewInvoiceService,
+			service.NewEnvironmentService,
+			service.NewSecretService,
+			service.NewActivityService,
+			service.NewPortalService,
+			service.NewConnectionService,
+			service.NewWebhookService,
+			service.NewEmailService,
+			service.NewLedgerSyncService,
+			service.NewCRMSyncService,
+			service.NewStripeImportService,
+			service.NewPaymentMethodService,
+			service.NewRefundService,
+			service.NewPaymentService,
+			service.NewMandateService,
+			service.NewTaxService,
+			service.NewLegalEntityService,
+			service.NewAnomalyService,
+			service.NewRequestLogService,
+			service.NewTrialService,
+			service.NewCancellationReasonService,
+			service.NewRateCardService,
+			service.NewPriceBookService,
+			service.NewBudgetService,
+			service.NewCreditNoteService,
+			service.NewUsageStreamService,
+			service.NewAutoTopUpService,
+			service.NewEventRetentionService,
+			service.NewFeatureFlagService,
+			service.NewSimulationService,
+			service.NewSoftDeleteService,
+			service.NewUsageRollupService,
+			service.NewJobService,
+			service.NewAuditLogService,
+			service.NewRoleService,
+			service.NewEventCorrectionService,
+			service.NewEventBackfillService,
+			service.NewMeterVersionService,
+			service.NewEventSchemaService,
+			service.NewEventDeadLetterService,
+			service.NewEventConsumerService,
+			service.NewForecastService,
+			service.NewMarginService,
+			service.NewRevenueAnalyticsService,
+			service.NewReceivablesService,
+			service.NewReportService,
 
 			// Handlers
 			provideHandlers,
 
 			// Router
 			provideRouter,
+
+			// Background jobs
+			repository.NewJobRunRepository,
+			provideScheduler,
+
+			// gRPC server
+			grpcapi.NewServer,
 		),
 		fx.Invoke(startServer),
 	)
@@ -109,6 +251,52 @@ func provideHandlers(
 	planService service.PlanService,
 	subscriptionService service.SubscriptionService,
 	walletService service.WalletService,
+	exportService service.ExportService,
+	taskService service.TaskService,
+	invoiceService service.InvoiceService,
+	environmentService service.EnvironmentService,
+	secretService service.SecretService,
+	activityService service.ActivityService,
+	portalService service.PortalService,
+	connectionService service.ConnectionService,
+	webhookService service.WebhookService,
+	emailService service.EmailService,
+	ledgerSyncService service.LedgerSyncService,
+	anomalyService service.AnomalyService,
+	requestLogService service.RequestLogService,
+	cancellationReasonService service.CancellationReasonService,
+	rateCardService service.RateCardService,
+	priceBookService service.PriceBookService,
+	budgetService service.BudgetService,
+	creditNoteService service.CreditNoteService,
+	usageStreamService service.UsageStreamService,
+	autoTopUpService service.AutoTopUpService,
+	eventRetentionService service.EventRetentionService,
+	jobService service.JobService,
+	priceCatalogService service.PriceCatalogService,
+	stripeImportService service.StripeImportService,
+	subscriptionLineItemService service.SubscriptionLineItemService,
+	paymentMethodService service.PaymentMethodService,
+	refundService service.RefundService,
+	paymentService service.PaymentService,
+	mandateService service.MandateService,
+	taxService service.TaxService,
+	legalEntityService service.LegalEntityService,
+	auditLogService service.AuditLogService,
+	roleService service.RoleService,
+	eventCorrectionService service.EventCorrectionService,
+	eventBackfillService service.EventBackfillService,
+	meterVersionService service.MeterVersionService,
+	eventSchemaService service.EventSchemaService,
+	eventDeadLetterService service.EventDeadLetterService,
+	eventConsumerService service.EventConsumerService,
+	forecastService service.ForecastService,
+	marginService service.MarginService,
+	revenueAnalyticsService service.RevenueAnalyticsService,
+	receivablesService service.ReceivablesService,
+	reportService service.ReportService,
+	featureFlagService service.FeatureFlagService,
+	simulationService service.SimulationService,
 ) api.Handlers {
 	return api.Handlers{
 		Events:       v1.NewEventsHandler(eventService, logger),
@@ -120,19 +308,344 @@ func provideHandlers(
 		Plan:         v1.NewPlanHandler(planService, logger),
 		Subscription: v1.NewSubscriptionHandler(subscriptionService, logger),
 		Wallet:       v1.NewWalletHandler(walletService, logger),
+		Export:       v1.NewExportHandler(exportService, logger),
+		Task:         v1.NewTaskHandler(taskService, logger),
+		Invoice:      v1.NewInvoiceHandler(invoiceService, logger),
+		Environment:  v1.NewEnvironmentHandler(environmentService, logger),
+		Secret:       v1.NewSecretHandler(secretService, logger),
+		Activity:     v1.NewActivityHandler(activityService, logger),
+		Portal:       v1.NewPortalHandler(portalService, logger),
+		Connection:   v1.NewConnectionHandler(connectionService, logger),
+		Webhook:      v1.NewWebhookHandler(webhookService, logger),
+		Email:        v1.NewEmailHandler(emailService, logger),
+		LedgerSync:   v1.NewLedgerSyncHandler(ledgerSyncService, logger),
+		Anomaly:      v1.NewAnomalyHandler(anomalyService, logger),
+		RequestLog:   v1.NewRequestLogHandler(requestLogService, logger),
+
+		CancellationReason:   v1.NewCancellationReasonHandler(cancellationReasonService, logger),
+		GraphQL:              v1.NewGraphQLHandler(customerService, subscriptionService, invoiceService, logger),
+		RateCard:             v1.NewRateCardHandler(rateCardService, logger),
+		PriceBook:            v1.NewPriceBookHandler(priceBookService, logger),
+		Budget:               v1.NewBudgetHandler(budgetService, logger),
+		CreditNote:           v1.NewCreditNoteHandler(creditNoteService, logger),
+		UsageStream:          v1.NewUsageStreamHandler(cfg, usageStreamService, logger),
+		AutoTopUp:            v1.NewAutoTopUpHandler(autoTopUpService, logger),
+		EventRetention:       v1.NewEventRetentionHandler(eventRetentionService, logger),
+		Job:                  v1.NewJobHandler(jobService, logger),
+		PriceCatalog:         v1.NewPriceCatalogHandler(priceCatalogService, logger),
+		StripeImport:         v1.NewStripeImportHandler(stripeImportService, logger),
+		SubscriptionLineItem: v1.NewSubscriptionLineItemHandler(subscriptionLineItemService, logger),
+		PaymentMethod:        v1.NewPaymentMethodHandler(paymentMethodService, logger),
+		Refund:               v1.NewRefundHandler(refundService, logger),
+		Payment:              v1.NewPaymentHandler(paymentService, logger),
+		Mandate:              v1.NewMandateHandler(mandateService, logger),
+		Tax:                  v1.NewTaxHandler(taxService, logger),
+		LegalEntity:          v1.NewLegalEntityHandler(legalEntityService, logger),
+		AuditLog:             v1.NewAuditLogHandler(auditLogService, logger),
+		Role:                 v1.NewRoleHandler(roleService, logger),
+		EventCorrection:      v1.NewEventCorrectionHandler(eventCorrectionService, logger),
+		EventBackfill:        v1.NewEventBackfillHandler(eventBackfillService, logger),
+		MeterVersion:         v1.NewMeterVersionHandler(meterVersionService, logger),
+		EventSchema:          v1.NewEventSchemaHandler(eventSchemaService, logger),
+		EventDeadLetter:      v1.NewEventDeadLetterHandler(eventDeadLetterService, logger),
+		EventConsumer:        v1.NewEventConsumerHandler(eventConsumerService, logger),
+		Forecast:             v1.NewForecastHandler(forecastService, logger),
+		Margin:               v1.NewMarginHandler(marginService, logger),
+		Analytics:            v1.NewAnalyticsHandler(revenueAnalyticsService, logger),
+		Receivables:          v1.NewReceivablesHandler(receivablesService, logger),
+		Report:               v1.NewReportHandler(reportService, logger),
+		FeatureFlag:          v1.NewFeatureFlagHandler(featureFlagService, logger),
+		Simulation:           v1.NewSimulationHandler(simulationService, logger),
+	}
+}
+
+// consumerFactory returns a consumer in the given consumer group
+type consumerFactory func(consumerGroup string) (kafka.MessageConsumer, error)
+
+func provideProducer(cfg *config.Configuration, db *postgres.DB) (kafka.MessageProducer, error) {
+	if cfg.Queue.UsesPostgres() {
+		return pgqueue.NewProducer(db), nil
+	}
+	return kafka.NewProducer(cfg)
+}
+
+func provideConsumerFactory(cfg *config.Configuration, db *postgres.DB) consumerFactory {
+	if cfg.Queue.UsesPostgres() {
+		return func(string) (kafka.MessageConsumer, error) {
+			return pgqueue.NewConsumer(db, cfg), nil
+		}
+	}
+	return func(consumerGroup string) (kafka.MessageConsumer, error) {
+		return kafka.NewGroupConsumer(cfg, consumerGroup)
+	}
+}
+
+func provideConsumer(cfg *config.Configuration, newConsumer consumerFactory) (kafka.MessageConsumer, error) {
+	return newConsumer(cfg.Kafka.ConsumerGroup)
+}
+
+func provideLagReader(cfg *config.Configuration, db *postgres.DB) kafka.LagReader {
+	if cfg.Queue.UsesPostgres() {
+		return pgqueue.NewLagReader(db)
+	}
+	return kafka.NewLagReader(cfg)
+}
+
+func provideSyncQueue(lc fx.Lifecycle, cfg *config.Configuration, logger *logger.Logger) *syncqueue.Manager {
+	manager := syncqueue.NewManager(cfg, logger)
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			manager.Stop()
+			return nil
+		},
+	})
+	return manager
+}
+
+func provideWebhookDispatcher(lc fx.Lifecycle, cfg *config.Configuration, repo webhookDomain.Repository, logger *logger.Logger) *webhook.Dispatcher {
+	dispatcher := webhook.NewDispatcher(cfg, repo, logger)
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			dispatcher.Stop()
+			return nil
+		},
+	})
+	return dispatcher
+}
+
+func provideAuditPublisher(lc fx.Lifecycle, repo auditDomain.Repository, logger *logger.Logger) audit.Publisher {
+	publisher := audit.NewPublisher(repo, logger)
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			publisher.Close()
+			return nil
+		},
+	})
+	return publisher
+}
+
+// provideScheduler registers the background jobs with the schedule of their
+// config sections, scheduler.jobs overrides it per job
+func provideScheduler(
+	cfg *config.Configuration,
+	runRepo job.RunRepository,
+	anomalyService service.AnomalyService,
+	trialService service.TrialService,
+	autoTopUpService service.AutoTopUpService,
+	walletService service.WalletService,
+	invoiceService service.InvoiceService,
+	paymentService service.PaymentService,
+	eventRetentionService service.EventRetentionService,
+	usageRollupService service.UsageRollupService,
+	softDeleteService service.SoftDeleteService,
+	receivablesService service.ReceivablesService,
+	reportService service.ReportService,
+	exportService service.ExportService,
+	emailSender *email.Sender,
+	clock *clock.Clock,
+	log *logger.Logger,
+) *scheduler.Scheduler {
+	billingInterval := time.Duration(cfg.Billing.CronIntervalMins) * time.Minute
+	if billingInterval <= 0 {
+		billingInterval = 15 * time.Minute
+	}
+
+	jobScheduler := scheduler.New(cfg, runRepo, clock, log)
+	jobScheduler.Register(scheduler.Job{
+		Name:        "process_trials",
+		Description: "Sends the trial_will_end webhooks and emails and ends the trials that are due",
+		Enabled:     true,
+		Interval:    billingInterval,
+		Run:         trialService.ProcessTrials,
+	})
+	jobScheduler.Register(scheduler.Job{
+		Name:        "expire_wallet_credits",
+		Description: "Expires the wallet credits past their expiry date",
+		Enabled:     true,
+		Interval:    billingInterval,
+		Run:         walletService.ExpireCredits,
+	})
+	jobScheduler.Register(scheduler.Job{
+		Name:        "process_auto_topups",
+		Description: "Tops up the wallets whose balance is below their auto top-up threshold",
+		Enabled:     true,
+		Interval:    billingInterval,
+		Run:         autoTopUpService.ProcessAutoTopUps,
+	})
+	jobScheduler.Register(scheduler.Job{
+		Name:        "process_billing_thresholds",
+		Description: "Invoices the subscriptions whose usage exceeds their billing threshold",
+		Enabled:     true,
+		Interval:    billingInterval,
+		Run:         invoiceService.ProcessBillingThresholds,
+	})
+	jobScheduler.Register(scheduler.Job{
+		Name:        "renew_subscription_periods",
+		Description: "Moves the subscriptions whose period is over and locked to their next period and invoices the period that ended, for the tenants with period_renewal turned on",
+		Enabled:     true,
+		Interval:    billingInterval,
+		Run:         invoiceService.ProcessPeriodEnds,
+	})
+	jobScheduler.Register(scheduler.Job{
+		Name:        "reconcile_late_events",
+		Description: "Recalculates the draft invoices whose period received events after they were calculated and sends the invoice.late_usage webhook for the finalized ones",
+		Enabled:     true,
+		Interval:    billingInterval,
+		Run:         invoiceService.ReconcileLateEvents,
+	})
+	jobScheduler.Register(scheduler.Job{
+		Name:        "collect_direct_debits",
+		Description: "Submits the bank debits whose charge date has come to the payment gateway of their mandate and fails the ones whose mandate was revoked",
+		Enabled:     true,
+		Interval:    billingInterval,
+		Run:         paymentService.CollectDueDebits,
+	})
+
+	jobScheduler.Register(scheduler.Job{
+		Name:        "send_emails",
+		Description: "Sends the queued emails and retries the failed ones",
+		Enabled:     cfg.Email.Provider != "",
+		Interval:    time.Minute,
+		Run:         emailSender.SendDue,
+	})
+
+	anomalyInterval := time.Duration(cfg.Anomaly.IntervalMins) * time.Minute
+	if anomalyInterval <= 0 {
+		anomalyInterval = time.Hour
+	}
+	jobScheduler.Register(scheduler.Job{
+		Name:        "detect_anomalies",
+		Description: "Detects the spikes and drops of the usage of every meter",
+		Enabled:     true,
+		Interval:    anomalyInterval,
+		Run:         anomalyService.DetectAnomalies,
+	})
+
+	retentionInterval := time.Duration(cfg.EventRetention.IntervalMins) * time.Minute
+	if retentionInterval <= 0 {
+		retentionInterval = 24 * time.Hour
 	}
+	jobScheduler.Register(scheduler.Job{
+		Name:        "apply_event_retention",
+		Description: "Deletes the events past the retention TTL of their tenant",
+		Enabled:     cfg.EventRetention.Enabled,
+		Interval:    retentionInterval,
+		Run:         eventRetentionService.ApplyRetention,
+	})
+
+	jobScheduler.Register(scheduler.Job{
+		Name:        "snapshot_receivables",
+		Description: "Records the daily accounts receivable aging of every tenant",
+		Enabled:     true,
+		Interval:    24 * time.Hour,
+		Run:         receivablesService.SnapshotReceivables,
+	})
+
+	jobScheduler.Register(scheduler.Job{
+		Name:        "run_scheduled_reports",
+		Description: "Creates the runs of the report templates whose daily, weekly or monthly schedule is due, then runs the pending report runs, and the runs which were interrupted, and delivers their CSV",
+		Enabled:     cfg.Export.Bucket != "",
+		Interval:    time.Minute,
+		Run:         reportService.RunScheduledReports,
+	})
+
+	jobScheduler.Register(scheduler.Job{
+		Name:        "process_exports",
+		Description: "Creates the exports of the due export schedules and runs the pending exports, and the exports whose run was interrupted",
+		Enabled:     cfg.Export.Bucket != "",
+		Interval:    time.Minute,
+		Run:         exportService.ProcessExports,
+	})
+
+	purgeInterval := time.Duration(cfg.SoftDelete.IntervalMins) * time.Minute
+	if purgeInterval <= 0 {
+		purgeInterval = 24 * time.Hour
+	}
+	jobScheduler.Register(scheduler.Job{
+		Name:        "purge_deleted_records",
+		Description: "Removes the plans, prices and customers deleted for longer than the purge delay",
+		Enabled:     cfg.SoftDelete.Enabled,
+		Interval:    purgeInterval,
+		Run:         softDeleteService.PurgeDeleted,
+	})
+
+	// The rollups table only exists when the rollups are enabled
+	if cfg.UsageRollup.Enabled {
+		rollupInterval := time.Duration(cfg.UsageRollup.IntervalMins) * time.Minute
+		if rollupInterval <= 0 {
+			rollupInterval = 15 * time.Minute
+		}
+		jobScheduler.Register(scheduler.Job{
+			Name:        "roll_up_usage",
+			Description: "Rolls up the hourly and daily usage of every meter",
+			Enabled:     true,
+			Interval:    rollupInterval,
+			Run:         usageRollupService.RollUpUsage,
+		})
+	}
+
+	return jobScheduler
 }
 
-func provideRouter(handlers api.Handlers, cfg *config.Configuration, logger *logger.Logger) *gin.Engine {
-	return api.NewRouter(handlers, cfg, logger)
+func provideRouter(handlers api.Handlers, cfg *config.Configuration, secretService service.SecretService, roleService service.RoleService, requestLogService service.RequestLogService, limiter ratelimit.Limiter, logger *logger.Logger) *gin.Engine {
+	return api.NewRouter(handlers, cfg, secretService, roleService, requestLogService, limiter, logger)
+}
+
+// provideRateLimiter connects to Redis when rate limiting is enabled. The
+// middleware lets every request through without a limiter
+func provideRateLimiter(lc fx.Lifecycle, cfg *config.Configuration) ratelimit.Limiter {
+	if !cfg.RateLimit.Enabled {
+		return nil
+	}
+
+	client := ratelimit.NewRedisClient(cfg.Redis)
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return client.Close()
+		},
+	})
+	return ratelimit.NewRedisLimiter(client)
+}
+
+// provideUsageStreamBroker returns the broker fanning the ingested events out to
+// the live usage streams, nil when streaming is disabled
+func provideUsageStreamBroker(lc fx.Lifecycle, cfg *config.Configuration, log *logger.Logger) usagestream.Broker {
+	if !cfg.UsageStream.Enabled {
+		return nil
+	}
+
+	switch cfg.UsageStream.Backend {
+	case types.UsageStreamBackendRedis:
+		client := ratelimit.NewRedisClient(cfg.Redis)
+		lc.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				return client.Close()
+			},
+		})
+		return usagestream.NewRedisBroker(client)
+	case types.UsageStreamBackendMemory, "":
+		if cfg.Deployment.Mode != types.ModeLocal {
+			log.Warnf("usage stream memory backend only reaches streams served by the consumer process, use redis in %s mode", cfg.Deployment.Mode)
+		}
+		return usagestream.NewMemoryBroker()
+	default:
+		log.Fatalf("Unknown usage stream backend: %s", cfg.UsageStream.Backend)
+		return nil
+	}
 }
 
 func startServer(
 	lc fx.Lifecycle,
 	cfg *config.Configuration,
 	r *gin.Engine,
+	grpcServer *grpc.Server,
 	consumer kafka.MessageConsumer,
+	newConsumer consumerFactory,
 	eventRepo events.Repository,
+	usageBroker usagestream.Broker,
+	deadLetterService service.EventDeadLetterService,
+	webhookDispatcher *webhook.Dispatcher,
+	jobScheduler *scheduler.Scheduler,
 	log *logger.Logger,
 ) {
 	mode := cfg.Deployment.Mode
@@ -143,18 +656,27 @@ func startServer(
 			log.Fatal("Kafka consumer required for local mode")
 		}
 		startAPIServer(lc, r, cfg, log)
-		startConsumer(lc, consumer, eventRepo, cfg, log)
+		startGRPCServer(lc, grpcServer, cfg, log)
+		startConsumer(lc, consumer, newConsumer, eventRepo, usageBroker, deadLetterService, cfg, log)
+		startWebhookDispatcher(lc, webhookDispatcher)
+		startScheduler(lc, jobScheduler, log)
 	case types.ModeAPI:
 		startAPIServer(lc, r, cfg, log)
+		startWebhookDispatcher(lc, webhookDispatcher)
+	case types.ModeGRPC:
+		startGRPCServer(lc, grpcServer, cfg, log)
+	case types.ModeWorker:
+		startWebhookDispatcher(lc, webhookDispatcher)
+		startScheduler(lc, jobScheduler, log)
 	case types.ModeConsumer:
 		if consumer == nil {
 			log.Fatal("Kafka consumer required for consumer mode")
 		}
-		startConsumer(lc, consumer, eventRepo, cfg, log)
+		startConsumer(lc, consumer, newConsumer, eventRepo, usageBroker, deadLetterService, cfg, log)
 	case types.ModeAWSLambdaAPI:
 		startAWSLambdaAPI(r)
 	case types.ModeAWSLambdaConsumer:
-		startAWSLambdaConsumer(eventRepo, log)
+		startAWSLambdaConsumer(eventRepo, deadLetterService, log)
 	default:
 		log.Fatalf("Unknown deployment mode: %s", mode)
 	}
@@ -182,20 +704,106 @@ func startAPIServer(
 	})
 }
 
+func startGRPCServer(
+	lc fx.Lifecycle,
+	server *grpc.Server,
+	cfg *config.Configuration,
+	log *logger.Logger,
+) {
+	address := cfg.Server.GRPCAddress
+	if address == "" {
+		address = ":9090"
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", address)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", address, err)
+			}
+
+			go func() {
+				if err := server.Serve(listener); err != nil {
+					log.Fatalf("Failed to start gRPC server: %v", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			log.Info("Shutting down gRPC server...")
+			server.GracefulStop()
+			return nil
+		},
+	})
+}
+
 func startConsumer(
 	lc fx.Lifecycle,
 	consumer kafka.MessageConsumer,
+	newConsumer consumerFactory,
 	eventRepo events.Repository,
+	usageBroker usagestream.Broker,
+	deadLetterService service.EventDeadLetterService,
 	cfg *config.Configuration,
 	log *logger.Logger,
 ) {
+	// Each lane is read by its own consumer group, so the events of a lane are
+	// not held up by the backlog of the others. The process runs concurrency
+	// members of each group, the partitions of the lane are shared between them
+	consumers := []kafka.MessageConsumer{consumer}
+	ctx, cancel := context.WithCancel(context.Background())
 	lc.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
-			go consumeMessages(consumer, eventRepo, cfg.Kafka.Topic, log)
+		OnStart: func(context.Context) error {
+			for _, lane := range cfg.Kafka.ConsumerLanes() {
+				for i := 0; i < lane.Concurrency; i++ {
+					member := consumer
+					if lane.ConsumerGroup != cfg.Kafka.ConsumerGroup || i > 0 {
+						var err error
+						member, err = newConsumer(lane.ConsumerGroup)
+						if err != nil {
+							return fmt.Errorf("failed to create consumer of lane %s: %w", lane.Name, err)
+						}
+						consumers = append(consumers, member)
+					}
+					go consumeMessages(ctx, member, eventRepo, usageBroker, deadLetterService, lane.Topic, log)
+				}
+
+				log.Infof("Consuming lane %s: topic=%s, consumer_group=%s, concurrency=%d",
+					lane.Name, lane.Topic, lane.ConsumerGroup, lane.Concurrency)
+			}
 			return nil
 		},
-		OnStop: func(ctx context.Context) error {
+		OnStop: func(context.Context) error {
 			log.Info("Shutting down consumer...")
+			cancel()
+			for _, member := range consumers {
+				if err := member.Close(); err != nil {
+					log.Errorf("Failed to close consumer: %v", err)
+				}
+			}
+			return nil
+		},
+	})
+}
+
+func startWebhookDispatcher(lc fx.Lifecycle, dispatcher *webhook.Dispatcher) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			dispatcher.Start()
+			return nil
+		},
+	})
+}
+
+func startScheduler(lc fx.Lifecycle, jobScheduler *scheduler.Scheduler, log *logger.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			jobScheduler.Start()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			log.Info("Shutting down scheduler...")
+			jobScheduler.Stop()
 			return nil
 		},
 	})
@@ -206,18 +814,19 @@ func startAWSLambdaAPI(r *gin.Engine) {
 	lambda.Start(ginLambda.ProxyWithContext)
 }
 
-func startAWSLambdaConsumer(eventRepo events.Repository, log *logger.Logger) {
+func startAWSLambdaConsumer(eventRepo events.Repository, deadLetterService service.EventDeadLetterService, log *logger.Logger) {
 	handler := func(ctx context.Context, kafkaEvent lambdaEvents.KafkaEvent) error {
 		log.Debugf("Received Kafka event: %+v", kafkaEvent)
 
+		// The records are grouped by partition, each partition is stored as a
+		// batch. A batch that fails has the invocation retried
+		var errs []error
 		for _, record := range kafkaEvent.Records {
+			batch := make([]*kafka.Message, 0, len(record))
 			for _, r := range record {
 				log.Debugf("Processing record: topic=%s, partition=%d, offset=%d",
 					r.Topic, r.Partition, r.Offset)
 
-				// TODO decide the repository to use based on the event topic and properties
-				// For now we will use the event repository from the events topic
-
 				// Decode base64 payload first
 				decodedPayload, err := base64.StdEncoding.DecodeString(string(r.Value))
 				if err != nil {
@@ -225,49 +834,102 @@ func startAWSLambdaConsumer(eventRepo events.Repository, log *logger.Logger) {
 					continue
 				}
 
-				var event events.Event
-				if err := json.Unmarshal(decodedPayload, &event); err != nil {
-					log.Errorf("Failed to unmarshal event: %v, payload: %s", err, decodedPayload)
-					continue // Skip invalid messages
-				}
-
-				if err := eventRepo.InsertEvent(ctx, &event); err != nil {
-					log.Errorf("Failed to insert event: %v, event: %+v", err, event)
-					// TODO: Handle error and decide if we should retry or send to DLQ
-					continue
-				}
+				batch = append(batch, &kafka.Message{
+					ID:        fmt.Sprintf("%d-%d", r.Partition, r.Offset),
+					Topic:     r.Topic,
+					Partition: int32(r.Partition),
+					Offset:    r.Offset,
+					Payload:   decodedPayload,
+				})
+			}
 
-				log.Infof("Successfully processed event: topic=%s, partition=%d, offset=%d",
-					r.Topic, r.Partition, r.Offset)
+			if err := storeEvents(ctx, eventRepo, nil, deadLetterService, batch, log); err != nil {
+				errs = append(errs, err)
 			}
 		}
-		return nil
+		return errors.Join(errs...)
 	}
 
 	lambda.Start(handler)
 }
 
-func consumeMessages(consumer kafka.MessageConsumer, eventRepo events.Repository, topic string, log *logger.Logger) {
-	messages, err := consumer.Subscribe(topic)
+func consumeMessages(ctx context.Context, consumer kafka.MessageConsumer, eventRepo events.Repository, usageBroker usagestream.Broker, deadLetterService service.EventDeadLetterService, topic string, log *logger.Logger) {
+	err := consumer.Consume(ctx, topic, func(ctx context.Context, batch []*kafka.Message) error {
+		return storeEvents(ctx, eventRepo, usageBroker, deadLetterService, batch, log)
+	})
 	if err != nil {
-		log.Fatalf("Failed to subscribe to topic %s: %v", topic, err)
+		log.Fatalf("Failed to consume topic %s: %v", topic, err)
+	}
+}
+
+// storeEvents inserts the events of a batch of messages at once. Messages which
+// cannot be decoded or fail validation are dead-lettered. When the insert
+// fails the events are inserted one by one, so only the failing ones are
+// dead-lettered. When every insert fails the event store is taken to be down
+// and nothing is dead-lettered, the error is returned so the batch is consumed
+// again. An error is also returned when a message could not be dead-lettered,
+// the batch is then consumed again and its stored events deduplicated
+func storeEvents(ctx context.Context, eventRepo events.Repository, usageBroker usagestream.Broker, deadLetterService service.EventDeadLetterService, batch []*kafka.Message, log *logger.Logger) error {
+	type rejection struct {
+		msg       *kafka.Message
+		messageID string
+		err       error
 	}
 
-	for msg := range messages {
+	var rejected []rejection
+	consumed := make([]*events.Event, 0, len(batch))
+	payloads := make(map[*events.Event]*kafka.Message, len(batch))
+	for _, msg := range batch {
 		var event events.Event
 		if err := json.Unmarshal(msg.Payload, &event); err != nil {
 			log.Errorf("Failed to unmarshal event: %v, payload: %s", err, string(msg.Payload))
-			msg.Ack() // Acknowledge invalid messages
+			rejected = append(rejected, rejection{msg: msg, messageID: msg.ID, err: err})
 			continue
 		}
+		if err := event.Validate(); err != nil {
+			log.Errorf("Invalid event: %v, event_id: %s", err, event.ID)
+			rejected = append(rejected, rejection{msg: msg, messageID: event.ID, err: err})
+			continue
+		}
+		consumed = append(consumed, &event)
+		payloads[&event] = msg
+	}
 
-		log.Debugf("Starting to process event: %+v", event)
+	stored := consumed
+	if err := eventRepo.InsertEvents(ctx, consumed); err != nil {
+		log.Errorf("Failed to insert batch of %d events, inserting them one by one: %v", len(consumed), err)
+
+		stored = make([]*events.Event, 0, len(consumed))
+		var failed []rejection
+		for _, event := range consumed {
+			if err := eventRepo.InsertEvent(ctx, event); err != nil {
+				log.Errorf("Failed to insert event: %v, event: %+v", err, event)
+				failed = append(failed, rejection{msg: payloads[event], messageID: event.ID, err: err})
+				continue
+			}
+			stored = append(stored, event)
+		}
 
-		if err := eventRepo.InsertEvent(context.Background(), &event); err != nil {
-			log.Errorf("Failed to insert event: %v, event: %+v", err, event)
-			// TODO: Handle error and decide if we should retry or send to DLQ
+		// The events are not at fault when none of them could be stored
+		if len(stored) == 0 && len(consumed) > 0 {
+			return fmt.Errorf("failed to insert batch of %d events: %w", len(consumed), err)
 		}
-		msg.Ack()
-		log.Debugf("Successfully processed event: %+v", event)
+		rejected = append(rejected, failed...)
 	}
+
+	var errs []error
+	for _, r := range rejected {
+		errs = append(errs, deadLetterService.Record(ctx, r.msg.Topic, r.messageID, r.msg.Payload, r.err))
+	}
+
+	for _, event := range stored {
+		if usageBroker != nil && event.ExternalCustomerID != "" {
+			if err := usageBroker.Publish(ctx, usagestream.NewUpdate(event)); err != nil {
+				log.Errorf("Failed to publish usage update: %v, event_id: %s", err, event.ID)
+			}
+		}
+	}
+
+	log.Debugf("Stored %d of %d events", len(stored), len(batch))
+	return errors.Join(errs...)
 }
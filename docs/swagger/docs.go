@@ -15,39 +15,34 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Login a user",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/clock": {
+            "get": {
+                "description": "Get the time of the billing flows and whether it is simulated",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "Admin"
                 ],
-                "summary": "Login",
+                "summary": "Get billing clock",
                 "parameters": [
                     {
-                        "description": "Login request",
-                        "name": "login",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.AuthResponse"
+                            "$ref": "#/definitions/dto.SimulationClockResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -55,9 +50,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/signup": {
+        "/admin/clock/advance": {
             "post": {
-                "description": "Sign up a new user",
+                "description": "Move the simulated clock of a sandbox environment forward and run the trial, threshold, renewal, credit expiry and auto top-up jobs at the new time. Only available with simulation enabled, for the sandbox environment of the clock",
                 "consumes": [
                     "application/json"
                 ],
@@ -65,17 +60,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "Admin"
                 ],
-                "summary": "Sign up",
+                "summary": "Advance simulated clock",
                 "parameters": [
                     {
-                        "description": "Sign up request",
-                        "name": "signup",
+                        "type": "string",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Time to move the clock to",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.SignUpRequest"
+                            "$ref": "#/definitions/dto.AdvanceClockRequest"
                         }
                     }
                 ],
@@ -83,7 +85,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.AuthResponse"
+                            "$ref": "#/definitions/dto.SimulationClockResponse"
                         }
                     },
                     "400": {
@@ -91,74 +93,15 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/customers": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get customers",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "customers"
-                ],
-                "summary": "Get customers",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "order",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "sort",
-                        "in": "query"
                     },
-                    {
-                        "enum": [
-                            "published",
-                            "deleted",
-                            "archived"
-                        ],
-                        "type": "string",
-                        "x-enum-varnames": [
-                            "StatusPublished",
-                            "StatusDeleted",
-                            "StatusArchived"
-                        ],
-                        "name": "status",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/dto.ListCustomersResponse"
+                            "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -170,44 +113,36 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create a customer",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/events/consumer-lag": {
+            "get": {
+                "description": "Get how many events of each lane, and of each partition of its topic, the consumer group of the lane has not stored yet",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "customers"
+                    "Admin"
                 ],
-                "summary": "Create a customer",
+                "summary": "Get events consumer lag",
                 "parameters": [
                     {
-                        "description": "Customer",
-                        "name": "customer",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.CreateCustomerRequest"
-                        }
+                        "type": "string",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.CustomerResponse"
+                            "$ref": "#/definitions/dto.ConsumerLagResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -221,30 +156,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/customers/{id}": {
+        "/admin/events/retention": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a customer",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List the event retention policies of the tenants. Tenants without a policy keep their events for the default TTL",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "customers"
+                    "Admin"
                 ],
-                "summary": "Get a customer",
+                "summary": "List event retention policies",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Customer ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
                         "required": true
                     }
                 ],
@@ -252,11 +179,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.CustomerResponse"
+                            "$ref": "#/definitions/dto.ListRetentionPoliciesResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -268,14 +195,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/events/retention/{tenant_id}": {
             "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update a customer",
+                "description": "Create or replace how long the events of a tenant are kept, a TTL of 0 keeps them forever",
                 "consumes": [
                     "application/json"
                 ],
@@ -283,24 +207,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "customers"
+                    "Admin"
                 ],
-                "summary": "Update a customer",
+                "summary": "Set event retention policy",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Customer ID",
-                        "name": "id",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tenant ID",
+                        "name": "tenant_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Customer",
-                        "name": "customer",
+                        "description": "Retention policy",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateCustomerRequest"
+                            "$ref": "#/definitions/dto.SetRetentionPolicyRequest"
                         }
                     }
                 ],
@@ -308,7 +239,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.CustomerResponse"
+                            "$ref": "#/definitions/dto.RetentionPolicyResponse"
                         }
                     },
                     "400": {
@@ -317,6 +248,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -326,27 +263,26 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Delete a customer",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete the retention policy of a tenant, its events are then kept for the default TTL",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "customers"
+                    "Admin"
                 ],
-                "summary": "Delete a customer",
+                "summary": "Delete event retention policy",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Customer ID",
-                        "name": "id",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tenant ID",
+                        "name": "tenant_id",
                         "in": "path",
                         "required": true
                     }
@@ -355,14 +291,14 @@ const docTemplate = `{
                     "204": {
                         "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -370,30 +306,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/customers/{id}/wallets": {
+        "/admin/events/storage": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all wallets for a customer",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the rows and bytes of events of every tenant and every monthly partition, with the cutoff of the next retention run and when each partition is dropped",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Wallet"
+                    "Admin"
                 ],
-                "summary": "Get wallets by customer ID",
+                "summary": "Get events storage",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Customer ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
                         "required": true
                     }
                 ],
@@ -401,14 +329,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.WalletResponse"
-                            }
+                            "$ref": "#/definitions/dto.EventStorageResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -422,70 +347,104 @@ const docTemplate = `{
                 }
             }
         },
-        "/events": {
+        "/admin/feature-flags/{tenant_id}": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieve raw events with pagination and filtering",
+                "description": "List the feature flags with their default and the overrides of a tenant",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "events"
+                    "Admin"
                 ],
-                "summary": "Get raw events",
+                "summary": "List feature flags of a tenant",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "External Customer ID",
-                        "name": "external_customer_id",
-                        "in": "query"
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Event Name",
-                        "name": "event_name",
-                        "in": "query"
+                        "description": "Tenant ID",
+                        "name": "tenant_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListFeatureFlagsResponse"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Start Time (RFC3339)",
-                        "name": "start_time",
-                        "in": "query"
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "End Time (RFC3339)",
-                        "name": "end_time",
-                        "in": "query"
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/feature-flags/{tenant_id}/{flag}": {
+            "put": {
+                "description": "Turn a feature flag on or off for a tenant, in one of its environments or in all of them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Set feature flag override",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Iter First Key (unix_timestamp_nanoseconds::event_id)",
-                        "name": "iter_first_key",
-                        "in": "query"
+                        "description": "Tenant ID",
+                        "name": "tenant_id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Iter Last Key (unix_timestamp_nanoseconds::event_id)",
-                        "name": "iter_last_key",
-                        "in": "query"
+                        "description": "Feature flag",
+                        "name": "flag",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Page Size (1-50)",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "Feature flag override",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetFeatureFlagRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.GetEventsResponse"
+                            "$ref": "#/definitions/dto.FeatureFlagOverrideResponse"
                         }
                     },
                     "400": {
@@ -494,6 +453,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -502,52 +467,56 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Ingest a new event into the system",
-                "consumes": [
-                    "application/json"
-                ],
+            "delete": {
+                "description": "Delete an override of a tenant, the flag then falls back on the override of the tenant or on its default",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "events"
+                    "Admin"
                 ],
-                "summary": "Ingest event",
+                "summary": "Delete feature flag override",
                 "parameters": [
                     {
-                        "description": "Event data",
-                        "name": "event",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.IngestEventRequest"
-                        }
+                        "type": "string",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tenant ID",
+                        "name": "tenant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Feature flag",
+                        "name": "flag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment of the override, the override of all environments when empty",
+                        "name": "environment_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "message:Event accepted for processing",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -555,41 +524,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/events/usage": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieve aggregated usage statistics for events",
+        "/admin/jobs": {
+            "get": {
+                "description": "List the background jobs with their schedule as configured for the deployment and their last run",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "events"
+                    "Admin"
                 ],
-                "summary": "Get usage statistics",
+                "summary": "List background jobs",
                 "parameters": [
                     {
-                        "description": "Request body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.GetUsageRequest"
-                        }
+                        "type": "string",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.GetUsageResponse"
+                            "$ref": "#/definitions/dto.ListJobsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -603,41 +565,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/events/usage/meter": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieve aggregated usage statistics using meter configuration",
+        "/admin/jobs/{name}/runs": {
+            "get": {
+                "description": "List the latest runs of a background job first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "events"
+                    "Admin"
                 ],
-                "summary": "Get usage by meter",
+                "summary": "List job runs",
                 "parameters": [
                     {
-                        "description": "Request body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.GetUsageByMeterRequest"
-                        }
+                        "type": "string",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of runs (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.GetUsageResponse"
+                            "$ref": "#/definitions/dto.ListJobRunsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -657,46 +625,68 @@ const docTemplate = `{
                 }
             }
         },
-        "/meters": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all meters",
+        "/admin/jobs/{name}/trigger": {
+            "post": {
+                "description": "Start a run of a background job right away, disabled jobs included. The run happens in the background, poll the runs of the job for its result",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meters"
+                    "Admin"
+                ],
+                "summary": "Trigger job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin key",
+                        "name": "X-Admin-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List meters",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.MeterResponse"
-                            }
+                            "$ref": "#/definitions/dto.JobRunResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/analytics/churn-reasons": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new meter with the specified configuration",
+                "description": "Aggregate the subscriptions cancelled in a window by cancellation reason, plan and tenure. The window defaults to the last 30 days",
                 "consumes": [
                     "application/json"
                 ],
@@ -704,25 +694,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "meters"
+                    "Analytics"
                 ],
-                "summary": "Create meter",
+                "summary": "Get churn reasons",
                 "parameters": [
                     {
-                        "description": "Meter configuration",
-                        "name": "meter",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.CreateMeterRequest"
-                        }
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.MeterResponse"
+                            "$ref": "#/definitions/dto.ChurnReasonsResponse"
                         }
                     },
                     "400": {
@@ -740,27 +731,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/meters/{id}": {
+        "/analytics/revenue": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a specific meter by ID",
+                "description": "Report the MRR, ARR, net revenue retention, churn and expansion of each calendar month from start_month to end_month (YYYY-MM), optionally by plan, currency or customer segment, the segment metadata of the customers. The MRR of a subscription is the revenue of its finalized invoices for the period running at the end of the month, per month of its billing period. Closed months are served from snapshots",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meters"
+                    "Analytics"
                 ],
-                "summary": "Get meter",
+                "summary": "Get revenue analytics",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Meter ID",
-                        "name": "id",
-                        "in": "path",
+                        "name": "end_month",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "plan",
+                            "currency",
+                            "segment"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "RevenueGroupByPlan",
+                            "RevenueGroupByCurrency",
+                            "RevenueGroupBySegment"
+                        ],
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_month",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -768,11 +782,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.MeterResponse"
+                            "$ref": "#/definitions/dto.RevenueAnalyticsResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -784,91 +798,46 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/analytics/revenue/snapshots/refresh": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing meter",
-                "produces": [
+                "description": "Compute the revenue snapshots of the closed months of the range from the invoices again, after invoices of those months were finalized or voided late. Months not closed yet are skipped",
+                "consumes": [
                     "application/json"
                 ],
-                "tags": [
-                    "meters"
-                ],
-                "summary": "Delete meter",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Meter ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "message:Meter deleted successfully",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/meters/{id}/disable": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Disable an existing meter",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meters"
+                    "Analytics"
                 ],
-                "summary": "Disable meter [TODO: Deprecate]",
+                "summary": "Refresh revenue snapshots",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Meter ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Months to refresh",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshRevenueSnapshotsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "message:Meter disabled successfully",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.RefreshRevenueSnapshotsResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -882,14 +851,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/plans": {
+        "/audit-logs": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get plans with the specified filter",
+                "description": "List the changes made to the entities of the environment, most recent first, with the user or API key that made them and the fields they changed",
                 "consumes": [
                     "application/json"
                 ],
@@ -897,43 +866,121 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "plans"
+                    "Audit Logs"
                 ],
-                "summary": "Get plans",
+                "summary": "List audit logs",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "name": "limit",
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete",
+                            "restore"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "AuditActionCreate",
+                            "AuditActionUpdate",
+                            "AuditActionDelete",
+                            "AuditActionRestore"
+                        ],
+                        "name": "action",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "name": "offset",
+                        "type": "string",
+                        "description": "ActorID is a user ID or an API key ID",
+                        "name": "actor_id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "name": "order",
+                        "name": "end_time",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "name": "sort",
+                        "name": "entity_id",
                         "in": "query"
                     },
                     {
                         "enum": [
-                            "published",
-                            "deleted",
-                            "archived"
+                            "customer",
+                            "plan",
+                            "price",
+                            "meter",
+                            "rate_card",
+                            "price_book",
+                            "subscription",
+                            "subscription_line_item",
+                            "invoice",
+                            "invoice_line_item",
+                            "invoice_numbering",
+                            "invoice_payment",
+                            "refund",
+                            "invoice_dispute",
+                            "credit_allocation",
+                            "wallet",
+                            "payment_method",
+                            "mandate",
+                            "api_key",
+                            "connection",
+                            "webhook_endpoint",
+                            "cancellation_reason",
+                            "role_assignment",
+                            "sso_config",
+                            "event",
+                            "event_schema",
+                            "budget",
+                            "legal_entity"
                         ],
                         "type": "string",
                         "x-enum-varnames": [
-                            "StatusPublished",
-                            "StatusDeleted",
-                            "StatusArchived"
+                            "AuditEntityTypeCustomer",
+                            "AuditEntityTypePlan",
+                            "AuditEntityTypePrice",
+                            "AuditEntityTypeMeter",
+                            "AuditEntityTypeRateCard",
+                            "AuditEntityTypePriceBook",
+                            "AuditEntityTypeSubscription",
+                            "AuditEntityTypeSubscriptionItem",
+                            "AuditEntityTypeInvoice",
+                            "AuditEntityTypeInvoiceLineItem",
+                            "AuditEntityTypeInvoiceNumbering",
+                            "AuditEntityTypeInvoicePayment",
+                            "AuditEntityTypeRefund",
+                            "AuditEntityTypeInvoiceDispute",
+                            "AuditEntityTypeCreditAllocation",
+                            "AuditEntityTypeWallet",
+                            "AuditEntityTypePaymentMethod",
+                            "AuditEntityTypeMandate",
+                            "AuditEntityTypeAPIKey",
+                            "AuditEntityTypeConnection",
+                            "AuditEntityTypeWebhookEndpoint",
+                            "AuditEntityTypeCancellationReason",
+                            "AuditEntityTypeRoleAssignment",
+                            "AuditEntityTypeSSOConfig",
+                            "AuditEntityTypeEvent",
+                            "AuditEntityTypeEventSchema",
+                            "AuditEntityTypeBudget",
+                            "AuditEntityTypeLegalEntity"
                         ],
-                        "name": "status",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
                         "in": "query"
                     }
                 ],
@@ -941,7 +988,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ListPlansResponse"
+                            "$ref": "#/definitions/dto.ListAuditLogsResponse"
                         }
                     },
                     "400": {
@@ -957,14 +1004,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/login": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create a new plan with the specified configuration",
+                "description": "Login a user",
                 "consumes": [
                     "application/json"
                 ],
@@ -972,25 +1016,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "plans"
+                    "auth"
                 ],
-                "summary": "Create a new plan",
+                "summary": "Login",
                 "parameters": [
                     {
-                        "description": "Plan configuration",
-                        "name": "plan",
+                        "description": "Login request",
+                        "name": "login",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreatePlanRequest"
+                            "$ref": "#/definitions/dto.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PlanResponse"
+                            "$ref": "#/definitions/dto.AuthResponse"
                         }
                     },
                     "400": {
@@ -998,24 +1042,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
                     }
                 }
             }
         },
-        "/plans/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a plan by ID",
+        "/auth/signup": {
+            "post": {
+                "description": "Sign up a new user",
                 "consumes": [
                     "application/json"
                 ],
@@ -1023,23 +1056,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "plans"
+                    "auth"
                 ],
-                "summary": "Get a plan by ID",
+                "summary": "Sign up",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Plan ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Sign up request",
+                        "name": "signup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignUpRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PlanResponse"
+                            "$ref": "#/definitions/dto.AuthResponse"
                         }
                     },
                     "400": {
@@ -1047,94 +1082,81 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update a plan by ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/auth/sso/oidc/callback": {
+            "get": {
+                "description": "Sign in the user authenticated by the OIDC provider. Redirects to the dashboard with the auth token in the URL fragment when a redirect URL is configured",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "plans"
+                    "auth"
                 ],
-                "summary": "Update a plan by ID",
+                "summary": "OIDC single sign on callback",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Plan ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "Plan configuration",
-                        "name": "plan",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UpdatePlanRequest"
-                        }
+                        "type": "string",
+                        "description": "Login state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PlanResponse"
+                            "$ref": "#/definitions/dto.AuthResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
+                    "302": {
+                        "description": "Found"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Delete a plan by ID",
+            }
+        },
+        "/auth/sso/saml/acs": {
+            "post": {
+                "description": "Sign in the user of the SAML response posted by the identity provider. Redirects to the dashboard with the auth token in the URL fragment when a redirect URL is configured",
                 "consumes": [
-                    "application/json"
+                    "application/x-www-form-urlencoded"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "plans"
+                    "auth"
                 ],
-                "summary": "Delete a plan by ID",
+                "summary": "SAML assertion consumer service",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Plan ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "SAML response",
+                        "name": "SAMLResponse",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Login state",
+                        "name": "RelayState",
+                        "in": "formData",
                         "required": true
                     }
                 ],
@@ -1142,17 +1164,14 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/dto.AuthResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
+                    "302": {
+                        "description": "Found"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -1160,14 +1179,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/prices": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get prices with the specified filter",
+        "/auth/sso/start": {
+            "post": {
+                "description": "Start the single sign on of a user with the identity provider of the tenant of the email domain",
                 "consumes": [
                     "application/json"
                 ],
@@ -1175,43 +1189,65 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "auth"
                 ],
-                "summary": "Get prices",
+                "summary": "Start single sign on",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Start SSO request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.StartSSORequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StartSSOResponse"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "name": "order",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets": {
+            "get": {
+                "security": [
                     {
-                        "type": "string",
-                        "name": "sort",
-                        "in": "query"
-                    },
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the budgets of the tenant, or of a customer, with their spend as of their last evaluation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "List budgets",
+                "parameters": [
                     {
-                        "enum": [
-                            "published",
-                            "deleted",
-                            "archived"
-                        ],
                         "type": "string",
-                        "x-enum-varnames": [
-                            "StatusPublished",
-                            "StatusDeleted",
-                            "StatusArchived"
-                        ],
-                        "name": "status",
+                        "description": "Customer ID",
+                        "name": "customer_id",
                         "in": "query"
                     }
                 ],
@@ -1219,7 +1255,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ListPricesResponse"
+                            "$ref": "#/definitions/dto.ListBudgetsResponse"
                         }
                     },
                     "400": {
@@ -1242,7 +1278,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new price with the specified configuration",
+                "description": "Cap the usage charges of a customer, or of one of its subscriptions, over a month or billing period. Once the spend exceeds the amount a budget.exceeded webhook is sent and the action of the budget is taken: alert only, block the events of the billed meters, or pause the subscriptions",
                 "consumes": [
                     "application/json"
                 ],
@@ -1250,17 +1286,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "Budgets"
                 ],
-                "summary": "Create a new price",
+                "summary": "Create a budget",
                 "parameters": [
                     {
-                        "description": "Price configuration",
-                        "name": "price",
+                        "description": "Create budget request",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreatePriceRequest"
+                            "$ref": "#/definitions/dto.CreateBudgetRequest"
                         }
                     }
                 ],
@@ -1268,7 +1304,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.PriceResponse"
+                            "$ref": "#/definitions/dto.BudgetResponse"
                         }
                     },
                     "400": {
@@ -1286,14 +1322,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/prices/{id}": {
+        "/budgets/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a price by ID",
+                "description": "Get a budget with its spend as of its last evaluation",
                 "consumes": [
                     "application/json"
                 ],
@@ -1301,13 +1337,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "Budgets"
                 ],
-                "summary": "Get a price by ID",
+                "summary": "Get a budget",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Price ID",
+                        "description": "Budget ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1317,7 +1353,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PriceResponse"
+                            "$ref": "#/definitions/dto.BudgetResponse"
                         }
                     },
                     "400": {
@@ -1326,8 +1362,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -1340,7 +1376,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a price with the specified configuration",
+                "description": "Change the name, amount or action of a budget. The spend is evaluated against them right away",
                 "consumes": [
                     "application/json"
                 ],
@@ -1348,24 +1384,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "Budgets"
                 ],
-                "summary": "Update a price",
+                "summary": "Update a budget",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Price ID",
+                        "description": "Budget ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Price configuration",
-                        "name": "price",
+                        "description": "Update budget request",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdatePriceRequest"
+                            "$ref": "#/definitions/dto.UpdateBudgetRequest"
                         }
                     }
                 ],
@@ -1373,7 +1409,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PriceResponse"
+                            "$ref": "#/definitions/dto.BudgetResponse"
                         }
                     },
                     "400": {
@@ -1396,7 +1432,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a price",
+                "description": "Delete a budget. The usage it blocked is let through again, the subscriptions it paused stay paused",
                 "consumes": [
                     "application/json"
                 ],
@@ -1404,13 +1440,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "Budgets"
                 ],
-                "summary": "Delete a price",
+                "summary": "Delete a budget",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Price ID",
+                        "description": "Budget ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1438,64 +1474,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions": {
+        "/cancellation-reasons": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get subscriptions with optional filtering",
+                "description": "List the cancellation taxonomy of the tenant, or the default taxonomy when the tenant has not configured one",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
-                ],
-                "summary": "List subscriptions",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Filter by customer ID",
-                        "name": "customer_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by subscription status",
-                        "name": "subscription_status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by plan ID",
-                        "name": "plan_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Offset for pagination",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit for pagination",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "Cancellation Reasons"
                 ],
+                "summary": "List cancellation reasons",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ListSubscriptionsResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/dto.ListCancellationReasonsResponse"
                         }
                     },
                     "500": {
@@ -1512,7 +1513,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new subscription",
+                "description": "Add a reason to the tenant's cancellation taxonomy. Once a tenant has a reason, the default taxonomy no longer applies",
                 "consumes": [
                     "application/json"
                 ],
@@ -1520,17 +1521,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "Cancellation Reasons"
                 ],
-                "summary": "Create subscription",
+                "summary": "Create a cancellation reason",
                 "parameters": [
                     {
-                        "description": "Subscription Request",
-                        "name": "subscription",
+                        "description": "Create cancellation reason request",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateSubscriptionRequest"
+                            "$ref": "#/definitions/dto.CreateCancellationReasonRequest"
                         }
                     }
                 ],
@@ -1538,7 +1539,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                            "$ref": "#/definitions/dto.CancellationReasonResponse"
                         }
                     },
                     "400": {
@@ -1556,37 +1557,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions/usage": {
-            "post": {
+        "/cancellation-reasons/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get usage by subscription",
+                "description": "Remove a reason from the tenant's taxonomy. Past cancellations keep their reason",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "Cancellation Reasons"
                 ],
-                "summary": "Get usage by subscription",
+                "summary": "Delete a cancellation reason",
                 "parameters": [
                     {
-                        "description": "Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.GetUsageBySubscriptionRequest"
-                        }
+                        "type": "string",
+                        "description": "Cancellation reason ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.GetUsageBySubscriptionResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
                     "400": {
@@ -1604,35 +1606,92 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions/{id}": {
+        "/connections": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a subscription by ID",
+                "description": "List the integration connections of the tenant",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "Connections"
                 ],
-                "summary": "Get subscription",
+                "summary": "List connections",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Subscription ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                            "$ref": "#/definitions/dto.ListConnectionsResponse"
                         }
                     },
                     "400": {
@@ -1648,16 +1707,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/subscriptions/{id}/cancel": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Cancel a subscription",
+                "description": "Create a connection to an external integration",
                 "consumes": [
                     "application/json"
                 ],
@@ -1665,29 +1722,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "Connections"
                 ],
-                "summary": "Cancel subscription",
+                "summary": "Create a connection",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Subscription ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Cancel at period end",
-                        "name": "cancel_at_period_end",
-                        "in": "query"
-                    }
-                ],
+                        "description": "Create connection request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateConnectionRequest"
+                        }
+                    }
+                ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/dto.ConnectionResponse"
                         }
                     },
                     "400": {
@@ -1705,14 +1758,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me": {
+        "/connections/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get the current user's information",
+                "description": "Get a connection by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1720,14 +1773,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "Connections"
+                ],
+                "summary": "Get a connection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Connection ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get user info",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.ConnectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
                     "500": {
@@ -1739,14 +1807,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/wallets": {
+        "/connections/{id}/stripe-import": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new wallet for a customer",
+                "description": "Import the products, prices, customers and subscriptions of the Stripe account of a connection. Objects imported before are reported as existing, so the import can be run again. Use dry_run to preview the import",
                 "consumes": [
                     "application/json"
                 ],
@@ -1754,17 +1822,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Wallet"
+                    "Connections"
                 ],
-                "summary": "Create a new wallet",
+                "summary": "Import from Stripe",
                 "parameters": [
                     {
-                        "description": "Create wallet request",
+                        "type": "string",
+                        "description": "Connection ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Import request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateWalletRequest"
+                            "$ref": "#/definitions/dto.ImportStripeRequest"
                         }
                     }
                 ],
@@ -1772,7 +1847,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.WalletResponse"
+                            "$ref": "#/definitions/dto.ImportStripeResponse"
                         }
                     },
                     "400": {
@@ -1790,14 +1865,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/wallets/{id}": {
+        "/connections/{id}/sync-queue": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a wallet by its ID",
+                "description": "Get the pending, in flight and failed outbound sync tasks of a connection and whether it is backing off after a rate limit",
                 "consumes": [
                     "application/json"
                 ],
@@ -1805,13 +1880,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Wallet"
+                    "Connections"
                 ],
-                "summary": "Get wallet by ID",
+                "summary": "Get the sync queue status of a connection",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Wallet ID",
+                        "description": "Connection ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1821,7 +1896,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.WalletResponse"
+                            "$ref": "#/definitions/dto.SyncQueueStatusResponse"
                         }
                     },
                     "400": {
@@ -1830,12 +1905,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1845,28 +1914,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/wallets/{id}/balance/real-time": {
+        "/credit-notes/{id}/allocations": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get real-time balance of a wallet",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List where the amount of a credit note went along with the credit left to allocate",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Wallet"
+                    "Credit Notes"
                 ],
-                "summary": "Get wallet balance",
+                "summary": "List the allocations of a credit note",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Wallet ID",
+                        "description": "Credit invoice ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1876,7 +1942,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.WalletBalanceResponse"
+                            "$ref": "#/definitions/dto.ListCreditAllocationsResponse"
                         }
                     },
                     "400": {
@@ -1885,12 +1951,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1898,11 +1958,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/wallets/{id}/terminate": {
+            },
             "post": {
-                "description": "Terminates a wallet by closing it and debiting remaining balance",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allocate part of a finalized credit note, a CREDIT invoice, to reduce the amount due of another invoice of the customer or to credit one of its wallets",
                 "consumes": [
                     "application/json"
                 ],
@@ -1910,23 +1973,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "Credit Notes"
                 ],
-                "summary": "Terminate a wallet",
+                "summary": "Allocate a credit note",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Wallet ID",
+                        "description": "Credit invoice ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Allocation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AllocateCreditNoteRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.WalletResponse"
+                            "$ref": "#/definitions/dto.CreditAllocationResponse"
                         }
                     },
                     "400": {
@@ -1935,12 +2007,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1950,47 +2016,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/wallets/{id}/top-up": {
+        "/credit-notes/{id}/void": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Add credits to a wallet",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Void a credit note nothing was allocated from",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Wallet"
+                    "Credit Notes"
                 ],
-                "summary": "Top up wallet",
+                "summary": "Void a credit note",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Wallet ID",
+                        "description": "Credit invoice ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Top up request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.TopUpWalletRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.WalletResponse"
+                            "$ref": "#/definitions/dto.InvoiceResponse"
                         }
                     },
                     "400": {
@@ -1999,12 +2053,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2014,14 +2062,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/wallets/{id}/transactions": {
+        "/customers": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get transactions for a wallet with pagination",
+                "description": "Get customers",
                 "consumes": [
                     "application/json"
                 ],
@@ -2029,47 +2077,69 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Wallet"
+                    "customers"
                 ],
-                "summary": "Get wallet transactions",
+                "summary": "Get customers",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Wallet ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "default": 50,
-                        "description": "Limit",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "default": 0,
-                        "description": "Offset",
                         "name": "offset",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "default": "created_at",
-                        "description": "Sort field",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
                         "name": "sort",
                         "in": "query"
                     },
                     {
                         "enum": [
-                            "asc",
-                            "desc"
+                            "published",
+                            "deleted",
+                            "archived"
                         ],
                         "type": "string",
-                        "default": "desc",
-                        "description": "Sort order",
-                        "name": "order",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
                         "in": "query"
                     }
                 ],
@@ -2077,7 +2147,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.WalletTransactionsResponse"
+                            "$ref": "#/definitions/dto.ListCustomersResponse"
                         }
                     },
                     "400": {
@@ -2086,8 +2156,51 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Create a customer",
+                "parameters": [
+                    {
+                        "description": "Customer",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateCustomerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -2100,174 +2213,18768 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.AuthResponse": {
-            "type": "object",
-            "properties": {
-                "token": {
-                    "type": "string"
-                }
-            }
-        },
-        "dto.CreateCustomerRequest": {
-            "type": "object",
-            "required": [
-                "external_id"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "external_id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                }
-            }
         },
-        "dto.CreateMeterRequest": {
-            "type": "object",
-            "required": [
-                "aggregation",
-                "event_name",
-                "name"
+        "/customers/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Get a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Update a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Customer",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateCustomerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Delete a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/activity": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a chronological feed of subscription, invoice and wallet activity for a customer, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Get customer activity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "subscription.created",
+                                "subscription.cancelled",
+                                "invoice.created",
+                                "invoice.finalized",
+                                "wallet.created",
+                                "wallet.credited",
+                                "wallet.debited"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "activity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListCustomerActivityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/collections/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pause the collections of a customer until a date. Their scheduled bank debits are held and no payment can be collected through a gateway until the pause expires or is lifted. Payments can still be recorded",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Pause the collections of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pause",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PauseCollectionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/collections/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lift the pause of the collections of a customer before it expires",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Resume the collections of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/forecast": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Project the total of the invoice each active subscription of the customer renews with, from the usage of the current period so far. The linear model extrapolates the run-rate of the period, the seasonal model weighs the days left by the usage of their day of the week over the last four weeks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Forecast customer spend",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "linear",
+                            "seasonal"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "ForecastModelLinear",
+                            "ForecastModelSeasonal"
+                        ],
+                        "name": "model",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerForecastResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/invoices": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Run billing for the subscriptions of a customer whose current period ends at the billing date. Customers consolidating invoices get one invoice per currency with a section per subscription, others one invoice per subscription",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Create the invoices of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create customer invoices request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateCustomerInvoicesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListInvoicesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/mandates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the bank debit mandates of the customer, oldest first, with the ones revoked or failed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "List the mandates of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListMandatesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record the SEPA or ACH mandate the customer signed for one of their bank account payment methods. Its debits are notified to the customer the pre-notification days of the scheme before they are charged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Create a mandate for a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Mandate",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateMandateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MandateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/mandates/{mandate_id}/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke an active mandate, its scheduled debits fail when they are due",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Revoke a mandate of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Mandate ID",
+                        "name": "mandate_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Revocation",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RevokeMandateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MandateResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/payment-methods": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the payment methods attached to the customer at the payment gateway, the one the customer is charged to is marked as default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "List the payment methods of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListPaymentMethodsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attach a payment method collected at the payment gateway, ex with Stripe.js, to the customer at the gateway. The customer must have a stripe_customer_id in its metadata. The first payment method of a customer becomes its default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Attach a payment method to a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment method to attach",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AttachPaymentMethodRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PaymentMethodResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/payment-methods/{pm_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Detach the payment method at the payment gateway, it can no longer be charged. Payment methods charged for active subscriptions can not be detached",
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Detach a payment method from a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payment method ID",
+                        "name": "pm_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/payment-methods/{pm_id}/default": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make the payment method the one the customer is charged to, at the payment gateway and in the payment_method_id of the customer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Set the default payment method of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payment method ID",
+                        "name": "pm_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PaymentMethodResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore a deleted customer. Deleted customers can be restored until they are purged",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Restore a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/tax/validate-address": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Resolve the billing address of the customer, or the address passed, with the Avalara or TaxJar connection of the tenant. With update the resolved address is saved as the billing address of the customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Validate the address of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Address validation",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ValidateAddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ValidateAddressResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/usage/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream the events ingested for the customer as server-sent events named usage, as they are consumed. A keep alive comment is sent periodically",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "customers"
+                ],
+                "summary": "Stream customer usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/usagestream.Update"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/wallets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all wallets for a customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Get wallets by customer ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.WalletResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/developer/logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the recent requests made with API keys, most recent first, with their redacted and truncated bodies. Logs are kept for 7 days. Filter on the route template with endpoint ex /v1/customers/:id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Developer"
+                ],
+                "summary": "List API request logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "api_key_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "endpoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "RequestLogStatusSucceeded",
+                            "RequestLogStatusFailed"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "status_code",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListRequestLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/emails/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the emails sent to the customers of the tenant. Filter by entity_id for the emails of an invoice",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emails"
+                ],
+                "summary": "List email deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "sent",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "EmailDeliveryStatusPending",
+                            "EmailDeliveryStatusSent",
+                            "EmailDeliveryStatusFailed"
+                        ],
+                        "name": "delivery_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "invoice_finalized",
+                            "trial_will_end",
+                            "report_ready",
+                            "debit_pre_notification"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "EmailTemplateInvoiceFinalized",
+                            "EmailTemplateTrialWillEnd",
+                            "EmailTemplateReportReady",
+                            "EmailTemplateDebitPreNotification"
+                        ],
+                        "name": "template_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListEmailDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/emails/deliveries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an email delivery with its rendered content and its last attempt",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emails"
+                ],
+                "summary": "Get an email delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/emails/deliveries/{id}/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue a copy of an email delivery to be sent right away as it was first rendered",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emails"
+                ],
+                "summary": "Resend an email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/emails/templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the email template of every type, the default one when the tenant has not replaced it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emails"
+                ],
+                "summary": "List email templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListEmailTemplatesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/emails/templates/{type}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the template of a type of email. Subject and bodies are Go templates and are checked against sample data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emails"
+                ],
+                "summary": "Set an email template",
+                "parameters": [
+                    {
+                        "enum": [
+                            "invoice_finalized",
+                            "trial_will_end",
+                            "report_ready"
+                        ],
+                        "type": "string",
+                        "description": "Template type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Set email template request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetEmailTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EmailTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the template of the tenant for a type of email so that the default one is used",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emails"
+                ],
+                "summary": "Restore the default email template",
+                "parameters": [
+                    {
+                        "enum": [
+                            "invoice_finalized",
+                            "trial_will_end",
+                            "report_ready"
+                        ],
+                        "type": "string",
+                        "description": "Template type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/environments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the environments of the tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Environments"
+                ],
+                "summary": "List environments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListEnvironmentsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new environment for the tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Environments"
+                ],
+                "summary": "Create an environment",
+                "parameters": [
+                    {
+                        "description": "Create environment request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateEnvironmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EnvironmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/environments/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an environment by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Environments"
+                ],
+                "summary": "Get an environment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Environment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EnvironmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/environments/{id}/reset": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Wipe customers, subscriptions, invoices, wallets and events of a sandbox environment while preserving the catalog. Call without a token to get a confirmation token, then call again with it to start the reset",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Environments"
+                ],
+                "summary": "Reset a sandbox environment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Environment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reset environment request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResetEnvironmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation token issued",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResetEnvironmentResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Reset started",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResetEnvironmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/environments/{id}/resets/{reset_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the status and progress of an environment reset",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Environments"
+                ],
+                "summary": "Get environment reset progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Environment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reset ID",
+                        "name": "reset_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EnvironmentResetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/event-schemas": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the event schemas of the tenant ordered by event name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event Schemas"
+                ],
+                "summary": "List event schemas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListEventSchemasResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Define the required properties, types and allowed values of the events of an event name. Events that do not match are rejected or quarantined, per on_invalid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event Schemas"
+                ],
+                "summary": "Create an event schema",
+                "parameters": [
+                    {
+                        "description": "Create event schema request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateEventSchemaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventSchemaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/event-schemas/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an event schema by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event Schemas"
+                ],
+                "summary": "Get an event schema",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event schema ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventSchemaResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the rules of an event schema. Events already ingested or quarantined are not checked again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event Schemas"
+                ],
+                "summary": "Update an event schema",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event schema ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update event schema request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateEventSchemaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventSchemaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop checking the events of an event name. Quarantined events stay in quarantine",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event Schemas"
+                ],
+                "summary": "Delete an event schema",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event schema ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve raw events with pagination and filtering",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Get raw events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "External Customer ID",
+                        "name": "external_customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event Name",
+                        "name": "event_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start Time (RFC3339)",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End Time (RFC3339)",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Iter First Key (unix_timestamp_nanoseconds::event_id)",
+                        "name": "iter_first_key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Iter Last Key (unix_timestamp_nanoseconds::event_id)",
+                        "name": "iter_last_key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size (1-50)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ingest a new event into the system. An event that does not match the schema of its event name is rejected, or accepted into quarantine when its schema says so. Events of the meters blocked by an exceeded budget of the customer are rejected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Ingest event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Idempotency key of the event, used when the body has none",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Event data",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.IngestEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "message:Event accepted for processing",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.EventValidationResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "The customer is over a budget blocking the usage of the meter",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/backfill": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ingest historical events with their original timestamps, given inline or read from a JSON array of events at an s3 path. Events outside of the time range of the request are rejected. Backfills run in the background, rate limited and apart from the live ingestion, and the rollups of the usage they change are recomputed once they complete. The returned task tracks the progress, rows which failed are listed in its error report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Backfill historical events",
+                "parameters": [
+                    {
+                        "description": "Backfill request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateEventBackfillRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/backfill/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the status and progress of an event backfill",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Get an event backfill",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Backfill task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the events that the consumer failed to store, with the payload and the reason of their last failure. Filter by dead_letter_status=failed for the ones not replayed yet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "List dead-lettered events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "failed",
+                            "replayed"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "DeadLetterStatusFailed",
+                            "DeadLetterStatusReplayed"
+                        ],
+                        "name": "dead_letter_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "topic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListDeadLettersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/dead-letters/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Publish the selected dead-lettered events again to be consumed. Events that fail again are dead-lettered again with their new error",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Replay dead-lettered events",
+                "parameters": [
+                    {
+                        "description": "Dead letters to replay",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReplayDeadLettersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReplayDeadLettersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/dead-letters/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a dead-lettered event by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Get a dead-lettered event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DeadLetterResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream the events of a time range as newline delimited JSON, oldest first. Every line carries a cursor, an interrupted export resumes after an event by repeating the request with its cursor. The response is gzip compressed when the client accepts it. An error occurring once the export started is reported as a last line with an error field",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Export events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-12-09T00:00:00Z",
+                        "name": "end_time",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "event_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "external_customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-11-09T00:00:00Z",
+                        "name": "start_time",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportedEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/quarantine": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the events quarantined because they did not match the schema of their event name, with their violations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "List quarantined events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "event_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "quarantined",
+                            "resubmitted"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "QuarantineStatusQuarantined",
+                            "QuarantineStatusResubmitted"
+                        ],
+                        "name": "quarantine_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListQuarantinedEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/quarantine/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a quarantined event by the ID of the event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Get a quarantined event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.QuarantinedEventResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/quarantine/{id}/resubmit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ingest a quarantined event, replacing its properties with the fixed ones of the request if any. An event still not matching its schema stays in quarantine with its fixes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Re-submit a quarantined event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fixed properties",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResubmitQuarantinedEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.QuarantinedEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.EventValidationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/usage": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve aggregated usage statistics for events",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Get usage statistics",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetUsageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetUsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/usage/meter": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve aggregated usage statistics using meter configuration",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Get usage by meter",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetUsageByMeterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetUsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/correct": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the properties of an ingested event, identified by the event_id given at ingestion. A reversal of the event and a replacement with the new properties are written, so usage is aggregated from the corrected properties. Events in a billing period that is already invoiced can not be corrected. The correction is recorded in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Correct an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Correction request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CorrectEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an ingested event with the voids and corrections made to it, and the row its usage is currently aggregated from",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Get the history of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventHistoryResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/void": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel the usage of an ingested event, identified by the event_id given at ingestion. A reversal of the event is written which the usage aggregations subtract. Events in a billing period that is already invoiced can not be voided. The void is recorded in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Void an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Void request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VoidEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EventHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List exports with pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "List exports",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListExportsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start an asynchronous export of raw events or aggregated usage to object storage. The export is pending until the export job runs it, poll it for its status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Create an export",
+                "parameters": [
+                    {
+                        "description": "Create export request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateExportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/schedules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the export schedules of the tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "List export schedules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListExportSchedulesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedule a daily, weekly or monthly export of raw events or aggregated usage. Each run, at midnight UTC, exports the previous day, week or month",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Create an export schedule",
+                "parameters": [
+                    {
+                        "description": "Create export schedule request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateExportScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/schedules/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an export schedule by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Get an export schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an export schedule, which stops its runs. Its exports are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Delete an export schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an export by ID to poll its status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Get an export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a signed, time limited URL to download a completed export",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Get export download URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportDownloadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/gateways/{tenant_id}/{connection_id}/webhooks": {
+            "post": {
+                "description": "Endpoint to register at Stripe, Adyen or Razorpay for the webhooks of a connection. The signature is verified with the webhook secret of the connection, refunds pending at the gateway and bank debits processing are settled with the outcome notified",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Receive a webhook of a payment gateway",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID",
+                        "name": "tenant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Connection ID",
+                        "name": "connection_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Read only GraphQL endpoint over customers, subscriptions with their line items and usage, and invoices, to fetch nested data in one round trip. Field errors are returned next to the partial data with a 200 status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "Query billing data with GraphQL",
+                "parameters": [
+                    {
+                        "description": "GraphQL request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List invoices with filters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "List invoices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "FINALIZED",
+                            "VOIDED",
+                            "HELD"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "InvoiceStatusDraft",
+                            "InvoiceStatusFinalized",
+                            "InvoiceStatusVoided",
+                            "InvoiceStatusHeld"
+                        ],
+                        "name": "invoice_status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "SUBSCRIPTION",
+                            "ONE_OFF",
+                            "CREDIT"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "InvoiceTypeSubscription",
+                            "InvoiceTypeOneOff",
+                            "InvoiceTypeCredit"
+                        ],
+                        "name": "invoice_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "original_invoice_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "subscription_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListInvoicesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/numbering": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the invoice number format of the current tenant environment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Get invoice numbering config",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceNumberingConfigResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the invoice number format of the current tenant environment. Changing the prefix starts a new counter",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Update invoice numbering config",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the numbering config the update is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Invoice numbering config",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateInvoiceNumberingConfigRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceNumberingConfigResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/one-off": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Raise an invoice for a customer outside of any subscription, ex for professional services. Charges are priced by an existing fixed price or a free-form unit amount, discounts apply to the charges and taxes to the discounted subtotal. The invoice is created in draft unless finalize is set and is paid like any other invoice",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Create a one-off invoice",
+                "parameters": [
+                    {
+                        "description": "Create one-off invoice request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateOneOffInvoiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an invoice by ID along with its line items and linked credit invoices",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Get an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move an invoice held for review by the billing guardrails back to draft so that it can be finalized",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Approve a held invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the invoice the approval is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/disputes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the disputes of an invoice, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "List invoice disputes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListDisputesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record the amounts of line items of a finalized invoice the customer disputes, up to the part of the invoice not paid or submitted for collection. The bank debits and gateway collections of the invoice are paused until the dispute is resolved. Sent as an invoice.dispute.opened event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Dispute an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dispute",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OpenDisputeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DisputeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/disputes/{dispute_id}/resolve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Settle the dispute of an invoice and resume its collection. credit_note issues a credit note for the disputed amounts allocated to the invoice, reissue voids the invoice and raises a corrected draft without them, uphold keeps the invoice as issued. The debits of the invoice still scheduled are cancelled when its amount changes. Sent as an invoice.dispute.resolved event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Resolve an invoice dispute",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dispute ID",
+                        "name": "dispute_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resolution",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResolveDisputeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DisputeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/disputes/{dispute_id}/review": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move an open dispute of an invoice under review. Sent as an invoice.dispute.under_review event",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Review an invoice dispute",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dispute ID",
+                        "name": "dispute_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DisputeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/finalize": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Finalize a draft invoice so that it can no longer be edited",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Finalize an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the invoice the finalization is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/ledger-syncs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the reconciliation status of an invoice with every ledger connection",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ledger"
+                ],
+                "summary": "List the ledger syncs of an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListLedgerSyncsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/ledger-syncs/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue a finalized invoice again for the ledger connections it is not synced with",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ledger"
+                ],
+                "summary": "Retry the ledger sync of an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListLedgerSyncsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/line-items": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a manual line item to a draft or held invoice, such as a one-off charge or, with a negative amount, a discount. The totals of the invoice are recomputed and the edit is recorded in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Add an invoice line item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the invoice the edit is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Line item",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AddInvoiceLineItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/line-items/{li_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a line item from a draft or held invoice. A usage line item can only be removed when the configured tolerance allows its amount to go to zero. The edit is recorded in the audit log",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Remove an invoice line item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Line item ID",
+                        "name": "li_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the invoice the edit is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Edit the display name, amount or quantity of a line item of a draft or held invoice. The quantity of a usage line item is metered and its amount can only be adjusted within the configured tolerance. The edit is recorded in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Update an invoice line item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Line item ID",
+                        "name": "li_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the invoice the edit is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Line item change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateInvoiceLineItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/payments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the payments recorded against an invoice in the order they were paid, along with the amount paid and remaining",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "List invoice payments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListInvoicePaymentsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record a full or partial payment against a finalized invoice. The amount remaining and the payment status of the invoice are recomputed, a payment can not exceed the amount remaining",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Record an invoice payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the invoice the payment is recorded against",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Record invoice payment request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecordInvoicePaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoicePaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/payments/collect": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Charge a finalized invoice to a payment method of the customer at the payment gateway of a connection, Stripe, Adyen or Razorpay. The amount, the amount remaining by default, is authorized then captured and recorded as a payment of the invoice. A declined payment is sent as an invoice.payment_failed event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Collect the payment of an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Collection",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CollectInvoicePaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoicePaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/payments/debit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedule a SEPA or ACH debit of a finalized invoice under an active mandate of the customer, the one on its default payment method by default. The customer is emailed the amount and the charge date, which is the pre-notification days of the mandate away. The amount is processing on the invoice until the debit clears, a failed debit is sent as an invoice.payment_failed event with its dunning action",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Schedule a bank debit of an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Debit",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ScheduleDirectDebitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoicePaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/void": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Void a finalized invoice that was not paid, collected or credited. Its tax transaction is voided at the tax provider",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Void an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the invoice the void is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/write-off": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark the amount remaining of a finalized invoice as uncollectible. Its scheduled bank debits are cancelled, it is no longer collected and it moves from the receivables aging to the write-off report. Payments recovered later can still be recorded against it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Write off an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the invoice the write-off is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Write-off",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WriteOffInvoiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger-syncs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the reconciliation status of the invoices and credit notes pushed to NetSuite and QuickBooks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ledger"
+                ],
+                "summary": "List ledger syncs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "connection_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "synced",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "LedgerSyncStatusPending",
+                            "LedgerSyncStatusSynced",
+                            "LedgerSyncStatusFailed"
+                        ],
+                        "name": "sync_status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListLedgerSyncsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/legal-entities": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the legal entities of the tenant ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Legal Entities"
+                ],
+                "summary": "List legal entities",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListLegalEntitiesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a company of the tenant invoices are issued from, with its address, tax IDs, invoice numbering and bank details. Customers and subscriptions are assigned to it with their legal_entity_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Legal Entities"
+                ],
+                "summary": "Create a legal entity",
+                "parameters": [
+                    {
+                        "description": "Create legal entity request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateLegalEntityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LegalEntityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/legal-entities/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Legal Entities"
+                ],
+                "summary": "Get a legal entity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Legal entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LegalEntityResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace a legal entity. The invoices it already issued keep their numbers, a new prefix starts a new sequence",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Legal Entities"
+                ],
+                "summary": "Update a legal entity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Legal entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update legal entity request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateLegalEntityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LegalEntityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a legal entity no customer or active subscription is assigned to. The invoices it issued keep it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Legal Entities"
+                ],
+                "summary": "Delete a legal entity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Legal entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/meters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all meters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meters"
+                ],
+                "summary": "List meters",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.MeterResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new meter with the specified configuration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meters"
+                ],
+                "summary": "Create meter",
+                "parameters": [
+                    {
+                        "description": "Meter configuration",
+                        "name": "meter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateMeterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MeterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/meters/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a specific meter by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meters"
+                ],
+                "summary": "Get meter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MeterResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an existing meter",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meters"
+                ],
+                "summary": "Delete meter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message:Meter deleted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/meters/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Disable an existing meter",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meters"
+                ],
+                "summary": "Disable meter [TODO: Deprecate]",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message:Meter disabled successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/meters/{id}/reaggregate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Recompute the usage of a past period with the definition of a version of the meter, the current one by default. A version bounded to the period is created and usage in it is read with that definition right away; its rolled up usage is recomputed in the background and the returned version tracks the progress in reaggregation_status. Invoices already issued for the period are not changed. The re-aggregation is recorded in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meters"
+                ],
+                "summary": "Re-aggregate the usage of a meter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Re-aggregation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReaggregateMeterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MeterVersionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/meters/{id}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the definitions of a meter over time, by version number",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meters"
+                ],
+                "summary": "List meter versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.MeterVersionResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the aggregation and filters of a meter from effective_from on, now by default. Usage before the effective date keeps being aggregated with the definition it happened under; recompute a past period under a new definition with a re-aggregation. A meter can not switch to or from an AVG aggregation and COUNT_UNIQUE and PERCENTILE meters are not versioned. The change is recorded in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meters"
+                ],
+                "summary": "Create a meter version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Meter definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateMeterVersionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MeterVersionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/meters/{id}/versions/{version}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a version of a meter. The version of a re-aggregation reports the recomputation of the rolled up usage of its period",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meters"
+                ],
+                "summary": "Get a meter version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version number",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MeterVersionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/refunds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the refunds of an invoice payment, oldest first, along with the amount left to refund",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Refunds"
+                ],
+                "summary": "List the refunds of a payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListRefundsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Refund part or all of an invoice payment. Payments collected at a gateway are refunded there, a failed gateway refund is kept and sent as a refund.failed event. With create_credit_note a credit note is issued for the refunded amount against the invoice, otherwise the amount is owed again on the invoice",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Refunds"
+                ],
+                "summary": "Refund a payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Refund",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateRefundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefundResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plans": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get plans with the specified filter",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "plans"
+                ],
+                "summary": "Get plans",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListPlansResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new plan with the specified configuration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "plans"
+                ],
+                "summary": "Create a new plan",
+                "parameters": [
+                    {
+                        "description": "Plan configuration",
+                        "name": "plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PlanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plans/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a plan by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "plans"
+                ],
+                "summary": "Get a plan by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PlanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a plan by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "plans"
+                ],
+                "summary": "Update a plan by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the plan the update is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Plan configuration",
+                        "name": "plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdatePlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PlanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a plan by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "plans"
+                ],
+                "summary": "Delete a plan by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plans/{id}/prices/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Export all the prices of a plan as JSON or as a CSV file with one line per tier, in the format accepted by the import",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "plans"
+                ],
+                "summary": "Export the prices of a plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportPlanPricesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plans/{id}/prices/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create, update and archive the prices of a plan at once from a JSON body or a text/csv body in the export format. Rows match prices by id or lookup key, and prices whose amount or billing terms change are archived and replaced. Nothing is applied on a dry run or when any row is invalid",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "plans"
+                ],
+                "summary": "Import the prices of a plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Import request, for JSON bodies",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ImportPlanPricesRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only report the changes, for CSV bodies",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Archive the prices missing from the import, for CSV bodies",
+                        "name": "archive_missing",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ImportPlanPricesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plans/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore a deleted plan. Deleted plans can be restored until they are purged",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "plans"
+                ],
+                "summary": "Restore a plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Plan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PlanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portal/customer": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the customer the portal session is scoped to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portal"
+                ],
+                "summary": "Get the portal customer",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portal/invoices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the finalized invoices of the portal customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portal"
+                ],
+                "summary": "List the portal customer invoices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "DRAFT",
+                            "FINALIZED",
+                            "VOIDED",
+                            "HELD"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "InvoiceStatusDraft",
+                            "InvoiceStatusFinalized",
+                            "InvoiceStatusVoided",
+                            "InvoiceStatusHeld"
+                        ],
+                        "name": "invoice_status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "SUBSCRIPTION",
+                            "ONE_OFF",
+                            "CREDIT"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "InvoiceTypeSubscription",
+                            "InvoiceTypeOneOff",
+                            "InvoiceTypeCredit"
+                        ],
+                        "name": "invoice_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "original_invoice_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "subscription_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListInvoicesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portal/invoices/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a finalized invoice of the portal customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portal"
+                ],
+                "summary": "Get a portal customer invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portal/sessions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a short lived session token scoped to a single customer, for use with the /portal endpoints from the customer's browser",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portal"
+                ],
+                "summary": "Create a portal session",
+                "parameters": [
+                    {
+                        "description": "Create portal session request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePortalSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PortalSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portal/usage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the current period usage of the active subscriptions of the portal customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portal"
+                ],
+                "summary": "Get the portal customer usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PortalUsageResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portal/usage/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream the events ingested for the portal customer as server-sent events named usage, as they are consumed. A keep alive comment is sent periodically",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Portal"
+                ],
+                "summary": "Stream the portal customer usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/usagestream.Update"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/price-books": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the price books of a plan new subscriptions can be put on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Books"
+                ],
+                "summary": "List price books",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "plan_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListPriceBooksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a price book overriding the prices of a plan. New subscriptions of the plan are put on it when they name it, or when their customer is in its segment, and keep its prices for their lifetime",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Books"
+                ],
+                "summary": "Create a price book",
+                "parameters": [
+                    {
+                        "description": "Create price book request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePriceBookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceBookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/price-books/report": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compare the trial conversion and revenue of the subscriptions of a plan created in the window, by the price book they were created on. Defaults to the last 30 days",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Books"
+                ],
+                "summary": "Compare the price books of a plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "plan_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceBookReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/price-books/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a price book by id, including deleted price books",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Books"
+                ],
+                "summary": "Get a price book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Price book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceBookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop putting new subscriptions on a price book. The subscriptions created on it keep its prices",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price Books"
+                ],
+                "summary": "Delete a price book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Price book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get prices with the specified filter",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Get prices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListPricesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new price with the specified configuration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Create a new price",
+                "parameters": [
+                    {
+                        "description": "Price configuration",
+                        "name": "price",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prices/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a price by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Get a price by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Price ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a price with the specified configuration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Update a price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Price ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Price configuration",
+                        "name": "price",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdatePriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a price",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Delete a price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Price ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prices/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore a deleted price. Deleted prices can be restored until they are purged",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Restore a price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Price ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rate-cards": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List all the versions of the rate cards of a customer and its subscriptions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rate Cards"
+                ],
+                "summary": "List rate cards",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "customer_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListRateCardsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create the next version of the negotiated rate card of a customer, or of one of its subscriptions. Its price overrides apply to the billing periods starting from effective_from, subscription rate cards take precedence over customer rate cards",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rate Cards"
+                ],
+                "summary": "Create a rate card version",
+                "parameters": [
+                    {
+                        "description": "Create rate card request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateRateCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RateCardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rate-cards/rates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compare the prices the ongoing subscriptions of a customer are billed at, after their price book and rate card, with the list prices of their plans at a point in time, now by default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rate Cards"
+                ],
+                "summary": "Compare the rates of a customer with list prices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "customer_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerRatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rate-cards/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a rate card version by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rate Cards"
+                ],
+                "summary": "Get a rate card",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rate card ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RateCardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a rate card version. The previous version applies again from the next invoice, issued invoices are unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rate Cards"
+                ],
+                "summary": "Delete a rate card",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rate card ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/margin": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Report the revenue of the charges of the finalized invoices whose service period starts in the given range against their cost of goods sold, by customer, plan or meter. The cost of a charge is its quantity times the unit cost of its price, charges of prices without a unit cost count as uncosted revenue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get gross margin report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "customer",
+                            "plan",
+                            "meter"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "MarginGroupByCustomer",
+                            "MarginGroupByPlan",
+                            "MarginGroupByMeter"
+                        ],
+                        "name": "group_by",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MarginReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/receivables": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Report the amounts remaining on the finalized invoices by currency and by customer, in aging buckets of current, 30, 60 and 90+ days since the invoices were finalized. Today's report is computed on request, the report of a past date (YYYY-MM-DD) is served from the daily snapshot",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get accounts receivable report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReceivablesReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/runs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a report run by ID to poll its status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get a report run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportRunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/runs/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a signed, time limited URL to download the CSV of a completed report run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get report run download URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExportDownloadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the report templates of the tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "List report templates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListReportTemplatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Define a report on customers, subscriptions, invoices or invoice line items: its columns, filters and group-bys, the period of each run, its daily, weekly or monthly schedule and where its CSV is delivered (email, s3 or webhook)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Create a report template",
+                "parameters": [
+                    {
+                        "description": "Report template",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a report template by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get a report template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the definition of a report template. Its next run is rescheduled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Update a report template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Report template",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a report template, which stops its schedule. Its runs are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Delete a report template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/templates/{id}/run": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a pending run of a report template, run by the report job. Poll the run for its status, its CSV is delivered to the destinations of the template once completed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Run a report template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReportRunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/templates/{id}/runs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the runs of a report template, most recent first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "List report runs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "-",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListReportRunsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/write-offs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Report the invoices written off as uncollectible in a period and the amounts written off by currency. Written off invoices are left out of the receivables report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get write-off report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WriteOffReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/role-assignments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the roles assigned to the users of the tenant, tenant wide and per environment",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "List role assignments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "EnvironmentID only returns the assignments scoped to the environment",
+                        "name": "environment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListRoleAssignmentsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set the role of a user for an environment, or for all the environments without a role of their own when no environment is given. It replaces the role the user had in the same scope",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Assign a role",
+                "parameters": [
+                    {
+                        "description": "Role assignment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AssignRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RoleAssignmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/role-assignments/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a role from a user. The last tenant wide admin can not be removed",
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Delete a role assignment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role assignment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the roles of the dashboard users with the permissions they grant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListRolesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the API keys of the tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "List API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListAPIKeysResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a scoped API key. The raw key is only returned in this response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "Create API key request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke an API key",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Delete an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sso/config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the identity provider the users of the tenant sign in with",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SSO"
+                ],
+                "summary": "Get the SSO config",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SSOConfigResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Configure the OIDC or SAML identity provider the users of the tenant sign in with, replacing the current one",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SSO"
+                ],
+                "summary": "Set the SSO config",
+                "parameters": [
+                    {
+                        "description": "SSO config",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetSSOConfigRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SSOConfigResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the identity provider of the tenant. Its users sign in with their password again",
+                "tags": [
+                    "SSO"
+                ],
+                "summary": "Delete the SSO config",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get subscriptions with optional filtering",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by customer ID",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by subscription status",
+                        "name": "subscription_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by plan ID",
+                        "name": "plan_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination mode, offset (default) or cursor",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated records to embed in each subscription: plan, prices, customer. Defaults to plan",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListSubscriptionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new subscription",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription Request",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Denied by a pre-operation hook",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/usage": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get usage by subscription",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get usage by subscription",
+                "parameters": [
+                    {
+                        "description": "Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetUsageBySubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetUsageBySubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a subscription by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated records to embed: plan, prices, customer. Defaults to plan,prices",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel a subscription. The reason must be one of the tenant's cancellation reasons",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Cancel subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the subscription the cancellation is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Cancel subscription request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CancelSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Denied by a pre-operation hook",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/invoices": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Run billing for a subscription period and create a draft invoice. A negative total is issued as a credit invoice linked to the last finalized invoice",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Create a subscription invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create subscription invoice request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSubscriptionInvoiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/invoices/upcoming": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Preview the invoice billed at the end of the current period of a subscription: the fixed charges, the usage to date or projected over the whole period at its current run-rate, the taxes, the simulated wallet draw-down and the amount left to pay out of pocket. Nothing is persisted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Preview the upcoming invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "ProjectUsage bills the usage projected over the whole period at the\nrun-rate measured so far instead of the usage to date",
+                        "name": "project_usage",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpcomingInvoiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/line-items/{li_id}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the quantity or the unit amount of a recurring fixed line item. With create_prorations, the default, a change in the middle of a paid period is prorated on the next invoice. Emits subscription.updated",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Update a subscription line item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Line item ID",
+                        "name": "li_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the subscription the change is made against",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Line item change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateSubscriptionLineItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionLineItemResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.VersionConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/usage-lock": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get whether the current period of the subscription is open, in its grace period accepting late events with a timestamp inside the period, or locked for invoicing. Periods lock billing.usage_lock_delay_hours after their end",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get the usage lock of a subscription period",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UsageLockResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List tasks with pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "List tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListTasksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start an asynchronous task such as a bulk customer import. The CSV or JSON file is either uploaded as the multipart \"file\" field or downloaded from file_url, e.g. a presigned URL",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Create a task",
+                "parameters": [
+                    {
+                        "description": "Create task request, for JSON bodies",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateTaskRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task type, for multipart uploads",
+                        "name": "task_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File format, guessed from the file name when omitted",
+                        "name": "file_format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to process",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a task by ID to poll its status and progress",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Get a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/error-report": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a signed, time limited URL to download the CSV report of the rows which failed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Get task error report URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TaskErrorReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/usage/anomalies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the usage spikes and drops flagged by the anomaly detection job, most recent window first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "List usage anomalies",
+                "parameters": [
+                    {
+                        "enum": [
+                            "spike",
+                            "drop"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "AnomalyTypeSpike",
+                            "AnomalyTypeDrop"
+                        ],
+                        "name": "anomaly_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "external_customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "meter_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListAnomaliesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the current user's information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user info",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new wallet for a customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Create a new wallet",
+                "parameters": [
+                    {
+                        "description": "Create wallet request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateWalletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalletResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a wallet by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Get wallet by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalletResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/auto-topup": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the auto top-up rule of a wallet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Get wallet auto top-up",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AutoTopUpResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create or replace the rule topping the wallet up by a fixed amount when its balance drops below a threshold. The daily and monthly caps bound the amount topped up, zero meaning no cap",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Configure wallet auto top-up",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Auto top-up rule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateAutoTopUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AutoTopUpResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/balance/real-time": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get real-time balance of a wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Get wallet balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalletBalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/debit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Consume credits of a wallet, drawing from the oldest credits that have not expired first and from the credits that never expire last",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Debit wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Debit request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.DebitWalletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalletResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/terminate": {
+            "post": {
+                "description": "Terminates a wallet by closing it and debiting remaining balance",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Terminate a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalletResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/top-up": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add credits to a wallet, expiring at expires_at when set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Top up wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Top up request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TopUpWalletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalletResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{id}/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get transactions for a wallet with pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Get wallet transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalletTransactionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the webhook deliveries of the tenant. Filter by delivery_status=failed for deliveries that exhausted their retries",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "WebhookDeliveryStatusPending",
+                            "WebhookDeliveryStatusSucceeded",
+                            "WebhookDeliveryStatusFailed"
+                        ],
+                        "name": "delivery_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "endpoint_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "invoice.finalized",
+                            "invoice.held",
+                            "usage.anomaly_detected",
+                            "subscription.trial_will_end",
+                            "subscription.trial_ended",
+                            "subscription.updated",
+                            "wallet.auto_topup.succeeded",
+                            "wallet.auto_topup.failed",
+                            "wallet.credits.expired",
+                            "refund.created",
+                            "refund.failed",
+                            "report.completed",
+                            "budget.exceeded",
+                            "invoice.late_usage",
+                            "invoice.payment_failed",
+                            "invoice.dispute.opened",
+                            "invoice.dispute.under_review",
+                            "invoice.dispute.resolved"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "WebhookEventInvoiceFinalized",
+                            "WebhookEventInvoiceHeld",
+                            "WebhookEventUsageAnomalyDetected",
+                            "WebhookEventTrialWillEnd",
+                            "WebhookEventTrialEnded",
+                            "WebhookEventSubscriptionUpdated",
+                            "WebhookEventWalletAutoTopUpSucceeded",
+                            "WebhookEventWalletAutoTopUpFailed",
+                            "WebhookEventWalletCreditsExpired",
+                            "WebhookEventRefundCreated",
+                            "WebhookEventRefundFailed",
+                            "WebhookEventReportCompleted",
+                            "WebhookEventBudgetExceeded",
+                            "WebhookEventInvoiceLateUsage",
+                            "WebhookEventInvoicePaymentFailed",
+                            "WebhookEventDisputeOpened",
+                            "WebhookEventDisputeUnderReview",
+                            "WebhookEventDisputeResolved"
+                        ],
+                        "name": "event_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListWebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a webhook delivery by ID, including its payload and last attempt",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a webhook delivery again with a fresh retry budget",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Replay a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/endpoints": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the webhook endpoints of the current environment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhook endpoints",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "PaginationModeOffset",
+                            "PaginationModeCursor"
+                        ],
+                        "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published",
+                            "deleted",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "StatusPublished",
+                            "StatusDeleted",
+                            "StatusArchived"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListWebhookEndpointsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a URL to receive webhook events in the current environment. The signing secret is only returned here and on rotation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create a webhook endpoint",
+                "parameters": [
+                    {
+                        "description": "Create webhook endpoint request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookEndpointSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/endpoints/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a webhook endpoint by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookEndpointResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the URL, description and event types of a webhook endpoint",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Update a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update webhook endpoint request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookEndpointResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop sending webhook events to an endpoint. Pending deliveries to it fail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/endpoints/{id}/rotate-secret": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a new signing secret. Deliveries carry a signature for both the new and the previous secret until the previous one expires",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Rotate the signing secret of a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rotate secret request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RotateWebhookSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookEndpointSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "connection.CRMInvoiceFields": {
+            "type": "object",
+            "properties": {
+                "amount_due": {
+                    "description": "AmountDue is the amount due on the finalized invoices ex Flexprice_Amount_Due__c",
+                    "type": "string"
+                },
+                "last_invoice_date": {
+                    "description": "LastInvoiceDate is the date the last invoice was finalized ex Flexprice_Last_Invoice_Date__c",
+                    "type": "string"
+                },
+                "total_invoiced": {
+                    "description": "TotalInvoiced is the sum of the finalized invoices ex Flexprice_Total_Invoiced__c",
+                    "type": "string"
+                }
+            }
+        },
+        "connection.CRMSettings": {
+            "type": "object",
+            "required": [
+                "instance_url"
+            ],
+            "properties": {
+                "instance_url": {
+                    "description": "InstanceURL is the Salesforce instance of the org ex https://acme.my.salesforce.com",
+                    "type": "string"
+                },
+                "invoice_fields": {
+                    "$ref": "#/definitions/connection.CRMInvoiceFields"
+                },
+                "subscription_object": {
+                    "description": "SubscriptionObject is Opportunity or Contract, Opportunity by default",
+                    "enum": [
+                        "Opportunity",
+                        "Contract"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.CRMSubscriptionObject"
+                        }
+                    ]
+                },
+                "sync_customers": {
+                    "description": "SyncCustomers, SyncSubscriptions and SyncInvoiceTotals turn each direction\nof the sync on. Customers are synced to Accounts, subscriptions to\nSubscriptionObject and invoice totals to InvoiceFields of the Account",
+                    "type": "boolean"
+                },
+                "sync_invoice_totals": {
+                    "type": "boolean"
+                },
+                "sync_subscriptions": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "connection.FallbackTaxRate": {
+            "type": "object",
+            "required": [
+                "country"
+            ],
+            "properties": {
+                "country": {
+                    "description": "Country is the ISO 3166-1 alpha-2 code of the country ex US",
+                    "type": "string"
+                },
+                "display_name": {
+                    "description": "DisplayName is the name of the tax line items, Tax by default",
+                    "type": "string"
+                },
+                "rate_percent": {
+                    "type": "string",
+                    "example": "8.25"
+                },
+                "state": {
+                    "description": "State restricts the rate to a state of the country ex CA, the rates without\none apply to the rest of the country",
+                    "type": "string"
+                }
+            }
+        },
+        "connection.GatewaySettings": {
+            "type": "object",
+            "properties": {
+                "key_id": {
+                    "description": "KeyID is the Razorpay key ID, its key secret is the credentials",
+                    "type": "string"
+                },
+                "live_url_prefix": {
+                    "description": "LiveURLPrefix is the prefix of the live endpoints of the Adyen company\naccount ex 1797a841fbb37ca7-AdyenDemo. The test endpoints are used without one",
+                    "type": "string"
+                },
+                "merchant_account": {
+                    "description": "MerchantAccount is the Adyen merchant account the payments are made to",
+                    "type": "string"
+                }
+            }
+        },
+        "connection.LedgerFieldMapping": {
+            "type": "object",
+            "required": [
+                "default_item_id"
+            ],
+            "properties": {
+                "customer_ids": {
+                    "description": "CustomerIDs maps customer IDs to ledger customers. Customers without one\nare expected to exist in the ledger with their external ID as ID",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "default_item_id": {
+                    "description": "DefaultItemID is the ledger item of the line items whose price has no item",
+                    "type": "string"
+                },
+                "item_ids": {
+                    "description": "ItemIDs maps price IDs to ledger items",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "connection.LedgerSettings": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "AccountID is the NetSuite account ID ex 1234567_SB1 or the QuickBooks company (realm) ID",
+                    "type": "string"
+                },
+                "field_mapping": {
+                    "$ref": "#/definitions/connection.LedgerFieldMapping"
+                },
+                "sandbox": {
+                    "description": "Sandbox sends the documents to the QuickBooks sandbox. NetSuite sandboxes\nhave their own account ID",
+                    "type": "boolean"
+                }
+            }
+        },
+        "connection.TaxSettings": {
+            "type": "object",
+            "required": [
+                "ship_from"
+            ],
+            "properties": {
+                "account_id": {
+                    "description": "AccountID is the Avalara account ID, its license key is the credentials",
+                    "type": "string"
+                },
+                "company_code": {
+                    "description": "CompanyCode is the Avalara company the transactions are recorded in",
+                    "type": "string"
+                },
+                "default_tax_code": {
+                    "description": "DefaultTaxCode is the product tax code of the line items whose price has\nnone ex SW054000 for Avalara or 81162000A9000 for TaxJar. Without one the\nline items are taxed as tangible goods",
+                    "type": "string"
+                },
+                "fallback_rates": {
+                    "description": "FallbackRates tax the invoices when the provider is unavailable, so that\ninvoicing does not stop with it",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/connection.FallbackTaxRate"
+                    }
+                },
+                "sandbox": {
+                    "description": "Sandbox sends the requests to the sandbox of the provider",
+                    "type": "boolean"
+                },
+                "ship_from": {
+                    "description": "ShipFrom is the address the tenant sells from. Invoices are taxed at the\nbilling address of the customer",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/customer.Address"
+                        }
+                    ]
+                },
+                "tax_codes": {
+                    "description": "TaxCodes maps price IDs to product tax codes",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "customer.Address": {
+            "type": "object",
+            "required": [
+                "city",
+                "country",
+                "line1"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "description": "Country is the ISO 3166-1 alpha-2 code of the country ex DE",
+                    "type": "string"
+                },
+                "line1": {
+                    "type": "string"
+                },
+                "line2": {
+                    "type": "string"
+                },
+                "postal_code": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "customer.TaxID": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "description": "Country is the ISO 3166-1 alpha-2 code of the country that issued the number",
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/types.TaxIDType"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix is the first characters of the key, used to identify it in the UI",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Scopes limit what the key can do, see types.APIKeyScope",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AddInvoiceLineItemRequest": {
+            "type": "object",
+            "required": [
+                "display_name"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "150.00"
+                },
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Onboarding fee"
+                },
+                "quantity": {
+                    "description": "Quantity defaults to 1",
+                    "type": "string",
+                    "example": "1"
+                }
+            }
+        },
+        "dto.AdvanceClockRequest": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "hours": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AllocateCreditNoteRequest": {
+            "type": "object",
+            "required": [
+                "target_id",
+                "target_type"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount defaults to the remaining credit, capped by the amount due when the\ntarget is an invoice",
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.CreditAllocationTarget"
+                        }
+                    ],
+                    "example": "invoice"
+                }
+            }
+        },
+        "dto.AnomalyResponse": {
+            "type": "object",
+            "properties": {
+                "anomaly_type": {
+                    "$ref": "#/definitions/types.AnomalyType"
+                },
+                "baseline": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "external_customer_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "meter_id": {
+                    "type": "string"
+                },
+                "sigma": {
+                    "description": "Sigma is the signed distance of the value from the baseline in standard deviations",
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "std_dev": {
+                    "type": "number"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value is the usage in the window, Baseline and StdDev the mean and standard\ndeviation of the usage in the windows before it",
+                    "type": "number"
+                },
+                "window_end": {
+                    "type": "string"
+                },
+                "window_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AssignRoleRequest": {
+            "type": "object",
+            "required": [
+                "role",
+                "user_id"
+            ],
+            "properties": {
+                "environment_id": {
+                    "description": "EnvironmentID scopes the role to an environment. The role applies to\nall the environments without an assignment of their own when empty",
+                    "type": "string"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Role"
+                        }
+                    ],
+                    "example": "billing_manager"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AttachPaymentMethodRequest": {
+            "type": "object",
+            "required": [
+                "connection_id",
+                "gateway_payment_method_id"
+            ],
+            "properties": {
+                "connection_id": {
+                    "description": "ConnectionID is the payment gateway connection the payment method is vaulted at",
+                    "type": "string"
+                },
+                "gateway_payment_method_id": {
+                    "description": "GatewayPaymentMethodID is the payment method collected at the gateway ex with Stripe.js",
+                    "type": "string",
+                    "example": "pm_1NqK2aLkdIwHu7ix"
+                },
+                "set_default": {
+                    "description": "SetDefault makes it the default payment method of the customer. The first\npayment method of a customer is always the default",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.AuditLogResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/types.AuditAction"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "actor_type": {
+                    "description": "ActorType and ActorID are the user or the API key that made the change.\nSystem changes have no actor ID",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.AuditActorType"
+                        }
+                    ]
+                },
+                "changes": {
+                    "description": "Changes maps the changed fields to their value before and after the change,\nsee Change. Fields hidden from the API are never recorded",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "entity_type": {
+                    "$ref": "#/definitions/types.AuditEntityType"
+                },
+                "environment_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AutoTopUpResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "daily_cap": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "monthly_cap": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "wallet_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BudgetResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/types.BudgetAction"
+                },
+                "amount": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "evaluated_at": {
+                    "type": "string"
+                },
+                "exceeded_at": {
+                    "description": "ExceededAt is when the spend went over the amount, nil while it is under",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "period": {
+                    "$ref": "#/definitions/types.BudgetPeriod"
+                },
+                "spend": {
+                    "description": "Spend is the usage charges of the window as of EvaluatedAt. Subscriptions\nin another currency than the budget are left out",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "subscription_id": {
+                    "description": "SubscriptionID restricts the budget to one subscription of the customer.\nEmpty for budgets covering all the subscriptions of the customer",
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CancelSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "cancel_at_period_end": {
+                    "type": "boolean"
+                },
+                "comment": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "too_expensive"
+                }
+            }
+        },
+        "dto.CancellationReasonResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the stable identifier recorded on cancelled subscriptions",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ChurnPlanBreakdown": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "plan_id": {
+                    "type": "string"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChurnReasonCount"
+                    }
+                }
+            }
+        },
+        "dto.ChurnReasonCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "percentage": {
+                    "description": "Percentage is the share of the cancellations of the group with this reason",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ChurnReasonsResponse": {
+            "type": "object",
+            "properties": {
+                "by_plan": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChurnPlanBreakdown"
+                    }
+                },
+                "by_reason": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChurnReasonCount"
+                    }
+                },
+                "by_tenure": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChurnTenureBreakdown"
+                    }
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "total_cancellations": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ChurnTenureBreakdown": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChurnReasonCount"
+                    }
+                },
+                "tenure": {
+                    "description": "Tenure is the bucket of time between the start and the cancellation of the subscriptions",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CollectInvoicePaymentRequest": {
+            "type": "object",
+            "required": [
+                "connection_id"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount defaults to the amount remaining of the invoice",
+                    "type": "string",
+                    "example": "50.00"
+                },
+                "connection_id": {
+                    "type": "string"
+                },
+                "payment_method_id": {
+                    "description": "PaymentMethodID is one of the payment methods of the customer on the\nconnection, the default payment method of the customer when empty",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ConnectionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "crm_settings": {
+                    "description": "CRMSettings configure the Salesforce connections",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/connection.CRMSettings"
+                        }
+                    ]
+                },
+                "gateway_settings": {
+                    "description": "GatewaySettings configure the Adyen and Razorpay connections",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/connection.GatewaySettings"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ledger_settings": {
+                    "description": "LedgerSettings configure the NetSuite and QuickBooks connections",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/connection.LedgerSettings"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "provider": {
+                    "$ref": "#/definitions/types.IntegrationProvider"
+                },
+                "rate_limit_per_second": {
+                    "description": "RateLimitPerSecond caps outbound calls to the provider for this connection.\nZero uses the configured default",
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tax_settings": {
+                    "description": "TaxSettings configure the Avalara and TaxJar connections",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/connection.TaxSettings"
+                        }
+                    ]
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ConsumerLagResponse": {
+            "type": "object",
+            "properties": {
+                "lag": {
+                    "description": "Lag is the number of events of all the lanes not consumed yet",
+                    "type": "integer"
+                },
+                "lanes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ConsumerLaneLag"
+                    }
+                }
+            }
+        },
+        "dto.ConsumerLaneLag": {
+            "type": "object",
+            "properties": {
+                "consumer_group": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "lag": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/kafka.PartitionLag"
+                    }
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CorrectEventRequest": {
+            "type": "object",
+            "required": [
+                "properties"
+            ],
+            "properties": {
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "{\"request_size\"": "80}"
+                    }
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "wrong request size reported"
+                }
+            }
+        },
+        "dto.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Mobile app ingestion"
+                },
+                "scopes": {
+                    "description": "Scopes of the key, one or more of read_only, events, billing_admin",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/types.APIKeyScope"
+                    },
+                    "example": [
+                        "events"
+                    ]
+                }
+            }
+        },
+        "dto.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix is the first characters of the key, used to identify it in the UI",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Scopes limit what the key can do, see types.APIKeyScope",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateBudgetRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "currency",
+                "customer_id",
+                "name",
+                "period"
+            ],
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BudgetAction"
+                        }
+                    ],
+                    "example": "alert"
+                },
+                "amount": {
+                    "type": "string",
+                    "example": "500"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "usd"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Monthly API spend"
+                },
+                "period": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BudgetPeriod"
+                        }
+                    ],
+                    "example": "monthly"
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateCancellationReasonRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "name"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "too_expensive"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Too expensive"
+                }
+            }
+        },
+        "dto.CreateConnectionRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "provider"
+            ],
+            "properties": {
+                "credentials": {
+                    "description": "Credentials is the OAuth 2.0 access token of the provider, the secret API\nkey of the payment gateways or the Avalara license key and TaxJar API token",
+                    "type": "string"
+                },
+                "crm_settings": {
+                    "description": "CRMSettings and Credentials are required for the salesforce provider",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/connection.CRMSettings"
+                        }
+                    ]
+                },
+                "gateway_settings": {
+                    "description": "GatewaySettings and Credentials are required for the adyen and razorpay providers",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/connection.GatewaySettings"
+                        }
+                    ]
+                },
+                "ledger_settings": {
+                    "description": "LedgerSettings and Credentials are required for the netsuite and quickbooks providers",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/connection.LedgerSettings"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Stripe production"
+                },
+                "provider": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.IntegrationProvider"
+                        }
+                    ],
+                    "example": "stripe"
+                },
+                "rate_limit_per_second": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 25
+                },
+                "tax_settings": {
+                    "description": "TaxSettings and Credentials are required for the avalara and taxjar providers",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/connection.TaxSettings"
+                        }
+                    ]
+                },
+                "webhook_secret": {
+                    "description": "WebhookSecret verifies the webhooks of the payment gateways: the signing\nsecret of the Stripe endpoint, the HMAC key of Adyen or the Razorpay webhook secret",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateCustomerInvoicesRequest": {
+            "type": "object",
+            "required": [
+                "billing_date"
+            ],
+            "properties": {
+                "billing_date": {
+                    "type": "string",
+                    "example": "2024-12-01T00:00:00Z"
+                }
+            }
+        },
+        "dto.CreateCustomerRequest": {
+            "type": "object",
+            "required": [
+                "external_id"
+            ],
+            "properties": {
+                "billing_address": {
+                    "$ref": "#/definitions/customer.Address"
+                },
+                "consolidate_invoices": {
+                    "description": "ConsolidateInvoices bills all subscriptions sharing a billing date and currency on one invoice",
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "legal_entity_id": {
+                    "description": "LegalEntityID is the legal entity the customer is invoiced from, the\ndefault entity of the tenant when empty",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "payment_method_id": {
+                    "type": "string"
+                },
+                "shipping_address": {
+                    "$ref": "#/definitions/customer.Address"
+                },
+                "tax_ids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TaxIDRequest"
+                    }
+                },
+                "timezone": {
+                    "description": "Timezone is the IANA timezone the billing periods of new subscriptions are computed in. Defaults to UTC",
+                    "type": "string",
+                    "example": "America/New_York"
+                }
+            }
+        },
+        "dto.CreateEnvironmentRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "type"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "QA"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.EnvironmentType"
+                        }
+                    ],
+                    "example": "SANDBOX"
+                }
+            }
+        },
+        "dto.CreateEventBackfillRequest": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "start_time"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "example": "2024-02-01T00:00:00Z"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IngestEventRequest"
+                    }
+                },
+                "s3_path": {
+                    "type": "string",
+                    "example": "s3://usage-archive/2024-01/events.json"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "dto.CreateEventSchemaRequest": {
+            "type": "object",
+            "required": [
+                "event_name",
+                "rules"
+            ],
+            "properties": {
+                "event_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "api_request"
+                },
+                "on_invalid": {
+                    "description": "OnInvalid is what happens to the events that do not match the schema,\nrejected by default",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvalidEventAction"
+                        }
+                    ],
+                    "example": "quarantine"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/eventschema.PropertyRule"
+                    }
+                }
+            }
+        },
+        "dto.CreateExportRequest": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "export_type",
+                "start_time"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "example": "2024-12-01T00:00:00Z"
+                },
+                "event_name": {
+                    "type": "string",
+                    "example": "api_request"
+                },
+                "export_format": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ExportFormat"
+                        }
+                    ],
+                    "example": "PARQUET"
+                },
+                "export_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ExportType"
+                        }
+                    ],
+                    "example": "EVENTS"
+                },
+                "external_customer_id": {
+                    "type": "string",
+                    "example": "customer456"
+                },
+                "meter_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2024-11-01T00:00:00Z"
+                },
+                "window_size": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.WindowSize"
+                        }
+                    ],
+                    "example": "DAY"
+                }
+            }
+        },
+        "dto.CreateExportScheduleRequest": {
+            "type": "object",
+            "required": [
+                "export_type",
+                "schedule"
+            ],
+            "properties": {
+                "event_name": {
+                    "type": "string",
+                    "example": "api_request"
+                },
+                "export_format": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ExportFormat"
+                        }
+                    ],
+                    "example": "PARQUET"
+                },
+                "export_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ExportType"
+                        }
+                    ],
+                    "example": "USAGE"
+                },
+                "external_customer_id": {
+                    "type": "string",
+                    "example": "customer456"
+                },
+                "meter_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Daily usage"
+                },
+                "schedule": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ReportSchedule"
+                        }
+                    ],
+                    "example": "daily"
+                },
+                "window_size": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.WindowSize"
+                        }
+                    ],
+                    "example": "DAY"
+                }
+            }
+        },
+        "dto.CreateLegalEntityRequest": {
+            "type": "object",
+            "required": [
+                "address",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/customer.Address"
+                },
+                "bank_details": {
+                    "$ref": "#/definitions/legalentity.BankDetails"
+                },
+                "invoice_padding": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1,
+                    "example": 5
+                },
+                "invoice_prefix": {
+                    "description": "InvoicePrefix, InvoiceSeparator, InvoicePadding and InvoiceResetYearly\nformat the invoice numbers of the entity, as the invoice numbering of the\ntenant. Padding defaults to 5",
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "DE"
+                },
+                "invoice_reset_yearly": {
+                    "type": "boolean"
+                },
+                "invoice_separator": {
+                    "type": "string",
+                    "maxLength": 5,
+                    "example": "-"
+                },
+                "is_default": {
+                    "description": "IsDefault issues the invoices of the customers and subscriptions not\nassigned to an entity from this one. It replaces the current default",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Acme GmbH"
+                },
+                "pdf_template": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "classic"
+                },
+                "tax_ids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TaxIDRequest"
+                    }
+                }
+            }
+        },
+        "dto.CreateMandateRequest": {
+            "type": "object",
+            "required": [
+                "payment_method_id",
+                "reference",
+                "scheme"
+            ],
+            "properties": {
+                "payment_method_id": {
+                    "description": "PaymentMethodID is the bank account payment method of the customer the\nmandate authorizes debits of",
+                    "type": "string"
+                },
+                "pre_notification_days": {
+                    "description": "PreNotificationDays overrides the pre-notification period of the scheme,\n14 days for SEPA and 10 days for ACH, when the mandate agrees on a shorter one",
+                    "type": "integer"
+                },
+                "reference": {
+                    "description": "Reference identifies the mandate to the bank ex the unique mandate reference of SEPA",
+                    "type": "string",
+                    "example": "FLEX-MANDATE-000001"
+                },
+                "scheme": {
+                    "$ref": "#/definitions/types.MandateScheme"
+                },
+                "signed_at": {
+                    "description": "SignedAt defaults to now",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateMeterRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "aggregation": {
+                    "$ref": "#/definitions/meter.Aggregation"
+                },
+                "event_name": {
+                    "type": "string",
+                    "example": "api_request"
+                },
+                "filters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meter.Filter"
+                    }
+                },
+                "formula": {
+                    "description": "Formula creates a derived meter over the usage of other meters, in place\nof the event_name, aggregation and filters",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/meter.Formula"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "API Usage Meter"
+                },
+                "reset_usage": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ResetUsage"
+                        }
+                    ],
+                    "example": "BILLING_PERIOD"
+                }
+            }
+        },
+        "dto.CreateMeterVersionRequest": {
+            "type": "object",
+            "required": [
+                "aggregation"
+            ],
+            "properties": {
+                "aggregation": {
+                    "$ref": "#/definitions/meter.Aggregation"
+                },
+                "effective_from": {
+                    "description": "EffectiveFrom is when the definition takes effect, now by default. It can\nnot be in the past, past usage is only recomputed by a re-aggregation",
+                    "type": "string",
+                    "example": "2024-12-01T00:00:00Z"
+                },
+                "filters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meter.Filter"
+                    }
+                }
+            }
+        },
+        "dto.CreateOneOffInvoiceRequest": {
+            "type": "object",
+            "required": [
+                "currency",
+                "customer_id",
+                "line_items"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "usd"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Implementation services"
+                },
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.InvoiceDiscountRequest"
+                    }
+                },
+                "finalize": {
+                    "description": "Finalize finalizes the invoice on creation instead of leaving it in draft",
+                    "type": "boolean"
+                },
+                "line_items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.OneOffInvoiceLineItemRequest"
+                    }
+                },
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                },
+                "taxes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.InvoiceTaxRequest"
+                    }
+                }
+            }
+        },
+        "dto.CreatePlanPriceRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "billing_cadence",
+                "billing_model",
+                "billing_period",
+                "billing_period_count",
+                "currency",
+                "type"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "billing_cadence": {
+                    "$ref": "#/definitions/types.BillingCadence"
+                },
+                "billing_model": {
+                    "$ref": "#/definitions/types.BillingModel"
+                },
+                "billing_period": {
+                    "$ref": "#/definitions/types.BillingPeriod"
+                },
+                "billing_period_count": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "filter_values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "lookup_key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "meter_id": {
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "string"
+                },
+                "tier_mode": {
+                    "$ref": "#/definitions/types.BillingTier"
+                },
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CreatePriceTier"
+                    }
+                },
+                "transform_quantity": {
+                    "$ref": "#/definitions/price.TransformQuantity"
+                },
+                "type": {
+                    "$ref": "#/definitions/types.PriceType"
+                },
+                "unit_cost": {
+                    "description": "UnitCost is the cost of goods sold of one unit billed by the price, in the\ncurrency of the price, used by the margin reports",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreatePlanRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "invoice_cadence": {
+                    "$ref": "#/definitions/types.InvoiceCadence"
+                },
+                "lookup_key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CreatePlanPriceRequest"
+                    }
+                },
+                "trial_period": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreatePortalSessionRequest": {
+            "type": "object",
+            "required": [
+                "customer_id"
+            ],
+            "properties": {
+                "customer_id": {
+                    "type": "string",
+                    "example": "cust_123"
+                }
+            }
+        },
+        "dto.CreatePriceBookRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "overrides",
+                "plan_id"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Annual discount test"
+                },
+                "overrides": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/ratecard.Override"
+                    }
+                },
+                "plan_id": {
+                    "type": "string"
+                },
+                "segment": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "smb"
+                }
+            }
+        },
+        "dto.CreatePriceRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "billing_cadence",
+                "billing_model",
+                "billing_period",
+                "billing_period_count",
+                "currency",
+                "type"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "billing_cadence": {
+                    "$ref": "#/definitions/types.BillingCadence"
+                },
+                "billing_model": {
+                    "$ref": "#/definitions/types.BillingModel"
+                },
+                "billing_period": {
+                    "$ref": "#/definitions/types.BillingPeriod"
+                },
+                "billing_period_count": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "filter_values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "lookup_key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "meter_id": {
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "string"
+                },
+                "tier_mode": {
+                    "$ref": "#/definitions/types.BillingTier"
+                },
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CreatePriceTier"
+                    }
+                },
+                "transform_quantity": {
+                    "$ref": "#/definitions/price.TransformQuantity"
+                },
+                "type": {
+                    "$ref": "#/definitions/types.PriceType"
+                },
+                "unit_cost": {
+                    "description": "UnitCost is the cost of goods sold of one unit billed by the price, in the\ncurrency of the price, used by the margin reports",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreatePriceTier": {
+            "type": "object",
+            "required": [
+                "unit_amount"
+            ],
+            "properties": {
+                "flat_amount": {
+                    "type": "string"
+                },
+                "unit_amount": {
+                    "type": "string"
+                },
+                "up_to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreateRateCardRequest": {
+            "type": "object",
+            "required": [
+                "customer_id",
+                "name",
+                "overrides"
+            ],
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "description": "EffectiveFrom is when the version starts applying, billing periods starting\nfrom then use it. It defaults to now",
+                    "type": "string"
+                },
+                "effective_to": {
+                    "description": "EffectiveTo is the end of the contract, open ended when empty",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Acme 2025 contract"
+                },
+                "overrides": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/ratecard.Override"
+                    }
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateRefundRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount defaults to the part of the payment not refunded yet",
+                    "type": "string",
+                    "example": "10.00"
+                },
+                "create_credit_note": {
+                    "description": "CreateCreditNote issues a credit note for the refunded amount against the\ninvoice of the payment so that the invoice stays settled. Without it the\nrefunded amount is owed again on the invoice",
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Duplicate charge"
+                }
+            }
+        },
+        "dto.CreateSubscriptionInvoiceRequest": {
+            "type": "object",
+            "properties": {
+                "adjustments": {
+                    "description": "Adjustments are additional line items for the billing run ex proration credits.\nCredits are passed as negative amounts",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.InvoiceAdjustmentRequest"
+                    }
+                },
+                "period_end": {
+                    "type": "string",
+                    "example": "2024-12-01T00:00:00Z"
+                },
+                "period_start": {
+                    "description": "PeriodStart and PeriodEnd default to the current period of the subscription",
+                    "type": "string",
+                    "example": "2024-11-01T00:00:00Z"
+                }
+            }
+        },
+        "dto.CreateSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "customer_id",
+                "plan_id"
+            ],
+            "properties": {
+                "billing_anchor_day": {
+                    "description": "BillingAnchorDay bills a monthly subscription on this day of the month\nwhatever its start date, ex 15 to always bill on the 15th. Months shorter\nthan the day are billed on their last day",
+                    "type": "integer",
+                    "maximum": 31,
+                    "minimum": 1,
+                    "example": 15
+                },
+                "billing_cadence": {
+                    "$ref": "#/definitions/types.BillingCadence"
+                },
+                "billing_cycle": {
+                    "description": "BillingCycle aligns the periods on calendar boundaries when set to calendar.\nDefaults to anniversary",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingCycle"
+                        }
+                    ]
+                },
+                "billing_period": {
+                    "$ref": "#/definitions/types.BillingPeriod"
+                },
+                "billing_period_count": {
+                    "type": "integer"
+                },
+                "billing_threshold": {
+                    "description": "BillingThreshold raises an interim invoice whenever the usage charges of the\nperiod exceed it. Zero disables threshold billing",
+                    "type": "string"
+                },
+                "commitment_amount": {
+                    "description": "CommitmentAmount is the minimum amount billed per period",
+                    "type": "string"
+                },
+                "consolidate_past_invoices": {
+                    "description": "ConsolidatePastInvoices bills the elapsed periods on a single catch-up invoice\ninstead of one invoice per period",
+                    "type": "boolean"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "generate_past_invoices": {
+                    "description": "GeneratePastInvoices bills the periods elapsed before now when the start date\nis in the past. The subscription starts in the period containing now either way",
+                    "type": "boolean"
+                },
+                "invoice_cadence": {
+                    "$ref": "#/definitions/types.InvoiceCadence"
+                },
+                "legal_entity_id": {
+                    "description": "LegalEntityID issues the invoices of the subscription from one of the legal\nentities of the tenant instead of the entity of the customer",
+                    "type": "string"
+                },
+                "lookup_key": {
+                    "type": "string"
+                },
+                "partial_period_behavior": {
+                    "description": "PartialPeriodBehavior is how the partial first period of a calendar or\nanchor day billed subscription is charged. Defaults to prorate",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.PartialPeriodBehavior"
+                        }
+                    ]
+                },
+                "payment_method_id": {
+                    "description": "PaymentMethodID is one of the payment methods of the customer, charged for\nthe subscription instead of the default payment method of the customer",
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "string"
+                },
+                "price_book_id": {
+                    "description": "PriceBookID puts the subscription on a price book of its plan. Without it\nthe price book of the segment of the customer applies, if any",
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "trial_end": {
+                    "description": "TrialEnd starts the subscription with a trial ending at this date. It\noverrides the trial period of the plan",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateTaskRequest": {
+            "type": "object",
+            "required": [
+                "file_format",
+                "task_type"
+            ],
+            "properties": {
+                "file_format": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.TaskFileFormat"
+                        }
+                    ],
+                    "example": "CSV"
+                },
+                "file_url": {
+                    "type": "string",
+                    "example": "https://bucket.s3.amazonaws.com/customers.csv"
+                },
+                "task_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.TaskType"
+                        }
+                    ],
+                    "example": "CUSTOMER_IMPORT"
+                }
+            }
+        },
+        "dto.CreateWalletRequest": {
+            "type": "object",
+            "required": [
+                "currency",
+                "customer_id"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                }
+            }
+        },
+        "dto.CreateWebhookEndpointRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Billing events for the ledger service"
+                },
+                "event_types": {
+                    "description": "EventTypes limits the endpoint to the listed events, all events when empty",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.WebhookEventType"
+                    },
+                    "example": [
+                        "invoice.finalized"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks/flexprice"
+                }
+            }
+        },
+        "dto.CreditAllocationResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "credit_invoice_id": {
+                    "description": "CreditInvoiceID is the CREDIT invoice the amount is drawn from",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "description": "TargetType and TargetID are the invoice whose amount due was reduced or the\nwallet that was credited",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.CreditAllocationTarget"
+                        }
+                    ]
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreditApplication": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "balance_after": {
+                    "type": "string"
+                },
+                "balance_before": {
+                    "type": "string"
+                },
+                "wallet_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CustomerActivity": {
+            "type": "object",
+            "properties": {
+                "activity_type": {
+                    "$ref": "#/definitions/types.ActivityType"
+                },
+                "amount": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "description": "ResourceType and ResourceID point to the record the activity is about",
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CustomerForecastResponse": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "forecasted_at": {
+                    "type": "string"
+                },
+                "model": {
+                    "$ref": "#/definitions/types.ForecastModel"
+                },
+                "projected_totals": {
+                    "description": "ProjectedTotals sums the projected totals of the subscriptions by currency",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriptionForecast"
+                    }
+                }
+            }
+        },
+        "dto.CustomerRatesResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriptionRates"
+                    }
+                }
+            }
+        },
+        "dto.CustomerReceivables": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "days_30": {
+                    "type": "string"
+                },
+                "days_60": {
+                    "type": "string"
+                },
+                "days_90_plus": {
+                    "type": "string"
+                },
+                "invoices": {
+                    "description": "Invoices is the number of outstanding invoices",
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CustomerResponse": {
+            "type": "object",
+            "properties": {
+                "billing_address": {
+                    "description": "BillingAddress is printed on the invoices of the customer",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/customer.Address"
+                        }
+                    ]
+                },
+                "collections_pause_reason": {
+                    "type": "string"
+                },
+                "collections_paused_until": {
+                    "description": "CollectionsPausedUntil is when the collections paused on the customer\nresume. No payment is collected or bank debit submitted until then",
+                    "type": "string"
+                },
+                "consolidate_invoices": {
+                    "description": "ConsolidateInvoices bills all the subscriptions of the customer sharing a\nbilling date, a currency and a legal entity on a single invoice",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt is when the customer was deleted, it can be restored until it is purged",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email is the email of the customer",
+                    "type": "string"
+                },
+                "external_id": {
+                    "description": "ExternalID is the external identifier for the customer",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the unique identifier for the customer",
+                    "type": "string"
+                },
+                "legal_entity_id": {
+                    "description": "LegalEntityID is the legal entity the customer is invoiced from, the\ndefault entity of the tenant when empty",
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Metadata holds cross references to other systems ex stripe_customer_id",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Metadata"
+                        }
+                    ]
+                },
+                "name": {
+                    "description": "Name is the name of the customer",
+                    "type": "string"
+                },
+                "payment_method_id": {
+                    "description": "PaymentMethodID is the default payment method of the customer at the payment provider",
+                    "type": "string"
+                },
+                "shipping_address": {
+                    "description": "ShippingAddress is where the goods are delivered when it differs from the billing address",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/customer.Address"
+                        }
+                    ]
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tax_ids": {
+                    "description": "TaxIDs are the tax registration numbers of the customer ex their EU VAT number",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/customer.TaxID"
+                    }
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "Timezone is the IANA timezone the billing periods of the customer's new\nsubscriptions are computed in, so that they start at local midnight",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DeadLetterReplayError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DeadLetterResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "dead_letter_status": {
+                    "$ref": "#/definitions/types.DeadLetterStatus"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_failed_at": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "description": "MessageID is the id of the Kafka message, the id of the event for events",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "Payload is the message as consumed, which may not be valid JSON",
+                    "type": "string"
+                },
+                "replayed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DebitWalletRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                }
+            }
+        },
+        "dto.DisputeResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is the sum of the disputed amounts of the lines",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "credit_note_id": {
+                    "description": "CreditNoteID is the credit note issued for the disputed amount when the\ndispute is resolved with a credit note",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "dispute_status": {
+                    "$ref": "#/definitions/types.DisputeStatus"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invoice_id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "description": "Lines are the disputed amounts of the line items of the invoice",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/invoice.DisputedLine"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reissued_invoice_id": {
+                    "description": "ReissuedInvoiceID is the corrected invoice raised in place of the voided\ninvoice when the dispute is resolved by re-issuing it",
+                    "type": "string"
+                },
+                "resolution": {
+                    "description": "Resolution is how the dispute was resolved, set once it is resolved",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.DisputeResolution"
+                        }
+                    ]
+                },
+                "resolution_note": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DisputedLineRequest": {
+            "type": "object",
+            "required": [
+                "line_item_id"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount is the part of the amount of the line item disputed",
+                    "type": "string",
+                    "example": "40.00"
+                },
+                "line_item_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "dto.EmailDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "body_html": {
+                    "type": "string"
+                },
+                "body_text": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "delivery_status": {
+                    "$ref": "#/definitions/types.EmailDeliveryStatus"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "entity_type": {
+                    "description": "EntityType and EntityID are what the email is about ex an invoice",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "Provider and ProviderMessageID identify the email at the provider once sent",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.EmailProvider"
+                        }
+                    ]
+                },
+                "provider_message_id": {
+                    "type": "string"
+                },
+                "recipient": {
+                    "type": "string"
+                },
+                "resent_from": {
+                    "description": "ResentFrom is the delivery this one resends",
+                    "type": "string"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "template_type": {
+                    "$ref": "#/definitions/types.EmailTemplateType"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EmailTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "body_html": {
+                    "type": "string"
+                },
+                "body_text": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_default": {
+                    "description": "IsDefault is set when the tenant has not replaced the default template",
+                    "type": "boolean"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "template_type": {
+                    "$ref": "#/definitions/types.EmailTemplateType"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EnvironmentResetResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "current_step": {
+                    "description": "CurrentStep is the step being executed or the last executed step",
+                    "type": "string"
+                },
+                "environment_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "records_deleted": {
+                    "description": "RecordsDeleted is the number of rows deleted from Postgres",
+                    "type": "integer"
+                },
+                "reset_status": {
+                    "$ref": "#/definitions/types.ResetStatus"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "steps_completed": {
+                    "description": "StepsCompleted out of StepsTotal is the progress of the reset",
+                    "type": "integer"
+                },
+                "steps_total": {
+                    "type": "integer"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EnvironmentResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/types.EnvironmentType"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.Event": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "external_customer_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "source": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EventCorrection": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "external_customer_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ingested_at": {
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "sign": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EventHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "corrections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EventCorrection"
+                    }
+                },
+                "current": {
+                    "description": "Current is the row the usage of the event is aggregated from, absent when\nthe event is voided",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.Event"
+                        }
+                    ]
+                },
+                "event": {
+                    "$ref": "#/definitions/dto.Event"
+                },
+                "voided": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.EventPartitionResponse": {
+            "type": "object",
+            "properties": {
+                "bytes_on_disk": {
+                    "type": "integer"
+                },
+                "drop_at": {
+                    "description": "DropAt is when the partition is dropped, unset while a tenant keeps its\nevents forever",
+                    "type": "string"
+                },
+                "partition_id": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.EventSchemaResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "on_invalid": {
+                    "description": "OnInvalid is what happens to the events that do not match the schema",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvalidEventAction"
+                        }
+                    ]
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/eventschema.PropertyRule"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EventStorageResponse": {
+            "type": "object",
+            "properties": {
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EventPartitionResponse"
+                    }
+                },
+                "tenants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TenantStorageResponse"
+                    }
+                }
+            }
+        },
+        "dto.ExportDownloadResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ExportPlanPricesResponse": {
+            "type": "object",
+            "properties": {
+                "plan_id": {
+                    "type": "string"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PlanPriceRow"
+                    }
+                }
+            }
+        },
+        "dto.ExportResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error holds the failure reason when the export failed",
+                    "type": "string"
+                },
+                "event_name": {
+                    "description": "EventName filters the raw events to export. It is required for event exports\nand is derived from the meter for usage exports",
+                    "type": "string"
+                },
+                "export_format": {
+                    "description": "Format is the file format of the exported object",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ExportFormat"
+                        }
+                    ]
+                },
+                "export_status": {
+                    "description": "ExportStatus tracks the progress of the export job",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ExportStatus"
+                        }
+                    ]
+                },
+                "export_type": {
+                    "description": "Type is the kind of data being exported i.e raw events or aggregated usage",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ExportType"
+                        }
+                    ]
+                },
+                "external_customer_id": {
+                    "description": "ExternalCustomerID optionally limits the export to a single customer",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "meter_id": {
+                    "description": "MeterID is the meter whose usage is aggregated, only set for usage exports",
+                    "type": "string"
+                },
+                "object_key": {
+                    "description": "ObjectKey is the key of the generated file in the export bucket",
+                    "type": "string"
+                },
+                "row_count": {
+                    "description": "RowCount is the number of rows written to the file",
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "description": "ScheduleID is the schedule which created the export, empty for the\nexports created through the API",
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "window_size": {
+                    "description": "WindowSize is the aggregation window for usage exports",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.WindowSize"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.ExportScheduleResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "export_format": {
+                    "$ref": "#/definitions/types.ExportFormat"
+                },
+                "export_type": {
+                    "$ref": "#/definitions/types.ExportType"
+                },
+                "external_customer_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "meter_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "description": "NextRunAt is when the schedule creates its next export",
+                    "type": "string"
+                },
+                "schedule": {
+                    "description": "Schedule is how often the export runs, at midnight UTC",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ReportSchedule"
+                        }
+                    ]
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "window_size": {
+                    "$ref": "#/definitions/types.WindowSize"
+                }
+            }
+        },
+        "dto.ExportedEvent": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "external_customer_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "source": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FeatureFlagOverrideResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "environment_id": {
+                    "type": "string"
+                },
+                "flag": {
+                    "$ref": "#/definitions/types.FeatureFlag"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FeatureFlagResponse": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "description": "Default applies to the environments of the tenant without an override",
+                    "type": "boolean"
+                },
+                "flag": {
+                    "$ref": "#/definitions/types.FeatureFlag"
+                },
+                "overrides": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FeatureFlagOverrideResponse"
+                    }
+                }
+            }
+        },
+        "dto.GetEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.Event"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "iter_first_key": {
+                    "type": "string"
+                },
+                "iter_last_key": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GetUsageByMeterRequest": {
+            "type": "object",
+            "required": [
+                "meter_id"
+            ],
+            "properties": {
+                "customer_id": {
+                    "type": "string",
+                    "example": "customer456"
+                },
+                "end_time": {
+                    "type": "string",
+                    "example": "2024-12-09T00:00:00Z"
+                },
+                "external_customer_id": {
+                    "type": "string",
+                    "example": "user_5"
+                },
+                "filters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "meter_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "reset_anchor": {
+                    "description": "ResetAnchor aligns the weekly and monthly resets of meters with a calendar\nreset_usage, typically the subscription start. Defaults to start_time",
+                    "type": "string",
+                    "example": "2024-11-09T00:00:00Z"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2024-11-09T00:00:00Z"
+                },
+                "timezone": {
+                    "description": "Timezone is the IANA timezone reset periods are computed in. Defaults to UTC",
+                    "type": "string",
+                    "example": "America/New_York"
+                },
+                "window_size": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.WindowSize"
+                        }
+                    ],
+                    "example": "HOUR"
+                }
+            }
+        },
+        "dto.GetUsageBySubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "subscription_id"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "example": "2024-03-20T00:00:00Z"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2024-03-13T00:00:00Z"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "dto.GetUsageBySubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "charges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriptionUsageByMetersResponse"
+                    }
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "display_amount": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GetUsageRequest": {
+            "type": "object",
+            "required": [
+                "aggregation_type",
+                "event_name"
+            ],
+            "properties": {
+                "aggregation_type": {
+                    "type": "string",
+                    "example": "COUNT"
+                },
+                "customer_id": {
+                    "type": "string",
+                    "example": "customer456"
+                },
+                "end_time": {
+                    "type": "string",
+                    "example": "2024-03-20T00:00:00Z"
+                },
+                "event_name": {
+                    "type": "string",
+                    "example": "api_request"
+                },
+                "external_customer_id": {
+                    "type": "string",
+                    "example": "customer456"
+                },
+                "filters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "percentile": {
+                    "description": "Percentile of a PERCENTILE aggregation, greater than 0 and up to 100",
+                    "type": "number",
+                    "maximum": 100,
+                    "example": 99
+                },
+                "precision": {
+                    "description": "Precision of the HyperLogLog sketch of a COUNT_UNIQUE aggregation, from 12\nto 20. The distinct values are counted exactly when it is omitted",
+                    "type": "integer",
+                    "maximum": 20,
+                    "minimum": 12,
+                    "example": 14
+                },
+                "property_name": {
+                    "description": "will be empty/ignored in case of COUNT",
+                    "type": "string",
+                    "example": "request_size"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2024-03-13T00:00:00Z"
+                },
+                "window_size": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.WindowSize"
+                        }
+                    ],
+                    "example": "HOUR"
+                }
+            }
+        },
+        "dto.GetUsageResponse": {
+            "type": "object",
+            "properties": {
+                "event_name": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UsageResult"
+                    }
+                },
+                "type": {
+                    "$ref": "#/definitions/types.AggregationType"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.ImportPlanPricesRequest": {
+            "type": "object",
+            "properties": {
+                "archive_missing": {
+                    "description": "ArchiveMissing archives the prices of the plan which are not in the import",
+                    "type": "boolean"
+                },
+                "dry_run": {
+                    "description": "DryRun reports what the import would do without applying it",
+                    "type": "boolean"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PlanPriceRow"
+                    }
+                }
+            }
+        },
+        "dto.ImportPlanPricesResponse": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "Applied is false when the import was a dry run or when any row is invalid,\nthe rows are applied all together or not at all",
+                    "type": "boolean"
+                },
+                "archived": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "replaced": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceImportResult"
+                    }
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ImportStripeRequest": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "description": "DryRun reports what the import would create and the conflicts it detects\nwithout creating anything",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.ImportStripeResponse": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "Applied is false for dry runs, the objects are created all together or\nnot at all",
+                    "type": "boolean"
+                },
+                "conflicts": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "existing": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StripeImportResult"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.IngestEventRequest": {
+            "type": "object",
+            "required": [
+                "event_name",
+                "external_customer_id"
+            ],
+            "properties": {
+                "customer_id": {
+                    "type": "string",
+                    "example": "customer456"
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "event123"
+                },
+                "event_name": {
+                    "type": "string",
+                    "example": "api_request"
+                },
+                "external_customer_id": {
+                    "type": "string",
+                    "example": "customer456"
+                },
+                "idempotency_key": {
+                    "description": "IdempotencyKey makes retries of the event count once. Without an event_id,\nthe ID of the event is derived from the key and the timestamp, which is\nthen required. It can also be sent in the Idempotency-Key header",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "req_7f3a9c"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "\"response_status\"": "200}",
+                        "{\"request_size\"": "100"
+                    }
+                },
+                "source": {
+                    "type": "string",
+                    "example": "api"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2024-03-20T15:04:05Z"
+                }
+            }
+        },
+        "dto.InvoiceAdjustmentRequest": {
+            "type": "object",
+            "required": [
+                "display_name"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "-10.00"
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "Proration credit"
+                }
+            }
+        },
+        "dto.InvoiceDiscountRequest": {
+            "type": "object",
+            "required": [
+                "display_name"
+            ],
+            "properties": {
+                "amount_off": {
+                    "type": "string",
+                    "example": "100.00"
+                },
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "WELCOME10"
+                },
+                "percent_off": {
+                    "type": "string",
+                    "example": "10"
+                }
+            }
+        },
+        "dto.InvoiceNumberingConfigResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "environment_id": {
+                    "type": "string"
+                },
+                "padding": {
+                    "description": "Padding is the minimum number of digits of the counter, left padded with zeros",
+                    "type": "integer"
+                },
+                "prefix": {
+                    "description": "Prefix is prepended to every invoice number",
+                    "type": "string"
+                },
+                "reset_yearly": {
+                    "description": "ResetYearly restarts the counter every calendar year and adds the year to the number",
+                    "type": "boolean"
+                },
+                "separator": {
+                    "description": "Separator is placed between the prefix, the year and the counter",
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is incremented on every update and guards against concurrent writes.\nIt is zero until the tenant configures numbering",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.InvoicePaymentResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "amount_refunded": {
+                    "description": "AmountRefunded is the sum of the refunds of the payment, it never exceeds the amount",
+                    "type": "string"
+                },
+                "charge_date": {
+                    "type": "string"
+                },
+                "connection_id": {
+                    "description": "ConnectionID and GatewayPaymentID are set for payments collected at a payment\ngateway, their refunds are executed at the gateway",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "dunning_action": {
+                    "$ref": "#/definitions/types.DunningAction"
+                },
+                "failure_code": {
+                    "description": "FailureCode, FailureReason and DunningAction are set for failed bank debits,\nthe code is the return code of the scheme or the failure code of the gateway",
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "type": "string"
+                },
+                "gateway_payment_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invoice_id": {
+                    "type": "string"
+                },
+                "mandate_id": {
+                    "description": "MandateID and ChargeDate are set for bank debits, the customer was notified\nthat the mandate is debited on the charge date",
+                    "type": "string"
+                },
+                "paid_at": {
+                    "description": "PaidAt is when the customer paid, which may be before it was recorded",
+                    "type": "string"
+                },
+                "payment_method_type": {
+                    "description": "PaymentMethodType is how the amount was paid ex card, bank_transfer or check",
+                    "type": "string"
+                },
+                "payment_status": {
+                    "description": "PaymentStatus is SUCCEEDED for the payments settled when recorded, bank\ndebits are SCHEDULED then PROCESSING until they clear or fail",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.PaymentStatus"
+                        }
+                    ]
+                },
+                "reference": {
+                    "description": "Reference identifies the payment outside Flexprice ex the charge ID at the\ngateway or the reference of the bank transfer",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.InvoiceResponse": {
+            "type": "object",
+            "properties": {
+                "amount_due": {
+                    "description": "AmountDue is the amount to be collected from the customer and is never negative",
+                    "type": "number"
+                },
+                "amount_paid": {
+                    "description": "AmountPaid is the sum of the settled payments recorded against the invoice",
+                    "type": "number"
+                },
+                "amount_processing": {
+                    "description": "AmountProcessing is the sum of the bank debits of the invoice that are\nscheduled or not cleared yet. It is not collected again",
+                    "type": "number"
+                },
+                "amount_remaining": {
+                    "description": "AmountRemaining is the part of the amount due not paid yet",
+                    "type": "number"
+                },
+                "amount_written_off": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "credit_invoice_ids": {
+                    "description": "CreditInvoiceIDs are the credit (or negative) invoices issued against this invoice",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "currency": {
+                    "description": "Currency 3 digit ISO currency code in lowercase ex usd, eur, gbp",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "description": "CustomerID is the identifier of the customer being invoiced",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description is an optional note printed on the invoice",
+                    "type": "string"
+                },
+                "finalized_at": {
+                    "description": "FinalizedAt is the time the invoice was finalized and can no longer be edited",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the unique identifier for the invoice",
+                    "type": "string"
+                },
+                "invoice_flow": {
+                    "description": "InvoiceFlow is the billing flow that raised a subscription invoice ex PERIOD_END, THRESHOLD",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoiceFlow"
+                        }
+                    ]
+                },
+                "invoice_number": {
+                    "description": "InvoiceNumber is the human readable number assigned from the tenant sequence\nwhen the invoice is finalized. Drafts do not consume numbers",
+                    "type": "string"
+                },
+                "invoice_status": {
+                    "description": "InvoiceStatus is the lifecycle status of the invoice ex DRAFT, FINALIZED, VOIDED",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoiceStatus"
+                        }
+                    ]
+                },
+                "invoice_type": {
+                    "description": "InvoiceType is the type of the document ex SUBSCRIPTION, ONE_OFF, CREDIT",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoiceType"
+                        }
+                    ]
+                },
+                "legal_entity_id": {
+                    "description": "LegalEntityID is the legal entity the invoice was issued from, empty when the\ntenant has none. The invoice number is drawn from the sequence of the entity",
+                    "type": "string"
+                },
+                "line_items": {
+                    "description": "LineItems are loaded separately from the invoice_line_items table",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/invoice.InvoiceLineItem"
+                    }
+                },
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                },
+                "original_invoice_id": {
+                    "description": "OriginalInvoiceID links a credit (or negative) invoice to the invoice being credited",
+                    "type": "string"
+                },
+                "partial_period_behavior": {
+                    "description": "PartialPeriodBehavior is how the fixed charges were billed when the invoice covers\nthe partial first period of a calendar billed subscription, empty otherwise",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.PartialPeriodBehavior"
+                        }
+                    ]
+                },
+                "payment_status": {
+                    "description": "PaymentStatus is PAID once nothing remains to be paid",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoicePaymentStatus"
+                        }
+                    ]
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "description": "PeriodStart and PeriodEnd is the service period covered by the invoice",
+                    "type": "string"
+                },
+                "sections": {
+                    "description": "Sections group the line items per subscription on consolidated invoices",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.InvoiceSection"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "subscription_id": {
+                    "description": "SubscriptionID is the subscription the invoice was raised for, empty for one off\ninvoices and for invoices consolidating several subscriptions",
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total is the signed sum of all line items. It is negative when\ncredits like proration exceed the charges of the billing run",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is incremented on every update and guards against concurrent writes",
+                    "type": "integer"
+                },
+                "write_off_reason": {
+                    "type": "string"
+                },
+                "written_off_at": {
+                    "description": "WrittenOffAt, WriteOffReason and AmountWrittenOff are set when the invoice is\nwritten off as uncollectible, the amount is what remained to be paid then.\nWritten off invoices are left out of the receivables",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.InvoiceSection": {
+            "type": "object",
+            "properties": {
+                "line_items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/invoice.InvoiceLineItem"
+                    }
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "subtotal": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.InvoiceTaxRequest": {
+            "type": "object",
+            "required": [
+                "display_name"
+            ],
+            "properties": {
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "VAT 20%"
+                },
+                "rate_percent": {
+                    "type": "string",
+                    "example": "20"
+                }
+            }
+        },
+        "dto.JobResponse": {
+            "type": "object",
+            "properties": {
+                "batch_size": {
+                    "description": "BatchSize is how many items a run hands out at a time, 0 for all of them",
+                    "type": "integer"
+                },
+                "concurrency": {
+                    "description": "Concurrency is how many items a run processes at once",
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "interval_mins": {
+                    "description": "IntervalMins is the time between two scheduled runs",
+                    "type": "integer"
+                },
+                "last_run": {
+                    "$ref": "#/definitions/job.Run"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "running": {
+                    "description": "Running tells whether the job runs in the process serving the request",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.JobRunResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error holds the failure reason of a failed run",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_name": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.JobRunStatus"
+                },
+                "trigger": {
+                    "$ref": "#/definitions/types.JobTrigger"
+                },
+                "triggered_by": {
+                    "description": "TriggeredBy is the user who triggered a manual run",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LedgerSyncResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "connection_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "entity_type": {
+                    "description": "EntityType is invoice or credit_note and EntityID the ID of the invoice",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.SyncEntityType"
+                        }
+                    ]
+                },
+                "external_id": {
+                    "description": "ExternalID is the ID of the document in the ledger once synced",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "sync_status": {
+                    "$ref": "#/definitions/types.LedgerSyncStatus"
+                },
+                "synced_at": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LegalEntityResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/customer.Address"
+                },
+                "bank_details": {
+                    "description": "BankDetails is the account the customers of the entity pay by bank transfer to",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/legalentity.BankDetails"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invoice_padding": {
+                    "type": "integer"
+                },
+                "invoice_prefix": {
+                    "description": "InvoicePrefix, InvoiceSeparator, InvoicePadding and InvoiceResetYearly are\nthe format of the numbers of the invoices of the entity, drawn from a\nsequence of its own",
+                    "type": "string"
+                },
+                "invoice_reset_yearly": {
+                    "type": "boolean"
+                },
+                "invoice_separator": {
+                    "type": "string"
+                },
+                "is_default": {
+                    "description": "IsDefault marks the entity issuing the invoices of the customers and\nsubscriptions not assigned to one. A tenant has at most one",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Name is the registered name of the company printed on its invoices",
+                    "type": "string"
+                },
+                "pdf_template": {
+                    "description": "PDFTemplate is the template the invoice PDFs of the entity are rendered\nwith, the default template when empty",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tax_ids": {
+                    "description": "TaxIDs are the tax registration numbers of the company ex its EU VAT number",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/customer.TaxID"
+                    }
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ListAPIKeysResponse": {
+            "type": "object",
+            "properties": {
+                "api_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.APIKeyResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListAnomaliesResponse": {
+            "type": "object",
+            "properties": {
+                "anomalies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AnomalyResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListAuditLogsResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AuditLogResponse"
+                    }
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListBudgetsResponse": {
+            "type": "object",
+            "properties": {
+                "budgets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BudgetResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListCancellationReasonsResponse": {
+            "type": "object",
+            "properties": {
+                "is_default": {
+                    "description": "IsDefault is set when the tenant has not configured any reason and the\nbuilt in taxonomy applies",
+                    "type": "boolean"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CancellationReasonResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListConnectionsResponse": {
+            "type": "object",
+            "properties": {
+                "connections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ConnectionResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListCreditAllocationsResponse": {
+            "type": "object",
+            "properties": {
+                "allocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CreditAllocationResponse"
+                    }
+                },
+                "remaining": {
+                    "description": "Remaining is the credit left to allocate",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ListCustomerActivityResponse": {
+            "type": "object",
+            "properties": {
+                "activities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CustomerActivity"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListCustomersResponse": {
+            "type": "object",
+            "properties": {
+                "customers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CustomerResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListDeadLettersResponse": {
+            "type": "object",
+            "properties": {
+                "dead_letters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DeadLetterResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListDisputesResponse": {
+            "type": "object",
+            "properties": {
+                "disputes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DisputeResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListEmailDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EmailDeliveryResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListEmailTemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EmailTemplateResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListEnvironmentsResponse": {
+            "type": "object",
+            "properties": {
+                "environments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EnvironmentResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListEventSchemasResponse": {
+            "type": "object",
+            "properties": {
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EventSchemaResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListExportSchedulesResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ExportScheduleResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListExportsResponse": {
+            "type": "object",
+            "properties": {
+                "exports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ExportResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListFeatureFlagsResponse": {
+            "type": "object",
+            "properties": {
+                "flags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FeatureFlagResponse"
+                    }
+                },
+                "tenant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ListInvoicePaymentsResponse": {
+            "type": "object",
+            "properties": {
+                "amount_due": {
+                    "type": "string"
+                },
+                "amount_paid": {
+                    "type": "string"
+                },
+                "amount_processing": {
+                    "description": "AmountProcessing is the sum of the bank debits that did not clear yet",
+                    "type": "string"
+                },
+                "amount_remaining": {
+                    "type": "string"
+                },
+                "payment_status": {
+                    "$ref": "#/definitions/types.InvoicePaymentStatus"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.InvoicePaymentResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListInvoicesResponse": {
+            "type": "object",
+            "properties": {
+                "invoices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.InvoiceResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "description": "NextCursor lists the following page in cursor pagination, it is empty on\nthe last page",
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListJobRunsResponse": {
+            "type": "object",
+            "properties": {
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.JobRunResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListJobsResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.JobResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListLedgerSyncsResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "syncs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LedgerSyncResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListLegalEntitiesResponse": {
+            "type": "object",
+            "properties": {
+                "legal_entities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LegalEntityResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListMandatesResponse": {
+            "type": "object",
+            "properties": {
+                "mandates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MandateResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListPaymentMethodsResponse": {
+            "type": "object",
+            "properties": {
+                "payment_methods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PaymentMethodResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListPlansResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "plans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/plan.Plan"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListPriceBooksResponse": {
+            "type": "object",
+            "properties": {
+                "price_books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceBookResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListPricesResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListQuarantinedEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.QuarantinedEventResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListRateCardsResponse": {
+            "type": "object",
+            "properties": {
+                "rate_cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RateCardResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListRefundsResponse": {
+            "type": "object",
+            "properties": {
+                "refundable": {
+                    "description": "Refundable is the part of the payment not refunded yet",
+                    "type": "string"
+                },
+                "refunds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RefundResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListReportRunsResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReportRunResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListReportTemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReportTemplateResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListRequestLogsResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RequestLogResponse"
+                    }
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListRetentionPoliciesResponse": {
+            "type": "object",
+            "properties": {
+                "default_ttl_days": {
+                    "description": "DefaultTTLDays applies to the tenants without a policy",
+                    "type": "integer"
+                },
+                "policies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RetentionPolicyResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListRoleAssignmentsResponse": {
+            "type": "object",
+            "properties": {
+                "assignments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RoleAssignmentResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListRolesResponse": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RoleResponse"
+                    }
+                }
+            }
+        },
+        "dto.ListSubscriptionsResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "description": "NextCursor lists the following page in cursor pagination, it is empty on\nthe last page",
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriptionResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListTasksResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TaskResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WebhookDeliveryResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListWebhookEndpointsResponse": {
+            "type": "object",
+            "properties": {
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WebhookEndpointResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "dto.MandateResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "mandate_status": {
+                    "$ref": "#/definitions/types.MandateStatus"
+                },
+                "payment_method_id": {
+                    "description": "PaymentMethodID is the bank account payment method of the customer debited",
+                    "type": "string"
+                },
+                "pre_notification_days": {
+                    "description": "PreNotificationDays is how many days before each debit the customer is\nnotified, the default of the scheme unless the mandate agrees otherwise",
+                    "type": "integer"
+                },
+                "reference": {
+                    "description": "Reference identifies the mandate to the bank of the customer ex the unique\nmandate reference of SEPA",
+                    "type": "string"
+                },
+                "revocation_reason": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "RevokedAt and RevocationReason are set once the mandate is no longer active,\nthe reason is the failure code of the debit that revoked it, if any",
+                    "type": "string"
+                },
+                "scheme": {
+                    "$ref": "#/definitions/types.MandateScheme"
+                },
+                "signed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MarginGroup": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "gross_margin": {
+                    "description": "GrossMargin is the revenue less the cost, GrossMarginPercent is its share\nof the revenue and is omitted when there is no revenue",
+                    "type": "string"
+                },
+                "gross_margin_percent": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the ID of the customer, plan or meter of the group",
+                    "type": "string"
+                },
+                "revenue": {
+                    "type": "string"
+                },
+                "uncosted_revenue": {
+                    "description": "UncostedRevenue is the part of the revenue billed by prices without a unit\ncost, whose cost is unknown and not counted",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MarginReportResponse": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "group_by": {
+                    "$ref": "#/definitions/types.MarginGroupBy"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MarginGroup"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MeterResponse": {
+            "type": "object",
+            "properties": {
+                "aggregation": {
+                    "$ref": "#/definitions/meter.Aggregation"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-03-20T15:04:05Z"
+                },
+                "event_name": {
+                    "type": "string",
+                    "example": "api_request"
+                },
+                "filters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meter.Filter"
+                    }
+                },
+                "formula": {
+                    "$ref": "#/definitions/meter.Formula"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "API Usage Meter"
+                },
+                "reset_usage": {
+                    "$ref": "#/definitions/types.ResetUsage"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "published"
+                },
+                "tenant_id": {
+                    "type": "string",
+                    "example": "tenant123"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-03-20T15:04:05Z"
+                }
+            }
+        },
+        "dto.MeterVersionResponse": {
+            "type": "object",
+            "properties": {
+                "aggregation": {
+                    "$ref": "#/definitions/meter.Aggregation"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "description": "EffectiveFrom is the start of the usage aggregated with the version",
+                    "type": "string"
+                },
+                "effective_to": {
+                    "description": "EffectiveTo ends the range of a re-aggregation, other versions stay\neffective until a newer one",
+                    "type": "string"
+                },
+                "filters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meter.Filter"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "meter_id": {
+                    "type": "string"
+                },
+                "reaggregation_error": {
+                    "type": "string"
+                },
+                "reaggregation_status": {
+                    "description": "ReaggregationStatus tracks the recomputation of the rolled up usage of a\nre-aggregation, it is empty for other versions",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.TaskStatus"
+                        }
+                    ]
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version numbers the versions of a meter from 1 in order of creation",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.OneOffInvoiceLineItemRequest": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "description": "DisplayName defaults to the description of the price",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Solution architect, 8 hours"
+                },
+                "price_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "Quantity defaults to 1",
+                    "type": "string",
+                    "example": "8"
+                },
+                "unit_amount": {
+                    "type": "string",
+                    "example": "150.00"
+                }
+            }
+        },
+        "dto.OpenDisputeRequest": {
+            "type": "object",
+            "required": [
+                "lines"
+            ],
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.DisputedLineRequest"
+                    }
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Usage billed twice for March"
+                }
+            }
+        },
+        "dto.PauseCollectionsRequest": {
+            "type": "object",
+            "required": [
+                "until"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Payment plan agreed with the customer"
+                },
+                "until": {
+                    "description": "Until is when the collections resume, it must be in the future",
+                    "type": "string",
+                    "example": "2026-12-31T00:00:00Z"
+                }
+            }
+        },
+        "dto.PaymentMethodResponse": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "description": "Brand, Last4 and the expiry are only set for cards",
+                    "type": "string"
+                },
+                "connection_id": {
+                    "description": "ConnectionID is the connection of the gateway the payment method is vaulted at",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "exp_month": {
+                    "type": "integer"
+                },
+                "exp_year": {
+                    "type": "integer"
+                },
+                "gateway_payment_method_id": {
+                    "description": "GatewayPaymentMethodID is the ID of the payment method at the gateway ex pm_1NqK2a",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_default": {
+                    "description": "IsDefault is set on the payment method the customer is charged to",
+                    "type": "boolean"
+                },
+                "last4": {
+                    "type": "string"
+                },
+                "provider": {
+                    "$ref": "#/definitions/types.IntegrationProvider"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is the kind of payment method at the gateway ex card or sepa_debit",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PlanPriceRow": {
+            "type": "object",
+            "required": [
+                "amount",
+                "billing_cadence",
+                "billing_model",
+                "billing_period",
+                "billing_period_count",
+                "currency",
+                "type"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "billing_cadence": {
+                    "$ref": "#/definitions/types.BillingCadence"
+                },
+                "billing_model": {
+                    "$ref": "#/definitions/types.BillingModel"
+                },
+                "billing_period": {
+                    "$ref": "#/definitions/types.BillingPeriod"
+                },
+                "billing_period_count": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "filter_values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lookup_key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "meter_id": {
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "string"
+                },
+                "tier_mode": {
+                    "$ref": "#/definitions/types.BillingTier"
+                },
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CreatePriceTier"
+                    }
+                },
+                "transform_quantity": {
+                    "$ref": "#/definitions/price.TransformQuantity"
+                },
+                "type": {
+                    "$ref": "#/definitions/types.PriceType"
+                },
+                "unit_cost": {
+                    "description": "UnitCost is the cost of goods sold of one unit billed by the price, in the\ncurrency of the price, used by the margin reports",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PlanResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt is when the plan was deleted, it can be restored until it is purged",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invoice_cadence": {
+                    "$ref": "#/definitions/types.InvoiceCadence"
+                },
+                "lookup_key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceResponse"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "trial_period": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is incremented on every update and guards against concurrent writes",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PortalSessionResponse": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PortalSubscriptionUsage": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "charges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriptionUsageByMetersResponse"
+                    }
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "display_amount": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PortalUsageResponse": {
+            "type": "object",
+            "properties": {
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PortalSubscriptionUsage"
+                    }
+                }
+            }
+        },
+        "dto.PriceBookReportResponse": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "string"
+                },
+                "price_books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceBookStats"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PriceBookResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overrides": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ratecard.Override"
+                    }
+                },
+                "plan_id": {
+                    "type": "string"
+                },
+                "segment": {
+                    "description": "Segment assigns the price book to the new subscriptions of the customers\nof the segment, the segment metadata of the customer. Price books without\na segment only apply to the subscriptions targeted at them",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PriceBookRevenue": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "per_subscription": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PriceBookStats": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "integer"
+                },
+                "conversion_rate": {
+                    "description": "ConversionRate is the share of the ended trials which converted",
+                    "type": "string"
+                },
+                "converted": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price_book_id": {
+                    "type": "string"
+                },
+                "revenue": {
+                    "description": "Revenue is the amount finalized for the subscriptions since the start of\nthe window, per currency",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PriceBookRevenue"
+                    }
+                },
+                "subscriptions": {
+                    "type": "integer"
+                },
+                "trials_ended": {
+                    "description": "TrialsEnded is the number of subscriptions whose trial is over, of which\nConverted were not cancelled by the end of their trial",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PriceImportResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/types.PriceImportAction"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "lookup_key": {
+                    "type": "string"
+                },
+                "price_id": {
+                    "description": "PriceID is the price created or changed, created prices have no id in a dry run",
+                    "type": "string"
+                },
+                "replaced_price_id": {
+                    "description": "ReplacedPriceID is the archived price when the action is replace",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Row is the position of the price in the import starting at 1, it is 0\nfor the prices archived because they are missing from the import",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PriceResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount stored in main currency units (e.g., dollars, not cents)\nFor USD: 12.50 means $12.50",
+                    "type": "number"
+                },
+                "billing_cadence": {
+                    "description": "BillingCadence is the billing cadence for the price ex RECURRING, ONETIME",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingCadence"
+                        }
+                    ]
+                },
+                "billing_model": {
+                    "description": "BillingModel is the billing model for the price ex FLAT_FEE, PACKAGE, TIERED",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingModel"
+                        }
+                    ]
+                },
+                "billing_period": {
+                    "description": "BillingPeriod is the billing period for the price ex month, year",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingPeriod"
+                        }
+                    ]
+                },
+                "billing_period_count": {
+                    "description": "BillingPeriodCount is the count of the billing period ex 1, 3, 6, 12",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency 3 digit ISO currency code in lowercase ex usd, eur, gbp",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt is when the price was deleted, it can be restored until it is purged",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description of the price",
+                    "type": "string"
+                },
+                "display_amount": {
+                    "description": "DisplayAmount is the formatted amount with currency symbol\nFor USD: $12.50",
+                    "type": "string"
+                },
+                "filter_values": {
+                    "description": "FilterValues are the filter values for the price in case of usage based pricing",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/price.JSONBFilters"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "ID uuid identifier for the price",
+                    "type": "string"
+                },
+                "lookup_key": {
+                    "description": "LookupKey used for looking up the price in the database",
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Metadata is a jsonb field for additional information",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/price.JSONBMetadata"
+                        }
+                    ]
+                },
+                "meter_id": {
+                    "description": "MeterID is the id of the meter for usage based pricing",
+                    "type": "string"
+                },
+                "plan_id": {
+                    "description": "PlanID is the id of the plan for plan based pricing",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "tier_mode": {
+                    "description": "Tiered pricing fields when BillingModel is TIERED",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingTier"
+                        }
+                    ]
+                },
+                "tiers": {
+                    "description": "Tiers are the tiers for the price when BillingModel is TIERED",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/price.PriceTier"
+                    }
+                },
+                "transform_quantity": {
+                    "description": "Transform is the quantity transformation in case of PACKAGE billing model",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/price.JSONBTransformQuantity"
+                        }
+                    ]
+                },
+                "type": {
+                    "description": "Type is the type of the price ex USAGE, FIXED",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.PriceType"
+                        }
+                    ]
+                },
+                "unit_cost": {
+                    "description": "UnitCost is the cost of goods sold of one unit billed by the price, in the\ncurrency of the price. For usage prices it is the cost of one unit of the\nusage of the meter",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ProjectedUsage": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "meter_id": {
+                    "type": "string"
+                },
+                "price_id": {
+                    "type": "string"
+                },
+                "projected_quantity": {
+                    "type": "string"
+                },
+                "quantity_to_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.QuarantinedEventResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "external_customer_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "object"
+                },
+                "quarantine_status": {
+                    "$ref": "#/definitions/types.QuarantineStatus"
+                },
+                "resubmitted_at": {
+                    "type": "string"
+                },
+                "schema_id": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/eventschema.Violation"
+                    }
+                }
+            }
+        },
+        "dto.Rate": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "difference": {
+                    "description": "Difference is the effective amount minus the list amount, and\nDifferencePercent its share of the list amount when not zero",
+                    "type": "string"
+                },
+                "difference_percent": {
+                    "type": "string"
+                },
+                "effective_amount": {
+                    "type": "string"
+                },
+                "effective_tiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/price.PriceTier"
+                    }
+                },
+                "list_amount": {
+                    "type": "string"
+                },
+                "list_tiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/price.PriceTier"
+                    }
+                },
+                "meter_id": {
+                    "type": "string"
+                },
+                "price_id": {
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source is what sets the effective price: catalog, price_book or rate_card",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RateCardResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "type": "string"
+                },
+                "effective_to": {
+                    "description": "EffectiveTo is the end of the contract, the catalog prices apply again to\nthe billing periods starting from then unless a later version takes over",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overrides": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ratecard.Override"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "subscription_id": {
+                    "description": "SubscriptionID restricts the rate card to one subscription of the customer.\nEmpty for rate cards covering all the subscriptions of the customer",
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is incremented for each rate card of the same customer and subscription",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ReaggregateMeterRequest": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "start_time"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "example": "2024-12-01T00:00:00Z"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2024-11-01T00:00:00Z"
+                },
+                "version": {
+                    "description": "Version is the number of the version whose definition is applied, the\ncurrent definition by default",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "dto.ReceivablesAging": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "string"
+                },
+                "days_30": {
+                    "type": "string"
+                },
+                "days_60": {
+                    "type": "string"
+                },
+                "days_90_plus": {
+                    "type": "string"
+                },
+                "invoices": {
+                    "description": "Invoices is the number of outstanding invoices",
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReceivablesReportResponse": {
+            "type": "object",
+            "properties": {
+                "as_of": {
+                    "description": "AsOf is when the invoices were aged",
+                    "type": "string"
+                },
+                "currencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReceivablesAging"
+                    }
+                },
+                "customers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CustomerReceivables"
+                    }
+                },
+                "snapshotted": {
+                    "description": "Snapshotted reports whether the report was served from the snapshot of a\npast day, today's report is computed on every request",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.RecordInvoicePaymentRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "50.00"
+                },
+                "connection_id": {
+                    "description": "ConnectionID and GatewayPaymentID identify a payment collected at a payment\ngateway so that its refunds are executed there",
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency defaults to the currency of the invoice and must match it",
+                    "type": "string",
+                    "example": "usd"
+                },
+                "gateway_payment_id": {
+                    "type": "string",
+                    "example": "pi_3NqK2aLkdIwHu7ix"
+                },
+                "paid_at": {
+                    "description": "PaidAt defaults to now",
+                    "type": "string",
+                    "example": "2024-12-01T00:00:00Z"
+                },
+                "payment_method_type": {
+                    "type": "string",
+                    "example": "bank_transfer"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "TRX-20241201-001"
+                }
+            }
+        },
+        "dto.RefreshRevenueSnapshotsRequest": {
+            "type": "object",
+            "required": [
+                "end_month",
+                "start_month"
+            ],
+            "properties": {
+                "end_month": {
+                    "type": "string"
+                },
+                "start_month": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RefreshRevenueSnapshotsResponse": {
+            "type": "object",
+            "properties": {
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.RefundResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "credit_note_id": {
+                    "description": "CreditNoteID is the credit note issued for the refunded amount against the\ninvoice of the payment, if one was requested",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "description": "FailureReason is why the gateway did not refund the payment",
+                    "type": "string"
+                },
+                "gateway_refund_id": {
+                    "description": "GatewayRefundID is the ID of the refund at the gateway ex re_3NqK2a",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invoice_id": {
+                    "type": "string"
+                },
+                "payment_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "refund_status": {
+                    "$ref": "#/definitions/types.RefundStatus"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReplayDeadLettersRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.ReplayDeadLettersResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DeadLetterReplayError"
+                    }
+                },
+                "replayed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DeadLetterResponse"
+                    }
+                }
+            }
+        },
+        "dto.ReportRunResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error holds the failure reason when the run failed, or the destinations\na completed run could not be delivered to",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "object_key": {
+                    "description": "ObjectKey is the key of the CSV in the export bucket",
+                    "type": "string"
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "description": "PeriodStart and PeriodEnd bound the time field of the records reported,\nfor templates with a period",
+                    "type": "string"
+                },
+                "row_count": {
+                    "type": "integer"
+                },
+                "run_status": {
+                    "$ref": "#/definitions/types.ReportRunStatus"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "template_id": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "trigger": {
+                    "$ref": "#/definitions/types.ReportRunTrigger"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReportTemplateRequest": {
+            "type": "object",
+            "required": [
+                "columns",
+                "entity",
+                "name"
+            ],
+            "properties": {
+                "columns": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/report.Column"
+                    }
+                },
+                "destinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.Destination"
+                    }
+                },
+                "entity": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ReportEntity"
+                        }
+                    ],
+                    "example": "invoices"
+                },
+                "filters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.Filter"
+                    }
+                },
+                "group_by": {
+                    "description": "GroupBy aggregates the records by the listed fields, whose columns are\nnot aggregated. The other columns must be aggregated",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "currency"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Monthly revenue by currency"
+                },
+                "period": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ReportPeriod"
+                        }
+                    ],
+                    "example": "previous_month"
+                },
+                "schedule": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ReportSchedule"
+                        }
+                    ],
+                    "example": "monthly"
+                }
+            }
+        },
+        "dto.ReportTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.Column"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "destinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.Destination"
+                    }
+                },
+                "entity": {
+                    "$ref": "#/definitions/types.ReportEntity"
+                },
+                "filters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.Filter"
+                    }
+                },
+                "group_by": {
+                    "description": "GroupBy lists the fields the records are grouped by. Grouped reports have\na row per group and their other columns must be aggregated",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "description": "NextRunAt is when the schedule runs the template next",
+                    "type": "string"
+                },
+                "period": {
+                    "description": "Period restricts each run to the records whose time field falls in the\nday, week or month before it, all records when empty",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ReportPeriod"
+                        }
+                    ]
+                },
+                "schedule": {
+                    "$ref": "#/definitions/types.ReportSchedule"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RequestLogResponse": {
+            "type": "object",
+            "properties": {
+                "api_key_id": {
+                    "type": "string"
+                },
+                "endpoint": {
+                    "description": "Endpoint is the route template of the path ex /v1/customers/:id",
+                    "type": "string"
+                },
+                "environment_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "request_body": {
+                    "description": "RequestBody and ResponseBody are redacted of personal data and truncated",
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "response_body": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ResetEnvironmentRequest": {
+            "type": "object",
+            "properties": {
+                "confirmation_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ResetEnvironmentResponse": {
+            "type": "object",
+            "properties": {
+                "confirmation_token": {
+                    "description": "ConfirmationToken is returned when the request did not carry a valid token",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "reset": {
+                    "description": "Reset is the started reset job, poll it for progress",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/environment.Reset"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.ResolveDisputeRequest": {
+            "type": "object",
+            "required": [
+                "resolution"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Duplicate usage confirmed"
+                },
+                "resolution": {
+                    "description": "Resolution is credit_note to credit the disputed amount to the invoice,\nreissue to void the invoice and raise a corrected draft in its place, or\nuphold to keep the invoice as issued",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.DisputeResolution"
+                        }
+                    ],
+                    "example": "credit_note"
+                }
+            }
+        },
+        "dto.ResubmitQuarantinedEventRequest": {
+            "type": "object",
+            "properties": {
+                "properties": {
+                    "description": "Properties replace the properties of the quarantined event when set",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "\"response_status\"": "200}",
+                        "{\"request_size\"": "100"
+                    }
+                }
+            }
+        },
+        "dto.RetentionPolicyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "ttl_days": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RevenueAnalyticsResponse": {
+            "type": "object",
+            "properties": {
+                "group_by": {
+                    "$ref": "#/definitions/types.RevenueGroupBy"
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RevenueMonth"
+                    }
+                }
+            }
+        },
+        "dto.RevenueGroup": {
+            "type": "object",
+            "properties": {
+                "arr": {
+                    "type": "string"
+                },
+                "churned_customers": {
+                    "type": "integer"
+                },
+                "churned_mrr": {
+                    "type": "string"
+                },
+                "contraction_mrr": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customer_churn_rate": {
+                    "type": "string"
+                },
+                "customers": {
+                    "description": "Customers is the number of customers with recurring revenue at the end of\nthe month, ChurnedCustomers those who had some at the end of the month\nbefore and have none",
+                    "type": "integer"
+                },
+                "expansion_mrr": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the plan ID, currency or segment of the group, empty when the\nrevenue is not grouped",
+                    "type": "string"
+                },
+                "mrr": {
+                    "type": "string"
+                },
+                "net_revenue_retention": {
+                    "description": "NetRevenueRetention is the MRR of the customers of the month before as a\npercentage of their MRR then. The rates are percentages of the MRR and\ncustomers of the month before, and are omitted when there were none",
+                    "type": "string"
+                },
+                "new_mrr": {
+                    "description": "NewMRR is the revenue of the customers without any the month before,\nExpansionMRR and ContractionMRR the increase and decrease of the others",
+                    "type": "string"
+                },
+                "revenue_churn_rate": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RevenueMonth": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RevenueGroup"
+                    }
+                },
+                "month": {
+                    "description": "Month is the first instant of the month in UTC",
+                    "type": "string"
+                },
+                "snapshotted": {
+                    "description": "Snapshotted reports whether the month is closed and served from its\nsnapshot, the current month is computed from the invoices on every request",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.RevokeMandateRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RoleAssignmentResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "environment_id": {
+                    "description": "EnvironmentID scopes the role to an environment. The assignments without\nan environment apply to all the environments which have no assignment of\ntheir own, so access to staging does not imply access to production",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/types.Role"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RoleResponse": {
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_flexprice_flexprice_internal_types.Permission"
+                    }
+                },
+                "role": {
+                    "$ref": "#/definitions/types.Role"
+                }
+            }
+        },
+        "dto.RotateWebhookSecretRequest": {
+            "type": "object",
+            "properties": {
+                "previous_secret_ttl_hours": {
+                    "description": "PreviousSecretTTLHours is how long deliveries stay signed with the previous\nsecret as well as the new one. Defaults to 24, zero expires it right away",
+                    "type": "integer",
+                    "maximum": 168,
+                    "minimum": 0,
+                    "example": 24
+                }
+            }
+        },
+        "dto.SSOConfigResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "default_role": {
+                    "$ref": "#/definitions/types.Role"
+                },
+                "domains": {
+                    "description": "Domains are the email domains of the users signing in with the identity\nprovider. A domain belongs to a single tenant",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "group_roles": {
+                    "description": "GroupRoles maps the groups of the identity provider to roles. The most\nprivileged role of the groups of a user is assigned at each sign in",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sso.GroupRoles"
+                        }
+                    ]
+                },
+                "groups_claim": {
+                    "description": "GroupsClaim is the ID token claim or the SAML attribute listing the\ngroups of the user, groups by default",
+                    "type": "string"
+                },
+                "jit_provisioning": {
+                    "description": "JITProvisioning creates the users signing in for the first time with\nDefaultRole when none of their groups is mapped",
+                    "type": "boolean"
+                },
+                "oidc_redirect_url": {
+                    "description": "The service provider details to register with the identity provider",
+                    "type": "string"
+                },
+                "oidc_settings": {
+                    "$ref": "#/definitions/sso.OIDCSettings"
+                },
+                "password_login_disabled": {
+                    "description": "PasswordLoginDisabled rejects the password logins of the tenant users",
+                    "type": "boolean"
+                },
+                "protocol": {
+                    "$ref": "#/definitions/types.SSOProtocol"
+                },
+                "saml_acs_url": {
+                    "type": "string"
+                },
+                "saml_entity_id": {
+                    "type": "string"
+                },
+                "saml_settings": {
+                    "$ref": "#/definitions/sso.SAMLSettings"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ScheduleDirectDebitRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount defaults to the amount remaining of the invoice",
+                    "type": "string",
+                    "example": "50.00"
+                },
+                "mandate_id": {
+                    "description": "MandateID is an active mandate of the customer, the one on the default\npayment method of the customer when empty",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SetEmailTemplateRequest": {
+            "type": "object",
+            "required": [
+                "subject"
+            ],
+            "properties": {
+                "body_html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eHi {{.Customer.Name}}\u003c/p\u003e"
+                },
+                "body_text": {
+                    "type": "string",
+                    "example": "Hi {{.Customer.Name}}"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Invoice {{.Invoice.Number}}"
+                }
+            }
+        },
+        "dto.SetFeatureFlagRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "environment_id": {
+                    "description": "EnvironmentID restricts the override to an environment of the tenant,\nall its environments when empty",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SetRetentionPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "ttl_days": {
+                    "description": "TTLDays is the age in days after which events are deleted, 0 keeps them forever",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SetSSOConfigRequest": {
+            "type": "object",
+            "required": [
+                "domains",
+                "protocol"
+            ],
+            "properties": {
+                "client_secret": {
+                    "description": "ClientSecret is the OIDC client secret. The current secret is kept when empty",
+                    "type": "string"
+                },
+                "default_role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Role"
+                        }
+                    ],
+                    "example": "read_only"
+                },
+                "domains": {
+                    "description": "Domains are the email domains of the users signing in with the identity provider",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "group_roles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/types.Role"
+                    }
+                },
+                "groups_claim": {
+                    "description": "GroupsClaim is the ID token claim or the SAML attribute listing the groups of the user",
+                    "type": "string",
+                    "example": "groups"
+                },
+                "jit_provisioning": {
+                    "type": "boolean"
+                },
+                "oidc_settings": {
+                    "$ref": "#/definitions/sso.OIDCSettings"
+                },
+                "password_login_disabled": {
+                    "type": "boolean"
+                },
+                "protocol": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.SSOProtocol"
+                        }
+                    ],
+                    "example": "oidc"
+                },
+                "saml_settings": {
+                    "$ref": "#/definitions/sso.SAMLSettings"
+                }
+            }
+        },
+        "dto.SignUpRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "dto.SimulationClockResponse": {
+            "type": "object",
+            "properties": {
+                "environment_id": {
+                    "description": "EnvironmentID is the sandbox environment persisting the simulated clock",
+                    "type": "string"
+                },
+                "now": {
+                    "type": "string"
+                },
+                "runs": {
+                    "description": "Runs are the billing jobs run once the clock moved, in the order they ran",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.JobRunResponse"
+                    }
+                },
+                "simulated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.StartSSORequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.StartSSOResponse": {
+            "type": "object",
+            "properties": {
+                "redirect_url": {
+                    "description": "RedirectURL is the identity provider page to send the user to",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.StripeImportResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/types.StripeImportAction"
+                },
+                "flexprice_id": {
+                    "description": "FlexpriceID is the record created, or the one the object was imported\ninto or conflicts with. Created records have no id in a dry run",
+                    "type": "string"
+                },
+                "object": {
+                    "description": "Object is product, price, customer or subscription",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "stripe_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SubscriptionForecast": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "projected_total": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "usage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ProjectedUsage"
+                    }
+                }
+            }
+        },
+        "dto.SubscriptionLineItemResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price_id": {
+                    "type": "string"
+                },
+                "proration": {
+                    "$ref": "#/definitions/subscription.Proration"
+                },
+                "quantity": {
+                    "description": "Quantity is the number of times the price is charged per period",
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "unit_amount": {
+                    "description": "UnitAmount overrides the amount of the price, and of any rate card, when set",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SubscriptionRates": {
+            "type": "object",
+            "properties": {
+                "plan_id": {
+                    "type": "string"
+                },
+                "price_book_id": {
+                    "type": "string"
+                },
+                "rate_card_id": {
+                    "description": "RateCardID and RateCardVersion identify the rate card in effect, if any",
+                    "type": "string"
+                },
+                "rate_card_version": {
+                    "type": "integer"
+                },
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.Rate"
+                    }
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "billing_anchor": {
+                    "description": "BillingAnchor is the reference point that aligns future billing cycle dates.\nIt sets the day of week for week intervals, the day of month for month and year intervals,\nand the month of year for year intervals. The timestamp is in UTC format.",
+                    "type": "string"
+                },
+                "billing_anchor_day": {
+                    "description": "BillingAnchorDay bills monthly periods on this day of the month, or on the last day of\nshorter months, whatever the start date. Zero bills on the anniversary of the start date",
+                    "type": "integer"
+                },
+                "billing_cadence": {
+                    "description": "BillingCadence is the cadence of the billing cycle.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingCadence"
+                        }
+                    ]
+                },
+                "billing_cycle": {
+                    "description": "BillingCycle decides whether periods start on the anniversary of the start date or on calendar boundaries",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingCycle"
+                        }
+                    ]
+                },
+                "billing_period": {
+                    "description": "BillingPeriod is the period of the billing cycle.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingPeriod"
+                        }
+                    ]
+                },
+                "billing_period_count": {
+                    "description": "BillingPeriodCount is the total number units of the billing period.",
+                    "type": "integer"
+                },
+                "billing_threshold": {
+                    "description": "BillingThreshold raises an interim invoice whenever the usage charges accumulated\nin the current period exceed it. Zero means no threshold billing",
+                    "type": "number"
+                },
+                "cancel_at": {
+                    "description": "CancelAt is the date the subscription will be canceled",
+                    "type": "string"
+                },
+                "cancel_at_period_end": {
+                    "description": "CancelAtPeriodEnd is whether the subscription was canceled at the end of the current period",
+                    "type": "boolean"
+                },
+                "cancellation_comment": {
+                    "description": "CancellationComment is the free text feedback left on cancel",
+                    "type": "string"
+                },
+                "cancellation_reason": {
+                    "description": "CancellationReason is the code of the tenant's cancellation reason picked on cancel",
+                    "type": "string"
+                },
+                "cancelled_at": {
+                    "description": "CanceledAt is the date the subscription was canceled",
+                    "type": "string"
+                },
+                "commitment_amount": {
+                    "description": "CommitmentAmount is the minimum amount billed per period. Zero means no commitment",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency is the currency of the subscription in lowercase 3 digit ISO codes",
+                    "type": "string"
+                },
+                "current_period_end": {
+                    "description": "CurrentPeriodEnd is the end of the current period that the subscription has been invoiced for.\nAt the end of this period, a new invoice will be created.",
+                    "type": "string"
+                },
+                "current_period_start": {
+                    "description": "CurrentPeriodStart is the end of the current period that the subscription has been invoiced for.\nAt the end of this period, a new invoice will be created.",
+                    "type": "string"
+                },
+                "customer": {
+                    "description": "Customer is only set when the customer is expanded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.CustomerResponse"
+                        }
+                    ]
+                },
+                "customer_id": {
+                    "description": "CustomerID is the identifier for the customer in our system",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "EndDate is the end date of the subscription",
+                    "type": "string"
+                },
+                "gateway_payment_method_id": {
+                    "description": "GatewayPaymentMethodID is the payment method at the gateway the subscription is charged to.\nEmpty charges the default payment method of the customer",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the unique identifier for the subscription",
+                    "type": "string"
+                },
+                "invoice_cadence": {
+                    "description": "InvoiceCadence is the cadence of the invoice. This overrides the plan's invoice cadence.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoiceCadence"
+                        }
+                    ]
+                },
+                "legal_entity_id": {
+                    "description": "LegalEntityID is the legal entity the subscription is invoiced from, the\nentity of the customer when empty",
+                    "type": "string"
+                },
+                "line_items": {
+                    "description": "LineItems are the recurring fixed charges of the subscription",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/subscription.LineItem"
+                    }
+                },
+                "lookup_key": {
+                    "description": "LookupKey is the key used to lookup the subscription in our system",
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Metadata holds cross references to other systems ex stripe_subscription_id",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Metadata"
+                        }
+                    ]
+                },
+                "partial_period_behavior": {
+                    "description": "PartialPeriodBehavior is how the partial first period of a calendar or anchor day billed subscription is charged",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.PartialPeriodBehavior"
+                        }
+                    ]
+                },
+                "past_invoices": {
+                    "description": "PastInvoices are the invoices of the elapsed periods of a backdated\nsubscription, only set on creation",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.InvoiceResponse"
+                    }
+                },
+                "plan": {
+                    "$ref": "#/definitions/dto.PlanResponse"
+                },
+                "plan_id": {
+                    "description": "PlanID is the identifier for the plan in our system",
+                    "type": "string"
+                },
+                "price_book_id": {
+                    "description": "PriceBookID is the price book overriding the prices of the plan for the\nsubscription, empty when the catalog prices apply",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "StartDate is the start date of the subscription",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "subscription_status": {
+                    "description": "Status is the status of the subscription",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_flexprice_flexprice_internal_types.SubscriptionStatus"
+                        }
+                    ]
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "threshold_billed_until": {
+                    "description": "ThresholdBilledUntil is the end of the usage billed by the last threshold\ninvoice. Usage is accumulated from it when it falls in the current period",
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "Timezone is the IANA timezone the billing periods are computed in, taken from the customer\nwhen the subscription is created. Calendar and anchor day billed periods start at its midnight",
+                    "type": "string"
+                },
+                "trial_end": {
+                    "description": "TrialEnd is the end date of the trial period",
+                    "type": "string"
+                },
+                "trial_start": {
+                    "description": "TrialStart is the start date of the trial period",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is incremented on every update, including the changes of its line items,\nand guards against concurrent writes",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SubscriptionUsageByMetersResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "display_amount": {
+                    "type": "string"
+                },
+                "filter_values": {
+                    "$ref": "#/definitions/price.JSONBFilters"
+                },
+                "meter_display_name": {
+                    "type": "string"
+                },
+                "price": {
+                    "$ref": "#/definitions/price.Price"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.SyncQueueStatusResponse": {
+            "type": "object",
+            "properties": {
+                "connection_id": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "in_flight": {
+                    "type": "boolean"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "string"
+                },
+                "paused_until": {
+                    "description": "PausedUntil is set while the connection backs off after a 429",
+                    "type": "string"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "pending_by_type": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "rate_limited": {
+                    "type": "integer"
+                },
+                "rate_per_second": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.TaskErrorReportResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TaskResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error holds the failure reason when the whole task failed",
+                    "type": "string"
+                },
+                "error_report_key": {
+                    "description": "ErrorReportKey is the key of the report of the failed rows in the bucket,\nonly set when some rows failed",
+                    "type": "string"
+                },
+                "failed_rows": {
+                    "type": "integer"
+                },
+                "file_format": {
+                    "description": "FileFormat is the format of the processed file",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.TaskFileFormat"
+                        }
+                    ]
+                },
+                "file_url": {
+                    "description": "FileURL is where the file is downloaded from, empty when it was uploaded\nwith the request",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "processed_rows": {
+                    "description": "ProcessedRows is the number of rows handled so far, updated after every batch",
+                    "type": "integer"
+                },
+                "range_end": {
+                    "type": "string"
+                },
+                "range_start": {
+                    "description": "RangeStart and RangeEnd bound the timestamps of the events of a backfill",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "successful_rows": {
+                    "type": "integer"
+                },
+                "task_status": {
+                    "description": "TaskStatus tracks the progress of the task",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.TaskStatus"
+                        }
+                    ]
+                },
+                "task_type": {
+                    "description": "Type is the kind of work done by the task",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.TaskType"
+                        }
+                    ]
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "total_rows": {
+                    "description": "TotalRows is the number of rows in the file, known once it is parsed",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TaxIDRequest": {
+            "type": "object",
+            "required": [
+                "type",
+                "value"
             ],
             "properties": {
-                "aggregation": {
-                    "$ref": "#/definitions/meter.Aggregation"
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.TaxIDType"
+                        }
+                    ],
+                    "example": "eu_vat"
                 },
-                "event_name": {
+                "value": {
                     "type": "string",
-                    "example": "api_request"
+                    "example": "DE123456789"
+                }
+            }
+        },
+        "dto.TenantStorageResponse": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "description": "Bytes is the uncompressed size of the events of the tenant",
+                    "type": "integer"
                 },
-                "filters": {
+                "delete_before": {
+                    "description": "DeleteBefore is the cutoff of the next retention run, unset when the\nevents are kept forever",
+                    "type": "string"
+                },
+                "newest_event": {
+                    "type": "string"
+                },
+                "oldest_event": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "integer"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "ttl_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.TopUpWalletRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when the credits of the top-up expire, they never expire when unset",
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                }
+            }
+        },
+        "dto.UpcomingInvoiceResponse": {
+            "type": "object",
+            "properties": {
+                "amount_due": {
+                    "description": "AmountDue is the amount to be collected from the customer and is never negative",
+                    "type": "number"
+                },
+                "amount_out_of_pocket": {
+                    "type": "string"
+                },
+                "amount_paid": {
+                    "description": "AmountPaid is the sum of the settled payments recorded against the invoice",
+                    "type": "number"
+                },
+                "amount_processing": {
+                    "description": "AmountProcessing is the sum of the bank debits of the invoice that are\nscheduled or not cleared yet. It is not collected again",
+                    "type": "number"
+                },
+                "amount_remaining": {
+                    "description": "AmountRemaining is the part of the amount due not paid yet",
+                    "type": "number"
+                },
+                "amount_written_off": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "credit_applications": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/meter.Filter"
+                        "$ref": "#/definitions/dto.CreditApplication"
                     }
                 },
-                "name": {
-                    "type": "string",
-                    "example": "API Usage Meter"
+                "credits_applied": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency 3 digit ISO currency code in lowercase ex usd, eur, gbp",
+                    "type": "string"
+                },
+                "customer_id": {
+                    "description": "CustomerID is the identifier of the customer being invoiced",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description is an optional note printed on the invoice",
+                    "type": "string"
+                },
+                "finalized_at": {
+                    "description": "FinalizedAt is the time the invoice was finalized and can no longer be edited",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the unique identifier for the invoice",
+                    "type": "string"
+                },
+                "invoice_flow": {
+                    "description": "InvoiceFlow is the billing flow that raised a subscription invoice ex PERIOD_END, THRESHOLD",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoiceFlow"
+                        }
+                    ]
+                },
+                "invoice_number": {
+                    "description": "InvoiceNumber is the human readable number assigned from the tenant sequence\nwhen the invoice is finalized. Drafts do not consume numbers",
+                    "type": "string"
+                },
+                "invoice_status": {
+                    "description": "InvoiceStatus is the lifecycle status of the invoice ex DRAFT, FINALIZED, VOIDED",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoiceStatus"
+                        }
+                    ]
+                },
+                "invoice_type": {
+                    "description": "InvoiceType is the type of the document ex SUBSCRIPTION, ONE_OFF, CREDIT",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoiceType"
+                        }
+                    ]
+                },
+                "legal_entity_id": {
+                    "description": "LegalEntityID is the legal entity the invoice was issued from, empty when the\ntenant has none. The invoice number is drawn from the sequence of the entity",
+                    "type": "string"
+                },
+                "line_items": {
+                    "description": "LineItems are loaded separately from the invoice_line_items table",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/invoice.InvoiceLineItem"
+                    }
+                },
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                },
+                "original_invoice_id": {
+                    "description": "OriginalInvoiceID links a credit (or negative) invoice to the invoice being credited",
+                    "type": "string"
+                },
+                "partial_period_behavior": {
+                    "description": "PartialPeriodBehavior is how the fixed charges were billed when the invoice covers\nthe partial first period of a calendar billed subscription, empty otherwise",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.PartialPeriodBehavior"
+                        }
+                    ]
+                },
+                "payment_status": {
+                    "description": "PaymentStatus is PAID once nothing remains to be paid",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvoicePaymentStatus"
+                        }
+                    ]
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "description": "PeriodStart and PeriodEnd is the service period covered by the invoice",
+                    "type": "string"
+                },
+                "renewal_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "subscription_id": {
+                    "description": "SubscriptionID is the subscription the invoice was raised for, empty for one off\ninvoices and for invoices consolidating several subscriptions",
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Total is the signed sum of all line items. It is negative when\ncredits like proration exceed the charges of the billing run",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
                 },
-                "reset_usage": {
+                "usage_projection": {
+                    "description": "UsageProjection is set when the usage of the period is projected at its\nrun-rate, the usage line items are then billed at the projected quantities",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/types.ResetUsage"
+                            "$ref": "#/definitions/dto.UsageProjection"
                         }
-                    ],
-                    "example": "BILLING_PERIOD"
+                    ]
+                },
+                "version": {
+                    "description": "Version is incremented on every update and guards against concurrent writes",
+                    "type": "integer"
+                },
+                "write_off_reason": {
+                    "type": "string"
+                },
+                "written_off_at": {
+                    "description": "WrittenOffAt, WriteOffReason and AmountWrittenOff are set when the invoice is\nwritten off as uncollectible, the amount is what remained to be paid then.\nWritten off invoices are left out of the receivables",
+                    "type": "string"
                 }
             }
         },
-        "dto.CreatePlanPriceRequest": {
+        "dto.UpdateAutoTopUpRequest": {
             "type": "object",
-            "required": [
-                "amount",
-                "billing_cadence",
-                "billing_model",
-                "billing_period",
-                "billing_period_count",
-                "currency",
-                "type"
-            ],
             "properties": {
                 "amount": {
+                    "description": "Amount is credited to the wallet on each top-up",
                     "type": "string"
                 },
-                "billing_cadence": {
-                    "$ref": "#/definitions/types.BillingCadence"
-                },
-                "billing_model": {
-                    "$ref": "#/definitions/types.BillingModel"
+                "daily_cap": {
+                    "description": "DailyCap and MonthlyCap bound the amount topped up per UTC day and calendar\nmonth, zero meaning no cap",
+                    "type": "string"
                 },
-                "billing_period": {
-                    "$ref": "#/definitions/types.BillingPeriod"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "billing_period_count": {
-                    "type": "integer",
-                    "minimum": 1
+                "monthly_cap": {
+                    "type": "string"
                 },
-                "currency": {
+                "threshold": {
+                    "description": "Threshold is the balance below which the wallet is topped up",
                     "type": "string"
+                }
+            }
+        },
+        "dto.UpdateBudgetRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/types.BudgetAction"
                 },
-                "description": {
+                "amount": {
                     "type": "string"
                 },
-                "filter_values": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        }
-                    }
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "dto.UpdateCustomerRequest": {
+            "type": "object",
+            "properties": {
+                "billing_address": {
+                    "$ref": "#/definitions/customer.Address"
                 },
-                "lookup_key": {
+                "consolidate_invoices": {
+                    "description": "ConsolidateInvoices bills all subscriptions sharing a billing date and currency on one invoice",
+                    "type": "boolean"
+                },
+                "email": {
                     "type": "string"
                 },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "external_id": {
+                    "type": "string"
                 },
-                "meter_id": {
+                "legal_entity_id": {
+                    "description": "LegalEntityID is the legal entity the customer is invoiced from, the\ndefault entity of the tenant when empty",
                     "type": "string"
                 },
-                "plan_id": {
+                "name": {
                     "type": "string"
                 },
-                "tier_mode": {
-                    "$ref": "#/definitions/types.BillingTier"
+                "payment_method_id": {
+                    "type": "string"
                 },
-                "tiers": {
+                "shipping_address": {
+                    "$ref": "#/definitions/customer.Address"
+                },
+                "tax_ids": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.CreatePriceTier"
+                        "$ref": "#/definitions/dto.TaxIDRequest"
                     }
                 },
-                "transform_quantity": {
-                    "$ref": "#/definitions/price.TransformQuantity"
+                "timezone": {
+                    "description": "Timezone is the IANA timezone the billing periods of new subscriptions are computed in. Defaults to UTC",
+                    "type": "string",
+                    "example": "America/New_York"
+                }
+            }
+        },
+        "dto.UpdateEventSchemaRequest": {
+            "type": "object",
+            "required": [
+                "rules"
+            ],
+            "properties": {
+                "on_invalid": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.InvalidEventAction"
+                        }
+                    ],
+                    "example": "quarantine"
                 },
-                "type": {
-                    "$ref": "#/definitions/types.PriceType"
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/eventschema.PropertyRule"
+                    }
                 }
             }
         },
-        "dto.CreatePlanRequest": {
+        "dto.UpdateInvoiceLineItemRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "120.00"
+                },
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1,
+                    "example": "Onboarding fee (waived)"
+                },
+                "quantity": {
+                    "type": "string",
+                    "example": "2"
+                }
+            }
+        },
+        "dto.UpdateInvoiceNumberingConfigRequest": {
+            "type": "object",
+            "properties": {
+                "padding": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1,
+                    "example": 5
+                },
+                "prefix": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "INV"
+                },
+                "reset_yearly": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "separator": {
+                    "type": "string",
+                    "maxLength": 5,
+                    "example": "-"
+                }
+            }
+        },
+        "dto.UpdateLegalEntityRequest": {
             "type": "object",
             "required": [
+                "address",
                 "name"
             ],
             "properties": {
-                "description": {
-                    "type": "string"
+                "address": {
+                    "$ref": "#/definitions/customer.Address"
                 },
-                "invoice_cadence": {
-                    "$ref": "#/definitions/types.InvoiceCadence"
+                "bank_details": {
+                    "$ref": "#/definitions/legalentity.BankDetails"
                 },
-                "lookup_key": {
-                    "type": "string"
+                "invoice_padding": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1,
+                    "example": 5
+                },
+                "invoice_prefix": {
+                    "description": "InvoicePrefix, InvoiceSeparator, InvoicePadding and InvoiceResetYearly\nformat the invoice numbers of the entity, as the invoice numbering of the\ntenant. Padding defaults to 5",
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "DE"
+                },
+                "invoice_reset_yearly": {
+                    "type": "boolean"
+                },
+                "invoice_separator": {
+                    "type": "string",
+                    "maxLength": 5,
+                    "example": "-"
+                },
+                "is_default": {
+                    "description": "IsDefault issues the invoices of the customers and subscriptions not\nassigned to an entity from this one. It replaces the current default",
+                    "type": "boolean"
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Acme GmbH"
                 },
-                "prices": {
+                "pdf_template": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "classic"
+                },
+                "tax_ids": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.CreatePlanPriceRequest"
+                        "$ref": "#/definitions/dto.TaxIDRequest"
                     }
-                },
-                "trial_period": {
-                    "type": "integer"
                 }
             }
         },
-        "dto.CreatePriceRequest": {
+        "dto.UpdatePlanPriceRequest": {
             "type": "object",
             "required": [
                 "amount",
@@ -2310,6 +21017,10 @@ const docTemplate = `{
                         }
                     }
                 },
+                "id": {
+                    "description": "The ID of the price to update (present if the price is being updated)",
+                    "type": "string"
+                },
                 "lookup_key": {
                     "type": "string"
                 },
@@ -2339,49 +21050,20 @@ const docTemplate = `{
                 },
                 "type": {
                     "$ref": "#/definitions/types.PriceType"
-                }
-            }
-        },
-        "dto.CreatePriceTier": {
-            "type": "object",
-            "required": [
-                "unit_amount"
-            ],
-            "properties": {
-                "flat_amount": {
-                    "type": "string"
                 },
-                "unit_amount": {
+                "unit_cost": {
+                    "description": "UnitCost is the cost of goods sold of one unit billed by the price, in the\ncurrency of the price, used by the margin reports",
                     "type": "string"
-                },
-                "up_to": {
-                    "type": "integer"
                 }
             }
         },
-        "dto.CreateSubscriptionRequest": {
+        "dto.UpdatePlanRequest": {
             "type": "object",
             "required": [
-                "customer_id",
-                "plan_id"
+                "name"
             ],
             "properties": {
-                "billing_cadence": {
-                    "$ref": "#/definitions/types.BillingCadence"
-                },
-                "billing_period": {
-                    "$ref": "#/definitions/types.BillingPeriod"
-                },
-                "billing_period_count": {
-                    "type": "integer"
-                },
-                "currency": {
-                    "type": "string"
-                },
-                "customer_id": {
-                    "type": "string"
-                },
-                "end_date": {
+                "description": {
                     "type": "string"
                 },
                 "invoice_cadence": {
@@ -2390,475 +21072,443 @@ const docTemplate = `{
                 "lookup_key": {
                     "type": "string"
                 },
-                "plan_id": {
-                    "type": "string"
-                },
-                "start_date": {
+                "name": {
                     "type": "string"
                 },
-                "trial_end": {
-                    "type": "string"
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UpdatePlanPriceRequest"
+                    }
                 },
-                "trial_start": {
-                    "type": "string"
+                "trial_period": {
+                    "type": "integer"
                 }
             }
         },
-        "dto.CreateWalletRequest": {
+        "dto.UpdatePriceRequest": {
             "type": "object",
-            "required": [
-                "currency",
-                "customer_id"
-            ],
             "properties": {
-                "currency": {
+                "description": {
                     "type": "string"
                 },
-                "customer_id": {
+                "lookup_key": {
                     "type": "string"
                 },
                 "metadata": {
-                    "$ref": "#/definitions/types.Metadata"
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "unit_cost": {
+                    "description": "UnitCost replaces the unit cost of the price, it is removed when empty",
+                    "type": "string"
                 }
             }
         },
-        "dto.CustomerResponse": {
+        "dto.UpdateSubscriptionLineItemRequest": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "proration_behavior": {
+                    "description": "ProrationBehavior is how the change is billed for the rest of the current\nperiod. Defaults to create_prorations",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ProrationBehavior"
+                        }
+                    ]
                 },
-                "created_by": {
-                    "type": "string"
+                "quantity": {
+                    "type": "string",
+                    "example": "5"
                 },
-                "email": {
-                    "description": "Email is the email of the customer",
+                "unit_amount": {
+                    "description": "UnitAmount overrides the amount of the price for the subscription",
+                    "type": "string",
+                    "example": "12.00"
+                }
+            }
+        },
+        "dto.UpdateWebhookEndpointRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "description": {
                     "type": "string"
                 },
-                "external_id": {
-                    "description": "ExternalID is the external identifier for the customer",
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.WebhookEventType"
+                    },
+                    "example": [
+                        "invoice.finalized"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks/flexprice"
+                }
+            }
+        },
+        "dto.UsageLockResponse": {
+            "type": "object",
+            "properties": {
+                "locks_at": {
                     "type": "string"
                 },
-                "id": {
-                    "description": "ID is the unique identifier for the customer",
+                "period_end": {
                     "type": "string"
                 },
-                "name": {
-                    "description": "Name is the name of the customer",
+                "period_start": {
                     "type": "string"
                 },
                 "status": {
-                    "$ref": "#/definitions/types.Status"
-                },
-                "tenant_id": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
+                    "$ref": "#/definitions/types.UsageLockStatus"
                 },
-                "updated_by": {
+                "subscription_id": {
                     "type": "string"
                 }
             }
         },
-        "dto.Event": {
+        "dto.UsageProjection": {
             "type": "object",
             "properties": {
-                "customer_id": {
+                "factor": {
                     "type": "string"
                 },
-                "event_name": {
+                "projected_at": {
                     "type": "string"
                 },
-                "external_customer_id": {
-                    "type": "string"
+                "usage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ProjectedUsage"
+                    }
+                }
+            }
+        },
+        "dto.UsageResult": {
+            "type": "object",
+            "properties": {
+                "value": {
+                    "type": "number"
                 },
-                "id": {
+                "window_size": {
                     "type": "string"
-                },
-                "properties": {
-                    "type": "object",
-                    "additionalProperties": true
-                },
-                "source": {
+                }
+            }
+        },
+        "dto.UserResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
                     "type": "string"
                 },
-                "timestamp": {
+                "id": {
                     "type": "string"
                 }
             }
         },
-        "dto.GetEventsResponse": {
+        "dto.ValidateAddressRequest": {
             "type": "object",
             "properties": {
-                "events": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.Event"
-                    }
+                "address": {
+                    "description": "Address is validated instead of the billing address of the customer",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/customer.Address"
+                        }
+                    ]
                 },
-                "has_more": {
+                "update": {
+                    "description": "Update saves the resolved address as the billing address of the customer",
                     "type": "boolean"
-                },
-                "iter_first_key": {
-                    "type": "string"
-                },
-                "iter_last_key": {
-                    "type": "string"
                 }
             }
         },
-        "dto.GetUsageByMeterRequest": {
+        "dto.ValidateAddressResponse": {
             "type": "object",
-            "required": [
-                "meter_id"
-            ],
             "properties": {
-                "customer_id": {
-                    "type": "string",
-                    "example": "customer456"
-                },
-                "end_time": {
-                    "type": "string",
-                    "example": "2024-12-09T00:00:00Z"
-                },
-                "external_customer_id": {
-                    "type": "string",
-                    "example": "user_5"
-                },
-                "filters": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
+                "address": {
+                    "description": "Address is the address as resolved by the tax provider",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/customer.Address"
                         }
-                    }
+                    ]
                 },
-                "meter_id": {
-                    "type": "string",
-                    "example": "123"
+                "message": {
+                    "type": "string"
                 },
-                "start_time": {
-                    "type": "string",
-                    "example": "2024-11-09T00:00:00Z"
+                "updated": {
+                    "description": "Updated is true when the resolved address was saved as the billing address",
+                    "type": "boolean"
                 },
-                "window_size": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.WindowSize"
-                        }
-                    ],
-                    "example": "HOUR"
+                "valid": {
+                    "description": "Valid is false for addresses the tax provider could not resolve",
+                    "type": "boolean"
                 }
             }
         },
-        "dto.GetUsageBySubscriptionRequest": {
+        "dto.VoidEventRequest": {
             "type": "object",
-            "required": [
-                "subscription_id"
-            ],
             "properties": {
-                "end_time": {
+                "reason": {
                     "type": "string",
-                    "example": "2024-03-20T00:00:00Z"
-                },
-                "start_time": {
-                    "type": "string",
-                    "example": "2024-03-13T00:00:00Z"
-                },
-                "subscription_id": {
-                    "type": "string",
-                    "example": "123"
+                    "example": "duplicate event"
                 }
             }
         },
-        "dto.GetUsageBySubscriptionResponse": {
+        "dto.WalletBalanceResponse": {
             "type": "object",
             "properties": {
-                "amount": {
+                "balance": {
                     "type": "number"
                 },
-                "charges": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.SubscriptionUsageByMetersResponse"
-                    }
-                },
-                "currency": {
+                "balance_updated_at": {
                     "type": "string"
                 },
-                "display_amount": {
+                "created_at": {
                     "type": "string"
                 },
-                "end_time": {
+                "created_by": {
                     "type": "string"
                 },
-                "start_time": {
+                "currency": {
                     "type": "string"
-                }
-            }
-        },
-        "dto.GetUsageRequest": {
-            "type": "object",
-            "required": [
-                "aggregation_type",
-                "event_name"
-            ],
-            "properties": {
-                "aggregation_type": {
-                    "type": "string",
-                    "example": "COUNT"
                 },
                 "customer_id": {
-                    "type": "string",
-                    "example": "customer456"
-                },
-                "end_time": {
-                    "type": "string",
-                    "example": "2024-03-20T00:00:00Z"
+                    "type": "string"
                 },
-                "event_name": {
-                    "type": "string",
-                    "example": "api_request"
+                "description": {
+                    "type": "string"
                 },
-                "external_customer_id": {
-                    "type": "string",
-                    "example": "customer456"
+                "id": {
+                    "type": "string"
                 },
-                "filters": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        }
-                    }
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
                 },
-                "property_name": {
-                    "description": "will be empty/ignored in case of COUNT",
-                    "type": "string",
-                    "example": "request_size"
+                "real_time_balance": {
+                    "type": "number"
                 },
-                "start_time": {
-                    "type": "string",
-                    "example": "2024-03-13T00:00:00Z"
+                "status": {
+                    "$ref": "#/definitions/types.Status"
                 },
-                "window_size": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.WindowSize"
-                        }
-                    ],
-                    "example": "HOUR"
-                }
-            }
-        },
-        "dto.GetUsageResponse": {
-            "type": "object",
-            "properties": {
-                "event_name": {
+                "tenant_id": {
                     "type": "string"
                 },
-                "results": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.UsageResult"
-                    }
+                "updated_at": {
+                    "type": "string"
                 },
-                "type": {
-                    "$ref": "#/definitions/types.AggregationType"
+                "updated_by": {
+                    "type": "string"
                 },
-                "value": {
-                    "type": "number"
+                "wallet_status": {
+                    "$ref": "#/definitions/types.WalletStatus"
                 }
             }
         },
-        "dto.IngestEventRequest": {
+        "dto.WalletResponse": {
             "type": "object",
-            "required": [
-                "event_name",
-                "external_customer_id"
-            ],
             "properties": {
-                "customer_id": {
-                    "type": "string",
-                    "example": "customer456"
-                },
-                "event_id": {
-                    "type": "string",
-                    "example": "event123"
+                "balance": {
+                    "type": "number"
                 },
-                "event_name": {
-                    "type": "string",
-                    "example": "api_request"
+                "created_at": {
+                    "type": "string"
                 },
-                "external_customer_id": {
-                    "type": "string",
-                    "example": "customer456"
+                "currency": {
+                    "type": "string"
                 },
-                "properties": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    },
-                    "example": {
-                        "\"response_status\"": "200}",
-                        "{\"request_size\"": "100"
-                    }
+                "customer_id": {
+                    "type": "string"
                 },
-                "source": {
-                    "type": "string",
-                    "example": "api"
+                "id": {
+                    "type": "string"
                 },
-                "timestamp": {
-                    "type": "string",
-                    "example": "2024-03-20T15:04:05Z"
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "wallet_status": {
+                    "$ref": "#/definitions/types.WalletStatus"
                 }
             }
         },
-        "dto.ListCustomersResponse": {
+        "dto.WalletTransactionResponse": {
             "type": "object",
             "properties": {
-                "customers": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.CustomerResponse"
-                    }
+                "amount": {
+                    "type": "number"
                 },
-                "limit": {
-                    "type": "integer"
+                "balance_after": {
+                    "type": "number"
                 },
-                "offset": {
-                    "type": "integer"
+                "balance_before": {
+                    "type": "number"
                 },
-                "total": {
-                    "type": "integer"
-                }
-            }
-        },
-        "dto.ListPlansResponse": {
-            "type": "object",
-            "properties": {
-                "limit": {
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
                 },
-                "offset": {
-                    "type": "integer"
+                "description": {
+                    "type": "string"
                 },
-                "plans": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/plan.Plan"
-                    }
+                "id": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                },
+                "reference_id": {
+                    "type": "string"
+                },
+                "reference_type": {
+                    "type": "string"
+                },
+                "transaction_status": {
+                    "$ref": "#/definitions/types.TransactionStatus"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "wallet_id": {
+                    "type": "string"
                 }
             }
         },
-        "dto.ListPricesResponse": {
+        "dto.WalletTransactionsResponse": {
             "type": "object",
             "properties": {
-                "limit": {
-                    "type": "integer"
+                "filter": {
+                    "$ref": "#/definitions/types.Filter"
                 },
-                "offset": {
+                "total": {
                     "type": "integer"
                 },
-                "prices": {
+                "transactions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.PriceResponse"
+                        "$ref": "#/definitions/dto.WalletTransactionResponse"
                     }
-                },
-                "total": {
-                    "type": "integer"
                 }
             }
         },
-        "dto.ListSubscriptionsResponse": {
+        "dto.WebhookDeliveryResponse": {
             "type": "object",
             "properties": {
-                "limit": {
+                "attempts": {
                     "type": "integer"
                 },
-                "offset": {
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
                 },
-                "subscriptions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.SubscriptionResponse"
-                    }
+                "created_by": {
+                    "type": "string"
                 },
-                "total": {
+                "delivered_at": {
+                    "type": "string"
+                },
+                "delivery_status": {
+                    "$ref": "#/definitions/types.WebhookDeliveryStatus"
+                },
+                "endpoint_id": {
+                    "type": "string"
+                },
+                "endpoint_url": {
+                    "type": "string"
+                },
+                "environment_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "$ref": "#/definitions/types.WebhookEventType"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_response_code": {
+                    "description": "LastResponseCode is zero when the last attempt got no response",
                     "type": "integer"
-                }
-            }
-        },
-        "dto.LoginRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
-            "properties": {
-                "email": {
+                },
+                "next_attempt_at": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 8
+                "payload": {
+                    "description": "Payload is the exact body sent to the endpoint",
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
                 }
             }
         },
-        "dto.MeterResponse": {
+        "dto.WebhookEndpointResponse": {
             "type": "object",
             "properties": {
-                "aggregation": {
-                    "$ref": "#/definitions/meter.Aggregation"
-                },
                 "created_at": {
-                    "type": "string",
-                    "example": "2024-03-20T15:04:05Z"
+                    "type": "string"
                 },
-                "event_name": {
-                    "type": "string",
-                    "example": "api_request"
+                "created_by": {
+                    "type": "string"
                 },
-                "filters": {
+                "description": {
+                    "type": "string"
+                },
+                "environment_id": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "description": "EventTypes limits the endpoint to the listed events, all events when empty",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/meter.Filter"
+                        "type": "string"
                     }
                 },
                 "id": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
-                },
-                "name": {
-                    "type": "string",
-                    "example": "API Usage Meter"
+                    "type": "string"
                 },
-                "reset_usage": {
-                    "$ref": "#/definitions/types.ResetUsage"
+                "previous_secret_expires_at": {
+                    "type": "string"
                 },
                 "status": {
-                    "type": "string",
-                    "example": "published"
+                    "$ref": "#/definitions/types.Status"
                 },
                 "tenant_id": {
-                    "type": "string",
-                    "example": "tenant123"
+                    "type": "string"
                 },
                 "updated_at": {
-                    "type": "string",
-                    "example": "2024-03-20T15:04:05Z"
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "dto.PlanResponse": {
+        "dto.WebhookEndpointSecretResponse": {
             "type": "object",
             "properties": {
                 "created_at": {
@@ -2870,75 +21520,123 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
+                "environment_id": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "description": "EventTypes limits the endpoint to the listed events, all events when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "id": {
                     "type": "string"
                 },
-                "invoice_cadence": {
-                    "$ref": "#/definitions/types.InvoiceCadence"
+                "previous_secret_expires_at": {
+                    "type": "string"
                 },
-                "lookup_key": {
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_3f9a..."
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
                     "type": "string"
                 },
-                "name": {
+                "updated_at": {
                     "type": "string"
                 },
-                "prices": {
+                "updated_by": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.WriteOffInvoiceRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Customer went out of business"
+                }
+            }
+        },
+        "dto.WriteOffReportResponse": {
+            "type": "object",
+            "properties": {
+                "currencies": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.PriceResponse"
+                        "$ref": "#/definitions/dto.WriteOffTotal"
+                    }
+                },
+                "invoices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WrittenOffInvoice"
                     }
+                }
+            }
+        },
+        "dto.WriteOffTotal": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "invoices": {
+                    "description": "Invoices is the number of invoices written off",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.WrittenOffInvoice": {
+            "type": "object",
+            "properties": {
+                "amount_remaining": {
+                    "description": "AmountRemaining is the part of the amount written off still not paid, it\nis lower when payments were recovered after the write-off",
+                    "type": "string"
+                },
+                "amount_written_off": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/types.Status"
+                "customer_id": {
+                    "type": "string"
                 },
-                "tenant_id": {
+                "invoice_id": {
                     "type": "string"
                 },
-                "trial_period": {
-                    "type": "integer"
+                "invoice_number": {
+                    "type": "string"
                 },
-                "updated_at": {
+                "reason": {
                     "type": "string"
                 },
-                "updated_by": {
+                "written_off_at": {
                     "type": "string"
                 }
             }
         },
-        "dto.PriceResponse": {
+        "environment.Reset": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "description": "Amount stored in main currency units (e.g., dollars, not cents)\nFor USD: 12.50 means $12.50",
-                    "type": "number"
-                },
-                "billing_cadence": {
-                    "description": "BillingCadence is the billing cadence for the price ex RECURRING, ONETIME",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingCadence"
-                        }
-                    ]
-                },
-                "billing_model": {
-                    "description": "BillingModel is the billing model for the price ex FLAT_FEE, PACKAGE, TIERED",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingModel"
-                        }
-                    ]
-                },
-                "billing_period": {
-                    "description": "BillingPeriod is the billing period for the price ex month, year",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingPeriod"
-                        }
-                    ]
-                },
-                "billing_period_count": {
-                    "description": "BillingPeriodCount is the count of the billing period ex 1, 3, 6, 12",
-                    "type": "integer"
+                "completed_at": {
+                    "type": "string"
                 },
                 "created_at": {
                     "type": "string"
@@ -2946,149 +21644,190 @@ const docTemplate = `{
                 "created_by": {
                     "type": "string"
                 },
-                "currency": {
-                    "description": "Currency 3 digit ISO currency code in lowercase ex usd, eur, gbp",
+                "current_step": {
+                    "description": "CurrentStep is the step being executed or the last executed step",
                     "type": "string"
                 },
-                "description": {
-                    "description": "Description of the price",
+                "environment_id": {
                     "type": "string"
                 },
-                "display_amount": {
-                    "description": "DisplayAmount is the formatted amount with currency symbol\nFor USD: $12.50",
+                "error": {
                     "type": "string"
                 },
-                "filter_values": {
-                    "description": "FilterValues are the filter values for the price in case of usage based pricing",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/price.JSONBFilters"
-                        }
-                    ]
-                },
                 "id": {
-                    "description": "ID uuid identifier for the price",
-                    "type": "string"
-                },
-                "lookup_key": {
-                    "description": "LookupKey used for looking up the price in the database",
                     "type": "string"
                 },
-                "metadata": {
-                    "description": "Metadata is a jsonb field for additional information",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/price.JSONBMetadata"
-                        }
-                    ]
-                },
-                "meter_id": {
-                    "description": "MeterID is the id of the meter for usage based pricing",
-                    "type": "string"
+                "records_deleted": {
+                    "description": "RecordsDeleted is the number of rows deleted from Postgres",
+                    "type": "integer"
                 },
-                "plan_id": {
-                    "description": "PlanID is the id of the plan for plan based pricing",
-                    "type": "string"
+                "reset_status": {
+                    "$ref": "#/definitions/types.ResetStatus"
                 },
                 "status": {
                     "$ref": "#/definitions/types.Status"
                 },
+                "steps_completed": {
+                    "description": "StepsCompleted out of StepsTotal is the progress of the reset",
+                    "type": "integer"
+                },
+                "steps_total": {
+                    "type": "integer"
+                },
                 "tenant_id": {
                     "type": "string"
                 },
-                "tier_mode": {
-                    "description": "Tiered pricing fields when BillingModel is TIERED",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingTier"
-                        }
-                    ]
+                "updated_at": {
+                    "type": "string"
                 },
-                "tiers": {
-                    "description": "Tiers are the tiers for the price when BillingModel is TIERED",
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "eventschema.PropertyRule": {
+            "type": "object",
+            "properties": {
+                "allowed_values": {
+                    "description": "AllowedValues restricts the property to the listed values, compared with\nthe text form of the value ex \"3\" for the number 3. Any value is allowed\nwhen empty",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/price.PriceTier"
+                        "type": "string"
                     }
                 },
-                "transform_quantity": {
-                    "description": "Transform is the quantity transformation in case of PACKAGE billing model",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/price.JSONBTransformQuantity"
-                        }
-                    ]
+                "name": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
                 },
                 "type": {
-                    "description": "Type is the type of the price ex USAGE, FIXED",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.PriceType"
-                        }
-                    ]
+                    "$ref": "#/definitions/types.EventPropertyType"
+                }
+            }
+        },
+        "eventschema.Violation": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
                 },
-                "updated_at": {
+                "message": {
                     "type": "string"
                 },
-                "updated_by": {
+                "property": {
                     "type": "string"
                 }
             }
         },
-        "dto.SignUpRequest": {
+        "gin.H": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
+            "additionalProperties": {}
+        },
+        "github_com_flexprice_flexprice_internal_types.Permission": {
+            "type": "string",
+            "enum": [
+                "read",
+                "write",
+                "events:write",
+                "integrations:write",
+                "members:write"
+            ],
+            "x-enum-varnames": [
+                "PermissionRead",
+                "PermissionWrite",
+                "PermissionEventsWrite",
+                "PermissionIntegrationsWrite",
+                "PermissionMembersWrite"
+            ]
+        },
+        "github_com_flexprice_flexprice_internal_types.SubscriptionStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "paused",
+                "cancelled",
+                "incomplete",
+                "incomplete_expired",
+                "past_due",
+                "trialing",
+                "unpaid"
             ],
+            "x-enum-varnames": [
+                "SubscriptionStatusActive",
+                "SubscriptionStatusPaused",
+                "SubscriptionStatusCancelled",
+                "SubscriptionStatusIncomplete",
+                "SubscriptionStatusIncompleteExpired",
+                "SubscriptionStatusPastDue",
+                "SubscriptionStatusTrialing",
+                "SubscriptionStatusUnpaid"
+            ]
+        },
+        "graphql.Error": {
+            "type": "object",
             "properties": {
-                "email": {
+                "message": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 8
+                "path": {
+                    "type": "array",
+                    "items": {}
                 }
             }
         },
-        "dto.SubscriptionResponse": {
+        "graphql.Request": {
             "type": "object",
+            "required": [
+                "query"
+            ],
             "properties": {
-                "billing_anchor": {
-                    "description": "BillingAnchor is the reference point that aligns future billing cycle dates.\nIt sets the day of week for week intervals, the day of month for month and year intervals,\nand the month of year for year intervals. The timestamp is in UTC format.",
+                "operationName": {
                     "type": "string"
                 },
-                "billing_cadence": {
-                    "description": "BillingCadence is the cadence of the billing cycle.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingCadence"
-                        }
-                    ]
-                },
-                "billing_period": {
-                    "description": "BillingPeriod is the period of the billing cycle.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingPeriod"
-                        }
-                    ]
+                "query": {
+                    "type": "string"
                 },
-                "billing_period_count": {
-                    "description": "BillingPeriodCount is the total number units of the billing period.",
-                    "type": "integer"
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "graphql.Response": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "additionalProperties": true
                 },
-                "cancel_at": {
-                    "description": "CancelAt is the date the subscription will be canceled",
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graphql.Error"
+                    }
+                }
+            }
+        },
+        "invoice.DisputedLine": {
+            "type": "object",
+            "properties": {
+                "amount": {
                     "type": "string"
                 },
-                "cancel_at_period_end": {
-                    "description": "CancelAtPeriodEnd is whether the subscription was canceled at the end of the current period",
-                    "type": "boolean"
+                "line_item_id": {
+                    "type": "string"
                 },
-                "cancelled_at": {
-                    "description": "CanceledAt is the date the subscription was canceled",
+                "reason": {
                     "type": "string"
+                }
+            }
+        },
+        "invoice.InvoiceLineItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
                 },
                 "created_at": {
                     "type": "string"
@@ -3097,72 +21836,45 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "currency": {
-                    "description": "Currency is the currency of the subscription in lowercase 3 digit ISO codes",
-                    "type": "string"
-                },
-                "current_period_end": {
-                    "description": "CurrentPeriodEnd is the end of the current period that the subscription has been invoiced for.\nAt the end of this period, a new invoice will be created.",
-                    "type": "string"
-                },
-                "current_period_start": {
-                    "description": "CurrentPeriodStart is the end of the current period that the subscription has been invoiced for.\nAt the end of this period, a new invoice will be created.",
                     "type": "string"
                 },
                 "customer_id": {
-                    "description": "CustomerID is the identifier for the customer in our system",
                     "type": "string"
                 },
-                "end_date": {
-                    "description": "EndDate is the end date of the subscription",
+                "display_name": {
                     "type": "string"
                 },
                 "id": {
-                    "description": "ID is the unique identifier for the subscription",
                     "type": "string"
                 },
-                "invoice_cadence": {
-                    "description": "InvoiceCadence is the cadence of the invoice. This overrides the plan's invoice cadence.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.InvoiceCadence"
-                        }
-                    ]
+                "invoice_id": {
+                    "type": "string"
                 },
-                "lookup_key": {
-                    "description": "LookupKey is the key used to lookup the subscription in our system",
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                },
+                "meter_id": {
                     "type": "string"
                 },
-                "plan": {
-                    "$ref": "#/definitions/dto.PlanResponse"
+                "period_end": {
+                    "type": "string"
                 },
-                "plan_id": {
-                    "description": "PlanID is the identifier for the plan in our system",
+                "period_start": {
                     "type": "string"
                 },
-                "start_date": {
-                    "description": "StartDate is the start date of the subscription",
+                "price_id": {
                     "type": "string"
                 },
+                "quantity": {
+                    "type": "number"
+                },
                 "status": {
                     "$ref": "#/definitions/types.Status"
                 },
-                "subscription_status": {
-                    "description": "Status is the status of the subscription",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.SubscriptionStatus"
-                        }
-                    ]
-                },
-                "tenant_id": {
-                    "type": "string"
-                },
-                "trial_end": {
-                    "description": "TrialEnd is the end date of the trial period",
+                "subscription_id": {
                     "type": "string"
                 },
-                "trial_start": {
-                    "description": "TrialStart is the start date of the trial period",
+                "tenant_id": {
                     "type": "string"
                 },
                 "updated_at": {
@@ -3173,218 +21885,249 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.SubscriptionUsageByMetersResponse": {
+        "job.Run": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "number"
+                "error": {
+                    "description": "Error holds the failure reason of a failed run",
+                    "type": "string"
                 },
-                "currency": {
+                "finished_at": {
                     "type": "string"
                 },
-                "display_amount": {
+                "id": {
                     "type": "string"
                 },
-                "filter_values": {
-                    "$ref": "#/definitions/price.JSONBFilters"
+                "job_name": {
+                    "type": "string"
                 },
-                "meter_display_name": {
+                "started_at": {
                     "type": "string"
                 },
-                "price": {
-                    "$ref": "#/definitions/price.Price"
+                "status": {
+                    "$ref": "#/definitions/types.JobRunStatus"
                 },
-                "quantity": {
-                    "type": "number"
+                "trigger": {
+                    "$ref": "#/definitions/types.JobTrigger"
+                },
+                "triggered_by": {
+                    "description": "TriggeredBy is the user who triggered a manual run",
+                    "type": "string"
                 }
             }
         },
-        "dto.TopUpWalletRequest": {
+        "kafka.PartitionLag": {
             "type": "object",
-            "required": [
-                "amount"
-            ],
             "properties": {
-                "amount": {
-                    "type": "number"
+                "committed_offset": {
+                    "description": "CommittedOffset is the next offset the group consumes, -1 when the group\nhas not committed an offset of the partition yet",
+                    "type": "integer"
                 },
-                "description": {
-                    "type": "string"
+                "high_watermark": {
+                    "type": "integer"
                 },
-                "metadata": {
-                    "$ref": "#/definitions/types.Metadata"
+                "lag": {
+                    "type": "integer"
+                },
+                "partition": {
+                    "type": "integer"
                 }
             }
         },
-        "dto.UpdateCustomerRequest": {
+        "legalentity.BankDetails": {
             "type": "object",
             "properties": {
-                "email": {
+                "account_holder": {
                     "type": "string"
                 },
-                "external_id": {
+                "account_number": {
                     "type": "string"
                 },
-                "name": {
+                "bank_name": {
+                    "type": "string"
+                },
+                "bic": {
+                    "type": "string"
+                },
+                "iban": {
+                    "type": "string"
+                },
+                "routing_number": {
                     "type": "string"
                 }
             }
         },
-        "dto.UpdatePlanPriceRequest": {
+        "meter.Aggregation": {
             "type": "object",
-            "required": [
-                "amount",
-                "billing_cadence",
-                "billing_model",
-                "billing_period",
-                "billing_period_count",
-                "currency",
-                "type"
-            ],
             "properties": {
-                "amount": {
+                "field": {
+                    "description": "Field is the key in $event.properties on which the aggregation is to be applied\nFor ex if the aggregation type is sum for API usage, the field could be \"duration_ms\"",
                     "type": "string"
                 },
-                "billing_cadence": {
-                    "$ref": "#/definitions/types.BillingCadence"
-                },
-                "billing_model": {
-                    "$ref": "#/definitions/types.BillingModel"
-                },
-                "billing_period": {
-                    "$ref": "#/definitions/types.BillingPeriod"
-                },
-                "billing_period_count": {
-                    "type": "integer",
-                    "minimum": 1
-                },
-                "currency": {
-                    "type": "string"
+                "percentile": {
+                    "description": "Percentile applies to PERCENTILE only, it is the percentile of the values\nof the field the usage is, greater than 0 and up to 100. For ex 99 for\nthe p99 latency",
+                    "type": "number"
                 },
-                "description": {
-                    "type": "string"
+                "precision": {
+                    "description": "Precision applies to COUNT_UNIQUE only. Zero counts the distinct values\nexactly, which takes memory in proportion to their number. From 12 to 20\nthey are counted approximately with a HyperLogLog sketch of that\nprecision, for high cardinality fields like device IDs",
+                    "type": "integer"
                 },
-                "filter_values": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
+                "type": {
+                    "description": "Type is the type of aggregation to be applied on the events\nFor ex sum, count, avg, max, min etc",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.AggregationType"
                         }
-                    }
-                },
-                "id": {
-                    "description": "The ID of the price to update (present if the price is being updated)",
+                    ]
+                }
+            }
+        },
+        "meter.Filter": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "description": "Key is the key for the filter from $event.properties\nCurrently we support only first level keys in the properties and not nested keys",
                     "type": "string"
                 },
-                "lookup_key": {
+                "values": {
+                    "description": "Values are the possible values for the filter to be considered for the meter\nFor ex \"model_name\" could have values \"o1-mini\", \"gpt-4o\" etc",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "meter.Formula": {
+            "type": "object",
+            "properties": {
+                "expression": {
+                    "description": "Expression supports numbers, variables, + - * / and parentheses",
                     "type": "string"
                 },
-                "metadata": {
+                "variables": {
+                    "description": "Variables maps each variable of the expression to the ID of the meter\nwhose usage it stands for",
                     "type": "object",
                     "additionalProperties": {
                         "type": "string"
                     }
-                },
-                "meter_id": {
-                    "type": "string"
-                },
-                "plan_id": {
-                    "type": "string"
-                },
-                "tier_mode": {
-                    "$ref": "#/definitions/types.BillingTier"
-                },
-                "tiers": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.CreatePriceTier"
-                    }
-                },
-                "transform_quantity": {
-                    "$ref": "#/definitions/price.TransformQuantity"
-                },
-                "type": {
-                    "$ref": "#/definitions/types.PriceType"
                 }
             }
         },
-        "dto.UpdatePlanRequest": {
+        "plan.Plan": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt is when the plan was deleted, it can be restored until it is purged",
+                    "type": "string"
+                },
                 "description": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "string"
+                },
                 "invoice_cadence": {
                     "$ref": "#/definitions/types.InvoiceCadence"
                 },
                 "lookup_key": {
                     "type": "string"
                 },
+                "metadata": {
+                    "$ref": "#/definitions/types.Metadata"
+                },
                 "name": {
                     "type": "string"
                 },
-                "prices": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.UpdatePlanPriceRequest"
-                    }
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "tenant_id": {
+                    "type": "string"
                 },
                 "trial_period": {
                     "type": "integer"
-                }
-            }
-        },
-        "dto.UpdatePriceRequest": {
-            "type": "object",
-            "properties": {
-                "description": {
+                },
+                "updated_at": {
                     "type": "string"
                 },
-                "lookup_key": {
+                "updated_by": {
                     "type": "string"
                 },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "version": {
+                    "description": "Version is incremented on every update and guards against concurrent writes",
+                    "type": "integer"
                 }
             }
         },
-        "dto.UsageResult": {
+        "price.JSONBFilters": {
             "type": "object",
-            "properties": {
-                "value": {
-                    "type": "number"
-                },
-                "window_size": {
+            "additionalProperties": {
+                "type": "array",
+                "items": {
                     "type": "string"
                 }
             }
         },
-        "dto.UserResponse": {
+        "price.JSONBMetadata": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "price.JSONBTransformQuantity": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
+                "divide_by": {
+                    "description": "Divide quantity by this number",
+                    "type": "integer"
                 },
-                "id": {
+                "round": {
+                    "description": "up or down",
                     "type": "string"
                 }
             }
         },
-        "dto.WalletBalanceResponse": {
+        "price.Price": {
             "type": "object",
             "properties": {
-                "balance": {
+                "amount": {
+                    "description": "Amount stored in main currency units (e.g., dollars, not cents)\nFor USD: 12.50 means $12.50",
                     "type": "number"
                 },
-                "balance_updated_at": {
-                    "type": "string"
+                "billing_cadence": {
+                    "description": "BillingCadence is the billing cadence for the price ex RECURRING, ONETIME",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingCadence"
+                        }
+                    ]
+                },
+                "billing_model": {
+                    "description": "BillingModel is the billing model for the price ex FLAT_FEE, PACKAGE, TIERED",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingModel"
+                        }
+                    ]
+                },
+                "billing_period": {
+                    "description": "BillingPeriod is the billing period for the price ex month, year",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingPeriod"
+                        }
+                    ]
+                },
+                "billing_period_count": {
+                    "description": "BillingPeriodCount is the count of the billing period ex 1, 3, 6, 12",
+                    "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
@@ -3393,22 +22136,52 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "currency": {
+                    "description": "Currency 3 digit ISO currency code in lowercase ex usd, eur, gbp",
                     "type": "string"
                 },
-                "customer_id": {
+                "deleted_at": {
+                    "description": "DeletedAt is when the price was deleted, it can be restored until it is purged",
                     "type": "string"
                 },
                 "description": {
+                    "description": "Description of the price",
+                    "type": "string"
+                },
+                "display_amount": {
+                    "description": "DisplayAmount is the formatted amount with currency symbol\nFor USD: $12.50",
                     "type": "string"
                 },
+                "filter_values": {
+                    "description": "FilterValues are the filter values for the price in case of usage based pricing",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/price.JSONBFilters"
+                        }
+                    ]
+                },
                 "id": {
+                    "description": "ID uuid identifier for the price",
+                    "type": "string"
+                },
+                "lookup_key": {
+                    "description": "LookupKey used for looking up the price in the database",
                     "type": "string"
                 },
                 "metadata": {
-                    "$ref": "#/definitions/types.Metadata"
+                    "description": "Metadata is a jsonb field for additional information",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/price.JSONBMetadata"
+                        }
+                    ]
                 },
-                "real_time_balance": {
-                    "type": "number"
+                "meter_id": {
+                    "description": "MeterID is the id of the meter for usage based pricing",
+                    "type": "string"
+                },
+                "plan_id": {
+                    "description": "PlanID is the id of the plan for plan based pricing",
+                    "type": "string"
                 },
                 "status": {
                     "$ref": "#/definitions/types.Status"
@@ -3416,142 +22189,192 @@ const docTemplate = `{
                 "tenant_id": {
                     "type": "string"
                 },
+                "tier_mode": {
+                    "description": "Tiered pricing fields when BillingModel is TIERED",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BillingTier"
+                        }
+                    ]
+                },
+                "tiers": {
+                    "description": "Tiers are the tiers for the price when BillingModel is TIERED",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/price.PriceTier"
+                    }
+                },
+                "transform_quantity": {
+                    "description": "Transform is the quantity transformation in case of PACKAGE billing model",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/price.JSONBTransformQuantity"
+                        }
+                    ]
+                },
+                "type": {
+                    "description": "Type is the type of the price ex USAGE, FIXED",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.PriceType"
+                        }
+                    ]
+                },
+                "unit_cost": {
+                    "description": "UnitCost is the cost of goods sold of one unit billed by the price, in the\ncurrency of the price. For usage prices it is the cost of one unit of the\nusage of the meter",
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 },
                 "updated_by": {
                     "type": "string"
-                },
-                "wallet_status": {
-                    "$ref": "#/definitions/types.WalletStatus"
                 }
             }
         },
-        "dto.WalletResponse": {
+        "price.PriceTier": {
             "type": "object",
             "properties": {
-                "balance": {
+                "flat_amount": {
+                    "description": "FlatAmount is the flat amount for the given tier and it is applied\non top of the unit amount*quantity. It solves cases in banking like 2.7% + 5c",
                     "type": "number"
                 },
-                "created_at": {
-                    "type": "string"
-                },
-                "currency": {
-                    "type": "string"
-                },
-                "customer_id": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
+                "unit_amount": {
+                    "description": "UnitAmount is the amount per unit for the given tier",
+                    "type": "number"
                 },
-                "metadata": {
-                    "$ref": "#/definitions/types.Metadata"
+                "up_to": {
+                    "description": "Upto is the quantity up to which this tier applies. It is null for the last tier",
+                    "type": "integer"
+                }
+            }
+        },
+        "price.TransformQuantity": {
+            "type": "object",
+            "properties": {
+                "divide_by": {
+                    "description": "Divide quantity by this number",
+                    "type": "integer"
                 },
-                "updated_at": {
+                "round": {
+                    "description": "up or down",
                     "type": "string"
-                },
-                "wallet_status": {
-                    "$ref": "#/definitions/types.WalletStatus"
                 }
             }
         },
-        "dto.WalletTransactionResponse": {
+        "ratecard.Override": {
             "type": "object",
             "properties": {
                 "amount": {
-                    "type": "number"
-                },
-                "balance_after": {
-                    "type": "number"
-                },
-                "balance_before": {
-                    "type": "number"
-                },
-                "created_at": {
                     "type": "string"
                 },
-                "description": {
+                "meter_id": {
                     "type": "string"
                 },
-                "id": {
+                "price_id": {
                     "type": "string"
                 },
-                "metadata": {
-                    "$ref": "#/definitions/types.Metadata"
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/price.PriceTier"
+                    }
+                }
+            }
+        },
+        "report.Column": {
+            "type": "object",
+            "properties": {
+                "aggregate": {
+                    "$ref": "#/definitions/types.ReportAggregate"
                 },
-                "reference_id": {
+                "field": {
                     "type": "string"
-                },
-                "reference_type": {
+                }
+            }
+        },
+        "report.Destination": {
+            "type": "object",
+            "properties": {
+                "prefix": {
+                    "description": "Prefix is the key prefix of s3 destinations, under the folder of the\ntenant in the export bucket",
                     "type": "string"
                 },
-                "transaction_status": {
-                    "$ref": "#/definitions/types.TransactionStatus"
+                "recipients": {
+                    "description": "Recipients are the email addresses of email destinations",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
                 "type": {
-                    "type": "string"
-                },
-                "wallet_id": {
-                    "type": "string"
+                    "$ref": "#/definitions/types.ReportDestinationType"
                 }
             }
         },
-        "dto.WalletTransactionsResponse": {
+        "report.Filter": {
             "type": "object",
             "properties": {
-                "filter": {
-                    "$ref": "#/definitions/types.Filter"
+                "field": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "operator": {
+                    "$ref": "#/definitions/types.ReportFilterOperator"
                 },
-                "transactions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.WalletTransactionResponse"
-                    }
+                "value": {
+                    "type": "string"
                 }
             }
         },
-        "gin.H": {
+        "sso.GroupRoles": {
             "type": "object",
-            "additionalProperties": {}
+            "additionalProperties": {
+                "$ref": "#/definitions/types.Role"
+            }
         },
-        "meter.Aggregation": {
+        "sso.OIDCSettings": {
             "type": "object",
+            "required": [
+                "client_id",
+                "issuer_url"
+            ],
             "properties": {
-                "field": {
-                    "description": "Field is the key in $event.properties on which the aggregation is to be applied\nFor ex if the aggregation type is sum for API usage, the field could be \"duration_ms\"",
+                "client_id": {
                     "type": "string"
                 },
-                "type": {
-                    "description": "Type is the type of aggregation to be applied on the events\nFor ex sum, count, avg, max, min etc",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.AggregationType"
-                        }
-                    ]
+                "issuer_url": {
+                    "description": "IssuerURL is where the provider configuration is discovered ex https://acme.okta.com",
+                    "type": "string"
                 }
             }
         },
-        "meter.Filter": {
+        "sso.SAMLSettings": {
             "type": "object",
+            "required": [
+                "certificate",
+                "entity_id",
+                "sso_url"
+            ],
             "properties": {
-                "key": {
-                    "description": "Key is the key for the filter from $event.properties\nCurrently we support only first level keys in the properties and not nested keys",
+                "certificate": {
+                    "description": "Certificate is the PEM certificate the responses are signed with",
                     "type": "string"
                 },
-                "values": {
-                    "description": "Values are the possible values for the filter to be considered for the meter\nFor ex \"model_name\" could have values \"o1-mini\", \"gpt-4o\" etc",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "email_attribute": {
+                    "description": "EmailAttribute is the attribute holding the email of the user. The\nNameID is used when empty",
+                    "type": "string"
+                },
+                "entity_id": {
+                    "description": "EntityID is the issuer of the assertions of the identity provider",
+                    "type": "string"
+                },
+                "sso_url": {
+                    "description": "SSOURL is the HTTP-Redirect single sign on endpoint of the identity provider",
+                    "type": "string"
                 }
             }
         },
-        "plan.Plan": {
+        "subscription.LineItem": {
             "type": "object",
             "properties": {
                 "created_at": {
@@ -3560,100 +22383,45 @@ const docTemplate = `{
                 "created_by": {
                     "type": "string"
                 },
-                "description": {
-                    "type": "string"
-                },
                 "id": {
                     "type": "string"
                 },
-                "invoice_cadence": {
-                    "$ref": "#/definitions/types.InvoiceCadence"
-                },
-                "lookup_key": {
+                "price_id": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
+                "quantity": {
+                    "description": "Quantity is the number of times the price is charged per period",
+                    "type": "number"
                 },
                 "status": {
                     "$ref": "#/definitions/types.Status"
                 },
-                "tenant_id": {
+                "subscription_id": {
                     "type": "string"
                 },
-                "trial_period": {
-                    "type": "integer"
-                },
-                "updated_at": {
+                "tenant_id": {
                     "type": "string"
                 },
-                "updated_by": {
-                    "type": "string"
-                }
-            }
-        },
-        "price.JSONBFilters": {
-            "type": "object",
-            "additionalProperties": {
-                "type": "array",
-                "items": {
-                    "type": "string"
-                }
-            }
-        },
-        "price.JSONBMetadata": {
-            "type": "object",
-            "additionalProperties": {
-                "type": "string"
-            }
-        },
-        "price.JSONBTransformQuantity": {
-            "type": "object",
-            "properties": {
-                "divide_by": {
-                    "description": "Divide quantity by this number",
-                    "type": "integer"
+                "unit_amount": {
+                    "description": "UnitAmount overrides the amount of the price, and of any rate card, when set",
+                    "type": "number"
                 },
-                "round": {
-                    "description": "up or down",
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
                     "type": "string"
                 }
             }
         },
-        "price.Price": {
+        "subscription.Proration": {
             "type": "object",
             "properties": {
                 "amount": {
-                    "description": "Amount stored in main currency units (e.g., dollars, not cents)\nFor USD: 12.50 means $12.50",
                     "type": "number"
                 },
-                "billing_cadence": {
-                    "description": "BillingCadence is the billing cadence for the price ex RECURRING, ONETIME",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingCadence"
-                        }
-                    ]
-                },
-                "billing_model": {
-                    "description": "BillingModel is the billing model for the price ex FLAT_FEE, PACKAGE, TIERED",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingModel"
-                        }
-                    ]
-                },
-                "billing_period": {
-                    "description": "BillingPeriod is the billing period for the price ex month, year",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingPeriod"
-                        }
-                    ]
-                },
-                "billing_period_count": {
-                    "description": "BillingPeriodCount is the count of the billing period ex 1, 3, 6, 12",
-                    "type": "integer"
+                "changed_at": {
+                    "type": "string"
                 },
                 "created_at": {
                     "type": "string"
@@ -3662,244 +22430,1024 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "currency": {
-                    "description": "Currency 3 digit ISO currency code in lowercase ex usd, eur, gbp",
                     "type": "string"
                 },
-                "description": {
-                    "description": "Description of the price",
+                "display_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invoice_id": {
+                    "description": "InvoiceID is the invoice the proration was billed on, empty until then",
+                    "type": "string"
+                },
+                "line_item_id": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "description": "PeriodStart and ChangedAt bound the part of the period the proration is for",
+                    "type": "string"
+                },
+                "price_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.Status"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.APIKeyScope": {
+            "type": "string",
+            "enum": [
+                "read_only",
+                "events",
+                "billing_admin"
+            ],
+            "x-enum-varnames": [
+                "APIKeyScopeReadOnly",
+                "APIKeyScopeEvents",
+                "APIKeyScopeBillingAdmin"
+            ]
+        },
+        "types.ActivityType": {
+            "type": "string",
+            "enum": [
+                "subscription.created",
+                "subscription.cancelled",
+                "invoice.created",
+                "invoice.finalized",
+                "wallet.created",
+                "wallet.credited",
+                "wallet.debited"
+            ],
+            "x-enum-varnames": [
+                "ActivityTypeSubscriptionCreated",
+                "ActivityTypeSubscriptionCancelled",
+                "ActivityTypeInvoiceCreated",
+                "ActivityTypeInvoiceFinalized",
+                "ActivityTypeWalletCreated",
+                "ActivityTypeWalletCredited",
+                "ActivityTypeWalletDebited"
+            ]
+        },
+        "types.AggregationType": {
+            "type": "string",
+            "enum": [
+                "COUNT",
+                "SUM",
+                "AVG",
+                "COUNT_UNIQUE",
+                "PERCENTILE",
+                "FORMULA"
+            ],
+            "x-enum-varnames": [
+                "AggregationCount",
+                "AggregationSum",
+                "AggregationAvg",
+                "AggregationCountUnique",
+                "AggregationPercentile",
+                "AggregationFormula"
+            ]
+        },
+        "types.AnomalyType": {
+            "type": "string",
+            "enum": [
+                "spike",
+                "drop"
+            ],
+            "x-enum-varnames": [
+                "AnomalyTypeSpike",
+                "AnomalyTypeDrop"
+            ]
+        },
+        "types.AuditAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "restore"
+            ],
+            "x-enum-varnames": [
+                "AuditActionCreate",
+                "AuditActionUpdate",
+                "AuditActionDelete",
+                "AuditActionRestore"
+            ]
+        },
+        "types.AuditActorType": {
+            "type": "string",
+            "enum": [
+                "user",
+                "api_key",
+                "system"
+            ],
+            "x-enum-varnames": [
+                "AuditActorTypeUser",
+                "AuditActorTypeAPIKey",
+                "AuditActorTypeSystem"
+            ]
+        },
+        "types.AuditEntityType": {
+            "type": "string",
+            "enum": [
+                "customer",
+                "plan",
+                "price",
+                "meter",
+                "rate_card",
+                "price_book",
+                "subscription",
+                "subscription_line_item",
+                "invoice",
+                "invoice_line_item",
+                "invoice_numbering",
+                "invoice_payment",
+                "refund",
+                "invoice_dispute",
+                "credit_allocation",
+                "wallet",
+                "payment_method",
+                "mandate",
+                "api_key",
+                "connection",
+                "webhook_endpoint",
+                "cancellation_reason",
+                "role_assignment",
+                "sso_config",
+                "event",
+                "event_schema",
+                "budget",
+                "legal_entity"
+            ],
+            "x-enum-varnames": [
+                "AuditEntityTypeCustomer",
+                "AuditEntityTypePlan",
+                "AuditEntityTypePrice",
+                "AuditEntityTypeMeter",
+                "AuditEntityTypeRateCard",
+                "AuditEntityTypePriceBook",
+                "AuditEntityTypeSubscription",
+                "AuditEntityTypeSubscriptionItem",
+                "AuditEntityTypeInvoice",
+                "AuditEntityTypeInvoiceLineItem",
+                "AuditEntityTypeInvoiceNumbering",
+                "AuditEntityTypeInvoicePayment",
+                "AuditEntityTypeRefund",
+                "AuditEntityTypeInvoiceDispute",
+                "AuditEntityTypeCreditAllocation",
+                "AuditEntityTypeWallet",
+                "AuditEntityTypePaymentMethod",
+                "AuditEntityTypeMandate",
+                "AuditEntityTypeAPIKey",
+                "AuditEntityTypeConnection",
+                "AuditEntityTypeWebhookEndpoint",
+                "AuditEntityTypeCancellationReason",
+                "AuditEntityTypeRoleAssignment",
+                "AuditEntityTypeSSOConfig",
+                "AuditEntityTypeEvent",
+                "AuditEntityTypeEventSchema",
+                "AuditEntityTypeBudget",
+                "AuditEntityTypeLegalEntity"
+            ]
+        },
+        "types.BillingCadence": {
+            "type": "string",
+            "enum": [
+                "RECURRING",
+                "ONETIME"
+            ],
+            "x-enum-varnames": [
+                "BILLING_CADENCE_RECURRING",
+                "BILLING_CADENCE_ONETIME"
+            ]
+        },
+        "types.BillingCycle": {
+            "type": "string",
+            "enum": [
+                "anniversary",
+                "calendar"
+            ],
+            "x-enum-varnames": [
+                "BillingCycleAnniversary",
+                "BillingCycleCalendar"
+            ]
+        },
+        "types.BillingModel": {
+            "type": "string",
+            "enum": [
+                "FLAT_FEE",
+                "PACKAGE",
+                "TIERED"
+            ],
+            "x-enum-varnames": [
+                "BILLING_MODEL_FLAT_FEE",
+                "BILLING_MODEL_PACKAGE",
+                "BILLING_MODEL_TIERED"
+            ]
+        },
+        "types.BillingPeriod": {
+            "type": "string",
+            "enum": [
+                "MONTHLY",
+                "ANNUAL",
+                "WEEKLY",
+                "DAILY"
+            ],
+            "x-enum-varnames": [
+                "BILLING_PERIOD_MONTHLY",
+                "BILLING_PERIOD_ANNUAL",
+                "BILLING_PERIOD_WEEKLY",
+                "BILLING_PERIOD_DAILY"
+            ]
+        },
+        "types.BillingTier": {
+            "type": "string",
+            "enum": [
+                "VOLUME",
+                "SLAB"
+            ],
+            "x-enum-varnames": [
+                "BILLING_TIER_VOLUME",
+                "BILLING_TIER_SLAB"
+            ]
+        },
+        "types.BudgetAction": {
+            "type": "string",
+            "enum": [
+                "alert",
+                "block_usage",
+                "pause_subscription"
+            ],
+            "x-enum-varnames": [
+                "BudgetActionAlert",
+                "BudgetActionBlockUsage",
+                "BudgetActionPauseSubscription"
+            ]
+        },
+        "types.BudgetPeriod": {
+            "type": "string",
+            "enum": [
+                "monthly",
+                "billing_period"
+            ],
+            "x-enum-varnames": [
+                "BudgetPeriodMonthly",
+                "BudgetPeriodBillingPeriod"
+            ]
+        },
+        "types.CRMSubscriptionObject": {
+            "type": "string",
+            "enum": [
+                "Opportunity",
+                "Contract"
+            ],
+            "x-enum-varnames": [
+                "CRMSubscriptionObjectOpportunity",
+                "CRMSubscriptionObjectContract"
+            ]
+        },
+        "types.CreditAllocationTarget": {
+            "type": "string",
+            "enum": [
+                "invoice",
+                "wallet",
+                "refund"
+            ],
+            "x-enum-varnames": [
+                "CreditAllocationTargetInvoice",
+                "CreditAllocationTargetWallet",
+                "CreditAllocationTargetRefund"
+            ]
+        },
+        "types.DeadLetterStatus": {
+            "type": "string",
+            "enum": [
+                "failed",
+                "replayed"
+            ],
+            "x-enum-varnames": [
+                "DeadLetterStatusFailed",
+                "DeadLetterStatusReplayed"
+            ]
+        },
+        "types.DisputeResolution": {
+            "type": "string",
+            "enum": [
+                "credit_note",
+                "reissue",
+                "uphold"
+            ],
+            "x-enum-varnames": [
+                "DisputeResolutionCreditNote",
+                "DisputeResolutionReissue",
+                "DisputeResolutionUphold"
+            ]
+        },
+        "types.DisputeStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "under_review",
+                "resolved"
+            ],
+            "x-enum-varnames": [
+                "DisputeStatusOpen",
+                "DisputeStatusUnderReview",
+                "DisputeStatusResolved"
+            ]
+        },
+        "types.DunningAction": {
+            "type": "string",
+            "enum": [
+                "retry",
+                "update_payment_method",
+                "new_mandate",
+                "contact_customer",
+                "manual_review"
+            ],
+            "x-enum-varnames": [
+                "DunningActionRetry",
+                "DunningActionUpdatePaymentMethod",
+                "DunningActionNewMandate",
+                "DunningActionContactCustomer",
+                "DunningActionManualReview"
+            ]
+        },
+        "types.EmailDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "sent",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "EmailDeliveryStatusPending",
+                "EmailDeliveryStatusSent",
+                "EmailDeliveryStatusFailed"
+            ]
+        },
+        "types.EmailProvider": {
+            "type": "string",
+            "enum": [
+                "smtp",
+                "ses",
+                "sendgrid"
+            ],
+            "x-enum-varnames": [
+                "EmailProviderSMTP",
+                "EmailProviderSES",
+                "EmailProviderSendGrid"
+            ]
+        },
+        "types.EmailTemplateType": {
+            "type": "string",
+            "enum": [
+                "invoice_finalized",
+                "trial_will_end",
+                "report_ready",
+                "debit_pre_notification"
+            ],
+            "x-enum-varnames": [
+                "EmailTemplateInvoiceFinalized",
+                "EmailTemplateTrialWillEnd",
+                "EmailTemplateReportReady",
+                "EmailTemplateDebitPreNotification"
+            ]
+        },
+        "types.EnvironmentType": {
+            "type": "string",
+            "enum": [
+                "PRODUCTION",
+                "SANDBOX",
+                "DEVELOPMENT"
+            ],
+            "x-enum-varnames": [
+                "EnvironmentTypeProduction",
+                "EnvironmentTypeSandbox",
+                "EnvironmentTypeDevelopment"
+            ]
+        },
+        "types.EventPropertyType": {
+            "type": "string",
+            "enum": [
+                "string",
+                "number",
+                "boolean",
+                "object",
+                "array"
+            ],
+            "x-enum-varnames": [
+                "EventPropertyTypeString",
+                "EventPropertyTypeNumber",
+                "EventPropertyTypeBoolean",
+                "EventPropertyTypeObject",
+                "EventPropertyTypeArray"
+            ]
+        },
+        "types.ExportFormat": {
+            "type": "string",
+            "enum": [
+                "PARQUET",
+                "CSV"
+            ],
+            "x-enum-varnames": [
+                "ExportFormatParquet",
+                "ExportFormatCSV"
+            ]
+        },
+        "types.ExportStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "processing",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ExportStatusPending",
+                "ExportStatusProcessing",
+                "ExportStatusCompleted",
+                "ExportStatusFailed"
+            ]
+        },
+        "types.ExportType": {
+            "type": "string",
+            "enum": [
+                "EVENTS",
+                "USAGE"
+            ],
+            "x-enum-varnames": [
+                "ExportTypeEvents",
+                "ExportTypeUsage"
+            ]
+        },
+        "types.FeatureFlag": {
+            "type": "string",
+            "enum": [
+                "threshold_billing",
+                "mid_period_proration",
+                "rollup_usage_queries",
+                "period_renewal"
+            ],
+            "x-enum-varnames": [
+                "FeatureFlagThresholdBilling",
+                "FeatureFlagMidPeriodProration",
+                "FeatureFlagRollupUsageQueries",
+                "FeatureFlagPeriodRenewal"
+            ]
+        },
+        "types.Filter": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "description": "Cursor is the next_cursor of the previous page and implies cursor pagination",
                     "type": "string"
                 },
-                "display_amount": {
-                    "description": "DisplayAmount is the formatted amount with currency symbol\nFor USD: $12.50",
-                    "type": "string"
+                "include_deleted": {
+                    "description": "IncludeDeleted lists the deleted records along with the published ones,\nfor the resources which can be restored",
+                    "type": "boolean"
                 },
-                "filter_values": {
-                    "description": "FilterValues are the filter values for the price in case of usage based pricing",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/price.JSONBFilters"
-                        }
-                    ]
+                "limit": {
+                    "type": "integer"
                 },
-                "id": {
-                    "description": "ID uuid identifier for the price",
-                    "type": "string"
+                "offset": {
+                    "type": "integer"
                 },
-                "lookup_key": {
-                    "description": "LookupKey used for looking up the price in the database",
+                "order": {
                     "type": "string"
                 },
-                "metadata": {
-                    "description": "Metadata is a jsonb field for additional information",
+                "pagination": {
+                    "description": "Pagination opts into cursor pagination, offset is ignored in that mode",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/price.JSONBMetadata"
+                            "$ref": "#/definitions/types.PaginationMode"
                         }
                     ]
                 },
-                "meter_id": {
-                    "description": "MeterID is the id of the meter for usage based pricing",
-                    "type": "string"
-                },
-                "plan_id": {
-                    "description": "PlanID is the id of the plan for plan based pricing",
+                "sort": {
                     "type": "string"
                 },
                 "status": {
                     "$ref": "#/definitions/types.Status"
-                },
-                "tenant_id": {
-                    "type": "string"
-                },
-                "tier_mode": {
-                    "description": "Tiered pricing fields when BillingModel is TIERED",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.BillingTier"
-                        }
-                    ]
-                },
-                "tiers": {
-                    "description": "Tiers are the tiers for the price when BillingModel is TIERED",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/price.PriceTier"
-                    }
-                },
-                "transform_quantity": {
-                    "description": "Transform is the quantity transformation in case of PACKAGE billing model",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/price.JSONBTransformQuantity"
-                        }
-                    ]
-                },
-                "type": {
-                    "description": "Type is the type of the price ex USAGE, FIXED",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/types.PriceType"
-                        }
-                    ]
-                },
-                "updated_at": {
-                    "type": "string"
-                },
-                "updated_by": {
-                    "type": "string"
                 }
             }
         },
-        "price.PriceTier": {
+        "types.ForecastModel": {
+            "type": "string",
+            "enum": [
+                "linear",
+                "seasonal"
+            ],
+            "x-enum-varnames": [
+                "ForecastModelLinear",
+                "ForecastModelSeasonal"
+            ]
+        },
+        "types.IntegrationProvider": {
+            "type": "string",
+            "enum": [
+                "stripe",
+                "hubspot",
+                "netsuite",
+                "quickbooks",
+                "salesforce",
+                "adyen",
+                "razorpay",
+                "avalara",
+                "taxjar"
+            ],
+            "x-enum-varnames": [
+                "IntegrationProviderStripe",
+                "IntegrationProviderHubSpot",
+                "IntegrationProviderNetSuite",
+                "IntegrationProviderQuickBooks",
+                "IntegrationProviderSalesforce",
+                "IntegrationProviderAdyen",
+                "IntegrationProviderRazorpay",
+                "IntegrationProviderAvalara",
+                "IntegrationProviderTaxJar"
+            ]
+        },
+        "types.InvalidEventAction": {
+            "type": "string",
+            "enum": [
+                "reject",
+                "quarantine"
+            ],
+            "x-enum-varnames": [
+                "InvalidEventActionReject",
+                "InvalidEventActionQuarantine"
+            ]
+        },
+        "types.InvoiceCadence": {
+            "type": "string",
+            "enum": [
+                "ARREAR",
+                "ADVANCE"
+            ],
+            "x-enum-varnames": [
+                "InvoiceCadenceArrear",
+                "InvoiceCadenceAdvance"
+            ]
+        },
+        "types.InvoiceFlow": {
+            "type": "string",
+            "enum": [
+                "PERIOD_END",
+                "THRESHOLD"
+            ],
+            "x-enum-varnames": [
+                "InvoiceFlowPeriodEnd",
+                "InvoiceFlowThreshold"
+            ]
+        },
+        "types.InvoicePaymentStatus": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "PARTIALLY_PAID",
+                "PAID",
+                "PROCESSING",
+                "UNCOLLECTIBLE"
+            ],
+            "x-enum-varnames": [
+                "InvoicePaymentStatusPending",
+                "InvoicePaymentStatusPartiallyPaid",
+                "InvoicePaymentStatusPaid",
+                "InvoicePaymentStatusProcessing",
+                "InvoicePaymentStatusUncollectible"
+            ]
+        },
+        "types.InvoiceStatus": {
+            "type": "string",
+            "enum": [
+                "DRAFT",
+                "FINALIZED",
+                "VOIDED",
+                "HELD"
+            ],
+            "x-enum-varnames": [
+                "InvoiceStatusDraft",
+                "InvoiceStatusFinalized",
+                "InvoiceStatusVoided",
+                "InvoiceStatusHeld"
+            ]
+        },
+        "types.InvoiceType": {
+            "type": "string",
+            "enum": [
+                "SUBSCRIPTION",
+                "ONE_OFF",
+                "CREDIT"
+            ],
+            "x-enum-varnames": [
+                "InvoiceTypeSubscription",
+                "InvoiceTypeOneOff",
+                "InvoiceTypeCredit"
+            ]
+        },
+        "types.JobRunStatus": {
+            "type": "string",
+            "enum": [
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "JobRunStatusRunning",
+                "JobRunStatusSucceeded",
+                "JobRunStatusFailed"
+            ]
+        },
+        "types.JobTrigger": {
+            "type": "string",
+            "enum": [
+                "schedule",
+                "manual"
+            ],
+            "x-enum-varnames": [
+                "JobTriggerSchedule",
+                "JobTriggerManual"
+            ]
+        },
+        "types.LedgerSyncStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "synced",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "LedgerSyncStatusPending",
+                "LedgerSyncStatusSynced",
+                "LedgerSyncStatusFailed"
+            ]
+        },
+        "types.MandateScheme": {
+            "type": "string",
+            "enum": [
+                "sepa_core",
+                "sepa_b2b",
+                "ach"
+            ],
+            "x-enum-varnames": [
+                "MandateSchemeSEPACore",
+                "MandateSchemeSEPAB2B",
+                "MandateSchemeACH"
+            ]
+        },
+        "types.MandateStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "revoked",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "MandateStatusActive",
+                "MandateStatusRevoked",
+                "MandateStatusFailed"
+            ]
+        },
+        "types.MarginGroupBy": {
+            "type": "string",
+            "enum": [
+                "customer",
+                "plan",
+                "meter"
+            ],
+            "x-enum-varnames": [
+                "MarginGroupByCustomer",
+                "MarginGroupByPlan",
+                "MarginGroupByMeter"
+            ]
+        },
+        "types.Metadata": {
             "type": "object",
-            "properties": {
-                "flat_amount": {
-                    "description": "FlatAmount is the flat amount for the given tier and it is applied\non top of the unit amount*quantity. It solves cases in banking like 2.7% + 5c",
-                    "type": "number"
-                },
-                "unit_amount": {
-                    "description": "UnitAmount is the amount per unit for the given tier",
-                    "type": "number"
-                },
-                "up_to": {
-                    "description": "Upto is the quantity up to which this tier applies. It is null for the last tier",
-                    "type": "integer"
-                }
+            "additionalProperties": {
+                "type": "string"
             }
         },
-        "price.TransformQuantity": {
-            "type": "object",
-            "properties": {
-                "divide_by": {
-                    "description": "Divide quantity by this number",
-                    "type": "integer"
-                },
-                "round": {
-                    "description": "up or down",
-                    "type": "string"
-                }
-            }
+        "types.PaginationMode": {
+            "type": "string",
+            "enum": [
+                "offset",
+                "cursor"
+            ],
+            "x-enum-varnames": [
+                "PaginationModeOffset",
+                "PaginationModeCursor"
+            ]
+        },
+        "types.PartialPeriodBehavior": {
+            "type": "string",
+            "enum": [
+                "prorate",
+                "free",
+                "full"
+            ],
+            "x-enum-varnames": [
+                "PartialPeriodBehaviorProrate",
+                "PartialPeriodBehaviorFree",
+                "PartialPeriodBehaviorFull"
+            ]
+        },
+        "types.PaymentStatus": {
+            "type": "string",
+            "enum": [
+                "SCHEDULED",
+                "PROCESSING",
+                "SUCCEEDED",
+                "FAILED",
+                "CANCELLED"
+            ],
+            "x-enum-varnames": [
+                "PaymentStatusScheduled",
+                "PaymentStatusProcessing",
+                "PaymentStatusSucceeded",
+                "PaymentStatusFailed",
+                "PaymentStatusCancelled"
+            ]
+        },
+        "types.PriceImportAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "replace",
+                "archive",
+                "unchanged"
+            ],
+            "x-enum-varnames": [
+                "PriceImportActionCreate",
+                "PriceImportActionUpdate",
+                "PriceImportActionReplace",
+                "PriceImportActionArchive",
+                "PriceImportActionUnchanged"
+            ]
+        },
+        "types.PriceType": {
+            "type": "string",
+            "enum": [
+                "USAGE",
+                "FIXED"
+            ],
+            "x-enum-varnames": [
+                "PRICE_TYPE_USAGE",
+                "PRICE_TYPE_FIXED"
+            ]
+        },
+        "types.ProrationBehavior": {
+            "type": "string",
+            "enum": [
+                "create_prorations",
+                "none"
+            ],
+            "x-enum-varnames": [
+                "ProrationBehaviorCreateProrations",
+                "ProrationBehaviorNone"
+            ]
+        },
+        "types.QuarantineStatus": {
+            "type": "string",
+            "enum": [
+                "quarantined",
+                "resubmitted"
+            ],
+            "x-enum-varnames": [
+                "QuarantineStatusQuarantined",
+                "QuarantineStatusResubmitted"
+            ]
+        },
+        "types.RefundStatus": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "SUCCEEDED",
+                "FAILED"
+            ],
+            "x-enum-varnames": [
+                "RefundStatusPending",
+                "RefundStatusSucceeded",
+                "RefundStatusFailed"
+            ]
         },
-        "types.AggregationType": {
+        "types.ReportAggregate": {
             "type": "string",
             "enum": [
-                "COUNT",
-                "SUM",
-                "AVG"
+                "sum",
+                "avg",
+                "min",
+                "max",
+                "count"
             ],
             "x-enum-varnames": [
-                "AggregationCount",
-                "AggregationSum",
-                "AggregationAvg"
+                "ReportAggregateSum",
+                "ReportAggregateAvg",
+                "ReportAggregateMin",
+                "ReportAggregateMax",
+                "ReportAggregateCount"
             ]
         },
-        "types.BillingCadence": {
+        "types.ReportDestinationType": {
             "type": "string",
             "enum": [
-                "RECURRING",
-                "ONETIME"
+                "email",
+                "s3",
+                "webhook"
             ],
             "x-enum-varnames": [
-                "BILLING_CADENCE_RECURRING",
-                "BILLING_CADENCE_ONETIME"
+                "ReportDestinationEmail",
+                "ReportDestinationS3",
+                "ReportDestinationWebhook"
             ]
         },
-        "types.BillingModel": {
+        "types.ReportEntity": {
             "type": "string",
             "enum": [
-                "FLAT_FEE",
-                "PACKAGE",
-                "TIERED"
+                "customers",
+                "subscriptions",
+                "invoices",
+                "invoice_line_items"
             ],
             "x-enum-varnames": [
-                "BILLING_MODEL_FLAT_FEE",
-                "BILLING_MODEL_PACKAGE",
-                "BILLING_MODEL_TIERED"
+                "ReportEntityCustomers",
+                "ReportEntitySubscriptions",
+                "ReportEntityInvoices",
+                "ReportEntityInvoiceLineItems"
             ]
         },
-        "types.BillingPeriod": {
+        "types.ReportFilterOperator": {
             "type": "string",
             "enum": [
-                "MONTHLY",
-                "ANNUAL",
-                "WEEKLY",
-                "DAILY"
+                "eq",
+                "neq",
+                "gt",
+                "gte",
+                "lt",
+                "lte",
+                "in"
             ],
             "x-enum-varnames": [
-                "BILLING_PERIOD_MONTHLY",
-                "BILLING_PERIOD_ANNUAL",
-                "BILLING_PERIOD_WEEKLY",
-                "BILLING_PERIOD_DAILY"
+                "ReportFilterEq",
+                "ReportFilterNeq",
+                "ReportFilterGt",
+                "ReportFilterGte",
+                "ReportFilterLt",
+                "ReportFilterLte",
+                "ReportFilterIn"
             ]
         },
-        "types.BillingTier": {
+        "types.ReportPeriod": {
             "type": "string",
             "enum": [
-                "VOLUME",
-                "SLAB"
+                "",
+                "previous_day",
+                "previous_week",
+                "previous_month"
             ],
             "x-enum-varnames": [
-                "BILLING_TIER_VOLUME",
-                "BILLING_TIER_SLAB"
+                "ReportPeriodAll",
+                "ReportPeriodPreviousDay",
+                "ReportPeriodPreviousWeek",
+                "ReportPeriodPreviousMonth"
             ]
         },
-        "types.Filter": {
-            "type": "object",
-            "properties": {
-                "limit": {
-                    "type": "integer"
-                },
-                "offset": {
-                    "type": "integer"
-                },
-                "order": {
-                    "type": "string"
-                },
-                "sort": {
-                    "type": "string"
-                },
-                "status": {
-                    "$ref": "#/definitions/types.Status"
-                }
-            }
+        "types.ReportRunStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "processing",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ReportRunStatusPending",
+                "ReportRunStatusProcessing",
+                "ReportRunStatusCompleted",
+                "ReportRunStatusFailed"
+            ]
         },
-        "types.InvoiceCadence": {
+        "types.ReportRunTrigger": {
             "type": "string",
             "enum": [
-                "ARREAR",
-                "ADVANCE"
+                "manual",
+                "schedule"
             ],
             "x-enum-varnames": [
-                "InvoiceCadenceArrear",
-                "InvoiceCadenceAdvance"
+                "ReportRunTriggerManual",
+                "ReportRunTriggerSchedule"
             ]
         },
-        "types.Metadata": {
-            "type": "object",
-            "additionalProperties": {
-                "type": "string"
-            }
+        "types.ReportSchedule": {
+            "type": "string",
+            "enum": [
+                "",
+                "daily",
+                "weekly",
+                "monthly"
+            ],
+            "x-enum-varnames": [
+                "ReportScheduleNone",
+                "ReportScheduleDaily",
+                "ReportScheduleWeekly",
+                "ReportScheduleMonthly"
+            ]
         },
-        "types.PriceType": {
+        "types.RequestLogStatus": {
             "type": "string",
             "enum": [
-                "USAGE",
-                "FIXED"
+                "succeeded",
+                "failed"
             ],
             "x-enum-varnames": [
-                "PRICE_TYPE_USAGE",
-                "PRICE_TYPE_FIXED"
+                "RequestLogStatusSucceeded",
+                "RequestLogStatusFailed"
+            ]
+        },
+        "types.ResetStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "processing",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ResetStatusPending",
+                "ResetStatusProcessing",
+                "ResetStatusCompleted",
+                "ResetStatusFailed"
             ]
         },
         "types.ResetUsage": {
             "type": "string",
             "enum": [
                 "BILLING_PERIOD",
-                "NEVER"
+                "NEVER",
+                "DAILY",
+                "WEEKLY",
+                "MONTHLY"
             ],
             "x-enum-varnames": [
                 "ResetUsageBillingPeriod",
-                "ResetUsageNever"
+                "ResetUsageNever",
+                "ResetUsageDaily",
+                "ResetUsageWeekly",
+                "ResetUsageMonthly"
+            ]
+        },
+        "types.RevenueGroupBy": {
+            "type": "string",
+            "enum": [
+                "plan",
+                "currency",
+                "segment"
+            ],
+            "x-enum-varnames": [
+                "RevenueGroupByPlan",
+                "RevenueGroupByCurrency",
+                "RevenueGroupBySegment"
+            ]
+        },
+        "types.Role": {
+            "type": "string",
+            "enum": [
+                "admin",
+                "billing_manager",
+                "developer",
+                "read_only"
+            ],
+            "x-enum-varnames": [
+                "RoleAdmin",
+                "RoleBillingManager",
+                "RoleDeveloper",
+                "RoleReadOnly"
+            ]
+        },
+        "types.SSOProtocol": {
+            "type": "string",
+            "enum": [
+                "oidc",
+                "saml"
+            ],
+            "x-enum-varnames": [
+                "SSOProtocolOIDC",
+                "SSOProtocolSAML"
             ]
         },
         "types.Status": {
@@ -3915,27 +23463,106 @@ const docTemplate = `{
                 "StatusArchived"
             ]
         },
-        "types.SubscriptionStatus": {
+        "types.StripeImportAction": {
             "type": "string",
             "enum": [
-                "active",
-                "paused",
-                "cancelled",
-                "incomplete",
-                "incomplete_expired",
-                "past_due",
-                "trialing",
-                "unpaid"
+                "create",
+                "exists",
+                "conflict",
+                "skip"
             ],
             "x-enum-varnames": [
-                "SubscriptionStatusActive",
-                "SubscriptionStatusPaused",
-                "SubscriptionStatusCancelled",
-                "SubscriptionStatusIncomplete",
-                "SubscriptionStatusIncompleteExpired",
-                "SubscriptionStatusPastDue",
-                "SubscriptionStatusTrialing",
-                "SubscriptionStatusUnpaid"
+                "StripeImportActionCreate",
+                "StripeImportActionExists",
+                "StripeImportActionConflict",
+                "StripeImportActionSkip"
+            ]
+        },
+        "types.SyncEntityType": {
+            "type": "string",
+            "enum": [
+                "invoice",
+                "credit_note",
+                "payment",
+                "subscription",
+                "customer",
+                "crm_note",
+                "catalog",
+                "payment_method",
+                "refund",
+                "tax_transaction"
+            ],
+            "x-enum-varnames": [
+                "SyncEntityTypeInvoice",
+                "SyncEntityTypeCreditNote",
+                "SyncEntityTypePayment",
+                "SyncEntityTypeSubscription",
+                "SyncEntityTypeCustomer",
+                "SyncEntityTypeCRMNote",
+                "SyncEntityTypeCatalog",
+                "SyncEntityTypePaymentMethod",
+                "SyncEntityTypeRefund",
+                "SyncEntityTypeTaxTransaction"
+            ]
+        },
+        "types.TaskFileFormat": {
+            "type": "string",
+            "enum": [
+                "CSV",
+                "JSON"
+            ],
+            "x-enum-varnames": [
+                "TaskFileFormatCSV",
+                "TaskFileFormatJSON"
+            ]
+        },
+        "types.TaskStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "processing",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "TaskStatusPending",
+                "TaskStatusProcessing",
+                "TaskStatusCompleted",
+                "TaskStatusFailed"
+            ]
+        },
+        "types.TaskType": {
+            "type": "string",
+            "enum": [
+                "CUSTOMER_IMPORT",
+                "EVENT_BACKFILL"
+            ],
+            "x-enum-varnames": [
+                "TaskTypeCustomerImport",
+                "TaskTypeEventBackfill"
+            ]
+        },
+        "types.TaxIDType": {
+            "type": "string",
+            "enum": [
+                "eu_vat",
+                "gb_vat",
+                "ch_vat",
+                "in_gst",
+                "au_abn",
+                "nz_gst",
+                "ca_gst_hst",
+                "sg_gst"
+            ],
+            "x-enum-varnames": [
+                "TaxIDTypeEUVAT",
+                "TaxIDTypeGBVAT",
+                "TaxIDTypeCHVAT",
+                "TaxIDTypeINGST",
+                "TaxIDTypeAUABN",
+                "TaxIDTypeNZGST",
+                "TaxIDTypeCAGST",
+                "TaxIDTypeSGGST"
             ]
         },
         "types.TransactionStatus": {
@@ -3951,6 +23578,19 @@ const docTemplate = `{
                 "TransactionStatusFailed"
             ]
         },
+        "types.UsageLockStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "grace",
+                "locked"
+            ],
+            "x-enum-varnames": [
+                "UsageLockStatusOpen",
+                "UsageLockStatusGrace",
+                "UsageLockStatusLocked"
+            ]
+        },
         "types.WalletStatus": {
             "type": "string",
             "enum": [
@@ -3964,6 +23604,62 @@ const docTemplate = `{
                 "WalletStatusClosed"
             ]
         },
+        "types.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "WebhookDeliveryStatusPending",
+                "WebhookDeliveryStatusSucceeded",
+                "WebhookDeliveryStatusFailed"
+            ]
+        },
+        "types.WebhookEventType": {
+            "type": "string",
+            "enum": [
+                "invoice.finalized",
+                "invoice.held",
+                "usage.anomaly_detected",
+                "subscription.trial_will_end",
+                "subscription.trial_ended",
+                "subscription.updated",
+                "wallet.auto_topup.succeeded",
+                "wallet.auto_topup.failed",
+                "wallet.credits.expired",
+                "refund.created",
+                "refund.failed",
+                "report.completed",
+                "budget.exceeded",
+                "invoice.late_usage",
+                "invoice.payment_failed",
+                "invoice.dispute.opened",
+                "invoice.dispute.under_review",
+                "invoice.dispute.resolved"
+            ],
+            "x-enum-varnames": [
+                "WebhookEventInvoiceFinalized",
+                "WebhookEventInvoiceHeld",
+                "WebhookEventUsageAnomalyDetected",
+                "WebhookEventTrialWillEnd",
+                "WebhookEventTrialEnded",
+                "WebhookEventSubscriptionUpdated",
+                "WebhookEventWalletAutoTopUpSucceeded",
+                "WebhookEventWalletAutoTopUpFailed",
+                "WebhookEventWalletCreditsExpired",
+                "WebhookEventRefundCreated",
+                "WebhookEventRefundFailed",
+                "WebhookEventReportCompleted",
+                "WebhookEventBudgetExceeded",
+                "WebhookEventInvoiceLateUsage",
+                "WebhookEventInvoicePaymentFailed",
+                "WebhookEventDisputeOpened",
+                "WebhookEventDisputeUnderReview",
+                "WebhookEventDisputeResolved"
+            ]
+        },
         "types.WindowSize": {
             "type": "string",
             "enum": [
@@ -3977,6 +23673,30 @@ const docTemplate = `{
                 "WindowSizeDay"
             ]
         },
+        "usagestream.Update": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_name": {
+                    "type": "string"
+                },
+                "external_customer_id": {
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -3989,9 +23709,57 @@ const docTemplate = `{
                     "example": "Invalid request payload"
                 }
             }
+        },
+        "v1.EventValidationResponse": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "Invalid request payload"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Invalid request payload"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "quarantined": {
+                    "type": "boolean"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/eventschema.Violation"
+                    }
+                }
+            }
+        },
+        "v1.VersionConflictResponse": {
+            "type": "object",
+            "properties": {
+                "current_version": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "Invalid request payload"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Invalid request payload"
+                }
+            }
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Scoped API key created with POST /secrets/api-keys",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "Enter your bearer token in the format **Bearer \u0026lt;token\u0026gt;**",
             "type": "apiKey",
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// CustomerActivity is one entry of a customer activity feed
+type CustomerActivity struct {
+	ID           string             `json:"id"`
+	ActivityType types.ActivityType `json:"activity_type"`
+	Timestamp    time.Time          `json:"timestamp"`
+
+	// ResourceType and ResourceID point to the record the activity is about
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+
+	Description string           `json:"description"`
+	Amount      *decimal.Decimal `json:"amount,omitempty" swaggertype:"string"`
+	Currency    string           `json:"currency,omitempty"`
+}
+
+type ListCustomerActivityResponse struct {
+	Activities []CustomerActivity `json:"activities"`
+	Total      int                `json:"total"`
+	Offset     int                `json:"offset"`
+	Limit      int                `json:"limit"`
+}
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// RevenueAnalyticsResponse is the recurring revenue of each month of the
+// requested range, by group and currency
+type RevenueAnalyticsResponse struct {
+	GroupBy types.RevenueGroupBy `json:"group_by,omitempty"`
+	Months  []RevenueMonth       `json:"months"`
+}
+
+// RevenueMonth is the recurring revenue at the end of a calendar month and its
+// movements since the end of the month before
+type RevenueMonth struct {
+	// Month is the first instant of the month in UTC
+	Month time.Time `json:"month"`
+
+	// Snapshotted reports whether the month is closed and served from its
+	// snapshot, the current month is computed from the invoices on every request
+	Snapshotted bool           `json:"snapshotted"`
+	Groups      []RevenueGroup `json:"groups"`
+}
+
+// RevenueGroup is the recurring revenue of a plan, currency or customer segment
+// in one currency. MRR moves from the month before as
+// MRR = starting MRR + new + expansion - contraction - churned
+type RevenueGroup struct {
+	// ID is the plan ID, currency or segment of the group, empty when the
+	// revenue is not grouped
+	ID       string          `json:"id"`
+	Currency string          `json:"currency"`
+	MRR      decimal.Decimal `json:"mrr" swaggertype:"string"`
+	ARR      decimal.Decimal `json:"arr" swaggertype:"string"`
+
+	// Customers is the number of customers with recurring revenue at the end of
+	// the month, ChurnedCustomers those who had some at the end of the month
+	// before and have none
+	Customers        int `json:"customers"`
+	ChurnedCustomers int `json:"churned_customers"`
+
+	// NewMRR is the revenue of the customers without any the month before,
+	// ExpansionMRR and ContractionMRR the increase and decrease of the others
+	NewMRR         decimal.Decimal `json:"new_mrr" swaggertype:"string"`
+	ExpansionMRR   decimal.Decimal `json:"expansion_mrr" swaggertype:"string"`
+	ContractionMRR decimal.Decimal `json:"contraction_mrr" swaggertype:"string"`
+	ChurnedMRR     decimal.Decimal `json:"churned_mrr" swaggertype:"string"`
+
+	// NetRevenueRetention is the MRR of the customers of the month before as a
+	// percentage of their MRR then. The rates are percentages of the MRR and
+	// customers of the month before, and are omitted when there were none
+	NetRevenueRetention *decimal.Decimal `json:"net_revenue_retention,omitempty" swaggertype:"string"`
+	RevenueChurnRate    *decimal.Decimal `json:"revenue_churn_rate,omitempty" swaggertype:"string"`
+	CustomerChurnRate   *decimal.Decimal `json:"customer_churn_rate,omitempty" swaggertype:"string"`
+}
+
+// RefreshRevenueSnapshotsRequest recomputes the snapshots of the closed months
+// from start_month to end_month inclusive, after invoices of those months were
+// finalized or voided late
+type RefreshRevenueSnapshotsRequest struct {
+	StartMonth time.Time `json:"start_month" binding:"required"`
+	EndMonth   time.Time `json:"end_month" binding:"required"`
+}
+
+// RefreshRevenueSnapshotsResponse lists the months snapshotted again, open
+// months of the range are left out
+type RefreshRevenueSnapshotsResponse struct {
+	Months []time.Time `json:"months"`
+}
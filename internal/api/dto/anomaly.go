@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/flexprice/flexprice/internal/domain/anomaly"
+
+type AnomalyResponse struct {
+	*anomaly.Anomaly
+}
+
+type ListAnomaliesResponse struct {
+	Anomalies []AnomalyResponse `json:"anomalies"`
+	Total     int               `json:"total"`
+	Offset    int               `json:"offset"`
+	Limit     int               `json:"limit"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/flexprice/flexprice/internal/domain/audit"
+
+type AuditLogResponse struct {
+	*audit.Log
+}
+
+type ListAuditLogsResponse struct {
+	Logs   []AuditLogResponse `json:"logs"`
+	Total  int                `json:"total"`
+	Offset int                `json:"offset"`
+	Limit  int                `json:"limit"`
+}
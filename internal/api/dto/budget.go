@@ -0,0 +1,95 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/domain/budget"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// CreateBudgetRequest caps the usage charges of a customer, or of one of its
+// subscriptions when SubscriptionID is set
+type CreateBudgetRequest struct {
+	Name           string             `json:"name" validate:"required,max=255" example:"Monthly API spend"`
+	CustomerID     string             `json:"customer_id" validate:"required"`
+	SubscriptionID string             `json:"subscription_id,omitempty"`
+	Amount         decimal.Decimal    `json:"amount" swaggertype:"string" example:"500"`
+	Currency       string             `json:"currency" validate:"required,len=3" example:"usd"`
+	Period         types.BudgetPeriod `json:"period" validate:"required" example:"monthly"`
+	Action         types.BudgetAction `json:"action" validate:"required" example:"alert"`
+}
+
+// UpdateBudgetRequest changes the amount or action of a budget, the spend is
+// evaluated against them right away
+type UpdateBudgetRequest struct {
+	Name   *string             `json:"name,omitempty" validate:"omitempty,max=255"`
+	Amount *decimal.Decimal    `json:"amount,omitempty" swaggertype:"string"`
+	Action *types.BudgetAction `json:"action,omitempty"`
+}
+
+type BudgetResponse struct {
+	*budget.Budget
+}
+
+type ListBudgetsResponse struct {
+	Budgets []BudgetResponse `json:"budgets"`
+}
+
+// ListBudgetsRequest lists the budgets of the tenant, or of a customer
+type ListBudgetsRequest struct {
+	CustomerID string `form:"customer_id"`
+}
+
+func (r *CreateBudgetRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+
+	if !r.Period.Validate() {
+		return fmt.Errorf("invalid period: %s", r.Period)
+	}
+
+	if !r.Action.Validate() {
+		return fmt.Errorf("invalid action: %s", r.Action)
+	}
+
+	return nil
+}
+
+func (r *UpdateBudgetRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if r.Amount != nil && !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+
+	if r.Action != nil && !r.Action.Validate() {
+		return fmt.Errorf("invalid action: %s", *r.Action)
+	}
+
+	return nil
+}
+
+func (r *CreateBudgetRequest) ToBudget(ctx context.Context) *budget.Budget {
+	return &budget.Budget{
+		ID:             types.GenerateUUID(),
+		Name:           r.Name,
+		CustomerID:     r.CustomerID,
+		SubscriptionID: r.SubscriptionID,
+		Amount:         r.Amount,
+		Currency:       strings.ToLower(r.Currency),
+		Period:         r.Period,
+		Action:         r.Action,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}
+}
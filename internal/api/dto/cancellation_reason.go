@@ -0,0 +1,99 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/cancellationreason"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+var cancellationReasonCodePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+type CreateCancellationReasonRequest struct {
+	Code        string `json:"code" validate:"required,max=100" example:"too_expensive"`
+	Name        string `json:"name" validate:"required,max=255" example:"Too expensive"`
+	Description string `json:"description,omitempty"`
+}
+
+type CancellationReasonResponse struct {
+	*cancellationreason.CancellationReason
+}
+
+type ListCancellationReasonsResponse struct {
+	Reasons []CancellationReasonResponse `json:"reasons"`
+	// IsDefault is set when the tenant has not configured any reason and the
+	// built in taxonomy applies
+	IsDefault bool `json:"is_default"`
+}
+
+func (r *CreateCancellationReasonRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !cancellationReasonCodePattern.MatchString(r.Code) {
+		return fmt.Errorf("code must only contain lowercase letters, digits and underscores")
+	}
+
+	return nil
+}
+
+func (r *CreateCancellationReasonRequest) ToCancellationReason(ctx context.Context) *cancellationreason.CancellationReason {
+	return &cancellationreason.CancellationReason{
+		ID:          types.GenerateUUID(),
+		Code:        r.Code,
+		Name:        r.Name,
+		Description: r.Description,
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}
+}
+
+// ChurnReasonsRequest is the cancellation window of the churn analytics.
+// It defaults to the last 30 days
+type ChurnReasonsRequest struct {
+	StartTime time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime   time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (r *ChurnReasonsRequest) Validate() error {
+	if !r.StartTime.IsZero() && !r.EndTime.IsZero() && !r.EndTime.After(r.StartTime) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	return nil
+}
+
+// ChurnReasonCount is the number of cancellations of a reason
+type ChurnReasonCount struct {
+	Reason string `json:"reason"`
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+	// Percentage is the share of the cancellations of the group with this reason
+	Percentage decimal.Decimal `json:"percentage" swaggertype:"string"`
+}
+
+type ChurnPlanBreakdown struct {
+	PlanID  string             `json:"plan_id"`
+	Count   int                `json:"count"`
+	Reasons []ChurnReasonCount `json:"reasons"`
+}
+
+type ChurnTenureBreakdown struct {
+	// Tenure is the bucket of time between the start and the cancellation of the subscriptions
+	Tenure  string             `json:"tenure"`
+	Count   int                `json:"count"`
+	Reasons []ChurnReasonCount `json:"reasons"`
+}
+
+type ChurnReasonsResponse struct {
+	StartTime          time.Time              `json:"start_time"`
+	EndTime            time.Time              `json:"end_time"`
+	TotalCancellations int                    `json:"total_cancellations"`
+	ByReason           []ChurnReasonCount     `json:"by_reason"`
+	ByPlan             []ChurnPlanBreakdown   `json:"by_plan"`
+	ByTenure           []ChurnTenureBreakdown `json:"by_tenure"`
+}
@@ -0,0 +1,112 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+type CreateConnectionRequest struct {
+	Name               string                    `json:"name" validate:"required" example:"Stripe production"`
+	Provider           types.IntegrationProvider `json:"provider" validate:"required" example:"stripe"`
+	RateLimitPerSecond float64                   `json:"rate_limit_per_second" validate:"gte=0" example:"25"`
+
+	// LedgerSettings and Credentials are required for the netsuite and quickbooks providers
+	LedgerSettings *connection.LedgerSettings `json:"ledger_settings,omitempty"`
+	// CRMSettings and Credentials are required for the salesforce provider
+	CRMSettings *connection.CRMSettings `json:"crm_settings,omitempty"`
+	// GatewaySettings and Credentials are required for the adyen and razorpay providers
+	GatewaySettings *connection.GatewaySettings `json:"gateway_settings,omitempty"`
+	// TaxSettings and Credentials are required for the avalara and taxjar providers
+	TaxSettings *connection.TaxSettings `json:"tax_settings,omitempty"`
+	// Credentials is the OAuth 2.0 access token of the provider, the secret API
+	// key of the payment gateways or the Avalara license key and TaxJar API token
+	Credentials string `json:"credentials,omitempty"`
+	// WebhookSecret verifies the webhooks of the payment gateways: the signing
+	// secret of the Stripe endpoint, the HMAC key of Adyen or the Razorpay webhook secret
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+}
+
+type ConnectionResponse struct {
+	*connection.Connection
+}
+
+type ListConnectionsResponse struct {
+	Connections []ConnectionResponse `json:"connections"`
+	Total       int                  `json:"total"`
+	Offset      int                  `json:"offset"`
+	Limit       int                  `json:"limit"`
+}
+
+type SyncQueueStatusResponse struct {
+	*syncqueue.Status
+}
+
+func (r *CreateConnectionRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.Provider.Validate() {
+		return fmt.Errorf("invalid provider: %s", r.Provider)
+	}
+
+	if r.Provider.IsLedger() && (r.LedgerSettings == nil || r.Credentials == "") {
+		return fmt.Errorf("ledger_settings and credentials are required for %s connections", r.Provider)
+	}
+
+	if r.Provider == types.IntegrationProviderSalesforce && (r.CRMSettings == nil || r.Credentials == "") {
+		return fmt.Errorf("crm_settings and credentials are required for %s connections", r.Provider)
+	}
+
+	switch r.Provider {
+	case types.IntegrationProviderAdyen:
+		if r.GatewaySettings == nil || r.GatewaySettings.MerchantAccount == "" || r.Credentials == "" {
+			return fmt.Errorf("gateway_settings.merchant_account and credentials are required for %s connections", r.Provider)
+		}
+	case types.IntegrationProviderRazorpay:
+		if r.GatewaySettings == nil || r.GatewaySettings.KeyID == "" || r.Credentials == "" {
+			return fmt.Errorf("gateway_settings.key_id and credentials are required for %s connections", r.Provider)
+		}
+	}
+
+	if r.Provider.IsTax() {
+		if r.TaxSettings == nil || r.Credentials == "" {
+			return fmt.Errorf("tax_settings and credentials are required for %s connections", r.Provider)
+		}
+		if err := validator.New().Struct(r.TaxSettings); err != nil {
+			return err
+		}
+		if r.Provider == types.IntegrationProviderAvalara && (r.TaxSettings.AccountID == "" || r.TaxSettings.CompanyCode == "") {
+			return fmt.Errorf("tax_settings.account_id and tax_settings.company_code are required for %s connections", r.Provider)
+		}
+		for i, rate := range r.TaxSettings.FallbackRates {
+			if !rate.RatePercent.IsPositive() || rate.RatePercent.GreaterThan(decimal.NewFromInt(100)) {
+				return fmt.Errorf("tax_settings.fallback_rates[%d].rate_percent must be greater than 0 and at most 100", i)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *CreateConnectionRequest) ToConnection(ctx context.Context) *connection.Connection {
+	return &connection.Connection{
+		ID:                 types.GenerateUUID(),
+		Name:               r.Name,
+		Provider:           r.Provider,
+		RateLimitPerSecond: r.RateLimitPerSecond,
+		LedgerSettings:     r.LedgerSettings,
+		CRMSettings:        r.CRMSettings,
+		GatewaySettings:    r.GatewaySettings,
+		TaxSettings:        r.TaxSettings,
+		Credentials:        r.Credentials,
+		WebhookSecret:      r.WebhookSecret,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// AllocateCreditNoteRequest allocates part of a credit note to an invoice or a
+// wallet of the same customer and currency
+type AllocateCreditNoteRequest struct {
+	TargetType types.CreditAllocationTarget `json:"target_type" validate:"required" example:"invoice"`
+	TargetID   string                       `json:"target_id" validate:"required"`
+	// Amount defaults to the remaining credit, capped by the amount due when the
+	// target is an invoice
+	Amount *decimal.Decimal `json:"amount,omitempty" swaggertype:"string"`
+}
+
+func (r *AllocateCreditNoteRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.TargetType.Validate() {
+		return fmt.Errorf("invalid target_type: %s", r.TargetType)
+	}
+
+	if r.Amount != nil && !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
+}
+
+type CreditAllocationResponse struct {
+	*invoice.CreditAllocation
+}
+
+type ListCreditAllocationsResponse struct {
+	Allocations []CreditAllocationResponse `json:"allocations"`
+	// Remaining is the credit left to allocate
+	Remaining decimal.Decimal `json:"remaining" swaggertype:"string"`
+}
@@ -2,23 +2,59 @@ package dto
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/flexprice/flexprice/internal/domain/customer"
 	"github.com/flexprice/flexprice/internal/types"
 	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
 )
 
 type CreateCustomerRequest struct {
-	ExternalID string `json:"external_id" validate:"required"`
-	Name       string `json:"name"`
-	Email      string `json:"email"`
+	ExternalID      string `json:"external_id" validate:"required"`
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	PaymentMethodID string `json:"payment_method_id"`
+
+	// ConsolidateInvoices bills all subscriptions sharing a billing date and currency on one invoice
+	ConsolidateInvoices bool `json:"consolidate_invoices"`
+
+	BillingAddress  *customer.Address `json:"billing_address,omitempty"`
+	ShippingAddress *customer.Address `json:"shipping_address,omitempty"`
+	TaxIDs          []TaxIDRequest    `json:"tax_ids,omitempty" validate:"dive"`
+
+	// LegalEntityID is the legal entity the customer is invoiced from, the
+	// default entity of the tenant when empty
+	LegalEntityID string `json:"legal_entity_id,omitempty"`
+
+	// Timezone is the IANA timezone the billing periods of new subscriptions are computed in. Defaults to UTC
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,timezone" example:"America/New_York"`
 }
 
 type UpdateCustomerRequest struct {
-	ExternalID string `json:"external_id"`
-	Name       string `json:"name"`
-	Email      string `json:"email"`
+	ExternalID      string `json:"external_id"`
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	PaymentMethodID string `json:"payment_method_id"`
+
+	// ConsolidateInvoices bills all subscriptions sharing a billing date and currency on one invoice
+	ConsolidateInvoices bool `json:"consolidate_invoices"`
+
+	BillingAddress  *customer.Address `json:"billing_address,omitempty"`
+	ShippingAddress *customer.Address `json:"shipping_address,omitempty"`
+	TaxIDs          []TaxIDRequest    `json:"tax_ids,omitempty" validate:"dive"`
+
+	// LegalEntityID is the legal entity the customer is invoiced from, the
+	// default entity of the tenant when empty
+	LegalEntityID string `json:"legal_entity_id,omitempty"`
+
+	// Timezone is the IANA timezone the billing periods of new subscriptions are computed in. Defaults to UTC
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,timezone" example:"America/New_York"`
+}
+
+// TaxIDRequest is a tax registration number, checked against the format of its type
+type TaxIDRequest struct {
+	Type  types.TaxIDType `json:"type" validate:"required" example:"eu_vat"`
+	Value string          `json:"value" validate:"required" example:"DE123456789"`
 }
 
 type CustomerResponse struct {
@@ -33,19 +69,77 @@ type ListCustomersResponse struct {
 }
 
 func (r *CreateCustomerRequest) Validate() error {
-	return validator.New().Struct(r)
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+	_, err := toTaxIDs(r.TaxIDs)
+	return err
 }
 
+// ToCustomer expects a validated request
 func (r *CreateCustomerRequest) ToCustomer(ctx context.Context) *customer.Customer {
+	taxIDs, _ := toTaxIDs(r.TaxIDs)
 	return &customer.Customer{
-		ID:         uuid.New().String(),
-		ExternalID: r.ExternalID,
-		Name:       r.Name,
-		Email:      r.Email,
-		BaseModel:  types.GetDefaultBaseModel(ctx),
+		ID:                  types.GenerateUUID(),
+		ExternalID:          r.ExternalID,
+		Name:                r.Name,
+		Email:               r.Email,
+		PaymentMethodID:     r.PaymentMethodID,
+		ConsolidateInvoices: r.ConsolidateInvoices,
+		BillingAddress:      r.BillingAddress,
+		ShippingAddress:     r.ShippingAddress,
+		TaxIDs:              taxIDs,
+		LegalEntityID:       r.LegalEntityID,
+		Timezone:            timezoneOrDefault(r.Timezone),
+		BaseModel:           types.GetDefaultBaseModel(ctx),
 	}
 }
 
 func (r *UpdateCustomerRequest) Validate() error {
-	return validator.New().Struct(r)
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+	_, err := toTaxIDs(r.TaxIDs)
+	return err
+}
+
+// ApplyTo replaces the editable fields of the customer. It expects a validated request
+func (r *UpdateCustomerRequest) ApplyTo(c *customer.Customer) {
+	taxIDs, _ := toTaxIDs(r.TaxIDs)
+	c.Name = r.Name
+	c.ExternalID = r.ExternalID
+	c.Email = r.Email
+	c.PaymentMethodID = r.PaymentMethodID
+	c.ConsolidateInvoices = r.ConsolidateInvoices
+	c.BillingAddress = r.BillingAddress
+	c.ShippingAddress = r.ShippingAddress
+	c.TaxIDs = taxIDs
+	c.LegalEntityID = r.LegalEntityID
+	c.Timezone = timezoneOrDefault(r.Timezone)
+}
+
+func timezoneOrDefault(timezone string) string {
+	if timezone == "" {
+		return types.DefaultTimezone
+	}
+	return timezone
+}
+
+// toTaxIDs normalizes the tax IDs and fails on the first one not matching the
+// format of its type
+func toTaxIDs(reqs []TaxIDRequest) (customer.TaxIDs, error) {
+	taxIDs := make(customer.TaxIDs, 0, len(reqs))
+	for _, req := range reqs {
+		value, country, err := types.NormalizeTaxID(req.Type, req.Value)
+		if err != nil {
+			return nil, err
+		}
+		for _, existing := range taxIDs {
+			if existing.Type == req.Type && existing.Value == value {
+				return nil, fmt.Errorf("duplicate tax id: %s", value)
+			}
+		}
+		taxIDs = append(taxIDs, customer.TaxID{Type: req.Type, Value: value, Country: country})
+	}
+	return taxIDs, nil
 }
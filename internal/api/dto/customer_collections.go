@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// PauseCollectionsRequest pauses the collections of a customer until a date
+type PauseCollectionsRequest struct {
+	// Until is when the collections resume, it must be in the future
+	Until  time.Time `json:"until" validate:"required" example:"2026-12-31T00:00:00Z"`
+	Reason string    `json:"reason,omitempty" validate:"max=1000" example:"Payment plan agreed with the customer"`
+}
+
+func (r *PauseCollectionsRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"github.com/flexprice/flexprice/internal/domain/deadletter"
+	"github.com/go-playground/validator/v10"
+)
+
+type DeadLetterResponse struct {
+	*deadletter.DeadLetter
+}
+
+type ListDeadLettersResponse struct {
+	DeadLetters []DeadLetterResponse `json:"dead_letters"`
+	Total       int                  `json:"total"`
+	Offset      int                  `json:"offset"`
+	Limit       int                  `json:"limit"`
+}
+
+// ReplayDeadLettersRequest selects the dead letters to publish again to their topic
+type ReplayDeadLettersRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+type ReplayDeadLettersResponse struct {
+	Replayed []DeadLetterResponse    `json:"replayed"`
+	Failed   []DeadLetterReplayError `json:"failed"`
+}
+
+// DeadLetterReplayError is a selected dead letter that could not be replayed
+type DeadLetterReplayError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+func (r *ReplayDeadLettersRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/domain/email"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+// SetEmailTemplateRequest replaces the template of a type of email. Subject and
+// bodies are Go templates rendered with the customer and the invoice or the
+// subscription the email is about ex {{.Customer.Name}}, {{.Invoice.Number}}
+type SetEmailTemplateRequest struct {
+	Subject  string `json:"subject" validate:"required" example:"Invoice {{.Invoice.Number}}"`
+	BodyHTML string `json:"body_html" example:"<p>Hi {{.Customer.Name}}</p>"`
+	BodyText string `json:"body_text" example:"Hi {{.Customer.Name}}"`
+}
+
+type EmailTemplateResponse struct {
+	*email.Template
+	// IsDefault is set when the tenant has not replaced the default template
+	IsDefault bool `json:"is_default"`
+}
+
+type ListEmailTemplatesResponse struct {
+	Templates []EmailTemplateResponse `json:"templates"`
+}
+
+type EmailDeliveryResponse struct {
+	*email.Delivery
+}
+
+type ListEmailDeliveriesResponse struct {
+	Deliveries []EmailDeliveryResponse `json:"deliveries"`
+	Total      int                     `json:"total"`
+	Offset     int                     `json:"offset"`
+	Limit      int                     `json:"limit"`
+}
+
+func (r *SetEmailTemplateRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *SetEmailTemplateRequest) ToTemplate(ctx context.Context, templateType types.EmailTemplateType) *email.Template {
+	return &email.Template{
+		ID:           types.GenerateUUID(),
+		TemplateType: templateType,
+		Subject:      r.Subject,
+		BodyHTML:     r.BodyHTML,
+		BodyText:     r.BodyText,
+		BaseModel:    types.GetDefaultBaseModel(ctx),
+	}
+}
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateEnvironmentRequest struct {
+	Name string                `json:"name" validate:"required" example:"QA"`
+	Type types.EnvironmentType `json:"type" validate:"required" example:"SANDBOX"`
+}
+
+type EnvironmentResponse struct {
+	*environment.Environment
+}
+
+type ListEnvironmentsResponse struct {
+	Environments []EnvironmentResponse `json:"environments"`
+	Total        int                   `json:"total"`
+	Offset       int                   `json:"offset"`
+	Limit        int                   `json:"limit"`
+}
+
+// ResetEnvironmentRequest starts a sandbox reset. The first call without a token
+// returns a short lived confirmation token that must be sent back to start the reset
+type ResetEnvironmentRequest struct {
+	ConfirmationToken string `json:"confirmation_token"`
+}
+
+type ResetEnvironmentResponse struct {
+	// ConfirmationToken is returned when the request did not carry a valid token
+	ConfirmationToken string     `json:"confirmation_token,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+
+	// Reset is the started reset job, poll it for progress
+	Reset *environment.Reset `json:"reset,omitempty"`
+}
+
+type EnvironmentResetResponse struct {
+	*environment.Reset
+}
+
+func (r *CreateEnvironmentRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.Type.Validate() {
+		return fmt.Errorf("invalid environment type: %s", r.Type)
+	}
+
+	return nil
+}
+
+func (r *CreateEnvironmentRequest) ToEnvironment(ctx context.Context) *environment.Environment {
+	return &environment.Environment{
+		ID:        types.GenerateUUID(),
+		Name:      r.Name,
+		Type:      r.Type,
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "github.com/flexprice/flexprice/internal/kafka"
+
+type ConsumerLagResponse struct {
+	Lanes []ConsumerLaneLag `json:"lanes"`
+	// Lag is the number of events of all the lanes not consumed yet
+	Lag int64 `json:"lag"`
+}
+
+// ConsumerLaneLag is how far the consumer group of a lane is behind its topic.
+// Error is set when the lag of the lane could not be read
+type ConsumerLaneLag struct {
+	Name          string               `json:"name"`
+	Topic         string               `json:"topic"`
+	ConsumerGroup string               `json:"consumer_group"`
+	Lag           int64                `json:"lag"`
+	Partitions    []kafka.PartitionLag `json:"partitions"`
+	Error         string               `json:"error,omitempty"`
+}
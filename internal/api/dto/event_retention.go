@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/retention"
+)
+
+// SetRetentionPolicyRequest sets how long the events of a tenant are kept
+type SetRetentionPolicyRequest struct {
+	// TTLDays is the age in days after which events are deleted, 0 keeps them forever
+	TTLDays int `json:"ttl_days"`
+}
+
+func (r *SetRetentionPolicyRequest) Validate() error {
+	if r.TTLDays < 0 {
+		return fmt.Errorf("ttl_days must not be negative")
+	}
+	return nil
+}
+
+type RetentionPolicyResponse struct {
+	*retention.Policy
+}
+
+type ListRetentionPoliciesResponse struct {
+	Policies []RetentionPolicyResponse `json:"policies"`
+	// DefaultTTLDays applies to the tenants without a policy
+	DefaultTTLDays int `json:"default_ttl_days"`
+}
+
+// EventStorageResponse reports the size of the events table per tenant and
+// per monthly partition
+type EventStorageResponse struct {
+	Tenants    []TenantStorageResponse  `json:"tenants"`
+	Partitions []EventPartitionResponse `json:"partitions"`
+}
+
+type TenantStorageResponse struct {
+	TenantID string `json:"tenant_id"`
+	Rows     uint64 `json:"rows"`
+	// Bytes is the uncompressed size of the events of the tenant
+	Bytes       uint64    `json:"bytes"`
+	OldestEvent time.Time `json:"oldest_event"`
+	NewestEvent time.Time `json:"newest_event"`
+	TTLDays     int       `json:"ttl_days"`
+	// DeleteBefore is the cutoff of the next retention run, unset when the
+	// events are kept forever
+	DeleteBefore *time.Time `json:"delete_before,omitempty"`
+}
+
+type EventPartitionResponse struct {
+	PartitionID string `json:"partition_id"`
+	Rows        uint64 `json:"rows"`
+	BytesOnDisk uint64 `json:"bytes_on_disk"`
+	// DropAt is when the partition is dropped, unset while a tenant keeps its
+	// events forever
+	DropAt *time.Time `json:"drop_at,omitempty"`
+}
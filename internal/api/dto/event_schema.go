@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/domain/eventschema"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateEventSchemaRequest struct {
+	EventName string                     `json:"event_name" validate:"required,max=255" example:"api_request"`
+	Rules     []eventschema.PropertyRule `json:"rules" validate:"required"`
+
+	// OnInvalid is what happens to the events that do not match the schema,
+	// rejected by default
+	OnInvalid types.InvalidEventAction `json:"on_invalid,omitempty" example:"quarantine"`
+}
+
+// UpdateEventSchemaRequest replaces the rules of a schema. Events already
+// ingested or quarantined are not checked again
+type UpdateEventSchemaRequest struct {
+	Rules     []eventschema.PropertyRule `json:"rules" validate:"required"`
+	OnInvalid types.InvalidEventAction   `json:"on_invalid,omitempty" example:"quarantine"`
+}
+
+type EventSchemaResponse struct {
+	*eventschema.EventSchema
+}
+
+type ListEventSchemasResponse struct {
+	Schemas []EventSchemaResponse `json:"schemas"`
+}
+
+// ResubmitQuarantinedEventRequest fixes a quarantined event before it is
+// ingested again
+type ResubmitQuarantinedEventRequest struct {
+	// Properties replace the properties of the quarantined event when set
+	Properties map[string]interface{} `json:"properties,omitempty" swaggertype:"object,string,number" example:"{\"request_size\":100,\"response_status\":200}"`
+}
+
+type QuarantinedEventResponse struct {
+	*eventschema.QuarantinedEvent
+}
+
+type ListQuarantinedEventsResponse struct {
+	Events []QuarantinedEventResponse `json:"events"`
+	Total  int                        `json:"total"`
+	Offset int                        `json:"offset"`
+	Limit  int                        `json:"limit"`
+}
+
+func (r *CreateEventSchemaRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *CreateEventSchemaRequest) ToEventSchema(ctx context.Context) *eventschema.EventSchema {
+	onInvalid := r.OnInvalid
+	if onInvalid == "" {
+		onInvalid = types.InvalidEventActionReject
+	}
+
+	return &eventschema.EventSchema{
+		ID:        types.GenerateUUID(),
+		EventName: r.EventName,
+		Rules:     r.Rules,
+		OnInvalid: onInvalid,
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}
+}
+
+func (r *UpdateEventSchemaRequest) Validate() error {
+	return validator.New().Struct(r)
+}
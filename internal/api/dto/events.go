@@ -1,10 +1,14 @@
 package dto
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/task"
+	"github.com/flexprice/flexprice/internal/storage"
 	"github.com/flexprice/flexprice/internal/types"
 	"github.com/go-playground/validator/v10"
 )
@@ -17,6 +21,11 @@ type IngestEventRequest struct {
 	Timestamp          time.Time              `json:"timestamp" example:"2024-03-20T15:04:05Z"`
 	Source             string                 `json:"source" example:"api"`
 	Properties         map[string]interface{} `json:"properties" swaggertype:"object,string,number" example:"{\"request_size\":100,\"response_status\":200}"`
+
+	// IdempotencyKey makes retries of the event count once. Without an event_id,
+	// the ID of the event is derived from the key and the timestamp, which is
+	// then required. It can also be sent in the Idempotency-Key header
+	IdempotencyKey string `json:"idempotency_key,omitempty" validate:"omitempty,max=255" example:"req_7f3a9c"`
 }
 
 type GetUsageRequest struct {
@@ -29,6 +38,13 @@ type GetUsageRequest struct {
 	EndTime            time.Time           `form:"end_time" json:"end_time" example:"2024-03-20T00:00:00Z"`
 	WindowSize         types.WindowSize    `form:"window_size" json:"window_size" example:"HOUR"`
 	Filters            map[string][]string `form:"filters,omitempty" json:"filters,omitempty"`
+
+	// Precision of the HyperLogLog sketch of a COUNT_UNIQUE aggregation, from 12
+	// to 20. The distinct values are counted exactly when it is omitted
+	Precision int `form:"precision" json:"precision,omitempty" validate:"omitempty,min=12,max=20" example:"14"`
+
+	// Percentile of a PERCENTILE aggregation, greater than 0 and up to 100
+	Percentile float64 `form:"percentile" json:"percentile,omitempty" validate:"omitempty,gt=0,lte=100" example:"99"`
 }
 
 type GetUsageByMeterRequest struct {
@@ -39,6 +55,12 @@ type GetUsageByMeterRequest struct {
 	EndTime            time.Time           `form:"end_time" json:"end_time" example:"2024-12-09T00:00:00Z"`
 	WindowSize         types.WindowSize    `form:"window_size" json:"window_size" example:"HOUR"`
 	Filters            map[string][]string `form:"filters,omitempty" json:"filters,omitempty"`
+
+	// ResetAnchor aligns the weekly and monthly resets of meters with a calendar
+	// reset_usage, typically the subscription start. Defaults to start_time
+	ResetAnchor *time.Time `form:"reset_anchor" json:"reset_anchor,omitempty" example:"2024-11-09T00:00:00Z"`
+	// Timezone is the IANA timezone reset periods are computed in. Defaults to UTC
+	Timezone string `form:"timezone" json:"timezone,omitempty" example:"America/New_York"`
 }
 
 type GetEventsRequest struct {
@@ -48,7 +70,10 @@ type GetEventsRequest struct {
 	EndTime            time.Time `json:"end_time" example:"2024-12-09T00:00:00Z"`
 	IterFirstKey       string    `json:"iter_first_key"`
 	IterLastKey        string    `json:"iter_last_key"`
-	PageSize           int       `json:"page_size" default:"50"`
+	// Cursor is the next_cursor of the previous page, an opaque alternative to
+	// iter_last_key
+	Cursor   string `json:"cursor"`
+	PageSize int    `json:"page_size" default:"50"`
 }
 
 type GetEventsResponse struct {
@@ -56,6 +81,7 @@ type GetEventsResponse struct {
 	HasMore      bool    `json:"has_more"`
 	IterFirstKey string  `json:"iter_first_key,omitempty"`
 	IterLastKey  string  `json:"iter_last_key,omitempty"`
+	NextCursor   string  `json:"next_cursor,omitempty"`
 }
 
 type Event struct {
@@ -68,6 +94,65 @@ type Event struct {
 	Source             string                 `json:"source"`
 }
 
+// ExportEventsRequest selects the events to export. Resuming an export takes the
+// same filters along with the cursor of the last event received
+type ExportEventsRequest struct {
+	ExternalCustomerID string    `form:"external_customer_id" json:"external_customer_id"`
+	EventName          string    `form:"event_name" json:"event_name"`
+	StartTime          time.Time `form:"start_time" json:"start_time" time_format:"2006-01-02T15:04:05Z07:00" validate:"required" example:"2024-11-09T00:00:00Z"`
+	EndTime            time.Time `form:"end_time" json:"end_time" time_format:"2006-01-02T15:04:05Z07:00" validate:"required" example:"2024-12-09T00:00:00Z"`
+	Cursor             string    `form:"cursor" json:"cursor"`
+}
+
+// ExportedEvent is a line of an event export. Cursor resumes the export right
+// after the event
+type ExportedEvent struct {
+	Event
+	Cursor string `json:"cursor"`
+}
+
+// VoidEventRequest cancels the usage of an event
+type VoidEventRequest struct {
+	Reason string `json:"reason,omitempty" example:"duplicate event"`
+}
+
+// CorrectEventRequest replaces the properties of an event, its usage is
+// aggregated from the new properties from then on
+type CorrectEventRequest struct {
+	Properties map[string]interface{} `json:"properties" validate:"required" swaggertype:"object,string,number" example:"{\"request_size\":80}"`
+	Reason     string                 `json:"reason,omitempty" example:"wrong request size reported"`
+}
+
+// EventCorrection is a row written by a void or a correction. A sign of -1
+// reverses the usage of the row it copies
+type EventCorrection struct {
+	Event
+	Sign       int8      `json:"sign"`
+	IngestedAt time.Time `json:"ingested_at"`
+}
+
+// EventHistoryResponse is an ingested event, its corrections in the order they
+// were made and the row its usage is currently aggregated from
+type EventHistoryResponse struct {
+	Event       Event             `json:"event"`
+	Corrections []EventCorrection `json:"corrections"`
+	Voided      bool              `json:"voided"`
+	// Current is the row the usage of the event is aggregated from, absent when
+	// the event is voided
+	Current *Event `json:"current,omitempty"`
+}
+
+// CreateEventBackfillRequest backfills historical events with their original
+// timestamps. The events are either given inline or read from a JSON array of
+// events at S3Path, of the form s3://bucket/key. Events with a timestamp
+// outside of [start_time, end_time) are rejected
+type CreateEventBackfillRequest struct {
+	StartTime time.Time            `json:"start_time" validate:"required" example:"2024-01-01T00:00:00Z"`
+	EndTime   time.Time            `json:"end_time" validate:"required" example:"2024-02-01T00:00:00Z"`
+	Events    []IngestEventRequest `json:"events,omitempty"`
+	S3Path    string               `json:"s3_path,omitempty" example:"s3://usage-archive/2024-01/events.json"`
+}
+
 type GetUsageResponse struct {
 	Results   []UsageResult         `json:"results,omitempty"`
 	Value     float64               `json:"value,omitempty"`
@@ -105,6 +190,60 @@ func FromAggregationResult(result *events.AggregationResult) *GetUsageResponse {
 }
 
 func (r *IngestEventRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	// A retry defaulting to the time it is received would get another ID
+	if r.IdempotencyKey != "" && r.EventID == "" && r.Timestamp.IsZero() {
+		return fmt.Errorf("timestamp is required with an idempotency_key")
+	}
+	return nil
+}
+
+// Validate checks the request, at most maxInlineEvents events can be given inline
+func (r *CreateEventBackfillRequest) Validate(maxInlineEvents int) error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.EndTime.After(r.StartTime) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	if r.EndTime.After(time.Now()) {
+		return fmt.Errorf("end_time can not be in the future, backfills are for historical events")
+	}
+
+	if (len(r.Events) > 0) == (r.S3Path != "") {
+		return fmt.Errorf("either events or an s3_path is required")
+	}
+	if len(r.Events) > maxInlineEvents {
+		return fmt.Errorf("at most %d events can be backfilled inline, use an s3_path for more", maxInlineEvents)
+	}
+	if r.S3Path != "" {
+		if _, _, err := storage.ParseS3Path(r.S3Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *CreateEventBackfillRequest) ToTask(ctx context.Context) *task.Task {
+	start, end := r.StartTime.UTC(), r.EndTime.UTC()
+	return &task.Task{
+		ID:         types.GenerateUUID(),
+		Type:       types.TaskTypeEventBackfill,
+		FileFormat: types.TaskFileFormatJSON,
+		FileURL:    r.S3Path,
+		TaskStatus: types.TaskStatusPending,
+		RangeStart: &start,
+		RangeEnd:   &end,
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}
+}
+
+func (r *CorrectEventRequest) Validate() error {
 	return validator.New().Struct(r)
 }
 
@@ -127,6 +266,8 @@ func (r *GetUsageRequest) ToUsageParams() *events.UsageParams {
 		EndTime:            r.EndTime,
 		WindowSize:         r.WindowSize,
 		Filters:            r.Filters,
+		Precision:          r.Precision,
+		Percentile:         r.Percentile,
 	}
 }
 
@@ -137,3 +278,50 @@ func (r *GetUsageByMeterRequest) Validate() error {
 func (r *GetEventsRequest) Validate() error {
 	return validator.New().Struct(r)
 }
+
+func (r *ExportEventsRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.EndTime.After(r.StartTime) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	return nil
+}
+
+func newEvent(event *events.Event) Event {
+	return Event{
+		ID:                 event.ID,
+		ExternalCustomerID: event.ExternalCustomerID,
+		CustomerID:         event.CustomerID,
+		EventName:          event.EventName,
+		Timestamp:          event.Timestamp,
+		Properties:         event.Properties,
+		Source:             event.Source,
+	}
+}
+
+// NewEventHistoryResponse builds the response from the history of an event, the
+// ingested event first
+func NewEventHistoryResponse(history []*events.Event) *EventHistoryResponse {
+	resp := &EventHistoryResponse{
+		Event:       newEvent(history[0]),
+		Corrections: make([]EventCorrection, 0, len(history)-1),
+	}
+	for _, event := range history[1:] {
+		resp.Corrections = append(resp.Corrections, EventCorrection{
+			Event:      newEvent(event),
+			Sign:       event.GetSign(),
+			IngestedAt: event.IngestedAt,
+		})
+	}
+
+	if current := events.EffectiveEvent(history); current != nil {
+		e := newEvent(current)
+		resp.Current = &e
+	} else {
+		resp.Voided = true
+	}
+	return resp
+}
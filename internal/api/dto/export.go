@@ -0,0 +1,154 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/export"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateExportRequest struct {
+	Type               types.ExportType   `json:"export_type" validate:"required" example:"EVENTS"`
+	Format             types.ExportFormat `json:"export_format" example:"PARQUET"`
+	EventName          string             `json:"event_name" example:"api_request"`
+	MeterID            string             `json:"meter_id" example:"123"`
+	ExternalCustomerID string             `json:"external_customer_id" example:"customer456"`
+	WindowSize         types.WindowSize   `json:"window_size" example:"DAY"`
+	StartTime          time.Time          `json:"start_time" validate:"required" example:"2024-11-01T00:00:00Z"`
+	EndTime            time.Time          `json:"end_time" validate:"required" example:"2024-12-01T00:00:00Z"`
+}
+
+// CreateExportScheduleRequest creates a schedule exporting the data of the
+// previous day, week or month at each of its runs
+type CreateExportScheduleRequest struct {
+	Name               string               `json:"name" example:"Daily usage"`
+	Type               types.ExportType     `json:"export_type" validate:"required" example:"USAGE"`
+	Format             types.ExportFormat   `json:"export_format" example:"PARQUET"`
+	EventName          string               `json:"event_name" example:"api_request"`
+	MeterID            string               `json:"meter_id" example:"123"`
+	ExternalCustomerID string               `json:"external_customer_id" example:"customer456"`
+	WindowSize         types.WindowSize     `json:"window_size" example:"DAY"`
+	Schedule           types.ReportSchedule `json:"schedule" validate:"required" example:"daily"`
+}
+
+type ExportScheduleResponse struct {
+	*export.Schedule
+}
+
+type ListExportSchedulesResponse struct {
+	Schedules []ExportScheduleResponse `json:"schedules"`
+	Total     int                      `json:"total"`
+	Offset    int                      `json:"offset"`
+	Limit     int                      `json:"limit"`
+}
+
+type ExportResponse struct {
+	*export.Export
+}
+
+type ListExportsResponse struct {
+	Exports []ExportResponse `json:"exports"`
+	Total   int              `json:"total"`
+	Offset  int              `json:"offset"`
+	Limit   int              `json:"limit"`
+}
+
+type ExportDownloadResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (r *CreateExportRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.EndTime.After(r.StartTime) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+
+	return validateExportData(r.Type, &r.Format, r.EventName, r.MeterID, &r.WindowSize)
+}
+
+// validateExportData validates the data exported by an export or a schedule
+// and defaults its format and window size
+func validateExportData(exportType types.ExportType, format *types.ExportFormat, eventName, meterID string, windowSize *types.WindowSize) error {
+	if !exportType.Validate() {
+		return fmt.Errorf("invalid export type: %s", exportType)
+	}
+
+	if *format == "" {
+		*format = types.ExportFormatParquet
+	}
+	if !format.Validate() {
+		return fmt.Errorf("invalid export format: %s", *format)
+	}
+
+	switch exportType {
+	case types.ExportTypeEvents:
+		if eventName == "" {
+			return fmt.Errorf("event_name is required for events export")
+		}
+	case types.ExportTypeUsage:
+		if meterID == "" {
+			return fmt.Errorf("meter_id is required for usage export")
+		}
+		if *windowSize == "" {
+			*windowSize = types.WindowSizeDay
+		}
+	}
+
+	return nil
+}
+
+func (r *CreateExportRequest) ToExport(ctx context.Context) *export.Export {
+	return &export.Export{
+		ID:                 types.GenerateUUID(),
+		Type:               r.Type,
+		Format:             r.Format,
+		ExportStatus:       types.ExportStatusPending,
+		EventName:          r.EventName,
+		MeterID:            r.MeterID,
+		ExternalCustomerID: r.ExternalCustomerID,
+		WindowSize:         r.WindowSize,
+		StartTime:          r.StartTime.UTC(),
+		EndTime:            r.EndTime.UTC(),
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}
+}
+
+func (r *CreateExportScheduleRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	switch r.Schedule {
+	case types.ReportScheduleDaily, types.ReportScheduleWeekly, types.ReportScheduleMonthly:
+	default:
+		return fmt.Errorf("invalid schedule: %s", r.Schedule)
+	}
+
+	return validateExportData(r.Type, &r.Format, r.EventName, r.MeterID, &r.WindowSize)
+}
+
+// ToSchedule returns the schedule, its first run at the next occurrence of
+// the schedule after now
+func (r *CreateExportScheduleRequest) ToSchedule(ctx context.Context, now time.Time) *export.Schedule {
+	next := r.Schedule.Next(now)
+	return &export.Schedule{
+		ID:                 types.GenerateUUID(),
+		Name:               r.Name,
+		Type:               r.Type,
+		Format:             r.Format,
+		EventName:          r.EventName,
+		MeterID:            r.MeterID,
+		ExternalCustomerID: r.ExternalCustomerID,
+		WindowSize:         r.WindowSize,
+		Schedule:           r.Schedule,
+		NextRunAt:          &next,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"github.com/flexprice/flexprice/internal/domain/featureflag"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// SetFeatureFlagRequest turns a feature flag on or off for a tenant
+type SetFeatureFlagRequest struct {
+	// EnvironmentID restricts the override to an environment of the tenant,
+	// all its environments when empty
+	EnvironmentID string `json:"environment_id,omitempty"`
+	Enabled       bool   `json:"enabled"`
+}
+
+type FeatureFlagOverrideResponse struct {
+	*featureflag.Override
+}
+
+// FeatureFlagResponse is a feature flag with its default and the overrides of
+// the tenant
+type FeatureFlagResponse struct {
+	Flag types.FeatureFlag `json:"flag"`
+	// Default applies to the environments of the tenant without an override
+	Default   bool                          `json:"default"`
+	Overrides []FeatureFlagOverrideResponse `json:"overrides"`
+}
+
+type ListFeatureFlagsResponse struct {
+	TenantID string                `json:"tenant_id"`
+	Flags    []FeatureFlagResponse `json:"flags"`
+}
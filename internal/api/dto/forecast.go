@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// CustomerForecastResponse is the spend of a customer projected to the end of
+// the current period of each of its subscriptions
+type CustomerForecastResponse struct {
+	CustomerID   string              `json:"customer_id"`
+	Model        types.ForecastModel `json:"model"`
+	ForecastedAt time.Time           `json:"forecasted_at"`
+
+	// ProjectedTotals sums the projected totals of the subscriptions by currency
+	ProjectedTotals map[string]decimal.Decimal `json:"projected_totals" swaggertype:"object,string"`
+	Subscriptions   []SubscriptionForecast     `json:"subscriptions"`
+}
+
+// SubscriptionForecast is the total of the invoice a subscription renews with,
+// its usage projected to the end of the period
+type SubscriptionForecast struct {
+	SubscriptionID string           `json:"subscription_id"`
+	Currency       string           `json:"currency"`
+	PeriodStart    time.Time        `json:"period_start"`
+	PeriodEnd      time.Time        `json:"period_end"`
+	ProjectedTotal decimal.Decimal  `json:"projected_total" swaggertype:"string"`
+	Usage          []ProjectedUsage `json:"usage"`
+}
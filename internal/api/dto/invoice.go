@@ -0,0 +1,402 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/sequence"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+type CreateSubscriptionInvoiceRequest struct {
+	// PeriodStart and PeriodEnd default to the current period of the subscription
+	PeriodStart time.Time `json:"period_start" example:"2024-11-01T00:00:00Z"`
+	PeriodEnd   time.Time `json:"period_end" example:"2024-12-01T00:00:00Z"`
+
+	// Adjustments are additional line items for the billing run ex proration credits.
+	// Credits are passed as negative amounts
+	Adjustments []InvoiceAdjustmentRequest `json:"adjustments,omitempty" validate:"dive"`
+}
+
+type InvoiceAdjustmentRequest struct {
+	DisplayName string          `json:"display_name" validate:"required" example:"Proration credit"`
+	Amount      decimal.Decimal `json:"amount" swaggertype:"string" example:"-10.00"`
+}
+
+// CreateCustomerInvoicesRequest runs billing for the subscriptions of a customer
+// whose current period ends at the billing date
+type CreateCustomerInvoicesRequest struct {
+	BillingDate time.Time `json:"billing_date" validate:"required" example:"2024-12-01T00:00:00Z"`
+}
+
+type InvoiceResponse struct {
+	*invoice.Invoice
+
+	// CreditInvoiceIDs are the credit (or negative) invoices issued against this invoice
+	CreditInvoiceIDs []string `json:"credit_invoice_ids,omitempty"`
+
+	// Sections group the line items per subscription on consolidated invoices
+	Sections []InvoiceSection `json:"sections,omitempty"`
+}
+
+// InvoiceSection is the part of a consolidated invoice billing one subscription
+type InvoiceSection struct {
+	SubscriptionID string                     `json:"subscription_id"`
+	PeriodStart    *time.Time                 `json:"period_start,omitempty"`
+	PeriodEnd      *time.Time                 `json:"period_end,omitempty"`
+	Subtotal       decimal.Decimal            `json:"subtotal" swaggertype:"string"`
+	LineItems      []*invoice.InvoiceLineItem `json:"line_items"`
+}
+
+// NewInvoiceResponse builds the response of an invoice with its subscription
+// sections when it consolidates several subscriptions
+func NewInvoiceResponse(inv *invoice.Invoice) *InvoiceResponse {
+	response := &InvoiceResponse{Invoice: inv}
+	if inv.InvoiceType != types.InvoiceTypeSubscription || inv.SubscriptionID != "" {
+		return response
+	}
+
+	index := make(map[string]int)
+	for _, item := range inv.LineItems {
+		i, ok := index[item.SubscriptionID]
+		if !ok {
+			i = len(response.Sections)
+			index[item.SubscriptionID] = i
+			response.Sections = append(response.Sections, InvoiceSection{
+				SubscriptionID: item.SubscriptionID,
+				PeriodStart:    item.PeriodStart,
+				PeriodEnd:      item.PeriodEnd,
+				Subtotal:       decimal.Zero,
+			})
+		}
+
+		response.Sections[i].Subtotal = response.Sections[i].Subtotal.Add(item.Amount)
+		response.Sections[i].LineItems = append(response.Sections[i].LineItems, item)
+	}
+
+	return response
+}
+
+// UpcomingInvoiceRequest are the options of the preview of the upcoming invoice
+type UpcomingInvoiceRequest struct {
+	// ProjectUsage bills the usage projected over the whole period at the
+	// run-rate measured so far instead of the usage to date
+	ProjectUsage bool `form:"project_usage" json:"project_usage"`
+}
+
+// UpcomingInvoiceResponse is the preview of the invoice billed at the renewal of a
+// subscription along with the wallet credits expected to be drawn down against it
+type UpcomingInvoiceResponse struct {
+	*invoice.Invoice
+
+	RenewalDate        time.Time           `json:"renewal_date"`
+	CreditApplications []CreditApplication `json:"credit_applications"`
+	CreditsApplied     decimal.Decimal     `json:"credits_applied" swaggertype:"string"`
+	AmountOutOfPocket  decimal.Decimal     `json:"amount_out_of_pocket" swaggertype:"string"`
+
+	// UsageProjection is set when the usage of the period is projected at its
+	// run-rate, the usage line items are then billed at the projected quantities
+	UsageProjection *UsageProjection `json:"usage_projection,omitempty"`
+}
+
+// UsageProjection extrapolates the usage of the period measured until
+// projected_at to the whole period. Factor is the length of the period over the
+// part elapsed
+type UsageProjection struct {
+	ProjectedAt time.Time        `json:"projected_at"`
+	Factor      decimal.Decimal  `json:"factor" swaggertype:"string"`
+	Usage       []ProjectedUsage `json:"usage"`
+}
+
+type ProjectedUsage struct {
+	PriceID           string          `json:"price_id"`
+	MeterID           string          `json:"meter_id"`
+	DisplayName       string          `json:"display_name"`
+	QuantityToDate    decimal.Decimal `json:"quantity_to_date" swaggertype:"string"`
+	ProjectedQuantity decimal.Decimal `json:"projected_quantity" swaggertype:"string"`
+}
+
+// CreditApplication is the simulated draw-down of a wallet at renewal
+type CreditApplication struct {
+	WalletID      string          `json:"wallet_id"`
+	BalanceBefore decimal.Decimal `json:"balance_before" swaggertype:"string"`
+	Amount        decimal.Decimal `json:"amount" swaggertype:"string"`
+	BalanceAfter  decimal.Decimal `json:"balance_after" swaggertype:"string"`
+}
+
+// InvoiceLateUsageEvent is the data of the invoice.late_usage webhook event, sent
+// when events ingested after an invoice was finalized land in its period
+type InvoiceLateUsageEvent struct {
+	Invoice *invoice.Invoice `json:"invoice"`
+	// LateEvents is the number of events ingested in the window
+	LateEvents     uint64    `json:"late_events"`
+	IngestedAfter  time.Time `json:"ingested_after"`
+	IngestedBefore time.Time `json:"ingested_before"`
+}
+
+type ListInvoicesResponse struct {
+	Invoices []InvoiceResponse `json:"invoices"`
+	Total    int               `json:"total"`
+	Offset   int               `json:"offset"`
+	Limit    int               `json:"limit"`
+	// NextCursor lists the following page in cursor pagination, it is empty on
+	// the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (r *CreateSubscriptionInvoiceRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *CreateCustomerInvoicesRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+type UpdateInvoiceNumberingConfigRequest struct {
+	Prefix      string `json:"prefix" validate:"max=20,excludesall= " example:"INV"`
+	Separator   string `json:"separator" validate:"max=5" example:"-"`
+	Padding     int    `json:"padding" validate:"min=1,max=12" example:"5"`
+	ResetYearly bool   `json:"reset_yearly" example:"true"`
+}
+
+type InvoiceNumberingConfigResponse struct {
+	*sequence.InvoiceNumberingConfig
+}
+
+func (r *UpdateInvoiceNumberingConfigRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+// RecordInvoicePaymentRequest records an amount paid against a finalized invoice.
+// Several payments can be recorded until the invoice is paid in full
+type RecordInvoicePaymentRequest struct {
+	Amount decimal.Decimal `json:"amount" swaggertype:"string" example:"50.00"`
+	// Currency defaults to the currency of the invoice and must match it
+	Currency          string `json:"currency,omitempty" example:"usd"`
+	PaymentMethodType string `json:"payment_method_type,omitempty" example:"bank_transfer"`
+	Reference         string `json:"reference,omitempty" example:"TRX-20241201-001"`
+	// PaidAt defaults to now
+	PaidAt *time.Time `json:"paid_at,omitempty" example:"2024-12-01T00:00:00Z"`
+
+	// ConnectionID and GatewayPaymentID identify a payment collected at a payment
+	// gateway so that its refunds are executed there
+	ConnectionID     string `json:"connection_id,omitempty"`
+	GatewayPaymentID string `json:"gateway_payment_id,omitempty" example:"pi_3NqK2aLkdIwHu7ix"`
+}
+
+func (r *RecordInvoicePaymentRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	if (r.ConnectionID == "") != (r.GatewayPaymentID == "") {
+		return fmt.Errorf("connection_id and gateway_payment_id must be set together")
+	}
+	return nil
+}
+
+// CollectInvoicePaymentRequest charges an invoice to a payment method of the
+// customer at a payment gateway
+type CollectInvoicePaymentRequest struct {
+	ConnectionID string `json:"connection_id" validate:"required"`
+	// PaymentMethodID is one of the payment methods of the customer on the
+	// connection, the default payment method of the customer when empty
+	PaymentMethodID string `json:"payment_method_id,omitempty"`
+	// Amount defaults to the amount remaining of the invoice
+	Amount *decimal.Decimal `json:"amount,omitempty" swaggertype:"string" example:"50.00"`
+}
+
+func (r *CollectInvoicePaymentRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if r.Amount != nil && !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
+}
+
+// ScheduleDirectDebitRequest schedules a bank debit of an invoice under a
+// mandate of the customer
+type ScheduleDirectDebitRequest struct {
+	// MandateID is an active mandate of the customer, the one on the default
+	// payment method of the customer when empty
+	MandateID string `json:"mandate_id,omitempty"`
+	// Amount defaults to the amount remaining of the invoice
+	Amount *decimal.Decimal `json:"amount,omitempty" swaggertype:"string" example:"50.00"`
+}
+
+func (r *ScheduleDirectDebitRequest) Validate() error {
+	if r.Amount != nil && !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
+}
+
+type InvoicePaymentResponse struct {
+	*invoice.Payment
+}
+
+// InvoicePaymentFailedEvent is the payload of the invoice.payment_failed webhook,
+// sent when a payment gateway declines the collection of an invoice or a bank
+// debit of an invoice fails
+type InvoicePaymentFailedEvent struct {
+	InvoiceID string `json:"invoice_id"`
+	// PaymentID is set for bank debits, their payment is kept as failed
+	PaymentID        string          `json:"payment_id,omitempty"`
+	CustomerID       string          `json:"customer_id"`
+	ConnectionID     string          `json:"connection_id"`
+	GatewayPaymentID string          `json:"gateway_payment_id,omitempty"`
+	Amount           decimal.Decimal `json:"amount" swaggertype:"string"`
+	Currency         string          `json:"currency"`
+	FailureReason    string          `json:"failure_reason"`
+	// FailureCode and DunningAction are set for bank debits
+	FailureCode   string              `json:"failure_code,omitempty"`
+	DunningAction types.DunningAction `json:"dunning_action,omitempty"`
+}
+
+// ListInvoicePaymentsResponse is the allocation ledger of an invoice along with
+// the amounts it adds up to
+type ListInvoicePaymentsResponse struct {
+	Payments   []InvoicePaymentResponse `json:"payments"`
+	AmountDue  decimal.Decimal          `json:"amount_due" swaggertype:"string"`
+	AmountPaid decimal.Decimal          `json:"amount_paid" swaggertype:"string"`
+	// AmountProcessing is the sum of the bank debits that did not clear yet
+	AmountProcessing decimal.Decimal            `json:"amount_processing" swaggertype:"string"`
+	AmountRemaining  decimal.Decimal            `json:"amount_remaining" swaggertype:"string"`
+	PaymentStatus    types.InvoicePaymentStatus `json:"payment_status"`
+}
+
+// AddInvoiceLineItemRequest adds a manual line item to a draft invoice ex a one-off
+// charge or, with a negative amount, a discount
+type AddInvoiceLineItemRequest struct {
+	DisplayName string          `json:"display_name" validate:"required,max=255" example:"Onboarding fee"`
+	Amount      decimal.Decimal `json:"amount" swaggertype:"string" example:"150.00"`
+	// Quantity defaults to 1
+	Quantity *decimal.Decimal `json:"quantity,omitempty" swaggertype:"string" example:"1"`
+}
+
+func (r *AddInvoiceLineItemRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if r.Amount.IsZero() {
+		return fmt.Errorf("amount must not be zero")
+	}
+	if r.Quantity != nil && !r.Quantity.IsPositive() {
+		return fmt.Errorf("quantity must be positive")
+	}
+	return nil
+}
+
+// UpdateInvoiceLineItemRequest edits a line item of a draft invoice. The amount of
+// a usage line item can only be changed within the configured tolerance
+type UpdateInvoiceLineItemRequest struct {
+	DisplayName *string          `json:"display_name,omitempty" validate:"omitempty,min=1,max=255" example:"Onboarding fee (waived)"`
+	Amount      *decimal.Decimal `json:"amount,omitempty" swaggertype:"string" example:"120.00"`
+	Quantity    *decimal.Decimal `json:"quantity,omitempty" swaggertype:"string" example:"2"`
+}
+
+func (r *UpdateInvoiceLineItemRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if r.DisplayName == nil && r.Amount == nil && r.Quantity == nil {
+		return fmt.Errorf("display_name, amount or quantity is required")
+	}
+	if r.Quantity != nil && !r.Quantity.IsPositive() {
+		return fmt.Errorf("quantity must be positive")
+	}
+	return nil
+}
+
+// CreateOneOffInvoiceRequest raises an invoice outside of any subscription ex for
+// professional services. Discounts apply to the charges and taxes to the
+// discounted subtotal
+type CreateOneOffInvoiceRequest struct {
+	CustomerID  string                         `json:"customer_id" validate:"required"`
+	Currency    string                         `json:"currency" validate:"required,len=3" example:"usd"`
+	Description string                         `json:"description,omitempty" example:"Implementation services"`
+	LineItems   []OneOffInvoiceLineItemRequest `json:"line_items" validate:"required,min=1,dive"`
+	Discounts   []InvoiceDiscountRequest       `json:"discounts,omitempty" validate:"dive"`
+	Taxes       []InvoiceTaxRequest            `json:"taxes,omitempty" validate:"dive"`
+	Metadata    types.Metadata                 `json:"metadata,omitempty"`
+
+	// Finalize finalizes the invoice on creation instead of leaving it in draft
+	Finalize bool `json:"finalize,omitempty"`
+}
+
+// OneOffInvoiceLineItemRequest is a charge of a one-off invoice, either priced by
+// an existing fixed price or a free-form unit amount
+type OneOffInvoiceLineItemRequest struct {
+	PriceID string `json:"price_id,omitempty"`
+	// DisplayName defaults to the description of the price
+	DisplayName string           `json:"display_name,omitempty" validate:"max=255" example:"Solution architect, 8 hours"`
+	UnitAmount  *decimal.Decimal `json:"unit_amount,omitempty" swaggertype:"string" example:"150.00"`
+	// Quantity defaults to 1
+	Quantity *decimal.Decimal `json:"quantity,omitempty" swaggertype:"string" example:"8"`
+}
+
+// InvoiceDiscountRequest is a coupon applied to the charges of an invoice, either
+// a fixed amount or a percentage off
+type InvoiceDiscountRequest struct {
+	DisplayName string           `json:"display_name" validate:"required,max=255" example:"WELCOME10"`
+	AmountOff   *decimal.Decimal `json:"amount_off,omitempty" swaggertype:"string" example:"100.00"`
+	PercentOff  *decimal.Decimal `json:"percent_off,omitempty" swaggertype:"string" example:"10"`
+}
+
+// InvoiceTaxRequest is a tax charged at a rate on the discounted subtotal
+type InvoiceTaxRequest struct {
+	DisplayName string          `json:"display_name" validate:"required,max=255" example:"VAT 20%"`
+	RatePercent decimal.Decimal `json:"rate_percent" swaggertype:"string" example:"20"`
+}
+
+func (r *CreateOneOffInvoiceRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	hundred := decimal.NewFromInt(100)
+	for i, item := range r.LineItems {
+		if (item.PriceID == "") == (item.UnitAmount == nil) {
+			return fmt.Errorf("line_items[%d]: price_id or unit_amount is required", i)
+		}
+		if item.PriceID == "" && item.DisplayName == "" {
+			return fmt.Errorf("line_items[%d]: display_name is required with unit_amount", i)
+		}
+		if item.UnitAmount != nil && !item.UnitAmount.IsPositive() {
+			return fmt.Errorf("line_items[%d]: unit_amount must be positive", i)
+		}
+		if item.Quantity != nil && !item.Quantity.IsPositive() {
+			return fmt.Errorf("line_items[%d]: quantity must be positive", i)
+		}
+	}
+
+	for i, discount := range r.Discounts {
+		if (discount.AmountOff == nil) == (discount.PercentOff == nil) {
+			return fmt.Errorf("discounts[%d]: amount_off or percent_off is required", i)
+		}
+		if discount.AmountOff != nil && !discount.AmountOff.IsPositive() {
+			return fmt.Errorf("discounts[%d]: amount_off must be positive", i)
+		}
+		if discount.PercentOff != nil && (!discount.PercentOff.IsPositive() || discount.PercentOff.GreaterThan(hundred)) {
+			return fmt.Errorf("discounts[%d]: percent_off must be between 0 and 100", i)
+		}
+	}
+
+	for i, tax := range r.Taxes {
+		if !tax.RatePercent.IsPositive() || tax.RatePercent.GreaterThan(hundred) {
+			return fmt.Errorf("taxes[%d]: rate_percent must be between 0 and 100", i)
+		}
+	}
+	return nil
+}
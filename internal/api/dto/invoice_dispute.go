@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// OpenDisputeRequest disputes amounts of line items of a finalized invoice
+type OpenDisputeRequest struct {
+	Reason string                `json:"reason,omitempty" validate:"max=1000" example:"Usage billed twice for March"`
+	Lines  []DisputedLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+type DisputedLineRequest struct {
+	LineItemID string `json:"line_item_id" validate:"required"`
+	// Amount is the part of the amount of the line item disputed
+	Amount decimal.Decimal `json:"amount" swaggertype:"string" example:"40.00"`
+	Reason string          `json:"reason,omitempty" validate:"max=500"`
+}
+
+func (r *OpenDisputeRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(r.Lines))
+	for i, line := range r.Lines {
+		if !line.Amount.IsPositive() {
+			return fmt.Errorf("lines[%d]: amount must be positive", i)
+		}
+		if seen[line.LineItemID] {
+			return fmt.Errorf("lines[%d]: line item %s is disputed twice", i, line.LineItemID)
+		}
+		seen[line.LineItemID] = true
+	}
+	return nil
+}
+
+// ResolveDisputeRequest settles the dispute of an invoice
+type ResolveDisputeRequest struct {
+	// Resolution is credit_note to credit the disputed amount to the invoice,
+	// reissue to void the invoice and raise a corrected draft in its place, or
+	// uphold to keep the invoice as issued
+	Resolution types.DisputeResolution `json:"resolution" validate:"required" example:"credit_note"`
+	Note       string                  `json:"note,omitempty" validate:"max=1000" example:"Duplicate usage confirmed"`
+}
+
+func (r *ResolveDisputeRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.Resolution.Validate() {
+		return fmt.Errorf("invalid resolution: %s", r.Resolution)
+	}
+	return nil
+}
+
+type DisputeResponse struct {
+	*invoice.Dispute
+}
+
+type ListDisputesResponse struct {
+	Disputes []DisputeResponse `json:"disputes"`
+}
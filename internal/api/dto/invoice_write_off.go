@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// WriteOffInvoiceRequest marks the amount remaining of an invoice as uncollectible
+type WriteOffInvoiceRequest struct {
+	Reason string `json:"reason" validate:"required,max=1000" example:"Customer went out of business"`
+}
+
+func (r *WriteOffInvoiceRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"github.com/flexprice/flexprice/internal/domain/job"
+)
+
+// JobResponse is a background job with its schedule as configured for the
+// deployment and its latest run
+type JobResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+
+	// IntervalMins is the time between two scheduled runs
+	IntervalMins int `json:"interval_mins"`
+
+	// BatchSize is how many items a run hands out at a time, 0 for all of them
+	BatchSize int `json:"batch_size"`
+
+	// Concurrency is how many items a run processes at once
+	Concurrency int `json:"concurrency"`
+
+	// Running tells whether the job runs in the process serving the request
+	Running bool `json:"running"`
+
+	LastRun *job.Run `json:"last_run,omitempty"`
+}
+
+type ListJobsResponse struct {
+	Jobs []JobResponse `json:"jobs"`
+}
+
+type JobRunResponse struct {
+	*job.Run
+}
+
+type ListJobRunsResponse struct {
+	Runs []JobRunResponse `json:"runs"`
+}
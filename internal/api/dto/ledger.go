@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/flexprice/flexprice/internal/domain/ledger"
+
+type LedgerSyncResponse struct {
+	*ledger.Sync
+}
+
+type ListLedgerSyncsResponse struct {
+	Syncs  []LedgerSyncResponse `json:"syncs"`
+	Total  int                  `json:"total"`
+	Offset int                  `json:"offset"`
+	Limit  int                  `json:"limit"`
+}
+
+func NewListLedgerSyncsResponse(syncs []*ledger.Sync, offset, limit int) *ListLedgerSyncsResponse {
+	response := &ListLedgerSyncsResponse{
+		Syncs:  make([]LedgerSyncResponse, len(syncs)),
+		Total:  len(syncs),
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	for i, sync := range syncs {
+		response.Syncs[i] = LedgerSyncResponse{Sync: sync}
+	}
+
+	return response
+}
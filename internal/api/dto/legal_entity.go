@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/domain/sequence"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateLegalEntityRequest struct {
+	Name    string            `json:"name" validate:"required,max=255" example:"Acme GmbH"`
+	Address *customer.Address `json:"address" validate:"required"`
+	TaxIDs  []TaxIDRequest    `json:"tax_ids,omitempty" validate:"dive"`
+
+	// InvoicePrefix, InvoiceSeparator, InvoicePadding and InvoiceResetYearly
+	// format the invoice numbers of the entity, as the invoice numbering of the
+	// tenant. Padding defaults to 5
+	InvoicePrefix      string `json:"invoice_prefix" validate:"max=20,excludesall= " example:"DE"`
+	InvoiceSeparator   string `json:"invoice_separator" validate:"max=5" example:"-"`
+	InvoicePadding     int    `json:"invoice_padding,omitempty" validate:"omitempty,min=1,max=12" example:"5"`
+	InvoiceResetYearly bool   `json:"invoice_reset_yearly"`
+
+	BankDetails *legalentity.BankDetails `json:"bank_details,omitempty"`
+	PDFTemplate string                   `json:"pdf_template,omitempty" validate:"max=255" example:"classic"`
+
+	// IsDefault issues the invoices of the customers and subscriptions not
+	// assigned to an entity from this one. It replaces the current default
+	IsDefault bool `json:"is_default"`
+}
+
+// UpdateLegalEntityRequest replaces the legal entity. The invoices already
+// issued keep their numbers
+type UpdateLegalEntityRequest struct {
+	CreateLegalEntityRequest
+}
+
+type LegalEntityResponse struct {
+	*legalentity.LegalEntity
+}
+
+type ListLegalEntitiesResponse struct {
+	LegalEntities []LegalEntityResponse `json:"legal_entities"`
+}
+
+func (r *CreateLegalEntityRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+	if _, err := toTaxIDs(r.TaxIDs); err != nil {
+		return err
+	}
+
+	if b := r.BankDetails; b != nil {
+		if b.AccountHolder == "" {
+			return fmt.Errorf("bank_details.account_holder is required")
+		}
+		if b.IBAN == "" && b.AccountNumber == "" {
+			return fmt.Errorf("bank_details requires an iban or an account_number")
+		}
+	}
+	return nil
+}
+
+// ToLegalEntity expects a validated request
+func (r *CreateLegalEntityRequest) ToLegalEntity(ctx context.Context) *legalentity.LegalEntity {
+	entity := &legalentity.LegalEntity{
+		ID:        types.GenerateUUID(),
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}
+	r.ApplyTo(entity)
+	return entity
+}
+
+// ApplyTo replaces the fields of the entity. It expects a validated request
+func (r *CreateLegalEntityRequest) ApplyTo(e *legalentity.LegalEntity) {
+	taxIDs, _ := toTaxIDs(r.TaxIDs)
+	e.Name = r.Name
+	e.Address = r.Address
+	e.TaxIDs = taxIDs
+	e.InvoicePrefix = r.InvoicePrefix
+	e.InvoiceSeparator = r.InvoiceSeparator
+	e.InvoicePadding = r.InvoicePadding
+	if e.InvoicePadding == 0 {
+		e.InvoicePadding = sequence.DefaultInvoicePadding
+	}
+	e.InvoiceResetYearly = r.InvoiceResetYearly
+	e.BankDetails = r.BankDetails
+	e.PDFTemplate = r.PDFTemplate
+	e.IsDefault = r.IsDefault
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/mandate"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateMandateRequest struct {
+	// PaymentMethodID is the bank account payment method of the customer the
+	// mandate authorizes debits of
+	PaymentMethodID string              `json:"payment_method_id" validate:"required"`
+	Scheme          types.MandateScheme `json:"scheme" validate:"required"`
+	// Reference identifies the mandate to the bank ex the unique mandate reference of SEPA
+	Reference string `json:"reference" validate:"required" example:"FLEX-MANDATE-000001"`
+	// SignedAt defaults to now
+	SignedAt *time.Time `json:"signed_at,omitempty"`
+	// PreNotificationDays overrides the pre-notification period of the scheme,
+	// 14 days for SEPA and 10 days for ACH, when the mandate agrees on a shorter one
+	PreNotificationDays *int `json:"pre_notification_days,omitempty"`
+}
+
+func (r *CreateMandateRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.Scheme.Validate() {
+		return fmt.Errorf("invalid scheme %s", r.Scheme)
+	}
+	if r.PreNotificationDays != nil && (*r.PreNotificationDays < 1 || *r.PreNotificationDays > 30) {
+		return fmt.Errorf("pre_notification_days must be between 1 and 30")
+	}
+	return nil
+}
+
+type RevokeMandateRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type MandateResponse struct {
+	*mandate.Mandate
+}
+
+type ListMandatesResponse struct {
+	Mandates []MandateResponse `json:"mandates"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// MarginReportResponse is the gross margin of the charges invoiced over a
+// period, by group and currency
+type MarginReportResponse struct {
+	GroupBy   types.MarginGroupBy `json:"group_by"`
+	StartTime time.Time           `json:"start_time"`
+	EndTime   time.Time           `json:"end_time"`
+	Groups    []MarginGroup       `json:"groups"`
+}
+
+// MarginGroup is the revenue and cost of the charges of one customer, plan or
+// meter in one currency
+type MarginGroup struct {
+	// ID is the ID of the customer, plan or meter of the group
+	ID       string          `json:"id"`
+	Currency string          `json:"currency"`
+	Revenue  decimal.Decimal `json:"revenue" swaggertype:"string"`
+	Cost     decimal.Decimal `json:"cost" swaggertype:"string"`
+
+	// GrossMargin is the revenue less the cost, GrossMarginPercent is its share
+	// of the revenue and is omitted when there is no revenue
+	GrossMargin        decimal.Decimal  `json:"gross_margin" swaggertype:"string"`
+	GrossMarginPercent *decimal.Decimal `json:"gross_margin_percent,omitempty" swaggertype:"string"`
+
+	// UncostedRevenue is the part of the revenue billed by prices without a unit
+	// cost, whose cost is unknown and not counted
+	UncostedRevenue decimal.Decimal `json:"uncosted_revenue" swaggertype:"string"`
+}
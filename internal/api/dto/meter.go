@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/flexprice/flexprice/internal/domain/meter"
@@ -11,10 +12,14 @@ import (
 // CreateMeterRequest represents the request payload for creating a meter
 type CreateMeterRequest struct {
 	Name        string            `json:"name" binding:"required" example:"API Usage Meter"`
-	EventName   string            `json:"event_name" binding:"required" example:"api_request"`
-	Aggregation meter.Aggregation `json:"aggregation" binding:"required"`
+	EventName   string            `json:"event_name" example:"api_request"`
+	Aggregation meter.Aggregation `json:"aggregation"`
 	Filters     []meter.Filter    `json:"filters"`
 	ResetUsage  types.ResetUsage  `json:"reset_usage" example:"BILLING_PERIOD"`
+
+	// Formula creates a derived meter over the usage of other meters, in place
+	// of the event_name, aggregation and filters
+	Formula *meter.Formula `json:"formula,omitempty"`
 }
 
 // MeterResponse represents the meter response structure
@@ -26,6 +31,7 @@ type MeterResponse struct {
 	Aggregation meter.Aggregation `json:"aggregation"`
 	Filters     []meter.Filter    `json:"filters"`
 	ResetUsage  types.ResetUsage  `json:"reset_usage"`
+	Formula     *meter.Formula    `json:"formula,omitempty"`
 	CreatedAt   time.Time         `json:"created_at" example:"2024-03-20T15:04:05Z"`
 	UpdatedAt   time.Time         `json:"updated_at" example:"2024-03-20T15:04:05Z"`
 	Status      string            `json:"status" example:"published"`
@@ -41,6 +47,7 @@ func ToMeterResponse(m *meter.Meter) *MeterResponse {
 		Aggregation: m.Aggregation,
 		Filters:     m.Filters,
 		ResetUsage:  m.ResetUsage,
+		Formula:     m.Formula,
 		CreatedAt:   m.CreatedAt,
 		UpdatedAt:   m.UpdatedAt,
 		Status:      string(m.Status),
@@ -55,10 +62,71 @@ func (r *CreateMeterRequest) ToMeter(tenantID, createdBy string) *meter.Meter {
 	m.Filters = r.Filters
 	m.ResetUsage = r.ResetUsage
 	m.Status = types.StatusPublished
+	if r.Formula != nil {
+		m.Formula = r.Formula
+		m.Aggregation = meter.Aggregation{Type: types.AggregationFormula}
+	}
 	return m
 }
 
 // Request validations
 func (r *CreateMeterRequest) Validate() error {
-	return validator.New().Struct(r)
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if r.ResetUsage != "" && !r.ResetUsage.Validate() {
+		return fmt.Errorf("invalid reset_usage: %s", r.ResetUsage)
+	}
+
+	return nil
+}
+
+// CreateMeterVersionRequest changes the aggregation and filters of a meter from
+// a date on. Usage before the date keeps the definition it was aggregated with
+type CreateMeterVersionRequest struct {
+	Aggregation meter.Aggregation `json:"aggregation" binding:"required"`
+	Filters     []meter.Filter    `json:"filters"`
+
+	// EffectiveFrom is when the definition takes effect, now by default. It can
+	// not be in the past, past usage is only recomputed by a re-aggregation
+	EffectiveFrom *time.Time `json:"effective_from,omitempty" example:"2024-12-01T00:00:00Z"`
+}
+
+func (r *CreateMeterVersionRequest) Validate() error {
+	if r.EffectiveFrom != nil && r.EffectiveFrom.Before(time.Now().UTC().Add(-time.Minute)) {
+		return fmt.Errorf("effective_from can not be in the past, use a re-aggregation to recompute past usage")
+	}
+	return nil
+}
+
+// ReaggregateMeterRequest recomputes the usage of a period with the definition
+// of a version of the meter
+type ReaggregateMeterRequest struct {
+	StartTime time.Time `json:"start_time" binding:"required" example:"2024-11-01T00:00:00Z"`
+	EndTime   time.Time `json:"end_time" binding:"required" example:"2024-12-01T00:00:00Z"`
+
+	// Version is the number of the version whose definition is applied, the
+	// current definition by default
+	Version int `json:"version,omitempty" example:"2"`
+}
+
+func (r *ReaggregateMeterRequest) Validate() error {
+	if !r.EndTime.After(r.StartTime) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	if r.EndTime.After(time.Now().UTC()) {
+		return fmt.Errorf("end_time can not be in the future, new definitions take effect with a meter version")
+	}
+	if r.Version < 0 {
+		return fmt.Errorf("invalid version: %d", r.Version)
+	}
+	return nil
+}
+
+// MeterVersionResponse is a definition of a meter over time. Re-aggregations
+// bound it with effective_to and report the recomputation of the rolled up
+// usage in reaggregation_status
+type MeterVersionResponse struct {
+	*meter.MeterVersion
 }
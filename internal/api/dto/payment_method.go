@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
+	"github.com/go-playground/validator/v10"
+)
+
+type AttachPaymentMethodRequest struct {
+	// ConnectionID is the payment gateway connection the payment method is vaulted at
+	ConnectionID string `json:"connection_id" validate:"required"`
+
+	// GatewayPaymentMethodID is the payment method collected at the gateway ex with Stripe.js
+	GatewayPaymentMethodID string `json:"gateway_payment_method_id" validate:"required" example:"pm_1NqK2aLkdIwHu7ix"`
+
+	// SetDefault makes it the default payment method of the customer. The first
+	// payment method of a customer is always the default
+	SetDefault bool `json:"set_default"`
+}
+
+func (r *AttachPaymentMethodRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+type PaymentMethodResponse struct {
+	*paymentmethod.PaymentMethod
+
+	// IsDefault is set on the payment method the customer is charged to
+	IsDefault bool `json:"is_default"`
+}
+
+type ListPaymentMethodsResponse struct {
+	PaymentMethods []PaymentMethodResponse `json:"payment_methods"`
+}
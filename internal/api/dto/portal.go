@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CreatePortalSessionRequest struct {
+	CustomerID string `json:"customer_id" validate:"required" example:"cust_123"`
+}
+
+// PortalSessionResponse carries a short lived token scoped to a single customer.
+// It can be handed to the customer's browser and used with the /portal endpoints
+type PortalSessionResponse struct {
+	Token      string    `json:"token"`
+	CustomerID string    `json:"customer_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type PortalUsageResponse struct {
+	Subscriptions []PortalSubscriptionUsage `json:"subscriptions"`
+}
+
+type PortalSubscriptionUsage struct {
+	SubscriptionID string `json:"subscription_id"`
+	PlanID         string `json:"plan_id"`
+	*GetUsageBySubscriptionResponse
+}
+
+func (r *CreatePortalSessionRequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/flexprice/flexprice/internal/domain/price"
 	"github.com/flexprice/flexprice/internal/types"
 	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -29,6 +28,10 @@ type CreatePriceRequest struct {
 	TierMode           types.BillingTier        `json:"tier_mode,omitempty"`
 	Tiers              []CreatePriceTier        `json:"tiers,omitempty"`
 	TransformQuantity  *price.TransformQuantity `json:"transform_quantity,omitempty"`
+
+	// UnitCost is the cost of goods sold of one unit billed by the price, in the
+	// currency of the price, used by the margin reports
+	UnitCost *string `json:"unit_cost,omitempty"`
 }
 
 type CreatePriceTier struct {
@@ -50,6 +53,10 @@ func (r *CreatePriceRequest) Validate() error {
 		return fmt.Errorf("amount must be greater than 0")
 	}
 
+	if _, err := parseUnitCost(r.UnitCost); err != nil {
+		return err
+	}
+
 	// Ensure currency is lowercase
 	r.Currency = strings.ToLower(r.Currency)
 
@@ -169,8 +176,13 @@ func (r *CreatePriceRequest) ToPrice(ctx context.Context) (*price.Price, error)
 		tiers = price.JSONBTiers(priceTiers)
 	}
 
+	unitCost, err := parseUnitCost(r.UnitCost)
+	if err != nil {
+		return nil, err
+	}
+
 	price := &price.Price{
-		ID:                 uuid.New().String(),
+		ID:                 types.GenerateUUID(),
 		Amount:             amount,
 		Currency:           r.Currency,
 		PlanID:             r.PlanID,
@@ -187,6 +199,7 @@ func (r *CreatePriceRequest) ToPrice(ctx context.Context) (*price.Price, error)
 		TierMode:           r.TierMode,
 		Tiers:              tiers,
 		TransformQuantity:  transformQuantity,
+		UnitCost:           unitCost,
 		BaseModel:          types.GetDefaultBaseModel(ctx),
 	}
 	price.DisplayAmount = price.GetDisplayAmount()
@@ -197,6 +210,30 @@ type UpdatePriceRequest struct {
 	LookupKey   string            `json:"lookup_key"`
 	Description string            `json:"description"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+
+	// UnitCost replaces the unit cost of the price, it is removed when empty
+	UnitCost *string `json:"unit_cost,omitempty"`
+}
+
+// GetUnitCost returns the unit cost of the request, nil when it has none
+func (r *UpdatePriceRequest) GetUnitCost() (*decimal.Decimal, error) {
+	return parseUnitCost(r.UnitCost)
+}
+
+// parseUnitCost parses an optional unit cost, which can not be negative
+func parseUnitCost(unitCost *string) (*decimal.Decimal, error) {
+	if unitCost == nil || *unitCost == "" {
+		return nil, nil
+	}
+
+	cost, err := decimal.NewFromString(*unitCost)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unit_cost format: %w", err)
+	}
+	if cost.IsNegative() {
+		return nil, fmt.Errorf("unit_cost can not be negative")
+	}
+	return &cost, nil
 }
 
 type PriceResponse struct {
@@ -0,0 +1,110 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// CreatePriceBookRequest creates a price book overriding prices of a plan. With
+// a segment, it applies to the new subscriptions of the customers of the segment
+type CreatePriceBookRequest struct {
+	Name      string              `json:"name" validate:"required,max=255" example:"Annual discount test"`
+	PlanID    string              `json:"plan_id" validate:"required"`
+	Segment   string              `json:"segment,omitempty" validate:"max=255" example:"smb"`
+	Overrides []ratecard.Override `json:"overrides" validate:"required,min=1"`
+}
+
+type PriceBookResponse struct {
+	*pricebook.PriceBook
+}
+
+type ListPriceBooksResponse struct {
+	PriceBooks []PriceBookResponse `json:"price_books"`
+}
+
+// ListPriceBooksRequest lists the price books of a plan
+type ListPriceBooksRequest struct {
+	PlanID string `form:"plan_id" validate:"required"`
+}
+
+// PriceBookReportRequest is the plan and the window the subscriptions reported
+// on were created in. The window defaults to the last 30 days
+type PriceBookReportRequest struct {
+	PlanID    string    `form:"plan_id" validate:"required"`
+	StartTime time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime   time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// PriceBookReportResponse compares the subscriptions created on each price book
+// of a plan with the ones created on its catalog prices
+type PriceBookReportResponse struct {
+	PlanID     string           `json:"plan_id"`
+	StartTime  time.Time        `json:"start_time"`
+	EndTime    time.Time        `json:"end_time"`
+	PriceBooks []PriceBookStats `json:"price_books"`
+}
+
+// PriceBookStats are the conversion and revenue of the subscriptions created on
+// a price book in the window. An empty PriceBookID stands for the catalog prices
+type PriceBookStats struct {
+	PriceBookID   string `json:"price_book_id"`
+	Name          string `json:"name"`
+	Subscriptions int    `json:"subscriptions"`
+	// TrialsEnded is the number of subscriptions whose trial is over, of which
+	// Converted were not cancelled by the end of their trial
+	TrialsEnded int `json:"trials_ended"`
+	Converted   int `json:"converted"`
+	// ConversionRate is the share of the ended trials which converted
+	ConversionRate decimal.Decimal `json:"conversion_rate" swaggertype:"string"`
+	Cancelled      int             `json:"cancelled"`
+	// Revenue is the amount finalized for the subscriptions since the start of
+	// the window, per currency
+	Revenue []PriceBookRevenue `json:"revenue"`
+}
+
+type PriceBookRevenue struct {
+	Currency        string          `json:"currency"`
+	Amount          decimal.Decimal `json:"amount" swaggertype:"string"`
+	PerSubscription decimal.Decimal `json:"per_subscription" swaggertype:"string"`
+}
+
+func (r *CreatePriceBookRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	return validateOverrides(r.Overrides)
+}
+
+func (r *ListPriceBooksRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *PriceBookReportRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.StartTime.IsZero() && !r.EndTime.IsZero() && !r.EndTime.After(r.StartTime) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	return nil
+}
+
+func (r *CreatePriceBookRequest) ToPriceBook(ctx context.Context) *pricebook.PriceBook {
+	return &pricebook.PriceBook{
+		ID:        types.GenerateUUID(),
+		Name:      r.Name,
+		PlanID:    r.PlanID,
+		Segment:   r.Segment,
+		Overrides: r.Overrides,
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}
+}
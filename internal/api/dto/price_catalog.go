@@ -0,0 +1,340 @@
+package dto
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// PlanPriceRow is a price of a plan as exported and imported in bulk. Rows
+// with an id or a known lookup key update that price, the others create one
+type PlanPriceRow struct {
+	ID string `json:"id,omitempty"`
+	CreatePriceRequest
+}
+
+type ExportPlanPricesResponse struct {
+	PlanID string         `json:"plan_id"`
+	Prices []PlanPriceRow `json:"prices"`
+}
+
+type ImportPlanPricesRequest struct {
+	Prices []PlanPriceRow `json:"prices"`
+
+	// DryRun reports what the import would do without applying it
+	DryRun bool `json:"dry_run" form:"dry_run"`
+
+	// ArchiveMissing archives the prices of the plan which are not in the import
+	ArchiveMissing bool `json:"archive_missing" form:"archive_missing"`
+}
+
+type ImportPlanPricesResponse struct {
+	DryRun bool `json:"dry_run"`
+
+	// Applied is false when the import was a dry run or when any row is invalid,
+	// the rows are applied all together or not at all
+	Applied bool `json:"applied"`
+
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Replaced  int `json:"replaced"`
+	Archived  int `json:"archived"`
+	Unchanged int `json:"unchanged"`
+	Failed    int `json:"failed"`
+
+	Results []PriceImportResult `json:"results"`
+}
+
+type PriceImportResult struct {
+	// Row is the position of the price in the import starting at 1, it is 0
+	// for the prices archived because they are missing from the import
+	Row    int                     `json:"row"`
+	Action types.PriceImportAction `json:"action,omitempty"`
+
+	// PriceID is the price created or changed, created prices have no id in a dry run
+	PriceID string `json:"price_id,omitempty"`
+
+	// ReplacedPriceID is the archived price when the action is replace
+	ReplacedPriceID string `json:"replaced_price_id,omitempty"`
+
+	LookupKey string `json:"lookup_key,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// NewPlanPriceRow converts a price to its bulk export row
+func NewPlanPriceRow(p *price.Price) PlanPriceRow {
+	row := PlanPriceRow{
+		ID: p.ID,
+		CreatePriceRequest: CreatePriceRequest{
+			Amount:             p.Amount.String(),
+			Currency:           p.Currency,
+			Type:               p.Type,
+			BillingPeriod:      p.BillingPeriod,
+			BillingPeriodCount: p.BillingPeriodCount,
+			BillingModel:       p.BillingModel,
+			BillingCadence:     p.BillingCadence,
+			MeterID:            p.MeterID,
+			LookupKey:          p.LookupKey,
+			Description:        p.Description,
+			TierMode:           p.TierMode,
+		},
+	}
+
+	if len(p.FilterValues) > 0 {
+		row.FilterValues = p.FilterValues
+	}
+	if len(p.Metadata) > 0 {
+		row.Metadata = p.Metadata
+	}
+	if p.TransformQuantity != (price.JSONBTransformQuantity{}) {
+		transform := price.TransformQuantity(p.TransformQuantity)
+		row.TransformQuantity = &transform
+	}
+	for _, tier := range p.Tiers {
+		t := CreatePriceTier{UpTo: tier.UpTo, UnitAmount: tier.UnitAmount.String()}
+		if tier.FlatAmount != nil {
+			flat := tier.FlatAmount.String()
+			t.FlatAmount = &flat
+		}
+		row.Tiers = append(row.Tiers, t)
+	}
+
+	return row
+}
+
+// planPriceColumns are the columns of the CSV file of the prices of a plan. A
+// tiered price spans one line per tier, the lines after the first one only
+// have the tier columns set
+var planPriceColumns = []string{
+	"id", "lookup_key", "description", "type", "currency", "amount",
+	"billing_period", "billing_period_count", "billing_model", "billing_cadence",
+	"meter_id", "tier_mode", "tier_up_to", "tier_unit_amount", "tier_flat_amount",
+	"divide_by", "round", "filter_values", "metadata",
+}
+
+func isTierColumn(column string) bool {
+	return strings.HasPrefix(column, "tier_") && column != "tier_mode"
+}
+
+// WritePlanPricesCSV writes the rows in the CSV format read by ParsePlanPricesCSV
+func WritePlanPricesCSV(w io.Writer, rows []PlanPriceRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(planPriceColumns); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		values := map[string]string{
+			"id":                   row.ID,
+			"lookup_key":           row.LookupKey,
+			"description":          row.Description,
+			"type":                 string(row.Type),
+			"currency":             row.Currency,
+			"amount":               row.Amount,
+			"billing_period":       string(row.BillingPeriod),
+			"billing_period_count": strconv.Itoa(row.BillingPeriodCount),
+			"billing_model":        string(row.BillingModel),
+			"billing_cadence":      string(row.BillingCadence),
+			"meter_id":             row.MeterID,
+			"tier_mode":            string(row.TierMode),
+		}
+		if row.TransformQuantity != nil {
+			values["divide_by"] = strconv.Itoa(row.TransformQuantity.DivideBy)
+			values["round"] = row.TransformQuantity.Round
+		}
+		if len(row.FilterValues) > 0 {
+			data, err := json.Marshal(row.FilterValues)
+			if err != nil {
+				return err
+			}
+			values["filter_values"] = string(data)
+		}
+		if len(row.Metadata) > 0 {
+			data, err := json.Marshal(row.Metadata)
+			if err != nil {
+				return err
+			}
+			values["metadata"] = string(data)
+		}
+
+		tiers := row.Tiers
+		if len(tiers) == 0 {
+			tiers = []CreatePriceTier{{}}
+		}
+		for i, tier := range tiers {
+			if i > 0 {
+				values = map[string]string{}
+			}
+			if tier.UpTo != nil {
+				values["tier_up_to"] = strconv.FormatUint(*tier.UpTo, 10)
+			}
+			values["tier_unit_amount"] = tier.UnitAmount
+			if tier.FlatAmount != nil {
+				values["tier_flat_amount"] = *tier.FlatAmount
+			}
+
+			record := make([]string, len(planPriceColumns))
+			for j, column := range planPriceColumns {
+				record[j] = values[column]
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ParsePlanPricesCSV reads the prices of a plan from a CSV file written by
+// WritePlanPricesCSV. Columns are matched by the header and may be omitted
+func ParsePlanPricesCSV(r io.Reader) ([]PlanPriceRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	var rows []PlanPriceRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		continuation := true
+		for _, column := range planPriceColumns {
+			if !isTierColumn(column) && value(column) != "" {
+				continuation = false
+				break
+			}
+		}
+
+		tier, err := parsePlanPriceTier(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if continuation {
+			if tier == nil {
+				continue
+			}
+			if len(rows) == 0 {
+				return nil, fmt.Errorf("line %d: tier without a price", line)
+			}
+			last := &rows[len(rows)-1]
+			last.Tiers = append(last.Tiers, *tier)
+			continue
+		}
+
+		row, err := parsePlanPriceRow(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if tier != nil {
+			row.Tiers = append(row.Tiers, *tier)
+		}
+		rows = append(rows, *row)
+	}
+
+	return rows, nil
+}
+
+func parsePlanPriceRow(value func(string) string) (*PlanPriceRow, error) {
+	row := &PlanPriceRow{
+		ID: value("id"),
+		CreatePriceRequest: CreatePriceRequest{
+			LookupKey:      value("lookup_key"),
+			Description:    value("description"),
+			Type:           types.PriceType(value("type")),
+			Currency:       value("currency"),
+			Amount:         value("amount"),
+			BillingPeriod:  types.BillingPeriod(value("billing_period")),
+			BillingModel:   types.BillingModel(value("billing_model")),
+			BillingCadence: types.BillingCadence(value("billing_cadence")),
+			MeterID:        value("meter_id"),
+			TierMode:       types.BillingTier(value("tier_mode")),
+		},
+	}
+
+	if v := value("billing_period_count"); v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid billing_period_count: %s", v)
+		}
+		row.BillingPeriodCount = count
+	}
+
+	if v := value("divide_by"); v != "" {
+		divideBy, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid divide_by: %s", v)
+		}
+		row.TransformQuantity = &price.TransformQuantity{DivideBy: divideBy, Round: value("round")}
+	}
+
+	if v := value("filter_values"); v != "" {
+		if err := json.Unmarshal([]byte(v), &row.FilterValues); err != nil {
+			return nil, fmt.Errorf("invalid filter_values: %w", err)
+		}
+	}
+
+	if v := value("metadata"); v != "" {
+		if err := json.Unmarshal([]byte(v), &row.Metadata); err != nil {
+			return nil, fmt.Errorf("invalid metadata: %w", err)
+		}
+	}
+
+	return row, nil
+}
+
+func parsePlanPriceTier(value func(string) string) (*CreatePriceTier, error) {
+	upTo, unitAmount, flatAmount := value("tier_up_to"), value("tier_unit_amount"), value("tier_flat_amount")
+	if upTo == "" && unitAmount == "" && flatAmount == "" {
+		return nil, nil
+	}
+
+	tier := &CreatePriceTier{UnitAmount: unitAmount}
+	if upTo != "" {
+		parsed, err := strconv.ParseUint(upTo, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tier_up_to: %s", upTo)
+		}
+		tier.UpTo = &parsed
+	}
+	if flatAmount != "" {
+		tier.FlatAmount = &flatAmount
+	}
+
+	return tier, nil
+}
@@ -0,0 +1,158 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// CreateRateCardRequest creates the next version of the rate card of a customer,
+// or of one of its subscriptions when SubscriptionID is set
+type CreateRateCardRequest struct {
+	Name           string `json:"name" validate:"required,max=255" example:"Acme 2025 contract"`
+	CustomerID     string `json:"customer_id" validate:"required"`
+	SubscriptionID string `json:"subscription_id,omitempty"`
+	// EffectiveFrom is when the version starts applying, billing periods starting
+	// from then use it. It defaults to now
+	EffectiveFrom time.Time `json:"effective_from,omitempty"`
+	// EffectiveTo is the end of the contract, open ended when empty
+	EffectiveTo *time.Time          `json:"effective_to,omitempty"`
+	Overrides   []ratecard.Override `json:"overrides" validate:"required,min=1"`
+}
+
+type RateCardResponse struct {
+	*ratecard.RateCard
+}
+
+type ListRateCardsResponse struct {
+	RateCards []RateCardResponse `json:"rate_cards"`
+}
+
+// ListRateCardsRequest lists all the versions of the rate cards of a customer
+type ListRateCardsRequest struct {
+	CustomerID string `form:"customer_id" validate:"required"`
+}
+
+func (r *CreateRateCardRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if r.EffectiveTo != nil {
+		effectiveFrom := r.EffectiveFrom
+		if effectiveFrom.IsZero() {
+			effectiveFrom = time.Now().UTC()
+		}
+		if !r.EffectiveTo.After(effectiveFrom) {
+			return fmt.Errorf("effective_to must be after effective_from")
+		}
+	}
+
+	return validateOverrides(r.Overrides)
+}
+
+// validateOverrides checks that each override targets a single price or meter,
+// at most once, with an amount or tiers
+func validateOverrides(overrides []ratecard.Override) error {
+	keys := make(map[string]bool, len(overrides))
+	for i, override := range overrides {
+		if (override.PriceID == "") == (override.MeterID == "") {
+			return fmt.Errorf("override %d must set exactly one of price_id and meter_id", i)
+		}
+
+		if override.Amount == nil && len(override.Tiers) == 0 {
+			return fmt.Errorf("override %d must set an amount or tiers", i)
+		}
+
+		if override.Amount != nil && override.Amount.IsNegative() {
+			return fmt.Errorf("override %d amount can not be negative", i)
+		}
+
+		key := "price:" + override.PriceID
+		if override.MeterID != "" {
+			key = "meter:" + override.MeterID
+		}
+		if keys[key] {
+			return fmt.Errorf("override %d duplicates another override of the same price or meter", i)
+		}
+		keys[key] = true
+	}
+
+	return nil
+}
+
+func (r *ListRateCardsRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func (r *CreateRateCardRequest) ToRateCard(ctx context.Context) *ratecard.RateCard {
+	effectiveFrom := r.EffectiveFrom
+	if effectiveFrom.IsZero() {
+		effectiveFrom = time.Now().UTC()
+	}
+
+	return &ratecard.RateCard{
+		ID:             types.GenerateUUID(),
+		Name:           r.Name,
+		CustomerID:     r.CustomerID,
+		SubscriptionID: r.SubscriptionID,
+		EffectiveFrom:  effectiveFrom,
+		EffectiveTo:    r.EffectiveTo,
+		Overrides:      r.Overrides,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}
+}
+
+// CustomerRatesRequest asks for the rates a customer is billed at, at a point in
+// time which defaults to now
+type CustomerRatesRequest struct {
+	CustomerID string    `form:"customer_id" validate:"required"`
+	At         time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (r *CustomerRatesRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+// CustomerRatesResponse compares the rates of the ongoing subscriptions of the
+// customer with the list prices of their plans
+type CustomerRatesResponse struct {
+	CustomerID    string              `json:"customer_id"`
+	At            time.Time           `json:"at"`
+	Subscriptions []SubscriptionRates `json:"subscriptions"`
+}
+
+// SubscriptionRates are the rates of the prices of the plan of a subscription
+type SubscriptionRates struct {
+	SubscriptionID string `json:"subscription_id"`
+	PlanID         string `json:"plan_id"`
+	// RateCardID and RateCardVersion identify the rate card in effect, if any
+	RateCardID      string `json:"rate_card_id,omitempty"`
+	RateCardVersion int    `json:"rate_card_version,omitempty"`
+	PriceBookID     string `json:"price_book_id,omitempty"`
+	Rates           []Rate `json:"rates"`
+}
+
+// Rate is the list price of the plan next to the price the subscription is
+// billed at
+type Rate struct {
+	PriceID  string `json:"price_id"`
+	MeterID  string `json:"meter_id,omitempty"`
+	Currency string `json:"currency"`
+	// Source is what sets the effective price: catalog, price_book or rate_card
+	Source          string            `json:"source"`
+	ListAmount      decimal.Decimal   `json:"list_amount" swaggertype:"string"`
+	EffectiveAmount decimal.Decimal   `json:"effective_amount" swaggertype:"string"`
+	ListTiers       []price.PriceTier `json:"list_tiers,omitempty"`
+	EffectiveTiers  []price.PriceTier `json:"effective_tiers,omitempty"`
+	// Difference is the effective amount minus the list amount, and
+	// DifferencePercent its share of the list amount when not zero
+	Difference        decimal.Decimal  `json:"difference" swaggertype:"string"`
+	DifferencePercent *decimal.Decimal `json:"difference_percent,omitempty" swaggertype:"string"`
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReceivablesReportResponse is the amount outstanding on the finalized invoices
+// by currency and by customer, aged in days since the invoices were finalized
+type ReceivablesReportResponse struct {
+	// AsOf is when the invoices were aged
+	AsOf time.Time `json:"as_of"`
+
+	// Snapshotted reports whether the report was served from the snapshot of a
+	// past day, today's report is computed on every request
+	Snapshotted bool                  `json:"snapshotted"`
+	Currencies  []ReceivablesAging    `json:"currencies"`
+	Customers   []CustomerReceivables `json:"customers"`
+}
+
+// ReceivablesAging is the amount outstanding in a currency split in buckets by
+// age: current under 30 days, then 30 to 59, 60 to 89 and 90 days or more
+type ReceivablesAging struct {
+	Currency   string          `json:"currency"`
+	Current    decimal.Decimal `json:"current" swaggertype:"string"`
+	Days30     decimal.Decimal `json:"days_30" swaggertype:"string"`
+	Days60     decimal.Decimal `json:"days_60" swaggertype:"string"`
+	Days90Plus decimal.Decimal `json:"days_90_plus" swaggertype:"string"`
+	Total      decimal.Decimal `json:"total" swaggertype:"string"`
+
+	// Invoices is the number of outstanding invoices
+	Invoices int `json:"invoices"`
+}
+
+// CustomerReceivables is the amount a customer owes in a currency
+type CustomerReceivables struct {
+	CustomerID string `json:"customer_id"`
+	ReceivablesAging
+}
+
+// WriteOffReportResponse is the amount written off as uncollectible by
+// currency, with the invoices written off
+type WriteOffReportResponse struct {
+	Currencies []WriteOffTotal     `json:"currencies"`
+	Invoices   []WrittenOffInvoice `json:"invoices"`
+}
+
+// WriteOffTotal is the amount written off in a currency
+type WriteOffTotal struct {
+	Currency string          `json:"currency"`
+	Amount   decimal.Decimal `json:"amount" swaggertype:"string"`
+
+	// Invoices is the number of invoices written off
+	Invoices int `json:"invoices"`
+}
+
+type WrittenOffInvoice struct {
+	InvoiceID     string `json:"invoice_id"`
+	InvoiceNumber string `json:"invoice_number,omitempty"`
+	CustomerID    string `json:"customer_id"`
+	Currency      string `json:"currency"`
+
+	AmountWrittenOff decimal.Decimal `json:"amount_written_off" swaggertype:"string"`
+	// AmountRemaining is the part of the amount written off still not paid, it
+	// is lower when payments were recovered after the write-off
+	AmountRemaining decimal.Decimal `json:"amount_remaining" swaggertype:"string"`
+
+	Reason       string    `json:"reason"`
+	WrittenOffAt time.Time `json:"written_off_at"`
+}
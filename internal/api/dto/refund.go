@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// CreateRefundRequest refunds part or all of an invoice payment
+type CreateRefundRequest struct {
+	// Amount defaults to the part of the payment not refunded yet
+	Amount *decimal.Decimal `json:"amount,omitempty" swaggertype:"string" example:"10.00"`
+	Reason string           `json:"reason,omitempty" validate:"max=500" example:"Duplicate charge"`
+
+	// CreateCreditNote issues a credit note for the refunded amount against the
+	// invoice of the payment so that the invoice stays settled. Without it the
+	// refunded amount is owed again on the invoice
+	CreateCreditNote bool `json:"create_credit_note,omitempty"`
+}
+
+func (r *CreateRefundRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if r.Amount != nil && !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
+}
+
+type RefundResponse struct {
+	*invoice.Refund
+}
+
+type ListRefundsResponse struct {
+	Refunds []RefundResponse `json:"refunds"`
+	// Refundable is the part of the payment not refunded yet
+	Refundable decimal.Decimal `json:"refundable" swaggertype:"string"`
+}
@@ -0,0 +1,132 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/report"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+// ReportTemplateRequest creates a report template or replaces all of its fields
+type ReportTemplateRequest struct {
+	Name   string             `json:"name" validate:"required" example:"Monthly revenue by currency"`
+	Entity types.ReportEntity `json:"entity" validate:"required" example:"invoices"`
+
+	Columns []report.Column `json:"columns" validate:"required,min=1"`
+	Filters []report.Filter `json:"filters"`
+	// GroupBy aggregates the records by the listed fields, whose columns are
+	// not aggregated. The other columns must be aggregated
+	GroupBy []string `json:"group_by" example:"currency"`
+
+	Period   types.ReportPeriod   `json:"period" example:"previous_month"`
+	Schedule types.ReportSchedule `json:"schedule" example:"monthly"`
+
+	Destinations []report.Destination `json:"destinations"`
+}
+
+type ReportTemplateResponse struct {
+	*report.Template
+}
+
+type ListReportTemplatesResponse struct {
+	Templates []ReportTemplateResponse `json:"templates"`
+	Total     int                      `json:"total"`
+	Offset    int                      `json:"offset"`
+	Limit     int                      `json:"limit"`
+}
+
+type ReportRunResponse struct {
+	*report.Run
+}
+
+type ListReportRunsResponse struct {
+	Runs   []ReportRunResponse `json:"runs"`
+	Total  int                 `json:"total"`
+	Offset int                 `json:"offset"`
+	Limit  int                 `json:"limit"`
+}
+
+// ReportCompletedEvent is the data of the report.completed webhook event
+type ReportCompletedEvent struct {
+	Run          *report.Run `json:"run"`
+	TemplateName string      `json:"template_name"`
+	URL          string      `json:"url"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+}
+
+func (r *ReportTemplateRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	switch r.Period {
+	case types.ReportPeriodAll, types.ReportPeriodPreviousDay, types.ReportPeriodPreviousWeek, types.ReportPeriodPreviousMonth:
+	default:
+		return fmt.Errorf("invalid period: %s", r.Period)
+	}
+
+	switch r.Schedule {
+	case types.ReportScheduleNone, types.ReportScheduleDaily, types.ReportScheduleWeekly, types.ReportScheduleMonthly:
+	default:
+		return fmt.Errorf("invalid schedule: %s", r.Schedule)
+	}
+
+	for _, d := range r.Destinations {
+		switch d.Type {
+		case types.ReportDestinationEmail:
+			if len(d.Recipients) == 0 {
+				return fmt.Errorf("recipients are required for email destinations")
+			}
+			for _, recipient := range d.Recipients {
+				if _, err := mail.ParseAddress(recipient); err != nil {
+					return fmt.Errorf("invalid recipient: %s", recipient)
+				}
+			}
+		case types.ReportDestinationS3:
+			if strings.HasPrefix(d.Prefix, "/") || strings.Contains(d.Prefix, "..") {
+				return fmt.Errorf("invalid s3 prefix: %s", d.Prefix)
+			}
+		case types.ReportDestinationWebhook:
+		default:
+			return fmt.Errorf("invalid destination type: %s", d.Type)
+		}
+	}
+
+	return nil
+}
+
+func (r *ReportTemplateRequest) ToTemplate(ctx context.Context, now time.Time) *report.Template {
+	t := &report.Template{
+		ID:        types.GenerateUUID(),
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}
+	r.ApplyTo(t, now)
+	return t
+}
+
+// ApplyTo replaces the editable fields of the template and schedules its next
+// run after now
+func (r *ReportTemplateRequest) ApplyTo(t *report.Template, now time.Time) {
+	t.Name = r.Name
+	t.Entity = r.Entity
+	t.Columns = r.Columns
+	t.Filters = r.Filters
+	t.GroupBy = pq.StringArray(r.GroupBy)
+	if t.GroupBy == nil {
+		t.GroupBy = pq.StringArray{}
+	}
+	t.Period = r.Period
+	t.Destinations = r.Destinations
+
+	t.Schedule = r.Schedule
+	t.NextRunAt = nil
+	if next := r.Schedule.Next(now); !next.IsZero() {
+		t.NextRunAt = &next
+	}
+}
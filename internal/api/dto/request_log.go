@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/flexprice/flexprice/internal/domain/requestlog"
+
+type RequestLogResponse struct {
+	*requestlog.RequestLog
+}
+
+type ListRequestLogsResponse struct {
+	Logs   []RequestLogResponse `json:"logs"`
+	Total  int                  `json:"total"`
+	Offset int                  `json:"offset"`
+	Limit  int                  `json:"limit"`
+}
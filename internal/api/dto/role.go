@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/role"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+type RoleResponse struct {
+	Role        types.Role         `json:"role"`
+	Permissions []types.Permission `json:"permissions"`
+}
+
+type ListRolesResponse struct {
+	Roles []RoleResponse `json:"roles"`
+}
+
+// AssignRoleRequest sets the role of a user, replacing the role the user had
+// in the same scope
+type AssignRoleRequest struct {
+	UserID string     `json:"user_id" validate:"required"`
+	Role   types.Role `json:"role" validate:"required" example:"billing_manager"`
+	// EnvironmentID scopes the role to an environment. The role applies to
+	// all the environments without an assignment of their own when empty
+	EnvironmentID string `json:"environment_id,omitempty"`
+}
+
+type RoleAssignmentResponse struct {
+	*role.Assignment
+}
+
+type ListRoleAssignmentsResponse struct {
+	Assignments []RoleAssignmentResponse `json:"assignments"`
+}
+
+func (r *AssignRoleRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.Role.Validate() {
+		return fmt.Errorf("invalid role: %s", r.Role)
+	}
+
+	return nil
+}
+
+func (r *AssignRoleRequest) ToAssignment(ctx context.Context) *role.Assignment {
+	return &role.Assignment{
+		ID:            types.GenerateUUID(),
+		UserID:        r.UserID,
+		Role:          r.Role,
+		EnvironmentID: r.EnvironmentID,
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+}
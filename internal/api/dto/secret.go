@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/secret"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required" example:"Mobile app ingestion"`
+	// Scopes of the key, one or more of read_only, events, billing_admin
+	Scopes    []types.APIKeyScope `json:"scopes" validate:"required,min=1" example:"events"`
+	ExpiresAt *time.Time          `json:"expires_at,omitempty"`
+}
+
+type APIKeyResponse struct {
+	*secret.Secret
+}
+
+// CreateAPIKeyResponse carries the raw key. It is only returned once
+type CreateAPIKeyResponse struct {
+	*secret.Secret
+	APIKey string `json:"api_key"`
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+	Total   int              `json:"total"`
+	Offset  int              `json:"offset"`
+	Limit   int              `json:"limit"`
+}
+
+func (r *CreateAPIKeyRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	for _, scope := range r.Scopes {
+		if !scope.Validate() {
+			return fmt.Errorf("invalid scope: %s", scope)
+		}
+	}
+
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now().UTC()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+
+	return nil
+}
+
+func (r *CreateAPIKeyRequest) ToSecret(ctx context.Context, prefix, keyHash string) *secret.Secret {
+	scopes := make([]string, 0, len(r.Scopes))
+	for _, scope := range r.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return &secret.Secret{
+		ID:        types.GenerateUUID(),
+		Name:      r.Name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    scopes,
+		ExpiresAt: r.ExpiresAt,
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}
+}
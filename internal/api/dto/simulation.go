@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+)
+
+// AdvanceClockRequest moves the simulated clock forward, either to a time or by
+// a number of days and hours
+type AdvanceClockRequest struct {
+	To    *time.Time `json:"to,omitempty"`
+	Days  int        `json:"days,omitempty"`
+	Hours int        `json:"hours,omitempty"`
+}
+
+func (r *AdvanceClockRequest) Validate() error {
+	if r.Days < 0 || r.Hours < 0 {
+		return fmt.Errorf("days and hours must not be negative")
+	}
+	if r.To != nil && (r.Days > 0 || r.Hours > 0) {
+		return fmt.Errorf("either to or days and hours must be set, not both")
+	}
+	if r.To == nil && r.Days == 0 && r.Hours == 0 {
+		return fmt.Errorf("to, days or hours is required")
+	}
+	return nil
+}
+
+// Target returns the time the clock moves to from now
+func (r *AdvanceClockRequest) Target(now time.Time) time.Time {
+	if r.To != nil {
+		return r.To.UTC()
+	}
+	return now.AddDate(0, 0, r.Days).Add(time.Duration(r.Hours) * time.Hour)
+}
+
+type SimulationClockResponse struct {
+	Now       time.Time `json:"now"`
+	Simulated bool      `json:"simulated"`
+
+	// EnvironmentID is the sandbox environment persisting the simulated clock
+	EnvironmentID string `json:"environment_id,omitempty"`
+
+	// Runs are the billing jobs run once the clock moved, in the order they ran
+	Runs []JobRunResponse `json:"runs,omitempty"`
+}
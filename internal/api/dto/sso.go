@@ -0,0 +1,133 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/domain/sso"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+// SetSSOConfigRequest configures the identity provider the users of the tenant
+// sign in with, replacing the previous configuration
+type SetSSOConfigRequest struct {
+	Protocol types.SSOProtocol `json:"protocol" validate:"required" example:"oidc"`
+	// Domains are the email domains of the users signing in with the identity provider
+	Domains []string `json:"domains" validate:"required,min=1,dive,required,fqdn"`
+
+	OIDC *sso.OIDCSettings `json:"oidc_settings,omitempty"`
+	SAML *sso.SAMLSettings `json:"saml_settings,omitempty"`
+
+	// ClientSecret is the OIDC client secret. The current secret is kept when empty
+	ClientSecret string `json:"client_secret,omitempty"`
+
+	// GroupsClaim is the ID token claim or the SAML attribute listing the groups of the user
+	GroupsClaim string                `json:"groups_claim,omitempty" example:"groups"`
+	GroupRoles  map[string]types.Role `json:"group_roles,omitempty"`
+
+	JITProvisioning bool       `json:"jit_provisioning"`
+	DefaultRole     types.Role `json:"default_role,omitempty" example:"read_only"`
+
+	PasswordLoginDisabled bool `json:"password_login_disabled"`
+}
+
+type SSOConfigResponse struct {
+	*sso.Config
+	// The service provider details to register with the identity provider
+	OIDCRedirectURL string `json:"oidc_redirect_url,omitempty"`
+	SAMLEntityID    string `json:"saml_entity_id,omitempty"`
+	SAMLACSURL      string `json:"saml_acs_url,omitempty"`
+}
+
+// StartSSORequest starts the single sign on of the user with the identity
+// provider of the email domain
+type StartSSORequest struct {
+	Email string `json:"email" binding:"required,email" validate:"email"`
+}
+
+type StartSSOResponse struct {
+	// RedirectURL is the identity provider page to send the user to
+	RedirectURL string `json:"redirect_url"`
+}
+
+func (r *SetSSOConfigRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.Protocol.Validate() {
+		return fmt.Errorf("invalid protocol: %s", r.Protocol)
+	}
+
+	switch r.Protocol {
+	case types.SSOProtocolOIDC:
+		if r.OIDC == nil {
+			return fmt.Errorf("oidc_settings are required for the oidc protocol")
+		}
+		if err := validator.New().Struct(r.OIDC); err != nil {
+			return err
+		}
+	case types.SSOProtocolSAML:
+		if r.SAML == nil {
+			return fmt.Errorf("saml_settings are required for the saml protocol")
+		}
+		if err := validator.New().Struct(r.SAML); err != nil {
+			return err
+		}
+	}
+
+	for group, role := range r.GroupRoles {
+		if !role.Validate() {
+			return fmt.Errorf("invalid role %s for group %s", role, group)
+		}
+	}
+
+	if r.JITProvisioning && r.DefaultRole == "" {
+		return fmt.Errorf("default_role is required with jit_provisioning")
+	}
+	if r.DefaultRole != "" && !r.DefaultRole.Validate() {
+		return fmt.Errorf("invalid default role: %s", r.DefaultRole)
+	}
+
+	return nil
+}
+
+func (r *SetSSOConfigRequest) ToConfig(ctx context.Context) *sso.Config {
+	domains := make([]string, len(r.Domains))
+	for i, domain := range r.Domains {
+		domains[i] = strings.ToLower(domain)
+	}
+
+	groupRoles := make(sso.GroupRoles, len(r.GroupRoles))
+	for group, role := range r.GroupRoles {
+		groupRoles[group] = role
+	}
+
+	config := &sso.Config{
+		Protocol:              r.Protocol,
+		Domains:               domains,
+		ClientSecret:          r.ClientSecret,
+		GroupsClaim:           r.GroupsClaim,
+		GroupRoles:            groupRoles,
+		JITProvisioning:       r.JITProvisioning,
+		DefaultRole:           r.DefaultRole,
+		PasswordLoginDisabled: r.PasswordLoginDisabled,
+		BaseModel:             types.GetDefaultBaseModel(ctx),
+	}
+
+	// Only the settings of the protocol are kept
+	switch r.Protocol {
+	case types.SSOProtocolOIDC:
+		config.OIDC = r.OIDC
+	case types.SSOProtocolSAML:
+		config.SAML = r.SAML
+	}
+
+	return config
+}
+
+func (r *StartSSORequest) Validate() error {
+	return validator.New().Struct(r)
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "github.com/flexprice/flexprice/internal/types"
+
+type ImportStripeRequest struct {
+	// DryRun reports what the import would create and the conflicts it detects
+	// without creating anything
+	DryRun bool `json:"dry_run" form:"dry_run"`
+}
+
+type ImportStripeResponse struct {
+	DryRun bool `json:"dry_run"`
+
+	// Applied is false for dry runs, the objects are created all together or
+	// not at all
+	Applied bool `json:"applied"`
+
+	Created   int `json:"created"`
+	Existing  int `json:"existing"`
+	Conflicts int `json:"conflicts"`
+	Skipped   int `json:"skipped"`
+
+	Results []StripeImportResult `json:"results"`
+}
+
+type StripeImportResult struct {
+	// Object is product, price, customer or subscription
+	Object   string                   `json:"object"`
+	StripeID string                   `json:"stripe_id"`
+	Action   types.StripeImportAction `json:"action"`
+
+	// FlexpriceID is the record created, or the one the object was imported
+	// into or conflicts with. Created records have no id in a dry run
+	FlexpriceID string `json:"flexprice_id,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// Add records the result of an object
+func (r *ImportStripeResponse) Add(result StripeImportResult) {
+	switch result.Action {
+	case types.StripeImportActionCreate:
+		r.Created++
+	case types.StripeImportActionExists:
+		r.Existing++
+	case types.StripeImportActionConflict:
+		r.Conflicts++
+	case types.StripeImportActionSkip:
+		r.Skipped++
+	}
+	r.Results = append(r.Results, result)
+}
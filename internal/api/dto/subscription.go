@@ -2,28 +2,63 @@ package dto
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/flexprice/flexprice/internal/domain/price"
 	"github.com/flexprice/flexprice/internal/domain/subscription"
 	"github.com/flexprice/flexprice/internal/types"
 	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type CreateSubscriptionRequest struct {
-	CustomerID         string               `json:"customer_id" validate:"required"`
-	PlanID             string               `json:"plan_id" validate:"required"`
-	Currency           string               `json:"currency"`
-	LookupKey          string               `json:"lookup_key"`
-	StartDate          time.Time            `json:"start_date,omitempty"`
-	EndDate            *time.Time           `json:"end_date,omitempty"`
-	TrialStart         *time.Time           `json:"trial_start,omitempty"`
+	CustomerID string     `json:"customer_id" validate:"required"`
+	PlanID     string     `json:"plan_id" validate:"required"`
+	Currency   string     `json:"currency"`
+	LookupKey  string     `json:"lookup_key"`
+	StartDate  time.Time  `json:"start_date,omitempty"`
+	EndDate    *time.Time `json:"end_date,omitempty"`
+	// TrialEnd starts the subscription with a trial ending at this date. It
+	// overrides the trial period of the plan
 	TrialEnd           *time.Time           `json:"trial_end,omitempty"`
 	InvoiceCadence     types.InvoiceCadence `json:"invoice_cadence,omitempty"`
 	BillingCadence     types.BillingCadence `json:"billing_cadence,omitempty"`
 	BillingPeriod      types.BillingPeriod  `json:"billing_period,omitempty"`
 	BillingPeriodCount int                  `json:"billing_period_count,omitempty"`
+
+	// CommitmentAmount is the minimum amount billed per period
+	CommitmentAmount decimal.Decimal `json:"commitment_amount" swaggertype:"string"`
+
+	// BillingThreshold raises an interim invoice whenever the usage charges of the
+	// period exceed it. Zero disables threshold billing
+	BillingThreshold decimal.Decimal `json:"billing_threshold" swaggertype:"string"`
+
+	// BillingCycle aligns the periods on calendar boundaries when set to calendar.
+	// Defaults to anniversary
+	BillingCycle types.BillingCycle `json:"billing_cycle,omitempty"`
+	// BillingAnchorDay bills a monthly subscription on this day of the month
+	// whatever its start date, ex 15 to always bill on the 15th. Months shorter
+	// than the day are billed on their last day
+	BillingAnchorDay int `json:"billing_anchor_day,omitempty" validate:"omitempty,min=1,max=31" example:"15"`
+	// PartialPeriodBehavior is how the partial first period of a calendar or
+	// anchor day billed subscription is charged. Defaults to prorate
+	PartialPeriodBehavior types.PartialPeriodBehavior `json:"partial_period_behavior,omitempty"`
+	// PaymentMethodID is one of the payment methods of the customer, charged for
+	// the subscription instead of the default payment method of the customer
+	PaymentMethodID string `json:"payment_method_id,omitempty"`
+	// PriceBookID puts the subscription on a price book of its plan. Without it
+	// the price book of the segment of the customer applies, if any
+	PriceBookID string `json:"price_book_id,omitempty"`
+	// LegalEntityID issues the invoices of the subscription from one of the legal
+	// entities of the tenant instead of the entity of the customer
+	LegalEntityID string `json:"legal_entity_id,omitempty"`
+	// GeneratePastInvoices bills the periods elapsed before now when the start date
+	// is in the past. The subscription starts in the period containing now either way
+	GeneratePastInvoices bool `json:"generate_past_invoices,omitempty"`
+	// ConsolidatePastInvoices bills the elapsed periods on a single catch-up invoice
+	// instead of one invoice per period
+	ConsolidatePastInvoices bool `json:"consolidate_past_invoices,omitempty"`
 }
 
 type UpdateSubscriptionRequest struct {
@@ -32,9 +67,43 @@ type UpdateSubscriptionRequest struct {
 	CancelAtPeriodEnd bool                     `json:"cancel_at_period_end,omitempty"`
 }
 
+// CancelSubscriptionRequest records why the customer cancels. Reason is the code
+// of one of the tenant's cancellation reasons
+type CancelSubscriptionRequest struct {
+	CancelAtPeriodEnd bool   `json:"cancel_at_period_end"`
+	Reason            string `json:"reason" validate:"required" example:"too_expensive"`
+	Comment           string `json:"comment,omitempty" validate:"max=2000"`
+}
+
+// UpdateSubscriptionLineItemRequest changes the quantity or the unit amount of
+// a recurring fixed line item
+type UpdateSubscriptionLineItemRequest struct {
+	Quantity *decimal.Decimal `json:"quantity,omitempty" swaggertype:"string" example:"5"`
+
+	// UnitAmount overrides the amount of the price for the subscription
+	UnitAmount *decimal.Decimal `json:"unit_amount,omitempty" swaggertype:"string" example:"12.00"`
+
+	// ProrationBehavior is how the change is billed for the rest of the current
+	// period. Defaults to create_prorations
+	ProrationBehavior types.ProrationBehavior `json:"proration_behavior,omitempty"`
+}
+
+// SubscriptionLineItemResponse is the line item after the change along with
+// the proration billed on the next invoice, if any
+type SubscriptionLineItemResponse struct {
+	*subscription.LineItem
+	Proration *subscription.Proration `json:"proration,omitempty"`
+}
+
 type SubscriptionResponse struct {
 	*subscription.Subscription
 	Plan *PlanResponse `json:"plan"`
+	// Customer is only set when the customer is expanded
+	Customer *CustomerResponse `json:"customer,omitempty"`
+
+	// PastInvoices are the invoices of the elapsed periods of a backdated
+	// subscription, only set on creation
+	PastInvoices []InvoiceResponse `json:"past_invoices,omitempty"`
 }
 
 type ListSubscriptionsResponse struct {
@@ -42,9 +111,74 @@ type ListSubscriptionsResponse struct {
 	Total         int                     `json:"total"`
 	Offset        int                     `json:"offset"`
 	Limit         int                     `json:"limit"`
+	// NextCursor lists the following page in cursor pagination, it is empty on
+	// the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func (r *CreateSubscriptionRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if r.CommitmentAmount.IsNegative() {
+		return fmt.Errorf("commitment_amount must not be negative")
+	}
+
+	if r.BillingThreshold.IsNegative() {
+		return fmt.Errorf("billing_threshold must not be negative")
+	}
+
+	if r.BillingCycle != "" && !r.BillingCycle.Validate() {
+		return fmt.Errorf("invalid billing_cycle: %s", r.BillingCycle)
+	}
+
+	if r.BillingAnchorDay > 0 {
+		if r.BillingPeriod != types.BILLING_PERIOD_MONTHLY {
+			return fmt.Errorf("billing_anchor_day requires the monthly billing period")
+		}
+		if r.BillingCycle == types.BillingCycleCalendar {
+			return fmt.Errorf("billing_anchor_day can not be used with the calendar billing cycle")
+		}
+	}
+
+	if r.PartialPeriodBehavior != "" {
+		if !r.PartialPeriodBehavior.Validate() {
+			return fmt.Errorf("invalid partial_period_behavior: %s", r.PartialPeriodBehavior)
+		}
+		if r.BillingCycle != types.BillingCycleCalendar && r.BillingAnchorDay == 0 {
+			return fmt.Errorf("partial_period_behavior requires the calendar billing cycle or a billing_anchor_day")
+		}
+	}
+
+	if r.ConsolidatePastInvoices && !r.GeneratePastInvoices {
+		return fmt.Errorf("consolidate_past_invoices requires generate_past_invoices")
+	}
+
+	return nil
+}
+
+func (r *UpdateSubscriptionLineItemRequest) Validate() error {
+	if r.Quantity == nil && r.UnitAmount == nil {
+		return fmt.Errorf("quantity or unit_amount is required")
+	}
+
+	if r.Quantity != nil && r.Quantity.IsNegative() {
+		return fmt.Errorf("quantity must not be negative")
+	}
+
+	if r.UnitAmount != nil && r.UnitAmount.IsNegative() {
+		return fmt.Errorf("unit_amount must not be negative")
+	}
+
+	if r.ProrationBehavior != "" && !r.ProrationBehavior.Validate() {
+		return fmt.Errorf("invalid proration_behavior: %s", r.ProrationBehavior)
+	}
+
+	return nil
+}
+
+func (r *CancelSubscriptionRequest) Validate() error {
 	return validator.New().Struct(r)
 }
 
@@ -55,7 +189,7 @@ func (r *CreateSubscriptionRequest) ToSubscription(ctx context.Context) *subscri
 	}
 
 	return &subscription.Subscription{
-		ID:                 uuid.New().String(),
+		ID:                 types.GenerateUUID(),
 		CustomerID:         r.CustomerID,
 		PlanID:             r.PlanID,
 		Currency:           r.Currency,
@@ -63,14 +197,19 @@ func (r *CreateSubscriptionRequest) ToSubscription(ctx context.Context) *subscri
 		SubscriptionStatus: types.SubscriptionStatusActive,
 		StartDate:          r.StartDate,
 		EndDate:            r.EndDate,
-		TrialStart:         r.TrialStart,
-		TrialEnd:           r.TrialEnd,
 		InvoiceCadence:     r.InvoiceCadence,
 		BillingCadence:     r.BillingCadence,
 		BillingPeriod:      r.BillingPeriod,
 		BillingPeriodCount: r.BillingPeriodCount,
+		CommitmentAmount:   r.CommitmentAmount,
+		BillingThreshold:   r.BillingThreshold,
+		BillingCycle:       r.BillingCycle,
 		BillingAnchor:      r.StartDate,
+		BillingAnchorDay:   r.BillingAnchorDay,
+		LegalEntityID:      r.LegalEntityID,
 		BaseModel:          types.GetDefaultBaseModel(ctx),
+
+		PartialPeriodBehavior: r.PartialPeriodBehavior,
 	}
 }
 
@@ -98,3 +237,14 @@ type SubscriptionUsageByMetersResponse struct {
 	MeterDisplayName string             `json:"meter_display_name"`
 	Price            *price.Price       `json:"price"`
 }
+
+// UsageLockResponse is the usage lock status of the current period of a
+// subscription. Events with a timestamp inside the period are billed with it
+// until locks_at
+type UsageLockResponse struct {
+	SubscriptionID string                `json:"subscription_id"`
+	PeriodStart    time.Time             `json:"period_start"`
+	PeriodEnd      time.Time             `json:"period_end"`
+	LocksAt        time.Time             `json:"locks_at"`
+	Status         types.UsageLockStatus `json:"status"`
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/task"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateTaskRequest starts a task on a file either uploaded with the request
+// or downloaded from FileURL, such as a presigned URL
+type CreateTaskRequest struct {
+	Type       types.TaskType       `json:"task_type" form:"task_type" validate:"required" example:"CUSTOMER_IMPORT"`
+	FileFormat types.TaskFileFormat `json:"file_format" form:"file_format" validate:"required" example:"CSV"`
+	FileURL    string               `json:"file_url" form:"file_url" example:"https://bucket.s3.amazonaws.com/customers.csv"`
+}
+
+type TaskResponse struct {
+	*task.Task
+}
+
+type ListTasksResponse struct {
+	Tasks  []TaskResponse `json:"tasks"`
+	Total  int            `json:"total"`
+	Offset int            `json:"offset"`
+	Limit  int            `json:"limit"`
+}
+
+type TaskErrorReportResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Validate checks the request, hasFile tells whether a file was uploaded with it
+func (r *CreateTaskRequest) Validate(hasFile bool) error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if !r.Type.Validate() {
+		return fmt.Errorf("invalid task type: %s", r.Type)
+	}
+
+	if !r.FileFormat.Validate() {
+		return fmt.Errorf("invalid file format: %s", r.FileFormat)
+	}
+
+	if hasFile == (r.FileURL != "") {
+		return fmt.Errorf("either a file or a file_url is required")
+	}
+
+	if r.FileURL != "" {
+		u, err := url.Parse(r.FileURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid file_url: %s", r.FileURL)
+		}
+	}
+
+	return nil
+}
+
+func (r *CreateTaskRequest) ToTask(ctx context.Context) *task.Task {
+	return &task.Task{
+		ID:         types.GenerateUUID(),
+		Type:       r.Type,
+		FileFormat: r.FileFormat,
+		FileURL:    r.FileURL,
+		TaskStatus: types.TaskStatusPending,
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/go-playground/validator/v10"
+)
+
+type ValidateAddressRequest struct {
+	// Address is validated instead of the billing address of the customer
+	Address *customer.Address `json:"address,omitempty"`
+	// Update saves the resolved address as the billing address of the customer
+	Update bool `json:"update"`
+}
+
+func (r *ValidateAddressRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+type ValidateAddressResponse struct {
+	// Valid is false for addresses the tax provider could not resolve
+	Valid bool `json:"valid"`
+	// Address is the address as resolved by the tax provider
+	Address *customer.Address `json:"address,omitempty"`
+	Message string            `json:"message,omitempty"`
+	// Updated is true when the resolved address was saved as the billing address
+	Updated bool `json:"updated"`
+}
@@ -2,12 +2,12 @@ package dto
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/flexprice/flexprice/internal/domain/wallet"
 	"github.com/flexprice/flexprice/internal/types"
 	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -20,7 +20,7 @@ type CreateWalletRequest struct {
 
 func (r *CreateWalletRequest) ToWallet(ctx context.Context) *wallet.Wallet {
 	return &wallet.Wallet{
-		ID:           uuid.New().String(),
+		ID:           types.GenerateUUID(),
 		CustomerID:   r.CustomerID,
 		Currency:     r.Currency,
 		Metadata:     r.Metadata,
@@ -74,6 +74,32 @@ type TopUpWalletRequest struct {
 	Amount      decimal.Decimal `json:"amount" binding:"required"`
 	Description string          `json:"description,omitempty"`
 	Metadata    types.Metadata  `json:"metadata,omitempty"`
+	// ExpiresAt is when the credits of the top-up expire, they never expire when unset
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (r *TopUpWalletRequest) Validate() error {
+	if !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	return nil
+}
+
+// DebitWalletRequest represents a request to consume credits of a wallet
+type DebitWalletRequest struct {
+	Amount      decimal.Decimal `json:"amount" binding:"required"`
+	Description string          `json:"description,omitempty"`
+	Metadata    types.Metadata  `json:"metadata,omitempty"`
+}
+
+func (r *DebitWalletRequest) Validate() error {
+	if !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
 }
 
 // WalletBalanceResponse represents the real-time balance of a wallet
@@ -82,3 +108,39 @@ type WalletBalanceResponse struct {
 	BalanceUpdatedAt time.Time       `json:"balance_updated_at"`
 	*wallet.Wallet
 }
+
+// UpdateAutoTopUpRequest configures the auto top-up of a wallet
+type UpdateAutoTopUpRequest struct {
+	Enabled bool `json:"enabled"`
+	// Threshold is the balance below which the wallet is topped up
+	Threshold decimal.Decimal `json:"threshold" swaggertype:"string"`
+	// Amount is credited to the wallet on each top-up
+	Amount decimal.Decimal `json:"amount" swaggertype:"string"`
+	// DailyCap and MonthlyCap bound the amount topped up per UTC day and calendar
+	// month, zero meaning no cap
+	DailyCap   decimal.Decimal `json:"daily_cap" swaggertype:"string"`
+	MonthlyCap decimal.Decimal `json:"monthly_cap" swaggertype:"string"`
+}
+
+func (r *UpdateAutoTopUpRequest) Validate() error {
+	if !r.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	if r.Threshold.IsNegative() {
+		return fmt.Errorf("threshold must not be negative")
+	}
+	if r.DailyCap.IsNegative() || r.MonthlyCap.IsNegative() {
+		return fmt.Errorf("caps must not be negative")
+	}
+	if r.DailyCap.IsPositive() && r.DailyCap.LessThan(r.Amount) {
+		return fmt.Errorf("daily_cap must be at least the amount")
+	}
+	if r.MonthlyCap.IsPositive() && r.MonthlyCap.LessThan(r.Amount) {
+		return fmt.Errorf("monthly_cap must be at least the amount")
+	}
+	return nil
+}
+
+type AutoTopUpResponse struct {
+	*wallet.AutoTopUp
+}
@@ -0,0 +1,119 @@
+package dto
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+type CreateWebhookEndpointRequest struct {
+	URL         string `json:"url" validate:"required,url" example:"https://example.com/webhooks/flexprice"`
+	Description string `json:"description" example:"Billing events for the ledger service"`
+	// EventTypes limits the endpoint to the listed events, all events when empty
+	EventTypes []types.WebhookEventType `json:"event_types" example:"invoice.finalized"`
+}
+
+type UpdateWebhookEndpointRequest struct {
+	URL         string                   `json:"url" validate:"required,url" example:"https://example.com/webhooks/flexprice"`
+	Description string                   `json:"description"`
+	EventTypes  []types.WebhookEventType `json:"event_types" example:"invoice.finalized"`
+}
+
+type RotateWebhookSecretRequest struct {
+	// PreviousSecretTTLHours is how long deliveries stay signed with the previous
+	// secret as well as the new one. Defaults to 24, zero expires it right away
+	PreviousSecretTTLHours *int `json:"previous_secret_ttl_hours" validate:"omitempty,gte=0,lte=168" example:"24"`
+}
+
+type WebhookEndpointResponse struct {
+	*webhook.Endpoint
+}
+
+// WebhookEndpointSecretResponse is returned when the signing secret is created
+// or rotated, the only times it is shown
+type WebhookEndpointSecretResponse struct {
+	WebhookEndpointResponse
+	Secret string `json:"secret" example:"whsec_3f9a..."`
+}
+
+type ListWebhookEndpointsResponse struct {
+	Endpoints []WebhookEndpointResponse `json:"endpoints"`
+	Total     int                       `json:"total"`
+	Offset    int                       `json:"offset"`
+	Limit     int                       `json:"limit"`
+}
+
+type WebhookDeliveryResponse struct {
+	*webhook.Delivery
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Total      int                       `json:"total"`
+	Offset     int                       `json:"offset"`
+	Limit      int                       `json:"limit"`
+}
+
+func (r *CreateWebhookEndpointRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+	return validateWebhookEndpoint(r.URL, r.EventTypes)
+}
+
+func (r *CreateWebhookEndpointRequest) ToEndpoint(ctx context.Context, secret string) *webhook.Endpoint {
+	return &webhook.Endpoint{
+		ID:            types.GenerateUUID(),
+		EnvironmentID: types.GetEnvironmentID(ctx),
+		URL:           r.URL,
+		Description:   r.Description,
+		EventTypes:    webhookEventTypesToArray(r.EventTypes),
+		Secret:        secret,
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+}
+
+func (r *UpdateWebhookEndpointRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+	return validateWebhookEndpoint(r.URL, r.EventTypes)
+}
+
+func (r *RotateWebhookSecretRequest) Validate() error {
+	return validator.New().Struct(r)
+}
+
+func validateWebhookEndpoint(rawURL string, eventTypes []types.WebhookEventType) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("url must be an http or https url")
+	}
+
+	for _, t := range eventTypes {
+		if !t.Validate() {
+			return fmt.Errorf("invalid event type: %s", t)
+		}
+	}
+	return nil
+}
+
+func webhookEventTypesToArray(eventTypes []types.WebhookEventType) pq.StringArray {
+	result := make(pq.StringArray, len(eventTypes))
+	for i, t := range eventTypes {
+		result[i] = string(t)
+	}
+	return result
+}
+
+// ApplyTo replaces the editable fields of the endpoint
+func (r *UpdateWebhookEndpointRequest) ApplyTo(endpoint *webhook.Endpoint) {
+	endpoint.URL = r.URL
+	endpoint.Description = r.Description
+	endpoint.EventTypes = webhookEventTypesToArray(r.EventTypes)
+}
@@ -0,0 +1,147 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/api/grpc/flexpricev1"
+	"github.com/flexprice/flexprice/internal/auth"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodPermissions is the permission required by each method. Methods which
+// are not listed are rejected
+var methodPermissions = map[string]types.Permission{
+	flexpricev1.EventService_IngestEvent_FullMethodName:  types.PermissionEventsWrite,
+	flexpricev1.EventService_IngestEvents_FullMethodName: types.PermissionEventsWrite,
+	flexpricev1.EventService_GetUsage_FullMethodName:     types.PermissionRead,
+}
+
+// authenticator sets the user, tenant and environment of a call in its context
+// the same way the REST API does for requests
+type authenticator struct {
+	cfg           *config.Configuration
+	secretService service.SecretService
+	roleService   service.RoleService
+	logger        *logger.Logger
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *gogrpc.UnaryServerInfo, handler gogrpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) streamInterceptor(srv interface{}, ss gogrpc.ServerStream, info *gogrpc.StreamServerInfo, handler gogrpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a *authenticator) authorize(ctx context.Context, method string) (context.Context, error) {
+	permission, ok := methodPermissions[method]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !types.HasPermission(ctx, permission) {
+		return nil, status.Error(codes.PermissionDenied, "missing permission: "+string(permission))
+	}
+	return ctx, nil
+}
+
+// authenticate reads the API key from the x-api-key metadata, or else the
+// JWT token from the authorization metadata as a Bearer token
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	environmentID := firstValue(md, types.HeaderEnvironment)
+
+	if apiKey := firstValue(md, types.HeaderAPIKey); apiKey != "" {
+		key, err := a.secretService.VerifyAPIKey(ctx, apiKey)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid api key: "+err.Error())
+		}
+
+		ctx = context.WithValue(ctx, types.CtxUserID, key.CreatedBy)
+		ctx = context.WithValue(ctx, types.CtxTenantID, key.TenantID)
+		ctx = context.WithValue(ctx, types.CtxAPIKeyID, key.ID)
+		ctx = context.WithValue(ctx, types.CtxPermissions, types.PermissionsForScopes(key.Scopes))
+		ctx = setEnvironment(ctx, environmentID)
+
+		a.logger.Debugf("authenticated call: api_key_id=%s, tenant_id=%s env_id=%s",
+			key.ID, key.TenantID, environmentID)
+		return ctx, nil
+	}
+
+	authHeader := firstValue(md, types.HeaderAuthorization)
+	if authHeader == "" {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization header format")
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	claims, err := auth.NewProvider(a.cfg).ValidateToken(ctx, tokenString)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token: "+err.Error())
+	}
+	if claims == nil || claims.UserID == "" || claims.TenantID == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid token claims")
+	}
+
+	ctx = context.WithValue(ctx, types.CtxUserID, claims.UserID)
+	ctx = context.WithValue(ctx, types.CtxTenantID, claims.TenantID)
+	ctx = context.WithValue(ctx, types.CtxJWT, tokenString)
+	ctx = setEnvironment(ctx, environmentID)
+
+	permissions, err := a.roleService.GetPermissions(ctx, claims.UserID, environmentID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get user permissions: "+err.Error())
+	}
+	ctx = context.WithValue(ctx, types.CtxPermissions, permissions)
+
+	a.logger.Debugf("authenticated call: user_id=%s, tenant_id=%s env_id=%s",
+		claims.UserID, claims.TenantID, environmentID)
+	return ctx, nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func setEnvironment(ctx context.Context, environmentID string) context.Context {
+	if environmentID != "" {
+		ctx = context.WithValue(ctx, types.CtxEnvironmentID, environmentID)
+	}
+	return ctx
+}
+
+// authenticatedStream replaces the context of a stream with the authenticated one
+type authenticatedStream struct {
+	gogrpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
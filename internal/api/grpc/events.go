@@ -0,0 +1,182 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/api/grpc/flexpricev1"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxStreamErrors caps the errors reported at the end of an ingestion stream,
+// the failed count still includes every failed event
+const maxStreamErrors = 100
+
+type eventServer struct {
+	flexpricev1.UnimplementedEventServiceServer
+
+	eventService service.EventService
+	log          *logger.Logger
+}
+
+func (s *eventServer) IngestEvent(ctx context.Context, req *flexpricev1.IngestEventRequest) (*flexpricev1.IngestEventResponse, error) {
+	eventID, err := s.ingest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &flexpricev1.IngestEventResponse{EventId: eventID}, nil
+}
+
+func (s *eventServer) IngestEvents(stream flexpricev1.EventService_IngestEventsServer) error {
+	ctx := stream.Context()
+	resp := &flexpricev1.IngestEventsResponse{}
+
+	for index := int64(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.ingest(ctx, req); err != nil {
+			resp.Failed++
+			if len(resp.Errors) < maxStreamErrors {
+				resp.Errors = append(resp.Errors, &flexpricev1.IngestEventError{
+					Index:   index,
+					EventId: req.GetEventId(),
+					Error:   status.Convert(err).Message(),
+				})
+			}
+			continue
+		}
+		resp.Accepted++
+	}
+}
+
+// ingest publishes an event and returns its id
+func (s *eventServer) ingest(ctx context.Context, req *flexpricev1.IngestEventRequest) (string, error) {
+	ingestReq := &dto.IngestEventRequest{
+		EventName:          req.GetEventName(),
+		EventID:            req.GetEventId(),
+		CustomerID:         req.GetCustomerId(),
+		ExternalCustomerID: req.GetExternalCustomerId(),
+		Source:             req.GetSource(),
+	}
+	if req.GetTimestamp() != nil {
+		ingestReq.Timestamp = req.GetTimestamp().AsTime()
+	}
+	if req.GetProperties() != nil {
+		ingestReq.Properties = req.GetProperties().AsMap()
+	}
+
+	if err := ingestReq.Validate(); err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.eventService.CreateEvent(ctx, ingestReq); err != nil {
+		var validationErr *service.EventValidationError
+		if errors.As(err, &validationErr) {
+			return "", status.Error(codes.InvalidArgument, validationErr.Error())
+		}
+		if errors.Is(err, service.ErrUsageBlocked) {
+			return "", status.Error(codes.ResourceExhausted, err.Error())
+		}
+		s.log.Errorw("failed to ingest event", "error", err)
+		return "", status.Error(codes.Internal, "failed to ingest event")
+	}
+
+	return ingestReq.EventID, nil
+}
+
+func (s *eventServer) GetUsage(ctx context.Context, req *flexpricev1.GetUsageRequest) (*flexpricev1.GetUsageResponse, error) {
+	startTime, endTime, err := usageTimeRange(req.GetStartTime(), req.GetEndTime())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var filters map[string][]string
+	if len(req.GetFilters()) > 0 {
+		filters = make(map[string][]string, len(req.GetFilters()))
+		for key, values := range req.GetFilters() {
+			filters[key] = values.GetValues()
+		}
+	}
+
+	var result *events.AggregationResult
+	if req.GetMeterId() != "" {
+		result, err = s.eventService.GetUsageByMeter(ctx, &dto.GetUsageByMeterRequest{
+			MeterID:            req.GetMeterId(),
+			CustomerID:         req.GetCustomerId(),
+			ExternalCustomerID: req.GetExternalCustomerId(),
+			StartTime:          startTime,
+			EndTime:            endTime,
+			WindowSize:         types.WindowSize(req.GetWindowSize()),
+			Filters:            filters,
+		})
+	} else {
+		result, err = s.eventService.GetUsage(ctx, &dto.GetUsageRequest{
+			EventName:          req.GetEventName(),
+			PropertyName:       req.GetPropertyName(),
+			AggregationType:    req.GetAggregationType(),
+			CustomerID:         req.GetCustomerId(),
+			ExternalCustomerID: req.GetExternalCustomerId(),
+			StartTime:          startTime,
+			EndTime:            endTime,
+			WindowSize:         types.WindowSize(req.GetWindowSize()),
+			Filters:            filters,
+		})
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp := &flexpricev1.GetUsageResponse{
+		EventName: result.EventName,
+		Type:      string(result.Type),
+		Value:     result.Value.String(),
+		Results:   make([]*flexpricev1.UsageWindow, len(result.Results)),
+	}
+	for i, r := range result.Results {
+		resp.Results[i] = &flexpricev1.UsageWindow{
+			WindowStart: timestamppb.New(r.WindowSize),
+			Value:       r.Value.String(),
+		}
+	}
+
+	return resp, nil
+}
+
+// usageTimeRange defaults the usage period the same way the REST API does,
+// end time to now and start time to 3 days before the end time
+func usageTimeRange(start, end *timestamppb.Timestamp) (time.Time, time.Time, error) {
+	var startTime, endTime time.Time
+	if start != nil {
+		startTime = start.AsTime()
+	}
+	if end != nil {
+		endTime = end.AsTime()
+	}
+
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+	if startTime.IsZero() {
+		startTime = endTime.AddDate(0, 0, -3)
+	}
+	if endTime.Before(startTime) {
+		return time.Time{}, time.Time{}, errors.New("end time must be after start time")
+	}
+
+	return startTime.UTC(), endTime.UTC(), nil
+}
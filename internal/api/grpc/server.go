@@ -0,0 +1,35 @@
+// Package grpc serves the high frequency event APIs over gRPC for backend
+// services. The service is defined in proto/flexprice/v1 and its code is
+// generated in the flexpricev1 package
+package grpc
+
+import (
+	"github.com/flexprice/flexprice/internal/api/grpc/flexpricev1"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	gogrpc "google.golang.org/grpc"
+)
+
+// NewServer returns a gRPC server with the event service registered. Every
+// call is authenticated with the API key or the JWT token of its metadata
+func NewServer(
+	cfg *config.Configuration,
+	secretService service.SecretService,
+	roleService service.RoleService,
+	eventService service.EventService,
+	logger *logger.Logger,
+) *gogrpc.Server {
+	a := &authenticator{cfg: cfg, secretService: secretService, roleService: roleService, logger: logger}
+
+	server := gogrpc.NewServer(
+		gogrpc.UnaryInterceptor(a.unaryInterceptor),
+		gogrpc.StreamInterceptor(a.streamInterceptor),
+	)
+	flexpricev1.RegisterEventServiceServer(server, &eventServer{
+		eventService: eventService,
+		log:          logger,
+	})
+
+	return server
+}
@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/api/grpc/flexpricev1"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/suite"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type ServerSuite struct {
+	suite.Suite
+	ctx           context.Context
+	broker        *testutil.InMemoryMessageBroker
+	secretService service.SecretService
+	server        *gogrpc.Server
+	conn          *gogrpc.ClientConn
+	client        flexpricev1.EventServiceClient
+}
+
+func TestServer(t *testing.T) {
+	suite.Run(t, new(ServerSuite))
+}
+
+func (s *ServerSuite) SetupTest() {
+	s.ctx = testutil.SetupContext()
+	s.broker = testutil.NewInMemoryMessageBroker()
+	log := logger.GetLogger()
+	s.secretService = service.NewSecretService(testutil.NewInMemorySecretStore(), nil, log)
+	eventService := service.NewEventService(config.GetDefaultConfig(), s.broker, testutil.NewInMemoryEventStore(), nil, nil, nil, nil, nil, nil, log)
+
+	s.server = NewServer(config.GetDefaultConfig(), s.secretService, nil, eventService, log)
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = s.server.Serve(listener) }()
+
+	conn, err := gogrpc.NewClient("passthrough:///bufnet",
+		gogrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		gogrpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	s.Require().NoError(err)
+	s.conn = conn
+	s.client = flexpricev1.NewEventServiceClient(conn)
+}
+
+func (s *ServerSuite) TearDownTest() {
+	s.conn.Close()
+	s.server.Stop()
+}
+
+func (s *ServerSuite) apiKeyContext(scope types.APIKeyScope) context.Context {
+	resp, err := s.secretService.CreateAPIKey(s.ctx, dto.CreateAPIKeyRequest{
+		Name:   "Backend",
+		Scopes: []types.APIKeyScope{scope},
+	})
+	s.Require().NoError(err)
+	return metadata.AppendToOutgoingContext(context.Background(), types.HeaderAPIKey, resp.APIKey)
+}
+
+func (s *ServerSuite) TestIngestEvent() {
+	ctx := s.apiKeyContext(types.APIKeyScopeEvents)
+	properties, err := structpb.NewStruct(map[string]interface{}{"tokens": 42})
+	s.Require().NoError(err)
+
+	resp, err := s.client.IngestEvent(ctx, &flexpricev1.IngestEventRequest{
+		EventName:          "api_request",
+		ExternalCustomerId: "customer-1",
+		Properties:         properties,
+	})
+	s.Require().NoError(err)
+	s.NotEmpty(resp.EventId)
+	s.True(s.broker.HasMessage("events", resp.EventId))
+
+	_, err = s.client.IngestEvent(ctx, &flexpricev1.IngestEventRequest{EventName: "api_request"})
+	s.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (s *ServerSuite) TestIngestEventsStream() {
+	stream, err := s.client.IngestEvents(s.apiKeyContext(types.APIKeyScopeEvents))
+	s.Require().NoError(err)
+
+	s.Require().NoError(stream.Send(&flexpricev1.IngestEventRequest{EventName: "api_request", ExternalCustomerId: "customer-1"}))
+	s.Require().NoError(stream.Send(&flexpricev1.IngestEventRequest{EventName: "api_request", EventId: "missing-customer"}))
+	s.Require().NoError(stream.Send(&flexpricev1.IngestEventRequest{EventName: "api_request", ExternalCustomerId: "customer-2"}))
+
+	resp, err := stream.CloseAndRecv()
+	s.Require().NoError(err)
+	s.Equal(int64(2), resp.Accepted)
+	s.Equal(int64(1), resp.Failed)
+	s.Require().Len(resp.Errors, 1)
+	s.Equal(int64(1), resp.Errors[0].Index)
+	s.Equal("missing-customer", resp.Errors[0].EventId)
+}
+
+func (s *ServerSuite) TestAuthentication() {
+	_, err := s.client.IngestEvent(context.Background(), &flexpricev1.IngestEventRequest{})
+	s.Equal(codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), types.HeaderAPIKey, "invalid")
+	_, err = s.client.IngestEvent(ctx, &flexpricev1.IngestEventRequest{})
+	s.Equal(codes.Unauthenticated, status.Code(err))
+
+	// An ingestion key cannot read usage and a read only key cannot ingest
+	_, err = s.client.GetUsage(s.apiKeyContext(types.APIKeyScopeEvents), &flexpricev1.GetUsageRequest{EventName: "api_request"})
+	s.Equal(codes.PermissionDenied, status.Code(err))
+
+	stream, err := s.client.IngestEvents(s.apiKeyContext(types.APIKeyScopeReadOnly))
+	s.Require().NoError(err)
+	_, err = stream.CloseAndRecv()
+	s.Equal(codes.PermissionDenied, status.Code(err))
+}
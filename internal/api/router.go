@@ -5,7 +5,10 @@ import (
 	v1 "github.com/flexprice/flexprice/internal/api/v1"
 	"github.com/flexprice/flexprice/internal/config"
 	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/ratelimit"
 	"github.com/flexprice/flexprice/internal/rest/middleware"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -22,15 +25,66 @@ type Handlers struct {
 	Plan         *v1.PlanHandler
 	Subscription *v1.SubscriptionHandler
 	Wallet       *v1.WalletHandler
+	Export       *v1.ExportHandler
+	Task         *v1.TaskHandler
+	Invoice      *v1.InvoiceHandler
+	Environment  *v1.EnvironmentHandler
+	Secret       *v1.SecretHandler
+	Activity     *v1.ActivityHandler
+	Portal       *v1.PortalHandler
+	Connection   *v1.ConnectionHandler
+	Webhook      *v1.WebhookHandler
+	Email        *v1.EmailHandler
+	LedgerSync   *v1.LedgerSyncHandler
+	Anomaly      *v1.AnomalyHandler
+	RequestLog   *v1.RequestLogHandler
+
+	CancellationReason   *v1.CancellationReasonHandler
+	GraphQL              *v1.GraphQLHandler
+	RateCard             *v1.RateCardHandler
+	PriceBook            *v1.PriceBookHandler
+	Budget               *v1.BudgetHandler
+	CreditNote           *v1.CreditNoteHandler
+	UsageStream          *v1.UsageStreamHandler
+	AutoTopUp            *v1.AutoTopUpHandler
+	EventRetention       *v1.EventRetentionHandler
+	Job                  *v1.JobHandler
+	PriceCatalog         *v1.PriceCatalogHandler
+	StripeImport         *v1.StripeImportHandler
+	SubscriptionLineItem *v1.SubscriptionLineItemHandler
+	PaymentMethod        *v1.PaymentMethodHandler
+	Refund               *v1.RefundHandler
+	Payment              *v1.PaymentHandler
+	Mandate              *v1.MandateHandler
+	Tax                  *v1.TaxHandler
+	LegalEntity          *v1.LegalEntityHandler
+	AuditLog             *v1.AuditLogHandler
+	Role                 *v1.RoleHandler
+	EventCorrection      *v1.EventCorrectionHandler
+	EventBackfill        *v1.EventBackfillHandler
+	MeterVersion         *v1.MeterVersionHandler
+	EventSchema          *v1.EventSchemaHandler
+	EventDeadLetter      *v1.EventDeadLetterHandler
+	EventConsumer        *v1.EventConsumerHandler
+	Forecast             *v1.ForecastHandler
+	Margin               *v1.MarginHandler
+	Analytics            *v1.AnalyticsHandler
+	Receivables          *v1.ReceivablesHandler
+	Report               *v1.ReportHandler
+	FeatureFlag          *v1.FeatureFlagHandler
+	Simulation           *v1.SimulationHandler
 }
 
-func NewRouter(handlers Handlers, cfg *config.Configuration, logger *logger.Logger) *gin.Engine {
+func NewRouter(handlers Handlers, cfg *config.Configuration, secretService service.SecretService, roleService service.RoleService, requestLogService service.RequestLogService, limiter ratelimit.Limiter, logger *logger.Logger) *gin.Engine {
 	// gin.SetMode(gin.ReleaseMode)
 
 	router := gin.Default()
 	router.Use(
 		middleware.RequestIDMiddleware,
 		middleware.CORSMiddleware,
+		middleware.IfMatchMiddleware,
+		middleware.ConsistencyMiddleware,
+		middleware.RequestCacheMiddleware,
 	)
 
 	// Add middleware to set swagger host dynamically
@@ -55,84 +109,376 @@ func NewRouter(handlers Handlers, cfg *config.Configuration, logger *logger.Logg
 		// Auth routes
 		v1Public.POST("/auth/signup", handlers.Auth.SignUp)
 		v1Public.POST("/auth/login", handlers.Auth.Login)
+		v1Public.POST("/auth/sso/start", handlers.Auth.StartSSO)
+		v1Public.GET("/auth/sso/oidc/callback", handlers.Auth.OIDCCallback)
+		v1Public.POST("/auth/sso/saml/acs", handlers.Auth.SAMLACS)
 		v1Public.POST("/events/ingest", handlers.Events.IngestEvent)
+		v1Public.POST("/gateways/:tenant_id/:connection_id/webhooks", handlers.Payment.HandleGatewayWebhook)
 	}
 
-	private := router.Group("/", middleware.AuthenticateMiddleware(cfg, logger))
+	private := router.Group("/",
+		middleware.RequestLogMiddleware(cfg, requestLogService, logger),
+		middleware.AuthenticateMiddleware(cfg, secretService, roleService, logger),
+		middleware.RateLimitMiddleware(cfg, limiter, logger),
+	)
+
+	// Permissions checked on each private route, granted by the scopes of the
+	// API key or the role of the user
+	read := middleware.RequirePermission(types.PermissionRead)
+	write := middleware.RequirePermission(types.PermissionWrite)
+	ingest := middleware.RequirePermission(types.PermissionEventsWrite)
+	integrations := middleware.RequirePermission(types.PermissionIntegrationsWrite)
+	members := middleware.RequirePermission(types.PermissionMembersWrite)
 
 	v1Private := private.Group("/v1")
 	{
 		user := v1Private.Group("/users")
 		{
-			user.GET("/me", handlers.User.GetUserInfo)
+			user.GET("/me", read, handlers.User.GetUserInfo)
 		}
 
 		// Events routes
 		events := v1Private.Group("/events")
 		{
-			events.POST("", handlers.Events.IngestEvent)
-			events.GET("", handlers.Events.GetEvents)
-			events.POST("/usage", handlers.Events.GetUsage)
-			events.POST("/usage/meter", handlers.Events.GetUsageByMeter)
+			events.POST("", ingest, handlers.Events.IngestEvent)
+			events.GET("", read, handlers.Events.GetEvents)
+			events.GET("/export", read, handlers.Events.ExportEvents)
+			events.POST("/usage", read, handlers.Events.GetUsage)
+			events.POST("/usage/meter", read, handlers.Events.GetUsageByMeter)
+			events.GET("/quarantine", read, handlers.EventSchema.ListQuarantinedEvents)
+			events.GET("/quarantine/:id", read, handlers.EventSchema.GetQuarantinedEvent)
+			events.POST("/quarantine/:id/resubmit", ingest, handlers.EventSchema.ResubmitQuarantinedEvent)
+			events.GET("/dead-letters", integrations, handlers.EventDeadLetter.ListDeadLetters)
+			events.GET("/dead-letters/:id", integrations, handlers.EventDeadLetter.GetDeadLetter)
+			events.POST("/dead-letters/replay", integrations, handlers.EventDeadLetter.ReplayDeadLetters)
+			events.POST("/backfill", ingest, handlers.EventBackfill.CreateBackfill)
+			events.GET("/backfill/:id", read, handlers.EventBackfill.GetBackfill)
+			events.GET("/:id/history", read, handlers.EventCorrection.GetEventHistory)
+			events.POST("/:id/void", ingest, handlers.EventCorrection.VoidEvent)
+			events.POST("/:id/correct", ingest, handlers.EventCorrection.CorrectEvent)
+		}
+
+		v1Private.GET("/usage/anomalies", read, handlers.Anomaly.ListAnomalies)
+		v1Private.GET("/reports/margin", read, handlers.Margin.GetMarginReport)
+		v1Private.GET("/reports/receivables", read, handlers.Receivables.GetReceivablesReport)
+		v1Private.GET("/reports/write-offs", read, handlers.Receivables.GetWriteOffReport)
+		reports := v1Private.Group("/reports")
+		{
+			reports.POST("/templates", write, handlers.Report.CreateTemplate)
+			reports.GET("/templates", read, handlers.Report.ListTemplates)
+			reports.GET("/templates/:id", read, handlers.Report.GetTemplate)
+			reports.PUT("/templates/:id", write, handlers.Report.UpdateTemplate)
+			reports.DELETE("/templates/:id", write, handlers.Report.DeleteTemplate)
+			reports.POST("/templates/:id/run", write, handlers.Report.RunTemplate)
+			reports.GET("/templates/:id/runs", read, handlers.Report.ListRuns)
+			reports.GET("/runs/:id", read, handlers.Report.GetRun)
+			reports.GET("/runs/:id/download", read, handlers.Report.GetRunDownloadURL)
+		}
+		v1Private.GET("/analytics/revenue", read, handlers.Analytics.GetRevenueAnalytics)
+		v1Private.POST("/analytics/revenue/snapshots/refresh", write, handlers.Analytics.RefreshRevenueSnapshots)
+		v1Private.GET("/developer/logs", read, handlers.RequestLog.ListRequestLogs)
+		v1Private.GET("/audit-logs", read, handlers.AuditLog.ListAuditLogs)
+
+		v1Private.GET("/roles", read, handlers.Role.ListRoles)
+		roleAssignment := v1Private.Group("/role-assignments")
+		{
+			roleAssignment.GET("", read, handlers.Role.ListRoleAssignments)
+			roleAssignment.POST("", members, handlers.Role.AssignRole)
+			roleAssignment.DELETE("/:id", members, handlers.Role.DeleteRoleAssignment)
 		}
 
+		ssoConfig := v1Private.Group("/sso/config")
+		{
+			ssoConfig.GET("", read, handlers.Auth.GetSSOConfig)
+			ssoConfig.PUT("", members, handlers.Auth.SetSSOConfig)
+			ssoConfig.DELETE("", members, handlers.Auth.DeleteSSOConfig)
+		}
+		v1Private.GET("/analytics/churn-reasons", read, handlers.CancellationReason.GetChurnReasons)
+
+		// GraphQL only exposes queries, so it is a read route despite the POST
+		v1Private.POST("/graphql", read, handlers.GraphQL.Query)
+
 		meters := v1Private.Group("/meters")
 		{
-			meters.POST("", handlers.Meter.CreateMeter)
-			meters.GET("", handlers.Meter.GetAllMeters)
-			meters.GET("/:id", handlers.Meter.GetMeter)
-			meters.POST("/:id/disable", handlers.Meter.DisableMeter)
-			meters.DELETE("/:id", handlers.Meter.DeleteMeter)
+			meters.POST("", integrations, handlers.Meter.CreateMeter)
+			meters.GET("", read, handlers.Meter.GetAllMeters)
+			meters.GET("/:id", read, handlers.Meter.GetMeter)
+			meters.POST("/:id/disable", integrations, handlers.Meter.DisableMeter)
+			meters.DELETE("/:id", integrations, handlers.Meter.DeleteMeter)
+			meters.GET("/:id/versions", read, handlers.MeterVersion.ListVersions)
+			meters.POST("/:id/versions", integrations, handlers.MeterVersion.CreateVersion)
+			meters.GET("/:id/versions/:version", read, handlers.MeterVersion.GetVersion)
+			meters.POST("/:id/reaggregate", integrations, handlers.MeterVersion.Reaggregate)
+		}
+
+		eventSchema := v1Private.Group("/event-schemas")
+		{
+			eventSchema.POST("", integrations, handlers.EventSchema.CreateEventSchema)
+			eventSchema.GET("", read, handlers.EventSchema.ListEventSchemas)
+			eventSchema.GET("/:id", read, handlers.EventSchema.GetEventSchema)
+			eventSchema.PUT("/:id", integrations, handlers.EventSchema.UpdateEventSchema)
+			eventSchema.DELETE("/:id", integrations, handlers.EventSchema.DeleteEventSchema)
 		}
 
 		price := v1Private.Group("/prices")
 		{
-			price.POST("", handlers.Price.CreatePrice)
-			price.GET("", handlers.Price.GetPrices)
-			price.GET("/:id", handlers.Price.GetPrice)
-			price.PUT("/:id", handlers.Price.UpdatePrice)
-			price.DELETE("/:id", handlers.Price.DeletePrice)
+			price.POST("", write, handlers.Price.CreatePrice)
+			price.GET("", read, handlers.Price.GetPrices)
+			price.GET("/:id", read, handlers.Price.GetPrice)
+			price.PUT("/:id", write, handlers.Price.UpdatePrice)
+			price.DELETE("/:id", write, handlers.Price.DeletePrice)
+			price.POST("/:id/restore", write, handlers.Price.RestorePrice)
 		}
 
 		customer := v1Private.Group("/customers")
 		{
-			customer.POST("", handlers.Customer.CreateCustomer)
-			customer.GET("", handlers.Customer.GetCustomers)
-			customer.GET("/:id", handlers.Customer.GetCustomer)
-			customer.PUT("/:id", handlers.Customer.UpdateCustomer)
-			customer.DELETE("/:id", handlers.Customer.DeleteCustomer)
+			customer.POST("", write, handlers.Customer.CreateCustomer)
+			customer.GET("", read, handlers.Customer.GetCustomers)
+			customer.GET("/:id", read, handlers.Customer.GetCustomer)
+			customer.PUT("/:id", write, handlers.Customer.UpdateCustomer)
+			customer.DELETE("/:id", write, handlers.Customer.DeleteCustomer)
+			customer.POST("/:id/restore", write, handlers.Customer.RestoreCustomer)
+			customer.POST("/:id/collections/pause", write, handlers.Customer.PauseCollections)
+			customer.POST("/:id/collections/resume", write, handlers.Customer.ResumeCollections)
 
 			// other routes for customer
-			customer.GET("/:id/wallets", handlers.Wallet.GetWalletsByCustomerID)
+			customer.GET("/:id/wallets", read, handlers.Wallet.GetWalletsByCustomerID)
+			customer.GET("/:id/activity", read, handlers.Activity.GetCustomerActivity)
+			customer.GET("/:id/forecast", read, handlers.Forecast.GetCustomerForecast)
+			customer.GET("/:id/usage/stream", read, handlers.UsageStream.StreamCustomerUsage)
+			customer.POST("/:id/invoices", write, handlers.Invoice.CreateCustomerInvoices)
+			customer.GET("/:id/payment-methods", read, handlers.PaymentMethod.ListPaymentMethods)
+			customer.POST("/:id/payment-methods", write, handlers.PaymentMethod.AttachPaymentMethod)
+			customer.DELETE("/:id/payment-methods/:pm_id", write, handlers.PaymentMethod.DetachPaymentMethod)
+			customer.POST("/:id/payment-methods/:pm_id/default", write, handlers.PaymentMethod.SetDefaultPaymentMethod)
+			customer.GET("/:id/mandates", read, handlers.Mandate.ListMandates)
+			customer.POST("/:id/mandates", write, handlers.Mandate.CreateMandate)
+			customer.POST("/:id/mandates/:mandate_id/revoke", write, handlers.Mandate.RevokeMandate)
+			customer.POST("/:id/tax/validate-address", write, handlers.Tax.ValidateCustomerAddress)
 		}
 
 		plan := v1Private.Group("/plans")
 		{
-			plan.POST("", handlers.Plan.CreatePlan)
-			plan.GET("", handlers.Plan.GetPlans)
-			plan.GET("/:id", handlers.Plan.GetPlan)
-			plan.PUT("/:id", handlers.Plan.UpdatePlan)
-			plan.DELETE("/:id", handlers.Plan.DeletePlan)
+			plan.POST("", write, handlers.Plan.CreatePlan)
+			plan.GET("", read, handlers.Plan.GetPlans)
+			plan.GET("/:id", read, handlers.Plan.GetPlan)
+			plan.PUT("/:id", write, handlers.Plan.UpdatePlan)
+			plan.DELETE("/:id", write, handlers.Plan.DeletePlan)
+			plan.POST("/:id/restore", write, handlers.Plan.RestorePlan)
+			plan.GET("/:id/prices/export", read, handlers.PriceCatalog.ExportPlanPrices)
+			plan.POST("/:id/prices/import", write, handlers.PriceCatalog.ImportPlanPrices)
 		}
 
 		subscription := v1Private.Group("/subscriptions")
 		{
-			subscription.POST("", handlers.Subscription.CreateSubscription)
-			subscription.GET("", handlers.Subscription.GetSubscriptions)
-			subscription.GET("/:id", handlers.Subscription.GetSubscription)
-			subscription.POST("/:id/cancel", handlers.Subscription.CancelSubscription)
-			subscription.POST("/usage", handlers.Subscription.GetUsageBySubscription)
+			subscription.POST("", write, handlers.Subscription.CreateSubscription)
+			subscription.GET("", read, handlers.Subscription.GetSubscriptions)
+			subscription.GET("/:id", read, handlers.Subscription.GetSubscription)
+			subscription.POST("/:id/cancel", write, handlers.Subscription.CancelSubscription)
+			subscription.PATCH("/:id/line-items/:li_id", write, handlers.SubscriptionLineItem.UpdateLineItem)
+			subscription.POST("/usage", read, handlers.Subscription.GetUsageBySubscription)
+			subscription.POST("/:id/invoices", write, handlers.Invoice.CreateSubscriptionInvoice)
+			subscription.GET("/:id/invoices/upcoming", read, handlers.Invoice.GetUpcomingInvoice)
+			subscription.GET("/:id/usage-lock", read, handlers.Invoice.GetUsageLock)
+		}
+
+		cancellationReason := v1Private.Group("/cancellation-reasons")
+		{
+			cancellationReason.POST("", write, handlers.CancellationReason.CreateCancellationReason)
+			cancellationReason.GET("", read, handlers.CancellationReason.ListCancellationReasons)
+			cancellationReason.DELETE("/:id", write, handlers.CancellationReason.DeleteCancellationReason)
+		}
+
+		legalEntity := v1Private.Group("/legal-entities")
+		{
+			legalEntity.POST("", write, handlers.LegalEntity.CreateLegalEntity)
+			legalEntity.GET("", read, handlers.LegalEntity.ListLegalEntities)
+			legalEntity.GET("/:id", read, handlers.LegalEntity.GetLegalEntity)
+			legalEntity.PUT("/:id", write, handlers.LegalEntity.UpdateLegalEntity)
+			legalEntity.DELETE("/:id", write, handlers.LegalEntity.DeleteLegalEntity)
+		}
+
+		rateCard := v1Private.Group("/rate-cards")
+		{
+			rateCard.POST("", write, handlers.RateCard.CreateRateCard)
+			rateCard.GET("", read, handlers.RateCard.ListRateCards)
+			rateCard.GET("/rates", read, handlers.RateCard.GetCustomerRates)
+			rateCard.GET("/:id", read, handlers.RateCard.GetRateCard)
+			rateCard.DELETE("/:id", write, handlers.RateCard.DeleteRateCard)
+		}
+
+		priceBook := v1Private.Group("/price-books")
+		{
+			priceBook.POST("", write, handlers.PriceBook.CreatePriceBook)
+			priceBook.GET("", read, handlers.PriceBook.ListPriceBooks)
+			priceBook.GET("/report", read, handlers.PriceBook.GetPriceBookReport)
+			priceBook.GET("/:id", read, handlers.PriceBook.GetPriceBook)
+			priceBook.DELETE("/:id", write, handlers.PriceBook.DeletePriceBook)
+		}
+
+		budget := v1Private.Group("/budgets")
+		{
+			budget.POST("", write, handlers.Budget.CreateBudget)
+			budget.GET("", read, handlers.Budget.ListBudgets)
+			budget.GET("/:id", read, handlers.Budget.GetBudget)
+			budget.PUT("/:id", write, handlers.Budget.UpdateBudget)
+			budget.DELETE("/:id", write, handlers.Budget.DeleteBudget)
+		}
+
+		creditNote := v1Private.Group("/credit-notes")
+		{
+			creditNote.POST("/:id/allocations", write, handlers.CreditNote.AllocateCreditNote)
+			creditNote.GET("/:id/allocations", read, handlers.CreditNote.ListCreditAllocations)
+			creditNote.POST("/:id/void", write, handlers.CreditNote.VoidCreditNote)
+		}
+
+		payment := v1Private.Group("/payments")
+		{
+			payment.POST("/:id/refunds", write, handlers.Refund.CreateRefund)
+			payment.GET("/:id/refunds", read, handlers.Refund.ListRefunds)
 		}
 
 		wallet := v1Private.Group("/wallets")
 		{
-			wallet.POST("", handlers.Wallet.CreateWallet)
-			wallet.GET("/:id", handlers.Wallet.GetWalletByID)
-			wallet.GET("/:id/transactions", handlers.Wallet.GetWalletTransactions)
-			wallet.POST("/:id/top-up", handlers.Wallet.TopUpWallet)
-			wallet.POST("/:id/terminate", handlers.Wallet.TerminateWallet)
-			wallet.GET("/:id/balance/real-time", handlers.Wallet.GetWalletBalance)
+			wallet.POST("", write, handlers.Wallet.CreateWallet)
+			wallet.GET("/:id", read, handlers.Wallet.GetWalletByID)
+			wallet.GET("/:id/transactions", read, handlers.Wallet.GetWalletTransactions)
+			wallet.POST("/:id/top-up", write, handlers.Wallet.TopUpWallet)
+			wallet.POST("/:id/debit", write, handlers.Wallet.DebitWallet)
+			wallet.POST("/:id/terminate", write, handlers.Wallet.TerminateWallet)
+			wallet.GET("/:id/balance/real-time", read, handlers.Wallet.GetWalletBalance)
+			wallet.PUT("/:id/auto-topup", write, handlers.AutoTopUp.SetAutoTopUp)
+			wallet.GET("/:id/auto-topup", read, handlers.AutoTopUp.GetAutoTopUp)
+		}
+
+		export := v1Private.Group("/exports")
+		{
+			export.POST("", write, handlers.Export.CreateExport)
+			export.GET("", read, handlers.Export.GetExports)
+			export.POST("/schedules", write, handlers.Export.CreateSchedule)
+			export.GET("/schedules", read, handlers.Export.ListSchedules)
+			export.GET("/schedules/:id", read, handlers.Export.GetSchedule)
+			export.DELETE("/schedules/:id", write, handlers.Export.DeleteSchedule)
+			export.GET("/:id", read, handlers.Export.GetExport)
+			export.GET("/:id/download", read, handlers.Export.GetExportDownloadURL)
+		}
+
+		task := v1Private.Group("/tasks")
+		{
+			task.POST("", write, handlers.Task.CreateTask)
+			task.GET("", read, handlers.Task.ListTasks)
+			task.GET("/:id", read, handlers.Task.GetTask)
+			task.GET("/:id/error-report", read, handlers.Task.GetTaskErrorReportURL)
+		}
+
+		invoice := v1Private.Group("/invoices")
+		{
+			invoice.GET("", read, handlers.Invoice.ListInvoices)
+			invoice.POST("/one-off", write, handlers.Invoice.CreateOneOffInvoice)
+			invoice.GET("/numbering", read, handlers.Invoice.GetInvoiceNumberingConfig)
+			invoice.PUT("/numbering", write, handlers.Invoice.UpdateInvoiceNumberingConfig)
+			invoice.GET("/:id", read, handlers.Invoice.GetInvoice)
+			invoice.POST("/:id/finalize", write, handlers.Invoice.FinalizeInvoice)
+			invoice.POST("/:id/approve", write, handlers.Invoice.ApproveInvoice)
+			invoice.POST("/:id/void", write, handlers.Invoice.VoidInvoice)
+			invoice.POST("/:id/write-off", write, handlers.Invoice.WriteOffInvoice)
+			invoice.POST("/:id/line-items", write, handlers.Invoice.AddLineItem)
+			invoice.PATCH("/:id/line-items/:li_id", write, handlers.Invoice.UpdateLineItem)
+			invoice.DELETE("/:id/line-items/:li_id", write, handlers.Invoice.RemoveLineItem)
+			invoice.GET("/:id/payments", read, handlers.Invoice.ListPayments)
+			invoice.POST("/:id/payments", write, handlers.Invoice.RecordPayment)
+			invoice.POST("/:id/payments/collect", write, handlers.Payment.CollectInvoicePayment)
+			invoice.POST("/:id/payments/debit", write, handlers.Payment.ScheduleDirectDebit)
+			invoice.GET("/:id/disputes", read, handlers.Invoice.ListDisputes)
+			invoice.POST("/:id/disputes", write, handlers.Invoice.OpenDispute)
+			invoice.POST("/:id/disputes/:dispute_id/review", write, handlers.Invoice.ReviewDispute)
+			invoice.POST("/:id/disputes/:dispute_id/resolve", write, handlers.Invoice.ResolveDispute)
+			invoice.GET("/:id/ledger-syncs", read, handlers.LedgerSync.ListInvoiceSyncs)
+			invoice.POST("/:id/ledger-syncs/retry", write, handlers.LedgerSync.RetryInvoiceSync)
+		}
+
+		environment := v1Private.Group("/environments")
+		{
+			environment.POST("", integrations, handlers.Environment.CreateEnvironment)
+			environment.GET("", read, handlers.Environment.GetEnvironments)
+			environment.GET("/:id", read, handlers.Environment.GetEnvironment)
+			environment.POST("/:id/reset", integrations, handlers.Environment.ResetEnvironment)
+			environment.GET("/:id/resets/:reset_id", read, handlers.Environment.GetEnvironmentReset)
+		}
+
+		secret := v1Private.Group("/secrets")
+		{
+			secret.POST("/api-keys", integrations, handlers.Secret.CreateAPIKey)
+			secret.GET("/api-keys", read, handlers.Secret.ListAPIKeys)
+			secret.DELETE("/api-keys/:id", integrations, handlers.Secret.DeleteAPIKey)
+		}
+
+		v1Private.POST("/portal/sessions", write, handlers.Portal.CreatePortalSession)
+
+		connection := v1Private.Group("/connections")
+		{
+			connection.POST("", integrations, handlers.Connection.CreateConnection)
+			connection.GET("", read, handlers.Connection.GetConnections)
+			connection.GET("/:id", read, handlers.Connection.GetConnection)
+			connection.GET("/:id/sync-queue", read, handlers.Connection.GetSyncQueueStatus)
+			connection.POST("/:id/stripe-import", write, handlers.StripeImport.Import)
+		}
+
+		v1Private.GET("/ledger-syncs", read, handlers.LedgerSync.ListSyncs)
+
+		webhook := v1Private.Group("/webhooks")
+		{
+			webhook.POST("/endpoints", integrations, handlers.Webhook.CreateEndpoint)
+			webhook.GET("/endpoints", read, handlers.Webhook.ListEndpoints)
+			webhook.GET("/endpoints/:id", read, handlers.Webhook.GetEndpoint)
+			webhook.PUT("/endpoints/:id", integrations, handlers.Webhook.UpdateEndpoint)
+			webhook.DELETE("/endpoints/:id", integrations, handlers.Webhook.DeleteEndpoint)
+			webhook.POST("/endpoints/:id/rotate-secret", integrations, handlers.Webhook.RotateEndpointSecret)
+			webhook.GET("/deliveries", read, handlers.Webhook.ListDeliveries)
+			webhook.GET("/deliveries/:id", read, handlers.Webhook.GetDelivery)
+			webhook.POST("/deliveries/:id/replay", integrations, handlers.Webhook.ReplayDelivery)
+		}
+
+		email := v1Private.Group("/emails")
+		{
+			email.GET("/templates", read, handlers.Email.ListTemplates)
+			email.PUT("/templates/:type", write, handlers.Email.SetTemplate)
+			email.DELETE("/templates/:type", write, handlers.Email.DeleteTemplate)
+			email.GET("/deliveries", read, handlers.Email.ListDeliveries)
+			email.GET("/deliveries/:id", read, handlers.Email.GetDelivery)
+			email.POST("/deliveries/:id/resend", write, handlers.Email.ResendDelivery)
 		}
 	}
+
+	// Customer portal routes, authenticated with a portal session token
+	portal := router.Group("/v1/portal", middleware.PortalAuthenticateMiddleware(cfg, logger))
+	{
+		portal.GET("/customer", handlers.Portal.GetPortalCustomer)
+		portal.GET("/usage", handlers.Portal.GetPortalUsage)
+		portal.GET("/usage/stream", handlers.UsageStream.StreamPortalUsage)
+		portal.GET("/invoices", handlers.Portal.ListPortalInvoices)
+		portal.GET("/invoices/:id", handlers.Portal.GetPortalInvoice)
+	}
+
+	// Operator routes across all the tenants, authenticated with the admin key
+	admin := router.Group("/v1/admin", middleware.AdminAuthenticateMiddleware(cfg, logger))
+	{
+		admin.GET("/events/storage", handlers.EventRetention.GetEventStorage)
+		admin.GET("/events/consumer-lag", handlers.EventConsumer.GetConsumerLag)
+		admin.GET("/events/retention", handlers.EventRetention.ListRetentionPolicies)
+		admin.PUT("/events/retention/:tenant_id", handlers.EventRetention.SetRetentionPolicy)
+		admin.DELETE("/events/retention/:tenant_id", handlers.EventRetention.DeleteRetentionPolicy)
+
+		admin.GET("/feature-flags/:tenant_id", handlers.FeatureFlag.ListFeatureFlags)
+		admin.PUT("/feature-flags/:tenant_id/:flag", handlers.FeatureFlag.SetFeatureFlag)
+		admin.DELETE("/feature-flags/:tenant_id/:flag", handlers.FeatureFlag.DeleteFeatureFlag)
+
+		admin.GET("/jobs", handlers.Job.ListJobs)
+		admin.GET("/jobs/:name/runs", handlers.Job.ListJobRuns)
+		admin.POST("/jobs/:name/trigger", handlers.Job.TriggerJob)
+
+		admin.GET("/clock", handlers.Simulation.GetClock)
+		admin.POST("/clock/advance", handlers.Simulation.AdvanceClock)
+	}
 	return router
 }
@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type ActivityHandler struct {
+	activityService service.ActivityService
+	logger          *logger.Logger
+}
+
+func NewActivityHandler(activityService service.ActivityService, logger *logger.Logger) *ActivityHandler {
+	return &ActivityHandler{
+		activityService: activityService,
+		logger:          logger,
+	}
+}
+
+// GetCustomerActivity godoc
+// @Summary Get customer activity
+// @Description Get a chronological feed of subscription, invoice and wallet activity for a customer, newest first
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param filter query types.ActivityFilter false "Filter"
+// @Success 200 {object} dto.ListCustomerActivityResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/activity [get]
+func (h *ActivityHandler) GetCustomerActivity(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var filter types.ActivityFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.activityService.GetCustomerActivity(c.Request.Context(), id, filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get customer activity", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
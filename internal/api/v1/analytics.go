@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	revenueAnalyticsService service.RevenueAnalyticsService
+	logger                  *logger.Logger
+}
+
+func NewAnalyticsHandler(revenueAnalyticsService service.RevenueAnalyticsService, logger *logger.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		revenueAnalyticsService: revenueAnalyticsService,
+		logger:                  logger,
+	}
+}
+
+// GetRevenueAnalytics godoc
+// @Summary Get revenue analytics
+// @Description Report the MRR, ARR, net revenue retention, churn and expansion of each calendar month from start_month to end_month (YYYY-MM), optionally by plan, currency or customer segment, the segment metadata of the customers. The MRR of a subscription is the revenue of its finalized invoices for the period running at the end of the month, per month of its billing period. Closed months are served from snapshots
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.RevenueAnalyticsFilter true "Filter"
+// @Success 200 {object} dto.RevenueAnalyticsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /analytics/revenue [get]
+func (h *AnalyticsHandler) GetRevenueAnalytics(c *gin.Context) {
+	var filter types.RevenueAnalyticsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.revenueAnalyticsService.GetRevenueAnalytics(c.Request.Context(), &filter, time.Now().UTC())
+	if errors.Is(err, service.ErrInvalidMonthRange) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get revenue analytics", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RefreshRevenueSnapshots godoc
+// @Summary Refresh revenue snapshots
+// @Description Compute the revenue snapshots of the closed months of the range from the invoices again, after invoices of those months were finalized or voided late. Months not closed yet are skipped
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RefreshRevenueSnapshotsRequest true "Months to refresh"
+// @Success 200 {object} dto.RefreshRevenueSnapshotsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /analytics/revenue/snapshots/refresh [post]
+func (h *AnalyticsHandler) RefreshRevenueSnapshots(c *gin.Context) {
+	var req dto.RefreshRevenueSnapshotsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request payload", err)
+		return
+	}
+
+	resp, err := h.revenueAnalyticsService.RefreshSnapshots(c.Request.Context(), &req, time.Now().UTC())
+	if errors.Is(err, service.ErrInvalidMonthRange) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to refresh revenue snapshots", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
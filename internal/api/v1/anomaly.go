@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type AnomalyHandler struct {
+	anomalyService service.AnomalyService
+	logger         *logger.Logger
+}
+
+func NewAnomalyHandler(anomalyService service.AnomalyService, logger *logger.Logger) *AnomalyHandler {
+	return &AnomalyHandler{
+		anomalyService: anomalyService,
+		logger:         logger,
+	}
+}
+
+// ListAnomalies godoc
+// @Summary List usage anomalies
+// @Description List the usage spikes and drops flagged by the anomaly detection job, most recent window first
+// @Tags Usage
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.AnomalyFilter false "Filter"
+// @Success 200 {object} dto.ListAnomaliesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /usage/anomalies [get]
+func (h *AnomalyHandler) ListAnomalies(c *gin.Context) {
+	var filter types.AnomalyFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.anomalyService.ListAnomalies(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list anomalies", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogHandler struct {
+	auditLogService service.AuditLogService
+	logger          *logger.Logger
+}
+
+func NewAuditLogHandler(auditLogService service.AuditLogService, logger *logger.Logger) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditLogService: auditLogService,
+		logger:          logger,
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary List audit logs
+// @Description List the changes made to the entities of the environment, most recent first, with the user or API key that made them and the fields they changed
+// @Tags Audit Logs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.AuditLogFilter false "Filter"
+// @Success 200 {object} dto.ListAuditLogsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /audit-logs [get]
+func (h *AuditLogHandler) ListAuditLogs(c *gin.Context) {
+	var filter types.AuditLogFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	if filter.StartTime != nil && filter.EndTime != nil && !filter.EndTime.After(*filter.StartTime) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", fmt.Errorf("end_time must be after start_time"))
+		return
+	}
+
+	resp, err := h.auditLogService.ListAuditLogs(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list audit logs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
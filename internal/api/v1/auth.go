@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
 	"github.com/flexprice/flexprice/internal/config"
@@ -11,12 +13,14 @@ import (
 )
 
 type AuthHandler struct {
+	cfg         *config.Configuration
 	authService service.AuthService
 	logger      *logger.Logger
 }
 
 func NewAuthHandler(cfg *config.Configuration, authService service.AuthService, logger *logger.Logger) *AuthHandler {
 	return &AuthHandler{
+		cfg:         cfg,
 		authService: authService,
 		logger:      logger,
 	}
@@ -74,6 +78,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	authResponse, err := h.authService.Login(c.Request.Context(), &req)
+	if errors.Is(err, service.ErrPasswordLoginDisabled) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -81,3 +89,178 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, authResponse)
 }
+
+// @Summary Start single sign on
+// @Description Start the single sign on of a user with the identity provider of the tenant of the email domain
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.StartSSORequest true "Start SSO request"
+// @Success 200 {object} dto.StartSSOResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /auth/sso/start [post]
+func (h *AuthHandler) StartSSO(c *gin.Context) {
+	var req dto.StartSSORequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.authService.StartSSO(c.Request.Context(), &req)
+	if errors.Is(err, service.ErrSSONotConfigured) {
+		NewErrorResponse(c, http.StatusNotFound, "single sign on is not configured for this email", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to start single sign on", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary OIDC single sign on callback
+// @Description Sign in the user authenticated by the OIDC provider. Redirects to the dashboard with the auth token in the URL fragment when a redirect URL is configured
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "Login state"
+// @Success 200 {object} dto.AuthResponse
+// @Success 302
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/sso/oidc/callback [get]
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if idpError := c.Query("error"); idpError != "" {
+		NewErrorResponse(c, http.StatusUnauthorized, "single sign on failed", errors.New(idpError))
+		return
+	}
+
+	resp, err := h.authService.CompleteOIDCLogin(c.Request.Context(), c.Query("code"), c.Query("state"))
+	h.completeSSO(c, resp, err)
+}
+
+// @Summary SAML assertion consumer service
+// @Description Sign in the user of the SAML response posted by the identity provider. Redirects to the dashboard with the auth token in the URL fragment when a redirect URL is configured
+// @Tags auth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param SAMLResponse formData string true "SAML response"
+// @Param RelayState formData string true "Login state"
+// @Success 200 {object} dto.AuthResponse
+// @Success 302
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/sso/saml/acs [post]
+func (h *AuthHandler) SAMLACS(c *gin.Context) {
+	resp, err := h.authService.CompleteSAMLLogin(c.Request.Context(), c.PostForm("SAMLResponse"), c.PostForm("RelayState"))
+	h.completeSSO(c, resp, err)
+}
+
+func (h *AuthHandler) completeSSO(c *gin.Context, resp *dto.AuthResponse, err error) {
+	if errors.Is(err, service.ErrSSOLoginRejected) || errors.Is(err, service.ErrSSONotConfigured) {
+		h.logger.Warnw("single sign on rejected", "error", err)
+		NewErrorResponse(c, http.StatusUnauthorized, "single sign on failed", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to complete single sign on", err)
+		return
+	}
+
+	// The token travels in the fragment so that it is not sent to servers nor logged
+	if h.cfg.Auth.SSO.RedirectURL != "" {
+		c.Redirect(http.StatusFound, h.cfg.Auth.SSO.RedirectURL+"#token="+url.QueryEscape(resp.Token))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetSSOConfig godoc
+// @Summary Get the SSO config
+// @Description Get the identity provider the users of the tenant sign in with
+// @Tags SSO
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SSOConfigResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sso/config [get]
+func (h *AuthHandler) GetSSOConfig(c *gin.Context) {
+	resp, err := h.authService.GetSSOConfig(c.Request.Context())
+	if errors.Is(err, service.ErrSSONotConfigured) {
+		NewErrorResponse(c, http.StatusNotFound, "sso config not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get sso config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetSSOConfig godoc
+// @Summary Set the SSO config
+// @Description Configure the OIDC or SAML identity provider the users of the tenant sign in with, replacing the current one
+// @Tags SSO
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.SetSSOConfigRequest true "SSO config"
+// @Success 200 {object} dto.SSOConfigResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sso/config [put]
+func (h *AuthHandler) SetSSOConfig(c *gin.Context) {
+	var req dto.SetSSOConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.authService.SetSSOConfig(c.Request.Context(), req)
+	if errors.Is(err, service.ErrSSODomainInUse) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to set sso config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteSSOConfig godoc
+// @Summary Delete the SSO config
+// @Description Remove the identity provider of the tenant. Its users sign in with their password again
+// @Tags SSO
+// @Security BearerAuth
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sso/config [delete]
+func (h *AuthHandler) DeleteSSOConfig(c *gin.Context) {
+	err := h.authService.DeleteSSOConfig(c.Request.Context())
+	if errors.Is(err, service.ErrSSONotConfigured) {
+		NewErrorResponse(c, http.StatusNotFound, "sso config not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete sso config", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
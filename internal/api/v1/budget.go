@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type BudgetHandler struct {
+	budgetService service.BudgetService
+	logger        *logger.Logger
+}
+
+func NewBudgetHandler(budgetService service.BudgetService, logger *logger.Logger) *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: budgetService,
+		logger:        logger,
+	}
+}
+
+// CreateBudget godoc
+// @Summary Create a budget
+// @Description Cap the usage charges of a customer, or of one of its subscriptions, over a month or billing period. Once the spend exceeds the amount a budget.exceeded webhook is sent and the action of the budget is taken: alert only, block the events of the billed meters, or pause the subscriptions
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateBudgetRequest true "Create budget request"
+// @Success 201 {object} dto.BudgetResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /budgets [post]
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	var req dto.CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.budgetService.CreateBudget(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create budget", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListBudgets godoc
+// @Summary List budgets
+// @Description List the budgets of the tenant, or of a customer, with their spend as of their last evaluation
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id query string false "Customer ID"
+// @Success 200 {object} dto.ListBudgetsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /budgets [get]
+func (h *BudgetHandler) ListBudgets(c *gin.Context) {
+	var req dto.ListBudgetsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.budgetService.ListBudgets(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list budgets", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetBudget godoc
+// @Summary Get a budget
+// @Description Get a budget with its spend as of its last evaluation
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Budget ID"
+// @Success 200 {object} dto.BudgetResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /budgets/{id} [get]
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.budgetService.GetBudget(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusNotFound, "budget not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateBudget godoc
+// @Summary Update a budget
+// @Description Change the name, amount or action of a budget. The spend is evaluated against them right away
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Budget ID"
+// @Param request body dto.UpdateBudgetRequest true "Update budget request"
+// @Success 200 {object} dto.BudgetResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /budgets/{id} [put]
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.UpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.budgetService.UpdateBudget(c.Request.Context(), id, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to update budget", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteBudget godoc
+// @Summary Delete a budget
+// @Description Delete a budget. The usage it blocked is let through again, the subscriptions it paused stay paused
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Budget ID"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /budgets/{id} [delete]
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	if err := h.budgetService.DeleteBudget(c.Request.Context(), id); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete budget", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "budget deleted successfully"})
+}
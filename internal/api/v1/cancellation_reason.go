@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type CancellationReasonHandler struct {
+	cancellationReasonService service.CancellationReasonService
+	logger                    *logger.Logger
+}
+
+func NewCancellationReasonHandler(cancellationReasonService service.CancellationReasonService, logger *logger.Logger) *CancellationReasonHandler {
+	return &CancellationReasonHandler{
+		cancellationReasonService: cancellationReasonService,
+		logger:                    logger,
+	}
+}
+
+// CreateCancellationReason godoc
+// @Summary Create a cancellation reason
+// @Description Add a reason to the tenant's cancellation taxonomy. Once a tenant has a reason, the default taxonomy no longer applies
+// @Tags Cancellation Reasons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateCancellationReasonRequest true "Create cancellation reason request"
+// @Success 201 {object} dto.CancellationReasonResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /cancellation-reasons [post]
+func (h *CancellationReasonHandler) CreateCancellationReason(c *gin.Context) {
+	var req dto.CreateCancellationReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.cancellationReasonService.CreateCancellationReason(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create cancellation reason", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListCancellationReasons godoc
+// @Summary List cancellation reasons
+// @Description List the cancellation taxonomy of the tenant, or the default taxonomy when the tenant has not configured one
+// @Tags Cancellation Reasons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ListCancellationReasonsResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /cancellation-reasons [get]
+func (h *CancellationReasonHandler) ListCancellationReasons(c *gin.Context) {
+	resp, err := h.cancellationReasonService.ListCancellationReasons(c.Request.Context())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list cancellation reasons", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteCancellationReason godoc
+// @Summary Delete a cancellation reason
+// @Description Remove a reason from the tenant's taxonomy. Past cancellations keep their reason
+// @Tags Cancellation Reasons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Cancellation reason ID"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /cancellation-reasons/{id} [delete]
+func (h *CancellationReasonHandler) DeleteCancellationReason(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	if err := h.cancellationReasonService.DeleteCancellationReason(c.Request.Context(), id); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete cancellation reason", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "cancellation reason deleted successfully"})
+}
+
+// GetChurnReasons godoc
+// @Summary Get churn reasons
+// @Description Aggregate the subscriptions cancelled in a window by cancellation reason, plan and tenure. The window defaults to the last 30 days
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request query dto.ChurnReasonsRequest false "Cancellation window"
+// @Success 200 {object} dto.ChurnReasonsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /analytics/churn-reasons [get]
+func (h *CancellationReasonHandler) GetChurnReasons(c *gin.Context) {
+	var req dto.ChurnReasonsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.cancellationReasonService.GetChurnReasons(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get churn reasons", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type ConnectionHandler struct {
+	connectionService service.ConnectionService
+	logger            *logger.Logger
+}
+
+func NewConnectionHandler(connectionService service.ConnectionService, logger *logger.Logger) *ConnectionHandler {
+	return &ConnectionHandler{
+		connectionService: connectionService,
+		logger:            logger,
+	}
+}
+
+// CreateConnection godoc
+// @Summary Create a connection
+// @Description Create a connection to an external integration
+// @Tags Connections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateConnectionRequest true "Create connection request"
+// @Success 201 {object} dto.ConnectionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /connections [post]
+func (h *ConnectionHandler) CreateConnection(c *gin.Context) {
+	var req dto.CreateConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.connectionService.CreateConnection(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create connection", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetConnection godoc
+// @Summary Get a connection
+// @Description Get a connection by ID
+// @Tags Connections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Connection ID"
+// @Success 200 {object} dto.ConnectionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /connections/{id} [get]
+func (h *ConnectionHandler) GetConnection(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.connectionService.GetConnection(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get connection", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetConnections godoc
+// @Summary List connections
+// @Description List the integration connections of the tenant
+// @Tags Connections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.Filter false "Filter"
+// @Success 200 {object} dto.ListConnectionsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /connections [get]
+func (h *ConnectionHandler) GetConnections(c *gin.Context) {
+	var filter types.Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.connectionService.GetConnections(c.Request.Context(), filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get connections", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetSyncQueueStatus godoc
+// @Summary Get the sync queue status of a connection
+// @Description Get the pending, in flight and failed outbound sync tasks of a connection and whether it is backing off after a rate limit
+// @Tags Connections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Connection ID"
+// @Success 200 {object} dto.SyncQueueStatusResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /connections/{id}/sync-queue [get]
+func (h *ConnectionHandler) GetSyncQueueStatus(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.connectionService.GetSyncQueueStatus(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get sync queue status", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
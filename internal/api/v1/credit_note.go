@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type CreditNoteHandler struct {
+	creditNoteService service.CreditNoteService
+	logger            *logger.Logger
+}
+
+func NewCreditNoteHandler(creditNoteService service.CreditNoteService, logger *logger.Logger) *CreditNoteHandler {
+	return &CreditNoteHandler{
+		creditNoteService: creditNoteService,
+		logger:            logger,
+	}
+}
+
+// AllocateCreditNote godoc
+// @Summary Allocate a credit note
+// @Description Allocate part of a finalized credit note, a CREDIT invoice, to reduce the amount due of another invoice of the customer or to credit one of its wallets
+// @Tags Credit Notes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Credit invoice ID"
+// @Param request body dto.AllocateCreditNoteRequest true "Allocation"
+// @Success 201 {object} dto.CreditAllocationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /credit-notes/{id}/allocations [post]
+func (h *CreditNoteHandler) AllocateCreditNote(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.AllocateCreditNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.creditNoteService.AllocateCreditNote(c.Request.Context(), id, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to allocate credit note", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListCreditAllocations godoc
+// @Summary List the allocations of a credit note
+// @Description List where the amount of a credit note went along with the credit left to allocate
+// @Tags Credit Notes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Credit invoice ID"
+// @Success 200 {object} dto.ListCreditAllocationsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /credit-notes/{id}/allocations [get]
+func (h *CreditNoteHandler) ListCreditAllocations(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.creditNoteService.ListCreditAllocations(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list credit allocations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// VoidCreditNote godoc
+// @Summary Void a credit note
+// @Description Void a credit note nothing was allocated from
+// @Tags Credit Notes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Credit invoice ID"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /credit-notes/{id}/void [post]
+func (h *CreditNoteHandler) VoidCreditNote(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.creditNoteService.VoidCreditNote(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to void credit note", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
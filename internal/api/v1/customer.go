@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
@@ -148,3 +149,83 @@ func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// @Summary Restore a customer
+// @Description Restore a deleted customer. Deleted customers can be restored until they are purged
+// @Tags customers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} dto.CustomerResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/restore [post]
+func (h *CustomerHandler) RestoreCustomer(c *gin.Context) {
+	resp, err := h.service.RestoreCustomer(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, service.ErrNotDeleted) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if errors.Is(err, service.ErrRestoreConflict) {
+		NewErrorResponse(c, http.StatusConflict, "customer can not be restored", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to restore customer", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Pause the collections of a customer
+// @Description Pause the collections of a customer until a date. Their scheduled bank debits are held and no payment can be collected through a gateway until the pause expires or is lifted. Payments can still be recorded
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param request body dto.PauseCollectionsRequest true "Pause"
+// @Success 200 {object} dto.CustomerResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/collections/pause [post]
+func (h *CustomerHandler) PauseCollections(c *gin.Context) {
+	var req dto.PauseCollectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.service.PauseCollections(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to pause collections", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Resume the collections of a customer
+// @Description Lift the pause of the collections of a customer before it expires
+// @Tags customers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} dto.CustomerResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/collections/resume [post]
+func (h *CustomerHandler) ResumeCollections(c *gin.Context) {
+	resp, err := h.service.ResumeCollections(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to resume collections", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
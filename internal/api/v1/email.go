@@ -0,0 +1,189 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailHandler struct {
+	emailService service.EmailService
+	logger       *logger.Logger
+}
+
+func NewEmailHandler(emailService service.EmailService, logger *logger.Logger) *EmailHandler {
+	return &EmailHandler{
+		emailService: emailService,
+		logger:       logger,
+	}
+}
+
+// ListTemplates godoc
+// @Summary List email templates
+// @Description List the email template of every type, the default one when the tenant has not replaced it
+// @Tags Emails
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ListEmailTemplatesResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /emails/templates [get]
+func (h *EmailHandler) ListTemplates(c *gin.Context) {
+	resp, err := h.emailService.ListTemplates(c.Request.Context())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list email templates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetTemplate godoc
+// @Summary Set an email template
+// @Description Replace the template of a type of email. Subject and bodies are Go templates and are checked against sample data
+// @Tags Emails
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Template type" Enums(invoice_finalized, trial_will_end, report_ready)
+// @Param request body dto.SetEmailTemplateRequest true "Set email template request"
+// @Success 200 {object} dto.EmailTemplateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /emails/templates/{type} [put]
+func (h *EmailHandler) SetTemplate(c *gin.Context) {
+	templateType := types.EmailTemplateType(c.Param("type"))
+	if !templateType.Validate() {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid template type", nil)
+		return
+	}
+
+	var req dto.SetEmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.emailService.SetTemplate(c.Request.Context(), templateType, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to set email template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteTemplate godoc
+// @Summary Restore the default email template
+// @Description Delete the template of the tenant for a type of email so that the default one is used
+// @Tags Emails
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Template type" Enums(invoice_finalized, trial_will_end, report_ready)
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /emails/templates/{type} [delete]
+func (h *EmailHandler) DeleteTemplate(c *gin.Context) {
+	templateType := types.EmailTemplateType(c.Param("type"))
+	if !templateType.Validate() {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid template type", nil)
+		return
+	}
+
+	if err := h.emailService.DeleteTemplate(c.Request.Context(), templateType); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete email template", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+// @Summary List email deliveries
+// @Description List the emails sent to the customers of the tenant. Filter by entity_id for the emails of an invoice
+// @Tags Emails
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.EmailDeliveryFilter false "Filter"
+// @Success 200 {object} dto.ListEmailDeliveriesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /emails/deliveries [get]
+func (h *EmailHandler) ListDeliveries(c *gin.Context) {
+	var filter types.EmailDeliveryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.emailService.ListDeliveries(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list email deliveries", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetDelivery godoc
+// @Summary Get an email delivery
+// @Description Get an email delivery with its rendered content and its last attempt
+// @Tags Emails
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} dto.EmailDeliveryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /emails/deliveries/{id} [get]
+func (h *EmailHandler) GetDelivery(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.emailService.GetDelivery(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get email delivery", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ResendDelivery godoc
+// @Summary Resend an email
+// @Description Queue a copy of an email delivery to be sent right away as it was first rendered
+// @Tags Emails
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Delivery ID"
+// @Success 201 {object} dto.EmailDeliveryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /emails/deliveries/{id}/resend [post]
+func (h *EmailHandler) ResendDelivery(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.emailService.ResendDelivery(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to resend email delivery", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
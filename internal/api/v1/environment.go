@@ -0,0 +1,180 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type EnvironmentHandler struct {
+	environmentService service.EnvironmentService
+	logger             *logger.Logger
+}
+
+func NewEnvironmentHandler(environmentService service.EnvironmentService, logger *logger.Logger) *EnvironmentHandler {
+	return &EnvironmentHandler{
+		environmentService: environmentService,
+		logger:             logger,
+	}
+}
+
+// CreateEnvironment godoc
+// @Summary Create an environment
+// @Description Create a new environment for the tenant
+// @Tags Environments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateEnvironmentRequest true "Create environment request"
+// @Success 201 {object} dto.EnvironmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /environments [post]
+func (h *EnvironmentHandler) CreateEnvironment(c *gin.Context) {
+	var req dto.CreateEnvironmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.environmentService.CreateEnvironment(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create environment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetEnvironment godoc
+// @Summary Get an environment
+// @Description Get an environment by ID
+// @Tags Environments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Environment ID"
+// @Success 200 {object} dto.EnvironmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /environments/{id} [get]
+func (h *EnvironmentHandler) GetEnvironment(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.environmentService.GetEnvironment(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get environment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetEnvironments godoc
+// @Summary List environments
+// @Description List the environments of the tenant
+// @Tags Environments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.Filter false "Filter"
+// @Success 200 {object} dto.ListEnvironmentsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /environments [get]
+func (h *EnvironmentHandler) GetEnvironments(c *gin.Context) {
+	var filter types.Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.environmentService.GetEnvironments(c.Request.Context(), filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get environments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ResetEnvironment godoc
+// @Summary Reset a sandbox environment
+// @Description Wipe customers, subscriptions, invoices, wallets and events of a sandbox environment while preserving the catalog. Call without a token to get a confirmation token, then call again with it to start the reset
+// @Tags Environments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Environment ID"
+// @Param request body dto.ResetEnvironmentRequest false "Reset environment request"
+// @Success 200 {object} dto.ResetEnvironmentResponse "Confirmation token issued"
+// @Success 202 {object} dto.ResetEnvironmentResponse "Reset started"
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /environments/{id}/reset [post]
+func (h *EnvironmentHandler) ResetEnvironment(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.ResetEnvironmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+			return
+		}
+	}
+
+	resp, err := h.environmentService.ResetEnvironment(c.Request.Context(), id, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "failed to reset environment", err)
+		return
+	}
+
+	if resp.Reset != nil {
+		c.JSON(http.StatusAccepted, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetEnvironmentReset godoc
+// @Summary Get environment reset progress
+// @Description Get the status and progress of an environment reset
+// @Tags Environments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Environment ID"
+// @Param reset_id path string true "Reset ID"
+// @Success 200 {object} dto.EnvironmentResetResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /environments/{id}/resets/{reset_id} [get]
+func (h *EnvironmentHandler) GetEnvironmentReset(c *gin.Context) {
+	id := c.Param("id")
+	resetID := c.Param("reset_id")
+	if id == "" || resetID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id and reset_id are required", nil)
+		return
+	}
+
+	resp, err := h.environmentService.GetEnvironmentReset(c.Request.Context(), id, resetID)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get environment reset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
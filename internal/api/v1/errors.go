@@ -1,6 +1,13 @@
 package v1
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"net/http"
+
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
 
 // ErrorResponse represents the API error response structure
 type ErrorResponse struct {
@@ -8,6 +15,15 @@ type ErrorResponse struct {
 	Detail string `json:"detail" example:"Invalid request payload"`
 }
 
+// VersionConflictResponse is returned when the entity was changed since the client
+// read it. The client reloads the entity and retries against the current version.
+// It documents every 409 response, the other conflicts are sent without the
+// current version
+type VersionConflictResponse struct {
+	ErrorResponse
+	CurrentVersion int `json:"current_version" example:"3"`
+}
+
 func NewErrorResponse(c *gin.Context, code int, message string, err error) {
 	detail := ""
 	if err != nil {
@@ -18,3 +34,28 @@ func NewErrorResponse(c *gin.Context, code int, message string, err error) {
 		Detail: detail,
 	})
 }
+
+// handleVersionConflict responds with 409 and the current version of the entity
+// when err is a version conflict, and reports whether it did
+func handleVersionConflict(c *gin.Context, err error) bool {
+	var conflict *domainErrors.VersionConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+
+	setETag(c, conflict.CurrentVersion)
+	c.AbortWithStatusJSON(http.StatusConflict, VersionConflictResponse{
+		ErrorResponse: ErrorResponse{
+			Error:  "version conflict",
+			Detail: conflict.Error(),
+		},
+		CurrentVersion: conflict.CurrentVersion,
+	})
+	return true
+}
+
+// setETag sets the ETag of the versioned entity of the response, sent back in
+// If-Match to make a write conditional on it
+func setETag(c *gin.Context, version int) {
+	c.Header(types.HeaderETag, types.ETag(version))
+}
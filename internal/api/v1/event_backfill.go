@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type EventBackfillHandler struct {
+	backfillService service.EventBackfillService
+	logger          *logger.Logger
+}
+
+func NewEventBackfillHandler(backfillService service.EventBackfillService, logger *logger.Logger) *EventBackfillHandler {
+	return &EventBackfillHandler{
+		backfillService: backfillService,
+		logger:          logger,
+	}
+}
+
+// CreateBackfill godoc
+// @Summary Backfill historical events
+// @Description Ingest historical events with their original timestamps, given inline or read from a JSON array of events at an s3 path. Events outside of the time range of the request are rejected. Backfills run in the background, rate limited and apart from the live ingestion, and the rollups of the usage they change are recomputed once they complete. The returned task tracks the progress, rows which failed are listed in its error report
+// @Tags events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateEventBackfillRequest true "Backfill request"
+// @Success 202 {object} dto.TaskResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/backfill [post]
+func (h *EventBackfillHandler) CreateBackfill(c *gin.Context) {
+	var req dto.CreateEventBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request payload", err)
+		return
+	}
+
+	resp, err := h.backfillService.CreateBackfill(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Errorw("failed to create event backfill", "error", err)
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create event backfill", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// GetBackfill godoc
+// @Summary Get an event backfill
+// @Description Get the status and progress of an event backfill
+// @Tags events
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Backfill task ID"
+// @Success 200 {object} dto.TaskResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/backfill/{id} [get]
+func (h *EventBackfillHandler) GetBackfill(c *gin.Context) {
+	resp, err := h.backfillService.GetBackfill(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, service.ErrEventBackfillNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "event backfill not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get event backfill", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
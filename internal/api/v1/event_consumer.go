@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type EventConsumerHandler struct {
+	consumerService service.EventConsumerService
+	logger          *logger.Logger
+}
+
+func NewEventConsumerHandler(consumerService service.EventConsumerService, logger *logger.Logger) *EventConsumerHandler {
+	return &EventConsumerHandler{
+		consumerService: consumerService,
+		logger:          logger,
+	}
+}
+
+// GetConsumerLag godoc
+// @Summary Get events consumer lag
+// @Description Get how many events of each lane, and of each partition of its topic, the consumer group of the lane has not stored yet
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Success 200 {object} dto.ConsumerLagResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/events/consumer-lag [get]
+func (h *EventConsumerHandler) GetConsumerLag(c *gin.Context) {
+	resp, err := h.consumerService.GetConsumerLag(c.Request.Context())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get consumer lag", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type EventCorrectionHandler struct {
+	correctionService service.EventCorrectionService
+	logger            *logger.Logger
+}
+
+func NewEventCorrectionHandler(correctionService service.EventCorrectionService, logger *logger.Logger) *EventCorrectionHandler {
+	return &EventCorrectionHandler{
+		correctionService: correctionService,
+		logger:            logger,
+	}
+}
+
+// VoidEvent godoc
+// @Summary Void an event
+// @Description Cancel the usage of an ingested event, identified by the event_id given at ingestion. A reversal of the event is written which the usage aggregations subtract. Events in a billing period that is already invoiced can not be voided. The void is recorded in the audit log
+// @Tags events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param request body dto.VoidEventRequest false "Void request"
+// @Success 200 {object} dto.EventHistoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/{id}/void [post]
+func (h *EventCorrectionHandler) VoidEvent(c *gin.Context) {
+	var req dto.VoidEventRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid request payload", err)
+			return
+		}
+	}
+
+	resp, err := h.correctionService.VoidEvent(c.Request.Context(), c.Param("id"), req)
+	if h.handleCorrectionError(c, err, "failed to void event") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CorrectEvent godoc
+// @Summary Correct an event
+// @Description Replace the properties of an ingested event, identified by the event_id given at ingestion. A reversal of the event and a replacement with the new properties are written, so usage is aggregated from the corrected properties. Events in a billing period that is already invoiced can not be corrected. The correction is recorded in the audit log
+// @Tags events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param request body dto.CorrectEventRequest true "Correction request"
+// @Success 200 {object} dto.EventHistoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/{id}/correct [post]
+func (h *EventCorrectionHandler) CorrectEvent(c *gin.Context) {
+	var req dto.CorrectEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request payload", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.correctionService.CorrectEvent(c.Request.Context(), c.Param("id"), req)
+	if h.handleCorrectionError(c, err, "failed to correct event") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetEventHistory godoc
+// @Summary Get the history of an event
+// @Description Get an ingested event with the voids and corrections made to it, and the row its usage is currently aggregated from
+// @Tags events
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Success 200 {object} dto.EventHistoryResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/{id}/history [get]
+func (h *EventCorrectionHandler) GetEventHistory(c *gin.Context) {
+	resp, err := h.correctionService.GetEventHistory(c.Request.Context(), c.Param("id"))
+	if h.handleCorrectionError(c, err, "failed to get event history") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleCorrectionError writes the response of a failed void or correction and
+// reports whether there was one
+func (h *EventCorrectionHandler) handleCorrectionError(c *gin.Context, err error, message string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrEventNotFound):
+		NewErrorResponse(c, http.StatusNotFound, "event not found", err)
+	case errors.Is(err, service.ErrEventVoided), errors.Is(err, service.ErrEventPeriodInvoiced):
+		NewErrorResponse(c, http.StatusConflict, "event can not be corrected", err)
+	default:
+		h.logger.Errorw(message, "event_id", c.Param("id"), "error", err)
+		NewErrorResponse(c, http.StatusInternalServerError, message, err)
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type EventDeadLetterHandler struct {
+	deadLetterService service.EventDeadLetterService
+	logger            *logger.Logger
+}
+
+func NewEventDeadLetterHandler(deadLetterService service.EventDeadLetterService, logger *logger.Logger) *EventDeadLetterHandler {
+	return &EventDeadLetterHandler{
+		deadLetterService: deadLetterService,
+		logger:            logger,
+	}
+}
+
+// ListDeadLetters godoc
+// @Summary List dead-lettered events
+// @Description List the events that the consumer failed to store, with the payload and the reason of their last failure. Filter by dead_letter_status=failed for the ones not replayed yet
+// @Tags events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.DeadLetterFilter false "Filter"
+// @Success 200 {object} dto.ListDeadLettersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/dead-letters [get]
+func (h *EventDeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	var filter types.DeadLetterFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.deadLetterService.ListDeadLetters(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list dead letters", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetDeadLetter godoc
+// @Summary Get a dead-lettered event
+// @Description Get a dead-lettered event by ID
+// @Tags events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Dead letter ID"
+// @Success 200 {object} dto.DeadLetterResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/dead-letters/{id} [get]
+func (h *EventDeadLetterHandler) GetDeadLetter(c *gin.Context) {
+	resp, err := h.deadLetterService.GetDeadLetter(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, service.ErrDeadLetterNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "dead letter not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get dead letter", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReplayDeadLetters godoc
+// @Summary Replay dead-lettered events
+// @Description Publish the selected dead-lettered events again to be consumed. Events that fail again are dead-lettered again with their new error
+// @Tags events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ReplayDeadLettersRequest true "Dead letters to replay"
+// @Success 200 {object} dto.ReplayDeadLettersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/dead-letters/replay [post]
+func (h *EventDeadLetterHandler) ReplayDeadLetters(c *gin.Context) {
+	var req dto.ReplayDeadLettersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.deadLetterService.ReplayDeadLetters(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to replay dead letters", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
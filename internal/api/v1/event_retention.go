@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type EventRetentionHandler struct {
+	retentionService service.EventRetentionService
+	logger           *logger.Logger
+}
+
+func NewEventRetentionHandler(retentionService service.EventRetentionService, logger *logger.Logger) *EventRetentionHandler {
+	return &EventRetentionHandler{
+		retentionService: retentionService,
+		logger:           logger,
+	}
+}
+
+// GetEventStorage godoc
+// @Summary Get events storage
+// @Description Get the rows and bytes of events of every tenant and every monthly partition, with the cutoff of the next retention run and when each partition is dropped
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Success 200 {object} dto.EventStorageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/events/storage [get]
+func (h *EventRetentionHandler) GetEventStorage(c *gin.Context) {
+	resp, err := h.retentionService.GetStorage(c.Request.Context(), time.Now())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get events storage", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListRetentionPolicies godoc
+// @Summary List event retention policies
+// @Description List the event retention policies of the tenants. Tenants without a policy keep their events for the default TTL
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Success 200 {object} dto.ListRetentionPoliciesResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/events/retention [get]
+func (h *EventRetentionHandler) ListRetentionPolicies(c *gin.Context) {
+	resp, err := h.retentionService.ListPolicies(c.Request.Context())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list retention policies", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetRetentionPolicy godoc
+// @Summary Set event retention policy
+// @Description Create or replace how long the events of a tenant are kept, a TTL of 0 keeps them forever
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Param tenant_id path string true "Tenant ID"
+// @Param request body dto.SetRetentionPolicyRequest true "Retention policy"
+// @Success 200 {object} dto.RetentionPolicyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/events/retention/{tenant_id} [put]
+func (h *EventRetentionHandler) SetRetentionPolicy(c *gin.Context) {
+	tenantID := c.Param("tenant_id")
+	if tenantID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "tenant_id is required", nil)
+		return
+	}
+
+	var req dto.SetRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.retentionService.SetPolicy(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to set retention policy", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteRetentionPolicy godoc
+// @Summary Delete event retention policy
+// @Description Delete the retention policy of a tenant, its events are then kept for the default TTL
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Param tenant_id path string true "Tenant ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/events/retention/{tenant_id} [delete]
+func (h *EventRetentionHandler) DeleteRetentionPolicy(c *gin.Context) {
+	if err := h.retentionService.DeletePolicy(c.Request.Context(), c.Param("tenant_id")); err != nil {
+		NewErrorResponse(c, http.StatusNotFound, "retention policy not found", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,283 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/eventschema"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// EventValidationResponse is returned when an event does not match the schema
+// of its event name
+type EventValidationResponse struct {
+	ErrorResponse
+	EventID     string                 `json:"event_id"`
+	Quarantined bool                   `json:"quarantined"`
+	Violations  eventschema.Violations `json:"violations"`
+}
+
+// handleEventValidationError responds with the violations of an event that
+// does not match its schema, and reports whether it did. Quarantined events
+// are accepted with 202 unless they are re-submitted
+func handleEventValidationError(c *gin.Context, err error, acceptQuarantined bool) bool {
+	var validationErr *service.EventValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+
+	if validationErr.Quarantined && acceptQuarantined {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":    "Event quarantined",
+			"event_id":   validationErr.EventID,
+			"violations": validationErr.Violations,
+		})
+		return true
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, EventValidationResponse{
+		ErrorResponse: ErrorResponse{
+			Error:  "event does not match its schema",
+			Detail: validationErr.Error(),
+		},
+		EventID:     validationErr.EventID,
+		Quarantined: validationErr.Quarantined,
+		Violations:  validationErr.Violations,
+	})
+	return true
+}
+
+type EventSchemaHandler struct {
+	eventSchemaService service.EventSchemaService
+	logger             *logger.Logger
+}
+
+func NewEventSchemaHandler(eventSchemaService service.EventSchemaService, logger *logger.Logger) *EventSchemaHandler {
+	return &EventSchemaHandler{
+		eventSchemaService: eventSchemaService,
+		logger:             logger,
+	}
+}
+
+// CreateEventSchema godoc
+// @Summary Create an event schema
+// @Description Define the required properties, types and allowed values of the events of an event name. Events that do not match are rejected or quarantined, per on_invalid
+// @Tags Event Schemas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateEventSchemaRequest true "Create event schema request"
+// @Success 201 {object} dto.EventSchemaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /event-schemas [post]
+func (h *EventSchemaHandler) CreateEventSchema(c *gin.Context) {
+	var req dto.CreateEventSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.eventSchemaService.CreateEventSchema(c.Request.Context(), req)
+	if h.handleError(c, err, "failed to create event schema") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListEventSchemas godoc
+// @Summary List event schemas
+// @Description List the event schemas of the tenant ordered by event name
+// @Tags Event Schemas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ListEventSchemasResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /event-schemas [get]
+func (h *EventSchemaHandler) ListEventSchemas(c *gin.Context) {
+	resp, err := h.eventSchemaService.ListEventSchemas(c.Request.Context())
+	if h.handleError(c, err, "failed to list event schemas") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetEventSchema godoc
+// @Summary Get an event schema
+// @Description Get an event schema by ID
+// @Tags Event Schemas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event schema ID"
+// @Success 200 {object} dto.EventSchemaResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /event-schemas/{id} [get]
+func (h *EventSchemaHandler) GetEventSchema(c *gin.Context) {
+	resp, err := h.eventSchemaService.GetEventSchema(c.Request.Context(), c.Param("id"))
+	if h.handleError(c, err, "failed to get event schema") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateEventSchema godoc
+// @Summary Update an event schema
+// @Description Replace the rules of an event schema. Events already ingested or quarantined are not checked again
+// @Tags Event Schemas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event schema ID"
+// @Param request body dto.UpdateEventSchemaRequest true "Update event schema request"
+// @Success 200 {object} dto.EventSchemaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /event-schemas/{id} [put]
+func (h *EventSchemaHandler) UpdateEventSchema(c *gin.Context) {
+	var req dto.UpdateEventSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.eventSchemaService.UpdateEventSchema(c.Request.Context(), c.Param("id"), req)
+	if h.handleError(c, err, "failed to update event schema") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteEventSchema godoc
+// @Summary Delete an event schema
+// @Description Stop checking the events of an event name. Quarantined events stay in quarantine
+// @Tags Event Schemas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event schema ID"
+// @Success 200 {object} gin.H
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /event-schemas/{id} [delete]
+func (h *EventSchemaHandler) DeleteEventSchema(c *gin.Context) {
+	err := h.eventSchemaService.DeleteEventSchema(c.Request.Context(), c.Param("id"))
+	if h.handleError(c, err, "failed to delete event schema") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "event schema deleted successfully"})
+}
+
+// ListQuarantinedEvents godoc
+// @Summary List quarantined events
+// @Description List the events quarantined because they did not match the schema of their event name, with their violations
+// @Tags events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.QuarantinedEventFilter false "Filter"
+// @Success 200 {object} dto.ListQuarantinedEventsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/quarantine [get]
+func (h *EventSchemaHandler) ListQuarantinedEvents(c *gin.Context) {
+	var filter types.QuarantinedEventFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.eventSchemaService.ListQuarantinedEvents(c.Request.Context(), &filter)
+	if h.handleError(c, err, "failed to list quarantined events") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetQuarantinedEvent godoc
+// @Summary Get a quarantined event
+// @Description Get a quarantined event by the ID of the event
+// @Tags events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Success 200 {object} dto.QuarantinedEventResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/quarantine/{id} [get]
+func (h *EventSchemaHandler) GetQuarantinedEvent(c *gin.Context) {
+	resp, err := h.eventSchemaService.GetQuarantinedEvent(c.Request.Context(), c.Param("id"))
+	if h.handleError(c, err, "failed to get quarantined event") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ResubmitQuarantinedEvent godoc
+// @Summary Re-submit a quarantined event
+// @Description Ingest a quarantined event, replacing its properties with the fixed ones of the request if any. An event still not matching its schema stays in quarantine with its fixes
+// @Tags events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Event ID"
+// @Param request body dto.ResubmitQuarantinedEventRequest false "Fixed properties"
+// @Success 200 {object} dto.QuarantinedEventResponse
+// @Failure 400 {object} EventValidationResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/quarantine/{id}/resubmit [post]
+func (h *EventSchemaHandler) ResubmitQuarantinedEvent(c *gin.Context) {
+	var req dto.ResubmitQuarantinedEventRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+			return
+		}
+	}
+
+	resp, err := h.eventSchemaService.ResubmitQuarantinedEvent(c.Request.Context(), c.Param("id"), req)
+	if handleEventValidationError(c, err, false) || h.handleError(c, err, "failed to resubmit quarantined event") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleError writes the response of a failed request and reports whether
+// there was one
+func (h *EventSchemaHandler) handleError(c *gin.Context, err error, message string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrInvalidEventSchema):
+		NewErrorResponse(c, http.StatusBadRequest, "invalid event schema", err)
+	case errors.Is(err, service.ErrEventSchemaNotFound):
+		NewErrorResponse(c, http.StatusNotFound, "event schema not found", err)
+	case errors.Is(err, service.ErrQuarantinedEventNotFound):
+		NewErrorResponse(c, http.StatusNotFound, "quarantined event not found", err)
+	case errors.Is(err, service.ErrEventSchemaExists), errors.Is(err, service.ErrEventAlreadyResubmitted):
+		NewErrorResponse(c, http.StatusConflict, message, err)
+	default:
+		h.logger.Errorw(message, "id", c.Param("id"), "error", err)
+		NewErrorResponse(c, http.StatusInternalServerError, message, err)
+	}
+	return true
+}
@@ -1,14 +1,19 @@
 package v1
 
 import (
+	"compress/gzip"
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
 	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
 	"github.com/gin-gonic/gin"
 )
 
@@ -25,14 +30,16 @@ func NewEventsHandler(eventService service.EventService, log *logger.Logger) *Ev
 }
 
 // @Summary Ingest event
-// @Description Ingest a new event into the system
+// @Description Ingest a new event into the system. An event that does not match the schema of its event name is rejected, or accepted into quarantine when its schema says so. Events of the meters blocked by an exceeded budget of the customer are rejected
 // @Tags events
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param Idempotency-Key header string false "Idempotency key of the event, used when the body has none"
 // @Param event body dto.IngestEventRequest true "Event data"
 // @Success 202 {object} map[string]string "message:Event accepted for processing"
-// @Failure 400 {object} ErrorResponse
+// @Failure 400 {object} EventValidationResponse
+// @Failure 402 {object} ErrorResponse "The customer is over a budget blocking the usage of the meter"
 // @Failure 500 {object} ErrorResponse
 // @Router /events [post]
 func (h *EventsHandler) IngestEvent(c *gin.Context) {
@@ -44,12 +51,23 @@ func (h *EventsHandler) IngestEvent(c *gin.Context) {
 		return
 	}
 
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = c.GetHeader(types.HeaderIdempotencyKey)
+	}
+
 	if err := req.Validate(); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	err := h.eventService.CreateEvent(ctx, &req)
+	if handleEventValidationError(c, err, true) {
+		return
+	}
+	if errors.Is(err, service.ErrUsageBlocked) {
+		NewErrorResponse(c, http.StatusPaymentRequired, "usage blocked by an exceeded budget", err)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to ingest event", "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to ingest event"})
@@ -143,6 +161,7 @@ func (h *EventsHandler) GetUsage(c *gin.Context) {
 // @Param end_time query string false "End Time (RFC3339)"
 // @Param iter_first_key query string false "Iter First Key (unix_timestamp_nanoseconds::event_id)"
 // @Param iter_last_key query string false "Iter Last Key (unix_timestamp_nanoseconds::event_id)"
+// @Param cursor query string false "next_cursor of the previous page"
 // @Param page_size query int false "Page Size (1-50)"
 // @Success 200 {object} dto.GetEventsResponse
 // @Failure 400 {object} ErrorResponse
@@ -156,6 +175,7 @@ func (h *EventsHandler) GetEvents(c *gin.Context) {
 	endTimeStr := c.Query("end_time")
 	iterFirstKey := c.Query("iter_first_key")
 	iterLastKey := c.Query("iter_last_key")
+	cursor := c.Query("cursor")
 
 	pageSize := 50
 	if size := c.Query("page_size"); size != "" {
@@ -180,7 +200,12 @@ func (h *EventsHandler) GetEvents(c *gin.Context) {
 		PageSize:           pageSize,
 		IterFirstKey:       iterFirstKey,
 		IterLastKey:        iterLastKey,
+		Cursor:             cursor,
 	})
+	if errors.Is(err, types.ErrInvalidCursor) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
 	if err != nil {
 		h.log.Error("Failed to get events", "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get events"})
@@ -190,6 +215,89 @@ func (h *EventsHandler) GetEvents(c *gin.Context) {
 	c.JSON(http.StatusOK, events)
 }
 
+// @Summary Export events
+// @Description Stream the events of a time range as newline delimited JSON, oldest first. Every line carries a cursor, an interrupted export resumes after an event by repeating the request with its cursor. The response is gzip compressed when the client accepts it. An error occurring once the export started is reported as a last line with an error field
+// @Tags events
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Param request query dto.ExportEventsRequest true "Export filters"
+// @Success 200 {object} dto.ExportedEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events/export [get]
+func (h *EventsHandler) ExportEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req dto.ExportEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	var out io.Writer = c.Writer
+	var gz *gzip.Writer
+	started := false
+
+	err := h.eventService.ExportEvents(ctx, &req, func(batch []*dto.ExportedEvent) error {
+		if !started {
+			started = true
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Header("Cache-Control", "no-cache")
+			c.Header("X-Accel-Buffering", "no")
+			if strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+				c.Header("Content-Encoding", "gzip")
+				c.Header("Vary", "Accept-Encoding")
+				gz = gzip.NewWriter(c.Writer)
+				out = gz
+			}
+			c.Status(http.StatusOK)
+		}
+
+		encoder := json.NewEncoder(out)
+		for _, event := range batch {
+			if err := encoder.Encode(event); err != nil {
+				return err
+			}
+		}
+
+		// Flush every batch so the client receives the events as they are read
+		if gz != nil {
+			if err := gz.Flush(); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return ctx.Err()
+	})
+
+	switch {
+	case errors.Is(err, service.ErrInvalidExportCursor):
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	case err != nil && !started:
+		h.log.Errorw("failed to export events", "error", err)
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to export events", err)
+		return
+	case err != nil:
+		h.log.Errorw("failed to export events", "error", err)
+		if ctx.Err() == nil {
+			_ = json.NewEncoder(out).Encode(gin.H{"error": "failed to export events"})
+		}
+	case !started:
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+	}
+
+	if gz != nil {
+		_ = gz.Close()
+	}
+}
+
 func parseStartAndEndTime(startTimeStr, endTimeStr string) (time.Time, time.Time, error) {
 	var startTime time.Time
 	var endTime time.Time
@@ -0,0 +1,246 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportService service.ExportService
+	logger        *logger.Logger
+}
+
+func NewExportHandler(exportService service.ExportService, logger *logger.Logger) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		logger:        logger,
+	}
+}
+
+// CreateExport godoc
+// @Summary Create an export
+// @Description Start an asynchronous export of raw events or aggregated usage to object storage. The export is pending until the export job runs it, poll it for its status
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateExportRequest true "Create export request"
+// @Success 202 {object} dto.ExportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /exports [post]
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	var req dto.CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.exportService.CreateExport(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create export", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// GetExport godoc
+// @Summary Get an export
+// @Description Get an export by ID to poll its status
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export ID"
+// @Success 200 {object} dto.ExportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.exportService.GetExport(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get export", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetExports godoc
+// @Summary List exports
+// @Description List exports with pagination
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.Filter false "Filter"
+// @Success 200 {object} dto.ListExportsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /exports [get]
+func (h *ExportHandler) GetExports(c *gin.Context) {
+	var filter types.Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.exportService.GetExports(c.Request.Context(), filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get exports", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetExportDownloadURL godoc
+// @Summary Get export download URL
+// @Description Get a signed, time limited URL to download a completed export
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export ID"
+// @Success 200 {object} dto.ExportDownloadResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /exports/{id}/download [get]
+func (h *ExportHandler) GetExportDownloadURL(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.exportService.GetDownloadURL(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get download url", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateSchedule godoc
+// @Summary Create an export schedule
+// @Description Schedule a daily, weekly or monthly export of raw events or aggregated usage. Each run, at midnight UTC, exports the previous day, week or month
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateExportScheduleRequest true "Create export schedule request"
+// @Success 201 {object} dto.ExportScheduleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /exports/schedules [post]
+func (h *ExportHandler) CreateSchedule(c *gin.Context) {
+	var req dto.CreateExportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.exportService.CreateSchedule(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create export schedule", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListSchedules godoc
+// @Summary List export schedules
+// @Description List the export schedules of the tenant
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.Filter false "Filter"
+// @Success 200 {object} dto.ListExportSchedulesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /exports/schedules [get]
+func (h *ExportHandler) ListSchedules(c *gin.Context) {
+	var filter types.Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.exportService.ListSchedules(c.Request.Context(), filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list export schedules", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetSchedule godoc
+// @Summary Get an export schedule
+// @Description Get an export schedule by ID
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export schedule ID"
+// @Success 200 {object} dto.ExportScheduleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /exports/schedules/{id} [get]
+func (h *ExportHandler) GetSchedule(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.exportService.GetSchedule(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get export schedule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteSchedule godoc
+// @Summary Delete an export schedule
+// @Description Delete an export schedule, which stops its runs. Its exports are kept
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export schedule ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /exports/schedules/{id} [delete]
+func (h *ExportHandler) DeleteSchedule(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	if err := h.exportService.DeleteSchedule(c.Request.Context(), id); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete export schedule", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
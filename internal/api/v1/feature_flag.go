@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type FeatureFlagHandler struct {
+	featureFlagService service.FeatureFlagService
+	logger             *logger.Logger
+}
+
+func NewFeatureFlagHandler(featureFlagService service.FeatureFlagService, logger *logger.Logger) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		featureFlagService: featureFlagService,
+		logger:             logger,
+	}
+}
+
+// ListFeatureFlags godoc
+// @Summary List feature flags of a tenant
+// @Description List the feature flags with their default and the overrides of a tenant
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Param tenant_id path string true "Tenant ID"
+// @Success 200 {object} dto.ListFeatureFlagsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/feature-flags/{tenant_id} [get]
+func (h *FeatureFlagHandler) ListFeatureFlags(c *gin.Context) {
+	resp, err := h.featureFlagService.ListFlags(c.Request.Context(), c.Param("tenant_id"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list feature flags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetFeatureFlag godoc
+// @Summary Set feature flag override
+// @Description Turn a feature flag on or off for a tenant, in one of its environments or in all of them
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Param tenant_id path string true "Tenant ID"
+// @Param flag path string true "Feature flag"
+// @Param request body dto.SetFeatureFlagRequest true "Feature flag override"
+// @Success 200 {object} dto.FeatureFlagOverrideResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/feature-flags/{tenant_id}/{flag} [put]
+func (h *FeatureFlagHandler) SetFeatureFlag(c *gin.Context) {
+	tenantID := c.Param("tenant_id")
+	if tenantID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "tenant_id is required", nil)
+		return
+	}
+
+	var req dto.SetFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.featureFlagService.SetOverride(c.Request.Context(), tenantID, types.FeatureFlag(c.Param("flag")), &req)
+	if errors.Is(err, service.ErrUnknownFeatureFlag) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to set feature flag", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteFeatureFlag godoc
+// @Summary Delete feature flag override
+// @Description Delete an override of a tenant, the flag then falls back on the override of the tenant or on its default
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Param tenant_id path string true "Tenant ID"
+// @Param flag path string true "Feature flag"
+// @Param environment_id query string false "Environment of the override, the override of all environments when empty"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/feature-flags/{tenant_id}/{flag} [delete]
+func (h *FeatureFlagHandler) DeleteFeatureFlag(c *gin.Context) {
+	err := h.featureFlagService.DeleteOverride(c.Request.Context(),
+		c.Param("tenant_id"), types.FeatureFlag(c.Param("flag")), c.Query("environment_id"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusNotFound, "feature flag override not found", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
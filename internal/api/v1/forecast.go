@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type ForecastHandler struct {
+	forecastService service.ForecastService
+	logger          *logger.Logger
+}
+
+func NewForecastHandler(forecastService service.ForecastService, logger *logger.Logger) *ForecastHandler {
+	return &ForecastHandler{
+		forecastService: forecastService,
+		logger:          logger,
+	}
+}
+
+// GetCustomerForecast godoc
+// @Summary Forecast customer spend
+// @Description Project the total of the invoice each active subscription of the customer renews with, from the usage of the current period so far. The linear model extrapolates the run-rate of the period, the seasonal model weighs the days left by the usage of their day of the week over the last four weeks
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param filter query types.ForecastFilter false "Filter"
+// @Success 200 {object} dto.CustomerForecastResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/forecast [get]
+func (h *ForecastHandler) GetCustomerForecast(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var filter types.ForecastFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	switch filter.Model {
+	case "", types.ForecastModelLinear, types.ForecastModelSeasonal:
+	default:
+		NewErrorResponse(c, http.StatusBadRequest, "model must be linear or seasonal", nil)
+		return
+	}
+
+	resp, err := h.forecastService.ForecastCustomerSpend(c.Request.Context(), id, filter, time.Now().UTC())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to forecast customer spend", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/graphql"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLHandler struct {
+	schema *graphql.Schema
+	logger *logger.Logger
+}
+
+func NewGraphQLHandler(
+	customerService service.CustomerService,
+	subscriptionService service.SubscriptionService,
+	invoiceService service.InvoiceService,
+	logger *logger.Logger,
+) *GraphQLHandler {
+	return &GraphQLHandler{
+		schema: graphql.NewSchema(customerService, subscriptionService, invoiceService),
+		logger: logger,
+	}
+}
+
+// Query godoc
+// @Summary Query billing data with GraphQL
+// @Description Read only GraphQL endpoint over customers, subscriptions with their line items and usage, and invoices, to fetch nested data in one round trip. Field errors are returned next to the partial data with a 200 status
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body graphql.Request true "GraphQL request"
+// @Success 200 {object} graphql.Response
+// @Failure 400 {object} ErrorResponse
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graphql.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	// A query only reads, the records it resolves several times are read once
+	resp := h.schema.Execute(types.WithRequestCache(c.Request.Context()), req)
+	if len(resp.Errors) > 0 {
+		h.logger.Debugw("graphql query returned errors", "errors", resp.Errors)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,766 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	invoiceService service.InvoiceService
+	logger         *logger.Logger
+}
+
+func NewInvoiceHandler(invoiceService service.InvoiceService, logger *logger.Logger) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		logger:         logger,
+	}
+}
+
+// CreateSubscriptionInvoice godoc
+// @Summary Create a subscription invoice
+// @Description Run billing for a subscription period and create a draft invoice. A negative total is issued as a credit invoice linked to the last finalized invoice
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Subscription ID"
+// @Param request body dto.CreateSubscriptionInvoiceRequest true "Create subscription invoice request"
+// @Success 201 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/invoices [post]
+func (h *InvoiceHandler) CreateSubscriptionInvoice(c *gin.Context) {
+	subscriptionID := c.Param("id")
+	if subscriptionID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "subscription id is required", nil)
+		return
+	}
+
+	var req dto.CreateSubscriptionInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.CreateSubscriptionInvoice(c.Request.Context(), subscriptionID, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create invoice", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// CreateCustomerInvoices godoc
+// @Summary Create the invoices of a customer
+// @Description Run billing for the subscriptions of a customer whose current period ends at the billing date. Customers consolidating invoices get one invoice per currency with a section per subscription, others one invoice per subscription
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param request body dto.CreateCustomerInvoicesRequest true "Create customer invoices request"
+// @Success 201 {object} dto.ListInvoicesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/invoices [post]
+func (h *InvoiceHandler) CreateCustomerInvoices(c *gin.Context) {
+	customerID := c.Param("id")
+	if customerID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "customer id is required", nil)
+		return
+	}
+
+	var req dto.CreateCustomerInvoicesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.CreateCustomerInvoices(c.Request.Context(), customerID, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create invoices", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// CreateOneOffInvoice godoc
+// @Summary Create a one-off invoice
+// @Description Raise an invoice for a customer outside of any subscription, ex for professional services. Charges are priced by an existing fixed price or a free-form unit amount, discounts apply to the charges and taxes to the discounted subtotal. The invoice is created in draft unless finalize is set and is paid like any other invoice
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateOneOffInvoiceRequest true "Create one-off invoice request"
+// @Success 201 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/one-off [post]
+func (h *InvoiceHandler) CreateOneOffInvoice(c *gin.Context) {
+	var req dto.CreateOneOffInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.CreateOneOffInvoice(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create invoice", err)
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetUpcomingInvoice godoc
+// @Summary Preview the upcoming invoice
+// @Description Preview the invoice billed at the end of the current period of a subscription: the fixed charges, the usage to date or projected over the whole period at its current run-rate, the taxes, the simulated wallet draw-down and the amount left to pay out of pocket. Nothing is persisted
+// @Tags Invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Subscription ID"
+// @Param request query dto.UpcomingInvoiceRequest false "Preview options"
+// @Success 200 {object} dto.UpcomingInvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/invoices/upcoming [get]
+func (h *InvoiceHandler) GetUpcomingInvoice(c *gin.Context) {
+	subscriptionID := c.Param("id")
+	if subscriptionID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "subscription id is required", nil)
+		return
+	}
+
+	var req dto.UpcomingInvoiceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	var projection *service.UpcomingInvoiceProjection
+	if req.ProjectUsage {
+		projection = &service.UpcomingInvoiceProjection{}
+	}
+
+	resp, err := h.invoiceService.GetUpcomingInvoice(c.Request.Context(), subscriptionID, projection)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to preview upcoming invoice", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetUsageLock godoc
+// @Summary Get the usage lock of a subscription period
+// @Description Get whether the current period of the subscription is open, in its grace period accepting late events with a timestamp inside the period, or locked for invoicing. Periods lock billing.usage_lock_delay_hours after their end
+// @Tags subscriptions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} dto.UsageLockResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/usage-lock [get]
+func (h *InvoiceHandler) GetUsageLock(c *gin.Context) {
+	subscriptionID := c.Param("id")
+	if subscriptionID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "subscription id is required", nil)
+		return
+	}
+
+	resp, err := h.invoiceService.GetUsageLock(c.Request.Context(), subscriptionID, time.Now().UTC())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get usage lock", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetInvoice godoc
+// @Summary Get an invoice
+// @Description Get an invoice by ID along with its line items and linked credit invoices
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id} [get]
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.invoiceService.GetInvoice(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get invoice", err)
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListInvoices godoc
+// @Summary List invoices
+// @Description List invoices with filters
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.InvoiceFilter false "Filter"
+// @Success 200 {object} dto.ListInvoicesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices [get]
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	var filter types.InvoiceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+	if err := filter.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.invoiceService.ListInvoices(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list invoices", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// FinalizeInvoice godoc
+// @Summary Finalize an invoice
+// @Description Finalize a draft invoice so that it can no longer be edited
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param If-Match header string false "ETag of the invoice the finalization is made against"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/finalize [post]
+func (h *InvoiceHandler) FinalizeInvoice(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.invoiceService.FinalizeInvoice(c.Request.Context(), id)
+	if handleVersionConflict(c, err) {
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to finalize invoice", err)
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// ApproveInvoice godoc
+// @Summary Approve a held invoice
+// @Description Move an invoice held for review by the billing guardrails back to draft so that it can be finalized
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param If-Match header string false "ETag of the invoice the approval is made against"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/approve [post]
+func (h *InvoiceHandler) ApproveInvoice(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.invoiceService.ApproveInvoice(c.Request.Context(), id)
+	if handleVersionConflict(c, err) {
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to approve invoice", err)
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// VoidInvoice godoc
+// @Summary Void an invoice
+// @Description Void a finalized invoice that was not paid, collected or credited. Its tax transaction is voided at the tax provider
+// @Tags Invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param If-Match header string false "ETag of the invoice the void is made against"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/void [post]
+func (h *InvoiceHandler) VoidInvoice(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.invoiceService.VoidInvoice(c.Request.Context(), id)
+	if errors.Is(err, service.ErrInvoiceNotVoidable) {
+		NewErrorResponse(c, http.StatusBadRequest, "invoice can not be voided", err)
+		return
+	}
+	if handleVersionConflict(c, err) {
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to void invoice", err)
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// WriteOffInvoice godoc
+// @Summary Write off an invoice
+// @Description Mark the amount remaining of a finalized invoice as uncollectible. Its scheduled bank debits are cancelled, it is no longer collected and it moves from the receivables aging to the write-off report. Payments recovered later can still be recorded against it
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param If-Match header string false "ETag of the invoice the write-off is made against"
+// @Param request body dto.WriteOffInvoiceRequest true "Write-off"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/write-off [post]
+func (h *InvoiceHandler) WriteOffInvoice(c *gin.Context) {
+	var req dto.WriteOffInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.WriteOffInvoice(c.Request.Context(), c.Param("id"), req)
+	if errors.Is(err, service.ErrInvoiceNotWritableOff) {
+		NewErrorResponse(c, http.StatusBadRequest, "invoice can not be written off", err)
+		return
+	}
+	if errors.Is(err, service.ErrInvoiceDisputed) {
+		NewErrorResponse(c, http.StatusConflict, "dispute conflict", err)
+		return
+	}
+	if handleVersionConflict(c, err) {
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to write off invoice", err)
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// AddLineItem godoc
+// @Summary Add an invoice line item
+// @Description Add a manual line item to a draft or held invoice, such as a one-off charge or, with a negative amount, a discount. The totals of the invoice are recomputed and the edit is recorded in the audit log
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param If-Match header string false "ETag of the invoice the edit is made against"
+// @Param request body dto.AddInvoiceLineItemRequest true "Line item"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/line-items [post]
+func (h *InvoiceHandler) AddLineItem(c *gin.Context) {
+	var req dto.AddInvoiceLineItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.AddLineItem(c.Request.Context(), c.Param("id"), req)
+	if h.handleLineItemError(c, err, "failed to add line item") {
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateLineItem godoc
+// @Summary Update an invoice line item
+// @Description Edit the display name, amount or quantity of a line item of a draft or held invoice. The quantity of a usage line item is metered and its amount can only be adjusted within the configured tolerance. The edit is recorded in the audit log
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param li_id path string true "Line item ID"
+// @Param If-Match header string false "ETag of the invoice the edit is made against"
+// @Param request body dto.UpdateInvoiceLineItemRequest true "Line item change"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/line-items/{li_id} [patch]
+func (h *InvoiceHandler) UpdateLineItem(c *gin.Context) {
+	var req dto.UpdateInvoiceLineItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.UpdateLineItem(c.Request.Context(), c.Param("id"), c.Param("li_id"), req)
+	if h.handleLineItemError(c, err, "failed to update line item") {
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// RemoveLineItem godoc
+// @Summary Remove an invoice line item
+// @Description Remove a line item from a draft or held invoice. A usage line item can only be removed when the configured tolerance allows its amount to go to zero. The edit is recorded in the audit log
+// @Tags Invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param li_id path string true "Line item ID"
+// @Param If-Match header string false "ETag of the invoice the edit is made against"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/line-items/{li_id} [delete]
+func (h *InvoiceHandler) RemoveLineItem(c *gin.Context) {
+	resp, err := h.invoiceService.RemoveLineItem(c.Request.Context(), c.Param("id"), c.Param("li_id"))
+	if h.handleLineItemError(c, err, "failed to remove line item") {
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleLineItemError writes the response of a failed line item edit and
+// reports whether there was one
+func (h *InvoiceHandler) handleLineItemError(c *gin.Context, err error, message string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrInvoiceLineItemNotFound):
+		NewErrorResponse(c, http.StatusNotFound, "line item not found", err)
+	case errors.Is(err, service.ErrInvoiceNotEditable), errors.Is(err, service.ErrUsageAdjustmentOutOfTolerance),
+		errors.Is(err, service.ErrTaxLineItemNotEditable):
+		NewErrorResponse(c, http.StatusBadRequest, "invalid line item edit", err)
+	case handleVersionConflict(c, err):
+	default:
+		NewErrorResponse(c, http.StatusInternalServerError, message, err)
+	}
+	return true
+}
+
+// RecordPayment godoc
+// @Summary Record an invoice payment
+// @Description Record a full or partial payment against a finalized invoice. The amount remaining and the payment status of the invoice are recomputed, a payment can not exceed the amount remaining
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param If-Match header string false "ETag of the invoice the payment is recorded against"
+// @Param request body dto.RecordInvoicePaymentRequest true "Record invoice payment request"
+// @Success 201 {object} dto.InvoicePaymentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/payments [post]
+func (h *InvoiceHandler) RecordPayment(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.RecordInvoicePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.RecordPayment(c.Request.Context(), id, req)
+	if handleVersionConflict(c, err) {
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to record payment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListPayments godoc
+// @Summary List invoice payments
+// @Description List the payments recorded against an invoice in the order they were paid, along with the amount paid and remaining
+// @Tags Invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} dto.ListInvoicePaymentsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/payments [get]
+func (h *InvoiceHandler) ListPayments(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.invoiceService.ListPayments(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list payments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// OpenDispute godoc
+// @Summary Dispute an invoice
+// @Description Record the amounts of line items of a finalized invoice the customer disputes, up to the part of the invoice not paid or submitted for collection. The bank debits and gateway collections of the invoice are paused until the dispute is resolved. Sent as an invoice.dispute.opened event
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param request body dto.OpenDisputeRequest true "Dispute"
+// @Success 201 {object} dto.DisputeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/disputes [post]
+func (h *InvoiceHandler) OpenDispute(c *gin.Context) {
+	var req dto.OpenDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.OpenDispute(c.Request.Context(), c.Param("id"), req)
+	if h.handleDisputeError(c, err, "failed to open dispute") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListDisputes godoc
+// @Summary List invoice disputes
+// @Description List the disputes of an invoice, oldest first
+// @Tags Invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} dto.ListDisputesResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/disputes [get]
+func (h *InvoiceHandler) ListDisputes(c *gin.Context) {
+	resp, err := h.invoiceService.ListDisputes(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list disputes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReviewDispute godoc
+// @Summary Review an invoice dispute
+// @Description Move an open dispute of an invoice under review. Sent as an invoice.dispute.under_review event
+// @Tags Invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param dispute_id path string true "Dispute ID"
+// @Success 200 {object} dto.DisputeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/disputes/{dispute_id}/review [post]
+func (h *InvoiceHandler) ReviewDispute(c *gin.Context) {
+	resp, err := h.invoiceService.ReviewDispute(c.Request.Context(), c.Param("id"), c.Param("dispute_id"))
+	if h.handleDisputeError(c, err, "failed to review dispute") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ResolveDispute godoc
+// @Summary Resolve an invoice dispute
+// @Description Settle the dispute of an invoice and resume its collection. credit_note issues a credit note for the disputed amounts allocated to the invoice, reissue voids the invoice and raises a corrected draft without them, uphold keeps the invoice as issued. The debits of the invoice still scheduled are cancelled when its amount changes. Sent as an invoice.dispute.resolved event
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param dispute_id path string true "Dispute ID"
+// @Param request body dto.ResolveDisputeRequest true "Resolution"
+// @Success 200 {object} dto.DisputeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/disputes/{dispute_id}/resolve [post]
+func (h *InvoiceHandler) ResolveDispute(c *gin.Context) {
+	var req dto.ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.ResolveDispute(c.Request.Context(), c.Param("id"), c.Param("dispute_id"), req)
+	if h.handleDisputeError(c, err, "failed to resolve dispute") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleDisputeError writes the response of a failed dispute operation and
+// reports whether there was one
+func (h *InvoiceHandler) handleDisputeError(c *gin.Context, err error, message string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrDisputeNotFound), errors.Is(err, service.ErrInvoiceLineItemNotFound):
+		NewErrorResponse(c, http.StatusNotFound, "not found", err)
+	case errors.Is(err, service.ErrInvoiceDisputed), errors.Is(err, service.ErrDisputeResolved):
+		NewErrorResponse(c, http.StatusConflict, "dispute conflict", err)
+	case errors.Is(err, service.ErrInvoiceNotVoidable):
+		NewErrorResponse(c, http.StatusBadRequest, "invoice can not be re-issued", err)
+	default:
+		NewErrorResponse(c, http.StatusInternalServerError, message, err)
+	}
+	return true
+}
+
+// GetInvoiceNumberingConfig godoc
+// @Summary Get invoice numbering config
+// @Description Get the invoice number format of the current tenant environment
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.InvoiceNumberingConfigResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/numbering [get]
+func (h *InvoiceHandler) GetInvoiceNumberingConfig(c *gin.Context) {
+	resp, err := h.invoiceService.GetInvoiceNumberingConfig(c.Request.Context())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get invoice numbering config", err)
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateInvoiceNumberingConfig godoc
+// @Summary Update invoice numbering config
+// @Description Update the invoice number format of the current tenant environment. Changing the prefix starts a new counter
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param If-Match header string false "ETag of the numbering config the update is made against"
+// @Param request body dto.UpdateInvoiceNumberingConfigRequest true "Invoice numbering config"
+// @Success 200 {object} dto.InvoiceNumberingConfigResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/numbering [put]
+func (h *InvoiceHandler) UpdateInvoiceNumberingConfig(c *gin.Context) {
+	var req dto.UpdateInvoiceNumberingConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.invoiceService.UpdateInvoiceNumberingConfig(c.Request.Context(), req)
+	if handleVersionConflict(c, err) {
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to update invoice numbering config", err)
+		return
+	}
+
+	setETag(c, resp.Version)
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/scheduler"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	jobService service.JobService
+	logger     *logger.Logger
+}
+
+func NewJobHandler(jobService service.JobService, logger *logger.Logger) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+		logger:     logger,
+	}
+}
+
+// ListJobs godoc
+// @Summary List background jobs
+// @Description List the background jobs with their schedule as configured for the deployment and their last run
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Success 200 {object} dto.ListJobsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	resp, err := h.jobService.ListJobs(c.Request.Context())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list jobs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListJobRuns godoc
+// @Summary List job runs
+// @Description List the latest runs of a background job first
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Param name path string true "Job name"
+// @Param limit query int false "Number of runs (1-100, default 20)"
+// @Success 200 {object} dto.ListJobRunsResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/jobs/{name}/runs [get]
+func (h *JobHandler) ListJobRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	resp, err := h.jobService.ListJobRuns(c.Request.Context(), c.Param("name"), limit)
+	if errors.Is(err, scheduler.ErrJobNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "job not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list job runs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// TriggerJob godoc
+// @Summary Trigger job
+// @Description Start a run of a background job right away, disabled jobs included. The run happens in the background, poll the runs of the job for its result
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Param name path string true "Job name"
+// @Success 202 {object} dto.JobRunResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Router /admin/jobs/{name}/trigger [post]
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	resp, err := h.jobService.TriggerJob(c.Request.Context(), c.Param("name"))
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		NewErrorResponse(c, http.StatusNotFound, "job not found", err)
+		return
+	case errors.Is(err, scheduler.ErrJobRunning):
+		NewErrorResponse(c, http.StatusConflict, "job is already running", err)
+		return
+	case err != nil:
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to trigger job", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
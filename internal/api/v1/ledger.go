@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerSyncHandler struct {
+	ledgerSyncService service.LedgerSyncService
+	logger            *logger.Logger
+}
+
+func NewLedgerSyncHandler(ledgerSyncService service.LedgerSyncService, logger *logger.Logger) *LedgerSyncHandler {
+	return &LedgerSyncHandler{
+		ledgerSyncService: ledgerSyncService,
+		logger:            logger,
+	}
+}
+
+// ListSyncs godoc
+// @Summary List ledger syncs
+// @Description List the reconciliation status of the invoices and credit notes pushed to NetSuite and QuickBooks
+// @Tags Ledger
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.LedgerSyncFilter false "Filter"
+// @Success 200 {object} dto.ListLedgerSyncsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /ledger-syncs [get]
+func (h *LedgerSyncHandler) ListSyncs(c *gin.Context) {
+	var filter types.LedgerSyncFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.ledgerSyncService.ListSyncs(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list ledger syncs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListInvoiceSyncs godoc
+// @Summary List the ledger syncs of an invoice
+// @Description Get the reconciliation status of an invoice with every ledger connection
+// @Tags Ledger
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} dto.ListLedgerSyncsResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/ledger-syncs [get]
+func (h *LedgerSyncHandler) ListInvoiceSyncs(c *gin.Context) {
+	resp, err := h.ledgerSyncService.ListInvoiceSyncs(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list ledger syncs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RetryInvoiceSync godoc
+// @Summary Retry the ledger sync of an invoice
+// @Description Queue a finalized invoice again for the ledger connections it is not synced with
+// @Tags Ledger
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} dto.ListLedgerSyncsResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/ledger-syncs/retry [post]
+func (h *LedgerSyncHandler) RetryInvoiceSync(c *gin.Context) {
+	resp, err := h.ledgerSyncService.RetryInvoiceSync(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to retry ledger sync", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
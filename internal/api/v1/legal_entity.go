@@ -0,0 +1,153 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type LegalEntityHandler struct {
+	legalEntityService service.LegalEntityService
+	logger             *logger.Logger
+}
+
+func NewLegalEntityHandler(legalEntityService service.LegalEntityService, logger *logger.Logger) *LegalEntityHandler {
+	return &LegalEntityHandler{
+		legalEntityService: legalEntityService,
+		logger:             logger,
+	}
+}
+
+// CreateLegalEntity godoc
+// @Summary Create a legal entity
+// @Description Add a company of the tenant invoices are issued from, with its address, tax IDs, invoice numbering and bank details. Customers and subscriptions are assigned to it with their legal_entity_id
+// @Tags Legal Entities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateLegalEntityRequest true "Create legal entity request"
+// @Success 201 {object} dto.LegalEntityResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /legal-entities [post]
+func (h *LegalEntityHandler) CreateLegalEntity(c *gin.Context) {
+	var req dto.CreateLegalEntityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.legalEntityService.CreateLegalEntity(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create legal entity", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListLegalEntities godoc
+// @Summary List legal entities
+// @Description List the legal entities of the tenant ordered by name
+// @Tags Legal Entities
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ListLegalEntitiesResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /legal-entities [get]
+func (h *LegalEntityHandler) ListLegalEntities(c *gin.Context) {
+	resp, err := h.legalEntityService.ListLegalEntities(c.Request.Context())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list legal entities", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetLegalEntity godoc
+// @Summary Get a legal entity
+// @Tags Legal Entities
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Legal entity ID"
+// @Success 200 {object} dto.LegalEntityResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /legal-entities/{id} [get]
+func (h *LegalEntityHandler) GetLegalEntity(c *gin.Context) {
+	resp, err := h.legalEntityService.GetLegalEntity(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusNotFound, "legal entity not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateLegalEntity godoc
+// @Summary Update a legal entity
+// @Description Replace a legal entity. The invoices it already issued keep their numbers, a new prefix starts a new sequence
+// @Tags Legal Entities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Legal entity ID"
+// @Param request body dto.UpdateLegalEntityRequest true "Update legal entity request"
+// @Success 200 {object} dto.LegalEntityResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /legal-entities/{id} [put]
+func (h *LegalEntityHandler) UpdateLegalEntity(c *gin.Context) {
+	var req dto.UpdateLegalEntityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.legalEntityService.UpdateLegalEntity(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to update legal entity", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteLegalEntity godoc
+// @Summary Delete a legal entity
+// @Description Delete a legal entity no customer or active subscription is assigned to. The invoices it issued keep it
+// @Tags Legal Entities
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Legal entity ID"
+// @Success 200 {object} gin.H
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /legal-entities/{id} [delete]
+func (h *LegalEntityHandler) DeleteLegalEntity(c *gin.Context) {
+	err := h.legalEntityService.DeleteLegalEntity(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, service.ErrLegalEntityInUse) {
+		NewErrorResponse(c, http.StatusConflict, "legal entity is in use", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete legal entity", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "legal entity deleted successfully"})
+}
@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type MandateHandler struct {
+	mandateService service.MandateService
+	logger         *logger.Logger
+}
+
+func NewMandateHandler(mandateService service.MandateService, logger *logger.Logger) *MandateHandler {
+	return &MandateHandler{
+		mandateService: mandateService,
+		logger:         logger,
+	}
+}
+
+// ListMandates godoc
+// @Summary List the mandates of a customer
+// @Description List the bank debit mandates of the customer, oldest first, with the ones revoked or failed
+// @Tags customers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} dto.ListMandatesResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/mandates [get]
+func (h *MandateHandler) ListMandates(c *gin.Context) {
+	resp, err := h.mandateService.ListMandates(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list mandates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateMandate godoc
+// @Summary Create a mandate for a customer
+// @Description Record the SEPA or ACH mandate the customer signed for one of their bank account payment methods. Its debits are notified to the customer the pre-notification days of the scheme before they are charged
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param request body dto.CreateMandateRequest true "Mandate"
+// @Success 201 {object} dto.MandateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/mandates [post]
+func (h *MandateHandler) CreateMandate(c *gin.Context) {
+	var req dto.CreateMandateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.mandateService.CreateMandate(c.Request.Context(), c.Param("id"), req)
+	if errors.Is(err, service.ErrPaymentMethodNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "payment method not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create mandate", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// RevokeMandate godoc
+// @Summary Revoke a mandate of a customer
+// @Description Revoke an active mandate, its scheduled debits fail when they are due
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param mandate_id path string true "Mandate ID"
+// @Param request body dto.RevokeMandateRequest false "Revocation"
+// @Success 200 {object} dto.MandateResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/mandates/{mandate_id}/revoke [post]
+func (h *MandateHandler) RevokeMandate(c *gin.Context) {
+	var req dto.RevokeMandateRequest
+	// The reason is optional, so is the body
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+	}
+
+	resp, err := h.mandateService.RevokeMandate(c.Request.Context(), c.Param("id"), c.Param("mandate_id"), req)
+	if errors.Is(err, service.ErrMandateNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "mandate not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to revoke mandate", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type MarginHandler struct {
+	marginService service.MarginService
+	logger        *logger.Logger
+}
+
+func NewMarginHandler(marginService service.MarginService, logger *logger.Logger) *MarginHandler {
+	return &MarginHandler{
+		marginService: marginService,
+		logger:        logger,
+	}
+}
+
+// GetMarginReport godoc
+// @Summary Get gross margin report
+// @Description Report the revenue of the charges of the finalized invoices whose service period starts in the given range against their cost of goods sold, by customer, plan or meter. The cost of a charge is its quantity times the unit cost of its price, charges of prices without a unit cost count as uncosted revenue
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.MarginFilter true "Filter"
+// @Success 200 {object} dto.MarginReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/margin [get]
+func (h *MarginHandler) GetMarginReport(c *gin.Context) {
+	var filter types.MarginFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	if !filter.EndTime.After(filter.StartTime) {
+		NewErrorResponse(c, http.StatusBadRequest, "end_time must be after start_time", nil)
+		return
+	}
+
+	resp, err := h.marginService.GetMarginReport(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get margin report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
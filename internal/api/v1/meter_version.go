@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type MeterVersionHandler struct {
+	versionService service.MeterVersionService
+	logger         *logger.Logger
+}
+
+func NewMeterVersionHandler(versionService service.MeterVersionService, logger *logger.Logger) *MeterVersionHandler {
+	return &MeterVersionHandler{
+		versionService: versionService,
+		logger:         logger,
+	}
+}
+
+// CreateVersion godoc
+// @Summary Create a meter version
+// @Description Change the aggregation and filters of a meter from effective_from on, now by default. Usage before the effective date keeps being aggregated with the definition it happened under; recompute a past period under a new definition with a re-aggregation. A meter can not switch to or from an AVG aggregation and COUNT_UNIQUE and PERCENTILE meters are not versioned. The change is recorded in the audit log
+// @Tags meters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Meter ID"
+// @Param request body dto.CreateMeterVersionRequest true "Meter definition"
+// @Success 201 {object} dto.MeterVersionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /meters/{id}/versions [post]
+func (h *MeterVersionHandler) CreateVersion(c *gin.Context) {
+	var req dto.CreateMeterVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request payload", err)
+		return
+	}
+
+	resp, err := h.versionService.CreateVersion(c.Request.Context(), c.Param("id"), req)
+	if h.handleVersionError(c, err, "failed to create meter version") {
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListVersions godoc
+// @Summary List meter versions
+// @Description List the definitions of a meter over time, by version number
+// @Tags meters
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Meter ID"
+// @Success 200 {array} dto.MeterVersionResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /meters/{id}/versions [get]
+func (h *MeterVersionHandler) ListVersions(c *gin.Context) {
+	resp, err := h.versionService.ListVersions(c.Request.Context(), c.Param("id"))
+	if h.handleVersionError(c, err, "failed to list meter versions") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetVersion godoc
+// @Summary Get a meter version
+// @Description Get a version of a meter. The version of a re-aggregation reports the recomputation of the rolled up usage of its period
+// @Tags meters
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Meter ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} dto.MeterVersionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /meters/{id}/versions/{version} [get]
+func (h *MeterVersionHandler) GetVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid version", err)
+		return
+	}
+
+	resp, err := h.versionService.GetVersion(c.Request.Context(), c.Param("id"), version)
+	if h.handleVersionError(c, err, "failed to get meter version") {
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Reaggregate godoc
+// @Summary Re-aggregate the usage of a meter
+// @Description Recompute the usage of a past period with the definition of a version of the meter, the current one by default. A version bounded to the period is created and usage in it is read with that definition right away; its rolled up usage is recomputed in the background and the returned version tracks the progress in reaggregation_status. Invoices already issued for the period are not changed. The re-aggregation is recorded in the audit log
+// @Tags meters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Meter ID"
+// @Param request body dto.ReaggregateMeterRequest true "Re-aggregation request"
+// @Success 202 {object} dto.MeterVersionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /meters/{id}/reaggregate [post]
+func (h *MeterVersionHandler) Reaggregate(c *gin.Context) {
+	var req dto.ReaggregateMeterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request payload", err)
+		return
+	}
+
+	resp, err := h.versionService.Reaggregate(c.Request.Context(), c.Param("id"), req)
+	if h.handleVersionError(c, err, "failed to re-aggregate meter") {
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// handleVersionError writes the response of a failed meter version request and
+// reports whether there was one
+func (h *MeterVersionHandler) handleVersionError(c *gin.Context, err error, message string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrInvalidMeterVersion):
+		NewErrorResponse(c, http.StatusBadRequest, "invalid meter version", err)
+	case errors.Is(err, service.ErrMeterVersionNotFound):
+		NewErrorResponse(c, http.StatusNotFound, "meter version not found", err)
+	default:
+		h.logger.Errorw(message, "meter_id", c.Param("id"), "error", err)
+		NewErrorResponse(c, http.StatusInternalServerError, message, err)
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/gateway"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// maxGatewayWebhookSize bounds the payload of the webhooks of the payment gateways
+const maxGatewayWebhookSize = 1 << 20
+
+type PaymentHandler struct {
+	paymentService service.PaymentService
+	logger         *logger.Logger
+}
+
+func NewPaymentHandler(paymentService service.PaymentService, logger *logger.Logger) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+		logger:         logger,
+	}
+}
+
+// CollectInvoicePayment godoc
+// @Summary Collect the payment of an invoice
+// @Description Charge a finalized invoice to a payment method of the customer at the payment gateway of a connection, Stripe, Adyen or Razorpay. The amount, the amount remaining by default, is authorized then captured and recorded as a payment of the invoice. A declined payment is sent as an invoice.payment_failed event
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param request body dto.CollectInvoicePaymentRequest true "Collection"
+// @Success 201 {object} dto.InvoicePaymentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 402 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/payments/collect [post]
+func (h *PaymentHandler) CollectInvoicePayment(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.CollectInvoicePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.paymentService.CollectInvoicePayment(c.Request.Context(), id, req)
+	if errors.Is(err, service.ErrPaymentDeclined) {
+		NewErrorResponse(c, http.StatusPaymentRequired, "payment declined", err)
+		return
+	}
+	if errors.Is(err, service.ErrPaymentMethodNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "payment method not found", err)
+		return
+	}
+	if errors.Is(err, service.ErrInvoiceDisputed) {
+		NewErrorResponse(c, http.StatusConflict, "invoice is disputed", err)
+		return
+	}
+	if errors.Is(err, service.ErrInvoiceWrittenOff) || errors.Is(err, service.ErrCollectionsPaused) {
+		NewErrorResponse(c, http.StatusConflict, "invoice is not collectible", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to collect payment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ScheduleDirectDebit godoc
+// @Summary Schedule a bank debit of an invoice
+// @Description Schedule a SEPA or ACH debit of a finalized invoice under an active mandate of the customer, the one on its default payment method by default. The customer is emailed the amount and the charge date, which is the pre-notification days of the mandate away. The amount is processing on the invoice until the debit clears, a failed debit is sent as an invoice.payment_failed event with its dunning action
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param request body dto.ScheduleDirectDebitRequest true "Debit"
+// @Success 201 {object} dto.InvoicePaymentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invoices/{id}/payments/debit [post]
+func (h *PaymentHandler) ScheduleDirectDebit(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.ScheduleDirectDebitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.paymentService.ScheduleDirectDebit(c.Request.Context(), id, req)
+	if errors.Is(err, service.ErrMandateNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "mandate not found", err)
+		return
+	}
+	if errors.Is(err, service.ErrInvoiceDisputed) {
+		NewErrorResponse(c, http.StatusConflict, "invoice is disputed", err)
+		return
+	}
+	if errors.Is(err, service.ErrInvoiceWrittenOff) || errors.Is(err, service.ErrCollectionsPaused) {
+		NewErrorResponse(c, http.StatusConflict, "invoice is not collectible", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to schedule debit", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// HandleGatewayWebhook godoc
+// @Summary Receive a webhook of a payment gateway
+// @Description Endpoint to register at Stripe, Adyen or Razorpay for the webhooks of a connection. The signature is verified with the webhook secret of the connection, refunds pending at the gateway and bank debits processing are settled with the outcome notified
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param tenant_id path string true "Tenant ID"
+// @Param connection_id path string true "Connection ID"
+// @Success 200
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /gateways/{tenant_id}/{connection_id}/webhooks [post]
+func (h *PaymentHandler) HandleGatewayWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxGatewayWebhookSize))
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "failed to read webhook", err)
+		return
+	}
+
+	// The webhook is not authenticated, the tenant is the one of the URL
+	// registered at the gateway and the signature of the connection proves it
+	ctx := context.WithValue(c.Request.Context(), types.CtxTenantID, c.Param("tenant_id"))
+
+	err = h.paymentService.HandleGatewayWebhook(ctx, c.Param("connection_id"), payload, c.Request.Header)
+	if errors.Is(err, gateway.ErrInvalidSignature) {
+		NewErrorResponse(c, http.StatusUnauthorized, "invalid signature", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to handle webhook", err)
+		return
+	}
+
+	// Adyen only acknowledges notifications answered with [accepted]
+	c.String(http.StatusOK, "[accepted]")
+}
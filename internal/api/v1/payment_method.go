@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentMethodHandler struct {
+	paymentMethodService service.PaymentMethodService
+	logger               *logger.Logger
+}
+
+func NewPaymentMethodHandler(paymentMethodService service.PaymentMethodService, logger *logger.Logger) *PaymentMethodHandler {
+	return &PaymentMethodHandler{
+		paymentMethodService: paymentMethodService,
+		logger:               logger,
+	}
+}
+
+// ListPaymentMethods godoc
+// @Summary List the payment methods of a customer
+// @Description List the payment methods attached to the customer at the payment gateway, the one the customer is charged to is marked as default
+// @Tags customers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} dto.ListPaymentMethodsResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/payment-methods [get]
+func (h *PaymentMethodHandler) ListPaymentMethods(c *gin.Context) {
+	resp, err := h.paymentMethodService.ListPaymentMethods(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list payment methods", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AttachPaymentMethod godoc
+// @Summary Attach a payment method to a customer
+// @Description Attach a payment method collected at the payment gateway, ex with Stripe.js, to the customer at the gateway. The customer must have a stripe_customer_id in its metadata. The first payment method of a customer becomes its default
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param request body dto.AttachPaymentMethodRequest true "Payment method to attach"
+// @Success 201 {object} dto.PaymentMethodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/payment-methods [post]
+func (h *PaymentMethodHandler) AttachPaymentMethod(c *gin.Context) {
+	var req dto.AttachPaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.paymentMethodService.AttachPaymentMethod(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to attach payment method", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// DetachPaymentMethod godoc
+// @Summary Detach a payment method from a customer
+// @Description Detach the payment method at the payment gateway, it can no longer be charged. Payment methods charged for active subscriptions can not be detached
+// @Tags customers
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param pm_id path string true "Payment method ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/payment-methods/{pm_id} [delete]
+func (h *PaymentMethodHandler) DetachPaymentMethod(c *gin.Context) {
+	err := h.paymentMethodService.DetachPaymentMethod(c.Request.Context(), c.Param("id"), c.Param("pm_id"))
+	if errors.Is(err, service.ErrPaymentMethodNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "payment method not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to detach payment method", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetDefaultPaymentMethod godoc
+// @Summary Set the default payment method of a customer
+// @Description Make the payment method the one the customer is charged to, at the payment gateway and in the payment_method_id of the customer
+// @Tags customers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param pm_id path string true "Payment method ID"
+// @Success 200 {object} dto.PaymentMethodResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/payment-methods/{pm_id}/default [post]
+func (h *PaymentMethodHandler) SetDefaultPaymentMethod(c *gin.Context) {
+	resp, err := h.paymentMethodService.SetDefaultPaymentMethod(c.Request.Context(), c.Param("id"), c.Param("pm_id"))
+	if errors.Is(err, service.ErrPaymentMethodNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "payment method not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to set default payment method", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
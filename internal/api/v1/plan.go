@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
@@ -66,6 +67,7 @@ func (h *PlanHandler) GetPlan(c *gin.Context) {
 		return
 	}
 
+	setETag(c, resp.Version)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -103,9 +105,11 @@ func (h *PlanHandler) GetPlans(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Plan ID"
+// @Param If-Match header string false "ETag of the plan the update is made against"
 // @Param plan body dto.UpdatePlanRequest true "Plan configuration"
 // @Success 200 {object} dto.PlanResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /plans/{id} [put]
 func (h *PlanHandler) UpdatePlan(c *gin.Context) {
@@ -118,11 +122,15 @@ func (h *PlanHandler) UpdatePlan(c *gin.Context) {
 	}
 
 	resp, err := h.service.UpdatePlan(c.Request.Context(), id, req)
+	if handleVersionConflict(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	setETag(c, resp.Version)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -148,3 +156,27 @@ func (h *PlanHandler) DeletePlan(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "price deleted successfully"})
 }
+
+// @Summary Restore a plan
+// @Description Restore a deleted plan. Deleted plans can be restored until they are purged
+// @Tags plans
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Plan ID"
+// @Success 200 {object} dto.PlanResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /plans/{id}/restore [post]
+func (h *PlanHandler) RestorePlan(c *gin.Context) {
+	resp, err := h.service.RestorePlan(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, service.ErrNotDeleted) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to restore plan", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
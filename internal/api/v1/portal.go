@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type PortalHandler struct {
+	portalService service.PortalService
+	logger        *logger.Logger
+}
+
+func NewPortalHandler(portalService service.PortalService, logger *logger.Logger) *PortalHandler {
+	return &PortalHandler{
+		portalService: portalService,
+		logger:        logger,
+	}
+}
+
+// CreatePortalSession godoc
+// @Summary Create a portal session
+// @Description Create a short lived session token scoped to a single customer, for use with the /portal endpoints from the customer's browser
+// @Tags Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreatePortalSessionRequest true "Create portal session request"
+// @Success 201 {object} dto.PortalSessionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portal/sessions [post]
+func (h *PortalHandler) CreatePortalSession(c *gin.Context) {
+	var req dto.CreatePortalSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.portalService.CreateSession(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create portal session", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetPortalCustomer godoc
+// @Summary Get the portal customer
+// @Description Get the customer the portal session is scoped to
+// @Tags Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.CustomerResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portal/customer [get]
+func (h *PortalHandler) GetPortalCustomer(c *gin.Context) {
+	resp, err := h.portalService.GetCustomer(c.Request.Context())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get customer", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetPortalUsage godoc
+// @Summary Get the portal customer usage
+// @Description Get the current period usage of the active subscriptions of the portal customer
+// @Tags Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PortalUsageResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portal/usage [get]
+func (h *PortalHandler) GetPortalUsage(c *gin.Context) {
+	resp, err := h.portalService.GetUsage(c.Request.Context())
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get usage", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListPortalInvoices godoc
+// @Summary List the portal customer invoices
+// @Description List the finalized invoices of the portal customer
+// @Tags Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.InvoiceFilter false "Filter"
+// @Success 200 {object} dto.ListInvoicesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portal/invoices [get]
+func (h *PortalHandler) ListPortalInvoices(c *gin.Context) {
+	var filter types.InvoiceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.portalService.ListInvoices(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list invoices", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetPortalInvoice godoc
+// @Summary Get a portal customer invoice
+// @Description Get a finalized invoice of the portal customer
+// @Tags Portal
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} dto.InvoiceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portal/invoices/{id} [get]
+func (h *PortalHandler) GetPortalInvoice(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.portalService.GetInvoice(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get invoice", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
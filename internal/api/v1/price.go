@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
@@ -159,3 +160,27 @@ func (h *PriceHandler) DeletePrice(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "price deleted successfully"})
 }
+
+// @Summary Restore a price
+// @Description Restore a deleted price. Deleted prices can be restored until they are purged
+// @Tags prices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Price ID"
+// @Success 200 {object} dto.PriceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /prices/{id}/restore [post]
+func (h *PriceHandler) RestorePrice(c *gin.Context) {
+	resp, err := h.service.RestorePrice(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, service.ErrNotDeleted) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to restore price", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
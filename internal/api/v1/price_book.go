@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PriceBookHandler struct {
+	priceBookService service.PriceBookService
+	logger           *logger.Logger
+}
+
+func NewPriceBookHandler(priceBookService service.PriceBookService, logger *logger.Logger) *PriceBookHandler {
+	return &PriceBookHandler{
+		priceBookService: priceBookService,
+		logger:           logger,
+	}
+}
+
+// CreatePriceBook godoc
+// @Summary Create a price book
+// @Description Create a price book overriding the prices of a plan. New subscriptions of the plan are put on it when they name it, or when their customer is in its segment, and keep its prices for their lifetime
+// @Tags Price Books
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreatePriceBookRequest true "Create price book request"
+// @Success 201 {object} dto.PriceBookResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /price-books [post]
+func (h *PriceBookHandler) CreatePriceBook(c *gin.Context) {
+	var req dto.CreatePriceBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.priceBookService.CreatePriceBook(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create price book", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetPriceBook godoc
+// @Summary Get a price book
+// @Description Get a price book by id, including deleted price books
+// @Tags Price Books
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Price book ID"
+// @Success 200 {object} dto.PriceBookResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /price-books/{id} [get]
+func (h *PriceBookHandler) GetPriceBook(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.priceBookService.GetPriceBook(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get price book", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListPriceBooks godoc
+// @Summary List price books
+// @Description List the price books of a plan new subscriptions can be put on
+// @Tags Price Books
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request query dto.ListPriceBooksRequest true "Filter"
+// @Success 200 {object} dto.ListPriceBooksResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /price-books [get]
+func (h *PriceBookHandler) ListPriceBooks(c *gin.Context) {
+	var req dto.ListPriceBooksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.priceBookService.ListPriceBooks(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list price books", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetPriceBookReport godoc
+// @Summary Compare the price books of a plan
+// @Description Compare the trial conversion and revenue of the subscriptions of a plan created in the window, by the price book they were created on. Defaults to the last 30 days
+// @Tags Price Books
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request query dto.PriceBookReportRequest true "Filter"
+// @Success 200 {object} dto.PriceBookReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /price-books/report [get]
+func (h *PriceBookHandler) GetPriceBookReport(c *gin.Context) {
+	var req dto.PriceBookReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.priceBookService.GetPriceBookReport(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get price book report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeletePriceBook godoc
+// @Summary Delete a price book
+// @Description Stop putting new subscriptions on a price book. The subscriptions created on it keep its prices
+// @Tags Price Books
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Price book ID"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /price-books/{id} [delete]
+func (h *PriceBookHandler) DeletePriceBook(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	if err := h.priceBookService.DeletePriceBook(c.Request.Context(), id); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete price book", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "price book deleted successfully"})
+}
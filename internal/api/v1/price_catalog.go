@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PriceCatalogHandler struct {
+	priceCatalogService service.PriceCatalogService
+	logger              *logger.Logger
+}
+
+func NewPriceCatalogHandler(priceCatalogService service.PriceCatalogService, logger *logger.Logger) *PriceCatalogHandler {
+	return &PriceCatalogHandler{
+		priceCatalogService: priceCatalogService,
+		logger:              logger,
+	}
+}
+
+// ExportPlanPrices godoc
+// @Summary Export the prices of a plan
+// @Description Export all the prices of a plan as JSON or as a CSV file with one line per tier, in the format accepted by the import
+// @Tags plans
+// @Produce json,text/csv
+// @Security BearerAuth
+// @Param id path string true "Plan ID"
+// @Param format query string false "Export format" Enums(json, csv) default(json)
+// @Success 200 {object} dto.ExportPlanPricesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /plans/{id}/prices/export [get]
+func (h *PriceCatalogHandler) ExportPlanPrices(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "csv" {
+		NewErrorResponse(c, http.StatusBadRequest, "format must be json or csv", nil)
+		return
+	}
+
+	resp, err := h.priceCatalogService.ExportPlanPrices(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to export prices", err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := dto.WritePlanPricesCSV(&buf, resp.Prices); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to export prices", err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\"plan_"+id+"_prices.csv\"")
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
+
+// ImportPlanPrices godoc
+// @Summary Import the prices of a plan
+// @Description Create, update and archive the prices of a plan at once from a JSON body or a text/csv body in the export format. Rows match prices by id or lookup key, and prices whose amount or billing terms change are archived and replaced. Nothing is applied on a dry run or when any row is invalid
+// @Tags plans
+// @Accept json,text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Plan ID"
+// @Param request body dto.ImportPlanPricesRequest true "Import request, for JSON bodies"
+// @Param dry_run query bool false "Only report the changes, for CSV bodies"
+// @Param archive_missing query bool false "Archive the prices missing from the import, for CSV bodies"
+// @Success 200 {object} dto.ImportPlanPricesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /plans/{id}/prices/import [post]
+func (h *PriceCatalogHandler) ImportPlanPrices(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.ImportPlanPricesRequest
+	if c.ContentType() == "text/csv" {
+		if err := c.ShouldBindQuery(&req); err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+			return
+		}
+
+		prices, err := dto.ParsePlanPricesCSV(c.Request.Body)
+		if err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid csv file", err)
+			return
+		}
+		req.Prices = prices
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.priceCatalogService.ImportPlanPrices(c.Request.Context(), id, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to import prices", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
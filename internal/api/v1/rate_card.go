@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type RateCardHandler struct {
+	rateCardService service.RateCardService
+	logger          *logger.Logger
+}
+
+func NewRateCardHandler(rateCardService service.RateCardService, logger *logger.Logger) *RateCardHandler {
+	return &RateCardHandler{
+		rateCardService: rateCardService,
+		logger:          logger,
+	}
+}
+
+// CreateRateCard godoc
+// @Summary Create a rate card version
+// @Description Create the next version of the negotiated rate card of a customer, or of one of its subscriptions. Its price overrides apply to the billing periods starting from effective_from, subscription rate cards take precedence over customer rate cards
+// @Tags Rate Cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateRateCardRequest true "Create rate card request"
+// @Success 201 {object} dto.RateCardResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /rate-cards [post]
+func (h *RateCardHandler) CreateRateCard(c *gin.Context) {
+	var req dto.CreateRateCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.rateCardService.CreateRateCard(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create rate card", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetRateCard godoc
+// @Summary Get a rate card
+// @Description Get a rate card version by id
+// @Tags Rate Cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rate card ID"
+// @Success 200 {object} dto.RateCardResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /rate-cards/{id} [get]
+func (h *RateCardHandler) GetRateCard(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.rateCardService.GetRateCard(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get rate card", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListRateCards godoc
+// @Summary List rate cards
+// @Description List all the versions of the rate cards of a customer and its subscriptions
+// @Tags Rate Cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request query dto.ListRateCardsRequest true "Filter"
+// @Success 200 {object} dto.ListRateCardsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /rate-cards [get]
+func (h *RateCardHandler) ListRateCards(c *gin.Context) {
+	var req dto.ListRateCardsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.rateCardService.ListRateCards(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list rate cards", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetCustomerRates godoc
+// @Summary Compare the rates of a customer with list prices
+// @Description Compare the prices the ongoing subscriptions of a customer are billed at, after their price book and rate card, with the list prices of their plans at a point in time, now by default
+// @Tags Rate Cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request query dto.CustomerRatesRequest true "Filter"
+// @Success 200 {object} dto.CustomerRatesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /rate-cards/rates [get]
+func (h *RateCardHandler) GetCustomerRates(c *gin.Context) {
+	var req dto.CustomerRatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.rateCardService.GetCustomerRates(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get customer rates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteRateCard godoc
+// @Summary Delete a rate card
+// @Description Delete a rate card version. The previous version applies again from the next invoice, issued invoices are unchanged
+// @Tags Rate Cards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rate card ID"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /rate-cards/{id} [delete]
+func (h *RateCardHandler) DeleteRateCard(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	if err := h.rateCardService.DeleteRateCard(c.Request.Context(), id); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete rate card", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "rate card deleted successfully"})
+}
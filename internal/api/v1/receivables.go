@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type ReceivablesHandler struct {
+	receivablesService service.ReceivablesService
+	logger             *logger.Logger
+}
+
+func NewReceivablesHandler(receivablesService service.ReceivablesService, logger *logger.Logger) *ReceivablesHandler {
+	return &ReceivablesHandler{
+		receivablesService: receivablesService,
+		logger:             logger,
+	}
+}
+
+// GetReceivablesReport godoc
+// @Summary Get accounts receivable report
+// @Description Report the amounts remaining on the finalized invoices by currency and by customer, in aging buckets of current, 30, 60 and 90+ days since the invoices were finalized. Today's report is computed on request, the report of a past date (YYYY-MM-DD) is served from the daily snapshot
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.ReceivablesFilter false "Filter"
+// @Success 200 {object} dto.ReceivablesReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/receivables [get]
+func (h *ReceivablesHandler) GetReceivablesReport(c *gin.Context) {
+	var filter types.ReceivablesFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.receivablesService.GetReceivablesReport(c.Request.Context(), &filter, time.Now().UTC())
+	if errors.Is(err, service.ErrInvalidReceivablesDate) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if errors.Is(err, service.ErrReceivablesSnapshotNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "receivables snapshot not found", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get receivables report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWriteOffReport godoc
+// @Summary Get write-off report
+// @Description Report the invoices written off as uncollectible in a period and the amounts written off by currency. Written off invoices are left out of the receivables report
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.WriteOffFilter false "Filter"
+// @Success 200 {object} dto.WriteOffReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/write-offs [get]
+func (h *ReceivablesHandler) GetWriteOffReport(c *gin.Context) {
+	var filter types.WriteOffFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.receivablesService.GetWriteOffReport(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get write-off report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type RefundHandler struct {
+	refundService service.RefundService
+	logger        *logger.Logger
+}
+
+func NewRefundHandler(refundService service.RefundService, logger *logger.Logger) *RefundHandler {
+	return &RefundHandler{
+		refundService: refundService,
+		logger:        logger,
+	}
+}
+
+// CreateRefund godoc
+// @Summary Refund a payment
+// @Description Refund part or all of an invoice payment. Payments collected at a gateway are refunded there, a failed gateway refund is kept and sent as a refund.failed event. With create_credit_note a credit note is issued for the refunded amount against the invoice, otherwise the amount is owed again on the invoice
+// @Tags Refunds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Param request body dto.CreateRefundRequest true "Refund"
+// @Success 201 {object} dto.RefundResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /payments/{id}/refunds [post]
+func (h *RefundHandler) CreateRefund(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.refundService.CreateRefund(c.Request.Context(), id, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to refund payment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListRefunds godoc
+// @Summary List the refunds of a payment
+// @Description List the refunds of an invoice payment, oldest first, along with the amount left to refund
+// @Tags Refunds
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} dto.ListRefundsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /payments/{id}/refunds [get]
+func (h *RefundHandler) ListRefunds(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.refundService.ListRefunds(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list refunds", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,292 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	reportService service.ReportService
+	logger        *logger.Logger
+}
+
+func NewReportHandler(reportService service.ReportService, logger *logger.Logger) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+		logger:        logger,
+	}
+}
+
+// CreateTemplate godoc
+// @Summary Create a report template
+// @Description Define a report on customers, subscriptions, invoices or invoice line items: its columns, filters and group-bys, the period of each run, its daily, weekly or monthly schedule and where its CSV is delivered (email, s3 or webhook)
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ReportTemplateRequest true "Report template"
+// @Success 201 {object} dto.ReportTemplateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/templates [post]
+func (h *ReportHandler) CreateTemplate(c *gin.Context) {
+	var req dto.ReportTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.reportService.CreateTemplate(c.Request.Context(), req)
+	if errors.Is(err, service.ErrInvalidReportTemplate) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create report template", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListTemplates godoc
+// @Summary List report templates
+// @Description List the report templates of the tenant
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.Filter false "Filter"
+// @Success 200 {object} dto.ListReportTemplatesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/templates [get]
+func (h *ReportHandler) ListTemplates(c *gin.Context) {
+	var filter types.Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.reportService.ListTemplates(c.Request.Context(), filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list report templates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetTemplate godoc
+// @Summary Get a report template
+// @Description Get a report template by ID
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report template ID"
+// @Success 200 {object} dto.ReportTemplateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/templates/{id} [get]
+func (h *ReportHandler) GetTemplate(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.reportService.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get report template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateTemplate godoc
+// @Summary Update a report template
+// @Description Replace the definition of a report template. Its next run is rescheduled
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report template ID"
+// @Param request body dto.ReportTemplateRequest true "Report template"
+// @Success 200 {object} dto.ReportTemplateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/templates/{id} [put]
+func (h *ReportHandler) UpdateTemplate(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.ReportTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.reportService.UpdateTemplate(c.Request.Context(), id, req)
+	if errors.Is(err, service.ErrInvalidReportTemplate) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to update report template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a report template
+// @Description Delete a report template, which stops its schedule. Its runs are kept
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report template ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/templates/{id} [delete]
+func (h *ReportHandler) DeleteTemplate(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	if err := h.reportService.DeleteTemplate(c.Request.Context(), id); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete report template", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunTemplate godoc
+// @Summary Run a report template
+// @Description Create a pending run of a report template, run by the report job. Poll the run for its status, its CSV is delivered to the destinations of the template once completed
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report template ID"
+// @Success 202 {object} dto.ReportRunResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/templates/{id}/run [post]
+func (h *ReportHandler) RunTemplate(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.reportService.RunTemplate(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to run report template", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// ListRuns godoc
+// @Summary List report runs
+// @Description List the runs of a report template, most recent first
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report template ID"
+// @Param filter query types.ReportRunFilter false "Filter"
+// @Success 200 {object} dto.ListReportRunsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/templates/{id}/runs [get]
+func (h *ReportHandler) ListRuns(c *gin.Context) {
+	var filter types.ReportRunFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+	filter.TemplateID = c.Param("id")
+
+	resp, err := h.reportService.ListRuns(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list report runs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetRun godoc
+// @Summary Get a report run
+// @Description Get a report run by ID to poll its status
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report run ID"
+// @Success 200 {object} dto.ReportRunResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/runs/{id} [get]
+func (h *ReportHandler) GetRun(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.reportService.GetRun(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get report run", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetRunDownloadURL godoc
+// @Summary Get report run download URL
+// @Description Get a signed, time limited URL to download the CSV of a completed report run
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report run ID"
+// @Success 200 {object} dto.ExportDownloadResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/runs/{id}/download [get]
+func (h *ReportHandler) GetRunDownloadURL(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.reportService.GetRunDownloadURL(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get report run download url", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
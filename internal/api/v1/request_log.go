@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type RequestLogHandler struct {
+	requestLogService service.RequestLogService
+	logger            *logger.Logger
+}
+
+func NewRequestLogHandler(requestLogService service.RequestLogService, logger *logger.Logger) *RequestLogHandler {
+	return &RequestLogHandler{
+		requestLogService: requestLogService,
+		logger:            logger,
+	}
+}
+
+// ListRequestLogs godoc
+// @Summary List API request logs
+// @Description List the recent requests made with API keys, most recent first, with their redacted and truncated bodies. Logs are kept for 7 days. Filter on the route template with endpoint ex /v1/customers/:id
+// @Tags Developer
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.RequestLogFilter false "Filter"
+// @Success 200 {object} dto.ListRequestLogsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /developer/logs [get]
+func (h *RequestLogHandler) ListRequestLogs(c *gin.Context) {
+	var filter types.RequestLogFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.requestLogService.ListRequestLogs(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list request logs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
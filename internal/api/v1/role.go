@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService service.RoleService
+	logger      *logger.Logger
+}
+
+func NewRoleHandler(roleService service.RoleService, logger *logger.Logger) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		logger:      logger,
+	}
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the roles of the dashboard users with the permissions they grant
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ListRolesResponse
+// @Router /roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, h.roleService.ListRoles(c.Request.Context()))
+}
+
+// ListRoleAssignments godoc
+// @Summary List role assignments
+// @Description List the roles assigned to the users of the tenant, tenant wide and per environment
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.RoleAssignmentFilter false "Filter"
+// @Success 200 {object} dto.ListRoleAssignmentsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /role-assignments [get]
+func (h *RoleHandler) ListRoleAssignments(c *gin.Context) {
+	var filter types.RoleAssignmentFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.roleService.ListRoleAssignments(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list role assignments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AssignRole godoc
+// @Summary Assign a role
+// @Description Set the role of a user for an environment, or for all the environments without a role of their own when no environment is given. It replaces the role the user had in the same scope
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.AssignRoleRequest true "Role assignment"
+// @Success 200 {object} dto.RoleAssignmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /role-assignments [post]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.roleService.AssignRole(c.Request.Context(), req)
+	if errors.Is(err, service.ErrLastAdmin) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to assign role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteRoleAssignment godoc
+// @Summary Delete a role assignment
+// @Description Remove a role from a user. The last tenant wide admin can not be removed
+// @Tags Roles
+// @Security BearerAuth
+// @Param id path string true "Role assignment ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /role-assignments/{id} [delete]
+func (h *RoleHandler) DeleteRoleAssignment(c *gin.Context) {
+	err := h.roleService.DeleteRoleAssignment(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, service.ErrRoleAssignmentNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "role assignment not found", err)
+		return
+	}
+	if errors.Is(err, service.ErrLastAdmin) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete role assignment", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
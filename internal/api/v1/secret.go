@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type SecretHandler struct {
+	secretService service.SecretService
+	logger        *logger.Logger
+}
+
+func NewSecretHandler(secretService service.SecretService, logger *logger.Logger) *SecretHandler {
+	return &SecretHandler{
+		secretService: secretService,
+		logger:        logger,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create a scoped API key. The raw key is only returned in this response
+// @Tags Secrets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateAPIKeyRequest true "Create API key request"
+// @Success 201 {object} dto.CreateAPIKeyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /secrets/api-keys [post]
+func (h *SecretHandler) CreateAPIKey(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.secretService.CreateAPIKey(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create api key", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List the API keys of the tenant
+// @Tags Secrets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.Filter false "Filter"
+// @Success 200 {object} dto.ListAPIKeysResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /secrets/api-keys [get]
+func (h *SecretHandler) ListAPIKeys(c *gin.Context) {
+	var filter types.Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.secretService.ListAPIKeys(c.Request.Context(), filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list api keys", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteAPIKey godoc
+// @Summary Delete an API key
+// @Description Revoke an API key
+// @Tags Secrets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /secrets/api-keys/{id} [delete]
+func (h *SecretHandler) DeleteAPIKey(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	if err := h.secretService.DeleteAPIKey(c.Request.Context(), id); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete api key", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type SimulationHandler struct {
+	simulationService service.SimulationService
+	logger            *logger.Logger
+}
+
+func NewSimulationHandler(simulationService service.SimulationService, logger *logger.Logger) *SimulationHandler {
+	return &SimulationHandler{
+		simulationService: simulationService,
+		logger:            logger,
+	}
+}
+
+// GetClock godoc
+// @Summary Get billing clock
+// @Description Get the time of the billing flows and whether it is simulated
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Success 200 {object} dto.SimulationClockResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/clock [get]
+func (h *SimulationHandler) GetClock(c *gin.Context) {
+	c.JSON(http.StatusOK, h.simulationService.GetClock(c.Request.Context()))
+}
+
+// AdvanceClock godoc
+// @Summary Advance simulated clock
+// @Description Move the simulated clock of a sandbox environment forward and run the trial, threshold, renewal, credit expiry and auto top-up jobs at the new time. Only available with simulation enabled, for the sandbox environment of the clock
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Admin key"
+// @Param request body dto.AdvanceClockRequest true "Time to move the clock to"
+// @Success 200 {object} dto.SimulationClockResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/clock/advance [post]
+func (h *SimulationHandler) AdvanceClock(c *gin.Context) {
+	var req dto.AdvanceClockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.simulationService.AdvanceClock(c.Request.Context(), &req)
+	if errors.Is(err, clock.ErrNotSimulated) {
+		NewErrorResponse(c, http.StatusForbidden, "simulation is not enabled", err)
+		return
+	}
+	if errors.Is(err, clock.ErrNotSandbox) {
+		NewErrorResponse(c, http.StatusForbidden, "simulation is only available in sandbox environments", err)
+		return
+	}
+	if errors.Is(err, clock.ErrClockBackwards) {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to advance clock", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
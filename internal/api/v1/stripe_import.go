@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type StripeImportHandler struct {
+	stripeImportService service.StripeImportService
+	logger              *logger.Logger
+}
+
+func NewStripeImportHandler(stripeImportService service.StripeImportService, logger *logger.Logger) *StripeImportHandler {
+	return &StripeImportHandler{
+		stripeImportService: stripeImportService,
+		logger:              logger,
+	}
+}
+
+// Import godoc
+// @Summary Import from Stripe
+// @Description Import the products, prices, customers and subscriptions of the Stripe account of a connection. Objects imported before are reported as existing, so the import can be run again. Use dry_run to preview the import
+// @Tags Connections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Connection ID"
+// @Param request body dto.ImportStripeRequest true "Import request"
+// @Success 200 {object} dto.ImportStripeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /connections/{id}/stripe-import [post]
+func (h *StripeImportHandler) Import(c *gin.Context) {
+	var req dto.ImportStripeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	resp, err := h.stripeImportService.Import(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to import from stripe", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
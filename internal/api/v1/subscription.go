@@ -1,12 +1,14 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
 	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/service"
 	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,6 +30,7 @@ func NewSubscriptionHandler(service service.SubscriptionService, log *logger.Log
 // @Param subscription body dto.CreateSubscriptionRequest true "Subscription Request"
 // @Success 201 {object} dto.SubscriptionResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Denied by a pre-operation hook"
 // @Failure 500 {object} ErrorResponse
 // @Router /subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
@@ -38,6 +41,10 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	}
 
 	resp, err := h.service.CreateSubscription(c.Request.Context(), req)
+	if errors.Is(err, webhook.ErrOperationDenied) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -52,18 +59,26 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Subscription ID"
+// @Param expand query string false "Comma separated records to embed: plan, prices, customer. Defaults to plan,prices"
 // @Success 200 {object} dto.SubscriptionResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /subscriptions/{id} [get]
 func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 	id := c.Param("id")
-	resp, err := h.service.GetSubscription(c.Request.Context(), id)
+	expand, err := types.ParseExpand(c.Query("expand"), types.DefaultSubscriptionExpand)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.GetSubscriptionWithExpand(c.Request.Context(), id, expand)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	setETag(c, resp.Version)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -77,6 +92,9 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 // @Param plan_id query string false "Filter by plan ID"
 // @Param offset query int false "Offset for pagination"
 // @Param limit query int false "Limit for pagination"
+// @Param pagination query string false "Pagination mode, offset (default) or cursor"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param expand query string false "Comma separated records to embed in each subscription: plan, prices, customer. Defaults to plan"
 // @Success 200 {object} dto.ListSubscriptionsResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -87,6 +105,14 @@ func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := filter.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := types.ParseExpand(filter.Expand, nil); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	resp, err := h.service.ListSubscriptions(c.Request.Context(), &filter)
 	if err != nil {
@@ -99,22 +125,45 @@ func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
 }
 
 // @Summary Cancel subscription
-// @Description Cancel a subscription
+// @Description Cancel a subscription. The reason must be one of the tenant's cancellation reasons
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Subscription ID"
-// @Param cancel_at_period_end query bool false "Cancel at period end"
+// @Param If-Match header string false "ETag of the subscription the cancellation is made against"
+// @Param request body dto.CancelSubscriptionRequest true "Cancel subscription request"
 // @Success 200 {object} gin.H
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "Denied by a pre-operation hook"
+// @Failure 409 {object} VersionConflictResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /subscriptions/{id}/cancel [post]
 func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
 	id := c.Param("id")
-	cancelAtPeriodEnd := c.DefaultQuery("cancel_at_period_end", "false") == "true"
 
-	err := h.service.CancelSubscription(c.Request.Context(), id, cancelAtPeriodEnd)
+	var req dto.CancelSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.service.CancelSubscription(c.Request.Context(), id, req)
+	if errors.Is(err, webhook.ErrOperationDenied) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrUnknownCancellationReason) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if handleVersionConflict(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
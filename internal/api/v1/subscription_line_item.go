@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type SubscriptionLineItemHandler struct {
+	lineItemService service.SubscriptionLineItemService
+	logger          *logger.Logger
+}
+
+func NewSubscriptionLineItemHandler(lineItemService service.SubscriptionLineItemService, logger *logger.Logger) *SubscriptionLineItemHandler {
+	return &SubscriptionLineItemHandler{
+		lineItemService: lineItemService,
+		logger:          logger,
+	}
+}
+
+// UpdateLineItem godoc
+// @Summary Update a subscription line item
+// @Description Change the quantity or the unit amount of a recurring fixed line item. With create_prorations, the default, a change in the middle of a paid period is prorated on the next invoice. Emits subscription.updated
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Subscription ID"
+// @Param li_id path string true "Line item ID"
+// @Param If-Match header string false "ETag of the subscription the change is made against"
+// @Param request body dto.UpdateSubscriptionLineItemRequest true "Line item change"
+// @Success 200 {object} dto.SubscriptionLineItemResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} VersionConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /subscriptions/{id}/line-items/{li_id} [patch]
+func (h *SubscriptionLineItemHandler) UpdateLineItem(c *gin.Context) {
+	var req dto.UpdateSubscriptionLineItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.lineItemService.UpdateLineItem(c.Request.Context(), c.Param("id"), c.Param("li_id"), req)
+	if errors.Is(err, service.ErrSubscriptionLineItemNotFound) {
+		NewErrorResponse(c, http.StatusNotFound, "line item not found", err)
+		return
+	}
+	if handleVersionConflict(c, err) {
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to update line item", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
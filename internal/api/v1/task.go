@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type TaskHandler struct {
+	taskService service.TaskService
+	logger      *logger.Logger
+}
+
+func NewTaskHandler(taskService service.TaskService, logger *logger.Logger) *TaskHandler {
+	return &TaskHandler{
+		taskService: taskService,
+		logger:      logger,
+	}
+}
+
+// CreateTask godoc
+// @Summary Create a task
+// @Description Start an asynchronous task such as a bulk customer import. The CSV or JSON file is either uploaded as the multipart "file" field or downloaded from file_url, e.g. a presigned URL
+// @Tags Tasks
+// @Accept json,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateTaskRequest false "Create task request, for JSON bodies"
+// @Param task_type formData string false "Task type, for multipart uploads"
+// @Param file_format formData string false "File format, guessed from the file name when omitted"
+// @Param file formData file false "File to process"
+// @Success 202 {object} dto.TaskResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tasks [post]
+func (h *TaskHandler) CreateTask(c *gin.Context) {
+	var req dto.CreateTaskRequest
+	var file io.Reader
+
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		if err := c.ShouldBind(&req); err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+			return
+		}
+
+		header, err := c.FormFile("file")
+		if err != nil && err != http.ErrMissingFile {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid file", err)
+			return
+		}
+		if header != nil {
+			f, err := header.Open()
+			if err != nil {
+				NewErrorResponse(c, http.StatusBadRequest, "invalid file", err)
+				return
+			}
+			defer f.Close()
+			file = f
+
+			if req.FileFormat == "" {
+				req.FileFormat = types.TaskFileFormat(strings.ToUpper(strings.TrimPrefix(filepath.Ext(header.Filename), ".")))
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.taskService.CreateTask(c.Request.Context(), req, file)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create task", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// GetTask godoc
+// @Summary Get a task
+// @Description Get a task by ID to poll its status and progress
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} dto.TaskResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tasks/{id} [get]
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.taskService.GetTask(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListTasks godoc
+// @Summary List tasks
+// @Description List tasks with pagination
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.Filter false "Filter"
+// @Success 200 {object} dto.ListTasksResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tasks [get]
+func (h *TaskHandler) ListTasks(c *gin.Context) {
+	var filter types.Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.taskService.ListTasks(c.Request.Context(), filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get tasks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetTaskErrorReportURL godoc
+// @Summary Get task error report URL
+// @Description Get a signed, time limited URL to download the CSV report of the rows which failed
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} dto.TaskErrorReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tasks/{id}/error-report [get]
+func (h *TaskHandler) GetTaskErrorReportURL(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.taskService.GetErrorReportURL(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get error report url", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type TaxHandler struct {
+	taxService service.TaxService
+	logger     *logger.Logger
+}
+
+func NewTaxHandler(taxService service.TaxService, logger *logger.Logger) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+		logger:     logger,
+	}
+}
+
+// ValidateCustomerAddress godoc
+// @Summary Validate the address of a customer
+// @Description Resolve the billing address of the customer, or the address passed, with the Avalara or TaxJar connection of the tenant. With update the resolved address is saved as the billing address of the customer
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Param request body dto.ValidateAddressRequest false "Address validation"
+// @Success 200 {object} dto.ValidateAddressResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /customers/{id}/tax/validate-address [post]
+func (h *TaxHandler) ValidateCustomerAddress(c *gin.Context) {
+	var req dto.ValidateAddressRequest
+	// Without a body the billing address of the customer is validated
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+	}
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.taxService.ValidateCustomerAddress(c.Request.Context(), c.Param("id"), req)
+	if errors.Is(err, service.ErrNoTaxConnection) {
+		NewErrorResponse(c, http.StatusBadRequest, "no avalara or taxjar connection", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to validate address", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
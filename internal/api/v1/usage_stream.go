@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// usageStreamEvent is the name of the server-sent events carrying usage updates
+const usageStreamEvent = "usage"
+
+type UsageStreamHandler struct {
+	usageStreamService service.UsageStreamService
+	keepAlive          time.Duration
+	logger             *logger.Logger
+}
+
+func NewUsageStreamHandler(cfg *config.Configuration, usageStreamService service.UsageStreamService, logger *logger.Logger) *UsageStreamHandler {
+	keepAlive := time.Duration(cfg.UsageStream.KeepAliveSecs) * time.Second
+	if keepAlive <= 0 {
+		keepAlive = 15 * time.Second
+	}
+
+	return &UsageStreamHandler{
+		usageStreamService: usageStreamService,
+		keepAlive:          keepAlive,
+		logger:             logger,
+	}
+}
+
+// StreamCustomerUsage godoc
+// @Summary Stream customer usage
+// @Description Stream the events ingested for the customer as server-sent events named usage, as they are consumed. A keep alive comment is sent periodically
+// @Tags customers
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param id path string true "Customer ID"
+// @Success 200 {object} usagestream.Update
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /customers/{id}/usage/stream [get]
+func (h *UsageStreamHandler) StreamCustomerUsage(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	h.stream(c, id)
+}
+
+// StreamPortalUsage godoc
+// @Summary Stream the portal customer usage
+// @Description Stream the events ingested for the portal customer as server-sent events named usage, as they are consumed. A keep alive comment is sent periodically
+// @Tags Portal
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {object} usagestream.Update
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /portal/usage/stream [get]
+func (h *UsageStreamHandler) StreamPortalUsage(c *gin.Context) {
+	h.stream(c, types.GetCustomerID(c.Request.Context()))
+}
+
+func (h *UsageStreamHandler) stream(c *gin.Context, customerID string) {
+	ctx := c.Request.Context()
+
+	updates, err := h.usageStreamService.StreamCustomerUsage(ctx, customerID)
+	if errors.Is(err, service.ErrUsageStreamDisabled) {
+		NewErrorResponse(c, http.StatusNotImplemented, "usage streaming is not enabled", err)
+		return
+	}
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to stream usage", err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keeps reverse proxies from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(h.keepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent(usageStreamEvent, update)
+			return true
+		case <-keepAlive.C:
+			_, err := fmt.Fprint(w, ": keep-alive\n\n")
+			return err == nil
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
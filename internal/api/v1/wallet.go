@@ -148,7 +148,7 @@ func (h *WalletHandler) GetWalletTransactions(c *gin.Context) {
 
 // TopUpWallet godoc
 // @Summary Top up wallet
-// @Description Add credits to a wallet
+// @Description Add credits to a wallet, expiring at expires_at when set
 // @Tags Wallet
 // @Accept json
 // @Produce json
@@ -182,6 +182,46 @@ func (h *WalletHandler) TopUpWallet(c *gin.Context) {
 	c.JSON(http.StatusOK, wallet)
 }
 
+// DebitWallet godoc
+// @Summary Debit wallet
+// @Description Consume credits of a wallet, drawing from the oldest credits that have not expired first and from the credits that never expire last
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wallet ID"
+// @Param request body dto.DebitWalletRequest true "Debit request"
+// @Success 200 {object} dto.WalletResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /wallets/{id}/debit [post]
+func (h *WalletHandler) DebitWallet(c *gin.Context) {
+	walletID := c.Param("id")
+	if walletID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.DebitWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	wallet, err := h.walletService.DebitWallet(c.Request.Context(), walletID, &req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to debit wallet", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
 // GetWalletBalance godoc
 // @Summary Get wallet balance
 // @Description Get real-time balance of a wallet
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AutoTopUpHandler struct {
+	autoTopUpService service.AutoTopUpService
+	logger           *logger.Logger
+}
+
+func NewAutoTopUpHandler(autoTopUpService service.AutoTopUpService, logger *logger.Logger) *AutoTopUpHandler {
+	return &AutoTopUpHandler{
+		autoTopUpService: autoTopUpService,
+		logger:           logger,
+	}
+}
+
+// SetAutoTopUp godoc
+// @Summary Configure wallet auto top-up
+// @Description Create or replace the rule topping the wallet up by a fixed amount when its balance drops below a threshold. The daily and monthly caps bound the amount topped up, zero meaning no cap
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wallet ID"
+// @Param request body dto.UpdateAutoTopUpRequest true "Auto top-up rule"
+// @Success 200 {object} dto.AutoTopUpResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /wallets/{id}/auto-topup [put]
+func (h *AutoTopUpHandler) SetAutoTopUp(c *gin.Context) {
+	walletID := c.Param("id")
+	if walletID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.UpdateAutoTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.autoTopUpService.SetAutoTopUp(c.Request.Context(), walletID, &req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to set auto top-up", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetAutoTopUp godoc
+// @Summary Get wallet auto top-up
+// @Description Get the auto top-up rule of a wallet
+// @Tags Wallet
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wallet ID"
+// @Success 200 {object} dto.AutoTopUpResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /wallets/{id}/auto-topup [get]
+func (h *AutoTopUpHandler) GetAutoTopUp(c *gin.Context) {
+	walletID := c.Param("id")
+	if walletID == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.autoTopUpService.GetAutoTopUp(c.Request.Context(), walletID)
+	if err != nil {
+		NewErrorResponse(c, http.StatusNotFound, "auto top-up not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
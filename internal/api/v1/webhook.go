@@ -0,0 +1,290 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+	logger         *logger.Logger
+}
+
+func NewWebhookHandler(webhookService service.WebhookService, logger *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		logger:         logger,
+	}
+}
+
+// CreateEndpoint godoc
+// @Summary Create a webhook endpoint
+// @Description Register a URL to receive webhook events in the current environment. The signing secret is only returned here and on rotation
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateWebhookEndpointRequest true "Create webhook endpoint request"
+// @Success 201 {object} dto.WebhookEndpointSecretResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/endpoints [post]
+func (h *WebhookHandler) CreateEndpoint(c *gin.Context) {
+	var req dto.CreateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.webhookService.CreateEndpoint(c.Request.Context(), req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to create webhook endpoint", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListEndpoints godoc
+// @Summary List webhook endpoints
+// @Description List the webhook endpoints of the current environment
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.Filter false "Filter"
+// @Success 200 {object} dto.ListWebhookEndpointsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/endpoints [get]
+func (h *WebhookHandler) ListEndpoints(c *gin.Context) {
+	var filter types.Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.webhookService.ListEndpoints(c.Request.Context(), filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list webhook endpoints", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetEndpoint godoc
+// @Summary Get a webhook endpoint
+// @Description Get a webhook endpoint by ID
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint ID"
+// @Success 200 {object} dto.WebhookEndpointResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/endpoints/{id} [get]
+func (h *WebhookHandler) GetEndpoint(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.webhookService.GetEndpoint(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get webhook endpoint", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateEndpoint godoc
+// @Summary Update a webhook endpoint
+// @Description Replace the URL, description and event types of a webhook endpoint
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint ID"
+// @Param request body dto.UpdateWebhookEndpointRequest true "Update webhook endpoint request"
+// @Success 200 {object} dto.WebhookEndpointResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/endpoints/{id} [put]
+func (h *WebhookHandler) UpdateEndpoint(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.UpdateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	resp, err := h.webhookService.UpdateEndpoint(c.Request.Context(), id, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to update webhook endpoint", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteEndpoint godoc
+// @Summary Delete a webhook endpoint
+// @Description Stop sending webhook events to an endpoint. Pending deliveries to it fail
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/endpoints/{id} [delete]
+func (h *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	if err := h.webhookService.DeleteEndpoint(c.Request.Context(), id); err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to delete webhook endpoint", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RotateEndpointSecret godoc
+// @Summary Rotate the signing secret of a webhook endpoint
+// @Description Issue a new signing secret. Deliveries carry a signature for both the new and the previous secret until the previous one expires
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint ID"
+// @Param request body dto.RotateWebhookSecretRequest false "Rotate secret request"
+// @Success 200 {object} dto.WebhookEndpointSecretResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/endpoints/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateEndpointSecret(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req dto.RotateWebhookSecretRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "invalid request", err)
+			return
+		}
+	}
+
+	resp, err := h.webhookService.RotateEndpointSecret(c.Request.Context(), id, req)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to rotate webhook endpoint secret", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description List the webhook deliveries of the tenant. Filter by delivery_status=failed for deliveries that exhausted their retries
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter query types.WebhookDeliveryFilter false "Filter"
+// @Success 200 {object} dto.ListWebhookDeliveriesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	var filter types.WebhookDeliveryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "invalid filter parameters", err)
+		return
+	}
+
+	resp, err := h.webhookService.ListDeliveries(c.Request.Context(), &filter)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to list webhook deliveries", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetDelivery godoc
+// @Summary Get a webhook delivery
+// @Description Get a webhook delivery by ID, including its payload and last attempt
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} dto.WebhookDeliveryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/deliveries/{id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.webhookService.GetDelivery(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to get webhook delivery", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReplayDelivery godoc
+// @Summary Replay a webhook delivery
+// @Description Send a webhook delivery again with a fresh retry budget
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} dto.WebhookDeliveryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/deliveries/{id}/replay [post]
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	resp, err := h.webhookService.ReplayDelivery(c.Request.Context(), id)
+	if err != nil {
+		NewErrorResponse(c, http.StatusInternalServerError, "failed to replay webhook delivery", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
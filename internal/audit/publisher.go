@@ -0,0 +1,182 @@
+// Package audit records who changed what and when across the services
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	auditDomain "github.com/flexprice/flexprice/internal/domain/audit"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// bufferSize is the number of logs waiting to be written before Publish writes
+// them itself
+const bufferSize = 1024
+
+// ignoredFields change on every update and are not recorded
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+	"updated_by": true,
+}
+
+// Publisher records a log of a change made in ctx. The changes are computed
+// when publishing and the log is written asynchronously, a failure to write it
+// is logged and never fails the change
+type Publisher interface {
+	// Publish records the change of an entity from before to after. Before is nil
+	// on create and after on delete
+	Publish(ctx context.Context, entityType types.AuditEntityType, entityID string, action types.AuditAction, before, after interface{})
+
+	// Close writes the logs waiting to be written and stops the publisher
+	Close()
+}
+
+type publisher struct {
+	repo   auditDomain.Repository
+	logger *logger.Logger
+
+	logs chan *auditDomain.Log
+	wg   sync.WaitGroup
+}
+
+func NewPublisher(repo auditDomain.Repository, logger *logger.Logger) Publisher {
+	p := &publisher{
+		repo:   repo,
+		logger: logger,
+		logs:   make(chan *auditDomain.Log, bufferSize),
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for log := range p.logs {
+			p.write(log)
+		}
+	}()
+
+	return p
+}
+
+func (p *publisher) Publish(ctx context.Context, entityType types.AuditEntityType, entityID string, action types.AuditAction, before, after interface{}) {
+	changes, err := Diff(before, after)
+	if err != nil {
+		p.logger.Errorw("failed to compute audit log changes",
+			"entity_type", entityType,
+			"entity_id", entityID,
+			"error", err,
+		)
+		return
+	}
+
+	// Updates that only touched the ignored fields are not recorded
+	if action == types.AuditActionUpdate && len(changes) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		p.logger.Errorw("failed to marshal audit log changes", "entity_id", entityID, "error", err)
+		return
+	}
+
+	log := &auditDomain.Log{
+		ID:            types.GenerateUUID(),
+		TenantID:      types.GetTenantID(ctx),
+		EnvironmentID: types.GetEnvironmentID(ctx),
+		EntityType:    entityType,
+		EntityID:      entityID,
+		Action:        action,
+		RequestID:     types.GetRequestID(ctx),
+		Changes:       payload,
+		CreatedAt:     time.Now().UTC(),
+	}
+	log.ActorType, log.ActorID = actorOf(ctx)
+
+	// A full buffer means the database is slow, the log is written in the
+	// request rather than dropped
+	select {
+	case p.logs <- log:
+	default:
+		p.write(log)
+	}
+}
+
+func (p *publisher) Close() {
+	close(p.logs)
+	p.wg.Wait()
+}
+
+func (p *publisher) write(log *auditDomain.Log) {
+	if err := p.repo.Insert(context.Background(), log); err != nil {
+		p.logger.Errorw("failed to write audit log",
+			"entity_type", log.EntityType,
+			"entity_id", log.EntityID,
+			"action", log.Action,
+			"error", err,
+		)
+	}
+}
+
+// actorOf returns the API key the request was authenticated with, the user of
+// the session otherwise
+func actorOf(ctx context.Context) (types.AuditActorType, string) {
+	if apiKeyID := types.GetAPIKeyID(ctx); apiKeyID != "" {
+		return types.AuditActorTypeAPIKey, apiKeyID
+	}
+	if userID := types.GetUserID(ctx); userID != "" && userID != types.DefaultUserID {
+		return types.AuditActorTypeUser, userID
+	}
+	return types.AuditActorTypeSystem, ""
+}
+
+// Diff returns the fields of the JSON representations of before and after whose
+// values differ. Nested objects are compared as a whole
+func Diff(before, after interface{}) (map[string]auditDomain.Change, error) {
+	beforeFields, err := fieldsOf(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := fieldsOf(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]auditDomain.Change)
+	for field, value := range beforeFields {
+		if ignoredFields[field] {
+			continue
+		}
+		if afterValue, ok := afterFields[field]; !ok || !reflect.DeepEqual(value, afterValue) {
+			changes[field] = auditDomain.Change{Before: value, After: afterValue}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; ok || ignoredFields[field] {
+			continue
+		}
+		changes[field] = auditDomain.Change{After: value}
+	}
+
+	return changes, nil
+}
+
+func fieldsOf(entity interface{}) (map[string]interface{}, error) {
+	if entity == nil || (reflect.ValueOf(entity).Kind() == reflect.Ptr && reflect.ValueOf(entity).IsNil()) {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
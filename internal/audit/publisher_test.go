@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	auditDomain "github.com/flexprice/flexprice/internal/domain/audit"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type entity struct {
+	Name      string            `json:"name"`
+	Secret    string            `json:"-"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	UpdatedAt string            `json:"updated_at"`
+}
+
+type memoryRepository struct {
+	logs []*auditDomain.Log
+}
+
+func (r *memoryRepository) Insert(ctx context.Context, log *auditDomain.Log) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *memoryRepository) List(ctx context.Context, filter *types.AuditLogFilter) ([]*auditDomain.Log, error) {
+	return r.logs, nil
+}
+
+func TestDiff(t *testing.T) {
+	before := &entity{Name: "Acme", Secret: "s1", UpdatedAt: "2024-01-01"}
+	after := &entity{Name: "Acme Inc", Secret: "s2", Metadata: map[string]string{"tier": "gold"}, UpdatedAt: "2024-01-02"}
+
+	changes, err := Diff(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]auditDomain.Change{
+		"name":     {Before: "Acme", After: "Acme Inc"},
+		"metadata": {After: map[string]interface{}{"tier": "gold"}},
+	}, changes)
+
+	changes, err = Diff(nil, before)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]auditDomain.Change{"name": {After: "Acme"}}, changes)
+
+	var missing *entity
+	changes, err = Diff(before, missing)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]auditDomain.Change{"name": {Before: "Acme"}}, changes)
+}
+
+func TestPublisher(t *testing.T) {
+	ctx := context.WithValue(context.Background(), types.CtxTenantID, "tenant_1")
+	ctx = context.WithValue(ctx, types.CtxAPIKeyID, "key_1")
+
+	repo := &memoryRepository{}
+	p := NewPublisher(repo, logger.GetLogger())
+
+	p.Publish(ctx, types.AuditEntityTypeCustomer, "cust_1", types.AuditActionCreate, nil, &entity{Name: "Acme"})
+	// Updates of the ignored fields only are not recorded
+	p.Publish(ctx, types.AuditEntityTypeCustomer, "cust_1", types.AuditActionUpdate,
+		&entity{Name: "Acme", UpdatedAt: "1"}, &entity{Name: "Acme", UpdatedAt: "2"})
+	p.Close()
+
+	require.Len(t, repo.logs, 1)
+	log := repo.logs[0]
+	assert.Equal(t, "tenant_1", log.TenantID)
+	assert.Equal(t, types.AuditActorTypeAPIKey, log.ActorType)
+	assert.Equal(t, "key_1", log.ActorID)
+
+	var changes map[string]auditDomain.Change
+	require.NoError(t, json.Unmarshal(log.Changes, &changes))
+	assert.Equal(t, "Acme", changes["name"].After)
+}
@@ -99,6 +99,10 @@ func (f *flexpriceAuth) ValidateToken(ctx context.Context, token string) (*auth.
 }
 
 func (f *flexpriceAuth) generateToken(userID, tenantID string) (string, error) {
+	return signUserToken(f.AuthConfig.Secret, userID, tenantID)
+}
+
+func signUserToken(secret, userID, tenantID string) (string, error) {
 	// generate a JWT token with the user ID and tenant ID with 30 days expiration
 	expiration := time.Now().Add(30 * 24 * time.Hour)
 
@@ -110,5 +114,5 @@ func (f *flexpriceAuth) generateToken(userID, tenantID string) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(f.AuthConfig.Secret))
+	return token.SignedString([]byte(secret))
 }
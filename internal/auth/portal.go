@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/golang-jwt/jwt"
+)
+
+// PortalAudience marks a token as a customer portal session token. These tokens
+// carry no user and are rejected by the regular authentication
+const PortalAudience = "portal"
+
+// PortalClaims are the claims of a customer portal session token
+type PortalClaims struct {
+	SessionID     string
+	TenantID      string
+	CustomerID    string
+	EnvironmentID string
+	ExpiresAt     time.Time
+}
+
+// NewPortalToken signs a portal session token for the claims
+func NewPortalToken(cfg *config.Configuration, claims PortalClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"aud":            PortalAudience,
+		"jti":            claims.SessionID,
+		"tenant_id":      claims.TenantID,
+		"customer_id":    claims.CustomerID,
+		"environment_id": claims.EnvironmentID,
+		"exp":            claims.ExpiresAt.Unix(),
+		"iat":            time.Now().Unix(),
+	})
+	return token.SignedString([]byte(cfg.Auth.Secret))
+}
+
+// ValidatePortalToken verifies a portal session token and returns its claims
+func ValidatePortalToken(cfg *config.Configuration, token string) (*PortalClaims, error) {
+	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(cfg.Auth.Secret), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("token parse error: %w", err)
+	}
+
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok || !parsedToken.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	if !claims.VerifyAudience(PortalAudience, true) {
+		return nil, fmt.Errorf("not a portal token")
+	}
+
+	tenantID, _ := claims["tenant_id"].(string)
+	customerID, _ := claims["customer_id"].(string)
+	if tenantID == "" || customerID == "" {
+		return nil, fmt.Errorf("token missing tenant or customer")
+	}
+
+	sessionID, _ := claims["jti"].(string)
+	environmentID, _ := claims["environment_id"].(string)
+	exp, _ := claims["exp"].(float64)
+
+	return &PortalClaims{
+		SessionID:     sessionID,
+		TenantID:      tenantID,
+		CustomerID:    customerID,
+		EnvironmentID: environmentID,
+		ExpiresAt:     time.Unix(int64(exp), 0).UTC(),
+	}, nil
+}
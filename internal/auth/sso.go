@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/golang-jwt/jwt"
+)
+
+// SSOStateAudience marks a token as the state of a single sign on login. The state
+// travels through the identity provider and binds its response to the login started
+const SSOStateAudience = "sso"
+
+// SSOStateTTL bounds the time a user has to authenticate with the identity provider
+const SSOStateTTL = 10 * time.Minute
+
+// SSOState is the state of a single sign on login
+type SSOState struct {
+	TenantID string
+	Protocol types.SSOProtocol
+	// Nonce is the OIDC nonce or the ID of the SAML authentication request
+	Nonce string
+}
+
+// NewSSOStateToken signs the state of a single sign on login
+func NewSSOStateToken(cfg *config.Configuration, state SSOState) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"aud":       SSOStateAudience,
+		"tenant_id": state.TenantID,
+		"protocol":  string(state.Protocol),
+		"nonce":     state.Nonce,
+		"exp":       time.Now().Add(SSOStateTTL).Unix(),
+		"iat":       time.Now().Unix(),
+	})
+	return token.SignedString([]byte(cfg.Auth.Secret))
+}
+
+// ValidateSSOStateToken verifies the state of a single sign on login
+func ValidateSSOStateToken(cfg *config.Configuration, token string) (*SSOState, error) {
+	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(cfg.Auth.Secret), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("token parse error: %w", err)
+	}
+
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok || !parsedToken.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	if !claims.VerifyAudience(SSOStateAudience, true) {
+		return nil, fmt.Errorf("not a sso state token")
+	}
+
+	tenantID, _ := claims["tenant_id"].(string)
+	protocol, _ := claims["protocol"].(string)
+	nonce, _ := claims["nonce"].(string)
+	if tenantID == "" || nonce == "" {
+		return nil, fmt.Errorf("token missing tenant or nonce")
+	}
+
+	return &SSOState{
+		TenantID: tenantID,
+		Protocol: types.SSOProtocol(protocol),
+		Nonce:    nonce,
+	}, nil
+}
+
+// NewUserToken signs the auth token of a user of the tenant, as the password login does
+func NewUserToken(cfg *config.Configuration, userID, tenantID string) (string, error) {
+	return signUserToken(cfg.Auth.Secret, userID, tenantID)
+}
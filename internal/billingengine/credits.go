@@ -0,0 +1,53 @@
+package billingengine
+
+import "github.com/shopspring/decimal"
+
+// Credit is a balance that can pay for an amount due, such as a prepaid wallet
+type Credit struct {
+	ID      string          `json:"id"`
+	Balance decimal.Decimal `json:"balance"`
+}
+
+type CreditApplication struct {
+	ID            string          `json:"id"`
+	BalanceBefore decimal.Decimal `json:"balance_before"`
+	Amount        decimal.Decimal `json:"amount"`
+	BalanceAfter  decimal.Decimal `json:"balance_after"`
+}
+
+type CreditsResult struct {
+	Applications []CreditApplication `json:"applications"`
+	Applied      decimal.Decimal     `json:"applied"`
+	// Remaining is the part of the amount due left to pay
+	Remaining decimal.Decimal `json:"remaining"`
+}
+
+// ApplyCredits draws the amount due down from the credits in the given order
+// until it is paid or the credits run out. Credits without a positive balance
+// are skipped
+func ApplyCredits(amountDue decimal.Decimal, credits []Credit) *CreditsResult {
+	result := &CreditsResult{Applied: decimal.Zero, Remaining: amountDue}
+
+	for _, credit := range credits {
+		if !result.Remaining.IsPositive() {
+			break
+		}
+
+		if !credit.Balance.IsPositive() {
+			continue
+		}
+
+		amount := decimal.Min(credit.Balance, result.Remaining)
+		result.Remaining = result.Remaining.Sub(amount)
+		result.Applied = result.Applied.Add(amount)
+
+		result.Applications = append(result.Applications, CreditApplication{
+			ID:            credit.ID,
+			BalanceBefore: credit.Balance,
+			Amount:        amount,
+			BalanceAfter:  credit.Balance.Sub(amount),
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,189 @@
+// Package billingengine computes the charges and totals of a billing period.
+// It is pure: every input is passed in, nothing is read from or written to a
+// store, so the same inputs always produce the same result. Services gather
+// the subscription, prices and usage and persist what the engine returns
+package billingengine
+
+import (
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// CommitmentTrueUpDisplayName is the display name of the line item topping the
+// plan charges up to the commitment
+const CommitmentTrueUpDisplayName = "Commitment true-up"
+
+// LineItemType is what a computed line item charges for
+type LineItemType string
+
+const (
+	LineItemTypeFixed            LineItemType = "fixed"
+	LineItemTypeUsage            LineItemType = "usage"
+	LineItemTypeCommitmentTrueUp LineItemType = "commitment_true_up"
+	LineItemTypeAdjustment       LineItemType = "adjustment"
+)
+
+// Input is everything needed to compute the charges of a subscription period
+type Input struct {
+	Currency string `json:"currency"`
+
+	// PeriodShare is the share of a full period being billed. Recurring fixed
+	// charges and the commitment are scaled by it and left out when it is zero
+	PeriodShare decimal.Decimal `json:"period_share"`
+
+	// FirstPaidPeriod is set for the first billed period of the subscription,
+	// the only one carrying the one time charges
+	FirstPaidPeriod bool `json:"first_paid_period"`
+
+	// FixedPrices are the fixed prices of the plan valid for the subscription
+	FixedPrices []*price.Price `json:"fixed_prices"`
+
+	// FixedQuantities are the quantities of the fixed prices by price ID. Prices
+	// missing from it are charged once
+	FixedQuantities map[string]decimal.Decimal `json:"fixed_quantities,omitempty"`
+
+	// Usage is the metered quantity of the period for each usage price
+	Usage []Usage `json:"usage"`
+
+	// Commitment is the minimum amount billed for a full period. Zero means no commitment
+	Commitment decimal.Decimal `json:"commitment"`
+
+	// Adjustments are applied on top of the plan charges and do not count
+	// towards the commitment
+	Adjustments []Adjustment `json:"adjustments"`
+}
+
+type Usage struct {
+	Price       *price.Price    `json:"price"`
+	DisplayName string          `json:"display_name"`
+	Quantity    decimal.Decimal `json:"quantity"`
+}
+
+type Adjustment struct {
+	DisplayName string          `json:"display_name"`
+	Amount      decimal.Decimal `json:"amount"`
+
+	// ProrationID is the subscription proration the adjustment bills, if any
+	ProrationID string `json:"proration_id,omitempty"`
+}
+
+type LineItem struct {
+	Type        LineItemType    `json:"type"`
+	PriceID     string          `json:"price_id,omitempty"`
+	MeterID     string          `json:"meter_id,omitempty"`
+	DisplayName string          `json:"display_name"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	Amount      decimal.Decimal `json:"amount"`
+	ProrationID string          `json:"proration_id,omitempty"`
+}
+
+type Result struct {
+	LineItems []LineItem `json:"line_items"`
+
+	// PlanCharges is the sum of the fixed and usage charges the commitment is compared to
+	PlanCharges decimal.Decimal `json:"plan_charges"`
+
+	// Total is the signed sum of all line items
+	Total decimal.Decimal `json:"total"`
+
+	// AmountDue is the non negative part of the total
+	AmountDue decimal.Decimal `json:"amount_due"`
+}
+
+// Calculate computes the line items and totals of the period
+func Calculate(in Input) *Result {
+	result := &Result{LineItems: []LineItem{}, PlanCharges: decimal.Zero}
+	precision := types.GetCurrencyPrecision(in.Currency)
+	one := decimal.NewFromInt(1)
+
+	for _, p := range in.FixedPrices {
+		if p.Type != types.PRICE_TYPE_FIXED {
+			continue
+		}
+
+		quantity, ok := in.FixedQuantities[p.ID]
+		if !ok {
+			quantity = one
+		}
+		if !quantity.IsPositive() {
+			continue
+		}
+
+		amount := p.Amount.Mul(quantity)
+		if p.BillingCadence == types.BILLING_CADENCE_ONETIME {
+			if !in.FirstPaidPeriod {
+				continue
+			}
+		} else if in.PeriodShare.LessThan(one) {
+			if !in.PeriodShare.IsPositive() {
+				continue
+			}
+			amount = amount.Mul(in.PeriodShare).Round(precision)
+			quantity = quantity.Mul(in.PeriodShare).Round(4)
+		}
+
+		displayName := p.Description
+		if displayName == "" {
+			displayName = p.LookupKey
+		}
+
+		result.add(LineItem{
+			Type:        LineItemTypeFixed,
+			PriceID:     p.ID,
+			DisplayName: displayName,
+			Quantity:    quantity,
+			Amount:      amount,
+		})
+	}
+
+	for _, usage := range in.Usage {
+		amount := PriceCost(usage.Price, usage.Quantity)
+		if !usage.Quantity.IsPositive() || !amount.IsPositive() {
+			continue
+		}
+
+		result.add(LineItem{
+			Type:        LineItemTypeUsage,
+			PriceID:     usage.Price.ID,
+			MeterID:     usage.Price.MeterID,
+			DisplayName: usage.DisplayName,
+			Quantity:    usage.Quantity,
+			Amount:      amount,
+		})
+	}
+
+	result.PlanCharges = result.Total
+
+	// Plan charges below the commitment are topped up to it
+	if in.Commitment.IsPositive() && in.PeriodShare.IsPositive() {
+		commitment := in.Commitment.Mul(in.PeriodShare).Round(precision)
+		if shortfall := commitment.Sub(result.PlanCharges); shortfall.IsPositive() {
+			result.add(LineItem{
+				Type:        LineItemTypeCommitmentTrueUp,
+				DisplayName: CommitmentTrueUpDisplayName,
+				Quantity:    one,
+				Amount:      shortfall,
+			})
+		}
+	}
+
+	for _, adjustment := range in.Adjustments {
+		result.add(LineItem{
+			Type:        LineItemTypeAdjustment,
+			DisplayName: adjustment.DisplayName,
+			Quantity:    one,
+			Amount:      adjustment.Amount,
+			ProrationID: adjustment.ProrationID,
+		})
+	}
+
+	result.AmountDue = decimal.Max(result.Total, decimal.Zero)
+
+	return result
+}
+
+func (r *Result) add(item LineItem) {
+	r.LineItems = append(r.LineItems, item)
+	r.Total = r.Total.Add(item.Amount)
+}
@@ -0,0 +1,223 @@
+package billingengine
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run with -update to rewrite the golden files after an intended change
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestCalculate_Golden computes every testdata/<case>.input.json and compares
+// the result to testdata/<case>.golden.json
+func TestCalculate_Golden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.input.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+
+	for _, inputPath := range inputs {
+		name := strings.TrimSuffix(filepath.Base(inputPath), ".input.json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(inputPath)
+			require.NoError(t, err)
+
+			var in Input
+			require.NoError(t, json.Unmarshal(data, &in))
+
+			got, err := json.MarshalIndent(Calculate(in), "", "  ")
+			require.NoError(t, err)
+			got = append(got, '\n')
+
+			goldenPath := filepath.Join("testdata", name+".golden.json")
+			if *update {
+				require.NoError(t, os.WriteFile(goldenPath, got, 0o644))
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			require.NoError(t, err, "run go test ./internal/billingengine -update to create the golden file")
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestPeriodShare(t *testing.T) {
+	feb15 := time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC)
+	mar1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		period       Period
+		wantShare    string
+		wantBehavior types.PartialPeriodBehavior
+	}{
+		{
+			name: "anniversary billing is always billed in full",
+			period: Period{
+				Start: feb15, FirstPaidStart: feb15,
+				BillingCycle: types.BillingCycleAnniversary, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+			},
+			wantShare: "1",
+		},
+		{
+			name: "calendar billing starting on the boundary",
+			period: Period{
+				Start: mar1, FirstPaidStart: mar1,
+				BillingCycle: types.BillingCycleCalendar, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+			},
+			wantShare: "1",
+		},
+		{
+			name: "calendar billing after the first period",
+			period: Period{
+				Start: mar1, FirstPaidStart: feb15,
+				BillingCycle: types.BillingCycleCalendar, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+			},
+			wantShare: "1",
+		},
+		{
+			name: "partial first period prorated",
+			period: Period{
+				Start: feb15, FirstPaidStart: feb15,
+				BillingCycle: types.BillingCycleCalendar, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+				PartialPeriodBehavior: types.PartialPeriodBehaviorProrate,
+			},
+			wantShare:    "0.5",
+			wantBehavior: types.PartialPeriodBehaviorProrate,
+		},
+		{
+			name: "partial first period free",
+			period: Period{
+				Start: feb15, FirstPaidStart: feb15,
+				BillingCycle: types.BillingCycleCalendar, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+				PartialPeriodBehavior: types.PartialPeriodBehaviorFree,
+			},
+			wantShare:    "0",
+			wantBehavior: types.PartialPeriodBehaviorFree,
+		},
+		{
+			name: "partial first period billed in full",
+			period: Period{
+				Start: feb15, FirstPaidStart: feb15,
+				BillingCycle: types.BillingCycleCalendar, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+				PartialPeriodBehavior: types.PartialPeriodBehaviorFull,
+			},
+			wantShare:    "1",
+			wantBehavior: types.PartialPeriodBehaviorFull,
+		},
+		{
+			name: "calendar billing in the subscription timezone",
+			period: Period{
+				Start: feb15.Add(5 * time.Hour), FirstPaidStart: feb15.Add(5 * time.Hour), Timezone: "America/New_York",
+				BillingCycle: types.BillingCycleCalendar, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+				PartialPeriodBehavior: types.PartialPeriodBehaviorProrate,
+			},
+			wantShare:    "0.5",
+			wantBehavior: types.PartialPeriodBehaviorProrate,
+		},
+		{
+			name: "anchor day billing starting on the anchor day",
+			period: Period{
+				Start: mar1, FirstPaidStart: mar1, BillingAnchorDay: 1,
+				BillingCycle: types.BillingCycleAnniversary, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+				PartialPeriodBehavior: types.PartialPeriodBehaviorProrate,
+			},
+			wantShare: "1",
+		},
+		{
+			name: "anchor day billing starting later on the anchor day",
+			period: Period{
+				Start: mar1.Add(10 * time.Hour), FirstPaidStart: mar1.Add(10 * time.Hour), BillingAnchorDay: 1,
+				BillingCycle: types.BillingCycleAnniversary, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+				PartialPeriodBehavior: types.PartialPeriodBehaviorProrate,
+			},
+			wantShare: "1",
+		},
+		{
+			name: "anchor day billing partial first period prorated",
+			period: Period{
+				Start: feb15, FirstPaidStart: feb15, BillingAnchorDay: 1, BillingPeriodCount: 1,
+				BillingCycle: types.BillingCycleAnniversary, BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+				PartialPeriodBehavior: types.PartialPeriodBehaviorProrate,
+			},
+			wantShare:    "0.5",
+			wantBehavior: types.PartialPeriodBehaviorProrate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			share, behavior, err := PeriodShare(tt.period)
+			require.NoError(t, err)
+			assert.True(t, decimal.RequireFromString(tt.wantShare).Equal(share), "share %s", share)
+			assert.Equal(t, tt.wantBehavior, behavior)
+		})
+	}
+}
+
+func TestApplyCredits(t *testing.T) {
+	result := ApplyCredits(decimal.NewFromInt(50), []Credit{
+		{ID: "empty", Balance: decimal.Zero},
+		{ID: "first", Balance: decimal.NewFromInt(30)},
+		{ID: "second", Balance: decimal.NewFromInt(40)},
+		{ID: "unused", Balance: decimal.NewFromInt(10)},
+	})
+
+	require.Len(t, result.Applications, 2)
+	assert.Equal(t, "first", result.Applications[0].ID)
+	assert.True(t, decimal.NewFromInt(30).Equal(result.Applications[0].Amount))
+	assert.True(t, result.Applications[0].BalanceAfter.IsZero())
+	assert.Equal(t, "second", result.Applications[1].ID)
+	assert.True(t, decimal.NewFromInt(20).Equal(result.Applications[1].Amount))
+	assert.True(t, decimal.NewFromInt(20).Equal(result.Applications[1].BalanceAfter))
+	assert.True(t, decimal.NewFromInt(50).Equal(result.Applied))
+	assert.True(t, result.Remaining.IsZero())
+}
+
+func TestProration(t *testing.T) {
+	mar1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	apr1 := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	full := decimal.NewFromInt(1)
+
+	tests := []struct {
+		name   string
+		change Change
+		want   string
+	}{
+		{
+			name:   "upgrade credits the days before the change",
+			change: Change{Currency: "usd", PeriodStart: mar1, PeriodEnd: apr1, ChangedAt: mar1.AddDate(0, 0, 10), PeriodShare: full, Before: decimal.NewFromInt(31), After: decimal.NewFromInt(93)},
+			want:   "-20",
+		},
+		{
+			name:   "downgrade charges the days before the change",
+			change: Change{Currency: "usd", PeriodStart: mar1, PeriodEnd: apr1, ChangedAt: mar1.AddDate(0, 0, 10), PeriodShare: full, Before: decimal.NewFromInt(93), After: decimal.NewFromInt(31)},
+			want:   "20",
+		},
+		{
+			name:   "partial period is prorated on its share",
+			change: Change{Currency: "usd", PeriodStart: mar1, PeriodEnd: apr1, ChangedAt: mar1.AddDate(0, 0, 10), PeriodShare: decimal.NewFromFloat(0.5), Before: decimal.NewFromInt(31), After: decimal.NewFromInt(93)},
+			want:   "-10",
+		},
+		{
+			name:   "change outside of the period",
+			change: Change{Currency: "usd", PeriodStart: mar1, PeriodEnd: apr1, ChangedAt: apr1, PeriodShare: full, Before: decimal.NewFromInt(31), After: decimal.NewFromInt(93)},
+			want:   "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Proration(tt.change).String())
+		})
+	}
+}
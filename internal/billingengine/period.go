@@ -0,0 +1,75 @@
+package billingengine
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Period describes where a billed period falls in the life of the subscription
+type Period struct {
+	Start time.Time `json:"start"`
+
+	// FirstPaidStart is the start of the first billed period of the
+	// subscription, the end of the trial if any
+	FirstPaidStart time.Time `json:"first_paid_start"`
+
+	BillingCycle          types.BillingCycle          `json:"billing_cycle"`
+	BillingPeriod         types.BillingPeriod         `json:"billing_period"`
+	BillingPeriodCount    int                         `json:"billing_period_count,omitempty"`
+	BillingAnchorDay      int                         `json:"billing_anchor_day,omitempty"`
+	PartialPeriodBehavior types.PartialPeriodBehavior `json:"partial_period_behavior"`
+
+	// Timezone is the IANA timezone calendar and anchor day boundaries fall at midnight in, UTC when empty
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// PeriodShare returns the share of a full period billed for the period. Only the
+// partial first period of a calendar or anchor day billed subscription is billed
+// less than a full period, according to its partial period behavior which is
+// returned as applied. The behavior is empty for any other period
+func PeriodShare(p Period) (decimal.Decimal, types.PartialPeriodBehavior, error) {
+	full := decimal.NewFromInt(1)
+	if !p.Start.Equal(p.FirstPaidStart) {
+		return full, "", nil
+	}
+
+	loc, err := types.LoadTimezone(p.Timezone)
+	if err != nil {
+		return decimal.Zero, "", err
+	}
+	start := p.Start.In(loc)
+
+	var fullStart, fullEnd time.Time
+	switch {
+	case p.BillingCycle == types.BillingCycleCalendar:
+		fullStart, fullEnd, err = types.CalendarPeriod(start, p.BillingPeriod)
+	case p.BillingAnchorDay > 0:
+		// Starting at any time of the anchor day is a full period
+		if types.IsAnchorDay(start, p.BillingAnchorDay) {
+			return full, "", nil
+		}
+		fullStart, fullEnd, err = types.AnchoredPeriod(start, p.BillingAnchorDay, max(p.BillingPeriodCount, 1))
+	default:
+		return full, "", nil
+	}
+	if err != nil {
+		return decimal.Zero, "", err
+	}
+
+	if fullStart.Equal(p.Start) {
+		return full, "", nil
+	}
+
+	switch p.PartialPeriodBehavior {
+	case types.PartialPeriodBehaviorFree:
+		return decimal.Zero, types.PartialPeriodBehaviorFree, nil
+	case types.PartialPeriodBehaviorFull:
+		return full, types.PartialPeriodBehaviorFull, nil
+	default:
+		used := decimal.NewFromInt(int64(fullEnd.Sub(p.Start) / time.Second))
+		length := decimal.NewFromInt(int64(fullEnd.Sub(fullStart) / time.Second))
+		return used.Div(length), types.PartialPeriodBehaviorProrate, nil
+	}
+}
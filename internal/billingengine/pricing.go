@@ -0,0 +1,100 @@
+package billingengine
+
+import (
+	"sort"
+
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// PriceCost returns the cost of the quantity at the price, rounded to the
+// precision of the price currency. Prices that can not be computed, such as
+// tiered prices without tiers, cost nothing
+func PriceCost(p *price.Price, quantity decimal.Decimal) decimal.Decimal {
+	cost := decimal.Zero
+	if quantity.IsZero() {
+		return cost
+	}
+
+	switch p.BillingModel {
+	case types.BILLING_MODEL_FLAT_FEE:
+		cost = p.CalculateAmount(quantity)
+
+	case types.BILLING_MODEL_PACKAGE:
+		if p.TransformQuantity.DivideBy <= 0 {
+			return decimal.Zero
+		}
+
+		transformedQuantity := quantity.Div(decimal.NewFromInt(int64(p.TransformQuantity.DivideBy)))
+
+		if p.TransformQuantity.Round == types.ROUND_UP {
+			transformedQuantity = transformedQuantity.Ceil()
+		} else if p.TransformQuantity.Round == types.ROUND_DOWN {
+			transformedQuantity = transformedQuantity.Floor()
+		}
+
+		cost = p.CalculateAmount(transformedQuantity)
+
+	case types.BILLING_MODEL_TIERED:
+		cost = tieredCost(p, quantity)
+	}
+
+	return cost.Round(types.GetCurrencyPrecision(p.Currency))
+}
+
+// tieredCost prices the quantity on the tiers of the price. Volume pricing bills
+// the whole quantity at the tier it reaches, slab pricing bills each tier for
+// the part of the quantity that falls into it
+func tieredCost(p *price.Price, quantity decimal.Decimal) decimal.Decimal {
+	if len(p.Tiers) == 0 {
+		return decimal.Zero
+	}
+
+	// Sort a copy of the tiers by up_to value to leave the price untouched
+	tiers := make([]price.PriceTier, len(p.Tiers))
+	copy(tiers, p.Tiers)
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].GetTierUpTo() < tiers[j].GetTierUpTo()
+	})
+
+	cost := decimal.Zero
+	switch p.TierMode {
+	case types.BILLING_TIER_VOLUME:
+		selectedTierIndex := len(tiers) - 1
+		// Find the tier that the quantity falls into
+		for i, tier := range tiers {
+			if tier.UpTo == nil {
+				selectedTierIndex = i
+				break
+			}
+			if quantity.LessThan(decimal.NewFromUint64(*tier.UpTo)) {
+				selectedTierIndex = i
+				break
+			}
+		}
+
+		cost = tiers[selectedTierIndex].CalculateTierAmount(quantity, p.Currency)
+
+	case types.BILLING_TIER_SLAB:
+		remainingQuantity := quantity
+		for _, tier := range tiers {
+			tierQuantity := remainingQuantity
+			if tier.UpTo != nil {
+				upTo := decimal.NewFromUint64(*tier.UpTo)
+				if remainingQuantity.GreaterThan(upTo) {
+					tierQuantity = upTo
+				}
+			}
+
+			cost = cost.Add(tier.CalculateTierAmount(tierQuantity, p.Currency))
+			remainingQuantity = remainingQuantity.Sub(tierQuantity)
+
+			if remainingQuantity.LessThanOrEqual(decimal.Zero) {
+				break
+			}
+		}
+	}
+
+	return cost
+}
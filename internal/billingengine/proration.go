@@ -0,0 +1,45 @@
+package billingengine
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Change is a change to the charge of a recurring fixed price in the middle of
+// a billing period
+type Change struct {
+	Currency    string    `json:"currency"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	ChangedAt   time.Time `json:"changed_at"`
+
+	// PeriodShare is the share of a full period billed for the period, as
+	// returned by PeriodShare
+	PeriodShare decimal.Decimal `json:"period_share"`
+
+	// Before and After are the charges of a full period before and after the change
+	Before decimal.Decimal `json:"before"`
+	After  decimal.Decimal `json:"after"`
+}
+
+// Proration returns the amount to bill on top of the period for the change.
+// Periods are charged at the charge in effect when they are invoiced, so the
+// part of the period before the change is charged the difference to the previous
+// charge: a credit for an upgrade, a charge for a downgrade. Changes outside of
+// the period are not prorated
+func Proration(c Change) decimal.Decimal {
+	if !c.ChangedAt.After(c.PeriodStart) || !c.ChangedAt.Before(c.PeriodEnd) {
+		return decimal.Zero
+	}
+
+	elapsed := decimal.NewFromInt(int64(c.ChangedAt.Sub(c.PeriodStart) / time.Second))
+	length := decimal.NewFromInt(int64(c.PeriodEnd.Sub(c.PeriodStart) / time.Second))
+
+	return c.Before.Sub(c.After).
+		Mul(c.PeriodShare).
+		Mul(elapsed).
+		Div(length).
+		Round(types.GetCurrencyPrecision(c.Currency))
+}
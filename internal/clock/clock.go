@@ -0,0 +1,147 @@
+// Package clock tells the time of the billing flows. It is the wall clock,
+// unless simulation is enabled and it can be moved forward to run the renewals
+// of months or years in minutes
+package clock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+var (
+	// ErrNotSimulated is returned when moving the wall clock
+	ErrNotSimulated = errors.New("clock is not simulated")
+
+	// ErrNotSandbox is returned when the environment of a simulated clock is
+	// not a sandbox, only sandboxes can travel in time
+	ErrNotSandbox = errors.New("clock environment is not a sandbox")
+
+	// ErrClockBackwards is returned when moving the clock to the past, the jobs
+	// assume that time never goes backwards
+	ErrClockBackwards = errors.New("clock can only move forward")
+)
+
+// Clock is the wall clock shifted by the time it was moved forward. A nil
+// clock is the wall clock
+type Clock struct {
+	simulated bool
+
+	// repo persists the offset of a simulated clock with its sandbox
+	// environment. Clocks without a repository only keep it in memory
+	repo          environment.Repository
+	tenantID      string
+	environmentID string
+
+	mu     sync.RWMutex
+	offset time.Duration
+}
+
+// New returns the clock of the deployment. A simulated clock starts at the
+// offset persisted with its sandbox environment
+func New(cfg *config.Configuration, repo environment.Repository) (*Clock, error) {
+	if !cfg.Simulation.Enabled {
+		return &Clock{}, nil
+	}
+
+	c := &Clock{
+		simulated:     true,
+		repo:          repo,
+		tenantID:      cfg.Simulation.TenantID,
+		environmentID: cfg.Simulation.EnvironmentID,
+	}
+
+	env, err := c.environment(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	c.offset = time.Duration(env.ClockOffsetMs) * time.Millisecond
+	return c, nil
+}
+
+// NewSimulated returns a simulated clock starting at the wall clock time,
+// which is not persisted
+func NewSimulated() *Clock {
+	return &Clock{simulated: true}
+}
+
+// Now returns the current time in UTC
+func (c *Clock) Now() time.Time {
+	now := time.Now().UTC()
+	if c == nil {
+		return now
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return now.Add(c.offset)
+}
+
+func (c *Clock) Simulated() bool {
+	return c != nil && c.simulated
+}
+
+// EnvironmentID returns the sandbox environment persisting a simulated clock
+func (c *Clock) EnvironmentID() string {
+	if c == nil {
+		return ""
+	}
+	return c.environmentID
+}
+
+// AdvanceTo moves a simulated clock forward to t, from which it keeps running.
+// The offset persisted with the environment is the one moved, and the new
+// offset is persisted before the clock moves
+func (c *Clock) AdvanceTo(ctx context.Context, t time.Time) error {
+	if !c.Simulated() {
+		return ErrNotSimulated
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.repo != nil {
+		env, err := c.environment(ctx)
+		if err != nil {
+			return err
+		}
+		c.offset = time.Duration(env.ClockOffsetMs) * time.Millisecond
+	}
+
+	now := time.Now().UTC().Add(c.offset)
+	if !t.After(now) {
+		return ErrClockBackwards
+	}
+	offset := c.offset + t.Sub(now)
+
+	if c.repo != nil {
+		if err := c.repo.UpdateClockOffset(c.tenantContext(ctx), c.environmentID, offset.Milliseconds()); err != nil {
+			return fmt.Errorf("failed to persist clock: %w", err)
+		}
+	}
+
+	c.offset = offset
+	return nil
+}
+
+// environment returns the sandbox environment persisting the clock
+func (c *Clock) environment(ctx context.Context) (*environment.Environment, error) {
+	env, err := c.repo.Get(c.tenantContext(ctx), c.environmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clock environment: %w", err)
+	}
+	if env.Type != types.EnvironmentTypeSandbox {
+		return nil, ErrNotSandbox
+	}
+	return env, nil
+}
+
+func (c *Clock) tenantContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, types.CtxTenantID, c.tenantID)
+}
@@ -0,0 +1,92 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClock(t *testing.T) {
+	ctx := context.Background()
+
+	var wall *Clock
+	assert.False(t, wall.Simulated())
+	assert.WithinDuration(t, time.Now(), wall.Now(), time.Second)
+	assert.ErrorIs(t, wall.AdvanceTo(ctx, time.Now().AddDate(1, 0, 0)), ErrNotSimulated)
+
+	disabled, err := New(&config.Configuration{}, nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, disabled.AdvanceTo(ctx, time.Now().AddDate(1, 0, 0)), ErrNotSimulated)
+
+	c := NewSimulated()
+	assert.True(t, c.Simulated())
+
+	target := time.Now().UTC().AddDate(0, 3, 0)
+	require.NoError(t, c.AdvanceTo(ctx, target))
+	assert.WithinDuration(t, target, c.Now(), time.Second)
+
+	// The simulated clock keeps running from where it was moved to
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, c.Now().After(target))
+
+	assert.ErrorIs(t, c.AdvanceTo(ctx, target), ErrClockBackwards)
+	assert.ErrorIs(t, c.AdvanceTo(ctx, time.Now()), ErrClockBackwards)
+}
+
+func TestClockPersisted(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	store := testutil.NewInMemoryEnvironmentStore()
+	for id, envType := range map[string]types.EnvironmentType{
+		"env_sandbox":    types.EnvironmentTypeSandbox,
+		"env_production": types.EnvironmentTypeProduction,
+	} {
+		require.NoError(t, store.Create(ctx, &environment.Environment{
+			ID:        id,
+			Type:      envType,
+			BaseModel: types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	cfg := func(environmentID string) *config.Configuration {
+		return &config.Configuration{Simulation: config.SimulationConfig{
+			Enabled:       true,
+			TenantID:      types.DefaultTenantID,
+			EnvironmentID: environmentID,
+		}}
+	}
+
+	_, err := New(cfg("env_production"), store)
+	assert.ErrorIs(t, err, ErrNotSandbox)
+	_, err = New(cfg("env_missing"), store)
+	assert.Error(t, err)
+
+	c, err := New(cfg("env_sandbox"), store)
+	require.NoError(t, err)
+	assert.Equal(t, "env_sandbox", c.EnvironmentID())
+
+	target := time.Now().UTC().AddDate(1, 0, 0)
+	require.NoError(t, c.AdvanceTo(context.Background(), target))
+
+	env, err := store.Get(ctx, "env_sandbox")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Until(target).Milliseconds(), env.ClockOffsetMs, float64(time.Second.Milliseconds()))
+
+	// A restarted deployment resumes from the persisted clock
+	restarted, err := New(cfg("env_sandbox"), store)
+	require.NoError(t, err)
+	assert.WithinDuration(t, target, restarted.Now(), time.Second)
+	assert.ErrorIs(t, restarted.AdvanceTo(ctx, target.AddDate(0, -1, 0)), ErrClockBackwards)
+
+	// The clock moved by another process is the one moved forward
+	require.NoError(t, restarted.AdvanceTo(ctx, target.AddDate(0, 1, 0)))
+	assert.ErrorIs(t, c.AdvanceTo(ctx, target.AddDate(0, 0, 15)), ErrClockBackwards)
+	assert.WithinDuration(t, target.AddDate(0, 1, 0), c.Now(), time.Second)
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"slices"
 
 	"strings"
 
@@ -16,35 +17,93 @@ import (
 )
 
 type Configuration struct {
-	Deployment DeploymentConfig `validate:"required"`
-	Server     ServerConfig     `validate:"required"`
-	Auth       AuthConfig       `validate:"required"`
-	Kafka      KafkaConfig      `validate:"required"`
-	ClickHouse ClickHouseConfig `validate:"required"`
-	Logging    LoggingConfig    `validate:"required"`
-	Postgres   PostgresConfig   `validate:"required"`
+	Deployment  DeploymentConfig  `validate:"required"`
+	Server      ServerConfig      `validate:"required"`
+	Auth        AuthConfig        `validate:"required"`
+	Queue       QueueConfig       `mapstructure:"queue"`
+	Kafka       KafkaConfig       `validate:"required"`
+	ClickHouse  ClickHouseConfig  `validate:"required"`
+	EventStore  EventStoreConfig  `mapstructure:"event_store"`
+	Logging     LoggingConfig     `validate:"required"`
+	Postgres    PostgresConfig    `validate:"required"`
+	Export      ExportConfig      `mapstructure:"export"`
+	Billing     BillingConfig     `mapstructure:"billing"`
+	Integration IntegrationConfig `mapstructure:"integration"`
+	Webhook     WebhookConfig     `mapstructure:"webhook"`
+	Email       EmailConfig       `mapstructure:"email"`
+	Anomaly     AnomalyConfig     `mapstructure:"anomaly"`
+	RequestLog  RequestLogConfig  `mapstructure:"request_log"`
+	Redis       RedisConfig       `mapstructure:"redis"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
+	UsageStream UsageStreamConfig `mapstructure:"usage_stream"`
+	Admin       AdminConfig       `mapstructure:"admin"`
+
+	EventRetention EventRetentionConfig `mapstructure:"event_retention"`
+	UsageRollup    UsageRollupConfig    `mapstructure:"usage_rollup"`
+	SoftDelete     SoftDeleteConfig     `mapstructure:"soft_delete"`
+	EventBackfill  EventBackfillConfig  `mapstructure:"event_backfill"`
+	Scheduler      SchedulerConfig      `mapstructure:"scheduler"`
+	Simulation     SimulationConfig     `mapstructure:"simulation"`
 }
 
 type DeploymentConfig struct {
 	Mode types.RunMode `mapstructure:"mode" validate:"required"`
+
+	// IDStrategy is how the IDs of new entities are generated ex uuid or ulid
+	IDStrategy types.IDStrategy `mapstructure:"id_strategy"`
 }
 
 type ServerConfig struct {
 	Address string `mapstructure:"address" validate:"required"`
+
+	// GRPCAddress is where the gRPC server listens in the local and grpc modes
+	GRPCAddress string `mapstructure:"grpc_address"`
 }
 
 type AuthConfig struct {
 	Provider types.AuthProvider `mapstructure:"provider" validate:"required"`
 	Secret   string             `mapstructure:"secret" validate:"required"`
 	Supabase SupabaseConfig     `mapstructure:"supabase"`
+
+	// PortalSessionTTLMins is the lifetime of customer portal session tokens
+	PortalSessionTTLMins int `mapstructure:"portal_session_ttl_mins"`
+
+	SSO SSOConfig `mapstructure:"sso"`
 }
 
 type SupabaseConfig struct {
 	BaseURL string `mapstructure:"base_url"`
 }
 
+// SSOConfig configures the sign in of the tenant users with the OIDC and SAML
+// identity providers of their tenant
+type SSOConfig struct {
+	// BaseURL is the public URL of the API the identity providers send the
+	// users back to ex https://api.flexprice.io. It is also the SAML entity ID
+	BaseURL string `mapstructure:"base_url"`
+
+	// RedirectURL is the dashboard page the users signed in with SSO are sent
+	// to, with their token in the token fragment parameter. The token is
+	// returned as JSON when empty
+	RedirectURL string `mapstructure:"redirect_url"`
+}
+
+// QueueConfig selects what carries the ingested events to the consumer. With the
+// postgres backend the topics, consumer groups, lanes and batch size of the
+// kafka section are kept, the messages are stored in the queue_messages table
+// and polled every poll_interval_ms, 1000 when unset
+type QueueConfig struct {
+	Backend        types.QueueBackend `mapstructure:"backend"`
+	PollIntervalMs int                `mapstructure:"poll_interval_ms"`
+}
+
+// UsesPostgres reports whether the events are queued in Postgres instead of kafka
+func (c QueueConfig) UsesPostgres() bool {
+	return c.Backend == types.QueueBackendPostgres
+}
+
 type KafkaConfig struct {
-	Brokers       []string             `mapstructure:"brokers" validate:"required"`
+	Brokers       []string             `mapstructure:"brokers"`
 	ConsumerGroup string               `mapstructure:"consumer_group" validate:"required"`
 	Topic         string               `mapstructure:"topic" validate:"required"`
 	UseSASL       bool                 `mapstructure:"use_sasl"`
@@ -52,14 +111,94 @@ type KafkaConfig struct {
 	SASLUser      string               `mapstructure:"sasl_user"`
 	SASLPassword  string               `mapstructure:"sasl_password"`
 	ClientID      string               `mapstructure:"client_id" validate:"required"`
+
+	// DeadLetterTopic receives the messages that the consumer failed to process,
+	// which are also kept in Postgres to be listed and replayed. Failed messages
+	// are only kept in Postgres when empty
+	DeadLetterTopic string `mapstructure:"dead_letter_topic"`
+
+	// Concurrency is how many members of the consumer group of Topic each
+	// consumer process runs, 1 when unset. The partitions of the topic are
+	// shared out between the members of all the processes
+	Concurrency int `mapstructure:"concurrency"`
+
+	// BatchSize is how many events of a partition the consumer inserts at once,
+	// 100 when unset. A batch is inserted once full or batch_linger_ms after its
+	// first event, 500 when unset
+	BatchSize     int `mapstructure:"batch_size"`
+	BatchLingerMs int `mapstructure:"batch_linger_ms"`
+
+	// Lanes route the events of some tenants or event names to their own topic,
+	// consumed by their own consumer group, so a noisy tenant can not delay the
+	// billing critical events of the others. An event goes to the first lane it
+	// matches, or to Topic when it matches none
+	Lanes []KafkaLaneConfig `mapstructure:"lanes" validate:"dive"`
+}
+
+// KafkaLaneConfig is a topic of events consumed apart from the others. A lane
+// matches the events of its tenants and event names, a list left empty matches
+// any tenant or event name
+type KafkaLaneConfig struct {
+	Name          string   `mapstructure:"name" validate:"required"`
+	Topic         string   `mapstructure:"topic" validate:"required"`
+	ConsumerGroup string   `mapstructure:"consumer_group" validate:"required"`
+	Concurrency   int      `mapstructure:"concurrency"`
+	TenantIDs     []string `mapstructure:"tenant_ids" validate:"required_without=EventNames"`
+	EventNames    []string `mapstructure:"event_names"`
+}
+
+// Matches reports whether the events of the tenant with the event name go to the lane
+func (l KafkaLaneConfig) Matches(tenantID, eventName string) bool {
+	return (len(l.TenantIDs) == 0 || slices.Contains(l.TenantIDs, tenantID)) &&
+		(len(l.EventNames) == 0 || slices.Contains(l.EventNames, eventName))
+}
+
+// EventTopic returns the topic the events of the tenant with the event name are
+// published to
+func (c KafkaConfig) EventTopic(tenantID, eventName string) string {
+	for _, lane := range c.Lanes {
+		if lane.Matches(tenantID, eventName) {
+			return lane.Topic
+		}
+	}
+	return c.Topic
+}
+
+// ConsumerLanes returns the lanes the consumer reads, the default lane of Topic
+// first, with their concurrency defaulted
+func (c KafkaConfig) ConsumerLanes() []KafkaLaneConfig {
+	lanes := append([]KafkaLaneConfig{{
+		Name:          "default",
+		Topic:         c.Topic,
+		ConsumerGroup: c.ConsumerGroup,
+		Concurrency:   c.Concurrency,
+	}}, c.Lanes...)
+
+	for i := range lanes {
+		if lanes[i].Concurrency <= 0 {
+			lanes[i].Concurrency = 1
+		}
+	}
+	return lanes
 }
 
+// ClickHouseConfig is required with the clickhouse event store, which the
+// request logs and the usage rollups are also kept in
 type ClickHouseConfig struct {
-	Address  string `mapstructure:"address" validate:"required"`
+	Address  string `mapstructure:"address"`
 	TLS      bool   `mapstructure:"tls"`
-	Username string `mapstructure:"username" validate:"required"`
-	Password string `mapstructure:"password" validate:"required"`
-	Database string `mapstructure:"database" validate:"required"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Database string `mapstructure:"database"`
+}
+
+// EventStoreConfig selects the database the events are stored in. The
+// timescaledb backend keeps them in the Postgres database, with or without the
+// TimescaleDB extension, for deployments that can not run ClickHouse. It counts
+// the unique values of the COUNT_UNIQUE meters exactly whatever their precision,
+// and it has no partitions to drop but with the extension
+type EventStoreConfig struct {
+	Backend types.EventStoreBackend `mapstructure:"backend"`
 }
 
 type LoggingConfig struct {
@@ -77,6 +216,311 @@ type PostgresConfig struct {
 	MaxIdleConns           int    `mapstructure:"max_idle_conns default=5"`
 	ConnMaxLifetimeMinutes int    `mapstructure:"conn_max_lifetime_minutes default=60"`
 	AutoMigrate            bool   `mapstructure:"auto_migrate default=false"`
+
+	// ReadReplicas serve the Get and List reads of the GET requests, unless they
+	// ask for consistency=strong. They are connected to with the user, password
+	// and database of the primary
+	ReadReplicas []PostgresReplicaConfig `mapstructure:"read_replicas" validate:"dive"`
+}
+
+type PostgresReplicaConfig struct {
+	Host string `mapstructure:"host" validate:"required"`
+	Port int    `mapstructure:"port" validate:"required"`
+}
+
+// ExportConfig configures the object storage bucket used for usage exports.
+// GCS is supported through its S3 interoperability API using HMAC keys.
+type ExportConfig struct {
+	Provider        types.StorageProvider `mapstructure:"provider"`
+	Bucket          string                `mapstructure:"bucket"`
+	Region          string                `mapstructure:"region"`
+	Endpoint        string                `mapstructure:"endpoint"`
+	AccessKeyID     string                `mapstructure:"access_key_id"`
+	SecretAccessKey string                `mapstructure:"secret_access_key"`
+	Prefix          string                `mapstructure:"prefix"`
+	URLExpiryMins   int                   `mapstructure:"url_expiry_mins"`
+}
+
+type BillingConfig struct {
+	// NegativeInvoiceBehavior decides how a billing run with a negative total is issued
+	NegativeInvoiceBehavior types.NegativeInvoiceBehavior `mapstructure:"negative_invoice_behavior"`
+
+	// CronIntervalMins is the default interval of the billing jobs, such as
+	// ending the trials that are due. scheduler.jobs overrides it per job
+	CronIntervalMins int `mapstructure:"cron_interval_mins"`
+
+	// TrialWillEndDays is how long before the end of a trial the trial_will_end webhook is sent
+	TrialWillEndDays int `mapstructure:"trial_will_end_days"`
+
+	Guardrails InvoiceGuardrailsConfig `mapstructure:"guardrails"`
+
+	// UsageAdjustmentTolerancePercent is how far, in percent, the amount of a usage
+	// line item of a draft invoice may be edited away from its metered amount
+	UsageAdjustmentTolerancePercent float64 `mapstructure:"usage_adjustment_tolerance_percent"`
+
+	// LateEventsLookbackDays is how long after the end of its period a finalized
+	// invoice is watched for late events
+	LateEventsLookbackDays int `mapstructure:"late_events_lookback_days"`
+
+	// UsageLockDelayHours is how long after the end of a period its usage is
+	// locked and invoiced, so that the events arriving late are billed with it
+	UsageLockDelayHours int `mapstructure:"usage_lock_delay_hours"`
+}
+
+// InvoiceGuardrailsConfig holds generated invoices whose total looks off for
+// review instead of leaving them ready to be finalized
+type InvoiceGuardrailsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// MaxDeviationPercent is how far, in percent, the total may deviate from the
+	// average of the trailing invoices of the customer
+	MaxDeviationPercent float64 `mapstructure:"max_deviation_percent"`
+
+	// TrailingInvoices is the number of last finalized invoices averaged, the
+	// deviation is not checked for customers without finalized invoices
+	TrailingInvoices int `mapstructure:"trailing_invoices"`
+
+	// MaxTotal caps the total of an invoice per lowercase currency code
+	MaxTotal map[string]float64 `mapstructure:"max_total"`
+}
+
+// IntegrationConfig configures the outbound sync queue shared by all integrations.
+// The rate limit applies per connection unless the connection overrides it
+type IntegrationConfig struct {
+	SyncRatePerSecond float64 `mapstructure:"sync_rate_per_second"`
+	SyncBurst         int     `mapstructure:"sync_burst"`
+	SyncMaxAttempts   int     `mapstructure:"sync_max_attempts"`
+	SyncBackoffMillis int     `mapstructure:"sync_backoff_millis"`
+}
+
+// WebhookConfig configures outbound webhook delivery. Endpoints are managed
+// through the API
+type WebhookConfig struct {
+	PreHooks           []PreHookConfig `mapstructure:"pre_hooks"`
+	MaxAttempts        int             `mapstructure:"max_attempts"`
+	BackoffBaseSecs    int             `mapstructure:"backoff_base_secs"`
+	BackoffMaxSecs     int             `mapstructure:"backoff_max_secs"`
+	TimeoutSecs        int             `mapstructure:"timeout_secs"`
+	PollIntervalMillis int             `mapstructure:"poll_interval_millis"`
+}
+
+// EmailConfig configures the emails sent to customers, such as finalized
+// invoices. No emails are sent unless a provider is configured. Templates
+// are managed through the API
+type EmailConfig struct {
+	Provider        types.EmailProvider `mapstructure:"provider"`
+	FromAddress     string              `mapstructure:"from_address"`
+	FromName        string              `mapstructure:"from_name"`
+	MaxAttempts     int                 `mapstructure:"max_attempts"`
+	BackoffBaseSecs int                 `mapstructure:"backoff_base_secs"`
+	BackoffMaxSecs  int                 `mapstructure:"backoff_max_secs"`
+	SMTP            SMTPConfig          `mapstructure:"smtp"`
+	SES             SESConfig           `mapstructure:"ses"`
+	SendGrid        SendGridConfig      `mapstructure:"sendgrid"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// SESConfig falls back to the default AWS credentials when no access key is set
+type SESConfig struct {
+	Region          string `mapstructure:"region"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+}
+
+type SendGridConfig struct {
+	APIKey string `mapstructure:"api_key"`
+}
+
+// PreHookConfig is an HTTPS callback of a tenant that is called synchronously
+// before the listed operations and can deny them
+type PreHookConfig struct {
+	ID            string   `mapstructure:"id"`
+	TenantID      string   `mapstructure:"tenant_id"`
+	URL           string   `mapstructure:"url"`
+	Secret        string   `mapstructure:"secret"`
+	Operations    []string `mapstructure:"operations"`
+	TimeoutMillis int      `mapstructure:"timeout_millis"`
+	// FailOpen allows the operation when the hook times out or errors,
+	// otherwise the operation is denied
+	FailOpen bool `mapstructure:"fail_open"`
+}
+
+// AnomalyConfig configures the usage anomaly detection job. Every interval the
+// last complete window of usage of each customer on each meter is compared with
+// the previous baseline_windows windows and flagged when it is more than
+// spike_sigma standard deviations above or drop_sigma below their mean
+type AnomalyConfig struct {
+	IntervalMins       int              `mapstructure:"interval_mins"`
+	WindowSize         types.WindowSize `mapstructure:"window_size"`
+	BaselineWindows    int              `mapstructure:"baseline_windows"`
+	MinBaselineWindows int              `mapstructure:"min_baseline_windows"`
+	SpikeSigma         float64          `mapstructure:"spike_sigma"`
+	DropSigma          float64          `mapstructure:"drop_sigma"`
+}
+
+// RequestLogConfig configures the developer request logs of API key requests.
+// Bodies are redacted and cut at max_body_bytes. Logs are kept for 7 days by
+// the TTL of the ClickHouse table
+type RequestLogConfig struct {
+	Enabled      bool `mapstructure:"enabled"`
+	MaxBodyBytes int  `mapstructure:"max_body_bytes"`
+}
+
+// AdminConfig configures the operator APIs under /admin. They are authenticated
+// with api_key in the X-Admin-Key header and disabled when no key is set
+type AdminConfig struct {
+	APIKey string `mapstructure:"api_key"`
+}
+
+// EventRetentionConfig configures the event retention job. Every interval the
+// events of each tenant older than its TTL, or default_ttl_days for tenants
+// without a policy, are deleted and the monthly partitions past the longest TTL
+// are dropped. A TTL of 0 keeps the events forever
+type EventRetentionConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	DefaultTTLDays int  `mapstructure:"default_ttl_days"`
+	IntervalMins   int  `mapstructure:"interval_mins"`
+}
+
+// UsageRollupConfig configures the usage rollup job. Every interval the hourly
+// and daily usage of each customer on each meter is rolled up until
+// late_arrival_mins ago, recomputing the last lookback_hours so that late events
+// are included. Meter usage over the rolled up windows is read from the rollups
+type UsageRollupConfig struct {
+	Enabled         bool `mapstructure:"enabled"`
+	IntervalMins    int  `mapstructure:"interval_mins"`
+	LateArrivalMins int  `mapstructure:"late_arrival_mins"`
+	LookbackHours   int  `mapstructure:"lookback_hours"`
+}
+
+// SoftDeleteConfig configures the purge of the deleted plans, prices and
+// customers. Every interval the records deleted for longer than
+// purge_after_days are removed for good, until then they can be restored.
+// A purge_after_days of 0 keeps them forever
+type SoftDeleteConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	PurgeAfterDays int  `mapstructure:"purge_after_days"`
+	IntervalMins   int  `mapstructure:"interval_mins"`
+}
+
+// EventBackfillConfig configures the backfills of historical events. Backfilled
+// events are written straight to the events store, not through the live
+// consumer, in batches of batch_size at most events_per_second across all the
+// backfills of the process. A request carries at most max_inline_events events,
+// larger backfills are read from a file in object storage
+type EventBackfillConfig struct {
+	EventsPerSecond int `mapstructure:"events_per_second"`
+	BatchSize       int `mapstructure:"batch_size"`
+	MaxInlineEvents int `mapstructure:"max_inline_events"`
+}
+
+// SchedulerConfig overrides the schedule of the background jobs by job name.
+// Jobs without an entry keep the schedule of their own config section, ex the
+// interval of the anomaly job is anomaly.interval_mins
+type SchedulerConfig struct {
+	Jobs map[string]JobConfig `mapstructure:"jobs"`
+}
+
+// JobConfig is the schedule of a background job. BatchSize and Concurrency are
+// how many items a run hands out at a time and processes at once, for the jobs
+// which process items one by one
+type JobConfig struct {
+	Enabled      *bool `mapstructure:"enabled"`
+	IntervalMins int   `mapstructure:"interval_mins"`
+	BatchSize    int   `mapstructure:"batch_size"`
+	Concurrency  int   `mapstructure:"concurrency"`
+}
+
+// SimulationConfig turns the clock of the billing flows into a simulated clock
+// which the operator APIs move forward, to run months of renewals in minutes.
+// It is meant for sandbox deployments only and requires the local mode, as the
+// jobs run by the process are all run at the time of its clock. The clock is the
+// one of the sandbox environment EnvironmentID of TenantID, which persists it
+type SimulationConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	TenantID      string `mapstructure:"tenant_id"`
+	EnvironmentID string `mapstructure:"environment_id"`
+}
+
+type RedisConfig struct {
+	Address  string `mapstructure:"address"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
+// UsageStreamConfig configures the live usage streams. The consumer publishes each
+// ingested event to the streams of its customer through the backend, streams
+// send a keep alive comment every keep_alive_secs
+type UsageStreamConfig struct {
+	Enabled       bool                     `mapstructure:"enabled"`
+	Backend       types.UsageStreamBackend `mapstructure:"backend"`
+	KeepAliveSecs int                      `mapstructure:"keep_alive_secs"`
+}
+
+// RateLimitConfig configures the request quotas enforced with token buckets kept
+// in Redis. Requests are limited per tenant and, when authenticated with an API
+// key, per key. Event ingestion and the management APIs have separate limits.
+// Tenant overrides replace the defaults for the listed tenants
+type RateLimitConfig struct {
+	Enabled         bool                `mapstructure:"enabled"`
+	Ingest          RateLimitQuotas     `mapstructure:"ingest"`
+	Management      RateLimitQuotas     `mapstructure:"management"`
+	TenantOverrides []RateLimitOverride `mapstructure:"tenant_overrides"`
+}
+
+type RateLimitQuotas struct {
+	Tenant RateLimit `mapstructure:"tenant"`
+	APIKey RateLimit `mapstructure:"api_key"`
+}
+
+// RateLimit is a token bucket refilled at rate_per_second up to burst tokens.
+// A zero rate disables the limit
+type RateLimit struct {
+	RatePerSecond float64 `mapstructure:"rate_per_second"`
+	Burst         int     `mapstructure:"burst"`
+}
+
+// RateLimitOverride replaces the default quotas of a tenant. Limits left at zero
+// keep the default
+type RateLimitOverride struct {
+	TenantID   string          `mapstructure:"tenant_id"`
+	Ingest     RateLimitQuotas `mapstructure:"ingest"`
+	Management RateLimitQuotas `mapstructure:"management"`
+}
+
+// QuotasFor returns the quotas of the tenant for the class of requests
+func (c RateLimitConfig) QuotasFor(tenantID string, class types.RateLimitClass) RateLimitQuotas {
+	quotas := c.Management
+	if class == types.RateLimitClassIngest {
+		quotas = c.Ingest
+	}
+
+	for _, override := range c.TenantOverrides {
+		if override.TenantID != tenantID {
+			continue
+		}
+
+		tenantQuotas := override.Management
+		if class == types.RateLimitClassIngest {
+			tenantQuotas = override.Ingest
+		}
+
+		if tenantQuotas.Tenant.RatePerSecond > 0 {
+			quotas.Tenant = tenantQuotas.Tenant
+		}
+		if tenantQuotas.APIKey.RatePerSecond > 0 {
+			quotas.APIKey = tenantQuotas.APIKey
+		}
+		break
+	}
+
+	return quotas
 }
 
 func NewConfig() (*Configuration, error) {
@@ -118,12 +562,51 @@ func NewConfig() (*Configuration, error) {
 		return nil, err
 	}
 
+	if err := types.SetIDStrategy(config.Deployment.IDStrategy); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
 func (c Configuration) Validate() error {
 	validate := validator.New()
-	return validate.Struct(c)
+	if err := validate.Struct(c); err != nil {
+		return err
+	}
+
+	switch c.Queue.Backend {
+	case types.QueueBackendKafka, "":
+		if len(c.Kafka.Brokers) == 0 {
+			return fmt.Errorf("kafka brokers are required with the %s queue backend", types.QueueBackendKafka)
+		}
+	case types.QueueBackendPostgres:
+	default:
+		return fmt.Errorf("unknown queue backend: %s", c.Queue.Backend)
+	}
+
+	switch c.EventStore.Backend {
+	case types.EventStoreBackendClickHouse, "":
+		if c.ClickHouse.Address == "" || c.ClickHouse.Username == "" || c.ClickHouse.Password == "" || c.ClickHouse.Database == "" {
+			return fmt.Errorf("clickhouse address, username, password and database are required with the %s event store", types.EventStoreBackendClickHouse)
+		}
+	case types.EventStoreBackendTimescaleDB:
+		// The request logs and the usage rollups are only kept in ClickHouse
+		if c.RequestLog.Enabled || c.UsageRollup.Enabled {
+			return fmt.Errorf("request logs and usage rollups require the %s event store", types.EventStoreBackendClickHouse)
+		}
+	default:
+		return fmt.Errorf("unknown event store backend: %s", c.EventStore.Backend)
+	}
+
+	if c.Simulation.Enabled && c.Deployment.Mode != types.ModeLocal {
+		return fmt.Errorf("simulation requires the %s deployment mode", types.ModeLocal)
+	}
+	if c.Simulation.Enabled && (c.Simulation.TenantID == "" || c.Simulation.EnvironmentID == "") {
+		return fmt.Errorf("simulation requires the tenant and the sandbox environment of its clock")
+	}
+
+	return nil
 }
 
 // GetDefaultConfig returns a default configuration for local development
@@ -132,6 +615,7 @@ func GetDefaultConfig() *Configuration {
 	return &Configuration{
 		Deployment: DeploymentConfig{Mode: types.ModeLocal},
 		Logging:    LoggingConfig{Level: types.LogLevelDebug},
+		Kafka:      KafkaConfig{Topic: "events"},
 	}
 }
 
@@ -152,13 +636,22 @@ func (c ClickHouseConfig) GetClientOptions() *clickhouse.Options {
 }
 
 func (c PostgresConfig) GetDSN() string {
+	return c.dsn(c.Host, c.Port)
+}
+
+// GetReplicaDSN returns the DSN of a read replica
+func (c PostgresConfig) GetReplicaDSN(replica PostgresReplicaConfig) string {
+	return c.dsn(replica.Host, replica.Port)
+}
+
+func (c PostgresConfig) dsn(host string, port int) string {
 	return fmt.Sprintf(
 		"user=%s password=%s dbname=%s host=%s port=%d sslmode=%s",
 		c.User,
 		c.Password,
 		c.DBName,
-		c.Host,
-		c.Port,
+		host,
+		port,
 		c.SSLMode,
 	)
 }
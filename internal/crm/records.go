@@ -0,0 +1,105 @@
+package crm
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ObjectAccount = "Account"
+
+	salesforceDate = "2006-01-02"
+)
+
+// InvoiceTotals of a customer across its finalized invoices
+type InvoiceTotals struct {
+	Currency        string
+	TotalInvoiced   decimal.Decimal
+	AmountDue       decimal.Decimal
+	LastInvoiceDate *time.Time
+}
+
+// AccountFields maps a customer to the fields of its Account
+func AccountFields(cust *customer.Customer) map[string]interface{} {
+	fields := map[string]interface{}{
+		"Name": cust.Name,
+	}
+	if cust.BillingAddress != nil {
+		addAddress(fields, "Billing", cust.BillingAddress)
+	}
+	if cust.ShippingAddress != nil {
+		addAddress(fields, "Shipping", cust.ShippingAddress)
+	}
+	return fields
+}
+
+func addAddress(fields map[string]interface{}, prefix string, addr *customer.Address) {
+	street := addr.Line1
+	if addr.Line2 != "" {
+		street += "\n" + addr.Line2
+	}
+	fields[prefix+"Street"] = street
+	fields[prefix+"City"] = addr.City
+	fields[prefix+"State"] = addr.State
+	fields[prefix+"PostalCode"] = addr.PostalCode
+	fields[prefix+"Country"] = addr.Country
+}
+
+// SubscriptionObject returns the object the subscriptions are synced to
+func SubscriptionObject(settings *connection.CRMSettings) string {
+	if settings.SubscriptionObject == "" {
+		return string(types.CRMSubscriptionObjectOpportunity)
+	}
+	return string(settings.SubscriptionObject)
+}
+
+// SubscriptionFields maps a subscription of the plan to the fields of its
+// Opportunity or Contract. The record is linked to the Account of the
+// customer through its external ID
+func SubscriptionFields(settings *connection.CRMSettings, sub *subscription.Subscription, planName string) map[string]interface{} {
+	account := map[string]interface{}{ExternalIDField: sub.CustomerID}
+
+	if SubscriptionObject(settings) == string(types.CRMSubscriptionObjectContract) {
+		fields := map[string]interface{}{
+			"Account":     account,
+			"StartDate":   sub.StartDate.Format(salesforceDate),
+			"Description": planName,
+		}
+		if sub.EndDate != nil {
+			fields["EndDate"] = sub.EndDate.Format(salesforceDate)
+		}
+		return fields
+	}
+
+	stage := "Closed Won"
+	if sub.SubscriptionStatus == types.SubscriptionStatusCancelled {
+		stage = "Closed Lost"
+	}
+	return map[string]interface{}{
+		"Account":   account,
+		"Name":      planName,
+		"StageName": stage,
+		"CloseDate": sub.StartDate.Format(salesforceDate),
+	}
+}
+
+// InvoiceTotalsFields maps the invoice totals of a customer to the configured
+// fields of its Account. Fields without a name are left out
+func InvoiceTotalsFields(settings *connection.CRMSettings, totals *InvoiceTotals) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if f := settings.InvoiceFields.TotalInvoiced; f != "" {
+		fields[f], _ = totals.TotalInvoiced.Float64()
+	}
+	if f := settings.InvoiceFields.AmountDue; f != "" {
+		fields[f], _ = totals.AmountDue.Float64()
+	}
+	if f := settings.InvoiceFields.LastInvoiceDate; f != "" && totals.LastInvoiceDate != nil {
+		fields[f] = totals.LastInvoiceDate.Format(salesforceDate)
+	}
+	return fields
+}
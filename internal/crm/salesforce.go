@@ -0,0 +1,111 @@
+// Package crm pushes customers, subscriptions and invoice totals to the
+// Salesforce orgs of the integration connections
+package crm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const salesforceAPIVersion = "v59.0"
+
+// ExternalIDField is the custom external ID field, on every synced object,
+// that holds the Flexprice ID of the record. Records are upserted on it so
+// that a retried sync never creates a duplicate
+const ExternalIDField = "Flexprice_ID__c"
+
+// Client upserts records in a CRM
+type Client interface {
+	// Upsert creates or updates the record of the object with the Flexprice ID
+	// and returns its ID in the CRM
+	Upsert(ctx context.Context, object, flexpriceID string, fields map[string]interface{}) (string, error)
+}
+
+// NewClient returns the client of the CRM of the connection
+func NewClient(conn *connection.Connection) (Client, error) {
+	if conn.Provider != types.IntegrationProviderSalesforce {
+		return nil, fmt.Errorf("provider %s is not a crm", conn.Provider)
+	}
+	if conn.CRMSettings == nil {
+		return nil, fmt.Errorf("connection %s has no crm settings", conn.ID)
+	}
+	if conn.Credentials == "" {
+		return nil, fmt.Errorf("connection %s has no credentials", conn.ID)
+	}
+	return newSalesforceClient(conn.CRMSettings.InstanceURL, conn.Credentials), nil
+}
+
+type salesforceClient struct {
+	baseURL     string
+	accessToken string
+	client      *http.Client
+}
+
+func newSalesforceClient(instanceURL, accessToken string) *salesforceClient {
+	return &salesforceClient{
+		baseURL:     strings.TrimRight(instanceURL, "/") + "/services/data/" + salesforceAPIVersion,
+		accessToken: accessToken,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type salesforceUpsertResponse struct {
+	ID      string `json:"id"`
+	Created bool   `json:"created"`
+}
+
+func (c *salesforceClient) Upsert(ctx context.Context, object, flexpriceID string, fields map[string]interface{}) (string, error) {
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode salesforce %s: %w", object, err)
+	}
+
+	endpoint := fmt.Sprintf("%s/sobjects/%s/%s/%s", c.baseURL, object, ExternalIDField, url.PathEscape(flexpriceID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upsert salesforce %s: %w", object, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	// Salesforce answers 403 REQUEST_LIMIT_EXCEEDED once the org is out of API calls
+	if resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && bytes.Contains(respBody, []byte("REQUEST_LIMIT_EXCEEDED"))) {
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return "", &syncqueue.RateLimitError{RetryAfter: retryAfter}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("salesforce responded with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	// Updates answer 204 without a body on older API versions
+	var result salesforceUpsertResponse
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return "", fmt.Errorf("failed to decode salesforce response: %w", err)
+		}
+	}
+	return result.ID, nil
+}
@@ -0,0 +1,87 @@
+package crm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSalesforceClient_Upsert(t *testing.T) {
+	var method, path string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"001D000000IqhSLIAZ","created":true}`))
+	}))
+	defer server.Close()
+
+	client := newSalesforceClient(server.URL+"/", "access-token")
+	id, err := client.Upsert(context.Background(), ObjectAccount, "cust_1", map[string]interface{}{"Name": "Acme"})
+	require.NoError(t, err)
+	assert.Equal(t, "001D000000IqhSLIAZ", id)
+	assert.Equal(t, http.MethodPatch, method)
+	assert.Equal(t, "/services/data/v59.0/sobjects/Account/Flexprice_ID__c/cust_1", path)
+	assert.Equal(t, "Acme", body["Name"])
+}
+
+func TestSalesforceClient_RequestLimitExceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`[{"errorCode":"REQUEST_LIMIT_EXCEEDED","message":"TotalRequests Limit exceeded."}]`))
+	}))
+	defer server.Close()
+
+	client := newSalesforceClient(server.URL, "access-token")
+	_, err := client.Upsert(context.Background(), ObjectAccount, "cust_1", nil)
+	_, ok := syncqueue.IsRateLimited(err)
+	assert.True(t, ok)
+}
+
+func TestRecordFields(t *testing.T) {
+	cust := &customer.Customer{
+		ID:             "cust_1",
+		Name:           "Acme",
+		BillingAddress: &customer.Address{Line1: "1 Main St", Line2: "Suite 2", City: "Berlin", Country: "DE"},
+	}
+	account := AccountFields(cust)
+	assert.Equal(t, "Acme", account["Name"])
+	assert.Equal(t, "1 Main St\nSuite 2", account["BillingStreet"])
+	assert.NotContains(t, account, "ShippingCity")
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	sub := &subscription.Subscription{ID: "sub_1", CustomerID: "cust_1", StartDate: start, SubscriptionStatus: types.SubscriptionStatusCancelled}
+
+	settings := &connection.CRMSettings{}
+	assert.Equal(t, "Opportunity", SubscriptionObject(settings))
+	opportunity := SubscriptionFields(settings, sub, "Pro")
+	assert.Equal(t, "Closed Lost", opportunity["StageName"])
+	assert.Equal(t, "2024-03-01", opportunity["CloseDate"])
+	assert.Equal(t, map[string]interface{}{ExternalIDField: "cust_1"}, opportunity["Account"])
+
+	settings.SubscriptionObject = types.CRMSubscriptionObjectContract
+	contract := SubscriptionFields(settings, sub, "Pro")
+	assert.Equal(t, "2024-03-01", contract["StartDate"])
+	assert.NotContains(t, contract, "StageName")
+
+	settings.InvoiceFields = connection.CRMInvoiceFields{TotalInvoiced: "Flexprice_Total_Invoiced__c", LastInvoiceDate: "Flexprice_Last_Invoice_Date__c"}
+	totals := InvoiceTotalsFields(settings, &InvoiceTotals{TotalInvoiced: decimal.NewFromFloat(120.5), AmountDue: decimal.NewFromInt(20), LastInvoiceDate: &start})
+	assert.Equal(t, map[string]interface{}{
+		"Flexprice_Total_Invoiced__c":    120.5,
+		"Flexprice_Last_Invoice_Date__c": "2024-03-01",
+	}, totals)
+}
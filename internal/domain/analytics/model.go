@@ -0,0 +1,27 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// RevenueSnapshot is the monthly recurring revenue of a subscription in a closed
+// month, materialized so that the revenue analytics of past months are not
+// computed from the invoices again
+type RevenueSnapshot struct {
+	ID string `db:"id" json:"id"`
+
+	// Month is the first instant of the calendar month in UTC
+	Month          time.Time `db:"month" json:"month"`
+	SubscriptionID string    `db:"subscription_id" json:"subscription_id"`
+	CustomerID     string    `db:"customer_id" json:"customer_id"`
+	PlanID         string    `db:"plan_id" json:"plan_id"`
+
+	// Segment is the segment of the customer when the month was snapshotted
+	Segment  string          `db:"segment" json:"segment"`
+	Currency string          `db:"currency" json:"currency"`
+	MRR      decimal.Decimal `db:"mrr" json:"mrr"`
+	types.BaseModel
+}
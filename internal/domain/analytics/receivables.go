@@ -0,0 +1,44 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// ReceivablesSnapshot is the amount a customer owed in a currency on a day,
+// by age of the invoices, as recorded by the daily receivables snapshot job
+type ReceivablesSnapshot struct {
+	ID string `db:"id" json:"id"`
+
+	// SnapshotDate is the first instant of the day in UTC
+	SnapshotDate time.Time `db:"snapshot_date" json:"snapshot_date"`
+	CustomerID   string    `db:"customer_id" json:"customer_id"`
+	Currency     string    `db:"currency" json:"currency"`
+
+	Current    decimal.Decimal `db:"current" json:"current"`
+	Days30     decimal.Decimal `db:"days_30" json:"days_30"`
+	Days60     decimal.Decimal `db:"days_60" json:"days_60"`
+	Days90Plus decimal.Decimal `db:"days_90_plus" json:"days_90_plus"`
+	Total      decimal.Decimal `db:"total" json:"total"`
+
+	// Invoices is the number of outstanding invoices
+	Invoices int `db:"invoices" json:"invoices"`
+	types.BaseModel
+}
+
+type ReceivablesRepository interface {
+	// ReplaceDay replaces the snapshots of the day and records it as
+	// snapshotted with the invoices aged at asOf, even when nothing was
+	// outstanding
+	ReplaceDay(ctx context.Context, day, asOf time.Time, snapshots []*ReceivablesSnapshot) error
+
+	// GetSnapshotAsOf returns when the invoices of the snapshot of the day were
+	// aged, nil when the day was not snapshotted
+	GetSnapshotAsOf(ctx context.Context, day time.Time) (*time.Time, error)
+
+	// List returns the snapshots of the day, of the customer when customerID is set
+	List(ctx context.Context, day time.Time, customerID string) ([]*ReceivablesSnapshot, error)
+}
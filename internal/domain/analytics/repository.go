@@ -0,0 +1,18 @@
+package analytics
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// ReplaceMonth replaces the snapshots of the month and records it as
+	// snapshotted, even when it has no snapshots
+	ReplaceMonth(ctx context.Context, month time.Time, snapshots []*RevenueSnapshot) error
+
+	// ListMonths returns the snapshotted months in [start, end)
+	ListMonths(ctx context.Context, start, end time.Time) ([]time.Time, error)
+
+	// List returns the snapshots of the months in [start, end)
+	List(ctx context.Context, start, end time.Time) ([]*RevenueSnapshot, error)
+}
@@ -0,0 +1,30 @@
+package anomaly
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Anomaly is a window of usage of a customer on a meter that is further from the
+// baseline of the customer than the configured number of standard deviations
+type Anomaly struct {
+	ID                 string            `db:"id" json:"id"`
+	MeterID            string            `db:"meter_id" json:"meter_id"`
+	EventName          string            `db:"event_name" json:"event_name"`
+	ExternalCustomerID string            `db:"external_customer_id" json:"external_customer_id"`
+	AnomalyType        types.AnomalyType `db:"anomaly_type" json:"anomaly_type"`
+	WindowStart        time.Time         `db:"window_start" json:"window_start"`
+	WindowEnd          time.Time         `db:"window_end" json:"window_end"`
+
+	// Value is the usage in the window, Baseline and StdDev the mean and standard
+	// deviation of the usage in the windows before it
+	Value    decimal.Decimal `db:"value" json:"value"`
+	Baseline decimal.Decimal `db:"baseline" json:"baseline"`
+	StdDev   decimal.Decimal `db:"std_dev" json:"std_dev"`
+
+	// Sigma is the signed distance of the value from the baseline in standard deviations
+	Sigma decimal.Decimal `db:"sigma" json:"sigma"`
+	types.BaseModel
+}
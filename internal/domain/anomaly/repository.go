@@ -0,0 +1,16 @@
+package anomaly
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	Create(ctx context.Context, anomaly *Anomaly) error
+	List(ctx context.Context, filter *types.AnomalyFilter) ([]*Anomaly, error)
+
+	// Exists reports whether an anomaly was already recorded for the window
+	Exists(ctx context.Context, meterID, externalCustomerID string, windowStart time.Time) (bool, error)
+}
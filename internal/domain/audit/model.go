@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Log records who changed an entity, how and when. Logs are append only
+type Log struct {
+	ID            string `db:"id" json:"id"`
+	TenantID      string `db:"tenant_id" json:"tenant_id"`
+	EnvironmentID string `db:"environment_id" json:"environment_id,omitempty"`
+
+	EntityType types.AuditEntityType `db:"entity_type" json:"entity_type"`
+	EntityID   string                `db:"entity_id" json:"entity_id"`
+	Action     types.AuditAction     `db:"action" json:"action"`
+
+	// ActorType and ActorID are the user or the API key that made the change.
+	// System changes have no actor ID
+	ActorType types.AuditActorType `db:"actor_type" json:"actor_type"`
+	ActorID   string               `db:"actor_id" json:"actor_id,omitempty"`
+	RequestID string               `db:"request_id" json:"request_id,omitempty"`
+
+	// Changes maps the changed fields to their value before and after the change,
+	// see Change. Fields hidden from the API are never recorded
+	Changes json.RawMessage `db:"changes" json:"changes" swaggertype:"object"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Change is the value of a field before and after a change. Before is empty on
+// create and After on delete
+type Change struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
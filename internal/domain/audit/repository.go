@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	// Insert stores a log for the tenant and environment of the log rather than
+	// the ones of ctx
+	Insert(ctx context.Context, log *Log) error
+	List(ctx context.Context, filter *types.AuditLogFilter) ([]*Log, error)
+}
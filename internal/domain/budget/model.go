@@ -0,0 +1,65 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Budget caps the usage charges of a customer, or of a single subscription of
+// the customer, over a month or billing period. Its action is taken when the
+// spend exceeds the amount and lifted once the spend of a new window is back
+// under it
+type Budget struct {
+	ID         string `db:"id" json:"id"`
+	Name       string `db:"name" json:"name"`
+	CustomerID string `db:"customer_id" json:"customer_id"`
+
+	// SubscriptionID restricts the budget to one subscription of the customer.
+	// Empty for budgets covering all the subscriptions of the customer
+	SubscriptionID string `db:"subscription_id" json:"subscription_id,omitempty"`
+
+	Amount   decimal.Decimal    `db:"amount" json:"amount" swaggertype:"string"`
+	Currency string             `db:"currency" json:"currency"`
+	Period   types.BudgetPeriod `db:"period" json:"period"`
+	Action   types.BudgetAction `db:"action" json:"action"`
+
+	// Spend is the usage charges of the window as of EvaluatedAt. Subscriptions
+	// in another currency than the budget are left out
+	Spend       decimal.Decimal `db:"spend" json:"spend" swaggertype:"string"`
+	EvaluatedAt *time.Time      `db:"evaluated_at" json:"evaluated_at,omitempty"`
+	// ExceededAt is when the spend went over the amount, nil while it is under
+	ExceededAt *time.Time `db:"exceeded_at" json:"exceeded_at,omitempty"`
+
+	types.BaseModel
+}
+
+// Covers reports whether the usage of the subscription counts towards the budget
+func (b *Budget) Covers(subscriptionID string) bool {
+	return b.SubscriptionID == "" || b.SubscriptionID == subscriptionID
+}
+
+// WindowStart is when the spend of the subscription starts counting towards the
+// budget at the given time
+func (b *Budget) WindowStart(sub *subscription.Subscription, now time.Time) time.Time {
+	// A subscription that is not renewed, as while it is paused, starts its next
+	// period at the end of the current one
+	periodStart := sub.CurrentPeriodStart
+	if !now.Before(sub.CurrentPeriodEnd) {
+		periodStart = sub.CurrentPeriodEnd
+	}
+
+	if b.Period == types.BudgetPeriodBillingPeriod {
+		return periodStart
+	}
+
+	// Usage of the month before the current period was billed with the previous
+	// one and is left out
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if periodStart.After(monthStart) {
+		return periodStart
+	}
+	return monthStart
+}
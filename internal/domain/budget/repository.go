@@ -0,0 +1,14 @@
+package budget
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, b *Budget) error
+	Get(ctx context.Context, id string) (*Budget, error)
+	// ListByCustomer returns the budgets of the customer, including the ones of
+	// its subscriptions
+	ListByCustomer(ctx context.Context, customerID string) ([]*Budget, error)
+	List(ctx context.Context) ([]*Budget, error)
+	Update(ctx context.Context, b *Budget) error
+	Delete(ctx context.Context, id string) error
+}
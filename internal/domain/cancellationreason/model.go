@@ -0,0 +1,27 @@
+package cancellationreason
+
+import "github.com/flexprice/flexprice/internal/types"
+
+// CancellationReason is a category of the tenant's taxonomy that customers pick
+// from when they cancel a subscription
+type CancellationReason struct {
+	ID string `db:"id" json:"id"`
+
+	// Code is the stable identifier recorded on cancelled subscriptions
+	Code        string `db:"code" json:"code"`
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+	types.BaseModel
+}
+
+// Defaults is the taxonomy of tenants that have not configured their own reasons
+var Defaults = []CancellationReason{
+	{Code: "too_expensive", Name: "Too expensive"},
+	{Code: "missing_features", Name: "Missing features"},
+	{Code: "switched_service", Name: "Switched to another service"},
+	{Code: "unused", Name: "Not using it enough"},
+	{Code: "customer_service", Name: "Customer service was less than expected"},
+	{Code: "low_quality", Name: "Quality was less than expected"},
+	{Code: "too_complex", Name: "Too complex to use"},
+	{Code: "other", Name: "Other"},
+}
@@ -0,0 +1,11 @@
+package cancellationreason
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, reason *CancellationReason) error
+	Get(ctx context.Context, id string) (*CancellationReason, error)
+	// List returns all the published reasons of the tenant ordered by code
+	List(ctx context.Context) ([]*CancellationReason, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,267 @@
+package connection
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Connection is a tenant's link to an external system such as Stripe or HubSpot
+type Connection struct {
+	ID       string                    `db:"id" json:"id"`
+	Name     string                    `db:"name" json:"name"`
+	Provider types.IntegrationProvider `db:"provider" json:"provider"`
+
+	// RateLimitPerSecond caps outbound calls to the provider for this connection.
+	// Zero uses the configured default
+	RateLimitPerSecond float64 `db:"rate_limit_per_second" json:"rate_limit_per_second"`
+
+	// LedgerSettings configure the NetSuite and QuickBooks connections
+	LedgerSettings *LedgerSettings `db:"ledger_settings" json:"ledger_settings,omitempty"`
+
+	// CRMSettings configure the Salesforce connections
+	CRMSettings *CRMSettings `db:"crm_settings" json:"crm_settings,omitempty"`
+
+	// GatewaySettings configure the Adyen and Razorpay connections
+	GatewaySettings *GatewaySettings `db:"gateway_settings" json:"gateway_settings,omitempty"`
+
+	// TaxSettings configure the Avalara and TaxJar connections
+	TaxSettings *TaxSettings `db:"tax_settings" json:"tax_settings,omitempty"`
+
+	// Credentials is the OAuth 2.0 access token of the connection, the secret
+	// API key of the payment gateways or the Avalara license key and TaxJar API
+	// token of the tax providers. It is never returned
+	Credentials string `db:"credentials" json:"-"`
+
+	// WebhookSecret is the secret the payment gateway signs its webhooks with. It
+	// is never returned
+	WebhookSecret string `db:"webhook_secret" json:"-"`
+	types.BaseModel
+}
+
+// GatewaySettings identify the account of a payment gateway beyond its API key
+type GatewaySettings struct {
+	// MerchantAccount is the Adyen merchant account the payments are made to
+	MerchantAccount string `json:"merchant_account,omitempty"`
+
+	// LiveURLPrefix is the prefix of the live endpoints of the Adyen company
+	// account ex 1797a841fbb37ca7-AdyenDemo. The test endpoints are used without one
+	LiveURLPrefix string `json:"live_url_prefix,omitempty"`
+
+	// KeyID is the Razorpay key ID, its key secret is the credentials
+	KeyID string `json:"key_id,omitempty"`
+}
+
+// TaxSettings configure how the invoices are taxed by a tax provider
+type TaxSettings struct {
+	// AccountID is the Avalara account ID, its license key is the credentials
+	AccountID string `json:"account_id,omitempty"`
+
+	// CompanyCode is the Avalara company the transactions are recorded in
+	CompanyCode string `json:"company_code,omitempty"`
+
+	// Sandbox sends the requests to the sandbox of the provider
+	Sandbox bool `json:"sandbox"`
+
+	// ShipFrom is the address the tenant sells from. Invoices are taxed at the
+	// billing address of the customer
+	ShipFrom *customer.Address `json:"ship_from" validate:"required"`
+
+	// DefaultTaxCode is the product tax code of the line items whose price has
+	// none ex SW054000 for Avalara or 81162000A9000 for TaxJar. Without one the
+	// line items are taxed as tangible goods
+	DefaultTaxCode string `json:"default_tax_code,omitempty"`
+
+	// TaxCodes maps price IDs to product tax codes
+	TaxCodes map[string]string `json:"tax_codes,omitempty"`
+
+	// FallbackRates tax the invoices when the provider is unavailable, so that
+	// invoicing does not stop with it
+	FallbackRates []FallbackTaxRate `json:"fallback_rates,omitempty" validate:"dive"`
+}
+
+// FallbackTaxRate is the internal rate of a country, or of a state of it, used
+// when the tax provider is unavailable
+type FallbackTaxRate struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country ex US
+	Country string `json:"country" validate:"required,iso3166_1_alpha2"`
+	// State restricts the rate to a state of the country ex CA, the rates without
+	// one apply to the rest of the country
+	State       string          `json:"state,omitempty"`
+	RatePercent decimal.Decimal `json:"rate_percent" swaggertype:"string" example:"8.25"`
+	// DisplayName is the name of the tax line items, Tax by default
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+// TaxCode returns the product tax code of the price
+func (s TaxSettings) TaxCode(priceID string) string {
+	if code, ok := s.TaxCodes[priceID]; ok && priceID != "" {
+		return code
+	}
+	return s.DefaultTaxCode
+}
+
+// FallbackRate returns the fallback rate of the address: the rate of its state,
+// else the rate of its country. Nil when neither has one
+func (s TaxSettings) FallbackRate(address *customer.Address) *FallbackTaxRate {
+	if address == nil {
+		return nil
+	}
+
+	var countryRate *FallbackTaxRate
+	for i, rate := range s.FallbackRates {
+		if !strings.EqualFold(rate.Country, address.Country) {
+			continue
+		}
+		if rate.State == "" {
+			countryRate = &s.FallbackRates[i]
+		} else if strings.EqualFold(rate.State, address.State) {
+			return &s.FallbackRates[i]
+		}
+	}
+	return countryRate
+}
+
+// LedgerSettings configure where finalized invoices and credit notes are
+// created in a ledger
+type LedgerSettings struct {
+	// AccountID is the NetSuite account ID ex 1234567_SB1 or the QuickBooks company (realm) ID
+	AccountID string `json:"account_id" validate:"required"`
+
+	// Sandbox sends the documents to the QuickBooks sandbox. NetSuite sandboxes
+	// have their own account ID
+	Sandbox bool `json:"sandbox"`
+
+	FieldMapping LedgerFieldMapping `json:"field_mapping"`
+}
+
+// LedgerFieldMapping maps Flexprice records to the records of the ledger
+type LedgerFieldMapping struct {
+	// DefaultItemID is the ledger item of the line items whose price has no item
+	DefaultItemID string `json:"default_item_id" validate:"required"`
+
+	// ItemIDs maps price IDs to ledger items
+	ItemIDs map[string]string `json:"item_ids,omitempty"`
+
+	// CustomerIDs maps customer IDs to ledger customers. Customers without one
+	// are expected to exist in the ledger with their external ID as ID
+	CustomerIDs map[string]string `json:"customer_ids,omitempty"`
+}
+
+// ItemID returns the ledger item of the price
+func (m LedgerFieldMapping) ItemID(priceID string) string {
+	if id, ok := m.ItemIDs[priceID]; ok && priceID != "" {
+		return id
+	}
+	return m.DefaultItemID
+}
+
+// CRMSettings configure which records are synced to a CRM and how
+type CRMSettings struct {
+	// InstanceURL is the Salesforce instance of the org ex https://acme.my.salesforce.com
+	InstanceURL string `json:"instance_url" validate:"required,url"`
+
+	// SyncCustomers, SyncSubscriptions and SyncInvoiceTotals turn each direction
+	// of the sync on. Customers are synced to Accounts, subscriptions to
+	// SubscriptionObject and invoice totals to InvoiceFields of the Account
+	SyncCustomers     bool `json:"sync_customers"`
+	SyncSubscriptions bool `json:"sync_subscriptions"`
+	SyncInvoiceTotals bool `json:"sync_invoice_totals"`
+
+	// SubscriptionObject is Opportunity or Contract, Opportunity by default
+	SubscriptionObject types.CRMSubscriptionObject `json:"subscription_object,omitempty" validate:"omitempty,oneof=Opportunity Contract"`
+
+	InvoiceFields CRMInvoiceFields `json:"invoice_fields"`
+}
+
+// CRMInvoiceFields are the custom Account fields the invoice totals of the
+// customer are written to
+type CRMInvoiceFields struct {
+	// TotalInvoiced is the sum of the finalized invoices ex Flexprice_Total_Invoiced__c
+	TotalInvoiced string `json:"total_invoiced,omitempty"`
+	// AmountDue is the amount due on the finalized invoices ex Flexprice_Amount_Due__c
+	AmountDue string `json:"amount_due,omitempty"`
+	// LastInvoiceDate is the date the last invoice was finalized ex Flexprice_Last_Invoice_Date__c
+	LastInvoiceDate string `json:"last_invoice_date,omitempty"`
+}
+
+// Scanner/Valuer implementations for LedgerSettings
+func (s *LedgerSettings) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb ledger settings")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s *LedgerSettings) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// Scanner/Valuer implementations for CRMSettings
+func (s *CRMSettings) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb crm settings")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s *CRMSettings) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// Scanner/Valuer implementations for GatewaySettings
+func (s *GatewaySettings) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb gateway settings")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s *GatewaySettings) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// Scanner/Valuer implementations for TaxSettings
+func (s *TaxSettings) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb tax settings")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s *TaxSettings) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
@@ -0,0 +1,15 @@
+package connection
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	Create(ctx context.Context, conn *Connection) error
+	Get(ctx context.Context, id string) (*Connection, error)
+	List(ctx context.Context, filter types.Filter) ([]*Connection, error)
+	// ListByProviders returns all the connections of the tenant to the providers
+	ListByProviders(ctx context.Context, providers []types.IntegrationProvider) ([]*Connection, error)
+}
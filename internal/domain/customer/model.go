@@ -1,6 +1,13 @@
 package customer
 
-import "github.com/flexprice/flexprice/internal/types"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
 
 type Customer struct {
 	// ID is the unique identifier for the customer
@@ -15,5 +22,104 @@ type Customer struct {
 	// Email is the email of the customer
 	Email string `db:"email" json:"email"`
 
+	// PaymentMethodID is the default payment method of the customer at the payment provider
+	PaymentMethodID string `db:"payment_method_id" json:"payment_method_id"`
+
+	// ConsolidateInvoices bills all the subscriptions of the customer sharing a
+	// billing date, a currency and a legal entity on a single invoice
+	ConsolidateInvoices bool `db:"consolidate_invoices" json:"consolidate_invoices"`
+
+	// BillingAddress is printed on the invoices of the customer
+	BillingAddress *Address `db:"billing_address" json:"billing_address,omitempty"`
+
+	// ShippingAddress is where the goods are delivered when it differs from the billing address
+	ShippingAddress *Address `db:"shipping_address" json:"shipping_address,omitempty"`
+
+	// TaxIDs are the tax registration numbers of the customer ex their EU VAT number
+	TaxIDs TaxIDs `db:"tax_ids" json:"tax_ids"`
+
+	// LegalEntityID is the legal entity the customer is invoiced from, the
+	// default entity of the tenant when empty
+	LegalEntityID string `db:"legal_entity_id" json:"legal_entity_id,omitempty"`
+
+	// CollectionsPausedUntil is when the collections paused on the customer
+	// resume. No payment is collected or bank debit submitted until then
+	CollectionsPausedUntil *time.Time `db:"collections_paused_until" json:"collections_paused_until,omitempty"`
+	CollectionsPauseReason string     `db:"collections_pause_reason" json:"collections_pause_reason,omitempty"`
+
+	// Timezone is the IANA timezone the billing periods of the customer's new
+	// subscriptions are computed in, so that they start at local midnight
+	Timezone string `db:"timezone" json:"timezone"`
+
+	// Metadata holds cross references to other systems ex stripe_customer_id
+	Metadata types.Metadata `db:"metadata" json:"metadata,omitempty"`
+
+	// DeletedAt is when the customer was deleted, it can be restored until it is purged
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+
 	types.BaseModel
 }
+
+// CollectionsPaused reports whether the collections of the customer are paused at t
+func (c *Customer) CollectionsPaused(t time.Time) bool {
+	return c.CollectionsPausedUntil != nil && t.Before(*c.CollectionsPausedUntil)
+}
+
+type Address struct {
+	Line1      string `json:"line1" validate:"required"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city" validate:"required"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	// Country is the ISO 3166-1 alpha-2 code of the country ex DE
+	Country string `json:"country" validate:"required,iso3166_1_alpha2"`
+}
+
+// TaxID is a tax registration number, normalized by types.NormalizeTaxID
+type TaxID struct {
+	Type  types.TaxIDType `json:"type"`
+	Value string          `json:"value"`
+	// Country is the ISO 3166-1 alpha-2 code of the country that issued the number
+	Country string `json:"country"`
+}
+
+type TaxIDs []TaxID
+
+// Scanner/Valuer implementations for Address
+func (a *Address) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb address")
+	}
+	return json.Unmarshal(bytes, a)
+}
+
+func (a *Address) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+// Scanner/Valuer implementations for TaxIDs
+func (t *TaxIDs) Scan(value interface{}) error {
+	if value == nil {
+		*t = TaxIDs{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb tax ids")
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+func (t TaxIDs) Value() (driver.Value, error) {
+	if t == nil {
+		return json.Marshal(TaxIDs{})
+	}
+	return json.Marshal(t)
+}
@@ -2,6 +2,7 @@ package customer
 
 import (
 	"context"
+	"time"
 
 	"github.com/flexprice/flexprice/internal/types"
 )
@@ -9,7 +10,17 @@ import (
 type Repository interface {
 	Create(ctx context.Context, customer *Customer) error
 	Get(ctx context.Context, id string) (*Customer, error)
+	// ListByExternalIDs returns the active customers with any of the given external ids
+	ListByExternalIDs(ctx context.Context, externalIDs []string) ([]*Customer, error)
 	List(ctx context.Context, filter types.Filter) ([]*Customer, error)
 	Update(ctx context.Context, customer *Customer) error
+	// Delete marks the customer deleted, it is kept until purged
 	Delete(ctx context.Context, id string) error
+	// Restore publishes a deleted customer again
+	Restore(ctx context.Context, id string) error
+	// PurgeDeleted removes the customers of all the tenants deleted before the
+	// given time that no subscription, invoice or wallet refers to, and returns
+	// how many were removed. It is used by the purge job and is not scoped to the
+	// tenant in context
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
@@ -0,0 +1,28 @@
+package deadletter
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// DeadLetter is a message that the consumer failed to process, kept with the
+// reason of its last failure until it is replayed. A message failing again
+// updates its dead letter rather than adding another one
+type DeadLetter struct {
+	ID string `db:"id" json:"id"`
+
+	// MessageID is the id of the Kafka message, the id of the event for events
+	MessageID string `db:"message_id" json:"message_id"`
+	Topic     string `db:"topic" json:"topic"`
+
+	// Payload is the message as consumed, which may not be valid JSON
+	Payload  string `db:"payload" json:"payload"`
+	Error    string `db:"error" json:"error"`
+	Attempts int    `db:"attempts" json:"attempts"`
+
+	DeadLetterStatus types.DeadLetterStatus `db:"dead_letter_status" json:"dead_letter_status"`
+	LastFailedAt     time.Time              `db:"last_failed_at" json:"last_failed_at"`
+	ReplayedAt       *time.Time             `db:"replayed_at" json:"replayed_at,omitempty"`
+	types.BaseModel
+}
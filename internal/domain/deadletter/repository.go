@@ -0,0 +1,16 @@
+package deadletter
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	// Record stores a failed message, or counts another failure of a message
+	// of the same tenant and topic already stored
+	Record(ctx context.Context, deadLetter *DeadLetter) error
+	Get(ctx context.Context, id string) (*DeadLetter, error)
+	List(ctx context.Context, filter *types.DeadLetterFilter) ([]*DeadLetter, error)
+	Update(ctx context.Context, deadLetter *DeadLetter) error
+}
@@ -0,0 +1,48 @@
+package email
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Template replaces the default template of a type of email for a tenant.
+// Subject and bodies are Go templates, the HTML body is escaped as HTML
+type Template struct {
+	ID           string                  `db:"id" json:"id"`
+	TemplateType types.EmailTemplateType `db:"template_type" json:"template_type"`
+	Subject      string                  `db:"subject" json:"subject"`
+	BodyHTML     string                  `db:"body_html" json:"body_html"`
+	BodyText     string                  `db:"body_text" json:"body_text"`
+	types.BaseModel
+}
+
+// Delivery is one email to one recipient. The rendered email is kept so that
+// retries and resends send it as it was first rendered
+type Delivery struct {
+	ID           string                  `db:"id" json:"id"`
+	TemplateType types.EmailTemplateType `db:"template_type" json:"template_type"`
+	Recipient    string                  `db:"recipient" json:"recipient"`
+	Subject      string                  `db:"subject" json:"subject"`
+	BodyHTML     string                  `db:"body_html" json:"body_html"`
+	BodyText     string                  `db:"body_text" json:"body_text"`
+
+	// EntityType and EntityID are what the email is about ex an invoice
+	EntityType string `db:"entity_type" json:"entity_type"`
+	EntityID   string `db:"entity_id" json:"entity_id"`
+
+	// ResentFrom is the delivery this one resends
+	ResentFrom string `db:"resent_from" json:"resent_from,omitempty"`
+
+	DeliveryStatus types.EmailDeliveryStatus `db:"delivery_status" json:"delivery_status"`
+	Attempts       int                       `db:"attempts" json:"attempts"`
+	NextAttemptAt  *time.Time                `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time                `db:"last_attempt_at" json:"last_attempt_at,omitempty"`
+	LastError      string                    `db:"last_error" json:"last_error,omitempty"`
+
+	// Provider and ProviderMessageID identify the email at the provider once sent
+	Provider          types.EmailProvider `db:"provider" json:"provider,omitempty"`
+	ProviderMessageID string              `db:"provider_message_id" json:"provider_message_id,omitempty"`
+	SentAt            *time.Time          `db:"sent_at" json:"sent_at,omitempty"`
+	types.BaseModel
+}
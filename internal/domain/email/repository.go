@@ -0,0 +1,26 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	// UpsertTemplate creates or replaces the template of the type of the template
+	UpsertTemplate(ctx context.Context, template *Template) error
+	GetTemplate(ctx context.Context, templateType types.EmailTemplateType) (*Template, error)
+	ListTemplates(ctx context.Context) ([]*Template, error)
+	DeleteTemplate(ctx context.Context, templateType types.EmailTemplateType) error
+
+	CreateDelivery(ctx context.Context, delivery *Delivery) error
+	GetDelivery(ctx context.Context, id string) (*Delivery, error)
+	ListDeliveries(ctx context.Context, filter *types.EmailDeliveryFilter) ([]*Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+
+	// ClaimDueDeliveries returns up to limit pending deliveries of all tenants whose
+	// next attempt is due and pushes their next attempt back by lease so that
+	// concurrent senders do not send them twice
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+}
@@ -0,0 +1,51 @@
+package environment
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Environment struct {
+	ID   string                `db:"id" json:"id"`
+	Name string                `db:"name" json:"name"`
+	Type types.EnvironmentType `db:"type" json:"type"`
+
+	// ClockOffsetMs is how far the simulated clock of a sandbox environment was
+	// moved ahead of the wall clock, in milliseconds
+	ClockOffsetMs int64 `db:"clock_offset_ms" json:"-"`
+
+	types.BaseModel
+}
+
+// ResetSteps are the groups of records wiped by a sandbox reset, in order.
+// The catalog (plans, prices, meters) is preserved
+var ResetSteps = []string{
+	"invoices",
+	"wallets",
+	"subscriptions",
+	"customers",
+	"events",
+}
+
+// Reset tracks the progress of a sandbox reset job
+type Reset struct {
+	ID            string            `db:"id" json:"id"`
+	EnvironmentID string            `db:"environment_id" json:"environment_id"`
+	ResetStatus   types.ResetStatus `db:"reset_status" json:"reset_status"`
+
+	// CurrentStep is the step being executed or the last executed step
+	CurrentStep string `db:"current_step" json:"current_step,omitempty"`
+
+	// StepsCompleted out of StepsTotal is the progress of the reset
+	StepsCompleted int `db:"steps_completed" json:"steps_completed"`
+	StepsTotal     int `db:"steps_total" json:"steps_total"`
+
+	// RecordsDeleted is the number of rows deleted from Postgres
+	RecordsDeleted int64 `db:"records_deleted" json:"records_deleted"`
+
+	Error       string     `db:"error" json:"error,omitempty"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+
+	types.BaseModel
+}
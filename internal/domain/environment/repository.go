@@ -0,0 +1,25 @@
+package environment
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	Create(ctx context.Context, env *Environment) error
+	Get(ctx context.Context, id string) (*Environment, error)
+	List(ctx context.Context, filter types.Filter) ([]*Environment, error)
+
+	// UpdateClockOffset persists the offset of the simulated clock of the
+	// environment
+	UpdateClockOffset(ctx context.Context, id string, offsetMs int64) error
+
+	CreateReset(ctx context.Context, reset *Reset) error
+	GetReset(ctx context.Context, id string) (*Reset, error)
+	UpdateReset(ctx context.Context, reset *Reset) error
+
+	// DeleteResetStep hard deletes all records of the tenant belonging to one of
+	// the Postgres ResetSteps and returns the number of deleted rows
+	DeleteResetStep(ctx context.Context, step string) (int64, error)
+}
@@ -1,6 +1,8 @@
 // internal/domain/errors/errors.go
 package errors
 
+import "fmt"
+
 type AttributeNotFoundError struct {
 	Attribute string
 }
@@ -17,6 +19,18 @@ func (e *InvalidInputError) Error() string {
 	return "invalid input: " + e.Input
 }
 
+// VersionConflictError is returned when an entity is written with a version
+// other than its current one, i.e. it was changed since the writer read it
+type VersionConflictError struct {
+	Entity         string
+	ID             string
+	CurrentVersion int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s %s was modified concurrently, current version is %d", e.Entity, e.ID, e.CurrentVersion)
+}
+
 func NewInvalidInputError(input string) *InvalidInputError {
 	return &InvalidInputError{
 		Input: input,
@@ -28,3 +42,11 @@ func NewAttributeNotFoundError(attribute string) error {
 		Attribute: attribute,
 	}
 }
+
+func NewVersionConflictError(entity, id string, currentVersion int) error {
+	return &VersionConflictError{
+		Entity:         entity,
+		ID:             id,
+		CurrentVersion: currentVersion,
+	}
+}
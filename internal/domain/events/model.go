@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/flexprice/flexprice/internal/types"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 )
@@ -38,6 +39,14 @@ type Event struct {
 
 	// ExternalCustomerID is the identifier of the customer in the external system ex Customer DB or Stripe
 	ExternalCustomerID string `json:"external_customer_id" ch:"external_customer_id"`
+
+	// CorrectionOf is the ID of the event a correction row reverses or replaces,
+	// it is empty for ingested events
+	CorrectionOf string `json:"correction_of,omitempty" ch:"correction_of"`
+
+	// Sign is -1 for the correction rows reversing an event, whose usage the
+	// aggregations subtract, and 1 otherwise. Zero is read as 1
+	Sign int8 `json:"sign,omitempty" ch:"sign"`
 }
 
 // NewEvent creates a new event with defaults
@@ -48,7 +57,7 @@ func NewEvent(
 	eventID, customerID, source string,
 ) *Event {
 	if eventID == "" {
-		eventID = uuid.New().String()
+		eventID = types.GenerateUUID()
 	}
 
 	now := time.Now().UTC()
@@ -71,6 +80,17 @@ func NewEvent(
 	}
 }
 
+// eventIDNamespace is the namespace of the UUIDs derived from idempotency keys
+var eventIDNamespace = uuid.MustParse("6f1c3a52-8d7e-4b0a-9c4e-2f5d8a1b7e90")
+
+// DeterministicEventID returns the ID of an event sent with an idempotency key,
+// a name based UUID of the tenant, the key and the timestamp of the event. A
+// retried event gets the same ID, so its copies are stored as one event
+func DeterministicEventID(tenantID, idempotencyKey string, timestamp time.Time) string {
+	name := fmt.Sprintf("%s:%s:%d", tenantID, idempotencyKey, timestamp.UTC().UnixMilli())
+	return uuid.NewSHA1(eventIDNamespace, []byte(name)).String()
+}
+
 // Validate validates the event
 func (e *Event) Validate() error {
 	if e.CustomerID == "" && e.ExternalCustomerID == "" {
@@ -79,3 +99,60 @@ func (e *Event) Validate() error {
 
 	return validator.New().Struct(e)
 }
+
+// GetSign returns the sign the usage of the row is aggregated with
+func (e *Event) GetSign() int8 {
+	if e.Sign == 0 {
+		return 1
+	}
+	return e.Sign
+}
+
+// correctionID returns the ID of a correction row of the given kind made to
+// the row with the given ID. A row is reversed and replaced at most once, so
+// the rows written by concurrent corrections of the same row get the same ID
+// and are stored as one row
+func correctionID(tenantID, rowID, kind string) string {
+	name := fmt.Sprintf("%s:%s:%s", tenantID, rowID, kind)
+	return uuid.NewSHA1(eventIDNamespace, []byte(name)).String()
+}
+
+// Reversal returns a correction row cancelling the usage of the event
+func (e *Event) Reversal(originalID string) *Event {
+	reversal := *e
+	reversal.ID = correctionID(e.TenantID, e.ID, "reversal")
+	reversal.CorrectionOf = originalID
+	reversal.Sign = -1
+	reversal.IngestedAt = time.Time{}
+	return &reversal
+}
+
+// Replacement returns a correction row standing in for the event with the
+// given properties
+func (e *Event) Replacement(originalID string, properties map[string]interface{}) *Event {
+	replacement := *e
+	replacement.ID = correctionID(e.TenantID, e.ID, "replacement")
+	replacement.CorrectionOf = originalID
+	replacement.Sign = 1
+	replacement.Properties = properties
+	replacement.IngestedAt = time.Time{}
+	return &replacement
+}
+
+// EffectiveEvent returns the row the usage of an event is aggregated from given
+// its history, the latest row not reversed by a later one, or nil when the
+// event is voided
+func EffectiveEvent(history []*Event) *Event {
+	var current *Event
+	sign := 0
+	for _, event := range history {
+		sign += int(event.GetSign())
+		if event.GetSign() > 0 {
+			current = event
+		}
+	}
+	if sign <= 0 {
+		return nil
+	}
+	return current
+}
@@ -10,9 +10,49 @@ import (
 
 type Repository interface {
 	InsertEvent(ctx context.Context, event *Event) error
+
+	// InsertEvents writes the events in a single insert, so that either all or
+	// none of them are aggregated
+	InsertEvents(ctx context.Context, events []*Event) error
+
+	// GetEventHistory returns the ingested event of the tenant in context with
+	// the given ID followed by its correction rows in the order they were written,
+	// nil when there is no such event
+	GetEventHistory(ctx context.Context, id string) ([]*Event, error)
 	GetUsage(ctx context.Context, params *UsageParams) (*AggregationResult, error)
 	GetUsageWithFilters(ctx context.Context, params *UsageWithFiltersParams) ([]*AggregationResult, error)
 	GetEvents(ctx context.Context, params *GetEventsParams) ([]*Event, error)
+
+	// ExportEvents returns the next batch of at most params.Limit events in
+	// ascending (timestamp, id) order, starting after params.After
+	ExportEvents(ctx context.Context, params *ExportEventsParams) ([]*Event, error)
+
+	// CountLateEvents counts the events and corrections of a customer in a time
+	// range which were ingested within an ingestion window
+	CountLateEvents(ctx context.Context, params *LateEventsParams) (uint64, error)
+
+	// GetUsageByCustomer aggregates the usage of every customer of the tenant per
+	// window of params.WindowSize, ignoring params.ExternalCustomerID
+	GetUsageByCustomer(ctx context.Context, params *UsageParams) ([]*CustomerUsage, error)
+
+	// GetTenantIDs returns the tenants with events since the given time, across all tenants
+	GetTenantIDs(ctx context.Context, since time.Time) ([]string, error)
+
+	// DeleteEvents removes all the events of the tenant in context
+	DeleteEvents(ctx context.Context) error
+
+	// DeleteEventsBefore removes the events of the tenant in context with a
+	// timestamp before the given time
+	DeleteEventsBefore(ctx context.Context, before time.Time) error
+
+	// GetStorageByTenant returns the size of the events of every tenant, across all tenants
+	GetStorageByTenant(ctx context.Context) ([]*TenantStorage, error)
+
+	// ListPartitions returns the active monthly partitions of the events table
+	ListPartitions(ctx context.Context) ([]*Partition, error)
+
+	// DropPartition drops a monthly partition with the events of all the tenants
+	DropPartition(ctx context.Context, partitionID string) error
 }
 
 type UsageParams struct {
@@ -25,6 +65,12 @@ type UsageParams struct {
 	StartTime          time.Time             `json:"start_time" validate:"required"`
 	EndTime            time.Time             `json:"end_time" validate:"required"`
 	Filters            map[string][]string   `json:"filters"`
+
+	// Precision of the sketch of a COUNT_UNIQUE aggregation, zero for an exact count
+	Precision int `json:"precision"`
+
+	// Percentile of a PERCENTILE aggregation, from 0 to 100
+	Percentile float64 `json:"percentile"`
 }
 
 type GetEventsParams struct {
@@ -37,17 +83,73 @@ type GetEventsParams struct {
 	PageSize           int            `json:"page_size"`
 }
 
+type ExportEventsParams struct {
+	ExternalCustomerID string
+	EventName          string
+	StartTime          time.Time
+	EndTime            time.Time
+	After              *EventIterator
+	Limit              int
+}
+
+// LateEventsParams selects the events of a customer with a timestamp in
+// [StartTime, EndTime) ingested in (IngestedAfter, IngestedBefore], of any of
+// EventNames when set
+type LateEventsParams struct {
+	ExternalCustomerID string
+	EventNames         []string
+	StartTime          time.Time
+	EndTime            time.Time
+	IngestedAfter      time.Time
+	IngestedBefore     time.Time
+}
+
 type UsageResult struct {
 	WindowSize time.Time       `json:"window_size"`
 	Value      decimal.Decimal `json:"value"`
 }
 
+// CustomerUsage is the windowed usage of one customer, windows without events are omitted
+type CustomerUsage struct {
+	ExternalCustomerID string        `json:"external_customer_id"`
+	Results            []UsageResult `json:"results"`
+}
+
 type AggregationResult struct {
 	Results   []UsageResult         `json:"results,omitempty"`
 	Value     decimal.Decimal       `json:"value,omitempty"`
 	EventName string                `json:"event_name"`
 	Type      types.AggregationType `json:"type"`
 	Metadata  map[string]string     `json:"metadata,omitempty"`
+
+	// ResetPeriodStart is when the usage last reset, and ResetPeriodEnd when it
+	// next resets. Usage which never resets counts from the creation of the
+	// meter, up to the end of the window
+	ResetPeriodStart *time.Time `json:"reset_period_start,omitempty"`
+	ResetPeriodEnd   *time.Time `json:"reset_period_end,omitempty"`
+}
+
+// TenantStorage is the size of the events of a tenant. Bytes is the
+// uncompressed size of the rows, disk usage is only known per partition
+type TenantStorage struct {
+	TenantID    string
+	Rows        uint64
+	Bytes       uint64
+	OldestEvent time.Time
+	NewestEvent time.Time
+}
+
+// Partition is a monthly partition of the events table, its ID is the
+// YYYYMM month of the event timestamps
+type Partition struct {
+	PartitionID string
+	Rows        uint64
+	BytesOnDisk uint64
+}
+
+// PartitionMonth returns the first instant of the month of a partition
+func PartitionMonth(partitionID string) (time.Time, error) {
+	return time.Parse("200601", partitionID)
 }
 
 type EventIterator struct {
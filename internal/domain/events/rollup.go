@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// RollupRepository maintains the hourly and daily usage of every customer on
+// every meter of the tenant in context, so that usage over long closed periods
+// is read without scanning the raw events
+type RollupRepository interface {
+	// RollUpUsage recomputes the rollups of the windows of params.WindowSize
+	// within [StartTime, EndTime) from the raw events, a zero StartTime meaning
+	// since the first event
+	RollUpUsage(ctx context.Context, params *RollupParams) error
+
+	// GetRollupWatermark returns the time before which the windows of the meter
+	// are rolled up, zero when the meter was never rolled up
+	GetRollupWatermark(ctx context.Context, meterID string) (time.Time, error)
+	SetRollupWatermark(ctx context.Context, meterID string, until time.Time) error
+
+	// GetRolledUpUsage sums the rollups of the meter over the given windows
+	GetRolledUpUsage(ctx context.Context, params *RolledUpUsageParams) (*RolledUpUsage, error)
+
+	// GetRolledUpWindows returns the rollups of the meter over the given windows
+	// one by one, ordered by the start of the window. Windows without usage are
+	// left out
+	GetRolledUpWindows(ctx context.Context, params *RolledUpUsageParams) ([]*RolledUpWindow, error)
+
+	// GetUniqueCount counts the distinct values of the field of a COUNT_UNIQUE
+	// meter over the given windows and raw ranges at once. Unique counts do not
+	// add up, so the rolled up state of each window is merged with the others
+	// and with the raw events before counting
+	GetUniqueCount(ctx context.Context, params *UniqueCountParams) (uint64, error)
+}
+
+type RollupParams struct {
+	MeterID      string
+	EventName    string
+	PropertyName string
+	WindowSize   types.WindowSize
+	StartTime    time.Time
+	EndTime      time.Time
+
+	// AggregationType and Precision select the state rolled up for COUNT_UNIQUE
+	// meters, the distinct values of the field instead of a count and a sum
+	AggregationType types.AggregationType
+	Precision       int
+}
+
+// RollupRange selects the rollups of one window size starting within [StartTime, EndTime)
+type RollupRange struct {
+	WindowSize types.WindowSize
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+type RolledUpUsageParams struct {
+	MeterID            string
+	ExternalCustomerID string
+	CustomerID         string
+	Ranges             []RollupRange
+}
+
+// TimeRange is [StartTime, EndTime), a zero EndTime being unbounded
+type TimeRange struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+type UniqueCountParams struct {
+	MeterID            string
+	EventName          string
+	PropertyName       string
+	Precision          int
+	ExternalCustomerID string
+	CustomerID         string
+	Ranges             []RollupRange
+	Raw                []TimeRange
+}
+
+// RolledUpUsage is the number of deduplicated events and the sum of the
+// aggregated field, from which the count, sum and average are derived
+type RolledUpUsage struct {
+	EventCount uint64
+	ValueSum   decimal.Decimal
+}
+
+// RolledUpWindow is the rolled up usage of one window
+type RolledUpWindow struct {
+	RolledUpUsage
+	WindowSize  types.WindowSize
+	WindowStart time.Time
+}
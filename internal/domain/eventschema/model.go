@@ -0,0 +1,211 @@
+package eventschema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Violation codes of the properties of an event
+const (
+	ViolationMissing     = "missing"
+	ViolationInvalidType = "invalid_type"
+	ViolationNotAllowed  = "not_allowed"
+)
+
+// EventSchema constrains the properties of the events of an event name.
+// Properties without a rule are accepted as they are
+type EventSchema struct {
+	ID        string        `db:"id" json:"id"`
+	EventName string        `db:"event_name" json:"event_name"`
+	Rules     PropertyRules `db:"rules" json:"rules"`
+
+	// OnInvalid is what happens to the events that do not match the schema
+	OnInvalid types.InvalidEventAction `db:"on_invalid" json:"on_invalid"`
+	types.BaseModel
+}
+
+// PropertyRule constrains one property of an event
+type PropertyRule struct {
+	Name     string                  `json:"name"`
+	Type     types.EventPropertyType `json:"type"`
+	Required bool                    `json:"required"`
+
+	// AllowedValues restricts the property to the listed values, compared with
+	// the text form of the value ex "3" for the number 3. Any value is allowed
+	// when empty
+	AllowedValues []string `json:"allowed_values,omitempty"`
+}
+
+type PropertyRules []PropertyRule
+
+// Violation is a property of an event that does not match its rule
+type Violation struct {
+	Property string `json:"property"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+type Violations []Violation
+
+// QuarantinedEvent is an event kept aside because it did not match the schema
+// of its event name. It keeps the id of the event, so quarantining an event
+// again replaces its previous copy
+type QuarantinedEvent struct {
+	ID                 string          `db:"id" json:"id"`
+	EventName          string          `db:"event_name" json:"event_name"`
+	ExternalCustomerID string          `db:"external_customer_id" json:"external_customer_id"`
+	CustomerID         string          `db:"customer_id" json:"customer_id"`
+	Source             string          `db:"source" json:"source"`
+	Timestamp          time.Time       `db:"timestamp" json:"timestamp"`
+	Properties         EventProperties `db:"properties" json:"properties" swaggertype:"object"`
+	SchemaID           string          `db:"schema_id" json:"schema_id"`
+	Violations         Violations      `db:"violations" json:"violations"`
+
+	QuarantineStatus types.QuarantineStatus `db:"quarantine_status" json:"quarantine_status"`
+	ResubmittedAt    *time.Time             `db:"resubmitted_at" json:"resubmitted_at,omitempty"`
+	types.BaseModel
+}
+
+type EventProperties map[string]interface{}
+
+// Validate checks the definition of the schema
+func (s *EventSchema) Validate() error {
+	if s.EventName == "" {
+		return fmt.Errorf("event_name is required")
+	}
+	if !s.OnInvalid.Validate() {
+		return fmt.Errorf("invalid on_invalid %q", s.OnInvalid)
+	}
+
+	seen := make(map[string]bool, len(s.Rules))
+	for _, rule := range s.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("property name is required")
+		}
+		if seen[rule.Name] {
+			return fmt.Errorf("property %s has several rules", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		if !rule.Type.Validate() {
+			return fmt.Errorf("invalid type %q of property %s", rule.Type, rule.Name)
+		}
+		if len(rule.AllowedValues) > 0 && (rule.Type == types.EventPropertyTypeObject || rule.Type == types.EventPropertyTypeArray) {
+			return fmt.Errorf("allowed values of property %s are not supported for %s properties", rule.Name, rule.Type)
+		}
+	}
+	return nil
+}
+
+// Check returns the violations of the schema by the given properties, in the
+// order of the rules
+func (s *EventSchema) Check(properties map[string]interface{}) Violations {
+	var violations Violations
+	for _, rule := range s.Rules {
+		value, ok := properties[rule.Name]
+		if !ok || value == nil {
+			if rule.Required {
+				violations = append(violations, Violation{
+					Property: rule.Name,
+					Code:     ViolationMissing,
+					Message:  fmt.Sprintf("property %s is required", rule.Name),
+				})
+			}
+			continue
+		}
+
+		if !hasType(value, rule.Type) {
+			violations = append(violations, Violation{
+				Property: rule.Name,
+				Code:     ViolationInvalidType,
+				Message:  fmt.Sprintf("property %s must be of type %s", rule.Name, rule.Type),
+			})
+			continue
+		}
+
+		if len(rule.AllowedValues) > 0 && !isAllowed(value, rule.AllowedValues) {
+			violations = append(violations, Violation{
+				Property: rule.Name,
+				Code:     ViolationNotAllowed,
+				Message:  fmt.Sprintf("value %v of property %s is not allowed", value, rule.Name),
+			})
+		}
+	}
+	return violations
+}
+
+func hasType(value interface{}, t types.EventPropertyType) bool {
+	switch value.(type) {
+	case string:
+		return t == types.EventPropertyTypeString
+	case float64, float32, int, int32, int64, uint, uint32, uint64, json.Number:
+		return t == types.EventPropertyTypeNumber
+	case bool:
+		return t == types.EventPropertyTypeBoolean
+	case map[string]interface{}:
+		return t == types.EventPropertyTypeObject
+	case []interface{}:
+		return t == types.EventPropertyTypeArray
+	}
+	return false
+}
+
+func isAllowed(value interface{}, allowed []string) bool {
+	text := fmt.Sprint(value)
+	for _, v := range allowed {
+		if v == text {
+			return true
+		}
+	}
+	return false
+}
+
+// Scanner/Valuer implementations for the jsonb columns
+
+func (r *PropertyRules) Scan(value interface{}) error {
+	return scanJSONB(value, r)
+}
+
+func (r PropertyRules) Value() (driver.Value, error) {
+	if r == nil {
+		return json.Marshal(PropertyRules{})
+	}
+	return json.Marshal(r)
+}
+
+func (v *Violations) Scan(value interface{}) error {
+	return scanJSONB(value, v)
+}
+
+func (v Violations) Value() (driver.Value, error) {
+	if v == nil {
+		return json.Marshal(Violations{})
+	}
+	return json.Marshal(v)
+}
+
+func (p *EventProperties) Scan(value interface{}) error {
+	return scanJSONB(value, p)
+}
+
+func (p EventProperties) Value() (driver.Value, error) {
+	if p == nil {
+		return json.Marshal(EventProperties{})
+	}
+	return json.Marshal(p)
+}
+
+func scanJSONB(value interface{}, dest interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value: %v", value)
+	}
+	return json.Unmarshal(bytes, dest)
+}
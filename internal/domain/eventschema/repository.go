@@ -0,0 +1,26 @@
+package eventschema
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	Create(ctx context.Context, schema *EventSchema) error
+	Get(ctx context.Context, id string) (*EventSchema, error)
+	// GetByEventName returns the published schema of the event name, or nil
+	// when the event name has none
+	GetByEventName(ctx context.Context, eventName string) (*EventSchema, error)
+	// List returns all the published schemas of the tenant ordered by event name
+	List(ctx context.Context) ([]*EventSchema, error)
+	Update(ctx context.Context, schema *EventSchema) error
+	Delete(ctx context.Context, id string) error
+
+	// Quarantine stores an event that did not match its schema, replacing the
+	// copy of a previous quarantine of the same event
+	Quarantine(ctx context.Context, event *QuarantinedEvent) error
+	GetQuarantinedEvent(ctx context.Context, id string) (*QuarantinedEvent, error)
+	ListQuarantinedEvents(ctx context.Context, filter *types.QuarantinedEventFilter) ([]*QuarantinedEvent, error)
+	UpdateQuarantinedEvent(ctx context.Context, event *QuarantinedEvent) error
+}
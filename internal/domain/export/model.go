@@ -0,0 +1,116 @@
+package export
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Export is an asynchronous job dumping events or aggregated usage
+// from the event store into a file in object storage
+type Export struct {
+	ID string `db:"id" json:"id"`
+
+	// Type is the kind of data being exported i.e raw events or aggregated usage
+	Type types.ExportType `db:"export_type" json:"export_type"`
+
+	// Format is the file format of the exported object
+	Format types.ExportFormat `db:"export_format" json:"export_format"`
+
+	// ExportStatus tracks the progress of the export job
+	ExportStatus types.ExportStatus `db:"export_status" json:"export_status"`
+
+	// EventName filters the raw events to export. It is required for event exports
+	// and is derived from the meter for usage exports
+	EventName string `db:"event_name" json:"event_name"`
+
+	// MeterID is the meter whose usage is aggregated, only set for usage exports
+	MeterID string `db:"meter_id" json:"meter_id,omitempty"`
+
+	// ExternalCustomerID optionally limits the export to a single customer
+	ExternalCustomerID string `db:"external_customer_id" json:"external_customer_id,omitempty"`
+
+	// WindowSize is the aggregation window for usage exports
+	WindowSize types.WindowSize `db:"window_size" json:"window_size,omitempty"`
+
+	StartTime time.Time `db:"start_time" json:"start_time"`
+	EndTime   time.Time `db:"end_time" json:"end_time"`
+
+	// ObjectKey is the key of the generated file in the export bucket
+	ObjectKey string `db:"object_key" json:"object_key,omitempty"`
+
+	// RowCount is the number of rows written to the file
+	RowCount int64 `db:"row_count" json:"row_count"`
+
+	// Error holds the failure reason when the export failed
+	Error string `db:"error" json:"error,omitempty"`
+
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+
+	// ScheduleID is the schedule which created the export, empty for the
+	// exports created through the API
+	ScheduleID string `db:"schedule_id" json:"schedule_id,omitempty"`
+
+	// LeaseUntil is when the claim of the export job on a processing export
+	// expires. An export whose lease expired is claimed and run again
+	LeaseUntil *time.Time `db:"lease_until" json:"-"`
+
+	types.BaseModel
+}
+
+// Schedule creates an export of the day, week or month before each of its
+// runs, for the data warehouses loading the exports on a schedule
+type Schedule struct {
+	ID   string `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+
+	Type               types.ExportType   `db:"export_type" json:"export_type"`
+	Format             types.ExportFormat `db:"export_format" json:"export_format"`
+	EventName          string             `db:"event_name" json:"event_name"`
+	MeterID            string             `db:"meter_id" json:"meter_id,omitempty"`
+	ExternalCustomerID string             `db:"external_customer_id" json:"external_customer_id,omitempty"`
+	WindowSize         types.WindowSize   `db:"window_size" json:"window_size,omitempty"`
+
+	// Schedule is how often the export runs, at midnight UTC
+	Schedule types.ReportSchedule `db:"schedule" json:"schedule"`
+	// NextRunAt is when the schedule creates its next export
+	NextRunAt *time.Time `db:"next_run_at" json:"next_run_at,omitempty"`
+
+	types.BaseModel
+}
+
+// schedulePeriods are the periods exported by the runs of each schedule
+var schedulePeriods = map[types.ReportSchedule]types.ReportPeriod{
+	types.ReportScheduleDaily:   types.ReportPeriodPreviousDay,
+	types.ReportScheduleWeekly:  types.ReportPeriodPreviousWeek,
+	types.ReportScheduleMonthly: types.ReportPeriodPreviousMonth,
+}
+
+// Period returns the day, week or month before at exported by a run of the
+// schedule
+func (s *Schedule) Period(at time.Time) (time.Time, time.Time, bool) {
+	return schedulePeriods[s.Schedule].Range(at)
+}
+
+// NewExport returns the pending export of the period before at
+func (s *Schedule) NewExport(at time.Time, baseModel types.BaseModel) (*Export, bool) {
+	start, end, ok := s.Period(at)
+	if !ok {
+		return nil, false
+	}
+
+	return &Export{
+		ID:                 types.GenerateUUID(),
+		Type:               s.Type,
+		Format:             s.Format,
+		ExportStatus:       types.ExportStatusPending,
+		EventName:          s.EventName,
+		MeterID:            s.MeterID,
+		ExternalCustomerID: s.ExternalCustomerID,
+		WindowSize:         s.WindowSize,
+		StartTime:          start,
+		EndTime:            end,
+		ScheduleID:         s.ID,
+		BaseModel:          baseModel,
+	}, true
+}
@@ -0,0 +1,30 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	Create(ctx context.Context, export *Export) error
+	Get(ctx context.Context, id string) (*Export, error)
+	List(ctx context.Context, filter types.Filter) ([]*Export, error)
+	Update(ctx context.Context, export *Export) error
+
+	// ClaimPending returns up to limit exports of all tenants which are pending,
+	// or processing with an expired lease, and marks them processing with a
+	// lease until now+lease so that concurrent schedulers do not run them twice
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Export, error)
+
+	CreateSchedule(ctx context.Context, schedule *Schedule) error
+	GetSchedule(ctx context.Context, id string) (*Schedule, error)
+	ListSchedules(ctx context.Context, filter types.Filter) ([]*Schedule, error)
+	UpdateSchedule(ctx context.Context, schedule *Schedule) error
+	DeleteSchedule(ctx context.Context, id string) error
+
+	// ClaimDueSchedules returns up to limit schedules of all tenants whose next
+	// run is due and pushes their next run back by lease
+	ClaimDueSchedules(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Schedule, error)
+}
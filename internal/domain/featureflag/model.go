@@ -0,0 +1,33 @@
+package featureflag
+
+import "github.com/flexprice/flexprice/internal/types"
+
+// Override turns a feature flag on or off for a tenant. TenantID of the base
+// model is the tenant the override applies to, an empty EnvironmentID applies
+// it to all the environments of the tenant
+type Override struct {
+	ID            string            `db:"id" json:"id"`
+	Flag          types.FeatureFlag `db:"flag" json:"flag"`
+	EnvironmentID string            `db:"environment_id" json:"environment_id,omitempty"`
+	Enabled       bool              `db:"enabled" json:"enabled"`
+	types.BaseModel
+}
+
+// Resolve returns whether the flag is on in an environment of the tenant of
+// the overrides: the override of the environment first, then the override of
+// the tenant, then the default of the flag
+func Resolve(overrides []*Override, flag types.FeatureFlag, environmentID string) bool {
+	enabled := flag.Default()
+	for _, o := range overrides {
+		if o.Flag != flag {
+			continue
+		}
+		if environmentID != "" && o.EnvironmentID == environmentID {
+			return o.Enabled
+		}
+		if o.EnvironmentID == "" {
+			enabled = o.Enabled
+		}
+	}
+	return enabled
+}
@@ -0,0 +1,17 @@
+package featureflag
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Repository stores the feature flag overrides of all the tenants. It is used
+// by the operator APIs and is not scoped to the tenant in context
+type Repository interface {
+	// Upsert creates or replaces the override of override.TenantID for its flag
+	// and environment
+	Upsert(ctx context.Context, override *Override) error
+	List(ctx context.Context, tenantID string) ([]*Override, error)
+	Delete(ctx context.Context, tenantID string, flag types.FeatureFlag, environmentID string) error
+}
@@ -0,0 +1,35 @@
+package invoice
+
+import (
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// CreditAllocation records where part of a credit invoice went. The amounts
+// allocated from a credit invoice never exceed the credit it carries
+type CreditAllocation struct {
+	ID string `db:"id" json:"id"`
+
+	// CreditInvoiceID is the CREDIT invoice the amount is drawn from
+	CreditInvoiceID string `db:"credit_invoice_id" json:"credit_invoice_id"`
+
+	CustomerID string `db:"customer_id" json:"customer_id"`
+
+	// TargetType and TargetID are the invoice whose amount due was reduced or the
+	// wallet that was credited
+	TargetType types.CreditAllocationTarget `db:"target_type" json:"target_type"`
+	TargetID   string                       `db:"target_id" json:"target_id"`
+
+	Amount   decimal.Decimal `db:"amount" json:"amount" swaggertype:"string"`
+	Currency string          `db:"currency" json:"currency"`
+
+	types.BaseModel
+}
+
+// Credit returns the amount a credit invoice carries
+func (i *Invoice) Credit() decimal.Decimal {
+	if i.InvoiceType != types.InvoiceTypeCredit || !i.Total.IsNegative() {
+		return decimal.Zero
+	}
+	return i.Total.Neg()
+}
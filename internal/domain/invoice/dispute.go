@@ -0,0 +1,83 @@
+package invoice
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// MetadataReissuedFrom is the invoice metadata key of the invoice a corrected
+// invoice was re-issued from to resolve a dispute
+const MetadataReissuedFrom = "reissued_from"
+
+// Dispute is the disagreement of a customer with charges of a finalized invoice.
+// The collection of the invoice is paused until the dispute is resolved
+type Dispute struct {
+	ID         string `db:"id" json:"id"`
+	InvoiceID  string `db:"invoice_id" json:"invoice_id"`
+	CustomerID string `db:"customer_id" json:"customer_id"`
+
+	DisputeStatus types.DisputeStatus `db:"dispute_status" json:"dispute_status"`
+	Reason        string              `db:"reason" json:"reason,omitempty"`
+
+	// Lines are the disputed amounts of the line items of the invoice
+	Lines DisputedLines `db:"lines" json:"lines"`
+
+	// Amount is the sum of the disputed amounts of the lines
+	Amount   decimal.Decimal `db:"amount" json:"amount" swaggertype:"string"`
+	Currency string          `db:"currency" json:"currency"`
+
+	// Resolution is how the dispute was resolved, set once it is resolved
+	Resolution     types.DisputeResolution `db:"resolution" json:"resolution,omitempty"`
+	ResolutionNote string                  `db:"resolution_note" json:"resolution_note,omitempty"`
+
+	// CreditNoteID is the credit note issued for the disputed amount when the
+	// dispute is resolved with a credit note
+	CreditNoteID string `db:"credit_note_id" json:"credit_note_id,omitempty"`
+
+	// ReissuedInvoiceID is the corrected invoice raised in place of the voided
+	// invoice when the dispute is resolved by re-issuing it
+	ReissuedInvoiceID string `db:"reissued_invoice_id" json:"reissued_invoice_id,omitempty"`
+
+	ResolvedAt *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+
+	types.BaseModel
+}
+
+// DisputedLine is the amount of a line item of the invoice the customer disputes
+type DisputedLine struct {
+	LineItemID string          `json:"line_item_id"`
+	Amount     decimal.Decimal `json:"amount" swaggertype:"string"`
+	Reason     string          `json:"reason,omitempty"`
+}
+
+type DisputedLines []DisputedLine
+
+// IsActive returns whether the dispute still pauses the collection of its invoice
+func (d *Dispute) IsActive() bool {
+	return d.DisputeStatus != types.DisputeStatusResolved
+}
+
+// Scanner/Valuer implementations for DisputedLines
+func (l *DisputedLines) Scan(value interface{}) error {
+	if value == nil {
+		*l = DisputedLines{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb disputed lines")
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+func (l DisputedLines) Value() (driver.Value, error) {
+	if l == nil {
+		return json.Marshal(DisputedLines{})
+	}
+	return json.Marshal(l)
+}
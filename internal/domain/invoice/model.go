@@ -0,0 +1,175 @@
+package invoice
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+type Invoice struct {
+	// ID is the unique identifier for the invoice
+	ID string `db:"id" json:"id"`
+
+	// InvoiceNumber is the human readable number assigned from the tenant sequence
+	// when the invoice is finalized. Drafts do not consume numbers
+	InvoiceNumber *string `db:"invoice_number" json:"invoice_number,omitempty"`
+
+	// CustomerID is the identifier of the customer being invoiced
+	CustomerID string `db:"customer_id" json:"customer_id"`
+
+	// SubscriptionID is the subscription the invoice was raised for, empty for one off
+	// invoices and for invoices consolidating several subscriptions
+	SubscriptionID string `db:"subscription_id" json:"subscription_id,omitempty"`
+
+	// LegalEntityID is the legal entity the invoice was issued from, empty when the
+	// tenant has none. The invoice number is drawn from the sequence of the entity
+	LegalEntityID string `db:"legal_entity_id" json:"legal_entity_id,omitempty"`
+
+	// InvoiceType is the type of the document ex SUBSCRIPTION, ONE_OFF, CREDIT
+	InvoiceType types.InvoiceType `db:"invoice_type" json:"invoice_type"`
+
+	// InvoiceFlow is the billing flow that raised a subscription invoice ex PERIOD_END, THRESHOLD
+	InvoiceFlow types.InvoiceFlow `db:"invoice_flow" json:"invoice_flow,omitempty"`
+
+	// InvoiceStatus is the lifecycle status of the invoice ex DRAFT, FINALIZED, VOIDED
+	InvoiceStatus types.InvoiceStatus `db:"invoice_status" json:"invoice_status"`
+
+	// Currency 3 digit ISO currency code in lowercase ex usd, eur, gbp
+	Currency string `db:"currency" json:"currency"`
+
+	// Total is the signed sum of all line items. It is negative when
+	// credits like proration exceed the charges of the billing run
+	Total decimal.Decimal `db:"total" json:"total"`
+
+	// AmountDue is the amount to be collected from the customer and is never negative
+	AmountDue decimal.Decimal `db:"amount_due" json:"amount_due"`
+
+	// AmountPaid is the sum of the settled payments recorded against the invoice
+	AmountPaid decimal.Decimal `db:"amount_paid" json:"amount_paid"`
+
+	// AmountProcessing is the sum of the bank debits of the invoice that are
+	// scheduled or not cleared yet. It is not collected again
+	AmountProcessing decimal.Decimal `db:"amount_processing" json:"amount_processing"`
+
+	// AmountRemaining is the part of the amount due not paid yet
+	AmountRemaining decimal.Decimal `db:"amount_remaining" json:"amount_remaining"`
+
+	// PaymentStatus is PAID once nothing remains to be paid
+	PaymentStatus types.InvoicePaymentStatus `db:"payment_status" json:"payment_status"`
+
+	// WrittenOffAt, WriteOffReason and AmountWrittenOff are set when the invoice is
+	// written off as uncollectible, the amount is what remained to be paid then.
+	// Written off invoices are left out of the receivables
+	WrittenOffAt     *time.Time      `db:"written_off_at" json:"written_off_at,omitempty"`
+	WriteOffReason   string          `db:"write_off_reason" json:"write_off_reason,omitempty"`
+	AmountWrittenOff decimal.Decimal `db:"amount_written_off" json:"amount_written_off"`
+
+	// OriginalInvoiceID links a credit (or negative) invoice to the invoice being credited
+	OriginalInvoiceID string `db:"original_invoice_id" json:"original_invoice_id,omitempty"`
+
+	// Description is an optional note printed on the invoice
+	Description string `db:"description" json:"description,omitempty"`
+
+	// PeriodStart and PeriodEnd is the service period covered by the invoice
+	PeriodStart *time.Time `db:"period_start" json:"period_start,omitempty"`
+	PeriodEnd   *time.Time `db:"period_end" json:"period_end,omitempty"`
+
+	// PartialPeriodBehavior is how the fixed charges were billed when the invoice covers
+	// the partial first period of a calendar billed subscription, empty otherwise
+	PartialPeriodBehavior types.PartialPeriodBehavior `db:"partial_period_behavior" json:"partial_period_behavior,omitempty"`
+
+	// FinalizedAt is the time the invoice was finalized and can no longer be edited
+	FinalizedAt *time.Time `db:"finalized_at" json:"finalized_at,omitempty"`
+
+	Metadata types.Metadata `db:"metadata" json:"metadata,omitempty"`
+
+	// Version is incremented on every update and guards against concurrent writes
+	Version int `db:"version" json:"version"`
+
+	// LineItems are loaded separately from the invoice_line_items table
+	LineItems []*InvoiceLineItem `db:"-" json:"line_items,omitempty"`
+
+	types.BaseModel
+}
+
+// MetadataProrationID is the line item metadata key of the subscription
+// proration the line item bills
+const MetadataProrationID = "proration_id"
+
+// MetadataMeteredAmount is the line item metadata key of the amount computed from
+// the usage of a usage line item whose amount was edited on the draft
+const MetadataMeteredAmount = "metered_amount"
+
+// MetadataLateEventsCheckedAt is the invoice metadata key of the ingestion time
+// up to which the events of its period were reconciled with it, the creation
+// of the invoice when it was never reconciled
+const MetadataLateEventsCheckedAt = "late_events_checked_at"
+
+// MetadataTaxSource is the line item metadata key of how a tax line item was
+// calculated, see types.TaxSource
+const MetadataTaxSource = "tax_source"
+
+// MetadataTaxedLineItemID is the line item metadata key of the line item a tax
+// line item quoted through the tax connection taxes
+const MetadataTaxedLineItemID = "taxed_line_item_id"
+
+// MetadataTaxRatePercent is the line item metadata key of the rate of a tax
+// line item quoted through the tax connection
+const MetadataTaxRatePercent = "tax_rate_percent"
+
+type InvoiceLineItem struct {
+	ID             string          `db:"id" json:"id"`
+	InvoiceID      string          `db:"invoice_id" json:"invoice_id"`
+	CustomerID     string          `db:"customer_id" json:"customer_id"`
+	SubscriptionID string          `db:"subscription_id" json:"subscription_id,omitempty"`
+	PriceID        string          `db:"price_id" json:"price_id,omitempty"`
+	MeterID        string          `db:"meter_id" json:"meter_id,omitempty"`
+	DisplayName    string          `db:"display_name" json:"display_name"`
+	Amount         decimal.Decimal `db:"amount" json:"amount"`
+	Quantity       decimal.Decimal `db:"quantity" json:"quantity"`
+	Currency       string          `db:"currency" json:"currency"`
+	PeriodStart    *time.Time      `db:"period_start" json:"period_start,omitempty"`
+	PeriodEnd      *time.Time      `db:"period_end" json:"period_end,omitempty"`
+	Metadata       types.Metadata  `db:"metadata" json:"metadata,omitempty"`
+	types.BaseModel
+}
+
+// TaxSource returns how the line item was calculated when it is a tax, empty
+// for the other line items
+func (i *InvoiceLineItem) TaxSource() types.TaxSource {
+	return types.TaxSource(i.Metadata[MetadataTaxSource])
+}
+
+// RecalculateTotals sets the total as the sum of the line items and the
+// amount due as the non negative part of it
+func (i *Invoice) RecalculateTotals() {
+	total := decimal.Zero
+	for _, item := range i.LineItems {
+		total = total.Add(item.Amount)
+	}
+
+	i.Total = total
+	i.AmountDue = decimal.Max(total, decimal.Zero)
+	i.RecalculateAmountRemaining()
+}
+
+// RecalculateAmountRemaining sets the amount remaining as the part of the amount
+// due not covered by the payments, settled or processing, and the payment status
+// from it
+func (i *Invoice) RecalculateAmountRemaining() {
+	i.AmountRemaining = decimal.Max(i.AmountDue.Sub(i.AmountPaid).Sub(i.AmountProcessing), decimal.Zero)
+
+	switch {
+	case i.AmountRemaining.IsPositive() && i.WrittenOffAt != nil:
+		i.PaymentStatus = types.InvoicePaymentStatusUncollectible
+	case i.AmountRemaining.IsZero() && i.AmountProcessing.IsPositive():
+		i.PaymentStatus = types.InvoicePaymentStatusProcessing
+	case i.AmountRemaining.IsZero():
+		i.PaymentStatus = types.InvoicePaymentStatusPaid
+	case i.AmountPaid.IsPositive():
+		i.PaymentStatus = types.InvoicePaymentStatusPartiallyPaid
+	default:
+		i.PaymentStatus = types.InvoicePaymentStatusPending
+	}
+}
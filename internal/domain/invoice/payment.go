@@ -0,0 +1,68 @@
+package invoice
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Payment is an amount collected against an invoice. The payments of an invoice
+// are its allocation ledger, the sum of the settled ones is the amount paid of
+// the invoice and the sum of all but the failed ones never exceeds its amount due
+type Payment struct {
+	ID         string `db:"id" json:"id"`
+	InvoiceID  string `db:"invoice_id" json:"invoice_id"`
+	CustomerID string `db:"customer_id" json:"customer_id"`
+
+	Amount   decimal.Decimal `db:"amount" json:"amount" swaggertype:"string"`
+	Currency string          `db:"currency" json:"currency"`
+
+	// PaymentMethodType is how the amount was paid ex card, bank_transfer or check
+	PaymentMethodType string `db:"payment_method_type" json:"payment_method_type,omitempty"`
+
+	// Reference identifies the payment outside Flexprice ex the charge ID at the
+	// gateway or the reference of the bank transfer
+	Reference string `db:"reference" json:"reference,omitempty"`
+
+	// ConnectionID and GatewayPaymentID are set for payments collected at a payment
+	// gateway, their refunds are executed at the gateway
+	ConnectionID     string `db:"connection_id" json:"connection_id,omitempty"`
+	GatewayPaymentID string `db:"gateway_payment_id" json:"gateway_payment_id,omitempty"`
+
+	// AmountRefunded is the sum of the refunds of the payment, it never exceeds the amount
+	AmountRefunded decimal.Decimal `db:"amount_refunded" json:"amount_refunded" swaggertype:"string"`
+
+	// PaidAt is when the customer paid, which may be before it was recorded
+	PaidAt time.Time `db:"paid_at" json:"paid_at"`
+
+	// PaymentStatus is SUCCEEDED for the payments settled when recorded, bank
+	// debits are SCHEDULED then PROCESSING until they clear or fail
+	PaymentStatus types.PaymentStatus `db:"payment_status" json:"payment_status"`
+
+	// MandateID and ChargeDate are set for bank debits, the customer was notified
+	// that the mandate is debited on the charge date
+	MandateID  string     `db:"mandate_id" json:"mandate_id,omitempty"`
+	ChargeDate *time.Time `db:"charge_date" json:"charge_date,omitempty"`
+
+	// LeaseUntil is when the claim of the debit collection job on a due debit
+	// expires, the debit can then be claimed again
+	LeaseUntil *time.Time `db:"lease_until" json:"-"`
+
+	// FailureCode, FailureReason and DunningAction are set for failed bank debits,
+	// the code is the return code of the scheme or the failure code of the gateway
+	FailureCode   string              `db:"failure_code" json:"failure_code,omitempty"`
+	FailureReason string              `db:"failure_reason" json:"failure_reason,omitempty"`
+	DunningAction types.DunningAction `db:"dunning_action" json:"dunning_action,omitempty"`
+
+	types.BaseModel
+}
+
+// Refundable returns the part of the payment not refunded yet, nothing for the
+// payments that are not settled
+func (p *Payment) Refundable() decimal.Decimal {
+	if p.PaymentStatus != types.PaymentStatusSucceeded {
+		return decimal.Zero
+	}
+	return p.Amount.Sub(p.AmountRefunded)
+}
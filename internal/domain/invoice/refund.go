@@ -0,0 +1,33 @@
+package invoice
+
+import (
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Refund returns part or all of a payment to the customer. Refunds of payments
+// collected at a gateway are executed there, others are only recorded
+type Refund struct {
+	ID         string `db:"id" json:"id"`
+	PaymentID  string `db:"payment_id" json:"payment_id"`
+	InvoiceID  string `db:"invoice_id" json:"invoice_id"`
+	CustomerID string `db:"customer_id" json:"customer_id"`
+
+	Amount   decimal.Decimal `db:"amount" json:"amount" swaggertype:"string"`
+	Currency string          `db:"currency" json:"currency"`
+	Reason   string          `db:"reason" json:"reason,omitempty"`
+
+	RefundStatus types.RefundStatus `db:"refund_status" json:"refund_status"`
+
+	// GatewayRefundID is the ID of the refund at the gateway ex re_3NqK2a
+	GatewayRefundID string `db:"gateway_refund_id" json:"gateway_refund_id,omitempty"`
+
+	// FailureReason is why the gateway did not refund the payment
+	FailureReason string `db:"failure_reason" json:"failure_reason,omitempty"`
+
+	// CreditNoteID is the credit note issued for the refunded amount against the
+	// invoice of the payment, if one was requested
+	CreditNoteID string `db:"credit_note_id" json:"credit_note_id,omitempty"`
+
+	types.BaseModel
+}
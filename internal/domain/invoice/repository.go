@@ -0,0 +1,86 @@
+package invoice
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	// Create persists the invoice along with its line items
+	Create(ctx context.Context, invoice *Invoice) error
+	// Get returns the invoice along with its line items
+	Get(ctx context.Context, id string) (*Invoice, error)
+	// List returns the invoices without their line items
+	List(ctx context.Context, filter *types.InvoiceFilter) ([]*Invoice, error)
+	// Update updates the invoice header fields
+	Update(ctx context.Context, invoice *Invoice) error
+
+	// GetForUpdate returns the invoice header locked until the end of the
+	// transaction it is called in
+	GetForUpdate(ctx context.Context, id string) (*Invoice, error)
+
+	// CreateLineItem adds a line item to an existing invoice
+	CreateLineItem(ctx context.Context, item *InvoiceLineItem) error
+	// UpdateLineItem updates the display name, amount, quantity and metadata of a line item
+	UpdateLineItem(ctx context.Context, item *InvoiceLineItem) error
+	// DeleteLineItem removes a line item from its invoice
+	DeleteLineItem(ctx context.Context, item *InvoiceLineItem) error
+
+	// ListFinalizedLineItems returns the line items of the finalized invoices
+	// whose service period starts in [start, end). Line items without a period
+	// fall back on the period of their invoice, then on its finalization time
+	ListFinalizedLineItems(ctx context.Context, start, end time.Time) ([]*InvoiceLineItem, error)
+
+	// ListOutstanding returns the finalized invoices of the tenant with an
+	// amount remaining to be paid that were not written off, of the customer
+	// when customerID is set, oldest first
+	ListOutstanding(ctx context.Context, customerID string) ([]*Invoice, error)
+	// ListWrittenOff returns the invoices of the tenant written off in the
+	// period of the filter, oldest write-off first
+	ListWrittenOff(ctx context.Context, filter *types.WriteOffFilter) ([]*Invoice, error)
+	// ListInvoicingTenantIDs returns the tenants with finalized invoices
+	ListInvoicingTenantIDs(ctx context.Context) ([]string, error)
+	// ListForReconciliation returns the subscription invoices of all the tenants
+	// whose usage can still change: the drafts and held ones, and the finalized
+	// ones with a period ending after since
+	ListForReconciliation(ctx context.Context, since time.Time) ([]*Invoice, error)
+
+	CreateCreditAllocation(ctx context.Context, allocation *CreditAllocation) error
+	// ListCreditAllocations returns the allocations of a credit invoice, oldest first
+	ListCreditAllocations(ctx context.Context, creditInvoiceID string) ([]*CreditAllocation, error)
+
+	CreatePayment(ctx context.Context, payment *Payment) error
+	GetPayment(ctx context.Context, id string) (*Payment, error)
+	// UpdatePayment updates the amount refunded, the settlement and the gateway
+	// payment of a payment
+	UpdatePayment(ctx context.Context, payment *Payment) error
+	// GetPaymentByGatewayID returns the payment with the ID of a payment at a gateway
+	GetPaymentByGatewayID(ctx context.Context, gatewayPaymentID string) (*Payment, error)
+	// ClaimDueDebits returns up to limit bank debits of all the tenants which are
+	// scheduled with a charge date before now, or processing without a gateway
+	// payment, and whose lease expired. They are leased until now+lease so that
+	// concurrent schedulers do not submit them twice
+	ClaimDueDebits(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Payment, error)
+	// ListPayments returns the payments of an invoice in the order they were paid
+	ListPayments(ctx context.Context, invoiceID string) ([]*Payment, error)
+
+	CreateRefund(ctx context.Context, refund *Refund) error
+	// ListRefunds returns the refunds of a payment, oldest first
+	ListRefunds(ctx context.Context, paymentID string) ([]*Refund, error)
+	// GetRefundByGatewayID returns the refund with the ID of a refund at a gateway
+	GetRefundByGatewayID(ctx context.Context, gatewayRefundID string) (*Refund, error)
+	// UpdateRefund updates the status and the failure reason of a refund
+	UpdateRefund(ctx context.Context, refund *Refund) error
+
+	CreateDispute(ctx context.Context, dispute *Dispute) error
+	GetDispute(ctx context.Context, id string) (*Dispute, error)
+	// GetActiveDispute returns the dispute of an invoice that is not resolved,
+	// nil when there is none
+	GetActiveDispute(ctx context.Context, invoiceID string) (*Dispute, error)
+	// ListDisputes returns the disputes of an invoice, oldest first
+	ListDisputes(ctx context.Context, invoiceID string) ([]*Dispute, error)
+	// UpdateDispute updates the status and the resolution of a dispute
+	UpdateDispute(ctx context.Context, dispute *Dispute) error
+}
@@ -0,0 +1,25 @@
+package job
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Run is a run of a scheduled job. Jobs run across all the tenants so runs
+// are not scoped to a tenant
+type Run struct {
+	ID      string             `db:"id" json:"id"`
+	JobName string             `db:"job_name" json:"job_name"`
+	Trigger types.JobTrigger   `db:"trigger_type" json:"trigger"`
+	Status  types.JobRunStatus `db:"status" json:"status"`
+
+	// TriggeredBy is the user who triggered a manual run
+	TriggeredBy string `db:"triggered_by" json:"triggered_by,omitempty"`
+
+	StartedAt  time.Time  `db:"started_at" json:"started_at"`
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+
+	// Error holds the failure reason of a failed run
+	Error string `db:"error" json:"error,omitempty"`
+}
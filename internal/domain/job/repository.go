@@ -0,0 +1,12 @@
+package job
+
+import "context"
+
+// RunRepository stores the run history of the scheduled jobs
+type RunRepository interface {
+	Create(ctx context.Context, run *Run) error
+	Update(ctx context.Context, run *Run) error
+
+	// ListRuns returns the latest runs of a job first, at most limit of them
+	ListRuns(ctx context.Context, jobName string, limit int) ([]*Run, error)
+}
@@ -0,0 +1,27 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Sync is the reconciliation status of an invoice or a credit note with the
+// ledger of a connection
+type Sync struct {
+	ID           string `db:"id" json:"id"`
+	ConnectionID string `db:"connection_id" json:"connection_id"`
+
+	// EntityType is invoice or credit_note and EntityID the ID of the invoice
+	EntityType types.SyncEntityType `db:"entity_type" json:"entity_type"`
+	EntityID   string               `db:"entity_id" json:"entity_id"`
+
+	SyncStatus types.LedgerSyncStatus `db:"sync_status" json:"sync_status"`
+
+	// ExternalID is the ID of the document in the ledger once synced
+	ExternalID string     `db:"external_id" json:"external_id,omitempty"`
+	Attempts   int        `db:"attempts" json:"attempts"`
+	LastError  string     `db:"last_error" json:"last_error,omitempty"`
+	SyncedAt   *time.Time `db:"synced_at" json:"synced_at,omitempty"`
+	types.BaseModel
+}
@@ -0,0 +1,17 @@
+package ledger
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	// Upsert records the sync, or resets the existing sync of the entity with the
+	// connection to the status of sync, keeping its ID and external ID
+	Upsert(ctx context.Context, sync *Sync) error
+	Update(ctx context.Context, sync *Sync) error
+	// ListByEntity returns the syncs of the entity with every connection
+	ListByEntity(ctx context.Context, entityID string) ([]*Sync, error)
+	List(ctx context.Context, filter *types.LedgerSyncFilter) ([]*Sync, error)
+}
@@ -0,0 +1,91 @@
+package legalentity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/sequence"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// LegalEntity is one of the companies of the tenant invoices are issued from.
+// Subscriptions and customers are assigned to an entity, the invoices of the
+// others are issued from the default entity of the tenant, if any
+type LegalEntity struct {
+	ID string `db:"id" json:"id"`
+
+	// Name is the registered name of the company printed on its invoices
+	Name string `db:"name" json:"name"`
+
+	Address *customer.Address `db:"address" json:"address"`
+
+	// TaxIDs are the tax registration numbers of the company ex its EU VAT number
+	TaxIDs customer.TaxIDs `db:"tax_ids" json:"tax_ids"`
+
+	// InvoicePrefix, InvoiceSeparator, InvoicePadding and InvoiceResetYearly are
+	// the format of the numbers of the invoices of the entity, drawn from a
+	// sequence of its own
+	InvoicePrefix      string `db:"invoice_prefix" json:"invoice_prefix"`
+	InvoiceSeparator   string `db:"invoice_separator" json:"invoice_separator"`
+	InvoicePadding     int    `db:"invoice_padding" json:"invoice_padding"`
+	InvoiceResetYearly bool   `db:"invoice_reset_yearly" json:"invoice_reset_yearly"`
+
+	// BankDetails is the account the customers of the entity pay by bank transfer to
+	BankDetails *BankDetails `db:"bank_details" json:"bank_details,omitempty"`
+
+	// PDFTemplate is the template the invoice PDFs of the entity are rendered
+	// with, the default template when empty
+	PDFTemplate string `db:"pdf_template" json:"pdf_template,omitempty"`
+
+	// IsDefault marks the entity issuing the invoices of the customers and
+	// subscriptions not assigned to one. A tenant has at most one
+	IsDefault bool `db:"is_default" json:"is_default"`
+
+	types.BaseModel
+}
+
+// BankDetails is a bank account, identified by its IBAN and BIC or by its
+// account and routing numbers
+type BankDetails struct {
+	AccountHolder string `json:"account_holder"`
+	BankName      string `json:"bank_name,omitempty"`
+	IBAN          string `json:"iban,omitempty"`
+	BIC           string `json:"bic,omitempty"`
+	AccountNumber string `json:"account_number,omitempty"`
+	RoutingNumber string `json:"routing_number,omitempty"`
+}
+
+// InvoiceNumbering is the numbering of the invoices of the entity. Its
+// sequence is scoped to the entity so that numbers are never shared with the
+// tenant or the other entities
+func (e *LegalEntity) InvoiceNumbering() *sequence.InvoiceNumberingConfig {
+	return &sequence.InvoiceNumberingConfig{
+		TenantID:    e.TenantID,
+		Scope:       e.ID,
+		Prefix:      e.InvoicePrefix,
+		Separator:   e.InvoiceSeparator,
+		Padding:     e.InvoicePadding,
+		ResetYearly: e.InvoiceResetYearly,
+	}
+}
+
+// Scanner/Valuer implementations for BankDetails
+func (b *BankDetails) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb bank details")
+	}
+	return json.Unmarshal(bytes, b)
+}
+
+func (b *BankDetails) Value() (driver.Value, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return json.Marshal(b)
+}
@@ -0,0 +1,17 @@
+package legalentity
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, entity *LegalEntity) error
+	Get(ctx context.Context, id string) (*LegalEntity, error)
+	// GetDefault returns the default entity of the tenant, nil when it has none
+	GetDefault(ctx context.Context) (*LegalEntity, error)
+	// List returns the entities of the tenant ordered by name
+	List(ctx context.Context) ([]*LegalEntity, error)
+	Update(ctx context.Context, entity *LegalEntity) error
+	// InUse reports whether customers, or subscriptions that are not cancelled,
+	// are assigned to the entity
+	InUse(ctx context.Context, id string) (bool, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,38 @@
+package mandate
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Mandate is the authorization of a customer to debit a bank account, one of
+// their payment methods, under a direct debit scheme
+type Mandate struct {
+	ID         string `db:"id" json:"id"`
+	CustomerID string `db:"customer_id" json:"customer_id"`
+
+	// PaymentMethodID is the bank account payment method of the customer debited
+	PaymentMethodID string `db:"payment_method_id" json:"payment_method_id"`
+
+	Scheme types.MandateScheme `db:"scheme" json:"scheme"`
+
+	// Reference identifies the mandate to the bank of the customer ex the unique
+	// mandate reference of SEPA
+	Reference string `db:"reference" json:"reference"`
+
+	MandateStatus types.MandateStatus `db:"mandate_status" json:"mandate_status"`
+
+	// PreNotificationDays is how many days before each debit the customer is
+	// notified, the default of the scheme unless the mandate agrees otherwise
+	PreNotificationDays int `db:"pre_notification_days" json:"pre_notification_days"`
+
+	SignedAt time.Time `db:"signed_at" json:"signed_at"`
+
+	// RevokedAt and RevocationReason are set once the mandate is no longer active,
+	// the reason is the failure code of the debit that revoked it, if any
+	RevokedAt        *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	RevocationReason string     `db:"revocation_reason" json:"revocation_reason,omitempty"`
+
+	types.BaseModel
+}
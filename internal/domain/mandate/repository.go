@@ -0,0 +1,12 @@
+package mandate
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, mandate *Mandate) error
+	Get(ctx context.Context, id string) (*Mandate, error)
+	// ListByCustomer returns the mandates of the customer, oldest first
+	ListByCustomer(ctx context.Context, customerID string) ([]*Mandate, error)
+	// Update updates the status and the revocation of a mandate
+	Update(ctx context.Context, mandate *Mandate) error
+}
@@ -0,0 +1,254 @@
+package meter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// Formula defines the usage of a derived meter as an arithmetic expression over
+// the usage of other meters, for ex "3 * gpu_seconds + 0.5 * cpu_seconds"
+type Formula struct {
+	// Expression supports numbers, variables, + - * / and parentheses
+	Expression string `json:"expression"`
+
+	// Variables maps each variable of the expression to the ID of the meter
+	// whose usage it stands for
+	Variables map[string]string `json:"variables"`
+}
+
+// Validate parses the expression and checks that its variables are exactly the
+// variables of the formula
+func (f *Formula) Validate() error {
+	expr, err := f.parse()
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	expr.variables(used)
+	for name := range used {
+		if f.Variables[name] == "" {
+			return fmt.Errorf("variable %s of the formula is not mapped to a meter", name)
+		}
+	}
+	for name := range f.Variables {
+		if !used[name] {
+			return fmt.Errorf("variable %s is not used in the formula", name)
+		}
+	}
+	return nil
+}
+
+// MeterIDs returns the IDs of the meters of the formula, sorted
+func (f *Formula) MeterIDs() []string {
+	seen := make(map[string]bool, len(f.Variables))
+	ids := make([]string, 0, len(f.Variables))
+	for _, id := range f.Variables {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Evaluate computes the formula with the usage of each meter, by meter ID.
+// Meters without usage count as zero and so does a division by zero, so that
+// a window where a denominator meter had no usage does not fail billing
+func (f *Formula) Evaluate(usage map[string]decimal.Decimal) (decimal.Decimal, error) {
+	expr, err := f.parse()
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	values := make(map[string]decimal.Decimal, len(f.Variables))
+	for name, meterID := range f.Variables {
+		values[name] = usage[meterID]
+	}
+	return expr.eval(values), nil
+}
+
+func (f *Formula) parse() (formulaNode, error) {
+	if strings.TrimSpace(f.Expression) == "" {
+		return nil, fmt.Errorf("formula expression is required")
+	}
+
+	p := &formulaParser{input: []rune(f.Expression)}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpaces(); p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d of the formula", p.input[p.pos], p.pos+1)
+	}
+	return expr, nil
+}
+
+type formulaNode interface {
+	eval(values map[string]decimal.Decimal) decimal.Decimal
+	variables(into map[string]bool)
+}
+
+type formulaNumber struct{ value decimal.Decimal }
+
+func (n formulaNumber) eval(map[string]decimal.Decimal) decimal.Decimal { return n.value }
+func (n formulaNumber) variables(map[string]bool)                       {}
+
+type formulaVariable struct{ name string }
+
+func (v formulaVariable) eval(values map[string]decimal.Decimal) decimal.Decimal {
+	return values[v.name]
+}
+func (v formulaVariable) variables(into map[string]bool) { into[v.name] = true }
+
+type formulaNegation struct{ operand formulaNode }
+
+func (n formulaNegation) eval(values map[string]decimal.Decimal) decimal.Decimal {
+	return n.operand.eval(values).Neg()
+}
+func (n formulaNegation) variables(into map[string]bool) { n.operand.variables(into) }
+
+type formulaBinary struct {
+	op          rune
+	left, right formulaNode
+}
+
+func (b formulaBinary) eval(values map[string]decimal.Decimal) decimal.Decimal {
+	left, right := b.left.eval(values), b.right.eval(values)
+	switch b.op {
+	case '+':
+		return left.Add(right)
+	case '-':
+		return left.Sub(right)
+	case '*':
+		return left.Mul(right)
+	default:
+		if right.IsZero() {
+			return decimal.Zero
+		}
+		return left.Div(right)
+	}
+}
+
+func (b formulaBinary) variables(into map[string]bool) {
+	b.left.variables(into)
+	b.right.variables(into)
+}
+
+// formulaParser is a recursive descent parser of
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = number | variable | "(" expr ")" | "-" factor
+type formulaParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *formulaParser) parseExpr() (formulaNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.consume('+', '-')
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *formulaParser) parseTerm() (formulaNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.consume('*', '/')
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *formulaParser) parseFactor() (formulaNode, error) {
+	if _, ok := p.consume('-'); ok {
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return formulaNegation{operand: operand}, nil
+	}
+
+	if _, ok := p.consume('('); ok {
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.consume(')'); !ok {
+			return nil, fmt.Errorf("missing ) at position %d of the formula", p.pos+1)
+		}
+		return expr, nil
+	}
+
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of the formula")
+	}
+
+	start := p.pos
+	switch r := p.input[p.pos]; {
+	case unicode.IsDigit(r) || r == '.':
+		for p.pos < len(p.input) && (unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		value, err := decimal.NewFromString(string(p.input[start:p.pos]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q in the formula", string(p.input[start:p.pos]))
+		}
+		return formulaNumber{value: value}, nil
+	case unicode.IsLetter(r) || r == '_':
+		for p.pos < len(p.input) && (unicode.IsLetter(p.input[p.pos]) || unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '_') {
+			p.pos++
+		}
+		return formulaVariable{name: string(p.input[start:p.pos])}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d of the formula", r, p.pos+1)
+	}
+}
+
+// consume skips spaces and the next rune when it is one of ops
+func (p *formulaParser) consume(ops ...rune) (rune, bool) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0, false
+	}
+	for _, op := range ops {
+		if p.input[p.pos] == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return 0, false
+}
+
+func (p *formulaParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
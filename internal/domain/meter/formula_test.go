@@ -0,0 +1,53 @@
+package meter
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormula(t *testing.T) {
+	usage := map[string]decimal.Decimal{
+		"meter_gpu": decimal.NewFromInt(10),
+		"meter_cpu": decimal.NewFromInt(4),
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{"precedence", "3 * gpu_seconds + 0.5 * cpu_seconds", "32"},
+		{"parentheses", "(gpu_seconds - cpu_seconds) / 2", "3"},
+		{"negation", "-gpu_seconds + 2*cpu_seconds", "-2"},
+		{"division by zero", "gpu_seconds / (cpu_seconds - 4)", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formula{
+				Expression: tt.expression,
+				Variables:  map[string]string{"gpu_seconds": "meter_gpu", "cpu_seconds": "meter_cpu"},
+			}
+			require.NoError(t, f.Validate())
+
+			value, err := f.Evaluate(usage)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value.String())
+		})
+	}
+}
+
+func TestFormulaValidate(t *testing.T) {
+	variables := map[string]string{"gpu_seconds": "meter_gpu"}
+
+	assert.Error(t, (&Formula{Expression: "", Variables: variables}).Validate())
+	assert.Error(t, (&Formula{Expression: "3 * gpu_seconds +", Variables: variables}).Validate())
+	assert.Error(t, (&Formula{Expression: "(gpu_seconds", Variables: variables}).Validate())
+	assert.Error(t, (&Formula{Expression: "gpu_seconds % 2", Variables: variables}).Validate())
+	assert.Error(t, (&Formula{Expression: "gpu_seconds + cpu_seconds", Variables: variables}).Validate(), "unmapped variable")
+	assert.Error(t, (&Formula{Expression: "2", Variables: variables}).Validate(), "unused variable")
+	assert.Error(t, (&Formula{Expression: "1..2 * gpu_seconds", Variables: variables}).Validate())
+	assert.NoError(t, (&Formula{Expression: " 2*gpu_seconds ", Variables: variables}).Validate())
+}
@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/flexprice/flexprice/internal/types"
-	"github.com/google/uuid"
 )
 
 type Meter struct {
@@ -33,6 +32,11 @@ type Meter struct {
 	// total API requests do.
 	ResetUsage types.ResetUsage `db:"reset_usage" json:"reset_usage"`
 
+	// Formula makes the meter a derived meter, whose usage is computed from the
+	// usage of other meters instead of aggregated from events. Derived meters
+	// have no event name and the FORMULA aggregation type
+	Formula *Formula `db:"formula" json:"formula,omitempty"`
+
 	// BaseModel is the base model for the meter
 	types.BaseModel
 }
@@ -55,6 +59,43 @@ type Aggregation struct {
 	// Field is the key in $event.properties on which the aggregation is to be applied
 	// For ex if the aggregation type is sum for API usage, the field could be "duration_ms"
 	Field string `json:"field,omitempty"`
+
+	// Precision applies to COUNT_UNIQUE only. Zero counts the distinct values
+	// exactly, which takes memory in proportion to their number. From 12 to 20
+	// they are counted approximately with a HyperLogLog sketch of that
+	// precision, for high cardinality fields like device IDs
+	Precision int `json:"precision,omitempty"`
+
+	// Percentile applies to PERCENTILE only, it is the percentile of the values
+	// of the field the usage is, greater than 0 and up to 100. For ex 99 for
+	// the p99 latency
+	Percentile float64 `json:"percentile,omitempty"`
+}
+
+// Validate validates the aggregation of an event meter
+func (a Aggregation) Validate() error {
+	if !a.Type.Validate() {
+		return fmt.Errorf("invalid aggregation type: %s", a.Type)
+	}
+	if a.Type.RequiresField() && a.Field == "" {
+		return fmt.Errorf("field is required for aggregation type: %s", a.Type)
+	}
+	if a.Precision != 0 {
+		if a.Type != types.AggregationCountUnique {
+			return fmt.Errorf("precision is only supported for aggregation type: %s", types.AggregationCountUnique)
+		}
+		if a.Precision < types.MinUniquePrecision || a.Precision > types.MaxUniquePrecision {
+			return fmt.Errorf("precision must be between %d and %d", types.MinUniquePrecision, types.MaxUniquePrecision)
+		}
+	}
+	if a.Type == types.AggregationPercentile {
+		if a.Percentile <= 0 || a.Percentile > 100 {
+			return fmt.Errorf("percentile must be greater than 0 and at most 100")
+		}
+	} else if a.Percentile != 0 {
+		return fmt.Errorf("percentile is only supported for aggregation type: %s", types.AggregationPercentile)
+	}
+	return nil
 }
 
 // Validate validates the meter configuration
@@ -65,14 +106,24 @@ func (m *Meter) Validate() error {
 	if m.Name == "" {
 		return fmt.Errorf("name is required")
 	}
+	if m.IsDerived() {
+		if m.EventName != "" || len(m.Filters) > 0 {
+			return fmt.Errorf("derived meters can not have an event_name or filters")
+		}
+		if m.Aggregation.Type != types.AggregationFormula {
+			return fmt.Errorf("aggregation type of derived meters must be %s", types.AggregationFormula)
+		}
+		if err := m.Formula.Validate(); err != nil {
+			return fmt.Errorf("invalid formula: %w", err)
+		}
+		return nil
+	}
+
 	if m.EventName == "" {
 		return fmt.Errorf("event_name is required")
 	}
-	if !m.Aggregation.Type.Validate() {
-		return fmt.Errorf("invalid aggregation type: %s", m.Aggregation.Type)
-	}
-	if m.Aggregation.Type.RequiresField() && m.Aggregation.Field == "" {
-		return fmt.Errorf("field is required for aggregation type: %s", m.Aggregation.Type)
+	if err := m.Aggregation.Validate(); err != nil {
+		return err
 	}
 
 	for _, filter := range m.Filters {
@@ -86,11 +137,16 @@ func (m *Meter) Validate() error {
 	return nil
 }
 
+// IsDerived reports whether the usage of the meter is a formula over other meters
+func (m *Meter) IsDerived() bool {
+	return m.Formula != nil
+}
+
 // Constructor for creating new meters with defaults
 func NewMeter(name string, tenantID, createdBy string) *Meter {
 	now := time.Now().UTC()
 	return &Meter{
-		ID:   uuid.New().String(),
+		ID:   types.GenerateUUID(),
 		Name: name,
 		BaseModel: types.BaseModel{
 			TenantID:  tenantID,
@@ -0,0 +1,70 @@
+package meter
+
+import (
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregationValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		aggregation Aggregation
+		wantErr     bool
+	}{
+		{
+			name:        "exact unique count",
+			aggregation: Aggregation{Type: types.AggregationCountUnique, Field: "device_id"},
+		},
+		{
+			name:        "approximate unique count",
+			aggregation: Aggregation{Type: types.AggregationCountUnique, Field: "device_id", Precision: 14},
+		},
+		{
+			name:        "unique count without field",
+			aggregation: Aggregation{Type: types.AggregationCountUnique},
+			wantErr:     true,
+		},
+		{
+			name:        "precision out of range",
+			aggregation: Aggregation{Type: types.AggregationCountUnique, Field: "device_id", Precision: 8},
+			wantErr:     true,
+		},
+		{
+			name:        "p99",
+			aggregation: Aggregation{Type: types.AggregationPercentile, Field: "latency_ms", Percentile: 99},
+		},
+		{
+			name:        "percentile without percentile",
+			aggregation: Aggregation{Type: types.AggregationPercentile, Field: "latency_ms"},
+			wantErr:     true,
+		},
+		{
+			name:        "percentile above 100",
+			aggregation: Aggregation{Type: types.AggregationPercentile, Field: "latency_ms", Percentile: 101},
+			wantErr:     true,
+		},
+		{
+			name:        "percentile of a count",
+			aggregation: Aggregation{Type: types.AggregationCount, Percentile: 99},
+			wantErr:     true,
+		},
+		{
+			name:        "precision of a sum",
+			aggregation: Aggregation{Type: types.AggregationSum, Field: "tokens", Precision: 14},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.aggregation.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -3,8 +3,17 @@ package meter
 import "context"
 
 type Repository interface {
+	// CreateMeter creates the meter with its first version
 	CreateMeter(ctx context.Context, meter *Meter) error
 	GetMeter(ctx context.Context, id string) (*Meter, error)
 	GetAllMeters(ctx context.Context) ([]*Meter, error)
 	DisableMeter(ctx context.Context, id string) error
+
+	// CreateVersion creates a version of a meter. A version without an end
+	// becomes the definition of the meter
+	CreateVersion(ctx context.Context, version *MeterVersion) error
+	// UpdateVersion saves the re-aggregation status of a version
+	UpdateVersion(ctx context.Context, version *MeterVersion) error
+	// ListVersions returns the versions of a meter by version number
+	ListVersions(ctx context.Context, meterID string) ([]*MeterVersion, error)
 }
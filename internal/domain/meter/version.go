@@ -0,0 +1,138 @@
+package meter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// MeterVersion is a definition of a meter effective over a time range. Usage at
+// a time is aggregated with the newest version whose range holds it, so a new
+// definition only changes the usage after its effective date. The first
+// version of every meter is effective since the zero time
+type MeterVersion struct {
+	ID      string `db:"id" json:"id"`
+	MeterID string `db:"meter_id" json:"meter_id"`
+
+	// Version numbers the versions of a meter from 1 in order of creation
+	Version int `db:"version" json:"version"`
+
+	Aggregation Aggregation `db:"aggregation" json:"aggregation"`
+	Filters     []Filter    `db:"filters" json:"filters"`
+
+	// EffectiveFrom is the start of the usage aggregated with the version
+	EffectiveFrom time.Time `db:"effective_from" json:"effective_from"`
+
+	// EffectiveTo ends the range of a re-aggregation, other versions stay
+	// effective until a newer one
+	EffectiveTo *time.Time `db:"effective_to" json:"effective_to,omitempty"`
+
+	// ReaggregationStatus tracks the recomputation of the rolled up usage of a
+	// re-aggregation, it is empty for other versions
+	ReaggregationStatus types.TaskStatus `db:"reaggregation_status" json:"reaggregation_status,omitempty"`
+	ReaggregationError  *string          `db:"reaggregation_error" json:"reaggregation_error,omitempty"`
+
+	types.BaseModel
+}
+
+// NewVersion returns the first version of a meter, holding its definition
+func NewVersion(m *Meter) *MeterVersion {
+	return &MeterVersion{
+		ID:          types.GenerateUUID(),
+		MeterID:     m.ID,
+		Version:     1,
+		Aggregation: m.Aggregation,
+		Filters:     m.Filters,
+		BaseModel:   m.BaseModel,
+	}
+}
+
+// Validate validates the definition of the version
+func (v *MeterVersion) Validate() error {
+	if err := v.Aggregation.Validate(); err != nil {
+		return err
+	}
+	for _, filter := range v.Filters {
+		if filter.Key == "" {
+			return fmt.Errorf("filter key cannot be empty")
+		}
+		if len(filter.Values) == 0 {
+			return fmt.Errorf("filter values cannot be empty for key: %s", filter.Key)
+		}
+	}
+	if v.EffectiveTo != nil && !v.EffectiveFrom.Before(*v.EffectiveTo) {
+		return fmt.Errorf("effective_to must be after effective_from")
+	}
+	return nil
+}
+
+// covers reports whether usage at t is in the range of the version
+func (v *MeterVersion) covers(t time.Time) bool {
+	return !t.Before(v.EffectiveFrom) && (v.EffectiveTo == nil || t.Before(*v.EffectiveTo))
+}
+
+// Segment is a part of a time range aggregated with one definition of a meter
+type Segment struct {
+	// Start and End bound the segment, zero when the range was unbounded
+	Start time.Time
+	End   time.Time
+
+	// Version is the number of the version of the segment, 0 for the
+	// definition of a meter without versions
+	Version     int
+	Aggregation Aggregation
+	Filters     []Filter
+}
+
+// Segments splits [start, end) at the effective dates of the versions of the
+// meter, each segment with the newest version covering it. A zero end is
+// unbounded. Without versions the definition of the meter covers the range
+func Segments(m *Meter, versions []*MeterVersion, start, end time.Time) []Segment {
+	bounds := []time.Time{start}
+	for _, v := range versions {
+		for _, t := range []*time.Time{&v.EffectiveFrom, v.EffectiveTo} {
+			if t != nil && t.After(start) && (end.IsZero() || t.Before(end)) {
+				bounds = append(bounds, *t)
+			}
+		}
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Before(bounds[j]) })
+
+	var segments []Segment
+	for i, from := range bounds {
+		segment := Segment{Start: from, End: end, Aggregation: m.Aggregation, Filters: m.Filters}
+		if i+1 < len(bounds) {
+			segment.End = bounds[i+1]
+		}
+		if !segment.End.IsZero() && !from.Before(segment.End) {
+			// Versions sharing an effective date
+			continue
+		}
+		if active := versionAt(versions, from); active != nil {
+			segment.Version = active.Version
+			segment.Aggregation = active.Aggregation
+			segment.Filters = active.Filters
+		}
+
+		if n := len(segments); n > 0 && segments[n-1].Version == segment.Version {
+			segments[n-1].End = segment.End
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+// versionAt returns the newest version covering t
+func versionAt(versions []*MeterVersion, t time.Time) *MeterVersion {
+	var active *MeterVersion
+	for _, v := range versions {
+		if v.covers(t) && (active == nil || v.Version > active.Version) {
+			active = v
+		}
+	}
+	return active
+}
@@ -0,0 +1,35 @@
+package meter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegments(t *testing.T) {
+	at := func(day int) time.Time {
+		return time.Date(2024, time.March, day, 0, 0, 0, 0, time.UTC)
+	}
+	m := &Meter{Aggregation: Aggregation{Type: types.AggregationSum, Field: "v2"}}
+	reaggregatedTo := at(8)
+	versions := []*MeterVersion{
+		{Version: 1, Aggregation: Aggregation{Type: types.AggregationSum, Field: "v1"}},
+		{Version: 2, Aggregation: Aggregation{Type: types.AggregationSum, Field: "v2"}, EffectiveFrom: at(10)},
+		{Version: 3, Aggregation: Aggregation{Type: types.AggregationSum, Field: "v2"}, EffectiveFrom: at(5), EffectiveTo: &reaggregatedTo},
+	}
+
+	segments := Segments(m, versions, at(1), time.Time{})
+	require.Len(t, segments, 4)
+	assert.Equal(t, []int{1, 3, 1, 2}, []int{segments[0].Version, segments[1].Version, segments[2].Version, segments[3].Version})
+	assert.Equal(t, at(5), segments[1].Start)
+	assert.Equal(t, at(8), segments[1].End)
+	assert.Equal(t, at(10), segments[3].Start)
+	assert.True(t, segments[3].End.IsZero())
+
+	segments = Segments(m, nil, at(1), at(2))
+	require.Len(t, segments, 1)
+	assert.Equal(t, "v2", segments[0].Aggregation.Field)
+}
@@ -0,0 +1,28 @@
+package paymentmethod
+
+import "github.com/flexprice/flexprice/internal/types"
+
+// PaymentMethod is a payment method of a customer vaulted at a payment gateway.
+// Only the reference and the details needed to display it are kept
+type PaymentMethod struct {
+	ID         string `db:"id" json:"id"`
+	CustomerID string `db:"customer_id" json:"customer_id"`
+
+	// ConnectionID is the connection of the gateway the payment method is vaulted at
+	ConnectionID string                    `db:"connection_id" json:"connection_id"`
+	Provider     types.IntegrationProvider `db:"provider" json:"provider"`
+
+	// GatewayPaymentMethodID is the ID of the payment method at the gateway ex pm_1NqK2a
+	GatewayPaymentMethodID string `db:"gateway_payment_method_id" json:"gateway_payment_method_id"`
+
+	// Type is the kind of payment method at the gateway ex card or sepa_debit
+	Type string `db:"type" json:"type"`
+
+	// Brand, Last4 and the expiry are only set for cards
+	Brand    string `db:"brand" json:"brand,omitempty"`
+	Last4    string `db:"last4" json:"last4,omitempty"`
+	ExpMonth int    `db:"exp_month" json:"exp_month,omitempty"`
+	ExpYear  int    `db:"exp_year" json:"exp_year,omitempty"`
+
+	types.BaseModel
+}
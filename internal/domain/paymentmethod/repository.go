@@ -0,0 +1,11 @@
+package paymentmethod
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, pm *PaymentMethod) error
+	Get(ctx context.Context, id string) (*PaymentMethod, error)
+	// ListByCustomer returns the attached payment methods of the customer, oldest first
+	ListByCustomer(ctx context.Context, customerID string) ([]*PaymentMethod, error)
+	Delete(ctx context.Context, id string) error
+}
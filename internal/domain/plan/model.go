@@ -1,6 +1,8 @@
 package plan
 
 import (
+	"time"
+
 	"github.com/flexprice/flexprice/internal/types"
 )
 
@@ -11,5 +13,10 @@ type Plan struct {
 	Description    string               `db:"description" json:"description"`
 	InvoiceCadence types.InvoiceCadence `db:"invoice_cadence" json:"invoice_cadence"`
 	TrialPeriod    int                  `db:"trial_period" json:"trial_period"`
+	Metadata       types.Metadata       `db:"metadata" json:"metadata,omitempty"`
+	// DeletedAt is when the plan was deleted, it can be restored until it is purged
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	// Version is incremented on every update and guards against concurrent writes
+	Version int `db:"version" json:"version"`
 	types.BaseModel
 }
@@ -2,6 +2,7 @@ package plan
 
 import (
 	"context"
+	"time"
 
 	"github.com/flexprice/flexprice/internal/types"
 )
@@ -11,5 +12,12 @@ type Repository interface {
 	Get(ctx context.Context, id string) (*Plan, error)
 	List(ctx context.Context, filter types.Filter) ([]*Plan, error)
 	Update(ctx context.Context, plan *Plan) error
+	// Delete marks the plan deleted, it is kept until purged
 	Delete(ctx context.Context, id string) error
+	// Restore publishes a deleted plan again
+	Restore(ctx context.Context, id string) error
+	// PurgeDeleted removes the plans of all the tenants deleted before the given
+	// time that no subscription or price refers to, and returns how many were
+	// removed. It is used by the purge job and is not scoped to the tenant in context
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
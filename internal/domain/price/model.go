@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/flexprice/flexprice/internal/types"
 	"github.com/shopspring/decimal"
@@ -74,6 +75,14 @@ type Price struct {
 	// Metadata is a jsonb field for additional information
 	Metadata JSONBMetadata `db:"metadata,jsonb" json:"metadata"` // JSONB field
 
+	// UnitCost is the cost of goods sold of one unit billed by the price, in the
+	// currency of the price. For usage prices it is the cost of one unit of the
+	// usage of the meter
+	UnitCost *decimal.Decimal `db:"unit_cost" json:"unit_cost,omitempty" swaggertype:"string"`
+
+	// DeletedAt is when the price was deleted, it can be restored until it is purged
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+
 	types.BaseModel
 }
 
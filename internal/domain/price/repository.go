@@ -2,6 +2,7 @@ package price
 
 import (
 	"context"
+	"time"
 
 	"github.com/flexprice/flexprice/internal/types"
 )
@@ -12,5 +13,13 @@ type Repository interface {
 	GetByPlanID(ctx context.Context, planID string) ([]*Price, error)
 	List(ctx context.Context, filter types.Filter) ([]*Price, error)
 	Update(ctx context.Context, price *Price) error
+	// Delete marks the price deleted, it is kept until purged
 	Delete(ctx context.Context, id string) error
+	// Restore publishes a deleted price again
+	Restore(ctx context.Context, id string) error
+	// PurgeDeleted removes the prices of all the tenants deleted before the given
+	// time that no subscription or invoice refers to, and returns how many were
+	// removed. It is used by the purge job and is not scoped to the tenant in
+	// context
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
@@ -0,0 +1,44 @@
+package pricebook
+
+import (
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// PriceBook is a named set of overrides of the prices of a plan, to experiment
+// with prices without cloning the plan. Subscriptions are put on a price book
+// when they are created and keep it for their lifetime
+type PriceBook struct {
+	ID     string `db:"id" json:"id"`
+	Name   string `db:"name" json:"name"`
+	PlanID string `db:"plan_id" json:"plan_id"`
+
+	// Segment assigns the price book to the new subscriptions of the customers
+	// of the segment, the segment metadata of the customer. Price books without
+	// a segment only apply to the subscriptions targeted at them
+	Segment string `db:"segment" json:"segment,omitempty"`
+
+	Overrides ratecard.Overrides `db:"overrides" json:"overrides"`
+
+	types.BaseModel
+}
+
+// Apply returns the price of the plan as overridden by the price book
+func (b *PriceBook) Apply(p *price.Price) *price.Price {
+	return b.Overrides.Apply(p)
+}
+
+// ForSegment picks the price book of the segment among the price books of a
+// plan. It returns nil when the segment has none
+func ForSegment(books []*PriceBook, segment string) *PriceBook {
+	if segment == "" {
+		return nil
+	}
+	for _, book := range books {
+		if book.Segment == segment {
+			return book
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package pricebook
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, book *PriceBook) error
+	// Get returns the price book, deleted or not. Deleted price books are no
+	// longer assigned but keep pricing the subscriptions created on them
+	Get(ctx context.Context, id string) (*PriceBook, error)
+	// ListByPlan returns the price books of the plan which are not deleted
+	ListByPlan(ctx context.Context, planID string) ([]*PriceBook, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,141 @@
+package ratecard
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// RateCard is a negotiated rate sheet overriding catalog prices for a customer,
+// or for a single subscription of the customer. Each contract renewal creates a
+// new version, the latest version in effect at the start of a billing period applies
+// until its end date, if any
+type RateCard struct {
+	ID         string `db:"id" json:"id"`
+	Name       string `db:"name" json:"name"`
+	CustomerID string `db:"customer_id" json:"customer_id"`
+
+	// SubscriptionID restricts the rate card to one subscription of the customer.
+	// Empty for rate cards covering all the subscriptions of the customer
+	SubscriptionID string `db:"subscription_id" json:"subscription_id,omitempty"`
+
+	// Version is incremented for each rate card of the same customer and subscription
+	Version       int       `db:"version" json:"version"`
+	EffectiveFrom time.Time `db:"effective_from" json:"effective_from"`
+	// EffectiveTo is the end of the contract, the catalog prices apply again to
+	// the billing periods starting from then unless a later version takes over
+	EffectiveTo *time.Time `db:"effective_to" json:"effective_to,omitempty"`
+
+	Overrides Overrides `db:"overrides" json:"overrides"`
+
+	types.BaseModel
+}
+
+// Override replaces the amount, or the tiers, of a catalog price. It is keyed
+// by price, or by meter to cover all the usage prices of the meter
+type Override struct {
+	PriceID string            `json:"price_id,omitempty"`
+	MeterID string            `json:"meter_id,omitempty"`
+	Amount  *decimal.Decimal  `json:"amount,omitempty" swaggertype:"string"`
+	Tiers   []price.PriceTier `json:"tiers,omitempty"`
+}
+
+type Overrides []Override
+
+func (o *Overrides) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb overrides")
+	}
+	return json.Unmarshal(bytes, o)
+}
+
+func (o Overrides) Value() (driver.Value, error) {
+	if o == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(o)
+}
+
+// Apply returns the price as negotiated on the rate card. The catalog price is
+// returned untouched when the rate card does not cover it, and copied otherwise
+func (rc *RateCard) Apply(p *price.Price) *price.Price {
+	return rc.Overrides.Apply(p)
+}
+
+// Apply returns the price with its override applied. An override of the price
+// takes precedence over an override of its meter
+func (o Overrides) Apply(p *price.Price) *price.Price {
+	override := o.overrideFor(p)
+	if override == nil {
+		return p
+	}
+
+	overridden := *p
+	if override.Amount != nil {
+		overridden.Amount = *override.Amount
+	}
+	if len(override.Tiers) > 0 {
+		overridden.Tiers = override.Tiers
+	}
+	return &overridden
+}
+
+func (o Overrides) overrideFor(p *price.Price) *Override {
+	var meterOverride *Override
+	for i := range o {
+		override := &o[i]
+		if override.PriceID != "" && override.PriceID == p.ID {
+			return override
+		}
+		if override.PriceID == "" && override.MeterID != "" && override.MeterID == p.MeterID && meterOverride == nil {
+			meterOverride = override
+		}
+	}
+	return meterOverride
+}
+
+// Effective picks the rate card applying to the subscription at the given time
+// among the rate cards of its customer. Rate cards of the subscription take
+// precedence over the ones of the customer, and the latest version started is
+// used unless it has ended. It returns nil when no rate card applies
+func Effective(cards []*RateCard, subscriptionID string, at time.Time) *RateCard {
+	var subscriptionCard, customerCard *RateCard
+	for _, card := range cards {
+		if card.EffectiveFrom.After(at) {
+			continue
+		}
+
+		switch card.SubscriptionID {
+		case subscriptionID:
+			if subscriptionCard == nil || card.Version > subscriptionCard.Version {
+				subscriptionCard = card
+			}
+		case "":
+			if customerCard == nil || card.Version > customerCard.Version {
+				customerCard = card
+			}
+		}
+	}
+
+	// An ended version does not bring back the versions it replaced
+	if subscriptionCard != nil && !subscriptionCard.endedAt(at) {
+		return subscriptionCard
+	}
+	if customerCard != nil && !customerCard.endedAt(at) {
+		return customerCard
+	}
+	return nil
+}
+
+func (rc *RateCard) endedAt(t time.Time) bool {
+	return rc.EffectiveTo != nil && !t.Before(*rc.EffectiveTo)
+}
@@ -0,0 +1,12 @@
+package ratecard
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, card *RateCard) error
+	Get(ctx context.Context, id string) (*RateCard, error)
+	// ListByCustomer returns all the published versions of the rate cards of the
+	// customer, including the ones of its subscriptions, ordered by version
+	ListByCustomer(ctx context.Context, customerID string) ([]*RateCard, error)
+	Delete(ctx context.Context, id string) error
+}
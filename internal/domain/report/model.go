@@ -0,0 +1,153 @@
+package report
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/lib/pq"
+)
+
+// Template is a report defined by a tenant: the columns of the records of an
+// entity matching its filters, optionally aggregated by its group-bys, run on
+// demand or on its schedule and delivered as CSV to its destinations
+type Template struct {
+	ID     string             `db:"id" json:"id"`
+	Name   string             `db:"name" json:"name"`
+	Entity types.ReportEntity `db:"entity" json:"entity"`
+
+	Columns Columns `db:"columns" json:"columns"`
+	Filters Filters `db:"filters" json:"filters"`
+
+	// GroupBy lists the fields the records are grouped by. Grouped reports have
+	// a row per group and their other columns must be aggregated
+	GroupBy pq.StringArray `db:"group_by" json:"group_by" swaggertype:"array,string"`
+
+	// Period restricts each run to the records whose time field falls in the
+	// day, week or month before it, all records when empty
+	Period types.ReportPeriod `db:"period" json:"period,omitempty"`
+
+	Schedule types.ReportSchedule `db:"schedule" json:"schedule,omitempty"`
+	// NextRunAt is when the schedule runs the template next
+	NextRunAt *time.Time `db:"next_run_at" json:"next_run_at,omitempty"`
+
+	Destinations Destinations `db:"destinations" json:"destinations"`
+	types.BaseModel
+}
+
+// Column is a field of the records, or its aggregate over the records of a
+// group. A count column may have no field and counts the records
+type Column struct {
+	Field     string                `json:"field,omitempty"`
+	Aggregate types.ReportAggregate `json:"aggregate,omitempty"`
+}
+
+// Header is the name of the column in the CSV ex total or sum(total)
+func (c Column) Header() string {
+	if c.Aggregate == "" || c.Field == "" {
+		return c.Field + string(c.Aggregate)
+	}
+	return fmt.Sprintf("%s(%s)", c.Aggregate, c.Field)
+}
+
+// Filter keeps the records whose field compares with the value
+type Filter struct {
+	Field    string                     `json:"field"`
+	Operator types.ReportFilterOperator `json:"operator"`
+	Value    string                     `json:"value"`
+}
+
+// Destination is where the CSV of the completed runs is delivered
+type Destination struct {
+	Type types.ReportDestinationType `json:"type"`
+
+	// Recipients are the email addresses of email destinations
+	Recipients []string `json:"recipients,omitempty"`
+
+	// Prefix is the key prefix of s3 destinations, under the folder of the
+	// tenant in the export bucket
+	Prefix string `json:"prefix,omitempty"`
+}
+
+type Columns []Column
+
+type Filters []Filter
+
+type Destinations []Destination
+
+// Scanner/Valuer implementations for Columns
+func (c *Columns) Scan(value interface{}) error {
+	return scanJSON(value, c)
+}
+
+func (c Columns) Value() (driver.Value, error) {
+	if c == nil {
+		return json.Marshal(Columns{})
+	}
+	return json.Marshal(c)
+}
+
+// Scanner/Valuer implementations for Filters
+func (f *Filters) Scan(value interface{}) error {
+	return scanJSON(value, f)
+}
+
+func (f Filters) Value() (driver.Value, error) {
+	if f == nil {
+		return json.Marshal(Filters{})
+	}
+	return json.Marshal(f)
+}
+
+// Scanner/Valuer implementations for Destinations
+func (d *Destinations) Scan(value interface{}) error {
+	return scanJSON(value, d)
+}
+
+func (d Destinations) Value() (driver.Value, error) {
+	if d == nil {
+		return json.Marshal(Destinations{})
+	}
+	return json.Marshal(d)
+}
+
+func scanJSON(value interface{}, v interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb report field")
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+// Run is one run of a report template. The CSV of a completed run is kept in
+// the export bucket
+type Run struct {
+	ID         string                 `db:"id" json:"id"`
+	TemplateID string                 `db:"template_id" json:"template_id"`
+	Trigger    types.ReportRunTrigger `db:"trigger" json:"trigger"`
+	RunStatus  types.ReportRunStatus  `db:"run_status" json:"run_status"`
+
+	// PeriodStart and PeriodEnd bound the time field of the records reported,
+	// for templates with a period
+	PeriodStart *time.Time `db:"period_start" json:"period_start,omitempty"`
+	PeriodEnd   *time.Time `db:"period_end" json:"period_end,omitempty"`
+
+	// ObjectKey is the key of the CSV in the export bucket
+	ObjectKey string `db:"object_key" json:"object_key,omitempty"`
+	RowCount  int64  `db:"row_count" json:"row_count"`
+
+	// Error holds the failure reason when the run failed, or the destinations
+	// a completed run could not be delivered to
+	Error       string     `db:"error" json:"error,omitempty"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+
+	// LeaseUntil is when the claim of the report job on a processing run
+	// expires. A run whose lease expired is claimed and run again
+	LeaseUntil *time.Time `db:"lease_until" json:"-"`
+	types.BaseModel
+}
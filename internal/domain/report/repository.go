@@ -0,0 +1,32 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	CreateTemplate(ctx context.Context, template *Template) error
+	GetTemplate(ctx context.Context, id string) (*Template, error)
+	ListTemplates(ctx context.Context, filter types.Filter) ([]*Template, error)
+	UpdateTemplate(ctx context.Context, template *Template) error
+	DeleteTemplate(ctx context.Context, id string) error
+
+	// ClaimDueTemplates returns up to limit scheduled templates of all tenants
+	// whose next run is due and pushes their next run back by lease so that
+	// concurrent schedulers do not run them twice
+	ClaimDueTemplates(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Template, error)
+
+	CreateRun(ctx context.Context, run *Run) error
+	GetRun(ctx context.Context, id string) (*Run, error)
+	ListRuns(ctx context.Context, filter *types.ReportRunFilter) ([]*Run, error)
+	UpdateRun(ctx context.Context, run *Run) error
+
+	// ClaimPendingRuns returns up to limit runs of all tenants which are
+	// pending, or processing with an expired lease, and marks them processing
+	// with a lease until now+lease so that concurrent schedulers do not run
+	// them twice
+	ClaimPendingRuns(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Run, error)
+}
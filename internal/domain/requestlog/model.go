@@ -0,0 +1,25 @@
+package requestlog
+
+import "time"
+
+// RequestLog is an API request made with an API key, kept for a short time so
+// developers can inspect what their integration sent and received
+type RequestLog struct {
+	ID            string `json:"id"`
+	TenantID      string `json:"tenant_id"`
+	EnvironmentID string `json:"environment_id,omitempty"`
+	APIKeyID      string `json:"api_key_id"`
+	RequestID     string `json:"request_id"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+
+	// Endpoint is the route template of the path ex /v1/customers/:id
+	Endpoint   string `json:"endpoint"`
+	StatusCode int    `json:"status_code"`
+	LatencyMs  int64  `json:"latency_ms"`
+
+	// RequestBody and ResponseBody are redacted of personal data and truncated
+	RequestBody  string    `json:"request_body,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
@@ -0,0 +1,12 @@
+package requestlog
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	Insert(ctx context.Context, log *RequestLog) error
+	List(ctx context.Context, filter *types.RequestLogFilter) ([]*RequestLog, error)
+}
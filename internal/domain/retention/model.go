@@ -0,0 +1,25 @@
+package retention
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Policy is how long the events of a tenant are kept. TenantID of the base
+// model is the tenant the policy applies to, a TTL of 0 keeps the events forever
+type Policy struct {
+	ID      string `db:"id" json:"id"`
+	TTLDays int    `db:"ttl_days" json:"ttl_days"`
+	types.BaseModel
+}
+
+// CutoffFor returns the time before which events are deleted with a TTL in
+// days, nil for a TTL of 0
+func CutoffFor(ttlDays int, now time.Time) *time.Time {
+	if ttlDays <= 0 {
+		return nil
+	}
+	cutoff := now.UTC().AddDate(0, 0, -ttlDays)
+	return &cutoff
+}
@@ -0,0 +1,13 @@
+package retention
+
+import "context"
+
+// Repository stores the event retention policies of all the tenants. It is used
+// by the operator APIs and the retention job and is not scoped to the tenant in context
+type Repository interface {
+	// Upsert creates or replaces the policy of policy.TenantID
+	Upsert(ctx context.Context, policy *Policy) error
+	Get(ctx context.Context, tenantID string) (*Policy, error)
+	List(ctx context.Context) ([]*Policy, error)
+	Delete(ctx context.Context, tenantID string) error
+}
@@ -0,0 +1,16 @@
+package role
+
+import "github.com/flexprice/flexprice/internal/types"
+
+// Assignment grants a role to a user of the tenant
+type Assignment struct {
+	ID     string     `db:"id" json:"id"`
+	UserID string     `db:"user_id" json:"user_id"`
+	Role   types.Role `db:"role" json:"role"`
+
+	// EnvironmentID scopes the role to an environment. The assignments without
+	// an environment apply to all the environments which have no assignment of
+	// their own, so access to staging does not imply access to production
+	EnvironmentID string `db:"environment_id" json:"environment_id,omitempty"`
+	types.BaseModel
+}
@@ -0,0 +1,19 @@
+package role
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	Create(ctx context.Context, assignment *Assignment) error
+	Get(ctx context.Context, id string) (*Assignment, error)
+	// List returns the published assignments of the tenant ordered by user
+	List(ctx context.Context, filter *types.RoleAssignmentFilter) ([]*Assignment, error)
+	// ListByUser returns all the assignments of a user, tenant wide and
+	// environment scoped
+	ListByUser(ctx context.Context, userID string) ([]*Assignment, error)
+	Update(ctx context.Context, assignment *Assignment) error
+	Delete(ctx context.Context, id string) error
+}
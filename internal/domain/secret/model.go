@@ -0,0 +1,30 @@
+package secret
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/lib/pq"
+)
+
+// Secret is an API key issued to a tenant. Only the hash of the key is stored,
+// the raw key is returned once at creation time
+type Secret struct {
+	ID   string `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+
+	// Prefix is the first characters of the key, used to identify it in the UI
+	Prefix  string `db:"prefix" json:"prefix"`
+	KeyHash string `db:"key_hash" json:"-"`
+
+	// Scopes limit what the key can do, see types.APIKeyScope
+	Scopes pq.StringArray `db:"scopes" json:"scopes" swaggertype:"array,string"`
+
+	ExpiresAt *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	types.BaseModel
+}
+
+// IsExpired reports whether the key can no longer be used at the given time
+func (s *Secret) IsExpired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
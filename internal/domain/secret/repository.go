@@ -0,0 +1,18 @@
+package secret
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	Create(ctx context.Context, secret *Secret) error
+	Get(ctx context.Context, id string) (*Secret, error)
+	List(ctx context.Context, filter types.Filter) ([]*Secret, error)
+	Delete(ctx context.Context, id string) error
+
+	// GetByHash looks up a published key by its hash across all tenants.
+	// It is used to authenticate requests before the tenant is known
+	GetByHash(ctx context.Context, keyHash string) (*Secret, error)
+}
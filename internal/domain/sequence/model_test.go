@@ -0,0 +1,56 @@
+package sequence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoiceNumberingConfig_Format(t *testing.T) {
+	ts := time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		config   InvoiceNumberingConfig
+		value    int64
+		expected string
+		key      string
+	}{
+		{
+			name:     "default",
+			config:   *DefaultInvoiceNumberingConfig("t1", ""),
+			value:    42,
+			expected: "INV-00042",
+			key:      "invoice:INV",
+		},
+		{
+			name:     "yearly_reset",
+			config:   InvoiceNumberingConfig{Prefix: "FR", Separator: "/", Padding: 6, ResetYearly: true},
+			value:    7,
+			expected: "FR/2024/000007",
+			key:      "invoice:FR:2024",
+		},
+		{
+			name:     "no_prefix_overflowing_padding",
+			config:   InvoiceNumberingConfig{Separator: "-", Padding: 2},
+			value:    1234,
+			expected: "1234",
+			key:      "invoice:",
+		},
+		{
+			name:     "legal_entity_scope",
+			config:   InvoiceNumberingConfig{Scope: "le_1", Prefix: "FR", Separator: "-", Padding: 4, ResetYearly: true},
+			value:    3,
+			expected: "FR-2024-0003",
+			key:      "invoice:le_1:FR:2024",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.Format(tt.value, ts))
+			assert.Equal(t, tt.key, tt.config.SequenceKey(ts))
+		})
+	}
+}
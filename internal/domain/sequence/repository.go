@@ -0,0 +1,14 @@
+package sequence
+
+import "context"
+
+type Repository interface {
+	// GetInvoiceNumberingConfig returns the numbering config of the tenant environment in
+	// context or the default config when none is set
+	GetInvoiceNumberingConfig(ctx context.Context) (*InvoiceNumberingConfig, error)
+	// UpsertInvoiceNumberingConfig creates or replaces the numbering config
+	UpsertInvoiceNumberingConfig(ctx context.Context, config *InvoiceNumberingConfig) error
+	// NextValue increments and returns the counter for the key. The counter row stays
+	// locked until the surrounding transaction ends, which keeps the sequence gap free
+	NextValue(ctx context.Context, key string) (int64, error)
+}
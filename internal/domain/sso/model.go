@@ -0,0 +1,163 @@
+package sso
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/lib/pq"
+)
+
+// Config is the identity provider the users of a tenant sign in with
+type Config struct {
+	Protocol types.SSOProtocol `db:"protocol" json:"protocol"`
+
+	// Domains are the email domains of the users signing in with the identity
+	// provider. A domain belongs to a single tenant
+	Domains pq.StringArray `db:"domains" json:"domains" swaggertype:"array,string"`
+
+	OIDC *OIDCSettings `db:"oidc_settings" json:"oidc_settings,omitempty"`
+	SAML *SAMLSettings `db:"saml_settings" json:"saml_settings,omitempty"`
+
+	// ClientSecret is the OIDC client secret. It is never returned
+	ClientSecret string `db:"client_secret" json:"-"`
+
+	// GroupsClaim is the ID token claim or the SAML attribute listing the
+	// groups of the user, groups by default
+	GroupsClaim string `db:"groups_claim" json:"groups_claim"`
+
+	// GroupRoles maps the groups of the identity provider to roles. The most
+	// privileged role of the groups of a user is assigned at each sign in
+	GroupRoles GroupRoles `db:"group_roles" json:"group_roles"`
+
+	// JITProvisioning creates the users signing in for the first time with
+	// DefaultRole when none of their groups is mapped
+	JITProvisioning bool       `db:"jit_provisioning" json:"jit_provisioning"`
+	DefaultRole     types.Role `db:"default_role" json:"default_role,omitempty"`
+
+	// PasswordLoginDisabled rejects the password logins of the tenant users
+	PasswordLoginDisabled bool `db:"password_login_disabled" json:"password_login_disabled"`
+
+	types.BaseModel
+}
+
+// OIDCSettings configure an OpenID Connect identity provider
+type OIDCSettings struct {
+	// IssuerURL is where the provider configuration is discovered ex https://acme.okta.com
+	IssuerURL string `json:"issuer_url" validate:"required,url"`
+	ClientID  string `json:"client_id" validate:"required"`
+}
+
+// SAMLSettings configure a SAML 2.0 identity provider
+type SAMLSettings struct {
+	// EntityID is the issuer of the assertions of the identity provider
+	EntityID string `json:"entity_id" validate:"required"`
+	// SSOURL is the HTTP-Redirect single sign on endpoint of the identity provider
+	SSOURL string `json:"sso_url" validate:"required,url"`
+	// Certificate is the PEM certificate the responses are signed with
+	Certificate string `json:"certificate" validate:"required"`
+	// EmailAttribute is the attribute holding the email of the user. The
+	// NameID is used when empty
+	EmailAttribute string `json:"email_attribute,omitempty"`
+}
+
+// GroupRoles maps the groups of the identity provider to roles
+type GroupRoles map[string]types.Role
+
+// HasDomain reports whether the email is of one of the domains of the config
+func (c *Config) HasDomain(email string) bool {
+	domain := EmailDomain(email)
+	for _, d := range c.Domains {
+		if strings.EqualFold(d, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleForGroups returns the most privileged role mapped to the groups, or an
+// empty role when none of the groups is mapped
+func (c *Config) RoleForGroups(groups []string) types.Role {
+	mapped := make(map[types.Role]bool)
+	for _, group := range groups {
+		if role, ok := c.GroupRoles[group]; ok {
+			mapped[role] = true
+		}
+	}
+
+	for _, role := range types.Roles {
+		if mapped[role] {
+			return role
+		}
+	}
+	return ""
+}
+
+// EmailDomain returns the lowercased domain of the email
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+func (s *OIDCSettings) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb oidc settings")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s *OIDCSettings) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *SAMLSettings) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb saml settings")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s *SAMLSettings) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (g *GroupRoles) Scan(value interface{}) error {
+	if value == nil {
+		*g = make(GroupRoles)
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid type for jsonb group roles")
+	}
+	result := make(GroupRoles)
+	err := json.Unmarshal(bytes, &result)
+	*g = result
+	return err
+}
+
+func (g GroupRoles) Value() (driver.Value, error) {
+	if g == nil {
+		return json.Marshal(make(GroupRoles))
+	}
+	return json.Marshal(g)
+}
@@ -0,0 +1,15 @@
+package sso
+
+import "context"
+
+type Repository interface {
+	// Get returns the SSO config of the tenant in context, or nil when the
+	// tenant has not configured SSO
+	Get(ctx context.Context) (*Config, error)
+	// GetByDomain returns the SSO config of any tenant with the email domain,
+	// or nil when none. It is only used to sign in, before the tenant is known
+	GetByDomain(ctx context.Context, domain string) (*Config, error)
+	// Upsert creates or replaces the SSO config of the tenant
+	Upsert(ctx context.Context, config *Config) error
+	Delete(ctx context.Context) error
+}
@@ -3,7 +3,9 @@ package subscription
 import (
 	"time"
 
+	"github.com/flexprice/flexprice/internal/domain/price"
 	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
 )
 
 type Subscription struct {
@@ -53,12 +55,39 @@ type Subscription struct {
 	// CancelAtPeriodEnd is whether the subscription was canceled at the end of the current period
 	CancelAtPeriodEnd bool `db:"cancel_at_period_end" json:"cancel_at_period_end"`
 
+	// CancellationReason is the code of the tenant's cancellation reason picked on cancel
+	CancellationReason string `db:"cancellation_reason" json:"cancellation_reason,omitempty"`
+
+	// CancellationComment is the free text feedback left on cancel
+	CancellationComment string `db:"cancellation_comment" json:"cancellation_comment,omitempty"`
+
 	// TrialStart is the start date of the trial period
 	TrialStart *time.Time `db:"trial_start" json:"trial_start"`
 
 	// TrialEnd is the end date of the trial period
 	TrialEnd *time.Time `db:"trial_end" json:"trial_end"`
 
+	// TrialWillEndSentAt is when the trial_will_end webhook was queued for the trial
+	TrialWillEndSentAt *time.Time `db:"trial_will_end_sent_at" json:"-"`
+
+	// BillingCycle decides whether periods start on the anniversary of the start date or on calendar boundaries
+	BillingCycle types.BillingCycle `db:"billing_cycle" json:"billing_cycle"`
+
+	// BillingAnchorDay bills monthly periods on this day of the month, or on the last day of
+	// shorter months, whatever the start date. Zero bills on the anniversary of the start date
+	BillingAnchorDay int `db:"billing_anchor_day" json:"billing_anchor_day,omitempty"`
+
+	// PartialPeriodBehavior is how the partial first period of a calendar or anchor day billed subscription is charged
+	PartialPeriodBehavior types.PartialPeriodBehavior `db:"partial_period_behavior" json:"partial_period_behavior,omitempty"`
+
+	// Timezone is the IANA timezone the billing periods are computed in, taken from the customer
+	// when the subscription is created. Calendar and anchor day billed periods start at its midnight
+	Timezone string `db:"timezone" json:"timezone"`
+
+	// GatewayPaymentMethodID is the payment method at the gateway the subscription is charged to.
+	// Empty charges the default payment method of the customer
+	GatewayPaymentMethodID string `db:"gateway_payment_method_id" json:"gateway_payment_method_id,omitempty"`
+
 	// BillingCadence is the cadence of the billing cycle.
 	BillingCadence types.BillingCadence `db:"billing_cadence" json:"billing_cadence"`
 
@@ -68,8 +97,89 @@ type Subscription struct {
 	// BillingPeriodCount is the total number units of the billing period.
 	BillingPeriodCount int `db:"billing_period_count" json:"billing_period_count"`
 
+	// CommitmentAmount is the minimum amount billed per period. Zero means no commitment
+	CommitmentAmount decimal.Decimal `db:"commitment_amount" json:"commitment_amount"`
+
+	// BillingThreshold raises an interim invoice whenever the usage charges accumulated
+	// in the current period exceed it. Zero means no threshold billing
+	BillingThreshold decimal.Decimal `db:"billing_threshold" json:"billing_threshold"`
+
+	// ThresholdBilledUntil is the end of the usage billed by the last threshold
+	// invoice. Usage is accumulated from it when it falls in the current period
+	ThresholdBilledUntil *time.Time `db:"threshold_billed_until" json:"threshold_billed_until,omitempty"`
+
+	// PriceBookID is the price book overriding the prices of the plan for the
+	// subscription, empty when the catalog prices apply
+	PriceBookID string `db:"price_book_id" json:"price_book_id,omitempty"`
+
+	// LegalEntityID is the legal entity the subscription is invoiced from, the
+	// entity of the customer when empty
+	LegalEntityID string `db:"legal_entity_id" json:"legal_entity_id,omitempty"`
+
 	// InvoiceCadence is the cadence of the invoice. This overrides the plan's invoice cadence.
 	InvoiceCadence types.InvoiceCadence `db:"invoice_cadence" json:"invoice_cadence"`
 
+	// Metadata holds cross references to other systems ex stripe_subscription_id
+	Metadata types.Metadata `db:"metadata" json:"metadata,omitempty"`
+
+	// Version is incremented on every update, including the changes of its line items,
+	// and guards against concurrent writes
+	Version int `db:"version" json:"version"`
+
+	// LineItems are the recurring fixed charges of the subscription
+	LineItems []*LineItem `db:"-" json:"line_items,omitempty"`
+
+	types.BaseModel
+}
+
+// LineItem is a recurring fixed price of the plan as charged to the subscription.
+// Fixed prices without a line item are charged once per period at their amount
+type LineItem struct {
+	ID             string `db:"id" json:"id"`
+	SubscriptionID string `db:"subscription_id" json:"subscription_id"`
+	PriceID        string `db:"price_id" json:"price_id"`
+
+	// Quantity is the number of times the price is charged per period
+	Quantity decimal.Decimal `db:"quantity" json:"quantity"`
+
+	// UnitAmount overrides the amount of the price, and of any rate card, when set
+	UnitAmount *decimal.Decimal `db:"unit_amount" json:"unit_amount,omitempty"`
+
+	types.BaseModel
+}
+
+// Apply returns the price as charged for the line item, the price itself when
+// its amount is not overridden
+func (li *LineItem) Apply(p *price.Price) *price.Price {
+	if li.UnitAmount == nil {
+		return p
+	}
+
+	overridden := *p
+	overridden.Amount = *li.UnitAmount
+	overridden.DisplayAmount = overridden.GetDisplayAmount()
+	return &overridden
+}
+
+// Proration is the amount billed on the next invoice of the subscription for
+// a change to a line item in the middle of a period. Periods are charged at the
+// line items in effect when they are invoiced, so a proration credits or charges
+// the difference for the part of the period before the change
+type Proration struct {
+	ID             string          `db:"id" json:"id"`
+	SubscriptionID string          `db:"subscription_id" json:"subscription_id"`
+	LineItemID     string          `db:"line_item_id" json:"line_item_id"`
+	PriceID        string          `db:"price_id" json:"price_id"`
+	DisplayName    string          `db:"display_name" json:"display_name"`
+	Amount         decimal.Decimal `db:"amount" json:"amount"`
+	Currency       string          `db:"currency" json:"currency"`
+
+	// PeriodStart and ChangedAt bound the part of the period the proration is for
+	PeriodStart time.Time `db:"period_start" json:"period_start"`
+	ChangedAt   time.Time `db:"changed_at" json:"changed_at"`
+
+	// InvoiceID is the invoice the proration was billed on, empty until then
+	InvoiceID string `db:"invoice_id" json:"invoice_id,omitempty"`
+
 	types.BaseModel
 }
@@ -2,14 +2,41 @@ package subscription
 
 import (
 	"context"
+	"time"
 
 	"github.com/flexprice/flexprice/internal/types"
 )
 
 type Repository interface {
+	// Create persists the subscription along with its line items
 	Create(ctx context.Context, subscription *Subscription) error
+	// Get returns the subscription along with its line items
 	Get(ctx context.Context, id string) (*Subscription, error)
 	Update(ctx context.Context, subscription *Subscription) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, filter *types.SubscriptionFilter) ([]*Subscription, error)
+
+	// ListTrialsEndingBefore returns the trialing subscriptions of all tenants whose
+	// trial ends at or before the given time. It is meant for the billing cron only
+	ListTrialsEndingBefore(ctx context.Context, before time.Time) ([]*Subscription, error)
+
+	// ListWithBillingThreshold returns the active subscriptions of all tenants with a
+	// billing threshold. It is meant for the billing cron only
+	ListWithBillingThreshold(ctx context.Context) ([]*Subscription, error)
+
+	// ListPeriodsEndedBefore returns the active subscriptions of all tenants whose
+	// current period ends at or before the given time. It is meant for the billing cron only
+	ListPeriodsEndedBefore(ctx context.Context, before time.Time) ([]*Subscription, error)
+
+	// ListCancelledBetween returns the subscriptions of the tenant cancelled in [start, end)
+	ListCancelledBetween(ctx context.Context, start, end time.Time) ([]*Subscription, error)
+
+	// UpdateLineItem updates the quantity and unit amount of a line item
+	UpdateLineItem(ctx context.Context, item *LineItem) error
+
+	CreateProration(ctx context.Context, proration *Proration) error
+	// ListPendingProrations returns the prorations of the subscription not billed yet, oldest first
+	ListPendingProrations(ctx context.Context, subscriptionID string) ([]*Proration, error)
+	// MarkProrationInvoiced records the invoice a proration was billed on
+	MarkProrationInvoiced(ctx context.Context, id, invoiceID string) error
 }
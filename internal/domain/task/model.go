@@ -0,0 +1,50 @@
+package task
+
+import (
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Task is an asynchronous job processing an uploaded file row by row, such as
+// a bulk customer import
+type Task struct {
+	ID string `db:"id" json:"id"`
+
+	// Type is the kind of work done by the task
+	Type types.TaskType `db:"task_type" json:"task_type"`
+
+	// FileFormat is the format of the processed file
+	FileFormat types.TaskFileFormat `db:"file_format" json:"file_format"`
+
+	// FileURL is where the file is downloaded from, empty when it was uploaded
+	// with the request
+	FileURL string `db:"file_url" json:"file_url,omitempty"`
+
+	// TaskStatus tracks the progress of the task
+	TaskStatus types.TaskStatus `db:"task_status" json:"task_status"`
+
+	// TotalRows is the number of rows in the file, known once it is parsed
+	TotalRows int `db:"total_rows" json:"total_rows"`
+
+	// ProcessedRows is the number of rows handled so far, updated after every batch
+	ProcessedRows int `db:"processed_rows" json:"processed_rows"`
+
+	SuccessfulRows int `db:"successful_rows" json:"successful_rows"`
+	FailedRows     int `db:"failed_rows" json:"failed_rows"`
+
+	// ErrorReportKey is the key of the report of the failed rows in the bucket,
+	// only set when some rows failed
+	ErrorReportKey string `db:"error_report_key" json:"error_report_key,omitempty"`
+
+	// Error holds the failure reason when the whole task failed
+	Error string `db:"error" json:"error,omitempty"`
+
+	// RangeStart and RangeEnd bound the timestamps of the events of a backfill
+	RangeStart *time.Time `db:"range_start" json:"range_start,omitempty"`
+	RangeEnd   *time.Time `db:"range_end" json:"range_end,omitempty"`
+
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+
+	types.BaseModel
+}
@@ -0,0 +1,14 @@
+package task
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	Create(ctx context.Context, task *Task) error
+	Get(ctx context.Context, id string) (*Task, error)
+	List(ctx context.Context, filter types.Filter) ([]*Task, error)
+	Update(ctx context.Context, task *Task) error
+}
@@ -0,0 +1,45 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// AutoTopUpReferenceType is the reference type of the wallet transactions made by
+// auto top-ups
+const AutoTopUpReferenceType = "auto_topup"
+
+// AutoTopUp is the rule topping a wallet up by Amount when its balance drops
+// below Threshold. The caps bound the amount topped up per UTC day and calendar
+// month, zero meaning no cap
+type AutoTopUp struct {
+	ID         string          `db:"id" json:"id"`
+	WalletID   string          `db:"wallet_id" json:"wallet_id"`
+	Enabled    bool            `db:"enabled" json:"enabled"`
+	Threshold  decimal.Decimal `db:"threshold" json:"threshold" swaggertype:"string"`
+	Amount     decimal.Decimal `db:"amount" json:"amount" swaggertype:"string"`
+	DailyCap   decimal.Decimal `db:"daily_cap" json:"daily_cap" swaggertype:"string"`
+	MonthlyCap decimal.Decimal `db:"monthly_cap" json:"monthly_cap" swaggertype:"string"`
+	types.BaseModel
+}
+
+// AutoTopUpRepository persists the auto top-up rules of the wallets. Rules live
+// apart from the wallets so that they can be evaluated across tenants by the
+// billing cron
+type AutoTopUpRepository interface {
+	// UpsertAutoTopUp creates or replaces the rule of the wallet
+	UpsertAutoTopUp(ctx context.Context, rule *AutoTopUp) error
+
+	GetAutoTopUp(ctx context.Context, walletID string) (*AutoTopUp, error)
+
+	// ListDueAutoTopUps returns the enabled rules of all tenants whose active
+	// wallet balance is below the threshold
+	ListDueAutoTopUps(ctx context.Context) ([]*AutoTopUp, error)
+
+	// SumAutoTopUps returns the amount the wallet was topped up by its rule since
+	// the given time
+	SumAutoTopUps(ctx context.Context, walletID string, since time.Time) (decimal.Decimal, error)
+}
@@ -0,0 +1,43 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Reference types of the wallet transactions crediting a bucket and debiting its
+// expired credit, both referencing the bucket
+const (
+	CreditBucketReferenceType = "credit_bucket"
+	CreditExpiryReferenceType = "credit_expiry"
+)
+
+// CreditBucket is the part of a wallet balance topped up with an expiry date.
+// Debits draw from the oldest buckets that have not expired before the rest of
+// the balance, which never expires
+type CreditBucket struct {
+	ID        string          `db:"id" json:"id"`
+	WalletID  string          `db:"wallet_id" json:"wallet_id"`
+	Amount    decimal.Decimal `db:"amount" json:"amount" swaggertype:"string"`
+	Remaining decimal.Decimal `db:"remaining" json:"remaining" swaggertype:"string"`
+	ExpiresAt time.Time       `db:"expires_at" json:"expires_at"`
+	ExpiredAt *time.Time      `db:"expired_at" json:"expired_at,omitempty"`
+	types.BaseModel
+}
+
+// CreditBucketRepository persists the credit buckets of the wallets
+type CreditBucketRepository interface {
+	CreateCreditBucket(ctx context.Context, bucket *CreditBucket) error
+	UpdateCreditBucket(ctx context.Context, bucket *CreditBucket) error
+
+	// ListCreditBuckets returns the buckets of the wallet with credit remaining,
+	// oldest first
+	ListCreditBuckets(ctx context.Context, walletID string) ([]*CreditBucket, error)
+
+	// ListExpiredCreditBuckets returns the buckets of all tenants that expired at
+	// now with credit remaining
+	ListExpiredCreditBuckets(ctx context.Context, now time.Time) ([]*CreditBucket, error)
+}
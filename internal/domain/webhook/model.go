@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/lib/pq"
+)
+
+// Endpoint is a URL of a tenant environment that receives webhook events
+type Endpoint struct {
+	ID            string `db:"id" json:"id"`
+	EnvironmentID string `db:"environment_id" json:"environment_id"`
+	URL           string `db:"url" json:"url"`
+	Description   string `db:"description" json:"description"`
+
+	// EventTypes limits the endpoint to the listed events, all events when empty
+	EventTypes pq.StringArray `db:"event_types" json:"event_types" swaggertype:"array,string"`
+
+	// Secret signs deliveries. After a rotation the previous secret keeps signing
+	// them alongside the new one until PreviousSecretExpiresAt
+	Secret                  string     `db:"secret" json:"-"`
+	PreviousSecret          string     `db:"previous_secret" json:"-"`
+	PreviousSecretExpiresAt *time.Time `db:"previous_secret_expires_at" json:"previous_secret_expires_at,omitempty"`
+	types.BaseModel
+}
+
+// Subscribes reports whether the endpoint receives events of the given type
+func (e *Endpoint) Subscribes(eventType types.WebhookEventType) bool {
+	if len(e.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range e.EventTypes {
+		if types.WebhookEventType(t) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// SigningSecrets returns the secrets that sign deliveries at the given time,
+// the current one first
+func (e *Endpoint) SigningSecrets(now time.Time) []string {
+	secrets := []string{e.Secret}
+	if e.PreviousSecret != "" && e.PreviousSecretExpiresAt != nil && now.Before(*e.PreviousSecretExpiresAt) {
+		secrets = append(secrets, e.PreviousSecret)
+	}
+	return secrets
+}
+
+// Delivery is the delivery of one event to one webhook endpoint, kept across
+// retries so that failed deliveries can be inspected and replayed
+type Delivery struct {
+	ID            string                 `db:"id" json:"id"`
+	EnvironmentID string                 `db:"environment_id" json:"environment_id"`
+	EndpointID    string                 `db:"endpoint_id" json:"endpoint_id"`
+	EndpointURL   string                 `db:"endpoint_url" json:"endpoint_url"`
+	EventType     types.WebhookEventType `db:"event_type" json:"event_type"`
+
+	// Payload is the exact body sent to the endpoint
+	Payload json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+
+	DeliveryStatus types.WebhookDeliveryStatus `db:"delivery_status" json:"delivery_status"`
+	Attempts       int                         `db:"attempts" json:"attempts"`
+	NextAttemptAt  *time.Time                  `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time                  `db:"last_attempt_at" json:"last_attempt_at,omitempty"`
+
+	// LastResponseCode is zero when the last attempt got no response
+	LastResponseCode int        `db:"last_response_code" json:"last_response_code"`
+	LastError        string     `db:"last_error" json:"last_error,omitempty"`
+	DeliveredAt      *time.Time `db:"delivered_at" json:"delivered_at,omitempty"`
+	types.BaseModel
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type Repository interface {
+	CreateEndpoint(ctx context.Context, endpoint *Endpoint) error
+	GetEndpoint(ctx context.Context, id string) (*Endpoint, error)
+	ListEndpoints(ctx context.Context, filter types.Filter) ([]*Endpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *Endpoint) error
+	DeleteEndpoint(ctx context.Context, id string) error
+
+	// ListSubscribedEndpoints returns all endpoints of the environment in ctx that
+	// receive events of the given type. Without an environment in ctx, as for
+	// events raised by background jobs, it returns those of every environment
+	ListSubscribedEndpoints(ctx context.Context, eventType types.WebhookEventType) ([]*Endpoint, error)
+
+	CreateDelivery(ctx context.Context, delivery *Delivery) error
+	GetDelivery(ctx context.Context, id string) (*Delivery, error)
+	ListDeliveries(ctx context.Context, filter *types.WebhookDeliveryFilter) ([]*Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+
+	// ClaimDueDeliveries returns up to limit pending deliveries of all tenants whose
+	// next attempt is due and pushes their next attempt back by lease so that
+	// concurrent dispatchers do not send them twice
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+}
@@ -0,0 +1,60 @@
+// Package email sends the emails of the tenants to their customers through the
+// configured provider and renders them from templates
+package email
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Message is a rendered email to one recipient
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Provider sends emails through an email service
+type Provider interface {
+	// Name is the provider recorded on the deliveries it sends
+	Name() types.EmailProvider
+
+	// Send sends the message and returns the id the provider assigned to it,
+	// which is empty when the provider does not assign one
+	Send(ctx context.Context, msg *Message) (string, error)
+}
+
+// NewProvider builds the provider from the email configuration. It returns a
+// nil Provider when no provider is configured so that emails can be disabled
+// without failing the application startup
+func NewProvider(cfg *config.Configuration) (Provider, error) {
+	c := cfg.Email
+	if c.Provider == "" {
+		return nil, nil
+	}
+	if c.FromAddress == "" {
+		return nil, fmt.Errorf("email from address is required")
+	}
+
+	switch c.Provider {
+	case types.EmailProviderSMTP:
+		return newSMTPProvider(c.SMTP)
+	case types.EmailProviderSES:
+		return newSESProvider(c.SES)
+	case types.EmailProviderSendGrid:
+		return newSendGridProvider(c.SendGrid)
+	default:
+		return nil, fmt.Errorf("unsupported email provider: %s", c.Provider)
+	}
+}
+
+// FromHeader is the From header of the configured sender
+func FromHeader(c config.EmailConfig) string {
+	return (&mail.Address{Name: c.FromName, Address: c.FromAddress}).String()
+}
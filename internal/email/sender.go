@@ -0,0 +1,153 @@
+package email
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	emailDomain "github.com/flexprice/flexprice/internal/domain/email"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const (
+	sendTimeout = 30 * time.Second
+	batchSize   = 50
+)
+
+// Sender sends the pending deliveries of all tenants through the provider and
+// schedules retries. Deliveries that exhaust their attempts are marked failed
+// and stay there until resent
+type Sender struct {
+	repo     emailDomain.Repository
+	provider Provider
+	from     string
+	logger   *logger.Logger
+
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+}
+
+func NewSender(cfg *config.Configuration, repo emailDomain.Repository, provider Provider, logger *logger.Logger) *Sender {
+	s := &Sender{
+		repo:        repo,
+		provider:    provider,
+		from:        FromHeader(cfg.Email),
+		logger:      logger,
+		maxAttempts: cfg.Email.MaxAttempts,
+		baseBackoff: time.Duration(cfg.Email.BackoffBaseSecs) * time.Second,
+		maxBackoff:  time.Duration(cfg.Email.BackoffMaxSecs) * time.Second,
+	}
+	if s.maxAttempts <= 0 {
+		s.maxAttempts = 5
+	}
+	if s.baseBackoff <= 0 {
+		s.baseBackoff = time.Minute
+	}
+	if s.maxBackoff <= 0 {
+		s.maxBackoff = time.Hour
+	}
+	return s
+}
+
+// backoff is the delay before the retry following the given attempt: exponential
+// in the number of attempts, capped at maxBackoff, with the upper half jittered
+func (s *Sender) backoff(attempt int) time.Duration {
+	delay := s.baseBackoff
+	for i := 1; i < attempt && delay < s.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.maxBackoff {
+		delay = s.maxBackoff
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// SendDue sends the deliveries due at now batch by batch until none are left.
+// The claim lease outlasts the batch so that a sender crashing mid batch only
+// delays its deliveries
+func (s *Sender) SendDue(ctx context.Context, now time.Time) error {
+	if s.provider == nil {
+		return nil
+	}
+
+	lease := time.Duration(batchSize+1) * sendTimeout
+	for ctx.Err() == nil {
+		deliveries, err := s.repo.ClaimDueDeliveries(ctx, now, lease, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return nil
+			}
+			s.send(ctx, delivery)
+		}
+
+		if len(deliveries) < batchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *Sender) send(ctx context.Context, delivery *emailDomain.Delivery) {
+	ctx = context.WithValue(ctx, types.CtxTenantID, delivery.TenantID)
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	messageID, err := s.provider.Send(sendCtx, &Message{
+		From:    s.from,
+		To:      delivery.Recipient,
+		Subject: delivery.Subject,
+		HTML:    delivery.BodyHTML,
+		Text:    delivery.BodyText,
+	})
+	cancel()
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.Provider = s.provider.Name()
+	delivery.UpdatedAt = now
+
+	switch {
+	case err == nil:
+		delivery.DeliveryStatus = types.EmailDeliveryStatusSent
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.ProviderMessageID = messageID
+		delivery.SentAt = &now
+	case delivery.Attempts >= s.maxAttempts:
+		delivery.DeliveryStatus = types.EmailDeliveryStatusFailed
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(s.backoff(delivery.Attempts))
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = &next
+	}
+
+	if err != nil {
+		s.logger.Debugw("email delivery attempt failed",
+			"delivery_id", delivery.ID,
+			"tenant_id", delivery.TenantID,
+			"attempts", delivery.Attempts,
+			"delivery_status", delivery.DeliveryStatus,
+			"error", err,
+		)
+	}
+
+	// The attempt happened, so record it even if the sender is stopping
+	if err := s.repo.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		s.logger.Errorw("failed to update email delivery",
+			"delivery_id", delivery.ID,
+			"tenant_id", delivery.TenantID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,145 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	emailDomain "github.com/flexprice/flexprice/internal/domain/email"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	err  error
+	sent []*Message
+}
+
+func (p *testProvider) Name() types.EmailProvider {
+	return types.EmailProviderSMTP
+}
+
+func (p *testProvider) Send(ctx context.Context, msg *Message) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	p.sent = append(p.sent, msg)
+	return "msg_1", nil
+}
+
+func setupSenderTest(t *testing.T, provider Provider) (*Sender, *testutil.InMemoryEmailStore) {
+	store := testutil.NewInMemoryEmailStore()
+	ctx := testutil.SetupContext()
+	now := time.Now().UTC()
+	require.NoError(t, store.CreateDelivery(ctx, &emailDomain.Delivery{
+		ID:             "emd_1",
+		TemplateType:   types.EmailTemplateInvoiceFinalized,
+		Recipient:      "jane@example.com",
+		Subject:        "Invoice INV-1",
+		BodyText:       "Your invoice is ready",
+		DeliveryStatus: types.EmailDeliveryStatusPending,
+		NextAttemptAt:  &now,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}))
+
+	cfg := &config.Configuration{Email: config.EmailConfig{
+		FromAddress:     "billing@example.com",
+		FromName:        "Billing",
+		MaxAttempts:     2,
+		BackoffBaseSecs: 1,
+		BackoffMaxSecs:  1,
+	}}
+	return NewSender(cfg, store, provider, logger.GetLogger()), store
+}
+
+func TestSender_SendsDueDeliveries(t *testing.T) {
+	ctx := testutil.SetupContext()
+	provider := &testProvider{}
+	sender, store := setupSenderTest(t, provider)
+
+	require.NoError(t, sender.SendDue(ctx, time.Now().UTC()))
+
+	require.Len(t, provider.sent, 1)
+	assert.Equal(t, `"Billing" <billing@example.com>`, provider.sent[0].From)
+	assert.Equal(t, "jane@example.com", provider.sent[0].To)
+	assert.Equal(t, "Invoice INV-1", provider.sent[0].Subject)
+
+	delivery, err := store.GetDelivery(ctx, "emd_1")
+	require.NoError(t, err)
+	assert.Equal(t, types.EmailDeliveryStatusSent, delivery.DeliveryStatus)
+	assert.Equal(t, types.EmailProviderSMTP, delivery.Provider)
+	assert.Equal(t, "msg_1", delivery.ProviderMessageID)
+	assert.NotNil(t, delivery.SentAt)
+	assert.Nil(t, delivery.NextAttemptAt)
+}
+
+func TestSender_RetriesThenFails(t *testing.T) {
+	ctx := testutil.SetupContext()
+	sender, store := setupSenderTest(t, &testProvider{err: errors.New("connection refused")})
+
+	require.NoError(t, sender.SendDue(ctx, time.Now().UTC()))
+	delivery, err := store.GetDelivery(ctx, "emd_1")
+	require.NoError(t, err)
+	assert.Equal(t, types.EmailDeliveryStatusPending, delivery.DeliveryStatus)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, "connection refused", delivery.LastError)
+	require.NotNil(t, delivery.NextAttemptAt)
+
+	require.NoError(t, sender.SendDue(ctx, delivery.NextAttemptAt.Add(time.Second)))
+	delivery, err = store.GetDelivery(ctx, "emd_1")
+	require.NoError(t, err)
+	assert.Equal(t, types.EmailDeliveryStatusFailed, delivery.DeliveryStatus)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Nil(t, delivery.NextAttemptAt)
+}
+
+func TestSender_DisabledWithoutProvider(t *testing.T) {
+	ctx := testutil.SetupContext()
+	sender, store := setupSenderTest(t, nil)
+
+	require.NoError(t, sender.SendDue(ctx, time.Now().UTC()))
+	delivery, err := store.GetDelivery(ctx, "emd_1")
+	require.NoError(t, err)
+	assert.Equal(t, types.EmailDeliveryStatusPending, delivery.DeliveryStatus)
+}
+
+func TestSendGridProvider_Send(t *testing.T) {
+	var body sendGridRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &body)
+		w.Header().Set("X-Message-Id", "sg_1")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	provider, err := newSendGridProvider(config.SendGridConfig{APIKey: "SG.test"})
+	require.NoError(t, err)
+	provider.(*sendGridProvider).url = server.URL
+
+	id, err := provider.Send(context.Background(), &Message{
+		From:    `"Billing" <billing@example.com>`,
+		To:      "jane@example.com",
+		Subject: "Invoice INV-1",
+		HTML:    "<p>ready</p>",
+		Text:    "ready",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sg_1", id)
+	assert.Equal(t, "Bearer SG.test", auth)
+	assert.Equal(t, "billing@example.com", body.From.Email)
+	assert.Equal(t, "jane@example.com", body.Personalizations[0].To[0].Email)
+	require.Len(t, body.Content, 2)
+	assert.Equal(t, "text/plain", body.Content[0].Type)
+}
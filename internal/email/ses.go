@@ -0,0 +1,66 @@
+package email
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	sestypes "github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type sesProvider struct {
+	client *sesv2.Client
+}
+
+func newSESProvider(c config.SESConfig) (Provider, error) {
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(c.Region),
+	}
+	if c.AccessKeyID != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(c.AccessKeyID, c.SecretAccessKey, ""),
+		))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ses config: %w", err)
+	}
+
+	return &sesProvider{client: sesv2.NewFromConfig(awsCfg)}, nil
+}
+
+func (p *sesProvider) Name() types.EmailProvider {
+	return types.EmailProviderSES
+}
+
+func (p *sesProvider) Send(ctx context.Context, msg *Message) (string, error) {
+	body := &sestypes.Body{}
+	if msg.HTML != "" {
+		body.Html = &sestypes.Content{Data: aws.String(msg.HTML), Charset: aws.String("UTF-8")}
+	}
+	if msg.Text != "" {
+		body.Text = &sestypes.Content{Data: aws.String(msg.Text), Charset: aws.String("UTF-8")}
+	}
+
+	out, err := p.client.SendEmail(ctx, &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(msg.From),
+		Destination:      &sestypes.Destination{ToAddresses: []string{msg.To}},
+		Content: &sestypes.EmailContent{
+			Simple: &sestypes.Message{
+				Subject: &sestypes.Content{Data: aws.String(msg.Subject), Charset: aws.String("UTF-8")},
+				Body:    body,
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return aws.ToString(out.MessageId), nil
+}
@@ -0,0 +1,130 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type smtpProvider struct {
+	addr string
+	auth smtp.Auth
+}
+
+func newSMTPProvider(c config.SMTPConfig) (Provider, error) {
+	if c.Host == "" {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+	port := c.Port
+	if port == 0 {
+		port = 587
+	}
+
+	p := &smtpProvider{addr: net.JoinHostPort(c.Host, strconv.Itoa(port))}
+	if c.Username != "" {
+		p.auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+	return p, nil
+}
+
+func (p *smtpProvider) Name() types.EmailProvider {
+	return types.EmailProviderSMTP
+}
+
+// Send uses STARTTLS when the server offers it. SMTP assigns no message id so
+// the Message-ID header set here is returned instead
+func (p *smtpProvider) Send(ctx context.Context, msg *Message) (string, error) {
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return "", fmt.Errorf("invalid from address: %w", err)
+	}
+
+	messageID := fmt.Sprintf("<%s@%s>", types.GenerateUUID(), domainOf(from.Address))
+	body, err := buildMIME(msg, messageID)
+	if err != nil {
+		return "", err
+	}
+
+	// net/smtp does not take a context, the deadline of the context bounds the
+	// whole exchange instead
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(p.addr, p.auth, from.Address, []string{msg.To}, body)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return "", fmt.Errorf("failed to send email: %w", err)
+		}
+		return messageID, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func domainOf(address string) string {
+	for i := len(address) - 1; i >= 0; i-- {
+		if address[i] == '@' {
+			return address[i+1:]
+		}
+	}
+	return "localhost"
+}
+
+// buildMIME encodes the message as multipart/alternative with the text body
+// first so that clients prefer the HTML body
+func buildMIME(msg *Message, messageID string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	header := []struct{ key, value string }{
+		{"From", msg.From},
+		{"To", msg.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().UTC().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + w.Boundary()},
+	}
+	for _, h := range header {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h.key, h.value)
+	}
+	buf.WriteString("\r\n")
+
+	parts := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build email: %w", err)
+		}
+		if _, err := pw.Write([]byte(part.body)); err != nil {
+			return nil, fmt.Errorf("failed to build email: %w", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,329 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Data is what templates are rendered with. Invoice is set for the invoice
+// emails, Subscription for the trial emails, Debit along with Invoice for the
+// debit pre-notifications and Report for the report emails, which are not sent
+// to a customer. Issuer is set along with Invoice when the invoice was issued
+// from a legal entity
+type Data struct {
+	Customer     CustomerData
+	Issuer       *IssuerData
+	Invoice      *InvoiceData
+	Subscription *SubscriptionData
+	Debit        *DebitData
+	Report       *ReportData
+}
+
+type CustomerData struct {
+	ID             string
+	ExternalID     string
+	Name           string
+	Email          string
+	BillingAddress *customer.Address
+	TaxIDs         customer.TaxIDs
+}
+
+// IssuerData is the legal entity of the tenant an invoice was issued from
+type IssuerData struct {
+	ID          string
+	Name        string
+	Address     *customer.Address
+	TaxIDs      customer.TaxIDs
+	BankDetails *legalentity.BankDetails
+}
+
+type InvoiceData struct {
+	ID          string
+	Number      string
+	Currency    string
+	Total       decimal.Decimal
+	AmountDue   decimal.Decimal
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+	FinalizedAt *time.Time
+}
+
+type SubscriptionData struct {
+	ID       string
+	PlanID   string
+	Currency string
+	TrialEnd *time.Time
+}
+
+type DebitData struct {
+	ID         string
+	Amount     decimal.Decimal
+	Currency   string
+	ChargeDate time.Time
+	// Scheme is sepa_core, sepa_b2b or ach
+	Scheme           string
+	MandateReference string
+}
+
+type ReportData struct {
+	ID          string
+	Name        string
+	RowCount    int64
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+	// URL downloads the CSV of the run until ExpiresAt
+	URL       string
+	ExpiresAt time.Time
+}
+
+// Template is an email as Go templates. The HTML body is escaped as HTML, the
+// subject and the text body are not escaped
+type Template struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+var funcs = map[string]interface{}{
+	"upper": strings.ToUpper,
+	// date formats times and time pointers, nil pointers as an empty string
+	"date": func(t interface{}) string {
+		switch v := t.(type) {
+		case time.Time:
+			return v.Format("Jan 2, 2006")
+		case *time.Time:
+			if v != nil {
+				return v.Format("Jan 2, 2006")
+			}
+		}
+		return ""
+	},
+	// amount formats decimals with two decimal places
+	"amount": func(d decimal.Decimal) string {
+		return d.StringFixed(2)
+	},
+}
+
+var defaultTemplates = map[types.EmailTemplateType]Template{
+	types.EmailTemplateInvoiceFinalized: {
+		Subject: "Invoice {{.Invoice.Number}}",
+		HTML: `<p>Hi {{.Customer.Name}},</p>
+<p>Your invoice {{.Invoice.Number}} for {{amount .Invoice.AmountDue}} {{upper .Invoice.Currency}} is ready.</p>
+{{if .Invoice.PeriodStart}}<p>It covers {{date .Invoice.PeriodStart}} to {{date .Invoice.PeriodEnd}}.</p>
+{{end}}{{with .Issuer}}<p>From:<br>{{.Name}}{{with .Address}}<br>{{.Line1}}<br>{{if .Line2}}{{.Line2}}<br>{{end}}{{.PostalCode}} {{.City}}{{if .State}}, {{.State}}{{end}}<br>{{.Country}}{{end}}{{range .TaxIDs}}<br>Tax ID: {{.Value}}{{end}}</p>
+{{end}}{{with .Customer.BillingAddress}}<p>Billed to:<br>{{.Line1}}<br>{{if .Line2}}{{.Line2}}<br>{{end}}{{.PostalCode}} {{.City}}{{if .State}}, {{.State}}{{end}}<br>{{.Country}}</p>
+{{end}}{{range .Customer.TaxIDs}}<p>Tax ID: {{.Value}}</p>
+{{end}}{{with .Issuer}}{{with .BankDetails}}<p>Pay by bank transfer to:<br>{{.AccountHolder}}{{if .BankName}}<br>{{.BankName}}{{end}}{{if .IBAN}}<br>IBAN: {{.IBAN}}{{end}}{{if .BIC}}<br>BIC: {{.BIC}}{{end}}{{if .AccountNumber}}<br>Account number: {{.AccountNumber}}{{end}}{{if .RoutingNumber}}<br>Routing number: {{.RoutingNumber}}{{end}}</p>
+{{end}}{{end}}<p>Thank you for your business.</p>`,
+		Text: `Hi {{.Customer.Name}},
+
+Your invoice {{.Invoice.Number}} for {{amount .Invoice.AmountDue}} {{upper .Invoice.Currency}} is ready.
+{{if .Invoice.PeriodStart}}It covers {{date .Invoice.PeriodStart}} to {{date .Invoice.PeriodEnd}}.
+{{end}}{{with .Issuer}}
+From:
+{{.Name}}
+{{with .Address}}{{.Line1}}
+{{if .Line2}}{{.Line2}}
+{{end}}{{.PostalCode}} {{.City}}{{if .State}}, {{.State}}{{end}}
+{{.Country}}
+{{end}}{{range .TaxIDs}}Tax ID: {{.Value}}
+{{end}}{{end}}{{with .Customer.BillingAddress}}
+Billed to:
+{{.Line1}}
+{{if .Line2}}{{.Line2}}
+{{end}}{{.PostalCode}} {{.City}}{{if .State}}, {{.State}}{{end}}
+{{.Country}}
+{{end}}{{range .Customer.TaxIDs}}Tax ID: {{.Value}}
+{{end}}{{with .Issuer}}{{with .BankDetails}}
+Pay by bank transfer to:
+{{.AccountHolder}}
+{{if .BankName}}{{.BankName}}
+{{end}}{{if .IBAN}}IBAN: {{.IBAN}}
+{{end}}{{if .BIC}}BIC: {{.BIC}}
+{{end}}{{if .AccountNumber}}Account number: {{.AccountNumber}}
+{{end}}{{if .RoutingNumber}}Routing number: {{.RoutingNumber}}
+{{end}}{{end}}{{end}}
+Thank you for your business.`,
+	},
+	types.EmailTemplateTrialWillEnd: {
+		Subject: "Your trial ends on {{date .Subscription.TrialEnd}}",
+		HTML: `<p>Hi {{.Customer.Name}},</p>
+<p>Your trial ends on {{date .Subscription.TrialEnd}}. Your subscription continues as a paid subscription after that.</p>`,
+		Text: `Hi {{.Customer.Name}},
+
+Your trial ends on {{date .Subscription.TrialEnd}}. Your subscription continues as a paid subscription after that.`,
+	},
+	types.EmailTemplateDebitPreNotification: {
+		Subject: "Your account will be debited on {{date .Debit.ChargeDate}}",
+		HTML: `<p>Hi {{.Customer.Name}},</p>
+<p>{{amount .Debit.Amount}} {{upper .Debit.Currency}} will be debited from your bank account on {{date .Debit.ChargeDate}} for invoice {{.Invoice.Number}}.</p>
+<p>Mandate reference: {{.Debit.MandateReference}}</p>
+<p>Please make sure the funds are available on that date.</p>`,
+		Text: `Hi {{.Customer.Name}},
+
+{{amount .Debit.Amount}} {{upper .Debit.Currency}} will be debited from your bank account on {{date .Debit.ChargeDate}} for invoice {{.Invoice.Number}}.
+
+Mandate reference: {{.Debit.MandateReference}}
+
+Please make sure the funds are available on that date.`,
+	},
+	types.EmailTemplateReportReady: {
+		Subject: "Report {{.Report.Name}} is ready",
+		HTML: `<p>Your report {{.Report.Name}} is ready with {{.Report.RowCount}} rows.</p>
+{{if .Report.PeriodStart}}<p>It covers {{date .Report.PeriodStart}} to {{date .Report.PeriodEnd}}.</p>
+{{end}}<p><a href="{{.Report.URL}}">Download the CSV</a> before {{date .Report.ExpiresAt}}.</p>`,
+		Text: `Your report {{.Report.Name}} is ready with {{.Report.RowCount}} rows.
+{{if .Report.PeriodStart}}It covers {{date .Report.PeriodStart}} to {{date .Report.PeriodEnd}}.
+{{end}}
+Download the CSV before {{date .Report.ExpiresAt}}: {{.Report.URL}}`,
+	},
+}
+
+// DefaultTemplate returns the template used for the type when the tenant has
+// not replaced it
+func DefaultTemplate(templateType types.EmailTemplateType) (Template, bool) {
+	t, ok := defaultTemplates[templateType]
+	return t, ok
+}
+
+// SampleData is the data a template of the type is validated against
+func SampleData(templateType types.EmailTemplateType) *Data {
+	now := time.Now().UTC()
+	start := now.AddDate(0, -1, 0)
+	data := &Data{
+		Customer: CustomerData{
+			ID:         "cust_sample",
+			ExternalID: "sample",
+			Name:       "Jane Doe",
+			Email:      "jane@example.com",
+			BillingAddress: &customer.Address{
+				Line1:      "Unter den Linden 1",
+				City:       "Berlin",
+				PostalCode: "10117",
+				Country:    "DE",
+			},
+			TaxIDs: customer.TaxIDs{{Type: types.TaxIDTypeEUVAT, Value: "DE123456789", Country: "DE"}},
+		},
+	}
+
+	switch templateType {
+	case types.EmailTemplateInvoiceFinalized:
+		data.Issuer = &IssuerData{
+			ID:   "le_sample",
+			Name: "Acme GmbH",
+			Address: &customer.Address{
+				Line1:      "Friedrichstrasse 100",
+				City:       "Berlin",
+				PostalCode: "10117",
+				Country:    "DE",
+			},
+			TaxIDs:      customer.TaxIDs{{Type: types.TaxIDTypeEUVAT, Value: "DE987654321", Country: "DE"}},
+			BankDetails: &legalentity.BankDetails{AccountHolder: "Acme GmbH", IBAN: "DE89370400440532013000", BIC: "COBADEFFXXX"},
+		}
+		data.Invoice = &InvoiceData{
+			ID:          "inv_sample",
+			Number:      "INV-000001",
+			Currency:    "usd",
+			Total:       decimal.NewFromInt(100),
+			AmountDue:   decimal.NewFromInt(100),
+			PeriodStart: &start,
+			PeriodEnd:   &now,
+			FinalizedAt: &now,
+		}
+	case types.EmailTemplateTrialWillEnd:
+		data.Subscription = &SubscriptionData{ID: "sub_sample", PlanID: "plan_sample", Currency: "usd", TrialEnd: &now}
+	case types.EmailTemplateDebitPreNotification:
+		data.Invoice = &InvoiceData{
+			ID:          "inv_sample",
+			Number:      "INV-000001",
+			Currency:    "eur",
+			Total:       decimal.NewFromInt(100),
+			AmountDue:   decimal.NewFromInt(100),
+			FinalizedAt: &now,
+		}
+		data.Debit = &DebitData{
+			ID:               "pay_sample",
+			Amount:           decimal.NewFromInt(100),
+			Currency:         "eur",
+			ChargeDate:       now.AddDate(0, 0, 14),
+			Scheme:           string(types.MandateSchemeSEPACore),
+			MandateReference: "FLEX-MANDATE-000001",
+		}
+	case types.EmailTemplateReportReady:
+		data.Report = &ReportData{
+			ID:          "run_sample",
+			Name:        "Monthly revenue",
+			RowCount:    42,
+			PeriodStart: &start,
+			PeriodEnd:   &now,
+			URL:         "https://example.com/reports/run_sample.csv",
+			ExpiresAt:   now.AddDate(0, 0, 7),
+		}
+	}
+	return data
+}
+
+// Render renders the template into a message without its sender and recipient.
+// Templates referring to fields missing from the data fail to render
+func (t Template) Render(data *Data) (*Message, error) {
+	subject, err := renderText("subject", t.Subject, data)
+	if err != nil {
+		return nil, err
+	}
+	// Headers cannot span lines
+	subject = strings.Join(strings.Fields(subject), " ")
+	if subject == "" {
+		return nil, fmt.Errorf("subject is empty")
+	}
+
+	text, err := renderText("text", t.Text, data)
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := renderHTML(t.HTML, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if text == "" && html == "" {
+		return nil, fmt.Errorf("email has no body")
+	}
+
+	return &Message{Subject: subject, HTML: html, Text: text}, nil
+}
+
+func renderText(name, source string, data *Data) (string, error) {
+	tmpl, err := texttemplate.New(name).Funcs(funcs).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func renderHTML(source string, data *Data) (string, error) {
+	tmpl, err := htmltemplate.New("html").Funcs(funcs).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid html template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render html: %w", err)
+	}
+	return buf.String(), nil
+}
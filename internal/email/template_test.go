@@ -0,0 +1,70 @@
+package email
+
+import (
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTemplatesRender(t *testing.T) {
+	for _, templateType := range []types.EmailTemplateType{
+		types.EmailTemplateInvoiceFinalized,
+		types.EmailTemplateTrialWillEnd,
+		types.EmailTemplateReportReady,
+		types.EmailTemplateDebitPreNotification,
+	} {
+		tmpl, ok := DefaultTemplate(templateType)
+		require.True(t, ok, templateType)
+
+		msg, err := tmpl.Render(SampleData(templateType))
+		require.NoError(t, err, templateType)
+		assert.NotEmpty(t, msg.Subject)
+		assert.NotEmpty(t, msg.HTML)
+		assert.NotEmpty(t, msg.Text)
+	}
+}
+
+func TestTemplate_Render(t *testing.T) {
+	data := SampleData(types.EmailTemplateInvoiceFinalized)
+	data.Customer.Name = "<b>Jane</b>"
+
+	msg, err := Template{
+		Subject: "Invoice {{.Invoice.Number}}\nfor {{.Customer.Name}}",
+		HTML:    "<p>Hi {{.Customer.Name}}, {{amount .Invoice.AmountDue}} {{upper .Invoice.Currency}}</p>",
+	}.Render(data)
+	require.NoError(t, err)
+
+	// Only the HTML body is escaped and the subject stays on one line
+	assert.Equal(t, "Invoice INV-000001 for <b>Jane</b>", msg.Subject)
+	assert.Equal(t, "<p>Hi &lt;b&gt;Jane&lt;/b&gt;, 100.00 USD</p>", msg.HTML)
+
+	_, err = Template{Subject: "{{.Invoice.Missing}}", Text: "body"}.Render(data)
+	assert.Error(t, err)
+
+	_, err = Template{Subject: "Trial ends", Text: "{{.Subscription.TrialEnd}}"}.Render(data)
+	assert.Error(t, err, "the subscription is not set for invoice emails")
+
+	_, err = Template{Subject: "No body"}.Render(data)
+	assert.Error(t, err)
+}
+
+func TestInvoiceFinalizedTemplate_Issuer(t *testing.T) {
+	tmpl, ok := DefaultTemplate(types.EmailTemplateInvoiceFinalized)
+	require.True(t, ok)
+
+	data := SampleData(types.EmailTemplateInvoiceFinalized)
+	msg, err := tmpl.Render(data)
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, "From:\nAcme GmbH\nFriedrichstrasse 100\n10117 Berlin\nDE\nTax ID: DE987654321\n")
+	assert.Contains(t, msg.Text, "Pay by bank transfer to:\nAcme GmbH\nIBAN: DE89370400440532013000\nBIC: COBADEFFXXX\n")
+	assert.Contains(t, msg.HTML, "IBAN: DE89370400440532013000")
+
+	// Invoices issued from no legal entity do not name an issuer
+	data.Issuer = nil
+	msg, err = tmpl.Render(data)
+	require.NoError(t, err)
+	assert.NotContains(t, msg.Text, "From:")
+	assert.NotContains(t, msg.HTML, "bank transfer")
+}
@@ -0,0 +1,53 @@
+package export
+
+import "time"
+
+// EventRow is the flattened representation of a raw event in an export file.
+// Properties are serialized as a JSON string to keep the schema stable.
+type EventRow struct {
+	ID                 string    `parquet:"id" csv:"id"`
+	TenantID           string    `parquet:"tenant_id" csv:"tenant_id"`
+	EventName          string    `parquet:"event_name" csv:"event_name"`
+	CustomerID         string    `parquet:"customer_id" csv:"customer_id"`
+	ExternalCustomerID string    `parquet:"external_customer_id" csv:"external_customer_id"`
+	Source             string    `parquet:"source" csv:"source"`
+	Timestamp          time.Time `parquet:"timestamp,timestamp(millisecond)" csv:"timestamp"`
+	Properties         string    `parquet:"properties" csv:"properties"`
+}
+
+func (r EventRow) record() []string {
+	return []string{
+		r.ID,
+		r.TenantID,
+		r.EventName,
+		r.CustomerID,
+		r.ExternalCustomerID,
+		r.Source,
+		r.Timestamp.UTC().Format(time.RFC3339Nano),
+		r.Properties,
+	}
+}
+
+// UsageRow is a single aggregated usage window of a meter
+type UsageRow struct {
+	MeterID         string    `parquet:"meter_id" csv:"meter_id"`
+	EventName       string    `parquet:"event_name" csv:"event_name"`
+	AggregationType string    `parquet:"aggregation_type" csv:"aggregation_type"`
+	WindowStart     time.Time `parquet:"window_start,timestamp(millisecond)" csv:"window_start"`
+	Value           string    `parquet:"value" csv:"value"`
+}
+
+func (r UsageRow) record() []string {
+	return []string{
+		r.MeterID,
+		r.EventName,
+		r.AggregationType,
+		r.WindowStart.UTC().Format(time.RFC3339Nano),
+		r.Value,
+	}
+}
+
+var (
+	eventHeader = []string{"id", "tenant_id", "event_name", "customer_id", "external_customer_id", "source", "timestamp", "properties"}
+	usageHeader = []string{"meter_id", "event_name", "aggregation_type", "window_start", "value"}
+)
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Row is implemented by the supported export row types
+type Row interface {
+	EventRow | UsageRow
+	record() []string
+}
+
+// Writer streams rows of a single type into an export file
+type Writer[T Row] interface {
+	Write(rows []T) error
+	Close() error
+}
+
+// NewWriter returns a writer for the given format writing into w.
+// Close must be called to flush the footer (parquet) or buffered records (csv).
+func NewWriter[T Row](format types.ExportFormat, w io.Writer) (Writer[T], error) {
+	switch format {
+	case types.ExportFormatParquet:
+		return &parquetWriter[T]{w: parquet.NewGenericWriter[T](w)}, nil
+	case types.ExportFormatCSV:
+		cw := &csvWriter[T]{w: csv.NewWriter(w)}
+		if err := cw.w.Write(header[T]()); err != nil {
+			return nil, fmt.Errorf("failed to write csv header: %w", err)
+		}
+		return cw, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func header[T Row]() []string {
+	var zero T
+	switch any(zero).(type) {
+	case EventRow:
+		return eventHeader
+	default:
+		return usageHeader
+	}
+}
+
+type parquetWriter[T Row] struct {
+	w *parquet.GenericWriter[T]
+}
+
+func (p *parquetWriter[T]) Write(rows []T) error {
+	if _, err := p.w.Write(rows); err != nil {
+		return fmt.Errorf("failed to write parquet rows: %w", err)
+	}
+	return nil
+}
+
+func (p *parquetWriter[T]) Close() error {
+	return p.w.Close()
+}
+
+type csvWriter[T Row] struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter[T]) Write(rows []T) error {
+	for _, row := range rows {
+		if err := c.w.Write(row.record()); err != nil {
+			return fmt.Errorf("failed to write csv row: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *csvWriter[T]) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
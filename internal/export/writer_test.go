@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEventRows() []EventRow {
+	ts := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	return []EventRow{
+		{ID: "evt-1", TenantID: "t1", EventName: "api_call", CustomerID: "c1", Timestamp: ts, Properties: `{"tokens":10}`},
+		{ID: "evt-2", TenantID: "t1", EventName: "api_call", ExternalCustomerID: "ext-1", Timestamp: ts.Add(time.Minute), Properties: `{}`},
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[EventRow](types.ExportFormatCSV, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(testEventRows()))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, eventHeader, records[0])
+	assert.Equal(t, "evt-1", records[1][0])
+	assert.Equal(t, "2024-03-01T10:00:00Z", records[1][6])
+	assert.Equal(t, `{"tokens":10}`, records[1][7])
+}
+
+func TestParquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter[UsageRow](types.ExportFormatParquet, &buf)
+	require.NoError(t, err)
+
+	rows := []UsageRow{
+		{MeterID: "m1", EventName: "api_call", AggregationType: "SUM", WindowStart: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Value: "42"},
+	}
+	require.NoError(t, w.Write(rows))
+	require.NoError(t, w.Close())
+
+	got, err := parquet.Read[UsageRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "m1", got[0].MeterID)
+	assert.Equal(t, "42", got[0].Value)
+	assert.True(t, rows[0].WindowStart.Equal(got[0].WindowStart))
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	_, err := NewWriter[EventRow](types.ExportFormat("XML"), &bytes.Buffer{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,358 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+const adyenTestURL = "https://checkout-test.adyen.com/v71"
+
+type adyenClient struct {
+	baseURL         string
+	merchantAccount string
+	apiKey          string
+	hmacKey         string
+	client          *http.Client
+}
+
+func newAdyenClient(settings connection.GatewaySettings, apiKey, hmacKey string) *adyenClient {
+	baseURL := adyenTestURL
+	if settings.LiveURLPrefix != "" {
+		baseURL = fmt.Sprintf("https://%s-checkout-live.adyenpayments.com/checkout/v71", settings.LiveURLPrefix)
+	}
+	return &adyenClient{
+		baseURL:         baseURL,
+		merchantAccount: settings.MerchantAccount,
+		apiKey:          apiKey,
+		hmacKey:         hmacKey,
+		client:          &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type adyenAmount struct {
+	Value    int64  `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type adyenStoredPaymentMethod struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Brand       string `json:"brand"`
+	LastFour    string `json:"lastFour"`
+	ExpiryMonth string `json:"expiryMonth"`
+	ExpiryYear  string `json:"expiryYear"`
+}
+
+type adyenPaymentResponse struct {
+	PSPReference      string `json:"pspReference"`
+	ResultCode        string `json:"resultCode"`
+	RefusalReason     string `json:"refusalReason"`
+	RefusalReasonCode string `json:"refusalReasonCode"`
+}
+
+// adyenModificationResponse is the response of a capture or a refund, they are
+// always processed asynchronously
+type adyenModificationResponse struct {
+	PSPReference string `json:"pspReference"`
+	Status       string `json:"status"`
+}
+
+func (c *adyenClient) AttachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) (*PaymentMethod, error) {
+	query := url.Values{"merchantAccount": {c.merchantAccount}, "shopperReference": {customerID}}
+
+	var result struct {
+		StoredPaymentMethods []adyenStoredPaymentMethod `json:"storedPaymentMethods"`
+	}
+	if err := c.do(ctx, http.MethodGet, "storedPaymentMethods?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+
+	// Adyen stores the payment methods of a shopper as they are tokenized, they
+	// only have to exist
+	for _, stored := range result.StoredPaymentMethods {
+		if stored.ID != paymentMethodID {
+			continue
+		}
+		method := &PaymentMethod{ID: stored.ID, Type: stored.Type, Brand: stored.Brand, Last4: stored.LastFour}
+		method.ExpMonth, _ = strconv.Atoi(stored.ExpiryMonth)
+		method.ExpYear, _ = strconv.Atoi(stored.ExpiryYear)
+		if method.ExpYear > 0 && method.ExpYear < 100 {
+			method.ExpYear += 2000
+		}
+		return method, nil
+	}
+	return nil, fmt.Errorf("adyen has no stored payment method %s for shopper %s", paymentMethodID, customerID)
+}
+
+func (c *adyenClient) DetachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	query := url.Values{"merchantAccount": {c.merchantAccount}, "shopperReference": {customerID}}
+	return c.do(ctx, http.MethodDelete, "storedPaymentMethods/"+url.PathEscape(paymentMethodID)+"?"+query.Encode(), nil, nil)
+}
+
+// SetDefaultPaymentMethod is a no-op, Adyen has no default payment method
+func (c *adyenClient) SetDefaultPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	return nil
+}
+
+func (c *adyenClient) Authorize(ctx context.Context, req *AuthorizeRequest) (*Payment, error) {
+	return c.payment(ctx, req, true)
+}
+
+// Debit collects a payment without manual capture. Adyen authorises bank debits
+// when they are submitted, the AUTHORISATION webhook notifies that they cleared
+// and the CHARGEBACK webhook that they were returned
+func (c *adyenClient) Debit(ctx context.Context, req *AuthorizeRequest) (*Payment, error) {
+	payment, err := c.payment(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == PaymentStatusAuthorized {
+		payment.Status = PaymentStatusPending
+	}
+	return payment, nil
+}
+
+func (c *adyenClient) payment(ctx context.Context, req *AuthorizeRequest, manualCapture bool) (*Payment, error) {
+	methodType := req.PaymentMethodType
+	if methodType == "" || methodType == "card" {
+		methodType = "scheme"
+	}
+
+	body := map[string]interface{}{
+		"merchantAccount":          c.merchantAccount,
+		"amount":                   adyenAmount{Value: minorUnits(req.Amount, req.Currency), Currency: strings.ToUpper(req.Currency)},
+		"reference":                req.Reference,
+		"shopperReference":         req.CustomerID,
+		"shopperEmail":             req.Email,
+		"shopperInteraction":       "ContAuth",
+		"recurringProcessingModel": "UnscheduledCardOnFile",
+		"paymentMethod": map[string]string{
+			"type":                  methodType,
+			"storedPaymentMethodId": req.PaymentMethodID,
+		},
+	}
+	if manualCapture {
+		body["additionalData"] = map[string]string{"manualCapture": "true"}
+	}
+
+	var result adyenPaymentResponse
+	if err := c.doIdempotent(ctx, http.MethodPost, "payments", body, req.IdempotencyKey, &result); err != nil {
+		return nil, err
+	}
+
+	payment := &Payment{ID: result.PSPReference}
+	switch result.ResultCode {
+	case "Authorised":
+		payment.Status = PaymentStatusAuthorized
+	case "Pending", "Received":
+		payment.Status = PaymentStatusPending
+	default:
+		payment.Status = PaymentStatusFailed
+		payment.FailureCode = result.RefusalReasonCode
+		payment.FailureReason = result.ResultCode
+		if result.RefusalReason != "" {
+			payment.FailureReason = result.RefusalReason
+		}
+	}
+	return payment, nil
+}
+
+func (c *adyenClient) Capture(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*Payment, error) {
+	body := map[string]interface{}{
+		"merchantAccount": c.merchantAccount,
+		"amount":          adyenAmount{Value: minorUnits(amount, currency), Currency: strings.ToUpper(currency)},
+	}
+
+	var result adyenModificationResponse
+	if err := c.doIdempotent(ctx, http.MethodPost, "payments/"+url.PathEscape(paymentID)+"/captures", body, idempotencyKey, &result); err != nil {
+		return nil, err
+	}
+
+	// The outcome of the capture is notified by the CAPTURE webhook
+	return &Payment{ID: paymentID, Status: PaymentStatusPending}, nil
+}
+
+func (c *adyenClient) Refund(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*Refund, error) {
+	body := map[string]interface{}{
+		"merchantAccount": c.merchantAccount,
+		"amount":          adyenAmount{Value: minorUnits(amount, currency), Currency: strings.ToUpper(currency)},
+	}
+
+	var result adyenModificationResponse
+	if err := c.doIdempotent(ctx, http.MethodPost, "payments/"+url.PathEscape(paymentID)+"/refunds", body, idempotencyKey, &result); err != nil {
+		return nil, err
+	}
+
+	// The outcome of the refund is notified by the REFUND webhook
+	return &Refund{ID: result.PSPReference, Status: types.RefundStatusPending}, nil
+}
+
+type adyenNotificationItem struct {
+	PSPReference        string            `json:"pspReference"`
+	OriginalReference   string            `json:"originalReference"`
+	MerchantAccountCode string            `json:"merchantAccountCode"`
+	MerchantReference   string            `json:"merchantReference"`
+	Amount              adyenAmount       `json:"amount"`
+	EventCode           string            `json:"eventCode"`
+	Success             string            `json:"success"`
+	Reason              string            `json:"reason"`
+	AdditionalData      map[string]string `json:"additionalData"`
+}
+
+type adyenNotification struct {
+	NotificationItems []struct {
+		Item adyenNotificationItem `json:"NotificationRequestItem"`
+	} `json:"notificationItems"`
+}
+
+// ParseWebhook verifies the HMAC signature of each item of a standard
+// notification, Adyen batches several events in one webhook
+func (c *adyenClient) ParseWebhook(payload []byte, header http.Header) ([]*WebhookEvent, error) {
+	var notification adyenNotification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return nil, fmt.Errorf("failed to decode adyen notification: %w", err)
+	}
+
+	key, err := hex.DecodeString(c.hmacKey)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("%w: the hmac key of the connection is not set", ErrInvalidSignature)
+	}
+
+	var events []*WebhookEvent
+	for _, wrapper := range notification.NotificationItems {
+		item := wrapper.Item
+		if !validAdyenSignature(item, key) {
+			return nil, ErrInvalidSignature
+		}
+
+		event := &WebhookEvent{
+			ID:            item.PSPReference + ":" + item.EventCode + ":" + item.Success,
+			FailureReason: item.Reason,
+		}
+		success := item.Success == "true"
+		switch {
+		case item.EventCode == "AUTHORISATION" && success:
+			event.Type = EventTypePaymentAuthorized
+			event.PaymentID = item.PSPReference
+		case item.EventCode == "AUTHORISATION":
+			event.Type = EventTypePaymentFailed
+			event.PaymentID = item.PSPReference
+			event.FailureCode = item.AdditionalData["refusalReasonCode"]
+		case item.EventCode == "CHARGEBACK":
+			// Returned bank debits, the reason code is the one of the scheme
+			event.Type = EventTypePaymentFailed
+			event.PaymentID = item.OriginalReference
+			event.FailureCode = item.AdditionalData["chargebackReasonCode"]
+		case item.EventCode == "CAPTURE" && success:
+			event.Type = EventTypePaymentCaptured
+			event.PaymentID = item.OriginalReference
+		case item.EventCode == "CAPTURE" || item.EventCode == "CAPTURE_FAILED":
+			event.Type = EventTypePaymentFailed
+			event.PaymentID = item.OriginalReference
+		case item.EventCode == "REFUND" && success:
+			event.Type = EventTypeRefundSucceeded
+			event.PaymentID = item.OriginalReference
+			event.RefundID = item.PSPReference
+		case item.EventCode == "REFUND" || item.EventCode == "REFUND_FAILED" || item.EventCode == "REFUNDED_REVERSED":
+			event.Type = EventTypeRefundFailed
+			event.PaymentID = item.OriginalReference
+			event.RefundID = item.PSPReference
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// validAdyenSignature checks the hmacSignature of a notification item, the
+// HMAC-SHA256 of its fields joined with colons
+func validAdyenSignature(item adyenNotificationItem, key []byte) bool {
+	signature, err := base64.StdEncoding.DecodeString(item.AdditionalData["hmacSignature"])
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(adyenSigningString(item)))
+	return hmac.Equal(signature, mac.Sum(nil))
+}
+
+func adyenSigningString(item adyenNotificationItem) string {
+	return strings.Join([]string{
+		item.PSPReference,
+		item.OriginalReference,
+		item.MerchantAccountCode,
+		item.MerchantReference,
+		strconv.FormatInt(item.Amount.Value, 10),
+		item.Amount.Currency,
+		item.EventCode,
+		item.Success,
+	}, ":")
+}
+
+func (c *adyenClient) do(ctx context.Context, method, resource string, body, out interface{}) error {
+	return c.doIdempotent(ctx, method, resource, body, "", out)
+}
+
+// doIdempotent sends the Idempotency-Key header with the request when a key is
+// given, Adyen returns the response of the first request with the key instead
+// of making the payment or the modification again
+func (c *adyenClient) doIdempotent(ctx context.Context, method, resource string, body interface{}, idempotencyKey string, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode adyen request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/"+resource, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.apiKey)
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call adyen: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return rateLimitError(resp)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("adyen responded with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode adyen response: %w", err)
+	}
+	return nil
+}
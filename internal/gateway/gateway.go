@@ -0,0 +1,220 @@
+// Package gateway collects the payments of the customers through the payment
+// gateways, Stripe, Adyen and Razorpay, of the integration connections
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidSignature is returned for webhooks not signed with the webhook
+// secret of the connection
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// PaymentMethod is a payment method stored for a customer at the gateway
+type PaymentMethod struct {
+	ID string
+	// Type is card, sepa_debit, upi... as named by the gateway
+	Type string
+	// Brand, Last4, ExpMonth and ExpYear are only set for cards
+	Brand    string
+	Last4    string
+	ExpMonth int
+	ExpYear  int
+}
+
+// PaymentStatus is the outcome of an authorization or a capture at the gateway
+type PaymentStatus string
+
+const (
+	// PaymentStatusAuthorized payments hold the amount until it is captured
+	PaymentStatusAuthorized PaymentStatus = "authorized"
+	PaymentStatusCaptured   PaymentStatus = "captured"
+	// PaymentStatusPending payments were accepted, their outcome is notified by
+	// webhook. Captures of Adyen are always pending
+	PaymentStatusPending PaymentStatus = "pending"
+	PaymentStatusFailed  PaymentStatus = "failed"
+)
+
+// Payment is a payment at the gateway ex a Stripe payment intent, the PSP
+// reference of an Adyen payment or a Razorpay payment
+type Payment struct {
+	ID     string
+	Status PaymentStatus
+	// FailureCode is the failure code of the gateway or the return code of the
+	// bank debit scheme ex AM04 or R01, see types.DunningActionFor
+	FailureCode   string
+	FailureReason string
+}
+
+// Refund is a refund at the gateway
+type Refund struct {
+	ID            string
+	Status        types.RefundStatus
+	FailureReason string
+}
+
+// AuthorizeRequest charges a payment method stored for a customer, without the
+// customer being present
+type AuthorizeRequest struct {
+	// CustomerID is the ID of the customer at the gateway, see CustomerMetadataKey
+	CustomerID        string
+	PaymentMethodID   string
+	PaymentMethodType string
+	Amount            decimal.Decimal
+	Currency          string
+	// Reference is the ID of the invoice paid, it is shown at the gateway
+	Reference string
+	// Email of the customer, Razorpay requires it
+	Email string
+	// MandateReference is the reference of the mandate of a bank debit
+	MandateReference string
+	// IdempotencyKey makes a request retried by the sync queue return the payment
+	// made first instead of charging the customer again, see IdempotencyKey
+	IdempotencyKey string
+}
+
+// EventType is what a webhook of a gateway notifies, mapped from the event
+// types of each gateway
+type EventType string
+
+const (
+	EventTypePaymentAuthorized EventType = "payment.authorized"
+	EventTypePaymentCaptured   EventType = "payment.captured"
+	EventTypePaymentFailed     EventType = "payment.failed"
+	EventTypeRefundSucceeded   EventType = "refund.succeeded"
+	EventTypeRefundFailed      EventType = "refund.failed"
+)
+
+// WebhookEvent is an event of a webhook of the gateway about a payment or a
+// refund. Events of other types are left out when parsing
+type WebhookEvent struct {
+	// ID identifies the event at the gateway, gateways deliver an event again
+	// until it is acknowledged
+	ID            string
+	Type          EventType
+	PaymentID     string
+	RefundID      string
+	FailureCode   string
+	FailureReason string
+}
+
+// Client collects payments through the gateway of a connection
+type Client interface {
+	// AttachPaymentMethod attaches a payment method collected at the gateway, ex
+	// with Stripe.js or the Adyen Drop-in, to the customer and returns it
+	AttachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) (*PaymentMethod, error)
+
+	// DetachPaymentMethod removes a payment method of the customer, it can no
+	// longer be charged
+	DetachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error
+
+	// SetDefaultPaymentMethod sets the payment method the customer is charged to
+	// at the gateway. Gateways without a default payment method ignore it
+	SetDefaultPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error
+
+	// Authorize holds an amount on a payment method of the customer
+	Authorize(ctx context.Context, req *AuthorizeRequest) (*Payment, error)
+
+	// Capture captures an amount, at most the amount authorized, of a payment.
+	// A capture retried with the same idempotency key is made once
+	Capture(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*Payment, error)
+
+	// Debit debits a bank account of the customer under its mandate. Bank debits
+	// can not be authorized, they are collected in one step and stay pending
+	// until they clear, which is notified by webhook
+	Debit(ctx context.Context, req *AuthorizeRequest) (*Payment, error)
+
+	// Refund refunds an amount in the major unit of the currency of a payment. A
+	// refund retried with the same idempotency key is made once
+	Refund(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*Refund, error)
+
+	// ParseWebhook verifies the signature of a webhook of the gateway with the
+	// webhook secret of the connection and returns its payment and refund events
+	ParseWebhook(payload []byte, header http.Header) ([]*WebhookEvent, error)
+}
+
+// NewClient returns the client of the payment gateway of the connection
+func NewClient(conn *connection.Connection) (Client, error) {
+	if conn.Credentials == "" {
+		return nil, fmt.Errorf("connection %s has no credentials", conn.ID)
+	}
+
+	switch conn.Provider {
+	case types.IntegrationProviderStripe:
+		return newStripeClient(conn.Credentials, conn.WebhookSecret), nil
+	case types.IntegrationProviderAdyen:
+		if conn.GatewaySettings == nil || conn.GatewaySettings.MerchantAccount == "" {
+			return nil, fmt.Errorf("connection %s has no merchant account", conn.ID)
+		}
+		return newAdyenClient(*conn.GatewaySettings, conn.Credentials, conn.WebhookSecret), nil
+	case types.IntegrationProviderRazorpay:
+		if conn.GatewaySettings == nil || conn.GatewaySettings.KeyID == "" {
+			return nil, fmt.Errorf("connection %s has no key id", conn.ID)
+		}
+		return newRazorpayClient(*conn.GatewaySettings, conn.Credentials, conn.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("provider %s is not a payment gateway", conn.Provider)
+	}
+}
+
+// IdempotencyKey derives the idempotency key of a request that moves money from
+// the IDs it is made for ex the invoice, the payment and the step. The key is 32
+// hex characters, short enough for the 64 characters of Adyen and the 40 of the
+// receipts of Razorpay
+func IdempotencyKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, ":")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// CustomerMetadataKey returns the metadata key of the customers holding their
+// ID at the gateway of the provider
+func CustomerMetadataKey(provider types.IntegrationProvider) string {
+	switch provider {
+	case types.IntegrationProviderAdyen:
+		return types.MetadataAdyenShopperReference
+	case types.IntegrationProviderRazorpay:
+		return types.MetadataRazorpayCustomerID
+	default:
+		return types.MetadataStripeCustomerID
+	}
+}
+
+// currencyExponents are the ISO 4217 currencies whose minor unit is not the cent
+var currencyExponents = map[string]int32{
+	"bif": 0, "clp": 0, "djf": 0, "gnf": 0, "isk": 0, "jpy": 0, "kmf": 0, "krw": 0,
+	"pyg": 0, "rwf": 0, "ugx": 0, "uyi": 0, "vnd": 0, "vuv": 0, "xaf": 0, "xof": 0, "xpf": 0,
+	"bhd": 3, "iqd": 3, "jod": 3, "kwd": 3, "lyd": 3, "omr": 3, "tnd": 3,
+}
+
+// minorUnits converts an amount in the major unit of a currency to the integer
+// amount in its minor unit that Adyen and Razorpay expect
+func minorUnits(amount decimal.Decimal, currency string) int64 {
+	exponent, ok := currencyExponents[strings.ToLower(currency)]
+	if !ok {
+		exponent = 2
+	}
+	return amount.Shift(exponent).Round(0).IntPart()
+}
+
+// rateLimitError maps a 429 response to the error that makes the sync queue of
+// the connection back off
+func rateLimitError(resp *http.Response) error {
+	var retryAfter time.Duration
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+	return &syncqueue.RateLimitError{RetryAfter: retryAfter}
+}
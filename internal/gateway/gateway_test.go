@@ -0,0 +1,361 @@
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	client, err := NewClient(&connection.Connection{Provider: types.IntegrationProviderStripe, Credentials: "sk_test"})
+	require.NoError(t, err)
+	assert.IsType(t, &stripeClient{}, client)
+
+	client, err = NewClient(&connection.Connection{
+		Provider:        types.IntegrationProviderAdyen,
+		GatewaySettings: &connection.GatewaySettings{MerchantAccount: "AcmeECOM", LiveURLPrefix: "1797a841fbb37ca7-AdyenDemo"},
+		Credentials:     "api-key",
+	})
+	require.NoError(t, err)
+	require.IsType(t, &adyenClient{}, client)
+	assert.Equal(t, "https://1797a841fbb37ca7-AdyenDemo-checkout-live.adyenpayments.com/checkout/v71", client.(*adyenClient).baseURL)
+
+	client, err = NewClient(&connection.Connection{
+		Provider:        types.IntegrationProviderRazorpay,
+		GatewaySettings: &connection.GatewaySettings{KeyID: "rzp_test_1"},
+		Credentials:     "secret",
+	})
+	require.NoError(t, err)
+	assert.IsType(t, &razorpayClient{}, client)
+
+	_, err = NewClient(&connection.Connection{Provider: types.IntegrationProviderAdyen, Credentials: "api-key"})
+	assert.Error(t, err)
+	_, err = NewClient(&connection.Connection{Provider: types.IntegrationProviderHubSpot, Credentials: "token"})
+	assert.Error(t, err)
+}
+
+func TestMinorUnits(t *testing.T) {
+	assert.Equal(t, int64(1050), minorUnits(decimal.RequireFromString("10.5"), "EUR"))
+	assert.Equal(t, int64(1050), minorUnits(decimal.RequireFromString("1050"), "jpy"))
+	assert.Equal(t, int64(10500), minorUnits(decimal.RequireFromString("10.5"), "KWD"))
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	key := IdempotencyKey("inv_1", "pay_1", "authorize")
+	assert.Len(t, key, 32)
+	assert.Equal(t, key, IdempotencyKey("inv_1", "pay_1", "authorize"))
+	assert.NotEqual(t, key, IdempotencyKey("inv_1", "pay_1", "capture"))
+	assert.NotEqual(t, key, IdempotencyKey("inv_1", "pay_2", "authorize"))
+}
+
+func TestAdyenClient_Payments(t *testing.T) {
+	var paths, keys []string
+	var authorization map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		assert.Equal(t, "api-key", r.Header.Get("X-API-Key"))
+		if r.Method == http.MethodPost {
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+		}
+
+		switch r.URL.Path {
+		case "/storedPaymentMethods":
+			assert.Equal(t, "shopper_1", r.URL.Query().Get("shopperReference"))
+			_, _ = w.Write([]byte(`{"storedPaymentMethods":[{"id":"8415","type":"scheme","brand":"visa","lastFour":"1111","expiryMonth":"03","expiryYear":"30"}]}`))
+		case "/payments":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&authorization))
+			_, _ = w.Write([]byte(`{"pspReference":"PSP1","resultCode":"Authorised"}`))
+		case "/payments/PSP1/captures":
+			_, _ = w.Write([]byte(`{"pspReference":"PSP2","status":"received"}`))
+		case "/payments/PSP1/refunds":
+			_, _ = w.Write([]byte(`{"pspReference":"PSP3","status":"received"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newAdyenClient(connection.GatewaySettings{MerchantAccount: "AcmeECOM"}, "api-key", "")
+	assert.Equal(t, adyenTestURL, client.baseURL)
+	client.baseURL = server.URL
+	ctx := context.Background()
+
+	pm, err := client.AttachPaymentMethod(ctx, "shopper_1", "8415")
+	require.NoError(t, err)
+	assert.Equal(t, &PaymentMethod{ID: "8415", Type: "scheme", Brand: "visa", Last4: "1111", ExpMonth: 3, ExpYear: 2030}, pm)
+
+	_, err = client.AttachPaymentMethod(ctx, "shopper_1", "9999")
+	assert.Error(t, err)
+
+	payment, err := client.Authorize(ctx, &AuthorizeRequest{
+		CustomerID:      "shopper_1",
+		PaymentMethodID: "8415",
+		Amount:          decimal.RequireFromString("12.34"),
+		Currency:        "eur",
+		Reference:       "inv_1",
+		IdempotencyKey:  "key_authorize",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Payment{ID: "PSP1", Status: PaymentStatusAuthorized}, payment)
+	assert.Equal(t, "AcmeECOM", authorization["merchantAccount"])
+	assert.Equal(t, map[string]interface{}{"value": float64(1234), "currency": "EUR"}, authorization["amount"])
+	assert.Equal(t, "scheme", authorization["paymentMethod"].(map[string]interface{})["type"])
+
+	payment, err = client.Capture(ctx, "PSP1", decimal.RequireFromString("12.34"), "eur", "key_capture")
+	require.NoError(t, err)
+	assert.Equal(t, &Payment{ID: "PSP1", Status: PaymentStatusPending}, payment)
+
+	refund, err := client.Refund(ctx, "PSP1", decimal.NewFromInt(5), "eur", "key_refund")
+	require.NoError(t, err)
+	assert.Equal(t, &Refund{ID: "PSP3", Status: types.RefundStatusPending}, refund)
+
+	assert.Equal(t, []string{
+		"GET /storedPaymentMethods", "GET /storedPaymentMethods",
+		"POST /payments", "POST /payments/PSP1/captures", "POST /payments/PSP1/refunds",
+	}, paths)
+	assert.Equal(t, []string{"key_authorize", "key_capture", "key_refund"}, keys)
+}
+
+func TestAdyenClient_ParseWebhook(t *testing.T) {
+	key := "44782DEF547AAA06C910C43932B1EB0C71FC68D9D0C057550C48EC2ACF6BA056"
+	client := newAdyenClient(connection.GatewaySettings{MerchantAccount: "AcmeECOM"}, "api-key", key)
+
+	item := adyenNotificationItem{
+		PSPReference:        "PSP3",
+		OriginalReference:   "PSP1",
+		MerchantAccountCode: "AcmeECOM",
+		MerchantReference:   "inv_1",
+		Amount:              adyenAmount{Value: 500, Currency: "EUR"},
+		EventCode:           "REFUND",
+		Success:             "true",
+	}
+	rawKey, err := hex.DecodeString(key)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, rawKey)
+	mac.Write([]byte(adyenSigningString(item)))
+	item.AdditionalData = map[string]string{"hmacSignature": base64.StdEncoding.EncodeToString(mac.Sum(nil))}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"live":              "false",
+		"notificationItems": []map[string]interface{}{{"NotificationRequestItem": item}},
+	})
+	require.NoError(t, err)
+
+	events, err := client.ParseWebhook(payload, http.Header{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, EventTypeRefundSucceeded, events[0].Type)
+	assert.Equal(t, "PSP1", events[0].PaymentID)
+	assert.Equal(t, "PSP3", events[0].RefundID)
+
+	item.Amount.Value = 50000
+	payload, err = json.Marshal(map[string]interface{}{
+		"notificationItems": []map[string]interface{}{{"NotificationRequestItem": item}},
+	})
+	require.NoError(t, err)
+	_, err = client.ParseWebhook(payload, http.Header{})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestRazorpayClient_Payments(t *testing.T) {
+	var paths []string
+	captured, refunded := false, false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		keyID, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "rzp_test_1", keyID)
+		assert.Equal(t, "secret", secret)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /orders":
+			assert.Equal(t, "key_authorize", r.URL.Query().Get("receipt"))
+			_, _ = w.Write([]byte(`{"items":[]}`))
+		case "POST /orders":
+			var order map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&order))
+			assert.Equal(t, float64(150000), order["amount"])
+			assert.Equal(t, float64(0), order["payment_capture"])
+			assert.Equal(t, "key_authorize", order["receipt"])
+			_, _ = w.Write([]byte(`{"id":"order_1"}`))
+		case "POST /payments/create/recurring":
+			_, _ = w.Write([]byte(`{"razorpay_payment_id":"pay_1"}`))
+		case "GET /payments/pay_1":
+			if captured {
+				_, _ = w.Write([]byte(`{"id":"pay_1","status":"captured"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"pay_1","status":"authorized"}`))
+		case "POST /payments/pay_1/capture":
+			captured = true
+			_, _ = w.Write([]byte(`{"id":"pay_1","status":"captured"}`))
+		case "GET /payments/pay_1/refunds":
+			if refunded {
+				_, _ = w.Write([]byte(`{"items":[{"id":"rfnd_1","payment_id":"pay_1","status":"processed","receipt":"key_refund"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"items":[]}`))
+		case "POST /payments/pay_1/refund":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "key_refund", body["receipt"])
+			refunded = true
+			_, _ = w.Write([]byte(`{"id":"rfnd_1","payment_id":"pay_1","status":"processed"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newRazorpayClient(connection.GatewaySettings{KeyID: "rzp_test_1"}, "secret", "")
+	client.baseURL = server.URL
+	ctx := context.Background()
+
+	payment, err := client.Authorize(ctx, &AuthorizeRequest{
+		CustomerID:      "cust_1",
+		PaymentMethodID: "token_1",
+		Amount:          decimal.NewFromInt(1500),
+		Currency:        "inr",
+		Reference:       "inv_1",
+		IdempotencyKey:  "key_authorize",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Payment{ID: "pay_1", Status: PaymentStatusAuthorized}, payment)
+
+	payment, err = client.Capture(ctx, "pay_1", decimal.NewFromInt(1500), "inr", "key_capture")
+	require.NoError(t, err)
+	assert.Equal(t, PaymentStatusCaptured, payment.Status)
+
+	refund, err := client.Refund(ctx, "pay_1", decimal.NewFromInt(500), "inr", "key_refund")
+	require.NoError(t, err)
+	assert.Equal(t, &Refund{ID: "rfnd_1", Status: types.RefundStatusSucceeded}, refund)
+
+	assert.Equal(t, []string{
+		"GET /orders", "POST /orders", "POST /payments/create/recurring", "GET /payments/pay_1",
+		"GET /payments/pay_1", "POST /payments/pay_1/capture",
+		"GET /payments/pay_1/refunds", "POST /payments/pay_1/refund",
+	}, paths)
+
+	// Retried after the gateway made them, the capture and the refund are not
+	// made again
+	paths = nil
+	payment, err = client.Capture(ctx, "pay_1", decimal.NewFromInt(1500), "inr", "key_capture")
+	require.NoError(t, err)
+	assert.Equal(t, PaymentStatusCaptured, payment.Status)
+	refund, err = client.Refund(ctx, "pay_1", decimal.NewFromInt(500), "inr", "key_refund")
+	require.NoError(t, err)
+	assert.Equal(t, "rfnd_1", refund.ID)
+	assert.Equal(t, []string{"GET /payments/pay_1", "GET /payments/pay_1/refunds"}, paths)
+}
+
+func TestRazorpayClient_AuthorizeRetried(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /orders":
+			_, _ = w.Write([]byte(`{"items":[{"id":"order_1"}]}`))
+		case "GET /orders/order_1/payments":
+			_, _ = w.Write([]byte(`{"items":[{"id":"pay_1","status":"authorized"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newRazorpayClient(connection.GatewaySettings{KeyID: "rzp_test_1"}, "secret", "")
+	client.baseURL = server.URL
+
+	// The order of the idempotency key has a payment, the customer is not charged
+	// again
+	payment, err := client.Authorize(context.Background(), &AuthorizeRequest{
+		CustomerID:      "cust_1",
+		PaymentMethodID: "token_1",
+		Amount:          decimal.NewFromInt(1500),
+		Currency:        "inr",
+		Reference:       "inv_1",
+		IdempotencyKey:  "key_authorize",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Payment{ID: "pay_1", Status: PaymentStatusAuthorized}, payment)
+	assert.Equal(t, []string{"GET /orders", "GET /orders/order_1/payments"}, paths)
+}
+
+func TestRazorpayClient_ParseWebhook(t *testing.T) {
+	client := newRazorpayClient(connection.GatewaySettings{KeyID: "rzp_test_1"}, "secret", "whsec")
+	payload := []byte(`{"event":"refund.failed","payload":{"refund":{"entity":{"id":"rfnd_1","payment_id":"pay_1","status":"failed"}}}}`)
+
+	mac := hmac.New(sha256.New, []byte("whsec"))
+	mac.Write(payload)
+	header := http.Header{}
+	header.Set("X-Razorpay-Signature", hex.EncodeToString(mac.Sum(nil)))
+	header.Set("X-Razorpay-Event-Id", "evt_1")
+
+	events, err := client.ParseWebhook(payload, header)
+	require.NoError(t, err)
+	assert.Equal(t, []*WebhookEvent{{ID: "evt_1", Type: EventTypeRefundFailed, PaymentID: "pay_1", RefundID: "rfnd_1"}}, events)
+
+	header.Set("X-Razorpay-Signature", hex.EncodeToString([]byte("forged")))
+	_, err = client.ParseWebhook(payload, header)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestStripeClient_ParseWebhook(t *testing.T) {
+	client := newStripeClient("sk_test", "whsec_test")
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.payment_failed","data":{"object":{"id":"pi_1","status":"requires_payment_method","last_payment_error":{"message":"Your card was declined."}}}}`)
+
+	sign := func(at time.Time) string {
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte("whsec_test"))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(payload)
+		return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	header := http.Header{}
+	header.Set("Stripe-Signature", sign(time.Now()))
+	events, err := client.ParseWebhook(payload, header)
+	require.NoError(t, err)
+	assert.Equal(t, []*WebhookEvent{{
+		ID:            "evt_1",
+		Type:          EventTypePaymentFailed,
+		PaymentID:     "pi_1",
+		FailureReason: "Your card was declined.",
+	}}, events)
+
+	header.Set("Stripe-Signature", sign(time.Now().Add(-time.Hour)))
+	_, err = client.ParseWebhook(payload, header)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestGatewayRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := newRazorpayClient(connection.GatewaySettings{KeyID: "rzp_test_1"}, "secret", "")
+	client.baseURL = server.URL
+
+	_, err := client.Refund(context.Background(), "pay_1", decimal.NewFromInt(1), "inr", "")
+	var rateLimited *syncqueue.RateLimitError
+	require.ErrorAs(t, err, &rateLimited)
+	assert.Equal(t, 30*time.Second, rateLimited.RetryAfter)
+}
@@ -0,0 +1,400 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+const razorpayURL = "https://api.razorpay.com/v1"
+
+type razorpayClient struct {
+	baseURL       string
+	keyID         string
+	keySecret     string
+	webhookSecret string
+	client        *http.Client
+}
+
+func newRazorpayClient(settings connection.GatewaySettings, keySecret, webhookSecret string) *razorpayClient {
+	return &razorpayClient{
+		baseURL:       razorpayURL,
+		keyID:         settings.KeyID,
+		keySecret:     keySecret,
+		webhookSecret: webhookSecret,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type razorpayToken struct {
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	Card   *struct {
+		Network     string `json:"network"`
+		Last4       string `json:"last4"`
+		ExpiryMonth int    `json:"expiry_month"`
+		ExpiryYear  int    `json:"expiry_year"`
+	} `json:"card"`
+}
+
+type razorpayPayment struct {
+	ID string `json:"id"`
+	// Status is created, authorized, captured, refunded or failed
+	Status           string `json:"status"`
+	ErrorReason      string `json:"error_reason"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type razorpayRefund struct {
+	ID        string `json:"id"`
+	PaymentID string `json:"payment_id"`
+	// Status is pending, processed or failed
+	Status string `json:"status"`
+	// Receipt is the idempotency key the refund was made with
+	Receipt string `json:"receipt"`
+}
+
+func (c *razorpayClient) AttachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) (*PaymentMethod, error) {
+	var result struct {
+		Items []razorpayToken `json:"items"`
+	}
+	if err := c.do(ctx, http.MethodGet, "customers/"+url.PathEscape(customerID)+"/tokens", nil, &result); err != nil {
+		return nil, err
+	}
+
+	// Razorpay tokens belong to the customer they were created for, they only
+	// have to exist
+	for _, token := range result.Items {
+		if token.ID != paymentMethodID {
+			continue
+		}
+		method := &PaymentMethod{ID: token.ID, Type: token.Method}
+		if token.Card != nil {
+			method.Brand = strings.ToLower(token.Card.Network)
+			method.Last4 = token.Card.Last4
+			method.ExpMonth = token.Card.ExpiryMonth
+			method.ExpYear = token.Card.ExpiryYear
+		}
+		return method, nil
+	}
+	return nil, fmt.Errorf("razorpay has no token %s for customer %s", paymentMethodID, customerID)
+}
+
+func (c *razorpayClient) DetachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	return c.do(ctx, http.MethodDelete, "customers/"+url.PathEscape(customerID)+"/tokens/"+url.PathEscape(paymentMethodID), nil, nil)
+}
+
+// SetDefaultPaymentMethod is a no-op, Razorpay has no default token
+func (c *razorpayClient) SetDefaultPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	return nil
+}
+
+// Authorize creates an order that is not captured automatically and a recurring
+// payment of the order charged to the token
+func (c *razorpayClient) Authorize(ctx context.Context, req *AuthorizeRequest) (*Payment, error) {
+	payment, err := c.createRecurringPayment(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	return razorpayPaymentOf(payment), nil
+}
+
+// Debit creates an order captured automatically and a recurring payment of the
+// order charged to the emandate token. The payment is captured once the debit
+// is processed by the bank
+func (c *razorpayClient) Debit(ctx context.Context, req *AuthorizeRequest) (*Payment, error) {
+	payment, err := c.createRecurringPayment(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := razorpayPaymentOf(payment)
+	if result.Status == PaymentStatusAuthorized {
+		result.Status = PaymentStatusPending
+	}
+	return result, nil
+}
+
+// createRecurringPayment creates an order and a recurring payment of the order.
+// Razorpay has no idempotency keys, the idempotency key is the receipt of the
+// order instead and the payment of an order created by an earlier attempt is
+// returned rather than charged again
+func (c *razorpayClient) createRecurringPayment(ctx context.Context, req *AuthorizeRequest, capture bool) (*razorpayPayment, error) {
+	amount := minorUnits(req.Amount, req.Currency)
+	currency := strings.ToUpper(req.Currency)
+
+	receipt := req.Reference
+	if req.IdempotencyKey != "" {
+		receipt = req.IdempotencyKey
+	}
+
+	var orders struct {
+		Items []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+	}
+	if req.IdempotencyKey != "" {
+		query := url.Values{"receipt": {receipt}}
+		if err := c.do(ctx, http.MethodGet, "orders?"+query.Encode(), nil, &orders); err != nil {
+			return nil, err
+		}
+	}
+
+	var orderID string
+	if len(orders.Items) > 0 {
+		orderID = orders.Items[0].ID
+
+		var payments struct {
+			Items []razorpayPayment `json:"items"`
+		}
+		if err := c.do(ctx, http.MethodGet, "orders/"+url.PathEscape(orderID)+"/payments", nil, &payments); err != nil {
+			return nil, err
+		}
+		if len(payments.Items) > 0 {
+			return &payments.Items[0], nil
+		}
+	} else {
+		paymentCapture := 0
+		if capture {
+			paymentCapture = 1
+		}
+
+		var order struct {
+			ID string `json:"id"`
+		}
+		err := c.do(ctx, http.MethodPost, "orders", map[string]interface{}{
+			"amount":          amount,
+			"currency":        currency,
+			"receipt":         receipt,
+			"payment_capture": paymentCapture,
+		}, &order)
+		if err != nil {
+			return nil, err
+		}
+		orderID = order.ID
+	}
+
+	var created struct {
+		PaymentID string `json:"razorpay_payment_id"`
+	}
+	err := c.do(ctx, http.MethodPost, "payments/create/recurring", map[string]interface{}{
+		"amount":      amount,
+		"currency":    currency,
+		"order_id":    orderID,
+		"customer_id": req.CustomerID,
+		"token":       req.PaymentMethodID,
+		"email":       req.Email,
+		"recurring":   "1",
+		"description": req.Reference,
+	}, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	var payment razorpayPayment
+	if err := c.do(ctx, http.MethodGet, "payments/"+url.PathEscape(created.PaymentID), nil, &payment); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Capture returns the payment as is when an earlier attempt captured it already,
+// Razorpay has no idempotency keys and refuses to capture a payment twice
+func (c *razorpayClient) Capture(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*Payment, error) {
+	var payment razorpayPayment
+	if err := c.do(ctx, http.MethodGet, "payments/"+url.PathEscape(paymentID), nil, &payment); err != nil {
+		return nil, err
+	}
+	if payment.Status != "authorized" {
+		return razorpayPaymentOf(&payment), nil
+	}
+
+	err := c.do(ctx, http.MethodPost, "payments/"+url.PathEscape(paymentID)+"/capture", map[string]interface{}{
+		"amount":   minorUnits(amount, currency),
+		"currency": strings.ToUpper(currency),
+	}, &payment)
+	if err != nil {
+		return nil, err
+	}
+	return razorpayPaymentOf(&payment), nil
+}
+
+// Refund sends the idempotency key as the receipt of the refund and returns the
+// refund of the payment with the receipt when an earlier attempt made it
+func (c *razorpayClient) Refund(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*Refund, error) {
+	body := map[string]interface{}{
+		"amount": minorUnits(amount, currency),
+	}
+	if idempotencyKey != "" {
+		var refunds struct {
+			Items []razorpayRefund `json:"items"`
+		}
+		if err := c.do(ctx, http.MethodGet, "payments/"+url.PathEscape(paymentID)+"/refunds", nil, &refunds); err != nil {
+			return nil, err
+		}
+		for i := range refunds.Items {
+			if refunds.Items[i].Receipt == idempotencyKey {
+				return razorpayRefundOf(&refunds.Items[i]), nil
+			}
+		}
+		body["receipt"] = idempotencyKey
+	}
+
+	var refund razorpayRefund
+	if err := c.do(ctx, http.MethodPost, "payments/"+url.PathEscape(paymentID)+"/refund", body, &refund); err != nil {
+		return nil, err
+	}
+	return razorpayRefundOf(&refund), nil
+}
+
+type razorpayWebhook struct {
+	Event   string `json:"event"`
+	Payload struct {
+		Payment *struct {
+			Entity razorpayPayment `json:"entity"`
+		} `json:"payment"`
+		Refund *struct {
+			Entity razorpayRefund `json:"entity"`
+		} `json:"refund"`
+	} `json:"payload"`
+}
+
+// ParseWebhook verifies the X-Razorpay-Signature header, the hex HMAC-SHA256
+// of the payload with the webhook secret
+func (c *razorpayClient) ParseWebhook(payload []byte, header http.Header) ([]*WebhookEvent, error) {
+	if c.webhookSecret == "" {
+		return nil, fmt.Errorf("%w: the webhook secret of the connection is not set", ErrInvalidSignature)
+	}
+	signature, err := hex.DecodeString(header.Get("X-Razorpay-Signature"))
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(c.webhookSecret))
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var webhook razorpayWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to decode razorpay webhook: %w", err)
+	}
+
+	event := &WebhookEvent{ID: header.Get("X-Razorpay-Event-Id")}
+	switch webhook.Event {
+	case "payment.authorized", "payment.captured", "payment.failed":
+		if webhook.Payload.Payment == nil {
+			return nil, fmt.Errorf("razorpay %s webhook has no payment", webhook.Event)
+		}
+		payment := razorpayPaymentOf(&webhook.Payload.Payment.Entity)
+		event.PaymentID = payment.ID
+		event.FailureCode = payment.FailureCode
+		event.FailureReason = payment.FailureReason
+		switch webhook.Event {
+		case "payment.authorized":
+			event.Type = EventTypePaymentAuthorized
+		case "payment.captured":
+			event.Type = EventTypePaymentCaptured
+		default:
+			event.Type = EventTypePaymentFailed
+		}
+	case "refund.processed", "refund.failed":
+		if webhook.Payload.Refund == nil {
+			return nil, fmt.Errorf("razorpay %s webhook has no refund", webhook.Event)
+		}
+		event.RefundID = webhook.Payload.Refund.Entity.ID
+		event.PaymentID = webhook.Payload.Refund.Entity.PaymentID
+		event.Type = EventTypeRefundSucceeded
+		if webhook.Event == "refund.failed" {
+			event.Type = EventTypeRefundFailed
+		}
+	default:
+		return nil, nil
+	}
+
+	if event.ID == "" {
+		event.ID = webhook.Event + ":" + event.PaymentID + ":" + event.RefundID
+	}
+	return []*WebhookEvent{event}, nil
+}
+
+func razorpayPaymentOf(payment *razorpayPayment) *Payment {
+	result := &Payment{ID: payment.ID}
+	switch payment.Status {
+	case "authorized":
+		result.Status = PaymentStatusAuthorized
+	case "captured", "refunded":
+		result.Status = PaymentStatusCaptured
+	case "created":
+		result.Status = PaymentStatusPending
+	default:
+		result.Status = PaymentStatusFailed
+		result.FailureCode = payment.ErrorReason
+		result.FailureReason = payment.ErrorDescription
+	}
+	return result
+}
+
+func razorpayRefundOf(refund *razorpayRefund) *Refund {
+	result := &Refund{ID: refund.ID, Status: types.RefundStatusPending}
+	switch refund.Status {
+	case "processed":
+		result.Status = types.RefundStatusSucceeded
+	case "failed":
+		result.Status = types.RefundStatusFailed
+	}
+	return result
+}
+
+func (c *razorpayClient) do(ctx context.Context, method, resource string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode razorpay request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/"+resource, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.keyID, c.keySecret)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call razorpay: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return rateLimitError(resp)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("razorpay responded with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode razorpay response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/stripe"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+type stripeClient struct {
+	client        *stripe.Client
+	webhookSecret string
+}
+
+func newStripeClient(secretKey, webhookSecret string) *stripeClient {
+	return &stripeClient{client: stripe.NewClient(secretKey), webhookSecret: webhookSecret}
+}
+
+func (c *stripeClient) AttachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) (*PaymentMethod, error) {
+	pm, err := c.client.AttachPaymentMethod(ctx, paymentMethodID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	method := &PaymentMethod{ID: pm.ID, Type: pm.Type}
+	if pm.Card != nil {
+		method.Brand = pm.Card.Brand
+		method.Last4 = pm.Card.Last4
+		method.ExpMonth = pm.Card.ExpMonth
+		method.ExpYear = pm.Card.ExpYear
+	}
+	return method, nil
+}
+
+func (c *stripeClient) DetachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	return c.client.DetachPaymentMethod(ctx, paymentMethodID)
+}
+
+func (c *stripeClient) SetDefaultPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	return c.client.SetDefaultPaymentMethod(ctx, customerID, paymentMethodID)
+}
+
+func (c *stripeClient) Authorize(ctx context.Context, req *AuthorizeRequest) (*Payment, error) {
+	intent, err := c.client.CreatePaymentIntent(ctx, req.CustomerID, req.PaymentMethodID, req.Amount, req.Currency, req.Reference, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	return stripePayment(intent), nil
+}
+
+func (c *stripeClient) Capture(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*Payment, error) {
+	intent, err := c.client.CapturePaymentIntent(ctx, paymentID, amount, currency, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	return stripePayment(intent), nil
+}
+
+func (c *stripeClient) Debit(ctx context.Context, req *AuthorizeRequest) (*Payment, error) {
+	intent, err := c.client.CreateDebitPaymentIntent(ctx, req.CustomerID, req.PaymentMethodID, req.Amount, req.Currency, req.Reference, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	return stripePayment(intent), nil
+}
+
+func (c *stripeClient) Refund(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*Refund, error) {
+	refund, err := c.client.CreateRefund(ctx, paymentID, amount, currency, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	return stripeRefund(refund), nil
+}
+
+func (c *stripeClient) ParseWebhook(payload []byte, header http.Header) ([]*WebhookEvent, error) {
+	event, err := stripe.VerifyWebhook(payload, header.Get("Stripe-Signature"), c.webhookSecret, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSignature, err.Error())
+	}
+
+	switch event.Type {
+	case "payment_intent.amount_capturable_updated", "payment_intent.succeeded", "payment_intent.payment_failed":
+		var intent stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Object, &intent); err != nil {
+			return nil, fmt.Errorf("failed to decode stripe payment intent: %w", err)
+		}
+
+		payment := stripePayment(&intent)
+		webhookEvent := &WebhookEvent{ID: event.ID, PaymentID: payment.ID, FailureReason: payment.FailureReason}
+		switch payment.Status {
+		case PaymentStatusAuthorized:
+			webhookEvent.Type = EventTypePaymentAuthorized
+		case PaymentStatusCaptured:
+			webhookEvent.Type = EventTypePaymentCaptured
+		case PaymentStatusFailed:
+			webhookEvent.Type = EventTypePaymentFailed
+		default:
+			return nil, nil
+		}
+		return []*WebhookEvent{webhookEvent}, nil
+
+	case "refund.updated", "refund.failed", "charge.refund.updated":
+		var stripeRefundObject stripe.Refund
+		if err := json.Unmarshal(event.Data.Object, &stripeRefundObject); err != nil {
+			return nil, fmt.Errorf("failed to decode stripe refund: %w", err)
+		}
+
+		refund := stripeRefund(&stripeRefundObject)
+		webhookEvent := &WebhookEvent{
+			ID:            event.ID,
+			PaymentID:     stripeRefundObject.PaymentIntent,
+			RefundID:      refund.ID,
+			FailureReason: refund.FailureReason,
+		}
+		switch refund.Status {
+		case types.RefundStatusSucceeded:
+			webhookEvent.Type = EventTypeRefundSucceeded
+		case types.RefundStatusFailed:
+			webhookEvent.Type = EventTypeRefundFailed
+		default:
+			return nil, nil
+		}
+		return []*WebhookEvent{webhookEvent}, nil
+	}
+
+	return nil, nil
+}
+
+func stripePayment(intent *stripe.PaymentIntent) *Payment {
+	payment := &Payment{ID: intent.ID}
+	switch intent.Status {
+	case "requires_capture":
+		payment.Status = PaymentStatusAuthorized
+	case "succeeded":
+		payment.Status = PaymentStatusCaptured
+	case "processing":
+		payment.Status = PaymentStatusPending
+	default:
+		// Off session payments requiring an action of the customer ex 3D Secure
+		// can not be completed
+		payment.Status = PaymentStatusFailed
+		payment.FailureReason = intent.Status
+		if intent.LastPaymentError != nil {
+			payment.FailureCode = intent.LastPaymentError.DeclineCode
+			if payment.FailureCode == "" {
+				payment.FailureCode = intent.LastPaymentError.Code
+			}
+			payment.FailureReason = intent.LastPaymentError.Message
+		}
+	}
+	return payment
+}
+
+func stripeRefund(refund *stripe.Refund) *Refund {
+	result := &Refund{ID: refund.ID, Status: types.RefundStatusPending}
+	switch refund.Status {
+	case "succeeded":
+		result.Status = types.RefundStatusSucceeded
+	case "failed", "canceled":
+		result.Status = types.RefundStatusFailed
+		result.FailureReason = refund.FailureReason
+	}
+	return result
+}
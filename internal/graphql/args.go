@@ -0,0 +1,62 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Args are the arguments of a field with their variables resolved. Literals
+// and JSON variables use different Go types for the same GraphQL value, the
+// getters coerce them
+type Args map[string]interface{}
+
+// String returns the string argument or an empty string when it is not set
+func (a Args) String(name string) (string, error) {
+	value, ok := a[name]
+	if !ok || value == nil {
+		return "", nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("argument %q must be a string", name)
+	}
+	return s, nil
+}
+
+// Int returns the integer argument or the fallback when it is not set
+func (a Args) Int(name string, fallback int) (int, error) {
+	value, ok := a[name]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+
+	switch v := value.(type) {
+	case int64:
+		return int(v), nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i), nil
+		}
+	}
+	return 0, fmt.Errorf("argument %q must be an integer", name)
+}
+
+// Time returns the RFC 3339 time argument or nil when it is not set
+func (a Args) Time(name string) (*time.Time, error) {
+	s, err := a.String(name)
+	if err != nil || s == "" {
+		return nil, err
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("argument %q must be an RFC 3339 time", name)
+	}
+	return &t, nil
+}
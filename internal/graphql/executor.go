@@ -0,0 +1,386 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MaxDepth bounds how deeply selection sets can be nested in a query to keep a
+// single request from fanning out into an unbounded number of lookups
+const MaxDepth = 8
+
+// Schema is the set of types reachable from the root query type
+type Schema struct {
+	Query *Object
+}
+
+// Object is an object type. Fields without a resolver read the JSON field of
+// the same name on the source value, so domain models and DTOs can be exposed
+// without wrapping them
+type Object struct {
+	Name   string
+	Fields map[string]*FieldDefinition
+}
+
+type FieldDefinition struct {
+	// Type is the object type of the field, nil for scalar fields
+	Type *Object
+
+	// List is set when the field resolves to a list of Type
+	List bool
+
+	// Arguments are the names of the arguments the field accepts
+	Arguments []string
+
+	Resolve ResolveFunc
+}
+
+type ResolveFunc func(ctx context.Context, params ResolveParams) (interface{}, error)
+
+type ResolveParams struct {
+	// Source is the value of the parent object
+	Source interface{}
+
+	Args Args
+}
+
+// Request is a GraphQL request as posted by clients
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []*Error               `json:"errors,omitempty"`
+}
+
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Execute runs the query of the request against the schema. Errors raised while
+// resolving a field null the field and are reported next to the partial data,
+// request errors such as a syntax error return no data at all
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	operation, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	if operation.Type != "query" {
+		return &Response{Errors: []*Error{{Message: fmt.Sprintf("%s operations are not supported, the API is read only", operation.Type)}}}
+	}
+
+	e := &executor{doc: doc, variables: map[string]interface{}{}}
+	for _, definition := range operation.Variables {
+		if value, ok := req.Variables[definition.Name]; ok {
+			e.variables[definition.Name] = value
+		} else if definition.DefaultValue != nil {
+			e.variables[definition.Name] = definition.DefaultValue
+		}
+	}
+
+	if err := e.validate(s.Query, operation.SelectionSet, 1, map[string]bool{}); err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	data := e.executeObject(ctx, s.Query, nil, operation.SelectionSet, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document contains several operations")
+		}
+		return doc.Operations[0], nil
+	}
+
+	for _, operation := range doc.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+	return nil, fmt.Errorf("operation %q not found", name)
+}
+
+type executor struct {
+	doc       *Document
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+// validate checks every selected field exists on its type before anything is
+// resolved, so a typo does not run half of the query
+func (e *executor) validate(object *Object, selections []Selection, depth int, visiting map[string]bool) error {
+	if depth > MaxDepth {
+		return fmt.Errorf("query exceeds the maximum depth of %d", MaxDepth)
+	}
+
+	for _, selection := range selections {
+		switch sel := selection.(type) {
+		case *Field:
+			if sel.Name == "__typename" {
+				continue
+			}
+
+			definition, ok := object.Fields[sel.Name]
+			if !ok {
+				return fmt.Errorf("cannot query field %q on type %q", sel.Name, object.Name)
+			}
+
+			for arg := range sel.Arguments {
+				if !contains(definition.Arguments, arg) {
+					return fmt.Errorf("unknown argument %q on field %q of type %q", arg, sel.Name, object.Name)
+				}
+			}
+
+			if definition.Type == nil {
+				if len(sel.SelectionSet) > 0 {
+					return fmt.Errorf("field %q of type %q must not have a selection", sel.Name, object.Name)
+				}
+				continue
+			}
+
+			if len(sel.SelectionSet) == 0 {
+				return fmt.Errorf("field %q of type %q must have a selection of subfields", sel.Name, object.Name)
+			}
+
+			if err := e.validate(definition.Type, sel.SelectionSet, depth+1, visiting); err != nil {
+				return err
+			}
+
+		case *FragmentSpread:
+			fragment, ok := e.doc.Fragments[sel.Name]
+			if !ok {
+				return fmt.Errorf("unknown fragment %q", sel.Name)
+			}
+			if visiting[sel.Name] {
+				return fmt.Errorf("fragment %q spreads itself", sel.Name)
+			}
+			if fragment.TypeCondition != object.Name {
+				continue
+			}
+
+			visiting[sel.Name] = true
+			err := e.validate(object, fragment.SelectionSet, depth, visiting)
+			delete(visiting, sel.Name)
+			if err != nil {
+				return err
+			}
+
+		case *InlineFragment:
+			if sel.TypeCondition != "" && sel.TypeCondition != object.Name {
+				continue
+			}
+			if err := e.validate(object, sel.SelectionSet, depth, visiting); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *executor) executeObject(ctx context.Context, object *Object, source interface{}, selections []Selection, path []interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+
+	// Default resolvers read the JSON representation of the source
+	var fields map[string]interface{}
+	if source != nil {
+		var err error
+		if fields, err = toFields(source); err != nil {
+			e.addError(err, path)
+			return nil
+		}
+	}
+
+	for _, field := range e.collectFields(object, selections) {
+		key := field.ResponseKey()
+		fieldPath := append(append([]interface{}{}, path...), key)
+
+		if field.Name == "__typename" {
+			result[key] = object.Name
+			continue
+		}
+
+		definition := object.Fields[field.Name]
+
+		var value interface{}
+		if definition.Resolve != nil {
+			args, err := e.arguments(field)
+			if err != nil {
+				e.addError(err, fieldPath)
+				result[key] = nil
+				continue
+			}
+
+			if value, err = definition.Resolve(ctx, ResolveParams{Source: source, Args: args}); err != nil {
+				e.addError(err, fieldPath)
+				result[key] = nil
+				continue
+			}
+		} else {
+			value = fields[field.Name]
+		}
+
+		result[key] = e.completeValue(ctx, definition, value, field.SelectionSet, fieldPath)
+	}
+
+	return result
+}
+
+func (e *executor) completeValue(ctx context.Context, definition *FieldDefinition, value interface{}, selections []Selection, path []interface{}) interface{} {
+	if isNil(value) {
+		return nil
+	}
+
+	if definition.Type == nil {
+		return value
+	}
+
+	if !definition.List {
+		return e.executeObject(ctx, definition.Type, value, selections, path)
+	}
+
+	items := reflect.ValueOf(value)
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		e.addError(fmt.Errorf("expected a list"), path)
+		return nil
+	}
+
+	list := make([]interface{}, 0, items.Len())
+	for i := 0; i < items.Len(); i++ {
+		itemPath := append(append([]interface{}{}, path...), i)
+		list = append(list, e.completeValue(ctx, &FieldDefinition{Type: definition.Type}, items.Index(i).Interface(), selections, itemPath))
+	}
+	return list
+}
+
+// collectFields flattens the fragments of the selection set that apply to the object
+func (e *executor) collectFields(object *Object, selections []Selection) []*Field {
+	var fields []*Field
+	for _, selection := range selections {
+		switch sel := selection.(type) {
+		case *Field:
+			fields = append(fields, sel)
+		case *FragmentSpread:
+			if fragment := e.doc.Fragments[sel.Name]; fragment.TypeCondition == object.Name {
+				fields = append(fields, e.collectFields(object, fragment.SelectionSet)...)
+			}
+		case *InlineFragment:
+			if sel.TypeCondition == "" || sel.TypeCondition == object.Name {
+				fields = append(fields, e.collectFields(object, sel.SelectionSet)...)
+			}
+		}
+	}
+	return fields
+}
+
+func (e *executor) arguments(field *Field) (Args, error) {
+	args := Args{}
+	for name, value := range field.Arguments {
+		resolved, err := e.resolveValue(value)
+		if err != nil {
+			return nil, err
+		}
+		args[name] = resolved
+	}
+	return args, nil
+}
+
+func (e *executor) resolveValue(value Value) (interface{}, error) {
+	switch v := value.(type) {
+	case Variable:
+		resolved, ok := e.variables[v.Name]
+		if !ok {
+			return nil, fmt.Errorf("variable %q is not defined", v.Name)
+		}
+		return e.resolveValue(resolved)
+	case EnumValue:
+		return string(v), nil
+	case []Value:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			resolved, err := e.resolveValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, resolved)
+		}
+		return list, nil
+	case map[string]Value:
+		object := map[string]interface{}{}
+		for name, item := range v {
+			resolved, err := e.resolveValue(item)
+			if err != nil {
+				return nil, err
+			}
+			object[name] = resolved
+		}
+		return object, nil
+	default:
+		return v, nil
+	}
+}
+
+func (e *executor) addError(err error, path []interface{}) {
+	e.errors = append(e.errors, &Error{Message: err.Error(), Path: path})
+}
+
+// toFields returns the JSON fields of the value
+func toFields(value interface{}) (map[string]interface{}, error) {
+	if fields, ok := value.(map[string]interface{}); ok {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fields: %w", err)
+	}
+
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to read fields: %w", err)
+	}
+	return fields, nil
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,607 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Document is a parsed query document
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	Type         string
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+}
+
+type VariableDefinition struct {
+	Name         string
+	DefaultValue Value
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+}
+
+// Selection is a field, a fragment spread or an inline fragment
+type Selection interface {
+	selection()
+}
+
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    map[string]Value
+	SelectionSet []Selection
+}
+
+type FragmentSpread struct {
+	Name string
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	SelectionSet  []Selection
+}
+
+func (*Field) selection()          {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+// ResponseKey is the key of the field in the response, its alias if any
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Value is an argument value literal. Variables are resolved when the
+// arguments are evaluated
+type Value interface{}
+
+type Variable struct {
+	Name string
+}
+
+type EnumValue string
+
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+type parser struct {
+	src    string
+	pos    int
+	tok    token
+	tokErr error
+}
+
+// Parse parses a query document. Directives and block strings are not supported
+func Parse(query string) (*Document, error) {
+	p := &parser{src: query}
+	p.next()
+
+	doc := &Document{Fragments: map[string]*Fragment{}}
+	for p.tok.kind != tokenEOF {
+		if p.tokErr != nil {
+			return nil, p.tokErr
+		}
+
+		switch {
+		case p.peek(tokenPunctuator, "{"):
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", SelectionSet: selections})
+
+		case p.peek(tokenName, "fragment"):
+			fragment, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				return nil, fmt.Errorf("fragment %q is defined more than once", fragment.Name)
+			}
+			doc.Fragments[fragment.Name] = fragment
+
+		case p.tok.kind == tokenName:
+			operation, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, operation)
+
+		default:
+			return nil, p.unexpected()
+		}
+	}
+
+	if p.tokErr != nil {
+		return nil, p.tokErr
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("document does not contain any operation")
+	}
+
+	return doc, nil
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	operation := &Operation{Type: p.tok.value}
+	switch operation.Type {
+	case "query", "mutation", "subscription":
+	default:
+		return nil, p.unexpected()
+	}
+	p.next()
+
+	if p.tok.kind == tokenName {
+		operation.Name = p.tok.value
+		p.next()
+	}
+
+	if p.peek(tokenPunctuator, "(") {
+		p.next()
+		for !p.peek(tokenPunctuator, ")") {
+			definition, err := p.parseVariableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			operation.Variables = append(operation.Variables, definition)
+		}
+		p.next()
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	operation.SelectionSet = selections
+
+	return operation, nil
+}
+
+func (p *parser) parseVariableDefinition() (*VariableDefinition, error) {
+	if err := p.expect(tokenPunctuator, "$"); err != nil {
+		return nil, err
+	}
+
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(tokenPunctuator, ":"); err != nil {
+		return nil, err
+	}
+
+	// Types are not checked, arguments are coerced when read
+	if err := p.skipType(); err != nil {
+		return nil, err
+	}
+
+	definition := &VariableDefinition{Name: name}
+	if p.peek(tokenPunctuator, "=") {
+		p.next()
+		if definition.DefaultValue, err = p.parseValue(true); err != nil {
+			return nil, err
+		}
+	}
+
+	return definition, nil
+}
+
+func (p *parser) skipType() error {
+	if p.peek(tokenPunctuator, "[") {
+		p.next()
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expect(tokenPunctuator, "]"); err != nil {
+			return err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return err
+	}
+
+	if p.peek(tokenPunctuator, "!") {
+		p.next()
+	}
+	return nil
+}
+
+func (p *parser) parseFragment() (*Fragment, error) {
+	p.next()
+
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(tokenName, "on"); err != nil {
+		return nil, err
+	}
+
+	typeCondition, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fragment{Name: name, TypeCondition: typeCondition, SelectionSet: selections}, nil
+}
+
+func (p *parser) parseSelectionSet() ([]Selection, error) {
+	if err := p.expect(tokenPunctuator, "{"); err != nil {
+		return nil, err
+	}
+
+	var selections []Selection
+	for !p.peek(tokenPunctuator, "}") {
+		if p.tok.kind == tokenEOF {
+			return nil, p.unexpected()
+		}
+
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	p.next()
+
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("selection set can not be empty")
+	}
+
+	return selections, nil
+}
+
+func (p *parser) parseSelection() (Selection, error) {
+	if p.peek(tokenPunctuator, "...") {
+		p.next()
+
+		if p.peek(tokenName, "on") {
+			p.next()
+			typeCondition, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			return &InlineFragment{TypeCondition: typeCondition, SelectionSet: selections}, nil
+		}
+
+		if p.peek(tokenPunctuator, "{") {
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			return &InlineFragment{SelectionSet: selections}, nil
+		}
+
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		return &FragmentSpread{Name: name}, nil
+	}
+
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	field := &Field{Name: name}
+	if p.peek(tokenPunctuator, ":") {
+		p.next()
+		field.Alias = name
+		if field.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek(tokenPunctuator, "(") {
+		p.next()
+		field.Arguments = map[string]Value{}
+		for !p.peek(tokenPunctuator, ")") {
+			argName, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(tokenPunctuator, ":"); err != nil {
+				return nil, err
+			}
+
+			value, err := p.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+			field.Arguments[argName] = value
+		}
+		p.next()
+	}
+
+	if p.peek(tokenPunctuator, "{") {
+		if field.SelectionSet, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+
+	return field, nil
+}
+
+func (p *parser) parseValue(constant bool) (Value, error) {
+	tok := p.tok
+
+	switch tok.kind {
+	case tokenPunctuator:
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+			p.next()
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			return Variable{Name: name}, nil
+
+		case "[":
+			p.next()
+			list := []Value{}
+			for !p.peek(tokenPunctuator, "]") {
+				if p.tok.kind == tokenEOF {
+					return nil, p.unexpected()
+				}
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			p.next()
+			return list, nil
+
+		case "{":
+			p.next()
+			object := map[string]Value{}
+			for !p.peek(tokenPunctuator, "}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(tokenPunctuator, ":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				object[name] = value
+			}
+			p.next()
+			return object, nil
+		}
+
+	case tokenInt:
+		p.next()
+		return strconv.ParseInt(tok.value, 10, 64)
+
+	case tokenFloat:
+		p.next()
+		return strconv.ParseFloat(tok.value, 64)
+
+	case tokenString:
+		p.next()
+		return tok.value, nil
+
+	case tokenName:
+		p.next()
+		switch tok.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			return EnumValue(tok.value), nil
+		}
+	}
+
+	return nil, p.unexpected()
+}
+
+func (p *parser) peek(kind int, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+func (p *parser) expect(kind int, value string) error {
+	if !p.peek(kind, value) {
+		return p.unexpected()
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	p.next()
+	return name, nil
+}
+
+func (p *parser) unexpected() error {
+	if p.tokErr != nil {
+		return p.tokErr
+	}
+	if p.tok.kind == tokenEOF {
+		return fmt.Errorf("syntax error: unexpected end of document")
+	}
+	return fmt.Errorf("syntax error: unexpected %q at position %d", p.tok.value, p.tok.pos)
+}
+
+// next reads the following token, skipping whitespace, commas and comments
+func (p *parser) next() {
+	if p.tokErr != nil {
+		p.tok = token{kind: tokenEOF, pos: p.pos}
+		return
+	}
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+			continue
+		}
+		if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		break
+	}
+
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokenEOF, pos: start}
+		return
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokenPunctuator, value: "...", pos: start}
+
+	case strings.IndexByte("!$():=[]{}|", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokenPunctuator, value: string(c), pos: start}
+
+	case c == '@':
+		p.fail(start, fmt.Errorf("syntax error: directives are not supported at position %d", start))
+
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenName, value: p.src[start:p.pos], pos: start}
+
+	case c == '-' || isDigit(c):
+		p.readNumber(start)
+
+	case c == '"':
+		p.readString(start)
+
+	default:
+		p.fail(start, fmt.Errorf("syntax error: unexpected character %q at position %d", c, start))
+	}
+}
+
+func (p *parser) readNumber(start int) {
+	kind := tokenInt
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() {
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			p.pos++
+		}
+	}
+	digits()
+
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		kind = tokenFloat
+		p.pos++
+		digits()
+	}
+
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		kind = tokenFloat
+		p.pos++
+		if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+			p.pos++
+		}
+		digits()
+	}
+
+	p.tok = token{kind: kind, value: p.src[start:p.pos], pos: start}
+}
+
+func (p *parser) readString(start int) {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		p.fail(start, fmt.Errorf("syntax error: block strings are not supported at position %d", start))
+		return
+	}
+
+	p.pos++
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '\n':
+			p.fail(start, fmt.Errorf("syntax error: unterminated string at position %d", start))
+			return
+		case '"':
+			p.pos++
+			value, err := strconv.Unquote(p.src[start:p.pos])
+			if err != nil {
+				p.fail(start, fmt.Errorf("syntax error: invalid string at position %d", start))
+				return
+			}
+			p.tok = token{kind: tokenString, value: value, pos: start}
+			return
+		default:
+			p.pos++
+		}
+	}
+
+	p.fail(start, fmt.Errorf("syntax error: unterminated string at position %d", start))
+}
+
+func (p *parser) fail(pos int, err error) {
+	p.tokErr = err
+	p.tok = token{kind: tokenEOF, pos: pos}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,407 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// maxListLimit caps the page size of list fields, nested lists multiply the
+// number of lookups of a query
+const maxListLimit = 100
+
+// NewSchema builds the read only billing schema on top of the services backing
+// the REST API, so both return the same data for the tenant of the request
+func NewSchema(
+	customerService service.CustomerService,
+	subscriptionService service.SubscriptionService,
+	invoiceService service.InvoiceService,
+) *Schema {
+	r := &resolvers{
+		customerService:     customerService,
+		subscriptionService: subscriptionService,
+		invoiceService:      invoiceService,
+	}
+
+	base := []string{"tenant_id", "status", "created_at", "updated_at", "created_by", "updated_by"}
+
+	price := newObject("Price", append(base,
+		"id", "amount", "display_amount", "currency", "plan_id", "type", "billing_period",
+		"billing_period_count", "billing_model", "billing_cadence", "tier_mode", "tiers",
+		"meter_id", "lookup_key", "description", "filter_values", "transform_quantity", "metadata",
+	)...)
+
+	plan := newObject("Plan", append(base,
+		"id", "name", "lookup_key", "description", "invoice_cadence", "trial_period",
+	)...)
+	plan.Fields["prices"] = &FieldDefinition{Type: price, List: true}
+
+	usageCharge := newObject("UsageCharge",
+		"amount", "currency", "display_amount", "quantity", "filter_values", "meter_display_name",
+	)
+	usageCharge.Fields["price"] = &FieldDefinition{Type: price}
+
+	usage := newObject("UsageSummary", "amount", "currency", "display_amount", "start_time", "end_time")
+	usage.Fields["charges"] = &FieldDefinition{Type: usageCharge, List: true}
+
+	lineItem := newObject("InvoiceLineItem", append(base,
+		"id", "invoice_id", "customer_id", "subscription_id", "price_id", "meter_id",
+		"display_name", "amount", "quantity", "currency", "period_start", "period_end", "metadata",
+	)...)
+
+	customer := newObject("Customer", append(base,
+		"id", "external_id", "name", "email", "payment_method_id", "consolidate_invoices",
+		"billing_address", "shipping_address", "tax_ids",
+	)...)
+
+	subscription := newObject("Subscription", append(base,
+		"id", "lookup_key", "customer_id", "plan_id", "subscription_status", "currency",
+		"billing_anchor", "start_date", "end_date", "current_period_start", "current_period_end",
+		"cancelled_at", "cancel_at", "cancel_at_period_end", "cancellation_reason",
+		"cancellation_comment", "trial_start", "trial_end", "billing_cycle",
+		"partial_period_behavior", "billing_cadence", "billing_period", "billing_period_count",
+		"commitment_amount", "invoice_cadence",
+	)...)
+
+	inv := newObject("Invoice", append(base,
+		"id", "invoice_number", "customer_id", "subscription_id", "invoice_type", "invoice_status",
+		"currency", "total", "amount_due", "original_invoice_id", "description", "period_start",
+		"period_end", "partial_period_behavior", "finalized_at", "metadata", "credit_invoice_ids",
+	)...)
+
+	invoiceListArgs := []string{"invoice_status", "invoice_type", "limit", "offset"}
+	subscriptionListArgs := []string{"plan_id", "subscription_status", "limit", "offset"}
+
+	customer.Fields["subscriptions"] = &FieldDefinition{
+		Type: subscription, List: true, Arguments: subscriptionListArgs,
+		Resolve: r.customerSubscriptions,
+	}
+	customer.Fields["invoices"] = &FieldDefinition{
+		Type: inv, List: true, Arguments: invoiceListArgs,
+		Resolve: r.customerInvoices,
+	}
+
+	subscription.Fields["customer"] = &FieldDefinition{Type: customer, Resolve: r.subscriptionCustomer}
+	subscription.Fields["plan"] = &FieldDefinition{Type: plan, Resolve: r.subscriptionPlan}
+	subscription.Fields["line_items"] = &FieldDefinition{Type: price, List: true, Resolve: r.subscriptionLineItems}
+	subscription.Fields["usage"] = &FieldDefinition{
+		Type: usage, Arguments: []string{"start_time", "end_time"},
+		Resolve: r.subscriptionUsage,
+	}
+	subscription.Fields["invoices"] = &FieldDefinition{
+		Type: inv, List: true, Arguments: invoiceListArgs,
+		Resolve: r.subscriptionInvoices,
+	}
+
+	inv.Fields["line_items"] = &FieldDefinition{Type: lineItem, List: true, Resolve: r.invoiceLineItems}
+	inv.Fields["customer"] = &FieldDefinition{Type: customer, Resolve: r.invoiceCustomer}
+	inv.Fields["subscription"] = &FieldDefinition{Type: subscription, Resolve: r.invoiceSubscription}
+
+	query := &Object{Name: "Query", Fields: map[string]*FieldDefinition{
+		"customer": {Type: customer, Arguments: []string{"id"}, Resolve: r.customer},
+		"customers": {
+			Type: customer, List: true, Arguments: []string{"limit", "offset"},
+			Resolve: r.customers,
+		},
+		"subscription": {Type: subscription, Arguments: []string{"id"}, Resolve: r.subscription},
+		"subscriptions": {
+			Type: subscription, List: true, Arguments: append([]string{"customer_id"}, subscriptionListArgs...),
+			Resolve: r.subscriptions,
+		},
+		"invoice": {Type: inv, Arguments: []string{"id"}, Resolve: r.invoice},
+		"invoices": {
+			Type: inv, List: true, Arguments: append([]string{"customer_id", "subscription_id"}, invoiceListArgs...),
+			Resolve: r.invoices,
+		},
+		"usage": {
+			Type: usage, Arguments: []string{"subscription_id", "start_time", "end_time"},
+			Resolve: r.usage,
+		},
+	}}
+
+	return &Schema{Query: query}
+}
+
+func newObject(name string, scalars ...string) *Object {
+	object := &Object{Name: name, Fields: make(map[string]*FieldDefinition, len(scalars))}
+	for _, field := range scalars {
+		object.Fields[field] = &FieldDefinition{}
+	}
+	return object
+}
+
+type resolvers struct {
+	customerService     service.CustomerService
+	subscriptionService service.SubscriptionService
+	invoiceService      service.InvoiceService
+}
+
+func (r *resolvers) customer(ctx context.Context, p ResolveParams) (interface{}, error) {
+	id, err := requiredString(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	return r.customerService.GetCustomer(ctx, id)
+}
+
+func (r *resolvers) customers(ctx context.Context, p ResolveParams) (interface{}, error) {
+	filter := types.GetDefaultFilter()
+	if err := page(p.Args, &filter); err != nil {
+		return nil, err
+	}
+
+	resp, err := r.customerService.GetCustomers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	customers := make([]*dto.CustomerResponse, len(resp.Customers))
+	for i := range resp.Customers {
+		customers[i] = &resp.Customers[i]
+	}
+	return customers, nil
+}
+
+func (r *resolvers) subscription(ctx context.Context, p ResolveParams) (interface{}, error) {
+	id, err := requiredString(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	return r.subscriptionService.GetSubscription(ctx, id)
+}
+
+func (r *resolvers) subscriptions(ctx context.Context, p ResolveParams) (interface{}, error) {
+	customerID, err := p.Args.String("customer_id")
+	if err != nil {
+		return nil, err
+	}
+	return r.listSubscriptions(ctx, customerID, p.Args)
+}
+
+func (r *resolvers) customerSubscriptions(ctx context.Context, p ResolveParams) (interface{}, error) {
+	return r.listSubscriptions(ctx, p.Source.(*dto.CustomerResponse).ID, p.Args)
+}
+
+func (r *resolvers) listSubscriptions(ctx context.Context, customerID string, args Args) (interface{}, error) {
+	filter := &types.SubscriptionFilter{Filter: types.GetDefaultFilter(), CustomerID: customerID}
+	if err := page(args, &filter.Filter); err != nil {
+		return nil, err
+	}
+
+	planID, err := args.String("plan_id")
+	if err != nil {
+		return nil, err
+	}
+	filter.PlanID = planID
+
+	status, err := args.String("subscription_status")
+	if err != nil {
+		return nil, err
+	}
+	filter.SubscriptionStatus = types.SubscriptionStatus(status)
+
+	resp, err := r.subscriptionService.ListSubscriptions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Subscriptions, nil
+}
+
+func (r *resolvers) subscriptionCustomer(ctx context.Context, p ResolveParams) (interface{}, error) {
+	return r.getCustomer(ctx, p.Source.(*dto.SubscriptionResponse).CustomerID)
+}
+
+func (r *resolvers) subscriptionPlan(ctx context.Context, p ResolveParams) (interface{}, error) {
+	return r.planWithPrices(ctx, p.Source.(*dto.SubscriptionResponse))
+}
+
+// subscriptionLineItems returns the prices of the plan billed to the subscription,
+// the ones matching its currency and billing period
+func (r *resolvers) subscriptionLineItems(ctx context.Context, p ResolveParams) (interface{}, error) {
+	sub := p.Source.(*dto.SubscriptionResponse)
+	plan, err := r.planWithPrices(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	lineItems := []dto.PriceResponse{}
+	for _, price := range plan.Prices {
+		if price.Currency == sub.Currency &&
+			price.BillingPeriod == sub.BillingPeriod &&
+			price.BillingPeriodCount == sub.BillingPeriodCount {
+			lineItems = append(lineItems, price)
+		}
+	}
+	return lineItems, nil
+}
+
+// planWithPrices returns the plan of the subscription with its prices. Listed
+// subscriptions come with the plan alone, the prices are loaded on demand
+func (r *resolvers) planWithPrices(ctx context.Context, sub *dto.SubscriptionResponse) (*dto.PlanResponse, error) {
+	if sub.Plan != nil && sub.Plan.Prices != nil {
+		return sub.Plan, nil
+	}
+
+	resp, err := r.subscriptionService.GetSubscription(ctx, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+	sub.Plan = resp.Plan
+	return sub.Plan, nil
+}
+
+func (r *resolvers) subscriptionUsage(ctx context.Context, p ResolveParams) (interface{}, error) {
+	return r.getUsage(ctx, p.Source.(*dto.SubscriptionResponse).ID, p.Args)
+}
+
+func (r *resolvers) subscriptionInvoices(ctx context.Context, p ResolveParams) (interface{}, error) {
+	sub := p.Source.(*dto.SubscriptionResponse)
+	return r.listInvoices(ctx, &types.InvoiceFilter{CustomerID: sub.CustomerID, SubscriptionID: sub.ID}, p.Args)
+}
+
+func (r *resolvers) invoice(ctx context.Context, p ResolveParams) (interface{}, error) {
+	id, err := requiredString(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	return r.invoiceService.GetInvoice(ctx, id)
+}
+
+func (r *resolvers) invoices(ctx context.Context, p ResolveParams) (interface{}, error) {
+	customerID, err := p.Args.String("customer_id")
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionID, err := p.Args.String("subscription_id")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.listInvoices(ctx, &types.InvoiceFilter{CustomerID: customerID, SubscriptionID: subscriptionID}, p.Args)
+}
+
+func (r *resolvers) customerInvoices(ctx context.Context, p ResolveParams) (interface{}, error) {
+	return r.listInvoices(ctx, &types.InvoiceFilter{CustomerID: p.Source.(*dto.CustomerResponse).ID}, p.Args)
+}
+
+func (r *resolvers) listInvoices(ctx context.Context, filter *types.InvoiceFilter, args Args) (interface{}, error) {
+	filter.Filter = types.GetDefaultFilter()
+	if err := page(args, &filter.Filter); err != nil {
+		return nil, err
+	}
+
+	status, err := args.String("invoice_status")
+	if err != nil {
+		return nil, err
+	}
+	filter.InvoiceStatus = types.InvoiceStatus(status)
+
+	invoiceType, err := args.String("invoice_type")
+	if err != nil {
+		return nil, err
+	}
+	filter.InvoiceType = types.InvoiceType(invoiceType)
+
+	resp, err := r.invoiceService.ListInvoices(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]*dto.InvoiceResponse, len(resp.Invoices))
+	for i := range resp.Invoices {
+		invoices[i] = &resp.Invoices[i]
+	}
+	return invoices, nil
+}
+
+// invoiceLineItems loads the line items of invoices coming from a list, which
+// are listed without them
+func (r *resolvers) invoiceLineItems(ctx context.Context, p ResolveParams) (interface{}, error) {
+	inv := p.Source.(*dto.InvoiceResponse)
+	if inv.LineItems != nil {
+		return inv.LineItems, nil
+	}
+
+	resp, err := r.invoiceService.GetInvoice(ctx, inv.ID)
+	if err != nil {
+		return nil, err
+	}
+	return resp.LineItems, nil
+}
+
+func (r *resolvers) invoiceCustomer(ctx context.Context, p ResolveParams) (interface{}, error) {
+	return r.getCustomer(ctx, p.Source.(*dto.InvoiceResponse).CustomerID)
+}
+
+func (r *resolvers) invoiceSubscription(ctx context.Context, p ResolveParams) (interface{}, error) {
+	inv := p.Source.(*dto.InvoiceResponse)
+	if inv.SubscriptionID == "" {
+		return nil, nil
+	}
+	return r.subscriptionService.GetSubscription(ctx, inv.SubscriptionID)
+}
+
+func (r *resolvers) usage(ctx context.Context, p ResolveParams) (interface{}, error) {
+	subscriptionID, err := requiredString(p.Args, "subscription_id")
+	if err != nil {
+		return nil, err
+	}
+	return r.getUsage(ctx, subscriptionID, p.Args)
+}
+
+func (r *resolvers) getUsage(ctx context.Context, subscriptionID string, args Args) (interface{}, error) {
+	req := &dto.GetUsageBySubscriptionRequest{SubscriptionID: subscriptionID}
+
+	for name, t := range map[string]*time.Time{"start_time": &req.StartTime, "end_time": &req.EndTime} {
+		value, err := args.Time(name)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			*t = *value
+		}
+	}
+
+	return r.subscriptionService.GetUsageBySubscription(ctx, req)
+}
+
+func (r *resolvers) getCustomer(ctx context.Context, id string) (interface{}, error) {
+	return r.customerService.GetCustomer(ctx, id)
+}
+
+func requiredString(args Args, name string) (string, error) {
+	value, err := args.String(name)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", fmt.Errorf("argument %q is required", name)
+	}
+	return value, nil
+}
+
+func page(args Args, filter *types.Filter) error {
+	limit, err := args.Int("limit", filter.Limit)
+	if err != nil {
+		return err
+	}
+	if limit < 1 || limit > maxListLimit {
+		return fmt.Errorf("argument \"limit\" must be between 1 and %d", maxListLimit)
+	}
+
+	offset, err := args.Int("offset", 0)
+	if err != nil {
+		return err
+	}
+	if offset < 0 {
+		return fmt.Errorf("argument \"offset\" can not be negative")
+	}
+
+	filter.Limit = limit
+	filter.Offset = offset
+	return nil
+}
@@ -0,0 +1,208 @@
+package graphql
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSchemaTest(t *testing.T) *Schema {
+	ctx := testutil.SetupContext()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_123",
+		ExternalID: "ext_cust_123",
+		Name:       "Acme",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Pro",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	for _, p := range []*price.Price{
+		{ID: "price_usd", Currency: "usd", Amount: decimal.NewFromInt(20)},
+		{ID: "price_eur", Currency: "eur", Amount: decimal.NewFromInt(18)},
+	} {
+		p.PlanID = "plan_123"
+		p.Type = types.PRICE_TYPE_FIXED
+		p.BillingPeriod = types.BILLING_PERIOD_MONTHLY
+		p.BillingPeriodCount = 1
+		p.BillingModel = types.BILLING_MODEL_FLAT_FEE
+		p.BillingCadence = types.BILLING_CADENCE_RECURRING
+		p.Description = "Platform fee"
+		p.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, priceStore.Create(ctx, p))
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	customerService := service.NewCustomerService(customerStore, nil, nil, nil, logger.GetLogger())
+	subscriptionService := service.NewSubscriptionService(
+		subscriptionStore, planStore, priceStore, testutil.NewInMemoryMessageBroker(),
+		testutil.NewInMemoryEventStore(), testutil.NewInMemoryMeterStore(), customerStore,
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger.GetLogger(),
+	)
+	invoiceService := service.NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(),
+		testutil.NewInMemoryRateCardStore(), nil, nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, nil,
+		service.NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(), testutil.NewInMemoryMeterStore(), customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	_, err := invoiceService.CreateSubscriptionInvoice(ctx, "sub_123", dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+
+	return NewSchema(customerService, subscriptionService, invoiceService)
+}
+
+// execute runs the query and returns the response as plain JSON values
+func execute(t *testing.T, schema *Schema, req Request) map[string]interface{} {
+	resp := schema.Execute(testutil.SetupContext(), req)
+
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	return result
+}
+
+func TestSchema_NestedQuery(t *testing.T) {
+	schema := setupSchemaTest(t)
+
+	result := execute(t, schema, Request{
+		Query: `
+			query Dashboard($id: String!) {
+				customer(id: $id) {
+					__typename
+					name
+					subscriptions(subscription_status: "active") {
+						...subscriptionFields
+						invoices {
+							total
+							line_items { display_name amount }
+						}
+					}
+				}
+			}
+
+			fragment subscriptionFields on Subscription {
+				id
+				plan { name }
+				lines: line_items { id }
+			}`,
+		Variables: map[string]interface{}{"id": "cust_123"},
+	})
+
+	require.Nil(t, result["errors"])
+	customer := result["data"].(map[string]interface{})["customer"].(map[string]interface{})
+	assert.Equal(t, "Customer", customer["__typename"])
+	assert.Equal(t, "Acme", customer["name"])
+
+	subscriptions := customer["subscriptions"].([]interface{})
+	require.Len(t, subscriptions, 1)
+	sub := subscriptions[0].(map[string]interface{})
+	assert.Equal(t, "sub_123", sub["id"])
+	assert.Equal(t, "Pro", sub["plan"].(map[string]interface{})["name"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "price_usd"}}, sub["lines"])
+
+	invoices := sub["invoices"].([]interface{})
+	require.Len(t, invoices, 1)
+	inv := invoices[0].(map[string]interface{})
+	assert.Equal(t, "20", inv["total"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"display_name": "Platform fee", "amount": "20"},
+	}, inv["line_items"])
+}
+
+func TestSchema_Errors(t *testing.T) {
+	schema := setupSchemaTest(t)
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{name: "syntax error", query: `{ customer(id: "cust_123") { name }`, wantErr: "syntax error: unexpected end of document"},
+		{name: "unknown field", query: `{ customers { password } }`, wantErr: `cannot query field "password" on type "Customer"`},
+		{name: "unknown argument", query: `{ customers(email: "a") { id } }`, wantErr: `unknown argument "email" on field "customers" of type "Query"`},
+		{name: "missing selection", query: `{ customers }`, wantErr: `field "customers" of type "Query" must have a selection of subfields`},
+		{name: "mutation", query: `mutation { customers { id } }`, wantErr: "mutation operations are not supported, the API is read only"},
+		{
+			name:    "too deep",
+			query:   `{ customers { invoices { customer { invoices { customer { invoices { customer { invoices { id } } } } } } } } }`,
+			wantErr: "query exceeds the maximum depth of 8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := execute(t, schema, Request{Query: tt.query})
+			assert.Nil(t, result["data"])
+			errors := result["errors"].([]interface{})
+			require.Len(t, errors, 1)
+			assert.Equal(t, tt.wantErr, errors[0].(map[string]interface{})["message"])
+		})
+	}
+}
+
+func TestSchema_FieldErrorReturnsPartialData(t *testing.T) {
+	schema := setupSchemaTest(t)
+
+	result := execute(t, schema, Request{Query: `{
+		known: customer(id: "cust_123") { id }
+		missing: subscription(id: "sub_missing") { id }
+	}`})
+
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"id": "cust_123"}, data["known"])
+	assert.Nil(t, data["missing"])
+
+	errors := result["errors"].([]interface{})
+	require.Len(t, errors, 1)
+	assert.Equal(t, []interface{}{"missing"}, errors[0].(map[string]interface{})["path"])
+}
@@ -2,53 +2,176 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"time"
 
-	"github.com/ThreeDotsLabs/watermill"
+	"github.com/Shopify/sarama"
 	"github.com/ThreeDotsLabs/watermill-kafka/v2/pkg/kafka"
-	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/flexprice/flexprice/internal/config"
-	"github.com/flexprice/flexprice/internal/types"
 )
 
+// Message is a message consumed from a partition of a topic
+type Message struct {
+	// ID is the ID the message was published with
+	ID        string
+	Topic     string
+	Partition int32
+	Offset    int64
+	Payload   []byte
+}
+
+// BatchHandler processes a batch of messages of a partition, in the order of
+// the partition. The batch is committed once the handler returns nil, an error
+// has it consumed again
+type BatchHandler func(ctx context.Context, batch []*Message) error
+
 type MessageConsumer interface {
-	Subscribe(topic string) (<-chan *message.Message, error)
+	// Consume hands the messages of the topic to the handler in batches until
+	// the context is cancelled
+	Consume(ctx context.Context, topic string, handler BatchHandler) error
 	Close() error
 }
 
+// Consumer is a member of a consumer group. Each partition assigned to it is
+// consumed on its own, its messages batched up to batchSize or for linger
 type Consumer struct {
-	subscriber message.Subscriber
+	group     sarama.ConsumerGroup
+	batchSize int
+	linger    time.Duration
 }
 
 func NewConsumer(cfg *config.Configuration) (MessageConsumer, error) {
-	enableDebugLogs := cfg.Logging.Level == types.LogLevelDebug
+	return NewGroupConsumer(cfg, cfg.Kafka.ConsumerGroup)
+}
 
-	saramaConfig := GetSaramaConfig(cfg)
-	if saramaConfig != nil {
-		// add consumer configs
-		saramaConfig.Consumer.Group.Session.Timeout = 45000 * time.Millisecond
-	}
-
-	subscriber, err := kafka.NewSubscriber(
-		kafka.SubscriberConfig{
-			Brokers:               cfg.Kafka.Brokers,
-			ConsumerGroup:         cfg.Kafka.ConsumerGroup,
-			Unmarshaler:           kafka.DefaultMarshaler{},
-			OverwriteSaramaConfig: saramaConfig,
-		},
-		watermill.NewStdLogger(enableDebugLogs, enableDebugLogs),
-	)
+// NewGroupConsumer returns a consumer in the given consumer group, each lane of
+// events is consumed by its own group
+func NewGroupConsumer(cfg *config.Configuration, consumerGroup string) (MessageConsumer, error) {
+	group, err := sarama.NewConsumerGroup(cfg.Kafka.Brokers, consumerGroup, consumerSaramaConfig(cfg))
 	if err != nil {
 		return nil, err
 	}
 
-	return &Consumer{subscriber: subscriber}, nil
+	return newConsumer(group, cfg.Kafka.BatchSize, time.Duration(cfg.Kafka.BatchLingerMs)*time.Millisecond), nil
+}
+
+func newConsumer(group sarama.ConsumerGroup, batchSize int, linger time.Duration) *Consumer {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if linger <= 0 {
+		linger = 500 * time.Millisecond
+	}
+	return &Consumer{group: group, batchSize: batchSize, linger: linger}
+}
+
+func consumerSaramaConfig(cfg *config.Configuration) *sarama.Config {
+	saramaConfig := GetSaramaConfig(cfg)
+	if saramaConfig == nil {
+		saramaConfig = sarama.NewConfig()
+		saramaConfig.Version = sarama.V2_1_0_0
+		saramaConfig.ClientID = cfg.Kafka.ClientID
+	}
+
+	// add consumer configs
+	saramaConfig.Consumer.Group.Session.Timeout = 45000 * time.Millisecond
+	saramaConfig.Consumer.Return.Errors = true
+	return saramaConfig
 }
 
-func (c *Consumer) Subscribe(topic string) (<-chan *message.Message, error) {
-	return c.subscriber.Subscribe(context.Background(), topic)
+func (c *Consumer) Consume(ctx context.Context, topic string, handler BatchHandler) error {
+	for {
+		// Consume returns when the partitions are rebalanced between the
+		// members, it is called again to get the new assignment
+		err := c.group.Consume(ctx, []string{topic}, &batchClaimHandler{consumer: c, handler: handler})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return err
+		}
+		if err != nil {
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
 }
 
 func (c *Consumer) Close() error {
-	return c.subscriber.Close()
+	return c.group.Close()
+}
+
+// batchClaimHandler consumes the partitions claimed by the member in batches
+type batchClaimHandler struct {
+	consumer *Consumer
+	handler  BatchHandler
+}
+
+func (h *batchClaimHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *batchClaimHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// ConsumeClaim batches the messages of a partition. The offset of a batch is
+// marked once the handler processed it, a batch in progress when the partition
+// is revoked is consumed again by its next member
+func (h *batchClaimHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	var (
+		batch  []*Message
+		last   *sarama.ConsumerMessage
+		linger <-chan time.Time
+	)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := h.handler(sess.Context(), batch); err != nil {
+			return err
+		}
+		sess.MarkMessage(last, "")
+		batch, linger = nil, nil
+		return nil
+	}
+
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return flush()
+			}
+			if len(batch) == 0 {
+				linger = time.After(h.consumer.linger)
+			}
+			batch = append(batch, newMessage(msg))
+			last = msg
+			if len(batch) >= h.consumer.batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case <-linger:
+			if err := flush(); err != nil {
+				return err
+			}
+		case <-sess.Context().Done():
+			return nil
+		}
+	}
+}
+
+func newMessage(msg *sarama.ConsumerMessage) *Message {
+	message := &Message{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Payload:   msg.Value,
+	}
+	for _, header := range msg.Headers {
+		if header != nil && string(header.Key) == kafka.UUIDHeaderKey {
+			message.ID = string(header.Value)
+		}
+	}
+	return message
 }
@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/ThreeDotsLabs/watermill-kafka/v2/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *testSession) Context() context.Context { return s.ctx }
+
+func (s *testSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type testClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *testClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func newTestClaim(offsets ...int64) *testClaim {
+	claim := &testClaim{messages: make(chan *sarama.ConsumerMessage, len(offsets))}
+	for _, offset := range offsets {
+		claim.messages <- &sarama.ConsumerMessage{
+			Topic:     "events",
+			Partition: 3,
+			Offset:    offset,
+			Value:     []byte(`{}`),
+			Headers:   []*sarama.RecordHeader{{Key: []byte(kafka.UUIDHeaderKey), Value: []byte("evt")}},
+		}
+	}
+	return claim
+}
+
+func TestConsumeClaimBatches(t *testing.T) {
+	consumer := newConsumer(nil, 2, time.Hour)
+
+	t.Run("hands full batches and marks them once stored", func(t *testing.T) {
+		var batches [][]int64
+		handler := &batchClaimHandler{consumer: consumer, handler: func(ctx context.Context, batch []*Message) error {
+			var offsets []int64
+			for _, msg := range batch {
+				assert.Equal(t, "evt", msg.ID)
+				assert.Equal(t, int32(3), msg.Partition)
+				offsets = append(offsets, msg.Offset)
+			}
+			batches = append(batches, offsets)
+			return nil
+		}}
+
+		session := &testSession{ctx: context.Background()}
+		claim := newTestClaim(10, 11, 12, 13, 14)
+		close(claim.messages)
+
+		require.NoError(t, handler.ConsumeClaim(session, claim))
+		assert.Equal(t, [][]int64{{10, 11}, {12, 13}, {14}}, batches)
+		assert.Equal(t, []int64{11, 13, 14}, session.marked)
+	})
+
+	t.Run("hands a partial batch after the linger", func(t *testing.T) {
+		lingering := newConsumer(nil, 100, 10*time.Millisecond)
+		stored := make(chan []*Message, 1)
+		handler := &batchClaimHandler{consumer: lingering, handler: func(ctx context.Context, batch []*Message) error {
+			stored <- batch
+			return nil
+		}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		session := &testSession{ctx: ctx}
+		claim := newTestClaim(20, 21)
+
+		done := make(chan error)
+		go func() { done <- handler.ConsumeClaim(session, claim) }()
+
+		select {
+		case batch := <-stored:
+			assert.Len(t, batch, 2)
+		case <-time.After(time.Second):
+			t.Fatal("batch not handed after the linger")
+		}
+		cancel()
+		require.NoError(t, <-done)
+		assert.Equal(t, []int64{21}, session.marked)
+	})
+
+	t.Run("does not mark a batch that failed", func(t *testing.T) {
+		handler := &batchClaimHandler{consumer: consumer, handler: func(ctx context.Context, batch []*Message) error {
+			return errors.New("clickhouse unavailable")
+		}}
+
+		session := &testSession{ctx: context.Background()}
+		claim := newTestClaim(30, 31, 32)
+		close(claim.messages)
+
+		assert.Error(t, handler.ConsumeClaim(session, claim))
+		assert.Empty(t, session.marked)
+	})
+}
@@ -0,0 +1,72 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"github.com/flexprice/flexprice/internal/config"
+)
+
+// PartitionLag is how far a consumer group is behind a partition of its topic
+type PartitionLag struct {
+	Partition int32 `json:"partition"`
+	// CommittedOffset is the next offset the group consumes, -1 when the group
+	// has not committed an offset of the partition yet
+	CommittedOffset int64 `json:"committed_offset"`
+	HighWatermark   int64 `json:"high_watermark"`
+	Lag             int64 `json:"lag"`
+}
+
+// LagReader reads the lag of consumer groups from the brokers
+type LagReader interface {
+	ConsumerLag(topic, consumerGroup string) ([]PartitionLag, error)
+}
+
+type lagReader struct {
+	cfg *config.Configuration
+}
+
+// NewLagReader returns a lag reader connecting to the brokers on each read
+func NewLagReader(cfg *config.Configuration) LagReader {
+	return &lagReader{cfg: cfg}
+}
+
+func (r *lagReader) ConsumerLag(topic, consumerGroup string) ([]PartitionLag, error) {
+	client, err := sarama.NewClient(r.cfg.Kafka.Brokers, consumerSaramaConfig(r.cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to kafka: %w", err)
+	}
+	// Closing the admin closes its client
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to kafka: %w", err)
+	}
+	defer admin.Close()
+
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions of %s: %w", topic, err)
+	}
+
+	offsets, err := admin.ListConsumerGroupOffsets(consumerGroup, map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets of %s: %w", consumerGroup, err)
+	}
+
+	lags := make([]PartitionLag, 0, len(partitions))
+	for _, partition := range partitions {
+		highWatermark, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get offset of %s/%d: %w", topic, partition, err)
+		}
+
+		lag := PartitionLag{Partition: partition, CommittedOffset: -1, HighWatermark: highWatermark}
+		if block := offsets.GetBlock(topic, partition); block != nil && block.Offset >= 0 {
+			lag.CommittedOffset = block.Offset
+			lag.Lag = highWatermark - block.Offset
+		}
+		lags = append(lags, lag)
+	}
+	return lags, nil
+}
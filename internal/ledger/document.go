@@ -0,0 +1,124 @@
+// Package ledger pushes finalized invoices and credit notes to the accounting
+// ledgers, NetSuite and QuickBooks, of the integration connections
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Document is an invoice or a credit note as created in a ledger
+type Document struct {
+	Type        types.SyncEntityType
+	Number      string
+	Date        time.Time
+	Currency    string
+	CustomerRef string
+	Memo        string
+	Lines       []Line
+}
+
+// Line is a line of a ledger document. Amounts are positive for credit notes too
+type Line struct {
+	ItemRef     string
+	Description string
+	Amount      decimal.Decimal
+	Quantity    decimal.Decimal
+}
+
+// Client creates documents in the ledger of a connection
+type Client interface {
+	// Push creates the document and returns its ID in the ledger
+	Push(ctx context.Context, doc *Document) (string, error)
+}
+
+// NewClient returns the client of the ledger of the connection
+func NewClient(conn *connection.Connection) (Client, error) {
+	if conn.LedgerSettings == nil {
+		return nil, fmt.Errorf("connection %s has no ledger settings", conn.ID)
+	}
+	if conn.Credentials == "" {
+		return nil, fmt.Errorf("connection %s has no credentials", conn.ID)
+	}
+
+	switch conn.Provider {
+	case types.IntegrationProviderQuickBooks:
+		return newQuickBooksClient(*conn.LedgerSettings, conn.Credentials), nil
+	case types.IntegrationProviderNetSuite:
+		return newNetSuiteClient(*conn.LedgerSettings, conn.Credentials), nil
+	default:
+		return nil, fmt.Errorf("provider %s is not a ledger", conn.Provider)
+	}
+}
+
+// EntityType returns the type of ledger document of the invoice, credit
+// invoices are credit notes
+func EntityType(inv *invoice.Invoice) types.SyncEntityType {
+	if inv.InvoiceType == types.InvoiceTypeCredit {
+		return types.SyncEntityTypeCreditNote
+	}
+	return types.SyncEntityTypeInvoice
+}
+
+// BuildDocument maps a finalized invoice to a ledger document
+func BuildDocument(inv *invoice.Invoice, cust *customer.Customer, mapping connection.LedgerFieldMapping) (*Document, error) {
+	customerRef := mapping.CustomerIDs[cust.ID]
+	if customerRef == "" {
+		customerRef = cust.ExternalID
+	}
+	if customerRef == "" {
+		return nil, fmt.Errorf("customer %s has no ledger customer", cust.ID)
+	}
+
+	doc := &Document{
+		Type:        EntityType(inv),
+		Currency:    inv.Currency,
+		CustomerRef: customerRef,
+		Memo:        inv.Description,
+	}
+	if inv.InvoiceNumber != nil {
+		doc.Number = *inv.InvoiceNumber
+	}
+	if inv.FinalizedAt != nil {
+		doc.Date = *inv.FinalizedAt
+	} else {
+		doc.Date = inv.CreatedAt
+	}
+
+	// Credit invoices carry negative amounts while credit notes are positive
+	// documents of their own type in the ledgers
+	for _, item := range inv.LineItems {
+		amount := item.Amount
+		if doc.Type == types.SyncEntityTypeCreditNote {
+			amount = amount.Neg()
+		}
+		doc.Lines = append(doc.Lines, Line{
+			ItemRef:     mapping.ItemID(item.PriceID),
+			Description: item.DisplayName,
+			Amount:      amount,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	return doc, nil
+}
+
+// rateLimitError maps a 429 response to the error that makes the sync queue of
+// the connection back off
+func rateLimitError(resp *http.Response) error {
+	var retryAfter time.Duration
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+	return &syncqueue.RateLimitError{RetryAfter: retryAfter}
+}
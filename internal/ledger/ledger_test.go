@@ -0,0 +1,105 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDocument(docType types.SyncEntityType) *Document {
+	return &Document{
+		Type:        docType,
+		Number:      "INV-0001",
+		Date:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Currency:    "USD",
+		CustomerRef: "42",
+		Lines: []Line{
+			{ItemRef: "7", Description: "API calls", Amount: decimal.NewFromInt(20), Quantity: decimal.NewFromInt(1)},
+		},
+	}
+}
+
+func TestQuickBooksClient_Push(t *testing.T) {
+	var path string
+	var body quickBooksDocument
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"CreditMemo":{"Id":"145"}}`))
+	}))
+	defer server.Close()
+
+	client := newQuickBooksClient(connection.LedgerSettings{AccountID: "4620816365", Sandbox: true}, "access-token")
+	assert.Equal(t, quickBooksSandboxURL, client.baseURL)
+	client.baseURL = server.URL
+
+	id, err := client.Push(context.Background(), testDocument(types.SyncEntityTypeCreditNote))
+	require.NoError(t, err)
+	assert.Equal(t, "145", id)
+	assert.Equal(t, "/v3/company/4620816365/creditmemo", path)
+	assert.Equal(t, "2024-03-01", body.TxnDate)
+	assert.Equal(t, "42", body.CustomerRef.Value)
+	require.Len(t, body.Line, 1)
+	assert.Equal(t, float64(20), body.Line[0].Amount)
+	assert.Equal(t, "7", body.Line[0].SalesItemLineDetail.ItemRef.Value)
+}
+
+func TestNetSuiteClient_Push(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Location", "https://1234567-sb1.suitetalk.api.netsuite.com/services/rest/record/v1/invoice/981")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := newNetSuiteClient(connection.LedgerSettings{AccountID: "1234567_SB1"}, "access-token")
+	assert.Equal(t, "https://1234567-sb1.suitetalk.api.netsuite.com/services/rest/record/v1", client.baseURL)
+	client.baseURL = server.URL
+
+	id, err := client.Push(context.Background(), testDocument(types.SyncEntityTypeInvoice))
+	require.NoError(t, err)
+	assert.Equal(t, "981", id)
+	assert.Equal(t, "/invoice", path)
+}
+
+func TestPushRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := newNetSuiteClient(connection.LedgerSettings{AccountID: "1234567"}, "access-token")
+	client.baseURL = server.URL
+
+	_, err := client.Push(context.Background(), testDocument(types.SyncEntityTypeInvoice))
+	rateLimitErr, ok := syncqueue.IsRateLimited(err)
+	require.True(t, ok)
+	assert.Equal(t, 30*time.Second, rateLimitErr.RetryAfter)
+}
+
+func TestNewClient(t *testing.T) {
+	settings := &connection.LedgerSettings{AccountID: "1234567"}
+
+	_, err := NewClient(&connection.Connection{Provider: types.IntegrationProviderNetSuite, LedgerSettings: settings})
+	assert.Error(t, err, "credentials are required")
+
+	_, err = NewClient(&connection.Connection{Provider: types.IntegrationProviderHubSpot, LedgerSettings: settings, Credentials: "token"})
+	assert.Error(t, err)
+
+	client, err := NewClient(&connection.Connection{Provider: types.IntegrationProviderQuickBooks, LedgerSettings: settings, Credentials: "token"})
+	require.NoError(t, err)
+	assert.IsType(t, &quickBooksClient{}, client)
+}
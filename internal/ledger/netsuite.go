@@ -0,0 +1,112 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type netSuiteClient struct {
+	baseURL     string
+	accessToken string
+	client      *http.Client
+}
+
+func newNetSuiteClient(settings connection.LedgerSettings, accessToken string) *netSuiteClient {
+	// Account IDs such as 1234567_SB1 are 1234567-sb1 in the REST domain
+	host := strings.ReplaceAll(strings.ToLower(settings.AccountID), "_", "-")
+	return &netSuiteClient{
+		baseURL:     fmt.Sprintf("https://%s.suitetalk.api.netsuite.com/services/rest/record/v1", host),
+		accessToken: accessToken,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type netSuiteRef struct {
+	ID string `json:"id"`
+}
+
+type netSuiteLine struct {
+	Item        netSuiteRef `json:"item"`
+	Amount      float64     `json:"amount"`
+	Quantity    float64     `json:"quantity,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+type netSuiteDocument struct {
+	Entity   netSuiteRef `json:"entity"`
+	TranID   string      `json:"tranId,omitempty"`
+	TranDate string      `json:"tranDate"`
+	Currency netSuiteRef `json:"currency"`
+	Memo     string      `json:"memo,omitempty"`
+	Item     struct {
+		Items []netSuiteLine `json:"items"`
+	} `json:"item"`
+}
+
+func (c *netSuiteClient) Push(ctx context.Context, doc *Document) (string, error) {
+	payload := netSuiteDocument{
+		Entity:   netSuiteRef{ID: doc.CustomerRef},
+		TranID:   doc.Number,
+		TranDate: doc.Date.Format("2006-01-02"),
+		Currency: netSuiteRef{ID: doc.Currency},
+		Memo:     doc.Memo,
+	}
+	for _, line := range doc.Lines {
+		amount, _ := line.Amount.Float64()
+		qty, _ := line.Quantity.Float64()
+		payload.Item.Items = append(payload.Item.Items, netSuiteLine{
+			Item:        netSuiteRef{ID: line.ItemRef},
+			Amount:      amount,
+			Quantity:    qty,
+			Description: line.Description,
+		})
+	}
+
+	record := "invoice"
+	if doc.Type == types.SyncEntityTypeCreditNote {
+		record = "creditMemo"
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode netsuite %s: %w", record, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+record, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to push netsuite %s: %w", record, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", rateLimitError(resp)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("netsuite responded with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	// NetSuite answers 204 with the URL of the new record
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("netsuite response has no location")
+	}
+	return path.Base(location), nil
+}
@@ -0,0 +1,142 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const (
+	quickBooksURL        = "https://quickbooks.api.intuit.com"
+	quickBooksSandboxURL = "https://sandbox-quickbooks.api.intuit.com"
+)
+
+type quickBooksClient struct {
+	baseURL     string
+	realmID     string
+	accessToken string
+	client      *http.Client
+}
+
+func newQuickBooksClient(settings connection.LedgerSettings, accessToken string) *quickBooksClient {
+	baseURL := quickBooksURL
+	if settings.Sandbox {
+		baseURL = quickBooksSandboxURL
+	}
+	return &quickBooksClient{
+		baseURL:     baseURL,
+		realmID:     settings.AccountID,
+		accessToken: accessToken,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type quickBooksRef struct {
+	Value string `json:"value"`
+}
+
+type quickBooksItemDetail struct {
+	ItemRef quickBooksRef `json:"ItemRef"`
+	Qty     float64       `json:"Qty,omitempty"`
+}
+
+type quickBooksLine struct {
+	DetailType          string               `json:"DetailType"`
+	Amount              float64              `json:"Amount"`
+	Description         string               `json:"Description,omitempty"`
+	SalesItemLineDetail quickBooksItemDetail `json:"SalesItemLineDetail"`
+}
+
+type quickBooksDocument struct {
+	DocNumber   string           `json:"DocNumber,omitempty"`
+	TxnDate     string           `json:"TxnDate"`
+	CurrencyRef quickBooksRef    `json:"CurrencyRef"`
+	CustomerRef quickBooksRef    `json:"CustomerRef"`
+	PrivateNote string           `json:"PrivateNote,omitempty"`
+	Line        []quickBooksLine `json:"Line"`
+}
+
+type quickBooksEntity struct {
+	ID string `json:"Id"`
+}
+
+type quickBooksResponse struct {
+	Invoice    *quickBooksEntity `json:"Invoice"`
+	CreditMemo *quickBooksEntity `json:"CreditMemo"`
+}
+
+func (c *quickBooksClient) Push(ctx context.Context, doc *Document) (string, error) {
+	payload := quickBooksDocument{
+		DocNumber:   doc.Number,
+		TxnDate:     doc.Date.Format("2006-01-02"),
+		CurrencyRef: quickBooksRef{Value: doc.Currency},
+		CustomerRef: quickBooksRef{Value: doc.CustomerRef},
+		PrivateNote: doc.Memo,
+	}
+	for _, line := range doc.Lines {
+		amount, _ := line.Amount.Float64()
+		qty, _ := line.Quantity.Float64()
+		payload.Line = append(payload.Line, quickBooksLine{
+			DetailType:  "SalesItemLineDetail",
+			Amount:      amount,
+			Description: line.Description,
+			SalesItemLineDetail: quickBooksItemDetail{
+				ItemRef: quickBooksRef{Value: line.ItemRef},
+				Qty:     qty,
+			},
+		})
+	}
+
+	resource := "invoice"
+	if doc.Type == types.SyncEntityTypeCreditNote {
+		resource = "creditmemo"
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode quickbooks %s: %w", resource, err)
+	}
+
+	url := fmt.Sprintf("%s/v3/company/%s/%s", c.baseURL, c.realmID, resource)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to push quickbooks %s: %w", resource, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", rateLimitError(resp)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("quickbooks responded with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var result quickBooksResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("failed to decode quickbooks response: %w", err)
+	}
+	switch {
+	case result.Invoice != nil:
+		return result.Invoice.ID, nil
+	case result.CreditMemo != nil:
+		return result.CreditMemo.ID, nil
+	default:
+		return "", fmt.Errorf("quickbooks response has no %s", resource)
+	}
+}
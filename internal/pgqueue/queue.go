@@ -0,0 +1,176 @@
+package pgqueue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/kafka"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/lib/pq"
+)
+
+var (
+	_ kafka.MessageProducer = (*Producer)(nil)
+	_ kafka.MessageConsumer = (*Consumer)(nil)
+	_ kafka.LagReader       = (*LagReader)(nil)
+)
+
+// Producer publishes the messages to the queue_messages table
+type Producer struct {
+	db *postgres.DB
+}
+
+func NewProducer(db *postgres.DB) kafka.MessageProducer {
+	return &Producer{db: db}
+}
+
+func (p *Producer) PublishWithID(topic string, payload []byte, id string) error {
+	if id == "" {
+		id = watermill.NewUUID()
+	}
+
+	_, err := p.db.ExecContext(context.Background(),
+		"INSERT INTO queue_messages (message_id, topic, payload) VALUES ($1, $2, $3)",
+		id, topic, payload)
+	if err != nil {
+		return fmt.Errorf("failed to queue message: %w", err)
+	}
+	return nil
+}
+
+func (p *Producer) Close() error {
+	return nil
+}
+
+// Consumer polls the messages of a topic in batches. The batch is locked while
+// the handler processes it and deleted once it returns nil, so the members of a
+// consumer group, in this process or others, never get the same messages.
+// A batch whose handler fails is unlocked and consumed again
+type Consumer struct {
+	db        *postgres.DB
+	batchSize int
+	poll      time.Duration
+}
+
+func NewConsumer(db *postgres.DB, cfg *config.Configuration) kafka.MessageConsumer {
+	batchSize := cfg.Kafka.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	poll := time.Duration(cfg.Queue.PollIntervalMs) * time.Millisecond
+	if poll <= 0 {
+		poll = time.Second
+	}
+	return &Consumer{db: db, batchSize: batchSize, poll: poll}
+}
+
+func (c *Consumer) Consume(ctx context.Context, topic string, handler kafka.BatchHandler) error {
+	for {
+		consumed, err := c.consumeBatch(ctx, topic, handler)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// A full batch is followed at once by the next one, the queue is only
+		// polled again after the interval once it is drained or failing
+		if err == nil && consumed == c.batchSize {
+			continue
+		}
+		select {
+		case <-time.After(c.poll):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// consumeBatch hands the oldest unlocked messages of the topic to the handler
+// and returns how many it consumed
+func (c *Consumer) consumeBatch(ctx context.Context, topic string, handler kafka.BatchHandler) (int, error) {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, message_id, topic, payload FROM queue_messages
+		WHERE topic = $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`, topic, c.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var batch []*kafka.Message
+	for rows.Next() {
+		msg := &kafka.Message{}
+		if err := rows.Scan(&msg.Offset, &msg.ID, &msg.Topic, &msg.Payload); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, msg)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	if err := handler(ctx, batch); err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, 0, len(batch))
+	for _, msg := range batch {
+		ids = append(ids, msg.Offset)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM queue_messages WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
+
+func (c *Consumer) Close() error {
+	return nil
+}
+
+// LagReader reports the messages of a topic waiting in the queue as a single
+// partition, the consumer groups of a topic share its queue
+type LagReader struct {
+	db *postgres.DB
+}
+
+func NewLagReader(db *postgres.DB) kafka.LagReader {
+	return &LagReader{db: db}
+}
+
+func (r *LagReader) ConsumerLag(topic, consumerGroup string) ([]kafka.PartitionLag, error) {
+	var (
+		pending int64
+		first   *int64
+		last    *int64
+	)
+	err := r.db.QueryRowContext(context.Background(),
+		"SELECT COUNT(*), MIN(id), MAX(id) FROM queue_messages WHERE topic = $1", topic).
+		Scan(&pending, &first, &last)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue of %s: %w", topic, err)
+	}
+
+	lag := kafka.PartitionLag{Partition: 0, CommittedOffset: -1, Lag: pending}
+	if last != nil {
+		lag.HighWatermark = *last + 1
+		lag.CommittedOffset = *first
+	}
+	return []kafka.PartitionLag{lag}, nil
+}
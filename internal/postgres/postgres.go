@@ -3,10 +3,13 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"sync/atomic"
 
 	"github.com/flexprice/flexprice/internal/config"
 	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
@@ -15,6 +18,10 @@ import (
 type DB struct {
 	*sqlx.DB
 	logger *logger.Logger
+
+	// replicas serve the reads of the requests with eventual consistency, in turn
+	replicas []*sqlx.DB
+	next     atomic.Uint64
 }
 
 // Querier interface defines all database operations
@@ -39,7 +46,16 @@ func NewDB(config *config.Configuration, logger *logger.Logger) (*DB, error) {
 		return nil, err
 	}
 
-	return &DB{DB: db, logger: logger}, nil
+	replicas := make([]*sqlx.DB, 0, len(config.Postgres.ReadReplicas))
+	for _, replica := range config.Postgres.ReadReplicas {
+		replicaDB, err := sqlx.Connect("postgres", config.Postgres.GetReplicaDSN(replica))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to read replica %s:%d: %w", replica.Host, replica.Port, err)
+		}
+		replicas = append(replicas, replicaDB)
+	}
+
+	return &DB{DB: db, logger: logger, replicas: replicas}, nil
 }
 
 // Close closes the database connection
@@ -47,6 +63,11 @@ func (db *DB) Close() {
 	if err := db.DB.Close(); err != nil {
 		log.Printf("Error closing database: %v", err)
 	}
+	for _, replica := range db.replicas {
+		if err := replica.Close(); err != nil {
+			log.Printf("Error closing read replica: %v", err)
+		}
+	}
 }
 
 // GetQuerier returns either the transaction from context or the base DB
@@ -57,6 +78,25 @@ func (db *DB) GetQuerier(ctx context.Context) Querier {
 	return NewTracedQuerier(db.DB, db.logger, "")
 }
 
+// GetReadQuerier returns a read replica for the reads of a request with eventual
+// consistency. Reads within a transaction or with strong consistency, and all
+// reads without replicas, go to the primary like GetQuerier
+func (db *DB) GetReadQuerier(ctx context.Context) Querier {
+	if _, ok := GetTx(ctx); ok || len(db.replicas) == 0 ||
+		types.GetReadConsistency(ctx) != types.ReadConsistencyEventual {
+		return db.GetQuerier(ctx)
+	}
+
+	replica := db.replicas[db.next.Add(1)%uint64(len(db.replicas))]
+	return NewTracedQuerier(replica, db.logger, "")
+}
+
+// NamedQueryReadContext is NamedQueryContext for the read only queries, which
+// may be served by a read replica
+func (db *DB) NamedQueryReadContext(ctx context.Context, query string, arg interface{}) (*sqlx.Rows, error) {
+	return db.GetReadQuerier(ctx).NamedQuery(query, arg)
+}
+
 // NamedExecContext is a helper method that wraps NamedExec with context
 func (db *DB) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
 	q := db.GetQuerier(ctx)
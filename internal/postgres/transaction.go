@@ -178,3 +178,14 @@ func (db *DB) WithTx(ctx context.Context, fn func(ctx context.Context) error) er
 
 	return nil
 }
+
+// TxManager runs a function within a database transaction. Services depend on
+// it instead of the DB so that they can be tested with in-memory repositories
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NewTxManager provides the DB as a TxManager
+func NewTxManager(db *DB) TxManager {
+	return db
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+)
+
+// Bucket is a named token bucket and its quota
+type Bucket struct {
+	Key   string
+	Limit config.RateLimit
+}
+
+// Capacity is the number of tokens the bucket holds when full, the burst of its
+// quota and at least one so that a quota with only a rate allows a request
+func (b Bucket) Capacity() int {
+	return max(b.Limit.Burst, 1)
+}
+
+// Result is the state of a bucket after a request. Allowed is whether the bucket
+// had a token, which is only taken when every bucket of the request had one
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// RetryAfter is how long until a token is available when the request is not allowed
+	RetryAfter time.Duration
+}
+
+// Limiter takes tokens from named token buckets shared by all the API servers.
+// Allow takes a token from each of the buckets of a request at once, or from none
+// of them, and returns their results in order
+type Limiter interface {
+	Allow(ctx context.Context, buckets []Bucket) ([]*Result, error)
+}
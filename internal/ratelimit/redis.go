@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills the buckets for the time elapsed since their last
+// update, on the clock of Redis so that the API servers agree on it, and takes a
+// token from each of them when all of them have one. Buckets are stored as
+// hashes expiring once they would be full again. The rate and burst of each key
+// are passed as pairs of arguments. It returns whether each bucket had a token,
+// its tokens left and the milliseconds until its next token
+var tokenBucketScript = redis.NewScript(`
+redis.replicate_commands()
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local buckets = {}
+local allowed = 1
+for i, key in ipairs(KEYS) do
+	local rate = tonumber(ARGV[i * 2 - 1])
+	local burst = tonumber(ARGV[i * 2])
+
+	local bucket = redis.call("HMGET", key, "tokens", "updated_at")
+	local tokens = tonumber(bucket[1]) or burst
+	local updated_at = tonumber(bucket[2]) or now
+	tokens = math.min(burst, tokens + math.max(0, now - updated_at) / 1000 * rate)
+
+	if tokens < 1 then
+		allowed = 0
+	end
+	buckets[i] = {rate = rate, burst = burst, tokens = tokens}
+end
+
+local reply = {}
+for i, key in ipairs(KEYS) do
+	local b = buckets[i]
+	if allowed == 1 then
+		b.tokens = b.tokens - 1
+	end
+
+	local has_token = 1
+	local retry_after = 0
+	if allowed == 0 and b.tokens < 1 then
+		has_token = 0
+		retry_after = math.ceil((1 - b.tokens) / b.rate * 1000)
+	end
+
+	redis.call("HSET", key, "tokens", tostring(b.tokens), "updated_at", tostring(now))
+	redis.call("PEXPIRE", key, math.ceil(b.burst / b.rate * 1000) + 1000)
+
+	table.insert(reply, has_token)
+	table.insert(reply, tostring(b.tokens))
+	table.insert(reply, retry_after)
+end
+
+return reply
+`)
+
+type redisLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisLimiter(client *redis.Client) Limiter {
+	return &redisLimiter{client: client}
+}
+
+func NewRedisClient(cfg config.RedisConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, buckets []Bucket) ([]*Result, error) {
+	results := make([]*Result, len(buckets))
+
+	// Buckets without a rate are unlimited and not stored
+	var keys []string
+	var args []interface{}
+	var limited []int
+	for i, bucket := range buckets {
+		if bucket.Limit.RatePerSecond <= 0 {
+			results[i] = &Result{Allowed: true, Remaining: -1}
+			continue
+		}
+
+		keys = append(keys, bucket.Key)
+		args = append(args, bucket.Limit.RatePerSecond, bucket.Capacity())
+		limited = append(limited, i)
+	}
+	if len(keys) == 0 {
+		return results, nil
+	}
+
+	values, err := tokenBucketScript.Run(ctx, l.client, keys, args...).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take tokens: %w", err)
+	}
+
+	if len(values) != 3*len(keys) {
+		return nil, fmt.Errorf("unexpected token bucket reply: %v", values)
+	}
+
+	for n, i := range limited {
+		hasToken, _ := values[3*n].(int64)
+		tokensReply, _ := values[3*n+1].(string)
+		retryAfter, _ := values[3*n+2].(int64)
+		tokens, err := strconv.ParseFloat(tokensReply, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected token bucket reply: %w", err)
+		}
+
+		results[i] = &Result{
+			Allowed:    hasToken == 1,
+			Remaining:  int(math.Floor(tokens)),
+			RetryAfter: time.Duration(retryAfter) * time.Millisecond,
+		}
+	}
+
+	return results, nil
+}
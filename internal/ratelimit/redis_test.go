@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+
+	// Buckets are refilled on the clock of Redis
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server.SetTime(now)
+	limiter := NewRedisLimiter(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	limit := config.RateLimit{RatePerSecond: 2, Burst: 3}
+
+	allow := func(key string) *Result {
+		results, err := limiter.Allow(ctx, []Bucket{{Key: key, Limit: limit}})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		return results[0]
+	}
+
+	// The bucket starts full
+	for i := 2; i >= 0; i-- {
+		result := allow("tenant_a")
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result := allow("tenant_a")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	// Buckets are independent
+	assert.True(t, allow("tenant_b").Allowed)
+
+	// Tokens are refilled at the rate
+	now = now.Add(500 * time.Millisecond)
+	server.SetTime(now)
+	result = allow("tenant_a")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Up to the burst
+	now = now.Add(time.Hour)
+	server.SetTime(now)
+	result = allow("tenant_a")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+
+	// Buckets expire once full again
+	assert.Greater(t, server.TTL("tenant_a"), time.Duration(0))
+}
+
+func TestRedisLimiter_AllowAll(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	server.SetTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := NewRedisLimiter(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+
+	buckets := []Bucket{
+		{Key: "key_1", Limit: config.RateLimit{RatePerSecond: 1, Burst: 2}},
+		{Key: "tenant_a", Limit: config.RateLimit{RatePerSecond: 1, Burst: 1}},
+	}
+
+	results, err := limiter.Allow(ctx, buckets)
+	require.NoError(t, err)
+	assert.True(t, results[0].Allowed)
+	assert.True(t, results[1].Allowed)
+	assert.Equal(t, 1, results[0].Remaining)
+	assert.Equal(t, 0, results[1].Remaining)
+
+	// The tenant is out of tokens, the token of the key is not taken
+	results, err = limiter.Allow(ctx, buckets)
+	require.NoError(t, err)
+	assert.True(t, results[0].Allowed)
+	assert.Equal(t, 1, results[0].Remaining)
+	assert.False(t, results[1].Allowed)
+	assert.Equal(t, time.Second, results[1].RetryAfter)
+
+	results, err = limiter.Allow(ctx, buckets)
+	require.NoError(t, err)
+	assert.Equal(t, 1, results[0].Remaining)
+}
+
+func TestRedisLimiter_Unlimited(t *testing.T) {
+	server := miniredis.RunT(t)
+	limiter := NewRedisLimiter(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+
+	for i := 0; i < 10; i++ {
+		results, err := limiter.Allow(context.Background(), []Bucket{{Key: "tenant_a"}})
+		require.NoError(t, err)
+		assert.True(t, results[0].Allowed)
+	}
+	assert.False(t, server.Exists("tenant_a"))
+}
@@ -0,0 +1,338 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/domain/report"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Table is the result of a report: its header and rows of formatted values
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Validate checks that the fields of the template exist on its entity and
+// that its columns and filters can be evaluated
+func Validate(t *report.Template) error {
+	if _, ok := fields[t.Entity]; !ok {
+		return fmt.Errorf("unsupported entity: %s", t.Entity)
+	}
+
+	if len(t.Columns) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+
+	grouped := make(map[string]bool, len(t.GroupBy))
+	for _, field := range t.GroupBy {
+		if _, ok := FieldKind(t.Entity, field); !ok {
+			return fmt.Errorf("unknown group by field: %s", field)
+		}
+		grouped[field] = true
+	}
+
+	var aggregated, plain int
+	for _, column := range t.Columns {
+		if err := validateColumn(t.Entity, column); err != nil {
+			return err
+		}
+		if column.Aggregate != "" {
+			aggregated++
+			continue
+		}
+		plain++
+		if len(t.GroupBy) > 0 && !grouped[column.Field] {
+			return fmt.Errorf("column %s must be aggregated or grouped by", column.Field)
+		}
+	}
+
+	// Without group-bys the records are either listed or aggregated as a whole
+	if len(t.GroupBy) == 0 && aggregated > 0 && plain > 0 {
+		return fmt.Errorf("columns must all be aggregated when aggregating without group by")
+	}
+
+	for _, filter := range t.Filters {
+		if err := validateFilter(t.Entity, filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateColumn(entity types.ReportEntity, column report.Column) error {
+	if column.Field == "" {
+		if column.Aggregate != types.ReportAggregateCount {
+			return fmt.Errorf("column field is required")
+		}
+		return nil
+	}
+
+	kind, ok := FieldKind(entity, column.Field)
+	if !ok {
+		return fmt.Errorf("unknown column field: %s", column.Field)
+	}
+
+	switch column.Aggregate {
+	case "", types.ReportAggregateCount, types.ReportAggregateMin, types.ReportAggregateMax:
+	case types.ReportAggregateSum, types.ReportAggregateAvg:
+		if kind != KindNumber {
+			return fmt.Errorf("%s requires a numeric field: %s", column.Aggregate, column.Field)
+		}
+	default:
+		return fmt.Errorf("unsupported aggregate: %s", column.Aggregate)
+	}
+
+	return nil
+}
+
+func validateFilter(entity types.ReportEntity, filter report.Filter) error {
+	kind, ok := FieldKind(entity, filter.Field)
+	if !ok {
+		return fmt.Errorf("unknown filter field: %s", filter.Field)
+	}
+
+	values := []string{filter.Value}
+	switch filter.Operator {
+	case types.ReportFilterEq, types.ReportFilterNeq:
+	case types.ReportFilterGt, types.ReportFilterGte, types.ReportFilterLt, types.ReportFilterLte:
+	case types.ReportFilterIn:
+		values = strings.Split(filter.Value, ",")
+	default:
+		return fmt.Errorf("unsupported filter operator: %s", filter.Operator)
+	}
+
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if kind == KindNumber {
+			if _, ok := parseNumber(value); !ok {
+				return fmt.Errorf("filter on %s requires a number: %s", filter.Field, value)
+			}
+		}
+		if kind == KindTime {
+			if _, ok := parseTime(value); !ok {
+				return fmt.Errorf("filter on %s requires a RFC3339 time or date: %s", filter.Field, value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Evaluate filters the records with the filters of the template, then lists
+// its columns for each record or aggregates them by its group-bys. Grouped
+// rows are ordered by their group-by values
+func Evaluate(t *report.Template, records []Record) (*Table, error) {
+	if err := Validate(t); err != nil {
+		return nil, err
+	}
+
+	table := &Table{Header: make([]string, len(t.Columns))}
+	for i, column := range t.Columns {
+		table.Header[i] = column.Header()
+	}
+
+	var matched []Record
+	for _, record := range records {
+		if matches(t.Entity, t.Filters, record) {
+			matched = append(matched, record)
+		}
+	}
+
+	var aggregated bool
+	for _, column := range t.Columns {
+		aggregated = aggregated || column.Aggregate != ""
+	}
+
+	if len(t.GroupBy) == 0 && !aggregated {
+		for _, record := range matched {
+			row := make([]string, len(t.Columns))
+			for i, column := range t.Columns {
+				row[i] = record[column.Field]
+			}
+			table.Rows = append(table.Rows, row)
+		}
+		return table, nil
+	}
+
+	groups := make(map[string][]Record)
+	var keys [][]string
+	for _, record := range matched {
+		key := make([]string, len(t.GroupBy))
+		for i, field := range t.GroupBy {
+			key[i] = record[field]
+		}
+		id := strings.Join(key, "\x00")
+		if _, ok := groups[id]; !ok {
+			keys = append(keys, key)
+		}
+		groups[id] = append(groups[id], record)
+	}
+
+	// Aggregating all the records yields a single row, even without records
+	if len(t.GroupBy) == 0 && len(keys) == 0 {
+		keys = append(keys, []string{})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		for k := range keys[i] {
+			if keys[i][k] != keys[j][k] {
+				return keys[i][k] < keys[j][k]
+			}
+		}
+		return false
+	})
+
+	for _, key := range keys {
+		group := groups[strings.Join(key, "\x00")]
+		row := make([]string, len(t.Columns))
+		for i, column := range t.Columns {
+			if column.Aggregate == "" {
+				row[i] = group[0][column.Field]
+				continue
+			}
+			row[i] = aggregate(t.Entity, column, group)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table, nil
+}
+
+func matches(entity types.ReportEntity, filters []report.Filter, record Record) bool {
+	for _, filter := range filters {
+		kind, _ := FieldKind(entity, filter.Field)
+		value := record[filter.Field]
+
+		var ok bool
+		switch filter.Operator {
+		case types.ReportFilterEq:
+			ok = compare(kind, value, filter.Value) == 0
+		case types.ReportFilterNeq:
+			ok = compare(kind, value, filter.Value) != 0
+		case types.ReportFilterIn:
+			for _, v := range strings.Split(filter.Value, ",") {
+				if compare(kind, value, strings.TrimSpace(v)) == 0 {
+					ok = true
+					break
+				}
+			}
+		default:
+			// Missing values never compare as greater or lower
+			if value == "" {
+				return false
+			}
+			c := compare(kind, value, filter.Value)
+			switch filter.Operator {
+			case types.ReportFilterGt:
+				ok = c > 0
+			case types.ReportFilterGte:
+				ok = c >= 0
+			case types.ReportFilterLt:
+				ok = c < 0
+			case types.ReportFilterLte:
+				ok = c <= 0
+			}
+		}
+
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compare orders two values of a field by its kind, falling back on their
+// text when either does not parse
+func compare(kind Kind, a, b string) int {
+	switch kind {
+	case KindNumber:
+		x, okX := parseNumber(a)
+		y, okY := parseNumber(b)
+		if okX && okY {
+			return x.Cmp(y)
+		}
+	case KindTime:
+		x, okX := parseTime(a)
+		y, okY := parseTime(b)
+		if okX && okY {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func aggregate(entity types.ReportEntity, column report.Column, records []Record) string {
+	if column.Aggregate == types.ReportAggregateCount {
+		if column.Field == "" {
+			return fmt.Sprint(len(records))
+		}
+		var count int
+		for _, record := range records {
+			if record[column.Field] != "" {
+				count++
+			}
+		}
+		return fmt.Sprint(count)
+	}
+
+	kind, _ := FieldKind(entity, column.Field)
+
+	var result string
+	sum := decimal.Zero
+	var count int64
+	for _, record := range records {
+		value := record[column.Field]
+		if value == "" {
+			continue
+		}
+
+		switch column.Aggregate {
+		case types.ReportAggregateSum, types.ReportAggregateAvg:
+			if d, ok := parseNumber(value); ok {
+				sum = sum.Add(d)
+				count++
+			}
+		case types.ReportAggregateMin:
+			if result == "" || compare(kind, value, result) < 0 {
+				result = value
+			}
+		case types.ReportAggregateMax:
+			if result == "" || compare(kind, value, result) > 0 {
+				result = value
+			}
+		}
+	}
+
+	switch column.Aggregate {
+	case types.ReportAggregateSum:
+		return sum.String()
+	case types.ReportAggregateAvg:
+		if count == 0 {
+			return ""
+		}
+		return sum.Div(decimal.NewFromInt(count)).String()
+	default:
+		return result
+	}
+}
+
+// WriteCSV writes the table with its header into w
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Header); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return fmt.Errorf("failed to write csv rows: %w", err)
+	}
+	return nil
+}
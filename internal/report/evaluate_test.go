@@ -0,0 +1,147 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/domain/report"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoiceRecords() []Record {
+	return []Record{
+		{"id": "inv-1", "customer_id": "c1", "currency": "usd", "total": "100", "invoice_status": "FINALIZED", "finalized_at": "2024-03-01T00:00:00Z", "metadata.region": "eu"},
+		{"id": "inv-2", "customer_id": "c1", "currency": "usd", "total": "50", "invoice_status": "FINALIZED", "finalized_at": "2024-03-05T00:00:00Z"},
+		{"id": "inv-3", "customer_id": "c2", "currency": "eur", "total": "30", "invoice_status": "FINALIZED", "finalized_at": "2024-02-20T00:00:00Z", "metadata.region": "eu"},
+		{"id": "inv-4", "customer_id": "c2", "currency": "usd", "total": "20", "invoice_status": "DRAFT"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template report.Template
+		wantErr  bool
+	}{
+		{
+			name: "listing",
+			template: report.Template{Entity: types.ReportEntityInvoices, Columns: report.Columns{
+				{Field: "id"}, {Field: "metadata.region"},
+			}},
+		},
+		{
+			name:     "unknown entity",
+			template: report.Template{Entity: "plans", Columns: report.Columns{{Field: "id"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown field",
+			template: report.Template{Entity: types.ReportEntityInvoices, Columns: report.Columns{{Field: "plan_id"}}},
+			wantErr:  true,
+		},
+		{
+			name: "sum of a string",
+			template: report.Template{Entity: types.ReportEntityInvoices, Columns: report.Columns{
+				{Field: "currency", Aggregate: types.ReportAggregateSum},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "column not grouped by",
+			template: report.Template{Entity: types.ReportEntityInvoices, GroupBy: []string{"currency"}, Columns: report.Columns{
+				{Field: "customer_id"}, {Field: "total", Aggregate: types.ReportAggregateSum},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "aggregate mixed with listing",
+			template: report.Template{Entity: types.ReportEntityInvoices, Columns: report.Columns{
+				{Field: "id"}, {Aggregate: types.ReportAggregateCount},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "invalid filter value",
+			template: report.Template{Entity: types.ReportEntityInvoices, Columns: report.Columns{{Field: "id"}},
+				Filters: report.Filters{{Field: "total", Operator: types.ReportFilterGt, Value: "lots"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.template)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Run("lists the filtered records", func(t *testing.T) {
+		table, err := Evaluate(&report.Template{
+			Entity:  types.ReportEntityInvoices,
+			Columns: report.Columns{{Field: "id"}, {Field: "total"}},
+			Filters: report.Filters{
+				{Field: "total", Operator: types.ReportFilterGte, Value: "30"},
+				{Field: "finalized_at", Operator: types.ReportFilterGte, Value: "2024-03-01"},
+			},
+		}, testInvoiceRecords())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"id", "total"}, table.Header)
+		assert.Equal(t, [][]string{{"inv-1", "100"}, {"inv-2", "50"}}, table.Rows)
+	})
+
+	t.Run("aggregates by group", func(t *testing.T) {
+		table, err := Evaluate(&report.Template{
+			Entity:  types.ReportEntityInvoices,
+			GroupBy: []string{"currency"},
+			Columns: report.Columns{
+				{Field: "currency"},
+				{Field: "total", Aggregate: types.ReportAggregateSum},
+				{Field: "total", Aggregate: types.ReportAggregateAvg},
+				{Field: "finalized_at", Aggregate: types.ReportAggregateMax},
+				{Aggregate: types.ReportAggregateCount},
+			},
+			Filters: report.Filters{{Field: "invoice_status", Operator: types.ReportFilterIn, Value: "FINALIZED, VOIDED"}},
+		}, testInvoiceRecords())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"currency", "sum(total)", "avg(total)", "max(finalized_at)", "count"}, table.Header)
+		assert.Equal(t, [][]string{
+			{"eur", "30", "30", "2024-02-20T00:00:00Z", "1"},
+			{"usd", "150", "75", "2024-03-05T00:00:00Z", "2"},
+		}, table.Rows)
+	})
+
+	t.Run("aggregates all records without group by", func(t *testing.T) {
+		table, err := Evaluate(&report.Template{
+			Entity:  types.ReportEntityInvoices,
+			Columns: report.Columns{{Field: "total", Aggregate: types.ReportAggregateSum}, {Field: "metadata.region", Aggregate: types.ReportAggregateCount}},
+		}, testInvoiceRecords())
+		require.NoError(t, err)
+
+		assert.Equal(t, [][]string{{"200", "2"}}, table.Rows)
+	})
+}
+
+func TestTable_WriteCSV(t *testing.T) {
+	table := &Table{
+		Header: []string{"customer_id", "sum(total)"},
+		Rows:   [][]string{{"c1", "150"}, {"c2, inc", "50"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, table.WriteCSV(&buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"customer_id", "sum(total)"}, {"c1", "150"}, {"c2, inc", "50"}}, records)
+}
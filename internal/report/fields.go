@@ -0,0 +1,227 @@
+package report
+
+import (
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Kind decides how the values of a field are compared and aggregated
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindNumber
+	KindTime
+)
+
+// MetadataPrefix prefixes the fields reading a metadata key ex metadata.segment.
+// Metadata values are strings
+const MetadataPrefix = "metadata."
+
+// Record is a row of an entity by field name. Numbers are decimal strings,
+// times RFC3339 and missing values empty
+type Record map[string]string
+
+var fields = map[types.ReportEntity]map[string]Kind{
+	types.ReportEntityCustomers: {
+		"id":          KindString,
+		"external_id": KindString,
+		"name":        KindString,
+		"email":       KindString,
+		"timezone":    KindString,
+		"created_at":  KindTime,
+	},
+	types.ReportEntitySubscriptions: {
+		"id":                   KindString,
+		"customer_id":          KindString,
+		"plan_id":              KindString,
+		"subscription_status":  KindString,
+		"currency":             KindString,
+		"billing_cadence":      KindString,
+		"billing_period":       KindString,
+		"cancellation_reason":  KindString,
+		"commitment_amount":    KindNumber,
+		"start_date":           KindTime,
+		"end_date":             KindTime,
+		"current_period_start": KindTime,
+		"current_period_end":   KindTime,
+		"cancelled_at":         KindTime,
+		"created_at":           KindTime,
+	},
+	types.ReportEntityInvoices: {
+		"id":               KindString,
+		"invoice_number":   KindString,
+		"customer_id":      KindString,
+		"subscription_id":  KindString,
+		"invoice_type":     KindString,
+		"invoice_status":   KindString,
+		"payment_status":   KindString,
+		"currency":         KindString,
+		"total":            KindNumber,
+		"amount_due":       KindNumber,
+		"amount_paid":      KindNumber,
+		"amount_remaining": KindNumber,
+		"period_start":     KindTime,
+		"period_end":       KindTime,
+		"finalized_at":     KindTime,
+		"created_at":       KindTime,
+	},
+	types.ReportEntityInvoiceLineItems: {
+		"id":              KindString,
+		"invoice_id":      KindString,
+		"customer_id":     KindString,
+		"subscription_id": KindString,
+		"price_id":        KindString,
+		"meter_id":        KindString,
+		"display_name":    KindString,
+		"currency":        KindString,
+		"amount":          KindNumber,
+		"quantity":        KindNumber,
+		"period_start":    KindTime,
+		"period_end":      KindTime,
+	},
+}
+
+// periodFields are the time fields runs with a period are restricted on
+var periodFields = map[types.ReportEntity]string{
+	types.ReportEntityCustomers:        "created_at",
+	types.ReportEntitySubscriptions:    "start_date",
+	types.ReportEntityInvoices:         "created_at",
+	types.ReportEntityInvoiceLineItems: "period_start",
+}
+
+// FieldKind returns the kind of a field of the entity, false when the entity
+// has no such field
+func FieldKind(entity types.ReportEntity, field string) (Kind, bool) {
+	if key, ok := strings.CutPrefix(field, MetadataPrefix); ok {
+		_, known := fields[entity]
+		return KindString, known && key != ""
+	}
+	kind, ok := fields[entity][field]
+	return kind, ok
+}
+
+// PeriodField returns the time field of the entity the period of a run applies to
+func PeriodField(entity types.ReportEntity) string {
+	return periodFields[entity]
+}
+
+func CustomerRecord(c *customer.Customer) Record {
+	record := Record{
+		"id":          c.ID,
+		"external_id": c.ExternalID,
+		"name":        c.Name,
+		"email":       c.Email,
+		"timezone":    c.Timezone,
+		"created_at":  formatTime(&c.CreatedAt),
+	}
+	addMetadata(record, c.Metadata)
+	return record
+}
+
+func SubscriptionRecord(s *subscription.Subscription) Record {
+	record := Record{
+		"id":                   s.ID,
+		"customer_id":          s.CustomerID,
+		"plan_id":              s.PlanID,
+		"subscription_status":  string(s.SubscriptionStatus),
+		"currency":             s.Currency,
+		"billing_cadence":      string(s.BillingCadence),
+		"billing_period":       string(s.BillingPeriod),
+		"cancellation_reason":  s.CancellationReason,
+		"commitment_amount":    s.CommitmentAmount.String(),
+		"start_date":           formatTime(&s.StartDate),
+		"end_date":             formatTime(s.EndDate),
+		"current_period_start": formatTime(&s.CurrentPeriodStart),
+		"current_period_end":   formatTime(&s.CurrentPeriodEnd),
+		"cancelled_at":         formatTime(s.CancelledAt),
+		"created_at":           formatTime(&s.CreatedAt),
+	}
+	addMetadata(record, s.Metadata)
+	return record
+}
+
+func InvoiceRecord(inv *invoice.Invoice) Record {
+	record := Record{
+		"id":               inv.ID,
+		"customer_id":      inv.CustomerID,
+		"subscription_id":  inv.SubscriptionID,
+		"invoice_type":     string(inv.InvoiceType),
+		"invoice_status":   string(inv.InvoiceStatus),
+		"payment_status":   string(inv.PaymentStatus),
+		"currency":         inv.Currency,
+		"total":            inv.Total.String(),
+		"amount_due":       inv.AmountDue.String(),
+		"amount_paid":      inv.AmountPaid.String(),
+		"amount_remaining": inv.AmountRemaining.String(),
+		"period_start":     formatTime(inv.PeriodStart),
+		"period_end":       formatTime(inv.PeriodEnd),
+		"finalized_at":     formatTime(inv.FinalizedAt),
+		"created_at":       formatTime(&inv.CreatedAt),
+	}
+	if inv.InvoiceNumber != nil {
+		record["invoice_number"] = *inv.InvoiceNumber
+	}
+	addMetadata(record, inv.Metadata)
+	return record
+}
+
+func InvoiceLineItemRecord(item *invoice.InvoiceLineItem) Record {
+	record := Record{
+		"id":              item.ID,
+		"invoice_id":      item.InvoiceID,
+		"customer_id":     item.CustomerID,
+		"subscription_id": item.SubscriptionID,
+		"price_id":        item.PriceID,
+		"meter_id":        item.MeterID,
+		"display_name":    item.DisplayName,
+		"currency":        item.Currency,
+		"amount":          item.Amount.String(),
+		"quantity":        item.Quantity.String(),
+		"period_start":    formatTime(item.PeriodStart),
+		"period_end":      formatTime(item.PeriodEnd),
+	}
+	addMetadata(record, item.Metadata)
+	return record
+}
+
+func addMetadata(record Record, metadata types.Metadata) {
+	for key, value := range metadata {
+		record[MetadataPrefix+key] = value
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// FormatTime formats a time as the values of the time fields
+func FormatTime(t time.Time) string {
+	return formatTime(&t)
+}
+
+func parseNumber(value string) (decimal.Decimal, bool) {
+	d, err := decimal.NewFromString(strings.TrimSpace(value))
+	return d, err == nil
+}
+
+// parseTime accepts RFC3339 times and dates
+func parseTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/repository/clickhouse/builder"
 	"github.com/flexprice/flexprice/internal/types"
 )
 
@@ -18,6 +19,10 @@ func GetAggregator(aggregationType types.AggregationType) events.Aggregator {
 		return &SumAggregator{}
 	case types.AggregationAvg:
 		return &AvgAggregator{}
+	case types.AggregationCountUnique:
+		return &CountUniqueAggregator{}
+	case types.AggregationPercentile:
+		return &PercentileAggregator{}
 	}
 	return nil
 }
@@ -131,12 +136,14 @@ func (a *SumAggregator) GetQuery(ctx context.Context, params *events.UsageParams
 	filterConditions := buildFilterConditions(params.Filters)
 	timeConditions := buildTimeConditions(params)
 
+	// Correction rows reversing an event have a sign of -1 and are subtracted
 	return fmt.Sprintf(`
         SELECT 
-            %s sum(value) as total
+            %s sum(value * sign) as total
         FROM (
             SELECT
-                %s anyLast(JSONExtractFloat(assumeNotNull(properties), '%s')) as value
+                %s anyLast(JSONExtractFloat(assumeNotNull(properties), '%s')) as value,
+                anyLast(sign) as sign
             FROM events
             PREWHERE event_name = '%s' 
                 AND tenant_id = '%s'
@@ -172,11 +179,15 @@ type CountAggregator struct{}
 func (a *CountAggregator) GetQuery(ctx context.Context, params *events.UsageParams) string {
 	windowSize := formatWindowSize(params.WindowSize)
 	selectClause := ""
+	windowClause := ""
 	groupByClause := ""
+	windowGroupBy := ""
 
 	if windowSize != "" {
-		selectClause = fmt.Sprintf("%s AS window_size,", windowSize)
+		selectClause = "window_size,"
+		windowClause = fmt.Sprintf("%s AS window_size,", windowSize)
 		groupByClause = "GROUP BY window_size ORDER BY window_size"
+		windowGroupBy = ", window_size"
 	}
 
 	externalCustomerFilter := ""
@@ -192,26 +203,35 @@ func (a *CountAggregator) GetQuery(ctx context.Context, params *events.UsagePara
 	filterConditions := buildFilterConditions(params.Filters)
 	timeConditions := buildTimeConditions(params)
 
+	// Each deduplicated event counts for its sign so that the correction rows
+	// reversing an event cancel it out
 	return fmt.Sprintf(`
         SELECT 
-            %s count(DISTINCT %s) as total
-        FROM events
-        PREWHERE event_name = '%s'
-            AND tenant_id = '%s'
-			%s
-			%s
-            %s
-            %s
+            %s toUInt64(greatest(sum(sign), 0)) as total
+        FROM (
+            SELECT
+                %s anyLast(sign) as sign
+            FROM events
+            PREWHERE event_name = '%s'
+                AND tenant_id = '%s'
+				%s
+				%s
+                %s
+                %s
+            GROUP BY %s %s
+        )
         %s
     `,
 		selectClause,
-		getDeduplicationKey(),
+		windowClause,
 		params.EventName,
 		types.GetTenantID(ctx),
 		externalCustomerFilter,
 		customerFilter,
 		filterConditions,
 		timeConditions,
+		getDeduplicationKey(),
+		windowGroupBy,
 		groupByClause)
 }
 
@@ -249,12 +269,15 @@ func (a *AvgAggregator) GetQuery(ctx context.Context, params *events.UsageParams
 	filterConditions := buildFilterConditions(params.Filters)
 	timeConditions := buildTimeConditions(params)
 
+	// The average is taken over the events left once the reversed ones are
+	// subtracted
 	return fmt.Sprintf(`
         SELECT 
-            %s avg(value) as total
+            %s if(sum(sign) > 0, sum(value * sign) / sum(sign), 0) as total
         FROM (
             SELECT
-                %s anyLast(JSONExtractFloat(assumeNotNull(properties), '%s')) as value
+                %s anyLast(JSONExtractFloat(assumeNotNull(properties), '%s')) as value,
+                anyLast(sign) as sign
             FROM events
             PREWHERE event_name = '%s' 
                 AND tenant_id = '%s'
@@ -284,6 +307,101 @@ func (a *AvgAggregator) GetType() types.AggregationType {
 	return types.AggregationAvg
 }
 
+// CountUniqueAggregator implements the count of distinct values of the field
+type CountUniqueAggregator struct{}
+
+func (a *CountUniqueAggregator) GetQuery(ctx context.Context, params *events.UsageParams) string {
+	return nettedValuesQuery(ctx, params, fmt.Sprintf("toUInt64(%s(value))", builder.UniqueFunction(params.Precision, "")))
+}
+
+func (a *CountUniqueAggregator) GetType() types.AggregationType {
+	return types.AggregationCountUnique
+}
+
+// PercentileAggregator implements the percentile of the values of the field
+type PercentileAggregator struct{}
+
+func (a *PercentileAggregator) GetQuery(ctx context.Context, params *events.UsageParams) string {
+	return nettedValuesQuery(ctx, params, fmt.Sprintf("%s(JSONExtract(value, 'Float64'))", builder.PercentileFunction(params.Percentile)))
+}
+
+func (a *PercentileAggregator) GetType() types.AggregationType {
+	return types.AggregationPercentile
+}
+
+// nettedValuesQuery aggregates the raw JSON values of the field that are not
+// reversed with the given aggregation over value. The values of each ingested
+// event are netted out with its correction rows first, so that a voided event
+// is left out and a corrected one counts with its corrected value only
+func nettedValuesQuery(ctx context.Context, params *events.UsageParams, aggregation string) string {
+	windowSize := formatWindowSize(params.WindowSize)
+	selectClause := ""
+	windowClause := ""
+	groupByClause := ""
+	windowGroupBy := ""
+
+	if windowSize != "" {
+		selectClause = "window_size,"
+		windowClause = fmt.Sprintf("%s AS window_size,", windowSize)
+		groupByClause = "GROUP BY window_size ORDER BY window_size"
+		windowGroupBy = ", window_size"
+	}
+
+	externalCustomerFilter := ""
+	if params.ExternalCustomerID != "" {
+		externalCustomerFilter = fmt.Sprintf("AND external_customer_id = '%s'", params.ExternalCustomerID)
+	}
+
+	customerFilter := ""
+	if params.CustomerID != "" {
+		customerFilter = fmt.Sprintf("AND customer_id = '%s'", params.CustomerID)
+	}
+
+	filterConditions := buildFilterConditions(params.Filters)
+	timeConditions := buildTimeConditions(params)
+
+	return fmt.Sprintf(`
+        SELECT 
+            %s %s as total
+        FROM (
+            SELECT %s value
+            FROM (
+                SELECT
+                    id, %s anyLast(JSONExtractRaw(assumeNotNull(properties), '%s')) as value,
+                    anyLast(correction_of) as correction_of,
+                    anyLast(sign) as sign
+                FROM events
+                PREWHERE event_name = '%s'
+                    AND tenant_id = '%s'
+                    %s
+                    %s
+                    %s
+                    %s
+                GROUP BY %s %s
+            )
+            WHERE value != ''
+            GROUP BY if(correction_of = '', id, correction_of), value %s
+            HAVING sum(sign) > 0
+        )
+        %s
+    `,
+		selectClause,
+		aggregation,
+		selectClause,
+		windowClause,
+		params.PropertyName,
+		params.EventName,
+		types.GetTenantID(ctx),
+		externalCustomerFilter,
+		customerFilter,
+		filterConditions,
+		timeConditions,
+		getDeduplicationKey(),
+		windowGroupBy,
+		windowGroupBy,
+		groupByClause)
+}
+
 // // buildFilterGroupsQuery builds a query that matches events to the most specific filter group
 // func buildFilterGroupsQuery(params *events.UsageWithFiltersParams) string {
 //     var queryBuilder strings.Builder
@@ -3,6 +3,7 @@ package builder
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -61,7 +62,8 @@ func (qb *QueryBuilder) WithBaseFilters(ctx context.Context, params *events.Usag
 		}
 	}
 
-	qb.baseQuery = fmt.Sprintf("base_events AS (SELECT id, timestamp, properties FROM events WHERE %s)",
+	// Copies of an event not merged yet by the ReplacingMergeTree are counted once
+	qb.baseQuery = fmt.Sprintf("base_events AS (SELECT id, timestamp, properties, correction_of, sign FROM events WHERE %s LIMIT 1 BY id)",
 		strings.Join(conditions, " AND "))
 
 	qb.params = params
@@ -115,6 +117,8 @@ func (qb *QueryBuilder) WithFilterGroups(ctx context.Context, groups []events.Fi
 			id,
 			timestamp,
 			properties,
+			correction_of,
+			sign,
 			arrayMap(x -> (
 				x.1,
 				x.2,
@@ -128,6 +132,8 @@ func (qb *QueryBuilder) WithFilterGroups(ctx context.Context, groups []events.Fi
 			id,
 			timestamp,
 			properties,
+			correction_of,
+			sign,
 			arrayJoin(group_matches) as matched_group,
 			matched_group.1 as group_id,
 			matched_group.2 as total_filters,
@@ -138,10 +144,12 @@ func (qb *QueryBuilder) WithFilterGroups(ctx context.Context, groups []events.Fi
 		SELECT
 			id,
 			properties,
+			correction_of,
+			sign,
 			argMax(group_id, (total_filters, group_id)) as best_match_group
 		FROM matched_events
 		WHERE matches = 1
-		GROUP BY id, properties
+		GROUP BY id, properties, correction_of, sign
 	)`
 
 	qb.filterGroups = groups
@@ -149,15 +157,23 @@ func (qb *QueryBuilder) WithFilterGroups(ctx context.Context, groups []events.Fi
 	return qb
 }
 
-func (qb *QueryBuilder) WithAggregation(ctx context.Context, aggType types.AggregationType, propertyName string) *QueryBuilder {
+func (qb *QueryBuilder) WithAggregation(ctx context.Context, params *events.UsageParams) *QueryBuilder {
+	switch params.AggregationType {
+	case types.AggregationCountUnique:
+		return qb.withNettedValues(fmt.Sprintf("toUInt64(%s(value))", UniqueFunction(params.Precision, "")), params.PropertyName)
+	case types.AggregationPercentile:
+		return qb.withNettedValues(fmt.Sprintf("%s(JSONExtract(value, 'Float64'))", PercentileFunction(params.Percentile)), params.PropertyName)
+	}
+
 	var aggClause string
-	switch aggType {
+	// Correction rows reversing an event have a sign of -1 and are subtracted
+	switch params.AggregationType {
 	case types.AggregationCount:
-		aggClause = "COUNT(*)"
+		aggClause = "toUInt64(greatest(SUM(sign), 0))"
 	case types.AggregationSum:
-		aggClause = fmt.Sprintf("SUM(CAST(JSONExtractString(properties, '%s') AS Float64))", propertyName)
+		aggClause = fmt.Sprintf("SUM(CAST(JSONExtractString(properties, '%s') AS Float64) * sign)", params.PropertyName)
 	case types.AggregationAvg:
-		aggClause = fmt.Sprintf("AVG(CAST(JSONExtractString(properties, '%s') AS Float64))", propertyName)
+		aggClause = fmt.Sprintf("if(SUM(sign) > 0, SUM(CAST(JSONExtractString(properties, '%s') AS Float64) * sign) / SUM(sign), 0)", params.PropertyName)
 	}
 
 	qb.finalQuery = fmt.Sprintf("SELECT best_match_group as filter_group_id, %s as value FROM best_matches GROUP BY best_match_group ORDER BY best_match_group", aggClause)
@@ -165,6 +181,21 @@ func (qb *QueryBuilder) WithAggregation(ctx context.Context, aggType types.Aggre
 	return qb
 }
 
+// withNettedValues aggregates the values of the field that are not reversed.
+// The values of an event and of its correction rows are netted out by their
+// sign first, so a voided or corrected value is not aggregated
+func (qb *QueryBuilder) withNettedValues(aggregation, propertyName string) *QueryBuilder {
+	qb.finalQuery = fmt.Sprintf(`SELECT best_match_group as filter_group_id, %s as value FROM (
+		SELECT best_match_group, JSONExtractRaw(properties, '%s') AS value
+		FROM best_matches
+		WHERE value != ''
+		GROUP BY best_match_group, if(correction_of = '', id, correction_of), value
+		HAVING SUM(sign) > 0
+	) GROUP BY best_match_group ORDER BY best_match_group`, aggregation, propertyName)
+
+	return qb
+}
+
 func (qb *QueryBuilder) Build() (string, map[string]interface{}) {
 	var ctes []string
 
@@ -216,6 +247,24 @@ func formatClickHouseDateTime(t time.Time) string {
 	return t.UTC().Format("2006-01-02 15:04:05.000")
 }
 
+// UniqueFunction returns the ClickHouse aggregate function counting distinct
+// values with the given precision, with the combinator (State, Merge) applied.
+// A zero precision counts exactly with uniqExact, others approximately with
+// the HyperLogLog sketch of uniqCombined, whose states merge across windows
+func UniqueFunction(precision int, combinator string) string {
+	if precision == 0 {
+		return "uniqExact" + combinator
+	}
+	return fmt.Sprintf("uniqCombined%s(%d)", combinator, precision)
+}
+
+// PercentileFunction returns the ClickHouse aggregate function computing the
+// given percentile, from 0 to 100. The quantile is exact so that usage billed
+// on it is reproducible
+func PercentileFunction(percentile float64) string {
+	return fmt.Sprintf("quantileExact(%s)", strconv.FormatFloat(percentile/100, 'f', -1, 64))
+}
+
 /*
 
 ---------Sample Query with Filter Groups---------------------------------------------
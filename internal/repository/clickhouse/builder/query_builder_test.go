@@ -199,7 +199,7 @@ func TestQueryBuilder_WithAggregation(t *testing.T) {
 			qb := NewQueryBuilder()
 			qb.WithBaseFilters(ctx, &events.UsageParams{EventName: "test"})
 			qb.WithFilterGroups(ctx, []events.FilterGroup{{ID: "1"}})
-			qb.WithAggregation(ctx, tt.aggType, tt.propertyName)
+			qb.WithAggregation(ctx, &events.UsageParams{AggregationType: tt.aggType, PropertyName: tt.propertyName})
 			sql, _ := qb.Build()
 			assert.Contains(t, sql, tt.wantSQL)
 		})
@@ -273,7 +273,10 @@ func TestQueryBuilder_CompleteFlow(t *testing.T) {
 			qb := NewQueryBuilder()
 			qb.WithBaseFilters(ctx, tt.params)
 			qb.WithFilterGroups(ctx, tt.groups)
-			qb.WithAggregation(ctx, tt.meterConfig.Aggregation.Type, tt.meterConfig.Aggregation.Field)
+			qb.WithAggregation(ctx, &events.UsageParams{
+				AggregationType: tt.meterConfig.Aggregation.Type,
+				PropertyName:    tt.meterConfig.Aggregation.Field,
+			})
 			sql, args := qb.Build()
 
 			// Check for presence of each CTE part without WITH prefix
@@ -2,11 +2,15 @@ package clickhouse
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
+	clickhouse_go "github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/flexprice/flexprice/internal/clickhouse"
 	"github.com/flexprice/flexprice/internal/domain/events"
 	"github.com/flexprice/flexprice/internal/logger"
@@ -25,40 +29,141 @@ func NewEventRepository(store *clickhouse.ClickHouseStore, logger *logger.Logger
 }
 
 func (r *EventRepository) InsertEvent(ctx context.Context, event *events.Event) error {
-	propertiesJSON, err := json.Marshal(event.Properties)
-	if err != nil {
-		return fmt.Errorf("marshal properties: %w", err)
+	return r.InsertEvents(ctx, []*events.Event{event})
+}
+
+func (r *EventRepository) InsertEvents(ctx context.Context, eventsList []*events.Event) error {
+	if len(eventsList) == 0 {
+		return nil
 	}
 
-	// Adding a layer to validate the event before inserting it
-	if err := event.Validate(); err != nil {
-		return fmt.Errorf("validate event: %w", err)
+	values := make([]string, 0, len(eventsList))
+	args := make([]interface{}, 0, len(eventsList)*10)
+	for _, event := range eventsList {
+		propertiesJSON, err := json.Marshal(event.Properties)
+		if err != nil {
+			return fmt.Errorf("marshal properties: %w", err)
+		}
+
+		// Adding a layer to validate the event before inserting it
+		if err := event.Validate(); err != nil {
+			return fmt.Errorf("validate event: %w", err)
+		}
+
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			event.ID,
+			event.ExternalCustomerID,
+			event.CustomerID,
+			event.TenantID,
+			event.EventName,
+			event.Timestamp,
+			event.Source,
+			string(propertiesJSON),
+			event.CorrectionOf,
+			event.GetSign(),
+		)
 	}
 
+	// A single insert is written as one block, the rows become visible together
 	query := `
 		INSERT INTO events (
-			id, external_customer_id, customer_id, tenant_id, event_name, timestamp, source, properties
-		) VALUES (
-			?, ?, ?, ?, ?, ?, ?, ?
-		)
-	`
+			id, external_customer_id, customer_id, tenant_id, event_name, timestamp, source, properties,
+			correction_of, sign
+		) VALUES ` + strings.Join(values, ", ")
+
+	// The same events inserted again, by a consumer redelivered a message it
+	// had stored before failing to commit its offset, get the same token and
+	// their block is dropped by the deduplication window of the table
+	ctx = clickhouse_go.Context(ctx, clickhouse_go.WithSettings(clickhouse_go.Settings{
+		"insert_deduplication_token": insertDeduplicationToken(eventsList),
+	}))
+
+	if err := r.store.GetConn().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("insert event: %w", err)
+	}
 
-	err = r.store.GetConn().Exec(ctx, query,
-		event.ID,
-		event.ExternalCustomerID,
-		event.CustomerID,
-		event.TenantID,
-		event.EventName,
-		event.Timestamp,
-		event.Source,
-		string(propertiesJSON),
-	)
+	return nil
+}
+
+// insertDeduplicationToken identifies an insert by the IDs of its events
+func insertDeduplicationToken(eventsList []*events.Event) string {
+	hash := sha256.New()
+	for _, event := range eventsList {
+		hash.Write([]byte(event.TenantID))
+		hash.Write([]byte{0})
+		hash.Write([]byte(event.ID))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (r *EventRepository) GetEventHistory(ctx context.Context, id string) ([]*events.Event, error) {
+	// Duplicates of a row not merged yet are dropped with LIMIT 1 BY id. The
+	// reversal of a correction sorts before its replacement
+	query := `
+		SELECT
+			id,
+			external_customer_id,
+			customer_id,
+			tenant_id,
+			event_name,
+			timestamp,
+			ingested_at,
+			source,
+			properties,
+			correction_of,
+			sign
+		FROM events
+		WHERE tenant_id = ? AND ((id = ? AND correction_of = '') OR correction_of = ?)
+		ORDER BY correction_of != '', ingested_at, sign
+		LIMIT 1 BY id`
 
+	rows, err := r.store.GetConn().Query(ctx, query, types.GetTenantID(ctx), id, id)
 	if err != nil {
-		return fmt.Errorf("insert event: %w", err)
+		return nil, fmt.Errorf("query event history: %w", err)
 	}
+	defer rows.Close()
 
-	return nil
+	var history []*events.Event
+	for rows.Next() {
+		var event events.Event
+		var propertiesJSON string
+
+		err := rows.Scan(
+			&event.ID,
+			&event.ExternalCustomerID,
+			&event.CustomerID,
+			&event.TenantID,
+			&event.EventName,
+			&event.Timestamp,
+			&event.IngestedAt,
+			&event.Source,
+			&propertiesJSON,
+			&event.CorrectionOf,
+			&event.Sign,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(propertiesJSON), &event.Properties); err != nil {
+			return nil, fmt.Errorf("unmarshal properties: %w", err)
+		}
+
+		history = append(history, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	// A history without the ingested event is not one of the tenant
+	if len(history) > 0 && history[0].CorrectionOf != "" {
+		return nil, nil
+	}
+
+	return history, nil
 }
 
 type UsageResult struct {
@@ -92,13 +197,13 @@ func (r *EventRepository) GetUsage(ctx context.Context, params *events.UsagePara
 			var value decimal.Decimal
 
 			switch params.AggregationType {
-			case types.AggregationCount:
+			case types.AggregationCount, types.AggregationCountUnique:
 				var countValue uint64
 				if err := rows.Scan(&windowSize, &countValue); err != nil {
 					return nil, fmt.Errorf("scan result: %w", err)
 				}
 				value = decimal.NewFromUint64(countValue)
-			case types.AggregationSum, types.AggregationAvg:
+			case types.AggregationSum, types.AggregationAvg, types.AggregationPercentile:
 				var floatValue float64
 				if err := rows.Scan(&windowSize, &floatValue); err != nil {
 					return nil, fmt.Errorf("scan result: %w", err)
@@ -117,13 +222,13 @@ func (r *EventRepository) GetUsage(ctx context.Context, params *events.UsagePara
 		// Non-windowed query - process single row
 		if rows.Next() {
 			switch params.AggregationType {
-			case types.AggregationCount:
+			case types.AggregationCount, types.AggregationCountUnique:
 				var value uint64
 				if err := rows.Scan(&value); err != nil {
 					return nil, fmt.Errorf("scan result: %w", err)
 				}
 				result.Value = decimal.NewFromUint64(value)
-			case types.AggregationSum, types.AggregationAvg:
+			case types.AggregationSum, types.AggregationAvg, types.AggregationPercentile:
 				var value float64
 				if err := rows.Scan(&value); err != nil {
 					return nil, fmt.Errorf("scan result: %w", err)
@@ -146,7 +251,7 @@ func (r *EventRepository) GetUsageWithFilters(ctx context.Context, params *event
 	// Build query using the new builder
 	qb := builder.NewQueryBuilder().
 		WithBaseFilters(ctx, params.UsageParams).
-		WithAggregation(ctx, params.AggregationType, params.PropertyName).
+		WithAggregation(ctx, params.UsageParams).
 		WithFilterGroups(ctx, params.FilterGroups)
 
 	query, queryParams := qb.Build()
@@ -175,13 +280,13 @@ func (r *EventRepository) GetUsageWithFilters(ctx context.Context, params *event
 
 		// Use appropriate type based on aggregation
 		switch params.AggregationType {
-		case types.AggregationCount:
+		case types.AggregationCount, types.AggregationCountUnique:
 			var value uint64
 			if err := rows.Scan(&filterGroupID, &value); err != nil {
 				return nil, fmt.Errorf("failed to scan count row: %w", err)
 			}
 			result.Value = decimal.NewFromUint64(value)
-		case types.AggregationSum, types.AggregationAvg:
+		case types.AggregationSum, types.AggregationAvg, types.AggregationPercentile:
 			var value float64
 			if err := rows.Scan(&filterGroupID, &value); err != nil {
 				return nil, fmt.Errorf("failed to scan float row: %w", err)
@@ -216,7 +321,7 @@ func (r *EventRepository) GetEvents(ctx context.Context, params *events.GetEvent
 			source,
 			properties
 		FROM events
-		WHERE tenant_id = ?
+		WHERE tenant_id = ? AND correction_of = ''
 	`
 	args := make([]interface{}, 0)
 	args = append(args, types.GetTenantID(ctx))
@@ -248,9 +353,9 @@ func (r *EventRepository) GetEvents(ctx context.Context, params *events.GetEvent
 		args = append(args, params.IterLast.Timestamp, params.IterLast.ID)
 	}
 
-	// Order by timestamp and ID
+	// Order by timestamp and ID, listing the copies of an event not merged yet once
 	baseQuery += " ORDER BY timestamp DESC, id DESC"
-	baseQuery += " LIMIT ?"
+	baseQuery += " LIMIT 1 BY id LIMIT ?"
 	args = append(args, params.PageSize+1)
 
 	// Execute query
@@ -288,3 +393,355 @@ func (r *EventRepository) GetEvents(ctx context.Context, params *events.GetEvent
 
 	return eventsList, nil
 }
+
+func (r *EventRepository) ExportEvents(ctx context.Context, params *events.ExportEventsParams) ([]*events.Event, error) {
+	query := `
+		SELECT 
+			id,
+			external_customer_id,
+			customer_id,
+			tenant_id,
+			event_name,
+			timestamp,
+			source,
+			properties
+		FROM events
+		WHERE tenant_id = ?
+		AND correction_of = ''
+		AND timestamp >= ?
+		AND timestamp < ?
+	`
+	args := []interface{}{types.GetTenantID(ctx), params.StartTime, params.EndTime}
+
+	if params.ExternalCustomerID != "" {
+		query += " AND external_customer_id = ?"
+		args = append(args, params.ExternalCustomerID)
+	}
+	if params.EventName != "" {
+		query += " AND event_name = ?"
+		args = append(args, params.EventName)
+	}
+	if params.After != nil {
+		query += " AND (timestamp, id) > (?, ?)"
+		args = append(args, params.After.Timestamp, params.After.ID)
+	}
+
+	// Ascending order keeps the cursor stable while new events are ingested
+	// at the end of the range
+	query += " ORDER BY timestamp ASC, id ASC LIMIT 1 BY id LIMIT ?"
+	args = append(args, params.Limit)
+
+	rows, err := r.store.GetConn().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+	defer rows.Close()
+
+	eventsList := make([]*events.Event, 0, params.Limit)
+	for rows.Next() {
+		var event events.Event
+		var propertiesJSON string
+
+		err := rows.Scan(
+			&event.ID,
+			&event.ExternalCustomerID,
+			&event.CustomerID,
+			&event.TenantID,
+			&event.EventName,
+			&event.Timestamp,
+			&event.Source,
+			&propertiesJSON,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(propertiesJSON), &event.Properties); err != nil {
+			return nil, fmt.Errorf("unmarshal properties: %w", err)
+		}
+
+		eventsList = append(eventsList, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return eventsList, nil
+}
+
+func (r *EventRepository) GetUsageByCustomer(ctx context.Context, params *events.UsageParams) ([]*events.CustomerUsage, error) {
+	windowSize := formatWindowSize(params.WindowSize)
+	if windowSize == "" {
+		return nil, fmt.Errorf("window size is required")
+	}
+
+	var query string
+	switch params.AggregationType {
+	case types.AggregationCount:
+		query = signedUsageByCustomerQuery(ctx, params, windowSize, "toFloat64(greatest(sum(sign), 0))")
+	case types.AggregationSum:
+		query = signedUsageByCustomerQuery(ctx, params, windowSize, "sum(value * sign)")
+	case types.AggregationAvg:
+		query = signedUsageByCustomerQuery(ctx, params, windowSize, "if(sum(sign) > 0, sum(value * sign) / sum(sign), 0)")
+	case types.AggregationCountUnique:
+		query = nettedUsageByCustomerQuery(ctx, params, windowSize,
+			fmt.Sprintf("toFloat64(%s(value))", builder.UniqueFunction(params.Precision, "")))
+	case types.AggregationPercentile:
+		query = nettedUsageByCustomerQuery(ctx, params, windowSize,
+			fmt.Sprintf("toFloat64(%s(JSONExtract(value, 'Float64')))", builder.PercentileFunction(params.Percentile)))
+	default:
+		return nil, fmt.Errorf("unsupported aggregation type: %s", params.AggregationType)
+	}
+
+	rows, err := r.store.GetConn().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*events.CustomerUsage
+	for rows.Next() {
+		var customerID string
+		var window time.Time
+		var value float64
+		if err := rows.Scan(&customerID, &window, &value); err != nil {
+			return nil, fmt.Errorf("scan result: %w", err)
+		}
+
+		if len(results) == 0 || results[len(results)-1].ExternalCustomerID != customerID {
+			results = append(results, &events.CustomerUsage{ExternalCustomerID: customerID})
+		}
+		current := results[len(results)-1]
+		current.Results = append(current.Results, events.UsageResult{
+			WindowSize: window,
+			Value:      decimal.NewFromFloat(value),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// signedUsageByCustomerQuery deduplicates events first, as the aggregators do,
+// then aggregates per customer and window with the given aggregation,
+// subtracting the reversed events
+func signedUsageByCustomerQuery(ctx context.Context, params *events.UsageParams, windowSize, aggregation string) string {
+	return fmt.Sprintf(`
+		SELECT external_customer_id, window_size, %s AS total
+		FROM (
+			SELECT
+				external_customer_id,
+				%s AS window_size,
+				anyLast(JSONExtractFloat(assumeNotNull(properties), '%s')) AS value,
+				anyLast(sign) AS sign
+			FROM events
+			PREWHERE event_name = '%s'
+				AND tenant_id = '%s'
+				%s
+				%s
+			GROUP BY %s, window_size
+		)
+		GROUP BY external_customer_id, window_size
+		ORDER BY external_customer_id, window_size
+	`,
+		aggregation,
+		windowSize,
+		params.PropertyName,
+		params.EventName,
+		types.GetTenantID(ctx),
+		buildFilterConditions(params.Filters),
+		buildTimeConditions(params),
+		getDeduplicationKey(),
+	)
+}
+
+// nettedUsageByCustomerQuery aggregates the values of the field per customer
+// and window with the given aggregation over value, netting each ingested event
+// out with its correction rows first as the aggregators of values do
+func nettedUsageByCustomerQuery(ctx context.Context, params *events.UsageParams, windowSize, aggregation string) string {
+	return fmt.Sprintf(`
+		SELECT external_customer_id, window_size, %s AS total
+		FROM (
+			SELECT external_customer_id, window_size, value
+			FROM (
+				SELECT
+					id,
+					external_customer_id,
+					%s AS window_size,
+					anyLast(JSONExtractRaw(assumeNotNull(properties), '%s')) AS value,
+					anyLast(correction_of) AS correction_of,
+					anyLast(sign) AS sign
+				FROM events
+				PREWHERE event_name = '%s'
+					AND tenant_id = '%s'
+					%s
+					%s
+				GROUP BY %s, window_size
+			)
+			WHERE value != ''
+			GROUP BY external_customer_id, window_size, if(correction_of = '', id, correction_of), value
+			HAVING sum(sign) > 0
+		)
+		GROUP BY external_customer_id, window_size
+		ORDER BY external_customer_id, window_size
+	`,
+		aggregation,
+		windowSize,
+		params.PropertyName,
+		params.EventName,
+		types.GetTenantID(ctx),
+		buildFilterConditions(params.Filters),
+		buildTimeConditions(params),
+		getDeduplicationKey(),
+	)
+}
+
+func (r *EventRepository) CountLateEvents(ctx context.Context, params *events.LateEventsParams) (uint64, error) {
+	query := `
+		SELECT count()
+		FROM events
+		WHERE tenant_id = ?
+		AND external_customer_id = ?
+		AND timestamp >= ?
+		AND timestamp < ?
+		AND ingested_at > ?
+		AND ingested_at <= ?
+	`
+	args := []interface{}{
+		types.GetTenantID(ctx),
+		params.ExternalCustomerID,
+		params.StartTime,
+		params.EndTime,
+		params.IngestedAfter,
+		params.IngestedBefore,
+	}
+
+	if len(params.EventNames) > 0 {
+		query += " AND event_name IN ?"
+		args = append(args, params.EventNames)
+	}
+
+	var count uint64
+	if err := r.store.GetConn().QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count late events: %w", err)
+	}
+	return count, nil
+}
+
+func (r *EventRepository) GetTenantIDs(ctx context.Context, since time.Time) ([]string, error) {
+	query := "SELECT DISTINCT tenant_id FROM events WHERE timestamp >= ?"
+
+	rows, err := r.store.GetConn().Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("query tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenantIDs []string
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, fmt.Errorf("scan tenant: %w", err)
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+
+	return tenantIDs, nil
+}
+
+func (r *EventRepository) DeleteEvents(ctx context.Context) error {
+	// Lightweight deletes mark the rows as deleted immediately and
+	// the parts are cleaned up in the background by ClickHouse
+	query := "DELETE FROM events WHERE tenant_id = ?"
+
+	if err := r.store.GetConn().Exec(ctx, query, types.GetTenantID(ctx)); err != nil {
+		return fmt.Errorf("delete events: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EventRepository) DeleteEventsBefore(ctx context.Context, before time.Time) error {
+	query := "DELETE FROM events WHERE tenant_id = ? AND timestamp < ?"
+
+	if err := r.store.GetConn().Exec(ctx, query, types.GetTenantID(ctx), before); err != nil {
+		return fmt.Errorf("delete events: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EventRepository) GetStorageByTenant(ctx context.Context) ([]*events.TenantStorage, error) {
+	query := `
+		SELECT
+			tenant_id,
+			count() AS rows,
+			sum(byteSize(id, tenant_id, external_customer_id, customer_id, event_name,
+				source, timestamp, ingested_at, properties)) AS bytes,
+			min(timestamp) AS oldest_event,
+			max(timestamp) AS newest_event
+		FROM events
+		GROUP BY tenant_id
+		ORDER BY tenant_id`
+
+	rows, err := r.store.GetConn().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query storage: %w", err)
+	}
+	defer rows.Close()
+
+	var storage []*events.TenantStorage
+	for rows.Next() {
+		var s events.TenantStorage
+		if err := rows.Scan(&s.TenantID, &s.Rows, &s.Bytes, &s.OldestEvent, &s.NewestEvent); err != nil {
+			return nil, fmt.Errorf("scan storage: %w", err)
+		}
+		storage = append(storage, &s)
+	}
+
+	return storage, nil
+}
+
+func (r *EventRepository) ListPartitions(ctx context.Context) ([]*events.Partition, error) {
+	query := `
+		SELECT partition_id, sum(rows), sum(bytes_on_disk)
+		FROM system.parts
+		WHERE active AND database = currentDatabase() AND table = 'events'
+		GROUP BY partition_id
+		ORDER BY partition_id`
+
+	rows, err := r.store.GetConn().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []*events.Partition
+	for rows.Next() {
+		var p events.Partition
+		if err := rows.Scan(&p.PartitionID, &p.Rows, &p.BytesOnDisk); err != nil {
+			return nil, fmt.Errorf("scan partition: %w", err)
+		}
+		partitions = append(partitions, &p)
+	}
+
+	return partitions, nil
+}
+
+func (r *EventRepository) DropPartition(ctx context.Context, partitionID string) error {
+	// The ID cannot be bound in DDL, it is checked to be a month before being inlined
+	if _, err := events.PartitionMonth(partitionID); err != nil {
+		return fmt.Errorf("invalid partition %q: %w", partitionID, err)
+	}
+
+	query := fmt.Sprintf("ALTER TABLE events DROP PARTITION ID '%s'", partitionID)
+	if err := r.store.GetConn().Exec(ctx, query); err != nil {
+		return fmt.Errorf("drop partition: %w", err)
+	}
+
+	return nil
+}
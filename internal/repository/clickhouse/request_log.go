@@ -0,0 +1,137 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/clickhouse"
+	"github.com/flexprice/flexprice/internal/domain/requestlog"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type RequestLogRepository struct {
+	store  *clickhouse.ClickHouseStore
+	logger *logger.Logger
+}
+
+func NewRequestLogRepository(store *clickhouse.ClickHouseStore, logger *logger.Logger) requestlog.Repository {
+	return &RequestLogRepository{store: store, logger: logger}
+}
+
+func (r *RequestLogRepository) Insert(ctx context.Context, log *requestlog.RequestLog) error {
+	query := `
+		INSERT INTO request_logs (
+			id, tenant_id, environment_id, api_key_id, request_id, method, path, endpoint,
+			status_code, latency_ms, request_body, response_body, timestamp
+		) VALUES (
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		)
+	`
+
+	err := r.store.GetConn().Exec(ctx, query,
+		log.ID,
+		log.TenantID,
+		log.EnvironmentID,
+		log.APIKeyID,
+		log.RequestID,
+		log.Method,
+		log.Path,
+		log.Endpoint,
+		int32(log.StatusCode),
+		log.LatencyMs,
+		log.RequestBody,
+		log.ResponseBody,
+		log.Timestamp,
+	)
+	if err != nil {
+		return fmt.Errorf("insert request log: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RequestLogRepository) List(ctx context.Context, filter *types.RequestLogFilter) ([]*requestlog.RequestLog, error) {
+	query := `
+		SELECT
+			id, tenant_id, environment_id, api_key_id, request_id, method, path, endpoint,
+			status_code, latency_ms, request_body, response_body, timestamp
+		FROM request_logs
+		WHERE tenant_id = ?
+	`
+	args := []interface{}{types.GetTenantID(ctx)}
+
+	if environmentID := types.GetEnvironmentID(ctx); environmentID != "" {
+		query += " AND environment_id = ?"
+		args = append(args, environmentID)
+	}
+	if filter.APIKeyID != "" {
+		query += " AND api_key_id = ?"
+		args = append(args, filter.APIKeyID)
+	}
+	if filter.Method != "" {
+		query += " AND method = ?"
+		args = append(args, filter.Method)
+	}
+	if filter.Endpoint != "" {
+		query += " AND endpoint = ?"
+		args = append(args, filter.Endpoint)
+	}
+	switch filter.Status {
+	case types.RequestLogStatusSucceeded:
+		query += " AND status_code < 400"
+	case types.RequestLogStatusFailed:
+		query += " AND status_code >= 400"
+	}
+	if filter.StatusCode != 0 {
+		query += " AND status_code = ?"
+		args = append(args, int32(filter.StatusCode))
+	}
+	if filter.StartTime != nil {
+		query += " AND timestamp >= ?"
+		args = append(args, *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query += " AND timestamp <= ?"
+		args = append(args, *filter.EndTime)
+	}
+
+	query += " ORDER BY timestamp DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.store.GetConn().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query request logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*requestlog.RequestLog
+	for rows.Next() {
+		var log requestlog.RequestLog
+		var statusCode int32
+
+		err := rows.Scan(
+			&log.ID,
+			&log.TenantID,
+			&log.EnvironmentID,
+			&log.APIKeyID,
+			&log.RequestID,
+			&log.Method,
+			&log.Path,
+			&log.Endpoint,
+			&statusCode,
+			&log.LatencyMs,
+			&log.RequestBody,
+			&log.ResponseBody,
+			&log.Timestamp,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan request log: %w", err)
+		}
+
+		log.StatusCode = int(statusCode)
+		logs = append(logs, &log)
+	}
+
+	return logs, nil
+}
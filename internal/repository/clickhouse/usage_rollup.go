@@ -0,0 +1,395 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/clickhouse"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/repository/clickhouse/builder"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+type UsageRollupRepository struct {
+	store  *clickhouse.ClickHouseStore
+	logger *logger.Logger
+}
+
+func NewUsageRollupRepository(store *clickhouse.ClickHouseStore, logger *logger.Logger) events.RollupRepository {
+	return &UsageRollupRepository{store: store, logger: logger}
+}
+
+func (r *UsageRollupRepository) RollUpUsage(ctx context.Context, params *events.RollupParams) error {
+	var windowStart string
+	switch params.WindowSize {
+	case types.WindowSizeHour:
+		windowStart = "toStartOfHour(timestamp, 'UTC')"
+	case types.WindowSizeDay:
+		windowStart = "toStartOfDay(timestamp, 'UTC')"
+	default:
+		return fmt.Errorf("unsupported rollup window size: %s", params.WindowSize)
+	}
+
+	if params.AggregationType == types.AggregationCountUnique {
+		return r.rollUpUniques(ctx, params, windowStart)
+	}
+
+	// Events are deduplicated within a window the same way the usage queries
+	// deduplicate them and the reversed events subtracted, the field is 0 for
+	// count meters
+	value := "0"
+	if params.PropertyName != "" {
+		value = "anyLast(JSONExtractFloat(assumeNotNull(properties), ?))"
+	}
+
+	// A zero start rolls up every window since the first event
+	startCondition := ""
+	if !params.StartTime.IsZero() {
+		startCondition = "AND timestamp >= ?"
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO usage_rollups (
+			tenant_id, meter_id, external_customer_id, customer_id,
+			window_size, window_start, event_count, value_sum
+		)
+		SELECT
+			tenant_id, ?, external_customer_id, customer_id,
+			?, window_start, toUInt64(greatest(sum(sign), 0)), sum(value * sign)
+		FROM (
+			SELECT
+				tenant_id, external_customer_id, customer_id,
+				%s AS window_start, %s AS value, anyLast(sign) AS sign
+			FROM events
+			PREWHERE event_name = ? AND tenant_id = ?
+			WHERE timestamp < ? %s
+			GROUP BY %s, window_start
+		)
+		GROUP BY tenant_id, external_customer_id, customer_id, window_start`,
+		windowStart, value, startCondition, getDeduplicationKey())
+
+	args := []interface{}{params.MeterID, string(params.WindowSize)}
+	if params.PropertyName != "" {
+		args = append(args, params.PropertyName)
+	}
+	args = append(args, params.EventName, types.GetTenantID(ctx), params.EndTime)
+	if !params.StartTime.IsZero() {
+		args = append(args, params.StartTime)
+	}
+
+	if err := r.store.GetConn().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("roll up usage: %w", err)
+	}
+
+	return nil
+}
+
+// rollUpUniques writes the state of the unique count of each window. Every
+// window with events gets a state, even when none of its values is counted
+// anymore, so that it replaces the previous computation of the window
+func (r *UsageRollupRepository) rollUpUniques(ctx context.Context, params *events.RollupParams, windowStart string) error {
+	startCondition := ""
+	if !params.StartTime.IsZero() {
+		startCondition = "AND timestamp >= ?"
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO usage_rollup_uniques (
+			tenant_id, meter_id, external_customer_id, customer_id,
+			window_size, window_start, state
+		)
+		SELECT
+			tenant_id, ?, external_customer_id, customer_id,
+			?, window_start, CAST(%s(%s) AS String)
+		FROM (
+			SELECT
+				tenant_id, external_customer_id, customer_id, window_start,
+				value, sum(sign) AS net
+			FROM (
+				SELECT
+					id, tenant_id, external_customer_id, customer_id,
+					%s AS window_start,
+					anyLast(JSONExtractRaw(assumeNotNull(properties), ?)) AS value,
+					anyLast(correction_of) AS correction_of,
+					anyLast(sign) AS sign
+				FROM events
+				PREWHERE event_name = ? AND tenant_id = ?
+				WHERE timestamp < ? %s
+				GROUP BY %s, window_start
+			)
+			GROUP BY tenant_id, external_customer_id, customer_id, window_start,
+				if(correction_of = '', id, correction_of), value
+		)
+		GROUP BY tenant_id, external_customer_id, customer_id, window_start`,
+		builder.UniqueFunction(params.Precision, "State"), uniqueValue, windowStart, startCondition, getDeduplicationKey())
+
+	args := []interface{}{params.MeterID, string(params.WindowSize), params.PropertyName,
+		params.EventName, types.GetTenantID(ctx), params.EndTime}
+	if !params.StartTime.IsZero() {
+		args = append(args, params.StartTime)
+	}
+
+	if err := r.store.GetConn().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("roll up unique values: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UsageRollupRepository) GetRollupWatermark(ctx context.Context, meterID string) (time.Time, error) {
+	query := `
+		SELECT rolled_up_until FROM usage_rollup_watermarks FINAL
+		WHERE tenant_id = ? AND meter_id = ?`
+
+	rows, err := r.store.GetConn().Query(ctx, query, types.GetTenantID(ctx), meterID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query rollup watermark: %w", err)
+	}
+	defer rows.Close()
+
+	var until time.Time
+	if rows.Next() {
+		if err := rows.Scan(&until); err != nil {
+			return time.Time{}, fmt.Errorf("scan rollup watermark: %w", err)
+		}
+	}
+
+	return until, nil
+}
+
+func (r *UsageRollupRepository) SetRollupWatermark(ctx context.Context, meterID string, until time.Time) error {
+	query := `
+		INSERT INTO usage_rollup_watermarks (tenant_id, meter_id, rolled_up_until)
+		VALUES (?, ?, ?)`
+
+	if err := r.store.GetConn().Exec(ctx, query, types.GetTenantID(ctx), meterID, until); err != nil {
+		return fmt.Errorf("set rollup watermark: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UsageRollupRepository) GetRolledUpUsage(ctx context.Context, params *events.RolledUpUsageParams) (*events.RolledUpUsage, error) {
+	result := &events.RolledUpUsage{ValueSum: decimal.Zero}
+	if len(params.Ranges) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT sum(event_count), sum(value_sum)
+		FROM usage_rollups FINAL
+		WHERE tenant_id = ? AND meter_id = ?`
+	args := []interface{}{types.GetTenantID(ctx), params.MeterID}
+
+	if params.ExternalCustomerID != "" {
+		query += " AND external_customer_id = ?"
+		args = append(args, params.ExternalCustomerID)
+	}
+	if params.CustomerID != "" {
+		query += " AND customer_id = ?"
+		args = append(args, params.CustomerID)
+	}
+
+	ranges := make([]string, len(params.Ranges))
+	for i, rng := range params.Ranges {
+		if rng.StartTime.IsZero() {
+			ranges[i] = "(window_size = ? AND window_start < ?)"
+			args = append(args, string(rng.WindowSize), rng.EndTime)
+			continue
+		}
+		ranges[i] = "(window_size = ? AND window_start >= ? AND window_start < ?)"
+		args = append(args, string(rng.WindowSize), rng.StartTime, rng.EndTime)
+	}
+	query += " AND (" + strings.Join(ranges, " OR ") + ")"
+
+	rows, err := r.store.GetConn().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query rolled up usage: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var count uint64
+		var sum float64
+		if err := rows.Scan(&count, &sum); err != nil {
+			return nil, fmt.Errorf("scan rolled up usage: %w", err)
+		}
+		result.EventCount = count
+		result.ValueSum = decimal.NewFromFloat(sum)
+	}
+
+	return result, nil
+}
+
+func (r *UsageRollupRepository) GetRolledUpWindows(ctx context.Context, params *events.RolledUpUsageParams) ([]*events.RolledUpWindow, error) {
+	if len(params.Ranges) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT window_size, window_start, sum(event_count), sum(value_sum)
+		FROM usage_rollups FINAL
+		WHERE tenant_id = ? AND meter_id = ?`
+	args := []interface{}{types.GetTenantID(ctx), params.MeterID}
+
+	if params.ExternalCustomerID != "" {
+		query += " AND external_customer_id = ?"
+		args = append(args, params.ExternalCustomerID)
+	}
+	if params.CustomerID != "" {
+		query += " AND customer_id = ?"
+		args = append(args, params.CustomerID)
+	}
+
+	ranges := make([]string, len(params.Ranges))
+	for i, rng := range params.Ranges {
+		if rng.StartTime.IsZero() {
+			ranges[i] = "(window_size = ? AND window_start < ?)"
+			args = append(args, string(rng.WindowSize), rng.EndTime)
+			continue
+		}
+		ranges[i] = "(window_size = ? AND window_start >= ? AND window_start < ?)"
+		args = append(args, string(rng.WindowSize), rng.StartTime, rng.EndTime)
+	}
+	query += " AND (" + strings.Join(ranges, " OR ") + ")"
+	query += " GROUP BY window_size, window_start ORDER BY window_start, window_size"
+
+	rows, err := r.store.GetConn().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query rolled up windows: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []*events.RolledUpWindow
+	for rows.Next() {
+		var size string
+		var start time.Time
+		var count uint64
+		var sum float64
+		if err := rows.Scan(&size, &start, &count, &sum); err != nil {
+			return nil, fmt.Errorf("scan rolled up window: %w", err)
+		}
+		windows = append(windows, &events.RolledUpWindow{
+			RolledUpUsage: events.RolledUpUsage{EventCount: count, ValueSum: decimal.NewFromFloat(sum)},
+			WindowSize:    types.WindowSize(size),
+			WindowStart:   start.UTC(),
+		})
+	}
+
+	return windows, nil
+}
+
+// uniqueValue is the value a unique count counts for a value netted out with
+// its sign, NULL when it is not counted. Rolled up states and the states of
+// the raw events are built from the same expression so that they merge
+const uniqueValue = "if(net > 0 AND value != '', value, NULL)"
+
+func (r *UsageRollupRepository) GetUniqueCount(ctx context.Context, params *events.UniqueCountParams) (uint64, error) {
+	stateType := fmt.Sprintf("AggregateFunction(%s, Nullable(String))", builder.UniqueFunction(params.Precision, ""))
+
+	customerConditions := ""
+	var customerArgs []interface{}
+	if params.ExternalCustomerID != "" {
+		customerConditions += " AND external_customer_id = ?"
+		customerArgs = append(customerArgs, params.ExternalCustomerID)
+	}
+	if params.CustomerID != "" {
+		customerConditions += " AND customer_id = ?"
+		customerArgs = append(customerArgs, params.CustomerID)
+	}
+
+	states := []string{}
+	var args []interface{}
+
+	if len(params.Ranges) > 0 {
+		ranges := make([]string, len(params.Ranges))
+		rangeArgs := []interface{}{}
+		for i, rng := range params.Ranges {
+			if rng.StartTime.IsZero() {
+				ranges[i] = "(window_size = ? AND window_start < ?)"
+				rangeArgs = append(rangeArgs, string(rng.WindowSize), rng.EndTime)
+				continue
+			}
+			ranges[i] = "(window_size = ? AND window_start >= ? AND window_start < ?)"
+			rangeArgs = append(rangeArgs, string(rng.WindowSize), rng.StartTime, rng.EndTime)
+		}
+
+		states = append(states, fmt.Sprintf(`
+			SELECT CAST(state, '%s') AS state
+			FROM usage_rollup_uniques FINAL
+			WHERE tenant_id = ? AND meter_id = ?%s AND (%s)`,
+			stateType, customerConditions, strings.Join(ranges, " OR ")))
+		args = append(args, types.GetTenantID(ctx), params.MeterID)
+		args = append(args, customerArgs...)
+		args = append(args, rangeArgs...)
+	}
+
+	if len(params.Raw) > 0 {
+		ranges := make([]string, len(params.Raw))
+		rangeArgs := []interface{}{}
+		for i, rng := range params.Raw {
+			var conditions []string
+			if !rng.StartTime.IsZero() {
+				conditions = append(conditions, "timestamp >= ?")
+				rangeArgs = append(rangeArgs, rng.StartTime)
+			}
+			if !rng.EndTime.IsZero() {
+				conditions = append(conditions, "timestamp < ?")
+				rangeArgs = append(rangeArgs, rng.EndTime)
+			}
+			if len(conditions) == 0 {
+				conditions = append(conditions, "1")
+			}
+			ranges[i] = "(" + strings.Join(conditions, " AND ") + ")"
+		}
+
+		states = append(states, fmt.Sprintf(`
+			SELECT %s(%s) AS state
+			FROM (
+				SELECT value, sum(sign) AS net
+				FROM (
+					SELECT
+						id,
+						anyLast(JSONExtractRaw(assumeNotNull(properties), ?)) AS value,
+						anyLast(correction_of) AS correction_of,
+						anyLast(sign) AS sign
+					FROM events
+					PREWHERE event_name = ? AND tenant_id = ?
+					WHERE (%s)%s
+					GROUP BY %s
+				)
+				GROUP BY if(correction_of = '', id, correction_of), value
+			)`,
+			builder.UniqueFunction(params.Precision, "State"), uniqueValue,
+			strings.Join(ranges, " OR "), customerConditions, getDeduplicationKey()))
+		args = append(args, params.PropertyName, params.EventName, types.GetTenantID(ctx))
+		args = append(args, rangeArgs...)
+		args = append(args, customerArgs...)
+	}
+
+	if len(states) == 0 {
+		return 0, nil
+	}
+
+	query := fmt.Sprintf("SELECT toUInt64(%s(state)) FROM (%s\n\t\t)",
+		builder.UniqueFunction(params.Precision, "Merge"), strings.Join(states, "\n\t\t\tUNION ALL"))
+
+	rows, err := r.store.GetConn().Query(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("query unique count: %w", err)
+	}
+	defer rows.Close()
+
+	var count uint64
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, fmt.Errorf("scan unique count: %w", err)
+		}
+	}
+
+	return count, nil
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/postgres"
 	"github.com/flexprice/flexprice/internal/types"
-	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -178,7 +177,7 @@ func (r *walletRepository) processWalletOperation(ctx context.Context, req *wall
 
 		// Create transaction record
 		txn, err := r.client.Querier(ctx).WalletTransaction.Create().
-			SetID(uuid.NewString()).
+			SetID(types.GenerateUUID()).
 			SetTenantID(types.GetTenantID(ctx)).
 			SetWalletID(req.WalletID).
 			SetType(string(req.Type)).
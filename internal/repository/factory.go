@@ -2,20 +2,51 @@ package repository
 
 import (
 	"github.com/flexprice/flexprice/internal/clickhouse"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/analytics"
+	"github.com/flexprice/flexprice/internal/domain/anomaly"
+	"github.com/flexprice/flexprice/internal/domain/audit"
 	"github.com/flexprice/flexprice/internal/domain/auth"
+	"github.com/flexprice/flexprice/internal/domain/budget"
+	"github.com/flexprice/flexprice/internal/domain/cancellationreason"
+	"github.com/flexprice/flexprice/internal/domain/connection"
 	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/deadletter"
+	"github.com/flexprice/flexprice/internal/domain/email"
+	"github.com/flexprice/flexprice/internal/domain/environment"
 	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/eventschema"
+	"github.com/flexprice/flexprice/internal/domain/export"
+	"github.com/flexprice/flexprice/internal/domain/featureflag"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/job"
+	"github.com/flexprice/flexprice/internal/domain/ledger"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/domain/mandate"
 	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
 	"github.com/flexprice/flexprice/internal/domain/plan"
 	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/domain/report"
+	"github.com/flexprice/flexprice/internal/domain/requestlog"
+	"github.com/flexprice/flexprice/internal/domain/retention"
+	"github.com/flexprice/flexprice/internal/domain/role"
+	"github.com/flexprice/flexprice/internal/domain/secret"
+	"github.com/flexprice/flexprice/internal/domain/sequence"
+	"github.com/flexprice/flexprice/internal/domain/sso"
 	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/domain/task"
 	"github.com/flexprice/flexprice/internal/domain/user"
 	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/domain/webhook"
 	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/postgres"
 	clickhouseRepo "github.com/flexprice/flexprice/internal/repository/clickhouse"
 	entRepo "github.com/flexprice/flexprice/internal/repository/ent"
 	postgresRepo "github.com/flexprice/flexprice/internal/repository/postgres"
+	"github.com/flexprice/flexprice/internal/types"
 	"go.uber.org/fx"
 )
 
@@ -29,10 +60,27 @@ type RepositoryParams struct {
 	ClickHouseDB *clickhouse.ClickHouseStore
 }
 
-func NewEventRepository(p RepositoryParams) events.Repository {
+// NewEventRepository returns the repository of the event store of the config
+func NewEventRepository(p RepositoryParams, cfg *config.Configuration) events.Repository {
+	if cfg.EventStore.Backend == types.EventStoreBackendTimescaleDB {
+		return postgresRepo.NewEventRepository(p.DB, p.Logger)
+	}
 	return clickhouseRepo.NewEventRepository(p.ClickHouseDB, p.Logger)
 }
 
+// NewUsageRollupRepository returns nil when the rollup job is disabled, usage
+// is then always read from the raw events
+func NewUsageRollupRepository(p RepositoryParams, cfg *config.Configuration) events.RollupRepository {
+	if !cfg.UsageRollup.Enabled {
+		return nil
+	}
+	return clickhouseRepo.NewUsageRollupRepository(p.ClickHouseDB, p.Logger)
+}
+
+func NewRequestLogRepository(p RepositoryParams) requestlog.Repository {
+	return clickhouseRepo.NewRequestLogRepository(p.ClickHouseDB, p.Logger)
+}
+
 func NewMeterRepository(p RepositoryParams) meter.Repository {
 	return postgresRepo.NewMeterRepository(p.DB, p.Logger)
 }
@@ -69,3 +117,127 @@ func NewWalletRepository(p RepositoryParams) wallet.Repository {
 	// Fallback to PostgreSQL implementation
 	return postgresRepo.NewWalletRepository(p.DB, p.Logger)
 }
+
+func NewAutoTopUpRepository(p RepositoryParams) wallet.AutoTopUpRepository {
+	return postgresRepo.NewAutoTopUpRepository(p.DB, p.Logger)
+}
+
+func NewCreditBucketRepository(p RepositoryParams) wallet.CreditBucketRepository {
+	return postgresRepo.NewCreditBucketRepository(p.DB, p.Logger)
+}
+
+func NewExportRepository(p RepositoryParams) export.Repository {
+	return postgresRepo.NewExportRepository(p.DB, p.Logger)
+}
+
+func NewTaskRepository(p RepositoryParams) task.Repository {
+	return postgresRepo.NewTaskRepository(p.DB, p.Logger)
+}
+
+func NewInvoiceRepository(p RepositoryParams) invoice.Repository {
+	return postgresRepo.NewInvoiceRepository(p.DB, p.Logger)
+}
+
+func NewSequenceRepository(p RepositoryParams) sequence.Repository {
+	return postgresRepo.NewSequenceRepository(p.DB, p.Logger)
+}
+
+func NewEnvironmentRepository(p RepositoryParams) environment.Repository {
+	return postgresRepo.NewEnvironmentRepository(p.DB, p.Logger)
+}
+
+func NewSecretRepository(p RepositoryParams) secret.Repository {
+	return postgresRepo.NewSecretRepository(p.DB, p.Logger)
+}
+
+func NewConnectionRepository(p RepositoryParams) connection.Repository {
+	return postgresRepo.NewConnectionRepository(p.DB, p.Logger)
+}
+
+func NewWebhookRepository(p RepositoryParams) webhook.Repository {
+	return postgresRepo.NewWebhookRepository(p.DB, p.Logger)
+}
+
+func NewCancellationReasonRepository(p RepositoryParams) cancellationreason.Repository {
+	return postgresRepo.NewCancellationReasonRepository(p.DB, p.Logger)
+}
+
+func NewEventSchemaRepository(p RepositoryParams) eventschema.Repository {
+	return postgresRepo.NewEventSchemaRepository(p.DB, p.Logger)
+}
+
+func NewDeadLetterRepository(p RepositoryParams) deadletter.Repository {
+	return postgresRepo.NewDeadLetterRepository(p.DB, p.Logger)
+}
+
+func NewRateCardRepository(p RepositoryParams) ratecard.Repository {
+	return postgresRepo.NewRateCardRepository(p.DB, p.Logger)
+}
+
+func NewPriceBookRepository(p RepositoryParams) pricebook.Repository {
+	return postgresRepo.NewPriceBookRepository(p.DB, p.Logger)
+}
+
+func NewBudgetRepository(p RepositoryParams) budget.Repository {
+	return postgresRepo.NewBudgetRepository(p.DB, p.Logger)
+}
+
+func NewAnomalyRepository(p RepositoryParams) anomaly.Repository {
+	return postgresRepo.NewAnomalyRepository(p.DB, p.Logger)
+}
+
+func NewRevenueSnapshotRepository(p RepositoryParams) analytics.Repository {
+	return postgresRepo.NewRevenueSnapshotRepository(p.DB, p.Logger)
+}
+
+func NewReceivablesSnapshotRepository(p RepositoryParams) analytics.ReceivablesRepository {
+	return postgresRepo.NewReceivablesSnapshotRepository(p.DB, p.Logger)
+}
+
+func NewReportRepository(p RepositoryParams) report.Repository {
+	return postgresRepo.NewReportRepository(p.DB, p.Logger)
+}
+
+func NewFeatureFlagRepository(p RepositoryParams) featureflag.Repository {
+	return postgresRepo.NewFeatureFlagRepository(p.DB, p.Logger)
+}
+
+func NewRetentionRepository(p RepositoryParams) retention.Repository {
+	return postgresRepo.NewRetentionRepository(p.DB, p.Logger)
+}
+
+func NewPaymentMethodRepository(p RepositoryParams) paymentmethod.Repository {
+	return postgresRepo.NewPaymentMethodRepository(p.DB, p.Logger)
+}
+
+func NewMandateRepository(p RepositoryParams) mandate.Repository {
+	return postgresRepo.NewMandateRepository(p.DB, p.Logger)
+}
+
+func NewLegalEntityRepository(p RepositoryParams) legalentity.Repository {
+	return postgresRepo.NewLegalEntityRepository(p.DB, p.Logger)
+}
+
+func NewJobRunRepository(p RepositoryParams) job.RunRepository {
+	return postgresRepo.NewJobRunRepository(p.DB, p.Logger)
+}
+
+func NewEmailRepository(p RepositoryParams) email.Repository {
+	return postgresRepo.NewEmailRepository(p.DB, p.Logger)
+}
+
+func NewLedgerRepository(p RepositoryParams) ledger.Repository {
+	return postgresRepo.NewLedgerRepository(p.DB, p.Logger)
+}
+
+func NewAuditLogRepository(p RepositoryParams) audit.Repository {
+	return postgresRepo.NewAuditLogRepository(p.DB, p.Logger)
+}
+
+func NewRoleAssignmentRepository(p RepositoryParams) role.Repository {
+	return postgresRepo.NewRoleAssignmentRepository(p.DB, p.Logger)
+}
+
+func NewSSORepository(p RepositoryParams) sso.Repository {
+	return postgresRepo.NewSSORepository(p.DB, p.Logger)
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/anomaly"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type anomalyRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewAnomalyRepository(db *postgres.DB, logger *logger.Logger) anomaly.Repository {
+	return &anomalyRepository{db: db, logger: logger}
+}
+
+func (r *anomalyRepository) Create(ctx context.Context, a *anomaly.Anomaly) error {
+	// A window is flagged at most once even if two workers race on it
+	query := `
+		INSERT INTO usage_anomalies (
+			id, tenant_id, meter_id, event_name, external_customer_id, anomaly_type,
+			window_start, window_end, value, baseline, std_dev, sigma,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :meter_id, :event_name, :external_customer_id, :anomaly_type,
+			:window_start, :window_end, :value, :baseline, :std_dev, :sigma,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)
+		ON CONFLICT (tenant_id, meter_id, external_customer_id, window_start) DO NOTHING`
+
+	r.logger.Debug("creating usage anomaly",
+		"anomaly_id", a.ID,
+		"tenant_id", a.TenantID,
+		"meter_id", a.MeterID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, a); err != nil {
+		return fmt.Errorf("failed to create usage anomaly: %w", err)
+	}
+	return nil
+}
+
+func (r *anomalyRepository) Exists(ctx context.Context, meterID, externalCustomerID string, windowStart time.Time) (bool, error) {
+	query := `
+		SELECT id FROM usage_anomalies
+		WHERE tenant_id = :tenant_id AND meter_id = :meter_id
+		AND external_customer_id = :external_customer_id AND window_start = :window_start`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":            types.GetTenantID(ctx),
+		"meter_id":             meterID,
+		"external_customer_id": externalCustomerID,
+		"window_start":         windowStart,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check usage anomaly: %w", err)
+	}
+	defer rows.Close()
+
+	return rows.Next(), nil
+}
+
+func (r *anomalyRepository) List(ctx context.Context, filter *types.AnomalyFilter) ([]*anomaly.Anomaly, error) {
+	query := `
+		SELECT * FROM usage_anomalies
+		WHERE tenant_id = :tenant_id AND status = :status
+	`
+	params := filter.ToMap()
+	params["tenant_id"] = types.GetTenantID(ctx)
+	params["status"] = types.StatusPublished
+
+	if filter.MeterID != "" {
+		query += " AND meter_id = :meter_id"
+	}
+	if filter.ExternalCustomerID != "" {
+		query += " AND external_customer_id = :external_customer_id"
+	}
+	if filter.AnomalyType != "" {
+		query += " AND anomaly_type = :anomaly_type"
+	}
+	if filter.StartTime != nil {
+		query += " AND window_start >= :start_time"
+	}
+	if filter.EndTime != nil {
+		query += " AND window_start < :end_time"
+	}
+
+	query += " ORDER BY window_start DESC, created_at DESC LIMIT :limit OFFSET :offset"
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	var anomalies []*anomaly.Anomaly
+	for rows.Next() {
+		var a anomaly.Anomaly
+		if err := rows.StructScan(&a); err != nil {
+			return nil, fmt.Errorf("failed to scan usage anomaly: %w", err)
+		}
+		anomalies = append(anomalies, &a)
+	}
+
+	return anomalies, nil
+}
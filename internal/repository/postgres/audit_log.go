@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/audit"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type auditLogRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewAuditLogRepository(db *postgres.DB, logger *logger.Logger) audit.Repository {
+	return &auditLogRepository{db: db, logger: logger}
+}
+
+func (r *auditLogRepository) Insert(ctx context.Context, log *audit.Log) error {
+	query := `
+		INSERT INTO audit_logs (
+			id, tenant_id, environment_id, entity_type, entity_id, action,
+			actor_type, actor_id, request_id, changes, created_at
+		) VALUES (
+			:id, :tenant_id, :environment_id, :entity_type, :entity_id, :action,
+			:actor_type, :actor_id, :request_id, :changes, :created_at
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, log); err != nil {
+		return fmt.Errorf("failed to insert audit log: %w", err)
+	}
+	return nil
+}
+
+func (r *auditLogRepository) List(ctx context.Context, filter *types.AuditLogFilter) ([]*audit.Log, error) {
+	query := `
+		SELECT * FROM audit_logs
+		WHERE tenant_id = :tenant_id
+	`
+	params := filter.ToMap()
+	params["tenant_id"] = types.GetTenantID(ctx)
+
+	if environmentID := types.GetEnvironmentID(ctx); environmentID != "" {
+		query += " AND environment_id = :environment_id"
+		params["environment_id"] = environmentID
+	}
+	if filter.EntityType != "" {
+		query += " AND entity_type = :entity_type"
+	}
+	if filter.EntityID != "" {
+		query += " AND entity_id = :entity_id"
+	}
+	if filter.Action != "" {
+		query += " AND action = :action"
+	}
+	if filter.ActorID != "" {
+		query += " AND actor_id = :actor_id"
+	}
+	if filter.StartTime != nil {
+		query += " AND created_at >= :start_time"
+	}
+	if filter.EndTime != nil {
+		query += " AND created_at < :end_time"
+	}
+
+	query += " ORDER BY created_at DESC LIMIT :limit OFFSET :offset"
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*audit.Log
+	for rows.Next() {
+		var log audit.Log
+		if err := rows.StructScan(&log); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		logs = append(logs, &log)
+	}
+
+	return logs, nil
+}
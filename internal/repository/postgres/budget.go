@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/budget"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type budgetRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewBudgetRepository(db *postgres.DB, logger *logger.Logger) budget.Repository {
+	return &budgetRepository{db: db, logger: logger}
+}
+
+func (r *budgetRepository) Create(ctx context.Context, b *budget.Budget) error {
+	query := `
+		INSERT INTO budgets (
+			id, tenant_id, name, customer_id, subscription_id, amount, currency,
+			period, action, spend, evaluated_at, exceeded_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :name, :customer_id, :subscription_id, :amount, :currency,
+			:period, :action, :spend, :evaluated_at, :exceeded_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating budget",
+		"budget_id", b.ID,
+		"customer_id", b.CustomerID,
+		"subscription_id", b.SubscriptionID,
+		"tenant_id", b.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, b); err != nil {
+		return fmt.Errorf("failed to create budget: %w", err)
+	}
+	return nil
+}
+
+func (r *budgetRepository) Get(ctx context.Context, id string) (*budget.Budget, error) {
+	var b budget.Budget
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM budgets WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("budget not found")
+	}
+
+	if err := rows.StructScan(&b); err != nil {
+		return nil, fmt.Errorf("failed to scan budget: %w", err)
+	}
+
+	return &b, nil
+}
+
+func (r *budgetRepository) ListByCustomer(ctx context.Context, customerID string) ([]*budget.Budget, error) {
+	return r.list(ctx, "AND customer_id = :customer_id", map[string]interface{}{
+		"customer_id": customerID,
+	})
+}
+
+func (r *budgetRepository) List(ctx context.Context) ([]*budget.Budget, error) {
+	return r.list(ctx, "", map[string]interface{}{})
+}
+
+func (r *budgetRepository) list(ctx context.Context, condition string, params map[string]interface{}) ([]*budget.Budget, error) {
+	var budgets []*budget.Budget
+	query := `
+		SELECT * FROM budgets
+		WHERE tenant_id = :tenant_id AND status = :status ` + condition + `
+		ORDER BY created_at ASC`
+
+	params["tenant_id"] = types.GetTenantID(ctx)
+	params["status"] = types.StatusPublished
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b budget.Budget
+		if err := rows.StructScan(&b); err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		budgets = append(budgets, &b)
+	}
+
+	return budgets, nil
+}
+
+func (r *budgetRepository) Update(ctx context.Context, b *budget.Budget) error {
+	query := `
+		UPDATE budgets SET
+			name = :name,
+			amount = :amount,
+			action = :action,
+			spend = :spend,
+			evaluated_at = :evaluated_at,
+			exceeded_at = :exceeded_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id AND status = :status`
+
+	b.UpdatedAt = time.Now().UTC()
+	b.UpdatedBy = types.GetUserID(ctx)
+
+	result, err := r.db.NamedExecContext(ctx, query, b)
+	if err != nil {
+		return fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("budget not found")
+	}
+	return nil
+}
+
+func (r *budgetRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE budgets SET
+			status = :status,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("deleting budget",
+		"budget_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_by": types.GetUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/cancellationreason"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type cancellationReasonRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewCancellationReasonRepository(db *postgres.DB, logger *logger.Logger) cancellationreason.Repository {
+	return &cancellationReasonRepository{db: db, logger: logger}
+}
+
+func (r *cancellationReasonRepository) Create(ctx context.Context, reason *cancellationreason.CancellationReason) error {
+	query := `
+		INSERT INTO cancellation_reasons (
+			id, tenant_id, code, name, description,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :code, :name, :description,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating cancellation reason",
+		"cancellation_reason_id", reason.ID,
+		"code", reason.Code,
+		"tenant_id", reason.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, reason); err != nil {
+		return fmt.Errorf("failed to create cancellation reason: %w", err)
+	}
+	return nil
+}
+
+func (r *cancellationReasonRepository) Get(ctx context.Context, id string) (*cancellationreason.CancellationReason, error) {
+	var reason cancellationreason.CancellationReason
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM cancellation_reasons WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cancellation reason: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("cancellation reason not found")
+	}
+
+	if err := rows.StructScan(&reason); err != nil {
+		return nil, fmt.Errorf("failed to scan cancellation reason: %w", err)
+	}
+
+	return &reason, nil
+}
+
+func (r *cancellationReasonRepository) List(ctx context.Context) ([]*cancellationreason.CancellationReason, error) {
+	var reasons []*cancellationreason.CancellationReason
+	query := `
+		SELECT * FROM cancellation_reasons WHERE tenant_id = :tenant_id AND status = :status ORDER BY code ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cancellation reasons: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reason cancellationreason.CancellationReason
+		if err := rows.StructScan(&reason); err != nil {
+			return nil, fmt.Errorf("failed to scan cancellation reason: %w", err)
+		}
+		reasons = append(reasons, &reason)
+	}
+
+	return reasons, nil
+}
+
+func (r *cancellationReasonRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE cancellation_reasons SET
+			status = :status,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("deleting cancellation reason",
+		"cancellation_reason_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_by": types.GetUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to delete cancellation reason: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/lib/pq"
+)
+
+type connectionRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewConnectionRepository(db *postgres.DB, logger *logger.Logger) connection.Repository {
+	return &connectionRepository{db: db, logger: logger}
+}
+
+func (r *connectionRepository) Create(ctx context.Context, conn *connection.Connection) error {
+	query := `
+		INSERT INTO connections (
+			id, tenant_id, name, provider, rate_limit_per_second, ledger_settings, crm_settings, gateway_settings, tax_settings,
+			credentials, webhook_secret,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :name, :provider, :rate_limit_per_second, :ledger_settings, :crm_settings, :gateway_settings, :tax_settings,
+			:credentials, :webhook_secret,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating connection",
+		"connection_id", conn.ID,
+		"tenant_id", conn.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, conn); err != nil {
+		return fmt.Errorf("failed to create connection: %w", err)
+	}
+	return nil
+}
+
+func (r *connectionRepository) Get(ctx context.Context, id string) (*connection.Connection, error) {
+	var conn connection.Connection
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM connections WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("connection not found")
+	}
+
+	if err := rows.StructScan(&conn); err != nil {
+		return nil, fmt.Errorf("failed to scan connection: %w", err)
+	}
+
+	return &conn, nil
+}
+
+func (r *connectionRepository) List(ctx context.Context, filter types.Filter) ([]*connection.Connection, error) {
+	var conns []*connection.Connection
+	query := `
+		SELECT * FROM connections WHERE tenant_id = :tenant_id AND status = :status ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var conn connection.Connection
+		if err := rows.StructScan(&conn); err != nil {
+			return nil, fmt.Errorf("failed to scan connection: %w", err)
+		}
+		conns = append(conns, &conn)
+	}
+
+	return conns, nil
+}
+
+func (r *connectionRepository) ListByProviders(ctx context.Context, providers []types.IntegrationProvider) ([]*connection.Connection, error) {
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = string(p)
+	}
+
+	query := `
+		SELECT * FROM connections
+		WHERE tenant_id = :tenant_id AND status = :status AND provider = ANY(:providers)
+		ORDER BY created_at`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+		"providers": pq.Array(names),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+	defer rows.Close()
+
+	var conns []*connection.Connection
+	for rows.Next() {
+		var conn connection.Connection
+		if err := rows.StructScan(&conn); err != nil {
+			return nil, fmt.Errorf("failed to scan connection: %w", err)
+		}
+		conns = append(conns, &conn)
+	}
+
+	return conns, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/postgres"
 	"github.com/flexprice/flexprice/internal/types"
+	"github.com/lib/pq"
 )
 
 type customerRepository struct {
@@ -23,9 +24,11 @@ func NewCustomerRepository(db *postgres.DB, logger *logger.Logger) customer.Repo
 func (r *customerRepository) Create(ctx context.Context, customer *customer.Customer) error {
 	query := `
 		INSERT INTO customers (
-			id, tenant_id, external_id, name, email, created_at, updated_at, created_by, updated_by
+			id, tenant_id, external_id, name, email, payment_method_id, consolidate_invoices,
+			billing_address, shipping_address, tax_ids, legal_entity_id, timezone, metadata, created_at, updated_at, created_by, updated_by
 		) VALUES (
-			:id, :tenant_id, :external_id, :name, :email, :created_at, :updated_at, :created_by, :updated_by
+			:id, :tenant_id, :external_id, :name, :email, :payment_method_id, :consolidate_invoices,
+			:billing_address, :shipping_address, :tax_ids, :legal_entity_id, :timezone, :metadata, :created_at, :updated_at, :created_by, :updated_by
 		)`
 
 	r.logger.Debug("creating customer",
@@ -39,7 +42,7 @@ func (r *customerRepository) Create(ctx context.Context, customer *customer.Cust
 
 func (r *customerRepository) Get(ctx context.Context, id string) (*customer.Customer, error) {
 	var c customer.Customer
-	rows, err := r.db.NamedQueryContext(ctx, "SELECT * FROM customers WHERE id = :id AND tenant_id = :tenant_id", map[string]interface{}{
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM customers WHERE id = :id AND tenant_id = :tenant_id", map[string]interface{}{
 		"id":        id,
 		"tenant_id": types.GetTenantID(ctx),
 	})
@@ -59,15 +62,53 @@ func (r *customerRepository) Get(ctx context.Context, id string) (*customer.Cust
 	return &c, nil
 }
 
-func (r *customerRepository) List(ctx context.Context, filter types.Filter) ([]*customer.Customer, error) {
+func (r *customerRepository) ListByExternalIDs(ctx context.Context, externalIDs []string) ([]*customer.Customer, error) {
 	var customers []*customer.Customer
+	if len(externalIDs) == 0 {
+		return customers, nil
+	}
+
 	query := `
-		SELECT * FROM customers WHERE tenant_id = :tenant_id ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+		SELECT * FROM customers
+		WHERE tenant_id = :tenant_id AND status = :status AND external_id = ANY(:external_ids)
+		ORDER BY created_at`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":    types.GetTenantID(ctx),
+		"status":       types.StatusPublished,
+		"external_ids": pq.Array(externalIDs),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
+	defer rows.Close()
 
-	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
-		"tenant_id": types.GetTenantID(ctx),
-		"limit":     filter.Limit,
-		"offset":    filter.Offset,
+	for rows.Next() {
+		var c customer.Customer
+		if err := rows.StructScan(&c); err != nil {
+			return nil, fmt.Errorf("failed to scan customer: %w", err)
+		}
+		customers = append(customers, &c)
+	}
+
+	return customers, nil
+}
+
+func (r *customerRepository) List(ctx context.Context, filter types.Filter) ([]*customer.Customer, error) {
+	var customers []*customer.Customer
+	query := `
+		SELECT * FROM customers
+		WHERE tenant_id = :tenant_id
+		AND (status = :status OR (:include_deleted AND status = :deleted_status))
+		ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":       types.GetTenantID(ctx),
+		"status":          types.StatusPublished,
+		"include_deleted": filter.IncludeDeleted,
+		"deleted_status":  types.StatusDeleted,
+		"limit":           filter.Limit,
+		"offset":          filter.Offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list customers: %w", err)
@@ -91,6 +132,16 @@ func (r *customerRepository) Update(ctx context.Context, customer *customer.Cust
 			external_id = :external_id,
 			name = :name,
 			email = :email,
+			payment_method_id = :payment_method_id,
+			consolidate_invoices = :consolidate_invoices,
+			billing_address = :billing_address,
+			shipping_address = :shipping_address,
+			tax_ids = :tax_ids,
+			legal_entity_id = :legal_entity_id,
+			collections_paused_until = :collections_paused_until,
+			collections_pause_reason = :collections_pause_reason,
+			timezone = :timezone,
+			metadata = :metadata,
 			updated_at = :updated_at,
 			updated_by = :updated_by
 		WHERE id = :id AND tenant_id = :tenant_id`
@@ -108,6 +159,7 @@ func (r *customerRepository) Delete(ctx context.Context, id string) error {
 	query := `
 		UPDATE customers SET
 			status = :status,
+			deleted_at = :updated_at,
 			updated_at = :updated_at,
 			updated_by = :updated_by
 		WHERE id = :id AND tenant_id = :tenant_id`
@@ -126,3 +178,49 @@ func (r *customerRepository) Delete(ctx context.Context, id string) error {
 	})
 	return err
 }
+
+func (r *customerRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE customers SET
+			status = :status,
+			deleted_at = NULL,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id AND status = :deleted_status`
+
+	r.logger.Debug("restoring customer",
+		"customer_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	_, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":             id,
+		"tenant_id":      types.GetTenantID(ctx),
+		"status":         types.StatusPublished,
+		"deleted_status": types.StatusDeleted,
+		"updated_by":     types.GetUserID(ctx),
+		"updated_at":     time.Now().UTC(),
+	})
+	return err
+}
+
+func (r *customerRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM customers c
+		WHERE c.status = :status
+		AND c.deleted_at < :before
+		AND NOT EXISTS (SELECT 1 FROM subscriptions s WHERE s.tenant_id = c.tenant_id AND s.customer_id = c.id)
+		AND NOT EXISTS (SELECT 1 FROM invoices i WHERE i.tenant_id = c.tenant_id AND i.customer_id = c.id)
+		AND NOT EXISTS (SELECT 1 FROM wallets w WHERE w.tenant_id = c.tenant_id AND w.customer_id = c.id)
+		AND NOT EXISTS (SELECT 1 FROM payment_methods pm WHERE pm.tenant_id = c.tenant_id AND pm.customer_id = c.id::text)
+		AND NOT EXISTS (SELECT 1 FROM rate_cards rc WHERE rc.tenant_id = c.tenant_id AND rc.customer_id = c.id::text)`
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"status": types.StatusDeleted,
+		"before": before,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted customers: %w", err)
+	}
+	return result.RowsAffected()
+}
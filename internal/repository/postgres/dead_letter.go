@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/deadletter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type deadLetterRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewDeadLetterRepository(db *postgres.DB, logger *logger.Logger) deadletter.Repository {
+	return &deadLetterRepository{db: db, logger: logger}
+}
+
+func (r *deadLetterRepository) Record(ctx context.Context, deadLetter *deadletter.DeadLetter) error {
+	query := `
+		INSERT INTO event_dead_letters (
+			id, tenant_id, message_id, topic, payload, error, attempts,
+			dead_letter_status, last_failed_at, replayed_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :message_id, :topic, :payload, :error, :attempts,
+			:dead_letter_status, :last_failed_at, :replayed_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)
+		ON CONFLICT (tenant_id, topic, message_id) DO UPDATE SET
+			payload = EXCLUDED.payload,
+			error = EXCLUDED.error,
+			attempts = event_dead_letters.attempts + 1,
+			dead_letter_status = EXCLUDED.dead_letter_status,
+			last_failed_at = EXCLUDED.last_failed_at,
+			replayed_at = NULL,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by`
+
+	r.logger.Debug("recording dead letter",
+		"message_id", deadLetter.MessageID,
+		"topic", deadLetter.Topic,
+		"tenant_id", deadLetter.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, deadLetter); err != nil {
+		return fmt.Errorf("failed to record dead letter: %w", err)
+	}
+	return nil
+}
+
+func (r *deadLetterRepository) Get(ctx context.Context, id string) (*deadletter.DeadLetter, error) {
+	deadLetters, err := r.list(ctx, "SELECT * FROM event_dead_letters WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(deadLetters) == 0 {
+		return nil, fmt.Errorf("dead letter not found")
+	}
+	return deadLetters[0], nil
+}
+
+func (r *deadLetterRepository) List(ctx context.Context, filter *types.DeadLetterFilter) ([]*deadletter.DeadLetter, error) {
+	query := `
+		SELECT * FROM event_dead_letters
+		WHERE tenant_id = :tenant_id AND status = :status
+	`
+	params := filter.ToMap()
+	params["tenant_id"] = types.GetTenantID(ctx)
+	params["status"] = types.StatusPublished
+
+	if filter.DeadLetterStatus != "" {
+		query += " AND dead_letter_status = :dead_letter_status"
+	}
+	if filter.Topic != "" {
+		query += " AND topic = :topic"
+	}
+
+	query += " ORDER BY last_failed_at DESC LIMIT :limit OFFSET :offset"
+
+	return r.list(ctx, query, params)
+}
+
+func (r *deadLetterRepository) list(ctx context.Context, query string, params map[string]interface{}) ([]*deadletter.DeadLetter, error) {
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var deadLetters []*deadletter.DeadLetter
+	for rows.Next() {
+		var deadLetter deadletter.DeadLetter
+		if err := rows.StructScan(&deadLetter); err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		deadLetters = append(deadLetters, &deadLetter)
+	}
+
+	return deadLetters, nil
+}
+
+func (r *deadLetterRepository) Update(ctx context.Context, deadLetter *deadletter.DeadLetter) error {
+	query := `
+		UPDATE event_dead_letters SET
+			dead_letter_status = :dead_letter_status,
+			replayed_at = :replayed_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating dead letter",
+		"dead_letter_id", deadLetter.ID,
+		"tenant_id", deadLetter.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, deadLetter); err != nil {
+		return fmt.Errorf("failed to update dead letter: %w", err)
+	}
+	return nil
+}
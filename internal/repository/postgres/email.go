@@ -0,0 +1,287 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/email"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type emailRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewEmailRepository(db *postgres.DB, logger *logger.Logger) email.Repository {
+	return &emailRepository{db: db, logger: logger}
+}
+
+func (r *emailRepository) UpsertTemplate(ctx context.Context, template *email.Template) error {
+	query := `
+		INSERT INTO email_templates (
+			id, tenant_id, template_type, subject, body_html, body_text,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :template_type, :subject, :body_html, :body_text,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)
+		ON CONFLICT (tenant_id, template_type) DO UPDATE SET
+			subject = EXCLUDED.subject,
+			body_html = EXCLUDED.body_html,
+			body_text = EXCLUDED.body_text,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by
+		RETURNING id, created_at, created_by`
+
+	r.logger.Debug("upserting email template",
+		"tenant_id", template.TenantID,
+		"template_type", template.TemplateType,
+	)
+
+	rows, err := r.db.NamedQueryContext(ctx, query, template)
+	if err != nil {
+		return fmt.Errorf("failed to upsert email template: %w", err)
+	}
+	defer rows.Close()
+
+	// The template keeps its identity when replaced
+	if rows.Next() {
+		if err := rows.Scan(&template.ID, &template.CreatedAt, &template.CreatedBy); err != nil {
+			return fmt.Errorf("failed to scan email template: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *emailRepository) GetTemplate(ctx context.Context, templateType types.EmailTemplateType) (*email.Template, error) {
+	query := `
+		SELECT * FROM email_templates
+		WHERE tenant_id = :tenant_id AND template_type = :template_type AND status = :status`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":     types.GetTenantID(ctx),
+		"template_type": templateType,
+		"status":        types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email template: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("email template not found")
+	}
+
+	var template email.Template
+	if err := rows.StructScan(&template); err != nil {
+		return nil, fmt.Errorf("failed to scan email template: %w", err)
+	}
+
+	return &template, nil
+}
+
+func (r *emailRepository) ListTemplates(ctx context.Context) ([]*email.Template, error) {
+	query := `
+		SELECT * FROM email_templates
+		WHERE tenant_id = :tenant_id AND status = :status
+		ORDER BY template_type`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*email.Template
+	for rows.Next() {
+		var template email.Template
+		if err := rows.StructScan(&template); err != nil {
+			return nil, fmt.Errorf("failed to scan email template: %w", err)
+		}
+		templates = append(templates, &template)
+	}
+
+	return templates, nil
+}
+
+func (r *emailRepository) DeleteTemplate(ctx context.Context, templateType types.EmailTemplateType) error {
+	query := `DELETE FROM email_templates WHERE tenant_id = :tenant_id AND template_type = :template_type`
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"tenant_id":     types.GetTenantID(ctx),
+		"template_type": templateType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete email template: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("email template not found")
+	}
+
+	return nil
+}
+
+func (r *emailRepository) CreateDelivery(ctx context.Context, delivery *email.Delivery) error {
+	query := `
+		INSERT INTO email_deliveries (
+			id, tenant_id, template_type, recipient, subject, body_html, body_text,
+			entity_type, entity_id, resent_from, delivery_status, attempts,
+			next_attempt_at, last_attempt_at, last_error, provider, provider_message_id, sent_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :template_type, :recipient, :subject, :body_html, :body_text,
+			:entity_type, :entity_id, :resent_from, :delivery_status, :attempts,
+			:next_attempt_at, :last_attempt_at, :last_error, :provider, :provider_message_id, :sent_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating email delivery",
+		"delivery_id", delivery.ID,
+		"tenant_id", delivery.TenantID,
+		"template_type", delivery.TemplateType,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, delivery); err != nil {
+		return fmt.Errorf("failed to create email delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *emailRepository) GetDelivery(ctx context.Context, id string) (*email.Delivery, error) {
+	var delivery email.Delivery
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM email_deliveries WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email delivery: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("email delivery not found")
+	}
+
+	if err := rows.StructScan(&delivery); err != nil {
+		return nil, fmt.Errorf("failed to scan email delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+func (r *emailRepository) ListDeliveries(ctx context.Context, filter *types.EmailDeliveryFilter) ([]*email.Delivery, error) {
+	query := `
+		SELECT * FROM email_deliveries
+		WHERE tenant_id = :tenant_id AND status = :status
+	`
+	params := filter.ToMap()
+	params["tenant_id"] = types.GetTenantID(ctx)
+	params["status"] = types.StatusPublished
+
+	if filter.DeliveryStatus != "" {
+		query += " AND delivery_status = :delivery_status"
+	}
+	if filter.TemplateType != "" {
+		query += " AND template_type = :template_type"
+	}
+	if filter.EntityID != "" {
+		query += " AND entity_id = :entity_id"
+	}
+
+	query += " ORDER BY created_at DESC LIMIT :limit OFFSET :offset"
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*email.Delivery
+	for rows.Next() {
+		var delivery email.Delivery
+		if err := rows.StructScan(&delivery); err != nil {
+			return nil, fmt.Errorf("failed to scan email delivery: %w", err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (r *emailRepository) UpdateDelivery(ctx context.Context, delivery *email.Delivery) error {
+	query := `
+		UPDATE email_deliveries SET
+			delivery_status = :delivery_status,
+			attempts = :attempts,
+			next_attempt_at = :next_attempt_at,
+			last_attempt_at = :last_attempt_at,
+			last_error = :last_error,
+			provider = :provider,
+			provider_message_id = :provider_message_id,
+			sent_at = :sent_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating email delivery",
+		"delivery_id", delivery.ID,
+		"tenant_id", delivery.TenantID,
+		"delivery_status", delivery.DeliveryStatus,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, delivery); err != nil {
+		return fmt.Errorf("failed to update email delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *emailRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*email.Delivery, error) {
+	// Deliberately not tenant scoped: the sender works across all tenants
+	query := `
+		UPDATE email_deliveries SET next_attempt_at = :lease_until
+		WHERE id IN (
+			SELECT id FROM email_deliveries
+			WHERE delivery_status = :delivery_status AND status = :status AND next_attempt_at <= :now
+			ORDER BY next_attempt_at
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"lease_until":     now.Add(lease),
+		"delivery_status": types.EmailDeliveryStatusPending,
+		"status":          types.StatusPublished,
+		"now":             now,
+		"limit":           limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim email deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*email.Delivery
+	for rows.Next() {
+		var delivery email.Delivery
+		if err := rows.StructScan(&delivery); err != nil {
+			return nil, fmt.Errorf("failed to scan email delivery: %w", err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// resetStepTables are the tables wiped for each reset step, children first so
+// that their foreign keys to the parent tables are not violated
+var resetStepTables = map[string][]string{
+	"invoices": {
+		"invoice_disputes",
+		"refunds",
+		"invoice_payments",
+		"credit_allocations",
+		"invoice_line_items",
+		"invoices",
+	},
+	"wallets": {
+		"wallet_credit_buckets",
+		"wallet_auto_topups",
+		"wallet_transactions",
+		"wallets",
+	},
+	"subscriptions": {
+		"subscription_prorations",
+		"subscription_line_items",
+		"subscriptions",
+	},
+	"customers": {
+		"budgets",
+		"mandates",
+		"payment_methods",
+		"rate_cards",
+		"customers",
+	},
+}
+
+type environmentRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewEnvironmentRepository(db *postgres.DB, logger *logger.Logger) environment.Repository {
+	return &environmentRepository{db: db, logger: logger}
+}
+
+func (r *environmentRepository) Create(ctx context.Context, env *environment.Environment) error {
+	query := `
+		INSERT INTO environments (
+			id, tenant_id, name, type, status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :name, :type, :status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating environment",
+		"environment_id", env.ID,
+		"tenant_id", env.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, env); err != nil {
+		return fmt.Errorf("failed to create environment: %w", err)
+	}
+	return nil
+}
+
+func (r *environmentRepository) Get(ctx context.Context, id string) (*environment.Environment, error) {
+	var env environment.Environment
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM environments WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("environment not found")
+	}
+
+	if err := rows.StructScan(&env); err != nil {
+		return nil, fmt.Errorf("failed to scan environment: %w", err)
+	}
+
+	return &env, nil
+}
+
+func (r *environmentRepository) List(ctx context.Context, filter types.Filter) ([]*environment.Environment, error) {
+	var envs []*environment.Environment
+	query := `
+		SELECT * FROM environments WHERE tenant_id = :tenant_id AND status = :status ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var env environment.Environment
+		if err := rows.StructScan(&env); err != nil {
+			return nil, fmt.Errorf("failed to scan environment: %w", err)
+		}
+		envs = append(envs, &env)
+	}
+
+	return envs, nil
+}
+
+func (r *environmentRepository) UpdateClockOffset(ctx context.Context, id string, offsetMs int64) error {
+	query := `
+		UPDATE environments SET
+			clock_offset_ms = :clock_offset_ms,
+			updated_at = :updated_at
+		WHERE id = :id AND tenant_id = :tenant_id AND status = :status`
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":              id,
+		"tenant_id":       types.GetTenantID(ctx),
+		"status":          types.StatusPublished,
+		"clock_offset_ms": offsetMs,
+		"updated_at":      time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update environment clock: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update environment clock: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("environment not found")
+	}
+	return nil
+}
+
+func (r *environmentRepository) CreateReset(ctx context.Context, reset *environment.Reset) error {
+	query := `
+		INSERT INTO environment_resets (
+			id, tenant_id, environment_id, reset_status, current_step, steps_completed, steps_total,
+			records_deleted, error, completed_at, status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :environment_id, :reset_status, :current_step, :steps_completed, :steps_total,
+			:records_deleted, :error, :completed_at, :status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, reset); err != nil {
+		return fmt.Errorf("failed to create environment reset: %w", err)
+	}
+	return nil
+}
+
+func (r *environmentRepository) GetReset(ctx context.Context, id string) (*environment.Reset, error) {
+	var reset environment.Reset
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM environment_resets WHERE id = :id AND tenant_id = :tenant_id", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment reset: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("environment reset not found")
+	}
+
+	if err := rows.StructScan(&reset); err != nil {
+		return nil, fmt.Errorf("failed to scan environment reset: %w", err)
+	}
+
+	return &reset, nil
+}
+
+func (r *environmentRepository) UpdateReset(ctx context.Context, reset *environment.Reset) error {
+	query := `
+		UPDATE environment_resets SET
+			reset_status = :reset_status,
+			current_step = :current_step,
+			steps_completed = :steps_completed,
+			records_deleted = :records_deleted,
+			error = :error,
+			completed_at = :completed_at,
+			updated_at = :updated_at
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	if _, err := r.db.NamedExecContext(ctx, query, reset); err != nil {
+		return fmt.Errorf("failed to update environment reset: %w", err)
+	}
+	return nil
+}
+
+func (r *environmentRepository) DeleteResetStep(ctx context.Context, step string) (int64, error) {
+	tables, ok := resetStepTables[step]
+	if !ok {
+		return 0, fmt.Errorf("unknown reset step: %s", step)
+	}
+
+	var deleted int64
+	err := r.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, table := range tables {
+			// table names come from the fixed resetStepTables map and are safe to interpolate
+			query := fmt.Sprintf("DELETE FROM %s WHERE tenant_id = :tenant_id", table)
+
+			result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+				"tenant_id": types.GetTenantID(ctx),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to delete %s: %w", table, err)
+			}
+
+			count, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to count deleted %s: %w", table, err)
+			}
+			deleted += count
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	r.logger.Infow("deleted records for environment reset",
+		"tenant_id", types.GetTenantID(ctx),
+		"step", step,
+		"deleted", deleted,
+	)
+
+	return deleted, nil
+}
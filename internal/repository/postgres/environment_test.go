@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	createTablePattern = regexp.MustCompile(`(?i)^\s*CREATE TABLE (?:IF NOT EXISTS )?(\w+)`)
+	referencesPattern  = regexp.MustCompile(`(?i)REFERENCES (\w+)\s*\(`)
+)
+
+// migratedForeignKeys returns the tables each table references in the Postgres
+// migrations
+func migratedForeignKeys(t *testing.T) map[string][]string {
+	files, err := filepath.Glob("../../../migrations/postgres/*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	references := make(map[string][]string)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		table := ""
+		for _, line := range strings.Split(string(content), "\n") {
+			if match := createTablePattern.FindStringSubmatch(line); match != nil {
+				table = match[1]
+			}
+			if match := referencesPattern.FindStringSubmatch(line); match != nil && table != "" {
+				references[table] = append(references[table], match[1])
+			}
+		}
+	}
+	return references
+}
+
+// TestResetStepTables runs the deletes of a sandbox reset against rows of the
+// invoice, payment and refund tables, failing on the deletes the foreign keys
+// of the migrations would reject
+func TestResetStepTables(t *testing.T) {
+	references := migratedForeignKeys(t)
+	require.Contains(t, references["refunds"], "invoice_payments")
+
+	// Rows of a tenant with a paid, refunded, credited and disputed invoice
+	rows := map[string]int{
+		"customers":             1,
+		"subscriptions":         1,
+		"invoices":              2,
+		"invoice_line_items":    2,
+		"invoice_payments":      1,
+		"refunds":               1,
+		"credit_allocations":    1,
+		"invoice_disputes":      1,
+		"wallets":               1,
+		"wallet_transactions":   1,
+		"wallet_credit_buckets": 1,
+	}
+
+	for _, step := range environment.ResetSteps {
+		tables, ok := resetStepTables[step]
+		if !ok {
+			// The events step is deleted from the event store
+			continue
+		}
+		for _, table := range tables {
+			for child, parents := range references {
+				for _, parent := range parents {
+					if parent == table && rows[child] > 0 {
+						t.Fatalf("step %s: deleting %s violates the foreign key of %s", step, table, child)
+					}
+				}
+			}
+			rows[table] = 0
+		}
+	}
+
+	for table, count := range rows {
+		assert.Zero(t, count, "%s is not wiped by the reset", table)
+	}
+}
+
+// TestResetStepTablesCoverReferences checks that the tables referencing a
+// wiped table are wiped too
+func TestResetStepTablesCoverReferences(t *testing.T) {
+	wiped := make(map[string]bool)
+	for _, tables := range resetStepTables {
+		for _, table := range tables {
+			wiped[table] = true
+		}
+	}
+
+	for child, parents := range migratedForeignKeys(t) {
+		for _, parent := range parents {
+			if wiped[parent] {
+				assert.True(t, wiped[child], "%s references %s but is not wiped by the reset", child, parent)
+			}
+		}
+	}
+}
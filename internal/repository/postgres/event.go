@@ -0,0 +1,820 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+)
+
+// eventRepository stores the events in the events table of the Postgres
+// database. With the TimescaleDB extension the table is a hypertable, windows
+// are bucketed with time_bucket and the chunks are listed and dropped as the
+// monthly partitions of the events. Without it there are no partitions, the
+// retention job deletes the expired rows instead
+type eventRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+
+	mu         sync.Mutex
+	detected   bool
+	hypertable bool
+}
+
+func NewEventRepository(db *postgres.DB, logger *logger.Logger) events.Repository {
+	return &eventRepository{db: db, logger: logger}
+}
+
+// isHypertable reports whether the events table is a TimescaleDB hypertable,
+// detected on first use as the migrations may run after the repository is built
+func (r *eventRepository) isHypertable(ctx context.Context) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.detected {
+		return r.hypertable, nil
+	}
+
+	var installed bool
+	if err := r.db.GetContext(ctx, &installed,
+		"SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')"); err != nil {
+		return false, fmt.Errorf("detect timescaledb: %w", err)
+	}
+	if installed {
+		if err := r.db.GetContext(ctx, &r.hypertable,
+			"SELECT EXISTS (SELECT 1 FROM timescaledb_information.hypertables WHERE hypertable_name = 'events')"); err != nil {
+			return false, fmt.Errorf("detect timescaledb: %w", err)
+		}
+	}
+
+	r.detected = true
+	r.logger.Infow("detected the events table", "hypertable", r.hypertable)
+	return r.hypertable, nil
+}
+
+// query runs a query with named parameters
+func (r *eventRepository) query(ctx context.Context, query string, params map[string]interface{}) (*sql.Rows, error) {
+	query, args, err := sqlx.Named(query, params)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err = sqlx.In(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return r.db.GetQuerier(ctx).QueryContext(ctx, r.db.Rebind(query), args...)
+}
+
+func (r *eventRepository) exec(ctx context.Context, query string, params map[string]interface{}) error {
+	query, args, err := sqlx.Named(query, params)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.GetQuerier(ctx).ExecContext(ctx, r.db.Rebind(query), args...)
+	return err
+}
+
+func (r *eventRepository) InsertEvent(ctx context.Context, event *events.Event) error {
+	return r.InsertEvents(ctx, []*events.Event{event})
+}
+
+// insertEventsBatchSize is the number of events inserted by one statement. Each
+// event binds 10 parameters and a statement binds at most 65535
+const insertEventsBatchSize = 1000
+
+func (r *eventRepository) InsertEvents(ctx context.Context, eventsList []*events.Event) error {
+	if len(eventsList) == 0 {
+		return nil
+	}
+	if len(eventsList) <= insertEventsBatchSize {
+		return r.insertEvents(ctx, eventsList)
+	}
+
+	// The batches are inserted in one transaction so that the events are
+	// stored all or none, as with a single statement
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		for start := 0; start < len(eventsList); start += insertEventsBatchSize {
+			end := min(start+insertEventsBatchSize, len(eventsList))
+			if err := r.insertEvents(ctx, eventsList[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *eventRepository) insertEvents(ctx context.Context, eventsList []*events.Event) error {
+	query, params, err := insertEventsQuery(eventsList)
+	if err != nil {
+		return err
+	}
+	if err := r.exec(ctx, query, params); err != nil {
+		return fmt.Errorf("insert event: %w", err)
+	}
+	return nil
+}
+
+// insertEventsQuery returns the statement inserting the events and its parameters
+func insertEventsQuery(eventsList []*events.Event) (string, map[string]interface{}, error) {
+	values := make([]string, 0, len(eventsList))
+	params := make(map[string]interface{}, len(eventsList)*10)
+	for i, event := range eventsList {
+		propertiesJSON, err := json.Marshal(event.Properties)
+		if err != nil {
+			return "", nil, fmt.Errorf("marshal properties: %w", err)
+		}
+
+		if err := event.Validate(); err != nil {
+			return "", nil, fmt.Errorf("validate event: %w", err)
+		}
+
+		values = append(values, fmt.Sprintf(
+			"(:id%[1]d, :external_customer_id%[1]d, :customer_id%[1]d, :tenant_id%[1]d, :event_name%[1]d, :timestamp%[1]d, :source%[1]d, CAST(:properties%[1]d AS JSONB), :correction_of%[1]d, :sign%[1]d)", i))
+		params["id"+strconv.Itoa(i)] = event.ID
+		params["external_customer_id"+strconv.Itoa(i)] = event.ExternalCustomerID
+		params["customer_id"+strconv.Itoa(i)] = event.CustomerID
+		params["tenant_id"+strconv.Itoa(i)] = event.TenantID
+		params["event_name"+strconv.Itoa(i)] = event.EventName
+		params["timestamp"+strconv.Itoa(i)] = event.Timestamp.UTC()
+		params["source"+strconv.Itoa(i)] = event.Source
+		params["properties"+strconv.Itoa(i)] = string(propertiesJSON)
+		params["correction_of"+strconv.Itoa(i)] = event.CorrectionOf
+		params["sign"+strconv.Itoa(i)] = event.GetSign()
+	}
+
+	// The same events inserted again, by a consumer redelivered a message it
+	// had stored before failing to commit its offset, are skipped
+	query := `
+		INSERT INTO events (
+			id, external_customer_id, customer_id, tenant_id, event_name, timestamp, source, properties,
+			correction_of, sign
+		) VALUES ` + strings.Join(values, ", ") + `
+		ON CONFLICT (tenant_id, id, timestamp) DO NOTHING`
+	return query, params, nil
+}
+
+func (r *eventRepository) GetEventHistory(ctx context.Context, id string) ([]*events.Event, error) {
+	// The reversal of a correction sorts before its replacement
+	rows, err := r.query(ctx, `
+		SELECT id, external_customer_id, customer_id, tenant_id, event_name, timestamp, ingested_at,
+			source, properties, correction_of, sign
+		FROM events
+		WHERE tenant_id = :tenant_id AND ((id = :id AND correction_of = '') OR correction_of = :id)
+		ORDER BY correction_of != '', ingested_at, sign`,
+		map[string]interface{}{"tenant_id": types.GetTenantID(ctx), "id": id})
+	if err != nil {
+		return nil, fmt.Errorf("query event history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*events.Event
+	for rows.Next() {
+		var event events.Event
+		var propertiesJSON []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.ExternalCustomerID,
+			&event.CustomerID,
+			&event.TenantID,
+			&event.EventName,
+			&event.Timestamp,
+			&event.IngestedAt,
+			&event.Source,
+			&propertiesJSON,
+			&event.CorrectionOf,
+			&event.Sign,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		if err := json.Unmarshal(propertiesJSON, &event.Properties); err != nil {
+			return nil, fmt.Errorf("unmarshal properties: %w", err)
+		}
+		event.Timestamp = event.Timestamp.UTC()
+		event.IngestedAt = event.IngestedAt.UTC()
+		history = append(history, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	// A history without the ingested event is not one of the tenant
+	if len(history) > 0 && history[0].CorrectionOf != "" {
+		return nil, nil
+	}
+	return history, nil
+}
+
+// usageQuery is the conditions on the events of a usage aggregation and their
+// parameters
+type usageQuery struct {
+	conditions []string
+	params     map[string]interface{}
+}
+
+func newUsageQuery(ctx context.Context, params *events.UsageParams) *usageQuery {
+	q := &usageQuery{
+		conditions: []string{"tenant_id = :tenant_id", "event_name = :event_name"},
+		params: map[string]interface{}{
+			"tenant_id":  types.GetTenantID(ctx),
+			"event_name": params.EventName,
+			"property":   params.PropertyName,
+		},
+	}
+
+	if !params.StartTime.IsZero() {
+		q.conditions = append(q.conditions, "timestamp >= :start_time")
+		q.params["start_time"] = params.StartTime.UTC()
+	}
+	if !params.EndTime.IsZero() {
+		q.conditions = append(q.conditions, "timestamp < :end_time")
+		q.params["end_time"] = params.EndTime.UTC()
+	}
+	if params.ExternalCustomerID != "" {
+		q.conditions = append(q.conditions, "external_customer_id = :external_customer_id")
+		q.params["external_customer_id"] = params.ExternalCustomerID
+	}
+	if params.CustomerID != "" {
+		q.conditions = append(q.conditions, "customer_id = :customer_id")
+		q.params["customer_id"] = params.CustomerID
+	}
+	if condition := q.filterCondition("filter", params.Filters); condition != "" {
+		q.conditions = append(q.conditions, condition)
+	}
+	return q
+}
+
+// filterCondition matches the events whose properties have one of the values of
+// every filter, empty when there is nothing to filter on
+func (q *usageQuery) filterCondition(prefix string, filters map[string][]string) string {
+	// Sorted so that the same filters give the same query
+	keys := make([]string, 0, len(filters))
+	for key, values := range filters {
+		if len(values) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	conditions := make([]string, 0, len(keys))
+	for i, key := range keys {
+		name := fmt.Sprintf("%s_%d", prefix, i)
+		q.params[name+"_key"] = key
+		q.params[name+"_values"] = filters[key]
+		conditions = append(conditions, fmt.Sprintf("properties ->> :%[1]s_key IN (:%[1]s_values)", name))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+func (q *usageQuery) where() string {
+	return strings.Join(q.conditions, " AND ")
+}
+
+// numericValue is the JSON value as a number, as ClickHouse extracts floats:
+// numbers and numeric strings are read, anything else is 0
+func numericValue(value string) string {
+	return fmt.Sprintf(`CASE
+			WHEN jsonb_typeof(%[1]s) = 'number' THEN CAST(%[1]s #>> '{}' AS DOUBLE PRECISION)
+			WHEN jsonb_typeof(%[1]s) = 'string' AND %[1]s #>> '{}' ~ '^\s*[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?\s*$'
+				THEN CAST(%[1]s #>> '{}' AS DOUBLE PRECISION)
+			ELSE 0 END`, value)
+}
+
+// windowExpression is the start of the window of the events, empty without a window
+func (r *eventRepository) windowExpression(ctx context.Context, windowSize types.WindowSize) (string, error) {
+	var unit string
+	switch windowSize {
+	case types.WindowSizeMinute:
+		unit = "minute"
+	case types.WindowSizeHour:
+		unit = "hour"
+	case types.WindowSizeDay:
+		unit = "day"
+	default:
+		return "", nil
+	}
+
+	hypertable, err := r.isHypertable(ctx)
+	if err != nil {
+		return "", err
+	}
+	if hypertable {
+		return fmt.Sprintf("time_bucket(INTERVAL '1 %s', timestamp)", unit), nil
+	}
+	return fmt.Sprintf("date_trunc('%s', timestamp, 'UTC')", unit), nil
+}
+
+// aggregation returns the query of the aggregation of the given rows, with the
+// columns grouped by selected first. The rows have the properties, id,
+// correction_of and sign of the events
+func aggregation(params *events.UsageParams, rows string, groupBy []string) (string, error) {
+	columns := ""
+	groupClause := ""
+	if len(groupBy) > 0 {
+		columns = strings.Join(groupBy, ", ") + ", "
+		groupClause = "GROUP BY " + strings.Join(groupBy, ", ") + " ORDER BY " + strings.Join(groupBy, ", ")
+	}
+
+	value := numericValue("properties -> :property")
+
+	// Correction rows reversing an event have a sign of -1 and are subtracted
+	switch params.AggregationType {
+	case types.AggregationCount:
+		return fmt.Sprintf("SELECT %s CAST(GREATEST(SUM(sign), 0) AS DOUBLE PRECISION) AS total FROM (%s) AS e %s",
+			columns, rows, groupClause), nil
+	case types.AggregationSum:
+		return fmt.Sprintf("SELECT %s COALESCE(SUM((%s) * sign), 0) AS total FROM (%s) AS e %s",
+			columns, value, rows, groupClause), nil
+	case types.AggregationAvg:
+		return fmt.Sprintf("SELECT %s CASE WHEN SUM(sign) > 0 THEN SUM((%s) * sign) / SUM(sign) ELSE 0 END AS total FROM (%s) AS e %s",
+			columns, value, rows, groupClause), nil
+	case types.AggregationCountUnique:
+		// Counted exactly, Postgres has no sketch to count approximately with
+		return nettedValues(columns, groupClause, rows, groupBy, "CAST(COUNT(DISTINCT value) AS DOUBLE PRECISION)"), nil
+	case types.AggregationPercentile:
+		return nettedValues(columns, groupClause, rows, groupBy, fmt.Sprintf(
+			"COALESCE(percentile_disc(%s) WITHIN GROUP (ORDER BY %s), 0)",
+			strconv.FormatFloat(params.Percentile/100, 'f', -1, 64), numericValue("value"))), nil
+	default:
+		return "", fmt.Errorf("unsupported aggregation type: %s", params.AggregationType)
+	}
+}
+
+// nettedValues aggregates the values of the field that are not reversed. The
+// values of an event and of its correction rows are netted out by their sign
+// first, so a voided or corrected value is not aggregated
+func nettedValues(columns, groupClause, rows string, groupBy []string, aggregate string) string {
+	innerGroupBy := append(append([]string{}, groupBy...), "COALESCE(NULLIF(correction_of, ''), id)", "value")
+	return fmt.Sprintf(`
+		SELECT %s %s AS total
+		FROM (
+			SELECT %s value
+			FROM (SELECT e.*, properties -> :property AS value FROM (%s) AS e) AS v
+			WHERE value IS NOT NULL
+			GROUP BY %s
+			HAVING SUM(sign) > 0
+		) AS netted
+		%s`,
+		columns, aggregate, columns, rows, strings.Join(innerGroupBy, ", "), groupClause)
+}
+
+func (r *eventRepository) GetUsage(ctx context.Context, params *events.UsageParams) (*events.AggregationResult, error) {
+	window, err := r.windowExpression(ctx, params.WindowSize)
+	if err != nil {
+		return nil, err
+	}
+
+	q := newUsageQuery(ctx, params)
+	columns := "properties, id, correction_of, sign"
+	var groupBy []string
+	if window != "" {
+		columns = window + " AS window_size, " + columns
+		groupBy = []string{"window_size"}
+	}
+
+	query, err := aggregation(params, fmt.Sprintf("SELECT %s FROM events WHERE %s", columns, q.where()), groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.query(ctx, query, q.params)
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+	defer rows.Close()
+
+	result := &events.AggregationResult{Type: params.AggregationType, EventName: params.EventName}
+	for rows.Next() {
+		var value float64
+		if window == "" {
+			if err := rows.Scan(&value); err != nil {
+				return nil, fmt.Errorf("scan result: %w", err)
+			}
+			result.Value = decimal.NewFromFloat(value)
+			continue
+		}
+
+		var windowSize time.Time
+		if err := rows.Scan(&windowSize, &value); err != nil {
+			return nil, fmt.Errorf("scan result: %w", err)
+		}
+		result.Results = append(result.Results, events.UsageResult{
+			WindowSize: windowSize.UTC(),
+			Value:      decimal.NewFromFloat(value),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *eventRepository) GetUsageWithFilters(ctx context.Context, params *events.UsageWithFiltersParams) ([]*events.AggregationResult, error) {
+	if params == nil || params.UsageParams == nil {
+		return nil, fmt.Errorf("params cannot be nil")
+	}
+	if len(params.FilterGroups) == 0 {
+		return nil, nil
+	}
+
+	q := newUsageQuery(ctx, params.UsageParams)
+
+	// Each event goes to the matching filter group with the highest priority
+	groups := make([]string, 0, len(params.FilterGroups))
+	for i, group := range params.FilterGroups {
+		name := fmt.Sprintf("group_%d", i)
+		q.params[name+"_id"] = group.ID
+		q.params[name+"_priority"] = group.Priority
+
+		condition := q.filterCondition(name+"_filter", group.Filters)
+		if condition == "" {
+			condition = "TRUE"
+		}
+		groups = append(groups, fmt.Sprintf("(CAST(:%[1]s_id AS TEXT), CAST(:%[1]s_priority AS INTEGER), %[2]s)", name, condition))
+	}
+
+	rowsQuery := fmt.Sprintf(`
+		SELECT DISTINCT ON (e.id) e.properties, e.id, e.correction_of, e.sign, g.group_id AS filter_group_id
+		FROM events AS e
+		JOIN LATERAL (VALUES %s) AS g(group_id, priority, matches) ON g.matches
+		WHERE %s
+		ORDER BY e.id, g.priority DESC, g.group_id DESC`,
+		strings.Join(groups, ", "), q.where())
+
+	query, err := aggregation(params.UsageParams, rowsQuery, []string{"filter_group_id"})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debugw("executing filter groups query",
+		"event_name", params.EventName,
+		"filter_groups", len(params.FilterGroups),
+		"query", query)
+
+	rows, err := r.query(ctx, query, q.params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*events.AggregationResult
+	for rows.Next() {
+		var filterGroupID string
+		var value float64
+		if err := rows.Scan(&filterGroupID, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		results = append(results, &events.AggregationResult{
+			Type:      params.AggregationType,
+			EventName: params.EventName,
+			Value:     decimal.NewFromFloat(value),
+			Metadata:  map[string]string{"filter_group_id": filterGroupID},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return results, nil
+}
+
+func (r *eventRepository) GetUsageByCustomer(ctx context.Context, params *events.UsageParams) ([]*events.CustomerUsage, error) {
+	window, err := r.windowExpression(ctx, params.WindowSize)
+	if err != nil {
+		return nil, err
+	}
+	if window == "" {
+		return nil, fmt.Errorf("window size is required")
+	}
+
+	customerParams := *params
+	customerParams.ExternalCustomerID = ""
+	q := newUsageQuery(ctx, &customerParams)
+
+	query, err := aggregation(params, fmt.Sprintf(
+		"SELECT external_customer_id, %s AS window_size, properties, id, correction_of, sign FROM events WHERE %s",
+		window, q.where()), []string{"external_customer_id", "window_size"})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.query(ctx, query, q.params)
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*events.CustomerUsage
+	for rows.Next() {
+		var customerID string
+		var windowSize time.Time
+		var value float64
+		if err := rows.Scan(&customerID, &windowSize, &value); err != nil {
+			return nil, fmt.Errorf("scan result: %w", err)
+		}
+
+		if len(results) == 0 || results[len(results)-1].ExternalCustomerID != customerID {
+			results = append(results, &events.CustomerUsage{ExternalCustomerID: customerID})
+		}
+		current := results[len(results)-1]
+		current.Results = append(current.Results, events.UsageResult{
+			WindowSize: windowSize.UTC(),
+			Value:      decimal.NewFromFloat(value),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return results, nil
+}
+
+func (r *eventRepository) GetEvents(ctx context.Context, params *events.GetEventsParams) ([]*events.Event, error) {
+	query := `
+		SELECT id, external_customer_id, customer_id, tenant_id, event_name, timestamp, source, properties
+		FROM events
+		WHERE tenant_id = :tenant_id AND correction_of = ''`
+	queryParams := map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"limit":     params.PageSize + 1,
+	}
+
+	if params.ExternalCustomerID != "" {
+		query += " AND external_customer_id = :external_customer_id"
+		queryParams["external_customer_id"] = params.ExternalCustomerID
+	}
+	if params.EventName != "" {
+		query += " AND event_name = :event_name"
+		queryParams["event_name"] = params.EventName
+	}
+	if !params.StartTime.IsZero() {
+		query += " AND timestamp >= :start_time"
+		queryParams["start_time"] = params.StartTime.UTC()
+	}
+	if !params.EndTime.IsZero() {
+		query += " AND timestamp <= :end_time"
+		queryParams["end_time"] = params.EndTime.UTC()
+	}
+
+	// Handle pagination and real-time refresh using composite keys
+	if params.IterFirst != nil {
+		query += " AND (timestamp, id) > (:iter_timestamp, :iter_id)"
+		queryParams["iter_timestamp"] = params.IterFirst.Timestamp.UTC()
+		queryParams["iter_id"] = params.IterFirst.ID
+	} else if params.IterLast != nil {
+		query += " AND (timestamp, id) < (:iter_timestamp, :iter_id)"
+		queryParams["iter_timestamp"] = params.IterLast.Timestamp.UTC()
+		queryParams["iter_id"] = params.IterLast.ID
+	}
+
+	query += " ORDER BY timestamp DESC, id DESC LIMIT :limit"
+
+	return r.listEvents(ctx, query, queryParams)
+}
+
+func (r *eventRepository) ExportEvents(ctx context.Context, params *events.ExportEventsParams) ([]*events.Event, error) {
+	query := `
+		SELECT id, external_customer_id, customer_id, tenant_id, event_name, timestamp, source, properties
+		FROM events
+		WHERE tenant_id = :tenant_id
+		AND correction_of = ''
+		AND timestamp >= :start_time
+		AND timestamp < :end_time`
+	queryParams := map[string]interface{}{
+		"tenant_id":  types.GetTenantID(ctx),
+		"start_time": params.StartTime.UTC(),
+		"end_time":   params.EndTime.UTC(),
+		"limit":      params.Limit,
+	}
+
+	if params.ExternalCustomerID != "" {
+		query += " AND external_customer_id = :external_customer_id"
+		queryParams["external_customer_id"] = params.ExternalCustomerID
+	}
+	if params.EventName != "" {
+		query += " AND event_name = :event_name"
+		queryParams["event_name"] = params.EventName
+	}
+	if params.After != nil {
+		query += " AND (timestamp, id) > (:after_timestamp, :after_id)"
+		queryParams["after_timestamp"] = params.After.Timestamp.UTC()
+		queryParams["after_id"] = params.After.ID
+	}
+
+	// Ascending order keeps the cursor stable while new events are ingested
+	// at the end of the range
+	query += " ORDER BY timestamp ASC, id ASC LIMIT :limit"
+
+	return r.listEvents(ctx, query, queryParams)
+}
+
+func (r *eventRepository) listEvents(ctx context.Context, query string, params map[string]interface{}) ([]*events.Event, error) {
+	rows, err := r.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+	defer rows.Close()
+
+	var eventsList []*events.Event
+	for rows.Next() {
+		var event events.Event
+		var propertiesJSON []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.ExternalCustomerID,
+			&event.CustomerID,
+			&event.TenantID,
+			&event.EventName,
+			&event.Timestamp,
+			&event.Source,
+			&propertiesJSON,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		if err := json.Unmarshal(propertiesJSON, &event.Properties); err != nil {
+			return nil, fmt.Errorf("unmarshal properties: %w", err)
+		}
+		event.Timestamp = event.Timestamp.UTC()
+		eventsList = append(eventsList, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return eventsList, nil
+}
+
+func (r *eventRepository) CountLateEvents(ctx context.Context, params *events.LateEventsParams) (uint64, error) {
+	query := `
+		SELECT COUNT(*) FROM events
+		WHERE tenant_id = :tenant_id
+		AND external_customer_id = :external_customer_id
+		AND timestamp >= :start_time
+		AND timestamp < :end_time
+		AND ingested_at > :ingested_after
+		AND ingested_at <= :ingested_before`
+	queryParams := map[string]interface{}{
+		"tenant_id":            types.GetTenantID(ctx),
+		"external_customer_id": params.ExternalCustomerID,
+		"start_time":           params.StartTime.UTC(),
+		"end_time":             params.EndTime.UTC(),
+		"ingested_after":       params.IngestedAfter.UTC(),
+		"ingested_before":      params.IngestedBefore.UTC(),
+	}
+
+	if len(params.EventNames) > 0 {
+		query += " AND event_name IN (:event_names)"
+		queryParams["event_names"] = params.EventNames
+	}
+
+	rows, err := r.query(ctx, query, queryParams)
+	if err != nil {
+		return 0, fmt.Errorf("count late events: %w", err)
+	}
+	defer rows.Close()
+
+	var count uint64
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, fmt.Errorf("scan late events: %w", err)
+		}
+	}
+	return count, rows.Err()
+}
+
+func (r *eventRepository) GetTenantIDs(ctx context.Context, since time.Time) ([]string, error) {
+	var tenantIDs []string
+	if err := r.db.SelectContext(ctx, &tenantIDs,
+		"SELECT DISTINCT tenant_id FROM events WHERE timestamp >= $1", since.UTC()); err != nil {
+		return nil, fmt.Errorf("query tenants: %w", err)
+	}
+	return tenantIDs, nil
+}
+
+func (r *eventRepository) DeleteEvents(ctx context.Context) error {
+	if err := r.exec(ctx, "DELETE FROM events WHERE tenant_id = :tenant_id",
+		map[string]interface{}{"tenant_id": types.GetTenantID(ctx)}); err != nil {
+		return fmt.Errorf("delete events: %w", err)
+	}
+	return nil
+}
+
+func (r *eventRepository) DeleteEventsBefore(ctx context.Context, before time.Time) error {
+	if err := r.exec(ctx, "DELETE FROM events WHERE tenant_id = :tenant_id AND timestamp < :before",
+		map[string]interface{}{"tenant_id": types.GetTenantID(ctx), "before": before.UTC()}); err != nil {
+		return fmt.Errorf("delete events: %w", err)
+	}
+	return nil
+}
+
+func (r *eventRepository) GetStorageByTenant(ctx context.Context) ([]*events.TenantStorage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			tenant_id,
+			COUNT(*) AS rows,
+			CAST(SUM(pg_column_size(events.*)) AS BIGINT) AS bytes,
+			MIN(timestamp) AS oldest_event,
+			MAX(timestamp) AS newest_event
+		FROM events
+		GROUP BY tenant_id
+		ORDER BY tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query storage: %w", err)
+	}
+	defer rows.Close()
+
+	var storage []*events.TenantStorage
+	for rows.Next() {
+		var s events.TenantStorage
+		if err := rows.Scan(&s.TenantID, &s.Rows, &s.Bytes, &s.OldestEvent, &s.NewestEvent); err != nil {
+			return nil, fmt.Errorf("scan storage: %w", err)
+		}
+		s.OldestEvent = s.OldestEvent.UTC()
+		s.NewestEvent = s.NewestEvent.UTC()
+		storage = append(storage, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return storage, nil
+}
+
+// ListPartitions returns the chunks of the hypertable grouped by the month they
+// start in, none without TimescaleDB
+func (r *eventRepository) ListPartitions(ctx context.Context) ([]*events.Partition, error) {
+	hypertable, err := r.isHypertable(ctx)
+	if err != nil || !hypertable {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			to_char(c.range_start AT TIME ZONE 'UTC', 'YYYYMM') AS partition_id,
+			CAST(SUM(GREATEST(cls.reltuples, 0)) AS BIGINT) AS rows,
+			CAST(SUM(s.total_bytes) AS BIGINT) AS bytes_on_disk
+		FROM timescaledb_information.chunks AS c
+		JOIN chunks_detailed_size('events') AS s
+			ON s.chunk_schema = c.chunk_schema AND s.chunk_name = c.chunk_name
+		JOIN pg_class AS cls
+			ON cls.oid = CAST(format('%I.%I', c.chunk_schema, c.chunk_name) AS regclass)
+		WHERE c.hypertable_name = 'events'
+		GROUP BY partition_id
+		ORDER BY partition_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []*events.Partition
+	for rows.Next() {
+		var p events.Partition
+		if err := rows.Scan(&p.PartitionID, &p.Rows, &p.BytesOnDisk); err != nil {
+			return nil, fmt.Errorf("scan partition: %w", err)
+		}
+		partitions = append(partitions, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return partitions, nil
+}
+
+// DropPartition drops the chunks of the hypertable within the month. A chunk
+// overlapping the next month is kept, its expired rows are deleted by the
+// retention job instead
+func (r *eventRepository) DropPartition(ctx context.Context, partitionID string) error {
+	month, err := events.PartitionMonth(partitionID)
+	if err != nil {
+		return fmt.Errorf("invalid partition %q: %w", partitionID, err)
+	}
+
+	hypertable, err := r.isHypertable(ctx)
+	if err != nil {
+		return err
+	}
+	if !hypertable {
+		return fmt.Errorf("drop partition: events are not partitioned without timescaledb")
+	}
+
+	if _, err := r.db.ExecContext(ctx,
+		"SELECT drop_chunks('events', older_than => $1, newer_than => $2)",
+		month.AddDate(0, 1, 0), month); err != nil {
+		return fmt.Errorf("drop partition: %w", err)
+	}
+	return nil
+}
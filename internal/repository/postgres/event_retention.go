@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/retention"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type retentionRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewRetentionRepository(db *postgres.DB, logger *logger.Logger) retention.Repository {
+	return &retentionRepository{db: db, logger: logger}
+}
+
+func (r *retentionRepository) Upsert(ctx context.Context, policy *retention.Policy) error {
+	query := `
+		INSERT INTO event_retention_policies (
+			id, tenant_id, ttl_days, status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :ttl_days, :status, :created_at, :updated_at, :created_by, :updated_by
+		)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			ttl_days = EXCLUDED.ttl_days,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by
+		RETURNING id, created_at, created_by`
+
+	r.logger.Debug("upserting event retention policy",
+		"tenant_id", policy.TenantID,
+		"ttl_days", policy.TTLDays,
+	)
+
+	rows, err := r.db.NamedQueryContext(ctx, query, policy)
+	if err != nil {
+		return fmt.Errorf("failed to upsert event retention policy: %w", err)
+	}
+	defer rows.Close()
+
+	// The policy keeps its identity when replaced
+	if rows.Next() {
+		if err := rows.Scan(&policy.ID, &policy.CreatedAt, &policy.CreatedBy); err != nil {
+			return fmt.Errorf("failed to scan event retention policy: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *retentionRepository) Get(ctx context.Context, tenantID string) (*retention.Policy, error) {
+	query := `
+		SELECT * FROM event_retention_policies
+		WHERE tenant_id = :tenant_id AND status = :status`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": tenantID,
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event retention policy: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("event retention policy not found")
+	}
+
+	var policy retention.Policy
+	if err := rows.StructScan(&policy); err != nil {
+		return nil, fmt.Errorf("failed to scan event retention policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+func (r *retentionRepository) List(ctx context.Context) ([]*retention.Policy, error) {
+	query := `
+		SELECT * FROM event_retention_policies
+		WHERE status = :status
+		ORDER BY tenant_id`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"status": types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*retention.Policy
+	for rows.Next() {
+		var policy retention.Policy
+		if err := rows.StructScan(&policy); err != nil {
+			return nil, fmt.Errorf("failed to scan event retention policy: %w", err)
+		}
+		policies = append(policies, &policy)
+	}
+
+	return policies, nil
+}
+
+func (r *retentionRepository) Delete(ctx context.Context, tenantID string) error {
+	query := `DELETE FROM event_retention_policies WHERE tenant_id = :tenant_id`
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"tenant_id": tenantID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete event retention policy: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("event retention policy not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/eventschema"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type eventSchemaRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewEventSchemaRepository(db *postgres.DB, logger *logger.Logger) eventschema.Repository {
+	return &eventSchemaRepository{db: db, logger: logger}
+}
+
+func (r *eventSchemaRepository) Create(ctx context.Context, schema *eventschema.EventSchema) error {
+	query := `
+		INSERT INTO event_schemas (
+			id, tenant_id, event_name, rules, on_invalid,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :event_name, :rules, :on_invalid,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating event schema",
+		"event_schema_id", schema.ID,
+		"event_name", schema.EventName,
+		"tenant_id", schema.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, schema); err != nil {
+		return fmt.Errorf("failed to create event schema: %w", err)
+	}
+	return nil
+}
+
+func (r *eventSchemaRepository) Get(ctx context.Context, id string) (*eventschema.EventSchema, error) {
+	schemas, err := r.list(ctx, "SELECT * FROM event_schemas WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("event schema not found")
+	}
+	return schemas[0], nil
+}
+
+func (r *eventSchemaRepository) GetByEventName(ctx context.Context, eventName string) (*eventschema.EventSchema, error) {
+	schemas, err := r.list(ctx, "SELECT * FROM event_schemas WHERE event_name = :event_name AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"event_name": eventName,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusPublished,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, nil
+	}
+	return schemas[0], nil
+}
+
+func (r *eventSchemaRepository) List(ctx context.Context) ([]*eventschema.EventSchema, error) {
+	return r.list(ctx, "SELECT * FROM event_schemas WHERE tenant_id = :tenant_id AND status = :status ORDER BY event_name ASC", map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+}
+
+func (r *eventSchemaRepository) list(ctx context.Context, query string, params map[string]interface{}) ([]*eventschema.EventSchema, error) {
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var schemas []*eventschema.EventSchema
+	for rows.Next() {
+		var schema eventschema.EventSchema
+		if err := rows.StructScan(&schema); err != nil {
+			return nil, fmt.Errorf("failed to scan event schema: %w", err)
+		}
+		schemas = append(schemas, &schema)
+	}
+
+	return schemas, nil
+}
+
+func (r *eventSchemaRepository) Update(ctx context.Context, schema *eventschema.EventSchema) error {
+	query := `
+		UPDATE event_schemas SET
+			rules = :rules,
+			on_invalid = :on_invalid,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating event schema",
+		"event_schema_id", schema.ID,
+		"tenant_id", schema.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, schema); err != nil {
+		return fmt.Errorf("failed to update event schema: %w", err)
+	}
+	return nil
+}
+
+func (r *eventSchemaRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE event_schemas SET
+			status = :status,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("deleting event schema",
+		"event_schema_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_by": types.GetUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to delete event schema: %w", err)
+	}
+	return nil
+}
+
+func (r *eventSchemaRepository) Quarantine(ctx context.Context, event *eventschema.QuarantinedEvent) error {
+	query := `
+		INSERT INTO quarantined_events (
+			id, tenant_id, event_name, external_customer_id, customer_id, source,
+			timestamp, properties, schema_id, violations, quarantine_status, resubmitted_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :event_name, :external_customer_id, :customer_id, :source,
+			:timestamp, :properties, :schema_id, :violations, :quarantine_status, :resubmitted_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)
+		ON CONFLICT (tenant_id, id) DO UPDATE SET
+			event_name = EXCLUDED.event_name,
+			external_customer_id = EXCLUDED.external_customer_id,
+			customer_id = EXCLUDED.customer_id,
+			source = EXCLUDED.source,
+			timestamp = EXCLUDED.timestamp,
+			properties = EXCLUDED.properties,
+			schema_id = EXCLUDED.schema_id,
+			violations = EXCLUDED.violations,
+			quarantine_status = EXCLUDED.quarantine_status,
+			resubmitted_at = NULL,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by`
+
+	r.logger.Debug("quarantining event",
+		"event_id", event.ID,
+		"event_name", event.EventName,
+		"tenant_id", event.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, event); err != nil {
+		return fmt.Errorf("failed to quarantine event: %w", err)
+	}
+	return nil
+}
+
+func (r *eventSchemaRepository) GetQuarantinedEvent(ctx context.Context, id string) (*eventschema.QuarantinedEvent, error) {
+	events, err := r.listQuarantinedEvents(ctx, "SELECT * FROM quarantined_events WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("quarantined event not found")
+	}
+	return events[0], nil
+}
+
+func (r *eventSchemaRepository) ListQuarantinedEvents(ctx context.Context, filter *types.QuarantinedEventFilter) ([]*eventschema.QuarantinedEvent, error) {
+	query := `
+		SELECT * FROM quarantined_events
+		WHERE tenant_id = :tenant_id AND status = :status
+	`
+	params := filter.ToMap()
+	params["tenant_id"] = types.GetTenantID(ctx)
+	params["status"] = types.StatusPublished
+
+	if filter.EventName != "" {
+		query += " AND event_name = :event_name"
+	}
+	if filter.QuarantineStatus != "" {
+		query += " AND quarantine_status = :quarantine_status"
+	}
+
+	query += " ORDER BY created_at DESC LIMIT :limit OFFSET :offset"
+
+	return r.listQuarantinedEvents(ctx, query, params)
+}
+
+func (r *eventSchemaRepository) listQuarantinedEvents(ctx context.Context, query string, params map[string]interface{}) ([]*eventschema.QuarantinedEvent, error) {
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantined events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*eventschema.QuarantinedEvent
+	for rows.Next() {
+		var event eventschema.QuarantinedEvent
+		if err := rows.StructScan(&event); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+func (r *eventSchemaRepository) UpdateQuarantinedEvent(ctx context.Context, event *eventschema.QuarantinedEvent) error {
+	query := `
+		UPDATE quarantined_events SET
+			properties = :properties,
+			violations = :violations,
+			quarantine_status = :quarantine_status,
+			resubmitted_at = :resubmitted_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating quarantined event",
+		"event_id", event.ID,
+		"tenant_id", event.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, event); err != nil {
+		return fmt.Errorf("failed to update quarantined event: %w", err)
+	}
+	return nil
+}
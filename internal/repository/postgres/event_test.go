@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bindQuery binds the named parameters of a query as the repository does,
+// failing on a parameter the query uses and the params miss
+func bindQuery(t *testing.T, query string, params map[string]interface{}) (string, []interface{}) {
+	query, args, err := sqlx.Named(query, params)
+	require.NoError(t, err)
+	query, args, err = sqlx.In(query, args...)
+	require.NoError(t, err)
+	return sqlx.Rebind(sqlx.DOLLAR, query), args
+}
+
+// squash collapses the whitespace of a query so that it can be matched
+func squash(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func usageContext() context.Context {
+	return context.WithValue(context.Background(), types.CtxTenantID, "tenant_1")
+}
+
+func TestInsertEventsQuery(t *testing.T) {
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newEvents := func(n int) []*events.Event {
+		list := make([]*events.Event, 0, n)
+		for i := 0; i < n; i++ {
+			list = append(list, events.NewEvent("api_request", "tenant_1", "acme",
+				map[string]interface{}{"size": i}, timestamp, fmt.Sprintf("evt_%d", i), "", ""))
+		}
+		return list
+	}
+
+	t.Run("binds the columns of every event", func(t *testing.T) {
+		query, params, err := insertEventsQuery(newEvents(2))
+		require.NoError(t, err)
+
+		bound, args := bindQuery(t, query, params)
+		assert.Len(t, args, 20)
+		assert.Contains(t, squash(bound), "VALUES ($1, $2, $3, $4, $5, $6, $7, CAST($8 AS JSONB), $9, $10), ($11,")
+		assert.Contains(t, squash(bound), "ON CONFLICT (tenant_id, id, timestamp) DO NOTHING")
+	})
+
+	t.Run("keeps a batch under the parameter limit of a statement", func(t *testing.T) {
+		query, params, err := insertEventsQuery(newEvents(insertEventsBatchSize))
+		require.NoError(t, err)
+
+		_, args := bindQuery(t, query, params)
+		assert.LessOrEqual(t, len(args), 65535)
+	})
+
+	t.Run("rejects an invalid event", func(t *testing.T) {
+		invalid := newEvents(1)
+		invalid[0].ExternalCustomerID = ""
+		_, _, err := insertEventsQuery(invalid)
+		assert.Error(t, err)
+	})
+}
+
+func TestAggregation(t *testing.T) {
+	rows := "SELECT properties, id, correction_of, sign FROM events"
+
+	tests := []struct {
+		name        string
+		aggregation types.AggregationType
+		percentile  float64
+		contains    []string
+	}{
+		{
+			name:        "count adds up the signs",
+			aggregation: types.AggregationCount,
+			contains:    []string{"SELECT CAST(GREATEST(SUM(sign), 0) AS DOUBLE PRECISION) AS total FROM (" + rows + ") AS e"},
+		},
+		{
+			name:        "sum weighs the values with their sign",
+			aggregation: types.AggregationSum,
+			contains:    []string{"COALESCE(SUM((CASE", "END) * sign), 0) AS total"},
+		},
+		{
+			name:        "avg divides by the events not reversed",
+			aggregation: types.AggregationAvg,
+			contains:    []string{"CASE WHEN SUM(sign) > 0 THEN SUM((CASE", "END) * sign) / SUM(sign) ELSE 0 END AS total"},
+		},
+		{
+			name:        "count unique counts the netted values",
+			aggregation: types.AggregationCountUnique,
+			contains: []string{
+				"SELECT CAST(COUNT(DISTINCT value) AS DOUBLE PRECISION) AS total",
+				"GROUP BY COALESCE(NULLIF(correction_of, ''), id), value HAVING SUM(sign) > 0",
+			},
+		},
+		{
+			name:        "percentile orders the netted values",
+			aggregation: types.AggregationPercentile,
+			percentile:  95,
+			contains: []string{
+				"COALESCE(percentile_disc(0.95) WITHIN GROUP (ORDER BY CASE",
+				"GROUP BY COALESCE(NULLIF(correction_of, ''), id), value HAVING SUM(sign) > 0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &events.UsageParams{
+				EventName:       "api_request",
+				PropertyName:    "size",
+				AggregationType: tt.aggregation,
+				Percentile:      tt.percentile,
+			}
+
+			query, err := aggregation(params, rows, nil)
+			require.NoError(t, err)
+			for _, fragment := range tt.contains {
+				assert.Contains(t, squash(query), fragment)
+			}
+			assert.NotContains(t, query, "GROUP BY window_size")
+
+			grouped, err := aggregation(params, rows, []string{"window_size"})
+			require.NoError(t, err)
+			assert.Contains(t, squash(grouped), "SELECT window_size, ")
+			assert.True(t, strings.HasSuffix(squash(grouped), "GROUP BY window_size ORDER BY window_size"), grouped)
+
+			// The property is the only parameter of an aggregation
+			bindQuery(t, query, map[string]interface{}{"property": "size"})
+		})
+	}
+
+	t.Run("rejects an unsupported aggregation", func(t *testing.T) {
+		_, err := aggregation(&events.UsageParams{AggregationType: types.AggregationFormula}, rows, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestNettedValues(t *testing.T) {
+	query := squash(nettedValues("filter_group_id, ", "GROUP BY filter_group_id ORDER BY filter_group_id",
+		"SELECT * FROM events", []string{"filter_group_id"}, "COUNT(DISTINCT value)"))
+
+	// The values are netted per event and group before they are aggregated
+	assert.Contains(t, query, "SELECT filter_group_id, COUNT(DISTINCT value) AS total FROM ( SELECT filter_group_id, value")
+	assert.Contains(t, query, "FROM (SELECT e.*, properties -> :property AS value FROM (SELECT * FROM events) AS e) AS v")
+	assert.Contains(t, query, "WHERE value IS NOT NULL GROUP BY filter_group_id, COALESCE(NULLIF(correction_of, ''), id), value HAVING SUM(sign) > 0")
+	assert.True(t, strings.HasSuffix(query, ") AS netted GROUP BY filter_group_id ORDER BY filter_group_id"), query)
+}
+
+func TestUsageQuery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	end := start.AddDate(0, 1, 0)
+
+	t.Run("matches the events of the usage", func(t *testing.T) {
+		q := newUsageQuery(usageContext(), &events.UsageParams{
+			EventName:          "api_request",
+			PropertyName:       "size",
+			ExternalCustomerID: "acme",
+			StartTime:          start,
+			EndTime:            end,
+		})
+
+		assert.Equal(t, "tenant_id = :tenant_id AND event_name = :event_name AND timestamp >= :start_time"+
+			" AND timestamp < :end_time AND external_customer_id = :external_customer_id", q.where())
+		assert.Equal(t, "tenant_1", q.params["tenant_id"])
+		assert.Equal(t, start.UTC(), q.params["start_time"])
+	})
+
+	t.Run("filters on sorted keys and skips the empty ones", func(t *testing.T) {
+		q := newUsageQuery(usageContext(), &events.UsageParams{
+			EventName: "api_request",
+			Filters: map[string][]string{
+				"region": {"us", "eu"},
+				"model":  {"small"},
+				"tier":   {},
+			},
+		})
+
+		assert.Equal(t, "tenant_id = :tenant_id AND event_name = :event_name"+
+			" AND properties ->> :filter_0_key IN (:filter_0_values) AND properties ->> :filter_1_key IN (:filter_1_values)", q.where())
+		assert.Equal(t, "model", q.params["filter_0_key"])
+		assert.Equal(t, "region", q.params["filter_1_key"])
+		assert.NotContains(t, q.params, "filter_2_key")
+
+		// The values of a filter are expanded into the IN list
+		bound, args := bindQuery(t, "SELECT 1 FROM events WHERE "+q.where(), q.params)
+		assert.Contains(t, bound, "properties ->> $3 IN ($4) AND properties ->> $5 IN ($6, $7)")
+		assert.Equal(t, []interface{}{"tenant_1", "api_request", "model", "small", "region", "us", "eu"}, args)
+	})
+
+	t.Run("filters nothing without values", func(t *testing.T) {
+		q := &usageQuery{params: map[string]interface{}{}}
+		assert.Empty(t, q.filterCondition("filter", map[string][]string{"region": nil}))
+		assert.Empty(t, q.params)
+	})
+}
@@ -0,0 +1,306 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/export"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type exportRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewExportRepository(db *postgres.DB, logger *logger.Logger) export.Repository {
+	return &exportRepository{db: db, logger: logger}
+}
+
+func (r *exportRepository) Create(ctx context.Context, e *export.Export) error {
+	query := `
+		INSERT INTO exports (
+			id, tenant_id, export_type, export_format, export_status, event_name, meter_id,
+			external_customer_id, window_size, start_time, end_time, object_key, row_count, error,
+			completed_at, schedule_id, lease_until, status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :export_type, :export_format, :export_status, :event_name, :meter_id,
+			:external_customer_id, :window_size, :start_time, :end_time, :object_key, :row_count, :error,
+			:completed_at, :schedule_id, :lease_until, :status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating export",
+		"export_id", e.ID,
+		"tenant_id", e.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, e); err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	return nil
+}
+
+func (r *exportRepository) Get(ctx context.Context, id string) (*export.Export, error) {
+	var e export.Export
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM exports WHERE id = :id AND tenant_id = :tenant_id", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("export not found")
+	}
+
+	if err := rows.StructScan(&e); err != nil {
+		return nil, fmt.Errorf("failed to scan export: %w", err)
+	}
+
+	return &e, nil
+}
+
+func (r *exportRepository) List(ctx context.Context, filter types.Filter) ([]*export.Export, error) {
+	query := `
+		SELECT * FROM exports WHERE tenant_id = :tenant_id ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+
+	return r.listExports(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+}
+
+func (r *exportRepository) listExports(ctx context.Context, query string, params map[string]interface{}) ([]*export.Export, error) {
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+	return scanExports(rows)
+}
+
+func scanExports(rows *sqlx.Rows) ([]*export.Export, error) {
+	defer rows.Close()
+
+	var exports []*export.Export
+	for rows.Next() {
+		var e export.Export
+		if err := rows.StructScan(&e); err != nil {
+			return nil, fmt.Errorf("failed to scan export: %w", err)
+		}
+		exports = append(exports, &e)
+	}
+
+	return exports, nil
+}
+
+func (r *exportRepository) Update(ctx context.Context, e *export.Export) error {
+	query := `
+		UPDATE exports SET
+			export_status = :export_status,
+			object_key = :object_key,
+			row_count = :row_count,
+			error = :error,
+			completed_at = :completed_at,
+			lease_until = :lease_until,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating export",
+		"export_id", e.ID,
+		"tenant_id", e.TenantID,
+		"export_status", e.ExportStatus,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, e); err != nil {
+		return fmt.Errorf("failed to update export: %w", err)
+	}
+	return nil
+}
+
+func (r *exportRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*export.Export, error) {
+	// Deliberately not tenant scoped: the scheduler runs the exports of all tenants
+	query := `
+		UPDATE exports SET export_status = :processing, lease_until = :lease_until, updated_at = :now
+		WHERE id IN (
+			SELECT id FROM exports
+			WHERE export_status = :pending OR (export_status = :processing AND lease_until < :now)
+			ORDER BY created_at
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	// The claim writes, so it never goes to a read replica
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"pending":     types.ExportStatusPending,
+		"processing":  types.ExportStatusProcessing,
+		"lease_until": now.Add(lease),
+		"now":         now,
+		"limit":       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim exports: %w", err)
+	}
+	return scanExports(rows)
+}
+
+func (r *exportRepository) CreateSchedule(ctx context.Context, s *export.Schedule) error {
+	query := `
+		INSERT INTO export_schedules (
+			id, tenant_id, name, export_type, export_format, event_name, meter_id,
+			external_customer_id, window_size, schedule, next_run_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :name, :export_type, :export_format, :event_name, :meter_id,
+			:external_customer_id, :window_size, :schedule, :next_run_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating export schedule",
+		"schedule_id", s.ID,
+		"tenant_id", s.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, s); err != nil {
+		return fmt.Errorf("failed to create export schedule: %w", err)
+	}
+	return nil
+}
+
+func (r *exportRepository) GetSchedule(ctx context.Context, id string) (*export.Schedule, error) {
+	schedules, err := r.listSchedules(ctx, "SELECT * FROM export_schedules WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return nil, fmt.Errorf("export schedule not found")
+	}
+
+	return schedules[0], nil
+}
+
+func (r *exportRepository) ListSchedules(ctx context.Context, filter types.Filter) ([]*export.Schedule, error) {
+	query := `
+		SELECT * FROM export_schedules
+		WHERE tenant_id = :tenant_id AND status = :status
+		ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+
+	return r.listSchedules(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+}
+
+func (r *exportRepository) listSchedules(ctx context.Context, query string, params map[string]interface{}) ([]*export.Schedule, error) {
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export schedules: %w", err)
+	}
+	return scanSchedules(rows)
+}
+
+func scanSchedules(rows *sqlx.Rows) ([]*export.Schedule, error) {
+	defer rows.Close()
+
+	var schedules []*export.Schedule
+	for rows.Next() {
+		var s export.Schedule
+		if err := rows.StructScan(&s); err != nil {
+			return nil, fmt.Errorf("failed to scan export schedule: %w", err)
+		}
+		schedules = append(schedules, &s)
+	}
+
+	return schedules, nil
+}
+
+func (r *exportRepository) UpdateSchedule(ctx context.Context, s *export.Schedule) error {
+	query := `
+		UPDATE export_schedules SET
+			next_run_at = :next_run_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating export schedule",
+		"schedule_id", s.ID,
+		"tenant_id", s.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, s); err != nil {
+		return fmt.Errorf("failed to update export schedule: %w", err)
+	}
+	return nil
+}
+
+func (r *exportRepository) DeleteSchedule(ctx context.Context, id string) error {
+	query := `
+		UPDATE export_schedules
+		SET status = :status, updated_at = :updated_at, updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id AND status <> :status`
+
+	r.logger.Debug("deleting export schedule",
+		"schedule_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_at": time.Now().UTC(),
+		"updated_by": types.GetUserID(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete export schedule: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("export schedule not found")
+	}
+
+	return nil
+}
+
+func (r *exportRepository) ClaimDueSchedules(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*export.Schedule, error) {
+	// Deliberately not tenant scoped: the scheduler runs the exports of all tenants
+	query := `
+		UPDATE export_schedules SET next_run_at = :lease_until
+		WHERE id IN (
+			SELECT id FROM export_schedules
+			WHERE status = :status AND next_run_at <= :now
+			ORDER BY next_run_at
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"lease_until": now.Add(lease),
+		"status":      types.StatusPublished,
+		"now":         now,
+		"limit":       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim export schedules: %w", err)
+	}
+	return scanSchedules(rows)
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/featureflag"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type featureFlagRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewFeatureFlagRepository(db *postgres.DB, logger *logger.Logger) featureflag.Repository {
+	return &featureFlagRepository{db: db, logger: logger}
+}
+
+func (r *featureFlagRepository) Upsert(ctx context.Context, override *featureflag.Override) error {
+	query := `
+		INSERT INTO feature_flag_overrides (
+			id, tenant_id, environment_id, flag, enabled, status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :environment_id, :flag, :enabled, :status, :created_at, :updated_at, :created_by, :updated_by
+		)
+		ON CONFLICT (tenant_id, environment_id, flag) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by
+		RETURNING id, created_at, created_by`
+
+	r.logger.Debug("upserting feature flag override",
+		"tenant_id", override.TenantID,
+		"environment_id", override.EnvironmentID,
+		"flag", override.Flag,
+		"enabled", override.Enabled,
+	)
+
+	rows, err := r.db.NamedQueryContext(ctx, query, override)
+	if err != nil {
+		return fmt.Errorf("failed to upsert feature flag override: %w", err)
+	}
+	defer rows.Close()
+
+	// The override keeps its identity when replaced
+	if rows.Next() {
+		if err := rows.Scan(&override.ID, &override.CreatedAt, &override.CreatedBy); err != nil {
+			return fmt.Errorf("failed to scan feature flag override: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *featureFlagRepository) List(ctx context.Context, tenantID string) ([]*featureflag.Override, error) {
+	query := `
+		SELECT * FROM feature_flag_overrides
+		WHERE tenant_id = :tenant_id AND status = :status
+		ORDER BY flag, environment_id`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": tenantID,
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*featureflag.Override
+	for rows.Next() {
+		var override featureflag.Override
+		if err := rows.StructScan(&override); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag override: %w", err)
+		}
+		overrides = append(overrides, &override)
+	}
+
+	return overrides, nil
+}
+
+func (r *featureFlagRepository) Delete(ctx context.Context, tenantID string, flag types.FeatureFlag, environmentID string) error {
+	query := `
+		DELETE FROM feature_flag_overrides
+		WHERE tenant_id = :tenant_id AND environment_id = :environment_id AND flag = :flag`
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"tenant_id":      tenantID,
+		"environment_id": environmentID,
+		"flag":           flag,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag override: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("feature flag override not found")
+	}
+
+	return nil
+}
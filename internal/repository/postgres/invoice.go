@@ -0,0 +1,925 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type invoiceRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewInvoiceRepository(db *postgres.DB, logger *logger.Logger) invoice.Repository {
+	return &invoiceRepository{db: db, logger: logger}
+}
+
+func (r *invoiceRepository) Create(ctx context.Context, inv *invoice.Invoice) error {
+	// New rows start at the default version of the column
+	inv.Version = 1
+
+	query := `
+		INSERT INTO invoices (
+			id, tenant_id, invoice_number, customer_id, subscription_id, legal_entity_id, invoice_type, invoice_flow, invoice_status, currency,
+			total, amount_due, amount_paid, amount_processing, amount_remaining, payment_status, original_invoice_id, description, period_start, period_end, partial_period_behavior,
+			finalized_at, metadata, status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :invoice_number, :customer_id, :subscription_id, :legal_entity_id, :invoice_type, :invoice_flow, :invoice_status, :currency,
+			:total, :amount_due, :amount_paid, :amount_processing, :amount_remaining, :payment_status, :original_invoice_id, :description, :period_start, :period_end, :partial_period_behavior,
+			:finalized_at, :metadata, :status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating invoice",
+		"invoice_id", inv.ID,
+		"tenant_id", inv.TenantID,
+		"line_items", len(inv.LineItems),
+	)
+
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := r.db.NamedExecContext(ctx, query, inv); err != nil {
+			return fmt.Errorf("failed to create invoice: %w", err)
+		}
+
+		for _, item := range inv.LineItems {
+			if err := r.createLineItem(ctx, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *invoiceRepository) createLineItem(ctx context.Context, item *invoice.InvoiceLineItem) error {
+	query := `
+		INSERT INTO invoice_line_items (
+			id, tenant_id, invoice_id, customer_id, subscription_id, price_id, meter_id, display_name,
+			amount, quantity, currency, period_start, period_end, metadata,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :invoice_id, :customer_id, :subscription_id, :price_id, :meter_id, :display_name,
+			:amount, :quantity, :currency, :period_start, :period_end, :metadata,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, item); err != nil {
+		return fmt.Errorf("failed to create invoice line item: %w", err)
+	}
+	return nil
+}
+
+func (r *invoiceRepository) CreateLineItem(ctx context.Context, item *invoice.InvoiceLineItem) error {
+	r.logger.Debug("creating invoice line item",
+		"line_item_id", item.ID,
+		"invoice_id", item.InvoiceID,
+	)
+
+	return r.createLineItem(ctx, item)
+}
+
+func (r *invoiceRepository) UpdateLineItem(ctx context.Context, item *invoice.InvoiceLineItem) error {
+	query := `
+		UPDATE invoice_line_items SET
+			display_name = :display_name,
+			amount = :amount,
+			quantity = :quantity,
+			metadata = :metadata,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id AND status = :status`
+
+	r.logger.Debug("updating invoice line item",
+		"line_item_id", item.ID,
+		"invoice_id", item.InvoiceID,
+	)
+
+	result, err := r.db.NamedExecContext(ctx, query, item)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice line item: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("invoice line item not found")
+	}
+	return nil
+}
+
+func (r *invoiceRepository) DeleteLineItem(ctx context.Context, item *invoice.InvoiceLineItem) error {
+	query := `
+		UPDATE invoice_line_items SET
+			status = :deleted,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id AND status = :published`
+
+	r.logger.Debug("deleting invoice line item",
+		"line_item_id", item.ID,
+		"invoice_id", item.InvoiceID,
+	)
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         item.ID,
+		"tenant_id":  types.GetTenantID(ctx),
+		"deleted":    types.StatusDeleted,
+		"published":  types.StatusPublished,
+		"updated_at": item.UpdatedAt,
+		"updated_by": item.UpdatedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete invoice line item: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("invoice line item not found")
+	}
+	return nil
+}
+
+func (r *invoiceRepository) Get(ctx context.Context, id string) (*invoice.Invoice, error) {
+	var inv invoice.Invoice
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM invoices WHERE id = :id AND tenant_id = :tenant_id", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("invoice not found")
+	}
+
+	if err := rows.StructScan(&inv); err != nil {
+		return nil, fmt.Errorf("failed to scan invoice: %w", err)
+	}
+
+	lineItems, err := r.getLineItems(ctx, inv.ID)
+	if err != nil {
+		return nil, err
+	}
+	inv.LineItems = lineItems
+
+	return &inv, nil
+}
+
+func (r *invoiceRepository) getLineItems(ctx context.Context, invoiceID string) ([]*invoice.InvoiceLineItem, error) {
+	query := `
+		SELECT * FROM invoice_line_items
+		WHERE invoice_id = :invoice_id AND tenant_id = :tenant_id AND status = :status
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"invoice_id": invoiceID,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice line items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*invoice.InvoiceLineItem
+	for rows.Next() {
+		var item invoice.InvoiceLineItem
+		if err := rows.StructScan(&item); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice line item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	return items, nil
+}
+
+func (r *invoiceRepository) ListFinalizedLineItems(ctx context.Context, start, end time.Time) ([]*invoice.InvoiceLineItem, error) {
+	query := `
+		SELECT li.* FROM invoice_line_items li
+		JOIN invoices i ON i.id = li.invoice_id AND i.tenant_id = li.tenant_id
+		WHERE li.tenant_id = :tenant_id AND li.status = :status
+		AND i.invoice_status = :invoice_status
+		AND COALESCE(li.period_start, i.period_start, i.finalized_at) >= :start
+		AND COALESCE(li.period_start, i.period_start, i.finalized_at) < :end
+		ORDER BY li.created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":      types.GetTenantID(ctx),
+		"status":         types.StatusPublished,
+		"invoice_status": types.InvoiceStatusFinalized,
+		"start":          start,
+		"end":            end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list finalized line items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*invoice.InvoiceLineItem
+	for rows.Next() {
+		var item invoice.InvoiceLineItem
+		if err := rows.StructScan(&item); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice line item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	return items, nil
+}
+
+func (r *invoiceRepository) ListOutstanding(ctx context.Context, customerID string) ([]*invoice.Invoice, error) {
+	query := `
+		SELECT * FROM invoices
+		WHERE tenant_id = :tenant_id AND status = :status
+		AND invoice_status = :invoice_status AND amount_remaining > 0 AND written_off_at IS NULL`
+	params := map[string]interface{}{
+		"tenant_id":      types.GetTenantID(ctx),
+		"status":         types.StatusPublished,
+		"invoice_status": types.InvoiceStatusFinalized,
+	}
+
+	if customerID != "" {
+		query += " AND customer_id = :customer_id"
+		params["customer_id"] = customerID
+	}
+
+	query += " ORDER BY finalized_at ASC, id ASC"
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outstanding invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*invoice.Invoice
+	for rows.Next() {
+		var inv invoice.Invoice
+		if err := rows.StructScan(&inv); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, &inv)
+	}
+
+	return invoices, nil
+}
+
+func (r *invoiceRepository) ListWrittenOff(ctx context.Context, filter *types.WriteOffFilter) ([]*invoice.Invoice, error) {
+	query := `
+		SELECT * FROM invoices
+		WHERE tenant_id = :tenant_id AND status = :status AND written_off_at IS NOT NULL`
+	params := map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	}
+
+	if filter.CustomerID != "" {
+		query += " AND customer_id = :customer_id"
+		params["customer_id"] = filter.CustomerID
+	}
+	if filter.StartTime != nil {
+		query += " AND written_off_at >= :start_time"
+		params["start_time"] = *filter.StartTime
+	}
+	if filter.EndTime != nil {
+		query += " AND written_off_at < :end_time"
+		params["end_time"] = *filter.EndTime
+	}
+
+	query += " ORDER BY written_off_at ASC, id ASC"
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list written off invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*invoice.Invoice
+	for rows.Next() {
+		var inv invoice.Invoice
+		if err := rows.StructScan(&inv); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, &inv)
+	}
+
+	return invoices, nil
+}
+
+func (r *invoiceRepository) ListInvoicingTenantIDs(ctx context.Context) ([]string, error) {
+	// Deliberately not tenant scoped: the receivables snapshot job works across all tenants
+	query := `
+		SELECT DISTINCT tenant_id FROM invoices
+		WHERE status = :status AND invoice_status = :invoice_status
+		ORDER BY tenant_id`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"status":         types.StatusPublished,
+		"invoice_status": types.InvoiceStatusFinalized,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoicing tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenantIDs []string
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant id: %w", err)
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+
+	return tenantIDs, nil
+}
+
+func (r *invoiceRepository) ListForReconciliation(ctx context.Context, since time.Time) ([]*invoice.Invoice, error) {
+	// Deliberately not tenant scoped: the late events job works across all tenants
+	query := `
+		SELECT * FROM invoices
+		WHERE status = :status
+		AND invoice_type = :invoice_type
+		AND period_start IS NOT NULL AND period_end IS NOT NULL
+		AND (
+			invoice_status IN (:draft, :held)
+			OR (invoice_status = :finalized AND period_end > :since)
+		)
+		ORDER BY tenant_id, period_end`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"status":       types.StatusPublished,
+		"invoice_type": types.InvoiceTypeSubscription,
+		"draft":        types.InvoiceStatusDraft,
+		"held":         types.InvoiceStatusHeld,
+		"finalized":    types.InvoiceStatusFinalized,
+		"since":        since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoices for reconciliation: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*invoice.Invoice
+	for rows.Next() {
+		var inv invoice.Invoice
+		if err := rows.StructScan(&inv); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, &inv)
+	}
+
+	return invoices, nil
+}
+
+func (r *invoiceRepository) List(ctx context.Context, filter *types.InvoiceFilter) ([]*invoice.Invoice, error) {
+	query := `
+		SELECT * FROM invoices
+		WHERE tenant_id = :tenant_id
+	`
+	params := filter.ToMap()
+	params["tenant_id"] = types.GetTenantID(ctx)
+
+	if filter.CustomerID != "" {
+		query += " AND customer_id = :customer_id"
+	}
+	if filter.SubscriptionID != "" {
+		query += " AND subscription_id = :subscription_id"
+	}
+	if filter.InvoiceType != "" {
+		query += " AND invoice_type = :invoice_type"
+	}
+	if filter.InvoiceStatus != "" {
+		query += " AND invoice_status = :invoice_status"
+	}
+	if filter.OriginalInvoiceID != "" {
+		query += " AND original_invoice_id = :original_invoice_id"
+	}
+
+	query, err := paginate(query, filter.Filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*invoice.Invoice
+	for rows.Next() {
+		var inv invoice.Invoice
+		if err := rows.StructScan(&inv); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, &inv)
+	}
+
+	return invoices, nil
+}
+
+func (r *invoiceRepository) Update(ctx context.Context, inv *invoice.Invoice) error {
+	query := `
+		UPDATE invoices SET
+			invoice_number = :invoice_number,
+			invoice_type = :invoice_type,
+			invoice_status = :invoice_status,
+			total = :total,
+			amount_due = :amount_due,
+			amount_paid = :amount_paid,
+			amount_processing = :amount_processing,
+			amount_remaining = :amount_remaining,
+			payment_status = :payment_status,
+			original_invoice_id = :original_invoice_id,
+			description = :description,
+			finalized_at = :finalized_at,
+			written_off_at = :written_off_at,
+			write_off_reason = :write_off_reason,
+			amount_written_off = :amount_written_off,
+			metadata = :metadata,
+			updated_at = :updated_at,
+			updated_by = :updated_by,
+			version = version + 1
+		WHERE id = :id AND tenant_id = :tenant_id AND version = :version`
+
+	r.logger.Debug("updating invoice",
+		"invoice_id", inv.ID,
+		"tenant_id", inv.TenantID,
+		"invoice_status", inv.InvoiceStatus,
+		"version", inv.Version,
+	)
+
+	result, err := r.db.NamedExecContext(ctx, query, inv)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice: %w", err)
+	}
+	if err := checkVersionedUpdate(ctx, r.db, result, "invoices", "invoice", inv.ID); err != nil {
+		return err
+	}
+
+	inv.Version++
+	return nil
+}
+
+func (r *invoiceRepository) GetForUpdate(ctx context.Context, id string) (*invoice.Invoice, error) {
+	query := `
+		SELECT * FROM invoices
+		WHERE id = :id AND tenant_id = :tenant_id
+		FOR UPDATE`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("invoice not found")
+	}
+
+	var inv invoice.Invoice
+	if err := rows.StructScan(&inv); err != nil {
+		return nil, fmt.Errorf("failed to scan invoice: %w", err)
+	}
+
+	return &inv, nil
+}
+
+func (r *invoiceRepository) CreateCreditAllocation(ctx context.Context, allocation *invoice.CreditAllocation) error {
+	query := `
+		INSERT INTO credit_allocations (
+			id, tenant_id, credit_invoice_id, customer_id, target_type, target_id, amount, currency,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :credit_invoice_id, :customer_id, :target_type, :target_id, :amount, :currency,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating credit allocation",
+		"allocation_id", allocation.ID,
+		"credit_invoice_id", allocation.CreditInvoiceID,
+		"target_type", allocation.TargetType,
+		"target_id", allocation.TargetID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, allocation); err != nil {
+		return fmt.Errorf("failed to create credit allocation: %w", err)
+	}
+	return nil
+}
+
+func (r *invoiceRepository) ListCreditAllocations(ctx context.Context, creditInvoiceID string) ([]*invoice.CreditAllocation, error) {
+	query := `
+		SELECT * FROM credit_allocations
+		WHERE credit_invoice_id = :credit_invoice_id AND tenant_id = :tenant_id AND status = :status
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"credit_invoice_id": creditInvoiceID,
+		"tenant_id":         types.GetTenantID(ctx),
+		"status":            types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credit allocations: %w", err)
+	}
+	defer rows.Close()
+
+	var allocations []*invoice.CreditAllocation
+	for rows.Next() {
+		var allocation invoice.CreditAllocation
+		if err := rows.StructScan(&allocation); err != nil {
+			return nil, fmt.Errorf("failed to scan credit allocation: %w", err)
+		}
+		allocations = append(allocations, &allocation)
+	}
+
+	return allocations, nil
+}
+
+func (r *invoiceRepository) CreatePayment(ctx context.Context, payment *invoice.Payment) error {
+	query := `
+		INSERT INTO invoice_payments (
+			id, tenant_id, invoice_id, customer_id, amount, currency, payment_method_type, reference, paid_at,
+			connection_id, gateway_payment_id, amount_refunded,
+			payment_status, mandate_id, charge_date, failure_code, failure_reason, dunning_action,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :invoice_id, :customer_id, :amount, :currency, :payment_method_type, :reference, :paid_at,
+			:connection_id, :gateway_payment_id, :amount_refunded,
+			:payment_status, :mandate_id, :charge_date, :failure_code, :failure_reason, :dunning_action,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating invoice payment",
+		"payment_id", payment.ID,
+		"invoice_id", payment.InvoiceID,
+		"amount", payment.Amount,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, payment); err != nil {
+		return fmt.Errorf("failed to create invoice payment: %w", err)
+	}
+	return nil
+}
+
+func (r *invoiceRepository) GetPayment(ctx context.Context, id string) (*invoice.Payment, error) {
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM invoice_payments WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice payment: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("invoice payment not found")
+	}
+
+	var payment invoice.Payment
+	if err := rows.StructScan(&payment); err != nil {
+		return nil, fmt.Errorf("failed to scan invoice payment: %w", err)
+	}
+
+	return &payment, nil
+}
+
+func (r *invoiceRepository) UpdatePayment(ctx context.Context, payment *invoice.Payment) error {
+	query := `
+		UPDATE invoice_payments SET
+			amount_refunded = :amount_refunded,
+			paid_at = :paid_at,
+			payment_status = :payment_status,
+			gateway_payment_id = :gateway_payment_id,
+			failure_code = :failure_code,
+			failure_reason = :failure_reason,
+			dunning_action = :dunning_action,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	if _, err := r.db.NamedExecContext(ctx, query, payment); err != nil {
+		return fmt.Errorf("failed to update invoice payment: %w", err)
+	}
+	return nil
+}
+
+func (r *invoiceRepository) GetPaymentByGatewayID(ctx context.Context, gatewayPaymentID string) (*invoice.Payment, error) {
+	query := `
+		SELECT * FROM invoice_payments
+		WHERE gateway_payment_id = :gateway_payment_id AND tenant_id = :tenant_id AND status = :status`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"gateway_payment_id": gatewayPaymentID,
+		"tenant_id":          types.GetTenantID(ctx),
+		"status":             types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice payment: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("invoice payment not found")
+	}
+
+	var payment invoice.Payment
+	if err := rows.StructScan(&payment); err != nil {
+		return nil, fmt.Errorf("failed to scan invoice payment: %w", err)
+	}
+	return &payment, nil
+}
+
+func (r *invoiceRepository) ClaimDueDebits(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*invoice.Payment, error) {
+	// Deliberately not tenant scoped: the debit collection job works across all
+	// tenants. Claimed on the primary, the lease is what keeps two schedulers
+	// from submitting a debit twice
+	query := `
+		UPDATE invoice_payments SET lease_until = :lease_until
+		WHERE id IN (
+			SELECT id FROM invoice_payments
+			WHERE status = :status AND mandate_id <> '' AND charge_date <= :now
+				AND (payment_status = :scheduled OR (payment_status = :processing AND gateway_payment_id = ''))
+				AND (lease_until IS NULL OR lease_until < :now)
+			ORDER BY charge_date
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"lease_until": now.Add(lease),
+		"status":      types.StatusPublished,
+		"scheduled":   types.PaymentStatusScheduled,
+		"processing":  types.PaymentStatusProcessing,
+		"now":         now,
+		"limit":       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due debits: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*invoice.Payment
+	for rows.Next() {
+		var payment invoice.Payment
+		if err := rows.StructScan(&payment); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice payment: %w", err)
+		}
+		payments = append(payments, &payment)
+	}
+
+	return payments, nil
+}
+
+func (r *invoiceRepository) ListPayments(ctx context.Context, invoiceID string) ([]*invoice.Payment, error) {
+	query := `
+		SELECT * FROM invoice_payments
+		WHERE invoice_id = :invoice_id AND tenant_id = :tenant_id AND status = :status
+		ORDER BY paid_at ASC, created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"invoice_id": invoiceID,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoice payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*invoice.Payment
+	for rows.Next() {
+		var payment invoice.Payment
+		if err := rows.StructScan(&payment); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice payment: %w", err)
+		}
+		payments = append(payments, &payment)
+	}
+
+	return payments, nil
+}
+
+func (r *invoiceRepository) CreateRefund(ctx context.Context, refund *invoice.Refund) error {
+	query := `
+		INSERT INTO refunds (
+			id, tenant_id, payment_id, invoice_id, customer_id, amount, currency, reason, refund_status,
+			gateway_refund_id, failure_reason, credit_note_id,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :payment_id, :invoice_id, :customer_id, :amount, :currency, :reason, :refund_status,
+			:gateway_refund_id, :failure_reason, :credit_note_id,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating refund",
+		"refund_id", refund.ID,
+		"payment_id", refund.PaymentID,
+		"refund_status", refund.RefundStatus,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, refund); err != nil {
+		return fmt.Errorf("failed to create refund: %w", err)
+	}
+	return nil
+}
+
+func (r *invoiceRepository) ListRefunds(ctx context.Context, paymentID string) ([]*invoice.Refund, error) {
+	query := `
+		SELECT * FROM refunds
+		WHERE payment_id = :payment_id AND tenant_id = :tenant_id AND status = :status
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"payment_id": paymentID,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %w", err)
+	}
+	defer rows.Close()
+
+	var refunds []*invoice.Refund
+	for rows.Next() {
+		var refund invoice.Refund
+		if err := rows.StructScan(&refund); err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		refunds = append(refunds, &refund)
+	}
+
+	return refunds, nil
+}
+
+func (r *invoiceRepository) GetRefundByGatewayID(ctx context.Context, gatewayRefundID string) (*invoice.Refund, error) {
+	query := `
+		SELECT * FROM refunds
+		WHERE gateway_refund_id = :gateway_refund_id AND tenant_id = :tenant_id AND status = :status`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"gateway_refund_id": gatewayRefundID,
+		"tenant_id":         types.GetTenantID(ctx),
+		"status":            types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("refund not found")
+	}
+
+	var refund invoice.Refund
+	if err := rows.StructScan(&refund); err != nil {
+		return nil, fmt.Errorf("failed to scan refund: %w", err)
+	}
+	return &refund, nil
+}
+
+func (r *invoiceRepository) UpdateRefund(ctx context.Context, refund *invoice.Refund) error {
+	query := `
+		UPDATE refunds SET
+			refund_status = :refund_status,
+			failure_reason = :failure_reason,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	if _, err := r.db.NamedExecContext(ctx, query, refund); err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+	return nil
+}
+
+func (r *invoiceRepository) CreateDispute(ctx context.Context, dispute *invoice.Dispute) error {
+	query := `
+		INSERT INTO invoice_disputes (
+			id, tenant_id, invoice_id, customer_id, dispute_status, reason, lines, amount, currency,
+			resolution, resolution_note, credit_note_id, reissued_invoice_id, resolved_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :invoice_id, :customer_id, :dispute_status, :reason, :lines, :amount, :currency,
+			:resolution, :resolution_note, :credit_note_id, :reissued_invoice_id, :resolved_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating invoice dispute",
+		"dispute_id", dispute.ID,
+		"invoice_id", dispute.InvoiceID,
+		"amount", dispute.Amount,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, dispute); err != nil {
+		return fmt.Errorf("failed to create invoice dispute: %w", err)
+	}
+	return nil
+}
+
+func (r *invoiceRepository) GetDispute(ctx context.Context, id string) (*invoice.Dispute, error) {
+	query := `
+		SELECT * FROM invoice_disputes
+		WHERE id = :id AND tenant_id = :tenant_id AND status = :status`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice dispute: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("invoice dispute not found")
+	}
+
+	var dispute invoice.Dispute
+	if err := rows.StructScan(&dispute); err != nil {
+		return nil, fmt.Errorf("failed to scan invoice dispute: %w", err)
+	}
+	return &dispute, nil
+}
+
+func (r *invoiceRepository) GetActiveDispute(ctx context.Context, invoiceID string) (*invoice.Dispute, error) {
+	query := `
+		SELECT * FROM invoice_disputes
+		WHERE invoice_id = :invoice_id AND tenant_id = :tenant_id AND status = :status
+			AND dispute_status <> :resolved`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"invoice_id": invoiceID,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusPublished,
+		"resolved":   types.DisputeStatusResolved,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active invoice dispute: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	var dispute invoice.Dispute
+	if err := rows.StructScan(&dispute); err != nil {
+		return nil, fmt.Errorf("failed to scan invoice dispute: %w", err)
+	}
+	return &dispute, nil
+}
+
+func (r *invoiceRepository) ListDisputes(ctx context.Context, invoiceID string) ([]*invoice.Dispute, error) {
+	query := `
+		SELECT * FROM invoice_disputes
+		WHERE invoice_id = :invoice_id AND tenant_id = :tenant_id AND status = :status
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"invoice_id": invoiceID,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoice disputes: %w", err)
+	}
+	defer rows.Close()
+
+	var disputes []*invoice.Dispute
+	for rows.Next() {
+		var dispute invoice.Dispute
+		if err := rows.StructScan(&dispute); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice dispute: %w", err)
+		}
+		disputes = append(disputes, &dispute)
+	}
+
+	return disputes, nil
+}
+
+func (r *invoiceRepository) UpdateDispute(ctx context.Context, dispute *invoice.Dispute) error {
+	query := `
+		UPDATE invoice_disputes SET
+			dispute_status = :dispute_status,
+			resolution = :resolution,
+			resolution_note = :resolution_note,
+			credit_note_id = :credit_note_id,
+			reissued_invoice_id = :reissued_invoice_id,
+			resolved_at = :resolved_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	if _, err := r.db.NamedExecContext(ctx, query, dispute); err != nil {
+		return fmt.Errorf("failed to update invoice dispute: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/job"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+)
+
+type jobRunRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewJobRunRepository(db *postgres.DB, logger *logger.Logger) job.RunRepository {
+	return &jobRunRepository{db: db, logger: logger}
+}
+
+func (r *jobRunRepository) Create(ctx context.Context, run *job.Run) error {
+	query := `
+		INSERT INTO job_runs (
+			id, job_name, trigger_type, status, triggered_by, started_at, finished_at, error
+		) VALUES (
+			:id, :job_name, :trigger_type, :status, :triggered_by, :started_at, :finished_at, :error
+		)`
+
+	r.logger.Debug("creating job run",
+		"run_id", run.ID,
+		"job_name", run.JobName,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, run); err != nil {
+		return fmt.Errorf("failed to create job run: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRunRepository) Update(ctx context.Context, run *job.Run) error {
+	query := `
+		UPDATE job_runs SET
+			status = :status,
+			finished_at = :finished_at,
+			error = :error
+		WHERE id = :id`
+
+	result, err := r.db.NamedExecContext(ctx, query, run)
+	if err != nil {
+		return fmt.Errorf("failed to update job run: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("job run not found")
+	}
+
+	return nil
+}
+
+func (r *jobRunRepository) ListRuns(ctx context.Context, jobName string, limit int) ([]*job.Run, error) {
+	query := `
+		SELECT * FROM job_runs
+		WHERE job_name = :job_name
+		ORDER BY started_at DESC
+		LIMIT :limit`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"job_name": jobName,
+		"limit":    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*job.Run
+	for rows.Next() {
+		var run job.Run
+		if err := rows.StructScan(&run); err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, nil
+}
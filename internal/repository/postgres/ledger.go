@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/ledger"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type ledgerRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewLedgerRepository(db *postgres.DB, logger *logger.Logger) ledger.Repository {
+	return &ledgerRepository{db: db, logger: logger}
+}
+
+func (r *ledgerRepository) Upsert(ctx context.Context, sync *ledger.Sync) error {
+	query := `
+		INSERT INTO ledger_syncs (
+			id, tenant_id, connection_id, entity_type, entity_id, sync_status,
+			external_id, attempts, last_error, synced_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :connection_id, :entity_type, :entity_id, :sync_status,
+			:external_id, :attempts, :last_error, :synced_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)
+		ON CONFLICT (tenant_id, connection_id, entity_type, entity_id) DO UPDATE SET
+			sync_status = EXCLUDED.sync_status,
+			attempts = EXCLUDED.attempts,
+			last_error = EXCLUDED.last_error,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by
+		RETURNING id, external_id, created_at, created_by`
+
+	r.logger.Debug("upserting ledger sync",
+		"connection_id", sync.ConnectionID,
+		"tenant_id", sync.TenantID,
+		"entity_id", sync.EntityID,
+	)
+
+	rows, err := r.db.NamedQueryContext(ctx, query, sync)
+	if err != nil {
+		return fmt.Errorf("failed to upsert ledger sync: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&sync.ID, &sync.ExternalID, &sync.CreatedAt, &sync.CreatedBy); err != nil {
+			return fmt.Errorf("failed to scan ledger sync: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *ledgerRepository) Update(ctx context.Context, sync *ledger.Sync) error {
+	query := `
+		UPDATE ledger_syncs SET
+			sync_status = :sync_status,
+			external_id = :external_id,
+			attempts = :attempts,
+			last_error = :last_error,
+			synced_at = :synced_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	if _, err := r.db.NamedExecContext(ctx, query, sync); err != nil {
+		return fmt.Errorf("failed to update ledger sync: %w", err)
+	}
+	return nil
+}
+
+func (r *ledgerRepository) ListByEntity(ctx context.Context, entityID string) ([]*ledger.Sync, error) {
+	query := `
+		SELECT * FROM ledger_syncs
+		WHERE tenant_id = :tenant_id AND entity_id = :entity_id AND status = :status
+		ORDER BY created_at`
+
+	return r.list(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"entity_id": entityID,
+		"status":    types.StatusPublished,
+	})
+}
+
+func (r *ledgerRepository) List(ctx context.Context, filter *types.LedgerSyncFilter) ([]*ledger.Sync, error) {
+	query := `
+		SELECT * FROM ledger_syncs
+		WHERE tenant_id = :tenant_id AND status = :status
+	`
+	params := filter.ToMap()
+	params["tenant_id"] = types.GetTenantID(ctx)
+	params["status"] = types.StatusPublished
+
+	if filter.ConnectionID != "" {
+		query += " AND connection_id = :connection_id"
+	}
+	if filter.SyncStatus != "" {
+		query += " AND sync_status = :sync_status"
+	}
+
+	query += " ORDER BY updated_at DESC LIMIT :limit OFFSET :offset"
+
+	return r.list(ctx, query, params)
+}
+
+func (r *ledgerRepository) list(ctx context.Context, query string, params map[string]interface{}) ([]*ledger.Sync, error) {
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger syncs: %w", err)
+	}
+	defer rows.Close()
+
+	var syncs []*ledger.Sync
+	for rows.Next() {
+		var sync ledger.Sync
+		if err := rows.StructScan(&sync); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger sync: %w", err)
+		}
+		syncs = append(syncs, &sync)
+	}
+
+	return syncs, nil
+}
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type legalEntityRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewLegalEntityRepository(db *postgres.DB, logger *logger.Logger) legalentity.Repository {
+	return &legalEntityRepository{db: db, logger: logger}
+}
+
+func (r *legalEntityRepository) Create(ctx context.Context, entity *legalentity.LegalEntity) error {
+	query := `
+		INSERT INTO legal_entities (
+			id, tenant_id, name, address, tax_ids, invoice_prefix, invoice_separator, invoice_padding,
+			invoice_reset_yearly, bank_details, pdf_template, is_default,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :name, :address, :tax_ids, :invoice_prefix, :invoice_separator, :invoice_padding,
+			:invoice_reset_yearly, :bank_details, :pdf_template, :is_default,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating legal entity",
+		"legal_entity_id", entity.ID,
+		"tenant_id", entity.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, entity); err != nil {
+		return fmt.Errorf("failed to create legal entity: %w", err)
+	}
+	return nil
+}
+
+func (r *legalEntityRepository) Get(ctx context.Context, id string) (*legalentity.LegalEntity, error) {
+	entity, err := r.getOne(ctx, "id = :id", map[string]interface{}{"id": id})
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("legal entity not found")
+	}
+	return entity, nil
+}
+
+func (r *legalEntityRepository) GetDefault(ctx context.Context) (*legalentity.LegalEntity, error) {
+	return r.getOne(ctx, "is_default", map[string]interface{}{})
+}
+
+func (r *legalEntityRepository) getOne(ctx context.Context, condition string, params map[string]interface{}) (*legalentity.LegalEntity, error) {
+	params["tenant_id"] = types.GetTenantID(ctx)
+	params["status"] = types.StatusPublished
+
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM legal_entities WHERE "+condition+" AND tenant_id = :tenant_id AND status = :status", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legal entity: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	var entity legalentity.LegalEntity
+	if err := rows.StructScan(&entity); err != nil {
+		return nil, fmt.Errorf("failed to scan legal entity: %w", err)
+	}
+
+	return &entity, nil
+}
+
+func (r *legalEntityRepository) List(ctx context.Context) ([]*legalentity.LegalEntity, error) {
+	var entities []*legalentity.LegalEntity
+	query := `
+		SELECT * FROM legal_entities WHERE tenant_id = :tenant_id AND status = :status ORDER BY name ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legal entities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity legalentity.LegalEntity
+		if err := rows.StructScan(&entity); err != nil {
+			return nil, fmt.Errorf("failed to scan legal entity: %w", err)
+		}
+		entities = append(entities, &entity)
+	}
+
+	return entities, nil
+}
+
+func (r *legalEntityRepository) Update(ctx context.Context, entity *legalentity.LegalEntity) error {
+	query := `
+		UPDATE legal_entities SET
+			name = :name,
+			address = :address,
+			tax_ids = :tax_ids,
+			invoice_prefix = :invoice_prefix,
+			invoice_separator = :invoice_separator,
+			invoice_padding = :invoice_padding,
+			invoice_reset_yearly = :invoice_reset_yearly,
+			bank_details = :bank_details,
+			pdf_template = :pdf_template,
+			is_default = :is_default,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	if _, err := r.db.NamedExecContext(ctx, query, entity); err != nil {
+		return fmt.Errorf("failed to update legal entity: %w", err)
+	}
+	return nil
+}
+
+func (r *legalEntityRepository) InUse(ctx context.Context, id string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM customers
+			WHERE tenant_id = :tenant_id AND legal_entity_id = :id AND status = :status
+		) OR EXISTS (
+			SELECT 1 FROM subscriptions
+			WHERE tenant_id = :tenant_id AND legal_entity_id = :id AND status = :status
+			AND subscription_status <> :cancelled
+		)`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+		"cancelled": types.SubscriptionStatusCancelled,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check legal entity assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var inUse bool
+	if rows.Next() {
+		if err := rows.Scan(&inUse); err != nil {
+			return false, fmt.Errorf("failed to scan legal entity assignments: %w", err)
+		}
+	}
+	return inUse, nil
+}
+
+func (r *legalEntityRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE legal_entities SET
+			status = :status,
+			is_default = FALSE,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("deleting legal entity",
+		"legal_entity_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_by": types.GetUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to delete legal entity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/mandate"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type mandateRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewMandateRepository(db *postgres.DB, logger *logger.Logger) mandate.Repository {
+	return &mandateRepository{db: db, logger: logger}
+}
+
+func (r *mandateRepository) Create(ctx context.Context, m *mandate.Mandate) error {
+	query := `
+		INSERT INTO mandates (
+			id, tenant_id, customer_id, payment_method_id, scheme, reference, mandate_status,
+			pre_notification_days, signed_at, revoked_at, revocation_reason,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :customer_id, :payment_method_id, :scheme, :reference, :mandate_status,
+			:pre_notification_days, :signed_at, :revoked_at, :revocation_reason,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating mandate",
+		"mandate_id", m.ID,
+		"customer_id", m.CustomerID,
+		"tenant_id", m.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, m); err != nil {
+		return fmt.Errorf("failed to create mandate: %w", err)
+	}
+	return nil
+}
+
+func (r *mandateRepository) Get(ctx context.Context, id string) (*mandate.Mandate, error) {
+	var m mandate.Mandate
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM mandates WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mandate: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("mandate not found")
+	}
+
+	if err := rows.StructScan(&m); err != nil {
+		return nil, fmt.Errorf("failed to scan mandate: %w", err)
+	}
+
+	return &m, nil
+}
+
+func (r *mandateRepository) ListByCustomer(ctx context.Context, customerID string) ([]*mandate.Mandate, error) {
+	var mandates []*mandate.Mandate
+	query := `
+		SELECT * FROM mandates
+		WHERE tenant_id = :tenant_id AND customer_id = :customer_id AND status = :status
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":   types.GetTenantID(ctx),
+		"customer_id": customerID,
+		"status":      types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mandates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m mandate.Mandate
+		if err := rows.StructScan(&m); err != nil {
+			return nil, fmt.Errorf("failed to scan mandate: %w", err)
+		}
+		mandates = append(mandates, &m)
+	}
+
+	return mandates, nil
+}
+
+func (r *mandateRepository) Update(ctx context.Context, m *mandate.Mandate) error {
+	query := `
+		UPDATE mandates SET
+			mandate_status = :mandate_status,
+			revoked_at = :revoked_at,
+			revocation_reason = :revocation_reason,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	if _, err := r.db.NamedExecContext(ctx, query, m); err != nil {
+		return fmt.Errorf("failed to update mandate: %w", err)
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"time"
+
 	"github.com/flexprice/flexprice/internal/domain/meter"
 	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/postgres"
@@ -20,59 +22,75 @@ func NewMeterRepository(db *postgres.DB, logger *logger.Logger) meter.Repository
 	return &meterRepository{db: db, logger: logger}
 }
 
-func (r *meterRepository) CreateMeter(ctx context.Context, meter *meter.Meter) error {
+func (r *meterRepository) CreateMeter(ctx context.Context, m *meter.Meter) error {
 	query := `
 	INSERT INTO meters (
-		id, tenant_id, name, event_name, filters, aggregation, reset_usage,
+		id, tenant_id, name, event_name, filters, aggregation, reset_usage, formula,
 		created_at, updated_at, created_by, updated_by, status
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 	)
 	`
 
-	aggregationJSON, err := json.Marshal(meter.Aggregation)
+	aggregationJSON, err := json.Marshal(m.Aggregation)
 	if err != nil {
 		return fmt.Errorf("marshal aggregation: %w", err)
 	}
 
-	filtersJSON, err := json.Marshal(meter.Filters)
+	filtersJSON, err := json.Marshal(m.Filters)
 	if err != nil {
 		return fmt.Errorf("marshal filters: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query,
-		meter.ID,
-		meter.TenantID,
-		meter.Name,
-		meter.EventName,
-		filtersJSON,
-		aggregationJSON,
-		meter.ResetUsage,
-		meter.CreatedAt,
-		meter.UpdatedAt,
-		meter.CreatedBy,
-		meter.UpdatedBy,
-		meter.Status,
-	)
-
-	if err != nil {
-		return fmt.Errorf("insert meter: %w", err)
+	// Event meters have no formula, stored as NULL
+	var formulaJSON interface{}
+	if m.Formula != nil {
+		data, err := json.Marshal(m.Formula)
+		if err != nil {
+			return fmt.Errorf("marshal formula: %w", err)
+		}
+		formulaJSON = data
 	}
 
-	return nil
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		_, err := r.db.GetQuerier(ctx).ExecContext(ctx, query,
+			m.ID,
+			m.TenantID,
+			m.Name,
+			m.EventName,
+			filtersJSON,
+			aggregationJSON,
+			m.ResetUsage,
+			formulaJSON,
+			m.CreatedAt,
+			m.UpdatedAt,
+			m.CreatedBy,
+			m.UpdatedBy,
+			m.Status,
+		)
+		if err != nil {
+			return fmt.Errorf("insert meter: %w", err)
+		}
+
+		// Derived meters are versioned through the meters of their formula
+		if m.IsDerived() {
+			return nil
+		}
+		return r.insertVersion(ctx, meter.NewVersion(m))
+	})
 }
 
 func (r *meterRepository) GetMeter(ctx context.Context, id string) (*meter.Meter, error) {
 	query := `
 	SELECT 
-		id, tenant_id, name, event_name, filters, aggregation, reset_usage,
+		id, tenant_id, name, event_name, filters, aggregation, reset_usage, formula,
 		created_at, updated_at, created_by, updated_by, status
 	FROM meters 
 	WHERE id = $1 AND tenant_id = $2
 	`
 
 	var m meter.Meter
-	var filtersJSON, aggregationJSON []byte
+	var filtersJSON, aggregationJSON, formulaJSON []byte
 
 	err := r.db.QueryRowContext(ctx, query, id, types.GetTenantID(ctx)).Scan(
 		&m.ID,
@@ -82,6 +100,7 @@ func (r *meterRepository) GetMeter(ctx context.Context, id string) (*meter.Meter
 		&filtersJSON,
 		&aggregationJSON,
 		&m.ResetUsage,
+		&formulaJSON,
 		&m.CreatedAt,
 		&m.UpdatedAt,
 		&m.CreatedBy,
@@ -107,13 +126,20 @@ func (r *meterRepository) GetMeter(ctx context.Context, id string) (*meter.Meter
 		}
 	}
 
+	// Unmarshal formula of derived meters
+	if len(formulaJSON) > 0 {
+		if err := json.Unmarshal(formulaJSON, &m.Formula); err != nil {
+			return nil, fmt.Errorf("unmarshal formula: %w", err)
+		}
+	}
+
 	return &m, nil
 }
 
 func (r *meterRepository) GetAllMeters(ctx context.Context) ([]*meter.Meter, error) {
 	query := `
 	SELECT 
-		id, tenant_id, name, event_name, filters, aggregation, reset_usage,
+		id, tenant_id, name, event_name, filters, aggregation, reset_usage, formula,
 		created_at, updated_at, created_by, updated_by, status
 	FROM meters
 	WHERE status = $1 AND tenant_id = $2
@@ -129,7 +155,7 @@ func (r *meterRepository) GetAllMeters(ctx context.Context) ([]*meter.Meter, err
 	var meters []*meter.Meter
 	for rows.Next() {
 		var m meter.Meter
-		var filtersJSON, aggregationJSON []byte
+		var filtersJSON, aggregationJSON, formulaJSON []byte
 
 		err := rows.Scan(
 			&m.ID,
@@ -139,6 +165,7 @@ func (r *meterRepository) GetAllMeters(ctx context.Context) ([]*meter.Meter, err
 			&filtersJSON,
 			&aggregationJSON,
 			&m.ResetUsage,
+			&formulaJSON,
 			&m.CreatedAt,
 			&m.UpdatedAt,
 			&m.CreatedBy,
@@ -163,6 +190,13 @@ func (r *meterRepository) GetAllMeters(ctx context.Context) ([]*meter.Meter, err
 			}
 		}
 
+		// Unmarshal formula of derived meters
+		if len(formulaJSON) > 0 {
+			if err := json.Unmarshal(formulaJSON, &m.Formula); err != nil {
+				return nil, fmt.Errorf("unmarshal formula: %w", err)
+			}
+		}
+
 		meters = append(meters, &m)
 	}
 
@@ -193,3 +227,170 @@ func (r *meterRepository) DisableMeter(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (r *meterRepository) CreateVersion(ctx context.Context, version *meter.MeterVersion) error {
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := r.insertVersion(ctx, version); err != nil {
+			return err
+		}
+		if version.EffectiveTo != nil {
+			return nil
+		}
+
+		aggregationJSON, err := json.Marshal(version.Aggregation)
+		if err != nil {
+			return fmt.Errorf("marshal aggregation: %w", err)
+		}
+
+		filtersJSON, err := json.Marshal(version.Filters)
+		if err != nil {
+			return fmt.Errorf("marshal filters: %w", err)
+		}
+
+		query := `
+		UPDATE meters
+		SET aggregation = $1, filters = $2, updated_at = $3, updated_by = $4
+		WHERE id = $5 AND tenant_id = $6
+		`
+
+		_, err = r.db.GetQuerier(ctx).ExecContext(ctx, query,
+			aggregationJSON,
+			filtersJSON,
+			version.CreatedAt,
+			version.CreatedBy,
+			version.MeterID,
+			version.TenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("update meter: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *meterRepository) insertVersion(ctx context.Context, version *meter.MeterVersion) error {
+	query := `
+	INSERT INTO meter_versions (
+		id, tenant_id, meter_id, version, aggregation, filters, effective_from, effective_to,
+		reaggregation_status, reaggregation_error, status, created_at, updated_at, created_by, updated_by
+	) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+	)
+	`
+
+	aggregationJSON, err := json.Marshal(version.Aggregation)
+	if err != nil {
+		return fmt.Errorf("marshal aggregation: %w", err)
+	}
+
+	filtersJSON, err := json.Marshal(version.Filters)
+	if err != nil {
+		return fmt.Errorf("marshal filters: %w", err)
+	}
+
+	_, err = r.db.GetQuerier(ctx).ExecContext(ctx, query,
+		version.ID,
+		version.TenantID,
+		version.MeterID,
+		version.Version,
+		aggregationJSON,
+		filtersJSON,
+		version.EffectiveFrom,
+		version.EffectiveTo,
+		version.ReaggregationStatus,
+		version.ReaggregationError,
+		version.Status,
+		version.CreatedAt,
+		version.UpdatedAt,
+		version.CreatedBy,
+		version.UpdatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("insert meter version: %w", err)
+	}
+
+	return nil
+}
+
+func (r *meterRepository) UpdateVersion(ctx context.Context, version *meter.MeterVersion) error {
+	query := `
+	UPDATE meter_versions
+	SET reaggregation_status = $1, reaggregation_error = $2, updated_at = $3, updated_by = $4
+	WHERE id = $5 AND tenant_id = $6
+	`
+
+	version.UpdatedAt = time.Now().UTC()
+	_, err := r.db.GetQuerier(ctx).ExecContext(ctx, query,
+		version.ReaggregationStatus,
+		version.ReaggregationError,
+		version.UpdatedAt,
+		version.UpdatedBy,
+		version.ID,
+		types.GetTenantID(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("update meter version: %w", err)
+	}
+
+	return nil
+}
+
+func (r *meterRepository) ListVersions(ctx context.Context, meterID string) ([]*meter.MeterVersion, error) {
+	query := `
+	SELECT
+		id, tenant_id, meter_id, version, aggregation, filters, effective_from, effective_to,
+		reaggregation_status, reaggregation_error, status, created_at, updated_at, created_by, updated_by
+	FROM meter_versions
+	WHERE meter_id = $1 AND tenant_id = $2
+	ORDER BY version
+	`
+
+	rows, err := r.db.GetReadQuerier(ctx).QueryContext(ctx, query, meterID, types.GetTenantID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query meter versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*meter.MeterVersion
+	for rows.Next() {
+		var v meter.MeterVersion
+		var filtersJSON, aggregationJSON []byte
+
+		err := rows.Scan(
+			&v.ID,
+			&v.TenantID,
+			&v.MeterID,
+			&v.Version,
+			&aggregationJSON,
+			&filtersJSON,
+			&v.EffectiveFrom,
+			&v.EffectiveTo,
+			&v.ReaggregationStatus,
+			&v.ReaggregationError,
+			&v.Status,
+			&v.CreatedAt,
+			&v.UpdatedAt,
+			&v.CreatedBy,
+			&v.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan meter version: %w", err)
+		}
+
+		if err := json.Unmarshal(filtersJSON, &v.Filters); err != nil {
+			return nil, fmt.Errorf("unmarshal filters: %w", err)
+		}
+		if err := json.Unmarshal(aggregationJSON, &v.Aggregation); err != nil {
+			return nil, fmt.Errorf("unmarshal aggregation: %w", err)
+		}
+
+		versions = append(versions, &v)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate meter versions: %w", err)
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// paginate appends the ordering and the page to a list query ordered newest
+// first. Cursor pagination seeks past the (created_at, id) of the cursor so a
+// page deep in a large table costs as much as the first one, offset pagination
+// is kept for the clients which have not moved to cursors
+func paginate(query string, filter types.Filter, params map[string]interface{}) (string, error) {
+	if !filter.UsesCursor() {
+		return query + " ORDER BY created_at DESC LIMIT :limit OFFSET :offset", nil
+	}
+
+	cursor, err := types.DecodeCursor(filter.Cursor)
+	if err != nil {
+		return "", err
+	}
+	if cursor != nil {
+		query += " AND (created_at, id) < (:cursor_created_at, :cursor_id)"
+		params["cursor_created_at"] = cursor.Time
+		params["cursor_id"] = cursor.ID
+	}
+
+	return query + " ORDER BY created_at DESC, id DESC LIMIT :limit", nil
+}
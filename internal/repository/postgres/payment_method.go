@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type paymentMethodRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewPaymentMethodRepository(db *postgres.DB, logger *logger.Logger) paymentmethod.Repository {
+	return &paymentMethodRepository{db: db, logger: logger}
+}
+
+func (r *paymentMethodRepository) Create(ctx context.Context, pm *paymentmethod.PaymentMethod) error {
+	query := `
+		INSERT INTO payment_methods (
+			id, tenant_id, customer_id, connection_id, provider, gateway_payment_method_id,
+			type, brand, last4, exp_month, exp_year,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :customer_id, :connection_id, :provider, :gateway_payment_method_id,
+			:type, :brand, :last4, :exp_month, :exp_year,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating payment method",
+		"payment_method_id", pm.ID,
+		"customer_id", pm.CustomerID,
+		"tenant_id", pm.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, pm); err != nil {
+		return fmt.Errorf("failed to create payment method: %w", err)
+	}
+	return nil
+}
+
+func (r *paymentMethodRepository) Get(ctx context.Context, id string) (*paymentmethod.PaymentMethod, error) {
+	var pm paymentmethod.PaymentMethod
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM payment_methods WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment method: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("payment method not found")
+	}
+
+	if err := rows.StructScan(&pm); err != nil {
+		return nil, fmt.Errorf("failed to scan payment method: %w", err)
+	}
+
+	return &pm, nil
+}
+
+func (r *paymentMethodRepository) ListByCustomer(ctx context.Context, customerID string) ([]*paymentmethod.PaymentMethod, error) {
+	var pms []*paymentmethod.PaymentMethod
+	query := `
+		SELECT * FROM payment_methods
+		WHERE tenant_id = :tenant_id AND customer_id = :customer_id AND status = :status
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":   types.GetTenantID(ctx),
+		"customer_id": customerID,
+		"status":      types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment methods: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pm paymentmethod.PaymentMethod
+		if err := rows.StructScan(&pm); err != nil {
+			return nil, fmt.Errorf("failed to scan payment method: %w", err)
+		}
+		pms = append(pms, &pm)
+	}
+
+	return pms, nil
+}
+
+func (r *paymentMethodRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE payment_methods SET
+			status = :status,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("deleting payment method",
+		"payment_method_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_by": types.GetUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to delete payment method: %w", err)
+	}
+	return nil
+}
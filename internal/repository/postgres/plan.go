@@ -21,6 +21,9 @@ func NewPlanRepository(db *postgres.DB, logger *logger.Logger) plan.Repository {
 }
 
 func (r *planRepository) Create(ctx context.Context, plan *plan.Plan) error {
+	// New rows start at the default version of the column
+	plan.Version = 1
+
 	query := `
 		INSERT INTO plans (
 			id, 
@@ -30,6 +33,7 @@ func (r *planRepository) Create(ctx context.Context, plan *plan.Plan) error {
 			description, 
 			invoice_cadence, 
 			trial_period, 
+			metadata, 
 			status, 
 			created_at, 
 			updated_at, 
@@ -44,6 +48,7 @@ func (r *planRepository) Create(ctx context.Context, plan *plan.Plan) error {
 			:description, 
 			:invoice_cadence, 
 			:trial_period, 
+			:metadata, 
 			:status, 
 			:created_at, 
 			:updated_at, 
@@ -74,7 +79,7 @@ func (r *planRepository) Get(ctx context.Context, id string) (*plan.Plan, error)
 	`
 
 	var p plan.Plan
-	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
 		"id":        id,
 		"tenant_id": types.GetTenantID(ctx),
 	})
@@ -100,17 +105,19 @@ func (r *planRepository) List(ctx context.Context, filter types.Filter) ([]*plan
 	query := `
 		SELECT * FROM plans 
 		WHERE tenant_id = :tenant_id 
-		AND status = :status
+		AND (status = :status OR (:include_deleted AND status = :deleted_status))
 		ORDER BY created_at DESC 
 		LIMIT :limit OFFSET :offset
 	`
 
 	var plans []*plan.Plan
-	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
-		"tenant_id": types.GetTenantID(ctx),
-		"status":    types.StatusPublished,
-		"limit":     filter.Limit,
-		"offset":    filter.Offset,
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":       types.GetTenantID(ctx),
+		"status":          types.StatusPublished,
+		"include_deleted": filter.IncludeDeleted,
+		"deleted_status":  types.StatusDeleted,
+		"limit":           filter.Limit,
+		"offset":          filter.Offset,
 	})
 	if err != nil {
 		r.logger.Error("failed to list plans", "error", err)
@@ -138,22 +145,31 @@ func (r *planRepository) Update(ctx context.Context, plan *plan.Plan) error {
 		description = :description, 
 		invoice_cadence = :invoice_cadence, 
 		trial_period = :trial_period, 
+		metadata = :metadata, 
 		updated_at = :updated_at, 
-		updated_by = :updated_by 
+		updated_by = :updated_by,
+		version = version + 1
 		WHERE id = :id 
 		AND tenant_id = :tenant_id
+		AND version = :version
 	`
 
 	r.logger.Debug("updating plan",
 		"plan_id", plan.ID,
 		"tenant_id", plan.TenantID,
+		"version", plan.Version,
 	)
 
-	_, err := r.db.NamedExecContext(ctx, query, plan)
+	result, err := r.db.NamedExecContext(ctx, query, plan)
 	if err != nil {
 		r.logger.Error("failed to update plan", "error", err)
 		return err
 	}
+	if err := checkVersionedUpdate(ctx, r.db, result, "plans", "plan", plan.ID); err != nil {
+		return err
+	}
+
+	plan.Version++
 	return nil
 }
 
@@ -161,8 +177,10 @@ func (r *planRepository) Delete(ctx context.Context, id string) error {
 	query := `
 		UPDATE plans 
 		SET status = :status, 
+		deleted_at = :updated_at,
 		updated_at = :updated_at, 
-		updated_by = :updated_by 
+		updated_by = :updated_by,
+		version = version + 1
 		WHERE id = :id
 		AND tenant_id = :tenant_id
 	`
@@ -185,3 +203,53 @@ func (r *planRepository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+func (r *planRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE plans
+		SET status = :status,
+		deleted_at = NULL,
+		updated_at = :updated_at,
+		updated_by = :updated_by,
+		version = version + 1
+		WHERE id = :id
+		AND tenant_id = :tenant_id
+		AND status = :deleted_status
+	`
+
+	r.logger.Debug("restoring plan",
+		"plan_id", id,
+	)
+
+	_, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":             id,
+		"status":         types.StatusPublished,
+		"deleted_status": types.StatusDeleted,
+		"updated_at":     time.Now().UTC(),
+		"updated_by":     types.GetUserID(ctx),
+		"tenant_id":      types.GetTenantID(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore plan: %w", err)
+	}
+	return nil
+}
+
+func (r *planRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM plans p
+		WHERE p.status = :status
+		AND p.deleted_at < :before
+		AND NOT EXISTS (SELECT 1 FROM subscriptions s WHERE s.tenant_id = p.tenant_id AND s.plan_id = p.id)
+		AND NOT EXISTS (SELECT 1 FROM prices pr WHERE pr.tenant_id = p.tenant_id AND pr.plan_id = p.id::text)
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"status": types.StatusDeleted,
+		"before": before,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted plans: %w", err)
+	}
+	return result.RowsAffected()
+}
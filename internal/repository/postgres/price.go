@@ -27,12 +27,12 @@ func (r *priceRepository) Create(ctx context.Context, price *price.Price) error
 			id, tenant_id, amount, display_amount, currency, plan_id, type, 
 			billing_period, billing_period_count, billing_model, billing_cadence, 
 			tier_mode, tiers, meter_id, filter_values, transform_quantity, lookup_key, description,
-			metadata, status, created_at, updated_at, created_by, updated_by
+			metadata, unit_cost, status, created_at, updated_at, created_by, updated_by
 		) VALUES (
 			:id, :tenant_id, :amount, :display_amount, :currency, :plan_id, :type,
 			:billing_period, :billing_period_count, :billing_model, :billing_cadence,
 			:tier_mode, :tiers, :meter_id, :filter_values, :transform_quantity, :lookup_key,
-			:description, :metadata, :status, :created_at, :updated_at, :created_by, :updated_by
+			:description, :metadata, :unit_cost, :status, :created_at, :updated_at, :created_by, :updated_by
 		)`
 
 	r.logger.Debug("creating price ",
@@ -56,7 +56,7 @@ func (r *priceRepository) Get(ctx context.Context, id string) (*price.Price, err
 		AND tenant_id = :tenant_id
 		AND status = :status`
 
-	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
 		"id":        id,
 		"tenant_id": types.GetTenantID(ctx),
 		"status":    types.StatusPublished,
@@ -86,7 +86,7 @@ func (r *priceRepository) GetByPlanID(ctx context.Context, planID string) ([]*pr
 		AND tenant_id = :tenant_id
 		AND status = :status`
 
-	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
 		"plan_id":   planID,
 		"tenant_id": types.GetTenantID(ctx),
 		"status":    types.StatusPublished,
@@ -112,16 +112,18 @@ func (r *priceRepository) List(ctx context.Context, filter types.Filter) ([]*pri
 	query := `
 		SELECT * FROM prices 
 		WHERE tenant_id = :tenant_id 
-		AND status = :status
+		AND (status = :status OR (:include_deleted AND status = :deleted_status))
 		ORDER BY created_at DESC 
 		LIMIT :limit OFFSET :offset`
 
 	// First, prepare the named query
-	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
-		"tenant_id": types.GetTenantID(ctx),
-		"status":    types.StatusPublished,
-		"limit":     filter.Limit,
-		"offset":    filter.Offset,
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":       types.GetTenantID(ctx),
+		"status":          types.StatusPublished,
+		"include_deleted": filter.IncludeDeleted,
+		"deleted_status":  types.StatusDeleted,
+		"limit":           filter.Limit,
+		"offset":          filter.Offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -153,6 +155,7 @@ func (r *priceRepository) Update(ctx context.Context, price *price.Price) error
 			lookup_key = :lookup_key,
 			description = :description,
 			metadata = :metadata,
+			unit_cost = :unit_cost,
 			status = :status,
 			updated_at = :updated_at,
 			updated_by = :updated_by
@@ -178,6 +181,7 @@ func (r *priceRepository) Delete(ctx context.Context, id string) error {
 	query := `
 		UPDATE prices SET 
 			status = :status,
+			deleted_at = :updated_at,
 			updated_at = :updated_at,
 			updated_by = :updated_by
 		WHERE id = :id 
@@ -187,7 +191,7 @@ func (r *priceRepository) Delete(ctx context.Context, id string) error {
 		"id":         id,
 		"tenant_id":  types.GetTenantID(ctx),
 		"status":     types.StatusDeleted,
-		"updated_at": time.Now(),
+		"updated_at": time.Now().UTC(),
 		"updated_by": types.GetUserID(ctx),
 	})
 	if err != nil {
@@ -195,3 +199,47 @@ func (r *priceRepository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+func (r *priceRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE prices SET
+			status = :status,
+			deleted_at = NULL,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id
+		AND tenant_id = :tenant_id
+		AND status = :deleted_status`
+
+	_, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":             id,
+		"tenant_id":      types.GetTenantID(ctx),
+		"status":         types.StatusPublished,
+		"deleted_status": types.StatusDeleted,
+		"updated_at":     time.Now().UTC(),
+		"updated_by":     types.GetUserID(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore price: %w", err)
+	}
+	return nil
+}
+
+func (r *priceRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM prices p
+		WHERE p.status = :status
+		AND p.deleted_at < :before
+		AND NOT EXISTS (SELECT 1 FROM subscription_line_items li WHERE li.tenant_id = p.tenant_id AND li.price_id = p.id::text)
+		AND NOT EXISTS (SELECT 1 FROM subscription_prorations sp WHERE sp.tenant_id = p.tenant_id AND sp.price_id = p.id::text)
+		AND NOT EXISTS (SELECT 1 FROM invoice_line_items il WHERE il.tenant_id = p.tenant_id AND il.price_id = p.id::text)`
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"status": types.StatusDeleted,
+		"before": before,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted prices: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type priceBookRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewPriceBookRepository(db *postgres.DB, logger *logger.Logger) pricebook.Repository {
+	return &priceBookRepository{db: db, logger: logger}
+}
+
+func (r *priceBookRepository) Create(ctx context.Context, book *pricebook.PriceBook) error {
+	query := `
+		INSERT INTO price_books (
+			id, tenant_id, name, plan_id, segment, overrides,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :name, :plan_id, :segment, :overrides,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating price book",
+		"price_book_id", book.ID,
+		"plan_id", book.PlanID,
+		"segment", book.Segment,
+		"tenant_id", book.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, book); err != nil {
+		return fmt.Errorf("failed to create price book: %w", err)
+	}
+	return nil
+}
+
+func (r *priceBookRepository) Get(ctx context.Context, id string) (*pricebook.PriceBook, error) {
+	var book pricebook.PriceBook
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM price_books WHERE id = :id AND tenant_id = :tenant_id", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price book: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("price book not found")
+	}
+
+	if err := rows.StructScan(&book); err != nil {
+		return nil, fmt.Errorf("failed to scan price book: %w", err)
+	}
+
+	return &book, nil
+}
+
+func (r *priceBookRepository) ListByPlan(ctx context.Context, planID string) ([]*pricebook.PriceBook, error) {
+	var books []*pricebook.PriceBook
+	query := `
+		SELECT * FROM price_books
+		WHERE tenant_id = :tenant_id AND plan_id = :plan_id AND status = :status
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"plan_id":   planID,
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price books: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book pricebook.PriceBook
+		if err := rows.StructScan(&book); err != nil {
+			return nil, fmt.Errorf("failed to scan price book: %w", err)
+		}
+		books = append(books, &book)
+	}
+
+	return books, nil
+}
+
+func (r *priceBookRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE price_books SET
+			status = :status,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("deleting price book",
+		"price_book_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_by": types.GetUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to delete price book: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type rateCardRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewRateCardRepository(db *postgres.DB, logger *logger.Logger) ratecard.Repository {
+	return &rateCardRepository{db: db, logger: logger}
+}
+
+func (r *rateCardRepository) Create(ctx context.Context, card *ratecard.RateCard) error {
+	query := `
+		INSERT INTO rate_cards (
+			id, tenant_id, name, customer_id, subscription_id, version,
+			effective_from, effective_to, overrides,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :name, :customer_id, :subscription_id, :version,
+			:effective_from, :effective_to, :overrides,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating rate card",
+		"rate_card_id", card.ID,
+		"customer_id", card.CustomerID,
+		"subscription_id", card.SubscriptionID,
+		"version", card.Version,
+		"tenant_id", card.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, card); err != nil {
+		return fmt.Errorf("failed to create rate card: %w", err)
+	}
+	return nil
+}
+
+func (r *rateCardRepository) Get(ctx context.Context, id string) (*ratecard.RateCard, error) {
+	var card ratecard.RateCard
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM rate_cards WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate card: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("rate card not found")
+	}
+
+	if err := rows.StructScan(&card); err != nil {
+		return nil, fmt.Errorf("failed to scan rate card: %w", err)
+	}
+
+	return &card, nil
+}
+
+func (r *rateCardRepository) ListByCustomer(ctx context.Context, customerID string) ([]*ratecard.RateCard, error) {
+	var cards []*ratecard.RateCard
+	query := `
+		SELECT * FROM rate_cards
+		WHERE tenant_id = :tenant_id AND customer_id = :customer_id AND status = :status
+		ORDER BY version ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":   types.GetTenantID(ctx),
+		"customer_id": customerID,
+		"status":      types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rate cards: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var card ratecard.RateCard
+		if err := rows.StructScan(&card); err != nil {
+			return nil, fmt.Errorf("failed to scan rate card: %w", err)
+		}
+		cards = append(cards, &card)
+	}
+
+	return cards, nil
+}
+
+func (r *rateCardRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE rate_cards SET
+			status = :status,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("deleting rate card",
+		"rate_card_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_by": types.GetUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to delete rate card: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/analytics"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type receivablesSnapshotRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewReceivablesSnapshotRepository(db *postgres.DB, logger *logger.Logger) analytics.ReceivablesRepository {
+	return &receivablesSnapshotRepository{db: db, logger: logger}
+}
+
+func (r *receivablesSnapshotRepository) ReplaceDay(ctx context.Context, day, asOf time.Time, snapshots []*analytics.ReceivablesSnapshot) error {
+	insertQuery := `
+		INSERT INTO receivables_snapshots (
+			id, tenant_id, snapshot_date, customer_id, currency,
+			current, days_30, days_60, days_90_plus, total, invoices,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :snapshot_date, :customer_id, :currency,
+			:current, :days_30, :days_60, :days_90_plus, :total, :invoices,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	params := map[string]interface{}{
+		"tenant_id":     types.GetTenantID(ctx),
+		"snapshot_date": day,
+		"as_of":         asOf,
+	}
+
+	r.logger.Debug("replacing receivables snapshots",
+		"tenant_id", params["tenant_id"],
+		"snapshot_date", day,
+		"snapshots", len(snapshots),
+	)
+
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := r.db.NamedExecContext(ctx, `
+			DELETE FROM receivables_snapshots WHERE tenant_id = :tenant_id AND snapshot_date = :snapshot_date`, params); err != nil {
+			return fmt.Errorf("failed to delete receivables snapshots: %w", err)
+		}
+
+		for _, snapshot := range snapshots {
+			if _, err := r.db.NamedExecContext(ctx, insertQuery, snapshot); err != nil {
+				return fmt.Errorf("failed to create receivables snapshot: %w", err)
+			}
+		}
+
+		if _, err := r.db.NamedExecContext(ctx, `
+			INSERT INTO receivables_snapshot_days (tenant_id, snapshot_date, as_of)
+			VALUES (:tenant_id, :snapshot_date, :as_of)
+			ON CONFLICT (tenant_id, snapshot_date) DO UPDATE SET as_of = EXCLUDED.as_of`, params); err != nil {
+			return fmt.Errorf("failed to record receivables snapshot day: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *receivablesSnapshotRepository) GetSnapshotAsOf(ctx context.Context, day time.Time) (*time.Time, error) {
+	query := `
+		SELECT as_of FROM receivables_snapshot_days
+		WHERE tenant_id = :tenant_id AND snapshot_date = :snapshot_date`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":     types.GetTenantID(ctx),
+		"snapshot_date": day,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receivables snapshot day: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	var asOf time.Time
+	if err := rows.Scan(&asOf); err != nil {
+		return nil, fmt.Errorf("failed to scan receivables snapshot day: %w", err)
+	}
+	asOf = asOf.UTC()
+	return &asOf, nil
+}
+
+func (r *receivablesSnapshotRepository) List(ctx context.Context, day time.Time, customerID string) ([]*analytics.ReceivablesSnapshot, error) {
+	query := `
+		SELECT * FROM receivables_snapshots
+		WHERE tenant_id = :tenant_id AND status = :status AND snapshot_date = :snapshot_date`
+	params := map[string]interface{}{
+		"tenant_id":     types.GetTenantID(ctx),
+		"status":        types.StatusPublished,
+		"snapshot_date": day,
+	}
+
+	if customerID != "" {
+		query += " AND customer_id = :customer_id"
+		params["customer_id"] = customerID
+	}
+
+	query += " ORDER BY customer_id ASC, currency ASC"
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receivables snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*analytics.ReceivablesSnapshot
+	for rows.Next() {
+		var snapshot analytics.ReceivablesSnapshot
+		if err := rows.StructScan(&snapshot); err != nil {
+			return nil, fmt.Errorf("failed to scan receivables snapshot: %w", err)
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,323 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/report"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type reportRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewReportRepository(db *postgres.DB, logger *logger.Logger) report.Repository {
+	return &reportRepository{db: db, logger: logger}
+}
+
+func (r *reportRepository) CreateTemplate(ctx context.Context, template *report.Template) error {
+	query := `
+		INSERT INTO report_templates (
+			id, tenant_id, name, entity, columns, filters, group_by,
+			period, schedule, next_run_at, destinations,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :name, :entity, :columns, :filters, :group_by,
+			:period, :schedule, :next_run_at, :destinations,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating report template",
+		"template_id", template.ID,
+		"tenant_id", template.TenantID,
+		"entity", template.Entity,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, template); err != nil {
+		return fmt.Errorf("failed to create report template: %w", err)
+	}
+	return nil
+}
+
+func (r *reportRepository) GetTemplate(ctx context.Context, id string) (*report.Template, error) {
+	var template report.Template
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM report_templates WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report template: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("report template not found")
+	}
+
+	if err := rows.StructScan(&template); err != nil {
+		return nil, fmt.Errorf("failed to scan report template: %w", err)
+	}
+
+	return &template, nil
+}
+
+func (r *reportRepository) ListTemplates(ctx context.Context, filter types.Filter) ([]*report.Template, error) {
+	query := `
+		SELECT * FROM report_templates
+		WHERE tenant_id = :tenant_id AND status = :status
+		ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+
+	return r.listTemplates(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+}
+
+func (r *reportRepository) listTemplates(ctx context.Context, query string, params map[string]interface{}) ([]*report.Template, error) {
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*report.Template
+	for rows.Next() {
+		var template report.Template
+		if err := rows.StructScan(&template); err != nil {
+			return nil, fmt.Errorf("failed to scan report template: %w", err)
+		}
+		templates = append(templates, &template)
+	}
+
+	return templates, nil
+}
+
+func (r *reportRepository) UpdateTemplate(ctx context.Context, template *report.Template) error {
+	query := `
+		UPDATE report_templates SET
+			name = :name,
+			columns = :columns,
+			filters = :filters,
+			group_by = :group_by,
+			period = :period,
+			schedule = :schedule,
+			next_run_at = :next_run_at,
+			destinations = :destinations,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating report template",
+		"template_id", template.ID,
+		"tenant_id", template.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, template); err != nil {
+		return fmt.Errorf("failed to update report template: %w", err)
+	}
+	return nil
+}
+
+func (r *reportRepository) DeleteTemplate(ctx context.Context, id string) error {
+	query := `
+		UPDATE report_templates
+		SET status = :status, updated_at = :updated_at, updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id AND status <> :status`
+
+	r.logger.Debug("deleting report template",
+		"template_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_at": time.Now().UTC(),
+		"updated_by": types.GetUserID(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete report template: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("report template not found")
+	}
+
+	return nil
+}
+
+func (r *reportRepository) ClaimDueTemplates(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*report.Template, error) {
+	// Deliberately not tenant scoped: the scheduler runs the reports of all tenants
+	query := `
+		UPDATE report_templates SET next_run_at = :lease_until
+		WHERE id IN (
+			SELECT id FROM report_templates
+			WHERE schedule <> '' AND status = :status AND next_run_at <= :now
+			ORDER BY next_run_at
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	return r.listTemplates(ctx, query, map[string]interface{}{
+		"lease_until": now.Add(lease),
+		"status":      types.StatusPublished,
+		"now":         now,
+		"limit":       limit,
+	})
+}
+
+func (r *reportRepository) CreateRun(ctx context.Context, run *report.Run) error {
+	query := `
+		INSERT INTO report_runs (
+			id, tenant_id, template_id, trigger, run_status, period_start, period_end,
+			object_key, row_count, error, completed_at, lease_until,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :template_id, :trigger, :run_status, :period_start, :period_end,
+			:object_key, :row_count, :error, :completed_at, :lease_until,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating report run",
+		"run_id", run.ID,
+		"tenant_id", run.TenantID,
+		"template_id", run.TemplateID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, run); err != nil {
+		return fmt.Errorf("failed to create report run: %w", err)
+	}
+	return nil
+}
+
+func (r *reportRepository) GetRun(ctx context.Context, id string) (*report.Run, error) {
+	var run report.Run
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM report_runs WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report run: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("report run not found")
+	}
+
+	if err := rows.StructScan(&run); err != nil {
+		return nil, fmt.Errorf("failed to scan report run: %w", err)
+	}
+
+	return &run, nil
+}
+
+func (r *reportRepository) ListRuns(ctx context.Context, filter *types.ReportRunFilter) ([]*report.Run, error) {
+	query := `
+		SELECT * FROM report_runs
+		WHERE tenant_id = :tenant_id AND status = :status`
+
+	params := map[string]interface{}{
+		"tenant_id":   types.GetTenantID(ctx),
+		"status":      types.StatusPublished,
+		"template_id": filter.TemplateID,
+		"limit":       filter.Limit,
+		"offset":      filter.Offset,
+	}
+
+	if filter.TemplateID != "" {
+		query += " AND template_id = :template_id"
+	}
+
+	query += " ORDER BY created_at DESC LIMIT :limit OFFSET :offset"
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report runs: %w", err)
+	}
+	return scanRuns(rows)
+}
+
+func scanRuns(rows *sqlx.Rows) ([]*report.Run, error) {
+	defer rows.Close()
+
+	var runs []*report.Run
+	for rows.Next() {
+		var run report.Run
+		if err := rows.StructScan(&run); err != nil {
+			return nil, fmt.Errorf("failed to scan report run: %w", err)
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, nil
+}
+
+func (r *reportRepository) UpdateRun(ctx context.Context, run *report.Run) error {
+	query := `
+		UPDATE report_runs SET
+			run_status = :run_status,
+			object_key = :object_key,
+			row_count = :row_count,
+			error = :error,
+			completed_at = :completed_at,
+			lease_until = :lease_until,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating report run",
+		"run_id", run.ID,
+		"tenant_id", run.TenantID,
+		"run_status", run.RunStatus,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, run); err != nil {
+		return fmt.Errorf("failed to update report run: %w", err)
+	}
+	return nil
+}
+
+func (r *reportRepository) ClaimPendingRuns(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*report.Run, error) {
+	// Deliberately not tenant scoped: the scheduler runs the reports of all tenants
+	query := `
+		UPDATE report_runs SET run_status = :processing, lease_until = :lease_until, updated_at = :now
+		WHERE id IN (
+			SELECT id FROM report_runs
+			WHERE status = :status AND (run_status = :pending OR (run_status = :processing AND lease_until < :now))
+			ORDER BY created_at
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	// The claim writes, so it never goes to a read replica
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"pending":     types.ReportRunStatusPending,
+		"processing":  types.ReportRunStatusProcessing,
+		"status":      types.StatusPublished,
+		"lease_until": now.Add(lease),
+		"now":         now,
+		"limit":       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim report runs: %w", err)
+	}
+	return scanRuns(rows)
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/analytics"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type revenueSnapshotRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewRevenueSnapshotRepository(db *postgres.DB, logger *logger.Logger) analytics.Repository {
+	return &revenueSnapshotRepository{db: db, logger: logger}
+}
+
+func (r *revenueSnapshotRepository) ReplaceMonth(ctx context.Context, month time.Time, snapshots []*analytics.RevenueSnapshot) error {
+	insertQuery := `
+		INSERT INTO revenue_snapshots (
+			id, tenant_id, month, subscription_id, customer_id, plan_id, segment, currency, mrr,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :month, :subscription_id, :customer_id, :plan_id, :segment, :currency, :mrr,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	params := map[string]interface{}{
+		"tenant_id":      types.GetTenantID(ctx),
+		"month":          month,
+		"snapshotted_at": time.Now().UTC(),
+	}
+
+	r.logger.Debug("replacing revenue snapshots",
+		"tenant_id", params["tenant_id"],
+		"month", month,
+		"snapshots", len(snapshots),
+	)
+
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := r.db.NamedExecContext(ctx, `
+			DELETE FROM revenue_snapshots WHERE tenant_id = :tenant_id AND month = :month`, params); err != nil {
+			return fmt.Errorf("failed to delete revenue snapshots: %w", err)
+		}
+
+		for _, snapshot := range snapshots {
+			if _, err := r.db.NamedExecContext(ctx, insertQuery, snapshot); err != nil {
+				return fmt.Errorf("failed to create revenue snapshot: %w", err)
+			}
+		}
+
+		if _, err := r.db.NamedExecContext(ctx, `
+			INSERT INTO revenue_snapshot_months (tenant_id, month, snapshotted_at)
+			VALUES (:tenant_id, :month, :snapshotted_at)
+			ON CONFLICT (tenant_id, month) DO UPDATE SET snapshotted_at = EXCLUDED.snapshotted_at`, params); err != nil {
+			return fmt.Errorf("failed to record revenue snapshot month: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *revenueSnapshotRepository) ListMonths(ctx context.Context, start, end time.Time) ([]time.Time, error) {
+	query := `
+		SELECT month FROM revenue_snapshot_months
+		WHERE tenant_id = :tenant_id AND month >= :start AND month < :end
+		ORDER BY month ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"start":     start,
+		"end":       end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revenue snapshot months: %w", err)
+	}
+	defer rows.Close()
+
+	var months []time.Time
+	for rows.Next() {
+		var month time.Time
+		if err := rows.Scan(&month); err != nil {
+			return nil, fmt.Errorf("failed to scan revenue snapshot month: %w", err)
+		}
+		months = append(months, month.UTC())
+	}
+
+	return months, nil
+}
+
+func (r *revenueSnapshotRepository) List(ctx context.Context, start, end time.Time) ([]*analytics.RevenueSnapshot, error) {
+	query := `
+		SELECT * FROM revenue_snapshots
+		WHERE tenant_id = :tenant_id AND status = :status
+		AND month >= :start AND month < :end
+		ORDER BY month ASC, subscription_id ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+		"start":     start,
+		"end":       end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revenue snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*analytics.RevenueSnapshot
+	for rows.Next() {
+		var snapshot analytics.RevenueSnapshot
+		if err := rows.StructScan(&snapshot); err != nil {
+			return nil, fmt.Errorf("failed to scan revenue snapshot: %w", err)
+		}
+		snapshot.Month = snapshot.Month.UTC()
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	return snapshots, nil
+}
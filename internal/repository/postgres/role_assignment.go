@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/role"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type roleAssignmentRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewRoleAssignmentRepository(db *postgres.DB, logger *logger.Logger) role.Repository {
+	return &roleAssignmentRepository{db: db, logger: logger}
+}
+
+func (r *roleAssignmentRepository) Create(ctx context.Context, assignment *role.Assignment) error {
+	query := `
+		INSERT INTO role_assignments (
+			id, tenant_id, user_id, environment_id, role,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :user_id, :environment_id, :role,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating role assignment",
+		"role_assignment_id", assignment.ID,
+		"user_id", assignment.UserID,
+		"role", assignment.Role,
+		"tenant_id", assignment.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, assignment); err != nil {
+		return fmt.Errorf("failed to create role assignment: %w", err)
+	}
+	return nil
+}
+
+func (r *roleAssignmentRepository) Get(ctx context.Context, id string) (*role.Assignment, error) {
+	var assignment role.Assignment
+	rows, err := r.db.NamedQueryContext(ctx, "SELECT * FROM role_assignments WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role assignment: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("role assignment not found")
+	}
+
+	if err := rows.StructScan(&assignment); err != nil {
+		return nil, fmt.Errorf("failed to scan role assignment: %w", err)
+	}
+
+	return &assignment, nil
+}
+
+func (r *roleAssignmentRepository) List(ctx context.Context, filter *types.RoleAssignmentFilter) ([]*role.Assignment, error) {
+	query := `SELECT * FROM role_assignments WHERE tenant_id = :tenant_id AND status = :status`
+	params := map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	}
+
+	if filter != nil {
+		if filter.UserID != "" {
+			query += " AND user_id = :user_id"
+			params["user_id"] = filter.UserID
+		}
+		if filter.EnvironmentID != "" {
+			query += " AND environment_id = :environment_id"
+			params["environment_id"] = filter.EnvironmentID
+		}
+	}
+	query += " ORDER BY user_id ASC, environment_id ASC"
+
+	return r.query(ctx, query, params)
+}
+
+func (r *roleAssignmentRepository) ListByUser(ctx context.Context, userID string) ([]*role.Assignment, error) {
+	query := `
+		SELECT * FROM role_assignments
+		WHERE tenant_id = :tenant_id AND user_id = :user_id AND status = :status
+		ORDER BY environment_id ASC`
+
+	return r.query(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"user_id":   userID,
+		"status":    types.StatusPublished,
+	})
+}
+
+func (r *roleAssignmentRepository) query(ctx context.Context, query string, params map[string]interface{}) ([]*role.Assignment, error) {
+	rows, err := r.db.NamedQueryContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*role.Assignment
+	for rows.Next() {
+		var assignment role.Assignment
+		if err := rows.StructScan(&assignment); err != nil {
+			return nil, fmt.Errorf("failed to scan role assignment: %w", err)
+		}
+		assignments = append(assignments, &assignment)
+	}
+
+	return assignments, nil
+}
+
+func (r *roleAssignmentRepository) Update(ctx context.Context, assignment *role.Assignment) error {
+	query := `
+		UPDATE role_assignments SET
+			role = :role,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id AND status = :status`
+
+	r.logger.Debug("updating role assignment",
+		"role_assignment_id", assignment.ID,
+		"role", assignment.Role,
+		"tenant_id", assignment.TenantID,
+	)
+
+	result, err := r.db.NamedExecContext(ctx, query, assignment)
+	if err != nil {
+		return fmt.Errorf("failed to update role assignment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("role assignment not found")
+	}
+	return nil
+}
+
+func (r *roleAssignmentRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE role_assignments SET
+			status = :status,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("deleting role assignment",
+		"role_assignment_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_by": types.GetUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to delete role assignment: %w", err)
+	}
+	return nil
+}
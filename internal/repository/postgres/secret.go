@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/secret"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type secretRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewSecretRepository(db *postgres.DB, logger *logger.Logger) secret.Repository {
+	return &secretRepository{db: db, logger: logger}
+}
+
+func (r *secretRepository) Create(ctx context.Context, s *secret.Secret) error {
+	query := `
+		INSERT INTO secrets (
+			id, tenant_id, name, prefix, key_hash, scopes, expires_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :name, :prefix, :key_hash, :scopes, :expires_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating secret",
+		"secret_id", s.ID,
+		"tenant_id", s.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, s); err != nil {
+		return fmt.Errorf("failed to create secret: %w", err)
+	}
+	return nil
+}
+
+func (r *secretRepository) Get(ctx context.Context, id string) (*secret.Secret, error) {
+	query := `SELECT * FROM secrets WHERE id = :id AND tenant_id = :tenant_id AND status = :status`
+
+	return r.getOne(ctx, query, map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+}
+
+func (r *secretRepository) GetByHash(ctx context.Context, keyHash string) (*secret.Secret, error) {
+	query := `SELECT * FROM secrets WHERE key_hash = :key_hash AND status = :status`
+
+	return r.getOne(ctx, query, map[string]interface{}{
+		"key_hash": keyHash,
+		"status":   types.StatusPublished,
+	})
+}
+
+func (r *secretRepository) getOne(ctx context.Context, query string, params map[string]interface{}) (*secret.Secret, error) {
+	rows, err := r.db.NamedQueryContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("secret not found")
+	}
+
+	var s secret.Secret
+	if err := rows.StructScan(&s); err != nil {
+		return nil, fmt.Errorf("failed to scan secret: %w", err)
+	}
+
+	return &s, nil
+}
+
+func (r *secretRepository) List(ctx context.Context, filter types.Filter) ([]*secret.Secret, error) {
+	var secrets []*secret.Secret
+	query := `
+		SELECT * FROM secrets WHERE tenant_id = :tenant_id AND status = :status ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s secret.Secret
+		if err := rows.StructScan(&s); err != nil {
+			return nil, fmt.Errorf("failed to scan secret: %w", err)
+		}
+		secrets = append(secrets, &s)
+	}
+
+	return secrets, nil
+}
+
+func (r *secretRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE secrets SET
+			status = :status,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("deleting secret",
+		"secret_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"tenant_id":  types.GetTenantID(ctx),
+		"status":     types.StatusDeleted,
+		"updated_by": types.GetUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
+	"github.com/flexprice/flexprice/internal/domain/sequence"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type sequenceRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewSequenceRepository(db *postgres.DB, logger *logger.Logger) sequence.Repository {
+	return &sequenceRepository{db: db, logger: logger}
+}
+
+func (r *sequenceRepository) GetInvoiceNumberingConfig(ctx context.Context) (*sequence.InvoiceNumberingConfig, error) {
+	query := `
+		SELECT * FROM invoice_numbering_configs
+		WHERE tenant_id = :tenant_id AND environment_id = :environment_id`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"tenant_id":      types.GetTenantID(ctx),
+		"environment_id": types.GetEnvironmentID(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice numbering config: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return sequence.DefaultInvoiceNumberingConfig(types.GetTenantID(ctx), types.GetEnvironmentID(ctx)), nil
+	}
+
+	var c sequence.InvoiceNumberingConfig
+	if err := rows.StructScan(&c); err != nil {
+		return nil, fmt.Errorf("failed to scan invoice numbering config: %w", err)
+	}
+
+	return &c, nil
+}
+
+func (r *sequenceRepository) UpsertInvoiceNumberingConfig(ctx context.Context, c *sequence.InvoiceNumberingConfig) error {
+	// The config is only replaced when it still has the version it was read at,
+	// which is zero for the default config of a tenant that has none stored
+	query := `
+		INSERT INTO invoice_numbering_configs (
+			tenant_id, environment_id, prefix, separator, padding, reset_yearly,
+			created_at, updated_at, created_by, updated_by, version
+		) VALUES (
+			:tenant_id, :environment_id, :prefix, :separator, :padding, :reset_yearly,
+			:created_at, :updated_at, :created_by, :updated_by, :version + 1
+		)
+		ON CONFLICT (tenant_id, environment_id) DO UPDATE SET
+			prefix = EXCLUDED.prefix,
+			separator = EXCLUDED.separator,
+			padding = EXCLUDED.padding,
+			reset_yearly = EXCLUDED.reset_yearly,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by,
+			version = EXCLUDED.version
+		WHERE invoice_numbering_configs.version = :version`
+
+	r.logger.Debug("upserting invoice numbering config",
+		"tenant_id", c.TenantID,
+		"environment_id", c.EnvironmentID,
+		"version", c.Version,
+	)
+
+	result, err := r.db.NamedExecContext(ctx, query, c)
+	if err != nil {
+		return fmt.Errorf("failed to upsert invoice numbering config: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		current, err := r.GetInvoiceNumberingConfig(ctx)
+		if err != nil {
+			return err
+		}
+		return domainErrors.NewVersionConflictError("invoice numbering config", c.TenantID, current.Version)
+	}
+
+	c.Version++
+	return nil
+}
+
+func (r *sequenceRepository) NextValue(ctx context.Context, key string) (int64, error) {
+	query := `
+		INSERT INTO sequences (tenant_id, environment_id, sequence_key, last_value, updated_at)
+		VALUES (:tenant_id, :environment_id, :sequence_key, 1, NOW())
+		ON CONFLICT (tenant_id, environment_id, sequence_key) DO UPDATE SET
+			last_value = sequences.last_value + 1,
+			updated_at = NOW()
+		RETURNING last_value`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"tenant_id":      types.GetTenantID(ctx),
+		"environment_id": types.GetEnvironmentID(ctx),
+		"sequence_key":   key,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment sequence: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return 0, fmt.Errorf("failed to increment sequence: no value returned")
+	}
+
+	var value int64
+	if err := rows.Scan(&value); err != nil {
+		return 0, fmt.Errorf("failed to scan sequence value: %w", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/sso"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type ssoRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewSSORepository(db *postgres.DB, logger *logger.Logger) sso.Repository {
+	return &ssoRepository{db: db, logger: logger}
+}
+
+func (r *ssoRepository) Get(ctx context.Context) (*sso.Config, error) {
+	return r.get(ctx, `SELECT * FROM sso_configs WHERE tenant_id = :tenant_id`, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+	})
+}
+
+func (r *ssoRepository) GetByDomain(ctx context.Context, domain string) (*sso.Config, error) {
+	return r.get(ctx, `SELECT * FROM sso_configs WHERE :domain = ANY(domains) LIMIT 1`, map[string]interface{}{
+		"domain": domain,
+	})
+}
+
+func (r *ssoRepository) get(ctx context.Context, query string, params map[string]interface{}) (*sso.Config, error) {
+	rows, err := r.db.NamedQueryContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sso config: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	var c sso.Config
+	if err := rows.StructScan(&c); err != nil {
+		return nil, fmt.Errorf("failed to scan sso config: %w", err)
+	}
+
+	return &c, nil
+}
+
+func (r *ssoRepository) Upsert(ctx context.Context, c *sso.Config) error {
+	query := `
+		INSERT INTO sso_configs (
+			tenant_id, protocol, domains, oidc_settings, saml_settings, client_secret,
+			groups_claim, group_roles, jit_provisioning, default_role, password_login_disabled,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:tenant_id, :protocol, :domains, :oidc_settings, :saml_settings, :client_secret,
+			:groups_claim, :group_roles, :jit_provisioning, :default_role, :password_login_disabled,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			protocol = EXCLUDED.protocol,
+			domains = EXCLUDED.domains,
+			oidc_settings = EXCLUDED.oidc_settings,
+			saml_settings = EXCLUDED.saml_settings,
+			client_secret = EXCLUDED.client_secret,
+			groups_claim = EXCLUDED.groups_claim,
+			group_roles = EXCLUDED.group_roles,
+			jit_provisioning = EXCLUDED.jit_provisioning,
+			default_role = EXCLUDED.default_role,
+			password_login_disabled = EXCLUDED.password_login_disabled,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by`
+
+	r.logger.Debug("upserting sso config",
+		"tenant_id", c.TenantID,
+		"protocol", c.Protocol,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, c); err != nil {
+		return fmt.Errorf("failed to upsert sso config: %w", err)
+	}
+	return nil
+}
+
+func (r *ssoRepository) Delete(ctx context.Context) error {
+	r.logger.Debug("deleting sso config", "tenant_id", types.GetTenantID(ctx))
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM sso_configs WHERE tenant_id = $1`, types.GetTenantID(ctx)); err != nil {
+		return fmt.Errorf("failed to delete sso config: %w", err)
+	}
+	return nil
+}
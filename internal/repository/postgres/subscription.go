@@ -21,6 +21,9 @@ func NewSubscriptionRepository(db *postgres.DB, logger *logger.Logger) subscript
 }
 
 func (r *subscriptionRepository) Create(ctx context.Context, subscription *subscription.Subscription) error {
+	// New rows start at the default version of the column
+	subscription.Version = 1
+
 	query := `
 		INSERT INTO subscriptions (
 			id, 
@@ -37,12 +40,24 @@ func (r *subscriptionRepository) Create(ctx context.Context, subscription *subsc
 			cancelled_at, 
 			cancel_at, 
 			cancel_at_period_end,
+			cancellation_reason,
+			cancellation_comment,
 			trial_start, 
 			trial_end, 
 			invoice_cadence,
 			billing_cadence,
 			billing_period,
 			billing_period_count,
+			billing_cycle,
+			billing_anchor_day,
+			partial_period_behavior,
+			timezone,
+			gateway_payment_method_id,
+			commitment_amount,
+			billing_threshold,
+			price_book_id,
+			legal_entity_id,
+			metadata,
 			tenant_id, 
 			status, 
 			created_at, 
@@ -64,12 +79,24 @@ func (r *subscriptionRepository) Create(ctx context.Context, subscription *subsc
 			:cancelled_at, 
 			:cancel_at, 
 			:cancel_at_period_end,
+			:cancellation_reason,
+			:cancellation_comment,
 			:trial_start, 
 			:trial_end, 
 			:invoice_cadence,
 			:billing_cadence,
 			:billing_period,
 			:billing_period_count,
+			:billing_cycle,
+			:billing_anchor_day,
+			:partial_period_behavior,
+			:timezone,
+			:gateway_payment_method_id,
+			:commitment_amount,
+			:billing_threshold,
+			:price_book_id,
+			:legal_entity_id,
+			:metadata,
 			:tenant_id, 
 			:status, 
 			:created_at, 
@@ -79,12 +106,34 @@ func (r *subscriptionRepository) Create(ctx context.Context, subscription *subsc
 		)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, subscription)
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := r.db.NamedExecContext(ctx, query, subscription); err != nil {
+			return fmt.Errorf("failed to create subscription: %w", err)
+		}
 
-	if err != nil {
-		return fmt.Errorf("failed to create subscription: %w", err)
-	}
+		for _, item := range subscription.LineItems {
+			if err := r.createLineItem(ctx, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *subscriptionRepository) createLineItem(ctx context.Context, item *subscription.LineItem) error {
+	query := `
+		INSERT INTO subscription_line_items (
+			id, tenant_id, subscription_id, price_id, quantity, unit_amount,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :subscription_id, :price_id, :quantity, :unit_amount,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
 
+	if _, err := r.db.NamedExecContext(ctx, query, item); err != nil {
+		return fmt.Errorf("failed to create subscription line item: %w", err)
+	}
 	return nil
 }
 
@@ -97,7 +146,7 @@ func (r *subscriptionRepository) Get(ctx context.Context, id string) (*subscript
 	`
 
 	var sub subscription.Subscription
-	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
 		"id":        id,
 		"tenant_id": types.GetTenantID(ctx),
 	})
@@ -115,9 +164,43 @@ func (r *subscriptionRepository) Get(ctx context.Context, id string) (*subscript
 		return nil, fmt.Errorf("failed to scan subscription: %w", err)
 	}
 
+	lineItems, err := r.getLineItems(ctx, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+	sub.LineItems = lineItems
+
 	return &sub, nil
 }
 
+func (r *subscriptionRepository) getLineItems(ctx context.Context, subscriptionID string) ([]*subscription.LineItem, error) {
+	query := `
+		SELECT * FROM subscription_line_items
+		WHERE subscription_id = :subscription_id AND tenant_id = :tenant_id AND status = :status
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"subscription_id": subscriptionID,
+		"tenant_id":       types.GetTenantID(ctx),
+		"status":          types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription line items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*subscription.LineItem
+	for rows.Next() {
+		var item subscription.LineItem
+		if err := rows.StructScan(&item); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription line item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	return items, nil
+}
+
 func (r *subscriptionRepository) Update(ctx context.Context, subscription *subscription.Subscription) error {
 	// TODO: Implement this after chalking out proper use cases here
 
@@ -131,18 +214,32 @@ func (r *subscriptionRepository) Update(ctx context.Context, subscription *subsc
 			cancelled_at = :cancelled_at,
 			cancel_at = :cancel_at,
 			cancel_at_period_end = :cancel_at_period_end,
+			cancellation_reason = :cancellation_reason,
+			cancellation_comment = :cancellation_comment,
+			billing_anchor = :billing_anchor,
+			current_period_start = :current_period_start,
+			current_period_end = :current_period_end,
+			trial_will_end_sent_at = :trial_will_end_sent_at,
+			threshold_billed_until = :threshold_billed_until,
 			status = :status, 
 			updated_at = :updated_at, 
-			updated_by = :updated_by
+			updated_by = :updated_by,
+			version = version + 1
 		WHERE 
 			id = :id AND 
-			tenant_id = :tenant_id
+			tenant_id = :tenant_id AND
+			version = :version
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, subscription)
+	result, err := r.db.NamedExecContext(ctx, query, subscription)
 	if err != nil {
 		return fmt.Errorf("failed to update subscription: %w", err)
 	}
+	if err := checkVersionedUpdate(ctx, r.db, result, "subscriptions", "subscription", subscription.ID); err != nil {
+		return err
+	}
+
+	subscription.Version++
 	return nil
 }
 
@@ -152,7 +249,8 @@ func (r *subscriptionRepository) Delete(ctx context.Context, id string) error {
 		SET 
 			status = :status, 
 			updated_at = :updated_at, 
-			updated_by = :updated_by
+			updated_by = :updated_by,
+			version = version + 1
 		WHERE 
 			id = :id AND 
 			tenant_id = :tenant_id
@@ -194,9 +292,12 @@ func (r *subscriptionRepository) List(ctx context.Context, filter *types.Subscri
 	}
 
 	// Add ordering and pagination
-	query += " ORDER BY created_at DESC LIMIT :limit OFFSET :offset"
+	query, err := paginate(query, filter.Filter, params)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := r.db.NamedQueryContext(ctx, query, params)
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
 	}
@@ -213,3 +314,223 @@ func (r *subscriptionRepository) List(ctx context.Context, filter *types.Subscri
 
 	return subscriptions, nil
 }
+
+func (r *subscriptionRepository) ListTrialsEndingBefore(ctx context.Context, before time.Time) ([]*subscription.Subscription, error) {
+	query := `
+		SELECT * FROM subscriptions
+		WHERE subscription_status = :subscription_status
+		AND status = :status
+		AND trial_end <= :before
+		ORDER BY trial_end ASC
+	`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"subscription_status": types.SubscriptionStatusTrialing,
+		"status":              types.StatusPublished,
+		"before":              before,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trialing subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*subscription.Subscription
+	for rows.Next() {
+		var sub subscription.Subscription
+		if err := rows.StructScan(&sub); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, &sub)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) ListWithBillingThreshold(ctx context.Context) ([]*subscription.Subscription, error) {
+	query := `
+		SELECT * FROM subscriptions
+		WHERE subscription_status = :subscription_status
+		AND status = :status
+		AND billing_threshold > 0
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"subscription_status": types.SubscriptionStatusActive,
+		"status":              types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions with billing threshold: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*subscription.Subscription
+	for rows.Next() {
+		var sub subscription.Subscription
+		if err := rows.StructScan(&sub); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, &sub)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) ListPeriodsEndedBefore(ctx context.Context, before time.Time) ([]*subscription.Subscription, error) {
+	query := `
+		SELECT * FROM subscriptions
+		WHERE subscription_status = :subscription_status
+		AND status = :status
+		AND current_period_end <= :before
+		ORDER BY current_period_end ASC
+	`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"subscription_status": types.SubscriptionStatusActive,
+		"status":              types.StatusPublished,
+		"before":              before,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions with ended periods: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*subscription.Subscription
+	for rows.Next() {
+		var sub subscription.Subscription
+		if err := rows.StructScan(&sub); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, &sub)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) ListCancelledBetween(ctx context.Context, start, end time.Time) ([]*subscription.Subscription, error) {
+	query := `
+		SELECT * FROM subscriptions
+		WHERE tenant_id = :tenant_id
+		AND status = :status
+		AND cancelled_at >= :start
+		AND cancelled_at < :end
+		ORDER BY cancelled_at ASC
+	`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+		"start":     start,
+		"end":       end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cancelled subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*subscription.Subscription
+	for rows.Next() {
+		var sub subscription.Subscription
+		if err := rows.StructScan(&sub); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, &sub)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) UpdateLineItem(ctx context.Context, item *subscription.LineItem) error {
+	item.UpdatedAt = time.Now().UTC()
+	item.UpdatedBy = types.GetUserID(ctx)
+
+	query := `
+		UPDATE subscription_line_items
+		SET
+			quantity = :quantity,
+			unit_amount = :unit_amount,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE
+			id = :id AND
+			tenant_id = :tenant_id
+	`
+
+	if _, err := r.db.NamedExecContext(ctx, query, item); err != nil {
+		return fmt.Errorf("failed to update subscription line item: %w", err)
+	}
+	return nil
+}
+
+func (r *subscriptionRepository) CreateProration(ctx context.Context, proration *subscription.Proration) error {
+	query := `
+		INSERT INTO subscription_prorations (
+			id, tenant_id, subscription_id, line_item_id, price_id, display_name, amount, currency,
+			period_start, changed_at, invoice_id, status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :subscription_id, :line_item_id, :price_id, :display_name, :amount, :currency,
+			:period_start, :changed_at, :invoice_id, :status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	if _, err := r.db.NamedExecContext(ctx, query, proration); err != nil {
+		return fmt.Errorf("failed to create proration: %w", err)
+	}
+	return nil
+}
+
+func (r *subscriptionRepository) ListPendingProrations(ctx context.Context, subscriptionID string) ([]*subscription.Proration, error) {
+	query := `
+		SELECT * FROM subscription_prorations
+		WHERE tenant_id = :tenant_id
+		AND subscription_id = :subscription_id
+		AND invoice_id = ''
+		AND status = :status
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id":       types.GetTenantID(ctx),
+		"subscription_id": subscriptionID,
+		"status":          types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending prorations: %w", err)
+	}
+	defer rows.Close()
+
+	var prorations []*subscription.Proration
+	for rows.Next() {
+		var proration subscription.Proration
+		if err := rows.StructScan(&proration); err != nil {
+			return nil, fmt.Errorf("failed to scan proration: %w", err)
+		}
+		prorations = append(prorations, &proration)
+	}
+
+	return prorations, nil
+}
+
+func (r *subscriptionRepository) MarkProrationInvoiced(ctx context.Context, id, invoiceID string) error {
+	query := `
+		UPDATE subscription_prorations
+		SET
+			invoice_id = :invoice_id,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE
+			id = :id AND
+			tenant_id = :tenant_id
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":         id,
+		"invoice_id": invoiceID,
+		"updated_at": time.Now().UTC(),
+		"updated_by": types.GetUserID(ctx),
+		"tenant_id":  types.GetTenantID(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark proration invoiced: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/task"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type taskRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewTaskRepository(db *postgres.DB, logger *logger.Logger) task.Repository {
+	return &taskRepository{db: db, logger: logger}
+}
+
+func (r *taskRepository) Create(ctx context.Context, t *task.Task) error {
+	query := `
+		INSERT INTO tasks (
+			id, tenant_id, task_type, file_format, file_url, task_status, total_rows, processed_rows,
+			successful_rows, failed_rows, error_report_key, error, range_start, range_end, completed_at, status,
+			created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :task_type, :file_format, :file_url, :task_status, :total_rows, :processed_rows,
+			:successful_rows, :failed_rows, :error_report_key, :error, :range_start, :range_end, :completed_at, :status,
+			:created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating task",
+		"task_id", t.ID,
+		"tenant_id", t.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, t); err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+	return nil
+}
+
+func (r *taskRepository) Get(ctx context.Context, id string) (*task.Task, error) {
+	var t task.Task
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM tasks WHERE id = :id AND tenant_id = :tenant_id", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("task not found")
+	}
+
+	if err := rows.StructScan(&t); err != nil {
+		return nil, fmt.Errorf("failed to scan task: %w", err)
+	}
+
+	return &t, nil
+}
+
+func (r *taskRepository) List(ctx context.Context, filter types.Filter) ([]*task.Task, error) {
+	var tasks []*task.Task
+	query := `
+		SELECT * FROM tasks WHERE tenant_id = :tenant_id ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"tenant_id": types.GetTenantID(ctx),
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t task.Task
+		if err := rows.StructScan(&t); err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, &t)
+	}
+
+	return tasks, nil
+}
+
+func (r *taskRepository) Update(ctx context.Context, t *task.Task) error {
+	query := `
+		UPDATE tasks SET
+			task_status = :task_status,
+			total_rows = :total_rows,
+			processed_rows = :processed_rows,
+			successful_rows = :successful_rows,
+			failed_rows = :failed_rows,
+			error_report_key = :error_report_key,
+			error = :error,
+			completed_at = :completed_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating task",
+		"task_id", t.ID,
+		"tenant_id", t.TenantID,
+		"task_status", t.TaskStatus,
+		"processed_rows", t.ProcessedRows,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, t); err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// checkVersionedUpdate checks the result of an update made with a version
+// precondition. An update that matched no row lost the race to another writer
+// and is returned as a conflict carrying the version the row has now
+func checkVersionedUpdate(ctx context.Context, db *postgres.DB, result sql.Result, table, entity, id string) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows > 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`SELECT version FROM %s WHERE id = :id AND tenant_id = :tenant_id`, table)
+	current, err := db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get %s version: %w", entity, err)
+	}
+	defer current.Close()
+
+	if !current.Next() {
+		return fmt.Errorf("%s not found", entity)
+	}
+
+	var version int
+	if err := current.Scan(&version); err != nil {
+		return fmt.Errorf("failed to scan %s version: %w", entity, err)
+	}
+
+	return domainErrors.NewVersionConflictError(entity, id, version)
+}
@@ -44,7 +44,7 @@ func (r *walletRepository) GetWalletByID(ctx context.Context, id string) (*walle
 	)
 
 	// Use NamedQueryContext for named queries
-	rows, err := r.db.NamedQueryContext(ctx, query, params)
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query wallet: %w", err)
 	}
@@ -82,7 +82,7 @@ func (r *walletRepository) GetWalletsByCustomerID(ctx context.Context, customerI
 		"tenant_id", types.GetTenantID(ctx),
 	)
 
-	rows, err := r.db.NamedQueryContext(ctx, query, params)
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query wallets: %w", err)
 	}
@@ -167,7 +167,7 @@ func (r *walletRepository) GetTransactionByID(ctx context.Context, id string) (*
 		"tenant_id", types.GetTenantID(ctx),
 	)
 
-	rows, err := r.db.NamedQueryContext(ctx, query, params)
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query transaction: %w", err)
 	}
@@ -209,7 +209,7 @@ func (r *walletRepository) GetTransactionsByWalletID(ctx context.Context, wallet
 		"offset", offset,
 	)
 
-	rows, err := r.db.NamedQueryContext(ctx, query, params)
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query transactions: %w", err)
 	}
@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+type autoTopUpRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewAutoTopUpRepository(db *postgres.DB, logger *logger.Logger) wallet.AutoTopUpRepository {
+	return &autoTopUpRepository{db: db, logger: logger}
+}
+
+func (r *autoTopUpRepository) UpsertAutoTopUp(ctx context.Context, rule *wallet.AutoTopUp) error {
+	query := `
+		INSERT INTO wallet_auto_topups (
+			id, tenant_id, wallet_id, enabled, threshold, amount, daily_cap, monthly_cap,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :wallet_id, :enabled, :threshold, :amount, :daily_cap, :monthly_cap,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)
+		ON CONFLICT (tenant_id, wallet_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			threshold = EXCLUDED.threshold,
+			amount = EXCLUDED.amount,
+			daily_cap = EXCLUDED.daily_cap,
+			monthly_cap = EXCLUDED.monthly_cap,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by
+		RETURNING id, created_at, created_by`
+
+	r.logger.Debug("upserting wallet auto top-up",
+		"wallet_id", rule.WalletID,
+		"tenant_id", rule.TenantID,
+		"enabled", rule.Enabled,
+	)
+
+	rows, err := r.db.NamedQueryContext(ctx, query, rule)
+	if err != nil {
+		return fmt.Errorf("failed to upsert wallet auto top-up: %w", err)
+	}
+	defer rows.Close()
+
+	// The rule keeps its identity when replaced
+	if rows.Next() {
+		if err := rows.Scan(&rule.ID, &rule.CreatedAt, &rule.CreatedBy); err != nil {
+			return fmt.Errorf("failed to scan wallet auto top-up: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *autoTopUpRepository) GetAutoTopUp(ctx context.Context, walletID string) (*wallet.AutoTopUp, error) {
+	query := `
+		SELECT * FROM wallet_auto_topups
+		WHERE wallet_id = :wallet_id AND tenant_id = :tenant_id AND status = :status`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"wallet_id": walletID,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet auto top-up: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("wallet auto top-up not found")
+	}
+
+	var rule wallet.AutoTopUp
+	if err := rows.StructScan(&rule); err != nil {
+		return nil, fmt.Errorf("failed to scan wallet auto top-up: %w", err)
+	}
+
+	return &rule, nil
+}
+
+func (r *autoTopUpRepository) ListDueAutoTopUps(ctx context.Context) ([]*wallet.AutoTopUp, error) {
+	query := `
+		SELECT r.* FROM wallet_auto_topups r
+		JOIN wallets w ON w.id = r.wallet_id AND w.tenant_id = r.tenant_id
+		WHERE r.enabled
+		AND r.status = :status
+		AND w.status = :status
+		AND w.wallet_status = :wallet_status
+		AND w.balance < r.threshold`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"status":        types.StatusPublished,
+		"wallet_status": types.WalletStatusActive,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due wallet auto top-ups: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*wallet.AutoTopUp
+	for rows.Next() {
+		var rule wallet.AutoTopUp
+		if err := rows.StructScan(&rule); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet auto top-up: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	return rules, nil
+}
+
+func (r *autoTopUpRepository) SumAutoTopUps(ctx context.Context, walletID string, since time.Time) (decimal.Decimal, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0) FROM wallet_transactions
+		WHERE wallet_id = :wallet_id
+		AND tenant_id = :tenant_id
+		AND status = :status
+		AND type = :type
+		AND reference_type = :reference_type
+		AND created_at >= :since`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"wallet_id":      walletID,
+		"tenant_id":      types.GetTenantID(ctx),
+		"status":         types.StatusPublished,
+		"type":           types.TransactionTypeCredit,
+		"reference_type": wallet.AutoTopUpReferenceType,
+		"since":          since,
+	})
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to sum wallet auto top-ups: %w", err)
+	}
+	defer rows.Close()
+
+	sum := decimal.Zero
+	if rows.Next() {
+		if err := rows.Scan(&sum); err != nil {
+			return decimal.Zero, fmt.Errorf("failed to scan wallet auto top-ups: %w", err)
+		}
+	}
+
+	return sum, nil
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type creditBucketRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewCreditBucketRepository(db *postgres.DB, logger *logger.Logger) wallet.CreditBucketRepository {
+	return &creditBucketRepository{db: db, logger: logger}
+}
+
+func (r *creditBucketRepository) CreateCreditBucket(ctx context.Context, bucket *wallet.CreditBucket) error {
+	query := `
+		INSERT INTO wallet_credit_buckets (
+			id, tenant_id, wallet_id, amount, remaining, expires_at, expired_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :wallet_id, :amount, :remaining, :expires_at, :expired_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating wallet credit bucket",
+		"bucket_id", bucket.ID,
+		"wallet_id", bucket.WalletID,
+		"tenant_id", bucket.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, bucket); err != nil {
+		return fmt.Errorf("failed to create wallet credit bucket: %w", err)
+	}
+
+	return nil
+}
+
+func (r *creditBucketRepository) UpdateCreditBucket(ctx context.Context, bucket *wallet.CreditBucket) error {
+	query := `
+		UPDATE wallet_credit_buckets SET
+			remaining = :remaining,
+			expired_at = :expired_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	result, err := r.db.NamedExecContext(ctx, query, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to update wallet credit bucket: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("wallet credit bucket not found")
+	}
+
+	return nil
+}
+
+func (r *creditBucketRepository) ListCreditBuckets(ctx context.Context, walletID string) ([]*wallet.CreditBucket, error) {
+	query := `
+		SELECT * FROM wallet_credit_buckets
+		WHERE wallet_id = :wallet_id
+		AND tenant_id = :tenant_id
+		AND status = :status
+		AND remaining > 0
+		ORDER BY created_at, id
+		FOR UPDATE`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"wallet_id": walletID,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallet credit buckets: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []*wallet.CreditBucket
+	for rows.Next() {
+		var bucket wallet.CreditBucket
+		if err := rows.StructScan(&bucket); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet credit bucket: %w", err)
+		}
+		buckets = append(buckets, &bucket)
+	}
+
+	return buckets, nil
+}
+
+func (r *creditBucketRepository) ListExpiredCreditBuckets(ctx context.Context, now time.Time) ([]*wallet.CreditBucket, error) {
+	query := `
+		SELECT * FROM wallet_credit_buckets
+		WHERE status = :status
+		AND remaining > 0
+		AND expires_at <= :now
+		ORDER BY expires_at, id`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"status": types.StatusPublished,
+		"now":    now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired wallet credit buckets: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []*wallet.CreditBucket
+	for rows.Next() {
+		var bucket wallet.CreditBucket
+		if err := rows.StructScan(&bucket); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet credit bucket: %w", err)
+		}
+		buckets = append(buckets, &bucket)
+	}
+
+	return buckets, nil
+}
@@ -0,0 +1,332 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type webhookRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewWebhookRepository(db *postgres.DB, logger *logger.Logger) webhook.Repository {
+	return &webhookRepository{db: db, logger: logger}
+}
+
+func (r *webhookRepository) CreateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (
+			id, tenant_id, environment_id, url, description, event_types,
+			secret, previous_secret, previous_secret_expires_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :environment_id, :url, :description, :event_types,
+			:secret, :previous_secret, :previous_secret_expires_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating webhook endpoint",
+		"endpoint_id", endpoint.ID,
+		"tenant_id", endpoint.TenantID,
+		"environment_id", endpoint.EnvironmentID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, endpoint); err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetEndpoint(ctx context.Context, id string) (*webhook.Endpoint, error) {
+	var endpoint webhook.Endpoint
+	query := `
+		SELECT * FROM webhook_endpoints
+		WHERE id = :id AND tenant_id = :tenant_id AND environment_id = :environment_id AND status = :status`
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, map[string]interface{}{
+		"id":             id,
+		"tenant_id":      types.GetTenantID(ctx),
+		"environment_id": types.GetEnvironmentID(ctx),
+		"status":         types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("webhook endpoint not found")
+	}
+
+	if err := rows.StructScan(&endpoint); err != nil {
+		return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+	}
+
+	return &endpoint, nil
+}
+
+func (r *webhookRepository) ListEndpoints(ctx context.Context, filter types.Filter) ([]*webhook.Endpoint, error) {
+	query := `
+		SELECT * FROM webhook_endpoints
+		WHERE tenant_id = :tenant_id AND environment_id = :environment_id AND status = :status
+		ORDER BY created_at DESC LIMIT :limit OFFSET :offset`
+
+	return r.listEndpoints(ctx, query, map[string]interface{}{
+		"tenant_id":      types.GetTenantID(ctx),
+		"environment_id": types.GetEnvironmentID(ctx),
+		"status":         types.StatusPublished,
+		"limit":          filter.Limit,
+		"offset":         filter.Offset,
+	})
+}
+
+func (r *webhookRepository) ListSubscribedEndpoints(ctx context.Context, eventType types.WebhookEventType) ([]*webhook.Endpoint, error) {
+	query := `
+		SELECT * FROM webhook_endpoints
+		WHERE tenant_id = :tenant_id AND status = :status
+		AND (:environment_id = '' OR environment_id = :environment_id)
+		AND (cardinality(event_types) = 0 OR :event_type = ANY(event_types))
+		ORDER BY created_at`
+
+	return r.listEndpoints(ctx, query, map[string]interface{}{
+		"tenant_id":      types.GetTenantID(ctx),
+		"environment_id": types.GetEnvironmentID(ctx),
+		"status":         types.StatusPublished,
+		"event_type":     eventType,
+	})
+}
+
+func (r *webhookRepository) listEndpoints(ctx context.Context, query string, params map[string]interface{}) ([]*webhook.Endpoint, error) {
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []*webhook.Endpoint
+	for rows.Next() {
+		var endpoint webhook.Endpoint
+		if err := rows.StructScan(&endpoint); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, &endpoint)
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookRepository) UpdateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error {
+	query := `
+		UPDATE webhook_endpoints SET
+			url = :url,
+			description = :description,
+			event_types = :event_types,
+			secret = :secret,
+			previous_secret = :previous_secret,
+			previous_secret_expires_at = :previous_secret_expires_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id AND environment_id = :environment_id`
+
+	r.logger.Debug("updating webhook endpoint",
+		"endpoint_id", endpoint.ID,
+		"tenant_id", endpoint.TenantID,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, endpoint); err != nil {
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, id string) error {
+	query := `
+		UPDATE webhook_endpoints
+		SET status = :status, updated_at = :updated_at, updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id AND environment_id = :environment_id`
+
+	r.logger.Debug("deleting webhook endpoint",
+		"endpoint_id", id,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	result, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+		"id":             id,
+		"tenant_id":      types.GetTenantID(ctx),
+		"environment_id": types.GetEnvironmentID(ctx),
+		"status":         types.StatusDeleted,
+		"updated_at":     time.Now().UTC(),
+		"updated_by":     types.GetUserID(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("webhook endpoint not found")
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (
+			id, tenant_id, environment_id, endpoint_id, endpoint_url, event_type, payload,
+			delivery_status, attempts, next_attempt_at, last_attempt_at,
+			last_response_code, last_error, delivered_at,
+			status, created_at, updated_at, created_by, updated_by
+		) VALUES (
+			:id, :tenant_id, :environment_id, :endpoint_id, :endpoint_url, :event_type, :payload,
+			:delivery_status, :attempts, :next_attempt_at, :last_attempt_at,
+			:last_response_code, :last_error, :delivered_at,
+			:status, :created_at, :updated_at, :created_by, :updated_by
+		)`
+
+	r.logger.Debug("creating webhook delivery",
+		"delivery_id", delivery.ID,
+		"tenant_id", delivery.TenantID,
+		"event_type", delivery.EventType,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, delivery); err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id string) (*webhook.Delivery, error) {
+	var delivery webhook.Delivery
+	rows, err := r.db.NamedQueryReadContext(ctx, "SELECT * FROM webhook_deliveries WHERE id = :id AND tenant_id = :tenant_id AND status = :status", map[string]interface{}{
+		"id":        id,
+		"tenant_id": types.GetTenantID(ctx),
+		"status":    types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("webhook delivery not found")
+	}
+
+	if err := rows.StructScan(&delivery); err != nil {
+		return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, filter *types.WebhookDeliveryFilter) ([]*webhook.Delivery, error) {
+	query := `
+		SELECT * FROM webhook_deliveries
+		WHERE tenant_id = :tenant_id AND status = :status
+	`
+	params := filter.ToMap()
+	params["tenant_id"] = types.GetTenantID(ctx)
+	params["status"] = types.StatusPublished
+
+	if filter.DeliveryStatus != "" {
+		query += " AND delivery_status = :delivery_status"
+	}
+	if filter.EventType != "" {
+		query += " AND event_type = :event_type"
+	}
+	if filter.EndpointID != "" {
+		query += " AND endpoint_id = :endpoint_id"
+	}
+
+	query += " ORDER BY created_at DESC LIMIT :limit OFFSET :offset"
+
+	rows, err := r.db.NamedQueryReadContext(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*webhook.Delivery
+	for rows.Next() {
+		var delivery webhook.Delivery
+		if err := rows.StructScan(&delivery); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	query := `
+		UPDATE webhook_deliveries SET
+			delivery_status = :delivery_status,
+			attempts = :attempts,
+			next_attempt_at = :next_attempt_at,
+			last_attempt_at = :last_attempt_at,
+			last_response_code = :last_response_code,
+			last_error = :last_error,
+			delivered_at = :delivered_at,
+			updated_at = :updated_at,
+			updated_by = :updated_by
+		WHERE id = :id AND tenant_id = :tenant_id`
+
+	r.logger.Debug("updating webhook delivery",
+		"delivery_id", delivery.ID,
+		"tenant_id", delivery.TenantID,
+		"delivery_status", delivery.DeliveryStatus,
+	)
+
+	if _, err := r.db.NamedExecContext(ctx, query, delivery); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	// Deliberately not tenant scoped: the dispatcher works across all tenants
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = :lease_until
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE delivery_status = :delivery_status AND status = :status AND next_attempt_at <= :now
+			ORDER BY next_attempt_at
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	rows, err := r.db.NamedQueryContext(ctx, query, map[string]interface{}{
+		"lease_until":     now.Add(lease),
+		"delivery_status": types.WebhookDeliveryStatusPending,
+		"status":          types.StatusPublished,
+		"now":             now,
+		"limit":           limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*webhook.Delivery
+	for rows.Next() {
+		var delivery webhook.Delivery
+		if err := rows.StructScan(&delivery); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, nil
+}
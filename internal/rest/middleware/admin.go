@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuthenticateMiddleware authenticates the operator APIs with the admin key
+// in the X-Admin-Key header. Admin requests are not scoped to a tenant, the
+// handlers take the tenant from the request when they need one
+func AdminAuthenticateMiddleware(cfg *config.Configuration, logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.Admin.APIKey == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			c.Abort()
+			return
+		}
+
+		key := c.GetHeader(types.HeaderAdminKey)
+		if subtle.ConstantTimeCompare([]byte(key), []byte(cfg.Admin.APIKey)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		ctx := context.WithValue(c.Request.Context(), types.CtxUserID, types.DefaultUserID)
+		c.Request = c.Request.WithContext(ctx)
+
+		logger.Debugf("authenticated admin request: %s %s", c.Request.Method, c.FullPath())
+		c.Next()
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/flexprice/flexprice/internal/auth"
 	"github.com/flexprice/flexprice/internal/config"
 	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
 	"github.com/flexprice/flexprice/internal/types"
 	"github.com/gin-gonic/gin"
 )
@@ -25,10 +26,17 @@ func GuestAuthenticateMiddleware(c *gin.Context) {
 
 // AuthenticateMiddleware is a middleware that authenticates requests based on the JWT token
 // It expects the JWT token to be in the Authorization header as a Bearer token
+// or an API key in the X-API-Key header
 // It sets the user ID and JWT token in the request context so it can be used by downstream handlers
 // It also sets the environment ID and other headers in the request context if present
-func AuthenticateMiddleware(cfg *config.Configuration, logger *logger.Logger) gin.HandlerFunc {
+// Users are restricted to the permissions of their role in the environment
+func AuthenticateMiddleware(cfg *config.Configuration, secretService service.SecretService, roleService service.RoleService, logger *logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader(types.HeaderAPIKey); apiKey != "" {
+			authenticateAPIKey(c, apiKey, secretService, logger)
+			return
+		}
+
 		authHeader := c.GetHeader(types.HeaderAuthorization)
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -67,9 +75,16 @@ func AuthenticateMiddleware(cfg *config.Configuration, logger *logger.Logger) gi
 
 		// Set additional headers for downstream handlers
 		environmentID := c.GetHeader(types.HeaderEnvironment)
-		if environmentID != "" {
-			ctx = context.WithValue(ctx, types.CtxEnvironmentID, environmentID)
+		ctx = setEnvironment(ctx, environmentID)
+
+		permissions, err := roleService.GetPermissions(ctx, claims.UserID, environmentID)
+		if err != nil {
+			logger.Errorw("failed to get user permissions", "user_id", claims.UserID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user permissions"})
+			c.Abort()
+			return
 		}
+		ctx = context.WithValue(ctx, types.CtxPermissions, permissions)
 		c.Request = c.Request.WithContext(ctx)
 
 		logger.Debugf("authenticated request: user_id=%s, tenant_id=%s env_id=%s",
@@ -77,3 +92,48 @@ func AuthenticateMiddleware(cfg *config.Configuration, logger *logger.Logger) gi
 		c.Next()
 	}
 }
+
+// authenticateAPIKey authenticates the request with a scoped API key and restricts
+// it to the permissions granted by the key scopes
+func authenticateAPIKey(c *gin.Context, apiKey string, secretService service.SecretService, logger *logger.Logger) {
+	key, err := secretService.VerifyAPIKey(c.Request.Context(), apiKey)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid api key: " + err.Error()})
+		c.Abort()
+		return
+	}
+
+	ctx := c.Request.Context()
+	ctx = context.WithValue(ctx, types.CtxUserID, key.CreatedBy)
+	ctx = context.WithValue(ctx, types.CtxTenantID, key.TenantID)
+	ctx = context.WithValue(ctx, types.CtxAPIKeyID, key.ID)
+	ctx = context.WithValue(ctx, types.CtxPermissions, types.PermissionsForScopes(key.Scopes))
+
+	environmentID := c.GetHeader(types.HeaderEnvironment)
+	ctx = setEnvironment(ctx, environmentID)
+	c.Request = c.Request.WithContext(ctx)
+
+	logger.Debugf("authenticated request: api_key_id=%s, tenant_id=%s env_id=%s",
+		key.ID, key.TenantID, environmentID)
+	c.Next()
+}
+
+func setEnvironment(ctx context.Context, environmentID string) context.Context {
+	if environmentID != "" {
+		ctx = context.WithValue(ctx, types.CtxEnvironmentID, environmentID)
+	}
+	return ctx
+}
+
+// RequirePermission is a middleware that rejects requests authenticated with
+// an API key whose scopes, or by a user whose role, do not grant the permission
+func RequirePermission(permission types.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !types.HasPermission(c.Request.Context(), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "missing permission: " + string(permission)})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -11,6 +11,8 @@ func CORSMiddleware(c *gin.Context) {
 	c.Writer.Header().Set("Access-Control-Allow-Origin", "*") // TODO: Set to specific origin
 	c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 	c.Writer.Header().Set("Access-Control-Allow-Headers", "*")
+	// The dashboard reads the ETag of versioned entities to send it back in If-Match
+	c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
 	c.Writer.Header().Set("Access-Control-Max-Age", "86400")
 
 	if c.Request.Method == "OPTIONS" {
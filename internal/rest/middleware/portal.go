@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/auth"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// PortalAuthenticateMiddleware authenticates customer portal requests
+// It expects a portal session token in the Authorization header as a Bearer token
+// and scopes the request to the tenant and customer of the session with read only access
+func PortalAuthenticateMiddleware(cfg *config.Configuration, logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader(types.HeaderAuthorization)
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		claims, err := auth.ValidatePortalToken(cfg, strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid portal session: " + err.Error()})
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		ctx = context.WithValue(ctx, types.CtxTenantID, claims.TenantID)
+		ctx = context.WithValue(ctx, types.CtxCustomerID, claims.CustomerID)
+		ctx = context.WithValue(ctx, types.CtxPermissions, []types.Permission{types.PermissionRead})
+		ctx = setEnvironment(ctx, claims.EnvironmentID)
+		c.Request = c.Request.WithContext(ctx)
+
+		logger.Debugf("authenticated portal request: session_id=%s, tenant_id=%s customer_id=%s",
+			claims.SessionID, claims.TenantID, claims.CustomerID)
+		c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/ratelimit"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// ingestRoutes are the routes limited with the event ingestion quotas. All the
+// other routes use the management quotas
+var ingestRoutes = map[string]bool{
+	http.MethodPost + " /v1/events": true,
+}
+
+// RateLimitMiddleware enforces the request quotas of the tenant and, for requests
+// authenticated with an API key, of the key. It must run after the authentication
+// middleware. Requests are let through when Redis is unavailable
+func RateLimitMiddleware(cfg *config.Configuration, limiter ratelimit.Limiter, logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.RateLimit.Enabled || limiter == nil {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		tenantID := types.GetTenantID(ctx)
+		apiKeyID := types.GetAPIKeyID(ctx)
+
+		class := types.RateLimitClassManagement
+		if ingestRoutes[c.Request.Method+" "+c.FullPath()] {
+			class = types.RateLimitClassIngest
+		}
+		quotas := cfg.RateLimit.QuotasFor(tenantID, class)
+
+		// The tokens of the key and of the tenant are taken together, so a request
+		// rejected by either quota uses neither of them
+		var buckets []ratelimit.Bucket
+		if apiKeyID != "" {
+			buckets = append(buckets, ratelimit.Bucket{
+				Key:   "ratelimit:" + string(class) + ":key:" + apiKeyID,
+				Limit: quotas.APIKey,
+			})
+		}
+		buckets = append(buckets, ratelimit.Bucket{
+			Key:   "ratelimit:" + string(class) + ":tenant:" + tenantID,
+			Limit: quotas.Tenant,
+		})
+
+		results, err := limiter.Allow(ctx, buckets)
+		if err != nil {
+			logger.Errorw("failed to check rate limit, allowing request",
+				"tenant_id", tenantID,
+				"error", err,
+			)
+			c.Next()
+			return
+		}
+
+		var retryAfter time.Duration
+		allowed := true
+		for i, bucket := range buckets {
+			result := results[i]
+			if bucket.Limit.RatePerSecond > 0 {
+				c.Header("X-RateLimit-Limit", strconv.Itoa(bucket.Capacity()))
+				c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			}
+			if !result.Allowed {
+				allowed = false
+				retryAfter = max(retryAfter, result.RetryAfter)
+			}
+		}
+
+		if !allowed {
+			retryAfterSecs := int(math.Ceil(retryAfter.Seconds()))
+			if retryAfterSecs < 1 {
+				retryAfterSecs = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfterSecs))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/ratelimit"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := miniredis.RunT(t)
+	limiter := ratelimit.NewRedisLimiter(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+
+	cfg := &config.Configuration{
+		RateLimit: config.RateLimitConfig{
+			Enabled: true,
+			Ingest: config.RateLimitQuotas{
+				Tenant: config.RateLimit{RatePerSecond: 0.001, Burst: 3},
+				APIKey: config.RateLimit{RatePerSecond: 0.001, Burst: 2},
+			},
+			Management: config.RateLimitQuotas{
+				Tenant: config.RateLimit{RatePerSecond: 0.001, Burst: 1},
+			},
+			TenantOverrides: []config.RateLimitOverride{
+				{
+					TenantID:   "tenant_big",
+					Management: config.RateLimitQuotas{Tenant: config.RateLimit{RatePerSecond: 0.001, Burst: 3}},
+				},
+				{
+					TenantID:   "tenant_rate_only",
+					Management: config.RateLimitQuotas{Tenant: config.RateLimit{RatePerSecond: 0.001}},
+				},
+			},
+		},
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), types.CtxTenantID, c.GetHeader("X-Tenant"))
+		if key := c.GetHeader("X-Key"); key != "" {
+			ctx = context.WithValue(ctx, types.CtxAPIKeyID, key)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}, RateLimitMiddleware(cfg, limiter, logger.GetLogger()))
+	router.POST("/v1/events", func(c *gin.Context) { c.Status(http.StatusAccepted) })
+	router.GET("/v1/meters", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(method, path, tenantID, apiKeyID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Tenant", tenantID)
+		req.Header.Set("X-Key", apiKeyID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Each key is limited on its own, then the tenant as a whole
+	assert.Equal(t, http.StatusAccepted, send(http.MethodPost, "/v1/events", "tenant_a", "key_1").Code)
+	assert.Equal(t, http.StatusAccepted, send(http.MethodPost, "/v1/events", "tenant_a", "key_1").Code)
+	w := send(http.MethodPost, "/v1/events", "tenant_a", "key_1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusAccepted, send(http.MethodPost, "/v1/events", "tenant_a", "key_2").Code)
+	assert.Equal(t, http.StatusTooManyRequests, send(http.MethodPost, "/v1/events", "tenant_a", "key_2").Code)
+
+	// Management requests have their own quota
+	w = send(http.MethodGet, "/v1/meters", "tenant_a", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, http.StatusTooManyRequests, send(http.MethodGet, "/v1/meters", "tenant_a", "").Code)
+
+	// Overrides replace the defaults of the tenant
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/v1/meters", "tenant_big", "").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, send(http.MethodGet, "/v1/meters", "tenant_big", "").Code)
+
+	// A quota with only a rate holds a single token, which is the limit reported
+	w = send(http.MethodGet, "/v1/meters", "tenant_rate_only", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, http.StatusTooManyRequests, send(http.MethodGet, "/v1/meters", "tenant_rate_only", "").Code)
+
+	// Requests are let through when Redis is down
+	server.Close()
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/v1/meters", "tenant_c", "").Code)
+}
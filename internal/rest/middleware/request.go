@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/flexprice/flexprice/internal/types"
 	"github.com/gin-gonic/gin"
@@ -29,3 +30,65 @@ func RequestIDMiddleware(c *gin.Context) {
 
 	c.Next()
 }
+
+// IfMatchMiddleware puts the version of an If-Match header in the context so the
+// write is only applied to an entity still at that version. A wildcard is the
+// same as no header
+func IfMatchMiddleware(c *gin.Context) {
+	header := c.GetHeader(types.HeaderIfMatch)
+	if header == "" || header == "*" {
+		c.Next()
+		return
+	}
+
+	version, ok := types.ParseETag(header)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid If-Match header, expected the ETag of the entity"})
+		c.Abort()
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), types.CtxIfMatch, version)
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// ConsistencyMiddleware lets the reads of the GET requests be served by the read
+// replicas. A request reads from the primary with consistency=strong, in the
+// query or the X-Consistency header, and every other request always does
+func ConsistencyMiddleware(c *gin.Context) {
+	consistency := types.ReadConsistency(c.Query("consistency"))
+	if consistency == "" {
+		consistency = types.ReadConsistency(c.GetHeader(types.HeaderConsistency))
+	}
+
+	switch consistency {
+	case "", types.ReadConsistencyEventual:
+		consistency = types.ReadConsistencyEventual
+	case types.ReadConsistencyStrong:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid consistency, expected strong or eventual"})
+		c.Abort()
+		return
+	}
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		consistency = types.ReadConsistencyStrong
+	}
+
+	ctx := context.WithValue(c.Request.Context(), types.CtxConsistency, consistency)
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// RequestCacheMiddleware gives the GET requests a cache of the records they read,
+// a record the request refers to several times is read once. The other requests
+// write and would read stale records from it
+func RequestCacheMiddleware(c *gin.Context) {
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		c.Request = c.Request.WithContext(types.WithRequestCache(c.Request.Context()))
+	}
+	c.Next()
+}
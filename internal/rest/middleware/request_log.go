@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/requestlog"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/service"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// maxCapturedBodyBytes bounds the bodies kept in memory for the request logs.
+// Larger bodies are cut, can not be parsed for redaction and are omitted
+const maxCapturedBodyBytes = 64 * 1024
+
+// requestLogExcludedPrefix keeps reads of the request logs out of the logs
+const requestLogExcludedPrefix = "/v1/developer"
+
+// RequestLogMiddleware records the requests authenticated with an API key for the
+// developer request logs. It must run before the authentication middleware so
+// the requests rejected for missing permissions are recorded too
+func RequestLogMiddleware(cfg *config.Configuration, requestLogService service.RequestLogService, logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.RequestLog.Enabled || strings.HasPrefix(c.Request.URL.Path, requestLogExcludedPrefix) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		var requestBody []byte
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				logger.Debugw("failed to read request body for request log", "error", err)
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			requestBody = body
+		}
+
+		writer := &responseCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// The authentication middleware replaces the request context
+		ctx := c.Request.Context()
+		apiKeyID := types.GetAPIKeyID(ctx)
+		if apiKeyID == "" {
+			return
+		}
+
+		log := &requestlog.RequestLog{
+			TenantID:      types.GetTenantID(ctx),
+			EnvironmentID: types.GetEnvironmentID(ctx),
+			APIKeyID:      apiKeyID,
+			RequestID:     types.GetRequestID(ctx),
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			Endpoint:      c.FullPath(),
+			StatusCode:    writer.Status(),
+			LatencyMs:     time.Since(start).Milliseconds(),
+			RequestBody:   string(capBody(requestBody)),
+			ResponseBody:  writer.body.String(),
+			Timestamp:     start.UTC(),
+		}
+
+		// Recording must neither slow down nor fail the request
+		go func() {
+			if err := requestLogService.RecordRequest(context.WithoutCancel(ctx), log); err != nil {
+				logger.Errorw("failed to record request log",
+					"request_id", log.RequestID,
+					"error", err,
+				)
+			}
+		}()
+	}
+}
+
+func capBody(body []byte) []byte {
+	if len(body) > maxCapturedBodyBytes {
+		return body[:maxCapturedBodyBytes]
+	}
+	return body
+}
+
+// responseCaptureWriter keeps a copy of the start of the response body
+type responseCaptureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseCaptureWriter) capture(b []byte) {
+	if remaining := maxCapturedBodyBytes - w.body.Len(); remaining > 0 {
+		w.body.Write(b[:min(len(b), remaining)])
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ConsistencyMiddleware)
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, string(types.GetReadConsistency(c.Request.Context())))
+	}
+	router.GET("/v1/customers", handler)
+	router.POST("/v1/customers", handler)
+
+	send := func(method, path, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if header != "" {
+			req.Header.Set(types.HeaderConsistency, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// GET requests may read from the replicas unless they ask for strong reads
+	assert.Equal(t, "eventual", send(http.MethodGet, "/v1/customers", "").Body.String())
+	assert.Equal(t, "strong", send(http.MethodGet, "/v1/customers?consistency=strong", "").Body.String())
+	assert.Equal(t, "strong", send(http.MethodGet, "/v1/customers", "strong").Body.String())
+
+	// Writes always read from the primary
+	assert.Equal(t, "strong", send(http.MethodPost, "/v1/customers?consistency=eventual", "").Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodGet, "/v1/customers?consistency=stale", "").Code)
+}
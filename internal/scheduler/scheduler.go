@@ -0,0 +1,280 @@
+// Package scheduler runs the background jobs, such as ending trials or
+// expiring wallet credits, on their schedule and records the history of
+// their runs
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/job"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrJobRunning is returned when a job is triggered while it runs, the runs
+	// of a job never overlap
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Job is a background job with its default schedule, which is overridden by
+// the scheduler.jobs config of its name
+type Job struct {
+	Name        string
+	Description string
+	Enabled     bool
+	Interval    time.Duration
+	Run         func(ctx context.Context, now time.Time) error
+}
+
+// JobInfo is the schedule of a job as configured for the deployment
+type JobInfo struct {
+	Name        string
+	Description string
+	Enabled     bool
+	Interval    time.Duration
+	BatchSize   int
+	Concurrency int
+	Running     bool
+}
+
+type scheduledJob struct {
+	Job
+	opts    types.JobRunOptions
+	running atomic.Bool
+}
+
+type Scheduler struct {
+	cfg    config.SchedulerConfig
+	repo   job.RunRepository
+	logger *logger.Logger
+
+	// clock is the time the jobs run at, which is simulated in sandboxes
+	clock *clock.Clock
+
+	mu     sync.RWMutex
+	jobs   map[string]*scheduledJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(cfg *config.Configuration, repo job.RunRepository, clock *clock.Clock, logger *logger.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cfg:    cfg.Scheduler,
+		repo:   repo,
+		logger: logger,
+		clock:  clock,
+		jobs:   make(map[string]*scheduledJob),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Register adds a job with the overrides of its config
+func (s *Scheduler) Register(j Job) {
+	sj := &scheduledJob{Job: j}
+	if jobCfg, ok := s.cfg.Jobs[j.Name]; ok {
+		if jobCfg.Enabled != nil {
+			sj.Enabled = *jobCfg.Enabled
+		}
+		if jobCfg.IntervalMins > 0 {
+			sj.Interval = time.Duration(jobCfg.IntervalMins) * time.Minute
+		}
+		sj.opts = types.JobRunOptions{
+			BatchSize:   jobCfg.BatchSize,
+			Concurrency: jobCfg.Concurrency,
+		}
+	}
+	if sj.opts.Concurrency <= 0 {
+		sj.opts.Concurrency = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[j.Name] = sj
+}
+
+// Start runs every enabled job right away and then on its interval
+func (s *Scheduler) Start() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, j := range s.jobs {
+		if !j.Enabled || j.Interval <= 0 {
+			continue
+		}
+
+		s.wg.Add(1)
+		go s.schedule(j)
+	}
+}
+
+// Stop cancels the running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) schedule(j *scheduledJob) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		run, err := s.begin(j, types.JobTriggerSchedule, "")
+		if err != nil {
+			s.logger.Warnw("skipping scheduled job run", "job", j.Name, "error", err)
+		} else {
+			s.execute(j, run)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Jobs returns the schedule of every registered job ordered by name
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		infos = append(infos, JobInfo{
+			Name:        j.Name,
+			Description: j.Description,
+			Enabled:     j.Enabled,
+			Interval:    j.Interval,
+			BatchSize:   j.opts.BatchSize,
+			Concurrency: j.opts.Concurrency,
+			Running:     j.running.Load(),
+		})
+	}
+
+	sort.Slice(infos, func(i, k int) bool {
+		return infos[i].Name < infos[k].Name
+	})
+	return infos
+}
+
+// Trigger starts a run of a job in the background, disabled jobs included, and
+// returns it as it starts
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*job.Run, error) {
+	j, err := s.job(name)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.begin(j, types.JobTriggerManual, types.GetUserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	started := *run
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(j, run)
+	}()
+
+	return &started, nil
+}
+
+// Run runs a job in the caller, disabled jobs included, and returns the run
+// once it is over
+func (s *Scheduler) Run(ctx context.Context, name string) (*job.Run, error) {
+	j, err := s.job(name)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.begin(j, types.JobTriggerManual, types.GetUserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	s.execute(j, run)
+	return run, nil
+}
+
+// ListRuns returns the latest runs of a job first
+func (s *Scheduler) ListRuns(ctx context.Context, name string, limit int) ([]*job.Run, error) {
+	if _, err := s.job(name); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRuns(ctx, name, limit)
+}
+
+func (s *Scheduler) job(name string) (*scheduledJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return j, nil
+}
+
+// begin marks the job as running and records the start of the run
+func (s *Scheduler) begin(j *scheduledJob, trigger types.JobTrigger, triggeredBy string) (*job.Run, error) {
+	if !j.running.CompareAndSwap(false, true) {
+		return nil, ErrJobRunning
+	}
+
+	run := &job.Run{
+		ID:          types.GenerateUUID(),
+		JobName:     j.Name,
+		Trigger:     trigger,
+		Status:      types.JobRunStatusRunning,
+		TriggeredBy: triggeredBy,
+		StartedAt:   time.Now().UTC(),
+	}
+
+	// The history is best effort, a job still runs when it cannot be recorded
+	if err := s.repo.Create(s.ctx, run); err != nil {
+		s.logger.Errorw("failed to record job run", "job", j.Name, "error", err)
+	}
+
+	return run, nil
+}
+
+func (s *Scheduler) execute(j *scheduledJob, run *job.Run) {
+	defer j.running.Store(false)
+
+	ctx := context.WithValue(s.ctx, types.CtxUserID, types.DefaultUserID)
+	ctx = context.WithValue(ctx, types.CtxJobRunOptions, j.opts)
+
+	// Runs are recorded at the wall clock time and process the items due at the
+	// time of the clock
+	err := j.Run(ctx, s.clock.Now())
+
+	finishedAt := time.Now().UTC()
+	run.FinishedAt = &finishedAt
+	run.Status = types.JobRunStatusSucceeded
+	if err != nil {
+		run.Status = types.JobRunStatusFailed
+		run.Error = err.Error()
+		s.logger.Errorw("job run failed", "job", j.Name, "run_id", run.ID, "error", err)
+	}
+
+	// The run is recorded even when the scheduler is stopping
+	if err := s.repo.Update(context.WithoutCancel(s.ctx), run); err != nil {
+		s.logger.Errorw("failed to record job run", "job", j.Name, "run_id", run.ID, "error", err)
+	}
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type SchedulerSuite struct {
+	suite.Suite
+	ctx   context.Context
+	store *testutil.InMemoryJobRunStore
+}
+
+func TestScheduler(t *testing.T) {
+	suite.Run(t, new(SchedulerSuite))
+}
+
+func (s *SchedulerSuite) SetupTest() {
+	s.ctx = testutil.SetupContext()
+	s.store = testutil.NewInMemoryJobRunStore()
+}
+
+func (s *SchedulerSuite) newScheduler(jobs map[string]config.JobConfig) *Scheduler {
+	cfg := config.GetDefaultConfig()
+	cfg.Scheduler.Jobs = jobs
+	return New(cfg, s.store, nil, logger.GetLogger())
+}
+
+func (s *SchedulerSuite) waitForRun(name string) {
+	s.Eventually(func() bool {
+		runs, err := s.store.ListRuns(s.ctx, name, 1)
+		return err == nil && len(runs) == 1 && runs[0].Status != types.JobRunStatusRunning
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *SchedulerSuite) TestConfigOverridesJobDefaults() {
+	disabled := false
+	sched := s.newScheduler(map[string]config.JobConfig{
+		"expire_wallet_credits": {Enabled: &disabled, IntervalMins: 5, BatchSize: 500, Concurrency: 4},
+	})
+	noop := func(context.Context, time.Time) error { return nil }
+	sched.Register(Job{Name: "process_trials", Enabled: true, Interval: 15 * time.Minute, Run: noop})
+	sched.Register(Job{Name: "expire_wallet_credits", Enabled: true, Interval: 15 * time.Minute, Run: noop})
+
+	jobs := sched.Jobs()
+	s.Require().Len(jobs, 2)
+
+	s.Equal("expire_wallet_credits", jobs[0].Name)
+	s.False(jobs[0].Enabled)
+	s.Equal(5*time.Minute, jobs[0].Interval)
+	s.Equal(500, jobs[0].BatchSize)
+	s.Equal(4, jobs[0].Concurrency)
+
+	s.Equal("process_trials", jobs[1].Name)
+	s.True(jobs[1].Enabled)
+	s.Equal(15*time.Minute, jobs[1].Interval)
+	s.Equal(0, jobs[1].BatchSize)
+	s.Equal(1, jobs[1].Concurrency)
+}
+
+func (s *SchedulerSuite) TestTriggerRecordsRunHistory() {
+	sched := s.newScheduler(map[string]config.JobConfig{
+		"detect_anomalies": {BatchSize: 10, Concurrency: 2},
+	})
+	var opts types.JobRunOptions
+	sched.Register(Job{Name: "detect_anomalies", Interval: time.Hour, Run: func(ctx context.Context, now time.Time) error {
+		opts = types.GetJobRunOptions(ctx)
+		return nil
+	}})
+	sched.Register(Job{Name: "process_trials", Interval: time.Hour, Run: func(context.Context, time.Time) error {
+		return errors.New("database is down")
+	}})
+	defer sched.Stop()
+
+	// Disabled jobs can still be triggered
+	run, err := sched.Trigger(context.WithValue(s.ctx, types.CtxUserID, "user-1"), "detect_anomalies")
+	s.Require().NoError(err)
+	s.Equal(types.JobTriggerManual, run.Trigger)
+	s.Equal(types.JobRunStatusRunning, run.Status)
+	s.Equal("user-1", run.TriggeredBy)
+
+	s.waitForRun("detect_anomalies")
+	runs, err := sched.ListRuns(s.ctx, "detect_anomalies", 10)
+	s.Require().NoError(err)
+	s.Equal(types.JobRunStatusSucceeded, runs[0].Status)
+	s.NotNil(runs[0].FinishedAt)
+	s.Equal(types.JobRunOptions{BatchSize: 10, Concurrency: 2}, opts)
+
+	_, err = sched.Trigger(s.ctx, "process_trials")
+	s.Require().NoError(err)
+	s.waitForRun("process_trials")
+	runs, err = sched.ListRuns(s.ctx, "process_trials", 10)
+	s.Require().NoError(err)
+	s.Equal(types.JobRunStatusFailed, runs[0].Status)
+	s.Equal("database is down", runs[0].Error)
+
+	_, err = sched.Trigger(s.ctx, "unknown")
+	s.ErrorIs(err, ErrJobNotFound)
+}
+
+func (s *SchedulerSuite) TestRunsOfAJobDoNotOverlap() {
+	sched := s.newScheduler(nil)
+	release := make(chan struct{})
+	sched.Register(Job{Name: "roll_up_usage", Interval: time.Hour, Run: func(context.Context, time.Time) error {
+		<-release
+		return nil
+	}})
+	defer sched.Stop()
+
+	_, err := sched.Trigger(s.ctx, "roll_up_usage")
+	s.Require().NoError(err)
+	s.True(sched.Jobs()[0].Running)
+
+	_, err = sched.Trigger(s.ctx, "roll_up_usage")
+	s.ErrorIs(err, ErrJobRunning)
+
+	close(release)
+	s.waitForRun("roll_up_usage")
+	s.Eventually(func() bool { return !sched.Jobs()[0].Running }, time.Second, 10*time.Millisecond)
+
+	_, err = sched.Trigger(s.ctx, "roll_up_usage")
+	s.NoError(err)
+}
+
+func (s *SchedulerSuite) TestStartRunsEnabledJobs() {
+	sched := s.newScheduler(nil)
+	ran := make(chan struct{}, 1)
+	sched.Register(Job{Name: "process_trials", Enabled: true, Interval: time.Hour, Run: func(context.Context, time.Time) error {
+		ran <- struct{}{}
+		return nil
+	}})
+	sched.Register(Job{Name: "apply_event_retention", Interval: time.Hour, Run: func(context.Context, time.Time) error {
+		s.Fail("disabled job ran")
+		return nil
+	}})
+
+	sched.Start()
+	<-ran
+	sched.Stop()
+
+	runs, err := sched.ListRuns(s.ctx, "process_trials", 10)
+	s.Require().NoError(err)
+	s.Require().Len(runs, 1)
+	s.Equal(types.JobTriggerSchedule, runs[0].Trigger)
+	s.Equal(types.JobRunStatusSucceeded, runs[0].Status)
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type ActivityService interface {
+	// GetCustomerActivity returns the activity of a customer across subscriptions,
+	// invoices and wallets, newest first
+	GetCustomerActivity(ctx context.Context, customerID string, filter types.ActivityFilter) (*dto.ListCustomerActivityResponse, error)
+}
+
+// activitySource returns the activities of a customer found in at most the
+// window most recent records of one domain table
+type activitySource func(ctx context.Context, customerID string, window int) ([]dto.CustomerActivity, error)
+
+type activityService struct {
+	customerRepo     customer.Repository
+	subscriptionRepo subscription.Repository
+	invoiceRepo      invoice.Repository
+	walletRepo       wallet.Repository
+	logger           *logger.Logger
+}
+
+func NewActivityService(
+	customerRepo customer.Repository,
+	subscriptionRepo subscription.Repository,
+	invoiceRepo invoice.Repository,
+	walletRepo wallet.Repository,
+	logger *logger.Logger,
+) ActivityService {
+	return &activityService{
+		customerRepo:     customerRepo,
+		subscriptionRepo: subscriptionRepo,
+		invoiceRepo:      invoiceRepo,
+		walletRepo:       walletRepo,
+		logger:           logger,
+	}
+}
+
+func (s *activityService) GetCustomerActivity(ctx context.Context, customerID string, filter types.ActivityFilter) (*dto.ListCustomerActivityResponse, error) {
+	if _, err := s.customerRepo.Get(ctx, customerID); err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	// Each source is read up to the end of the requested page, which is enough
+	// to fill the page since every record yields at least one activity
+	window := filter.Offset + filter.Limit
+	sources := []activitySource{
+		s.subscriptionActivities,
+		s.invoiceActivities,
+		s.walletActivities,
+	}
+
+	var activities []dto.CustomerActivity
+	for _, source := range sources {
+		found, err := source(ctx, customerID, window)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range found {
+			if filter.Matches(a.ActivityType, a.Timestamp) {
+				activities = append(activities, a)
+			}
+		}
+	}
+
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].Timestamp.After(activities[j].Timestamp)
+	})
+
+	response := &dto.ListCustomerActivityResponse{
+		Activities: []dto.CustomerActivity{},
+		Total:      len(activities),
+		Offset:     filter.Offset,
+		Limit:      filter.Limit,
+	}
+
+	if filter.Offset < len(activities) {
+		end := filter.Offset + filter.Limit
+		if end > len(activities) {
+			end = len(activities)
+		}
+		response.Activities = activities[filter.Offset:end]
+	}
+
+	return response, nil
+}
+
+func (s *activityService) subscriptionActivities(ctx context.Context, customerID string, window int) ([]dto.CustomerActivity, error) {
+	subs, err := s.subscriptionRepo.List(ctx, &types.SubscriptionFilter{
+		Filter:     types.Filter{Limit: window},
+		CustomerID: customerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	var activities []dto.CustomerActivity
+	for _, sub := range subs {
+		activities = append(activities, dto.CustomerActivity{
+			ID:           activityID(types.ActivityTypeSubscriptionCreated, sub.ID),
+			ActivityType: types.ActivityTypeSubscriptionCreated,
+			Timestamp:    sub.CreatedAt,
+			ResourceType: "subscription",
+			ResourceID:   sub.ID,
+			Description:  fmt.Sprintf("Subscribed to plan %s", sub.PlanID),
+			Currency:     sub.Currency,
+		})
+
+		if sub.CancelledAt != nil {
+			activities = append(activities, dto.CustomerActivity{
+				ID:           activityID(types.ActivityTypeSubscriptionCancelled, sub.ID),
+				ActivityType: types.ActivityTypeSubscriptionCancelled,
+				Timestamp:    *sub.CancelledAt,
+				ResourceType: "subscription",
+				ResourceID:   sub.ID,
+				Description:  fmt.Sprintf("Cancelled subscription to plan %s", sub.PlanID),
+				Currency:     sub.Currency,
+			})
+		}
+	}
+
+	return activities, nil
+}
+
+func (s *activityService) invoiceActivities(ctx context.Context, customerID string, window int) ([]dto.CustomerActivity, error) {
+	invoices, err := s.invoiceRepo.List(ctx, &types.InvoiceFilter{
+		Filter:     types.Filter{Limit: window},
+		CustomerID: customerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+
+	var activities []dto.CustomerActivity
+	for _, inv := range invoices {
+		total := inv.Total
+		activities = append(activities, dto.CustomerActivity{
+			ID:           activityID(types.ActivityTypeInvoiceCreated, inv.ID),
+			ActivityType: types.ActivityTypeInvoiceCreated,
+			Timestamp:    inv.CreatedAt,
+			ResourceType: "invoice",
+			ResourceID:   inv.ID,
+			Description:  fmt.Sprintf("Created %s invoice", inv.InvoiceType),
+			Amount:       &total,
+			Currency:     inv.Currency,
+		})
+
+		if inv.FinalizedAt != nil {
+			description := "Finalized invoice"
+			if inv.InvoiceNumber != nil {
+				description = fmt.Sprintf("Finalized invoice %s", *inv.InvoiceNumber)
+			}
+
+			activities = append(activities, dto.CustomerActivity{
+				ID:           activityID(types.ActivityTypeInvoiceFinalized, inv.ID),
+				ActivityType: types.ActivityTypeInvoiceFinalized,
+				Timestamp:    *inv.FinalizedAt,
+				ResourceType: "invoice",
+				ResourceID:   inv.ID,
+				Description:  description,
+				Amount:       &total,
+				Currency:     inv.Currency,
+			})
+		}
+	}
+
+	return activities, nil
+}
+
+func (s *activityService) walletActivities(ctx context.Context, customerID string, window int) ([]dto.CustomerActivity, error) {
+	wallets, err := s.walletRepo.GetWalletsByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallets: %w", err)
+	}
+
+	var activities []dto.CustomerActivity
+	for _, w := range wallets {
+		activities = append(activities, dto.CustomerActivity{
+			ID:           activityID(types.ActivityTypeWalletCreated, w.ID),
+			ActivityType: types.ActivityTypeWalletCreated,
+			Timestamp:    w.CreatedAt,
+			ResourceType: "wallet",
+			ResourceID:   w.ID,
+			Description:  "Created wallet",
+			Currency:     w.Currency,
+		})
+
+		txs, err := s.walletRepo.GetTransactionsByWalletID(ctx, w.ID, window, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get wallet transactions: %w", err)
+		}
+
+		for _, tx := range txs {
+			activityType := types.ActivityTypeWalletCredited
+			description := "Credits granted"
+			if tx.Type == types.TransactionTypeDebit {
+				activityType = types.ActivityTypeWalletDebited
+				description = "Credits used"
+			}
+			if tx.Description != "" {
+				description = tx.Description
+			}
+
+			amount := tx.Amount
+			activities = append(activities, dto.CustomerActivity{
+				ID:           activityID(activityType, tx.ID),
+				ActivityType: activityType,
+				Timestamp:    tx.CreatedAt,
+				ResourceType: "wallet_transaction",
+				ResourceID:   tx.ID,
+				Description:  description,
+				Amount:       &amount,
+				Currency:     w.Currency,
+			})
+		}
+	}
+
+	return activities, nil
+}
+
+func activityID(activityType types.ActivityType, resourceID string) string {
+	return fmt.Sprintf("%s:%s", activityType, resourceID)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+type ActivityServiceSuite struct {
+	suite.Suite
+	ctx     context.Context
+	service ActivityService
+	now     time.Time
+}
+
+func TestActivityService(t *testing.T) {
+	suite.Run(t, new(ActivityServiceSuite))
+}
+
+func (s *ActivityServiceSuite) SetupTest() {
+	s.ctx = testutil.SetupContext()
+	s.now = time.Now().UTC()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	walletStore := testutil.NewInMemoryWalletStore()
+	s.service = NewActivityService(customerStore, subscriptionStore, invoiceStore, walletStore, logger.GetLogger())
+
+	s.Require().NoError(customerStore.Create(s.ctx, &customer.Customer{
+		ID:        "cust_1",
+		Name:      "Acme",
+		BaseModel: types.GetDefaultBaseModel(s.ctx),
+	}))
+
+	cancelledAt := s.now.Add(-time.Hour)
+	sub := &subscription.Subscription{
+		ID:          "sub_1",
+		CustomerID:  "cust_1",
+		PlanID:      "plan_1",
+		Currency:    "usd",
+		CancelledAt: &cancelledAt,
+		BaseModel:   types.GetDefaultBaseModel(s.ctx),
+	}
+	sub.CreatedAt = s.now.Add(-72 * time.Hour)
+	s.Require().NoError(subscriptionStore.Create(s.ctx, sub))
+
+	finalizedAt := s.now.Add(-24 * time.Hour)
+	number := "INV-00001"
+	inv := &invoice.Invoice{
+		ID:            "inv_1",
+		InvoiceNumber: &number,
+		CustomerID:    "cust_1",
+		InvoiceType:   types.InvoiceTypeSubscription,
+		Currency:      "usd",
+		Total:         decimal.NewFromInt(100),
+		FinalizedAt:   &finalizedAt,
+		BaseModel:     types.GetDefaultBaseModel(s.ctx),
+	}
+	inv.CreatedAt = s.now.Add(-48 * time.Hour)
+	s.Require().NoError(invoiceStore.Create(s.ctx, inv))
+
+	w := &wallet.Wallet{
+		ID:           "wallet_1",
+		CustomerID:   "cust_1",
+		Currency:     "usd",
+		WalletStatus: types.WalletStatusActive,
+		BaseModel:    types.GetDefaultBaseModel(s.ctx),
+	}
+	w.CreatedAt = s.now.Add(-96 * time.Hour)
+	s.Require().NoError(walletStore.CreateWallet(s.ctx, w))
+	s.Require().NoError(walletStore.CreditWallet(s.ctx, &wallet.WalletOperation{
+		WalletID: "wallet_1",
+		Type:     types.TransactionTypeCredit,
+		Amount:   decimal.NewFromInt(50),
+	}))
+}
+
+func (s *ActivityServiceSuite) TestFeedIsMergedNewestFirst() {
+	resp, err := s.service.GetCustomerActivity(s.ctx, "cust_1", types.ActivityFilter{Filter: types.Filter{Limit: 10}})
+	s.Require().NoError(err)
+
+	var got []types.ActivityType
+	for _, a := range resp.Activities {
+		got = append(got, a.ActivityType)
+	}
+	s.Equal([]types.ActivityType{
+		types.ActivityTypeWalletCredited,
+		types.ActivityTypeSubscriptionCancelled,
+		types.ActivityTypeInvoiceFinalized,
+		types.ActivityTypeInvoiceCreated,
+		types.ActivityTypeSubscriptionCreated,
+		types.ActivityTypeWalletCreated,
+	}, got)
+	s.Equal(6, resp.Total)
+	s.Equal("Finalized invoice INV-00001", resp.Activities[2].Description)
+}
+
+func (s *ActivityServiceSuite) TestFilterAndPagination() {
+	start := s.now.Add(-50 * time.Hour)
+	resp, err := s.service.GetCustomerActivity(s.ctx, "cust_1", types.ActivityFilter{
+		Filter:        types.Filter{Limit: 10},
+		ActivityTypes: []types.ActivityType{types.ActivityTypeInvoiceCreated, types.ActivityTypeSubscriptionCreated},
+		StartTime:     &start,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Activities, 1)
+	s.Equal("inv_1", resp.Activities[0].ResourceID)
+
+	resp, err = s.service.GetCustomerActivity(s.ctx, "cust_1", types.ActivityFilter{Filter: types.Filter{Limit: 2, Offset: 4}})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Activities, 2)
+	s.Equal(types.ActivityTypeSubscriptionCreated, resp.Activities[0].ActivityType)
+
+	_, err = s.service.GetCustomerActivity(s.ctx, "missing", types.ActivityFilter{})
+	s.Error(err)
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/anomaly"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+)
+
+// minRelativeStdDev floors the standard deviation of a baseline at a fraction of
+// its mean, so that a perfectly flat baseline does not flag every small change
+const minRelativeStdDev = 0.1
+
+type AnomalyService interface {
+	ListAnomalies(ctx context.Context, filter *types.AnomalyFilter) (*dto.ListAnomaliesResponse, error)
+
+	// DetectAnomalies scores the last window completed before now for every
+	// customer on every meter of every tenant with recent usage. New anomalies
+	// are stored and sent as usage.anomaly_detected webhooks
+	DetectAnomalies(ctx context.Context, now time.Time) error
+}
+
+type anomalyService struct {
+	cfg              config.AnomalyConfig
+	anomalyRepo      anomaly.Repository
+	meterRepo        meter.Repository
+	eventRepo        events.Repository
+	webhookPublisher webhook.Publisher
+	logger           *logger.Logger
+}
+
+func NewAnomalyService(
+	cfg *config.Configuration,
+	anomalyRepo anomaly.Repository,
+	meterRepo meter.Repository,
+	eventRepo events.Repository,
+	webhookPublisher webhook.Publisher,
+	logger *logger.Logger,
+) AnomalyService {
+	anomalyCfg := cfg.Anomaly
+	if anomalyCfg.WindowSize != types.WindowSizeDay {
+		anomalyCfg.WindowSize = types.WindowSizeHour
+	}
+	if anomalyCfg.BaselineWindows <= 0 {
+		anomalyCfg.BaselineWindows = 168
+	}
+	if anomalyCfg.MinBaselineWindows <= 0 {
+		anomalyCfg.MinBaselineWindows = 24
+	}
+	if anomalyCfg.SpikeSigma <= 0 {
+		anomalyCfg.SpikeSigma = 4
+	}
+	if anomalyCfg.DropSigma <= 0 {
+		anomalyCfg.DropSigma = 4
+	}
+
+	return &anomalyService{
+		cfg:              anomalyCfg,
+		anomalyRepo:      anomalyRepo,
+		meterRepo:        meterRepo,
+		eventRepo:        eventRepo,
+		webhookPublisher: webhookPublisher,
+		logger:           logger,
+	}
+}
+
+func (s *anomalyService) ListAnomalies(ctx context.Context, filter *types.AnomalyFilter) (*dto.ListAnomaliesResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	anomalies, err := s.anomalyRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list anomalies: %w", err)
+	}
+
+	response := &dto.ListAnomaliesResponse{
+		Anomalies: make([]dto.AnomalyResponse, len(anomalies)),
+		Total:     len(anomalies),
+		Offset:    filter.Offset,
+		Limit:     filter.Limit,
+	}
+
+	for i, a := range anomalies {
+		response.Anomalies[i] = dto.AnomalyResponse{Anomaly: a}
+	}
+
+	return response, nil
+}
+
+func (s *anomalyService) DetectAnomalies(ctx context.Context, now time.Time) error {
+	window := time.Hour
+	if s.cfg.WindowSize == types.WindowSizeDay {
+		window = 24 * time.Hour
+	}
+
+	windowEnd := now.UTC().Truncate(window)
+	windowStart := windowEnd.Add(-window)
+	baselineStart := windowStart.Add(-time.Duration(s.cfg.BaselineWindows) * window)
+
+	tenantIDs, err := s.eventRepo.GetTenantIDs(ctx, baselineStart)
+	if err != nil {
+		return fmt.Errorf("failed to get tenants: %w", err)
+	}
+
+	for _, tenantID := range tenantIDs {
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, tenantID)
+
+		meters, err := s.meterRepo.GetAllMeters(tenantCtx)
+		if err != nil {
+			return fmt.Errorf("failed to get meters: %w", err)
+		}
+
+		for _, m := range meters {
+			// Derived meters aggregate no events, anomalies show on their meters
+			if m.Status != types.StatusPublished || m.IsDerived() {
+				continue
+			}
+
+			// One broken meter must not stop the detection for the others
+			if err := s.detectMeterAnomalies(tenantCtx, m, baselineStart, windowStart, windowEnd, window); err != nil {
+				s.logger.Errorw("failed to detect usage anomalies",
+					"tenant_id", tenantID,
+					"meter_id", m.ID,
+					"error", err,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *anomalyService) detectMeterAnomalies(ctx context.Context, m *meter.Meter, baselineStart, windowStart, windowEnd time.Time, window time.Duration) error {
+	filters := make(map[string][]string, len(m.Filters))
+	for _, f := range m.Filters {
+		filters[f.Key] = f.Values
+	}
+
+	usage, err := s.eventRepo.GetUsageByCustomer(ctx, &events.UsageParams{
+		EventName:       m.EventName,
+		PropertyName:    m.Aggregation.Field,
+		AggregationType: m.Aggregation.Type,
+		WindowSize:      s.cfg.WindowSize,
+		StartTime:       baselineStart,
+		EndTime:         windowEnd,
+		Filters:         filters,
+		Precision:       m.Aggregation.Precision,
+		Percentile:      m.Aggregation.Percentile,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get usage by customer: %w", err)
+	}
+
+	for _, customerUsage := range usage {
+		a := s.score(customerUsage, windowStart, window)
+		if a == nil {
+			continue
+		}
+
+		exists, err := s.anomalyRepo.Exists(ctx, m.ID, a.ExternalCustomerID, a.WindowStart)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		a.ID = types.GenerateUUID()
+		a.MeterID = m.ID
+		a.EventName = m.EventName
+		a.WindowEnd = windowEnd
+		a.BaseModel = types.GetDefaultBaseModel(ctx)
+
+		if err := s.anomalyRepo.Create(ctx, a); err != nil {
+			return err
+		}
+
+		s.logger.Infow("usage anomaly detected",
+			"tenant_id", a.TenantID,
+			"meter_id", a.MeterID,
+			"external_customer_id", a.ExternalCustomerID,
+			"anomaly_type", a.AnomalyType,
+			"sigma", a.Sigma,
+		)
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventUsageAnomalyDetected, a); err != nil {
+			s.logger.Errorw("failed to publish usage anomaly webhook",
+				"anomaly_id", a.ID,
+				"error", err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// score compares the usage of the customer in the window starting at windowStart
+// with the windows before it. The baseline starts at the first window with usage,
+// windows without events after it count as zero usage. It returns nil when the
+// baseline is too short or the window is within the thresholds
+func (s *anomalyService) score(usage *events.CustomerUsage, windowStart time.Time, window time.Duration) *anomaly.Anomaly {
+	values := make(map[time.Time]float64, len(usage.Results))
+	var first time.Time
+	for _, r := range usage.Results {
+		t := r.WindowSize.UTC()
+		values[t] = r.Value.InexactFloat64()
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+	if first.IsZero() || !first.Before(windowStart) {
+		return nil
+	}
+
+	var baseline []float64
+	for t := first; t.Before(windowStart); t = t.Add(window) {
+		baseline = append(baseline, values[t])
+	}
+	if len(baseline) < s.cfg.MinBaselineWindows {
+		return nil
+	}
+
+	var sum float64
+	for _, v := range baseline {
+		sum += v
+	}
+	mean := sum / float64(len(baseline))
+
+	var squares float64
+	for _, v := range baseline {
+		squares += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(squares / float64(len(baseline)))
+	stdDev = math.Max(stdDev, minRelativeStdDev*math.Abs(mean))
+	if stdDev == 0 {
+		return nil
+	}
+
+	value := values[windowStart]
+	sigma := (value - mean) / stdDev
+
+	var anomalyType types.AnomalyType
+	switch {
+	case sigma >= s.cfg.SpikeSigma:
+		anomalyType = types.AnomalyTypeSpike
+	case -sigma >= s.cfg.DropSigma:
+		anomalyType = types.AnomalyTypeDrop
+	default:
+		return nil
+	}
+
+	return &anomaly.Anomaly{
+		ExternalCustomerID: usage.ExternalCustomerID,
+		AnomalyType:        anomalyType,
+		WindowStart:        windowStart,
+		Value:              decimal.NewFromFloat(value),
+		Baseline:           decimal.NewFromFloat(mean).Round(8),
+		StdDev:             decimal.NewFromFloat(stdDev).Round(8),
+		Sigma:              decimal.NewFromFloat(sigma).Round(4),
+	}
+}
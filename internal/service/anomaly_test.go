@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnomalyService_DetectAnomalies(t *testing.T) {
+	ctx := testutil.SetupContext()
+	now := time.Date(2024, 3, 10, 12, 30, 0, 0, time.UTC)
+	currentWindow := time.Date(2024, 3, 10, 11, 0, 0, 0, time.UTC)
+
+	meterStore := testutil.NewInMemoryMeterStore()
+	require.NoError(t, meterStore.CreateMeter(ctx, &meter.Meter{
+		ID:          "meter_api_calls",
+		Name:        "API calls",
+		EventName:   "api_call",
+		Aggregation: meter.Aggregation{Type: types.AggregationCount},
+		ResetUsage:  types.ResetUsageBillingPeriod,
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}))
+
+	eventStore := testutil.NewInMemoryEventStore()
+	seq := 0
+	ingest := func(customerID string, window time.Time, count int) {
+		for i := 0; i < count; i++ {
+			seq++
+			require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+				ID:                 fmt.Sprintf("evt_%d", seq),
+				TenantID:           types.GetTenantID(ctx),
+				EventName:          "api_call",
+				ExternalCustomerID: customerID,
+				Timestamp:          window.Add(time.Duration(i) * time.Second),
+				Properties:         map[string]interface{}{},
+			}))
+		}
+	}
+
+	// 30 hours of steady usage between 9 and 11 calls an hour
+	for h := 30; h >= 1; h-- {
+		window := currentWindow.Add(-time.Duration(h) * time.Hour)
+		for _, customerID := range []string{"cust_spike", "cust_drop", "cust_steady"} {
+			ingest(customerID, window, 9+h%3)
+		}
+	}
+	// Too little history to have a baseline
+	for h := 3; h >= 1; h-- {
+		ingest("cust_new", currentWindow.Add(-time.Duration(h)*time.Hour), 1)
+	}
+
+	ingest("cust_spike", currentWindow, 60)
+	ingest("cust_steady", currentWindow, 11)
+	ingest("cust_new", currentWindow, 50)
+	// cust_drop sends nothing in the current window
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:         "ep_1",
+		URL:        "https://example.com/hooks",
+		Secret:     "whsec_test",
+		EventTypes: []string{string(types.WebhookEventUsageAnomalyDetected)},
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	anomalyStore := testutil.NewInMemoryAnomalyStore()
+	cfg := &config.Configuration{Anomaly: config.AnomalyConfig{
+		WindowSize:         types.WindowSizeHour,
+		BaselineWindows:    48,
+		MinBaselineWindows: 24,
+		SpikeSigma:         3,
+		DropSigma:          3,
+	}}
+	svc := NewAnomalyService(cfg, anomalyStore, meterStore, eventStore,
+		webhook.NewPublisher(webhookStore, logger.GetLogger()), logger.GetLogger())
+
+	require.NoError(t, svc.DetectAnomalies(ctx, now))
+
+	resp, err := svc.ListAnomalies(ctx, &types.AnomalyFilter{})
+	require.NoError(t, err)
+	require.Len(t, resp.Anomalies, 2)
+
+	byCustomer := make(map[string]types.AnomalyType)
+	for _, a := range resp.Anomalies {
+		byCustomer[a.ExternalCustomerID] = a.AnomalyType
+		assert.Equal(t, "meter_api_calls", a.MeterID)
+		assert.True(t, currentWindow.Equal(a.WindowStart))
+		assert.True(t, currentWindow.Add(time.Hour).Equal(a.WindowEnd))
+	}
+	assert.Equal(t, types.AnomalyTypeSpike, byCustomer["cust_spike"])
+	assert.Equal(t, types.AnomalyTypeDrop, byCustomer["cust_drop"])
+
+	drops, err := svc.ListAnomalies(ctx, &types.AnomalyFilter{AnomalyType: types.AnomalyTypeDrop})
+	require.NoError(t, err)
+	require.Len(t, drops.Anomalies, 1)
+	assert.True(t, drops.Anomalies[0].Sigma.IsNegative())
+
+	deliveries, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 2)
+
+	// Running again for the same window flags nothing new
+	require.NoError(t, svc.DetectAnomalies(ctx, now.Add(10*time.Minute)))
+	resp, err = svc.ListAnomalies(ctx, &types.AnomalyFilter{})
+	require.NoError(t, err)
+	assert.Len(t, resp.Anomalies, 2)
+
+	deliveries, err = webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 2)
+}
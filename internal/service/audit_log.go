@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	auditDomain "github.com/flexprice/flexprice/internal/domain/audit"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type AuditLogService interface {
+	ListAuditLogs(ctx context.Context, filter *types.AuditLogFilter) (*dto.ListAuditLogsResponse, error)
+}
+
+type auditLogService struct {
+	auditLogRepo auditDomain.Repository
+	logger       *logger.Logger
+}
+
+func NewAuditLogService(auditLogRepo auditDomain.Repository, logger *logger.Logger) AuditLogService {
+	return &auditLogService{
+		auditLogRepo: auditLogRepo,
+		logger:       logger,
+	}
+}
+
+func (s *auditLogService) ListAuditLogs(ctx context.Context, filter *types.AuditLogFilter) (*dto.ListAuditLogsResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	logs, err := s.auditLogRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	response := &dto.ListAuditLogsResponse{
+		Logs:   make([]dto.AuditLogResponse, len(logs)),
+		Total:  len(logs),
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+	}
+
+	for i, l := range logs {
+		response.Logs[i] = dto.AuditLogResponse{Log: l}
+	}
+
+	return response, nil
+}
+
+// recordAudit publishes the audit log of a change. Services built by other
+// services to reuse their reads have no publisher and record nothing
+func recordAudit(ctx context.Context, publisher audit.Publisher, entityType types.AuditEntityType, entityID string, action types.AuditAction, before, after interface{}) {
+	if publisher == nil {
+		return
+	}
+	publisher.Publish(ctx, entityType, entityID, action, before, after)
+}
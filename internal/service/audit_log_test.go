@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	auditDomain "github.com/flexprice/flexprice/internal/domain/audit"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogService_RecordsCustomerChanges(t *testing.T) {
+	ctx := context.WithValue(testutil.SetupContext(), types.CtxAPIKeyID, "key_123")
+	store := testutil.NewInMemoryAuditLogStore()
+	publisher := audit.NewPublisher(store, logger.GetLogger())
+
+	customerService := NewCustomerService(testutil.NewInMemoryCustomerStore(), nil, nil, publisher, logger.GetLogger())
+	auditLogService := NewAuditLogService(store, logger.GetLogger())
+
+	created, err := customerService.CreateCustomer(ctx, dto.CreateCustomerRequest{ExternalID: "cust_1", Name: "Acme"})
+	require.NoError(t, err)
+
+	_, err = customerService.UpdateCustomer(ctx, created.ID, dto.UpdateCustomerRequest{ExternalID: "cust_1", Name: "Acme Inc"})
+	require.NoError(t, err)
+
+	// An update that changes nothing is not recorded
+	_, err = customerService.UpdateCustomer(ctx, created.ID, dto.UpdateCustomerRequest{ExternalID: "cust_1", Name: "Acme Inc"})
+	require.NoError(t, err)
+
+	require.NoError(t, customerService.DeleteCustomer(ctx, created.ID))
+
+	// Close waits for the logs to be written
+	publisher.Close()
+
+	resp, err := auditLogService.ListAuditLogs(ctx, &types.AuditLogFilter{
+		EntityType: types.AuditEntityTypeCustomer,
+		EntityID:   created.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Logs, 3)
+
+	actions := make(map[types.AuditAction]*auditDomain.Log)
+	for _, log := range resp.Logs {
+		assert.Equal(t, types.AuditActorTypeAPIKey, log.ActorType)
+		assert.Equal(t, "key_123", log.ActorID)
+		assert.Equal(t, types.GetRequestID(ctx), log.RequestID)
+		actions[log.Action] = log.Log
+	}
+	require.Len(t, actions, 3)
+
+	var changes map[string]auditDomain.Change
+	require.NoError(t, json.Unmarshal(actions[types.AuditActionUpdate].Changes, &changes))
+	assert.Equal(t, map[string]auditDomain.Change{
+		"name": {Before: "Acme", After: "Acme Inc"},
+	}, changes)
+
+	filtered, err := auditLogService.ListAuditLogs(ctx, &types.AuditLogFilter{Action: types.AuditActionDelete})
+	require.NoError(t, err)
+	require.Len(t, filtered.Logs, 1)
+	assert.Equal(t, created.ID, filtered.Logs[0].EntityID)
+}
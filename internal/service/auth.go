@@ -5,29 +5,64 @@ import (
 	"fmt"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
 	authProvider "github.com/flexprice/flexprice/internal/auth"
 	"github.com/flexprice/flexprice/internal/config"
 	"github.com/flexprice/flexprice/internal/domain/auth"
+	"github.com/flexprice/flexprice/internal/domain/role"
+	"github.com/flexprice/flexprice/internal/domain/sso"
 	"github.com/flexprice/flexprice/internal/domain/user"
+	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/types"
 )
 
 type AuthService interface {
 	SignUp(ctx context.Context, req *dto.SignUpRequest) (*dto.AuthResponse, error)
 	Login(ctx context.Context, req *dto.LoginRequest) (*dto.AuthResponse, error)
+
+	// GetSSOConfig, SetSSOConfig and DeleteSSOConfig manage the identity provider
+	// of the tenant in context
+	GetSSOConfig(ctx context.Context) (*dto.SSOConfigResponse, error)
+	SetSSOConfig(ctx context.Context, req dto.SetSSOConfigRequest) (*dto.SSOConfigResponse, error)
+	DeleteSSOConfig(ctx context.Context) error
+
+	// StartSSO returns the identity provider page of the tenant of the email domain
+	StartSSO(ctx context.Context, req *dto.StartSSORequest) (*dto.StartSSOResponse, error)
+	// CompleteOIDCLogin and CompleteSAMLLogin sign in the user authenticated by
+	// the identity provider, provisioning the user and its role from its groups
+	CompleteOIDCLogin(ctx context.Context, code, state string) (*dto.AuthResponse, error)
+	CompleteSAMLLogin(ctx context.Context, samlResponse, relayState string) (*dto.AuthResponse, error)
 }
 
 type authService struct {
-	userRepo     user.Repository
-	authProvider authProvider.Provider
-	authRepo     auth.Repository
+	cfg            *config.Configuration
+	userRepo       user.Repository
+	authProvider   authProvider.Provider
+	authRepo       auth.Repository
+	roleRepo       role.Repository
+	ssoRepo        sso.Repository
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
 }
 
-func NewAuthService(cfg *config.Configuration, userRepo user.Repository, authRepo auth.Repository) AuthService {
+func NewAuthService(
+	cfg *config.Configuration,
+	userRepo user.Repository,
+	authRepo auth.Repository,
+	roleRepo role.Repository,
+	ssoRepo sso.Repository,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) AuthService {
 	return &authService{
-		userRepo:     userRepo,
-		authProvider: authProvider.NewProvider(cfg),
-		authRepo:     authRepo,
+		cfg:            cfg,
+		userRepo:       userRepo,
+		authProvider:   authProvider.NewProvider(cfg),
+		authRepo:       authRepo,
+		roleRepo:       roleRepo,
+		ssoRepo:        ssoRepo,
+		auditPublisher: auditPublisher,
+		logger:         logger,
 	}
 }
 
@@ -58,6 +93,19 @@ func (s *authService) SignUp(ctx context.Context, req *dto.SignUpRequest) (*dto.
 		return nil, fmt.Errorf("unable to create auth: %w", err)
 	}
 
+	// The user signing up for the tenant administers it
+	baseModel := types.GetDefaultBaseModel(ctx)
+	baseModel.TenantID = tenantID
+	err = s.roleRepo.Create(ctx, &role.Assignment{
+		ID:        types.GenerateUUID(),
+		UserID:    user.ID,
+		Role:      types.RoleAdmin,
+		BaseModel: baseModel,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to assign role: %w", err)
+	}
+
 	response := &dto.AuthResponse{
 		Token: authResponse.AuthToken,
 	}
@@ -72,6 +120,14 @@ func (s *authService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Au
 		return nil, fmt.Errorf("unable to get user: %w", err)
 	}
 
+	ssoConfig, err := s.ssoRepo.Get(context.WithValue(ctx, types.CtxTenantID, user.TenantID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get sso config: %w", err)
+	}
+	if ssoConfig != nil && ssoConfig.PasswordLoginDisabled {
+		return nil, ErrPasswordLoginDisabled
+	}
+
 	auth, err := s.authRepo.GetAuthByUserID(ctx, user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("unable to fetch user authentication channel: %w", err)
@@ -8,6 +8,7 @@ import (
 	authProvider "github.com/flexprice/flexprice/internal/auth"
 	"github.com/flexprice/flexprice/internal/config"
 	"github.com/flexprice/flexprice/internal/domain/user"
+	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/testutil"
 	"github.com/flexprice/flexprice/internal/types"
 	"github.com/stretchr/testify/suite"
@@ -19,6 +20,8 @@ type AuthServiceSuite struct {
 	authService *authService
 	userRepo    *testutil.InMemoryUserRepository
 	authRepo    *testutil.InMemoryAuthRepository
+	roleRepo    *testutil.InMemoryRoleAssignmentStore
+	ssoRepo     *testutil.InMemorySSOStore
 }
 
 func TestAuthService(t *testing.T) {
@@ -29,21 +32,30 @@ func (s *AuthServiceSuite) SetupTest() {
 	s.ctx = testutil.SetupContext()
 	s.userRepo = testutil.NewInMemoryUserRepository()
 	s.authRepo = testutil.NewInMemoryAuthRepository()
+	s.roleRepo = testutil.NewInMemoryRoleAssignmentStore()
+	s.ssoRepo = testutil.NewInMemorySSOStore()
 
 	// Create a real provider (e.g., flexpriceAuth) with test config
 	cfg := &config.Configuration{
 		Auth: config.AuthConfig{
 			Provider: types.AuthProviderFlexprice,
 			Secret:   "test-secret", // Use a test secret
+			SSO: config.SSOConfig{
+				BaseURL: "https://api.example.com",
+			},
 		},
 	}
 
 	realProvider := authProvider.NewFlexpriceAuth(cfg)
 
 	s.authService = &authService{
+		cfg:          cfg,
 		userRepo:     s.userRepo,
 		authProvider: realProvider,
 		authRepo:     s.authRepo,
+		roleRepo:     s.roleRepo,
+		ssoRepo:      s.ssoRepo,
+		logger:       logger.GetLogger(),
 	}
 }
 
@@ -96,6 +108,14 @@ func (s *AuthServiceSuite) TestSignUp() {
 				s.NotNil(resp)
 				// We used a real provider, so check that token exists (not necessarily 'auth-token' as before)
 				s.NotEmpty(resp.Token)
+
+				// The user signing up administers the tenant
+				created, err := s.userRepo.GetByEmail(s.ctx, tc.req.Email)
+				s.Require().NoError(err)
+				assignments, err := s.roleRepo.ListByUser(s.ctx, created.ID)
+				s.Require().NoError(err)
+				s.Require().Len(assignments, 1)
+				s.Equal(types.RoleAdmin, assignments[0].Role)
 			}
 		})
 	}
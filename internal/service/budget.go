@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/domain/budget"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+)
+
+// ErrUsageBlocked is returned when ingesting an event of a meter billed to a
+// subscription whose block_usage budget is exceeded
+var ErrUsageBlocked = errors.New("usage blocked by an exceeded budget")
+
+// budgetCacheTTL is how long the spend cached for a customer is used before
+// ingestion refreshes it
+const budgetCacheTTL = 30 * time.Second
+
+type BudgetService interface {
+	// CreateBudget creates the budget and evaluates the spend of its customer
+	CreateBudget(ctx context.Context, req dto.CreateBudgetRequest) (*dto.BudgetResponse, error)
+	GetBudget(ctx context.Context, id string) (*dto.BudgetResponse, error)
+	ListBudgets(ctx context.Context, req dto.ListBudgetsRequest) (*dto.ListBudgetsResponse, error)
+	UpdateBudget(ctx context.Context, id string, req dto.UpdateBudgetRequest) (*dto.BudgetResponse, error)
+	DeleteBudget(ctx context.Context, id string) error
+
+	// CheckUsage returns ErrUsageBlocked when the event name is the one of a
+	// meter blocked by an exceeded budget of the customer. It reads the spend
+	// cached for the customer and refreshes it in the background once stale, so
+	// ingestion never waits on the usage charges being computed
+	CheckUsage(ctx context.Context, externalCustomerID, eventName string) error
+}
+
+// BudgetExceededEvent is the payload of the budget.exceeded webhook
+type BudgetExceededEvent struct {
+	BudgetID       string             `json:"budget_id"`
+	CustomerID     string             `json:"customer_id"`
+	SubscriptionID string             `json:"subscription_id,omitempty"`
+	Amount         decimal.Decimal    `json:"amount"`
+	Spend          decimal.Decimal    `json:"spend"`
+	Currency       string             `json:"currency"`
+	Action         types.BudgetAction `json:"action"`
+	// PausedSubscriptionIDs are the subscriptions paused by the budget
+	PausedSubscriptionIDs []string `json:"paused_subscription_ids,omitempty"`
+}
+
+// budgetCacheEntry is the state of the budgets of a customer as of their last
+// evaluation
+type budgetCacheEntry struct {
+	refreshedAt time.Time
+	refreshing  bool
+	// blockedEvents are the event names of the meters billed to the
+	// subscriptions of the exceeded block_usage budgets
+	blockedEvents map[string]bool
+}
+
+type budgetService struct {
+	repo             budget.Repository
+	customerRepo     customer.Repository
+	subscriptionRepo subscription.Repository
+	priceRepo        price.Repository
+	meterRepo        meter.Repository
+	invoiceService   InvoiceService
+	webhookPublisher webhook.Publisher
+	auditPublisher   audit.Publisher
+	// clock is the time the spend is evaluated at, the wall clock when nil
+	clock  *clock.Clock
+	logger *logger.Logger
+
+	mu    sync.Mutex
+	cache map[string]*budgetCacheEntry
+}
+
+func NewBudgetService(
+	repo budget.Repository,
+	customerRepo customer.Repository,
+	subscriptionRepo subscription.Repository,
+	priceRepo price.Repository,
+	meterRepo meter.Repository,
+	invoiceService InvoiceService,
+	webhookPublisher webhook.Publisher,
+	auditPublisher audit.Publisher,
+	clock *clock.Clock,
+	logger *logger.Logger,
+) BudgetService {
+	return &budgetService{
+		repo:             repo,
+		customerRepo:     customerRepo,
+		subscriptionRepo: subscriptionRepo,
+		priceRepo:        priceRepo,
+		meterRepo:        meterRepo,
+		invoiceService:   invoiceService,
+		webhookPublisher: webhookPublisher,
+		auditPublisher:   auditPublisher,
+		clock:            clock,
+		logger:           logger,
+		cache:            make(map[string]*budgetCacheEntry),
+	}
+}
+
+func (s *budgetService) CreateBudget(ctx context.Context, req dto.CreateBudgetRequest) (*dto.BudgetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	cust, err := s.customerRepo.Get(ctx, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	if req.SubscriptionID != "" {
+		sub, err := s.subscriptionRepo.Get(ctx, req.SubscriptionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subscription: %w", err)
+		}
+		if sub.CustomerID != req.CustomerID {
+			return nil, fmt.Errorf("invalid request: subscription does not belong to the customer")
+		}
+	}
+
+	b := req.ToBudget(ctx)
+	if err := s.repo.Create(ctx, b); err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeBudget, b.ID, types.AuditActionCreate, nil, b)
+	return s.evaluateAndGet(ctx, cust, b.ID)
+}
+
+func (s *budgetService) GetBudget(ctx context.Context, id string) (*dto.BudgetResponse, error) {
+	b, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	return &dto.BudgetResponse{Budget: b}, nil
+}
+
+func (s *budgetService) ListBudgets(ctx context.Context, req dto.ListBudgetsRequest) (*dto.ListBudgetsResponse, error) {
+	var budgets []*budget.Budget
+	var err error
+	if req.CustomerID != "" {
+		budgets, err = s.repo.ListByCustomer(ctx, req.CustomerID)
+	} else {
+		budgets, err = s.repo.List(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+
+	response := &dto.ListBudgetsResponse{Budgets: make([]dto.BudgetResponse, len(budgets))}
+	for i, b := range budgets {
+		response.Budgets[i] = dto.BudgetResponse{Budget: b}
+	}
+
+	return response, nil
+}
+
+func (s *budgetService) UpdateBudget(ctx context.Context, id string, req dto.UpdateBudgetRequest) (*dto.BudgetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	b, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	before := *b
+
+	if req.Name != nil {
+		b.Name = *req.Name
+	}
+	if req.Amount != nil {
+		b.Amount = *req.Amount
+	}
+	if req.Action != nil {
+		b.Action = *req.Action
+	}
+
+	if err := s.repo.Update(ctx, b); err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeBudget, b.ID, types.AuditActionUpdate, &before, b)
+
+	cust, err := s.customerRepo.Get(ctx, b.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	return s.evaluateAndGet(ctx, cust, b.ID)
+}
+
+func (s *budgetService) DeleteBudget(ctx context.Context, id string) error {
+	b, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeBudget, id, types.AuditActionDelete, b, nil)
+
+	// The usage blocked by the budget is let through again
+	cust, err := s.customerRepo.Get(ctx, b.CustomerID)
+	if err != nil {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+	s.refresh(ctx, cust)
+
+	return nil
+}
+
+// evaluateAndGet evaluates the budgets of the customer so that the budget is
+// returned with its spend and its action is enforced right away
+func (s *budgetService) evaluateAndGet(ctx context.Context, cust *customer.Customer, id string) (*dto.BudgetResponse, error) {
+	s.refresh(ctx, cust)
+	return s.GetBudget(ctx, id)
+}
+
+func (s *budgetService) CheckUsage(ctx context.Context, externalCustomerID, eventName string) error {
+	key := budgetCacheKey(ctx, externalCustomerID)
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	if !ok {
+		entry = &budgetCacheEntry{}
+		s.cache[key] = entry
+	}
+	stale := !entry.refreshing && now.Sub(entry.refreshedAt) >= budgetCacheTTL
+	if stale {
+		entry.refreshing = true
+	}
+	blocked := entry.blockedEvents[eventName]
+	s.mu.Unlock()
+
+	if stale {
+		go s.refreshExternal(context.WithoutCancel(ctx), externalCustomerID)
+	}
+
+	if blocked {
+		return ErrUsageBlocked
+	}
+	return nil
+}
+
+func (s *budgetService) refreshExternal(ctx context.Context, externalCustomerID string) {
+	customers, err := s.customerRepo.ListByExternalIDs(ctx, []string{externalCustomerID})
+	if err != nil || len(customers) == 0 {
+		// Events of unknown customers are not billed, there is nothing to block
+		if err != nil {
+			s.logger.Errorw("failed to get customer of budgets",
+				"tenant_id", types.GetTenantID(ctx),
+				"external_customer_id", externalCustomerID,
+				"error", err,
+			)
+		}
+		s.store(budgetCacheKey(ctx, externalCustomerID), nil, err == nil)
+		return
+	}
+
+	s.refresh(ctx, customers[0])
+}
+
+// refresh evaluates the budgets of the customer and caches the events they
+// block. The events blocked before are kept when the evaluation fails
+func (s *budgetService) refresh(ctx context.Context, cust *customer.Customer) {
+	blocked, err := s.evaluate(ctx, cust)
+	if err != nil {
+		s.logger.Errorw("failed to evaluate budgets",
+			"tenant_id", types.GetTenantID(ctx),
+			"customer_id", cust.ID,
+			"error", err,
+		)
+	}
+	s.store(budgetCacheKey(ctx, cust.ExternalID), blocked, err == nil)
+}
+
+func (s *budgetService) store(key string, blocked map[string]bool, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.cache[key]
+	if !exists {
+		entry = &budgetCacheEntry{}
+		s.cache[key] = entry
+	}
+	// A failed evaluation is retried once the entry is stale like any other
+	entry.refreshing = false
+	entry.refreshedAt = s.clock.Now()
+	if ok {
+		entry.blockedEvents = blocked
+	}
+}
+
+// evaluate computes the spend of the budgets of the customer, takes or lifts
+// their actions when they cross their amount, and returns the event names
+// blocked for the customer
+func (s *budgetService) evaluate(ctx context.Context, cust *customer.Customer) (map[string]bool, error) {
+	budgets, err := s.repo.ListByCustomer(ctx, cust.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+
+	subs, err := s.subscriptionRepo.List(ctx, &types.SubscriptionFilter{
+		Filter:     types.Filter{Limit: types.DefaultFilterLimit},
+		CustomerID: cust.ID,
+		Status:     types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	now := s.clock.Now()
+
+	// Budgets of the same window share the usage charges of the subscriptions
+	charges := make(map[string]decimal.Decimal)
+	blocked := make(map[string]bool)
+
+	for _, b := range budgets {
+		var covered []*subscription.Subscription
+		spend := decimal.Zero
+		for _, sub := range subs {
+			// Paused subscriptions keep counting so that pausing does not lift
+			// the budget that paused them
+			if sub.SubscriptionStatus != types.SubscriptionStatusActive && sub.SubscriptionStatus != types.SubscriptionStatusPaused {
+				continue
+			}
+			if !b.Covers(sub.ID) || !strings.EqualFold(sub.Currency, b.Currency) {
+				continue
+			}
+			covered = append(covered, sub)
+
+			from := b.WindowStart(sub, now)
+			key := sub.ID + "/" + from.String()
+			amount, ok := charges[key]
+			if !ok {
+				amount, err = s.invoiceService.GetUsageCharges(ctx, sub.ID, from, now)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get usage charges of subscription %s: %w", sub.ID, err)
+				}
+				charges[key] = amount
+			}
+			spend = spend.Add(amount)
+		}
+
+		wasExceeded := b.ExceededAt != nil
+		exceeded := spend.GreaterThan(b.Amount)
+
+		b.Spend = spend
+		b.EvaluatedAt = &now
+		if exceeded && !wasExceeded {
+			b.ExceededAt = &now
+		} else if !exceeded {
+			b.ExceededAt = nil
+		}
+
+		if err := s.repo.Update(ctx, b); err != nil {
+			return nil, fmt.Errorf("failed to update budget: %w", err)
+		}
+
+		switch {
+		case exceeded && !wasExceeded:
+			if err := s.exceed(ctx, b, covered); err != nil {
+				return nil, err
+			}
+		case !exceeded && wasExceeded && b.Action == types.BudgetActionPauseSubscription:
+			if err := s.setStatus(ctx, covered, types.SubscriptionStatusPaused, types.SubscriptionStatusActive); err != nil {
+				return nil, err
+			}
+		}
+
+		if exceeded && b.Action == types.BudgetActionBlockUsage {
+			if err := s.addBillableEvents(ctx, covered, blocked); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return blocked, nil
+}
+
+// exceed takes the action of the budget whose spend went over its amount
+func (s *budgetService) exceed(ctx context.Context, b *budget.Budget, covered []*subscription.Subscription) error {
+	event := &BudgetExceededEvent{
+		BudgetID:       b.ID,
+		CustomerID:     b.CustomerID,
+		SubscriptionID: b.SubscriptionID,
+		Amount:         b.Amount,
+		Spend:          b.Spend,
+		Currency:       b.Currency,
+		Action:         b.Action,
+	}
+
+	if b.Action == types.BudgetActionPauseSubscription {
+		for _, sub := range covered {
+			if sub.SubscriptionStatus == types.SubscriptionStatusActive {
+				event.PausedSubscriptionIDs = append(event.PausedSubscriptionIDs, sub.ID)
+			}
+		}
+		if err := s.setStatus(ctx, covered, types.SubscriptionStatusActive, types.SubscriptionStatusPaused); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Infow("budget exceeded",
+		"tenant_id", b.TenantID,
+		"budget_id", b.ID,
+		"customer_id", b.CustomerID,
+		"spend", b.Spend,
+		"amount", b.Amount,
+		"action", b.Action,
+	)
+
+	if err := s.webhookPublisher.Publish(ctx, types.WebhookEventBudgetExceeded, event); err != nil {
+		return fmt.Errorf("failed to publish budget webhook: %w", err)
+	}
+	return nil
+}
+
+// setStatus moves the subscriptions in the from status to the to status
+func (s *budgetService) setStatus(ctx context.Context, subs []*subscription.Subscription, from, to types.SubscriptionStatus) error {
+	for _, sub := range subs {
+		if sub.SubscriptionStatus != from {
+			continue
+		}
+		sub.SubscriptionStatus = to
+		if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+	}
+	return nil
+}
+
+// addBillableEvents adds the event names of the meters of the usage prices of
+// the subscriptions to events
+func (s *budgetService) addBillableEvents(ctx context.Context, subs []*subscription.Subscription, events map[string]bool) error {
+	for _, sub := range subs {
+		prices, err := s.priceRepo.GetByPlanID(ctx, sub.PlanID)
+		if err != nil {
+			return fmt.Errorf("failed to get prices: %w", err)
+		}
+
+		for _, p := range prices {
+			if p.Type != types.PRICE_TYPE_USAGE || p.MeterID == "" {
+				continue
+			}
+			m, err := s.meterRepo.GetMeter(ctx, p.MeterID)
+			if err != nil {
+				return fmt.Errorf("failed to get meter: %w", err)
+			}
+			events[m.EventName] = true
+		}
+	}
+	return nil
+}
+
+func budgetCacheKey(ctx context.Context, externalCustomerID string) string {
+	return types.GetTenantID(ctx) + "/" + types.GetEnvironmentID(ctx) + "/" + externalCustomerID
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetService_Enforcement(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	cust := &customer.Customer{
+		ID:         "cust_123",
+		ExternalID: "ext_cust_123",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}
+	require.NoError(t, customerStore.Create(ctx, cust))
+
+	meterStore := testutil.NewInMemoryMeterStore()
+	require.NoError(t, meterStore.CreateMeter(ctx, &meter.Meter{
+		ID:          "meter_api_calls",
+		Name:        "API Calls",
+		EventName:   "api_call",
+		Aggregation: meter.Aggregation{Type: types.AggregationCount},
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}))
+
+	planStore := testutil.NewInMemoryPlanStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Usage Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	priceStore := testutil.NewInMemoryPriceStore()
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_api_calls",
+		PlanID:             "plan_123",
+		MeterID:            "meter_api_calls",
+		Type:               types.PRICE_TYPE_USAGE,
+		Amount:             decimal.NewFromInt(1),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	start := time.Now().UTC().AddDate(0, 0, -1)
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	eventStore := testutil.NewInMemoryEventStore()
+	calls := func(count int) {
+		for i := 0; i < count; i++ {
+			require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+				ID:                 types.GenerateUUID(),
+				TenantID:           types.GetTenantID(ctx),
+				EventName:          "api_call",
+				ExternalCustomerID: "ext_cust_123",
+				Timestamp:          time.Now().UTC().Add(-time.Minute),
+				Properties:         map[string]interface{}{},
+			}))
+		}
+	}
+
+	webhookPublisher := webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger())
+	invoiceService := NewInvoiceService(
+		testutil.NewInMemoryInvoiceStore(), testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+		testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+		nil, testutil.NewInMemoryTxManager(), webhookPublisher,
+		nil, nil, nil, nil, nil, nil,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	svc := NewBudgetService(
+		testutil.NewInMemoryBudgetStore(), customerStore, subscriptionStore, priceStore, meterStore,
+		invoiceService, webhookPublisher, nil, nil, logger.GetLogger(),
+	)
+	eventService := NewEventService(config.GetDefaultConfig(), testutil.NewInMemoryMessageBroker(), eventStore, meterStore, nil, nil, nil, svc, nil, logger.GetLogger())
+
+	ingest := func(eventName string) error {
+		return eventService.CreateEvent(ctx, &dto.IngestEventRequest{
+			EventName:          eventName,
+			ExternalCustomerID: "ext_cust_123",
+			Properties:         map[string]interface{}{},
+		})
+	}
+
+	calls(3)
+	blocking, err := svc.CreateBudget(ctx, dto.CreateBudgetRequest{
+		Name:       "API spend",
+		CustomerID: "cust_123",
+		Amount:     decimal.NewFromInt(5),
+		Currency:   "USD",
+		Period:     types.BudgetPeriodBillingPeriod,
+		Action:     types.BudgetActionBlockUsage,
+	})
+	require.NoError(t, err)
+	assert.True(t, blocking.Spend.Equal(decimal.NewFromInt(3)))
+	assert.Nil(t, blocking.ExceededAt)
+	require.NoError(t, ingest("api_call"))
+
+	// Ingestion reads the spend cached at the last evaluation
+	calls(4)
+	require.NoError(t, ingest("api_call"))
+	svc.(*budgetService).refresh(ctx, cust)
+
+	blocking, err = svc.GetBudget(ctx, blocking.ID)
+	require.NoError(t, err)
+	assert.True(t, blocking.Spend.Equal(decimal.NewFromInt(7)))
+	assert.NotNil(t, blocking.ExceededAt)
+	assert.ErrorIs(t, ingest("api_call"), ErrUsageBlocked)
+	assert.NoError(t, ingest("page_view"), "events of meters not billed are let through")
+
+	// Pausing budgets pause the subscriptions they cover until back under
+	pausing, err := svc.CreateBudget(ctx, dto.CreateBudgetRequest{
+		Name:           "Subscription spend",
+		CustomerID:     "cust_123",
+		SubscriptionID: "sub_123",
+		Amount:         decimal.NewFromInt(6),
+		Currency:       "usd",
+		Period:         types.BudgetPeriodBillingPeriod,
+		Action:         types.BudgetActionPauseSubscription,
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, pausing.ExceededAt)
+
+	sub, err := subscriptionStore.Get(ctx, "sub_123")
+	require.NoError(t, err)
+	assert.Equal(t, types.SubscriptionStatusPaused, sub.SubscriptionStatus)
+
+	amount := decimal.NewFromInt(100)
+	pausing, err = svc.UpdateBudget(ctx, pausing.ID, dto.UpdateBudgetRequest{Amount: &amount})
+	require.NoError(t, err)
+	assert.Nil(t, pausing.ExceededAt)
+
+	sub, err = subscriptionStore.Get(ctx, "sub_123")
+	require.NoError(t, err)
+	assert.Equal(t, types.SubscriptionStatusActive, sub.SubscriptionStatus)
+
+	// Deleting the blocking budget lets the usage through again
+	require.NoError(t, svc.DeleteBudget(ctx, blocking.ID))
+	assert.NoError(t, ingest("api_call"))
+}
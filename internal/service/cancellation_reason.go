@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/cancellationreason"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// unspecifiedCancellationReason groups the cancellations recorded without a reason,
+// such as trials cancelled by the billing cron and cancellations older than the taxonomy
+const unspecifiedCancellationReason = "unspecified"
+
+// churnTenureBuckets are the upper bounds in days of the tenure buckets of the
+// churn analytics. Tenures past the last bound fall in an open ended bucket
+var churnTenureBuckets = []struct {
+	label   string
+	maxDays int
+}{
+	{label: "0-30d", maxDays: 30},
+	{label: "31-90d", maxDays: 90},
+	{label: "91-180d", maxDays: 180},
+	{label: "181-365d", maxDays: 365},
+}
+
+const churnTenureBucketOverYear = "365d+"
+
+type CancellationReasonService interface {
+	CreateCancellationReason(ctx context.Context, req dto.CreateCancellationReasonRequest) (*dto.CancellationReasonResponse, error)
+	ListCancellationReasons(ctx context.Context) (*dto.ListCancellationReasonsResponse, error)
+	DeleteCancellationReason(ctx context.Context, id string) error
+
+	// GetChurnReasons aggregates the cancellations of the window by reason, plan and tenure
+	GetChurnReasons(ctx context.Context, req dto.ChurnReasonsRequest) (*dto.ChurnReasonsResponse, error)
+}
+
+type cancellationReasonService struct {
+	repo             cancellationreason.Repository
+	subscriptionRepo subscription.Repository
+	auditPublisher   audit.Publisher
+	logger           *logger.Logger
+}
+
+func NewCancellationReasonService(
+	repo cancellationreason.Repository,
+	subscriptionRepo subscription.Repository,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) CancellationReasonService {
+	return &cancellationReasonService{
+		repo:             repo,
+		subscriptionRepo: subscriptionRepo,
+		auditPublisher:   auditPublisher,
+		logger:           logger,
+	}
+}
+
+func (s *cancellationReasonService) CreateCancellationReason(ctx context.Context, req dto.CreateCancellationReasonRequest) (*dto.CancellationReasonResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if req.Code == unspecifiedCancellationReason {
+		return nil, fmt.Errorf("invalid request: code %s is reserved", unspecifiedCancellationReason)
+	}
+
+	reason := req.ToCancellationReason(ctx)
+	if err := s.repo.Create(ctx, reason); err != nil {
+		return nil, fmt.Errorf("failed to create cancellation reason: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCancellationReason, reason.ID, types.AuditActionCreate, nil, reason)
+	return &dto.CancellationReasonResponse{CancellationReason: reason}, nil
+}
+
+func (s *cancellationReasonService) ListCancellationReasons(ctx context.Context) (*dto.ListCancellationReasonsResponse, error) {
+	reasons, isDefault, err := listCancellationReasons(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ListCancellationReasonsResponse{
+		Reasons:   make([]dto.CancellationReasonResponse, len(reasons)),
+		IsDefault: isDefault,
+	}
+	for i, reason := range reasons {
+		response.Reasons[i] = dto.CancellationReasonResponse{CancellationReason: reason}
+	}
+
+	return response, nil
+}
+
+func (s *cancellationReasonService) DeleteCancellationReason(ctx context.Context, id string) error {
+	reason, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get cancellation reason: %w", err)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete cancellation reason: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCancellationReason, id, types.AuditActionDelete, reason, nil)
+	return nil
+}
+
+func (s *cancellationReasonService) GetChurnReasons(ctx context.Context, req dto.ChurnReasonsRequest) (*dto.ChurnReasonsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	end := req.EndTime
+	if end.IsZero() {
+		end = time.Now().UTC()
+	}
+	start := req.StartTime
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -30)
+	}
+
+	subs, err := s.subscriptionRepo.ListCancelledBetween(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cancelled subscriptions: %w", err)
+	}
+
+	reasons, _, err := listCancellationReasons(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{unspecifiedCancellationReason: "Unspecified"}
+	for _, reason := range reasons {
+		names[reason.Code] = reason.Name
+	}
+
+	all := newChurnTally()
+	byPlan := make(map[string]*churnTally)
+	byTenure := make(map[string]*churnTally)
+
+	for _, sub := range subs {
+		reason := sub.CancellationReason
+		if reason == "" {
+			reason = unspecifiedCancellationReason
+		}
+
+		all.add(reason)
+
+		if byPlan[sub.PlanID] == nil {
+			byPlan[sub.PlanID] = newChurnTally()
+		}
+		byPlan[sub.PlanID].add(reason)
+
+		tenure := churnTenureBucket(sub.StartDate, *sub.CancelledAt)
+		if byTenure[tenure] == nil {
+			byTenure[tenure] = newChurnTally()
+		}
+		byTenure[tenure].add(reason)
+	}
+
+	response := &dto.ChurnReasonsResponse{
+		StartTime:          start,
+		EndTime:            end,
+		TotalCancellations: all.total,
+		ByReason:           all.counts(names),
+		ByPlan:             make([]dto.ChurnPlanBreakdown, 0, len(byPlan)),
+		ByTenure:           make([]dto.ChurnTenureBreakdown, 0, len(byTenure)),
+	}
+
+	for planID, tally := range byPlan {
+		response.ByPlan = append(response.ByPlan, dto.ChurnPlanBreakdown{
+			PlanID:  planID,
+			Count:   tally.total,
+			Reasons: tally.counts(names),
+		})
+	}
+	sort.Slice(response.ByPlan, func(i, j int) bool {
+		if response.ByPlan[i].Count != response.ByPlan[j].Count {
+			return response.ByPlan[i].Count > response.ByPlan[j].Count
+		}
+		return response.ByPlan[i].PlanID < response.ByPlan[j].PlanID
+	})
+
+	// Tenure buckets keep their natural order
+	for _, bucket := range churnTenureBuckets {
+		if tally, ok := byTenure[bucket.label]; ok {
+			response.ByTenure = append(response.ByTenure, dto.ChurnTenureBreakdown{
+				Tenure:  bucket.label,
+				Count:   tally.total,
+				Reasons: tally.counts(names),
+			})
+		}
+	}
+	if tally, ok := byTenure[churnTenureBucketOverYear]; ok {
+		response.ByTenure = append(response.ByTenure, dto.ChurnTenureBreakdown{
+			Tenure:  churnTenureBucketOverYear,
+			Count:   tally.total,
+			Reasons: tally.counts(names),
+		})
+	}
+
+	return response, nil
+}
+
+// listCancellationReasons returns the taxonomy of the tenant, falling back to the
+// defaults when the tenant has not configured any reason
+func listCancellationReasons(ctx context.Context, repo cancellationreason.Repository) ([]*cancellationreason.CancellationReason, bool, error) {
+	if repo != nil {
+		reasons, err := repo.List(ctx)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list cancellation reasons: %w", err)
+		}
+		if len(reasons) > 0 {
+			return reasons, false, nil
+		}
+	}
+
+	reasons := make([]*cancellationreason.CancellationReason, len(cancellationreason.Defaults))
+	for i := range cancellationreason.Defaults {
+		reason := cancellationreason.Defaults[i]
+		reasons[i] = &reason
+	}
+	return reasons, true, nil
+}
+
+func churnTenureBucket(start, cancelledAt time.Time) string {
+	days := int(cancelledAt.Sub(start).Hours() / 24)
+	for _, bucket := range churnTenureBuckets {
+		if days <= bucket.maxDays {
+			return bucket.label
+		}
+	}
+	return churnTenureBucketOverYear
+}
+
+type churnTally struct {
+	total    int
+	byReason map[string]int
+}
+
+func newChurnTally() *churnTally {
+	return &churnTally{byReason: make(map[string]int)}
+}
+
+func (t *churnTally) add(reason string) {
+	t.total++
+	t.byReason[reason]++
+}
+
+// counts returns the reasons of the tally, most frequent first
+func (t *churnTally) counts(names map[string]string) []dto.ChurnReasonCount {
+	counts := make([]dto.ChurnReasonCount, 0, len(t.byReason))
+	for reason, count := range t.byReason {
+		name, ok := names[reason]
+		if !ok {
+			// Reasons deleted from the taxonomy keep being reported under their code
+			name = reason
+		}
+		counts = append(counts, dto.ChurnReasonCount{
+			Reason: reason,
+			Name:   name,
+			Count:  count,
+			Percentage: decimal.NewFromInt(int64(count)).
+				Div(decimal.NewFromInt(int64(t.total))).
+				Mul(decimal.NewFromInt(100)).
+				Round(2),
+		})
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Reason < counts[j].Reason
+	})
+	return counts
+}
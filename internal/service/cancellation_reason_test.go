@@ -0,0 +1,151 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancellationReasonService_Taxonomy(t *testing.T) {
+	ctx := testutil.SetupContext()
+	reasonStore := testutil.NewInMemoryCancellationReasonStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	svc := NewCancellationReasonService(reasonStore, subscriptionStore, nil, logger.GetLogger())
+	subSvc := NewSubscriptionService(
+		subscriptionStore, testutil.NewInMemoryPlanStore(), testutil.NewInMemoryPriceStore(),
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), testutil.NewInMemoryCustomerStore(), nil, reasonStore, nil, nil, nil, nil, nil, nil, nil, nil, logger.GetLogger(),
+	)
+
+	for _, id := range []string{"sub_1", "sub_2"} {
+		require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+			ID:                 id,
+			SubscriptionStatus: types.SubscriptionStatusActive,
+			BaseModel:          types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	// Tenants start on the default taxonomy
+	resp, err := svc.ListCancellationReasons(ctx)
+	require.NoError(t, err)
+	assert.True(t, resp.IsDefault)
+	assert.NotEmpty(t, resp.Reasons)
+
+	require.NoError(t, subSvc.CancelSubscription(ctx, "sub_1", dto.CancelSubscriptionRequest{
+		Reason:  "too_expensive",
+		Comment: "Pricing doubled",
+	}))
+	sub, err := subscriptionStore.Get(ctx, "sub_1")
+	require.NoError(t, err)
+	assert.Equal(t, types.SubscriptionStatusCancelled, sub.SubscriptionStatus)
+	assert.Equal(t, "too_expensive", sub.CancellationReason)
+	assert.Equal(t, "Pricing doubled", sub.CancellationComment)
+
+	err = subSvc.CancelSubscription(ctx, "sub_2", dto.CancelSubscriptionRequest{})
+	assert.Error(t, err)
+
+	// A configured taxonomy replaces the defaults
+	_, err = svc.CreateCancellationReason(ctx, dto.CreateCancellationReasonRequest{Code: "Bad Code", Name: "Bad"})
+	assert.Error(t, err)
+	_, err = svc.CreateCancellationReason(ctx, dto.CreateCancellationReasonRequest{Code: unspecifiedCancellationReason, Name: "Unspecified"})
+	assert.Error(t, err)
+
+	reason, err := svc.CreateCancellationReason(ctx, dto.CreateCancellationReasonRequest{Code: "moved_in_house", Name: "Built it in house"})
+	require.NoError(t, err)
+
+	resp, err = svc.ListCancellationReasons(ctx)
+	require.NoError(t, err)
+	assert.False(t, resp.IsDefault)
+	require.Len(t, resp.Reasons, 1)
+	assert.Equal(t, "moved_in_house", resp.Reasons[0].Code)
+
+	err = subSvc.CancelSubscription(ctx, "sub_2", dto.CancelSubscriptionRequest{Reason: "too_expensive"})
+	assert.ErrorIs(t, err, ErrUnknownCancellationReason)
+	require.NoError(t, subSvc.CancelSubscription(ctx, "sub_2", dto.CancelSubscriptionRequest{Reason: "moved_in_house"}))
+
+	// Deleting every reason brings the defaults back
+	require.NoError(t, svc.DeleteCancellationReason(ctx, reason.ID))
+	resp, err = svc.ListCancellationReasons(ctx)
+	require.NoError(t, err)
+	assert.True(t, resp.IsDefault)
+}
+
+func TestCancellationReasonService_GetChurnReasons(t *testing.T) {
+	ctx := testutil.SetupContext()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	svc := NewCancellationReasonService(testutil.NewInMemoryCancellationReasonStore(), subscriptionStore, nil, logger.GetLogger())
+
+	end := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -1, 0)
+
+	subs := []struct {
+		id          string
+		planID      string
+		reason      string
+		tenureDays  int
+		cancelledAt time.Time
+	}{
+		{id: "sub_1", planID: "plan_basic", reason: "too_expensive", tenureDays: 10, cancelledAt: start.AddDate(0, 0, 1)},
+		{id: "sub_2", planID: "plan_basic", reason: "too_expensive", tenureDays: 45, cancelledAt: start.AddDate(0, 0, 2)},
+		{id: "sub_3", planID: "plan_basic", reason: "missing_features", tenureDays: 400, cancelledAt: start.AddDate(0, 0, 3)},
+		{id: "sub_4", planID: "plan_pro", reason: "", tenureDays: 5, cancelledAt: start.AddDate(0, 0, 4)},
+		// Outside of the window
+		{id: "sub_5", planID: "plan_pro", reason: "too_expensive", tenureDays: 5, cancelledAt: end},
+		{id: "sub_6", planID: "plan_pro", reason: "too_expensive", tenureDays: 5, cancelledAt: start.AddDate(0, 0, -1)},
+	}
+	for _, s := range subs {
+		cancelledAt := s.cancelledAt
+		require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+			ID:                 s.id,
+			PlanID:             s.planID,
+			SubscriptionStatus: types.SubscriptionStatusCancelled,
+			StartDate:          cancelledAt.AddDate(0, 0, -s.tenureDays),
+			CancelledAt:        &cancelledAt,
+			CancellationReason: s.reason,
+			BaseModel:          types.GetDefaultBaseModel(ctx),
+		}))
+	}
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_active",
+		PlanID:             "plan_pro",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	_, err := svc.GetChurnReasons(ctx, dto.ChurnReasonsRequest{StartTime: end, EndTime: start})
+	assert.Error(t, err)
+
+	resp, err := svc.GetChurnReasons(ctx, dto.ChurnReasonsRequest{StartTime: start, EndTime: end})
+	require.NoError(t, err)
+	assert.Equal(t, 4, resp.TotalCancellations)
+
+	require.Len(t, resp.ByReason, 3)
+	assert.Equal(t, "too_expensive", resp.ByReason[0].Reason)
+	assert.Equal(t, "Too expensive", resp.ByReason[0].Name)
+	assert.Equal(t, 2, resp.ByReason[0].Count)
+	assert.True(t, decimal.NewFromInt(50).Equal(resp.ByReason[0].Percentage))
+	assert.Equal(t, "missing_features", resp.ByReason[1].Reason)
+	assert.Equal(t, unspecifiedCancellationReason, resp.ByReason[2].Reason)
+
+	require.Len(t, resp.ByPlan, 2)
+	assert.Equal(t, "plan_basic", resp.ByPlan[0].PlanID)
+	assert.Equal(t, 3, resp.ByPlan[0].Count)
+	assert.True(t, decimal.NewFromFloat(66.67).Equal(resp.ByPlan[0].Reasons[0].Percentage))
+	assert.Equal(t, "plan_pro", resp.ByPlan[1].PlanID)
+	assert.Equal(t, 1, resp.ByPlan[1].Count)
+
+	tenures := make([]string, len(resp.ByTenure))
+	for i, tenure := range resp.ByTenure {
+		tenures[i] = tenure.Tenure
+	}
+	assert.Equal(t, []string{"0-30d", "31-90d", "365d+"}, tenures)
+	assert.Equal(t, 2, resp.ByTenure[0].Count)
+}
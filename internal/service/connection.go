@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type ConnectionService interface {
+	CreateConnection(ctx context.Context, req dto.CreateConnectionRequest) (*dto.ConnectionResponse, error)
+	GetConnection(ctx context.Context, id string) (*dto.ConnectionResponse, error)
+	GetConnections(ctx context.Context, filter types.Filter) (*dto.ListConnectionsResponse, error)
+
+	// EnqueueSync schedules an outbound call on the sync queue of the connection.
+	// Every integration workflow must go through it instead of calling the provider directly
+	EnqueueSync(ctx context.Context, connectionID string, task *syncqueue.Task) error
+	GetSyncQueueStatus(ctx context.Context, connectionID string) (*dto.SyncQueueStatusResponse, error)
+}
+
+type connectionService struct {
+	repo           connection.Repository
+	syncQueue      *syncqueue.Manager
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
+}
+
+func NewConnectionService(repo connection.Repository, syncQueue *syncqueue.Manager, auditPublisher audit.Publisher, logger *logger.Logger) ConnectionService {
+	return &connectionService{
+		repo:           repo,
+		syncQueue:      syncQueue,
+		auditPublisher: auditPublisher,
+		logger:         logger,
+	}
+}
+
+func (s *connectionService) CreateConnection(ctx context.Context, req dto.CreateConnectionRequest) (*dto.ConnectionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	conn := req.ToConnection(ctx)
+	if err := s.repo.Create(ctx, conn); err != nil {
+		return nil, fmt.Errorf("failed to create connection: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeConnection, conn.ID, types.AuditActionCreate, nil, conn)
+
+	return &dto.ConnectionResponse{Connection: conn}, nil
+}
+
+func (s *connectionService) GetConnection(ctx context.Context, id string) (*dto.ConnectionResponse, error) {
+	conn, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	return &dto.ConnectionResponse{Connection: conn}, nil
+}
+
+func (s *connectionService) GetConnections(ctx context.Context, filter types.Filter) (*dto.ListConnectionsResponse, error) {
+	conns, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	response := &dto.ListConnectionsResponse{
+		Connections: make([]dto.ConnectionResponse, len(conns)),
+		Total:       len(conns),
+		Offset:      filter.Offset,
+		Limit:       filter.Limit,
+	}
+
+	for i, conn := range conns {
+		response.Connections[i] = dto.ConnectionResponse{Connection: conn}
+	}
+
+	return response, nil
+}
+
+func (s *connectionService) EnqueueSync(ctx context.Context, connectionID string, task *syncqueue.Task) error {
+	conn, err := s.repo.Get(ctx, connectionID)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	s.syncQueue.Enqueue(ctx, conn.ID, conn.RateLimitPerSecond, task)
+	return nil
+}
+
+func (s *connectionService) GetSyncQueueStatus(ctx context.Context, connectionID string) (*dto.SyncQueueStatusResponse, error) {
+	conn, err := s.repo.Get(ctx, connectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	status := s.syncQueue.Status(conn.ID)
+	if conn.RateLimitPerSecond > 0 {
+		status.RatePerSecond = conn.RateLimitPerSecond
+	}
+
+	return &dto.SyncQueueStatusResponse{Status: status}, nil
+}
+
+// runOnSyncQueue runs fn on the sync queue of the connection and waits for its
+// result, for the calls to a provider made while a request waits
+func runOnSyncQueue[T any](
+	ctx context.Context,
+	connectionService ConnectionService,
+	connectionID string,
+	entityType types.SyncEntityType,
+	entityID string,
+	fn func(ctx context.Context) (T, error),
+) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+
+	var zero T
+	err := connectionService.EnqueueSync(ctx, connectionID, &syncqueue.Task{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Run: func(ctx context.Context) error {
+			value, err := fn(ctx)
+			if err != nil {
+				return err
+			}
+			done <- result{value: value}
+			return nil
+		},
+		OnFailure: func(err error) {
+			done <- result{err: err}
+		},
+	})
+	if err != nil {
+		return zero, err
+	}
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionService_SyncQueue(t *testing.T) {
+	ctx := testutil.SetupContext()
+	manager := syncqueue.NewManager(&config.Configuration{
+		Integration: config.IntegrationConfig{SyncRatePerSecond: 100},
+	}, logger.GetLogger())
+	defer manager.Stop()
+
+	svc := NewConnectionService(testutil.NewInMemoryConnectionStore(), manager, nil, logger.GetLogger())
+
+	conn, err := svc.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:               "HubSpot",
+		Provider:           types.IntegrationProviderHubSpot,
+		RateLimitPerSecond: 10,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.CreateConnection(ctx, dto.CreateConnectionRequest{Name: "Unknown", Provider: "salesforce"})
+	assert.Error(t, err)
+
+	synced := make(chan string, 1)
+	require.NoError(t, svc.EnqueueSync(ctx, conn.ID, &syncqueue.Task{
+		EntityType: types.SyncEntityTypeCRMNote,
+		EntityID:   "note_1",
+		Run: func(ctx context.Context) error {
+			// Tasks keep the tenant of the request that enqueued them
+			synced <- types.GetTenantID(ctx)
+			return nil
+		},
+	}))
+
+	select {
+	case tenantID := <-synced:
+		assert.Equal(t, types.DefaultTenantID, tenantID)
+	case <-time.After(time.Second):
+		t.Fatal("sync task did not run")
+	}
+
+	require.Eventually(t, func() bool {
+		status, err := svc.GetSyncQueueStatus(ctx, conn.ID)
+		return err == nil && status.Processed == 1
+	}, time.Second, time.Millisecond)
+
+	status, err := svc.GetSyncQueueStatus(ctx, conn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, float64(10), status.RatePerSecond)
+
+	assert.Error(t, svc.EnqueueSync(ctx, "missing", &syncqueue.Task{}))
+}
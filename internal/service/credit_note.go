@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// CreditNoteService manages the lifecycle of credit notes, the CREDIT invoices
+// issued when a billing run ends with a negative total
+type CreditNoteService interface {
+	// AllocateCreditNote draws an amount from a finalized credit note to reduce the
+	// amount due of another invoice or to credit a wallet of the customer
+	AllocateCreditNote(ctx context.Context, id string, req dto.AllocateCreditNoteRequest) (*dto.CreditAllocationResponse, error)
+	ListCreditAllocations(ctx context.Context, id string) (*dto.ListCreditAllocationsResponse, error)
+	// VoidCreditNote voids a credit note nothing was allocated from
+	VoidCreditNote(ctx context.Context, id string) (*dto.InvoiceResponse, error)
+}
+
+// creditNoteReferenceType is the reference type of the wallet transactions
+// funded by a credit note
+const creditNoteReferenceType = "credit_note"
+
+type creditNoteService struct {
+	invoiceRepo    invoice.Repository
+	walletRepo     wallet.Repository
+	db             postgres.TxManager
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
+
+	// clock is the time credit notes change at, the wall clock when nil
+	clock *clock.Clock
+}
+
+func NewCreditNoteService(
+	invoiceRepo invoice.Repository,
+	walletRepo wallet.Repository,
+	db postgres.TxManager,
+	auditPublisher audit.Publisher,
+	clock *clock.Clock,
+	logger *logger.Logger,
+) CreditNoteService {
+	return &creditNoteService{
+		invoiceRepo:    invoiceRepo,
+		walletRepo:     walletRepo,
+		db:             db,
+		auditPublisher: auditPublisher,
+		clock:          clock,
+		logger:         logger,
+	}
+}
+
+func (s *creditNoteService) AllocateCreditNote(ctx context.Context, id string, req dto.AllocateCreditNoteRequest) (*dto.CreditAllocationResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	var allocation *invoice.CreditAllocation
+
+	// The credit note is locked so that concurrent allocations can not draw more
+	// than its credit
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		creditNote, err := s.invoiceRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get credit note: %w", err)
+		}
+
+		if creditNote.InvoiceType != types.InvoiceTypeCredit {
+			return fmt.Errorf("invoice is not a credit note")
+		}
+		if creditNote.InvoiceStatus != types.InvoiceStatusFinalized {
+			return fmt.Errorf("credit note must be finalized before being allocated")
+		}
+
+		remaining, err := s.remainingCredit(ctx, creditNote)
+		if err != nil {
+			return err
+		}
+		if !remaining.IsPositive() {
+			return fmt.Errorf("credit note has no credit left to allocate")
+		}
+
+		amount := remaining
+		if req.Amount != nil {
+			if req.Amount.GreaterThan(remaining) {
+				return fmt.Errorf("invalid request: amount exceeds the remaining credit of %s", remaining.String())
+			}
+			amount = *req.Amount
+		}
+
+		switch req.TargetType {
+		case types.CreditAllocationTargetInvoice:
+			amount, err = s.allocateToInvoice(ctx, creditNote, req.TargetID, amount, req.Amount != nil)
+		case types.CreditAllocationTargetWallet:
+			err = s.allocateToWallet(ctx, creditNote, req.TargetID, amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		if !amount.IsPositive() {
+			return fmt.Errorf("invalid request: invoice has no amount due")
+		}
+
+		allocation = &invoice.CreditAllocation{
+			ID:              types.GenerateUUID(),
+			CreditInvoiceID: creditNote.ID,
+			CustomerID:      creditNote.CustomerID,
+			TargetType:      req.TargetType,
+			TargetID:        req.TargetID,
+			Amount:          amount,
+			Currency:        creditNote.Currency,
+			BaseModel:       types.GetDefaultBaseModel(ctx),
+		}
+
+		if err := s.invoiceRepo.CreateCreditAllocation(ctx, allocation); err != nil {
+			return fmt.Errorf("failed to allocate credit note: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCreditAllocation, allocation.ID, types.AuditActionCreate, nil, allocation)
+
+	s.logger.Debugw("allocated credit note",
+		"credit_invoice_id", allocation.CreditInvoiceID,
+		"target_type", allocation.TargetType,
+		"target_id", allocation.TargetID,
+		"amount", allocation.Amount,
+	)
+
+	return &dto.CreditAllocationResponse{CreditAllocation: allocation}, nil
+}
+
+// allocateToInvoice reduces the amount due of the target invoice and returns the
+// amount allocated. Without an explicit amount the allocation is capped by the
+// amount due
+func (s *creditNoteService) allocateToInvoice(ctx context.Context, creditNote *invoice.Invoice, invoiceID string, amount decimal.Decimal, explicit bool) (decimal.Decimal, error) {
+	target, err := s.invoiceRepo.GetForUpdate(ctx, invoiceID)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	if target.CustomerID != creditNote.CustomerID || target.Currency != creditNote.Currency {
+		return decimal.Zero, fmt.Errorf("invalid request: invoice must belong to the customer of the credit note and share its currency")
+	}
+	if target.InvoiceType == types.InvoiceTypeCredit || target.InvoiceStatus == types.InvoiceStatusVoided {
+		return decimal.Zero, fmt.Errorf("invalid request: credit can not be applied to a voided or credit invoice")
+	}
+
+	if amount.GreaterThan(target.AmountRemaining) {
+		if explicit {
+			return decimal.Zero, fmt.Errorf("invalid request: amount exceeds the amount remaining of %s", target.AmountRemaining.String())
+		}
+		amount = target.AmountRemaining
+	}
+
+	if !amount.IsPositive() {
+		return amount, nil
+	}
+
+	target.AmountDue = target.AmountDue.Sub(amount)
+	target.RecalculateAmountRemaining()
+	target.UpdatedAt = s.clock.Now()
+	target.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.invoiceRepo.Update(ctx, target); err != nil {
+		return decimal.Zero, fmt.Errorf("failed to apply credit to invoice: %w", err)
+	}
+
+	return amount, nil
+}
+
+func (s *creditNoteService) allocateToWallet(ctx context.Context, creditNote *invoice.Invoice, walletID string, amount decimal.Decimal) error {
+	w, err := s.walletRepo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	if w.CustomerID != creditNote.CustomerID || w.Currency != creditNote.Currency {
+		return fmt.Errorf("invalid request: wallet must belong to the customer of the credit note and share its currency")
+	}
+
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	err = s.walletRepo.CreditWallet(ctx, &wallet.WalletOperation{
+		WalletID:      w.ID,
+		Type:          types.TransactionTypeCredit,
+		Amount:        amount,
+		ReferenceType: creditNoteReferenceType,
+		ReferenceID:   creditNote.ID,
+		Description:   "Credit note allocation",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to credit wallet: %w", err)
+	}
+
+	return nil
+}
+
+func (s *creditNoteService) remainingCredit(ctx context.Context, creditNote *invoice.Invoice) (decimal.Decimal, error) {
+	allocations, err := s.invoiceRepo.ListCreditAllocations(ctx, creditNote.ID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	remaining := creditNote.Credit()
+	for _, allocation := range allocations {
+		remaining = remaining.Sub(allocation.Amount)
+	}
+	return remaining, nil
+}
+
+func (s *creditNoteService) ListCreditAllocations(ctx context.Context, id string) (*dto.ListCreditAllocationsResponse, error) {
+	creditNote, err := s.invoiceRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit note: %w", err)
+	}
+
+	if creditNote.InvoiceType != types.InvoiceTypeCredit {
+		return nil, fmt.Errorf("invoice is not a credit note")
+	}
+
+	allocations, err := s.invoiceRepo.ListCreditAllocations(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ListCreditAllocationsResponse{
+		Allocations: make([]dto.CreditAllocationResponse, len(allocations)),
+		Remaining:   creditNote.Credit(),
+	}
+
+	for i, allocation := range allocations {
+		response.Allocations[i] = dto.CreditAllocationResponse{CreditAllocation: allocation}
+		response.Remaining = response.Remaining.Sub(allocation.Amount)
+	}
+
+	if creditNote.InvoiceStatus == types.InvoiceStatusVoided {
+		response.Remaining = decimal.Zero
+	}
+
+	return response, nil
+}
+
+func (s *creditNoteService) VoidCreditNote(ctx context.Context, id string) (*dto.InvoiceResponse, error) {
+	var creditNote *invoice.Invoice
+	var before invoice.Invoice
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		creditNote, err = s.invoiceRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get credit note: %w", err)
+		}
+
+		if creditNote.InvoiceType != types.InvoiceTypeCredit {
+			return fmt.Errorf("invoice is not a credit note")
+		}
+		if creditNote.InvoiceStatus == types.InvoiceStatusVoided {
+			return fmt.Errorf("credit note is already voided")
+		}
+
+		// Allocated amounts already reduced other invoices or sit in wallets, they
+		// can not be taken back by voiding
+		allocations, err := s.invoiceRepo.ListCreditAllocations(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(allocations) > 0 {
+			return fmt.Errorf("credit note has allocations and can not be voided")
+		}
+
+		before = *creditNote
+		creditNote.InvoiceStatus = types.InvoiceStatusVoided
+		creditNote.UpdatedAt = s.clock.Now()
+		creditNote.UpdatedBy = types.GetUserID(ctx)
+
+		if err := s.invoiceRepo.Update(ctx, creditNote); err != nil {
+			return fmt.Errorf("failed to void credit note: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, creditNote.ID, types.AuditActionUpdate, &before, creditNote)
+	return &dto.InvoiceResponse{Invoice: creditNote}, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreditNoteService(t *testing.T) {
+	ctx := testutil.SetupContext()
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	walletStore := testutil.NewInMemoryWalletStore()
+	svc := NewCreditNoteService(invoiceStore, walletStore, testutil.NewInMemoryTxManager(), nil, nil, logger.GetLogger())
+
+	newInvoice := func(id string, invoiceType types.InvoiceType, status types.InvoiceStatus, total int64) *invoice.Invoice {
+		inv := &invoice.Invoice{
+			ID:            id,
+			CustomerID:    "cust_123",
+			InvoiceType:   invoiceType,
+			InvoiceStatus: status,
+			Currency:      "usd",
+			Total:         decimal.NewFromInt(total),
+			AmountDue:     decimal.Max(decimal.NewFromInt(total), decimal.Zero),
+			BaseModel:     types.GetDefaultBaseModel(ctx),
+		}
+		inv.RecalculateAmountRemaining()
+		require.NoError(t, invoiceStore.Create(ctx, inv))
+		return inv
+	}
+
+	creditNote := newInvoice("inv_credit", types.InvoiceTypeCredit, types.InvoiceStatusFinalized, -30)
+	target := newInvoice("inv_target", types.InvoiceTypeSubscription, types.InvoiceStatusFinalized, 20)
+	draft := newInvoice("inv_credit_draft", types.InvoiceTypeCredit, types.InvoiceStatusDraft, -5)
+
+	w := &wallet.Wallet{
+		ID:           "wallet_123",
+		CustomerID:   "cust_123",
+		Currency:     "usd",
+		WalletStatus: types.WalletStatusActive,
+		BaseModel:    types.GetDefaultBaseModel(ctx),
+	}
+	require.NoError(t, walletStore.CreateWallet(ctx, w))
+
+	t.Run("draft credit notes can not be allocated", func(t *testing.T) {
+		_, err := svc.AllocateCreditNote(ctx, draft.ID, dto.AllocateCreditNoteRequest{
+			TargetType: types.CreditAllocationTargetInvoice,
+			TargetID:   target.ID,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("amount above the remaining credit", func(t *testing.T) {
+		amount := decimal.NewFromInt(31)
+		_, err := svc.AllocateCreditNote(ctx, creditNote.ID, dto.AllocateCreditNoteRequest{
+			TargetType: types.CreditAllocationTargetWallet,
+			TargetID:   w.ID,
+			Amount:     &amount,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("invoice allocation is capped by the amount due", func(t *testing.T) {
+		resp, err := svc.AllocateCreditNote(ctx, creditNote.ID, dto.AllocateCreditNoteRequest{
+			TargetType: types.CreditAllocationTargetInvoice,
+			TargetID:   target.ID,
+		})
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(20).Equal(resp.Amount))
+
+		updated, err := invoiceStore.Get(ctx, target.ID)
+		require.NoError(t, err)
+		assert.True(t, decimal.Zero.Equal(updated.AmountDue))
+	})
+
+	t.Run("wallet allocation takes the remaining credit", func(t *testing.T) {
+		resp, err := svc.AllocateCreditNote(ctx, creditNote.ID, dto.AllocateCreditNoteRequest{
+			TargetType: types.CreditAllocationTargetWallet,
+			TargetID:   w.ID,
+		})
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(10).Equal(resp.Amount))
+
+		credited, err := walletStore.GetWalletByID(ctx, w.ID)
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(10).Equal(credited.Balance))
+	})
+
+	t.Run("fully allocated credit note", func(t *testing.T) {
+		_, err := svc.AllocateCreditNote(ctx, creditNote.ID, dto.AllocateCreditNoteRequest{
+			TargetType: types.CreditAllocationTargetWallet,
+			TargetID:   w.ID,
+		})
+		assert.Error(t, err)
+
+		list, err := svc.ListCreditAllocations(ctx, creditNote.ID)
+		require.NoError(t, err)
+		require.Len(t, list.Allocations, 2)
+		assert.Equal(t, types.CreditAllocationTargetInvoice, list.Allocations[0].TargetType)
+		assert.Equal(t, types.CreditAllocationTargetWallet, list.Allocations[1].TargetType)
+		assert.True(t, decimal.Zero.Equal(list.Remaining))
+
+		// Allocated credit notes can not be voided
+		_, err = svc.VoidCreditNote(ctx, creditNote.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("void", func(t *testing.T) {
+		unallocated := newInvoice("inv_credit_unallocated", types.InvoiceTypeCredit, types.InvoiceStatusFinalized, -8)
+
+		resp, err := svc.VoidCreditNote(ctx, unallocated.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.InvoiceStatusVoided, resp.InvoiceStatus)
+
+		_, err = svc.AllocateCreditNote(ctx, unallocated.ID, dto.AllocateCreditNoteRequest{
+			TargetType: types.CreditAllocationTargetWallet,
+			TargetID:   w.ID,
+		})
+		assert.Error(t, err)
+
+		// Only credit invoices are credit notes
+		_, err = svc.VoidCreditNote(ctx, target.ID)
+		assert.Error(t, err)
+	})
+}
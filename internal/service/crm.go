@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/crm"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// crmInvoicePageSize is the number of invoices read at a time to total the
+// invoices of a customer
+const crmInvoicePageSize = 500
+
+// CRMSyncService pushes customers, subscriptions and invoice totals to the
+// Salesforce connections that sync them. Each sync is a task on the sync queue
+// of the connection that reads the record when it runs and upserts it on its
+// Flexprice ID, so retries push the latest state and never duplicate it
+type CRMSyncService interface {
+	// SyncCustomer queues the upsert of the Account of the customer
+	SyncCustomer(ctx context.Context, customerID string) error
+	// SyncSubscription queues the upsert of the Opportunity or Contract of the
+	// subscription along with the Account of its customer
+	SyncSubscription(ctx context.Context, subscriptionID string) error
+	// SyncInvoiceTotals queues the update of the invoice totals, in the
+	// currency of the invoice, on the Account of its customer
+	SyncInvoiceTotals(ctx context.Context, inv *invoice.Invoice) error
+}
+
+type crmSyncService struct {
+	connectionRepo    connection.Repository
+	customerRepo      customer.Repository
+	subscriptionRepo  subscription.Repository
+	planRepo          plan.Repository
+	invoiceRepo       invoice.Repository
+	connectionService ConnectionService
+	logger            *logger.Logger
+
+	// newClient is replaced in tests
+	newClient func(conn *connection.Connection) (crm.Client, error)
+}
+
+func NewCRMSyncService(
+	connectionRepo connection.Repository,
+	customerRepo customer.Repository,
+	subscriptionRepo subscription.Repository,
+	planRepo plan.Repository,
+	invoiceRepo invoice.Repository,
+	connectionService ConnectionService,
+	logger *logger.Logger,
+) CRMSyncService {
+	return &crmSyncService{
+		connectionRepo:    connectionRepo,
+		customerRepo:      customerRepo,
+		subscriptionRepo:  subscriptionRepo,
+		planRepo:          planRepo,
+		invoiceRepo:       invoiceRepo,
+		connectionService: connectionService,
+		logger:            logger,
+		newClient:         crm.NewClient,
+	}
+}
+
+func (s *crmSyncService) SyncCustomer(ctx context.Context, customerID string) error {
+	return s.enqueue(ctx, types.SyncEntityTypeCustomer, customerID,
+		func(settings *connection.CRMSettings) bool { return settings.SyncCustomers },
+		func(ctx context.Context, settings *connection.CRMSettings, client crm.Client) error {
+			_, err := s.upsertAccount(ctx, client, customerID, nil)
+			return err
+		},
+	)
+}
+
+func (s *crmSyncService) SyncSubscription(ctx context.Context, subscriptionID string) error {
+	return s.enqueue(ctx, types.SyncEntityTypeSubscription, subscriptionID,
+		func(settings *connection.CRMSettings) bool { return settings.SyncSubscriptions },
+		func(ctx context.Context, settings *connection.CRMSettings, client crm.Client) error {
+			sub, err := s.subscriptionRepo.Get(ctx, subscriptionID)
+			if err != nil {
+				return fmt.Errorf("failed to get subscription: %w", err)
+			}
+
+			p, err := s.planRepo.Get(ctx, sub.PlanID)
+			if err != nil {
+				return fmt.Errorf("failed to get plan: %w", err)
+			}
+
+			// The Account is upserted first so the subscription can be linked to
+			// it whatever the order the tasks run in
+			if _, err := s.upsertAccount(ctx, client, sub.CustomerID, nil); err != nil {
+				return err
+			}
+
+			object := crm.SubscriptionObject(settings)
+			_, err = client.Upsert(ctx, object, sub.ID, crm.SubscriptionFields(settings, sub, p.Name))
+			return err
+		},
+	)
+}
+
+func (s *crmSyncService) SyncInvoiceTotals(ctx context.Context, inv *invoice.Invoice) error {
+	// The totals are Account fields and are synced with the priority of customers
+	return s.enqueue(ctx, types.SyncEntityTypeCustomer, inv.CustomerID,
+		func(settings *connection.CRMSettings) bool { return settings.SyncInvoiceTotals },
+		func(ctx context.Context, settings *connection.CRMSettings, client crm.Client) error {
+			totals, err := s.invoiceTotals(ctx, inv.CustomerID, inv.Currency)
+			if err != nil {
+				return err
+			}
+
+			_, err = s.upsertAccount(ctx, client, inv.CustomerID, crm.InvoiceTotalsFields(settings, totals))
+			return err
+		},
+	)
+}
+
+// enqueue queues run on every Salesforce connection for which enabled is true
+func (s *crmSyncService) enqueue(
+	ctx context.Context,
+	entityType types.SyncEntityType,
+	entityID string,
+	enabled func(settings *connection.CRMSettings) bool,
+	run func(ctx context.Context, settings *connection.CRMSettings, client crm.Client) error,
+) error {
+	conns, err := s.connectionRepo.ListByProviders(ctx, []types.IntegrationProvider{types.IntegrationProviderSalesforce})
+	if err != nil {
+		return fmt.Errorf("failed to list crm connections: %w", err)
+	}
+
+	for _, conn := range conns {
+		if conn.CRMSettings == nil || !enabled(conn.CRMSettings) {
+			continue
+		}
+
+		connectionID := conn.ID
+		err := s.connectionService.EnqueueSync(ctx, connectionID, &syncqueue.Task{
+			EntityType: entityType,
+			EntityID:   entityID,
+			Run: func(ctx context.Context) error {
+				// The connection is read again so that retries use its latest
+				// settings and credentials
+				conn, err := s.connectionRepo.Get(ctx, connectionID)
+				if err != nil {
+					return fmt.Errorf("failed to get connection: %w", err)
+				}
+
+				client, err := s.newClient(conn)
+				if err != nil {
+					return err
+				}
+
+				if err := run(ctx, conn.CRMSettings, client); err != nil {
+					s.logger.Warnw("crm sync failed",
+						"connection_id", connectionID,
+						"entity_type", entityType,
+						"entity_id", entityID,
+						"error", err,
+					)
+					return err
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upsertAccount upserts the Account of the customer with the extra fields
+func (s *crmSyncService) upsertAccount(ctx context.Context, client crm.Client, customerID string, extra map[string]interface{}) (string, error) {
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	fields := crm.AccountFields(cust)
+	for k, v := range extra {
+		fields[k] = v
+	}
+
+	return client.Upsert(ctx, crm.ObjectAccount, cust.ID, fields)
+}
+
+func (s *crmSyncService) invoiceTotals(ctx context.Context, customerID, currency string) (*crm.InvoiceTotals, error) {
+	totals := &crm.InvoiceTotals{
+		Currency:      currency,
+		TotalInvoiced: decimal.Zero,
+		AmountDue:     decimal.Zero,
+	}
+
+	filter := &types.InvoiceFilter{
+		Filter:        types.Filter{Limit: crmInvoicePageSize},
+		CustomerID:    customerID,
+		InvoiceStatus: types.InvoiceStatusFinalized,
+	}
+	for {
+		invoices, err := s.invoiceRepo.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list invoices: %w", err)
+		}
+
+		for _, inv := range invoices {
+			if inv.Currency != currency {
+				continue
+			}
+			totals.TotalInvoiced = totals.TotalInvoiced.Add(inv.Total)
+			totals.AmountDue = totals.AmountDue.Add(inv.AmountDue)
+			if inv.FinalizedAt != nil && (totals.LastInvoiceDate == nil || inv.FinalizedAt.After(*totals.LastInvoiceDate)) {
+				totals.LastInvoiceDate = inv.FinalizedAt
+			}
+		}
+
+		if len(invoices) < filter.Limit {
+			return totals, nil
+		}
+		filter.Offset += filter.Limit
+	}
+}
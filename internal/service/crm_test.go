@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/crm"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCRMClient records the upserted records as object/id
+type fakeCRMClient struct {
+	mu      sync.Mutex
+	records []string
+	fields  map[string]map[string]interface{}
+}
+
+func (c *fakeCRMClient) Upsert(ctx context.Context, object, flexpriceID string, fields map[string]interface{}) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := object + "/" + flexpriceID
+	c.records = append(c.records, key)
+	c.fields[key] = fields
+	return "sf_" + flexpriceID, nil
+}
+
+func (c *fakeCRMClient) upserted() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.records...)
+}
+
+func TestCRMSyncService(t *testing.T) {
+	ctx := testutil.SetupContext()
+	manager := syncqueue.NewManager(&config.Configuration{
+		Integration: config.IntegrationConfig{SyncRatePerSecond: 100},
+	}, logger.GetLogger())
+	defer manager.Stop()
+
+	connectionStore := testutil.NewInMemoryConnectionStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	planStore := testutil.NewInMemoryPlanStore()
+	connectionService := NewConnectionService(connectionStore, manager, nil, logger.GetLogger())
+
+	client := &fakeCRMClient{fields: map[string]map[string]interface{}{}}
+	svc := NewCRMSyncService(connectionStore, customerStore, subscriptionStore, planStore,
+		testutil.NewInMemoryInvoiceStore(), connectionService, logger.GetLogger()).(*crmSyncService)
+	svc.newClient = func(*connection.Connection) (crm.Client, error) { return client, nil }
+
+	_, err := connectionService.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:     "Salesforce",
+		Provider: types.IntegrationProviderSalesforce,
+		CRMSettings: &connection.CRMSettings{
+			InstanceURL:       "https://acme.my.salesforce.com",
+			SyncCustomers:     true,
+			SyncSubscriptions: true,
+		},
+		Credentials: "access-token",
+	})
+	require.NoError(t, err)
+
+	customerService := NewCustomerService(customerStore, nil, svc, nil, logger.GetLogger())
+	cust, err := customerService.CreateCustomer(ctx, dto.CreateCustomerRequest{ExternalID: "acme", Name: "Acme"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(client.upserted()) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"Account/" + cust.ID}, client.upserted())
+
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{ID: "plan_pro", Name: "Pro", BaseModel: types.GetDefaultBaseModel(ctx)}))
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_1",
+		CustomerID:         cust.ID,
+		PlanID:             "plan_pro",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, svc.SyncSubscription(ctx, "sub_1"))
+
+	// The Account is upserted before the Opportunity it is linked to
+	require.Eventually(t, func() bool {
+		return len(client.upserted()) == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"Account/" + cust.ID, "Account/" + cust.ID, "Opportunity/sub_1"}, client.upserted()[:3])
+	assert.Equal(t, "Pro", client.fields["Opportunity/sub_1"]["Name"])
+	assert.Equal(t, "Closed Won", client.fields["Opportunity/sub_1"]["StageName"])
+
+	// Invoice totals are not synced by this connection
+	require.NoError(t, svc.SyncInvoiceTotals(ctx, &invoice.Invoice{ID: "inv_1", CustomerID: cust.ID, Currency: "USD"}))
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, client.upserted(), 3)
+}
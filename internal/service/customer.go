@@ -6,7 +6,10 @@ import (
 	"time"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
 	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/types"
 )
 
@@ -16,14 +19,27 @@ type CustomerService interface {
 	GetCustomers(ctx context.Context, filter types.Filter) (*dto.ListCustomersResponse, error)
 	UpdateCustomer(ctx context.Context, id string, req dto.UpdateCustomerRequest) (*dto.CustomerResponse, error)
 	DeleteCustomer(ctx context.Context, id string) error
+	// RestoreCustomer publishes a deleted customer again, unless another
+	// published customer took its external id
+	RestoreCustomer(ctx context.Context, id string) (*dto.CustomerResponse, error)
+
+	// PauseCollections holds the bank debits and gateway collections of the
+	// invoices of a customer until a date
+	PauseCollections(ctx context.Context, id string, req dto.PauseCollectionsRequest) (*dto.CustomerResponse, error)
+	// ResumeCollections lifts the pause of the collections of a customer
+	ResumeCollections(ctx context.Context, id string) (*dto.CustomerResponse, error)
 }
 
 type customerService struct {
-	repo customer.Repository
+	repo            customer.Repository
+	legalEntityRepo legalentity.Repository
+	crmSyncService  CRMSyncService
+	auditPublisher  audit.Publisher
+	logger          *logger.Logger
 }
 
-func NewCustomerService(repo customer.Repository) CustomerService {
-	return &customerService{repo: repo}
+func NewCustomerService(repo customer.Repository, legalEntityRepo legalentity.Repository, crmSyncService CRMSyncService, auditPublisher audit.Publisher, logger *logger.Logger) CustomerService {
+	return &customerService{repo: repo, legalEntityRepo: legalEntityRepo, crmSyncService: crmSyncService, auditPublisher: auditPublisher, logger: logger}
 }
 
 func (s *customerService) CreateCustomer(ctx context.Context, req dto.CreateCustomerRequest) (*dto.CustomerResponse, error) {
@@ -31,12 +47,18 @@ func (s *customerService) CreateCustomer(ctx context.Context, req dto.CreateCust
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
+	if err := checkLegalEntity(ctx, s.legalEntityRepo, req.LegalEntityID); err != nil {
+		return nil, err
+	}
+
 	customer := req.ToCustomer(ctx)
 
 	if err := s.repo.Create(ctx, customer); err != nil {
 		return nil, fmt.Errorf("failed to create customer: %w", err)
 	}
 
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCustomer, customer.ID, types.AuditActionCreate, nil, customer)
+	s.syncCRM(ctx, customer.ID)
 	return &dto.CustomerResponse{Customer: customer}, nil
 }
 
@@ -80,9 +102,14 @@ func (s *customerService) UpdateCustomer(ctx context.Context, id string, req dto
 		return nil, fmt.Errorf("failed to get customer: %w", err)
 	}
 
-	customer.Name = req.Name
-	customer.ExternalID = req.ExternalID
-	customer.Email = req.Email
+	if req.LegalEntityID != customer.LegalEntityID {
+		if err := checkLegalEntity(ctx, s.legalEntityRepo, req.LegalEntityID); err != nil {
+			return nil, err
+		}
+	}
+
+	before := *customer
+	req.ApplyTo(customer)
 	customer.UpdatedAt = time.Now().UTC()
 	customer.UpdatedBy = types.GetUserID(ctx)
 
@@ -90,12 +117,67 @@ func (s *customerService) UpdateCustomer(ctx context.Context, id string, req dto
 		return nil, fmt.Errorf("failed to update customer: %w", err)
 	}
 
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCustomer, customer.ID, types.AuditActionUpdate, &before, customer)
+	s.syncCRM(ctx, customer.ID)
 	return &dto.CustomerResponse{Customer: customer}, nil
 }
 
 func (s *customerService) DeleteCustomer(ctx context.Context, id string) error {
+	customer, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete customer: %w", err)
 	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCustomer, id, types.AuditActionDelete, customer, nil)
 	return nil
 }
+
+func (s *customerService) RestoreCustomer(ctx context.Context, id string) (*dto.CustomerResponse, error) {
+	customer, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer.Status != types.StatusDeleted {
+		return nil, ErrNotDeleted
+	}
+
+	// The events of an external id are billed to a single customer
+	if customer.ExternalID != "" {
+		existing, err := s.repo.ListByExternalIDs(ctx, []string{customer.ExternalID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list customers: %w", err)
+		}
+		if len(existing) > 0 {
+			return nil, fmt.Errorf("%w: customer %s has the external id %s", ErrRestoreConflict, existing[0].ID, customer.ExternalID)
+		}
+	}
+
+	before := *customer
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to restore customer: %w", err)
+	}
+
+	restored, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCustomer, id, types.AuditActionRestore, &before, restored)
+	s.syncCRM(ctx, id)
+	return &dto.CustomerResponse{Customer: restored}, nil
+}
+
+// syncCRM queues the customer for the CRM connections. A failure to queue does
+// not fail the change, the customer is synced again on its next change
+func (s *customerService) syncCRM(ctx context.Context, customerID string) {
+	if s.crmSyncService == nil {
+		return
+	}
+	if err := s.crmSyncService.SyncCustomer(ctx, customerID); err != nil {
+		s.logger.Errorw("failed to queue crm sync", "customer_id", customerID, "error", err)
+	}
+}
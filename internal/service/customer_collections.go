@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// ErrCollectionsPaused is returned when the payment of an invoice is collected
+// while the collections of its customer are paused
+var ErrCollectionsPaused = errors.New("collections of the customer are paused")
+
+// checkCollectionsActive returns ErrCollectionsPaused when the collections of
+// the customer are paused at now
+func checkCollectionsActive(cust *customer.Customer, now time.Time) error {
+	if cust.CollectionsPaused(now) {
+		return ErrCollectionsPaused
+	}
+	return nil
+}
+
+// PauseCollections pauses the collections of the customer until the date of
+// the request. Their scheduled bank debits are held and no payment can be
+// collected through a gateway until then, payments can still be recorded
+func (s *customerService) PauseCollections(ctx context.Context, id string, req dto.PauseCollectionsRequest) (*dto.CustomerResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if !req.Until.After(now) {
+		return nil, fmt.Errorf("invalid request: until must be in the future")
+	}
+
+	customer, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	before := *customer
+	until := req.Until.UTC()
+	customer.CollectionsPausedUntil = &until
+	customer.CollectionsPauseReason = req.Reason
+	customer.UpdatedAt = now
+	customer.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.repo.Update(ctx, customer); err != nil {
+		return nil, fmt.Errorf("failed to pause collections: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCustomer, customer.ID, types.AuditActionUpdate, &before, customer)
+	return &dto.CustomerResponse{Customer: customer}, nil
+}
+
+// ResumeCollections lifts the pause of the collections of the customer before
+// it expires
+func (s *customerService) ResumeCollections(ctx context.Context, id string) (*dto.CustomerResponse, error) {
+	customer, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer.CollectionsPausedUntil == nil {
+		return &dto.CustomerResponse{Customer: customer}, nil
+	}
+
+	before := *customer
+	customer.CollectionsPausedUntil = nil
+	customer.CollectionsPauseReason = ""
+	customer.UpdatedAt = time.Now().UTC()
+	customer.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.repo.Update(ctx, customer); err != nil {
+		return nil, fmt.Errorf("failed to resume collections: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCustomer, customer.ID, types.AuditActionUpdate, &before, customer)
+	return &dto.CustomerResponse{Customer: customer}, nil
+}
@@ -225,6 +225,46 @@ func (s *CustomerServiceSuite) TestUpdateCustomer() {
 	}
 }
 
+func (s *CustomerServiceSuite) TestCustomerAddressesAndTaxIDs() {
+	address := &customer.Address{Line1: "Unter den Linden 1", City: "Berlin", PostalCode: "10117", Country: "DE"}
+
+	resp, err := s.customerService.CreateCustomer(s.ctx, dto.CreateCustomerRequest{
+		ExternalID:     "ext-vat",
+		BillingAddress: address,
+		TaxIDs: []dto.TaxIDRequest{
+			{Type: types.TaxIDTypeEUVAT, Value: "de 123 456 789"},
+		},
+	})
+	s.Require().NoError(err)
+	s.Equal(address, resp.BillingAddress)
+	s.Nil(resp.ShippingAddress)
+	s.Equal(customer.TaxIDs{{Type: types.TaxIDTypeEUVAT, Value: "DE123456789", Country: "DE"}}, resp.TaxIDs)
+
+	_, err = s.customerService.CreateCustomer(s.ctx, dto.CreateCustomerRequest{
+		ExternalID: "ext-bad-vat",
+		TaxIDs:     []dto.TaxIDRequest{{Type: types.TaxIDTypeEUVAT, Value: "DE12345"}},
+	})
+	s.Error(err)
+
+	_, err = s.customerService.CreateCustomer(s.ctx, dto.CreateCustomerRequest{
+		ExternalID:     "ext-bad-country",
+		BillingAddress: &customer.Address{Line1: "1 Main St", City: "Springfield", Country: "USA"},
+	})
+	s.Error(err)
+
+	// Updates replace the addresses and the tax IDs
+	updated, err := s.customerService.UpdateCustomer(s.ctx, resp.ID, dto.UpdateCustomerRequest{
+		ExternalID:      "ext-vat",
+		ShippingAddress: address,
+		TaxIDs:          []dto.TaxIDRequest{{Type: types.TaxIDTypeGBVAT, Value: "GB123456789"}},
+	})
+	s.Require().NoError(err)
+	s.Nil(updated.BillingAddress)
+	s.Equal(address, updated.ShippingAddress)
+	s.Require().Len(updated.TaxIDs, 1)
+	s.Equal("GB", updated.TaxIDs[0].Country)
+}
+
 func (s *CustomerServiceSuite) TestDeleteCustomer() {
 	// Prepopulate the repository with a customer
 	_ = s.repo.Create(s.ctx, &customer.Customer{
@@ -259,9 +299,15 @@ func (s *CustomerServiceSuite) TestDeleteCustomer() {
 			} else {
 				s.NoError(err)
 
-				// Ensure the customer no longer exists
-				_, err := s.repo.Get(s.ctx, tc.id)
-				s.Error(err)
+				// The customer is kept deleted until purged
+				deleted, err := s.repo.Get(s.ctx, tc.id)
+				s.Require().NoError(err)
+				s.Equal(types.StatusDeleted, deleted.Status)
+				s.NotNil(deleted.DeletedAt)
+
+				customers, err := s.repo.List(s.ctx, types.Filter{Limit: 10})
+				s.Require().NoError(err)
+				s.Empty(customers)
 			}
 		})
 	}
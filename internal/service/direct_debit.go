@@ -0,0 +1,508 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/mandate"
+	"github.com/flexprice/flexprice/internal/gateway"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// failureCodeMandateInactive is the failure code of the debits whose mandate was
+// revoked or failed before their charge date
+const failureCodeMandateInactive = "MANDATE_REVOKED"
+
+const (
+	debitClaimBatch = 100
+	// debitClaimLease is how long a claimed debit is left to the scheduler that
+	// claimed it before it is claimed again, ex after a restart or while its
+	// customer's collections are paused
+	debitClaimLease = time.Hour
+)
+
+func (s *paymentService) ScheduleDirectDebit(ctx context.Context, invoiceID string, req dto.ScheduleDirectDebitRequest) (*dto.InvoicePaymentResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	inv, err := s.invoiceRepo.Get(ctx, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	if inv.InvoiceType == types.InvoiceTypeCredit {
+		return nil, fmt.Errorf("invalid request: payments can not be collected for a credit invoice")
+	}
+	if inv.InvoiceStatus != types.InvoiceStatusFinalized {
+		return nil, fmt.Errorf("invoice must be finalized before payments are collected")
+	}
+	if inv.WrittenOffAt != nil {
+		return nil, ErrInvoiceWrittenOff
+	}
+	if err := checkNotDisputed(ctx, s.invoiceRepo, inv.ID); err != nil {
+		return nil, err
+	}
+
+	cust, err := s.customerRepo.Get(ctx, inv.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if err := checkCollectionsActive(cust, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	m, err := s.mandateOf(ctx, cust, req.MandateID)
+	if err != nil {
+		return nil, err
+	}
+	pm, err := s.paymentMethodRepo.Get(ctx, m.PaymentMethodID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment method: %w", err)
+	}
+
+	var payment *invoice.Payment
+	var before invoice.Invoice
+
+	// The invoice is locked so that concurrent payments can not pay more than
+	// the amount remaining
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		inv, err = s.invoiceRepo.GetForUpdate(ctx, inv.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+
+		amount := inv.AmountRemaining
+		if req.Amount != nil {
+			if req.Amount.GreaterThan(amount) {
+				return fmt.Errorf("invalid request: amount exceeds the amount remaining of %s", amount.String())
+			}
+			amount = *req.Amount
+		}
+		if !amount.IsPositive() {
+			return fmt.Errorf("invalid request: invoice is fully paid")
+		}
+
+		now := s.clock.Now()
+		chargeDate := now.AddDate(0, 0, m.PreNotificationDays)
+		payment = &invoice.Payment{
+			ID:                types.GenerateUUID(),
+			InvoiceID:         inv.ID,
+			CustomerID:        inv.CustomerID,
+			Amount:            amount,
+			Currency:          inv.Currency,
+			PaymentMethodType: pm.Type,
+			Reference:         m.Reference,
+			ConnectionID:      pm.ConnectionID,
+			// Set to when the debit clears
+			PaidAt:        chargeDate,
+			PaymentStatus: types.PaymentStatusScheduled,
+			MandateID:     m.ID,
+			ChargeDate:    &chargeDate,
+			BaseModel:     types.GetDefaultBaseModel(ctx),
+		}
+		if err := s.invoiceRepo.CreatePayment(ctx, payment); err != nil {
+			return fmt.Errorf("failed to schedule debit: %w", err)
+		}
+
+		before = *inv
+		inv.AmountProcessing = inv.AmountProcessing.Add(amount)
+		inv.RecalculateAmountRemaining()
+		inv.UpdatedAt = now
+		inv.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoicePayment, payment.ID, types.AuditActionCreate, nil, payment)
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionUpdate, &before, inv)
+
+	// The debit stays scheduled when the email fails to queue, the delivery
+	// can be resent
+	if err := s.emailService.QueueDebitPreNotificationEmail(ctx, inv, payment, m); err != nil {
+		s.logger.Errorw("failed to queue debit pre-notification email",
+			"invoice_id", inv.ID,
+			"payment_id", payment.ID,
+			"error", err,
+		)
+	}
+
+	s.logger.Debugw("scheduled direct debit",
+		"invoice_id", inv.ID,
+		"payment_id", payment.ID,
+		"mandate_id", m.ID,
+		"charge_date", payment.ChargeDate,
+		"amount", payment.Amount,
+	)
+
+	return &dto.InvoicePaymentResponse{Payment: payment}, nil
+}
+
+// mandateOf returns the active mandate of the customer, the one on its default
+// payment method when no ID is given
+func (s *paymentService) mandateOf(ctx context.Context, cust *customer.Customer, id string) (*mandate.Mandate, error) {
+	if id != "" {
+		m, err := s.mandateRepo.Get(ctx, id)
+		if err != nil || m.CustomerID != cust.ID {
+			return nil, ErrMandateNotFound
+		}
+		if m.MandateStatus != types.MandateStatusActive {
+			return nil, fmt.Errorf("invalid request: mandate is %s", m.MandateStatus)
+		}
+		return m, nil
+	}
+
+	if cust.PaymentMethodID == "" {
+		return nil, fmt.Errorf("invalid request: customer has no default payment method")
+	}
+	pms, err := s.paymentMethodRepo.ListByCustomer(ctx, cust.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment methods: %w", err)
+	}
+	defaults := make(map[string]bool)
+	for _, pm := range pms {
+		if pm.GatewayPaymentMethodID == cust.PaymentMethodID {
+			defaults[pm.ID] = true
+		}
+	}
+
+	mandates, err := s.mandateRepo.ListByCustomer(ctx, cust.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mandates: %w", err)
+	}
+	for _, m := range mandates {
+		if defaults[m.PaymentMethodID] && m.MandateStatus == types.MandateStatusActive {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid request: default payment method of the customer has no active mandate")
+}
+
+func (s *paymentService) CollectDueDebits(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	for ctx.Err() == nil {
+		// Claimed debits are leased, so each debit is submitted once per claim and
+		// the loop ends when no due debit is left
+		payments, err := s.invoiceRepo.ClaimDueDebits(ctx, now, debitClaimLease, debitClaimBatch)
+		if err != nil {
+			return fmt.Errorf("failed to claim due debits: %w", err)
+		}
+
+		forEachJobItem(ctx, payments, func(payment *invoice.Payment) {
+			tenantCtx := context.WithValue(ctx, types.CtxTenantID, payment.TenantID)
+			tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+			// One failing debit must not hold back the others, it is submitted again
+			// once its lease expires
+			if err := s.collectDebit(tenantCtx, payment); err != nil {
+				s.logger.Errorw("failed to collect direct debit",
+					"tenant_id", payment.TenantID,
+					"invoice_id", payment.InvoiceID,
+					"payment_id", payment.ID,
+					"error", err,
+				)
+			}
+		})
+
+		if len(payments) < debitClaimBatch {
+			break
+		}
+	}
+	return nil
+}
+
+// collectDebit submits a scheduled debit to the gateway of its connection. Bank
+// debits are pending at the gateway until they clear, which is notified by webhook
+func (s *paymentService) collectDebit(ctx context.Context, payment *invoice.Payment) error {
+	// The debits of a disputed invoice stay scheduled until the dispute is
+	// resolved, they are cancelled if it changes the amount of the invoice
+	dispute, err := s.invoiceRepo.GetActiveDispute(ctx, payment.InvoiceID)
+	if err != nil {
+		return fmt.Errorf("failed to get invoice dispute: %w", err)
+	}
+	if dispute != nil {
+		s.logger.Debugw("skipped debit of disputed invoice",
+			"invoice_id", payment.InvoiceID,
+			"payment_id", payment.ID,
+			"dispute_id", dispute.ID,
+		)
+		return nil
+	}
+
+	// The debits of a customer whose collections are paused are submitted once
+	// the pause expires or is lifted
+	cust, err := s.customerRepo.Get(ctx, payment.CustomerID)
+	if err != nil {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+	if cust.CollectionsPaused(s.clock.Now()) {
+		s.logger.Debugw("skipped debit of customer with paused collections",
+			"invoice_id", payment.InvoiceID,
+			"payment_id", payment.ID,
+			"customer_id", cust.ID,
+			"paused_until", cust.CollectionsPausedUntil,
+		)
+		return nil
+	}
+
+	m, err := s.mandateRepo.Get(ctx, payment.MandateID)
+	if err != nil {
+		return fmt.Errorf("failed to get mandate: %w", err)
+	}
+	if m.MandateStatus != types.MandateStatusActive {
+		return s.failDebit(ctx, payment.ID, "", failureCodeMandateInactive, fmt.Sprintf("mandate is %s", m.MandateStatus))
+	}
+	pm, err := s.paymentMethodRepo.Get(ctx, m.PaymentMethodID)
+	if err != nil {
+		return fmt.Errorf("failed to get payment method: %w", err)
+	}
+	conn, err := s.connectionRepo.Get(ctx, payment.ConnectionID)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	client, err := s.newGateway(conn)
+	if err != nil {
+		return err
+	}
+
+	key := gateway.CustomerMetadataKey(conn.Provider)
+	gatewayCustomerID := cust.Metadata[key]
+	if gatewayCustomerID == "" {
+		return fmt.Errorf("customer has no %s", key)
+	}
+
+	// The debit is processing before it is submitted, so that it is neither
+	// cancelled nor submitted again while the gateway debits it. A processing
+	// debit was claimed again because its submission did not return, it is
+	// submitted again with the same idempotency key
+	if payment.PaymentStatus == types.PaymentStatusScheduled {
+		payment, err = s.updatePayment(ctx, payment.ID, func(inv *invoice.Invoice, payment *invoice.Payment, now time.Time) bool {
+			if payment.PaymentStatus != types.PaymentStatusScheduled {
+				return false
+			}
+			payment.PaymentStatus = types.PaymentStatusProcessing
+			return true
+		})
+		if err != nil || payment == nil {
+			return err
+		}
+	}
+
+	// The payment ID is the reference and the idempotency key of the debit at
+	// the gateway, a debit submitted again is not debited twice
+	result, err := runOnSyncQueue(ctx, s.connectionService, conn.ID, types.SyncEntityTypePayment, payment.ID,
+		func(ctx context.Context) (*gateway.Payment, error) {
+			return client.Debit(ctx, &gateway.AuthorizeRequest{
+				CustomerID:        gatewayCustomerID,
+				PaymentMethodID:   pm.GatewayPaymentMethodID,
+				PaymentMethodType: pm.Type,
+				Amount:            payment.Amount,
+				Currency:          payment.Currency,
+				Reference:         payment.ID,
+				Email:             cust.Email,
+				MandateReference:  m.Reference,
+				IdempotencyKey:    payment.ID,
+			})
+		})
+	if err != nil {
+		return fmt.Errorf("failed to submit debit: %w", err)
+	}
+
+	switch result.Status {
+	case gateway.PaymentStatusFailed:
+		return s.failDebit(ctx, payment.ID, result.ID, result.FailureCode, result.FailureReason)
+	case gateway.PaymentStatusCaptured:
+		_, err := s.clearPayment(ctx, payment.ID, result.ID)
+		return err
+	}
+
+	_, err = s.updatePayment(ctx, payment.ID, func(inv *invoice.Invoice, payment *invoice.Payment, now time.Time) bool {
+		if payment.PaymentStatus != types.PaymentStatusProcessing || payment.GatewayPaymentID != "" {
+			return false
+		}
+		payment.GatewayPaymentID = result.ID
+		return true
+	})
+	return err
+}
+
+// settleDebit clears the bank debit, or the collected payment whose capture
+// failed to return, whose payment at the gateway a webhook notifies was captured
+func (s *paymentService) settleDebit(ctx context.Context, event *gateway.WebhookEvent) error {
+	payment, err := s.invoiceRepo.GetPaymentByGatewayID(ctx, event.PaymentID)
+	// Payments made at the gateway directly are not tracked, the payments paid
+	// already are left as they are by clearPayment
+	if err != nil {
+		return nil
+	}
+	_, err = s.clearPayment(ctx, payment.ID, event.PaymentID)
+	return err
+}
+
+// failProcessingDebit fails the bank debit, or the collected payment whose
+// capture failed to return, whose payment at the gateway a webhook notifies
+// failed. It returns false when the payment is not processing
+func (s *paymentService) failProcessingDebit(ctx context.Context, event *gateway.WebhookEvent) (bool, error) {
+	payment, err := s.invoiceRepo.GetPaymentByGatewayID(ctx, event.PaymentID)
+	if err != nil {
+		return false, nil
+	}
+	if payment.MandateID == "" && payment.PaymentStatus != types.PaymentStatusProcessing {
+		return false, nil
+	}
+	// Debits returned after they cleared are reconciled as the other payments
+	if payment.PaymentStatus == types.PaymentStatusSucceeded {
+		return false, nil
+	}
+	return true, s.failDebit(ctx, payment.ID, event.PaymentID, event.FailureCode, event.FailureReason)
+}
+
+// clearPayment moves the amount of a debit that cleared, or of a collected
+// payment that was captured, from the amount processing to the amount paid of
+// its invoice
+func (s *paymentService) clearPayment(ctx context.Context, paymentID, gatewayPaymentID string) (*invoice.Payment, error) {
+	return s.updatePayment(ctx, paymentID, func(inv *invoice.Invoice, payment *invoice.Payment, now time.Time) bool {
+		if payment.PaymentStatus != types.PaymentStatusScheduled && payment.PaymentStatus != types.PaymentStatusProcessing {
+			return false
+		}
+		payment.PaymentStatus = types.PaymentStatusSucceeded
+		payment.GatewayPaymentID = gatewayPaymentID
+		payment.PaidAt = now
+		inv.AmountProcessing = inv.AmountProcessing.Sub(payment.Amount)
+		inv.AmountPaid = inv.AmountPaid.Add(payment.Amount)
+		return true
+	})
+}
+
+// failDebit takes the amount of a failed debit off the amount processing of its
+// invoice and sets the dunning action of its failure code. The mandate is
+// revoked or failed when the code says it can no longer be debited, and the
+// failure is notified with the invoice.payment_failed webhook
+func (s *paymentService) failDebit(ctx context.Context, paymentID, gatewayPaymentID, failureCode, failureReason string) error {
+	payment, err := s.updatePayment(ctx, paymentID, func(inv *invoice.Invoice, payment *invoice.Payment, now time.Time) bool {
+		if payment.PaymentStatus != types.PaymentStatusScheduled && payment.PaymentStatus != types.PaymentStatusProcessing {
+			return false
+		}
+		payment.PaymentStatus = types.PaymentStatusFailed
+		if gatewayPaymentID != "" {
+			payment.GatewayPaymentID = gatewayPaymentID
+		}
+		payment.FailureCode = failureCode
+		payment.FailureReason = failureReason
+		payment.DunningAction = types.DunningActionFor(failureCode)
+		inv.AmountProcessing = inv.AmountProcessing.Sub(payment.Amount)
+		return true
+	})
+	if err != nil || payment == nil {
+		return err
+	}
+
+	if status := types.MandateStatusAfter(payment.DunningAction); status != "" && payment.MandateID != "" {
+		m, err := s.mandateRepo.Get(ctx, payment.MandateID)
+		if err != nil {
+			return fmt.Errorf("failed to get mandate: %w", err)
+		}
+		if m.MandateStatus == types.MandateStatusActive {
+			reason := fmt.Sprintf("debit %s failed with %s", payment.ID, failureCode)
+			if err := revokeMandate(ctx, s.mandateRepo, s.auditPublisher, m, status, reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	err = s.webhookPublisher.Publish(ctx, types.WebhookEventInvoicePaymentFailed, &dto.InvoicePaymentFailedEvent{
+		InvoiceID:        payment.InvoiceID,
+		PaymentID:        payment.ID,
+		CustomerID:       payment.CustomerID,
+		ConnectionID:     payment.ConnectionID,
+		GatewayPaymentID: payment.GatewayPaymentID,
+		Amount:           payment.Amount,
+		Currency:         payment.Currency,
+		FailureReason:    payment.FailureReason,
+		FailureCode:      payment.FailureCode,
+		DunningAction:    payment.DunningAction,
+	})
+	if err != nil {
+		s.logger.Errorw("failed to publish invoice payment failed webhook", "invoice_id", payment.InvoiceID, "error", err)
+	}
+
+	s.logger.Infow("direct debit failed",
+		"invoice_id", payment.InvoiceID,
+		"payment_id", payment.ID,
+		"failure_code", payment.FailureCode,
+		"dunning_action", payment.DunningAction,
+	)
+	return nil
+}
+
+// updatePayment applies fn to a bank debit or a collected payment and its
+// invoice under the lock of the invoice. fn returns false when the payment is
+// not in a status it applies to, then nothing is updated and no payment is
+// returned
+func (s *paymentService) updatePayment(ctx context.Context, paymentID string, fn func(inv *invoice.Invoice, payment *invoice.Payment, now time.Time) bool) (*invoice.Payment, error) {
+	payment, err := s.invoiceRepo.GetPayment(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	var inv *invoice.Invoice
+	var beforeInvoice invoice.Invoice
+	var beforePayment invoice.Payment
+	updated := false
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		inv, err = s.invoiceRepo.GetForUpdate(ctx, payment.InvoiceID)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+
+		// Read again under the lock of the invoice, gateways deliver a webhook
+		// again until it is acknowledged
+		payment, err = s.invoiceRepo.GetPayment(ctx, paymentID)
+		if err != nil {
+			return fmt.Errorf("failed to get payment: %w", err)
+		}
+
+		now := s.clock.Now()
+		beforeInvoice = *inv
+		beforePayment = *payment
+		if !fn(inv, payment, now) {
+			return nil
+		}
+
+		payment.UpdatedAt = now
+		payment.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.UpdatePayment(ctx, payment); err != nil {
+			return err
+		}
+
+		inv.RecalculateAmountRemaining()
+		inv.UpdatedAt = now
+		inv.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		updated = true
+		return nil
+	})
+	if err != nil || !updated {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoicePayment, payment.ID, types.AuditActionUpdate, &beforePayment, payment)
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionUpdate, &beforeInvoice, inv)
+
+	s.logger.Debugw("updated invoice payment",
+		"invoice_id", inv.ID,
+		"payment_id", payment.ID,
+		"status", payment.PaymentStatus,
+	)
+	return payment, nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/gateway"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectDebit(t *testing.T) {
+	ctx := testutil.SetupContext()
+	manager := syncqueue.NewManager(&config.Configuration{
+		Integration: config.IntegrationConfig{SyncRatePerSecond: 100, SyncMaxAttempts: 1},
+	}, logger.GetLogger())
+	defer manager.Stop()
+
+	connectionStore := testutil.NewInMemoryConnectionStore()
+	connectionService := NewConnectionService(connectionStore, manager, nil, logger.GetLogger())
+	conn, err := connectionService.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:            "Adyen",
+		Provider:        types.IntegrationProviderAdyen,
+		Credentials:     "AQE_test",
+		GatewaySettings: &connection.GatewaySettings{MerchantAccount: "FlexpriceECOM"},
+	})
+	require.NoError(t, err)
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:              "cust_1",
+		Email:           "billing@acme.com",
+		PaymentMethodID: "stored_iban",
+		Metadata:        map[string]string{types.MetadataAdyenShopperReference: "shopper_1"},
+		BaseModel:       types.GetDefaultBaseModel(ctx),
+	}))
+
+	paymentMethodStore := testutil.NewInMemoryPaymentMethodStore()
+	for _, pm := range []*paymentmethod.PaymentMethod{
+		{ID: "pm_1", CustomerID: "cust_1", GatewayPaymentMethodID: "stored_iban"},
+		{ID: "pm_other", CustomerID: "cust_2", GatewayPaymentMethodID: "other_iban"},
+	} {
+		pm.ConnectionID = conn.ID
+		pm.Provider = types.IntegrationProviderAdyen
+		pm.Type = "sepadirectdebit"
+		pm.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, paymentMethodStore.Create(ctx, pm))
+	}
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:         "ep_1",
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{string(types.WebhookEventInvoicePaymentFailed)},
+		Secret:     "whsec_test",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+	publisher := webhook.NewPublisher(webhookStore, logger.GetLogger())
+
+	emailStore := testutil.NewInMemoryEmailStore()
+	emailService := NewEmailService(emailStore, customerStore, nil, stubEmailProvider{}, logger.GetLogger())
+
+	mandateStore := testutil.NewInMemoryMandateStore()
+	mandateService := NewMandateService(mandateStore, customerStore, paymentMethodStore, nil, logger.GetLogger())
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	fakeGateway := &fakeCollectionGateway{debitStatus: gateway.PaymentStatusPending}
+	svc := NewPaymentService(invoiceStore, customerStore, paymentMethodStore, connectionStore, connectionService,
+		mandateStore, emailService, testutil.NewInMemoryTxManager(), publisher, nil, nil,
+		logger.GetLogger()).(*paymentService)
+	svc.newGateway = func(*connection.Connection) (collectionGateway, error) { return fakeGateway, nil }
+
+	inv := &invoice.Invoice{
+		ID:            "inv_1",
+		CustomerID:    "cust_1",
+		InvoiceType:   types.InvoiceTypeSubscription,
+		InvoiceStatus: types.InvoiceStatusFinalized,
+		Currency:      "eur",
+		Total:         decimal.NewFromInt(100),
+		AmountDue:     decimal.NewFromInt(100),
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+	inv.RecalculateAmountRemaining()
+	require.NoError(t, invoiceStore.Create(ctx, inv))
+
+	getInvoice := func() *invoice.Invoice {
+		updated, err := invoiceStore.Get(ctx, inv.ID)
+		require.NoError(t, err)
+		return updated
+	}
+	getPayment := func(id string) *invoice.Payment {
+		payment, err := invoiceStore.GetPayment(ctx, id)
+		require.NoError(t, err)
+		return payment
+	}
+
+	t.Run("mandate on a payment method of another customer", func(t *testing.T) {
+		_, err := mandateService.CreateMandate(ctx, "cust_1", dto.CreateMandateRequest{
+			PaymentMethodID: "pm_other",
+			Scheme:          types.MandateSchemeSEPACore,
+			Reference:       "MANDATE-OTHER",
+		})
+		assert.ErrorIs(t, err, ErrPaymentMethodNotFound)
+	})
+
+	created, err := mandateService.CreateMandate(ctx, "cust_1", dto.CreateMandateRequest{
+		PaymentMethodID: "pm_1",
+		Scheme:          types.MandateSchemeSEPACore,
+		Reference:       "MANDATE-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.MandateStatusActive, created.MandateStatus)
+	assert.Equal(t, 14, created.PreNotificationDays)
+
+	t.Run("second active mandate on a payment method", func(t *testing.T) {
+		_, err := mandateService.CreateMandate(ctx, "cust_1", dto.CreateMandateRequest{
+			PaymentMethodID: "pm_1",
+			Scheme:          types.MandateSchemeSEPACore,
+			Reference:       "MANDATE-2",
+		})
+		assert.Error(t, err)
+	})
+
+	var debitID string
+	t.Run("debit is scheduled after the pre-notification", func(t *testing.T) {
+		amount := decimal.NewFromInt(60)
+		resp, err := svc.ScheduleDirectDebit(ctx, inv.ID, dto.ScheduleDirectDebitRequest{Amount: &amount})
+		require.NoError(t, err)
+		debitID = resp.ID
+
+		assert.Equal(t, types.PaymentStatusScheduled, resp.PaymentStatus)
+		assert.Equal(t, created.ID, resp.MandateID)
+		require.NotNil(t, resp.ChargeDate)
+		assert.WithinDuration(t, time.Now().AddDate(0, 0, 14), *resp.ChargeDate, time.Minute)
+
+		updated := getInvoice()
+		assert.True(t, decimal.NewFromInt(60).Equal(updated.AmountProcessing))
+		assert.True(t, decimal.NewFromInt(40).Equal(updated.AmountRemaining))
+
+		deliveries, err := emailStore.ListDeliveries(ctx, &types.EmailDeliveryFilter{EntityID: debitID})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, types.EmailTemplateDebitPreNotification, deliveries[0].TemplateType)
+	})
+
+	t.Run("debit is submitted on its charge date", func(t *testing.T) {
+		require.NoError(t, svc.CollectDueDebits(ctx, time.Now()))
+		assert.Empty(t, fakeGateway.debits)
+
+		// The submission does not return, the debit is processing and leased to
+		// the scheduler that claimed it
+		chargeDate := time.Now().AddDate(0, 0, 15)
+		fakeGateway.debitErr = errors.New("context deadline exceeded")
+		require.NoError(t, svc.CollectDueDebits(ctx, chargeDate))
+		assert.Equal(t, 1, fakeGateway.debitAttempts)
+		payment := getPayment(debitID)
+		assert.Equal(t, types.PaymentStatusProcessing, payment.PaymentStatus)
+		assert.Empty(t, payment.GatewayPaymentID)
+		assert.True(t, decimal.NewFromInt(60).Equal(getInvoice().AmountProcessing))
+
+		// Another scheduler does not submit the leased debit
+		require.NoError(t, svc.CollectDueDebits(ctx, chargeDate.Add(time.Minute)))
+		assert.Equal(t, 1, fakeGateway.debitAttempts)
+
+		// Once the lease expires it is submitted again with the same idempotency key
+		fakeGateway.debitErr = nil
+		require.NoError(t, svc.CollectDueDebits(ctx, chargeDate.Add(2*debitClaimLease)))
+		require.Len(t, fakeGateway.debits, 1)
+		assert.Equal(t, "MANDATE-1", fakeGateway.debits[0].MandateReference)
+		assert.Equal(t, "stored_iban", fakeGateway.debits[0].PaymentMethodID)
+		assert.Equal(t, debitID, fakeGateway.debits[0].Reference)
+		assert.Equal(t, debitID, fakeGateway.debits[0].IdempotencyKey)
+
+		payment = getPayment(debitID)
+		assert.Equal(t, types.PaymentStatusProcessing, payment.PaymentStatus)
+		assert.Equal(t, "psp_debit_1", payment.GatewayPaymentID)
+		assert.True(t, payment.Refundable().IsZero())
+
+		// A debit with a gateway payment is left to the webhook of the gateway
+		require.NoError(t, svc.CollectDueDebits(ctx, chargeDate.Add(4*debitClaimLease)))
+		assert.Len(t, fakeGateway.debits, 1)
+	})
+
+	t.Run("debit is paid once it clears", func(t *testing.T) {
+		fakeGateway.events = []*gateway.WebhookEvent{{
+			ID:        "psp_debit_1:AUTHORISATION:true",
+			Type:      gateway.EventTypePaymentAuthorized,
+			PaymentID: "psp_debit_1",
+		}}
+		header := http.Header{"Signature": {"valid"}}
+		require.NoError(t, svc.HandleGatewayWebhook(ctx, conn.ID, []byte(`{}`), header))
+		// Delivered again, it is settled once
+		require.NoError(t, svc.HandleGatewayWebhook(ctx, conn.ID, []byte(`{}`), header))
+
+		assert.Equal(t, types.PaymentStatusSucceeded, getPayment(debitID).PaymentStatus)
+		updated := getInvoice()
+		assert.True(t, decimal.NewFromInt(60).Equal(updated.AmountPaid))
+		assert.True(t, updated.AmountProcessing.IsZero())
+		assert.Equal(t, types.InvoicePaymentStatusPartiallyPaid, updated.PaymentStatus)
+	})
+
+	t.Run("failed debit sets the dunning action and revokes the mandate", func(t *testing.T) {
+		resp, err := svc.ScheduleDirectDebit(ctx, inv.ID, dto.ScheduleDirectDebitRequest{MandateID: created.ID})
+		require.NoError(t, err)
+		assert.Equal(t, types.InvoicePaymentStatusProcessing, getInvoice().PaymentStatus)
+
+		fakeGateway.debitStatus = gateway.PaymentStatusFailed
+		fakeGateway.debitFailure = "MD01"
+		require.NoError(t, svc.CollectDueDebits(ctx, time.Now().AddDate(0, 0, 15)))
+
+		payment := getPayment(resp.ID)
+		assert.Equal(t, types.PaymentStatusFailed, payment.PaymentStatus)
+		assert.Equal(t, "MD01", payment.FailureCode)
+		assert.Equal(t, types.DunningActionNewMandate, payment.DunningAction)
+
+		updated := getInvoice()
+		assert.True(t, updated.AmountProcessing.IsZero())
+		assert.True(t, decimal.NewFromInt(40).Equal(updated.AmountRemaining))
+
+		m, err := mandateStore.Get(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.MandateStatusRevoked, m.MandateStatus)
+
+		_, err = svc.ScheduleDirectDebit(ctx, inv.ID, dto.ScheduleDirectDebitRequest{})
+		assert.Error(t, err)
+	})
+
+	deliveries, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, types.WebhookEventInvoicePaymentFailed, deliveries[0].EventType)
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	emailDomain "github.com/flexprice/flexprice/internal/domain/email"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/domain/mandate"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/email"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const (
+	emailEntityInvoice      = "invoice"
+	emailEntityPayment      = "invoice_payment"
+	emailEntitySubscription = "subscription"
+	emailEntityReportRun    = "report_run"
+)
+
+type EmailService interface {
+	// QueueInvoiceEmail queues the invoice_finalized email of the invoice to its
+	// customer. Nothing is queued when emails are disabled or the customer has no
+	// email. It records the delivery in the transaction of ctx, if any
+	QueueInvoiceEmail(ctx context.Context, inv *invoice.Invoice) error
+
+	// QueueTrialWillEndEmail queues the trial_will_end email of the subscription
+	// to its customer, as QueueInvoiceEmail
+	QueueTrialWillEndEmail(ctx context.Context, sub *subscription.Subscription) error
+
+	// QueueDebitPreNotificationEmail queues the debit_pre_notification email of a
+	// scheduled bank debit of an invoice to its customer, as QueueInvoiceEmail
+	QueueDebitPreNotificationEmail(ctx context.Context, inv *invoice.Invoice, payment *invoice.Payment, m *mandate.Mandate) error
+
+	// QueueReportEmail queues the report_ready email of a report run to each
+	// of the recipients. Nothing is queued when emails are disabled
+	QueueReportEmail(ctx context.Context, recipients []string, report *email.ReportData) error
+
+	// ListTemplates returns the template of every type, the default one when
+	// the tenant has not replaced it
+	ListTemplates(ctx context.Context) (*dto.ListEmailTemplatesResponse, error)
+	// SetTemplate replaces the template of a type once it renders with sample data
+	SetTemplate(ctx context.Context, templateType types.EmailTemplateType, req dto.SetEmailTemplateRequest) (*dto.EmailTemplateResponse, error)
+	// DeleteTemplate restores the default template of a type
+	DeleteTemplate(ctx context.Context, templateType types.EmailTemplateType) error
+
+	ListDeliveries(ctx context.Context, filter *types.EmailDeliveryFilter) (*dto.ListEmailDeliveriesResponse, error)
+	GetDelivery(ctx context.Context, id string) (*dto.EmailDeliveryResponse, error)
+
+	// ResendDelivery queues a copy of a delivery to be sent right away, as it
+	// was first rendered. The original delivery is left as is
+	ResendDelivery(ctx context.Context, id string) (*dto.EmailDeliveryResponse, error)
+}
+
+type emailService struct {
+	repo            emailDomain.Repository
+	customerRepo    customer.Repository
+	legalEntityRepo legalentity.Repository
+	provider        email.Provider
+	logger          *logger.Logger
+}
+
+func NewEmailService(
+	repo emailDomain.Repository,
+	customerRepo customer.Repository,
+	legalEntityRepo legalentity.Repository,
+	provider email.Provider,
+	logger *logger.Logger,
+) EmailService {
+	return &emailService{
+		repo:            repo,
+		customerRepo:    customerRepo,
+		legalEntityRepo: legalEntityRepo,
+		provider:        provider,
+		logger:          logger,
+	}
+}
+
+func (s *emailService) QueueInvoiceEmail(ctx context.Context, inv *invoice.Invoice) error {
+	number := ""
+	if inv.InvoiceNumber != nil {
+		number = *inv.InvoiceNumber
+	}
+
+	issuer, err := s.issuer(ctx, inv)
+	if err != nil {
+		return err
+	}
+
+	return s.queue(ctx, types.EmailTemplateInvoiceFinalized, inv.CustomerID, emailEntityInvoice, inv.ID, &email.Data{
+		Issuer: issuer,
+		Invoice: &email.InvoiceData{
+			ID:          inv.ID,
+			Number:      number,
+			Currency:    inv.Currency,
+			Total:       inv.Total,
+			AmountDue:   inv.AmountDue,
+			PeriodStart: inv.PeriodStart,
+			PeriodEnd:   inv.PeriodEnd,
+			FinalizedAt: inv.FinalizedAt,
+		},
+	})
+}
+
+// issuer returns the legal entity the invoice was issued from, nil when it was
+// issued from none
+func (s *emailService) issuer(ctx context.Context, inv *invoice.Invoice) (*email.IssuerData, error) {
+	if s.provider == nil || s.legalEntityRepo == nil || inv.LegalEntityID == "" {
+		return nil, nil
+	}
+
+	entity, err := s.legalEntityRepo.Get(ctx, inv.LegalEntityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legal entity: %w", err)
+	}
+	return &email.IssuerData{
+		ID:          entity.ID,
+		Name:        entity.Name,
+		Address:     entity.Address,
+		TaxIDs:      entity.TaxIDs,
+		BankDetails: entity.BankDetails,
+	}, nil
+}
+
+func (s *emailService) QueueTrialWillEndEmail(ctx context.Context, sub *subscription.Subscription) error {
+	return s.queue(ctx, types.EmailTemplateTrialWillEnd, sub.CustomerID, emailEntitySubscription, sub.ID, &email.Data{
+		Subscription: &email.SubscriptionData{
+			ID:       sub.ID,
+			PlanID:   sub.PlanID,
+			Currency: sub.Currency,
+			TrialEnd: sub.TrialEnd,
+		},
+	})
+}
+
+func (s *emailService) QueueDebitPreNotificationEmail(ctx context.Context, inv *invoice.Invoice, payment *invoice.Payment, m *mandate.Mandate) error {
+	number := ""
+	if inv.InvoiceNumber != nil {
+		number = *inv.InvoiceNumber
+	}
+
+	issuer, err := s.issuer(ctx, inv)
+	if err != nil {
+		return err
+	}
+
+	return s.queue(ctx, types.EmailTemplateDebitPreNotification, inv.CustomerID, emailEntityPayment, payment.ID, &email.Data{
+		Issuer: issuer,
+		Invoice: &email.InvoiceData{
+			ID:          inv.ID,
+			Number:      number,
+			Currency:    inv.Currency,
+			Total:       inv.Total,
+			AmountDue:   inv.AmountDue,
+			PeriodStart: inv.PeriodStart,
+			PeriodEnd:   inv.PeriodEnd,
+			FinalizedAt: inv.FinalizedAt,
+		},
+		Debit: &email.DebitData{
+			ID:               payment.ID,
+			Amount:           payment.Amount,
+			Currency:         payment.Currency,
+			ChargeDate:       *payment.ChargeDate,
+			Scheme:           string(m.Scheme),
+			MandateReference: m.Reference,
+		},
+	})
+}
+
+func (s *emailService) QueueReportEmail(ctx context.Context, recipients []string, report *email.ReportData) error {
+	if s.provider == nil || len(recipients) == 0 {
+		return nil
+	}
+
+	tmpl, err := s.template(ctx, types.EmailTemplateReportReady)
+	if err != nil {
+		return err
+	}
+
+	msg, err := tmpl.Render(&email.Data{Report: report})
+	if err != nil {
+		return fmt.Errorf("failed to render %s email: %w", types.EmailTemplateReportReady, err)
+	}
+
+	now := time.Now().UTC()
+	for _, recipient := range recipients {
+		delivery := &emailDomain.Delivery{
+			ID:             types.GenerateUUID(),
+			TemplateType:   types.EmailTemplateReportReady,
+			Recipient:      recipient,
+			Subject:        msg.Subject,
+			BodyHTML:       msg.HTML,
+			BodyText:       msg.Text,
+			EntityType:     emailEntityReportRun,
+			EntityID:       report.ID,
+			DeliveryStatus: types.EmailDeliveryStatusPending,
+			NextAttemptAt:  &now,
+			BaseModel:      types.GetDefaultBaseModel(ctx),
+		}
+
+		if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to create email delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *emailService) queue(
+	ctx context.Context,
+	templateType types.EmailTemplateType,
+	customerID, entityType, entityID string,
+	data *email.Data,
+) error {
+	if s.provider == nil {
+		return nil
+	}
+
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+	if cust.Email == "" {
+		return nil
+	}
+
+	data.Customer = email.CustomerData{
+		ID:             cust.ID,
+		ExternalID:     cust.ExternalID,
+		Name:           cust.Name,
+		Email:          cust.Email,
+		BillingAddress: cust.BillingAddress,
+		TaxIDs:         cust.TaxIDs,
+	}
+
+	tmpl, err := s.template(ctx, templateType)
+	if err != nil {
+		return err
+	}
+
+	msg, err := tmpl.Render(data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s email: %w", templateType, err)
+	}
+
+	now := time.Now().UTC()
+	delivery := &emailDomain.Delivery{
+		ID:             types.GenerateUUID(),
+		TemplateType:   templateType,
+		Recipient:      cust.Email,
+		Subject:        msg.Subject,
+		BodyHTML:       msg.HTML,
+		BodyText:       msg.Text,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		DeliveryStatus: types.EmailDeliveryStatusPending,
+		NextAttemptAt:  &now,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}
+
+	if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to create email delivery: %w", err)
+	}
+	return nil
+}
+
+// template returns the template of the tenant for the type or the default one
+func (s *emailService) template(ctx context.Context, templateType types.EmailTemplateType) (email.Template, error) {
+	custom, err := s.repo.GetTemplate(ctx, templateType)
+	if err == nil {
+		return email.Template{Subject: custom.Subject, HTML: custom.BodyHTML, Text: custom.BodyText}, nil
+	}
+
+	tmpl, ok := email.DefaultTemplate(templateType)
+	if !ok {
+		return email.Template{}, fmt.Errorf("no template for %s emails", templateType)
+	}
+	return tmpl, nil
+}
+
+func (s *emailService) ListTemplates(ctx context.Context) (*dto.ListEmailTemplatesResponse, error) {
+	custom, err := s.repo.ListTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email templates: %w", err)
+	}
+
+	byType := make(map[types.EmailTemplateType]*emailDomain.Template, len(custom))
+	for _, t := range custom {
+		byType[t.TemplateType] = t
+	}
+
+	response := &dto.ListEmailTemplatesResponse{}
+	for _, templateType := range []types.EmailTemplateType{
+		types.EmailTemplateInvoiceFinalized,
+		types.EmailTemplateTrialWillEnd,
+		types.EmailTemplateReportReady,
+		types.EmailTemplateDebitPreNotification,
+	} {
+		if t, ok := byType[templateType]; ok {
+			response.Templates = append(response.Templates, dto.EmailTemplateResponse{Template: t})
+			continue
+		}
+
+		def, _ := email.DefaultTemplate(templateType)
+		response.Templates = append(response.Templates, dto.EmailTemplateResponse{
+			Template: &emailDomain.Template{
+				TemplateType: templateType,
+				Subject:      def.Subject,
+				BodyHTML:     def.HTML,
+				BodyText:     def.Text,
+			},
+			IsDefault: true,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *emailService) SetTemplate(ctx context.Context, templateType types.EmailTemplateType, req dto.SetEmailTemplateRequest) (*dto.EmailTemplateResponse, error) {
+	if !templateType.Validate() {
+		return nil, fmt.Errorf("invalid template type: %s", templateType)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	// Templates are checked against sample data so that a broken template is
+	// rejected here rather than when an email is due
+	tmpl := email.Template{Subject: req.Subject, HTML: req.BodyHTML, Text: req.BodyText}
+	if _, err := tmpl.Render(email.SampleData(templateType)); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	template := req.ToTemplate(ctx, templateType)
+	if err := s.repo.UpsertTemplate(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to set email template: %w", err)
+	}
+
+	return &dto.EmailTemplateResponse{Template: template}, nil
+}
+
+func (s *emailService) DeleteTemplate(ctx context.Context, templateType types.EmailTemplateType) error {
+	if err := s.repo.DeleteTemplate(ctx, templateType); err != nil {
+		return fmt.Errorf("failed to delete email template: %w", err)
+	}
+	return nil
+}
+
+func (s *emailService) ListDeliveries(ctx context.Context, filter *types.EmailDeliveryFilter) (*dto.ListEmailDeliveriesResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email deliveries: %w", err)
+	}
+
+	response := &dto.ListEmailDeliveriesResponse{
+		Deliveries: make([]dto.EmailDeliveryResponse, len(deliveries)),
+		Total:      len(deliveries),
+		Offset:     filter.Offset,
+		Limit:      filter.Limit,
+	}
+
+	for i, delivery := range deliveries {
+		response.Deliveries[i] = dto.EmailDeliveryResponse{Delivery: delivery}
+	}
+
+	return response, nil
+}
+
+func (s *emailService) GetDelivery(ctx context.Context, id string) (*dto.EmailDeliveryResponse, error) {
+	delivery, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email delivery: %w", err)
+	}
+
+	return &dto.EmailDeliveryResponse{Delivery: delivery}, nil
+}
+
+func (s *emailService) ResendDelivery(ctx context.Context, id string) (*dto.EmailDeliveryResponse, error) {
+	if s.provider == nil {
+		return nil, fmt.Errorf("emails are not enabled")
+	}
+
+	original, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email delivery: %w", err)
+	}
+
+	now := time.Now().UTC()
+	delivery := &emailDomain.Delivery{
+		ID:             types.GenerateUUID(),
+		TemplateType:   original.TemplateType,
+		Recipient:      original.Recipient,
+		Subject:        original.Subject,
+		BodyHTML:       original.BodyHTML,
+		BodyText:       original.BodyText,
+		EntityType:     original.EntityType,
+		EntityID:       original.EntityID,
+		ResentFrom:     original.ID,
+		DeliveryStatus: types.EmailDeliveryStatusPending,
+		NextAttemptAt:  &now,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}
+
+	if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to resend email delivery: %w", err)
+	}
+
+	s.logger.Infow("email delivery resent",
+		"delivery_id", delivery.ID,
+		"resent_from", original.ID,
+		"tenant_id", delivery.TenantID,
+	)
+
+	return &dto.EmailDeliveryResponse{Delivery: delivery}, nil
+}
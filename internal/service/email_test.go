@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/email"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubEmailProvider struct{}
+
+func (stubEmailProvider) Name() types.EmailProvider {
+	return types.EmailProviderSMTP
+}
+
+func (stubEmailProvider) Send(ctx context.Context, msg *email.Message) (string, error) {
+	return "", nil
+}
+
+func setupEmailServiceTest(t *testing.T, provider email.Provider) (EmailService, *testutil.InMemoryEmailStore) {
+	ctx := testutil.SetupContext()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_1",
+		ExternalID: "ext_1",
+		Name:       "Jane Doe",
+		Email:      "jane@example.com",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_no_email",
+		ExternalID: "ext_2",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	store := testutil.NewInMemoryEmailStore()
+	return NewEmailService(store, customerStore, nil, provider, logger.GetLogger()), store
+}
+
+func TestEmailService_QueueInvoiceEmail(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, store := setupEmailServiceTest(t, stubEmailProvider{})
+
+	number := "INV-000042"
+	inv := &invoice.Invoice{
+		ID:            "inv_1",
+		InvoiceNumber: &number,
+		CustomerID:    "cust_1",
+		Currency:      "usd",
+		Total:         decimal.NewFromInt(120),
+		AmountDue:     decimal.NewFromInt(120),
+	}
+	require.NoError(t, svc.QueueInvoiceEmail(ctx, inv))
+
+	deliveries, err := store.ListDeliveries(ctx, &types.EmailDeliveryFilter{EntityID: "inv_1"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "jane@example.com", deliveries[0].Recipient)
+	assert.Equal(t, "Invoice INV-000042", deliveries[0].Subject)
+	assert.Contains(t, deliveries[0].BodyText, "120.00 USD")
+	assert.Equal(t, types.EmailDeliveryStatusPending, deliveries[0].DeliveryStatus)
+
+	// The template of the tenant replaces the default one
+	_, err = svc.SetTemplate(ctx, types.EmailTemplateInvoiceFinalized, dto.SetEmailTemplateRequest{
+		Subject:  "Your Acme invoice {{.Invoice.Number}}",
+		BodyText: "Hi {{.Customer.Name}}",
+	})
+	require.NoError(t, err)
+	inv.ID = "inv_2"
+	require.NoError(t, svc.QueueInvoiceEmail(ctx, inv))
+
+	deliveries, err = store.ListDeliveries(ctx, &types.EmailDeliveryFilter{EntityID: "inv_2"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "Your Acme invoice INV-000042", deliveries[0].Subject)
+	assert.Equal(t, "Hi Jane Doe", deliveries[0].BodyText)
+
+	// Customers without an email get none
+	inv.ID = "inv_3"
+	inv.CustomerID = "cust_no_email"
+	require.NoError(t, svc.QueueInvoiceEmail(ctx, inv))
+	deliveries, err = store.ListDeliveries(ctx, &types.EmailDeliveryFilter{EntityID: "inv_3"})
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestEmailService_DisabledWithoutProvider(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, store := setupEmailServiceTest(t, nil)
+
+	trialEnd := time.Now().UTC().AddDate(0, 0, 3)
+	require.NoError(t, svc.QueueTrialWillEndEmail(ctx, &subscription.Subscription{
+		ID:         "sub_1",
+		CustomerID: "cust_1",
+		TrialEnd:   &trialEnd,
+	}))
+
+	deliveries, err := store.ListDeliveries(ctx, &types.EmailDeliveryFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestEmailService_Templates(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _ := setupEmailServiceTest(t, stubEmailProvider{})
+
+	_, err := svc.SetTemplate(ctx, types.EmailTemplateTrialWillEnd, dto.SetEmailTemplateRequest{
+		Subject:  "Trial ends {{.Invoice.Number}}",
+		BodyText: "body",
+	})
+	assert.Error(t, err, "trial emails have no invoice")
+
+	_, err = svc.SetTemplate(ctx, types.EmailTemplateTrialWillEnd, dto.SetEmailTemplateRequest{
+		Subject:  "Your trial ends on {{date .Subscription.TrialEnd}}",
+		BodyHTML: "<p>Hi {{.Customer.Name}}</p>",
+	})
+	require.NoError(t, err)
+
+	resp, err := svc.ListTemplates(ctx)
+	require.NoError(t, err)
+	require.Len(t, resp.Templates, 4)
+	assert.Equal(t, types.EmailTemplateInvoiceFinalized, resp.Templates[0].TemplateType)
+	assert.True(t, resp.Templates[0].IsDefault)
+	assert.Equal(t, types.EmailTemplateTrialWillEnd, resp.Templates[1].TemplateType)
+	assert.False(t, resp.Templates[1].IsDefault)
+	assert.Equal(t, types.EmailTemplateReportReady, resp.Templates[2].TemplateType)
+	assert.True(t, resp.Templates[2].IsDefault)
+	assert.Equal(t, types.EmailTemplateDebitPreNotification, resp.Templates[3].TemplateType)
+
+	require.NoError(t, svc.DeleteTemplate(ctx, types.EmailTemplateTrialWillEnd))
+	resp, err = svc.ListTemplates(ctx)
+	require.NoError(t, err)
+	assert.True(t, resp.Templates[1].IsDefault)
+}
+
+func TestEmailService_ResendDelivery(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, store := setupEmailServiceTest(t, stubEmailProvider{})
+
+	require.NoError(t, svc.QueueInvoiceEmail(ctx, &invoice.Invoice{ID: "inv_1", CustomerID: "cust_1", Currency: "usd"}))
+	deliveries, err := store.ListDeliveries(ctx, &types.EmailDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	original := deliveries[0]
+
+	resp, err := svc.ResendDelivery(ctx, original.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, original.ID, resp.ID)
+	assert.Equal(t, original.ID, resp.ResentFrom)
+	assert.Equal(t, original.Subject, resp.Subject)
+	assert.Equal(t, types.EmailDeliveryStatusPending, resp.DeliveryStatus)
+
+	list, err := svc.ListDeliveries(ctx, &types.EmailDeliveryFilter{EntityID: "inv_1"})
+	require.NoError(t, err)
+	assert.Len(t, list.Deliveries, 2)
+
+	_, err = svc.ResendDelivery(ctx, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const resetConfirmationTTL = 5 * time.Minute
+
+type EnvironmentService interface {
+	CreateEnvironment(ctx context.Context, req dto.CreateEnvironmentRequest) (*dto.EnvironmentResponse, error)
+	GetEnvironment(ctx context.Context, id string) (*dto.EnvironmentResponse, error)
+	GetEnvironments(ctx context.Context, filter types.Filter) (*dto.ListEnvironmentsResponse, error)
+	ResetEnvironment(ctx context.Context, id string, req dto.ResetEnvironmentRequest) (*dto.ResetEnvironmentResponse, error)
+	GetEnvironmentReset(ctx context.Context, environmentID, resetID string) (*dto.EnvironmentResetResponse, error)
+}
+
+type environmentService struct {
+	repo      environment.Repository
+	eventRepo events.Repository
+	secret    string
+	logger    *logger.Logger
+}
+
+func NewEnvironmentService(
+	repo environment.Repository,
+	eventRepo events.Repository,
+	cfg *config.Configuration,
+	logger *logger.Logger,
+) EnvironmentService {
+	return &environmentService{
+		repo:      repo,
+		eventRepo: eventRepo,
+		secret:    cfg.Auth.Secret,
+		logger:    logger,
+	}
+}
+
+func (s *environmentService) CreateEnvironment(ctx context.Context, req dto.CreateEnvironmentRequest) (*dto.EnvironmentResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	env := req.ToEnvironment(ctx)
+	if err := s.repo.Create(ctx, env); err != nil {
+		return nil, fmt.Errorf("failed to create environment: %w", err)
+	}
+
+	return &dto.EnvironmentResponse{Environment: env}, nil
+}
+
+func (s *environmentService) GetEnvironment(ctx context.Context, id string) (*dto.EnvironmentResponse, error) {
+	env, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment: %w", err)
+	}
+
+	return &dto.EnvironmentResponse{Environment: env}, nil
+}
+
+func (s *environmentService) GetEnvironments(ctx context.Context, filter types.Filter) (*dto.ListEnvironmentsResponse, error) {
+	envs, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environments: %w", err)
+	}
+
+	response := &dto.ListEnvironmentsResponse{
+		Environments: make([]dto.EnvironmentResponse, len(envs)),
+		Total:        len(envs),
+		Offset:       filter.Offset,
+		Limit:        filter.Limit,
+	}
+
+	for i, env := range envs {
+		response.Environments[i] = dto.EnvironmentResponse{Environment: env}
+	}
+
+	return response, nil
+}
+
+// ResetEnvironment wipes customers, subscriptions, invoices, wallets and events while
+// preserving the catalog. It is a two step operation: the first call returns a
+// confirmation token and the second call carrying the token starts the reset job.
+func (s *environmentService) ResetEnvironment(ctx context.Context, id string, req dto.ResetEnvironmentRequest) (*dto.ResetEnvironmentResponse, error) {
+	env, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment: %w", err)
+	}
+
+	if env.Type != types.EnvironmentTypeSandbox {
+		return nil, fmt.Errorf("only sandbox environments can be reset")
+	}
+
+	// Records are scoped by tenant and not by environment, so a reset would also
+	// wipe the production data of the tenant. Refuse until the data is isolated.
+	envs, err := s.repo.List(ctx, types.Filter{Limit: types.DefaultFilterLimit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environments: %w", err)
+	}
+	for _, e := range envs {
+		if e.Type == types.EnvironmentTypeProduction {
+			return nil, fmt.Errorf("tenant has a production environment, reset is only allowed for sandbox tenants")
+		}
+	}
+
+	if req.ConfirmationToken == "" {
+		expiresAt := time.Now().UTC().Add(resetConfirmationTTL)
+		return &dto.ResetEnvironmentResponse{
+			ConfirmationToken: s.signResetToken(ctx, env.ID, expiresAt),
+			ExpiresAt:         &expiresAt,
+		}, nil
+	}
+
+	if err := s.verifyResetToken(ctx, env.ID, req.ConfirmationToken); err != nil {
+		return nil, err
+	}
+
+	reset := &environment.Reset{
+		ID:            types.GenerateUUID(),
+		EnvironmentID: env.ID,
+		ResetStatus:   types.ResetStatusPending,
+		StepsTotal:    len(environment.ResetSteps),
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+
+	if err := s.repo.CreateReset(ctx, reset); err != nil {
+		return nil, fmt.Errorf("failed to create environment reset: %w", err)
+	}
+
+	go s.runReset(context.WithoutCancel(ctx), reset)
+
+	return &dto.ResetEnvironmentResponse{Reset: reset}, nil
+}
+
+func (s *environmentService) GetEnvironmentReset(ctx context.Context, environmentID, resetID string) (*dto.EnvironmentResetResponse, error) {
+	reset, err := s.repo.GetReset(ctx, resetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment reset: %w", err)
+	}
+
+	if reset.EnvironmentID != environmentID {
+		return nil, fmt.Errorf("environment reset not found")
+	}
+
+	return &dto.EnvironmentResetResponse{Reset: reset}, nil
+}
+
+func (s *environmentService) runReset(ctx context.Context, reset *environment.Reset) {
+	// local copy so that the caller's response is not mutated concurrently
+	r := *reset
+	r.ResetStatus = types.ResetStatusProcessing
+	s.updateReset(ctx, &r)
+
+	for _, step := range environment.ResetSteps {
+		r.CurrentStep = step
+		s.updateReset(ctx, &r)
+
+		var err error
+		if step == "events" {
+			err = s.eventRepo.DeleteEvents(ctx)
+		} else {
+			var deleted int64
+			deleted, err = s.repo.DeleteResetStep(ctx, step)
+			r.RecordsDeleted += deleted
+		}
+
+		if err != nil {
+			s.logger.Errorw("environment reset failed",
+				"reset_id", r.ID,
+				"step", step,
+				"error", err,
+			)
+			now := time.Now().UTC()
+			r.ResetStatus = types.ResetStatusFailed
+			r.Error = err.Error()
+			r.CompletedAt = &now
+			s.updateReset(ctx, &r)
+			return
+		}
+
+		r.StepsCompleted++
+		s.updateReset(ctx, &r)
+	}
+
+	now := time.Now().UTC()
+	r.ResetStatus = types.ResetStatusCompleted
+	r.CompletedAt = &now
+	s.updateReset(ctx, &r)
+}
+
+func (s *environmentService) updateReset(ctx context.Context, reset *environment.Reset) {
+	reset.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateReset(ctx, reset); err != nil {
+		s.logger.Errorw("failed to update environment reset", "reset_id", reset.ID, "error", err)
+	}
+}
+
+// signResetToken returns "<expiry unix>.<hmac>" bound to the tenant, user and environment
+func (s *environmentService) signResetToken(ctx context.Context, environmentID string, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expiry + "." + s.resetTokenSignature(ctx, environmentID, expiry)
+}
+
+func (s *environmentService) verifyResetToken(ctx context.Context, environmentID, token string) error {
+	expiry, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return fmt.Errorf("invalid confirmation token")
+	}
+
+	expected := s.resetTokenSignature(ctx, environmentID, expiry)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("invalid confirmation token")
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().UTC().Unix() > expiresAt {
+		return fmt.Errorf("confirmation token expired")
+	}
+
+	return nil
+}
+
+func (s *environmentService) resetTokenSignature(ctx context.Context, environmentID, expiry string) string {
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write([]byte(strings.Join([]string{
+		"environment_reset",
+		types.GetTenantID(ctx),
+		types.GetUserID(ctx),
+		environmentID,
+		expiry,
+	}, ":")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvironmentServiceSuite struct {
+	suite.Suite
+	ctx        context.Context
+	service    EnvironmentService
+	envStore   *testutil.InMemoryEnvironmentStore
+	eventStore *testutil.InMemoryEventStore
+	sandbox    *dto.EnvironmentResponse
+}
+
+func TestEnvironmentService(t *testing.T) {
+	suite.Run(t, new(EnvironmentServiceSuite))
+}
+
+func (s *EnvironmentServiceSuite) SetupTest() {
+	s.ctx = testutil.SetupContext()
+	s.envStore = testutil.NewInMemoryEnvironmentStore()
+	s.eventStore = testutil.NewInMemoryEventStore()
+	s.service = NewEnvironmentService(
+		s.envStore,
+		s.eventStore,
+		&config.Configuration{Auth: config.AuthConfig{Secret: "test-secret"}},
+		logger.GetLogger(),
+	)
+
+	var err error
+	s.sandbox, err = s.service.CreateEnvironment(s.ctx, dto.CreateEnvironmentRequest{
+		Name: "QA",
+		Type: types.EnvironmentTypeSandbox,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *EnvironmentServiceSuite) TestResetRequiresConfirmation() {
+	resp, err := s.service.ResetEnvironment(s.ctx, s.sandbox.ID, dto.ResetEnvironmentRequest{})
+	s.Require().NoError(err)
+	s.NotEmpty(resp.ConfirmationToken)
+	s.Nil(resp.Reset)
+
+	_, err = s.service.ResetEnvironment(s.ctx, s.sandbox.ID, dto.ResetEnvironmentRequest{ConfirmationToken: "1.bad"})
+	s.Error(err)
+
+	// A token is bound to the environment it was issued for
+	other, err := s.service.CreateEnvironment(s.ctx, dto.CreateEnvironmentRequest{Name: "QA 2", Type: types.EnvironmentTypeSandbox})
+	s.Require().NoError(err)
+	_, err = s.service.ResetEnvironment(s.ctx, other.ID, dto.ResetEnvironmentRequest{ConfirmationToken: resp.ConfirmationToken})
+	s.Error(err)
+
+	s.Empty(s.envStore.GetDeletedSteps())
+}
+
+func (s *EnvironmentServiceSuite) TestResetRejectsNonSandbox() {
+	dev, err := s.service.CreateEnvironment(s.ctx, dto.CreateEnvironmentRequest{Name: "Dev", Type: types.EnvironmentTypeDevelopment})
+	s.Require().NoError(err)
+
+	_, err = s.service.ResetEnvironment(s.ctx, dev.ID, dto.ResetEnvironmentRequest{})
+	s.Error(err)
+
+	_, err = s.service.CreateEnvironment(s.ctx, dto.CreateEnvironmentRequest{Name: "Prod", Type: types.EnvironmentTypeProduction})
+	s.Require().NoError(err)
+
+	_, err = s.service.ResetEnvironment(s.ctx, s.sandbox.ID, dto.ResetEnvironmentRequest{})
+	s.Error(err)
+}
+
+func (s *EnvironmentServiceSuite) TestReset() {
+	s.Require().NoError(s.eventStore.InsertEvent(s.ctx, &events.Event{
+		ID:        "evt-1",
+		TenantID:  types.GetTenantID(s.ctx),
+		EventName: "api_call",
+		Timestamp: time.Now().UTC(),
+	}))
+
+	confirmation, err := s.service.ResetEnvironment(s.ctx, s.sandbox.ID, dto.ResetEnvironmentRequest{})
+	s.Require().NoError(err)
+
+	resp, err := s.service.ResetEnvironment(s.ctx, s.sandbox.ID, dto.ResetEnvironmentRequest{
+		ConfirmationToken: confirmation.ConfirmationToken,
+	})
+	s.Require().NoError(err)
+	s.Require().NotNil(resp.Reset)
+
+	s.Eventually(func() bool {
+		reset, err := s.service.GetEnvironmentReset(s.ctx, s.sandbox.ID, resp.Reset.ID)
+		return err == nil && reset.ResetStatus == types.ResetStatusCompleted
+	}, time.Second, 10*time.Millisecond)
+
+	reset, err := s.service.GetEnvironmentReset(s.ctx, s.sandbox.ID, resp.Reset.ID)
+	s.Require().NoError(err)
+	s.Equal(len(environment.ResetSteps), reset.StepsCompleted)
+	s.Equal([]string{"invoices", "wallets", "subscriptions", "customers"}, s.envStore.GetDeletedSteps())
+
+	remaining, err := s.eventStore.GetEvents(s.ctx, &events.GetEventsParams{EventName: "api_call", PageSize: 10})
+	s.Require().NoError(err)
+	s.Empty(remaining)
+}
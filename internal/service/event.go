@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -10,6 +12,8 @@ import (
 	"time"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/config"
 	"github.com/flexprice/flexprice/internal/domain/errors"
 	"github.com/flexprice/flexprice/internal/domain/events"
 	"github.com/flexprice/flexprice/internal/domain/meter"
@@ -26,28 +30,67 @@ type EventService interface {
 	GetUsageByMeter(ctx context.Context, getUsageByMeterRequest *dto.GetUsageByMeterRequest) (*events.AggregationResult, error)
 	GetUsageByMeterWithFilters(ctx context.Context, req *dto.GetUsageByMeterRequest, filterGroups map[string]map[string][]string) ([]*events.AggregationResult, error)
 	GetEvents(ctx context.Context, req *dto.GetEventsRequest) (*dto.GetEventsResponse, error)
+
+	// ExportEvents hands the selected events to write in batches, oldest first,
+	// so exports of any size are served with bounded memory. Exporting stops at
+	// the first error returned by write
+	ExportEvents(ctx context.Context, req *dto.ExportEventsRequest, write func([]*dto.ExportedEvent) error) error
 }
 
+// ErrInvalidExportCursor is returned when an export is resumed with a cursor
+// that was not issued by an export
+var ErrInvalidExportCursor = stderrors.New("invalid export cursor")
+
+// exportBatchSize is the number of events read from the store at once while
+// exporting
+const exportBatchSize = 1000
+
 type eventService struct {
-	producer  kafka.MessageProducer
-	eventRepo events.Repository
-	meterRepo meter.Repository
+	cfg        *config.Configuration
+	producer   kafka.MessageProducer
+	eventRepo  events.Repository
+	meterRepo  meter.Repository
+	rollupRepo events.RollupRepository
+
+	// schemaService checks ingested events against the schema of their event
+	// name, events are not checked without it
+	schemaService EventSchemaService
+
+	// featureFlagService is optional, the flags keep their default without it
+	featureFlagService FeatureFlagService
+	// budgetService blocks the usage of the customers over their budget, no
+	// usage is blocked without it
+	budgetService BudgetService
+	// clock is the end of the windows left open, the wall clock when nil
+	clock     *clock.Clock
 	validator *validator.Validate
 	logger    *logger.Logger
 }
 
 func NewEventService(
+	cfg *config.Configuration,
 	producer kafka.MessageProducer,
 	eventRepo events.Repository,
 	meterRepo meter.Repository,
+	rollupRepo events.RollupRepository,
+	schemaService EventSchemaService,
+	featureFlagService FeatureFlagService,
+	budgetService BudgetService,
+	clock *clock.Clock,
 	logger *logger.Logger,
 ) EventService {
 	return &eventService{
-		producer:  producer,
-		eventRepo: eventRepo,
-		meterRepo: meterRepo,
-		validator: validator.New(),
-		logger:    logger,
+		cfg:                cfg,
+		producer:           producer,
+		eventRepo:          eventRepo,
+		meterRepo:          meterRepo,
+		rollupRepo:         rollupRepo,
+		schemaService:      schemaService,
+		featureFlagService: featureFlagService,
+		budgetService:      budgetService,
+		clock:              clock,
+		validator:          validator.New(),
+		logger:             logger,
 	}
 }
 
@@ -57,6 +100,14 @@ func (s *eventService) CreateEvent(ctx context.Context, createEventRequest *dto.
 	}
 
 	tenantID := types.GetTenantID(ctx)
+	if createEventRequest.EventID == "" && createEventRequest.IdempotencyKey != "" {
+		if createEventRequest.Timestamp.IsZero() {
+			return fmt.Errorf("validation failed: timestamp is required with an idempotency_key")
+		}
+		createEventRequest.EventID = events.DeterministicEventID(tenantID,
+			createEventRequest.IdempotencyKey, createEventRequest.Timestamp)
+	}
+
 	event := events.NewEvent(
 		createEventRequest.EventName,
 		tenantID,
@@ -68,16 +119,34 @@ func (s *eventService) CreateEvent(ctx context.Context, createEventRequest *dto.
 		createEventRequest.Source,
 	)
 
+	createEventRequest.EventID = event.ID
+
+	if s.schemaService != nil {
+		if err := s.schemaService.ValidateEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	if s.budgetService != nil {
+		if err := s.budgetService.CheckUsage(ctx, event.ExternalCustomerID, event.EventName); err != nil {
+			return err
+		}
+	}
+
+	return publishEvent(s.producer, s.cfg.Kafka, event)
+}
+
+// publishEvent hands an event to the consumer that stores it, through the
+// topic of its lane
+func publishEvent(producer kafka.MessageProducer, cfg config.KafkaConfig, event *events.Event) error {
 	payload, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
-	if err := s.producer.PublishWithID("events", payload, event.ID); err != nil {
+	if err := producer.PublishWithID(cfg.EventTopic(event.TenantID, event.EventName), payload, event.ID); err != nil {
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
-
-	createEventRequest.EventID = event.ID
 	return nil
 }
 
@@ -101,49 +170,314 @@ func (s *eventService) GetUsageByMeter(ctx context.Context, req *dto.GetUsageByM
 		return nil, errors.NewAttributeNotFoundError("meter")
 	}
 
+	if m.IsDerived() {
+		return s.getDerivedMeterUsage(ctx, m, req)
+	}
+
+	// The request is shared with the callers, the window is narrowed on a copy.
+	// A window left open ends at the time of the clock
+	usageReq := *req
+	if usageReq.EndTime.IsZero() {
+		usageReq.EndTime = s.clock.Now()
+	}
+
+	resetPeriodStart, resetPeriodEnd, err := resetPeriod(m, &usageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Usage resets at the start of the reset period containing the end of the
+	// requested window, which may be later than the billing period start
+	if m.ResetUsage.IsCalendar() && usageReq.StartTime.Before(resetPeriodStart) {
+		usageReq.StartTime = resetPeriodStart
+	}
+
 	getUsageRequest := dto.GetUsageRequest{
-		ExternalCustomerID: req.ExternalCustomerID,
-		CustomerID:         req.CustomerID,
+		ExternalCustomerID: usageReq.ExternalCustomerID,
+		CustomerID:         usageReq.CustomerID,
 		EventName:          m.EventName,
 		PropertyName:       m.Aggregation.Field,
 		AggregationType:    string(m.Aggregation.Type),
-		StartTime:          req.StartTime,
-		WindowSize:         req.WindowSize,
-		EndTime:            req.EndTime,
-		Filters:            req.Filters,
+		StartTime:          usageReq.StartTime,
+		WindowSize:         usageReq.WindowSize,
+		EndTime:            usageReq.EndTime,
+		Filters:            usageReq.Filters,
+		Precision:          m.Aggregation.Precision,
+		Percentile:         m.Aggregation.Percentile,
 	}
 
-	usage, err := s.GetUsage(ctx, &getUsageRequest)
+	usage, err := s.getVersionedUsage(ctx, m, getUsageRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate usage: %w", err)
 	}
 
-	if m.ResetUsage == types.ResetUsageNever {
+	if m.ResetUsage == types.ResetUsageNever && !m.Aggregation.Type.IsDecomposable() {
+		// Unique counts and percentiles of the usage before and during the window
+		// do not add up, so the total is computed over all the usage at once
+		getTotalUsageRequest := getUsageRequest
+		getTotalUsageRequest.StartTime = time.Time{}
+		getTotalUsageRequest.WindowSize = ""
+
+		totalUsage, err := s.getVersionedUsage(ctx, m, getTotalUsageRequest)
+		if err != nil {
+			return nil, fmt.Errorf("calculate total usage: %w", err)
+		}
+
+		usage.Value = totalUsage.Value
+	} else if m.ResetUsage == types.ResetUsageNever {
 		getHistoricUsageRequest := getUsageRequest
 		getHistoricUsageRequest.StartTime = time.Time{}
-		getHistoricUsageRequest.EndTime = req.StartTime
+		getHistoricUsageRequest.EndTime = usageReq.StartTime
 		getHistoricUsageRequest.WindowSize = ""
 
-		historicUsage, err := s.GetUsage(ctx, &getHistoricUsageRequest)
+		historicUsage, err := s.getVersionedUsage(ctx, m, getHistoricUsageRequest)
 		if err != nil {
 			return nil, fmt.Errorf("calculate before usage: %w", err)
 		}
 
-		return s.combineResults(historicUsage, usage, m), nil
+		usage = s.combineResults(historicUsage, usage, m)
 	}
 
+	usage.ResetPeriodStart = &resetPeriodStart
+	usage.ResetPeriodEnd = &resetPeriodEnd
 	return usage, nil
 }
 
+// resetPeriod returns the period since which the usage of the meter counts up,
+// and when it is next reset:
+//   - calendar resets count from the start of the reset period containing the
+//     end of the window until the next reset
+//   - billing period resets count over the requested window
+//   - usage which never resets counts from the creation of the meter, up to the
+//     end of the window
+func resetPeriod(m *meter.Meter, req *dto.GetUsageByMeterRequest) (time.Time, time.Time, error) {
+	switch {
+	case m.ResetUsage.IsCalendar():
+		return resetPeriodForUsage(m.ResetUsage, req)
+	case m.ResetUsage == types.ResetUsageNever:
+		return m.CreatedAt, req.EndTime, nil
+	default:
+		return req.StartTime, req.EndTime, nil
+	}
+}
+
+// getDerivedMeterUsage returns the usage of a derived meter, evaluated over the
+// usage of its meters for the same request. Each of them resets its usage on
+// its own schedule
+func (s *eventService) getDerivedMeterUsage(ctx context.Context, m *meter.Meter, req *dto.GetUsageByMeterRequest) (*events.AggregationResult, error) {
+	results, err := getDerivedUsage(m, func(meterID string) ([]*events.AggregationResult, error) {
+		meterReq := *req
+		meterReq.MeterID = meterID
+
+		usage, err := s.GetUsageByMeter(ctx, &meterReq)
+		if err != nil {
+			return nil, err
+		}
+		return []*events.AggregationResult{usage}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return &events.AggregationResult{Type: types.AggregationFormula, Value: decimal.Zero}, nil
+	}
+	return results[0], nil
+}
+
+// getVersionedUsage returns the usage of a meter with each part of the requested
+// window aggregated with the version of the meter effective over it
+func (s *eventService) getVersionedUsage(ctx context.Context, m *meter.Meter, req dto.GetUsageRequest) (*events.AggregationResult, error) {
+	segments, err := getMeterSegments(ctx, s.meterRepo, m, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := getSegmentedUsage(segments, func(segment meter.Segment, aggregation types.AggregationType) ([]*events.AggregationResult, error) {
+		segmentReq := req
+		segmentReq.StartTime = segment.Start
+		segmentReq.EndTime = segment.End
+		segmentReq.PropertyName = segment.Aggregation.Field
+		segmentReq.AggregationType = string(aggregation)
+		segmentReq.Precision = segment.Aggregation.Precision
+		segmentReq.Percentile = segment.Aggregation.Percentile
+
+		usage, err := s.getMeterUsage(ctx, m.ID, &segmentReq)
+		if err != nil {
+			return nil, err
+		}
+		return []*events.AggregationResult{usage}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// getMeterUsage returns the usage of a meter, reading the windows rolled up
+// before the watermark of the meter from the rollups. Windowed and filtered
+// usage and percentiles, which are not rolled up, are always read from the raw
+// events, as is all the usage of the tenants with rollup queries turned off
+func (s *eventService) getMeterUsage(ctx context.Context, meterID string, req *dto.GetUsageRequest) (*events.AggregationResult, error) {
+	if s.rollupRepo == nil || req.WindowSize != "" || len(req.Filters) > 0 ||
+		types.AggregationType(strings.ToUpper(req.AggregationType)) == types.AggregationPercentile ||
+		!featureEnabled(ctx, s.featureFlagService, types.FeatureFlagRollupUsageQueries) {
+		return s.GetUsage(ctx, req)
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	params := req.ToUsageParams()
+
+	watermark, err := s.rollupRepo.GetRollupWatermark(ctx, meterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollup watermark: %w", err)
+	}
+
+	read := planRollupRead(params.StartTime, params.EndTime, watermark)
+	if read == nil {
+		return s.GetUsage(ctx, req)
+	}
+
+	if params.AggregationType == types.AggregationCountUnique {
+		return s.getRolledUpUniqueCount(ctx, meterID, params, read)
+	}
+
+	total, err := s.rollupRepo.GetRolledUpUsage(ctx, &events.RolledUpUsageParams{
+		MeterID:            meterID,
+		ExternalCustomerID: params.ExternalCustomerID,
+		CustomerID:         params.CustomerID,
+		Ranges:             read.ranges,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rolled up usage: %w", err)
+	}
+
+	for _, r := range read.raw {
+		raw, err := s.getRawTotals(ctx, params, r)
+		if err != nil {
+			return nil, err
+		}
+		total.EventCount += raw.EventCount
+		total.ValueSum = total.ValueSum.Add(raw.ValueSum)
+	}
+
+	result := &events.AggregationResult{
+		EventName: params.EventName,
+		Type:      params.AggregationType,
+	}
+	switch params.AggregationType {
+	case types.AggregationCount:
+		result.Value = decimal.NewFromUint64(total.EventCount)
+	case types.AggregationSum:
+		result.Value = total.ValueSum
+	case types.AggregationAvg:
+		if total.EventCount > 0 {
+			result.Value = total.ValueSum.Div(decimal.NewFromUint64(total.EventCount))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported aggregation type: %s", params.AggregationType)
+	}
+
+	return result, nil
+}
+
+// getRolledUpUniqueCount counts the distinct values of the field over the
+// rolled up windows and the raw edges of the read together
+func (s *eventService) getRolledUpUniqueCount(ctx context.Context, meterID string, params *events.UsageParams, read *rollupRead) (*events.AggregationResult, error) {
+	raw := make([]events.TimeRange, len(read.raw))
+	for i, r := range read.raw {
+		raw[i] = events.TimeRange{StartTime: r.start, EndTime: r.end}
+	}
+
+	count, err := s.rollupRepo.GetUniqueCount(ctx, &events.UniqueCountParams{
+		MeterID:            meterID,
+		EventName:          params.EventName,
+		PropertyName:       params.PropertyName,
+		Precision:          params.Precision,
+		ExternalCustomerID: params.ExternalCustomerID,
+		CustomerID:         params.CustomerID,
+		Ranges:             read.ranges,
+		Raw:                raw,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rolled up unique count: %w", err)
+	}
+
+	return &events.AggregationResult{
+		EventName: params.EventName,
+		Type:      params.AggregationType,
+		Value:     decimal.NewFromUint64(count),
+	}, nil
+}
+
+// getRawTotals reads the number of events and the sum of the field over a time
+// range from the raw events, only what the aggregation of params needs
+func (s *eventService) getRawTotals(ctx context.Context, params *events.UsageParams, r timeRange) (*events.RolledUpUsage, error) {
+	totals := &events.RolledUpUsage{ValueSum: decimal.Zero}
+
+	rangeParams := *params
+	rangeParams.StartTime = r.start
+	rangeParams.EndTime = r.end
+
+	if params.AggregationType != types.AggregationSum {
+		countParams := rangeParams
+		countParams.AggregationType = types.AggregationCount
+
+		count, err := s.eventRepo.GetUsage(ctx, &countParams)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get usage: %w", err)
+		}
+		totals.EventCount = uint64(count.Value.IntPart())
+	}
+
+	if params.AggregationType != types.AggregationCount {
+		sumParams := rangeParams
+		sumParams.AggregationType = types.AggregationSum
+
+		sum, err := s.eventRepo.GetUsage(ctx, &sumParams)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get usage: %w", err)
+		}
+		totals.ValueSum = sum.Value
+	}
+
+	return totals, nil
+}
+
+// resetPeriodForUsage returns the calendar reset period containing the last
+// instant of the requested window
+func resetPeriodForUsage(resetUsage types.ResetUsage, req *dto.GetUsageByMeterRequest) (time.Time, time.Time, error) {
+	loc := time.UTC
+	if req.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.Timezone); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid timezone %s: %w", req.Timezone, err)
+		}
+	}
+
+	anchor := req.StartTime
+	if req.ResetAnchor != nil {
+		anchor = *req.ResetAnchor
+	}
+
+	return types.ResetPeriod(resetUsage, req.EndTime.Add(-time.Nanosecond), anchor, loc)
+}
+
 func (s *eventService) GetUsageByMeterWithFilters(ctx context.Context, req *dto.GetUsageByMeterRequest, filterGroups map[string]map[string][]string) ([]*events.AggregationResult, error) {
 	m, err := s.meterRepo.GetMeter(ctx, req.MeterID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get meter: %w", err)
 	}
 
-	meterFilters := make(map[string][]string)
-	for _, filter := range m.Filters {
-		meterFilters[filter.Key] = filter.Values
+	if m.IsDerived() {
+		return getDerivedUsage(m, func(meterID string) ([]*events.AggregationResult, error) {
+			meterReq := *req
+			meterReq.MeterID = meterID
+			return s.GetUsageByMeterWithFilters(ctx, &meterReq, filterGroups)
+		})
 	}
 
 	// Extract and sort priceIDs for stable ordering
@@ -164,20 +498,27 @@ func (s *eventService) GetUsageByMeterWithFilters(ctx context.Context, req *dto.
 		})
 	}
 
-	params := &events.UsageWithFiltersParams{
-		UsageParams: &events.UsageParams{
-			EventName:          m.EventName,
-			PropertyName:       m.Aggregation.Field,
-			AggregationType:    m.Aggregation.Type,
-			ExternalCustomerID: req.ExternalCustomerID,
-			StartTime:          req.StartTime,
-			EndTime:            req.EndTime,
-			Filters:            meterFilters,
-		},
-		FilterGroups: prioritizedGroups,
+	segments, err := getMeterSegments(ctx, s.meterRepo, m, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
 	}
 
-	results, err := s.eventRepo.GetUsageWithFilters(ctx, params)
+	results, err := getSegmentedUsage(segments, func(segment meter.Segment, aggregation types.AggregationType) ([]*events.AggregationResult, error) {
+		return s.eventRepo.GetUsageWithFilters(ctx, &events.UsageWithFiltersParams{
+			UsageParams: &events.UsageParams{
+				EventName:          m.EventName,
+				PropertyName:       segment.Aggregation.Field,
+				AggregationType:    aggregation,
+				ExternalCustomerID: req.ExternalCustomerID,
+				StartTime:          segment.Start,
+				EndTime:            segment.End,
+				Filters:            meterFilters(segment.Filters),
+				Precision:          segment.Aggregation.Precision,
+				Percentile:         segment.Aggregation.Percentile,
+			},
+			FilterGroups: prioritizedGroups,
+		})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get usage with filters: %w", err)
 	}
@@ -226,6 +567,14 @@ func (s *eventService) GetEvents(ctx context.Context, req *dto.GetEventsRequest)
 		return nil, fmt.Errorf("invalid after cursor key: %w", err)
 	}
 
+	if req.Cursor != "" {
+		cursor, err := types.DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		iterLast = &events.EventIterator{Timestamp: cursor.Time, ID: cursor.ID}
+	}
+
 	eventsList, err := s.eventRepo.GetEvents(ctx, &events.GetEventsParams{
 		ExternalCustomerID: req.ExternalCustomerID,
 		EventName:          req.EventName,
@@ -256,6 +605,7 @@ func (s *eventService) GetEvents(ctx context.Context, req *dto.GetEventsRequest)
 
 		if hasMore {
 			response.IterLastKey = createEventIteratorKey(lastEvent.Timestamp, lastEvent.ID)
+			response.NextCursor = types.EncodeCursor(lastEvent.Timestamp, lastEvent.ID)
 		}
 	}
 
@@ -274,6 +624,84 @@ func (s *eventService) GetEvents(ctx context.Context, req *dto.GetEventsRequest)
 	return response, nil
 }
 
+func (s *eventService) ExportEvents(ctx context.Context, req *dto.ExportEventsRequest, write func([]*dto.ExportedEvent) error) error {
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	after, err := parseExportCursor(req.Cursor)
+	if err != nil {
+		return err
+	}
+
+	for {
+		batch, err := s.eventRepo.ExportEvents(ctx, &events.ExportEventsParams{
+			ExternalCustomerID: req.ExternalCustomerID,
+			EventName:          req.EventName,
+			StartTime:          req.StartTime,
+			EndTime:            req.EndTime,
+			After:              after,
+			Limit:              exportBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("export events: %w", err)
+		}
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		exported := make([]*dto.ExportedEvent, len(batch))
+		for i, event := range batch {
+			exported[i] = &dto.ExportedEvent{
+				Event: dto.Event{
+					ID:                 event.ID,
+					ExternalCustomerID: event.ExternalCustomerID,
+					CustomerID:         event.CustomerID,
+					EventName:          event.EventName,
+					Timestamp:          event.Timestamp,
+					Properties:         event.Properties,
+					Source:             event.Source,
+				},
+				Cursor: createExportCursor(event.Timestamp, event.ID),
+			}
+		}
+
+		if err := write(exported); err != nil {
+			return err
+		}
+
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+
+		last := batch[len(batch)-1]
+		after = &events.EventIterator{Timestamp: last.Timestamp, ID: last.ID}
+	}
+}
+
+// createExportCursor returns the opaque token resuming an export after the event
+func createExportCursor(timestamp time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createEventIteratorKey(timestamp, id)))
+}
+
+func parseExportCursor(cursor string) (*events.EventIterator, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidExportCursor
+	}
+
+	after, err := parseEventIteratorToStruct(string(key))
+	if err != nil {
+		return nil, ErrInvalidExportCursor
+	}
+	return after, nil
+}
+
 func parseEventIteratorToStruct(key string) (*events.EventIterator, error) {
 	if key == "" {
 		return nil, nil
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/task"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/storage"
+	"github.com/flexprice/flexprice/internal/types"
+	"golang.org/x/time/rate"
+)
+
+// ErrEventBackfillNotFound is returned when the task is not an event backfill
+var ErrEventBackfillNotFound = errors.New("event backfill not found")
+
+// eventBackfillSource is the source set on backfilled events without one
+const eventBackfillSource = "backfill"
+
+// EventBackfillService ingests historical events with their original
+// timestamps. Backfills are tracked as tasks and write to the events store
+// directly, so they neither compete with nor delay the live consumer
+type EventBackfillService interface {
+	// CreateBackfill starts a backfill in the background and returns the task
+	// tracking it
+	CreateBackfill(ctx context.Context, req dto.CreateEventBackfillRequest) (*dto.TaskResponse, error)
+	GetBackfill(ctx context.Context, id string) (*dto.TaskResponse, error)
+}
+
+type eventBackfillService struct {
+	cfg        config.EventBackfillConfig
+	exportCfg  config.ExportConfig
+	taskRepo   task.Repository
+	eventRepo  events.Repository
+	meterRepo  meter.Repository
+	rollupRepo events.RollupRepository
+	store      storage.Store
+
+	// limiter caps the events written by all the backfills of the process
+	limiter *rate.Limiter
+	logger  *logger.Logger
+}
+
+func NewEventBackfillService(
+	cfg *config.Configuration,
+	taskRepo task.Repository,
+	eventRepo events.Repository,
+	meterRepo meter.Repository,
+	rollupRepo events.RollupRepository,
+	store storage.Store,
+	logger *logger.Logger,
+) EventBackfillService {
+	backfillCfg := cfg.EventBackfill
+	if backfillCfg.EventsPerSecond <= 0 {
+		backfillCfg.EventsPerSecond = 1000
+	}
+	if backfillCfg.BatchSize <= 0 {
+		backfillCfg.BatchSize = 500
+	}
+	if backfillCfg.MaxInlineEvents <= 0 {
+		backfillCfg.MaxInlineEvents = 1000
+	}
+
+	return &eventBackfillService{
+		cfg:        backfillCfg,
+		exportCfg:  cfg.Export,
+		taskRepo:   taskRepo,
+		eventRepo:  eventRepo,
+		meterRepo:  meterRepo,
+		rollupRepo: rollupRepo,
+		store:      store,
+		limiter:    rate.NewLimiter(rate.Limit(backfillCfg.EventsPerSecond), backfillCfg.BatchSize),
+		logger:     logger,
+	}
+}
+
+func (s *eventBackfillService) CreateBackfill(ctx context.Context, req dto.CreateEventBackfillRequest) (*dto.TaskResponse, error) {
+	if err := req.Validate(s.cfg.MaxInlineEvents); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if req.S3Path != "" && s.store == nil {
+		return nil, fmt.Errorf("backfills from object storage are not configured")
+	}
+
+	t := req.ToTask(ctx)
+	if len(req.Events) > 0 {
+		t.TotalRows = len(req.Events)
+	}
+	if err := s.taskRepo.Create(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to create backfill: %w", err)
+	}
+
+	// Like the other tasks the backfill outlives the request and works on its
+	// own copy of the task
+	job := *t
+	go s.runBackfill(context.WithoutCancel(ctx), &job, req.Events)
+
+	return &dto.TaskResponse{Task: t}, nil
+}
+
+func (s *eventBackfillService) GetBackfill(ctx context.Context, id string) (*dto.TaskResponse, error) {
+	t, err := s.taskRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill: %w", err)
+	}
+	if t.Type != types.TaskTypeEventBackfill {
+		return nil, ErrEventBackfillNotFound
+	}
+
+	return &dto.TaskResponse{Task: t}, nil
+}
+
+func (s *eventBackfillService) runBackfill(ctx context.Context, t *task.Task, inline []dto.IngestEventRequest) {
+	setTaskStatus(ctx, s.taskRepo, s.logger, t, types.TaskStatusProcessing, nil)
+
+	run := &backfillRun{
+		service:    s,
+		task:       t,
+		eventNames: make(map[string]bool),
+	}
+	if err := run.ingest(ctx, inline); err != nil {
+		s.logger.Errorw("event backfill failed", "task_id", t.ID, "error", err)
+		setTaskStatus(ctx, s.taskRepo, s.logger, t, types.TaskStatusFailed, err)
+		return
+	}
+
+	// Usage is read from the rollups for the windows already rolled up, which
+	// do not hold the backfilled events yet
+	if s.rollupRepo != nil && len(run.eventNames) > 0 {
+		rollUpAgain(ctx, s.rollupRepo, s.meterRepo, s.logger, run.eventNames, run.first, run.last.Add(time.Nanosecond))
+	}
+
+	setTaskStatus(ctx, s.taskRepo, s.logger, t, types.TaskStatusCompleted, nil)
+}
+
+// backfillRun is the state of a running backfill
+type backfillRun struct {
+	service *eventBackfillService
+	task    *task.Task
+
+	// read is the number of rows read so far
+	read     int
+	batch    []*events.Event
+	rows     []int
+	failures []taskRowError
+
+	// eventNames and the first and last timestamps written tell which rollups
+	// to recompute
+	eventNames  map[string]bool
+	first, last time.Time
+}
+
+// ingest writes the inline events or, when there are none, the events of the
+// file of the task. The file is streamed so that its size is not bounded
+func (r *backfillRun) ingest(ctx context.Context, inline []dto.IngestEventRequest) error {
+	if len(inline) > 0 {
+		for i := range inline {
+			if err := r.add(ctx, i+1, &inline[i], nil); err != nil {
+				return err
+			}
+		}
+		return r.finish(ctx)
+	}
+
+	bucket, key, err := storage.ParseS3Path(r.task.FileURL)
+	if err != nil {
+		return err
+	}
+	file, err := r.service.store.Download(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(file)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return fmt.Errorf("file must be a json array of events")
+	}
+
+	for row := 1; dec.More(); row++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("failed to read event %d: %w", row, err)
+		}
+
+		var req dto.IngestEventRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			err = r.add(ctx, row, nil, fmt.Errorf("invalid row: %w", err))
+		} else {
+			err = r.add(ctx, row, &req, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return r.finish(ctx)
+}
+
+// add queues the event of a row and writes the queue once it is a full batch
+func (r *backfillRun) add(ctx context.Context, row int, req *dto.IngestEventRequest, parseErr error) error {
+	r.read = row
+	if parseErr != nil {
+		r.failures = append(r.failures, taskRowError{Row: row, Error: parseErr.Error()})
+		return nil
+	}
+
+	event, err := r.toEvent(ctx, req)
+	if err != nil {
+		r.failures = append(r.failures, taskRowError{Row: row, ID: req.EventID, Error: err.Error()})
+		return nil
+	}
+
+	r.batch = append(r.batch, event)
+	r.rows = append(r.rows, row)
+	if len(r.batch) >= r.service.cfg.BatchSize {
+		return r.flush(ctx)
+	}
+	return nil
+}
+
+func (r *backfillRun) toEvent(ctx context.Context, req *dto.IngestEventRequest) (*events.Event, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+	if req.Timestamp.IsZero() {
+		return nil, fmt.Errorf("invalid event: timestamp is required")
+	}
+	if req.Timestamp.Before(*r.task.RangeStart) || !req.Timestamp.Before(*r.task.RangeEnd) {
+		return nil, fmt.Errorf("timestamp %s is outside of the backfill range", req.Timestamp.Format(time.RFC3339))
+	}
+
+	source := req.Source
+	if source == "" {
+		source = eventBackfillSource
+	}
+
+	return events.NewEvent(
+		req.EventName,
+		types.GetTenantID(ctx),
+		req.ExternalCustomerID,
+		req.Properties,
+		req.Timestamp,
+		req.EventID,
+		req.CustomerID,
+		source,
+	), nil
+}
+
+// flush writes the queued events within the rate limit and saves the progress
+// of the task
+func (r *backfillRun) flush(ctx context.Context) error {
+	if len(r.batch) > 0 {
+		if err := r.service.limiter.WaitN(ctx, len(r.batch)); err != nil {
+			return err
+		}
+
+		if err := r.service.eventRepo.InsertEvents(ctx, r.batch); err != nil {
+			for i, event := range r.batch {
+				r.failures = append(r.failures, taskRowError{Row: r.rows[i], ID: event.ID, Error: err.Error()})
+			}
+		} else {
+			for _, event := range r.batch {
+				r.eventNames[event.EventName] = true
+				if r.first.IsZero() || event.Timestamp.Before(r.first) {
+					r.first = event.Timestamp
+				}
+				if event.Timestamp.After(r.last) {
+					r.last = event.Timestamp
+				}
+			}
+		}
+		r.batch, r.rows = r.batch[:0], r.rows[:0]
+	}
+
+	r.task.ProcessedRows = r.read
+	r.task.FailedRows = len(r.failures)
+	r.task.SuccessfulRows = r.read - len(r.failures)
+	updateTask(ctx, r.service.taskRepo, r.service.logger, r.task)
+	return nil
+}
+
+// finish writes the last batch and the report of the rows which failed
+func (r *backfillRun) finish(ctx context.Context) error {
+	r.task.TotalRows = r.read
+	if err := r.flush(ctx); err != nil {
+		return err
+	}
+
+	if len(r.failures) == 0 || r.service.store == nil {
+		return nil
+	}
+	return uploadTaskErrorReport(ctx, r.service.store, r.service.exportCfg.Prefix, r.task, "event_id", r.failures)
+}
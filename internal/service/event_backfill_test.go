@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBackfillService(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	eventStore := testutil.NewInMemoryEventStore()
+	objectStore := testutil.NewInMemoryObjectStore()
+	cfg := &config.Configuration{
+		Export:        config.ExportConfig{Prefix: "exports"},
+		EventBackfill: config.EventBackfillConfig{EventsPerSecond: 1000, BatchSize: 2, MaxInlineEvents: 10},
+	}
+	svc := NewEventBackfillService(cfg, testutil.NewInMemoryTaskStore(), eventStore,
+		testutil.NewInMemoryMeterStore(), nil, objectStore, logger.GetLogger())
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	waitForBackfill := func(id string) *dto.TaskResponse {
+		var resp *dto.TaskResponse
+		require.Eventually(t, func() bool {
+			var err error
+			resp, err = svc.GetBackfill(ctx, id)
+			require.NoError(t, err)
+			return resp.TaskStatus == types.TaskStatusCompleted || resp.TaskStatus == types.TaskStatusFailed
+		}, 5*time.Second, 10*time.Millisecond)
+		return resp
+	}
+
+	usage := func(customerID string) decimal.Decimal {
+		result, err := eventStore.GetUsage(ctx, &events.UsageParams{
+			ExternalCustomerID: customerID,
+			EventName:          "api_request",
+			AggregationType:    types.AggregationCount,
+			StartTime:          start,
+			EndTime:            end,
+		})
+		require.NoError(t, err)
+		return result.Value
+	}
+
+	t.Run("backfills inline events with their original timestamps", func(t *testing.T) {
+		created, err := svc.CreateBackfill(ctx, dto.CreateEventBackfillRequest{
+			StartTime: start,
+			EndTime:   end,
+			Events: []dto.IngestEventRequest{
+				{EventID: "evt_1", EventName: "api_request", ExternalCustomerID: "acme", Timestamp: start.Add(time.Hour)},
+				{EventID: "evt_2", EventName: "api_request", ExternalCustomerID: "acme", Timestamp: start.Add(2 * time.Hour)},
+				{EventID: "evt_3", EventName: "api_request", ExternalCustomerID: "acme", Timestamp: end.Add(time.Hour)},
+				{EventID: "evt_4", EventName: "api_request", Timestamp: start.Add(3 * time.Hour)},
+				{EventID: "evt_5", EventName: "api_request", ExternalCustomerID: "acme", Timestamp: start.Add(4 * time.Hour)},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, types.TaskTypeEventBackfill, created.Type)
+
+		resp := waitForBackfill(created.ID)
+		assert.Equal(t, types.TaskStatusCompleted, resp.TaskStatus)
+		assert.Equal(t, 5, resp.TotalRows)
+		assert.Equal(t, 5, resp.ProcessedRows)
+		assert.Equal(t, 3, resp.SuccessfulRows)
+		assert.Equal(t, 2, resp.FailedRows)
+		require.NotEmpty(t, resp.ErrorReportKey)
+
+		report, ok := objectStore.Object(resp.ErrorReportKey)
+		require.True(t, ok)
+		assert.Contains(t, string(report), "3,evt_3,timestamp")
+		assert.Contains(t, string(report), "4,evt_4,invalid event")
+
+		assert.True(t, decimal.NewFromInt(3).Equal(usage("acme")))
+	})
+
+	t.Run("backfills the events of a file in object storage", func(t *testing.T) {
+		file := `[
+			{"event_id": "evt_file_1", "event_name": "api_request", "external_customer_id": "globex", "timestamp": "2024-01-10T00:00:00Z"},
+			{"event_id": "evt_file_2", "event_name": "api_request", "external_customer_id": "globex", "timestamp": "2024-01-11T00:00:00Z"},
+			{"event_id": "evt_file_3", "event_name": "api_request", "external_customer_id": "globex", "timestamp": "2024-01-12T00:00:00Z"}
+		]`
+		require.NoError(t, objectStore.Upload(ctx, "archive/events.json", bytes.NewReader([]byte(file)), "application/json"))
+
+		created, err := svc.CreateBackfill(ctx, dto.CreateEventBackfillRequest{
+			StartTime: start,
+			EndTime:   end,
+			S3Path:    "s3://usage-archive/archive/events.json",
+		})
+		require.NoError(t, err)
+
+		resp := waitForBackfill(created.ID)
+		assert.Equal(t, types.TaskStatusCompleted, resp.TaskStatus)
+		assert.Equal(t, 3, resp.SuccessfulRows)
+		assert.Empty(t, resp.ErrorReportKey)
+
+		assert.True(t, decimal.NewFromInt(3).Equal(usage("globex")))
+	})
+
+	t.Run("fails a backfill of a file which is not an array of events", func(t *testing.T) {
+		require.NoError(t, objectStore.Upload(ctx, "archive/broken.json", strings.NewReader(`{"event_name": "api_request"}`), "application/json"))
+
+		created, err := svc.CreateBackfill(ctx, dto.CreateEventBackfillRequest{
+			StartTime: start,
+			EndTime:   end,
+			S3Path:    "s3://usage-archive/archive/broken.json",
+		})
+		require.NoError(t, err)
+
+		resp := waitForBackfill(created.ID)
+		assert.Equal(t, types.TaskStatusFailed, resp.TaskStatus)
+		assert.Contains(t, resp.Error, "json array")
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		_, err := svc.CreateBackfill(ctx, dto.CreateEventBackfillRequest{StartTime: start, EndTime: end})
+		assert.Error(t, err, "events or an s3 path are required")
+
+		_, err = svc.CreateBackfill(ctx, dto.CreateEventBackfillRequest{
+			StartTime: start,
+			EndTime:   time.Now().Add(time.Hour),
+			S3Path:    "s3://usage-archive/archive/events.json",
+		})
+		assert.Error(t, err, "backfills are for historical events")
+
+		_, err = svc.CreateBackfill(ctx, dto.CreateEventBackfillRequest{
+			StartTime: start,
+			EndTime:   end,
+			S3Path:    "https://usage-archive/archive/events.json",
+		})
+		assert.Error(t, err, "only s3 paths are read")
+	})
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/kafka"
+	"github.com/flexprice/flexprice/internal/logger"
+)
+
+// EventConsumerService reports on the consumers storing the ingested events
+type EventConsumerService interface {
+	// GetConsumerLag returns how many events of each lane its consumer group
+	// has not consumed yet
+	GetConsumerLag(ctx context.Context) (*dto.ConsumerLagResponse, error)
+}
+
+type eventConsumerService struct {
+	cfg       *config.Configuration
+	lagReader kafka.LagReader
+	logger    *logger.Logger
+}
+
+func NewEventConsumerService(cfg *config.Configuration, lagReader kafka.LagReader, logger *logger.Logger) EventConsumerService {
+	return &eventConsumerService{
+		cfg:       cfg,
+		lagReader: lagReader,
+		logger:    logger,
+	}
+}
+
+func (s *eventConsumerService) GetConsumerLag(ctx context.Context) (*dto.ConsumerLagResponse, error) {
+	response := &dto.ConsumerLagResponse{Lanes: []dto.ConsumerLaneLag{}}
+	for _, lane := range s.cfg.Kafka.ConsumerLanes() {
+		laneLag := dto.ConsumerLaneLag{
+			Name:          lane.Name,
+			Topic:         lane.Topic,
+			ConsumerGroup: lane.ConsumerGroup,
+			Partitions:    []kafka.PartitionLag{},
+		}
+
+		// A lane whose lag can not be read is reported without hiding the others
+		partitions, err := s.lagReader.ConsumerLag(lane.Topic, lane.ConsumerGroup)
+		if err != nil {
+			s.logger.Errorw("failed to read consumer lag", "lane", lane.Name, "error", err)
+			laneLag.Error = err.Error()
+		}
+		for _, partition := range partitions {
+			laneLag.Lag += partition.Lag
+			laneLag.Partitions = append(laneLag.Partitions, partition)
+		}
+
+		response.Lag += laneLag.Lag
+		response.Lanes = append(response.Lanes, laneLag)
+	}
+	return response, nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/kafka"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLagReader map[string][]kafka.PartitionLag
+
+func (r testLagReader) ConsumerLag(topic, consumerGroup string) ([]kafka.PartitionLag, error) {
+	partitions, ok := r[topic+"/"+consumerGroup]
+	if !ok {
+		return nil, errors.New("unknown topic")
+	}
+	return partitions, nil
+}
+
+func TestEventConsumerLag(t *testing.T) {
+	cfg := &config.Configuration{Kafka: config.KafkaConfig{
+		Topic:         "events",
+		ConsumerGroup: "consumer",
+		Lanes: []config.KafkaLaneConfig{
+			{Name: "billing", Topic: "events_billing", ConsumerGroup: "billing", EventNames: []string{"invoice_usage"}},
+			{Name: "noisy", Topic: "events_noisy", ConsumerGroup: "noisy", TenantIDs: []string{"tenant_noisy"}},
+		},
+	}}
+	lagReader := testLagReader{
+		"events/consumer": {
+			{Partition: 0, CommittedOffset: 90, HighWatermark: 100, Lag: 10},
+			{Partition: 1, CommittedOffset: -1, HighWatermark: 40},
+		},
+		"events_billing/billing": {{Partition: 0, CommittedOffset: 5, HighWatermark: 7, Lag: 2}},
+	}
+	svc := NewEventConsumerService(cfg, lagReader, logger.GetLogger())
+
+	resp, err := svc.GetConsumerLag(testutil.SetupContext())
+	require.NoError(t, err)
+	require.Len(t, resp.Lanes, 3)
+
+	assert.Equal(t, "default", resp.Lanes[0].Name)
+	assert.Equal(t, int64(10), resp.Lanes[0].Lag)
+	assert.Len(t, resp.Lanes[0].Partitions, 2)
+	assert.Equal(t, int64(2), resp.Lanes[1].Lag)
+
+	// A lane whose lag can not be read is reported with its error
+	assert.Equal(t, "noisy", resp.Lanes[2].Name)
+	assert.Equal(t, "unknown topic", resp.Lanes[2].Error)
+	assert.Empty(t, resp.Lanes[2].Partitions)
+
+	assert.Equal(t, int64(12), resp.Lag)
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+var (
+	// ErrEventNotFound is returned when no event was ingested with the given ID
+	ErrEventNotFound = errors.New("event not found")
+
+	// ErrEventVoided is returned when a voided event is voided or corrected again
+	ErrEventVoided = errors.New("event is voided")
+
+	// ErrEventPeriodInvoiced is returned when an event is voided or corrected
+	// after the billing period it falls in was invoiced
+	ErrEventPeriodInvoiced = errors.New("the billing period of the event is already invoiced")
+)
+
+// eventCorrectionInvoicePageSize is the number of invoices of the customer read
+// at once when checking whether the period of an event is invoiced
+const eventCorrectionInvoicePageSize = 100
+
+// EventCorrectionService voids and corrects ingested events. Corrections never
+// rewrite an event, they append rows reversing its usage and standing in for it
+// which the aggregations add up with their sign
+type EventCorrectionService interface {
+	// VoidEvent cancels the usage of the event with the given ID, which is the
+	// event_id given at ingestion
+	VoidEvent(ctx context.Context, id string, req dto.VoidEventRequest) (*dto.EventHistoryResponse, error)
+
+	// CorrectEvent replaces the properties of the event with the given ID
+	CorrectEvent(ctx context.Context, id string, req dto.CorrectEventRequest) (*dto.EventHistoryResponse, error)
+
+	// GetEventHistory returns the event with the given ID and its corrections
+	GetEventHistory(ctx context.Context, id string) (*dto.EventHistoryResponse, error)
+}
+
+type eventCorrectionService struct {
+	eventRepo      events.Repository
+	customerRepo   customer.Repository
+	invoiceRepo    invoice.Repository
+	meterRepo      meter.Repository
+	rollupRepo     events.RollupRepository
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
+}
+
+func NewEventCorrectionService(
+	eventRepo events.Repository,
+	customerRepo customer.Repository,
+	invoiceRepo invoice.Repository,
+	meterRepo meter.Repository,
+	rollupRepo events.RollupRepository,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) EventCorrectionService {
+	return &eventCorrectionService{
+		eventRepo:      eventRepo,
+		customerRepo:   customerRepo,
+		invoiceRepo:    invoiceRepo,
+		meterRepo:      meterRepo,
+		rollupRepo:     rollupRepo,
+		auditPublisher: auditPublisher,
+		logger:         logger,
+	}
+}
+
+// correctedEvent is the state of an event recorded in the audit log, with the
+// reason it was voided or corrected
+type correctedEvent struct {
+	*events.Event
+	Reason string `json:"reason,omitempty"`
+}
+
+func (s *eventCorrectionService) VoidEvent(ctx context.Context, id string, req dto.VoidEventRequest) (*dto.EventHistoryResponse, error) {
+	history, current, err := s.getCorrectableEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reversal := current.Reversal(id)
+	if err := s.eventRepo.InsertEvents(ctx, []*events.Event{reversal}); err != nil {
+		return nil, fmt.Errorf("failed to void event: %w", err)
+	}
+	history, err = s.getCorrectedHistory(ctx, id, history, reversal)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rollUpCorrection(ctx, current)
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeEvent, id, types.AuditActionDelete,
+		current, &correctedEvent{Event: reversal, Reason: req.Reason})
+
+	return dto.NewEventHistoryResponse(history), nil
+}
+
+func (s *eventCorrectionService) CorrectEvent(ctx context.Context, id string, req dto.CorrectEventRequest) (*dto.EventHistoryResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	history, current, err := s.getCorrectableEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reversal := current.Reversal(id)
+	replacement := current.Replacement(id, req.Properties)
+	if err := s.eventRepo.InsertEvents(ctx, []*events.Event{reversal, replacement}); err != nil {
+		return nil, fmt.Errorf("failed to correct event: %w", err)
+	}
+	history, err = s.getCorrectedHistory(ctx, id, history, reversal, replacement)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rollUpCorrection(ctx, current)
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeEvent, id, types.AuditActionUpdate,
+		current, &correctedEvent{Event: replacement, Reason: req.Reason})
+
+	return dto.NewEventHistoryResponse(history), nil
+}
+
+func (s *eventCorrectionService) GetEventHistory(ctx context.Context, id string) (*dto.EventHistoryResponse, error) {
+	history, err := s.eventRepo.GetEventHistory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event history: %w", err)
+	}
+	if len(history) == 0 {
+		return nil, ErrEventNotFound
+	}
+
+	return dto.NewEventHistoryResponse(history), nil
+}
+
+// getCorrectableEvent returns the history of the event and the row its usage
+// is currently aggregated from, checking that the event is not voided and its
+// billing period is still open
+func (s *eventCorrectionService) getCorrectableEvent(ctx context.Context, id string) ([]*events.Event, *events.Event, error) {
+	history, err := s.eventRepo.GetEventHistory(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get event history: %w", err)
+	}
+	if len(history) == 0 {
+		return nil, nil, ErrEventNotFound
+	}
+
+	current := events.EffectiveEvent(history)
+	if current == nil {
+		return nil, nil, ErrEventVoided
+	}
+
+	if err := s.checkPeriodOpen(ctx, history[0]); err != nil {
+		return nil, nil, err
+	}
+
+	return history, current, nil
+}
+
+// getCorrectedHistory reads again the history of the event after its
+// correction rows were inserted. The correction rows of a row have the IDs of
+// the row they correct, so when another correction of the same row won the
+// race the rows inserted were dropped as duplicates and the history returned
+// is the one the other correction wrote
+func (s *eventCorrectionService) getCorrectedHistory(ctx context.Context, id string, history []*events.Event, corrections ...*events.Event) ([]*events.Event, error) {
+	corrected, err := s.eventRepo.GetEventHistory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event history: %w", err)
+	}
+	if len(corrected) < len(history)+len(corrections) {
+		// Rows not readable yet are returned as written
+		return append(history, corrections...), nil
+	}
+	return corrected, nil
+}
+
+// checkPeriodOpen rejects the correction of an event whose timestamp falls in
+// the period of a subscription invoice of the customer that was not voided
+func (s *eventCorrectionService) checkPeriodOpen(ctx context.Context, event *events.Event) error {
+	customerID := event.CustomerID
+	if customerID == "" {
+		customers, err := s.customerRepo.ListByExternalIDs(ctx, []string{event.ExternalCustomerID})
+		if err != nil {
+			return fmt.Errorf("failed to get customer: %w", err)
+		}
+		if len(customers) == 0 {
+			// Usage of unknown customers is never invoiced
+			return nil
+		}
+		customerID = customers[0].ID
+	}
+
+	filter := &types.InvoiceFilter{
+		Filter:      types.Filter{Limit: eventCorrectionInvoicePageSize},
+		CustomerID:  customerID,
+		InvoiceType: types.InvoiceTypeSubscription,
+	}
+	for {
+		invoices, err := s.invoiceRepo.List(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list invoices: %w", err)
+		}
+
+		for _, inv := range invoices {
+			if inv.InvoiceStatus == types.InvoiceStatusVoided || inv.PeriodStart == nil || inv.PeriodEnd == nil {
+				continue
+			}
+			if !event.Timestamp.Before(*inv.PeriodStart) && event.Timestamp.Before(*inv.PeriodEnd) {
+				return fmt.Errorf("%w: invoice %s covers %s", ErrEventPeriodInvoiced, inv.ID, event.Timestamp.Format(time.RFC3339))
+			}
+		}
+
+		if len(invoices) < filter.Limit {
+			return nil
+		}
+		filter.Offset += filter.Limit
+	}
+}
+
+// rollUpCorrection rolls up again the closed windows holding the event, for
+// the meters of its event name. Windows that are not rolled up yet pick the
+// correction up from the raw events
+func (s *eventCorrectionService) rollUpCorrection(ctx context.Context, event *events.Event) {
+	if s.rollupRepo == nil {
+		return
+	}
+
+	rollUpAgain(ctx, s.rollupRepo, s.meterRepo, s.logger,
+		map[string]bool{event.EventName: true}, event.Timestamp, event.Timestamp.Add(time.Nanosecond))
+}
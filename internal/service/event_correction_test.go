@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventCorrectionService(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	eventStore := testutil.NewInMemoryEventStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	auditStore := testutil.NewInMemoryAuditLogStore()
+	publisher := audit.NewPublisher(auditStore, logger.GetLogger())
+
+	svc := NewEventCorrectionService(eventStore, customerStore, invoiceStore,
+		testutil.NewInMemoryMeterStore(), nil, publisher, logger.GetLogger())
+
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{ID: "cust_1", ExternalID: "acme", BaseModel: types.GetDefaultBaseModel(ctx)}))
+
+	// January is invoiced, February is still open
+	periodStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := periodStart.AddDate(0, 1, 0)
+	require.NoError(t, invoiceStore.Create(ctx, &invoice.Invoice{
+		ID:            "inv_jan",
+		CustomerID:    "cust_1",
+		InvoiceType:   types.InvoiceTypeSubscription,
+		InvoiceStatus: types.InvoiceStatusFinalized,
+		PeriodStart:   &periodStart,
+		PeriodEnd:     &periodEnd,
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}))
+
+	ingest := func(id string, ts time.Time, size float64) {
+		event := events.NewEvent("api_request", types.GetTenantID(ctx), "acme",
+			map[string]interface{}{"request_size": size}, ts, id, "", "")
+		require.NoError(t, eventStore.InsertEvent(ctx, event))
+	}
+	ingest("evt_jan", periodStart.Add(time.Hour), 100)
+	ingest("evt_feb_1", periodEnd.Add(time.Hour), 100)
+	ingest("evt_feb_2", periodEnd.Add(2*time.Hour), 50)
+
+	usage := func(aggregation types.AggregationType) decimal.Decimal {
+		result, err := eventStore.GetUsage(ctx, &events.UsageParams{
+			ExternalCustomerID: "acme",
+			EventName:          "api_request",
+			PropertyName:       "request_size",
+			AggregationType:    aggregation,
+			StartTime:          periodEnd,
+			EndTime:            periodEnd.AddDate(0, 1, 0),
+		})
+		require.NoError(t, err)
+		return result.Value
+	}
+	require.True(t, decimal.NewFromInt(150).Equal(usage(types.AggregationSum)))
+
+	t.Run("corrects the properties of an event", func(t *testing.T) {
+		resp, err := svc.CorrectEvent(ctx, "evt_feb_1", dto.CorrectEventRequest{
+			Properties: map[string]interface{}{"request_size": float64(80)},
+			Reason:     "wrong request size reported",
+		})
+		require.NoError(t, err)
+
+		require.Len(t, resp.Corrections, 2)
+		assert.Equal(t, int8(-1), resp.Corrections[0].Sign)
+		assert.Equal(t, int8(1), resp.Corrections[1].Sign)
+		require.NotNil(t, resp.Current)
+		assert.Equal(t, float64(80), resp.Current.Properties["request_size"])
+
+		assert.True(t, decimal.NewFromInt(130).Equal(usage(types.AggregationSum)))
+		assert.True(t, decimal.NewFromInt(2).Equal(usage(types.AggregationCount)))
+	})
+
+	t.Run("voids a corrected event", func(t *testing.T) {
+		resp, err := svc.VoidEvent(ctx, "evt_feb_1", dto.VoidEventRequest{Reason: "duplicate"})
+		require.NoError(t, err)
+		assert.True(t, resp.Voided)
+		assert.Nil(t, resp.Current)
+		assert.Len(t, resp.Corrections, 3)
+
+		assert.True(t, decimal.NewFromInt(50).Equal(usage(types.AggregationSum)))
+		assert.True(t, decimal.NewFromInt(1).Equal(usage(types.AggregationCount)))
+	})
+
+	t.Run("rejects the correction of a voided event", func(t *testing.T) {
+		_, err := svc.VoidEvent(ctx, "evt_feb_1", dto.VoidEventRequest{})
+		assert.ErrorIs(t, err, ErrEventVoided)
+	})
+
+	t.Run("rejects the correction of an event in an invoiced period", func(t *testing.T) {
+		_, err := svc.VoidEvent(ctx, "evt_jan", dto.VoidEventRequest{})
+		assert.ErrorIs(t, err, ErrEventPeriodInvoiced)
+	})
+
+	t.Run("rejects the correction of an unknown event", func(t *testing.T) {
+		_, err := svc.CorrectEvent(ctx, "evt_unknown", dto.CorrectEventRequest{
+			Properties: map[string]interface{}{"request_size": float64(1)},
+		})
+		assert.ErrorIs(t, err, ErrEventNotFound)
+	})
+
+	t.Run("lists the corrections of an event", func(t *testing.T) {
+		resp, err := svc.GetEventHistory(ctx, "evt_feb_1")
+		require.NoError(t, err)
+		assert.Equal(t, "evt_feb_1", resp.Event.ID)
+		assert.True(t, resp.Voided)
+		assert.Len(t, resp.Corrections, 3)
+	})
+
+	t.Run("reverses an event once when corrected concurrently", func(t *testing.T) {
+		ingest("evt_feb_3", periodEnd.Add(3*time.Hour), 30)
+		require.True(t, decimal.NewFromInt(80).Equal(usage(types.AggregationSum)))
+
+		// Both corrections read the history before either inserts its rows
+		racing := &racingEventStore{InMemoryEventStore: eventStore, readers: 2}
+		racing.ready.Add(2)
+		racingSvc := NewEventCorrectionService(racing, customerStore, invoiceStore,
+			testutil.NewInMemoryMeterStore(), nil, publisher, logger.GetLogger())
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := racingSvc.VoidEvent(ctx, "evt_feb_3", dto.VoidEventRequest{})
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := racingSvc.CorrectEvent(ctx, "evt_feb_3", dto.CorrectEventRequest{
+				Properties: map[string]interface{}{"request_size": float64(10)},
+			})
+			assert.NoError(t, err)
+		}()
+		wg.Wait()
+
+		// The reversal is stored once and the replacement stands in for the event
+		resp, err := svc.GetEventHistory(ctx, "evt_feb_3")
+		require.NoError(t, err)
+		assert.Len(t, resp.Corrections, 2)
+		require.NotNil(t, resp.Current)
+		assert.Equal(t, float64(10), resp.Current.Properties["request_size"])
+		assert.True(t, decimal.NewFromInt(60).Equal(usage(types.AggregationSum)))
+	})
+
+	// Close waits for the logs to be written
+	publisher.Close()
+
+	logs, err := auditStore.List(ctx, &types.AuditLogFilter{EntityType: types.AuditEntityTypeEvent})
+	require.NoError(t, err)
+	assert.Len(t, logs, 4)
+}
+
+// racingEventStore holds the first readers of an event history until all of
+// them read it, so that their corrections race
+type racingEventStore struct {
+	*testutil.InMemoryEventStore
+	mu      sync.Mutex
+	readers int
+	ready   sync.WaitGroup
+}
+
+func (s *racingEventStore) GetEventHistory(ctx context.Context, id string) ([]*events.Event, error) {
+	history, err := s.InMemoryEventStore.GetEventHistory(ctx, id)
+
+	s.mu.Lock()
+	racing := s.readers > 0
+	s.readers--
+	s.mu.Unlock()
+	if racing {
+		s.ready.Done()
+		s.ready.Wait()
+	}
+	return history, err
+}
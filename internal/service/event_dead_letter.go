@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/deadletter"
+	"github.com/flexprice/flexprice/internal/kafka"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// ErrDeadLetterNotFound is returned when the tenant has no dead letter with the given id
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// EventDeadLetterService keeps the messages that the consumer failed to
+// process, so they can be inspected and replayed once the cause is fixed
+type EventDeadLetterService interface {
+	// Record dead-letters a message that failed to be processed. The message is
+	// published to the dead letter topic and stored under the tenant of its
+	// payload
+	Record(ctx context.Context, topic, messageID string, payload []byte, cause error) error
+
+	ListDeadLetters(ctx context.Context, filter *types.DeadLetterFilter) (*dto.ListDeadLettersResponse, error)
+	GetDeadLetter(ctx context.Context, id string) (*dto.DeadLetterResponse, error)
+
+	// ReplayDeadLetters publishes the selected dead letters again to their
+	// topic. A dead letter that fails once more is recorded again
+	ReplayDeadLetters(ctx context.Context, req dto.ReplayDeadLettersRequest) (*dto.ReplayDeadLettersResponse, error)
+}
+
+type eventDeadLetterService struct {
+	cfg      *config.Configuration
+	repo     deadletter.Repository
+	producer kafka.MessageProducer
+	logger   *logger.Logger
+}
+
+func NewEventDeadLetterService(
+	cfg *config.Configuration,
+	repo deadletter.Repository,
+	producer kafka.MessageProducer,
+	logger *logger.Logger,
+) EventDeadLetterService {
+	return &eventDeadLetterService{
+		cfg:      cfg,
+		repo:     repo,
+		producer: producer,
+		logger:   logger,
+	}
+}
+
+func (s *eventDeadLetterService) Record(ctx context.Context, topic, messageID string, payload []byte, cause error) error {
+	// Nothing consumes the dead letter topic of the Postgres queue, the dead
+	// letters are only kept in their table
+	var errs []error
+	if s.cfg.Kafka.DeadLetterTopic != "" && s.producer != nil && !s.cfg.Queue.UsesPostgres() {
+		if err := s.producer.PublishWithID(s.cfg.Kafka.DeadLetterTopic, payload, messageID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to publish dead letter: %w", err))
+		}
+	}
+
+	// The consumer has no tenant of its own, the dead letter belongs to the
+	// tenant of the message when it can be read
+	var message struct {
+		TenantID string `json:"tenant_id"`
+	}
+	_ = json.Unmarshal(payload, &message)
+	ctx = context.WithValue(ctx, types.CtxTenantID, message.TenantID)
+
+	now := time.Now().UTC()
+	deadLetter := &deadletter.DeadLetter{
+		ID:               types.GenerateUUID(),
+		MessageID:        messageID,
+		Topic:            topic,
+		Payload:          string(payload),
+		Error:            cause.Error(),
+		Attempts:         1,
+		DeadLetterStatus: types.DeadLetterStatusFailed,
+		LastFailedAt:     now,
+		BaseModel:        types.GetDefaultBaseModel(ctx),
+	}
+	if err := s.repo.Record(ctx, deadLetter); err != nil {
+		errs = append(errs, err)
+	}
+
+	s.logger.Warnw("message dead-lettered",
+		"topic", topic,
+		"message_id", messageID,
+		"tenant_id", message.TenantID,
+		"error", cause,
+	)
+
+	return errors.Join(errs...)
+}
+
+func (s *eventDeadLetterService) ListDeadLetters(ctx context.Context, filter *types.DeadLetterFilter) (*dto.ListDeadLettersResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	deadLetters, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	response := &dto.ListDeadLettersResponse{
+		DeadLetters: make([]dto.DeadLetterResponse, len(deadLetters)),
+		Total:       len(deadLetters),
+		Offset:      filter.Offset,
+		Limit:       filter.Limit,
+	}
+	for i, deadLetter := range deadLetters {
+		response.DeadLetters[i] = dto.DeadLetterResponse{DeadLetter: deadLetter}
+	}
+	return response, nil
+}
+
+func (s *eventDeadLetterService) GetDeadLetter(ctx context.Context, id string) (*dto.DeadLetterResponse, error) {
+	deadLetter, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeadLetterNotFound, err)
+	}
+	return &dto.DeadLetterResponse{DeadLetter: deadLetter}, nil
+}
+
+func (s *eventDeadLetterService) ReplayDeadLetters(ctx context.Context, req dto.ReplayDeadLettersRequest) (*dto.ReplayDeadLettersResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	response := &dto.ReplayDeadLettersResponse{
+		Replayed: []dto.DeadLetterResponse{},
+		Failed:   []dto.DeadLetterReplayError{},
+	}
+	for _, id := range req.IDs {
+		deadLetter, err := s.replay(ctx, id)
+		if err != nil {
+			response.Failed = append(response.Failed, dto.DeadLetterReplayError{ID: id, Error: err.Error()})
+			continue
+		}
+		response.Replayed = append(response.Replayed, dto.DeadLetterResponse{DeadLetter: deadLetter})
+	}
+
+	s.logger.Infow("dead letters replayed",
+		"tenant_id", types.GetTenantID(ctx),
+		"replayed", len(response.Replayed),
+		"failed", len(response.Failed),
+	)
+
+	return response, nil
+}
+
+func (s *eventDeadLetterService) replay(ctx context.Context, id string) (*deadletter.DeadLetter, error) {
+	deadLetter, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, ErrDeadLetterNotFound
+	}
+
+	if err := s.producer.PublishWithID(deadLetter.Topic, []byte(deadLetter.Payload), deadLetter.MessageID); err != nil {
+		return nil, fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	now := time.Now().UTC()
+	deadLetter.DeadLetterStatus = types.DeadLetterStatusReplayed
+	deadLetter.ReplayedAt = &now
+	deadLetter.UpdatedAt = now
+	deadLetter.UpdatedBy = types.GetUserID(ctx)
+	if err := s.repo.Update(ctx, deadLetter); err != nil {
+		return nil, fmt.Errorf("failed to update dead letter: %w", err)
+	}
+	return deadLetter, nil
+}
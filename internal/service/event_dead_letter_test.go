@@ -0,0 +1,71 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventDeadLetters(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	broker := testutil.NewInMemoryMessageBroker()
+	cfg := &config.Configuration{Kafka: config.KafkaConfig{Topic: "events", DeadLetterTopic: "events_dlq"}}
+	svc := NewEventDeadLetterService(cfg, testutil.NewInMemoryDeadLetterStore(), broker, logger.GetLogger())
+
+	event := events.NewEvent("api_request", types.GetTenantID(ctx), "acme", nil, time.Now().UTC(), "evt_1", "", "")
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Record(ctx, "events", event.ID, payload, errors.New("clickhouse unavailable")))
+	require.NoError(t, svc.Record(ctx, "events", event.ID, payload, errors.New("clickhouse timeout")))
+	require.NoError(t, svc.Record(ctx, "events", "msg_2", []byte("not json"), errors.New("invalid character")))
+
+	t.Run("publishes failed messages to the dead letter topic", func(t *testing.T) {
+		assert.True(t, broker.HasMessage("events_dlq", event.ID))
+		assert.True(t, broker.HasMessage("events_dlq", "msg_2"))
+	})
+
+	t.Run("lists the failed messages of the tenant with their last error", func(t *testing.T) {
+		list, err := svc.ListDeadLetters(ctx, &types.DeadLetterFilter{})
+		require.NoError(t, err)
+		require.Len(t, list.DeadLetters, 1)
+
+		deadLetter := list.DeadLetters[0]
+		assert.Equal(t, event.ID, deadLetter.MessageID)
+		assert.Equal(t, "clickhouse timeout", deadLetter.Error)
+		assert.Equal(t, 2, deadLetter.Attempts)
+		assert.Equal(t, types.DeadLetterStatusFailed, deadLetter.DeadLetterStatus)
+	})
+
+	t.Run("replays the selected messages", func(t *testing.T) {
+		list, err := svc.ListDeadLetters(ctx, &types.DeadLetterFilter{})
+		require.NoError(t, err)
+		id := list.DeadLetters[0].ID
+
+		resp, err := svc.ReplayDeadLetters(ctx, dto.ReplayDeadLettersRequest{IDs: []string{id, "missing"}})
+		require.NoError(t, err)
+		require.Len(t, resp.Replayed, 1)
+		assert.Equal(t, types.DeadLetterStatusReplayed, resp.Replayed[0].DeadLetterStatus)
+		assert.Equal(t, []dto.DeadLetterReplayError{{ID: "missing", Error: ErrDeadLetterNotFound.Error()}}, resp.Failed)
+		assert.True(t, broker.HasMessage("events", event.ID))
+
+		// A replayed message failing again is back to failed
+		require.NoError(t, svc.Record(ctx, "events", event.ID, payload, errors.New("clickhouse unavailable")))
+		deadLetter, err := svc.GetDeadLetter(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, types.DeadLetterStatusFailed, deadLetter.DeadLetterStatus)
+		assert.Nil(t, deadLetter.ReplayedAt)
+		assert.Equal(t, 3, deadLetter.Attempts)
+	})
+}
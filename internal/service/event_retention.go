@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/retention"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type EventRetentionService interface {
+	// GetStorage reports the size of the events of every tenant and every
+	// monthly partition with the deletes and drops scheduled by the retention job
+	GetStorage(ctx context.Context, now time.Time) (*dto.EventStorageResponse, error)
+
+	ListPolicies(ctx context.Context) (*dto.ListRetentionPoliciesResponse, error)
+	SetPolicy(ctx context.Context, tenantID string, req *dto.SetRetentionPolicyRequest) (*dto.RetentionPolicyResponse, error)
+	DeletePolicy(ctx context.Context, tenantID string) error
+
+	// ApplyRetention drops the partitions past the TTL of every tenant and
+	// deletes the events of each tenant older than its own TTL
+	ApplyRetention(ctx context.Context, now time.Time) error
+}
+
+type eventRetentionService struct {
+	cfg           config.EventRetentionConfig
+	retentionRepo retention.Repository
+	eventRepo     events.Repository
+	logger        *logger.Logger
+}
+
+func NewEventRetentionService(
+	cfg *config.Configuration,
+	retentionRepo retention.Repository,
+	eventRepo events.Repository,
+	logger *logger.Logger,
+) EventRetentionService {
+	retentionCfg := cfg.EventRetention
+	if retentionCfg.DefaultTTLDays < 0 {
+		retentionCfg.DefaultTTLDays = 0
+	}
+
+	return &eventRetentionService{
+		cfg:           retentionCfg,
+		retentionRepo: retentionRepo,
+		eventRepo:     eventRepo,
+		logger:        logger,
+	}
+}
+
+func (s *eventRetentionService) ListPolicies(ctx context.Context) (*dto.ListRetentionPoliciesResponse, error) {
+	policies, err := s.retentionRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ListRetentionPoliciesResponse{
+		Policies:       make([]dto.RetentionPolicyResponse, len(policies)),
+		DefaultTTLDays: s.cfg.DefaultTTLDays,
+	}
+	for i, p := range policies {
+		resp.Policies[i] = dto.RetentionPolicyResponse{Policy: p}
+	}
+
+	return resp, nil
+}
+
+func (s *eventRetentionService) SetPolicy(ctx context.Context, tenantID string, req *dto.SetRetentionPolicyRequest) (*dto.RetentionPolicyResponse, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	// The policy belongs to the target tenant, not to the caller
+	policy := &retention.Policy{
+		ID:        types.GenerateUUID(),
+		TTLDays:   req.TTLDays,
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}
+	policy.TenantID = tenantID
+
+	if err := s.retentionRepo.Upsert(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to set event retention policy: %w", err)
+	}
+
+	return &dto.RetentionPolicyResponse{Policy: policy}, nil
+}
+
+func (s *eventRetentionService) DeletePolicy(ctx context.Context, tenantID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenant_id is required")
+	}
+	return s.retentionRepo.Delete(ctx, tenantID)
+}
+
+func (s *eventRetentionService) GetStorage(ctx context.Context, now time.Time) (*dto.EventStorageResponse, error) {
+	plan, err := s.plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.EventStorageResponse{
+		Tenants:    make([]dto.TenantStorageResponse, len(plan.tenants)),
+		Partitions: make([]dto.EventPartitionResponse, len(plan.partitions)),
+	}
+	for i, t := range plan.tenants {
+		resp.Tenants[i] = dto.TenantStorageResponse{
+			TenantID:     t.TenantID,
+			Rows:         t.Rows,
+			Bytes:        t.Bytes,
+			OldestEvent:  t.OldestEvent,
+			NewestEvent:  t.NewestEvent,
+			TTLDays:      plan.ttlDays[t.TenantID],
+			DeleteBefore: retention.CutoffFor(plan.ttlDays[t.TenantID], now),
+		}
+	}
+	for i, p := range plan.partitions {
+		resp.Partitions[i] = dto.EventPartitionResponse{
+			PartitionID: p.PartitionID,
+			Rows:        p.Rows,
+			BytesOnDisk: p.BytesOnDisk,
+			DropAt:      plan.dropAt(p.PartitionID),
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *eventRetentionService) ApplyRetention(ctx context.Context, now time.Time) error {
+	plan, err := s.plan(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Dropping a partition is much cheaper than deleting its rows, so the
+	// partitions past every TTL go first
+	for _, p := range plan.partitions {
+		dropAt := plan.dropAt(p.PartitionID)
+		if dropAt == nil || dropAt.After(now) {
+			continue
+		}
+
+		if err := s.eventRepo.DropPartition(ctx, p.PartitionID); err != nil {
+			s.logger.Errorw("failed to drop events partition",
+				"partition_id", p.PartitionID,
+				"error", err,
+			)
+			continue
+		}
+		s.logger.Infow("dropped events partition",
+			"partition_id", p.PartitionID,
+			"rows", p.Rows,
+		)
+	}
+
+	for _, t := range plan.tenants {
+		cutoff := retention.CutoffFor(plan.ttlDays[t.TenantID], now)
+		if cutoff == nil || !t.OldestEvent.Before(*cutoff) {
+			continue
+		}
+
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, t.TenantID)
+		tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+		if err := s.eventRepo.DeleteEventsBefore(tenantCtx, *cutoff); err != nil {
+			s.logger.Errorw("failed to delete expired events",
+				"tenant_id", t.TenantID,
+				"before", *cutoff,
+				"error", err,
+			)
+			continue
+		}
+		s.logger.Infow("deleted expired events",
+			"tenant_id", t.TenantID,
+			"before", *cutoff,
+		)
+	}
+
+	return nil
+}
+
+// retentionPlan is the TTL of every tenant with events and the partitions of
+// the events table at a point in time
+type retentionPlan struct {
+	tenants    []*events.TenantStorage
+	partitions []*events.Partition
+	ttlDays    map[string]int
+
+	// maxTTLDays is the longest TTL of the tenants, partitions are never dropped
+	// when a tenant keeps its events forever
+	maxTTLDays  int
+	keepForever bool
+}
+
+func (s *eventRetentionService) plan(ctx context.Context) (*retentionPlan, error) {
+	policies, err := s.retentionRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tenants, err := s.eventRepo.GetStorageByTenant(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events storage: %w", err)
+	}
+
+	partitions, err := s.eventRepo.ListPartitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events partitions: %w", err)
+	}
+
+	plan := &retentionPlan{
+		tenants:    tenants,
+		partitions: partitions,
+		ttlDays:    make(map[string]int, len(tenants)),
+	}
+
+	policyTTL := make(map[string]int, len(policies))
+	for _, p := range policies {
+		policyTTL[p.TenantID] = p.TTLDays
+	}
+
+	for _, t := range tenants {
+		ttl, ok := policyTTL[t.TenantID]
+		if !ok {
+			ttl = s.cfg.DefaultTTLDays
+		}
+		plan.ttlDays[t.TenantID] = ttl
+
+		if ttl <= 0 {
+			plan.keepForever = true
+		} else if ttl > plan.maxTTLDays {
+			plan.maxTTLDays = ttl
+		}
+	}
+
+	return plan, nil
+}
+
+// dropAt returns when a partition is past the TTL of every tenant, that is the
+// longest TTL after the end of its month
+func (p *retentionPlan) dropAt(partitionID string) *time.Time {
+	if p.keepForever || len(p.tenants) == 0 {
+		return nil
+	}
+
+	month, err := events.PartitionMonth(partitionID)
+	if err != nil {
+		return nil
+	}
+
+	dropAt := month.AddDate(0, 1, p.maxTTLDays)
+	return &dropAt
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventRetentionService_ApplyRetention(t *testing.T) {
+	ctx := testutil.SetupContext()
+	tenantA := types.GetTenantID(ctx)
+	tenantB := "tenant_b"
+
+	eventStore := testutil.NewInMemoryEventStore()
+	insert := func(id, tenantID string, at time.Time) {
+		require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+			ID:                 id,
+			TenantID:           tenantID,
+			EventName:          "api_call",
+			ExternalCustomerID: "ext_cust_123",
+			Timestamp:          at,
+			Properties:         map[string]interface{}{},
+		}))
+	}
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	insert("a_jan", tenantA, date(time.January, 10))
+	insert("a_may", tenantA, date(time.May, 1))
+	insert("a_jun", tenantA, date(time.June, 10))
+	insert("b_jan", tenantB, date(time.January, 20))
+	insert("b_apr", tenantB, date(time.April, 1))
+	insert("b_jun", tenantB, date(time.June, 1))
+
+	cfg := &config.Configuration{EventRetention: config.EventRetentionConfig{DefaultTTLDays: 90}}
+	svc := NewEventRetentionService(cfg, testutil.NewInMemoryRetentionStore(), eventStore, logger.GetLogger())
+
+	_, err := svc.SetPolicy(ctx, tenantA, &dto.SetRetentionPolicyRequest{TTLDays: -1})
+	assert.Error(t, err)
+
+	now := date(time.June, 15)
+
+	// A tenant keeping its events forever prevents any partition drop
+	_, err = svc.SetPolicy(ctx, tenantB, &dto.SetRetentionPolicyRequest{TTLDays: 0})
+	require.NoError(t, err)
+	storage, err := svc.GetStorage(ctx, now)
+	require.NoError(t, err)
+	for _, p := range storage.Partitions {
+		assert.Nil(t, p.DropAt, p.PartitionID)
+	}
+	require.NoError(t, svc.DeletePolicy(ctx, tenantB))
+
+	_, err = svc.SetPolicy(ctx, tenantA, &dto.SetRetentionPolicyRequest{TTLDays: 30})
+	require.NoError(t, err)
+
+	storage, err = svc.GetStorage(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, storage.Tenants, 2)
+	assert.Equal(t, 30, storage.Tenants[0].TTLDays)
+	assert.Equal(t, date(time.May, 16), *storage.Tenants[0].DeleteBefore)
+	assert.Equal(t, 90, storage.Tenants[1].TTLDays)
+	assert.Equal(t, uint64(3), storage.Tenants[1].Rows)
+
+	// Partitions are dropped the longest TTL after the end of their month
+	require.Len(t, storage.Partitions, 4)
+	assert.Equal(t, "202401", storage.Partitions[0].PartitionID)
+	assert.Equal(t, date(time.May, 1), *storage.Partitions[0].DropAt)
+
+	require.NoError(t, svc.ApplyRetention(ctx, now))
+
+	for id, kept := range map[string]bool{
+		"a_jan": false,
+		"a_may": false,
+		"a_jun": true,
+		"b_jan": false,
+		"b_apr": true,
+		"b_jun": true,
+	} {
+		assert.Equal(t, kept, eventStore.HasEvent(id), id)
+	}
+}
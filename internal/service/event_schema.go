@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/eventschema"
+	"github.com/flexprice/flexprice/internal/kafka"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+var (
+	// ErrInvalidEventSchema is returned when the definition of a schema is invalid
+	ErrInvalidEventSchema = errors.New("invalid event schema")
+
+	// ErrEventSchemaExists is returned when a schema is created for an event name
+	// that already has one
+	ErrEventSchemaExists = errors.New("event schema already exists")
+
+	ErrEventSchemaNotFound      = errors.New("event schema not found")
+	ErrQuarantinedEventNotFound = errors.New("quarantined event not found")
+
+	// ErrEventAlreadyResubmitted is returned when a quarantined event that was
+	// already ingested is re-submitted
+	ErrEventAlreadyResubmitted = errors.New("quarantined event already resubmitted")
+)
+
+// EventValidationError is returned when an event does not match the schema of
+// its event name
+type EventValidationError struct {
+	EventID    string
+	EventName  string
+	Violations eventschema.Violations
+
+	// Quarantined is set when the event was kept in quarantine instead of
+	// being rejected
+	Quarantined bool
+}
+
+func (e *EventValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+
+	verb := "rejected"
+	if e.Quarantined {
+		verb = "quarantined"
+	}
+	return fmt.Sprintf("event %s of %s %s: %s", e.EventID, e.EventName, verb, strings.Join(messages, "; "))
+}
+
+// EventSchemaService keeps the schemas of the properties of the events of each
+// event name, and the events quarantined because they did not match them
+type EventSchemaService interface {
+	CreateEventSchema(ctx context.Context, req dto.CreateEventSchemaRequest) (*dto.EventSchemaResponse, error)
+	GetEventSchema(ctx context.Context, id string) (*dto.EventSchemaResponse, error)
+	ListEventSchemas(ctx context.Context) (*dto.ListEventSchemasResponse, error)
+	UpdateEventSchema(ctx context.Context, id string, req dto.UpdateEventSchemaRequest) (*dto.EventSchemaResponse, error)
+	DeleteEventSchema(ctx context.Context, id string) error
+
+	// ValidateEvent checks an event against the schema of its event name before
+	// it is ingested. An invalid event is quarantined when its schema says so,
+	// and either way an *EventValidationError is returned
+	ValidateEvent(ctx context.Context, event *events.Event) error
+
+	ListQuarantinedEvents(ctx context.Context, filter *types.QuarantinedEventFilter) (*dto.ListQuarantinedEventsResponse, error)
+	GetQuarantinedEvent(ctx context.Context, id string) (*dto.QuarantinedEventResponse, error)
+
+	// ResubmitQuarantinedEvent ingests a quarantined event, with the fixed
+	// properties of the request if any. An event still not matching the schema
+	// of its event name stays in quarantine with its fixes
+	ResubmitQuarantinedEvent(ctx context.Context, id string, req dto.ResubmitQuarantinedEventRequest) (*dto.QuarantinedEventResponse, error)
+}
+
+type eventSchemaService struct {
+	cfg            *config.Configuration
+	repo           eventschema.Repository
+	producer       kafka.MessageProducer
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
+}
+
+func NewEventSchemaService(
+	cfg *config.Configuration,
+	repo eventschema.Repository,
+	producer kafka.MessageProducer,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) EventSchemaService {
+	return &eventSchemaService{
+		cfg:            cfg,
+		repo:           repo,
+		producer:       producer,
+		auditPublisher: auditPublisher,
+		logger:         logger,
+	}
+}
+
+func (s *eventSchemaService) CreateEventSchema(ctx context.Context, req dto.CreateEventSchemaRequest) (*dto.EventSchemaResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEventSchema, err)
+	}
+
+	schema := req.ToEventSchema(ctx)
+	if err := schema.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEventSchema, err)
+	}
+
+	existing, err := s.repo.GetByEventName(ctx, schema.EventName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event schema: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEventSchemaExists, schema.EventName)
+	}
+
+	if err := s.repo.Create(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create event schema: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeEventSchema, schema.ID, types.AuditActionCreate, nil, schema)
+	return &dto.EventSchemaResponse{EventSchema: schema}, nil
+}
+
+func (s *eventSchemaService) GetEventSchema(ctx context.Context, id string) (*dto.EventSchemaResponse, error) {
+	schema, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEventSchemaNotFound, err)
+	}
+	return &dto.EventSchemaResponse{EventSchema: schema}, nil
+}
+
+func (s *eventSchemaService) ListEventSchemas(ctx context.Context) (*dto.ListEventSchemasResponse, error) {
+	schemas, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event schemas: %w", err)
+	}
+
+	response := &dto.ListEventSchemasResponse{
+		Schemas: make([]dto.EventSchemaResponse, len(schemas)),
+	}
+	for i, schema := range schemas {
+		response.Schemas[i] = dto.EventSchemaResponse{EventSchema: schema}
+	}
+	return response, nil
+}
+
+func (s *eventSchemaService) UpdateEventSchema(ctx context.Context, id string, req dto.UpdateEventSchemaRequest) (*dto.EventSchemaResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEventSchema, err)
+	}
+
+	schema, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEventSchemaNotFound, err)
+	}
+	before := *schema
+
+	schema.Rules = req.Rules
+	if req.OnInvalid != "" {
+		schema.OnInvalid = req.OnInvalid
+	}
+	if err := schema.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEventSchema, err)
+	}
+	schema.UpdatedAt = time.Now().UTC()
+	schema.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.repo.Update(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to update event schema: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeEventSchema, schema.ID, types.AuditActionUpdate, &before, schema)
+	return &dto.EventSchemaResponse{EventSchema: schema}, nil
+}
+
+func (s *eventSchemaService) DeleteEventSchema(ctx context.Context, id string) error {
+	schema, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrEventSchemaNotFound, err)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete event schema: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeEventSchema, id, types.AuditActionDelete, schema, nil)
+	return nil
+}
+
+func (s *eventSchemaService) ValidateEvent(ctx context.Context, event *events.Event) error {
+	schema, err := s.repo.GetByEventName(ctx, event.EventName)
+	if err != nil {
+		return fmt.Errorf("failed to get event schema: %w", err)
+	}
+	if schema == nil {
+		return nil
+	}
+
+	violations := schema.Check(event.Properties)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	validationErr := &EventValidationError{
+		EventID:    event.ID,
+		EventName:  event.EventName,
+		Violations: violations,
+	}
+	if schema.OnInvalid != types.InvalidEventActionQuarantine {
+		return validationErr
+	}
+
+	quarantined := &eventschema.QuarantinedEvent{
+		ID:                 event.ID,
+		EventName:          event.EventName,
+		ExternalCustomerID: event.ExternalCustomerID,
+		CustomerID:         event.CustomerID,
+		Source:             event.Source,
+		Timestamp:          event.Timestamp,
+		Properties:         event.Properties,
+		SchemaID:           schema.ID,
+		Violations:         violations,
+		QuarantineStatus:   types.QuarantineStatusQuarantined,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}
+	if err := s.repo.Quarantine(ctx, quarantined); err != nil {
+		return fmt.Errorf("failed to quarantine event: %w", err)
+	}
+
+	s.logger.Infow("event quarantined",
+		"event_id", event.ID,
+		"event_name", event.EventName,
+		"tenant_id", event.TenantID,
+		"violations", len(violations),
+	)
+
+	validationErr.Quarantined = true
+	return validationErr
+}
+
+func (s *eventSchemaService) ListQuarantinedEvents(ctx context.Context, filter *types.QuarantinedEventFilter) (*dto.ListQuarantinedEventsResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	quarantined, err := s.repo.ListQuarantinedEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantined events: %w", err)
+	}
+
+	response := &dto.ListQuarantinedEventsResponse{
+		Events: make([]dto.QuarantinedEventResponse, len(quarantined)),
+		Total:  len(quarantined),
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+	}
+	for i, event := range quarantined {
+		response.Events[i] = dto.QuarantinedEventResponse{QuarantinedEvent: event}
+	}
+	return response, nil
+}
+
+func (s *eventSchemaService) GetQuarantinedEvent(ctx context.Context, id string) (*dto.QuarantinedEventResponse, error) {
+	quarantined, err := s.repo.GetQuarantinedEvent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrQuarantinedEventNotFound, err)
+	}
+	return &dto.QuarantinedEventResponse{QuarantinedEvent: quarantined}, nil
+}
+
+func (s *eventSchemaService) ResubmitQuarantinedEvent(ctx context.Context, id string, req dto.ResubmitQuarantinedEventRequest) (*dto.QuarantinedEventResponse, error) {
+	quarantined, err := s.repo.GetQuarantinedEvent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrQuarantinedEventNotFound, err)
+	}
+	if quarantined.QuarantineStatus == types.QuarantineStatusResubmitted {
+		return nil, ErrEventAlreadyResubmitted
+	}
+
+	if req.Properties != nil {
+		quarantined.Properties = req.Properties
+	}
+	event := events.NewEvent(
+		quarantined.EventName,
+		quarantined.TenantID,
+		quarantined.ExternalCustomerID,
+		quarantined.Properties,
+		quarantined.Timestamp,
+		quarantined.ID,
+		quarantined.CustomerID,
+		quarantined.Source,
+	)
+
+	// The event is checked against the current schema of its event name, which
+	// may have been fixed instead of the event
+	schema, err := s.repo.GetByEventName(ctx, event.EventName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event schema: %w", err)
+	}
+	var violations eventschema.Violations
+	if schema != nil {
+		violations = schema.Check(event.Properties)
+	}
+
+	now := time.Now().UTC()
+	quarantined.Violations = violations
+	quarantined.UpdatedAt = now
+	quarantined.UpdatedBy = types.GetUserID(ctx)
+
+	if len(violations) > 0 {
+		if err := s.repo.UpdateQuarantinedEvent(ctx, quarantined); err != nil {
+			return nil, fmt.Errorf("failed to update quarantined event: %w", err)
+		}
+		return nil, &EventValidationError{
+			EventID:     event.ID,
+			EventName:   event.EventName,
+			Violations:  violations,
+			Quarantined: true,
+		}
+	}
+
+	if err := publishEvent(s.producer, s.cfg.Kafka, event); err != nil {
+		return nil, err
+	}
+
+	quarantined.QuarantineStatus = types.QuarantineStatusResubmitted
+	quarantined.ResubmittedAt = &now
+	if err := s.repo.UpdateQuarantinedEvent(ctx, quarantined); err != nil {
+		return nil, fmt.Errorf("failed to update quarantined event: %w", err)
+	}
+
+	s.logger.Infow("quarantined event resubmitted",
+		"event_id", event.ID,
+		"event_name", event.EventName,
+		"tenant_id", quarantined.TenantID,
+	)
+
+	return &dto.QuarantinedEventResponse{QuarantinedEvent: quarantined}, nil
+}
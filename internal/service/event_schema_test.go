@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/eventschema"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventSchemaValidation(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	broker := testutil.NewInMemoryMessageBroker()
+	schemaService := NewEventSchemaService(config.GetDefaultConfig(), testutil.NewInMemoryEventSchemaStore(), broker, nil, logger.GetLogger())
+	eventService := NewEventService(config.GetDefaultConfig(), broker, testutil.NewInMemoryEventStore(), nil, nil, schemaService, nil, nil, nil, logger.GetLogger())
+
+	rules := []eventschema.PropertyRule{
+		{Name: "tokens", Type: types.EventPropertyTypeNumber, Required: true},
+		{Name: "model", Type: types.EventPropertyTypeString, AllowedValues: []string{"small", "large"}},
+	}
+	_, err := schemaService.CreateEventSchema(ctx, dto.CreateEventSchemaRequest{EventName: "completion", Rules: rules})
+	require.NoError(t, err)
+	_, err = schemaService.CreateEventSchema(ctx, dto.CreateEventSchemaRequest{
+		EventName: "embedding",
+		Rules:     rules,
+		OnInvalid: types.InvalidEventActionQuarantine,
+	})
+	require.NoError(t, err)
+
+	ingest := func(eventID, eventName string, properties map[string]interface{}) error {
+		return eventService.CreateEvent(ctx, &dto.IngestEventRequest{
+			EventID:            eventID,
+			EventName:          eventName,
+			ExternalCustomerID: "acme",
+			Properties:         properties,
+		})
+	}
+
+	t.Run("schemas are unique per event name", func(t *testing.T) {
+		_, err := schemaService.CreateEventSchema(ctx, dto.CreateEventSchemaRequest{EventName: "completion", Rules: rules})
+		assert.ErrorIs(t, err, ErrEventSchemaExists)
+	})
+
+	t.Run("accepts valid events and events without a schema", func(t *testing.T) {
+		require.NoError(t, ingest("evt_valid", "completion", map[string]interface{}{"tokens": float64(10), "model": "small"}))
+		require.NoError(t, ingest("evt_other", "page_view", nil))
+		assert.True(t, broker.HasMessage("events", "evt_valid"))
+		assert.True(t, broker.HasMessage("events", "evt_other"))
+	})
+
+	t.Run("rejects invalid events", func(t *testing.T) {
+		err := ingest("evt_rejected", "completion", map[string]interface{}{"model": "medium"})
+
+		var validationErr *EventValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.False(t, validationErr.Quarantined)
+		assert.Equal(t, eventschema.Violations{
+			{Property: "tokens", Code: eventschema.ViolationMissing, Message: "property tokens is required"},
+			{Property: "model", Code: eventschema.ViolationNotAllowed, Message: "value medium of property model is not allowed"},
+		}, validationErr.Violations)
+		assert.False(t, broker.HasMessage("events", "evt_rejected"))
+	})
+
+	t.Run("quarantines invalid events and ingests them once fixed", func(t *testing.T) {
+		err := ingest("evt_quarantined", "embedding", map[string]interface{}{"tokens": "ten"})
+
+		var validationErr *EventValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.True(t, validationErr.Quarantined)
+		assert.False(t, broker.HasMessage("events", "evt_quarantined"))
+
+		list, err := schemaService.ListQuarantinedEvents(ctx, &types.QuarantinedEventFilter{QuarantineStatus: types.QuarantineStatusQuarantined})
+		require.NoError(t, err)
+		require.Len(t, list.Events, 1)
+		assert.Equal(t, "evt_quarantined", list.Events[0].ID)
+		assert.Equal(t, eventschema.ViolationInvalidType, list.Events[0].Violations[0].Code)
+
+		_, err = schemaService.ResubmitQuarantinedEvent(ctx, "evt_quarantined", dto.ResubmitQuarantinedEventRequest{
+			Properties: map[string]interface{}{"tokens": float64(10), "model": "huge"},
+		})
+		require.ErrorAs(t, err, &validationErr)
+		assert.False(t, broker.HasMessage("events", "evt_quarantined"))
+
+		resp, err := schemaService.ResubmitQuarantinedEvent(ctx, "evt_quarantined", dto.ResubmitQuarantinedEventRequest{
+			Properties: map[string]interface{}{"tokens": float64(10), "model": "large"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, types.QuarantineStatusResubmitted, resp.QuarantineStatus)
+		assert.Empty(t, resp.Violations)
+		assert.True(t, broker.HasMessage("events", "evt_quarantined"))
+
+		_, err = schemaService.ResubmitQuarantinedEvent(ctx, "evt_quarantined", dto.ResubmitQuarantinedEventRequest{})
+		assert.ErrorIs(t, err, ErrEventAlreadyResubmitted)
+	})
+
+	t.Run("rejects invalid schemas", func(t *testing.T) {
+		_, err := schemaService.CreateEventSchema(ctx, dto.CreateEventSchemaRequest{
+			EventName: "upload",
+			Rules:     []eventschema.PropertyRule{{Name: "size", Type: "integer"}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidEventSchema)
+	})
+}
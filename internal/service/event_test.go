@@ -3,12 +3,15 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/config"
 	"github.com/flexprice/flexprice/internal/domain/events"
 	"github.com/flexprice/flexprice/internal/domain/meter"
 	"github.com/flexprice/flexprice/internal/logger"
@@ -37,7 +40,7 @@ func (s *EventServiceSuite) SetupTest() {
 	s.store = testutil.NewInMemoryEventStore()
 	s.broker = testutil.NewInMemoryMessageBroker()
 	s.logger = logger.GetLogger()
-	s.service = NewEventService(s.broker, s.store, nil, s.logger).(*eventService)
+	s.service = NewEventService(config.GetDefaultConfig(), s.broker, s.store, nil, nil, nil, nil, nil, nil, s.logger).(*eventService)
 
 	// Setup message consumer
 	s.msgChannel = s.broker.Subscribe()
@@ -117,6 +120,70 @@ func (s *EventServiceSuite) TestCreateEvent() {
 	}
 }
 
+func (s *EventServiceSuite) TestCreateEventWithIdempotencyKey() {
+	timestamp := time.Now().Add(-time.Minute)
+	newRequest := func(key string, timestamp time.Time) *dto.IngestEventRequest {
+		return &dto.IngestEventRequest{
+			ExternalCustomerID: "customer-1",
+			EventName:          "api_request",
+			Timestamp:          timestamp,
+			IdempotencyKey:     key,
+		}
+	}
+
+	first := newRequest("req-1", timestamp)
+	s.Require().NoError(s.service.CreateEvent(s.ctx, first))
+	retry := newRequest("req-1", timestamp)
+	s.Require().NoError(s.service.CreateEvent(s.ctx, retry))
+
+	// Retries of an event get the ID derived from its key and timestamp
+	s.Equal(events.DeterministicEventID(types.GetTenantID(s.ctx), "req-1", timestamp), first.EventID)
+	s.Equal(first.EventID, retry.EventID)
+
+	other := newRequest("req-1", timestamp.Add(time.Second))
+	s.Require().NoError(s.service.CreateEvent(s.ctx, other))
+	s.NotEqual(first.EventID, other.EventID)
+
+	// An explicit event ID is kept
+	explicit := newRequest("req-1", timestamp)
+	explicit.EventID = "test-explicit"
+	s.Require().NoError(s.service.CreateEvent(s.ctx, explicit))
+	s.Equal("test-explicit", explicit.EventID)
+
+	// Without a timestamp every retry would get another ID
+	s.Error(s.service.CreateEvent(s.ctx, newRequest("req-2", time.Time{})))
+}
+
+func (s *EventServiceSuite) TestCreateEventRoutesToLane() {
+	cfg := config.GetDefaultConfig()
+	cfg.Kafka.Lanes = []config.KafkaLaneConfig{
+		{Name: "billing", Topic: "events_billing", ConsumerGroup: "billing", EventNames: []string{"invoice_usage"}},
+		{Name: "noisy", Topic: "events_noisy", ConsumerGroup: "noisy", TenantIDs: []string{types.GetTenantID(s.ctx)}},
+	}
+	service := NewEventService(cfg, s.broker, s.store, nil, nil, nil, nil, nil, nil, s.logger)
+
+	ingest := func(id, eventName string) {
+		s.Require().NoError(service.CreateEvent(s.ctx, &dto.IngestEventRequest{
+			EventID:            id,
+			ExternalCustomerID: "customer-1",
+			EventName:          eventName,
+			Timestamp:          time.Now(),
+		}))
+	}
+
+	// An event goes to the first lane it matches
+	ingest("lane-1", "invoice_usage")
+	s.True(s.broker.HasMessage("events_billing", "lane-1"))
+
+	ingest("lane-2", "api_request")
+	s.True(s.broker.HasMessage("events_noisy", "lane-2"))
+	s.False(s.broker.HasMessage("events", "lane-2"))
+
+	cfg.Kafka.Lanes = cfg.Kafka.Lanes[:1]
+	ingest("lane-3", "api_request")
+	s.True(s.broker.HasMessage("events", "lane-3"))
+}
+
 func (s *EventServiceSuite) TestGetUsage() {
 	// Setup test data with properties for filtering
 	testingEvents := []*dto.IngestEventRequest{
@@ -284,7 +351,7 @@ func (s *EventServiceSuite) TestGetUsageByMeter() {
 	s.NoError(err)
 
 	// Setup the event service with the mocked meter repository
-	s.service = NewEventService(s.broker, s.store, mockedMeterRepo, s.logger).(*eventService)
+	s.service = NewEventService(config.GetDefaultConfig(), s.broker, s.store, mockedMeterRepo, nil, nil, nil, nil, nil, s.logger).(*eventService)
 
 	// Setup test events
 	testingEvents := []*dto.IngestEventRequest{
@@ -358,6 +425,126 @@ func (s *EventServiceSuite) TestGetUsageByMeter() {
 	s.Equal(types.AggregationSum, result.Type)
 }
 
+func (s *EventServiceSuite) TestGetUsageByMeterCalendarReset() {
+	testMeter := &meter.Meter{
+		ID:        "meter-daily",
+		Name:      "Daily Meter",
+		EventName: "api_request",
+		Aggregation: meter.Aggregation{
+			Type:  types.AggregationSum,
+			Field: "duration_ms",
+		},
+		ResetUsage: types.ResetUsageDaily,
+		BaseModel: types.BaseModel{
+			TenantID: types.GetTenantID(s.ctx),
+		},
+	}
+
+	mockedMeterRepo := testutil.NewInMemoryMeterStore()
+	s.NoError(mockedMeterRepo.CreateMeter(s.ctx, testMeter))
+	s.service = NewEventService(config.GetDefaultConfig(), s.broker, s.store, mockedMeterRepo, nil, nil, nil, nil, nil, s.logger).(*eventService)
+
+	// The subscription period started days ago but usage resets every day
+	periodStart := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+	for i, ts := range []time.Time{
+		time.Date(2024, time.March, 4, 23, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 5, 1, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC),
+	} {
+		event := events.NewEvent("api_request", types.GetTenantID(s.ctx), "cust-1",
+			map[string]interface{}{"duration_ms": float64(100 * (i + 1))}, ts, "", "", "")
+		s.NoError(s.store.InsertEvent(s.ctx, event))
+	}
+
+	req := &dto.GetUsageByMeterRequest{
+		MeterID:            testMeter.ID,
+		ExternalCustomerID: "cust-1",
+		StartTime:          periodStart,
+		EndTime:            now,
+	}
+	result, err := s.service.GetUsageByMeter(s.ctx, req)
+	s.Require().NoError(err)
+	s.Equal(float64(500), result.Value.InexactFloat64())
+	s.Equal(periodStart, req.StartTime, "the request of the caller is left as is")
+	s.Equal(time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), *result.ResetPeriodStart)
+	s.Equal(time.Date(2024, time.March, 6, 0, 0, 0, 0, time.UTC), *result.ResetPeriodEnd)
+
+	// In New York the day started at 05:00 UTC, so the 01:00 UTC event belongs to the previous day
+	result, err = s.service.GetUsageByMeter(s.ctx, &dto.GetUsageByMeterRequest{
+		MeterID:            testMeter.ID,
+		ExternalCustomerID: "cust-1",
+		StartTime:          periodStart,
+		EndTime:            now,
+		Timezone:           "America/New_York",
+	})
+	s.Require().NoError(err)
+	s.Equal(float64(300), result.Value.InexactFloat64())
+
+	_, err = s.service.GetUsageByMeter(s.ctx, &dto.GetUsageByMeterRequest{
+		MeterID:   testMeter.ID,
+		StartTime: periodStart,
+		EndTime:   now,
+		Timezone:  "Mars/Olympus",
+	})
+	s.Error(err)
+}
+
+func (s *EventServiceSuite) TestGetUsageByMeterResetPeriods() {
+	meterCreatedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mockedMeterRepo := testutil.NewInMemoryMeterStore()
+	for _, m := range []*meter.Meter{
+		{ID: "meter-never", ResetUsage: types.ResetUsageNever},
+		{ID: "meter-billing", ResetUsage: types.ResetUsageBillingPeriod},
+		{ID: "meter-monthly", ResetUsage: types.ResetUsageMonthly},
+	} {
+		m.Name = m.ID
+		m.EventName = "api_request"
+		m.Aggregation = meter.Aggregation{Type: types.AggregationSum, Field: "duration_ms"}
+		m.BaseModel = types.BaseModel{TenantID: types.GetTenantID(s.ctx), CreatedAt: meterCreatedAt}
+		s.NoError(mockedMeterRepo.CreateMeter(s.ctx, m))
+	}
+
+	// The windows left open end at the time of the clock
+	simulatedClock := clock.NewSimulated()
+	now := time.Date(2030, time.March, 10, 12, 0, 0, 0, time.UTC)
+	s.Require().NoError(simulatedClock.AdvanceTo(s.ctx, now))
+	s.service = NewEventService(config.GetDefaultConfig(), s.broker, s.store, mockedMeterRepo, nil, nil, nil, nil, simulatedClock, s.logger).(*eventService)
+
+	for i, ts := range []time.Time{
+		time.Date(2030, time.February, 20, 0, 0, 0, 0, time.UTC),
+		time.Date(2030, time.March, 5, 0, 0, 0, 0, time.UTC),
+	} {
+		event := events.NewEvent("api_request", types.GetTenantID(s.ctx), "cust-1",
+			map[string]interface{}{"duration_ms": float64(100 * (i + 1))}, ts, "", "", "")
+		s.NoError(s.store.InsertEvent(s.ctx, event))
+	}
+
+	periodStart := time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		meterID    string
+		value      float64
+		resetStart time.Time
+		resetEnd   time.Time
+	}{
+		{"meter-never", 300, meterCreatedAt, now},
+		{"meter-billing", 200, periodStart, now},
+		{"meter-monthly", 200, periodStart, time.Date(2030, time.April, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		result, err := s.service.GetUsageByMeter(s.ctx, &dto.GetUsageByMeterRequest{
+			MeterID:            tc.meterID,
+			ExternalCustomerID: "cust-1",
+			StartTime:          periodStart,
+		})
+		s.Require().NoError(err, tc.meterID)
+		s.Equal(tc.value, result.Value.InexactFloat64(), tc.meterID)
+		s.Require().NotNil(result.ResetPeriodStart, tc.meterID)
+		s.Require().NotNil(result.ResetPeriodEnd, tc.meterID)
+		s.Equal(tc.resetStart, *result.ResetPeriodStart, tc.meterID)
+		s.WithinDuration(tc.resetEnd, *result.ResetPeriodEnd, time.Minute, tc.meterID)
+	}
+}
+
 func (s *EventServiceSuite) TestGetEvents() {
 	now := time.Now()
 	// Setup test data
@@ -481,3 +668,76 @@ func (s *EventServiceSuite) TestGetEvents() {
 		s.Equal("evt-5", result.Events[0].ID) // Only the new event
 	})
 }
+
+func (s *EventServiceSuite) TestExportEvents() {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	total := exportBatchSize + 5
+	for i := 0; i < total; i++ {
+		s.NoError(s.store.InsertEvent(s.ctx, &events.Event{
+			ID:                 fmt.Sprintf("evt-%05d", i),
+			TenantID:           types.GetTenantID(s.ctx),
+			ExternalCustomerID: "cust-1",
+			EventName:          "api_request",
+			Timestamp:          start.Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	// Outside of the range
+	s.NoError(s.store.InsertEvent(s.ctx, &events.Event{
+		ID:                 "evt-late",
+		TenantID:           types.GetTenantID(s.ctx),
+		ExternalCustomerID: "cust-1",
+		EventName:          "api_request",
+		Timestamp:          start.AddDate(0, 1, 0),
+	}))
+
+	req := &dto.ExportEventsRequest{
+		StartTime: start,
+		EndTime:   start.AddDate(0, 0, 1),
+	}
+
+	export := func(req *dto.ExportEventsRequest, stopAfter int) ([]*dto.ExportedEvent, error) {
+		var exported []*dto.ExportedEvent
+		err := s.service.ExportEvents(s.ctx, req, func(batch []*dto.ExportedEvent) error {
+			exported = append(exported, batch...)
+			if stopAfter > 0 && len(exported) >= stopAfter {
+				return fmt.Errorf("client went away")
+			}
+			return nil
+		})
+		return exported, err
+	}
+
+	s.Run("exports_the_range_in_batches", func() {
+		exported, err := export(req, 0)
+		s.NoError(err)
+		s.Len(exported, total)
+		s.Equal("evt-00000", exported[0].ID)
+		s.Equal(fmt.Sprintf("evt-%05d", total-1), exported[total-1].ID)
+	})
+
+	s.Run("resumes_from_cursor", func() {
+		exported, err := export(req, 1)
+		s.Error(err)
+		s.Len(exported, exportBatchSize)
+
+		resumed := *req
+		resumed.Cursor = exported[9].Cursor
+		rest, err := export(&resumed, 0)
+		s.NoError(err)
+		s.Len(rest, total-10)
+		s.Equal("evt-00010", rest[0].ID)
+	})
+
+	s.Run("invalid_cursor", func() {
+		invalid := *req
+		invalid.Cursor = "not-a-cursor"
+		_, err := export(&invalid, 0)
+		s.ErrorIs(err, ErrInvalidExportCursor)
+	})
+
+	s.Run("invalid_range", func() {
+		_, err := export(&dto.ExportEventsRequest{StartTime: start, EndTime: start}, 0)
+		s.Error(err)
+	})
+}
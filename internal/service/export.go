@@ -0,0 +1,526 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/export"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	exportWriter "github.com/flexprice/flexprice/internal/export"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/storage"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const (
+	exportPageSize         = 5000
+	defaultExportURLExpiry = 60 * time.Minute
+
+	exportClaimBatch = 20
+	// exportClaimLease is how long a claimed export may run before it is
+	// considered stuck, ex after a restart, and claimed again
+	exportClaimLease = time.Hour
+)
+
+type ExportService interface {
+	// CreateExport creates a pending export, run by the process_exports job.
+	// The caller polls the export for its status
+	CreateExport(ctx context.Context, req dto.CreateExportRequest) (*dto.ExportResponse, error)
+	GetExport(ctx context.Context, id string) (*dto.ExportResponse, error)
+	GetExports(ctx context.Context, filter types.Filter) (*dto.ListExportsResponse, error)
+	GetDownloadURL(ctx context.Context, id string) (*dto.ExportDownloadResponse, error)
+
+	CreateSchedule(ctx context.Context, req dto.CreateExportScheduleRequest) (*dto.ExportScheduleResponse, error)
+	GetSchedule(ctx context.Context, id string) (*dto.ExportScheduleResponse, error)
+	ListSchedules(ctx context.Context, filter types.Filter) (*dto.ListExportSchedulesResponse, error)
+	DeleteSchedule(ctx context.Context, id string) error
+
+	// ProcessExports creates the exports of the schedules of all tenants due
+	// at now, then runs the pending exports and the exports whose run was
+	// interrupted
+	ProcessExports(ctx context.Context, now time.Time) error
+}
+
+type exportService struct {
+	repo      export.Repository
+	eventRepo events.Repository
+	meterRepo meter.Repository
+	store     storage.Store
+	cfg       config.ExportConfig
+	logger    *logger.Logger
+}
+
+func NewExportService(
+	repo export.Repository,
+	eventRepo events.Repository,
+	meterRepo meter.Repository,
+	store storage.Store,
+	cfg *config.Configuration,
+	logger *logger.Logger,
+) ExportService {
+	return &exportService{
+		repo:      repo,
+		eventRepo: eventRepo,
+		meterRepo: meterRepo,
+		store:     store,
+		cfg:       cfg.Export,
+		logger:    logger,
+	}
+}
+
+func (s *exportService) CreateExport(ctx context.Context, req dto.CreateExportRequest) (*dto.ExportResponse, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("exports are not configured")
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	e := req.ToExport(ctx)
+
+	if e.Type == types.ExportTypeUsage {
+		eventName, err := s.meterEventName(ctx, e.MeterID)
+		if err != nil {
+			return nil, err
+		}
+		e.EventName = eventName
+	}
+
+	if err := s.repo.Create(ctx, e); err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	return &dto.ExportResponse{Export: e}, nil
+}
+
+func (s *exportService) meterEventName(ctx context.Context, meterID string) (string, error) {
+	m, err := s.meterRepo.GetMeter(ctx, meterID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get meter: %w", err)
+	}
+	return m.EventName, nil
+}
+
+func (s *exportService) GetExport(ctx context.Context, id string) (*dto.ExportResponse, error) {
+	e, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+
+	return &dto.ExportResponse{Export: e}, nil
+}
+
+func (s *exportService) GetExports(ctx context.Context, filter types.Filter) (*dto.ListExportsResponse, error) {
+	exports, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exports: %w", err)
+	}
+
+	response := &dto.ListExportsResponse{
+		Exports: make([]dto.ExportResponse, len(exports)),
+	}
+
+	for i, e := range exports {
+		response.Exports[i] = dto.ExportResponse{Export: e}
+	}
+
+	response.Total = len(exports)
+	response.Offset = filter.Offset
+	response.Limit = filter.Limit
+
+	return response, nil
+}
+
+func (s *exportService) GetDownloadURL(ctx context.Context, id string) (*dto.ExportDownloadResponse, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("exports are not configured")
+	}
+
+	e, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+
+	if e.ExportStatus != types.ExportStatusCompleted {
+		return nil, fmt.Errorf("export is not completed, current status: %s", e.ExportStatus)
+	}
+
+	expiry := defaultExportURLExpiry
+	if s.cfg.URLExpiryMins > 0 {
+		expiry = time.Duration(s.cfg.URLExpiryMins) * time.Minute
+	}
+
+	url, err := s.store.PresignGetURL(ctx, e.ObjectKey, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get download url: %w", err)
+	}
+
+	return &dto.ExportDownloadResponse{
+		URL:       url,
+		ExpiresAt: time.Now().UTC().Add(expiry),
+	}, nil
+}
+
+func (s *exportService) CreateSchedule(ctx context.Context, req dto.CreateExportScheduleRequest) (*dto.ExportScheduleResponse, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("exports are not configured")
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	schedule := req.ToSchedule(ctx, time.Now().UTC())
+
+	if schedule.Type == types.ExportTypeUsage {
+		eventName, err := s.meterEventName(ctx, schedule.MeterID)
+		if err != nil {
+			return nil, err
+		}
+		schedule.EventName = eventName
+	}
+
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to create export schedule: %w", err)
+	}
+
+	return &dto.ExportScheduleResponse{Schedule: schedule}, nil
+}
+
+func (s *exportService) GetSchedule(ctx context.Context, id string) (*dto.ExportScheduleResponse, error) {
+	schedule, err := s.repo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export schedule: %w", err)
+	}
+
+	return &dto.ExportScheduleResponse{Schedule: schedule}, nil
+}
+
+func (s *exportService) ListSchedules(ctx context.Context, filter types.Filter) (*dto.ListExportSchedulesResponse, error) {
+	schedules, err := s.repo.ListSchedules(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export schedules: %w", err)
+	}
+
+	response := &dto.ListExportSchedulesResponse{
+		Schedules: make([]dto.ExportScheduleResponse, len(schedules)),
+		Total:     len(schedules),
+		Offset:    filter.Offset,
+		Limit:     filter.Limit,
+	}
+	for i, schedule := range schedules {
+		response.Schedules[i] = dto.ExportScheduleResponse{Schedule: schedule}
+	}
+
+	return response, nil
+}
+
+func (s *exportService) DeleteSchedule(ctx context.Context, id string) error {
+	if err := s.repo.DeleteSchedule(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete export schedule: %w", err)
+	}
+	return nil
+}
+
+func (s *exportService) ProcessExports(ctx context.Context, now time.Time) error {
+	if s.store == nil {
+		return nil
+	}
+
+	now = now.UTC()
+	for ctx.Err() == nil {
+		schedules, err := s.repo.ClaimDueSchedules(ctx, now, exportClaimLease, exportClaimBatch)
+		if err != nil {
+			return fmt.Errorf("failed to claim export schedules: %w", err)
+		}
+
+		forEachJobItem(ctx, schedules, func(schedule *export.Schedule) {
+			tenantCtx := context.WithValue(ctx, types.CtxTenantID, schedule.TenantID)
+			tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+			if err := s.createScheduledExport(tenantCtx, schedule, now); err != nil {
+				s.logger.Errorw("failed to create scheduled export",
+					"tenant_id", schedule.TenantID,
+					"schedule_id", schedule.ID,
+					"error", err,
+				)
+			}
+		})
+
+		if len(schedules) < exportClaimBatch {
+			break
+		}
+	}
+
+	for ctx.Err() == nil {
+		// Claimed exports are marked processing, so each export is run once
+		// per claim and the loop ends when no pending export is left
+		exports, err := s.repo.ClaimPending(ctx, now, exportClaimLease, exportClaimBatch)
+		if err != nil {
+			return fmt.Errorf("failed to claim exports: %w", err)
+		}
+
+		forEachJobItem(ctx, exports, func(e *export.Export) {
+			tenantCtx := context.WithValue(ctx, types.CtxTenantID, e.TenantID)
+			tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+			s.runExport(tenantCtx, e)
+		})
+
+		if len(exports) < exportClaimBatch {
+			break
+		}
+	}
+	return nil
+}
+
+// createScheduledExport schedules the next run of the schedule and creates
+// the pending export of the period before now. The next run is scheduled
+// first so that a failing schedule is not retried before its next occurrence
+func (s *exportService) createScheduledExport(ctx context.Context, schedule *export.Schedule, now time.Time) error {
+	next := schedule.Schedule.Next(now)
+	schedule.NextRunAt = &next
+	schedule.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateSchedule(ctx, schedule); err != nil {
+		return fmt.Errorf("failed to schedule export: %w", err)
+	}
+
+	e, ok := schedule.NewExport(now, types.GetDefaultBaseModel(ctx))
+	if !ok {
+		return fmt.Errorf("invalid schedule: %s", schedule.Schedule)
+	}
+	if err := s.repo.Create(ctx, e); err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	return nil
+}
+
+// runExport runs an export claimed by ProcessExports
+func (s *exportService) runExport(ctx context.Context, e *export.Export) {
+	rowCount, err := s.writeAndUpload(ctx, e)
+	if err != nil {
+		s.logger.Errorw("export failed", "export_id", e.ID, "error", err)
+		s.setStatus(ctx, e, types.ExportStatusFailed, err)
+		return
+	}
+
+	e.RowCount = rowCount
+	s.setStatus(ctx, e, types.ExportStatusCompleted, nil)
+}
+
+func (s *exportService) setStatus(ctx context.Context, e *export.Export, status types.ExportStatus, cause error) {
+	now := time.Now().UTC()
+	e.ExportStatus = status
+	e.UpdatedAt = now
+	if cause != nil {
+		e.Error = cause.Error()
+	}
+	if status == types.ExportStatusCompleted || status == types.ExportStatusFailed {
+		e.CompletedAt = &now
+		e.LeaseUntil = nil
+	}
+
+	if err := s.repo.Update(ctx, e); err != nil {
+		s.logger.Errorw("failed to update export status", "export_id", e.ID, "status", status, "error", err)
+	}
+}
+
+// writeAndUpload writes the export into a temporary file so that memory usage
+// stays bounded for large exports, and then uploads it to the bucket
+func (s *exportService) writeAndUpload(ctx context.Context, e *export.Export) (int64, error) {
+	f, err := os.CreateTemp("", "flexprice-export-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	var rowCount int64
+	switch e.Type {
+	case types.ExportTypeEvents:
+		rowCount, err = s.writeEvents(ctx, e, f)
+	case types.ExportTypeUsage:
+		rowCount, err = s.writeUsage(ctx, e, f)
+	default:
+		err = fmt.Errorf("unsupported export type: %s", e.Type)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		return 0, fmt.Errorf("failed to rewind export file: %w", err)
+	}
+
+	e.ObjectKey = path.Join(
+		s.cfg.Prefix,
+		e.TenantID,
+		fmt.Sprintf("%s.%s", e.ID, e.Format.Extension()),
+	)
+
+	if err := s.store.Upload(ctx, e.ObjectKey, f, e.Format.ContentType()); err != nil {
+		return 0, err
+	}
+
+	return rowCount, nil
+}
+
+func (s *exportService) writeEvents(ctx context.Context, e *export.Export, f *os.File) (int64, error) {
+	w, err := exportWriter.NewWriter[exportWriter.EventRow](e.Format, f)
+	if err != nil {
+		return 0, err
+	}
+
+	var rowCount int64
+	var iterLast *events.EventIterator
+	for {
+		page, err := s.eventRepo.GetEvents(ctx, &events.GetEventsParams{
+			ExternalCustomerID: e.ExternalCustomerID,
+			EventName:          e.EventName,
+			StartTime:          e.StartTime,
+			EndTime:            e.EndTime,
+			IterLast:           iterLast,
+			PageSize:           exportPageSize,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to get events: %w", err)
+		}
+
+		rows := make([]exportWriter.EventRow, len(page))
+		for i, event := range page {
+			properties, err := json.Marshal(event.Properties)
+			if err != nil {
+				return 0, fmt.Errorf("failed to marshal event properties: %w", err)
+			}
+
+			rows[i] = exportWriter.EventRow{
+				ID:                 event.ID,
+				TenantID:           event.TenantID,
+				EventName:          event.EventName,
+				CustomerID:         event.CustomerID,
+				ExternalCustomerID: event.ExternalCustomerID,
+				Source:             event.Source,
+				Timestamp:          event.Timestamp,
+				Properties:         string(properties),
+			}
+		}
+
+		if err := w.Write(rows); err != nil {
+			return 0, err
+		}
+		rowCount += int64(len(rows))
+
+		if len(page) < exportPageSize {
+			break
+		}
+
+		last := page[len(page)-1]
+		iterLast = &events.EventIterator{Timestamp: last.Timestamp, ID: last.ID}
+	}
+
+	if err := w.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finalize export file: %w", err)
+	}
+
+	return rowCount, nil
+}
+
+// getUsage returns the windowed usage of the meter of a usage export. Each
+// window is aggregated with the versions of the meter effective over it, and
+// the windows of derived meters are evaluated over the windows of their meters
+func (s *exportService) getUsage(ctx context.Context, m *meter.Meter, e *export.Export) (*events.AggregationResult, error) {
+	var results []*events.AggregationResult
+	var err error
+	if m.IsDerived() {
+		results, err = getDerivedUsage(m, func(meterID string) ([]*events.AggregationResult, error) {
+			formulaMeter, err := s.meterRepo.GetMeter(ctx, meterID)
+			if err != nil {
+				return nil, err
+			}
+			usage, err := s.getUsage(ctx, formulaMeter, e)
+			if err != nil {
+				return nil, err
+			}
+			return []*events.AggregationResult{usage}, nil
+		})
+	} else {
+		var segments []meter.Segment
+		if segments, err = getMeterSegments(ctx, s.meterRepo, m, e.StartTime, e.EndTime); err != nil {
+			return nil, err
+		}
+
+		results, err = getSegmentedUsage(segments, func(segment meter.Segment, aggregation types.AggregationType) ([]*events.AggregationResult, error) {
+			usage, err := s.eventRepo.GetUsage(ctx, &events.UsageParams{
+				ExternalCustomerID: e.ExternalCustomerID,
+				EventName:          m.EventName,
+				PropertyName:       segment.Aggregation.Field,
+				AggregationType:    aggregation,
+				WindowSize:         e.WindowSize,
+				StartTime:          segment.Start,
+				EndTime:            segment.End,
+				Filters:            meterFilters(segment.Filters),
+				Precision:          segment.Aggregation.Precision,
+				Percentile:         segment.Aggregation.Percentile,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return []*events.AggregationResult{usage}, nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return &events.AggregationResult{Type: m.Aggregation.Type}, nil
+	}
+	return results[0], nil
+}
+
+func (s *exportService) writeUsage(ctx context.Context, e *export.Export, f *os.File) (int64, error) {
+	m, err := s.meterRepo.GetMeter(ctx, e.MeterID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get meter: %w", err)
+	}
+
+	result, err := s.getUsage(ctx, m, e)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	w, err := exportWriter.NewWriter[exportWriter.UsageRow](e.Format, f)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := make([]exportWriter.UsageRow, len(result.Results))
+	for i, r := range result.Results {
+		rows[i] = exportWriter.UsageRow{
+			MeterID:         m.ID,
+			EventName:       m.EventName,
+			AggregationType: string(result.Type),
+			WindowStart:     r.WindowSize,
+			Value:           r.Value.String(),
+		}
+	}
+
+	if err := w.Write(rows); err != nil {
+		return 0, err
+	}
+
+	if err := w.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finalize export file: %w", err)
+	}
+
+	return int64(len(rows)), nil
+}
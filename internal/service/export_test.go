@@ -0,0 +1,168 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/export"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportService_ProcessExports(t *testing.T) {
+	ctx := testutil.SetupContext()
+	now := time.Date(2024, 6, 1, 0, 5, 0, 0, time.UTC)
+
+	eventStore := testutil.NewInMemoryEventStore()
+	for id, timestamp := range map[string]time.Time{
+		"ev_1": time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC),
+		"ev_2": time.Date(2024, 5, 30, 12, 0, 0, 0, time.UTC),
+	} {
+		require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+			ID:                 id,
+			TenantID:           types.GetTenantID(ctx),
+			EventName:          "api_request",
+			ExternalCustomerID: "cust_1",
+			Timestamp:          timestamp,
+			Properties:         map[string]interface{}{},
+		}))
+	}
+
+	exportStore := testutil.NewInMemoryExportStore()
+	objectStore := testutil.NewInMemoryObjectStore()
+	svc := NewExportService(
+		exportStore,
+		eventStore,
+		testutil.NewInMemoryMeterStore(),
+		objectStore,
+		&config.Configuration{Export: config.ExportConfig{Prefix: "exports"}},
+		logger.GetLogger(),
+	)
+
+	// Exports created through the API are left pending for the job
+	created, err := svc.CreateExport(ctx, dto.CreateExportRequest{
+		Type:      types.ExportTypeEvents,
+		Format:    types.ExportFormatCSV,
+		EventName: "api_request",
+		StartTime: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.ExportStatusPending, created.ExportStatus)
+
+	// An export interrupted by a restart, and an export still being run
+	newProcessing := func(id string, leaseUntil time.Time) {
+		base := types.GetDefaultBaseModel(ctx)
+		base.CreatedAt = now.Add(-2 * time.Hour)
+		require.NoError(t, exportStore.Create(ctx, &export.Export{
+			ID:           id,
+			Type:         types.ExportTypeEvents,
+			Format:       types.ExportFormatCSV,
+			ExportStatus: types.ExportStatusProcessing,
+			EventName:    "api_request",
+			StartTime:    time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+			EndTime:      time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			LeaseUntil:   &leaseUntil,
+			BaseModel:    base,
+		}))
+	}
+	newProcessing("export_stuck", now.Add(-time.Minute))
+	newProcessing("export_running", now.Add(time.Minute))
+
+	schedule, err := svc.CreateSchedule(ctx, dto.CreateExportScheduleRequest{
+		Name:      "Daily events",
+		Type:      types.ExportTypeEvents,
+		Format:    types.ExportFormatCSV,
+		EventName: "api_request",
+		Schedule:  types.ReportScheduleDaily,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, schedule.NextRunAt)
+
+	due := now.Add(-5 * time.Minute)
+	schedule.NextRunAt = &due
+	require.NoError(t, exportStore.UpdateSchedule(ctx, schedule.Schedule))
+
+	require.NoError(t, svc.ProcessExports(ctx, now))
+
+	getExport := func(id string) *export.Export {
+		e, err := exportStore.Get(ctx, id)
+		require.NoError(t, err)
+		return e
+	}
+
+	completed := getExport(created.ID)
+	assert.Equal(t, types.ExportStatusCompleted, completed.ExportStatus)
+	assert.Equal(t, int64(2), completed.RowCount)
+	assert.Nil(t, completed.LeaseUntil)
+	_, ok := objectStore.Object(completed.ObjectKey)
+	assert.True(t, ok)
+
+	assert.Equal(t, types.ExportStatusCompleted, getExport("export_stuck").ExportStatus)
+	assert.Equal(t, types.ExportStatusProcessing, getExport("export_running").ExportStatus)
+
+	// The schedule exported the previous day and its next run is tomorrow
+	exports, err := exportStore.List(ctx, types.Filter{})
+	require.NoError(t, err)
+	require.Len(t, exports, 4)
+
+	var scheduled *export.Export
+	for _, e := range exports {
+		if e.ScheduleID == schedule.ID {
+			scheduled = e
+		}
+	}
+	require.NotNil(t, scheduled)
+	assert.Equal(t, types.ExportStatusCompleted, scheduled.ExportStatus)
+	assert.Equal(t, time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), scheduled.StartTime)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), scheduled.EndTime)
+	assert.Equal(t, int64(1), scheduled.RowCount)
+
+	updated, err := svc.GetSchedule(ctx, schedule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC), *updated.NextRunAt)
+
+	// Nothing is due or pending on the next run
+	require.NoError(t, svc.ProcessExports(ctx, now.Add(time.Minute)))
+	exports, err = exportStore.List(ctx, types.Filter{})
+	require.NoError(t, err)
+	assert.Len(t, exports, 4)
+
+	// Deleted schedules are not run
+	require.NoError(t, svc.DeleteSchedule(ctx, schedule.ID))
+	require.NoError(t, svc.ProcessExports(ctx, time.Date(2024, 6, 2, 0, 5, 0, 0, time.UTC)))
+	exports, err = exportStore.List(ctx, types.Filter{})
+	require.NoError(t, err)
+	assert.Len(t, exports, 4)
+}
+
+func TestExportService_CreateScheduleInvalid(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc := NewExportService(
+		testutil.NewInMemoryExportStore(),
+		testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(),
+		testutil.NewInMemoryObjectStore(),
+		&config.Configuration{},
+		logger.GetLogger(),
+	)
+
+	_, err := svc.CreateSchedule(ctx, dto.CreateExportScheduleRequest{
+		Type:      types.ExportTypeEvents,
+		EventName: "api_request",
+		Schedule:  "hourly",
+	})
+	assert.Error(t, err)
+
+	_, err = svc.CreateSchedule(ctx, dto.CreateExportScheduleRequest{
+		Type:     types.ExportTypeUsage,
+		Schedule: types.ReportScheduleDaily,
+	})
+	assert.Error(t, err)
+}
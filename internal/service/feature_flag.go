@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/featureflag"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// ErrUnknownFeatureFlag is returned for overrides of flags which do not exist
+var ErrUnknownFeatureFlag = errors.New("unknown feature flag")
+
+type FeatureFlagService interface {
+	// IsEnabled returns whether the flag is on for the tenant and environment
+	// in context. It falls back on the default of the flag when the overrides
+	// cannot be read, so billing never stops on a flag lookup
+	IsEnabled(ctx context.Context, flag types.FeatureFlag) bool
+
+	ListFlags(ctx context.Context, tenantID string) (*dto.ListFeatureFlagsResponse, error)
+	SetOverride(ctx context.Context, tenantID string, flag types.FeatureFlag, req *dto.SetFeatureFlagRequest) (*dto.FeatureFlagOverrideResponse, error)
+	DeleteOverride(ctx context.Context, tenantID string, flag types.FeatureFlag, environmentID string) error
+}
+
+type featureFlagService struct {
+	featureFlagRepo featureflag.Repository
+	logger          *logger.Logger
+}
+
+func NewFeatureFlagService(featureFlagRepo featureflag.Repository, logger *logger.Logger) FeatureFlagService {
+	return &featureFlagService{
+		featureFlagRepo: featureFlagRepo,
+		logger:          logger,
+	}
+}
+
+func (s *featureFlagService) IsEnabled(ctx context.Context, flag types.FeatureFlag) bool {
+	tenantID := types.GetTenantID(ctx)
+	if tenantID == "" {
+		return flag.Default()
+	}
+
+	overrides, err := s.featureFlagRepo.List(ctx, tenantID)
+	if err != nil {
+		s.logger.Errorw("failed to list feature flag overrides, using the default",
+			"tenant_id", tenantID,
+			"flag", flag,
+			"error", err,
+		)
+		return flag.Default()
+	}
+
+	return featureflag.Resolve(overrides, flag, types.GetEnvironmentID(ctx))
+}
+
+func (s *featureFlagService) ListFlags(ctx context.Context, tenantID string) (*dto.ListFeatureFlagsResponse, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+
+	overrides, err := s.featureFlagRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ListFeatureFlagsResponse{
+		TenantID: tenantID,
+		Flags:    make([]dto.FeatureFlagResponse, 0, len(types.FeatureFlags)),
+	}
+	for flag, enabled := range types.FeatureFlags {
+		f := dto.FeatureFlagResponse{
+			Flag:      flag,
+			Default:   enabled,
+			Overrides: []dto.FeatureFlagOverrideResponse{},
+		}
+		for _, o := range overrides {
+			if o.Flag == flag {
+				f.Overrides = append(f.Overrides, dto.FeatureFlagOverrideResponse{Override: o})
+			}
+		}
+		resp.Flags = append(resp.Flags, f)
+	}
+	sort.Slice(resp.Flags, func(i, j int) bool {
+		return resp.Flags[i].Flag < resp.Flags[j].Flag
+	})
+
+	return resp, nil
+}
+
+func (s *featureFlagService) SetOverride(ctx context.Context, tenantID string, flag types.FeatureFlag, req *dto.SetFeatureFlagRequest) (*dto.FeatureFlagOverrideResponse, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+	if !flag.Validate() {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFeatureFlag, flag)
+	}
+
+	// The override belongs to the target tenant, not to the caller
+	override := &featureflag.Override{
+		ID:            types.GenerateUUID(),
+		Flag:          flag,
+		EnvironmentID: req.EnvironmentID,
+		Enabled:       req.Enabled,
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+	override.TenantID = tenantID
+
+	if err := s.featureFlagRepo.Upsert(ctx, override); err != nil {
+		return nil, fmt.Errorf("failed to set feature flag override: %w", err)
+	}
+
+	s.logger.Infow("set feature flag override",
+		"tenant_id", tenantID,
+		"environment_id", req.EnvironmentID,
+		"flag", flag,
+		"enabled", req.Enabled,
+	)
+
+	return &dto.FeatureFlagOverrideResponse{Override: override}, nil
+}
+
+func (s *featureFlagService) DeleteOverride(ctx context.Context, tenantID string, flag types.FeatureFlag, environmentID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenant_id is required")
+	}
+	return s.featureFlagRepo.Delete(ctx, tenantID, flag, environmentID)
+}
+
+// featureEnabled returns whether the flag is on in ctx, or its default for the
+// services built without a feature flag service
+func featureEnabled(ctx context.Context, featureFlagService FeatureFlagService, flag types.FeatureFlag) bool {
+	if featureFlagService == nil {
+		return flag.Default()
+	}
+	return featureFlagService.IsEnabled(ctx, flag)
+}
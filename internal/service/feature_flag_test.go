@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlagService_IsEnabled(t *testing.T) {
+	ctx := testutil.SetupContext()
+	tenantID := types.GetTenantID(ctx)
+	prodCtx := context.WithValue(ctx, types.CtxEnvironmentID, "env_prod")
+	sandboxCtx := context.WithValue(ctx, types.CtxEnvironmentID, "env_sandbox")
+
+	svc := NewFeatureFlagService(testutil.NewInMemoryFeatureFlagStore(), logger.GetLogger())
+	flag := types.FeatureFlagMidPeriodProration
+
+	// Flags keep their default without overrides
+	assert.True(t, svc.IsEnabled(prodCtx, flag))
+
+	// Overrides of the tenant apply to all its environments
+	_, err := svc.SetOverride(ctx, tenantID, flag, &dto.SetFeatureFlagRequest{Enabled: false})
+	require.NoError(t, err)
+	assert.False(t, svc.IsEnabled(prodCtx, flag))
+	assert.False(t, svc.IsEnabled(sandboxCtx, flag))
+
+	// Overrides of an environment take precedence
+	_, err = svc.SetOverride(ctx, tenantID, flag, &dto.SetFeatureFlagRequest{EnvironmentID: "env_sandbox", Enabled: true})
+	require.NoError(t, err)
+	assert.False(t, svc.IsEnabled(prodCtx, flag))
+	assert.True(t, svc.IsEnabled(sandboxCtx, flag))
+
+	// Other tenants and flags are not affected
+	otherCtx := context.WithValue(prodCtx, types.CtxTenantID, "tenant_other")
+	assert.True(t, svc.IsEnabled(otherCtx, flag))
+	assert.True(t, svc.IsEnabled(prodCtx, types.FeatureFlagThresholdBilling))
+
+	resp, err := svc.ListFlags(ctx, tenantID)
+	require.NoError(t, err)
+	require.Len(t, resp.Flags, len(types.FeatureFlags))
+	for _, f := range resp.Flags {
+		if f.Flag == flag {
+			assert.Len(t, f.Overrides, 2)
+		} else {
+			assert.Empty(t, f.Overrides)
+		}
+	}
+
+	require.NoError(t, svc.DeleteOverride(ctx, tenantID, flag, ""))
+	assert.True(t, svc.IsEnabled(prodCtx, flag))
+	assert.Error(t, svc.DeleteOverride(ctx, tenantID, flag, ""))
+
+	_, err = svc.SetOverride(ctx, tenantID, "unknown_flag", &dto.SetFeatureFlagRequest{Enabled: true})
+	assert.ErrorIs(t, err, ErrUnknownFeatureFlag)
+}
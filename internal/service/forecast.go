@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// seasonalHistoryWeeks is the number of whole weeks of daily rollups the weights
+// of the days of the week are measured over
+const seasonalHistoryWeeks = 4
+
+type ForecastService interface {
+	// ForecastCustomerSpend projects the total of the invoice each active
+	// subscription of the customer renews with, from the usage of the period
+	// until at. Budget alerts compare the projected totals with the budget
+	ForecastCustomerSpend(ctx context.Context, customerID string, filter types.ForecastFilter, at time.Time) (*dto.CustomerForecastResponse, error)
+}
+
+// DayWeights is the usage of each day of the week, indexed by time.Weekday,
+// relative to the average day
+type DayWeights [7]float64
+
+// Seasonality is the weights of the days of the week of the meters by ID
+type Seasonality map[string]DayWeights
+
+// hours integrates the weights over [start, end), days being in UTC
+func (w DayWeights) hours(start, end time.Time) float64 {
+	var total float64
+	for t := start.UTC(); t.Before(end); {
+		next := t.Truncate(rollupDay).Add(rollupDay)
+		if next.After(end) {
+			next = end.UTC()
+		}
+		total += w[t.Weekday()] * next.Sub(t).Hours()
+		t = next
+	}
+	return total
+}
+
+// factor is the ratio of the weighted period [start, end) to its part elapsed
+// at at. It is not defined when no usage is expected until at
+func (w DayWeights) factor(start, at, end time.Time) (decimal.Decimal, bool) {
+	elapsed := w.hours(start, at)
+	if elapsed <= 0 {
+		return decimal.Zero, false
+	}
+	return decimal.NewFromFloat(w.hours(start, end) / elapsed), true
+}
+
+type forecastService struct {
+	customerRepo     customer.Repository
+	subscriptionRepo subscription.Repository
+	priceRepo        price.Repository
+	meterRepo        meter.Repository
+	rollupRepo       events.RollupRepository
+	invoiceService   InvoiceService
+	logger           *logger.Logger
+}
+
+func NewForecastService(
+	customerRepo customer.Repository,
+	subscriptionRepo subscription.Repository,
+	priceRepo price.Repository,
+	meterRepo meter.Repository,
+	rollupRepo events.RollupRepository,
+	invoiceService InvoiceService,
+	logger *logger.Logger,
+) ForecastService {
+	return &forecastService{
+		customerRepo:     customerRepo,
+		subscriptionRepo: subscriptionRepo,
+		priceRepo:        priceRepo,
+		meterRepo:        meterRepo,
+		rollupRepo:       rollupRepo,
+		invoiceService:   invoiceService,
+		logger:           logger,
+	}
+}
+
+func (s *forecastService) ForecastCustomerSpend(ctx context.Context, customerID string, filter types.ForecastFilter, at time.Time) (*dto.CustomerForecastResponse, error) {
+	model := filter.Model
+	switch model {
+	case "":
+		model = types.ForecastModelLinear
+	case types.ForecastModelLinear, types.ForecastModelSeasonal:
+	default:
+		return nil, fmt.Errorf("invalid forecast model: %s", filter.Model)
+	}
+
+	c, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	subs, err := s.listRenewingSubscriptions(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CustomerForecastResponse{
+		CustomerID:      customerID,
+		Model:           model,
+		ForecastedAt:    at,
+		ProjectedTotals: make(map[string]decimal.Decimal),
+		Subscriptions:   []dto.SubscriptionForecast{},
+	}
+
+	for _, sub := range subs {
+		var seasonality Seasonality
+		if model == types.ForecastModelSeasonal {
+			if seasonality, err = s.getSeasonality(ctx, sub, c.ExternalID, at); err != nil {
+				return nil, err
+			}
+		}
+
+		preview, err := s.invoiceService.GetUpcomingInvoice(ctx, sub.ID, &UpcomingInvoiceProjection{At: at, Seasonality: seasonality})
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview renewal invoice of subscription %s: %w", sub.ID, err)
+		}
+
+		forecast := dto.SubscriptionForecast{
+			SubscriptionID: sub.ID,
+			Currency:       preview.Currency,
+			PeriodStart:    sub.CurrentPeriodStart,
+			PeriodEnd:      sub.CurrentPeriodEnd,
+			ProjectedTotal: preview.Total,
+			Usage:          preview.UsageProjection.Usage,
+		}
+		response.Subscriptions = append(response.Subscriptions, forecast)
+		response.ProjectedTotals[forecast.Currency] = response.ProjectedTotals[forecast.Currency].Add(forecast.ProjectedTotal)
+	}
+
+	return response, nil
+}
+
+// listRenewingSubscriptions returns the active and trialing subscriptions of
+// the customer
+func (s *forecastService) listRenewingSubscriptions(ctx context.Context, customerID string) ([]*subscription.Subscription, error) {
+	var renewing []*subscription.Subscription
+
+	filter := &types.SubscriptionFilter{
+		Filter:     types.Filter{Limit: types.DefaultFilterLimit},
+		CustomerID: customerID,
+		Status:     types.StatusPublished,
+	}
+
+	for {
+		subs, err := s.subscriptionRepo.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+		}
+
+		for _, sub := range subs {
+			if sub.SubscriptionStatus == types.SubscriptionStatusActive ||
+				sub.SubscriptionStatus == types.SubscriptionStatusTrialing {
+				renewing = append(renewing, sub)
+			}
+		}
+
+		if len(subs) < filter.Limit {
+			return renewing, nil
+		}
+		filter.Offset += filter.Limit
+	}
+}
+
+// getSeasonality measures the weights of the days of the week of the metered
+// prices of the subscription from the daily rollups of the customer. Meters
+// whose usage does not add up by day, or without rolled up usage, are left to
+// the linear projection
+func (s *forecastService) getSeasonality(ctx context.Context, sub *subscription.Subscription, externalCustomerID string, at time.Time) (Seasonality, error) {
+	prices, err := s.priceRepo.GetByPlanID(ctx, sub.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+
+	seasonality := make(Seasonality)
+	for _, p := range prices {
+		if p.MeterID == "" {
+			continue
+		}
+		if _, ok := seasonality[p.MeterID]; ok {
+			continue
+		}
+
+		m, err := s.meterRepo.GetMeter(ctx, p.MeterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get meter: %w", err)
+		}
+		if m.IsDerived() || (m.Aggregation.Type != types.AggregationCount && m.Aggregation.Type != types.AggregationSum) {
+			continue
+		}
+
+		weights, err := s.getDayWeights(ctx, m, externalCustomerID, at)
+		if err != nil {
+			return nil, err
+		}
+		if weights != nil {
+			seasonality[m.ID] = *weights
+		}
+	}
+
+	return seasonality, nil
+}
+
+// getDayWeights averages the daily usage of the customer on the meter by day of
+// the week over the last whole weeks rolled up before at. It returns nil when
+// the customer has no usage over them
+func (s *forecastService) getDayWeights(ctx context.Context, m *meter.Meter, externalCustomerID string, at time.Time) (*DayWeights, error) {
+	watermark, err := s.rollupRepo.GetRollupWatermark(ctx, m.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollup watermark: %w", err)
+	}
+
+	end := minTime(at.UTC(), watermark).Truncate(rollupDay)
+	start := end.AddDate(0, 0, -7*seasonalHistoryWeeks)
+
+	windows, err := s.rollupRepo.GetRolledUpWindows(ctx, &events.RolledUpUsageParams{
+		MeterID:            m.ID,
+		ExternalCustomerID: externalCustomerID,
+		Ranges:             []events.RollupRange{{WindowSize: types.WindowSizeDay, StartTime: start, EndTime: end}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rolled up usage: %w", err)
+	}
+
+	var days DayWeights
+	var total float64
+	for _, w := range windows {
+		value := float64(w.EventCount)
+		if m.Aggregation.Type == types.AggregationSum {
+			value = w.ValueSum.InexactFloat64()
+		}
+		days[w.WindowStart.Weekday()] += value
+		total += value
+	}
+	if total <= 0 {
+		return nil, nil
+	}
+
+	// Every day of the week occurs as many times in whole weeks, so the sum of
+	// each day over the average day is its weight
+	average := total / 7
+	for i := range days {
+		days[i] /= average
+	}
+	return &days, nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecastService_ForecastCustomerSpend(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_123",
+		ExternalID: "ext_cust_123",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	meterStore := testutil.NewInMemoryMeterStore()
+	require.NoError(t, meterStore.CreateMeter(ctx, &meter.Meter{
+		ID:          "meter_api_calls",
+		Name:        "API Calls",
+		EventName:   "api_call",
+		Aggregation: meter.Aggregation{Type: types.AggregationCount},
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}))
+
+	planStore := testutil.NewInMemoryPlanStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Usage Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	priceStore := testutil.NewInMemoryPriceStore()
+	for _, p := range []*price.Price{
+		{ID: "price_fixed", Type: types.PRICE_TYPE_FIXED, Amount: decimal.NewFromInt(20)},
+		{ID: "price_api_calls", Type: types.PRICE_TYPE_USAGE, MeterID: "meter_api_calls", Amount: decimal.NewFromInt(1)},
+	} {
+		p.PlanID = "plan_123"
+		p.Currency = "usd"
+		p.BillingPeriod = types.BILLING_PERIOD_MONTHLY
+		p.BillingPeriodCount = 1
+		p.BillingModel = types.BILLING_MODEL_FLAT_FEE
+		p.BillingCadence = types.BILLING_CADENCE_RECURRING
+		p.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, priceStore.Create(ctx, p))
+	}
+
+	// April 2024 starts on a Monday and has 22 weekdays
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          start.AddDate(0, -1, 0),
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	// The customer calls the API 10 times a day on weekdays only, until
+	// Saturday 13 April
+	at := start.AddDate(0, 0, 12)
+	eventStore := testutil.NewInMemoryEventStore()
+	for day := start.AddDate(0, 0, -28); day.Before(at); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		for i := 0; i < 10; i++ {
+			require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+				ID:                 types.GenerateUUID(),
+				TenantID:           types.GetTenantID(ctx),
+				EventName:          "api_call",
+				ExternalCustomerID: "ext_cust_123",
+				Timestamp:          day.Add(time.Duration(i) * time.Hour),
+				Properties:         map[string]interface{}{},
+			}))
+		}
+	}
+
+	rollupStore := testutil.NewInMemoryRollupStore(eventStore)
+	require.NoError(t, rollupStore.RollUpUsage(ctx, &events.RollupParams{
+		MeterID:    "meter_api_calls",
+		EventName:  "api_call",
+		WindowSize: types.WindowSizeDay,
+		EndTime:    at,
+	}))
+	require.NoError(t, rollupStore.SetRollupWatermark(ctx, "meter_api_calls", at))
+
+	invoiceService := NewInvoiceService(
+		testutil.NewInMemoryInvoiceStore(), testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+		testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+		nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, nil,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+	svc := NewForecastService(customerStore, subscriptionStore, priceStore, meterStore, rollupStore, invoiceService, logger.GetLogger())
+
+	tests := []struct {
+		name          string
+		model         types.ForecastModel
+		wantProjected decimal.Decimal
+	}{
+		{
+			// 100 calls over 12 of the 30 days
+			name:          "linear extrapolates the run-rate of the period",
+			wantProjected: decimal.NewFromInt(250),
+		},
+		{
+			// 100 calls over 10 of the 22 weekdays, none on weekends
+			name:          "seasonal weighs the days left by their day of the week",
+			model:         types.ForecastModelSeasonal,
+			wantProjected: decimal.NewFromInt(220),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := svc.ForecastCustomerSpend(ctx, "cust_123", types.ForecastFilter{Model: tt.model}, at)
+			require.NoError(t, err)
+
+			require.Len(t, resp.Subscriptions, 1)
+			t.Logf("%+v", resp.Subscriptions[0])
+			forecast := resp.Subscriptions[0]
+			require.Len(t, forecast.Usage, 1)
+			assert.True(t, decimal.NewFromInt(100).Equal(forecast.Usage[0].QuantityToDate), "to date %s", forecast.Usage[0].QuantityToDate)
+			assert.True(t, tt.wantProjected.Equal(forecast.Usage[0].ProjectedQuantity), "projected %s", forecast.Usage[0].ProjectedQuantity)
+
+			wantTotal := tt.wantProjected.Add(decimal.NewFromInt(20))
+			assert.True(t, wantTotal.Equal(forecast.ProjectedTotal), "total %s", forecast.ProjectedTotal)
+			assert.True(t, wantTotal.Equal(resp.ProjectedTotals["usd"]))
+		})
+	}
+
+	_, err := svc.ForecastCustomerSpend(ctx, "cust_123", types.ForecastFilter{Model: "exponential"}, at)
+	assert.Error(t, err)
+}
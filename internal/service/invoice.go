@@ -0,0 +1,1240 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/billingengine"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/domain/sequence"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/kafka"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+)
+
+type InvoiceService interface {
+	CreateSubscriptionInvoice(ctx context.Context, subscriptionID string, req dto.CreateSubscriptionInvoiceRequest) (*dto.InvoiceResponse, error)
+	CreateCustomerInvoices(ctx context.Context, customerID string, req dto.CreateCustomerInvoicesRequest) (*dto.ListInvoicesResponse, error)
+
+	// CreateOneOffInvoice raises an invoice for a customer outside of any subscription
+	CreateOneOffInvoice(ctx context.Context, req dto.CreateOneOffInvoiceRequest) (*dto.InvoiceResponse, error)
+
+	// CreatePastInvoices bills the periods of a backdated subscription that elapsed
+	// before its current period, one invoice per period or a single catch-up invoice
+	// when consolidated
+	CreatePastInvoices(ctx context.Context, subscriptionID string, consolidate bool) (*dto.ListInvoicesResponse, error)
+
+	// GetUpcomingInvoice previews the invoice of the current period of the
+	// subscription, with its usage projected over the whole period when a
+	// projection is given
+	GetUpcomingInvoice(ctx context.Context, subscriptionID string, projection *UpcomingInvoiceProjection) (*dto.UpcomingInvoiceResponse, error)
+	GetInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error)
+	ListInvoices(ctx context.Context, filter *types.InvoiceFilter) (*dto.ListInvoicesResponse, error)
+	FinalizeInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error)
+
+	// VoidInvoice voids a finalized invoice that was not paid or credited, its
+	// taxes are voided at the tax provider
+	VoidInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error)
+
+	// ProcessBillingThresholds raises an interim invoice for the subscriptions of all
+	// tenants whose usage charges accumulated in the current period exceed their
+	// billing threshold
+	ProcessBillingThresholds(ctx context.Context, now time.Time) error
+
+	// GetUsageCharges returns the usage charges of the subscription between from
+	// and to, priced as in its current period
+	GetUsageCharges(ctx context.Context, subscriptionID string, from, to time.Time) (decimal.Decimal, error)
+
+	// ProcessPeriodEnds renews the active subscriptions of the tenants with period
+	// renewal turned on whose current period is over and locked, and invoices the
+	// periods they were renewed from
+	ProcessPeriodEnds(ctx context.Context, now time.Time) error
+
+	// GetUsageLock returns whether the current period of the subscription still
+	// accepts late events, which it does until billing.usage_lock_delay_hours
+	// after its end
+	GetUsageLock(ctx context.Context, subscriptionID string, now time.Time) (*dto.UsageLockResponse, error)
+
+	// ReconcileLateEvents recalculates the usage of the draft and held invoices
+	// whose period received events after they were calculated, and sends an
+	// invoice.late_usage webhook for the finalized ones
+	ReconcileLateEvents(ctx context.Context, now time.Time) error
+
+	// ApproveInvoice moves an invoice held by the billing guardrails back to draft
+	// once it was reviewed, so that it can be finalized
+	ApproveInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error)
+
+	// AddLineItem, UpdateLineItem and RemoveLineItem edit the line items of a draft
+	// or held invoice before it is finalized
+	AddLineItem(ctx context.Context, id string, req dto.AddInvoiceLineItemRequest) (*dto.InvoiceResponse, error)
+	UpdateLineItem(ctx context.Context, id, lineItemID string, req dto.UpdateInvoiceLineItemRequest) (*dto.InvoiceResponse, error)
+	RemoveLineItem(ctx context.Context, id, lineItemID string) (*dto.InvoiceResponse, error)
+	GetInvoiceNumberingConfig(ctx context.Context) (*dto.InvoiceNumberingConfigResponse, error)
+	UpdateInvoiceNumberingConfig(ctx context.Context, req dto.UpdateInvoiceNumberingConfigRequest) (*dto.InvoiceNumberingConfigResponse, error)
+
+	// RecordPayment records a full or partial payment against a finalized invoice
+	RecordPayment(ctx context.Context, id string, req dto.RecordInvoicePaymentRequest) (*dto.InvoicePaymentResponse, error)
+	// ListPayments returns the payments of an invoice in the order they were paid
+	ListPayments(ctx context.Context, id string) (*dto.ListInvoicePaymentsResponse, error)
+
+	// OpenDispute records the amounts of line items of a finalized invoice the
+	// customer disputes. The bank debits and gateway collections of the invoice
+	// are paused until the dispute is resolved
+	OpenDispute(ctx context.Context, id string, req dto.OpenDisputeRequest) (*dto.DisputeResponse, error)
+	// ListDisputes returns the disputes of an invoice, oldest first
+	ListDisputes(ctx context.Context, id string) (*dto.ListDisputesResponse, error)
+	// ReviewDispute moves an open dispute of an invoice under review
+	ReviewDispute(ctx context.Context, id, disputeID string) (*dto.DisputeResponse, error)
+	// ResolveDispute settles the dispute of an invoice with a credit note, a
+	// corrected invoice or by upholding the invoice, and resumes its collection
+	ResolveDispute(ctx context.Context, id, disputeID string, req dto.ResolveDisputeRequest) (*dto.DisputeResponse, error)
+
+	// WriteOffInvoice marks the amount remaining of a finalized invoice as
+	// uncollectible. The invoice leaves the receivables and is no longer collected
+	WriteOffInvoice(ctx context.Context, id string, req dto.WriteOffInvoiceRequest) (*dto.InvoiceResponse, error)
+}
+
+type invoiceService struct {
+	invoiceRepo       invoice.Repository
+	sequenceRepo      sequence.Repository
+	subscriptionRepo  subscription.Repository
+	planRepo          plan.Repository
+	priceRepo         price.Repository
+	producer          kafka.MessageProducer
+	eventRepo         events.Repository
+	meterRepo         meter.Repository
+	customerRepo      customer.Repository
+	walletRepo        wallet.Repository
+	bucketRepo        wallet.CreditBucketRepository
+	rateCardRepo      ratecard.Repository
+	priceBookRepo     pricebook.Repository
+	legalEntityRepo   legalentity.Repository
+	db                postgres.TxManager
+	webhookPublisher  webhook.Publisher
+	emailService      EmailService
+	ledgerSyncService LedgerSyncService
+	crmSyncService    CRMSyncService
+	taxService        TaxService
+	auditPublisher    audit.Publisher
+
+	// featureFlagService is optional, the flags keep their default without it
+	featureFlagService FeatureFlagService
+	subscriptionReader SubscriptionReader
+	// clock is the time invoices are finalized at, the wall clock when nil
+	clock  *clock.Clock
+	cfg    config.BillingConfig
+	logger *logger.Logger
+}
+
+func NewInvoiceService(
+	invoiceRepo invoice.Repository,
+	sequenceRepo sequence.Repository,
+	subscriptionRepo subscription.Repository,
+	planRepo plan.Repository,
+	priceRepo price.Repository,
+	producer kafka.MessageProducer,
+	eventRepo events.Repository,
+	meterRepo meter.Repository,
+	customerRepo customer.Repository,
+	walletRepo wallet.Repository,
+	bucketRepo wallet.CreditBucketRepository,
+	rateCardRepo ratecard.Repository,
+	priceBookRepo pricebook.Repository,
+	legalEntityRepo legalentity.Repository,
+	db postgres.TxManager,
+	webhookPublisher webhook.Publisher,
+	emailService EmailService,
+	ledgerSyncService LedgerSyncService,
+	crmSyncService CRMSyncService,
+	taxService TaxService,
+	auditPublisher audit.Publisher,
+	featureFlagService FeatureFlagService,
+	subscriptionReader SubscriptionReader,
+	clock *clock.Clock,
+	cfg *config.Configuration,
+	logger *logger.Logger,
+) InvoiceService {
+	return &invoiceService{
+		invoiceRepo:        invoiceRepo,
+		sequenceRepo:       sequenceRepo,
+		subscriptionRepo:   subscriptionRepo,
+		planRepo:           planRepo,
+		priceRepo:          priceRepo,
+		producer:           producer,
+		eventRepo:          eventRepo,
+		meterRepo:          meterRepo,
+		customerRepo:       customerRepo,
+		walletRepo:         walletRepo,
+		bucketRepo:         bucketRepo,
+		rateCardRepo:       rateCardRepo,
+		priceBookRepo:      priceBookRepo,
+		legalEntityRepo:    legalEntityRepo,
+		db:                 db,
+		webhookPublisher:   webhookPublisher,
+		emailService:       emailService,
+		ledgerSyncService:  ledgerSyncService,
+		crmSyncService:     crmSyncService,
+		taxService:         taxService,
+		auditPublisher:     auditPublisher,
+		featureFlagService: featureFlagService,
+		subscriptionReader: subscriptionReader,
+		clock:              clock,
+		cfg:                cfg.Billing,
+		logger:             logger,
+	}
+}
+
+// CreateSubscriptionInvoice runs billing for a subscription period and creates a draft invoice
+// with the fixed charges, the usage charges and any adjustments passed by the caller.
+// When the credits exceed the charges the invoice is issued as per the configured
+// NegativeInvoiceBehavior and linked to the last finalized invoice of the subscription.
+func (s *invoiceService) CreateSubscriptionInvoice(ctx context.Context, subscriptionID string, req dto.CreateSubscriptionInvoiceRequest) (*dto.InvoiceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	inv, err := s.buildSubscriptionInvoice(ctx, subscriptionID, req, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.taxInvoice(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	// The invoice is created with its prorations marked invoiced in one
+	// transaction, so that a failure does not bill them again on the next invoice
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		return s.issueInvoice(ctx, inv)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.InvoiceResponse{Invoice: inv}, nil
+}
+
+// CreateCustomerInvoices runs billing for the subscriptions of a customer whose current
+// period ends at the billing date. Customers consolidating invoices get one invoice per
+// currency with a section per subscription, others get one invoice per subscription
+func (s *invoiceService) CreateCustomerInvoices(ctx context.Context, customerID string, req dto.CreateCustomerInvoicesRequest) (*dto.ListInvoicesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	subs, err := s.listSubscriptionsDue(ctx, customerID, req.BillingDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var invoices []*invoice.Invoice
+	for _, sub := range subs {
+		inv, err := s.buildSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{}, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+	}
+
+	if cust.ConsolidateInvoices {
+		invoices = s.consolidateInvoices(ctx, invoices, req.BillingDate)
+	}
+	for _, inv := range invoices {
+		if err := s.taxInvoice(ctx, inv); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, inv := range invoices {
+			if err := s.issueInvoice(ctx, inv); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ListInvoicesResponse{
+		Invoices: make([]dto.InvoiceResponse, len(invoices)),
+		Total:    len(invoices),
+		Limit:    len(invoices),
+	}
+
+	for i, inv := range invoices {
+		response.Invoices[i] = *dto.NewInvoiceResponse(inv)
+	}
+
+	return response, nil
+}
+
+func (s *invoiceService) CreatePastInvoices(ctx context.Context, subscriptionID string, consolidate bool) (*dto.ListInvoicesResponse, error) {
+	sub, err := s.subscriptionRepo.Get(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	// The periods within the trial are free and are not billed
+	invoices, err := s.buildPeriodInvoices(ctx, sub, firstPaidPeriodStart(sub))
+	if err != nil {
+		return nil, err
+	}
+
+	// The catch-up invoice keeps the period of each line item and stays linked
+	// to the subscription as it bills no other
+	if consolidate && len(invoices) > 1 {
+		invoices = s.consolidateInvoices(ctx, invoices, sub.CurrentPeriodStart)
+		for _, inv := range invoices {
+			inv.SubscriptionID = sub.ID
+		}
+	}
+	for _, inv := range invoices {
+		if err := s.taxInvoice(ctx, inv); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, inv := range invoices {
+			if err := s.issueInvoice(ctx, inv); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ListInvoicesResponse{
+		Invoices: make([]dto.InvoiceResponse, len(invoices)),
+		Total:    len(invoices),
+		Limit:    len(invoices),
+	}
+
+	for i, inv := range invoices {
+		response.Invoices[i] = *dto.NewInvoiceResponse(inv)
+	}
+
+	return response, nil
+}
+
+// buildPeriodInvoices builds an invoice for each period of the subscription
+// from the given start to its current period
+func (s *invoiceService) buildPeriodInvoices(ctx context.Context, sub *subscription.Subscription, from time.Time) ([]*invoice.Invoice, error) {
+	var invoices []*invoice.Invoice
+	billedProrations := make(map[string]bool)
+	for start := from; start.Before(sub.CurrentPeriodStart); {
+		end, err := periodEndFrom(sub, start)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate period end: %w", err)
+		}
+		if end.After(sub.CurrentPeriodStart) {
+			end = sub.CurrentPeriodStart
+		}
+
+		inv, err := s.buildSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{
+			PeriodStart: start,
+			PeriodEnd:   end,
+		}, nil, billedProrations)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+		start = end
+	}
+	return invoices, nil
+}
+
+// listSubscriptionsDue returns the active and trialing subscriptions of the
+// customer whose current period ends at the billing date
+func (s *invoiceService) listSubscriptionsDue(ctx context.Context, customerID string, billingDate time.Time) ([]*subscription.Subscription, error) {
+	var due []*subscription.Subscription
+
+	filter := &types.SubscriptionFilter{
+		Filter:     types.Filter{Limit: types.DefaultFilterLimit},
+		CustomerID: customerID,
+		Status:     types.StatusPublished,
+	}
+
+	for {
+		subs, err := s.subscriptionRepo.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+		}
+
+		for _, sub := range subs {
+			if sub.SubscriptionStatus != types.SubscriptionStatusActive &&
+				sub.SubscriptionStatus != types.SubscriptionStatusTrialing {
+				continue
+			}
+			if sub.CurrentPeriodEnd.Equal(billingDate) {
+				due = append(due, sub)
+			}
+		}
+
+		if len(subs) < filter.Limit {
+			return due, nil
+		}
+		filter.Offset += filter.Limit
+	}
+}
+
+// consolidateInvoices merges the subscription invoices sharing a currency and a
+// legal entity into one invoice. The line items keep their subscription and period
+// so that they can be shown in a section per subscription
+func (s *invoiceService) consolidateInvoices(ctx context.Context, invoices []*invoice.Invoice, billingDate time.Time) []*invoice.Invoice {
+	var consolidated []*invoice.Invoice
+	byIssuer := make(map[string]*invoice.Invoice)
+
+	for _, inv := range invoices {
+		key := inv.Currency + ":" + inv.LegalEntityID
+		merged, ok := byIssuer[key]
+		if !ok {
+			periodEnd := billingDate
+			merged = &invoice.Invoice{
+				ID:            types.GenerateUUID(),
+				CustomerID:    inv.CustomerID,
+				LegalEntityID: inv.LegalEntityID,
+				InvoiceType:   types.InvoiceTypeSubscription,
+				InvoiceFlow:   types.InvoiceFlowPeriodEnd,
+				InvoiceStatus: types.InvoiceStatusDraft,
+				Currency:      inv.Currency,
+				PeriodStart:   inv.PeriodStart,
+				PeriodEnd:     &periodEnd,
+				BaseModel:     types.GetDefaultBaseModel(ctx),
+			}
+			byIssuer[key] = merged
+			consolidated = append(consolidated, merged)
+		}
+
+		if inv.PeriodStart.Before(*merged.PeriodStart) {
+			merged.PeriodStart = inv.PeriodStart
+		}
+
+		for _, item := range inv.LineItems {
+			item.InvoiceID = merged.ID
+			merged.LineItems = append(merged.LineItems, item)
+		}
+	}
+
+	for _, inv := range consolidated {
+		inv.RecalculateTotals()
+	}
+
+	return consolidated
+}
+
+// taxInvoice assigns the legal entity of the draft and quotes its taxes. The
+// quote calls the tax connection, so it is done before the transaction issuing
+// the invoice is opened
+func (s *invoiceService) taxInvoice(ctx context.Context, inv *invoice.Invoice) error {
+	if err := s.assignLegalEntity(ctx, inv); err != nil {
+		return err
+	}
+
+	_, err := s.applyTaxes(ctx, inv)
+	return err
+}
+
+// issueInvoice applies the negative invoice behavior and the billing guardrails
+// to a draft taxed with taxInvoice and saves it. Operators are notified of the
+// invoices held for review
+func (s *invoiceService) issueInvoice(ctx context.Context, inv *invoice.Invoice) error {
+	if inv.Total.IsNegative() {
+		if err := s.applyNegativeTotal(ctx, inv); err != nil {
+			return err
+		}
+	}
+
+	if err := s.applyGuardrails(ctx, inv); err != nil {
+		return err
+	}
+
+	if err := s.invoiceRepo.Create(ctx, inv); err != nil {
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionCreate, nil, inv)
+
+	for _, item := range inv.LineItems {
+		if id := item.Metadata[invoice.MetadataProrationID]; id != "" {
+			if err := s.subscriptionRepo.MarkProrationInvoiced(ctx, id, inv.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if inv.InvoiceStatus == types.InvoiceStatusHeld {
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventInvoiceHeld, inv); err != nil {
+			return fmt.Errorf("failed to publish invoice held webhook: %w", err)
+		}
+	}
+
+	s.logger.Debugw("created invoice",
+		"invoice_id", inv.ID,
+		"subscription_id", inv.SubscriptionID,
+		"invoice_type", inv.InvoiceType,
+		"total", inv.Total,
+		"original_invoice_id", inv.OriginalInvoiceID,
+	)
+
+	return nil
+}
+
+// assignLegalEntity sets the legal entity the invoice is issued from when the
+// caller has not: the entity of its subscription, else the entity of its
+// customer, else the default entity of the tenant, if any
+func (s *invoiceService) assignLegalEntity(ctx context.Context, inv *invoice.Invoice) error {
+	if s.legalEntityRepo == nil || inv.LegalEntityID != "" {
+		return nil
+	}
+
+	if inv.SubscriptionID != "" {
+		sub, err := s.subscriptionRepo.Get(ctx, inv.SubscriptionID)
+		if err != nil {
+			return fmt.Errorf("failed to get subscription: %w", err)
+		}
+		if sub.LegalEntityID != "" {
+			inv.LegalEntityID = sub.LegalEntityID
+			return nil
+		}
+	}
+
+	cust, err := s.customerRepo.Get(ctx, inv.CustomerID)
+	if err != nil {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+	if cust.LegalEntityID != "" {
+		inv.LegalEntityID = cust.LegalEntityID
+		return nil
+	}
+
+	entity, err := s.legalEntityRepo.GetDefault(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get default legal entity: %w", err)
+	}
+	if entity != nil {
+		inv.LegalEntityID = entity.ID
+	}
+	return nil
+}
+
+// buildSubscriptionInvoice computes the draft invoice of a subscription period
+// without persisting it. The prorations in billedProrations are on invoices
+// built before it and not persisted yet, the ones it bills are added to it
+func (s *invoiceService) buildSubscriptionInvoice(ctx context.Context, subscriptionID string, req dto.CreateSubscriptionInvoiceRequest, projection *usageProjection, billedProrations map[string]bool) (*invoice.Invoice, error) {
+	subscriptionResponse, err := s.subscriptionReader.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	sub := subscriptionResponse.Subscription
+
+	periodStart := req.PeriodStart
+	if periodStart.IsZero() {
+		periodStart = sub.CurrentPeriodStart
+	}
+
+	periodEnd := req.PeriodEnd
+	if periodEnd.IsZero() {
+		periodEnd = sub.CurrentPeriodEnd
+	}
+
+	if !periodEnd.After(periodStart) {
+		return nil, fmt.Errorf("period_end must be after period_start")
+	}
+
+	inv := &invoice.Invoice{
+		ID:             types.GenerateUUID(),
+		CustomerID:     sub.CustomerID,
+		SubscriptionID: sub.ID,
+		LegalEntityID:  sub.LegalEntityID,
+		InvoiceType:    types.InvoiceTypeSubscription,
+		InvoiceFlow:    types.InvoiceFlowPeriodEnd,
+		InvoiceStatus:  types.InvoiceStatusDraft,
+		Currency:       sub.Currency,
+		PeriodStart:    &periodStart,
+		PeriodEnd:      &periodEnd,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}
+
+	in := billingengine.Input{
+		Currency:    inv.Currency,
+		PeriodShare: decimal.NewFromInt(1),
+	}
+
+	// Periods within the trial are free and only carry the adjustments
+	if sub.TrialEnd == nil || periodEnd.After(*sub.TrialEnd) {
+		share, behavior, err := billingengine.PeriodShare(billingengine.Period{
+			Start:                 periodStart,
+			FirstPaidStart:        firstPaidPeriodStart(sub),
+			BillingCycle:          sub.BillingCycle,
+			BillingPeriod:         sub.BillingPeriod,
+			BillingPeriodCount:    sub.BillingPeriodCount,
+			BillingAnchorDay:      sub.BillingAnchorDay,
+			Timezone:              sub.Timezone,
+			PartialPeriodBehavior: sub.PartialPeriodBehavior,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate partial period: %w", err)
+		}
+		inv.PartialPeriodBehavior = behavior
+
+		in.PeriodShare = share
+		in.FirstPaidPeriod = periodStart.Equal(firstPaidPeriodStart(sub))
+		in.Commitment = sub.CommitmentAmount
+
+		card, err := s.effectiveRateCard(ctx, sub, periodStart)
+		if err != nil {
+			return nil, err
+		}
+
+		if card != nil {
+			inv.Metadata = types.Metadata{
+				"rate_card_id":      card.ID,
+				"rate_card_version": strconv.Itoa(card.Version),
+			}
+		}
+
+		if err := s.addPlanCharges(ctx, &in, subscriptionResponse, card, periodStart, periodEnd); err != nil {
+			return nil, err
+		}
+
+		if projection != nil {
+			projection.project(&in, usagePeriodStart(sub, periodStart, periodEnd), periodEnd)
+		}
+	}
+
+	for _, adjustment := range req.Adjustments {
+		in.Adjustments = append(in.Adjustments, billingengine.Adjustment{
+			DisplayName: adjustment.DisplayName,
+			Amount:      adjustment.Amount,
+		})
+	}
+
+	prorations, err := s.subscriptionRepo.ListPendingProrations(ctx, sub.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending prorations: %w", err)
+	}
+	for _, proration := range prorations {
+		// A proration is billed with the period it was made in, or with the
+		// next invoice when that period was invoiced before it was made. The
+		// periods caught up at once are built before any of them is
+		// persisted, so a proration is only billed on the first of them
+		if !proration.PeriodStart.Before(periodEnd) || billedProrations[proration.ID] {
+			continue
+		}
+		if billedProrations != nil {
+			billedProrations[proration.ID] = true
+		}
+		in.Adjustments = append(in.Adjustments, billingengine.Adjustment{
+			DisplayName: proration.DisplayName,
+			Amount:      proration.Amount,
+			ProrationID: proration.ID,
+		})
+	}
+
+	result := billingengine.Calculate(in)
+	for _, item := range result.LineItems {
+		inv.LineItems = append(inv.LineItems, s.newLineItem(ctx, inv, lineItemParams{
+			PriceID:     item.PriceID,
+			MeterID:     item.MeterID,
+			DisplayName: item.DisplayName,
+			Amount:      item.Amount,
+			Quantity:    item.Quantity,
+			ProrationID: item.ProrationID,
+		}))
+	}
+
+	inv.RecalculateTotals()
+
+	return inv, nil
+}
+
+// addPlanCharges gathers the fixed prices of the plan valid for the subscription
+// and the metered usage of the period into the billing engine input. Prices are
+// overridden by the price book of the subscription, then those covered by the rate
+// card, if any, are billed at their negotiated rates. Usage already billed by
+// threshold invoices is left out
+func (s *invoiceService) addPlanCharges(
+	ctx context.Context,
+	in *billingengine.Input,
+	subscriptionResponse *dto.SubscriptionResponse,
+	card *ratecard.RateCard,
+	periodStart, periodEnd time.Time,
+) error {
+	sub := subscriptionResponse.Subscription
+
+	book, err := subscriptionPriceBook(ctx, s.priceBookRepo, sub)
+	if err != nil {
+		return err
+	}
+
+	negotiated := func(p *price.Price) *price.Price {
+		if book != nil {
+			p = book.Apply(p)
+		}
+		if card == nil {
+			return p
+		}
+		return card.Apply(p)
+	}
+
+	lineItems := make(map[string]*subscription.LineItem, len(sub.LineItems))
+	for _, item := range sub.LineItems {
+		lineItems[item.PriceID] = item
+	}
+
+	for _, p := range filterValidPricesForSubscription(subscriptionResponse.Plan.Prices, sub) {
+		fixed := negotiated(p.Price)
+		if item, ok := lineItems[p.ID]; ok {
+			fixed = item.Apply(fixed)
+			if in.FixedQuantities == nil {
+				in.FixedQuantities = make(map[string]decimal.Decimal)
+			}
+			in.FixedQuantities[p.ID] = item.Quantity
+		}
+		in.FixedPrices = append(in.FixedPrices, fixed)
+	}
+
+	usage, err := s.subscriptionReader.GetUsageBySubscription(ctx, &dto.GetUsageBySubscriptionRequest{
+		SubscriptionID: sub.ID,
+		StartTime:      usagePeriodStart(sub, periodStart, periodEnd),
+		EndTime:        periodEnd,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	for _, charge := range usage.Charges {
+		in.Usage = append(in.Usage, billingengine.Usage{
+			Price:       negotiated(charge.Price),
+			DisplayName: charge.MeterDisplayName,
+			Quantity:    decimal.NewFromFloat(charge.Quantity),
+		})
+	}
+
+	return nil
+}
+
+// usagePeriodStart is the start of the usage billed for the period, which is the
+// end of the last threshold invoice of the period if any
+func usagePeriodStart(sub *subscription.Subscription, periodStart, periodEnd time.Time) time.Time {
+	if sub.ThresholdBilledUntil != nil && sub.ThresholdBilledUntil.After(periodStart) && sub.ThresholdBilledUntil.Before(periodEnd) {
+		return *sub.ThresholdBilledUntil
+	}
+	return periodStart
+}
+
+// usageProjection extrapolates the usage of a period measured until At to the
+// whole period, at the run-rate of the part elapsed. The usage of the meters of
+// Seasonality is extrapolated by the weight of the days of the week instead
+type usageProjection struct {
+	At          time.Time
+	Factor      decimal.Decimal
+	Seasonality Seasonality
+	Usage       []dto.ProjectedUsage
+}
+
+// project replaces the usage quantities of the input with their projection. The
+// usage of a period not started or already over is not extrapolated
+func (p *usageProjection) project(in *billingengine.Input, start, end time.Time) {
+	elapsed := p.At.After(start) && p.At.Before(end)
+	if elapsed {
+		p.Factor = decimal.NewFromInt(int64(end.Sub(start))).Div(decimal.NewFromInt(int64(p.At.Sub(start))))
+	}
+
+	p.Usage = make([]dto.ProjectedUsage, 0, len(in.Usage))
+	for i, usage := range in.Usage {
+		factor := p.Factor
+		if weights, ok := p.Seasonality[usage.Price.MeterID]; ok && elapsed {
+			if seasonal, ok := weights.factor(start, p.At, end); ok {
+				factor = seasonal
+			}
+		}
+
+		projected := usage.Quantity.Mul(factor).Round(4)
+		p.Usage = append(p.Usage, dto.ProjectedUsage{
+			PriceID:           usage.Price.ID,
+			MeterID:           usage.Price.MeterID,
+			DisplayName:       usage.DisplayName,
+			QuantityToDate:    usage.Quantity,
+			ProjectedQuantity: projected,
+		})
+		in.Usage[i].Quantity = projected
+	}
+}
+
+// effectiveRateCard returns the rate card negotiated for the subscription, or its
+// customer, in effect at the start of the period. It returns nil when the catalog
+// prices apply
+func (s *invoiceService) effectiveRateCard(ctx context.Context, sub *subscription.Subscription, periodStart time.Time) (*ratecard.RateCard, error) {
+	if s.rateCardRepo == nil {
+		return nil, nil
+	}
+
+	cards, err := s.rateCardRepo.ListByCustomer(ctx, sub.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rate cards: %w", err)
+	}
+
+	return ratecard.Effective(cards, sub.ID, periodStart), nil
+}
+
+// firstPaidPeriodStart is the start of the first billed period of the subscription,
+// which is the end of the trial if any
+func firstPaidPeriodStart(sub *subscription.Subscription) time.Time {
+	if sub.TrialEnd != nil {
+		return *sub.TrialEnd
+	}
+	return sub.StartDate
+}
+
+// UpcomingInvoiceProjection projects the usage of the upcoming invoice at the
+// run-rate measured until At, the current time when zero. The usage of the
+// meters with a seasonality is projected by day of the week
+type UpcomingInvoiceProjection struct {
+	At          time.Time
+	Seasonality Seasonality
+}
+
+// GetUpcomingInvoice previews the invoice the subscription will be billed at the
+// end of its current period. Nothing is persisted: the active wallets of the customer
+// in the invoice currency are drawn down in the order they were created to show
+// the amount that will be left to pay out of pocket
+func (s *invoiceService) GetUpcomingInvoice(ctx context.Context, subscriptionID string, projection *UpcomingInvoiceProjection) (*dto.UpcomingInvoiceResponse, error) {
+	return s.previewInvoice(ctx, subscriptionID, projection)
+}
+
+// previewInvoice builds the invoice of the current period of the subscription
+// without persisting it and simulates its wallet draw-down
+func (s *invoiceService) previewInvoice(ctx context.Context, subscriptionID string, options *UpcomingInvoiceProjection) (*dto.UpcomingInvoiceResponse, error) {
+	var projection *usageProjection
+	if options != nil {
+		at := options.At
+		if at.IsZero() {
+			at = s.clock.Now()
+		}
+		projection = &usageProjection{
+			At:          at,
+			Factor:      decimal.NewFromInt(1),
+			Seasonality: options.Seasonality,
+			Usage:       []dto.ProjectedUsage{},
+		}
+	}
+
+	inv, err := s.buildSubscriptionInvoice(ctx, subscriptionID, dto.CreateSubscriptionInvoiceRequest{}, projection, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The preview is taxed as the invoice will be when issued
+	if _, err := s.applyTaxes(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	wallets, err := s.walletRepo.GetWalletsByCustomerID(ctx, inv.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallets: %w", err)
+	}
+
+	sort.SliceStable(wallets, func(i, j int) bool {
+		return wallets[i].CreatedAt.Before(wallets[j].CreatedAt)
+	})
+
+	credits := make([]billingengine.Credit, 0, len(wallets))
+	for _, w := range wallets {
+		if w.WalletStatus != types.WalletStatusActive || w.Currency != inv.Currency {
+			continue
+		}
+		balance, err := s.balanceAt(ctx, w, *inv.PeriodEnd)
+		if err != nil {
+			return nil, err
+		}
+		credits = append(credits, billingengine.Credit{ID: w.ID, Balance: balance})
+	}
+
+	applied := billingengine.ApplyCredits(inv.AmountDue, credits)
+
+	resp := &dto.UpcomingInvoiceResponse{
+		Invoice:           inv,
+		RenewalDate:       *inv.PeriodEnd,
+		CreditsApplied:    applied.Applied,
+		AmountOutOfPocket: applied.Remaining,
+	}
+	if projection != nil {
+		resp.UsageProjection = &dto.UsageProjection{
+			ProjectedAt: projection.At,
+			Factor:      projection.Factor,
+			Usage:       projection.Usage,
+		}
+	}
+
+	for _, application := range applied.Applications {
+		resp.CreditApplications = append(resp.CreditApplications, dto.CreditApplication{
+			WalletID:      application.ID,
+			BalanceBefore: application.BalanceBefore,
+			Amount:        application.Amount,
+			BalanceAfter:  application.BalanceAfter,
+		})
+	}
+
+	return resp, nil
+}
+
+// balanceAt returns the balance of the wallet still available at, leaving out
+// the credit of the buckets expiring by then
+func (s *invoiceService) balanceAt(ctx context.Context, w *wallet.Wallet, at time.Time) (decimal.Decimal, error) {
+	buckets, err := s.bucketRepo.ListCreditBuckets(ctx, w.ID)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to list credit buckets: %w", err)
+	}
+
+	balance := w.Balance
+	for _, bucket := range buckets {
+		if !bucket.ExpiresAt.After(at) {
+			balance = balance.Sub(bucket.Remaining)
+		}
+	}
+	return decimal.Max(balance, decimal.Zero), nil
+}
+
+// applyNegativeTotal converts the invoice into a credit document instead of
+// issuing a zero invoice and links it to the invoice being credited
+func (s *invoiceService) applyNegativeTotal(ctx context.Context, inv *invoice.Invoice) error {
+	if s.cfg.NegativeInvoiceBehavior != types.NegativeInvoiceBehaviorNegativeInvoice {
+		inv.InvoiceType = types.InvoiceTypeCredit
+	}
+
+	previous, err := s.invoiceRepo.List(ctx, &types.InvoiceFilter{
+		Filter:         types.Filter{Limit: 1},
+		CustomerID:     inv.CustomerID,
+		SubscriptionID: inv.SubscriptionID,
+		InvoiceType:    types.InvoiceTypeSubscription,
+		InvoiceStatus:  types.InvoiceStatusFinalized,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get previous invoice: %w", err)
+	}
+
+	if len(previous) > 0 {
+		inv.OriginalInvoiceID = previous[0].ID
+	}
+
+	return nil
+}
+
+type lineItemParams struct {
+	PriceID     string
+	MeterID     string
+	DisplayName string
+	Amount      decimal.Decimal
+	Quantity    decimal.Decimal
+	ProrationID string
+	// Metadata is added to the metadata of the line item
+	Metadata types.Metadata
+}
+
+func (s *invoiceService) newLineItem(ctx context.Context, inv *invoice.Invoice, params lineItemParams) *invoice.InvoiceLineItem {
+	var metadata types.Metadata
+	if params.ProrationID != "" || len(params.Metadata) > 0 {
+		metadata = maps.Clone(params.Metadata)
+		if metadata == nil {
+			metadata = types.Metadata{}
+		}
+		if params.ProrationID != "" {
+			metadata[invoice.MetadataProrationID] = params.ProrationID
+		}
+	}
+
+	return &invoice.InvoiceLineItem{
+		ID:             types.GenerateUUID(),
+		InvoiceID:      inv.ID,
+		CustomerID:     inv.CustomerID,
+		SubscriptionID: inv.SubscriptionID,
+		PriceID:        params.PriceID,
+		MeterID:        params.MeterID,
+		DisplayName:    params.DisplayName,
+		Amount:         params.Amount,
+		Quantity:       params.Quantity,
+		Currency:       inv.Currency,
+		PeriodStart:    inv.PeriodStart,
+		PeriodEnd:      inv.PeriodEnd,
+		Metadata:       metadata,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}
+}
+
+func (s *invoiceService) GetInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error) {
+	inv, err := s.invoiceRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	response := dto.NewInvoiceResponse(inv)
+
+	credits, err := s.invoiceRepo.List(ctx, &types.InvoiceFilter{
+		Filter:            types.Filter{Limit: types.DefaultFilterLimit},
+		OriginalInvoiceID: inv.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit invoices: %w", err)
+	}
+
+	for _, credit := range credits {
+		response.CreditInvoiceIDs = append(response.CreditInvoiceIDs, credit.ID)
+	}
+
+	return response, nil
+}
+
+func (s *invoiceService) ListInvoices(ctx context.Context, filter *types.InvoiceFilter) (*dto.ListInvoicesResponse, error) {
+	if filter.Limit == 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	// A cursor page reads one invoice more to know whether another page follows
+	query := *filter
+	if filter.UsesCursor() {
+		query.Limit++
+	}
+
+	invoices, err := s.invoiceRepo.List(ctx, &query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+
+	var nextCursor string
+	if filter.UsesCursor() && len(invoices) > filter.Limit {
+		invoices = invoices[:filter.Limit]
+		last := invoices[len(invoices)-1]
+		nextCursor = types.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	response := &dto.ListInvoicesResponse{
+		Invoices:   make([]dto.InvoiceResponse, len(invoices)),
+		Total:      len(invoices),
+		Offset:     filter.Offset,
+		Limit:      filter.Limit,
+		NextCursor: nextCursor,
+	}
+
+	for i, inv := range invoices {
+		response.Invoices[i] = dto.InvoiceResponse{Invoice: inv}
+	}
+
+	return response, nil
+}
+
+func (s *invoiceService) FinalizeInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error) {
+	var inv *invoice.Invoice
+	var before invoice.Invoice
+
+	// The invoice number is drawn inside the same transaction as the status change so
+	// that a failed finalization rolls back the counter and leaves no gaps
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		inv, err = s.invoiceRepo.Get(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if err := checkIfMatch(ctx, "invoice", inv.ID, inv.Version); err != nil {
+			return err
+		}
+
+		if inv.InvoiceStatus == types.InvoiceStatusHeld {
+			return fmt.Errorf("invoice is held for review and must be approved before being finalized")
+		}
+		if inv.InvoiceStatus != types.InvoiceStatusDraft {
+			return fmt.Errorf("invoice is not in draft status")
+		}
+
+		now := s.clock.Now()
+		number, err := nextInvoiceNumber(ctx, s.sequenceRepo, s.legalEntityRepo, inv.LegalEntityID, now)
+		if err != nil {
+			return err
+		}
+
+		before = *inv
+		inv.InvoiceNumber = &number
+		inv.InvoiceStatus = types.InvoiceStatusFinalized
+		inv.FinalizedAt = &now
+		inv.UpdatedAt = now
+		inv.UpdatedBy = types.GetUserID(ctx)
+
+		if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+			return fmt.Errorf("failed to finalize invoice: %w", err)
+		}
+
+		// Queued in the same transaction so the event is sent if and only if the
+		// invoice is finalized
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventInvoiceFinalized, inv); err != nil {
+			return fmt.Errorf("failed to publish invoice finalized webhook: %w", err)
+		}
+
+		if s.emailService != nil {
+			if err := s.emailService.QueueInvoiceEmail(ctx, inv); err != nil {
+				return fmt.Errorf("failed to queue invoice email: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionUpdate, &before, inv)
+
+	// The ledgers, CRMs and tax providers are synced once the invoice is committed, a
+	// failure to queue is left for a retry and does not fail the finalization
+	s.commitTaxes(ctx, inv)
+	if s.ledgerSyncService != nil {
+		if err := s.ledgerSyncService.SyncInvoice(ctx, inv); err != nil {
+			s.logger.Errorw("failed to queue ledger sync", "invoice_id", inv.ID, "error", err)
+		}
+	}
+	if s.crmSyncService != nil {
+		if err := s.crmSyncService.SyncInvoiceTotals(ctx, inv); err != nil {
+			s.logger.Errorw("failed to queue crm sync", "invoice_id", inv.ID, "error", err)
+		}
+	}
+
+	return &dto.InvoiceResponse{Invoice: inv}, nil
+}
+
+func (s *invoiceService) ApproveInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error) {
+	inv, err := s.invoiceRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	if err := checkIfMatch(ctx, "invoice", inv.ID, inv.Version); err != nil {
+		return nil, err
+	}
+
+	if inv.InvoiceStatus != types.InvoiceStatusHeld {
+		return nil, fmt.Errorf("invoice is not held for review")
+	}
+
+	before := *inv
+	before.Metadata = maps.Clone(inv.Metadata)
+
+	// The hold reason is kept along with the approver for the audit trail
+	if inv.Metadata == nil {
+		inv.Metadata = types.Metadata{}
+	}
+	inv.Metadata[invoiceApprovedByKey] = types.GetUserID(ctx)
+	inv.InvoiceStatus = types.InvoiceStatusDraft
+	inv.UpdatedAt = s.clock.Now()
+	inv.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+		return nil, fmt.Errorf("failed to approve invoice: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionUpdate, &before, inv)
+
+	return &dto.InvoiceResponse{Invoice: inv}, nil
+}
+
+// nextInvoiceNumber draws the number of an invoice finalized at t from the
+// sequence of the legal entity it is issued from, or of the tenant environment
+// when it has none
+func nextInvoiceNumber(ctx context.Context, sequenceRepo sequence.Repository, legalEntityRepo legalentity.Repository, legalEntityID string, t time.Time) (string, error) {
+	var numbering *sequence.InvoiceNumberingConfig
+	if legalEntityID != "" {
+		entity, err := legalEntityRepo.Get(ctx, legalEntityID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get legal entity: %w", err)
+		}
+		numbering = entity.InvoiceNumbering()
+	} else {
+		var err error
+		numbering, err = sequenceRepo.GetInvoiceNumberingConfig(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get invoice numbering config: %w", err)
+		}
+	}
+
+	value, err := sequenceRepo.NextValue(ctx, numbering.SequenceKey(t))
+	if err != nil {
+		return "", fmt.Errorf("failed to get next invoice number: %w", err)
+	}
+
+	return numbering.Format(value, t), nil
+}
+
+func (s *invoiceService) GetInvoiceNumberingConfig(ctx context.Context) (*dto.InvoiceNumberingConfigResponse, error) {
+	numbering, err := s.sequenceRepo.GetInvoiceNumberingConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice numbering config: %w", err)
+	}
+
+	return &dto.InvoiceNumberingConfigResponse{InvoiceNumberingConfig: numbering}, nil
+}
+
+func (s *invoiceService) UpdateInvoiceNumberingConfig(ctx context.Context, req dto.UpdateInvoiceNumberingConfigRequest) (*dto.InvoiceNumberingConfigResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	numbering, err := s.sequenceRepo.GetInvoiceNumberingConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice numbering config: %w", err)
+	}
+	if err := checkIfMatch(ctx, "invoice numbering config", numbering.TenantID, numbering.Version); err != nil {
+		return nil, err
+	}
+
+	before := *numbering
+	now := s.clock.Now()
+	if numbering.CreatedAt.IsZero() {
+		numbering.CreatedAt = now
+		numbering.CreatedBy = types.GetUserID(ctx)
+	}
+
+	numbering.Prefix = req.Prefix
+	numbering.Separator = req.Separator
+	numbering.Padding = req.Padding
+	numbering.ResetYearly = req.ResetYearly
+	numbering.UpdatedAt = now
+	numbering.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.sequenceRepo.UpsertInvoiceNumberingConfig(ctx, numbering); err != nil {
+		return nil, fmt.Errorf("failed to update invoice numbering config: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoiceNumbering, numbering.TenantID, types.AuditActionUpdate, &before, numbering)
+
+	return &dto.InvoiceNumberingConfigResponse{InvoiceNumberingConfig: numbering}, nil
+}
@@ -0,0 +1,499 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvoiceDisputed is returned when a second dispute is opened on an
+	// invoice, or when its payment is collected while it is disputed
+	ErrInvoiceDisputed = errors.New("invoice has an unresolved dispute")
+
+	// ErrDisputeNotFound is returned when the dispute is not one of the invoice
+	ErrDisputeNotFound = errors.New("invoice dispute not found")
+
+	// ErrDisputeResolved is returned when a resolved dispute is reviewed or
+	// resolved again
+	ErrDisputeResolved = errors.New("invoice dispute is already resolved")
+)
+
+// checkNotDisputed returns ErrInvoiceDisputed when the invoice has a dispute
+// that is not resolved, its collection is paused until it is
+func checkNotDisputed(ctx context.Context, invoiceRepo invoice.Repository, invoiceID string) error {
+	dispute, err := invoiceRepo.GetActiveDispute(ctx, invoiceID)
+	if err != nil {
+		return err
+	}
+	if dispute != nil {
+		return ErrInvoiceDisputed
+	}
+	return nil
+}
+
+// OpenDispute records the amounts of line items of a finalized invoice the
+// customer disputes. The amount disputed can not exceed the part of the invoice
+// that was not paid or submitted for collection
+func (s *invoiceService) OpenDispute(ctx context.Context, id string, req dto.OpenDisputeRequest) (*dto.DisputeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	var dispute *invoice.Dispute
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		inv, err := s.invoiceRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if inv.InvoiceType == types.InvoiceTypeCredit || inv.InvoiceStatus != types.InvoiceStatusFinalized {
+			return fmt.Errorf("invalid request: only finalized invoices can be disputed")
+		}
+		if err := checkNotDisputed(ctx, s.invoiceRepo, inv.ID); err != nil {
+			return err
+		}
+
+		// The locked header has no line items
+		withItems, err := s.invoiceRepo.Get(ctx, inv.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+		items := make(map[string]*invoice.InvoiceLineItem, len(withItems.LineItems))
+		for _, item := range withItems.LineItems {
+			items[item.ID] = item
+		}
+
+		lines := make(invoice.DisputedLines, 0, len(req.Lines))
+		amount := decimal.Zero
+		for i, line := range req.Lines {
+			item, ok := items[line.LineItemID]
+			if !ok {
+				return fmt.Errorf("%w: lines[%d]: %s", ErrInvoiceLineItemNotFound, i, line.LineItemID)
+			}
+			if line.Amount.GreaterThan(item.Amount) {
+				return fmt.Errorf("invalid request: lines[%d]: amount exceeds the amount of %s of the line item", i, item.Amount.String())
+			}
+			lines = append(lines, invoice.DisputedLine{
+				LineItemID: item.ID,
+				Amount:     line.Amount,
+				Reason:     line.Reason,
+			})
+			amount = amount.Add(line.Amount)
+		}
+
+		disputable, err := s.disputableAmount(ctx, inv)
+		if err != nil {
+			return err
+		}
+		if amount.GreaterThan(disputable) {
+			return fmt.Errorf("invalid request: disputed amount exceeds the unpaid amount of %s", disputable.String())
+		}
+
+		dispute = &invoice.Dispute{
+			ID:            types.GenerateUUID(),
+			InvoiceID:     inv.ID,
+			CustomerID:    inv.CustomerID,
+			DisputeStatus: types.DisputeStatusOpen,
+			Reason:        req.Reason,
+			Lines:         lines,
+			Amount:        amount,
+			Currency:      inv.Currency,
+			BaseModel:     types.GetDefaultBaseModel(ctx),
+		}
+		if err := s.invoiceRepo.CreateDispute(ctx, dispute); err != nil {
+			return fmt.Errorf("failed to create dispute: %w", err)
+		}
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventDisputeOpened, dispute); err != nil {
+			return fmt.Errorf("failed to publish dispute opened webhook: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoiceDispute, dispute.ID, types.AuditActionCreate, nil, dispute)
+
+	s.logger.Debugw("opened invoice dispute",
+		"invoice_id", dispute.InvoiceID,
+		"dispute_id", dispute.ID,
+		"amount", dispute.Amount,
+	)
+
+	return &dto.DisputeResponse{Dispute: dispute}, nil
+}
+
+// disputableAmount is the part of the amount due of the invoice that was not paid
+// and whose debits were not submitted yet. The debits still scheduled are
+// cancelled when the dispute adjusts the invoice
+func (s *invoiceService) disputableAmount(ctx context.Context, inv *invoice.Invoice) (decimal.Decimal, error) {
+	payments, err := s.invoiceRepo.ListPayments(ctx, inv.ID)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	amount := inv.AmountRemaining
+	for _, payment := range payments {
+		if payment.PaymentStatus == types.PaymentStatusScheduled {
+			amount = amount.Add(payment.Amount)
+		}
+	}
+	return amount, nil
+}
+
+func (s *invoiceService) ListDisputes(ctx context.Context, id string) (*dto.ListDisputesResponse, error) {
+	inv, err := s.invoiceRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	disputes, err := s.invoiceRepo.ListDisputes(ctx, inv.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disputes: %w", err)
+	}
+
+	response := &dto.ListDisputesResponse{
+		Disputes: make([]dto.DisputeResponse, len(disputes)),
+	}
+	for i, dispute := range disputes {
+		response.Disputes[i] = dto.DisputeResponse{Dispute: dispute}
+	}
+	return response, nil
+}
+
+// ReviewDispute moves an open dispute under review
+func (s *invoiceService) ReviewDispute(ctx context.Context, id, disputeID string) (*dto.DisputeResponse, error) {
+	var dispute *invoice.Dispute
+	var before invoice.Dispute
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := s.invoiceRepo.GetForUpdate(ctx, id); err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+
+		var err error
+		dispute, err = s.disputeOf(ctx, id, disputeID)
+		if err != nil {
+			return err
+		}
+		if dispute.DisputeStatus != types.DisputeStatusOpen {
+			return fmt.Errorf("invalid request: dispute is already under review")
+		}
+
+		before = *dispute
+		dispute.DisputeStatus = types.DisputeStatusUnderReview
+		dispute.UpdatedAt = s.clock.Now()
+		dispute.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.UpdateDispute(ctx, dispute); err != nil {
+			return fmt.Errorf("failed to update dispute: %w", err)
+		}
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventDisputeUnderReview, dispute); err != nil {
+			return fmt.Errorf("failed to publish dispute under review webhook: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoiceDispute, dispute.ID, types.AuditActionUpdate, &before, dispute)
+	return &dto.DisputeResponse{Dispute: dispute}, nil
+}
+
+// ResolveDispute settles the dispute of an invoice. A credit note credits the
+// disputed amount to the invoice, a re-issue voids the invoice and raises a
+// corrected draft without the disputed amounts, which is finalized once it was
+// reviewed. Both cancel the debits of the invoice still scheduled as its amount
+// changes. An upheld invoice is collected as it was issued
+func (s *invoiceService) ResolveDispute(ctx context.Context, id, disputeID string, req dto.ResolveDisputeRequest) (*dto.DisputeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	var dispute *invoice.Dispute
+	var beforeDispute invoice.Dispute
+	var inv *invoice.Invoice
+	var beforeInvoice invoice.Invoice
+	var cancelled []*invoice.Payment
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		inv, err = s.invoiceRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+		dispute, err = s.disputeOf(ctx, id, disputeID)
+		if err != nil {
+			return err
+		}
+
+		now := s.clock.Now()
+		beforeDispute = *dispute
+		beforeInvoice = *inv
+
+		if req.Resolution != types.DisputeResolutionUphold {
+			cancelled, err = s.cancelScheduledDebits(ctx, inv, "invoice dispute resolved", now)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch req.Resolution {
+		case types.DisputeResolutionCreditNote:
+			dispute.CreditNoteID, err = s.creditDispute(ctx, inv, dispute, now)
+		case types.DisputeResolutionReissue:
+			dispute.ReissuedInvoiceID, err = s.reissueInvoice(ctx, inv, dispute, now)
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.Resolution != types.DisputeResolutionUphold {
+			inv.UpdatedAt = now
+			inv.UpdatedBy = types.GetUserID(ctx)
+			if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+				return fmt.Errorf("failed to update invoice: %w", err)
+			}
+		}
+
+		dispute.DisputeStatus = types.DisputeStatusResolved
+		dispute.Resolution = req.Resolution
+		dispute.ResolutionNote = req.Note
+		dispute.ResolvedAt = &now
+		dispute.UpdatedAt = now
+		dispute.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.UpdateDispute(ctx, dispute); err != nil {
+			return fmt.Errorf("failed to update dispute: %w", err)
+		}
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventDisputeResolved, dispute); err != nil {
+			return fmt.Errorf("failed to publish dispute resolved webhook: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoiceDispute, dispute.ID, types.AuditActionUpdate, &beforeDispute, dispute)
+	for _, payment := range cancelled {
+		recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoicePayment, payment.ID, types.AuditActionUpdate, nil, payment)
+	}
+	if req.Resolution != types.DisputeResolutionUphold {
+		recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionUpdate, &beforeInvoice, inv)
+	}
+
+	if req.Resolution == types.DisputeResolutionReissue && s.taxService != nil {
+		voided, err := s.invoiceRepo.Get(ctx, inv.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if err := s.taxService.VoidInvoice(ctx, voided); err != nil {
+			s.logger.Errorw("failed to queue tax void", "invoice_id", voided.ID, "error", err)
+		}
+	}
+
+	s.logger.Debugw("resolved invoice dispute",
+		"invoice_id", dispute.InvoiceID,
+		"dispute_id", dispute.ID,
+		"resolution", dispute.Resolution,
+		"credit_note_id", dispute.CreditNoteID,
+		"reissued_invoice_id", dispute.ReissuedInvoiceID,
+	)
+
+	return &dto.DisputeResponse{Dispute: dispute}, nil
+}
+
+// disputeOf returns the dispute of the invoice, which must not be resolved
+func (s *invoiceService) disputeOf(ctx context.Context, invoiceID, disputeID string) (*invoice.Dispute, error) {
+	dispute, err := s.invoiceRepo.GetDispute(ctx, disputeID)
+	if err != nil || dispute.InvoiceID != invoiceID {
+		return nil, ErrDisputeNotFound
+	}
+	if !dispute.IsActive() {
+		return nil, ErrDisputeResolved
+	}
+	return dispute, nil
+}
+
+// cancelScheduledDebits cancels the bank debits of the invoice that were not
+// submitted yet and releases their amount. The caller saves the invoice
+func (s *invoiceService) cancelScheduledDebits(ctx context.Context, inv *invoice.Invoice, reason string, now time.Time) ([]*invoice.Payment, error) {
+	payments, err := s.invoiceRepo.ListPayments(ctx, inv.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	var cancelled []*invoice.Payment
+	for _, payment := range payments {
+		if payment.PaymentStatus != types.PaymentStatusScheduled {
+			continue
+		}
+
+		payment.PaymentStatus = types.PaymentStatusCancelled
+		payment.FailureReason = reason
+		payment.UpdatedAt = now
+		payment.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.UpdatePayment(ctx, payment); err != nil {
+			return nil, err
+		}
+
+		inv.AmountProcessing = inv.AmountProcessing.Sub(payment.Amount)
+		cancelled = append(cancelled, payment)
+	}
+
+	inv.RecalculateAmountRemaining()
+	return cancelled, nil
+}
+
+// creditDispute issues a finalized credit note for the disputed amounts and
+// allocates it to the invoice, whose amount due it reduces. It returns the ID
+// of the credit note
+func (s *invoiceService) creditDispute(ctx context.Context, inv *invoice.Invoice, dispute *invoice.Dispute, now time.Time) (string, error) {
+	withItems, err := s.invoiceRepo.Get(ctx, inv.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get invoice: %w", err)
+	}
+	names := make(map[string]string, len(withItems.LineItems))
+	for _, item := range withItems.LineItems {
+		names[item.ID] = item.DisplayName
+	}
+
+	number, err := nextInvoiceNumber(ctx, s.sequenceRepo, s.legalEntityRepo, inv.LegalEntityID, now)
+	if err != nil {
+		return "", err
+	}
+
+	creditNote := &invoice.Invoice{
+		ID:                types.GenerateUUID(),
+		InvoiceNumber:     &number,
+		CustomerID:        inv.CustomerID,
+		SubscriptionID:    inv.SubscriptionID,
+		LegalEntityID:     inv.LegalEntityID,
+		InvoiceType:       types.InvoiceTypeCredit,
+		InvoiceStatus:     types.InvoiceStatusFinalized,
+		Currency:          inv.Currency,
+		OriginalInvoiceID: inv.ID,
+		Description:       dispute.Reason,
+		FinalizedAt:       &now,
+		BaseModel:         types.GetDefaultBaseModel(ctx),
+	}
+	for _, line := range dispute.Lines {
+		creditNote.LineItems = append(creditNote.LineItems, &invoice.InvoiceLineItem{
+			ID:             types.GenerateUUID(),
+			InvoiceID:      creditNote.ID,
+			CustomerID:     creditNote.CustomerID,
+			SubscriptionID: creditNote.SubscriptionID,
+			DisplayName:    "Disputed " + names[line.LineItemID],
+			Amount:         line.Amount.Neg(),
+			Quantity:       decimal.NewFromInt(1),
+			Currency:       creditNote.Currency,
+			BaseModel:      types.GetDefaultBaseModel(ctx),
+		})
+	}
+	creditNote.RecalculateTotals()
+
+	if err := s.invoiceRepo.Create(ctx, creditNote); err != nil {
+		return "", fmt.Errorf("failed to create credit note: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, creditNote.ID, types.AuditActionCreate, nil, creditNote)
+
+	err = s.invoiceRepo.CreateCreditAllocation(ctx, &invoice.CreditAllocation{
+		ID:              types.GenerateUUID(),
+		CreditInvoiceID: creditNote.ID,
+		CustomerID:      creditNote.CustomerID,
+		TargetType:      types.CreditAllocationTargetInvoice,
+		TargetID:        inv.ID,
+		Amount:          dispute.Amount,
+		Currency:        creditNote.Currency,
+		BaseModel:       types.GetDefaultBaseModel(ctx),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to allocate credit note: %w", err)
+	}
+
+	inv.AmountDue = decimal.Max(inv.AmountDue.Sub(dispute.Amount), decimal.Zero)
+	inv.RecalculateAmountRemaining()
+	return creditNote.ID, nil
+}
+
+// reissueInvoice voids the invoice and raises a draft copy of it without the
+// disputed amounts. The taxes quoted through the tax connection are quoted again
+// for the corrected charges, manual taxes are copied as they were and can be
+// edited on the draft. It returns the ID of the corrected invoice
+func (s *invoiceService) reissueInvoice(ctx context.Context, inv *invoice.Invoice, dispute *invoice.Dispute, now time.Time) (string, error) {
+	if !inv.AmountPaid.IsZero() || !inv.AmountProcessing.IsZero() || !inv.AmountDue.Equal(decimal.Max(inv.Total, decimal.Zero)) {
+		return "", fmt.Errorf("%w: invoice has payments or credits, resolve the dispute with a credit note", ErrInvoiceNotVoidable)
+	}
+
+	withItems, err := s.invoiceRepo.Get(ctx, inv.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	inv.InvoiceStatus = types.InvoiceStatusVoided
+
+	metadata := maps.Clone(inv.Metadata)
+	if metadata == nil {
+		metadata = types.Metadata{}
+	}
+	metadata[invoice.MetadataReissuedFrom] = inv.ID
+
+	corrected := &invoice.Invoice{
+		ID:                    types.GenerateUUID(),
+		CustomerID:            inv.CustomerID,
+		SubscriptionID:        inv.SubscriptionID,
+		LegalEntityID:         inv.LegalEntityID,
+		InvoiceType:           inv.InvoiceType,
+		InvoiceFlow:           inv.InvoiceFlow,
+		InvoiceStatus:         types.InvoiceStatusDraft,
+		Currency:              inv.Currency,
+		Description:           inv.Description,
+		PeriodStart:           inv.PeriodStart,
+		PeriodEnd:             inv.PeriodEnd,
+		PartialPeriodBehavior: inv.PartialPeriodBehavior,
+		Metadata:              metadata,
+		BaseModel:             types.GetDefaultBaseModel(ctx),
+	}
+
+	disputed := make(map[string]decimal.Decimal, len(dispute.Lines))
+	for _, line := range dispute.Lines {
+		disputed[line.LineItemID] = line.Amount
+	}
+	for _, item := range withItems.LineItems {
+		if item.TaxSource().IsQuoted() {
+			continue
+		}
+		amount := item.Amount.Sub(disputed[item.ID])
+		if amount.IsZero() {
+			continue
+		}
+		corrected.LineItems = append(corrected.LineItems, s.newLineItem(ctx, corrected, lineItemParams{
+			PriceID:     item.PriceID,
+			MeterID:     item.MeterID,
+			DisplayName: item.DisplayName,
+			Amount:      amount,
+			Quantity:    item.Quantity,
+			Metadata:    item.Metadata,
+		}))
+	}
+	corrected.RecalculateTotals()
+
+	if err := s.taxInvoice(ctx, corrected); err != nil {
+		return "", err
+	}
+	if err := s.issueInvoice(ctx, corrected); err != nil {
+		return "", err
+	}
+	return corrected.ID, nil
+}
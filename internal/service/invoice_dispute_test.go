@@ -0,0 +1,233 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceDispute(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:        "cust_1",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:  "ep_1",
+		URL: "https://example.com/hooks",
+		EventTypes: []string{
+			string(types.WebhookEventDisputeOpened),
+			string(types.WebhookEventDisputeUnderReview),
+			string(types.WebhookEventDisputeResolved),
+		},
+		Secret:    "whsec_test",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+	publisher := webhook.NewPublisher(webhookStore, logger.GetLogger())
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	svc := NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), testutil.NewInMemorySubscriptionStore(),
+		testutil.NewInMemoryPlanStore(), testutil.NewInMemoryPriceStore(),
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(),
+		testutil.NewInMemoryRateCardStore(), testutil.NewInMemoryPriceBookStore(),
+		nil, testutil.NewInMemoryTxManager(), publisher,
+		nil, nil, nil, nil,
+		nil, nil, nil, nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	fakeGateway := &fakeCollectionGateway{}
+	payments := NewPaymentService(invoiceStore, customerStore, testutil.NewInMemoryPaymentMethodStore(),
+		testutil.NewInMemoryConnectionStore(), nil, testutil.NewInMemoryMandateStore(), nil,
+		testutil.NewInMemoryTxManager(), publisher, nil, nil, logger.GetLogger()).(*paymentService)
+	payments.newGateway = func(*connection.Connection) (collectionGateway, error) { return fakeGateway, nil }
+
+	hundred := decimal.NewFromInt(100)
+	fifty := decimal.NewFromInt(50)
+	finalized := func() *dto.InvoiceResponse {
+		resp, err := svc.CreateOneOffInvoice(ctx, dto.CreateOneOffInvoiceRequest{
+			CustomerID: "cust_1",
+			Currency:   "usd",
+			LineItems: []dto.OneOffInvoiceLineItemRequest{
+				{DisplayName: "Onboarding", UnitAmount: &hundred},
+				{DisplayName: "Training", UnitAmount: &fifty},
+			},
+			Finalize: true,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	getInvoice := func(id string) *invoice.Invoice {
+		inv, err := invoiceStore.Get(ctx, id)
+		require.NoError(t, err)
+		return inv
+	}
+	dispute := func(inv *dto.InvoiceResponse, line int, amount int64) (*dto.DisputeResponse, error) {
+		return svc.OpenDispute(ctx, inv.ID, dto.OpenDisputeRequest{
+			Reason: "Not delivered",
+			Lines:  []dto.DisputedLineRequest{{LineItemID: inv.LineItems[line].ID, Amount: decimal.NewFromInt(amount)}},
+		})
+	}
+
+	t.Run("credit note resolution", func(t *testing.T) {
+		inv := finalized()
+
+		// A debit of the invoice is scheduled and due
+		chargeDate := time.Now().Add(-time.Hour)
+		require.NoError(t, invoiceStore.CreatePayment(ctx, &invoice.Payment{
+			ID:            "debit_1",
+			InvoiceID:     inv.ID,
+			CustomerID:    inv.CustomerID,
+			Amount:        fifty,
+			Currency:      inv.Currency,
+			PaidAt:        chargeDate,
+			PaymentStatus: types.PaymentStatusScheduled,
+			MandateID:     "mandate_1",
+			ChargeDate:    &chargeDate,
+			BaseModel:     types.GetDefaultBaseModel(ctx),
+		}))
+		scheduled := getInvoice(inv.ID)
+		scheduled.AmountProcessing = fifty
+		scheduled.RecalculateAmountRemaining()
+		require.NoError(t, invoiceStore.Update(ctx, scheduled))
+
+		_, err := dispute(inv, 0, 120)
+		assert.Error(t, err)
+		_, err = svc.OpenDispute(ctx, inv.ID, dto.OpenDisputeRequest{
+			Lines: []dto.DisputedLineRequest{{LineItemID: "li_unknown", Amount: fifty}},
+		})
+		assert.ErrorIs(t, err, ErrInvoiceLineItemNotFound)
+
+		opened, err := dispute(inv, 0, 40)
+		require.NoError(t, err)
+		assert.Equal(t, types.DisputeStatusOpen, opened.DisputeStatus)
+		assert.True(t, decimal.NewFromInt(40).Equal(opened.Amount))
+
+		_, err = dispute(inv, 1, 10)
+		assert.ErrorIs(t, err, ErrInvoiceDisputed)
+
+		// Collection is paused, the due debit stays scheduled
+		require.NoError(t, payments.CollectDueDebits(ctx, time.Now()))
+		assert.Empty(t, fakeGateway.debits)
+		debit, err := invoiceStore.GetPayment(ctx, "debit_1")
+		require.NoError(t, err)
+		assert.Equal(t, types.PaymentStatusScheduled, debit.PaymentStatus)
+
+		_, err = payments.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: "conn_1"})
+		assert.ErrorIs(t, err, ErrInvoiceDisputed)
+
+		reviewed, err := svc.ReviewDispute(ctx, inv.ID, opened.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.DisputeStatusUnderReview, reviewed.DisputeStatus)
+		_, err = svc.ReviewDispute(ctx, inv.ID, opened.ID)
+		assert.Error(t, err)
+
+		resolved, err := svc.ResolveDispute(ctx, inv.ID, opened.ID, dto.ResolveDisputeRequest{
+			Resolution: types.DisputeResolutionCreditNote,
+			Note:       "Onboarding was partly delivered",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, types.DisputeStatusResolved, resolved.DisputeStatus)
+		require.NotEmpty(t, resolved.CreditNoteID)
+		require.NotNil(t, resolved.ResolvedAt)
+
+		creditNote := getInvoice(resolved.CreditNoteID)
+		assert.Equal(t, types.InvoiceTypeCredit, creditNote.InvoiceType)
+		assert.Equal(t, types.InvoiceStatusFinalized, creditNote.InvoiceStatus)
+		assert.Equal(t, inv.ID, creditNote.OriginalInvoiceID)
+		assert.True(t, decimal.NewFromInt(-40).Equal(creditNote.Total))
+
+		// The scheduled debit was cancelled, the adjusted amount is collected anew
+		updated := getInvoice(inv.ID)
+		assert.True(t, decimal.NewFromInt(110).Equal(updated.AmountDue))
+		assert.True(t, updated.AmountProcessing.IsZero())
+		assert.True(t, decimal.NewFromInt(110).Equal(updated.AmountRemaining))
+		debit, err = invoiceStore.GetPayment(ctx, "debit_1")
+		require.NoError(t, err)
+		assert.Equal(t, types.PaymentStatusCancelled, debit.PaymentStatus)
+
+		_, err = svc.ResolveDispute(ctx, inv.ID, opened.ID, dto.ResolveDisputeRequest{Resolution: types.DisputeResolutionUphold})
+		assert.ErrorIs(t, err, ErrDisputeResolved)
+		_, err = svc.ReviewDispute(ctx, "inv_other", opened.ID)
+		assert.Error(t, err)
+		assert.NoError(t, checkNotDisputed(ctx, invoiceStore, inv.ID))
+	})
+
+	t.Run("re-issue resolution", func(t *testing.T) {
+		inv := finalized()
+		opened, err := dispute(inv, 1, 50)
+		require.NoError(t, err)
+
+		resolved, err := svc.ResolveDispute(ctx, inv.ID, opened.ID, dto.ResolveDisputeRequest{Resolution: types.DisputeResolutionReissue})
+		require.NoError(t, err)
+		require.NotEmpty(t, resolved.ReissuedInvoiceID)
+
+		assert.Equal(t, types.InvoiceStatusVoided, getInvoice(inv.ID).InvoiceStatus)
+
+		corrected := getInvoice(resolved.ReissuedInvoiceID)
+		assert.Equal(t, types.InvoiceStatusDraft, corrected.InvoiceStatus)
+		assert.Equal(t, inv.ID, corrected.Metadata[invoice.MetadataReissuedFrom])
+		assert.True(t, hundred.Equal(corrected.Total))
+		require.Len(t, corrected.LineItems, 1)
+		assert.Equal(t, "Onboarding", corrected.LineItems[0].DisplayName)
+	})
+
+	t.Run("paid invoices are not re-issued", func(t *testing.T) {
+		inv := finalized()
+		_, err := svc.RecordPayment(ctx, inv.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(10)})
+		require.NoError(t, err)
+
+		opened, err := dispute(inv, 1, 50)
+		require.NoError(t, err)
+
+		_, err = svc.ResolveDispute(ctx, inv.ID, opened.ID, dto.ResolveDisputeRequest{Resolution: types.DisputeResolutionReissue})
+		assert.ErrorIs(t, err, ErrInvoiceNotVoidable)
+		assert.Equal(t, types.InvoiceStatusFinalized, getInvoice(inv.ID).InvoiceStatus)
+	})
+
+	t.Run("upheld invoices are unchanged", func(t *testing.T) {
+		inv := finalized()
+		opened, err := dispute(inv, 0, 100)
+		require.NoError(t, err)
+
+		resolved, err := svc.ResolveDispute(ctx, inv.ID, opened.ID, dto.ResolveDisputeRequest{Resolution: types.DisputeResolutionUphold})
+		require.NoError(t, err)
+		assert.Equal(t, types.DisputeResolutionUphold, resolved.Resolution)
+
+		updated := getInvoice(inv.ID)
+		assert.True(t, decimal.NewFromInt(150).Equal(updated.AmountRemaining))
+
+		list, err := svc.ListDisputes(ctx, inv.ID)
+		require.NoError(t, err)
+		require.Len(t, list.Disputes, 1)
+	})
+
+	deliveries, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	counts := make(map[types.WebhookEventType]int)
+	for _, delivery := range deliveries {
+		counts[delivery.EventType]++
+	}
+	assert.Equal(t, 4, counts[types.WebhookEventDisputeOpened])
+	assert.Equal(t, 1, counts[types.WebhookEventDisputeUnderReview])
+	assert.Equal(t, 3, counts[types.WebhookEventDisputeResolved])
+}
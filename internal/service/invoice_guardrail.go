@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Metadata keys recording why an invoice was held and who approved it
+const (
+	invoiceHoldReasonKey = "hold_reason"
+	invoiceApprovedByKey = "hold_approved_by"
+)
+
+// applyGuardrails holds the invoice for review when its total exceeds the cap of
+// its currency or deviates too much from the trailing invoices of the customer
+func (s *invoiceService) applyGuardrails(ctx context.Context, inv *invoice.Invoice) error {
+	guardrails := s.cfg.Guardrails
+	if !guardrails.Enabled || inv.InvoiceType != types.InvoiceTypeSubscription || !inv.Total.IsPositive() {
+		return nil
+	}
+
+	var trailing []*invoice.Invoice
+	if guardrails.TrailingInvoices > 0 && guardrails.MaxDeviationPercent > 0 {
+		var err error
+		trailing, err = s.invoiceRepo.List(ctx, &types.InvoiceFilter{
+			Filter:        types.Filter{Limit: guardrails.TrailingInvoices},
+			CustomerID:    inv.CustomerID,
+			InvoiceType:   types.InvoiceTypeSubscription,
+			InvoiceStatus: types.InvoiceStatusFinalized,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list trailing invoices: %w", err)
+		}
+	}
+
+	reason := guardrailViolation(guardrails.MaxTotal, guardrails.MaxDeviationPercent, inv, trailing)
+	if reason == "" {
+		return nil
+	}
+
+	inv.InvoiceStatus = types.InvoiceStatusHeld
+	if inv.Metadata == nil {
+		inv.Metadata = types.Metadata{}
+	}
+	inv.Metadata[invoiceHoldReasonKey] = reason
+
+	s.logger.Warnw("invoice held for review",
+		"invoice_id", inv.ID,
+		"customer_id", inv.CustomerID,
+		"total", inv.Total,
+		"reason", reason,
+	)
+
+	return nil
+}
+
+// guardrailViolation returns why the invoice must be held, empty when it passes.
+// Trailing invoices in another currency are ignored. Threshold invoices cover part
+// of a period only, so they are held past the cap but never compared to the trend
+func guardrailViolation(maxTotal map[string]float64, maxDeviationPercent float64, inv *invoice.Invoice, trailing []*invoice.Invoice) string {
+	if limit, ok := maxTotal[inv.Currency]; ok && limit > 0 {
+		if inv.Total.GreaterThan(decimal.NewFromFloat(limit)) {
+			return fmt.Sprintf("total %s exceeds the cap of %s %s",
+				inv.Total.String(), decimal.NewFromFloat(limit).String(), inv.Currency)
+		}
+	}
+
+	if maxDeviationPercent <= 0 || inv.InvoiceFlow == types.InvoiceFlowThreshold {
+		return ""
+	}
+
+	sum := decimal.Zero
+	count := 0
+	for _, previous := range trailing {
+		if previous.Currency != inv.Currency || previous.InvoiceFlow == types.InvoiceFlowThreshold {
+			continue
+		}
+		sum = sum.Add(previous.Total)
+		count++
+	}
+
+	if count == 0 || !sum.IsPositive() {
+		return ""
+	}
+
+	average := sum.Div(decimal.NewFromInt(int64(count)))
+	deviation := inv.Total.Sub(average).Abs().Div(average).Mul(decimal.NewFromInt(100))
+	if deviation.GreaterThan(decimal.NewFromFloat(maxDeviationPercent)) {
+		return fmt.Sprintf("total %s deviates %s%% from the trailing average of %s",
+			inv.Total.String(), deviation.Round(2).String(), average.Round(types.GetCurrencyPrecision(inv.Currency)).String())
+	}
+
+	return ""
+}
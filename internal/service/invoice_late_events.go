@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// defaultLateEventsLookbackDays is how long finalized invoices are watched for
+// late events when the billing config does not say
+const defaultLateEventsLookbackDays = 30
+
+// ReconcileLateEvents looks for the events ingested in the period of an invoice
+// after its usage was calculated. The usage of the draft and held invoices is
+// recalculated, and an invoice.late_usage webhook is sent for the finalized ones.
+// Each invoice records the ingestion time it was reconciled up to, so the late
+// events are only acted on once
+func (s *invoiceService) ReconcileLateEvents(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+
+	lookbackDays := s.cfg.LateEventsLookbackDays
+	if lookbackDays <= 0 {
+		lookbackDays = defaultLateEventsLookbackDays
+	}
+
+	invoices, err := s.invoiceRepo.ListForReconciliation(ctx, now.AddDate(0, 0, -lookbackDays))
+	if err != nil {
+		return fmt.Errorf("failed to list invoices: %w", err)
+	}
+
+	forEachJobItem(ctx, invoices, func(inv *invoice.Invoice) {
+		// Consolidated invoices bill several subscriptions and are not recalculated
+		if inv.SubscriptionID == "" {
+			return
+		}
+
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, inv.TenantID)
+		tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+		// One failing invoice must not hold back the others
+		if err := s.reconcileLateEvents(tenantCtx, inv, now); err != nil {
+			s.logger.Errorw("failed to reconcile late events",
+				"tenant_id", inv.TenantID,
+				"invoice_id", inv.ID,
+				"error", err,
+			)
+		}
+	})
+
+	return nil
+}
+
+func (s *invoiceService) reconcileLateEvents(ctx context.Context, inv *invoice.Invoice, now time.Time) error {
+	checkedAt, err := lateEventsCheckedAt(inv)
+	if err != nil {
+		return err
+	}
+	if !now.After(checkedAt) {
+		return nil
+	}
+
+	params, err := s.lateEventsParams(ctx, inv)
+	if err != nil {
+		return err
+	}
+	// Plans without usage prices have no usage to reconcile
+	if params == nil {
+		return nil
+	}
+	params.IngestedAfter = checkedAt
+	params.IngestedBefore = now
+
+	count, err := s.eventRepo.CountLateEvents(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to count late events: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	if inv.InvoiceStatus == types.InvoiceStatusFinalized {
+		return s.reportLateUsage(ctx, inv, count, checkedAt, now)
+	}
+	return s.recalculateUsage(ctx, inv, count, now)
+}
+
+// lateEventsParams selects the events billed by the plan of the subscription of
+// the invoice, nil when the plan bills no usage
+func (s *invoiceService) lateEventsParams(ctx context.Context, inv *invoice.Invoice) (*events.LateEventsParams, error) {
+	sub, err := s.subscriptionRepo.Get(ctx, inv.SubscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	prices, err := s.priceRepo.GetByPlanID(ctx, sub.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var eventNames []string
+	for _, p := range prices {
+		if p.MeterID == "" || seen[p.MeterID] {
+			continue
+		}
+		seen[p.MeterID] = true
+
+		m, err := s.meterRepo.GetMeter(ctx, p.MeterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get meter: %w", err)
+		}
+		eventNames = append(eventNames, m.EventName)
+	}
+	if len(eventNames) == 0 {
+		return nil, nil
+	}
+
+	cust, err := s.customerRepo.Get(ctx, inv.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	return &events.LateEventsParams{
+		ExternalCustomerID: cust.ExternalID,
+		EventNames:         eventNames,
+		StartTime:          *inv.PeriodStart,
+		EndTime:            *inv.PeriodEnd,
+	}, nil
+}
+
+// recalculateUsage replaces the usage line items of a draft or held invoice with
+// the usage of its period as metered now. A usage line item whose amount was
+// edited keeps the edit on top of its new metered amount
+func (s *invoiceService) recalculateUsage(ctx context.Context, inv *invoice.Invoice, lateEvents uint64, now time.Time) error {
+	rebuilt, err := s.buildSubscriptionInvoice(ctx, inv.SubscriptionID, dto.CreateSubscriptionInvoiceRequest{
+		PeriodStart: *inv.PeriodStart,
+		PeriodEnd:   *inv.PeriodEnd,
+	}, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	metered := make(map[string]*invoice.InvoiceLineItem)
+	for _, item := range rebuilt.LineItems {
+		if item.MeterID != "" {
+			metered[item.PriceID] = item
+		}
+	}
+
+	var before invoice.Invoice
+	updated, err := s.editDraftInvoice(ctx, inv.ID, func(ctx context.Context, current *invoice.Invoice) error {
+		before = *current
+
+		kept := make([]*invoice.InvoiceLineItem, 0, len(current.LineItems))
+		for _, item := range current.LineItems {
+			if item.MeterID == "" {
+				kept = append(kept, item)
+				continue
+			}
+
+			fresh, ok := metered[item.PriceID]
+			if !ok {
+				if err := s.invoiceRepo.DeleteLineItem(ctx, item); err != nil {
+					return fmt.Errorf("failed to remove invoice line item: %w", err)
+				}
+				continue
+			}
+			delete(metered, item.PriceID)
+
+			if err := remeterLineItem(item, fresh); err != nil {
+				return err
+			}
+			item.UpdatedAt = now
+			item.UpdatedBy = types.GetUserID(ctx)
+			if err := s.invoiceRepo.UpdateLineItem(ctx, item); err != nil {
+				return fmt.Errorf("failed to update invoice line item: %w", err)
+			}
+			kept = append(kept, item)
+		}
+
+		// Usage of prices the draft had no line item for, in a stable order
+		priceIDs := make([]string, 0, len(metered))
+		for priceID := range metered {
+			priceIDs = append(priceIDs, priceID)
+		}
+		sort.Strings(priceIDs)
+		for _, priceID := range priceIDs {
+			item := metered[priceID]
+			item.InvoiceID = current.ID
+			if err := s.invoiceRepo.CreateLineItem(ctx, item); err != nil {
+				return fmt.Errorf("failed to add invoice line item: %w", err)
+			}
+			kept = append(kept, item)
+		}
+
+		current.LineItems = kept
+		current.Metadata = maps.Clone(current.Metadata)
+		if current.Metadata == nil {
+			current.Metadata = types.Metadata{}
+		}
+		current.Metadata[invoice.MetadataLateEventsCheckedAt] = now.Format(time.RFC3339Nano)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, updated.ID, types.AuditActionUpdate, &before, updated)
+
+	s.logger.Infow("recalculated invoice usage for late events",
+		"invoice_id", updated.ID,
+		"late_events", lateEvents,
+		"total_before", before.Total,
+		"total", updated.Total,
+	)
+	return nil
+}
+
+// remeterLineItem sets the quantity and amount of a usage line item to those of
+// its new metering, keeping the difference an edit made to its metered amount
+func remeterLineItem(item, fresh *invoice.InvoiceLineItem) error {
+	item.Quantity = fresh.Quantity
+	item.Metadata = maps.Clone(item.Metadata)
+
+	value, ok := item.Metadata[invoice.MetadataMeteredAmount]
+	if !ok {
+		item.Amount = fresh.Amount
+		return nil
+	}
+
+	previous, err := decimal.NewFromString(value)
+	if err != nil {
+		return fmt.Errorf("invalid metered amount of line item %s: %w", item.ID, err)
+	}
+	item.Amount = fresh.Amount.Add(item.Amount.Sub(previous))
+	item.Metadata[invoice.MetadataMeteredAmount] = fresh.Amount.String()
+	return nil
+}
+
+// reportLateUsage sends the invoice.late_usage webhook of a finalized invoice and
+// records that its late events were reported
+func (s *invoiceService) reportLateUsage(ctx context.Context, inv *invoice.Invoice, lateEvents uint64, checkedAt, now time.Time) error {
+	return s.db.WithTx(ctx, func(ctx context.Context) error {
+		current, err := s.invoiceRepo.GetForUpdate(ctx, inv.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+
+		current.Metadata = maps.Clone(current.Metadata)
+		if current.Metadata == nil {
+			current.Metadata = types.Metadata{}
+		}
+		current.Metadata[invoice.MetadataLateEventsCheckedAt] = now.Format(time.RFC3339Nano)
+		current.UpdatedAt = now
+		current.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.Update(ctx, current); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+
+		s.logger.Infow("late events landed in a finalized invoice period",
+			"invoice_id", current.ID,
+			"late_events", lateEvents,
+		)
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventInvoiceLateUsage, &dto.InvoiceLateUsageEvent{
+			Invoice:        current,
+			LateEvents:     lateEvents,
+			IngestedAfter:  checkedAt,
+			IngestedBefore: now,
+		}); err != nil {
+			return fmt.Errorf("failed to publish invoice late usage webhook: %w", err)
+		}
+		return nil
+	})
+}
+
+// lateEventsCheckedAt returns the ingestion time up to which the events of the
+// period of the invoice were reconciled with it
+func lateEventsCheckedAt(inv *invoice.Invoice) (time.Time, error) {
+	value, ok := inv.Metadata[invoice.MetadataLateEventsCheckedAt]
+	if !ok {
+		return inv.CreatedAt, nil
+	}
+
+	checkedAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s of invoice %s: %w", invoice.MetadataLateEventsCheckedAt, inv.ID, err)
+	}
+	return checkedAt, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceService_ReconcileLateEvents(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_123",
+		ExternalID: "ext_cust_123",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	meterStore := testutil.NewInMemoryMeterStore()
+	require.NoError(t, meterStore.CreateMeter(ctx, &meter.Meter{
+		ID:          "meter_api_calls",
+		Name:        "API Calls",
+		EventName:   "api_call",
+		Aggregation: meter.Aggregation{Type: types.AggregationCount},
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}))
+
+	planStore := testutil.NewInMemoryPlanStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Usage Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	priceStore := testutil.NewInMemoryPriceStore()
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_api_calls",
+		PlanID:             "plan_123",
+		MeterID:            "meter_api_calls",
+		Type:               types.PRICE_TYPE_USAGE,
+		Amount:             decimal.NewFromInt(1),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, -1, 0)
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	eventStore := testutil.NewInMemoryEventStore()
+	calls := func(count int, at, ingestedAt time.Time) {
+		for i := 0; i < count; i++ {
+			require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+				ID:                 types.GenerateUUID(),
+				TenantID:           types.GetTenantID(ctx),
+				EventName:          "api_call",
+				ExternalCustomerID: "ext_cust_123",
+				Timestamp:          at,
+				IngestedAt:         ingestedAt,
+				Properties:         map[string]interface{}{},
+			}))
+		}
+	}
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:         "ep_1",
+		URL:        "https://example.com/hooks",
+		Secret:     "whsec_test",
+		EventTypes: []string{string(types.WebhookEventInvoiceLateUsage)},
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	featureFlagService := NewFeatureFlagService(testutil.NewInMemoryFeatureFlagStore(), logger.GetLogger())
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	svc := NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+		testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+		nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(webhookStore, logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, featureFlagService,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{Billing: config.BillingConfig{UsageAdjustmentTolerancePercent: 10}}, logger.GetLogger(),
+	)
+
+	now := time.Now().UTC()
+	calls(3, start.AddDate(0, 0, 3), now.Add(-time.Hour))
+
+	created, err := svc.CreateSubscriptionInvoice(ctx, "sub_123", dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+	require.True(t, decimal.NewFromInt(3).Equal(created.Total), "total %s", created.Total)
+
+	getInvoice := func() *invoice.Invoice {
+		inv, err := invoiceStore.Get(ctx, created.ID)
+		require.NoError(t, err)
+		return inv
+	}
+
+	// Nothing arrived since the draft was calculated
+	require.NoError(t, svc.ReconcileLateEvents(ctx, now.Add(time.Minute)))
+	assert.True(t, decimal.NewFromInt(3).Equal(getInvoice().Total))
+
+	// Late events of the period are added to the draft, those of the next period are not
+	calls(2, start.AddDate(0, 0, 10), now.Add(2*time.Minute))
+	calls(4, start.AddDate(0, 1, 2), now.Add(2*time.Minute))
+	require.NoError(t, svc.ReconcileLateEvents(ctx, now.Add(3*time.Minute)))
+
+	draft := getInvoice()
+	assert.True(t, decimal.NewFromInt(5).Equal(draft.Total), "total %s", draft.Total)
+	require.Len(t, draft.LineItems, 1)
+	assert.True(t, decimal.NewFromInt(5).Equal(draft.LineItems[0].Quantity))
+
+	// A manual edit of the usage line item is kept on top of the new usage
+	edited := decimal.RequireFromString("5.4")
+	_, err = svc.UpdateLineItem(ctx, created.ID, draft.LineItems[0].ID, dto.UpdateInvoiceLineItemRequest{
+		Amount: &edited,
+	})
+	require.NoError(t, err)
+	calls(1, start.AddDate(0, 0, 11), now.Add(4*time.Minute))
+	require.NoError(t, svc.ReconcileLateEvents(ctx, now.Add(5*time.Minute)))
+	assert.True(t, decimal.RequireFromString("6.4").Equal(getInvoice().Total), "total %s", getInvoice().Total)
+
+	hooks, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+
+	// Late events of a finalized invoice are reported once
+	_, err = svc.FinalizeInvoice(ctx, created.ID)
+	require.NoError(t, err)
+	calls(2, start.AddDate(0, 0, 12), now.Add(6*time.Minute))
+	require.NoError(t, svc.ReconcileLateEvents(ctx, now.Add(7*time.Minute)))
+	require.NoError(t, svc.ReconcileLateEvents(ctx, now.Add(8*time.Minute)))
+
+	finalized := getInvoice()
+	assert.True(t, decimal.RequireFromString("6.4").Equal(finalized.Total), "total %s", finalized.Total)
+
+	hooks, err = webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, types.WebhookEventInvoiceLateUsage, hooks[0].EventType)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvoiceLineItemNotFound is returned when the line item is not one of the invoice
+	ErrInvoiceLineItemNotFound = errors.New("invoice line item not found")
+
+	// ErrInvoiceNotEditable is returned when the line items of an invoice that is
+	// no longer a draft are edited
+	ErrInvoiceNotEditable = errors.New("only draft or held invoices can be edited")
+
+	// ErrUsageAdjustmentOutOfTolerance is returned when the amount of a usage line
+	// item is edited further from its metered amount than the billing tolerance
+	ErrUsageAdjustmentOutOfTolerance = errors.New("usage line item adjustment exceeds the tolerance")
+)
+
+// AddLineItem adds a manual line item, such as a one-off charge or a discount, to
+// a draft or held invoice and recomputes its totals
+func (s *invoiceService) AddLineItem(ctx context.Context, id string, req dto.AddInvoiceLineItemRequest) (*dto.InvoiceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	var item *invoice.InvoiceLineItem
+	inv, err := s.editDraftInvoice(ctx, id, func(ctx context.Context, inv *invoice.Invoice) error {
+		quantity := decimal.NewFromInt(1)
+		if req.Quantity != nil {
+			quantity = *req.Quantity
+		}
+
+		item = s.newLineItem(ctx, inv, lineItemParams{
+			DisplayName: req.DisplayName,
+			Amount:      req.Amount,
+			Quantity:    quantity,
+		})
+
+		inv.LineItems = append(inv.LineItems, item)
+		if err := s.invoiceRepo.CreateLineItem(ctx, item); err != nil {
+			return fmt.Errorf("failed to add invoice line item: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoiceLineItem, item.ID, types.AuditActionCreate, nil, item)
+
+	return dto.NewInvoiceResponse(inv), nil
+}
+
+// UpdateLineItem edits the display name, amount or quantity of a line item of a
+// draft or held invoice. Usage line items keep their metered quantity and their
+// amount can only move within the configured tolerance of the metered amount
+func (s *invoiceService) UpdateLineItem(ctx context.Context, id, lineItemID string, req dto.UpdateInvoiceLineItemRequest) (*dto.InvoiceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	var before invoice.InvoiceLineItem
+	var item *invoice.InvoiceLineItem
+	inv, err := s.editDraftInvoice(ctx, id, func(ctx context.Context, inv *invoice.Invoice) error {
+		i := findInvoiceLineItem(inv, lineItemID)
+		if i < 0 {
+			return ErrInvoiceLineItemNotFound
+		}
+
+		if inv.LineItems[i].TaxSource().IsQuoted() {
+			return ErrTaxLineItemNotEditable
+		}
+
+		before = *inv.LineItems[i]
+		before.Metadata = maps.Clone(before.Metadata)
+
+		updated := before
+		updated.Metadata = maps.Clone(before.Metadata)
+		if req.DisplayName != nil {
+			updated.DisplayName = *req.DisplayName
+		}
+		if req.Quantity != nil {
+			if before.MeterID != "" && !req.Quantity.Equal(before.Quantity) {
+				return fmt.Errorf("invalid request: the quantity of a usage line item is metered and can not be edited")
+			}
+			updated.Quantity = *req.Quantity
+		}
+		if req.Amount != nil && !req.Amount.Equal(before.Amount) {
+			if before.MeterID != "" {
+				if err := s.checkUsageAdjustment(&before, *req.Amount); err != nil {
+					return err
+				}
+
+				// The metered amount is kept from the first edit so that successive
+				// edits can not drift past the tolerance
+				if updated.Metadata == nil {
+					updated.Metadata = types.Metadata{}
+				}
+				if _, ok := updated.Metadata[invoice.MetadataMeteredAmount]; !ok {
+					updated.Metadata[invoice.MetadataMeteredAmount] = before.Amount.String()
+				}
+			}
+			updated.Amount = *req.Amount
+		}
+		updated.UpdatedAt = s.clock.Now()
+		updated.UpdatedBy = types.GetUserID(ctx)
+
+		item = &updated
+		inv.LineItems[i] = item
+		if err := s.invoiceRepo.UpdateLineItem(ctx, item); err != nil {
+			return fmt.Errorf("failed to update invoice line item: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoiceLineItem, item.ID, types.AuditActionUpdate, &before, item)
+
+	return dto.NewInvoiceResponse(inv), nil
+}
+
+// RemoveLineItem removes a line item from a draft or held invoice. A usage line
+// item can only be removed when the tolerance allows its amount to go to zero
+func (s *invoiceService) RemoveLineItem(ctx context.Context, id, lineItemID string) (*dto.InvoiceResponse, error) {
+	var item *invoice.InvoiceLineItem
+	inv, err := s.editDraftInvoice(ctx, id, func(ctx context.Context, inv *invoice.Invoice) error {
+		i := findInvoiceLineItem(inv, lineItemID)
+		if i < 0 {
+			return ErrInvoiceLineItemNotFound
+		}
+
+		item = inv.LineItems[i]
+		if item.TaxSource().IsQuoted() {
+			return ErrTaxLineItemNotEditable
+		}
+		if item.MeterID != "" {
+			if err := s.checkUsageAdjustment(item, decimal.Zero); err != nil {
+				return err
+			}
+		}
+
+		inv.LineItems = append(inv.LineItems[:i:i], inv.LineItems[i+1:]...)
+		item.UpdatedAt = s.clock.Now()
+		item.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.DeleteLineItem(ctx, item); err != nil {
+			return fmt.Errorf("failed to remove invoice line item: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoiceLineItem, item.ID, types.AuditActionDelete, item, nil)
+
+	return dto.NewInvoiceResponse(inv), nil
+}
+
+// editDraftInvoice applies an edit of the line items of a draft or held invoice
+// and saves its taxes and recomputed totals in the same transaction. The invoice version
+// is bumped so that concurrent edits conflict
+func (s *invoiceService) editDraftInvoice(ctx context.Context, id string, edit func(ctx context.Context, inv *invoice.Invoice) error) (*invoice.Invoice, error) {
+	var inv *invoice.Invoice
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		inv, err = s.invoiceRepo.Get(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if err := checkIfMatch(ctx, "invoice", inv.ID, inv.Version); err != nil {
+			return err
+		}
+
+		if inv.InvoiceStatus != types.InvoiceStatusDraft && inv.InvoiceStatus != types.InvoiceStatusHeld {
+			return ErrInvoiceNotEditable
+		}
+
+		if err := edit(ctx, inv); err != nil {
+			return err
+		}
+
+		// The taxes are quoted for the charges as edited
+		if err := s.requoteTaxes(ctx, inv); err != nil {
+			return err
+		}
+
+		inv.RecalculateTotals()
+		inv.UpdatedAt = s.clock.Now()
+		inv.UpdatedBy = types.GetUserID(ctx)
+
+		if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// checkUsageAdjustment checks that amount is within the tolerance of the metered
+// amount of a usage line item
+func (s *invoiceService) checkUsageAdjustment(item *invoice.InvoiceLineItem, amount decimal.Decimal) error {
+	metered := item.Amount
+	if value, ok := item.Metadata[invoice.MetadataMeteredAmount]; ok {
+		parsed, err := decimal.NewFromString(value)
+		if err != nil {
+			return fmt.Errorf("invalid metered amount of line item %s: %w", item.ID, err)
+		}
+		metered = parsed
+	}
+
+	tolerance := metered.Abs().
+		Mul(decimal.NewFromFloat(s.cfg.UsageAdjustmentTolerancePercent)).
+		Div(decimal.NewFromInt(100))
+	if amount.Sub(metered).Abs().GreaterThan(tolerance) {
+		return fmt.Errorf("%w: %s is more than %v%% away from the metered amount of %s",
+			ErrUsageAdjustmentOutOfTolerance, amount.String(), s.cfg.UsageAdjustmentTolerancePercent, metered.String())
+	}
+	return nil
+}
+
+func findInvoiceLineItem(inv *invoice.Invoice, lineItemID string) int {
+	for i, item := range inv.LineItems {
+		if item.ID == lineItemID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/billingengine"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// CreateOneOffInvoice raises a draft invoice for a customer outside of any
+// subscription. The charges are priced first, then discounted and taxed, and the
+// invoice goes through the same finalization and payment lifecycle as the
+// subscription invoices
+func (s *invoiceService) CreateOneOffInvoice(ctx context.Context, req dto.CreateOneOffInvoiceRequest) (*dto.InvoiceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if _, err := s.customerRepo.Get(ctx, req.CustomerID); err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	inv := &invoice.Invoice{
+		ID:            types.GenerateUUID(),
+		CustomerID:    req.CustomerID,
+		InvoiceType:   types.InvoiceTypeOneOff,
+		InvoiceStatus: types.InvoiceStatusDraft,
+		Currency:      strings.ToLower(req.Currency),
+		Description:   req.Description,
+		Metadata:      req.Metadata,
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+	precision := types.GetCurrencyPrecision(inv.Currency)
+	hundred := decimal.NewFromInt(100)
+
+	subtotal := decimal.Zero
+	for i, item := range req.LineItems {
+		quantity := decimal.NewFromInt(1)
+		if item.Quantity != nil {
+			quantity = *item.Quantity
+		}
+
+		params := lineItemParams{
+			PriceID:     item.PriceID,
+			DisplayName: item.DisplayName,
+			Quantity:    quantity,
+		}
+
+		if item.PriceID != "" {
+			p, err := s.priceRepo.Get(ctx, item.PriceID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get price of line_items[%d]: %w", i, err)
+			}
+			if p.Type == types.PRICE_TYPE_USAGE {
+				return nil, fmt.Errorf("invalid request: line_items[%d]: usage price %s can not be billed one-off", i, p.ID)
+			}
+			if p.Currency != inv.Currency {
+				return nil, fmt.Errorf("invalid request: line_items[%d]: price currency %s does not match the invoice currency %s", i, p.Currency, inv.Currency)
+			}
+
+			params.Amount = billingengine.PriceCost(p, quantity)
+			if params.DisplayName == "" {
+				params.DisplayName = p.Description
+			}
+		} else {
+			params.Amount = item.UnitAmount.Mul(quantity).Round(precision)
+		}
+
+		subtotal = subtotal.Add(params.Amount)
+		inv.LineItems = append(inv.LineItems, s.newLineItem(ctx, inv, params))
+	}
+
+	discounted := subtotal
+	for _, discount := range req.Discounts {
+		var amount decimal.Decimal
+		if discount.AmountOff != nil {
+			amount = *discount.AmountOff
+		} else {
+			amount = subtotal.Mul(*discount.PercentOff).Div(hundred).Round(precision)
+		}
+
+		discounted = discounted.Sub(amount)
+		inv.LineItems = append(inv.LineItems, s.newLineItem(ctx, inv, lineItemParams{
+			DisplayName: discount.DisplayName,
+			Amount:      amount.Neg(),
+			Quantity:    decimal.NewFromInt(1),
+		}))
+	}
+	if discounted.IsNegative() {
+		return nil, fmt.Errorf("invalid request: discounts of %s exceed the subtotal of %s", subtotal.Sub(discounted).String(), subtotal.String())
+	}
+
+	for _, tax := range req.Taxes {
+		inv.LineItems = append(inv.LineItems, s.newLineItem(ctx, inv, lineItemParams{
+			DisplayName: tax.DisplayName,
+			Amount:      discounted.Mul(tax.RatePercent).Div(hundred).Round(precision),
+			Quantity:    decimal.NewFromInt(1),
+			Metadata:    types.Metadata{invoice.MetadataTaxSource: string(types.TaxSourceManual)},
+		}))
+	}
+
+	inv.RecalculateTotals()
+
+	if err := s.taxInvoice(ctx, inv); err != nil {
+		return nil, err
+	}
+	if err := s.issueInvoice(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	if req.Finalize {
+		finalized, err := s.FinalizeInvoice(ctx, inv.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invoice %s was created in draft but could not be finalized: %w", inv.ID, err)
+		}
+		return finalized, nil
+	}
+
+	return dto.NewInvoiceResponse(inv), nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// RecordPayment adds a payment to the allocation ledger of a finalized invoice and
+// recomputes its amount remaining and payment status. A payment can not exceed the
+// amount remaining
+func (s *invoiceService) RecordPayment(ctx context.Context, id string, req dto.RecordInvoicePaymentRequest) (*dto.InvoicePaymentResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	var payment *invoice.Payment
+	var inv *invoice.Invoice
+	var before invoice.Invoice
+
+	// The invoice is locked so that concurrent payments can not pay more than
+	// the amount remaining
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		inv, err = s.invoiceRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if err := checkIfMatch(ctx, "invoice", inv.ID, inv.Version); err != nil {
+			return err
+		}
+
+		if inv.InvoiceType == types.InvoiceTypeCredit {
+			return fmt.Errorf("invalid request: payments can not be recorded against a credit invoice")
+		}
+		if inv.InvoiceStatus != types.InvoiceStatusFinalized {
+			return fmt.Errorf("invoice must be finalized before payments are recorded")
+		}
+
+		currency := strings.ToLower(req.Currency)
+		if currency == "" {
+			currency = inv.Currency
+		}
+		if currency != inv.Currency {
+			return fmt.Errorf("invalid request: currency must match the invoice currency %s", inv.Currency)
+		}
+
+		if req.Amount.GreaterThan(inv.AmountRemaining) {
+			return fmt.Errorf("invalid request: amount exceeds the amount remaining of %s", inv.AmountRemaining.String())
+		}
+
+		now := s.clock.Now()
+		paidAt := now
+		if req.PaidAt != nil {
+			paidAt = req.PaidAt.UTC()
+		}
+
+		payment = &invoice.Payment{
+			ID:                types.GenerateUUID(),
+			InvoiceID:         inv.ID,
+			CustomerID:        inv.CustomerID,
+			Amount:            req.Amount,
+			Currency:          currency,
+			PaymentMethodType: req.PaymentMethodType,
+			Reference:         req.Reference,
+			PaidAt:            paidAt,
+			ConnectionID:      req.ConnectionID,
+			GatewayPaymentID:  req.GatewayPaymentID,
+			PaymentStatus:     types.PaymentStatusSucceeded,
+			BaseModel:         types.GetDefaultBaseModel(ctx),
+		}
+
+		if err := s.invoiceRepo.CreatePayment(ctx, payment); err != nil {
+			return fmt.Errorf("failed to record payment: %w", err)
+		}
+
+		before = *inv
+		inv.AmountPaid = inv.AmountPaid.Add(req.Amount)
+		inv.RecalculateAmountRemaining()
+		inv.UpdatedAt = now
+		inv.UpdatedBy = types.GetUserID(ctx)
+
+		if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoicePayment, payment.ID, types.AuditActionCreate, nil, payment)
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionUpdate, &before, inv)
+
+	s.logger.Debugw("recorded invoice payment",
+		"invoice_id", payment.InvoiceID,
+		"payment_id", payment.ID,
+		"amount", payment.Amount,
+	)
+
+	return &dto.InvoicePaymentResponse{Payment: payment}, nil
+}
+
+func (s *invoiceService) ListPayments(ctx context.Context, id string) (*dto.ListInvoicePaymentsResponse, error) {
+	inv, err := s.invoiceRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	payments, err := s.invoiceRepo.ListPayments(ctx, inv.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	response := &dto.ListInvoicePaymentsResponse{
+		Payments:         make([]dto.InvoicePaymentResponse, len(payments)),
+		AmountDue:        inv.AmountDue,
+		AmountPaid:       inv.AmountPaid,
+		AmountProcessing: inv.AmountProcessing,
+		AmountRemaining:  inv.AmountRemaining,
+		PaymentStatus:    inv.PaymentStatus,
+	}
+
+	for i, payment := range payments {
+		response.Payments[i] = dto.InvoicePaymentResponse{Payment: payment}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+func (s *invoiceService) ProcessPeriodEnds(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+
+	// Periods are renewed once their usage is locked, the events arriving within
+	// the delay are still billed with the period they belong to
+	lockedBefore := now.Add(-s.usageLockDelay())
+
+	subs, err := s.subscriptionRepo.ListPeriodsEndedBefore(ctx, lockedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	forEachJobItem(ctx, subs, func(sub *subscription.Subscription) {
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, sub.TenantID)
+		tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+		// Tenants invoicing their periods through the API are not renewed
+		if !featureEnabled(tenantCtx, s.featureFlagService, types.FeatureFlagPeriodRenewal) {
+			return
+		}
+
+		// One failing subscription must not hold back the others
+		if err := s.renewPeriod(tenantCtx, sub, lockedBefore, now); err != nil {
+			s.logger.Errorw("failed to renew subscription period",
+				"tenant_id", sub.TenantID,
+				"subscription_id", sub.ID,
+				"error", err,
+			)
+		}
+	})
+
+	return nil
+}
+
+// renewPeriod moves the subscription to the period containing lockedBefore and
+// invoices every period it skipped, so a job that did not run for a while
+// catches up
+func (s *invoiceService) renewPeriod(ctx context.Context, sub *subscription.Subscription, lockedBefore, now time.Time) error {
+	renewedFrom := sub.CurrentPeriodStart
+	if err := advanceToCurrentPeriod(sub, lockedBefore); err != nil {
+		return err
+	}
+
+	invoices, err := s.buildPeriodInvoices(ctx, sub, renewedFrom)
+	if err != nil {
+		return err
+	}
+	for _, inv := range invoices {
+		if err := s.taxInvoice(ctx, inv); err != nil {
+			return err
+		}
+	}
+
+	return s.db.WithTx(ctx, func(ctx context.Context) error {
+		sub.UpdatedAt = now
+		sub.UpdatedBy = types.GetUserID(ctx)
+
+		// The version check keeps concurrent runs from renewing a period twice
+		if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+
+		for _, inv := range invoices {
+			if err := s.issueInvoice(ctx, inv); err != nil {
+				return err
+			}
+		}
+
+		s.logger.Infow("renewed subscription period",
+			"subscription_id", sub.ID,
+			"period_start", sub.CurrentPeriodStart,
+			"period_end", sub.CurrentPeriodEnd,
+			"invoices", len(invoices),
+		)
+		return nil
+	})
+}
+
+func (s *invoiceService) GetUsageLock(ctx context.Context, subscriptionID string, now time.Time) (*dto.UsageLockResponse, error) {
+	sub, err := s.subscriptionRepo.Get(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	resp := &dto.UsageLockResponse{
+		SubscriptionID: sub.ID,
+		PeriodStart:    sub.CurrentPeriodStart,
+		PeriodEnd:      sub.CurrentPeriodEnd,
+		LocksAt:        sub.CurrentPeriodEnd.Add(s.usageLockDelay()),
+	}
+
+	switch {
+	case now.Before(resp.PeriodEnd):
+		resp.Status = types.UsageLockStatusOpen
+	case now.Before(resp.LocksAt):
+		resp.Status = types.UsageLockStatusGrace
+	default:
+		resp.Status = types.UsageLockStatusLocked
+	}
+	return resp, nil
+}
+
+// usageLockDelay is how long after its end the usage of a period is locked
+func (s *invoiceService) usageLockDelay() time.Duration {
+	return time.Duration(s.cfg.UsageLockDelayHours) * time.Hour
+}
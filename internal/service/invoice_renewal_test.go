@@ -0,0 +1,255 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceService_UsageLock(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_123",
+		ExternalID: "ext_cust_123",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	meterStore := testutil.NewInMemoryMeterStore()
+	require.NoError(t, meterStore.CreateMeter(ctx, &meter.Meter{
+		ID:          "meter_api_calls",
+		Name:        "API Calls",
+		EventName:   "api_call",
+		Aggregation: meter.Aggregation{Type: types.AggregationCount},
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}))
+
+	planStore := testutil.NewInMemoryPlanStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Usage Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	priceStore := testutil.NewInMemoryPriceStore()
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_api_calls",
+		PlanID:             "plan_123",
+		MeterID:            "meter_api_calls",
+		Type:               types.PRICE_TYPE_USAGE,
+		Amount:             decimal.NewFromInt(1),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := start.AddDate(0, 1, 0)
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   periodEnd,
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	eventStore := testutil.NewInMemoryEventStore()
+	calls := func(count int, at time.Time) {
+		for i := 0; i < count; i++ {
+			require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+				ID:                 types.GenerateUUID(),
+				TenantID:           types.GetTenantID(ctx),
+				EventName:          "api_call",
+				ExternalCustomerID: "ext_cust_123",
+				Timestamp:          at,
+				Properties:         map[string]interface{}{},
+			}))
+		}
+	}
+
+	featureFlagService := NewFeatureFlagService(testutil.NewInMemoryFeatureFlagStore(), logger.GetLogger())
+	_, err := featureFlagService.SetOverride(ctx, types.GetTenantID(ctx), types.FeatureFlagPeriodRenewal, &dto.SetFeatureFlagRequest{Enabled: true})
+	require.NoError(t, err)
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	svc := NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+		testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+		nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, featureFlagService,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{Billing: config.BillingConfig{UsageLockDelayHours: 6}}, logger.GetLogger(),
+	)
+
+	listInvoices := func() []dto.InvoiceResponse {
+		resp, err := svc.ListInvoices(ctx, &types.InvoiceFilter{})
+		require.NoError(t, err)
+		return resp.Invoices
+	}
+
+	lock, err := svc.GetUsageLock(ctx, "sub_123", start.AddDate(0, 0, 15))
+	require.NoError(t, err)
+	assert.Equal(t, types.UsageLockStatusOpen, lock.Status)
+	assert.Equal(t, periodEnd.Add(6*time.Hour), lock.LocksAt)
+
+	calls(3, start.AddDate(0, 0, 20))
+
+	// The period waits for late events until it locks
+	inGrace := periodEnd.Add(3 * time.Hour)
+	require.NoError(t, svc.ProcessPeriodEnds(ctx, inGrace))
+	assert.Empty(t, listInvoices())
+
+	lock, err = svc.GetUsageLock(ctx, "sub_123", inGrace)
+	require.NoError(t, err)
+	assert.Equal(t, types.UsageLockStatusGrace, lock.Status)
+	assert.Equal(t, start, lock.PeriodStart)
+
+	calls(2, periodEnd.Add(-time.Minute))
+
+	locked := periodEnd.Add(7 * time.Hour)
+	lock, err = svc.GetUsageLock(ctx, "sub_123", locked)
+	require.NoError(t, err)
+	assert.Equal(t, types.UsageLockStatusLocked, lock.Status)
+
+	require.NoError(t, svc.ProcessPeriodEnds(ctx, locked))
+
+	invoices := listInvoices()
+	require.Len(t, invoices, 1)
+	assert.True(t, decimal.NewFromInt(5).Equal(invoices[0].Total), "total %s", invoices[0].Total)
+	assert.Equal(t, periodEnd, *invoices[0].PeriodEnd)
+
+	lock, err = svc.GetUsageLock(ctx, "sub_123", locked)
+	require.NoError(t, err)
+	assert.Equal(t, types.UsageLockStatusOpen, lock.Status)
+	assert.Equal(t, periodEnd, lock.PeriodStart)
+}
+
+func TestInvoiceService_RenewalProrations(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:        "cust_123",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	planStore := testutil.NewInMemoryPlanStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Seats Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	// Seats added in January and in February, before the job caught up, and
+	// in December, after its period was invoiced
+	for id, periodStart := range map[string]time.Time{
+		"proration_dec": start.AddDate(0, -1, 0),
+		"proration_jan": start,
+		"proration_feb": start.AddDate(0, 1, 0),
+	} {
+		amount := decimal.NewFromInt(10)
+		if id == "proration_dec" {
+			amount = decimal.NewFromInt(5)
+		}
+		require.NoError(t, subscriptionStore.CreateProration(ctx, &subscription.Proration{
+			ID:             id,
+			SubscriptionID: "sub_123",
+			DisplayName:    "Seats",
+			Amount:         amount,
+			Currency:       "usd",
+			PeriodStart:    periodStart,
+			ChangedAt:      periodStart.AddDate(0, 0, 15),
+			BaseModel:      types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	featureFlagService := NewFeatureFlagService(testutil.NewInMemoryFeatureFlagStore(), logger.GetLogger())
+	_, err := featureFlagService.SetOverride(ctx, types.GetTenantID(ctx), types.FeatureFlagPeriodRenewal, &dto.SetFeatureFlagRequest{Enabled: true})
+	require.NoError(t, err)
+
+	eventStore := testutil.NewInMemoryEventStore()
+	meterStore := testutil.NewInMemoryMeterStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	svc := NewInvoiceService(
+		testutil.NewInMemoryInvoiceStore(), testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+		testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+		nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, featureFlagService,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	// The job catches up on January, February and March at once
+	require.NoError(t, svc.ProcessPeriodEnds(ctx, start.AddDate(0, 3, 1)))
+
+	resp, err := svc.ListInvoices(ctx, &types.InvoiceFilter{})
+	require.NoError(t, err)
+	require.Len(t, resp.Invoices, 3)
+
+	totals := map[time.Time]decimal.Decimal{}
+	for _, inv := range resp.Invoices {
+		totals[*inv.PeriodStart] = inv.Total
+	}
+	// The late December proration is billed once, with the first invoice
+	assert.True(t, decimal.NewFromInt(15).Equal(totals[start]), "january %s", totals[start])
+	assert.True(t, decimal.NewFromInt(10).Equal(totals[start.AddDate(0, 1, 0)]), "february %s", totals[start.AddDate(0, 1, 0)])
+	assert.True(t, totals[start.AddDate(0, 2, 0)].IsZero(), "march %s", totals[start.AddDate(0, 2, 0)])
+
+	pending, err := subscriptionStore.ListPendingProrations(ctx, "sub_123")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
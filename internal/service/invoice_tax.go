@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrTaxLineItemNotEditable is returned when a tax line item quoted through
+	// the tax connection is edited, it is quoted again with every edit instead
+	ErrTaxLineItemNotEditable = errors.New("taxes quoted by the tax connection can not be edited")
+
+	// ErrInvoiceNotVoidable is returned when an invoice that is not finalized, or
+	// that was paid or credited, is voided
+	ErrInvoiceNotVoidable = errors.New("only finalized invoices that were not paid or credited can be voided")
+)
+
+// applyTaxes adds the taxes quoted for the charges of a draft invoice through
+// the tax connection as line items and returns them. The caller saves them
+func (s *invoiceService) applyTaxes(ctx context.Context, inv *invoice.Invoice) ([]*invoice.InvoiceLineItem, error) {
+	if s.taxService == nil {
+		return nil, nil
+	}
+
+	taxes, err := s.taxService.QuoteInvoice(ctx, inv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tax invoice: %w", err)
+	}
+
+	items := make([]*invoice.InvoiceLineItem, 0, len(taxes))
+	for _, t := range taxes {
+		item := s.newLineItem(ctx, inv, lineItemParams{
+			DisplayName: t.DisplayName,
+			Amount:      t.Amount,
+			Quantity:    decimal.NewFromInt(1),
+			Metadata: types.Metadata{
+				invoice.MetadataTaxSource:       string(t.Source),
+				invoice.MetadataTaxedLineItemID: t.LineItemID,
+				invoice.MetadataTaxRatePercent:  t.RatePercent.String(),
+			},
+		})
+		items = append(items, item)
+	}
+
+	inv.LineItems = append(inv.LineItems, items...)
+	inv.RecalculateTotals()
+	return items, nil
+}
+
+// requoteTaxes replaces the quoted tax line items of a draft invoice that was
+// edited with the taxes of its charges as edited
+func (s *invoiceService) requoteTaxes(ctx context.Context, inv *invoice.Invoice) error {
+	if s.taxService == nil {
+		return nil
+	}
+
+	charges := make([]*invoice.InvoiceLineItem, 0, len(inv.LineItems))
+	var quoted []*invoice.InvoiceLineItem
+	for _, item := range inv.LineItems {
+		if item.TaxSource().IsQuoted() {
+			quoted = append(quoted, item)
+		} else {
+			charges = append(charges, item)
+		}
+	}
+
+	inv.LineItems = charges
+	for _, item := range quoted {
+		if err := s.invoiceRepo.DeleteLineItem(ctx, item); err != nil {
+			return fmt.Errorf("failed to remove tax line item: %w", err)
+		}
+	}
+
+	items, err := s.applyTaxes(ctx, inv)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := s.invoiceRepo.CreateLineItem(ctx, item); err != nil {
+			return fmt.Errorf("failed to add tax line item: %w", err)
+		}
+	}
+	return nil
+}
+
+// commitTaxes queues the commit of the taxes of a finalized invoice at the tax
+// provider. A failure to queue is logged and does not fail the finalization
+func (s *invoiceService) commitTaxes(ctx context.Context, inv *invoice.Invoice) {
+	if s.taxService == nil {
+		return
+	}
+	if err := s.taxService.CommitInvoice(ctx, inv); err != nil {
+		s.logger.Errorw("failed to queue tax commit", "invoice_id", inv.ID, "error", err)
+	}
+}
+
+// VoidInvoice voids a finalized invoice nothing was paid, collected or
+// credited on, and voids its tax transaction at the tax provider
+func (s *invoiceService) VoidInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error) {
+	var inv *invoice.Invoice
+	var before invoice.Invoice
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		inv, err = s.invoiceRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if err := checkIfMatch(ctx, "invoice", inv.ID, inv.Version); err != nil {
+			return err
+		}
+
+		if inv.InvoiceStatus != types.InvoiceStatusFinalized || inv.InvoiceType == types.InvoiceTypeCredit {
+			return ErrInvoiceNotVoidable
+		}
+		if !inv.AmountPaid.IsZero() || !inv.AmountProcessing.IsZero() || !inv.AmountDue.Equal(decimal.Max(inv.Total, decimal.Zero)) {
+			return fmt.Errorf("%w: invoice has payments or credits", ErrInvoiceNotVoidable)
+		}
+
+		before = *inv
+		inv.InvoiceStatus = types.InvoiceStatusVoided
+		inv.UpdatedAt = s.clock.Now()
+		inv.UpdatedBy = types.GetUserID(ctx)
+
+		if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+			return fmt.Errorf("failed to void invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionUpdate, &before, inv)
+
+	// The invoice is loaded with its line items, which decide whether it was
+	// taxed through the tax connection
+	voided, err := s.invoiceRepo.Get(ctx, inv.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	if s.taxService != nil {
+		if err := s.taxService.VoidInvoice(ctx, voided); err != nil {
+			s.logger.Errorw("failed to queue tax void", "invoice_id", voided.ID, "error", err)
+		}
+	}
+
+	return dto.NewInvoiceResponse(voided), nil
+}
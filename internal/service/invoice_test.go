@@ -0,0 +1,1019 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/billingengine"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupInvoiceServiceTest(t *testing.T, behavior types.NegativeInvoiceBehavior) (InvoiceService, *testutil.InMemoryInvoiceStore, *subscription.Subscription) {
+	svc, invoiceStore, _, sub := setupInvoiceServiceWithSequences(t, behavior, testutil.NewInMemoryWalletStore())
+	return svc, invoiceStore, sub
+}
+
+func setupInvoiceServiceWithSequences(t *testing.T, behavior types.NegativeInvoiceBehavior, walletStore *testutil.InMemoryWalletStore) (InvoiceService, *testutil.InMemoryInvoiceStore, *testutil.InMemorySequenceStore, *subscription.Subscription) {
+	ctx := testutil.SetupContext()
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	sequenceStore := testutil.NewInMemorySequenceStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_123",
+		ExternalID: "ext_cust_123",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Test Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_fixed",
+		PlanID:             "plan_123",
+		Type:               types.PRICE_TYPE_FIXED,
+		Amount:             decimal.NewFromInt(20),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		Description:        "Platform fee",
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sub := &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		StartDate:          start,
+		CurrentPeriodStart: start.AddDate(0, 1, 0),
+		CurrentPeriodEnd:   start.AddDate(0, 2, 0),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}
+	require.NoError(t, subscriptionStore.Create(ctx, sub))
+
+	cfg := &config.Configuration{Billing: config.BillingConfig{NegativeInvoiceBehavior: behavior}}
+	svc := NewInvoiceService(
+		invoiceStore, sequenceStore, subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, walletStore, walletStore, testutil.NewInMemoryRateCardStore(), testutil.NewInMemoryPriceBookStore(),
+		nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, nil,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(), testutil.NewInMemoryMeterStore(), customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		cfg, logger.GetLogger(),
+	)
+
+	return svc, invoiceStore, sequenceStore, sub
+}
+
+func TestInvoiceService_CreateSubscriptionInvoice(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+
+	resp, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, types.InvoiceTypeSubscription, resp.InvoiceType)
+	assert.Equal(t, types.InvoiceStatusDraft, resp.InvoiceStatus)
+	require.Len(t, resp.LineItems, 1)
+	assert.True(t, decimal.NewFromInt(20).Equal(resp.Total))
+	assert.True(t, decimal.NewFromInt(20).Equal(resp.AmountDue))
+	assert.Empty(t, resp.OriginalInvoiceID)
+}
+
+func TestInvoiceService_CreateSubscriptionInvoice_CommitmentTrueUp(t *testing.T) {
+	tests := []struct {
+		name        string
+		commitment  int64
+		adjustments []dto.InvoiceAdjustmentRequest
+		wantTrueUp  decimal.Decimal
+		wantTotal   decimal.Decimal
+	}{
+		{
+			name:       "charges below commitment",
+			commitment: 50,
+			wantTrueUp: decimal.NewFromInt(30),
+			wantTotal:  decimal.NewFromInt(50),
+		},
+		{
+			name:       "charges above commitment",
+			commitment: 15,
+			wantTotal:  decimal.NewFromInt(20),
+		},
+		{
+			name:       "adjustments do not count towards commitment",
+			commitment: 50,
+			adjustments: []dto.InvoiceAdjustmentRequest{
+				{DisplayName: "Proration credit", Amount: decimal.NewFromInt(-10)},
+			},
+			wantTrueUp: decimal.NewFromInt(30),
+			wantTotal:  decimal.NewFromInt(40),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.SetupContext()
+			svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+			sub.CommitmentAmount = decimal.NewFromInt(tt.commitment)
+
+			resp, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{
+				Adjustments: tt.adjustments,
+			})
+			require.NoError(t, err)
+			assert.True(t, tt.wantTotal.Equal(resp.Total), "total %s", resp.Total)
+
+			trueUp := decimal.Zero
+			for _, item := range resp.LineItems {
+				if item.DisplayName == billingengine.CommitmentTrueUpDisplayName {
+					trueUp = item.Amount
+				}
+			}
+			assert.True(t, tt.wantTrueUp.Equal(trueUp), "true-up %s", trueUp)
+		})
+	}
+}
+
+func TestInvoiceService_CreateSubscriptionInvoice_Trial(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+
+	// The current period is the trial
+	trialEnd := sub.CurrentPeriodEnd
+	sub.SubscriptionStatus = types.SubscriptionStatusTrialing
+	sub.TrialEnd = &trialEnd
+
+	resp, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.LineItems)
+	assert.True(t, resp.Total.IsZero())
+
+	// The first period after the trial is billed
+	resp, err = svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{
+		PeriodStart: trialEnd,
+		PeriodEnd:   trialEnd.AddDate(0, 1, 0),
+	})
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(20).Equal(resp.Total))
+}
+
+func TestInvoiceService_GetUpcomingInvoice(t *testing.T) {
+	ctx := testutil.SetupContext()
+	walletStore := testutil.NewInMemoryWalletStore()
+	svc, invoiceStore, _, sub := setupInvoiceServiceWithSequences(t, types.NegativeInvoiceBehaviorCreditInvoice, walletStore)
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newWallet := func(id, currency string, status types.WalletStatus, balance int64, age int) {
+		w := &wallet.Wallet{
+			ID:           id,
+			CustomerID:   sub.CustomerID,
+			Currency:     currency,
+			Balance:      decimal.NewFromInt(balance),
+			WalletStatus: status,
+			BaseModel:    types.GetDefaultBaseModel(ctx),
+		}
+		w.CreatedAt = created.AddDate(0, 0, age)
+		require.NoError(t, walletStore.CreateWallet(ctx, w))
+	}
+
+	newWallet("wallet_old", "usd", types.WalletStatusActive, 5, 0)
+	newWallet("wallet_frozen", "usd", types.WalletStatusFrozen, 100, 1)
+	newWallet("wallet_eur", "eur", types.WalletStatusActive, 100, 2)
+	newWallet("wallet_new", "usd", types.WalletStatusActive, 30, 3)
+
+	resp, err := svc.GetUpcomingInvoice(ctx, sub.ID, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, sub.CurrentPeriodEnd, resp.RenewalDate)
+	assert.True(t, decimal.NewFromInt(20).Equal(resp.AmountDue))
+	require.Len(t, resp.CreditApplications, 2)
+	assert.Equal(t, "wallet_old", resp.CreditApplications[0].WalletID)
+	assert.True(t, decimal.NewFromInt(5).Equal(resp.CreditApplications[0].Amount))
+	assert.True(t, decimal.Zero.Equal(resp.CreditApplications[0].BalanceAfter))
+	assert.Equal(t, "wallet_new", resp.CreditApplications[1].WalletID)
+	assert.True(t, decimal.NewFromInt(15).Equal(resp.CreditApplications[1].Amount))
+	assert.True(t, decimal.NewFromInt(15).Equal(resp.CreditApplications[1].BalanceAfter))
+	assert.True(t, decimal.NewFromInt(20).Equal(resp.CreditsApplied))
+	assert.True(t, resp.AmountOutOfPocket.IsZero())
+
+	// The preview neither creates an invoice nor touches the wallets
+	invoices, err := invoiceStore.List(ctx, &types.InvoiceFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, invoices)
+
+	w, err := walletStore.GetWalletByID(ctx, "wallet_new")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(30).Equal(w.Balance))
+}
+
+func TestInvoiceService_GetUpcomingInvoice_ExpiringCredits(t *testing.T) {
+	ctx := testutil.SetupContext()
+	walletStore := testutil.NewInMemoryWalletStore()
+	svc, _, _, sub := setupInvoiceServiceWithSequences(t, types.NegativeInvoiceBehaviorCreditInvoice, walletStore)
+
+	require.NoError(t, walletStore.CreateWallet(ctx, &wallet.Wallet{
+		ID:           "wallet_1",
+		CustomerID:   sub.CustomerID,
+		Currency:     "usd",
+		Balance:      decimal.NewFromInt(25),
+		WalletStatus: types.WalletStatusActive,
+		BaseModel:    types.GetDefaultBaseModel(ctx),
+	}))
+
+	newBucket := func(id string, remaining int64, expiresAt time.Time) {
+		require.NoError(t, walletStore.CreateCreditBucket(ctx, &wallet.CreditBucket{
+			ID:        id,
+			WalletID:  "wallet_1",
+			Amount:    decimal.NewFromInt(remaining),
+			Remaining: decimal.NewFromInt(remaining),
+			ExpiresAt: expiresAt,
+			BaseModel: types.GetDefaultBaseModel(ctx),
+		}))
+	}
+	// Expires before the renewal, so it cannot pay for the invoice
+	newBucket("bucket_expiring", 10, sub.CurrentPeriodEnd.AddDate(0, 0, -10))
+	newBucket("bucket_valid", 10, sub.CurrentPeriodEnd.AddDate(0, 1, 0))
+
+	resp, err := svc.GetUpcomingInvoice(ctx, sub.ID, nil)
+	require.NoError(t, err)
+
+	require.Len(t, resp.CreditApplications, 1)
+	assert.True(t, decimal.NewFromInt(15).Equal(resp.CreditApplications[0].BalanceBefore))
+	assert.True(t, decimal.NewFromInt(15).Equal(resp.CreditsApplied))
+	assert.True(t, decimal.NewFromInt(5).Equal(resp.AmountOutOfPocket))
+}
+
+func TestInvoiceService_GetUpcomingInvoice_NoWallets(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+
+	resp, err := svc.GetUpcomingInvoice(ctx, sub.ID, nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, resp.CreditApplications)
+	assert.True(t, resp.CreditsApplied.IsZero())
+	assert.True(t, decimal.NewFromInt(20).Equal(resp.AmountOutOfPocket))
+}
+
+func TestInvoiceService_GetUpcomingInvoice_ProjectedUsage(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_123",
+		ExternalID: "ext_cust_123",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	meterStore := testutil.NewInMemoryMeterStore()
+	require.NoError(t, meterStore.CreateMeter(ctx, &meter.Meter{
+		ID:          "meter_api_calls",
+		Name:        "API Calls",
+		EventName:   "api_call",
+		Aggregation: meter.Aggregation{Type: types.AggregationCount},
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}))
+
+	planStore := testutil.NewInMemoryPlanStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Usage Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	priceStore := testutil.NewInMemoryPriceStore()
+	for _, p := range []*price.Price{
+		{ID: "price_fixed", Type: types.PRICE_TYPE_FIXED, Amount: decimal.NewFromInt(20)},
+		{ID: "price_api_calls", Type: types.PRICE_TYPE_USAGE, MeterID: "meter_api_calls", Amount: decimal.NewFromInt(1)},
+	} {
+		p.PlanID = "plan_123"
+		p.Currency = "usd"
+		p.BillingPeriod = types.BILLING_PERIOD_MONTHLY
+		p.BillingPeriodCount = 1
+		p.BillingModel = types.BILLING_MODEL_FLAT_FEE
+		p.BillingCadence = types.BILLING_CADENCE_RECURRING
+		p.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, priceStore.Create(ctx, p))
+	}
+
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	eventStore := testutil.NewInMemoryEventStore()
+	for i := 0; i < 6; i++ {
+		require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+			ID:                 types.GenerateUUID(),
+			TenantID:           types.GetTenantID(ctx),
+			EventName:          "api_call",
+			ExternalCustomerID: "ext_cust_123",
+			Timestamp:          start.AddDate(0, 0, i),
+			Properties:         map[string]interface{}{},
+		}))
+	}
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	svc := NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+		testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+		nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, nil,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	// Half of the 30 days of April are elapsed, the usage to date is doubled
+	resp, err := svc.GetUpcomingInvoice(ctx, "sub_123", &UpcomingInvoiceProjection{At: start.AddDate(0, 0, 15)})
+	require.NoError(t, err)
+
+	require.NotNil(t, resp.UsageProjection)
+	assert.True(t, decimal.NewFromInt(2).Equal(resp.UsageProjection.Factor), "factor %s", resp.UsageProjection.Factor)
+	require.Len(t, resp.UsageProjection.Usage, 1)
+	assert.Equal(t, "price_api_calls", resp.UsageProjection.Usage[0].PriceID)
+	assert.True(t, decimal.NewFromInt(6).Equal(resp.UsageProjection.Usage[0].QuantityToDate))
+	assert.True(t, decimal.NewFromInt(12).Equal(resp.UsageProjection.Usage[0].ProjectedQuantity))
+	assert.True(t, decimal.NewFromInt(32).Equal(resp.Invoice.Total), "total %s", resp.Invoice.Total)
+	assert.Equal(t, start.AddDate(0, 1, 0), resp.RenewalDate)
+
+	// A period already over is not extrapolated
+	resp, err = svc.GetUpcomingInvoice(ctx, "sub_123", &UpcomingInvoiceProjection{At: start.AddDate(0, 2, 0)})
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(1).Equal(resp.UsageProjection.Factor))
+	assert.True(t, decimal.NewFromInt(26).Equal(resp.Invoice.Total), "total %s", resp.Invoice.Total)
+
+	// The preview does not create an invoice
+	invoices, err := invoiceStore.List(ctx, &types.InvoiceFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, invoices)
+}
+
+func TestInvoiceService_NegativeTotal(t *testing.T) {
+	tests := []struct {
+		name         string
+		behavior     types.NegativeInvoiceBehavior
+		expectedType types.InvoiceType
+	}{
+		{
+			name:         "credit_invoice",
+			behavior:     types.NegativeInvoiceBehaviorCreditInvoice,
+			expectedType: types.InvoiceTypeCredit,
+		},
+		{
+			name:         "default_behavior_is_credit_invoice",
+			behavior:     "",
+			expectedType: types.InvoiceTypeCredit,
+		},
+		{
+			name:         "negative_invoice",
+			behavior:     types.NegativeInvoiceBehaviorNegativeInvoice,
+			expectedType: types.InvoiceTypeSubscription,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.SetupContext()
+			svc, invoiceStore, sub := setupInvoiceServiceTest(t, tt.behavior)
+
+			// Previous period invoice that the credit is issued against
+			finalizedAt := sub.StartDate.AddDate(0, 1, 0)
+			previous := &invoice.Invoice{
+				ID:             "inv_previous",
+				CustomerID:     sub.CustomerID,
+				SubscriptionID: sub.ID,
+				InvoiceType:    types.InvoiceTypeSubscription,
+				InvoiceStatus:  types.InvoiceStatusFinalized,
+				Currency:       "usd",
+				Total:          decimal.NewFromInt(20),
+				AmountDue:      decimal.NewFromInt(20),
+				FinalizedAt:    &finalizedAt,
+				BaseModel:      types.GetDefaultBaseModel(ctx),
+			}
+			require.NoError(t, invoiceStore.Create(ctx, previous))
+
+			resp, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{
+				Adjustments: []dto.InvoiceAdjustmentRequest{
+					{DisplayName: "Proration credit", Amount: decimal.NewFromInt(-35)},
+				},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedType, resp.InvoiceType)
+			assert.True(t, decimal.NewFromInt(-15).Equal(resp.Total))
+			assert.True(t, decimal.Zero.Equal(resp.AmountDue))
+			assert.Equal(t, previous.ID, resp.OriginalInvoiceID)
+
+			// The linkage is exposed on the credited invoice as well
+			original, err := svc.GetInvoice(ctx, previous.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []string{resp.ID}, original.CreditInvoiceIDs)
+		})
+	}
+}
+
+func TestInvoiceService_FinalizeInvoice(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+
+	created, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+
+	finalized, err := svc.FinalizeInvoice(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.InvoiceStatusFinalized, finalized.InvoiceStatus)
+	assert.NotNil(t, finalized.FinalizedAt)
+	require.NotNil(t, finalized.InvoiceNumber)
+	assert.Equal(t, "INV-00001", *finalized.InvoiceNumber)
+
+	_, err = svc.FinalizeInvoice(ctx, created.ID)
+	assert.Error(t, err)
+}
+
+func TestInvoiceService_RecordPayment(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+
+	created, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, types.InvoicePaymentStatusPending, created.PaymentStatus)
+	assert.True(t, decimal.NewFromInt(20).Equal(created.AmountRemaining))
+
+	_, err = svc.RecordPayment(ctx, created.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(5)})
+	assert.Error(t, err, "draft invoices can not be paid")
+
+	_, err = svc.FinalizeInvoice(ctx, created.ID)
+	require.NoError(t, err)
+
+	paidAt := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	_, err = svc.RecordPayment(ctx, created.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(12), PaidAt: &paidAt})
+	require.NoError(t, err)
+	_, err = svc.RecordPayment(ctx, created.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(5), PaidAt: &paidAt, Currency: "eur"})
+	assert.Error(t, err, "currency must match the invoice")
+	_, err = svc.RecordPayment(ctx, created.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(5), PaidAt: &paidAt})
+	require.NoError(t, err)
+
+	payments, err := svc.ListPayments(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, payments.Payments, 2)
+	assert.True(t, decimal.NewFromInt(17).Equal(payments.AmountPaid))
+	assert.True(t, decimal.NewFromInt(3).Equal(payments.AmountRemaining))
+	assert.Equal(t, types.InvoicePaymentStatusPartiallyPaid, payments.PaymentStatus)
+
+	_, err = svc.RecordPayment(ctx, created.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(4)})
+	assert.Error(t, err, "payments can not exceed the amount remaining")
+
+	_, err = svc.RecordPayment(ctx, created.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(3)})
+	require.NoError(t, err)
+
+	inv, err := svc.GetInvoice(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, inv.AmountRemaining.IsZero())
+	assert.Equal(t, types.InvoicePaymentStatusPaid, inv.PaymentStatus)
+}
+
+func TestInvoiceService_InvoiceNumbering(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _, sequenceStore, sub := setupInvoiceServiceWithSequences(t, types.NegativeInvoiceBehaviorCreditInvoice, testutil.NewInMemoryWalletStore())
+
+	_, err := svc.UpdateInvoiceNumberingConfig(ctx, dto.UpdateInvoiceNumberingConfigRequest{
+		Prefix:      "ACME",
+		Separator:   "/",
+		Padding:     4,
+		ResetYearly: true,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.UpdateInvoiceNumberingConfig(ctx, dto.UpdateInvoiceNumberingConfigRequest{Prefix: "BAD PREFIX", Padding: 4})
+	assert.Error(t, err)
+
+	year := time.Now().UTC().Year()
+	for i := 1; i <= 3; i++ {
+		created, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+		require.NoError(t, err)
+
+		finalized, err := svc.FinalizeInvoice(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("ACME/%d/%04d", year, i), *finalized.InvoiceNumber)
+	}
+
+	// Counters are kept per environment
+	envCtx := context.WithValue(ctx, types.CtxEnvironmentID, "env_sandbox")
+	value, err := sequenceStore.NextValue(envCtx, "invoice:ACME")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+}
+
+func TestInvoiceService_VersionConflict(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _, sequenceStore, sub := setupInvoiceServiceWithSequences(t, types.NegativeInvoiceBehaviorCreditInvoice, testutil.NewInMemoryWalletStore())
+
+	created, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, created.Version)
+
+	// A write conditional on a version the invoice no longer has is rejected
+	// with the current version and leaves the invoice unchanged
+	_, err = svc.FinalizeInvoice(context.WithValue(ctx, types.CtxIfMatch, 7), created.ID)
+	var conflict *domainErrors.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, 1, conflict.CurrentVersion)
+
+	inv, err := svc.GetInvoice(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.InvoiceStatusDraft, inv.InvoiceStatus)
+
+	finalized, err := svc.FinalizeInvoice(context.WithValue(ctx, types.CtxIfMatch, 1), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, finalized.Version)
+
+	// The numbering config is versioned from its first save
+	numbering, err := svc.UpdateInvoiceNumberingConfig(ctx, dto.UpdateInvoiceNumberingConfigRequest{Prefix: "ACME", Padding: 4})
+	require.NoError(t, err)
+	assert.Equal(t, 1, numbering.Version)
+
+	_, err = svc.UpdateInvoiceNumberingConfig(context.WithValue(ctx, types.CtxIfMatch, 0), dto.UpdateInvoiceNumberingConfigRequest{Prefix: "INV", Padding: 4})
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, 1, conflict.CurrentVersion)
+
+	// A writer saving a config read before another update loses the race
+	stale, err := sequenceStore.GetInvoiceNumberingConfig(ctx)
+	require.NoError(t, err)
+	_, err = svc.UpdateInvoiceNumberingConfig(ctx, dto.UpdateInvoiceNumberingConfigRequest{Prefix: "BILL", Padding: 4})
+	require.NoError(t, err)
+
+	stale.Prefix = "STALE"
+	err = sequenceStore.UpsertInvoiceNumberingConfig(ctx, stale)
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, 2, conflict.CurrentVersion)
+
+	current, err := svc.GetInvoiceNumberingConfig(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "BILL", current.Prefix)
+}
+
+func TestInvoiceService_CreateCustomerInvoices(t *testing.T) {
+	tests := []struct {
+		name         string
+		consolidate  bool
+		wantInvoices int
+	}{
+		{name: "one invoice per subscription", consolidate: false, wantInvoices: 2},
+		{name: "consolidated", consolidate: true, wantInvoices: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.SetupContext()
+			walletStore := testutil.NewInMemoryWalletStore()
+			svc, _, _, base := setupInvoiceServiceWithSequences(t, types.NegativeInvoiceBehaviorCreditInvoice, walletStore)
+
+			// Reach the stores the setup wired into the service
+			invSvc := svc.(*invoiceService)
+			cust, err := invSvc.customerRepo.Get(ctx, base.CustomerID)
+			require.NoError(t, err)
+			cust.ConsolidateInvoices = tt.consolidate
+
+			billingDate := base.CurrentPeriodEnd
+			base.SubscriptionStatus = types.SubscriptionStatusActive
+
+			newSub := func(id string, status types.SubscriptionStatus, periodStart, periodEnd time.Time) {
+				sub := *base
+				sub.ID = id
+				sub.SubscriptionStatus = status
+				sub.CurrentPeriodStart = periodStart
+				sub.CurrentPeriodEnd = periodEnd
+				require.NoError(t, invSvc.subscriptionRepo.Create(ctx, &sub))
+			}
+
+			newSub("sub_second", types.SubscriptionStatusTrialing, billingDate.AddDate(0, 0, -14), billingDate)
+			newSub("sub_not_due", types.SubscriptionStatusActive, billingDate, billingDate.AddDate(0, 1, 0))
+			newSub("sub_cancelled", types.SubscriptionStatusCancelled, base.CurrentPeriodStart, billingDate)
+
+			resp, err := svc.CreateCustomerInvoices(ctx, cust.ID, dto.CreateCustomerInvoicesRequest{BillingDate: billingDate})
+			require.NoError(t, err)
+			require.Len(t, resp.Invoices, tt.wantInvoices)
+
+			if !tt.consolidate {
+				for _, inv := range resp.Invoices {
+					assert.NotEmpty(t, inv.SubscriptionID)
+					assert.Empty(t, inv.Sections)
+				}
+				return
+			}
+
+			inv := resp.Invoices[0]
+			assert.Empty(t, inv.SubscriptionID)
+			assert.True(t, decimal.NewFromInt(40).Equal(inv.Total))
+			assert.Equal(t, base.CurrentPeriodStart, *inv.PeriodStart)
+			assert.Equal(t, billingDate, *inv.PeriodEnd)
+
+			require.Len(t, inv.Sections, 2)
+			sections := make(map[string]dto.InvoiceSection)
+			for _, section := range inv.Sections {
+				sections[section.SubscriptionID] = section
+				assert.True(t, decimal.NewFromInt(20).Equal(section.Subtotal))
+				for _, item := range section.LineItems {
+					assert.Equal(t, inv.ID, item.InvoiceID)
+				}
+			}
+			assert.Equal(t, billingDate.AddDate(0, 0, -14), *sections["sub_second"].PeriodStart)
+
+			stored, err := svc.GetInvoice(ctx, inv.ID)
+			require.NoError(t, err)
+			assert.Len(t, stored.Sections, 2)
+		})
+	}
+}
+
+func TestInvoiceService_CreateSubscriptionInvoice_PartialPeriod(t *testing.T) {
+	// Calendar billed subscription started mid February, 15 of the 29 days of the month
+	start := time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		behavior     types.PartialPeriodBehavior
+		commitment   int64
+		periodStart  time.Time
+		wantBehavior types.PartialPeriodBehavior
+		wantTotal    decimal.Decimal
+	}{
+		{
+			name:         "prorate",
+			behavior:     types.PartialPeriodBehaviorProrate,
+			periodStart:  start,
+			wantBehavior: types.PartialPeriodBehaviorProrate,
+			wantTotal:    decimal.RequireFromString("10.34"),
+		},
+		{
+			name:         "prorated commitment",
+			behavior:     types.PartialPeriodBehaviorProrate,
+			commitment:   40,
+			periodStart:  start,
+			wantBehavior: types.PartialPeriodBehaviorProrate,
+			wantTotal:    decimal.RequireFromString("20.69"),
+		},
+		{
+			name:         "free",
+			behavior:     types.PartialPeriodBehaviorFree,
+			commitment:   40,
+			periodStart:  start,
+			wantBehavior: types.PartialPeriodBehaviorFree,
+			wantTotal:    decimal.Zero,
+		},
+		{
+			name:         "full",
+			behavior:     types.PartialPeriodBehaviorFull,
+			periodStart:  start,
+			wantBehavior: types.PartialPeriodBehaviorFull,
+			wantTotal:    decimal.NewFromInt(20),
+		},
+		{
+			name:        "later periods are billed in full",
+			behavior:    types.PartialPeriodBehaviorFree,
+			periodStart: periodEnd,
+			wantTotal:   decimal.NewFromInt(20),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.SetupContext()
+			svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+			sub.BillingCycle = types.BillingCycleCalendar
+			sub.PartialPeriodBehavior = tt.behavior
+			sub.CommitmentAmount = decimal.NewFromInt(tt.commitment)
+			sub.StartDate = start
+			sub.CurrentPeriodStart = tt.periodStart
+			sub.CurrentPeriodEnd = periodEnd
+			if !tt.periodStart.Before(periodEnd) {
+				sub.CurrentPeriodEnd = periodEnd.AddDate(0, 1, 0)
+			}
+
+			resp, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBehavior, resp.PartialPeriodBehavior)
+			assert.True(t, tt.wantTotal.Equal(resp.Total), "total %s", resp.Total)
+		})
+	}
+}
+
+func TestGuardrailViolation(t *testing.T) {
+	trailing := []*invoice.Invoice{
+		{Currency: "usd", Total: decimal.NewFromInt(100)},
+		{Currency: "usd", Total: decimal.NewFromInt(200)},
+		{Currency: "eur", Total: decimal.NewFromInt(10000)},
+	}
+
+	tests := []struct {
+		name       string
+		maxTotal   map[string]float64
+		deviation  float64
+		total      int64
+		trailing   []*invoice.Invoice
+		wantReason bool
+	}{
+		{name: "within the average", deviation: 50, total: 200, trailing: trailing},
+		{name: "above the average", deviation: 50, total: 300, trailing: trailing, wantReason: true},
+		{name: "below the average", deviation: 50, total: 50, trailing: trailing, wantReason: true},
+		{name: "no history", deviation: 50, total: 300},
+		{name: "above the cap", maxTotal: map[string]float64{"usd": 250}, total: 300, wantReason: true},
+		{name: "cap of another currency", maxTotal: map[string]float64{"eur": 250}, total: 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &invoice.Invoice{Currency: "usd", Total: decimal.NewFromInt(tt.total)}
+			reason := guardrailViolation(tt.maxTotal, tt.deviation, inv, tt.trailing)
+			assert.Equal(t, tt.wantReason, reason != "", reason)
+		})
+	}
+}
+
+func TestInvoiceService_Guardrails(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, invoiceStore, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+	svc.(*invoiceService).cfg.Guardrails = config.InvoiceGuardrailsConfig{
+		Enabled:             true,
+		MaxDeviationPercent: 100,
+		TrailingInvoices:    3,
+	}
+
+	// The platform fee of 20 is four times the previous invoice
+	require.NoError(t, invoiceStore.Create(ctx, &invoice.Invoice{
+		ID:            "inv_previous",
+		CustomerID:    sub.CustomerID,
+		InvoiceType:   types.InvoiceTypeSubscription,
+		InvoiceStatus: types.InvoiceStatusFinalized,
+		Currency:      "usd",
+		Total:         decimal.NewFromInt(5),
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}))
+
+	held, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, types.InvoiceStatusHeld, held.InvoiceStatus)
+	assert.NotEmpty(t, held.Metadata[invoiceHoldReasonKey])
+
+	_, err = svc.FinalizeInvoice(ctx, held.ID)
+	assert.Error(t, err)
+
+	approved, err := svc.ApproveInvoice(ctx, held.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.InvoiceStatusDraft, approved.InvoiceStatus)
+
+	finalized, err := svc.FinalizeInvoice(ctx, held.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.InvoiceStatusFinalized, finalized.InvoiceStatus)
+
+	// Only held invoices can be approved
+	_, err = svc.ApproveInvoice(ctx, held.ID)
+	assert.Error(t, err)
+}
+
+func TestInvoiceService_EditDraftLineItems(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, invoiceStore, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+	svc.(*invoiceService).cfg.UsageAdjustmentTolerancePercent = 10
+
+	created, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+
+	// A usage charge of 50 billed along with the platform fee of 20
+	usage := &invoice.InvoiceLineItem{
+		ID:          "li_usage",
+		InvoiceID:   created.ID,
+		CustomerID:  sub.CustomerID,
+		MeterID:     "meter_api_calls",
+		DisplayName: "API calls",
+		Amount:      decimal.NewFromInt(50),
+		Quantity:    decimal.NewFromInt(500),
+		Currency:    "usd",
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}
+	require.NoError(t, invoiceStore.CreateLineItem(ctx, usage))
+
+	added, err := svc.AddLineItem(ctx, created.ID, dto.AddInvoiceLineItemRequest{
+		DisplayName: "Onboarding fee",
+		Amount:      decimal.NewFromInt(100),
+	})
+	require.NoError(t, err)
+	require.Len(t, added.LineItems, 3)
+	assert.True(t, decimal.NewFromInt(170).Equal(added.Total))
+	assert.Equal(t, 2, added.Version)
+
+	discount, err := svc.AddLineItem(ctx, created.ID, dto.AddInvoiceLineItemRequest{
+		DisplayName: "Loyalty discount",
+		Amount:      decimal.NewFromInt(-30),
+	})
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(140).Equal(discount.Total))
+	discountID := discount.LineItems[3].ID
+
+	onboardingID := added.LineItems[2].ID
+	waived := "Onboarding fee (half waived)"
+	half := decimal.NewFromInt(50)
+	updated, err := svc.UpdateLineItem(ctx, created.ID, onboardingID, dto.UpdateInvoiceLineItemRequest{DisplayName: &waived, Amount: &half})
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(90).Equal(updated.Total))
+
+	// Usage amounts move within the tolerance of the metered amount, even
+	// across several edits
+	within := decimal.NewFromInt(54)
+	updated, err = svc.UpdateLineItem(ctx, created.ID, usage.ID, dto.UpdateInvoiceLineItemRequest{Amount: &within})
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(94).Equal(updated.Total))
+
+	beyond := decimal.NewFromInt(58)
+	_, err = svc.UpdateLineItem(ctx, created.ID, usage.ID, dto.UpdateInvoiceLineItemRequest{Amount: &beyond})
+	assert.ErrorIs(t, err, ErrUsageAdjustmentOutOfTolerance)
+
+	quantity := decimal.NewFromInt(400)
+	_, err = svc.UpdateLineItem(ctx, created.ID, usage.ID, dto.UpdateInvoiceLineItemRequest{Quantity: &quantity})
+	assert.Error(t, err, "usage quantities are metered")
+
+	_, err = svc.RemoveLineItem(ctx, created.ID, usage.ID)
+	assert.ErrorIs(t, err, ErrUsageAdjustmentOutOfTolerance)
+
+	_, err = svc.RemoveLineItem(ctx, created.ID, "li_unknown")
+	assert.ErrorIs(t, err, ErrInvoiceLineItemNotFound)
+
+	removed, err := svc.RemoveLineItem(ctx, created.ID, onboardingID)
+	require.NoError(t, err)
+	require.Len(t, removed.LineItems, 3)
+	assert.True(t, decimal.NewFromInt(44).Equal(removed.Total))
+
+	// Edits are conditional on the invoice version
+	_, err = svc.RemoveLineItem(context.WithValue(ctx, types.CtxIfMatch, 1), created.ID, discountID)
+	var conflict *domainErrors.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+
+	// Finalized invoices can no longer be edited
+	_, err = svc.FinalizeInvoice(ctx, created.ID)
+	require.NoError(t, err)
+	_, err = svc.AddLineItem(ctx, created.ID, dto.AddInvoiceLineItemRequest{DisplayName: "Late fee", Amount: decimal.NewFromInt(5)})
+	assert.ErrorIs(t, err, ErrInvoiceNotEditable)
+}
+
+func TestInvoiceService_CreateOneOffInvoice(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+
+	two := decimal.NewFromInt(2)
+	eight := decimal.NewFromInt(8)
+	rate := decimal.NewFromInt(150)
+	tenPercent := decimal.NewFromInt(10)
+	sixteen := decimal.NewFromInt(16)
+
+	req := dto.CreateOneOffInvoiceRequest{
+		CustomerID:  sub.CustomerID,
+		Currency:    "USD",
+		Description: "Implementation services",
+		LineItems: []dto.OneOffInvoiceLineItemRequest{
+			{PriceID: "price_fixed", Quantity: &two},
+			{DisplayName: "Solution architect", UnitAmount: &rate, Quantity: &eight},
+		},
+		Discounts: []dto.InvoiceDiscountRequest{
+			{DisplayName: "WELCOME10", PercentOff: &tenPercent},
+			{DisplayName: "Referral credit", AmountOff: &sixteen},
+		},
+		Taxes: []dto.InvoiceTaxRequest{{DisplayName: "VAT 20%", RatePercent: decimal.NewFromInt(20)}},
+	}
+
+	// The charges of 1240 are discounted by 124 and 16, and taxed at 20% of 1100
+	draft, err := svc.CreateOneOffInvoice(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, types.InvoiceTypeOneOff, draft.InvoiceType)
+	assert.Equal(t, types.InvoiceStatusDraft, draft.InvoiceStatus)
+	assert.Empty(t, draft.SubscriptionID)
+	assert.Equal(t, "usd", draft.Currency)
+	require.Len(t, draft.LineItems, 5)
+	assert.Equal(t, "Platform fee", draft.LineItems[0].DisplayName)
+	assert.True(t, decimal.NewFromInt(40).Equal(draft.LineItems[0].Amount))
+	assert.True(t, decimal.NewFromInt(1200).Equal(draft.LineItems[1].Amount))
+	assert.True(t, decimal.NewFromInt(-124).Equal(draft.LineItems[2].Amount))
+	assert.True(t, decimal.NewFromInt(-16).Equal(draft.LineItems[3].Amount))
+	assert.True(t, decimal.NewFromInt(220).Equal(draft.LineItems[4].Amount))
+	assert.True(t, decimal.NewFromInt(1320).Equal(draft.Total))
+
+	req.Finalize = true
+	finalized, err := svc.CreateOneOffInvoice(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, types.InvoiceStatusFinalized, finalized.InvoiceStatus)
+	require.NotNil(t, finalized.InvoiceNumber)
+
+	_, err = svc.RecordPayment(ctx, finalized.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(1320)})
+	require.NoError(t, err)
+
+	invalid := []struct {
+		name   string
+		mutate func(r *dto.CreateOneOffInvoiceRequest)
+	}{
+		{"unknown customer", func(r *dto.CreateOneOffInvoiceRequest) { r.CustomerID = "cust_unknown" }},
+		{"price in another currency", func(r *dto.CreateOneOffInvoiceRequest) { r.Currency = "eur" }},
+		{"discounts exceeding the charges", func(r *dto.CreateOneOffInvoiceRequest) {
+			off := decimal.NewFromInt(5000)
+			r.Discounts = []dto.InvoiceDiscountRequest{{DisplayName: "Too generous", AmountOff: &off}}
+		}},
+		{"line item without price or amount", func(r *dto.CreateOneOffInvoiceRequest) {
+			r.LineItems = []dto.OneOffInvoiceLineItemRequest{{DisplayName: "Consulting"}}
+		}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			r := req
+			tt.mutate(&r)
+			_, err := svc.CreateOneOffInvoice(ctx, r)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestInvoiceService_ListInvoicesCursor(t *testing.T) {
+	svc, invoiceStore, _ := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+	ctx := testutil.SetupContext()
+
+	// Two invoices share a creation time so the pages have to break the tie on the ID
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"inv_1", "inv_2", "inv_3", "inv_4", "inv_5"} {
+		base := types.GetDefaultBaseModel(ctx)
+		base.CreatedAt = created.Add(time.Duration(min(i, 3)) * time.Hour)
+		require.NoError(t, invoiceStore.Create(ctx, &invoice.Invoice{ID: id, CustomerID: "cust_123", BaseModel: base}))
+	}
+
+	var (
+		listed []string
+		cursor string
+	)
+	filter := &types.InvoiceFilter{Filter: types.Filter{Limit: 2, Pagination: types.PaginationModeCursor}}
+	for pages := 0; pages < 5; pages++ {
+		filter.Cursor = cursor
+		resp, err := svc.ListInvoices(ctx, filter)
+		require.NoError(t, err)
+		for _, inv := range resp.Invoices {
+			listed = append(listed, inv.ID)
+		}
+		cursor = resp.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"inv_5", "inv_4", "inv_3", "inv_2", "inv_1"}, listed)
+
+	_, err := svc.ListInvoices(ctx, &types.InvoiceFilter{Filter: types.Filter{Cursor: "not a cursor"}})
+	assert.ErrorIs(t, err, types.ErrInvalidCursor)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/billingengine"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+func (s *invoiceService) ProcessBillingThresholds(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+
+	subs, err := s.subscriptionRepo.ListWithBillingThreshold(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	forEachJobItem(ctx, subs, func(sub *subscription.Subscription) {
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, sub.TenantID)
+		tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+		if !featureEnabled(tenantCtx, s.featureFlagService, types.FeatureFlagThresholdBilling) {
+			return
+		}
+
+		// One failing subscription must not hold back the others
+		if err := s.billThreshold(tenantCtx, sub, now); err != nil {
+			s.logger.Errorw("failed to process billing threshold",
+				"tenant_id", sub.TenantID,
+				"subscription_id", sub.ID,
+				"error", err,
+			)
+		}
+	})
+
+	return nil
+}
+
+// billThreshold invoices the usage accumulated since the last threshold invoice
+// of the period when it exceeds the threshold, and resets the accumulator. Once
+// the period is over its usage is left to the period end invoice
+func (s *invoiceService) billThreshold(ctx context.Context, sub *subscription.Subscription, now time.Time) error {
+	if !now.Before(sub.CurrentPeriodEnd) {
+		return nil
+	}
+
+	from := sub.CurrentPeriodStart
+	if sub.ThresholdBilledUntil != nil && sub.ThresholdBilledUntil.After(from) {
+		from = *sub.ThresholdBilledUntil
+	}
+
+	if !now.After(from) {
+		return nil
+	}
+
+	inv, err := s.buildThresholdInvoice(ctx, sub.ID, from, now)
+	if err != nil {
+		return err
+	}
+
+	if !inv.Total.GreaterThan(sub.BillingThreshold) {
+		return nil
+	}
+	if err := s.taxInvoice(ctx, inv); err != nil {
+		return err
+	}
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.issueInvoice(ctx, inv); err != nil {
+			return err
+		}
+
+		sub.ThresholdBilledUntil = &now
+		if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infow("raised threshold invoice",
+		"tenant_id", sub.TenantID,
+		"subscription_id", sub.ID,
+		"invoice_id", inv.ID,
+		"total", inv.Total,
+		"threshold", sub.BillingThreshold,
+	)
+
+	return nil
+}
+
+func (s *invoiceService) GetUsageCharges(ctx context.Context, subscriptionID string, from, to time.Time) (decimal.Decimal, error) {
+	inv, err := s.buildThresholdInvoice(ctx, subscriptionID, from, to)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return inv.Total, nil
+}
+
+// buildThresholdInvoice computes the draft invoice of the usage of the subscription
+// between from and to. The fixed charges and the commitment are billed at the end
+// of the period only
+func (s *invoiceService) buildThresholdInvoice(ctx context.Context, subscriptionID string, from, to time.Time) (*invoice.Invoice, error) {
+	subscriptionResponse, err := s.subscriptionReader.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	sub := subscriptionResponse.Subscription
+
+	inv := &invoice.Invoice{
+		ID:             types.GenerateUUID(),
+		CustomerID:     sub.CustomerID,
+		SubscriptionID: sub.ID,
+		InvoiceType:    types.InvoiceTypeSubscription,
+		InvoiceFlow:    types.InvoiceFlowThreshold,
+		InvoiceStatus:  types.InvoiceStatusDraft,
+		Currency:       sub.Currency,
+		PeriodStart:    &from,
+		PeriodEnd:      &to,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}
+
+	// A zero period share leaves the recurring fixed charges and the commitment out
+	in := billingengine.Input{
+		Currency:    inv.Currency,
+		PeriodShare: decimal.Zero,
+	}
+
+	card, err := s.effectiveRateCard(ctx, sub, sub.CurrentPeriodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addPlanCharges(ctx, &in, subscriptionResponse, card, from, to); err != nil {
+		return nil, err
+	}
+
+	result := billingengine.Calculate(in)
+	for _, item := range result.LineItems {
+		inv.LineItems = append(inv.LineItems, s.newLineItem(ctx, inv, lineItemParams{
+			PriceID:     item.PriceID,
+			MeterID:     item.MeterID,
+			DisplayName: item.DisplayName,
+			Amount:      item.Amount,
+			Quantity:    item.Quantity,
+		}))
+	}
+
+	inv.RecalculateTotals()
+
+	return inv, nil
+}
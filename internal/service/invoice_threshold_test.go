@@ -0,0 +1,169 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceService_ProcessBillingThresholds(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_123",
+		ExternalID: "ext_cust_123",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	meterStore := testutil.NewInMemoryMeterStore()
+	require.NoError(t, meterStore.CreateMeter(ctx, &meter.Meter{
+		ID:          "meter_api_calls",
+		Name:        "API Calls",
+		EventName:   "api_call",
+		Aggregation: meter.Aggregation{Type: types.AggregationCount},
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}))
+
+	planStore := testutil.NewInMemoryPlanStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Usage Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	priceStore := testutil.NewInMemoryPriceStore()
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_api_calls",
+		PlanID:             "plan_123",
+		MeterID:            "meter_api_calls",
+		Type:               types.PRICE_TYPE_USAGE,
+		Amount:             decimal.NewFromInt(1),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingThreshold:   decimal.NewFromInt(5),
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	eventStore := testutil.NewInMemoryEventStore()
+	calls := func(count int, at time.Time) {
+		for i := 0; i < count; i++ {
+			require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+				ID:                 types.GenerateUUID(),
+				TenantID:           types.GetTenantID(ctx),
+				EventName:          "api_call",
+				ExternalCustomerID: "ext_cust_123",
+				Timestamp:          at,
+				Properties:         map[string]interface{}{},
+			}))
+		}
+	}
+
+	featureFlagService := NewFeatureFlagService(testutil.NewInMemoryFeatureFlagStore(), logger.GetLogger())
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	svc := NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+		testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+		nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, featureFlagService,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), eventStore, meterStore, customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	listInvoices := func() []*dto.InvoiceResponse {
+		resp, err := svc.ListInvoices(ctx, &types.InvoiceFilter{})
+		require.NoError(t, err)
+
+		invoices := make([]*dto.InvoiceResponse, len(resp.Invoices))
+		for i := range resp.Invoices {
+			invoices[i] = &resp.Invoices[i]
+		}
+		return invoices
+	}
+
+	// Usage at the threshold does not exceed it
+	calls(5, start.AddDate(0, 0, 2))
+	require.NoError(t, svc.ProcessBillingThresholds(ctx, start.AddDate(0, 0, 4)))
+	assert.Empty(t, listInvoices())
+
+	calls(2, start.AddDate(0, 0, 5))
+
+	// Tenants with threshold billing turned off are left to the period end invoice
+	tenantID := types.GetTenantID(ctx)
+	_, err := featureFlagService.SetOverride(ctx, tenantID, types.FeatureFlagThresholdBilling, &dto.SetFeatureFlagRequest{Enabled: false})
+	require.NoError(t, err)
+	require.NoError(t, svc.ProcessBillingThresholds(ctx, start.AddDate(0, 0, 6)))
+	assert.Empty(t, listInvoices())
+	require.NoError(t, featureFlagService.DeleteOverride(ctx, tenantID, types.FeatureFlagThresholdBilling, ""))
+
+	billedAt := start.AddDate(0, 0, 6)
+	require.NoError(t, svc.ProcessBillingThresholds(ctx, billedAt))
+
+	invoices := listInvoices()
+	require.Len(t, invoices, 1)
+	assert.Equal(t, types.InvoiceFlowThreshold, invoices[0].InvoiceFlow)
+	assert.True(t, decimal.NewFromInt(7).Equal(invoices[0].Total), "total %s", invoices[0].Total)
+	assert.Equal(t, start, *invoices[0].PeriodStart)
+	assert.Equal(t, billedAt, *invoices[0].PeriodEnd)
+
+	sub, err := subscriptionStore.Get(ctx, "sub_123")
+	require.NoError(t, err)
+	require.NotNil(t, sub.ThresholdBilledUntil)
+	assert.Equal(t, billedAt, *sub.ThresholdBilledUntil)
+
+	// The accumulator was reset by the threshold invoice
+	calls(3, start.AddDate(0, 0, 7))
+	require.NoError(t, svc.ProcessBillingThresholds(ctx, start.AddDate(0, 0, 8)))
+	assert.Len(t, listInvoices(), 1)
+
+	// Nothing is billed past the end of the period
+	require.NoError(t, svc.ProcessBillingThresholds(ctx, start.AddDate(0, 2, 0)))
+	assert.Len(t, listInvoices(), 1)
+
+	// The period end invoice bills the usage left after the threshold invoice only
+	resp, err := svc.CreateSubscriptionInvoice(ctx, "sub_123", dto.CreateSubscriptionInvoiceRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, types.InvoiceFlowPeriodEnd, resp.InvoiceFlow)
+	assert.True(t, decimal.NewFromInt(3).Equal(resp.Total), "total %s", resp.Total)
+}
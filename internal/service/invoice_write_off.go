@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+var (
+	// ErrInvoiceNotWritableOff is returned when an invoice that is not finalized,
+	// has nothing remaining to be paid or was already written off is written off
+	ErrInvoiceNotWritableOff = errors.New("invoice can not be written off")
+
+	// ErrInvoiceWrittenOff is returned when the payment of an invoice that was
+	// written off is collected
+	ErrInvoiceWrittenOff = errors.New("invoice was written off")
+)
+
+// WriteOffInvoice marks the amount remaining of a finalized invoice as
+// uncollectible. The bank debits of the invoice still scheduled are cancelled,
+// and the invoice is left out of the receivables aging and reported with the
+// write-offs instead. Payments can still be recorded against it if the amount
+// is recovered later
+func (s *invoiceService) WriteOffInvoice(ctx context.Context, id string, req dto.WriteOffInvoiceRequest) (*dto.InvoiceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	var inv *invoice.Invoice
+	var before invoice.Invoice
+	var cancelled []*invoice.Payment
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		inv, err = s.invoiceRepo.GetForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if err := checkIfMatch(ctx, "invoice", inv.ID, inv.Version); err != nil {
+			return err
+		}
+
+		if inv.InvoiceType == types.InvoiceTypeCredit || inv.InvoiceStatus != types.InvoiceStatusFinalized {
+			return fmt.Errorf("%w: only finalized invoices can be written off", ErrInvoiceNotWritableOff)
+		}
+		if inv.WrittenOffAt != nil {
+			return fmt.Errorf("%w: invoice was already written off", ErrInvoiceNotWritableOff)
+		}
+		// A disputed invoice is settled through its dispute first
+		if err := checkNotDisputed(ctx, s.invoiceRepo, inv.ID); err != nil {
+			return err
+		}
+
+		now := s.clock.Now()
+		before = *inv
+
+		cancelled, err = s.cancelScheduledDebits(ctx, inv, "invoice written off", now)
+		if err != nil {
+			return err
+		}
+		if !inv.AmountRemaining.IsPositive() {
+			return fmt.Errorf("%w: invoice has no amount remaining", ErrInvoiceNotWritableOff)
+		}
+
+		inv.AmountWrittenOff = inv.AmountRemaining
+		inv.WriteOffReason = req.Reason
+		inv.WrittenOffAt = &now
+		inv.RecalculateAmountRemaining()
+		inv.UpdatedAt = now
+		inv.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+			return fmt.Errorf("failed to write off invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, payment := range cancelled {
+		recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoicePayment, payment.ID, types.AuditActionUpdate, nil, payment)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionUpdate, &before, inv)
+
+	s.logger.Debugw("wrote off invoice",
+		"invoice_id", inv.ID,
+		"amount_written_off", inv.AmountWrittenOff,
+		"cancelled_debits", len(cancelled),
+	)
+
+	written, err := s.invoiceRepo.Get(ctx, inv.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	return dto.NewInvoiceResponse(written), nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceWriteOff(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	for _, id := range []string{"cust_1", "cust_2"} {
+		require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+			ID:        id,
+			BaseModel: types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	publisher := webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger())
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	svc := NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), testutil.NewInMemorySubscriptionStore(),
+		testutil.NewInMemoryPlanStore(), testutil.NewInMemoryPriceStore(),
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(),
+		testutil.NewInMemoryRateCardStore(), testutil.NewInMemoryPriceBookStore(),
+		nil, testutil.NewInMemoryTxManager(), publisher,
+		nil, nil, nil, nil,
+		nil, nil, nil, nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+	customers := NewCustomerService(customerStore, nil, nil, nil, logger.GetLogger())
+	receivables := NewReceivablesService(testutil.NewInMemoryReceivablesSnapshotStore(), invoiceStore, logger.GetLogger())
+
+	fakeGateway := &fakeCollectionGateway{}
+	payments := NewPaymentService(invoiceStore, customerStore, testutil.NewInMemoryPaymentMethodStore(),
+		testutil.NewInMemoryConnectionStore(), nil, testutil.NewInMemoryMandateStore(), nil,
+		testutil.NewInMemoryTxManager(), publisher, nil, nil, logger.GetLogger()).(*paymentService)
+	payments.newGateway = func(*connection.Connection) (collectionGateway, error) { return fakeGateway, nil }
+
+	hundred := decimal.NewFromInt(100)
+	forty := decimal.NewFromInt(40)
+	finalized := func(customerID string) *dto.InvoiceResponse {
+		resp, err := svc.CreateOneOffInvoice(ctx, dto.CreateOneOffInvoiceRequest{
+			CustomerID: customerID,
+			Currency:   "usd",
+			LineItems:  []dto.OneOffInvoiceLineItemRequest{{DisplayName: "Onboarding", UnitAmount: &hundred}},
+			Finalize:   true,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	getInvoice := func(id string) *invoice.Invoice {
+		inv, err := invoiceStore.Get(ctx, id)
+		require.NoError(t, err)
+		return inv
+	}
+	scheduleDebit := func(inv *dto.InvoiceResponse, id string) {
+		chargeDate := time.Now().Add(-time.Hour)
+		require.NoError(t, invoiceStore.CreatePayment(ctx, &invoice.Payment{
+			ID:            id,
+			InvoiceID:     inv.ID,
+			CustomerID:    inv.CustomerID,
+			Amount:        forty,
+			Currency:      inv.Currency,
+			PaidAt:        chargeDate,
+			PaymentStatus: types.PaymentStatusScheduled,
+			MandateID:     "mandate_1",
+			ChargeDate:    &chargeDate,
+			BaseModel:     types.GetDefaultBaseModel(ctx),
+		}))
+		scheduled := getInvoice(inv.ID)
+		scheduled.AmountProcessing = forty
+		scheduled.RecalculateAmountRemaining()
+		require.NoError(t, invoiceStore.Update(ctx, scheduled))
+	}
+
+	t.Run("write-off", func(t *testing.T) {
+		inv := finalized("cust_1")
+		scheduleDebit(inv, "debit_1")
+		_, err := svc.RecordPayment(ctx, inv.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(10)})
+		require.NoError(t, err)
+
+		_, err = svc.WriteOffInvoice(ctx, inv.ID, dto.WriteOffInvoiceRequest{})
+		assert.Error(t, err)
+
+		written, err := svc.WriteOffInvoice(ctx, inv.ID, dto.WriteOffInvoiceRequest{Reason: "Customer went out of business"})
+		require.NoError(t, err)
+		assert.Equal(t, types.InvoicePaymentStatusUncollectible, written.PaymentStatus)
+		require.NotNil(t, written.WrittenOffAt)
+
+		// The scheduled debit was cancelled and its amount written off
+		debit, err := invoiceStore.GetPayment(ctx, "debit_1")
+		require.NoError(t, err)
+		assert.Equal(t, types.PaymentStatusCancelled, debit.PaymentStatus)
+		updated := getInvoice(inv.ID)
+		assert.True(t, updated.AmountProcessing.IsZero())
+		assert.True(t, decimal.NewFromInt(90).Equal(updated.AmountWrittenOff))
+
+		_, err = svc.WriteOffInvoice(ctx, inv.ID, dto.WriteOffInvoiceRequest{Reason: "Again"})
+		assert.ErrorIs(t, err, ErrInvoiceNotWritableOff)
+		_, err = payments.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: "conn_1"})
+		assert.ErrorIs(t, err, ErrInvoiceWrittenOff)
+
+		// The invoice leaves the receivables for the write-off report
+		outstanding := finalized("cust_1")
+		report, err := receivables.GetReceivablesReport(ctx, &types.ReceivablesFilter{}, time.Now())
+		require.NoError(t, err)
+		require.Len(t, report.Currencies, 1)
+		assert.True(t, hundred.Equal(report.Currencies[0].Total))
+		assert.Equal(t, 1, report.Currencies[0].Invoices)
+
+		writeOffs, err := receivables.GetWriteOffReport(ctx, &types.WriteOffFilter{})
+		require.NoError(t, err)
+		require.Len(t, writeOffs.Currencies, 1)
+		assert.True(t, decimal.NewFromInt(90).Equal(writeOffs.Currencies[0].Amount))
+		require.Len(t, writeOffs.Invoices, 1)
+		assert.Equal(t, inv.ID, writeOffs.Invoices[0].InvoiceID)
+		assert.Equal(t, "Customer went out of business", writeOffs.Invoices[0].Reason)
+
+		future := time.Now().Add(time.Hour)
+		writeOffs, err = receivables.GetWriteOffReport(ctx, &types.WriteOffFilter{StartTime: &future})
+		require.NoError(t, err)
+		assert.Empty(t, writeOffs.Invoices)
+
+		// An amount recovered later can still be recorded
+		_, err = svc.RecordPayment(ctx, inv.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(90)})
+		require.NoError(t, err)
+		assert.Equal(t, types.InvoicePaymentStatusPaid, getInvoice(inv.ID).PaymentStatus)
+
+		_, err = svc.WriteOffInvoice(ctx, outstanding.ID, dto.WriteOffInvoiceRequest{Reason: "Unresponsive customer"})
+		require.NoError(t, err)
+	})
+
+	t.Run("disputed invoices are not written off", func(t *testing.T) {
+		inv := finalized("cust_1")
+		_, err := svc.OpenDispute(ctx, inv.ID, dto.OpenDisputeRequest{
+			Lines: []dto.DisputedLineRequest{{LineItemID: inv.LineItems[0].ID, Amount: forty}},
+		})
+		require.NoError(t, err)
+
+		_, err = svc.WriteOffInvoice(ctx, inv.ID, dto.WriteOffInvoiceRequest{Reason: "Not delivered"})
+		assert.ErrorIs(t, err, ErrInvoiceDisputed)
+	})
+
+	t.Run("collections pause", func(t *testing.T) {
+		inv := finalized("cust_2")
+		scheduleDebit(inv, "debit_2")
+
+		_, err := customers.PauseCollections(ctx, "cust_2", dto.PauseCollectionsRequest{Until: time.Now().Add(-time.Hour)})
+		assert.Error(t, err)
+
+		paused, err := customers.PauseCollections(ctx, "cust_2", dto.PauseCollectionsRequest{
+			Until:  time.Now().Add(24 * time.Hour),
+			Reason: "Payment plan agreed",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, paused.CollectionsPausedUntil)
+
+		// The due debit is held and no payment is collected
+		require.NoError(t, payments.CollectDueDebits(ctx, time.Now()))
+		assert.Empty(t, fakeGateway.debits)
+		debit, err := invoiceStore.GetPayment(ctx, "debit_2")
+		require.NoError(t, err)
+		assert.Equal(t, types.PaymentStatusScheduled, debit.PaymentStatus)
+
+		_, err = payments.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: "conn_1"})
+		assert.ErrorIs(t, err, ErrCollectionsPaused)
+		_, err = payments.ScheduleDirectDebit(ctx, inv.ID, dto.ScheduleDirectDebitRequest{})
+		assert.ErrorIs(t, err, ErrCollectionsPaused)
+
+		// The pause expires on its own
+		cust, err := customerStore.Get(ctx, "cust_2")
+		require.NoError(t, err)
+		assert.False(t, cust.CollectionsPaused(time.Now().Add(25*time.Hour)))
+
+		resumed, err := customers.ResumeCollections(ctx, "cust_2")
+		require.NoError(t, err)
+		assert.Nil(t, resumed.CollectionsPausedUntil)
+
+		_, err = payments.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: "conn_1"})
+		assert.NotErrorIs(t, err, ErrCollectionsPaused)
+	})
+}
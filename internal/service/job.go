@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/scheduler"
+)
+
+// JobService reports and triggers the background jobs of the scheduler
+type JobService interface {
+	ListJobs(ctx context.Context) (*dto.ListJobsResponse, error)
+	ListJobRuns(ctx context.Context, name string, limit int) (*dto.ListJobRunsResponse, error)
+
+	// TriggerJob starts a run of a job right away in this process, disabled
+	// jobs included. It fails with scheduler.ErrJobRunning while the job runs
+	TriggerJob(ctx context.Context, name string) (*dto.JobRunResponse, error)
+}
+
+type jobService struct {
+	scheduler *scheduler.Scheduler
+	logger    *logger.Logger
+}
+
+func NewJobService(scheduler *scheduler.Scheduler, logger *logger.Logger) JobService {
+	return &jobService{
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+func (s *jobService) ListJobs(ctx context.Context) (*dto.ListJobsResponse, error) {
+	jobs := s.scheduler.Jobs()
+
+	resp := &dto.ListJobsResponse{
+		Jobs: make([]dto.JobResponse, len(jobs)),
+	}
+	for i, j := range jobs {
+		runs, err := s.scheduler.ListRuns(ctx, j.Name, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last run of job %s: %w", j.Name, err)
+		}
+
+		resp.Jobs[i] = dto.JobResponse{
+			Name:         j.Name,
+			Description:  j.Description,
+			Enabled:      j.Enabled,
+			IntervalMins: int(j.Interval.Minutes()),
+			BatchSize:    j.BatchSize,
+			Concurrency:  j.Concurrency,
+			Running:      j.Running,
+		}
+		if len(runs) > 0 {
+			resp.Jobs[i].LastRun = runs[0]
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *jobService) ListJobRuns(ctx context.Context, name string, limit int) (*dto.ListJobRunsResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	runs, err := s.scheduler.ListRuns(ctx, name, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ListJobRunsResponse{
+		Runs: make([]dto.JobRunResponse, len(runs)),
+	}
+	for i, run := range runs {
+		resp.Runs[i] = dto.JobRunResponse{Run: run}
+	}
+
+	return resp, nil
+}
+
+func (s *jobService) TriggerJob(ctx context.Context, name string) (*dto.JobRunResponse, error) {
+	run, err := s.scheduler.Trigger(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infow("triggered job run",
+		"job", name,
+		"run_id", run.ID,
+	)
+
+	return &dto.JobRunResponse{Run: run}, nil
+}
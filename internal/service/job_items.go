@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// forEachJobItem calls fn for the items of a background job run as configured
+// for the job, up to concurrency items at once and batch size items at a time.
+// The run stops between batches once it is canceled
+func forEachJobItem[T any](ctx context.Context, items []T, fn func(item T)) {
+	opts := types.GetJobRunOptions(ctx)
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(items)
+	}
+
+	for start := 0; start < len(items); start += batchSize {
+		if ctx.Err() != nil {
+			return
+		}
+
+		batch := items[start:min(start+batchSize, len(items))]
+		if opts.Concurrency <= 1 {
+			for _, item := range batch {
+				fn(item)
+			}
+			continue
+		}
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, opts.Concurrency)
+		for _, item := range batch {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				fn(item)
+			}()
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachJobItem(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+
+	t.Run("processes every item by default", func(t *testing.T) {
+		var seen []int
+		forEachJobItem(context.Background(), items, func(item int) {
+			seen = append(seen, item)
+		})
+		assert.Equal(t, items, seen)
+	})
+
+	t.Run("processes up to concurrency items at once", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), types.CtxJobRunOptions, types.JobRunOptions{BatchSize: 3, Concurrency: 2})
+
+		var mu sync.Mutex
+		var seen []int
+		var running, maxRunning atomic.Int32
+		forEachJobItem(ctx, items, func(item int) {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			mu.Lock()
+			seen = append(seen, item)
+			mu.Unlock()
+			running.Add(-1)
+		})
+
+		assert.ElementsMatch(t, items, seen)
+		assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	})
+
+	t.Run("stops between batches once canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = context.WithValue(ctx, types.CtxJobRunOptions, types.JobRunOptions{BatchSize: 2})
+
+		var seen []int
+		forEachJobItem(ctx, items, func(item int) {
+			seen = append(seen, item)
+			cancel()
+		})
+		assert.Equal(t, []int{1, 2}, seen)
+	})
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	ledgerDomain "github.com/flexprice/flexprice/internal/domain/ledger"
+	"github.com/flexprice/flexprice/internal/ledger"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+type LedgerSyncService interface {
+	// SyncInvoice queues the push of a finalized invoice, or credit note for
+	// credit invoices, to every NetSuite and QuickBooks connection it is not
+	// synced with yet
+	SyncInvoice(ctx context.Context, inv *invoice.Invoice) error
+
+	// ListInvoiceSyncs returns the reconciliation status of the invoice with
+	// every ledger connection
+	ListInvoiceSyncs(ctx context.Context, invoiceID string) (*dto.ListLedgerSyncsResponse, error)
+	ListSyncs(ctx context.Context, filter *types.LedgerSyncFilter) (*dto.ListLedgerSyncsResponse, error)
+
+	// RetryInvoiceSync queues the invoice again for the connections it is not
+	// synced with, typically once the field mapping is fixed
+	RetryInvoiceSync(ctx context.Context, invoiceID string) (*dto.ListLedgerSyncsResponse, error)
+}
+
+type ledgerSyncService struct {
+	repo              ledgerDomain.Repository
+	connectionRepo    connection.Repository
+	invoiceRepo       invoice.Repository
+	customerRepo      customer.Repository
+	connectionService ConnectionService
+	logger            *logger.Logger
+
+	// newClient is replaced in tests
+	newClient func(conn *connection.Connection) (ledger.Client, error)
+}
+
+func NewLedgerSyncService(
+	repo ledgerDomain.Repository,
+	connectionRepo connection.Repository,
+	invoiceRepo invoice.Repository,
+	customerRepo customer.Repository,
+	connectionService ConnectionService,
+	logger *logger.Logger,
+) LedgerSyncService {
+	return &ledgerSyncService{
+		repo:              repo,
+		connectionRepo:    connectionRepo,
+		invoiceRepo:       invoiceRepo,
+		customerRepo:      customerRepo,
+		connectionService: connectionService,
+		logger:            logger,
+		newClient:         ledger.NewClient,
+	}
+}
+
+func (s *ledgerSyncService) SyncInvoice(ctx context.Context, inv *invoice.Invoice) error {
+	if inv.InvoiceStatus != types.InvoiceStatusFinalized {
+		return fmt.Errorf("invoice is not finalized")
+	}
+
+	conns, err := s.connectionRepo.ListByProviders(ctx, types.LedgerProviders)
+	if err != nil {
+		return fmt.Errorf("failed to list ledger connections: %w", err)
+	}
+	if len(conns) == 0 {
+		return nil
+	}
+
+	synced, err := s.syncedConnections(ctx, inv.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, conn := range conns {
+		if synced[conn.ID] {
+			continue
+		}
+		if err := s.enqueue(ctx, conn.ID, inv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ledgerSyncService) ListInvoiceSyncs(ctx context.Context, invoiceID string) (*dto.ListLedgerSyncsResponse, error) {
+	syncs, err := s.repo.ListByEntity(ctx, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger syncs: %w", err)
+	}
+
+	return dto.NewListLedgerSyncsResponse(syncs, 0, len(syncs)), nil
+}
+
+func (s *ledgerSyncService) ListSyncs(ctx context.Context, filter *types.LedgerSyncFilter) (*dto.ListLedgerSyncsResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	syncs, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger syncs: %w", err)
+	}
+
+	return dto.NewListLedgerSyncsResponse(syncs, filter.Offset, filter.Limit), nil
+}
+
+func (s *ledgerSyncService) RetryInvoiceSync(ctx context.Context, invoiceID string) (*dto.ListLedgerSyncsResponse, error) {
+	inv, err := s.invoiceRepo.Get(ctx, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	if err := s.SyncInvoice(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	return s.ListInvoiceSyncs(ctx, invoiceID)
+}
+
+// syncedConnections returns the connections the invoice is already in the
+// ledger of, it is never pushed twice to a ledger
+func (s *ledgerSyncService) syncedConnections(ctx context.Context, invoiceID string) (map[string]bool, error) {
+	syncs, err := s.repo.ListByEntity(ctx, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger syncs: %w", err)
+	}
+
+	synced := make(map[string]bool, len(syncs))
+	for _, sync := range syncs {
+		if sync.SyncStatus == types.LedgerSyncStatusSynced {
+			synced[sync.ConnectionID] = true
+		}
+	}
+	return synced, nil
+}
+
+func (s *ledgerSyncService) enqueue(ctx context.Context, connectionID string, inv *invoice.Invoice) error {
+	sync := &ledgerDomain.Sync{
+		ID:           types.GenerateUUID(),
+		ConnectionID: connectionID,
+		EntityType:   ledger.EntityType(inv),
+		EntityID:     inv.ID,
+		SyncStatus:   types.LedgerSyncStatusPending,
+		BaseModel:    types.GetDefaultBaseModel(ctx),
+	}
+	if err := s.repo.Upsert(ctx, sync); err != nil {
+		return fmt.Errorf("failed to record ledger sync: %w", err)
+	}
+
+	return s.connectionService.EnqueueSync(ctx, connectionID, &syncqueue.Task{
+		EntityType: sync.EntityType,
+		EntityID:   inv.ID,
+		Run: func(ctx context.Context) error {
+			return s.push(ctx, sync)
+		},
+	})
+}
+
+// push creates the document in the ledger and records the outcome of the
+// attempt. Failed attempts are retried by the sync queue, the sync is failed
+// until one of them succeeds
+func (s *ledgerSyncService) push(ctx context.Context, sync *ledgerDomain.Sync) error {
+	externalID, err := s.pushDocument(ctx, sync)
+
+	sync.Attempts++
+	sync.UpdatedAt = time.Now().UTC()
+	if err != nil {
+		sync.SyncStatus = types.LedgerSyncStatusFailed
+		sync.LastError = err.Error()
+	} else {
+		sync.SyncStatus = types.LedgerSyncStatusSynced
+		sync.ExternalID = externalID
+		sync.LastError = ""
+		sync.SyncedAt = &sync.UpdatedAt
+	}
+
+	if updateErr := s.repo.Update(ctx, sync); updateErr != nil {
+		s.logger.Errorw("failed to record ledger sync",
+			"sync_id", sync.ID,
+			"invoice_id", sync.EntityID,
+			"error", updateErr,
+		)
+	}
+
+	return err
+}
+
+func (s *ledgerSyncService) pushDocument(ctx context.Context, sync *ledgerDomain.Sync) (string, error) {
+	conn, err := s.connectionRepo.Get(ctx, sync.ConnectionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get connection: %w", err)
+	}
+	if conn.LedgerSettings == nil {
+		return "", fmt.Errorf("connection has no ledger settings")
+	}
+
+	inv, err := s.invoiceRepo.Get(ctx, sync.EntityID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	cust, err := s.customerRepo.Get(ctx, inv.CustomerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	doc, err := ledger.BuildDocument(inv, cust, conn.LedgerSettings.FieldMapping)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := s.newClient(conn)
+	if err != nil {
+		return "", err
+	}
+
+	return client.Push(ctx, doc)
+}
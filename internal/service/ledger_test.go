@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/ledger"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLedgerClient fails the first push and records the documents
+type fakeLedgerClient struct {
+	mu   sync.Mutex
+	docs []*ledger.Document
+}
+
+func (c *fakeLedgerClient) Push(ctx context.Context, doc *ledger.Document) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.docs = append(c.docs, doc)
+	if len(c.docs) == 1 {
+		return "", errors.New("quickbooks responded with status 500")
+	}
+	return "qb_1", nil
+}
+
+func (c *fakeLedgerClient) pushes() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.docs)
+}
+
+func TestLedgerSyncService_SyncInvoice(t *testing.T) {
+	ctx := testutil.SetupContext()
+	manager := syncqueue.NewManager(&config.Configuration{
+		Integration: config.IntegrationConfig{SyncRatePerSecond: 100, SyncBackoffMillis: 1},
+	}, logger.GetLogger())
+	defer manager.Stop()
+
+	connectionStore := testutil.NewInMemoryConnectionStore()
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	connectionService := NewConnectionService(connectionStore, manager, nil, logger.GetLogger())
+
+	client := &fakeLedgerClient{}
+	svc := NewLedgerSyncService(testutil.NewInMemoryLedgerStore(), connectionStore, invoiceStore, customerStore,
+		connectionService, logger.GetLogger()).(*ledgerSyncService)
+	svc.newClient = func(*connection.Connection) (ledger.Client, error) { return client, nil }
+
+	conn, err := connectionService.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:     "QuickBooks",
+		Provider: types.IntegrationProviderQuickBooks,
+		LedgerSettings: &connection.LedgerSettings{
+			AccountID: "4620816365",
+			FieldMapping: connection.LedgerFieldMapping{
+				DefaultItemID: "1",
+				ItemIDs:       map[string]string{"price_api": "7"},
+			},
+		},
+		Credentials: "access-token",
+	})
+	require.NoError(t, err)
+
+	_, err = connectionService.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:     "NetSuite",
+		Provider: types.IntegrationProviderNetSuite,
+	})
+	assert.Error(t, err, "ledger connections require settings and credentials")
+
+	cust := &customer.Customer{ID: "cust_1", ExternalID: "qb_customer_1", Name: "Acme", BaseModel: types.GetDefaultBaseModel(ctx)}
+	require.NoError(t, customerStore.Create(ctx, cust))
+
+	number := "INV-0001"
+	now := time.Now().UTC()
+	inv := &invoice.Invoice{
+		ID:            "inv_1",
+		InvoiceNumber: &number,
+		CustomerID:    cust.ID,
+		InvoiceType:   types.InvoiceTypeCredit,
+		InvoiceStatus: types.InvoiceStatusFinalized,
+		Currency:      "USD",
+		Total:         decimal.NewFromInt(-30),
+		FinalizedAt:   &now,
+		LineItems: []*invoice.InvoiceLineItem{
+			{ID: "line_1", PriceID: "price_api", DisplayName: "API calls", Amount: decimal.NewFromInt(-20), Quantity: decimal.NewFromInt(1)},
+			{ID: "line_2", PriceID: "price_seats", DisplayName: "Seats", Amount: decimal.NewFromInt(-10), Quantity: decimal.NewFromInt(2)},
+		},
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}
+	require.NoError(t, invoiceStore.Create(ctx, inv))
+
+	require.NoError(t, svc.SyncInvoice(ctx, inv))
+
+	// The first push fails and is retried by the sync queue
+	var syncs *dto.ListLedgerSyncsResponse
+	require.Eventually(t, func() bool {
+		syncs, err = svc.ListInvoiceSyncs(ctx, inv.ID)
+		return err == nil && len(syncs.Syncs) == 1 && syncs.Syncs[0].SyncStatus == types.LedgerSyncStatusSynced
+	}, time.Second, time.Millisecond)
+
+	sync := syncs.Syncs[0]
+	assert.Equal(t, conn.ID, sync.ConnectionID)
+	assert.Equal(t, types.SyncEntityTypeCreditNote, sync.EntityType)
+	assert.Equal(t, "qb_1", sync.ExternalID)
+	assert.Equal(t, 2, sync.Attempts)
+	assert.Empty(t, sync.LastError)
+	assert.NotNil(t, sync.SyncedAt)
+
+	doc := client.docs[1]
+	assert.Equal(t, types.SyncEntityTypeCreditNote, doc.Type)
+	assert.Equal(t, "INV-0001", doc.Number)
+	assert.Equal(t, "qb_customer_1", doc.CustomerRef)
+	require.Len(t, doc.Lines, 2)
+	assert.Equal(t, "7", doc.Lines[0].ItemRef)
+	assert.True(t, decimal.NewFromInt(20).Equal(doc.Lines[0].Amount))
+	assert.Equal(t, "1", doc.Lines[1].ItemRef)
+
+	// A synced invoice is never pushed twice to the same ledger
+	_, err = svc.RetryInvoiceSync(ctx, inv.ID)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 2, client.pushes())
+
+	inv.InvoiceStatus = types.InvoiceStatusDraft
+	assert.Error(t, svc.SyncInvoice(ctx, inv))
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// ErrLegalEntityInUse is returned when a legal entity customers or active
+// subscriptions are assigned to is deleted
+var ErrLegalEntityInUse = errors.New("legal entity is assigned to customers or subscriptions")
+
+type LegalEntityService interface {
+	CreateLegalEntity(ctx context.Context, req dto.CreateLegalEntityRequest) (*dto.LegalEntityResponse, error)
+	GetLegalEntity(ctx context.Context, id string) (*dto.LegalEntityResponse, error)
+	ListLegalEntities(ctx context.Context) (*dto.ListLegalEntitiesResponse, error)
+	UpdateLegalEntity(ctx context.Context, id string, req dto.UpdateLegalEntityRequest) (*dto.LegalEntityResponse, error)
+	DeleteLegalEntity(ctx context.Context, id string) error
+}
+
+type legalEntityService struct {
+	repo           legalentity.Repository
+	db             postgres.TxManager
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
+}
+
+func NewLegalEntityService(
+	repo legalentity.Repository,
+	db postgres.TxManager,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) LegalEntityService {
+	return &legalEntityService{
+		repo:           repo,
+		db:             db,
+		auditPublisher: auditPublisher,
+		logger:         logger,
+	}
+}
+
+func (s *legalEntityService) CreateLegalEntity(ctx context.Context, req dto.CreateLegalEntityRequest) (*dto.LegalEntityResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	entity := req.ToLegalEntity(ctx)
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		if entity.IsDefault {
+			if err := s.unsetDefault(ctx, entity.ID); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.Create(ctx, entity); err != nil {
+			return fmt.Errorf("failed to create legal entity: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeLegalEntity, entity.ID, types.AuditActionCreate, nil, entity)
+	return &dto.LegalEntityResponse{LegalEntity: entity}, nil
+}
+
+func (s *legalEntityService) GetLegalEntity(ctx context.Context, id string) (*dto.LegalEntityResponse, error) {
+	entity, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legal entity: %w", err)
+	}
+	return &dto.LegalEntityResponse{LegalEntity: entity}, nil
+}
+
+func (s *legalEntityService) ListLegalEntities(ctx context.Context) (*dto.ListLegalEntitiesResponse, error) {
+	entities, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legal entities: %w", err)
+	}
+
+	response := &dto.ListLegalEntitiesResponse{
+		LegalEntities: make([]dto.LegalEntityResponse, len(entities)),
+	}
+	for i, entity := range entities {
+		response.LegalEntities[i] = dto.LegalEntityResponse{LegalEntity: entity}
+	}
+	return response, nil
+}
+
+func (s *legalEntityService) UpdateLegalEntity(ctx context.Context, id string, req dto.UpdateLegalEntityRequest) (*dto.LegalEntityResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	entity, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legal entity: %w", err)
+	}
+
+	before := *entity
+	req.ApplyTo(entity)
+	entity.UpdatedAt = time.Now().UTC()
+	entity.UpdatedBy = types.GetUserID(ctx)
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		if entity.IsDefault && !before.IsDefault {
+			if err := s.unsetDefault(ctx, entity.ID); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.Update(ctx, entity); err != nil {
+			return fmt.Errorf("failed to update legal entity: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeLegalEntity, entity.ID, types.AuditActionUpdate, &before, entity)
+	return &dto.LegalEntityResponse{LegalEntity: entity}, nil
+}
+
+// unsetDefault clears the default flag of the current default entity of the
+// tenant, unless it is the entity becoming the default
+func (s *legalEntityService) unsetDefault(ctx context.Context, id string) error {
+	current, err := s.repo.GetDefault(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get default legal entity: %w", err)
+	}
+	if current == nil || current.ID == id {
+		return nil
+	}
+
+	before := *current
+	current.IsDefault = false
+	current.UpdatedAt = time.Now().UTC()
+	current.UpdatedBy = types.GetUserID(ctx)
+	if err := s.repo.Update(ctx, current); err != nil {
+		return fmt.Errorf("failed to update legal entity: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeLegalEntity, current.ID, types.AuditActionUpdate, &before, current)
+	return nil
+}
+
+func (s *legalEntityService) DeleteLegalEntity(ctx context.Context, id string) error {
+	entity, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get legal entity: %w", err)
+	}
+
+	inUse, err := s.repo.InUse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrLegalEntityInUse
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete legal entity: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeLegalEntity, id, types.AuditActionDelete, entity, nil)
+	return nil
+}
+
+// checkLegalEntity fails when the legal entity a customer or a subscription is
+// assigned to does not exist. An empty id assigns none
+func checkLegalEntity(ctx context.Context, repo legalentity.Repository, id string) error {
+	if id == "" {
+		return nil
+	}
+	if repo == nil {
+		return fmt.Errorf("legal entities are not supported")
+	}
+	if _, err := repo.Get(ctx, id); err != nil {
+		return fmt.Errorf("invalid request: legal entity %s not found", id)
+	}
+	return nil
+}
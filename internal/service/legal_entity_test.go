@@ -0,0 +1,124 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalEntityInvoicing(t *testing.T) {
+	ctx := testutil.SetupContext()
+	entityStore := testutil.NewInMemoryLegalEntityStore()
+	entityService := NewLegalEntityService(entityStore, testutil.NewInMemoryTxManager(), nil, logger.GetLogger())
+
+	entityRequest := func(name, prefix string) dto.CreateLegalEntityRequest {
+		return dto.CreateLegalEntityRequest{
+			Name:             name,
+			Address:          &customer.Address{Line1: "1 Main St", City: "Springfield", Country: "US"},
+			InvoicePrefix:    prefix,
+			InvoiceSeparator: "-",
+			IsDefault:        true,
+			BankDetails:      &legalentity.BankDetails{AccountHolder: name, AccountNumber: "000123456789", RoutingNumber: "110000000"},
+		}
+	}
+
+	gmbh, err := entityService.CreateLegalEntity(ctx, entityRequest("Acme GmbH", "DE"))
+	require.NoError(t, err)
+	inc, err := entityService.CreateLegalEntity(ctx, entityRequest("Acme Inc", "US"))
+	require.NoError(t, err)
+
+	// The tenant has a single default entity
+	gmbhEntity, err := entityStore.Get(ctx, gmbh.ID)
+	require.NoError(t, err)
+	assert.False(t, gmbhEntity.IsDefault)
+	assert.Equal(t, 5, gmbhEntity.InvoicePadding)
+	def, err := entityStore.GetDefault(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, inc.ID, def.ID)
+
+	invalid := entityRequest("Acme Ltd", "UK")
+	invalid.BankDetails = &legalentity.BankDetails{AccountHolder: "Acme Ltd"}
+	_, err = entityService.CreateLegalEntity(ctx, invalid)
+	assert.Error(t, err, "bank details need an account")
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	customerService := NewCustomerService(customerStore, entityStore, nil, nil, logger.GetLogger())
+	_, err = customerService.CreateCustomer(ctx, dto.CreateCustomerRequest{ExternalID: "unknown", LegalEntityID: "le_missing"})
+	assert.Error(t, err)
+
+	german, err := customerService.CreateCustomer(ctx, dto.CreateCustomerRequest{ExternalID: "german", LegalEntityID: gmbh.ID})
+	require.NoError(t, err)
+	plain, err := customerService.CreateCustomer(ctx, dto.CreateCustomerRequest{ExternalID: "plain"})
+	require.NoError(t, err)
+
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	svc := NewInvoiceService(
+		testutil.NewInMemoryInvoiceStore(), testutil.NewInMemorySequenceStore(), subscriptionStore,
+		testutil.NewInMemoryPlanStore(), testutil.NewInMemoryPriceStore(),
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(),
+		testutil.NewInMemoryRateCardStore(), testutil.NewInMemoryPriceBookStore(), entityStore,
+		testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, nil,
+		NewSubscriptionReader(subscriptionStore, testutil.NewInMemoryPlanStore(), testutil.NewInMemoryPriceStore(),
+			testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(), testutil.NewInMemoryMeterStore(), customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	hundred := decimal.NewFromInt(100)
+	issue := func(customerID string) *dto.InvoiceResponse {
+		resp, err := svc.CreateOneOffInvoice(ctx, dto.CreateOneOffInvoiceRequest{
+			CustomerID: customerID,
+			Currency:   "usd",
+			LineItems:  []dto.OneOffInvoiceLineItemRequest{{DisplayName: "Onboarding", UnitAmount: &hundred}},
+			Finalize:   true,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("invoices are numbered from the sequence of their entity", func(t *testing.T) {
+		first := issue(german.ID)
+		assert.Equal(t, gmbh.ID, first.LegalEntityID)
+		assert.Equal(t, "DE-00001", *first.InvoiceNumber)
+
+		// Customers not assigned to an entity are invoiced from the default one
+		other := issue(plain.ID)
+		assert.Equal(t, inc.ID, other.LegalEntityID)
+		assert.Equal(t, "US-00001", *other.InvoiceNumber)
+
+		second := issue(german.ID)
+		assert.Equal(t, "DE-00002", *second.InvoiceNumber)
+	})
+
+	t.Run("subscription entity overrides the customer entity", func(t *testing.T) {
+		sub := &subscription.Subscription{
+			ID:            "sub_1",
+			CustomerID:    plain.ID,
+			Currency:      "usd",
+			LegalEntityID: gmbh.ID,
+			BaseModel:     types.GetDefaultBaseModel(ctx),
+		}
+		require.NoError(t, subscriptionStore.Create(ctx, sub))
+
+		inv := &invoice.Invoice{CustomerID: plain.ID, SubscriptionID: sub.ID}
+		require.NoError(t, svc.(*invoiceService).assignLegalEntity(ctx, inv))
+		assert.Equal(t, gmbh.ID, inv.LegalEntityID)
+	})
+}
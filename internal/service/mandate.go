@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/mandate"
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// ErrMandateNotFound is returned when the mandate is not one of the customer
+var ErrMandateNotFound = errors.New("mandate not found")
+
+type MandateService interface {
+	// CreateMandate records the direct debit mandate a customer signed for one of
+	// their bank account payment methods. A payment method has at most one
+	// active mandate
+	CreateMandate(ctx context.Context, customerID string, req dto.CreateMandateRequest) (*dto.MandateResponse, error)
+
+	ListMandates(ctx context.Context, customerID string) (*dto.ListMandatesResponse, error)
+
+	// RevokeMandate revokes an active mandate, its scheduled debits fail when
+	// they are due
+	RevokeMandate(ctx context.Context, customerID, id string, req dto.RevokeMandateRequest) (*dto.MandateResponse, error)
+}
+
+type mandateService struct {
+	repo              mandate.Repository
+	customerRepo      customer.Repository
+	paymentMethodRepo paymentmethod.Repository
+	auditPublisher    audit.Publisher
+	logger            *logger.Logger
+}
+
+func NewMandateService(
+	repo mandate.Repository,
+	customerRepo customer.Repository,
+	paymentMethodRepo paymentmethod.Repository,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) MandateService {
+	return &mandateService{
+		repo:              repo,
+		customerRepo:      customerRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		auditPublisher:    auditPublisher,
+		logger:            logger,
+	}
+}
+
+func (s *mandateService) CreateMandate(ctx context.Context, customerID string, req dto.CreateMandateRequest) (*dto.MandateResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	pm, err := s.paymentMethodRepo.Get(ctx, req.PaymentMethodID)
+	if err != nil || pm.CustomerID != cust.ID {
+		return nil, ErrPaymentMethodNotFound
+	}
+
+	existing, err := s.repo.ListByCustomer(ctx, cust.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mandates: %w", err)
+	}
+	for _, m := range existing {
+		if m.PaymentMethodID == pm.ID && m.MandateStatus == types.MandateStatusActive {
+			return nil, fmt.Errorf("invalid request: payment method already has the active mandate %s", m.ID)
+		}
+	}
+
+	signedAt := time.Now().UTC()
+	if req.SignedAt != nil {
+		signedAt = req.SignedAt.UTC()
+	}
+	preNotificationDays := req.Scheme.PreNotificationDays()
+	if req.PreNotificationDays != nil {
+		preNotificationDays = *req.PreNotificationDays
+	}
+
+	m := &mandate.Mandate{
+		ID:                  types.GenerateUUID(),
+		CustomerID:          cust.ID,
+		PaymentMethodID:     pm.ID,
+		Scheme:              req.Scheme,
+		Reference:           req.Reference,
+		MandateStatus:       types.MandateStatusActive,
+		PreNotificationDays: preNotificationDays,
+		SignedAt:            signedAt,
+		BaseModel:           types.GetDefaultBaseModel(ctx),
+	}
+
+	if err := s.repo.Create(ctx, m); err != nil {
+		return nil, fmt.Errorf("failed to create mandate: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeMandate, m.ID, types.AuditActionCreate, nil, m)
+
+	return &dto.MandateResponse{Mandate: m}, nil
+}
+
+func (s *mandateService) ListMandates(ctx context.Context, customerID string) (*dto.ListMandatesResponse, error) {
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	mandates, err := s.repo.ListByCustomer(ctx, cust.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mandates: %w", err)
+	}
+
+	resp := &dto.ListMandatesResponse{Mandates: make([]dto.MandateResponse, 0, len(mandates))}
+	for _, m := range mandates {
+		resp.Mandates = append(resp.Mandates, dto.MandateResponse{Mandate: m})
+	}
+	return resp, nil
+}
+
+func (s *mandateService) RevokeMandate(ctx context.Context, customerID, id string, req dto.RevokeMandateRequest) (*dto.MandateResponse, error) {
+	m, err := s.repo.Get(ctx, id)
+	if err != nil || m.CustomerID != customerID {
+		return nil, ErrMandateNotFound
+	}
+	if m.MandateStatus != types.MandateStatusActive {
+		return nil, fmt.Errorf("invalid request: mandate is %s", m.MandateStatus)
+	}
+
+	if err := revokeMandate(ctx, s.repo, s.auditPublisher, m, types.MandateStatusRevoked, req.Reason); err != nil {
+		return nil, err
+	}
+	return &dto.MandateResponse{Mandate: m}, nil
+}
+
+// revokeMandate ends an active mandate with the status, revoked or failed
+func revokeMandate(ctx context.Context, repo mandate.Repository, auditPublisher audit.Publisher, m *mandate.Mandate, status types.MandateStatus, reason string) error {
+	before := *m
+	now := time.Now().UTC()
+	m.MandateStatus = status
+	m.RevokedAt = &now
+	m.RevocationReason = reason
+	m.UpdatedAt = now
+	m.UpdatedBy = types.GetUserID(ctx)
+	if err := repo.Update(ctx, m); err != nil {
+		return fmt.Errorf("failed to update mandate: %w", err)
+	}
+
+	recordAudit(ctx, auditPublisher, types.AuditEntityTypeMandate, m.ID, types.AuditActionUpdate, &before, m)
+	return nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+type MarginService interface {
+	// GetMarginReport reports the revenue of the charges of the finalized
+	// invoices of the period against their cost, the quantity billed times the
+	// unit cost of their price, by customer, plan or meter
+	GetMarginReport(ctx context.Context, filter *types.MarginFilter) (*dto.MarginReportResponse, error)
+}
+
+type marginService struct {
+	invoiceRepo invoice.Repository
+	priceRepo   price.Repository
+	logger      *logger.Logger
+}
+
+func NewMarginService(invoiceRepo invoice.Repository, priceRepo price.Repository, logger *logger.Logger) MarginService {
+	return &marginService{
+		invoiceRepo: invoiceRepo,
+		priceRepo:   priceRepo,
+		logger:      logger,
+	}
+}
+
+type marginKey struct {
+	id       string
+	currency string
+}
+
+func (s *marginService) GetMarginReport(ctx context.Context, filter *types.MarginFilter) (*dto.MarginReportResponse, error) {
+	if !filter.EndTime.After(filter.StartTime) {
+		return nil, fmt.Errorf("end_time must be after start_time")
+	}
+
+	items, err := s.invoiceRepo.ListFinalizedLineItems(ctx, filter.StartTime, filter.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoiced charges: %w", err)
+	}
+
+	prices := make(map[string]*price.Price)
+	groups := make(map[marginKey]*dto.MarginGroup)
+	for _, item := range items {
+		var p *price.Price
+		if item.PriceID != "" {
+			p = s.getPrice(ctx, prices, item.PriceID)
+		}
+
+		var id string
+		switch filter.GroupBy {
+		case types.MarginGroupByCustomer:
+			id = item.CustomerID
+		case types.MarginGroupByPlan:
+			if p != nil {
+				id = p.PlanID
+			}
+		case types.MarginGroupByMeter:
+			if item.MeterID == "" {
+				continue
+			}
+			id = item.MeterID
+		default:
+			return nil, fmt.Errorf("invalid group_by: %s", filter.GroupBy)
+		}
+
+		key := marginKey{id: id, currency: item.Currency}
+		group, ok := groups[key]
+		if !ok {
+			group = &dto.MarginGroup{ID: id, Currency: item.Currency}
+			groups[key] = group
+		}
+
+		group.Revenue = group.Revenue.Add(item.Amount)
+		if p == nil || p.UnitCost == nil {
+			group.UncostedRevenue = group.UncostedRevenue.Add(item.Amount)
+			continue
+		}
+		group.Cost = group.Cost.Add(item.Quantity.Mul(*p.UnitCost))
+	}
+
+	response := &dto.MarginReportResponse{
+		GroupBy:   filter.GroupBy,
+		StartTime: filter.StartTime,
+		EndTime:   filter.EndTime,
+		Groups:    make([]dto.MarginGroup, 0, len(groups)),
+	}
+	for _, group := range groups {
+		precision := types.GetCurrencyConfig(group.Currency).Precision
+		group.Cost = group.Cost.Round(precision)
+		group.GrossMargin = group.Revenue.Sub(group.Cost)
+		if !group.Revenue.IsZero() {
+			percent := group.GrossMargin.Div(group.Revenue).Mul(decimal.NewFromInt(100)).Round(2)
+			group.GrossMarginPercent = &percent
+		}
+		response.Groups = append(response.Groups, *group)
+	}
+
+	sort.Slice(response.Groups, func(i, j int) bool {
+		if response.Groups[i].ID == response.Groups[j].ID {
+			return response.Groups[i].Currency < response.Groups[j].Currency
+		}
+		return response.Groups[i].ID < response.Groups[j].ID
+	})
+
+	return response, nil
+}
+
+// getPrice returns the price of a charge, nil when it was deleted since
+func (s *marginService) getPrice(ctx context.Context, prices map[string]*price.Price, id string) *price.Price {
+	if p, ok := prices[id]; ok {
+		return p
+	}
+
+	p, err := s.priceRepo.Get(ctx, id)
+	if err != nil {
+		s.logger.Debugw("price of invoiced charge not found, its revenue is uncosted",
+			"price_id", id,
+			"error", err,
+		)
+		p = nil
+	}
+	prices[id] = p
+	return p
+}
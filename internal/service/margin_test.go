@@ -0,0 +1,135 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarginService_GetMarginReport(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	cost := func(v string) *decimal.Decimal {
+		d := decimal.RequireFromString(v)
+		return &d
+	}
+
+	priceStore := testutil.NewInMemoryPriceStore()
+	for _, p := range []*price.Price{
+		{ID: "price_platform", PlanID: "plan_pro", Type: types.PRICE_TYPE_FIXED, UnitCost: cost("5")},
+		{ID: "price_api_calls", PlanID: "plan_pro", Type: types.PRICE_TYPE_USAGE, MeterID: "meter_api_calls", UnitCost: cost("0.002")},
+		{ID: "price_storage", PlanID: "plan_basic", Type: types.PRICE_TYPE_USAGE, MeterID: "meter_storage"},
+	} {
+		p.Currency = "usd"
+		p.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, priceStore.Create(ctx, p))
+	}
+
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	newInvoice := func(id, customerID string, status types.InvoiceStatus, periodStart time.Time, items ...*invoice.InvoiceLineItem) {
+		for i, item := range items {
+			item.ID = id + "_" + string(rune('a'+i))
+			item.InvoiceID = id
+			item.CustomerID = customerID
+			item.Currency = "usd"
+		}
+		require.NoError(t, invoiceStore.Create(ctx, &invoice.Invoice{
+			ID:            id,
+			CustomerID:    customerID,
+			InvoiceStatus: status,
+			Currency:      "usd",
+			PeriodStart:   &periodStart,
+			LineItems:     items,
+			BaseModel:     types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	newInvoice("inv_1", "cust_a", types.InvoiceStatusFinalized, march,
+		&invoice.InvoiceLineItem{PriceID: "price_platform", Amount: decimal.NewFromInt(20), Quantity: decimal.NewFromInt(1)},
+		&invoice.InvoiceLineItem{PriceID: "price_api_calls", MeterID: "meter_api_calls", Amount: decimal.NewFromInt(10), Quantity: decimal.NewFromInt(1000)},
+	)
+	newInvoice("inv_2", "cust_b", types.InvoiceStatusFinalized, march.AddDate(0, 0, 10),
+		&invoice.InvoiceLineItem{PriceID: "price_storage", MeterID: "meter_storage", Amount: decimal.NewFromInt(8), Quantity: decimal.NewFromInt(80)},
+		&invoice.InvoiceLineItem{DisplayName: "Goodwill credit", Amount: decimal.NewFromInt(-3)},
+	)
+	// Drafts and charges of other periods are not reported
+	newInvoice("inv_3", "cust_a", types.InvoiceStatusDraft, march,
+		&invoice.InvoiceLineItem{PriceID: "price_platform", Amount: decimal.NewFromInt(20), Quantity: decimal.NewFromInt(1)},
+	)
+	newInvoice("inv_4", "cust_a", types.InvoiceStatusFinalized, march.AddDate(0, 1, 0),
+		&invoice.InvoiceLineItem{PriceID: "price_platform", Amount: decimal.NewFromInt(20), Quantity: decimal.NewFromInt(1)},
+	)
+
+	svc := NewMarginService(invoiceStore, priceStore, logger.GetLogger())
+
+	type group struct {
+		id       string
+		revenue  string
+		cost     string
+		margin   string
+		percent  string
+		uncosted string
+	}
+
+	tests := []struct {
+		groupBy types.MarginGroupBy
+		want    []group
+	}{
+		{
+			groupBy: types.MarginGroupByCustomer,
+			want: []group{
+				{id: "cust_a", revenue: "30", cost: "7", margin: "23", percent: "76.67", uncosted: "0"},
+				{id: "cust_b", revenue: "5", cost: "0", margin: "5", percent: "100", uncosted: "5"},
+			},
+		},
+		{
+			groupBy: types.MarginGroupByPlan,
+			want: []group{
+				// The credit is billed by no price and so belongs to no plan
+				{id: "", revenue: "-3", cost: "0", margin: "-3", percent: "100", uncosted: "-3"},
+				{id: "plan_basic", revenue: "8", cost: "0", margin: "8", percent: "100", uncosted: "8"},
+				{id: "plan_pro", revenue: "30", cost: "7", margin: "23", percent: "76.67", uncosted: "0"},
+			},
+		},
+		{
+			groupBy: types.MarginGroupByMeter,
+			want: []group{
+				{id: "meter_api_calls", revenue: "10", cost: "2", margin: "8", percent: "80", uncosted: "0"},
+				{id: "meter_storage", revenue: "8", cost: "0", margin: "8", percent: "100", uncosted: "8"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.groupBy), func(t *testing.T) {
+			resp, err := svc.GetMarginReport(ctx, &types.MarginFilter{
+				GroupBy:   tt.groupBy,
+				StartTime: march,
+				EndTime:   march.AddDate(0, 1, 0),
+			})
+			require.NoError(t, err)
+
+			require.Len(t, resp.Groups, len(tt.want))
+			for i, want := range tt.want {
+				got := resp.Groups[i]
+				assert.Equal(t, want.id, got.ID)
+				assert.Equal(t, "usd", got.Currency)
+				assert.True(t, decimal.RequireFromString(want.revenue).Equal(got.Revenue), "%s revenue %s", want.id, got.Revenue)
+				assert.True(t, decimal.RequireFromString(want.cost).Equal(got.Cost), "%s cost %s", want.id, got.Cost)
+				assert.True(t, decimal.RequireFromString(want.margin).Equal(got.GrossMargin), "%s margin %s", want.id, got.GrossMargin)
+				require.NotNil(t, got.GrossMarginPercent)
+				assert.True(t, decimal.RequireFromString(want.percent).Equal(*got.GrossMarginPercent), "%s percent %s", want.id, got.GrossMarginPercent)
+				assert.True(t, decimal.RequireFromString(want.uncosted).Equal(got.UncostedRevenue), "%s uncosted %s", want.id, got.UncostedRevenue)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
 	"github.com/flexprice/flexprice/internal/domain/meter"
 	"github.com/flexprice/flexprice/internal/types"
 )
@@ -17,11 +18,12 @@ type MeterService interface {
 }
 
 type meterService struct {
-	meterRepo meter.Repository
+	meterRepo      meter.Repository
+	auditPublisher audit.Publisher
 }
 
-func NewMeterService(meterRepo meter.Repository) MeterService {
-	return &meterService{meterRepo: meterRepo}
+func NewMeterService(meterRepo meter.Repository, auditPublisher audit.Publisher) MeterService {
+	return &meterService{meterRepo: meterRepo, auditPublisher: auditPublisher}
 }
 
 func (s *meterService) CreateMeter(ctx context.Context, req *dto.CreateMeterRequest) (*meter.Meter, error) {
@@ -29,7 +31,7 @@ func (s *meterService) CreateMeter(ctx context.Context, req *dto.CreateMeterRequ
 		return nil, fmt.Errorf("meter cannot be nil")
 	}
 
-	if req.EventName == "" {
+	if req.EventName == "" && req.Formula == nil {
 		return nil, fmt.Errorf("event_name is required")
 	}
 
@@ -38,11 +40,18 @@ func (s *meterService) CreateMeter(ctx context.Context, req *dto.CreateMeterRequ
 	if err := meter.Validate(); err != nil {
 		return nil, fmt.Errorf("validate meter: %w", err)
 	}
+	if meter.IsDerived() {
+		if err := s.validateFormulaMeters(ctx, meter.Formula); err != nil {
+			return nil, fmt.Errorf("validate meter: %w", err)
+		}
+	}
 
 	if err := s.meterRepo.CreateMeter(ctx, meter); err != nil {
 		return nil, fmt.Errorf("create meter: %w", err)
 	}
 
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeMeter, meter.ID, types.AuditActionCreate, nil, meter)
+
 	return meter, nil
 }
 
@@ -61,5 +70,34 @@ func (s *meterService) DisableMeter(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("id is required")
 	}
-	return s.meterRepo.DisableMeter(ctx, id)
+
+	meter, err := s.meterRepo.GetMeter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.meterRepo.DisableMeter(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeMeter, id, types.AuditActionDelete, meter, nil)
+	return nil
+}
+
+// validateFormulaMeters checks that the meters of a formula are published event
+// meters. Derived meters can not be nested, which also rules out cycles
+func (s *meterService) validateFormulaMeters(ctx context.Context, formula *meter.Formula) error {
+	for _, id := range formula.MeterIDs() {
+		m, err := s.meterRepo.GetMeter(ctx, id)
+		if err != nil {
+			return fmt.Errorf("meter %s of the formula not found: %w", id, err)
+		}
+		if m.Status != types.StatusPublished {
+			return fmt.Errorf("meter %s of the formula is not published", id)
+		}
+		if m.IsDerived() {
+			return fmt.Errorf("meter %s of the formula is a derived meter", id)
+		}
+	}
+	return nil
 }
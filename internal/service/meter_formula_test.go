@@ -0,0 +1,134 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivedMeterUsage(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	eventStore := testutil.NewInMemoryEventStore()
+	meterStore := testutil.NewInMemoryMeterStore()
+	meterService := NewMeterService(meterStore, nil)
+	eventService := NewEventService(config.GetDefaultConfig(), nil, eventStore, meterStore, nil, nil, nil, nil, nil, logger.GetLogger())
+
+	gpu, err := meterService.CreateMeter(ctx, &dto.CreateMeterRequest{
+		Name:        "GPU seconds",
+		EventName:   "gpu_usage",
+		Aggregation: meter.Aggregation{Type: types.AggregationSum, Field: "seconds"},
+	})
+	require.NoError(t, err)
+	cpu, err := meterService.CreateMeter(ctx, &dto.CreateMeterRequest{
+		Name:        "CPU seconds",
+		EventName:   "cpu_usage",
+		Aggregation: meter.Aggregation{Type: types.AggregationSum, Field: "seconds"},
+	})
+	require.NoError(t, err)
+
+	costUnits, err := meterService.CreateMeter(ctx, &dto.CreateMeterRequest{
+		Name: "Cost units",
+		Formula: &meter.Formula{
+			Expression: "3 * gpu_seconds + 0.5 * cpu_seconds",
+			Variables:  map[string]string{"gpu_seconds": gpu.ID, "cpu_seconds": cpu.ID},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.AggregationFormula, costUnits.Aggregation.Type)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	ingest := func(id, eventName string, ts time.Time, seconds float64, region string) {
+		require.NoError(t, eventStore.InsertEvent(ctx, events.NewEvent(eventName, types.GetTenantID(ctx), "acme",
+			map[string]interface{}{"seconds": seconds, "region": region}, ts, id, "", "")))
+	}
+	ingest("evt_gpu_1", "gpu_usage", start.Add(time.Hour), 10, "us")
+	ingest("evt_gpu_2", "gpu_usage", start.Add(26*time.Hour), 2, "eu")
+	ingest("evt_cpu_1", "cpu_usage", start.Add(2*time.Hour), 40, "us")
+
+	t.Run("evaluates the formula over the usage of its meters", func(t *testing.T) {
+		result, err := eventService.GetUsageByMeter(ctx, &dto.GetUsageByMeterRequest{
+			MeterID:            costUnits.ID,
+			ExternalCustomerID: "acme",
+			StartTime:          start,
+			EndTime:            start.AddDate(0, 1, 0),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, types.AggregationFormula, result.Type)
+		assert.True(t, decimal.NewFromInt(56).Equal(result.Value), "usage %s", result.Value)
+	})
+
+	t.Run("evaluates the formula per window", func(t *testing.T) {
+		day1, day2 := start, start.AddDate(0, 0, 1)
+		usage := map[string]*events.AggregationResult{
+			gpu.ID: {Value: decimal.NewFromInt(12), Results: []events.UsageResult{
+				{WindowSize: day1, Value: decimal.NewFromInt(10)},
+				{WindowSize: day2, Value: decimal.NewFromInt(2)},
+			}},
+			cpu.ID: {Value: decimal.NewFromInt(40), Results: []events.UsageResult{
+				{WindowSize: day1, Value: decimal.NewFromInt(40)},
+			}},
+		}
+
+		results, err := getDerivedUsage(costUnits, func(meterID string) ([]*events.AggregationResult, error) {
+			return []*events.AggregationResult{usage[meterID]}, nil
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Len(t, results[0].Results, 2)
+		assert.True(t, decimal.NewFromInt(56).Equal(results[0].Value))
+		assert.Equal(t, day1, results[0].Results[0].WindowSize)
+		assert.True(t, decimal.NewFromInt(50).Equal(results[0].Results[0].Value), "day 1 %s", results[0].Results[0].Value)
+		assert.True(t, decimal.NewFromInt(6).Equal(results[0].Results[1].Value), "day 2 %s", results[0].Results[1].Value)
+	})
+
+	t.Run("evaluates the formula per price filter group", func(t *testing.T) {
+		results, err := eventService.GetUsageByMeterWithFilters(ctx, &dto.GetUsageByMeterRequest{
+			MeterID:            costUnits.ID,
+			ExternalCustomerID: "acme",
+			StartTime:          start,
+			EndTime:            start.AddDate(0, 1, 0),
+		}, map[string]map[string][]string{
+			"price_us": {"region": {"us"}},
+			"price_eu": {"region": {"eu"}},
+		})
+		require.NoError(t, err)
+
+		usage := make(map[string]decimal.Decimal)
+		for _, r := range results {
+			usage[r.Metadata["filter_group_id"]] = r.Value
+		}
+		assert.True(t, decimal.NewFromInt(50).Equal(usage["price_us"]), "us %s", usage["price_us"])
+		assert.True(t, decimal.NewFromInt(6).Equal(usage["price_eu"]), "eu %s", usage["price_eu"])
+	})
+
+	t.Run("rejects invalid derived meters", func(t *testing.T) {
+		_, err := meterService.CreateMeter(ctx, &dto.CreateMeterRequest{
+			Name:    "Nested",
+			Formula: &meter.Formula{Expression: "2 * cost_units", Variables: map[string]string{"cost_units": costUnits.ID}},
+		})
+		assert.Error(t, err, "derived meters can not be nested")
+
+		_, err = meterService.CreateMeter(ctx, &dto.CreateMeterRequest{
+			Name:    "Unknown",
+			Formula: &meter.Formula{Expression: "2 * gpu", Variables: map[string]string{"gpu": "meter_unknown"}},
+		})
+		assert.Error(t, err, "meters of the formula must exist")
+
+		_, err = meterService.CreateMeter(ctx, &dto.CreateMeterRequest{
+			Name:    "Broken",
+			Formula: &meter.Formula{Expression: "3 * (gpu", Variables: map[string]string{"gpu": gpu.ID}},
+		})
+		assert.Error(t, err, "formula must parse")
+	})
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentileUsage(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	eventStore := testutil.NewInMemoryEventStore()
+	meterStore := testutil.NewInMemoryMeterStore()
+	rollupStore := testutil.NewInMemoryRollupStore(eventStore)
+
+	meterService := NewMeterService(meterStore, nil)
+	eventService := NewEventService(config.GetDefaultConfig(), nil, eventStore, meterStore, rollupStore, nil, nil, nil, nil, logger.GetLogger())
+	rollups := NewUsageRollupService(&config.Configuration{UsageRollup: config.UsageRollupConfig{LookbackHours: 72}},
+		rollupStore, eventStore, meterStore, logger.GetLogger())
+
+	m, err := meterService.CreateMeter(ctx, &dto.CreateMeterRequest{
+		Name:        "p99 latency",
+		EventName:   "api_request",
+		Aggregation: meter.Aggregation{Type: types.AggregationPercentile, Field: "latency_ms", Percentile: 99},
+	})
+	require.NoError(t, err)
+
+	_, err = meterService.CreateMeter(ctx, &dto.CreateMeterRequest{
+		Name:        "Latency",
+		EventName:   "api_request",
+		Aggregation: meter.Aggregation{Type: types.AggregationPercentile, Field: "latency_ms"},
+	})
+	assert.Error(t, err)
+
+	now := time.Now().UTC()
+	ingest := func(id string, ts time.Time, latency float64) *events.Event {
+		event := events.NewEvent("api_request", types.GetTenantID(ctx), "acme",
+			map[string]interface{}{"latency_ms": latency}, ts, id, "", "")
+		require.NoError(t, eventStore.InsertEvent(ctx, event))
+		return event
+	}
+	for i := 1; i <= 10; i++ {
+		ingest(fmt.Sprintf("evt_%d", i), now.Add(-time.Duration(i)*time.Hour), float64(i*10))
+	}
+	outlier := ingest("evt_outlier", now.Add(-5*time.Hour), 1000)
+	require.NoError(t, rollups.RollUpUsage(ctx, now))
+
+	usage := func() decimal.Decimal {
+		result, err := eventService.GetUsageByMeter(ctx, &dto.GetUsageByMeterRequest{
+			MeterID:            m.ID,
+			ExternalCustomerID: "acme",
+			StartTime:          now.Add(-24 * time.Hour),
+			EndTime:            now.Add(time.Hour),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, types.AggregationPercentile, result.Type)
+		return result.Value
+	}
+
+	t.Run("reads the percentile of the raw events", func(t *testing.T) {
+		assert.True(t, decimal.NewFromInt(1000).Equal(usage()), "usage %s", usage())
+	})
+
+	t.Run("leaves voided events out", func(t *testing.T) {
+		require.NoError(t, eventStore.InsertEvent(ctx, outlier.Reversal(outlier.ID)))
+		assert.True(t, decimal.NewFromInt(100).Equal(usage()), "usage %s", usage())
+	})
+}
@@ -25,7 +25,7 @@ func TestMeterService(t *testing.T) {
 func (s *MeterServiceSuite) SetupTest() {
 	s.ctx = testutil.SetupContext()
 	s.store = testutil.NewInMemoryMeterStore()
-	s.service = NewMeterService(s.store)
+	s.service = NewMeterService(s.store, nil)
 }
 
 func (s *MeterServiceSuite) TestCreateMeter() {
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountUniqueUsage(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	eventStore := testutil.NewInMemoryEventStore()
+	meterStore := testutil.NewInMemoryMeterStore()
+	rollupStore := testutil.NewInMemoryRollupStore(eventStore)
+
+	eventService := NewEventService(config.GetDefaultConfig(), nil, eventStore, meterStore, rollupStore, nil, nil, nil, nil, logger.GetLogger())
+	rawEventService := NewEventService(config.GetDefaultConfig(), nil, eventStore, meterStore, nil, nil, nil, nil, nil, logger.GetLogger())
+	rollups := NewUsageRollupService(&config.Configuration{UsageRollup: config.UsageRollupConfig{LookbackHours: 72}},
+		rollupStore, eventStore, meterStore, logger.GetLogger())
+
+	m := meter.NewMeter("Monthly active devices", types.GetTenantID(ctx), types.GetUserID(ctx))
+	m.ID = "meter_devices"
+	m.EventName = "app_open"
+	m.Aggregation = meter.Aggregation{Type: types.AggregationCountUnique, Field: "device_id", Precision: 14}
+	require.NoError(t, meterStore.CreateMeter(ctx, m))
+
+	now := time.Now().UTC()
+	ingest := func(id, customerID string, ts time.Time, deviceID string) *events.Event {
+		event := events.NewEvent("app_open", types.GetTenantID(ctx), customerID,
+			map[string]interface{}{"device_id": deviceID}, ts, id, "", "")
+		require.NoError(t, eventStore.InsertEvent(ctx, event))
+		return event
+	}
+	ingest("evt_1", "acme", now.Add(-50*time.Hour), "device_1")
+	ingest("evt_2", "acme", now.Add(-50*time.Hour), "device_2")
+	ingest("evt_3", "acme", now.Add(-30*time.Hour), "device_1")
+	voided := ingest("evt_4", "acme", now.Add(-30*time.Hour), "device_3")
+	ingest("evt_5", "acme", now.Add(-2*time.Hour), "device_1")
+	ingest("evt_6", "globex", now.Add(-2*time.Hour), "device_9")
+	require.NoError(t, rollups.RollUpUsage(ctx, now))
+
+	// Values of the last hour are read from the raw events and merged with the
+	// rolled up windows
+	ingest("evt_7", "acme", now, "device_4")
+
+	usage := func(svc EventService) decimal.Decimal {
+		result, err := svc.GetUsageByMeter(ctx, &dto.GetUsageByMeterRequest{
+			MeterID:            m.ID,
+			ExternalCustomerID: "acme",
+			StartTime:          now.Add(-72 * time.Hour),
+			EndTime:            now.Add(time.Hour),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, types.AggregationCountUnique, result.Type)
+		return result.Value
+	}
+
+	t.Run("counts values seen in several windows once", func(t *testing.T) {
+		assert.True(t, decimal.NewFromInt(4).Equal(usage(eventService)), "usage %s", usage(eventService))
+		assert.True(t, decimal.NewFromInt(4).Equal(usage(rawEventService)), "usage %s", usage(rawEventService))
+	})
+
+	t.Run("does not count the values of voided events", func(t *testing.T) {
+		require.NoError(t, eventStore.InsertEvent(ctx, voided.Reversal(voided.ID)))
+		require.NoError(t, rollups.RollUpUsage(ctx, now))
+
+		assert.True(t, decimal.NewFromInt(3).Equal(usage(eventService)), "usage %s", usage(eventService))
+		assert.True(t, decimal.NewFromInt(3).Equal(usage(rawEventService)), "usage %s", usage(rawEventService))
+	})
+
+	t.Run("is not versioned", func(t *testing.T) {
+		svc := NewMeterVersionService(meterStore, rollupStore, audit.NewPublisher(testutil.NewInMemoryAuditLogStore(), logger.GetLogger()), logger.GetLogger())
+		_, err := svc.CreateVersion(ctx, m.ID, dto.CreateMeterVersionRequest{
+			Aggregation: meter.Aggregation{Type: types.AggregationCountUnique, Field: "user_id"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidMeterVersion)
+	})
+}
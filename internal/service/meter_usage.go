@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// segmentReader reads the usage of a segment of a meter with the given
+// aggregation, which is the aggregation of the segment unless an average is
+// split into its sum and count
+type segmentReader func(segment meter.Segment, aggregation types.AggregationType) ([]*events.AggregationResult, error)
+
+// getMeterSegments splits [start, end) by the versions of the meter
+func getMeterSegments(ctx context.Context, meterRepo meter.Repository, m *meter.Meter, start, end time.Time) ([]meter.Segment, error) {
+	versions, err := meterRepo.ListVersions(ctx, m.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meter versions: %w", err)
+	}
+	return meter.Segments(m, versions, start, end), nil
+}
+
+// getSegmentedUsage reads the usage of each segment and adds up the results of
+// the same filter group and window. Averages do not add up, so an average
+// spanning several segments is the sum over all of them divided by the count
+func getSegmentedUsage(segments []meter.Segment, read segmentReader) ([]*events.AggregationResult, error) {
+	last := segments[len(segments)-1]
+	if len(segments) == 1 {
+		return read(last, last.Aggregation.Type)
+	}
+
+	if last.Aggregation.Type != types.AggregationAvg {
+		var combined usageSum
+		for _, segment := range segments {
+			results, err := read(segment, segment.Aggregation.Type)
+			if err != nil {
+				return nil, err
+			}
+			combined.add(results)
+		}
+		return combined.results(last.Aggregation.Type), nil
+	}
+
+	var sums, counts usageSum
+	for _, segment := range segments {
+		results, err := read(segment, types.AggregationSum)
+		if err != nil {
+			return nil, err
+		}
+		sums.add(results)
+
+		if results, err = read(segment, types.AggregationCount); err != nil {
+			return nil, err
+		}
+		counts.add(results)
+	}
+
+	averages := sums.results(types.AggregationAvg)
+	for _, result := range averages {
+		count, ok := counts.groups[filterGroupID(result)]
+		if !ok {
+			count = &groupUsage{}
+		}
+		result.Value = divideUsage(result.Value, count.value)
+		for i, window := range result.Results {
+			result.Results[i].Value = divideUsage(window.Value, count.windows[window.WindowSize.UnixNano()])
+		}
+	}
+	return averages, nil
+}
+
+// usageSum adds up results by filter group and window
+type usageSum struct {
+	order  []string
+	groups map[string]*groupUsage
+}
+
+type groupUsage struct {
+	first   *events.AggregationResult
+	value   decimal.Decimal
+	windows map[int64]decimal.Decimal
+	starts  map[int64]time.Time
+}
+
+func (u *usageSum) add(results []*events.AggregationResult) {
+	if u.groups == nil {
+		u.groups = make(map[string]*groupUsage)
+	}
+
+	for _, result := range results {
+		id := filterGroupID(result)
+		group, ok := u.groups[id]
+		if !ok {
+			group = &groupUsage{
+				first:   result,
+				windows: make(map[int64]decimal.Decimal),
+				starts:  make(map[int64]time.Time),
+			}
+			u.groups[id] = group
+			u.order = append(u.order, id)
+		}
+
+		group.value = group.value.Add(result.Value)
+		for _, window := range result.Results {
+			key := window.WindowSize.UnixNano()
+			group.windows[key] = group.windows[key].Add(window.Value)
+			group.starts[key] = window.WindowSize
+		}
+	}
+}
+
+func (u *usageSum) results(aggregation types.AggregationType) []*events.AggregationResult {
+	results := make([]*events.AggregationResult, 0, len(u.order))
+	for _, id := range u.order {
+		group := u.groups[id]
+
+		result := *group.first
+		result.Type = aggregation
+		result.Value = group.value
+		result.Results = nil
+		for key, value := range group.windows {
+			result.Results = append(result.Results, events.UsageResult{WindowSize: group.starts[key], Value: value})
+		}
+		sort.Slice(result.Results, func(i, j int) bool {
+			return result.Results[i].WindowSize.Before(result.Results[j].WindowSize)
+		})
+
+		results = append(results, &result)
+	}
+	return results
+}
+
+func filterGroupID(result *events.AggregationResult) string {
+	return result.Metadata["filter_group_id"]
+}
+
+func divideUsage(sum, count decimal.Decimal) decimal.Decimal {
+	if count.IsZero() {
+		return decimal.Zero
+	}
+	return sum.Div(count)
+}
+
+// meterFilters returns the filters of a segment of a meter in the form of the
+// usage params
+func meterFilters(filters []meter.Filter) map[string][]string {
+	result := make(map[string][]string, len(filters))
+	for _, filter := range filters {
+		result[filter.Key] = filter.Values
+	}
+	return result
+}
+
+// getDerivedUsage evaluates the formula of a derived meter over the usage of
+// each of its meters, read with read. Results of the same filter group and
+// windows of the same start are evaluated together, usage missing from a meter
+// counts as zero
+func getDerivedUsage(m *meter.Meter, read func(meterID string) ([]*events.AggregationResult, error)) ([]*events.AggregationResult, error) {
+	type formulaInputs struct {
+		metadata map[string]string
+		totals   map[string]decimal.Decimal
+		windows  map[int64]map[string]decimal.Decimal
+		starts   map[int64]time.Time
+	}
+
+	var order []string
+	inputs := make(map[string]*formulaInputs)
+	for _, meterID := range m.Formula.MeterIDs() {
+		results, err := read(meterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get usage of meter %s: %w", meterID, err)
+		}
+
+		for _, result := range results {
+			id := filterGroupID(result)
+			in, ok := inputs[id]
+			if !ok {
+				in = &formulaInputs{
+					metadata: result.Metadata,
+					totals:   make(map[string]decimal.Decimal),
+					windows:  make(map[int64]map[string]decimal.Decimal),
+					starts:   make(map[int64]time.Time),
+				}
+				inputs[id] = in
+				order = append(order, id)
+			}
+
+			in.totals[meterID] = in.totals[meterID].Add(result.Value)
+			for _, window := range result.Results {
+				key := window.WindowSize.UnixNano()
+				if in.windows[key] == nil {
+					in.windows[key] = make(map[string]decimal.Decimal)
+				}
+				in.windows[key][meterID] = in.windows[key][meterID].Add(window.Value)
+				in.starts[key] = window.WindowSize
+			}
+		}
+	}
+
+	results := make([]*events.AggregationResult, 0, len(order))
+	for _, id := range order {
+		in := inputs[id]
+
+		value, err := m.Formula.Evaluate(in.totals)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate formula: %w", err)
+		}
+		result := &events.AggregationResult{
+			Type:     types.AggregationFormula,
+			Value:    value,
+			Metadata: in.metadata,
+		}
+
+		for key, usage := range in.windows {
+			value, err := m.Formula.Evaluate(usage)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate formula: %w", err)
+			}
+			result.Results = append(result.Results, events.UsageResult{WindowSize: in.starts[key], Value: value})
+		}
+		sort.Slice(result.Results, func(i, j int) bool {
+			return result.Results[i].WindowSize.Before(result.Results[j].WindowSize)
+		})
+
+		results = append(results, result)
+	}
+	return results, nil
+}
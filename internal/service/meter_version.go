@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+var (
+	// ErrMeterVersionNotFound is returned when a meter has no version with the
+	// given number
+	ErrMeterVersionNotFound = errors.New("meter version not found")
+
+	// ErrInvalidMeterVersion is returned when a definition can not be applied to
+	// the usage of a meter
+	ErrInvalidMeterVersion = errors.New("invalid meter version")
+)
+
+// MeterVersionService changes the definition of meters over time. Usage is
+// always aggregated with the version effective when it happened, so changing a
+// meter never silently changes past results
+type MeterVersionService interface {
+	// CreateVersion changes the definition of the meter from the effective date
+	// of the request on
+	CreateVersion(ctx context.Context, meterID string, req dto.CreateMeterVersionRequest) (*dto.MeterVersionResponse, error)
+	ListVersions(ctx context.Context, meterID string) ([]*dto.MeterVersionResponse, error)
+	GetVersion(ctx context.Context, meterID string, version int) (*dto.MeterVersionResponse, error)
+
+	// Reaggregate applies the definition of a version to the usage of a past
+	// period. The usage of the period is read with it right away, while its
+	// rolled up windows are recomputed in the background
+	Reaggregate(ctx context.Context, meterID string, req dto.ReaggregateMeterRequest) (*dto.MeterVersionResponse, error)
+}
+
+type meterVersionService struct {
+	meterRepo      meter.Repository
+	rollupRepo     events.RollupRepository
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
+}
+
+func NewMeterVersionService(
+	meterRepo meter.Repository,
+	rollupRepo events.RollupRepository,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) MeterVersionService {
+	return &meterVersionService{
+		meterRepo:      meterRepo,
+		rollupRepo:     rollupRepo,
+		auditPublisher: auditPublisher,
+		logger:         logger,
+	}
+}
+
+func (s *meterVersionService) CreateVersion(ctx context.Context, meterID string, req dto.CreateMeterVersionRequest) (*dto.MeterVersionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMeterVersion, err)
+	}
+
+	m, versions, err := s.getMeterVersions(ctx, meterID)
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveFrom := time.Now().UTC()
+	if req.EffectiveFrom != nil && req.EffectiveFrom.After(effectiveFrom) {
+		effectiveFrom = req.EffectiveFrom.UTC()
+	}
+
+	v := s.newVersion(ctx, m, versions, req.Aggregation, req.Filters, effectiveFrom)
+	if err := s.validate(m, v); err != nil {
+		return nil, err
+	}
+
+	if err := s.meterRepo.CreateVersion(ctx, v); err != nil {
+		return nil, fmt.Errorf("failed to create meter version: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeMeter, m.ID, types.AuditActionUpdate, m, v)
+
+	return &dto.MeterVersionResponse{MeterVersion: v}, nil
+}
+
+func (s *meterVersionService) ListVersions(ctx context.Context, meterID string) ([]*dto.MeterVersionResponse, error) {
+	_, versions, err := s.getMeterVersions(ctx, meterID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*dto.MeterVersionResponse, len(versions))
+	for i, v := range versions {
+		resp[i] = &dto.MeterVersionResponse{MeterVersion: v}
+	}
+	return resp, nil
+}
+
+func (s *meterVersionService) GetVersion(ctx context.Context, meterID string, version int) (*dto.MeterVersionResponse, error) {
+	_, versions, err := s.getMeterVersions(ctx, meterID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := findMeterVersion(versions, version)
+	if v == nil {
+		return nil, ErrMeterVersionNotFound
+	}
+	return &dto.MeterVersionResponse{MeterVersion: v}, nil
+}
+
+func (s *meterVersionService) Reaggregate(ctx context.Context, meterID string, req dto.ReaggregateMeterRequest) (*dto.MeterVersionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMeterVersion, err)
+	}
+
+	m, versions, err := s.getMeterVersions(ctx, meterID)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregation, filters := m.Aggregation, m.Filters
+	if req.Version > 0 {
+		source := findMeterVersion(versions, req.Version)
+		if source == nil {
+			return nil, ErrMeterVersionNotFound
+		}
+		aggregation, filters = source.Aggregation, source.Filters
+	}
+
+	end := req.EndTime.UTC()
+	v := s.newVersion(ctx, m, versions, aggregation, filters, req.StartTime.UTC())
+	v.EffectiveTo = &end
+	v.ReaggregationStatus = types.TaskStatusPending
+	if s.rollupRepo == nil {
+		// Without rollups usage is always read from the raw events
+		v.ReaggregationStatus = types.TaskStatusCompleted
+	}
+	if err := s.validate(m, v); err != nil {
+		return nil, err
+	}
+
+	if err := s.meterRepo.CreateVersion(ctx, v); err != nil {
+		return nil, fmt.Errorf("failed to create meter version: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeMeter, m.ID, types.AuditActionUpdate, m, v)
+
+	if s.rollupRepo != nil {
+		// The recomputation outlives the request and works on its own copy of
+		// the version
+		job := *v
+		go s.reaggregate(context.WithoutCancel(ctx), m, &job)
+	}
+
+	return &dto.MeterVersionResponse{MeterVersion: v}, nil
+}
+
+// reaggregate recomputes the rolled up windows of the period of a
+// re-aggregation with its definition
+func (s *meterVersionService) reaggregate(ctx context.Context, m *meter.Meter, v *meter.MeterVersion) {
+	s.setReaggregationStatus(ctx, v, types.TaskStatusProcessing, nil)
+
+	if err := rollUpMeterAgain(ctx, s.rollupRepo, s.meterRepo, m, v.EffectiveFrom, *v.EffectiveTo); err != nil {
+		s.logger.Errorw("meter re-aggregation failed",
+			"meter_id", m.ID,
+			"version", v.Version,
+			"error", err,
+		)
+		s.setReaggregationStatus(ctx, v, types.TaskStatusFailed, err)
+		return
+	}
+
+	s.setReaggregationStatus(ctx, v, types.TaskStatusCompleted, nil)
+}
+
+func (s *meterVersionService) setReaggregationStatus(ctx context.Context, v *meter.MeterVersion, status types.TaskStatus, cause error) {
+	v.ReaggregationStatus = status
+	if cause != nil {
+		msg := cause.Error()
+		v.ReaggregationError = &msg
+	}
+	v.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.meterRepo.UpdateVersion(ctx, v); err != nil {
+		s.logger.Errorw("failed to update meter re-aggregation status",
+			"meter_id", v.MeterID,
+			"version", v.Version,
+			"error", err,
+		)
+	}
+}
+
+func (s *meterVersionService) getMeterVersions(ctx context.Context, meterID string) (*meter.Meter, []*meter.MeterVersion, error) {
+	if meterID == "" {
+		return nil, nil, fmt.Errorf("meter id is required")
+	}
+
+	m, err := s.meterRepo.GetMeter(ctx, meterID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get meter: %w", err)
+	}
+
+	if m.IsDerived() {
+		return nil, nil, fmt.Errorf("%w: derived meters are versioned through the meters of their formula", ErrInvalidMeterVersion)
+	}
+
+	versions, err := s.meterRepo.ListVersions(ctx, meterID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list meter versions: %w", err)
+	}
+
+	return m, versions, nil
+}
+
+// newVersion returns the next version of the meter with the given definition
+func (s *meterVersionService) newVersion(ctx context.Context, m *meter.Meter, versions []*meter.MeterVersion, aggregation meter.Aggregation, filters []meter.Filter, effectiveFrom time.Time) *meter.MeterVersion {
+	number := 1
+	for _, v := range versions {
+		if v.Version >= number {
+			number = v.Version + 1
+		}
+	}
+	if filters == nil {
+		filters = []meter.Filter{}
+	}
+
+	return &meter.MeterVersion{
+		ID:            types.GenerateUUID(),
+		MeterID:       m.ID,
+		Version:       number,
+		Aggregation:   aggregation,
+		Filters:       filters,
+		EffectiveFrom: effectiveFrom,
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+}
+
+// validate checks the definition of a new version. Averages can not be added
+// up with sums and counts, so a meter can not switch to or from an average.
+// Unique counts and percentiles of periods with different definitions do not
+// combine at all, so such meters are not versioned
+func (s *meterVersionService) validate(m *meter.Meter, v *meter.MeterVersion) error {
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMeterVersion, err)
+	}
+
+	for _, aggregation := range []types.AggregationType{m.Aggregation.Type, v.Aggregation.Type} {
+		if !aggregation.IsDecomposable() {
+			return fmt.Errorf("%w: %s meters can not be versioned", ErrInvalidMeterVersion, aggregation)
+		}
+	}
+
+	if (m.Aggregation.Type == types.AggregationAvg) != (v.Aggregation.Type == types.AggregationAvg) {
+		return fmt.Errorf("%w: aggregation type can not change from %s to %s",
+			ErrInvalidMeterVersion, m.Aggregation.Type, v.Aggregation.Type)
+	}
+	return nil
+}
+
+func findMeterVersion(versions []*meter.MeterVersion, version int) *meter.MeterVersion {
+	for _, v := range versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeterVersionService(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	eventStore := testutil.NewInMemoryEventStore()
+	meterStore := testutil.NewInMemoryMeterStore()
+	rollupStore := testutil.NewInMemoryRollupStore(eventStore)
+	auditStore := testutil.NewInMemoryAuditLogStore()
+	publisher := audit.NewPublisher(auditStore, logger.GetLogger())
+
+	svc := NewMeterVersionService(meterStore, rollupStore, publisher, logger.GetLogger())
+	eventService := NewEventService(config.GetDefaultConfig(), nil, eventStore, meterStore, rollupStore, nil, nil, nil, nil, logger.GetLogger())
+	rollups := NewUsageRollupService(&config.Configuration{UsageRollup: config.UsageRollupConfig{LookbackHours: 72}},
+		rollupStore, eventStore, meterStore, logger.GetLogger())
+
+	m := meter.NewMeter("LLM tokens", types.GetTenantID(ctx), types.GetUserID(ctx))
+	m.ID = "meter_tokens"
+	m.EventName = "llm_call"
+	m.Aggregation = meter.Aggregation{Type: types.AggregationSum, Field: "tokens"}
+	m.ResetUsage = types.ResetUsageNever
+	require.NoError(t, meterStore.CreateMeter(ctx, m))
+
+	now := time.Now().UTC()
+	ingest := func(id string, ts time.Time, tokens, inputTokens int) {
+		require.NoError(t, eventStore.InsertEvent(ctx, events.NewEvent("llm_call", types.GetTenantID(ctx), "acme",
+			map[string]interface{}{"tokens": float64(tokens), "input_tokens": float64(inputTokens)}, ts, id, "", "")))
+	}
+	ingest("evt_past_1", now.Add(-30*time.Hour), 10, 1)
+	ingest("evt_past_2", now.Add(-20*time.Hour), 10, 1)
+	require.NoError(t, rollups.RollUpUsage(ctx, now))
+
+	usage := func() decimal.Decimal {
+		result, err := eventService.GetUsageByMeter(ctx, &dto.GetUsageByMeterRequest{
+			MeterID:            m.ID,
+			ExternalCustomerID: "acme",
+			StartTime:          now.Add(-48 * time.Hour),
+			EndTime:            now.Add(48 * time.Hour),
+		})
+		require.NoError(t, err)
+		return result.Value
+	}
+	require.True(t, decimal.NewFromInt(20).Equal(usage()))
+
+	t.Run("keeps past usage on the version it happened under", func(t *testing.T) {
+		v, err := svc.CreateVersion(ctx, m.ID, dto.CreateMeterVersionRequest{
+			Aggregation: meter.Aggregation{Type: types.AggregationSum, Field: "input_tokens"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, v.Version)
+
+		ingest("evt_future_1", v.EffectiveFrom.Add(time.Hour), 100, 5)
+		ingest("evt_future_2", v.EffectiveFrom.Add(2*time.Hour), 100, 5)
+		assert.True(t, decimal.NewFromInt(30).Equal(usage()), "usage %s", usage())
+
+		current, err := meterStore.GetMeter(ctx, m.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "input_tokens", current.Aggregation.Field)
+	})
+
+	t.Run("re-aggregates a past period under a version", func(t *testing.T) {
+		v, err := svc.Reaggregate(ctx, m.ID, dto.ReaggregateMeterRequest{
+			StartTime: now.Add(-48 * time.Hour),
+			EndTime:   now,
+			Version:   2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, v.Version)
+		require.NotNil(t, v.EffectiveTo)
+
+		require.Eventually(t, func() bool {
+			resp, err := svc.GetVersion(ctx, m.ID, v.Version)
+			require.NoError(t, err)
+			return resp.ReaggregationStatus == types.TaskStatusCompleted
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// The rolled up windows of the period are recomputed with input_tokens
+		assert.True(t, decimal.NewFromInt(12).Equal(usage()), "usage %s", usage())
+	})
+
+	t.Run("rejects invalid versions", func(t *testing.T) {
+		_, err := svc.CreateVersion(ctx, m.ID, dto.CreateMeterVersionRequest{
+			Aggregation: meter.Aggregation{Type: types.AggregationAvg, Field: "tokens"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidMeterVersion)
+
+		past := now.Add(-time.Hour)
+		_, err = svc.CreateVersion(ctx, m.ID, dto.CreateMeterVersionRequest{
+			Aggregation:   meter.Aggregation{Type: types.AggregationCount},
+			EffectiveFrom: &past,
+		})
+		assert.ErrorIs(t, err, ErrInvalidMeterVersion)
+
+		_, err = svc.Reaggregate(ctx, m.ID, dto.ReaggregateMeterRequest{
+			StartTime: now.Add(-time.Hour),
+			EndTime:   now,
+			Version:   9,
+		})
+		assert.ErrorIs(t, err, ErrMeterVersionNotFound)
+	})
+
+	versions, err := svc.ListVersions(ctx, m.ID)
+	require.NoError(t, err)
+	assert.Len(t, versions, 3)
+
+	// Close waits for the logs to be written
+	publisher.Close()
+
+	logs, err := auditStore.List(ctx, &types.AuditLogFilter{EntityType: types.AuditEntityTypeMeter})
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+}
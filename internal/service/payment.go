@@ -0,0 +1,566 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/mandate"
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
+	"github.com/flexprice/flexprice/internal/gateway"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+)
+
+// ErrPaymentDeclined is returned when the gateway declines the payment of an invoice
+var ErrPaymentDeclined = errors.New("payment declined")
+
+type PaymentService interface {
+	// CollectInvoicePayment charges a finalized invoice to a payment method of the
+	// customer at a payment gateway, authorizing then capturing the amount, and
+	// records the payment against the invoice. Declined payments are notified
+	// with the invoice.payment_failed webhook
+	CollectInvoicePayment(ctx context.Context, invoiceID string, req dto.CollectInvoicePaymentRequest) (*dto.InvoicePaymentResponse, error)
+
+	// ScheduleDirectDebit schedules a bank debit of a finalized invoice under an
+	// active mandate of the customer. The debit is charged once the customer
+	// was notified for the pre-notification days of the mandate, its amount is
+	// processing on the invoice until it clears
+	ScheduleDirectDebit(ctx context.Context, invoiceID string, req dto.ScheduleDirectDebitRequest) (*dto.InvoicePaymentResponse, error)
+
+	// CollectDueDebits submits the scheduled bank debits of all the tenants whose
+	// charge date has come to their gateway
+	CollectDueDebits(ctx context.Context, now time.Time) error
+
+	// HandleGatewayWebhook verifies a webhook of the payment gateway of a
+	// connection and settles the pending refunds and bank debits whose outcome
+	// it notifies
+	HandleGatewayWebhook(ctx context.Context, connectionID string, payload []byte, header http.Header) error
+}
+
+// collectionGateway is implemented by gateway.Client
+type collectionGateway interface {
+	Authorize(ctx context.Context, req *gateway.AuthorizeRequest) (*gateway.Payment, error)
+	Capture(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*gateway.Payment, error)
+	Debit(ctx context.Context, req *gateway.AuthorizeRequest) (*gateway.Payment, error)
+	ParseWebhook(payload []byte, header http.Header) ([]*gateway.WebhookEvent, error)
+}
+
+type paymentService struct {
+	invoiceRepo       invoice.Repository
+	customerRepo      customer.Repository
+	paymentMethodRepo paymentmethod.Repository
+	connectionRepo    connection.Repository
+	connectionService ConnectionService
+	mandateRepo       mandate.Repository
+	emailService      EmailService
+	db                postgres.TxManager
+	webhookPublisher  webhook.Publisher
+	auditPublisher    audit.Publisher
+	logger            *logger.Logger
+
+	// clock is the time payments are made and collections are checked at, the
+	// wall clock when nil
+	clock *clock.Clock
+
+	// newGateway is replaced in tests
+	newGateway func(conn *connection.Connection) (collectionGateway, error)
+}
+
+func NewPaymentService(
+	invoiceRepo invoice.Repository,
+	customerRepo customer.Repository,
+	paymentMethodRepo paymentmethod.Repository,
+	connectionRepo connection.Repository,
+	connectionService ConnectionService,
+	mandateRepo mandate.Repository,
+	emailService EmailService,
+	db postgres.TxManager,
+	webhookPublisher webhook.Publisher,
+	auditPublisher audit.Publisher,
+	clock *clock.Clock,
+	logger *logger.Logger,
+) PaymentService {
+	return &paymentService{
+		invoiceRepo:       invoiceRepo,
+		customerRepo:      customerRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		connectionRepo:    connectionRepo,
+		connectionService: connectionService,
+		mandateRepo:       mandateRepo,
+		emailService:      emailService,
+		db:                db,
+		webhookPublisher:  webhookPublisher,
+		auditPublisher:    auditPublisher,
+		clock:             clock,
+		logger:            logger,
+		newGateway: func(conn *connection.Connection) (collectionGateway, error) {
+			return gateway.NewClient(conn)
+		},
+	}
+}
+
+func (s *paymentService) CollectInvoicePayment(ctx context.Context, invoiceID string, req dto.CollectInvoicePaymentRequest) (*dto.InvoicePaymentResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	inv, err := s.invoiceRepo.Get(ctx, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	if inv.InvoiceType == types.InvoiceTypeCredit {
+		return nil, fmt.Errorf("invalid request: payments can not be collected for a credit invoice")
+	}
+	if inv.InvoiceStatus != types.InvoiceStatusFinalized {
+		return nil, fmt.Errorf("invoice must be finalized before payments are collected")
+	}
+	if inv.WrittenOffAt != nil {
+		return nil, ErrInvoiceWrittenOff
+	}
+	if err := checkNotDisputed(ctx, s.invoiceRepo, inv.ID); err != nil {
+		return nil, err
+	}
+
+	cust, err := s.customerRepo.Get(ctx, inv.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if err := checkCollectionsActive(cust, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	conn, err := s.connectionRepo.Get(ctx, req.ConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	if !conn.Provider.IsGateway() {
+		return nil, fmt.Errorf("connection is not a payment gateway connection")
+	}
+	client, err := s.newGateway(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	key := gateway.CustomerMetadataKey(conn.Provider)
+	gatewayCustomerID := cust.Metadata[key]
+	if gatewayCustomerID == "" {
+		return nil, fmt.Errorf("customer has no %s", key)
+	}
+
+	pm, err := s.paymentMethodOf(ctx, cust, conn, req.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The amount is reserved as processing before the gateway is called, so that
+	// a concurrent collection can not charge the customer for it again
+	payment, err := s.reservePayment(ctx, inv.ID, req.Amount, conn, pm)
+	if err != nil {
+		return nil, err
+	}
+	amount := payment.Amount
+
+	// The authorization and the capture are separate tasks so that a capture
+	// retried after a rate limit does not authorize the amount again. The keys of
+	// the reserved payment make the gateway return the payment made by an attempt
+	// that timed out instead of charging the customer again
+	authorized, err := runOnSyncQueue(ctx, s.connectionService, conn.ID, types.SyncEntityTypePayment, inv.ID,
+		func(ctx context.Context) (*gateway.Payment, error) {
+			return client.Authorize(ctx, &gateway.AuthorizeRequest{
+				CustomerID:        gatewayCustomerID,
+				PaymentMethodID:   pm.GatewayPaymentMethodID,
+				PaymentMethodType: pm.Type,
+				Amount:            amount,
+				Currency:          inv.Currency,
+				Reference:         inv.ID,
+				Email:             cust.Email,
+				IdempotencyKey:    gateway.IdempotencyKey(inv.ID, payment.ID, "authorize"),
+			})
+		})
+	if err != nil {
+		// An authorization that is never captured expires at the gateway
+		if _, releaseErr := s.releasePayment(ctx, payment.ID, "", err.Error()); releaseErr != nil {
+			s.logger.Errorw("failed to release payment", "invoice_id", inv.ID, "payment_id", payment.ID, "error", releaseErr)
+		}
+		return nil, fmt.Errorf("failed to authorize payment: %w", err)
+	}
+
+	switch authorized.Status {
+	case gateway.PaymentStatusFailed:
+		if _, err := s.releasePayment(ctx, payment.ID, authorized.ID, authorized.FailureReason); err != nil {
+			return nil, err
+		}
+		return nil, s.declined(ctx, inv, conn, payment.ID, authorized, amount)
+	case gateway.PaymentStatusPending:
+		// The gateway notifies the outcome of the authorization by webhook, it can
+		// not be captured yet
+		s.logger.Infow("payment authorization is pending at the gateway",
+			"invoice_id", inv.ID,
+			"gateway_payment_id", authorized.ID,
+		)
+		if _, err := s.releasePayment(ctx, payment.ID, authorized.ID, "authorization is pending at the gateway"); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("payment %s is pending at the gateway", authorized.ID)
+	}
+
+	captured, err := runOnSyncQueue(ctx, s.connectionService, conn.ID, types.SyncEntityTypePayment, inv.ID,
+		func(ctx context.Context) (*gateway.Payment, error) {
+			return client.Capture(ctx, authorized.ID, amount, inv.Currency, gateway.IdempotencyKey(inv.ID, payment.ID, "capture"))
+		})
+	if err != nil {
+		// The capture may have been made, the payment stays processing until the
+		// webhook of the gateway settles it
+		s.logger.Errorw("failed to capture authorized payment",
+			"invoice_id", inv.ID,
+			"payment_id", payment.ID,
+			"gateway_payment_id", authorized.ID,
+			"error", err,
+		)
+		if _, err := s.updatePayment(ctx, payment.ID, func(inv *invoice.Invoice, payment *invoice.Payment, now time.Time) bool {
+			payment.GatewayPaymentID = authorized.ID
+			return payment.PaymentStatus == types.PaymentStatusProcessing
+		}); err != nil {
+			s.logger.Errorw("failed to update payment", "invoice_id", inv.ID, "payment_id", payment.ID, "error", err)
+		}
+		return nil, fmt.Errorf("failed to capture payment: %w", err)
+	}
+	if captured.Status == gateway.PaymentStatusFailed {
+		if _, err := s.releasePayment(ctx, payment.ID, authorized.ID, captured.FailureReason); err != nil {
+			return nil, err
+		}
+		return nil, s.declined(ctx, inv, conn, payment.ID, captured, amount)
+	}
+
+	// Captures pending at the gateway, ex all captures of Adyen, are recorded as
+	// paid, their failure is notified by webhook
+	paid, err := s.clearPayment(ctx, payment.ID, authorized.ID)
+	if err != nil {
+		// The money was collected at the gateway, the payment has to be reconciled
+		s.logger.Errorw("failed to record gateway payment",
+			"invoice_id", inv.ID,
+			"payment_id", payment.ID,
+			"gateway_payment_id", authorized.ID,
+			"error", err,
+		)
+		return nil, err
+	}
+	if paid == nil {
+		// Settled by a webhook of the gateway in the meantime
+		if paid, err = s.invoiceRepo.GetPayment(ctx, payment.ID); err != nil {
+			return nil, fmt.Errorf("failed to get payment: %w", err)
+		}
+	}
+
+	s.logger.Debugw("collected invoice payment",
+		"invoice_id", inv.ID,
+		"payment_id", paid.ID,
+		"gateway_payment_id", authorized.ID,
+		"amount", amount,
+	)
+
+	return &dto.InvoicePaymentResponse{Payment: paid}, nil
+}
+
+// reservePayment creates the payment of a collection as processing and adds its
+// amount, the amount remaining when no amount is given, to the amount processing
+// of the invoice under its lock
+func (s *paymentService) reservePayment(ctx context.Context, invoiceID string, requested *decimal.Decimal, conn *connection.Connection, pm *paymentmethod.PaymentMethod) (*invoice.Payment, error) {
+	var payment *invoice.Payment
+	var inv *invoice.Invoice
+	var before invoice.Invoice
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		inv, err = s.invoiceRepo.GetForUpdate(ctx, invoiceID)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if err := checkIfMatch(ctx, "invoice", inv.ID, inv.Version); err != nil {
+			return err
+		}
+
+		amount := inv.AmountRemaining
+		if requested != nil {
+			if requested.GreaterThan(amount) {
+				return fmt.Errorf("invalid request: amount exceeds the amount remaining of %s", amount.String())
+			}
+			amount = *requested
+		}
+		if !amount.IsPositive() {
+			return fmt.Errorf("invalid request: invoice is fully paid")
+		}
+
+		now := s.clock.Now()
+		payment = &invoice.Payment{
+			ID:                types.GenerateUUID(),
+			InvoiceID:         inv.ID,
+			CustomerID:        inv.CustomerID,
+			Amount:            amount,
+			Currency:          inv.Currency,
+			PaymentMethodType: pm.Type,
+			ConnectionID:      conn.ID,
+			// Set to when the payment is captured
+			PaidAt:        now,
+			PaymentStatus: types.PaymentStatusProcessing,
+			BaseModel:     types.GetDefaultBaseModel(ctx),
+		}
+		if err := s.invoiceRepo.CreatePayment(ctx, payment); err != nil {
+			return fmt.Errorf("failed to create payment: %w", err)
+		}
+
+		before = *inv
+		inv.AmountProcessing = inv.AmountProcessing.Add(amount)
+		inv.RecalculateAmountRemaining()
+		inv.UpdatedAt = now
+		inv.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoicePayment, payment.ID, types.AuditActionCreate, nil, payment)
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeInvoice, inv.ID, types.AuditActionUpdate, &before, inv)
+	return payment, nil
+}
+
+// releasePayment fails a collected payment the gateway did not capture and takes
+// its amount off the amount processing of its invoice
+func (s *paymentService) releasePayment(ctx context.Context, paymentID, gatewayPaymentID, reason string) (*invoice.Payment, error) {
+	return s.updatePayment(ctx, paymentID, func(inv *invoice.Invoice, payment *invoice.Payment, now time.Time) bool {
+		if payment.PaymentStatus != types.PaymentStatusProcessing {
+			return false
+		}
+		payment.PaymentStatus = types.PaymentStatusFailed
+		payment.GatewayPaymentID = gatewayPaymentID
+		payment.FailureReason = reason
+		inv.AmountProcessing = inv.AmountProcessing.Sub(payment.Amount)
+		return true
+	})
+}
+
+// paymentMethodOf returns the payment method of the customer on the connection,
+// its default payment method when no ID is given
+func (s *paymentService) paymentMethodOf(ctx context.Context, cust *customer.Customer, conn *connection.Connection, id string) (*paymentmethod.PaymentMethod, error) {
+	if id != "" {
+		pm, err := s.paymentMethodRepo.Get(ctx, id)
+		if err != nil || pm.CustomerID != cust.ID {
+			return nil, ErrPaymentMethodNotFound
+		}
+		if pm.ConnectionID != conn.ID {
+			return nil, fmt.Errorf("invalid request: payment method is not vaulted on connection %s", conn.ID)
+		}
+		return pm, nil
+	}
+
+	if cust.PaymentMethodID == "" {
+		return nil, fmt.Errorf("invalid request: customer has no default payment method")
+	}
+	pms, err := s.paymentMethodRepo.ListByCustomer(ctx, cust.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment methods: %w", err)
+	}
+	for _, pm := range pms {
+		if pm.GatewayPaymentMethodID == cust.PaymentMethodID && pm.ConnectionID == conn.ID {
+			return pm, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid request: default payment method of the customer is not vaulted on connection %s", conn.ID)
+}
+
+// declined notifies a payment declined by the gateway and returns the error for it
+func (s *paymentService) declined(ctx context.Context, inv *invoice.Invoice, conn *connection.Connection, paymentID string, payment *gateway.Payment, amount decimal.Decimal) error {
+	err := s.webhookPublisher.Publish(ctx, types.WebhookEventInvoicePaymentFailed, &dto.InvoicePaymentFailedEvent{
+		InvoiceID:        inv.ID,
+		PaymentID:        paymentID,
+		CustomerID:       inv.CustomerID,
+		ConnectionID:     conn.ID,
+		GatewayPaymentID: payment.ID,
+		Amount:           amount,
+		Currency:         inv.Currency,
+		FailureReason:    payment.FailureReason,
+	})
+	if err != nil {
+		s.logger.Errorw("failed to publish invoice payment failed webhook", "invoice_id", inv.ID, "error", err)
+	}
+
+	return fmt.Errorf("%w: %s", ErrPaymentDeclined, payment.FailureReason)
+}
+
+func (s *paymentService) HandleGatewayWebhook(ctx context.Context, connectionID string, payload []byte, header http.Header) error {
+	conn, err := s.connectionRepo.Get(ctx, connectionID)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	if !conn.Provider.IsGateway() {
+		return fmt.Errorf("connection is not a payment gateway connection")
+	}
+	client, err := s.newGateway(conn)
+	if err != nil {
+		return err
+	}
+
+	events, err := client.ParseWebhook(payload, header)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		switch event.Type {
+		case gateway.EventTypeRefundSucceeded, gateway.EventTypeRefundFailed:
+			// An error makes the gateway deliver the webhook again
+			if err := s.settleRefund(ctx, event); err != nil {
+				return err
+			}
+		case gateway.EventTypePaymentCaptured, gateway.EventTypePaymentAuthorized:
+			// Adyen notifies the bank debits that cleared with the AUTHORISATION
+			// webhook, the other gateways with the capture
+			if event.Type == gateway.EventTypePaymentAuthorized && conn.Provider != types.IntegrationProviderAdyen {
+				continue
+			}
+			if err := s.settleDebit(ctx, event); err != nil {
+				return err
+			}
+		case gateway.EventTypePaymentFailed:
+			failed, err := s.failProcessingDebit(ctx, event)
+			if err != nil {
+				return err
+			}
+			if failed {
+				continue
+			}
+			// Captures of collected payments that fail after they were recorded have
+			// to be reconciled
+			s.logger.Errorw("payment failed at the gateway",
+				"connection_id", conn.ID,
+				"event_id", event.ID,
+				"gateway_payment_id", event.PaymentID,
+				"failure_reason", event.FailureReason,
+			)
+		default:
+			s.logger.Debugw("ignored gateway webhook event",
+				"connection_id", conn.ID,
+				"event_id", event.ID,
+				"type", event.Type,
+			)
+		}
+	}
+	return nil
+}
+
+// settleRefund sets the outcome of a refund pending at the gateway. The amount
+// of a failed refund is taken back into the amount paid of the invoice
+func (s *paymentService) settleRefund(ctx context.Context, event *gateway.WebhookEvent) error {
+	refund, err := s.invoiceRepo.GetRefundByGatewayID(ctx, event.RefundID)
+	if err != nil {
+		// Refunds made at the gateway directly are not tracked
+		s.logger.Debugw("ignored webhook of an unknown refund", "gateway_refund_id", event.RefundID)
+		return nil
+	}
+
+	var before invoice.Refund
+	settled := false
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		inv, err := s.invoiceRepo.GetForUpdate(ctx, refund.InvoiceID)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+
+		// Read again under the lock of the invoice, gateways deliver a webhook
+		// again until it is acknowledged
+		refund, err = s.invoiceRepo.GetRefundByGatewayID(ctx, event.RefundID)
+		if err != nil {
+			return err
+		}
+		if refund.RefundStatus != types.RefundStatusPending {
+			return nil
+		}
+
+		now := s.clock.Now()
+		before = *refund
+		refund.UpdatedAt = now
+		refund.UpdatedBy = types.GetUserID(ctx)
+		if event.Type == gateway.EventTypeRefundSucceeded {
+			refund.RefundStatus = types.RefundStatusSucceeded
+		} else {
+			refund.RefundStatus = types.RefundStatusFailed
+			refund.FailureReason = event.FailureReason
+			if err := s.reverseRefund(ctx, inv, refund, now); err != nil {
+				return err
+			}
+		}
+
+		if err := s.invoiceRepo.UpdateRefund(ctx, refund); err != nil {
+			return err
+		}
+		settled = true
+
+		if refund.RefundStatus == types.RefundStatusFailed {
+			if err := s.webhookPublisher.Publish(ctx, types.WebhookEventRefundFailed, refund); err != nil {
+				return fmt.Errorf("failed to publish refund failed webhook: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil || !settled {
+		return err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRefund, refund.ID, types.AuditActionUpdate, &before, refund)
+
+	s.logger.Debugw("settled gateway refund",
+		"refund_id", refund.ID,
+		"gateway_refund_id", refund.GatewayRefundID,
+		"status", refund.RefundStatus,
+	)
+	return nil
+}
+
+// reverseRefund takes the amount of a failed refund back into the payment and
+// the invoice it was taken off
+func (s *paymentService) reverseRefund(ctx context.Context, inv *invoice.Invoice, refund *invoice.Refund, now time.Time) error {
+	payment, err := s.invoiceRepo.GetPayment(ctx, refund.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to get payment: %w", err)
+	}
+	payment.AmountRefunded = decimal.Max(payment.AmountRefunded.Sub(refund.Amount), decimal.Zero)
+	payment.UpdatedAt = now
+	payment.UpdatedBy = types.GetUserID(ctx)
+	if err := s.invoiceRepo.UpdatePayment(ctx, payment); err != nil {
+		return err
+	}
+
+	inv.AmountPaid = inv.AmountPaid.Add(refund.Amount)
+	if refund.CreditNoteID != "" {
+		// The credit note stays issued, its credit is allocated to a refund that
+		// did not happen and has to be settled manually
+		inv.AmountDue = inv.AmountDue.Add(refund.Amount)
+		s.logger.Warnw("credit note of a failed refund is left issued",
+			"refund_id", refund.ID,
+			"credit_note_id", refund.CreditNoteID,
+		)
+	}
+	inv.RecalculateAmountRemaining()
+	inv.UpdatedAt = now
+	inv.UpdatedBy = types.GetUserID(ctx)
+	if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+		return fmt.Errorf("failed to update invoice: %w", err)
+	}
+	return nil
+}
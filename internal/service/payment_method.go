@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/gateway"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// ErrPaymentMethodNotFound is returned when the payment method is not one of the customer
+var ErrPaymentMethodNotFound = errors.New("payment method not found")
+
+type PaymentMethodService interface {
+	ListPaymentMethods(ctx context.Context, customerID string) (*dto.ListPaymentMethodsResponse, error)
+
+	// AttachPaymentMethod attaches a payment method collected at the gateway to
+	// the customer of the gateway, found in the stripe_customer_id,
+	// adyen_shopper_reference or razorpay_customer_id metadata of the customer,
+	// and adds it to the payment methods of the customer
+	AttachPaymentMethod(ctx context.Context, customerID string, req dto.AttachPaymentMethodRequest) (*dto.PaymentMethodResponse, error)
+
+	// DetachPaymentMethod detaches a payment method at the gateway. Payment
+	// methods charged for active subscriptions can not be detached
+	DetachPaymentMethod(ctx context.Context, customerID, id string) error
+
+	// SetDefaultPaymentMethod makes the payment method the one the customer is
+	// charged to, at the gateway and in the payment_method_id of the customer
+	SetDefaultPaymentMethod(ctx context.Context, customerID, id string) (*dto.PaymentMethodResponse, error)
+}
+
+// paymentGateway is implemented by gateway.Client
+type paymentGateway interface {
+	AttachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) (*gateway.PaymentMethod, error)
+	DetachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error
+	SetDefaultPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error
+}
+
+type paymentMethodService struct {
+	repo              paymentmethod.Repository
+	customerRepo      customer.Repository
+	subscriptionRepo  subscription.Repository
+	connectionRepo    connection.Repository
+	connectionService ConnectionService
+	auditPublisher    audit.Publisher
+	logger            *logger.Logger
+
+	// newGateway is replaced in tests
+	newGateway func(conn *connection.Connection) (paymentGateway, error)
+}
+
+func NewPaymentMethodService(
+	repo paymentmethod.Repository,
+	customerRepo customer.Repository,
+	subscriptionRepo subscription.Repository,
+	connectionRepo connection.Repository,
+	connectionService ConnectionService,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) PaymentMethodService {
+	return &paymentMethodService{
+		repo:              repo,
+		customerRepo:      customerRepo,
+		subscriptionRepo:  subscriptionRepo,
+		connectionRepo:    connectionRepo,
+		connectionService: connectionService,
+		auditPublisher:    auditPublisher,
+		logger:            logger,
+		newGateway: func(conn *connection.Connection) (paymentGateway, error) {
+			return gateway.NewClient(conn)
+		},
+	}
+}
+
+func (s *paymentMethodService) ListPaymentMethods(ctx context.Context, customerID string) (*dto.ListPaymentMethodsResponse, error) {
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	pms, err := s.repo.ListByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment methods: %w", err)
+	}
+
+	resp := &dto.ListPaymentMethodsResponse{PaymentMethods: make([]dto.PaymentMethodResponse, 0, len(pms))}
+	for _, pm := range pms {
+		resp.PaymentMethods = append(resp.PaymentMethods, toPaymentMethodResponse(cust, pm))
+	}
+	return resp, nil
+}
+
+func (s *paymentMethodService) AttachPaymentMethod(ctx context.Context, customerID string, req dto.AttachPaymentMethodRequest) (*dto.PaymentMethodResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	conn, client, gatewayCustomerID, err := s.gatewayOf(ctx, cust, req.ConnectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	attached, err := runOnSyncQueue(ctx, s.connectionService, conn.ID, types.SyncEntityTypePaymentMethod, req.GatewayPaymentMethodID,
+		func(ctx context.Context) (*gateway.PaymentMethod, error) {
+			return client.AttachPaymentMethod(ctx, gatewayCustomerID, req.GatewayPaymentMethodID)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach payment method: %w", err)
+	}
+
+	pm := &paymentmethod.PaymentMethod{
+		ID:                     types.GenerateUUID(),
+		CustomerID:             cust.ID,
+		ConnectionID:           conn.ID,
+		Provider:               conn.Provider,
+		GatewayPaymentMethodID: attached.ID,
+		Type:                   attached.Type,
+		Brand:                  attached.Brand,
+		Last4:                  attached.Last4,
+		ExpMonth:               attached.ExpMonth,
+		ExpYear:                attached.ExpYear,
+		BaseModel:              types.GetDefaultBaseModel(ctx),
+	}
+
+	if err := s.repo.Create(ctx, pm); err != nil {
+		return nil, fmt.Errorf("failed to create payment method: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePaymentMethod, pm.ID, types.AuditActionCreate, nil, pm)
+
+	if req.SetDefault || cust.PaymentMethodID == "" {
+		if err := s.setDefault(ctx, cust, conn, client, gatewayCustomerID, pm); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := toPaymentMethodResponse(cust, pm)
+	return &resp, nil
+}
+
+func (s *paymentMethodService) DetachPaymentMethod(ctx context.Context, customerID, id string) error {
+	cust, pm, err := s.get(ctx, customerID, id)
+	if err != nil {
+		return err
+	}
+
+	subs, err := listAllPages(func(filter types.Filter) ([]*subscription.Subscription, error) {
+		return s.subscriptionRepo.List(ctx, &types.SubscriptionFilter{Filter: filter, CustomerID: customerID})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	for _, sub := range subs {
+		if sub.GatewayPaymentMethodID == pm.GatewayPaymentMethodID && sub.Status == types.StatusPublished &&
+			sub.SubscriptionStatus != types.SubscriptionStatusCancelled {
+			return fmt.Errorf("payment method is charged for subscription %s", sub.ID)
+		}
+	}
+
+	conn, client, gatewayCustomerID, err := s.gatewayOf(ctx, cust, pm.ConnectionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = runOnSyncQueue(ctx, s.connectionService, conn.ID, types.SyncEntityTypePaymentMethod, pm.GatewayPaymentMethodID,
+		func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, client.DetachPaymentMethod(ctx, gatewayCustomerID, pm.GatewayPaymentMethodID)
+		})
+	if err != nil {
+		return fmt.Errorf("failed to detach payment method: %w", err)
+	}
+
+	if err := s.repo.Delete(ctx, pm.ID); err != nil {
+		return fmt.Errorf("failed to delete payment method: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePaymentMethod, pm.ID, types.AuditActionDelete, pm, nil)
+
+	if cust.PaymentMethodID == pm.GatewayPaymentMethodID {
+		return s.updateDefault(ctx, cust, "")
+	}
+	return nil
+}
+
+func (s *paymentMethodService) SetDefaultPaymentMethod(ctx context.Context, customerID, id string) (*dto.PaymentMethodResponse, error) {
+	cust, pm, err := s.get(ctx, customerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, client, gatewayCustomerID, err := s.gatewayOf(ctx, cust, pm.ConnectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setDefault(ctx, cust, conn, client, gatewayCustomerID, pm); err != nil {
+		return nil, err
+	}
+
+	resp := toPaymentMethodResponse(cust, pm)
+	return &resp, nil
+}
+
+// get returns the customer and one of its payment methods
+func (s *paymentMethodService) get(ctx context.Context, customerID, id string) (*customer.Customer, *paymentmethod.PaymentMethod, error) {
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	pm, err := s.repo.Get(ctx, id)
+	if err != nil || pm.CustomerID != cust.ID {
+		return nil, nil, ErrPaymentMethodNotFound
+	}
+	return cust, pm, nil
+}
+
+// gatewayOf returns the gateway connection, its client and the ID of the
+// customer at the gateway
+func (s *paymentMethodService) gatewayOf(ctx context.Context, cust *customer.Customer, connectionID string) (*connection.Connection, paymentGateway, string, error) {
+	conn, err := s.connectionRepo.Get(ctx, connectionID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get connection: %w", err)
+	}
+	if !conn.Provider.IsGateway() {
+		return nil, nil, "", fmt.Errorf("connection is not a payment gateway connection")
+	}
+
+	client, err := s.newGateway(conn)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	key := gateway.CustomerMetadataKey(conn.Provider)
+	gatewayCustomerID := cust.Metadata[key]
+	if gatewayCustomerID == "" {
+		return nil, nil, "", fmt.Errorf("customer has no %s", key)
+	}
+	return conn, client, gatewayCustomerID, nil
+}
+
+func (s *paymentMethodService) setDefault(ctx context.Context, cust *customer.Customer, conn *connection.Connection, client paymentGateway, gatewayCustomerID string, pm *paymentmethod.PaymentMethod) error {
+	_, err := runOnSyncQueue(ctx, s.connectionService, conn.ID, types.SyncEntityTypePaymentMethod, pm.GatewayPaymentMethodID,
+		func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, client.SetDefaultPaymentMethod(ctx, gatewayCustomerID, pm.GatewayPaymentMethodID)
+		})
+	if err != nil {
+		return fmt.Errorf("failed to set default payment method: %w", err)
+	}
+
+	return s.updateDefault(ctx, cust, pm.GatewayPaymentMethodID)
+}
+
+func (s *paymentMethodService) updateDefault(ctx context.Context, cust *customer.Customer, gatewayPaymentMethodID string) error {
+	before := *cust
+	cust.PaymentMethodID = gatewayPaymentMethodID
+	cust.UpdatedAt = time.Now().UTC()
+	cust.UpdatedBy = types.GetUserID(ctx)
+	if err := s.customerRepo.Update(ctx, cust); err != nil {
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCustomer, cust.ID, types.AuditActionUpdate, &before, cust)
+	return nil
+}
+
+func toPaymentMethodResponse(cust *customer.Customer, pm *paymentmethod.PaymentMethod) dto.PaymentMethodResponse {
+	return dto.PaymentMethodResponse{
+		PaymentMethod: pm,
+		IsDefault:     cust.PaymentMethodID == pm.GatewayPaymentMethodID,
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/gateway"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePaymentGateway struct {
+	attached map[string]string
+	defaults map[string]string
+}
+
+func (g *fakePaymentGateway) AttachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) (*gateway.PaymentMethod, error) {
+	g.attached[paymentMethodID] = customerID
+	return &gateway.PaymentMethod{
+		ID:       paymentMethodID,
+		Type:     "card",
+		Brand:    "visa",
+		Last4:    "4242",
+		ExpMonth: 12,
+		ExpYear:  2030,
+	}, nil
+}
+
+func (g *fakePaymentGateway) DetachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	delete(g.attached, paymentMethodID)
+	return nil
+}
+
+func (g *fakePaymentGateway) SetDefaultPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	g.defaults[customerID] = paymentMethodID
+	return nil
+}
+
+func TestPaymentMethodService(t *testing.T) {
+	ctx := testutil.SetupContext()
+	manager := syncqueue.NewManager(&config.Configuration{
+		Integration: config.IntegrationConfig{SyncRatePerSecond: 100},
+	}, logger.GetLogger())
+	defer manager.Stop()
+
+	connectionStore := testutil.NewInMemoryConnectionStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	connectionService := NewConnectionService(connectionStore, manager, nil, logger.GetLogger())
+
+	fakeGateway := &fakePaymentGateway{attached: map[string]string{}, defaults: map[string]string{}}
+	svc := NewPaymentMethodService(testutil.NewInMemoryPaymentMethodStore(), customerStore, subscriptionStore,
+		connectionStore, connectionService, nil, logger.GetLogger()).(*paymentMethodService)
+	svc.newGateway = func(*connection.Connection) (paymentGateway, error) { return fakeGateway, nil }
+
+	conn, err := connectionService.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:        "Stripe",
+		Provider:    types.IntegrationProviderStripe,
+		Credentials: "sk_test",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:        "cust_1",
+		Metadata:  types.Metadata{types.MetadataStripeCustomerID: "cus_1"},
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:        "cust_2",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	attach := func(customerID, gatewayID string, setDefault bool) (*dto.PaymentMethodResponse, error) {
+		return svc.AttachPaymentMethod(ctx, customerID, dto.AttachPaymentMethodRequest{
+			ConnectionID:           conn.ID,
+			GatewayPaymentMethodID: gatewayID,
+			SetDefault:             setDefault,
+		})
+	}
+
+	// The first payment method becomes the default
+	first, err := attach("cust_1", "pm_1", false)
+	require.NoError(t, err)
+	assert.True(t, first.IsDefault)
+	assert.Equal(t, "4242", first.Last4)
+	assert.Equal(t, "cus_1", fakeGateway.attached["pm_1"])
+	assert.Equal(t, "pm_1", fakeGateway.defaults["cus_1"])
+
+	second, err := attach("cust_1", "pm_2", false)
+	require.NoError(t, err)
+	assert.False(t, second.IsDefault)
+
+	// Customers without a customer at the gateway can not attach payment methods
+	_, err = attach("cust_2", "pm_3", false)
+	assert.Error(t, err)
+
+	resp, err := svc.SetDefaultPaymentMethod(ctx, "cust_1", second.ID)
+	require.NoError(t, err)
+	assert.True(t, resp.IsDefault)
+	cust, err := customerStore.Get(ctx, "cust_1")
+	require.NoError(t, err)
+	assert.Equal(t, "pm_2", cust.PaymentMethodID)
+
+	list, err := svc.ListPaymentMethods(ctx, "cust_1")
+	require.NoError(t, err)
+	require.Len(t, list.PaymentMethods, 2)
+	assert.False(t, list.PaymentMethods[0].IsDefault)
+	assert.True(t, list.PaymentMethods[1].IsDefault)
+
+	_, err = svc.SetDefaultPaymentMethod(ctx, "cust_2", first.ID)
+	assert.ErrorIs(t, err, ErrPaymentMethodNotFound)
+
+	// A payment method charged for an active subscription is kept
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                     "sub_1",
+		CustomerID:             "cust_1",
+		SubscriptionStatus:     types.SubscriptionStatusActive,
+		GatewayPaymentMethodID: "pm_1",
+		BaseModel:              types.GetDefaultBaseModel(ctx),
+	}))
+	assert.Error(t, svc.DetachPaymentMethod(ctx, "cust_1", first.ID))
+
+	// Detaching the default leaves the customer without one
+	require.NoError(t, svc.DetachPaymentMethod(ctx, "cust_1", second.ID))
+	assert.NotContains(t, fakeGateway.attached, "pm_2")
+	cust, err = customerStore.Get(ctx, "cust_1")
+	require.NoError(t, err)
+	assert.Empty(t, cust.PaymentMethodID)
+
+	list, err = svc.ListPaymentMethods(ctx, "cust_1")
+	require.NoError(t, err)
+	require.Len(t, list.PaymentMethods, 1)
+	assert.Equal(t, "pm_1", list.PaymentMethods[0].GatewayPaymentMethodID)
+}
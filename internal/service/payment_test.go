@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/gateway"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCollectionGateway struct {
+	authorizeStatus gateway.PaymentStatus
+	authorized      []*gateway.AuthorizeRequest
+	// onAuthorize is called while the gateway authorizes a payment
+	onAuthorize  func()
+	captured     []string
+	captureKeys  []string
+	debitStatus  gateway.PaymentStatus
+	debitFailure string
+	// debitErr is returned by the debits submitted while it is set, as for a
+	// submission that timed out
+	debitErr      error
+	debitAttempts int
+	debits        []*gateway.AuthorizeRequest
+	events        []*gateway.WebhookEvent
+}
+
+func (g *fakeCollectionGateway) Authorize(ctx context.Context, req *gateway.AuthorizeRequest) (*gateway.Payment, error) {
+	g.authorized = append(g.authorized, req)
+	if g.onAuthorize != nil {
+		g.onAuthorize()
+	}
+	return &gateway.Payment{ID: "psp_1", Status: g.authorizeStatus, FailureReason: "Insufficient funds"}, nil
+}
+
+func (g *fakeCollectionGateway) Capture(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*gateway.Payment, error) {
+	g.captured = append(g.captured, paymentID+" "+amount.String())
+	g.captureKeys = append(g.captureKeys, idempotencyKey)
+	return &gateway.Payment{ID: paymentID, Status: gateway.PaymentStatusPending}, nil
+}
+
+func (g *fakeCollectionGateway) Debit(ctx context.Context, req *gateway.AuthorizeRequest) (*gateway.Payment, error) {
+	g.debitAttempts++
+	if g.debitErr != nil {
+		return nil, g.debitErr
+	}
+	g.debits = append(g.debits, req)
+	return &gateway.Payment{
+		ID:            fmt.Sprintf("psp_debit_%d", len(g.debits)),
+		Status:        g.debitStatus,
+		FailureCode:   g.debitFailure,
+		FailureReason: "Debit returned",
+	}, nil
+}
+
+func (g *fakeCollectionGateway) ParseWebhook(payload []byte, header http.Header) ([]*gateway.WebhookEvent, error) {
+	if header.Get("Signature") != "valid" {
+		return nil, gateway.ErrInvalidSignature
+	}
+	return g.events, nil
+}
+
+func TestPaymentService(t *testing.T) {
+	ctx := testutil.SetupContext()
+	manager := syncqueue.NewManager(&config.Configuration{
+		Integration: config.IntegrationConfig{SyncRatePerSecond: 100},
+	}, logger.GetLogger())
+	defer manager.Stop()
+
+	connectionStore := testutil.NewInMemoryConnectionStore()
+	connectionService := NewConnectionService(connectionStore, manager, nil, logger.GetLogger())
+	conn, err := connectionService.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:            "Adyen",
+		Provider:        types.IntegrationProviderAdyen,
+		Credentials:     "AQE_test",
+		GatewaySettings: &connection.GatewaySettings{MerchantAccount: "FlexpriceECOM"},
+	})
+	require.NoError(t, err)
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:              "cust_1",
+		Email:           "billing@acme.com",
+		PaymentMethodID: "stored_card",
+		Metadata:        map[string]string{types.MetadataAdyenShopperReference: "shopper_1"},
+		BaseModel:       types.GetDefaultBaseModel(ctx),
+	}))
+
+	paymentMethodStore := testutil.NewInMemoryPaymentMethodStore()
+	require.NoError(t, paymentMethodStore.Create(ctx, &paymentmethod.PaymentMethod{
+		ID:                     "pm_1",
+		CustomerID:             "cust_1",
+		ConnectionID:           conn.ID,
+		Provider:               types.IntegrationProviderAdyen,
+		GatewayPaymentMethodID: "stored_card",
+		Type:                   "scheme",
+		BaseModel:              types.GetDefaultBaseModel(ctx),
+	}))
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:         "ep_1",
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{string(types.WebhookEventInvoicePaymentFailed), string(types.WebhookEventRefundFailed)},
+		Secret:     "whsec_test",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+	publisher := webhook.NewPublisher(webhookStore, logger.GetLogger())
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	fakeGateway := &fakeCollectionGateway{authorizeStatus: gateway.PaymentStatusAuthorized}
+	svc := NewPaymentService(invoiceStore, customerStore, paymentMethodStore, connectionStore, connectionService,
+		testutil.NewInMemoryMandateStore(), nil, testutil.NewInMemoryTxManager(), publisher, nil, nil,
+		logger.GetLogger()).(*paymentService)
+	svc.newGateway = func(*connection.Connection) (collectionGateway, error) { return fakeGateway, nil }
+
+	inv := &invoice.Invoice{
+		ID:            "inv_1",
+		CustomerID:    "cust_1",
+		InvoiceType:   types.InvoiceTypeSubscription,
+		InvoiceStatus: types.InvoiceStatusFinalized,
+		Currency:      "eur",
+		Total:         decimal.NewFromInt(100),
+		AmountDue:     decimal.NewFromInt(100),
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+	inv.RecalculateAmountRemaining()
+	require.NoError(t, invoiceStore.Create(ctx, inv))
+
+	getInvoice := func() *invoice.Invoice {
+		updated, err := invoiceStore.Get(ctx, inv.ID)
+		require.NoError(t, err)
+		return updated
+	}
+
+	t.Run("declined payment is notified", func(t *testing.T) {
+		fakeGateway.authorizeStatus = gateway.PaymentStatusFailed
+		defer func() { fakeGateway.authorizeStatus = gateway.PaymentStatusAuthorized }()
+
+		_, err := svc.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: conn.ID})
+		assert.ErrorIs(t, err, ErrPaymentDeclined)
+		assert.Empty(t, fakeGateway.captured)
+		assert.True(t, getInvoice().AmountPaid.IsZero())
+
+		// The amount reserved for the payment is released
+		assert.True(t, getInvoice().AmountProcessing.IsZero())
+		assert.True(t, decimal.NewFromInt(100).Equal(getInvoice().AmountRemaining))
+		payments, err := invoiceStore.ListPayments(ctx, inv.ID)
+		require.NoError(t, err)
+		require.Len(t, payments, 1)
+		assert.Equal(t, types.PaymentStatusFailed, payments[0].PaymentStatus)
+		assert.Equal(t, "Insufficient funds", payments[0].FailureReason)
+	})
+
+	t.Run("amount above the amount remaining", func(t *testing.T) {
+		amount := decimal.NewFromInt(101)
+		_, err := svc.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: conn.ID, Amount: &amount})
+		assert.Error(t, err)
+	})
+
+	t.Run("default payment method is charged and captured", func(t *testing.T) {
+		amount := decimal.NewFromInt(60)
+		resp, err := svc.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: conn.ID, Amount: &amount})
+		require.NoError(t, err)
+
+		last := fakeGateway.authorized[len(fakeGateway.authorized)-1]
+		assert.Equal(t, "shopper_1", last.CustomerID)
+		assert.Equal(t, "stored_card", last.PaymentMethodID)
+		assert.Equal(t, "billing@acme.com", last.Email)
+		assert.Equal(t, []string{"psp_1 60"}, fakeGateway.captured)
+
+		// The keys are stable for the payment, a retried request is made once
+		assert.Equal(t, gateway.IdempotencyKey(inv.ID, resp.ID, "authorize"), last.IdempotencyKey)
+		assert.Equal(t, []string{gateway.IdempotencyKey(inv.ID, resp.ID, "capture")}, fakeGateway.captureKeys)
+
+		assert.Equal(t, conn.ID, resp.ConnectionID)
+		assert.Equal(t, "psp_1", resp.GatewayPaymentID)
+		assert.Equal(t, types.PaymentStatusSucceeded, resp.PaymentStatus)
+		assert.True(t, decimal.NewFromInt(60).Equal(getInvoice().AmountPaid))
+		assert.True(t, getInvoice().AmountProcessing.IsZero())
+		assert.True(t, decimal.NewFromInt(40).Equal(getInvoice().AmountRemaining))
+	})
+
+	t.Run("collection in flight reserves its amount", func(t *testing.T) {
+		var concurrentErr error
+		fakeGateway.onAuthorize = func() {
+			fakeGateway.onAuthorize = nil
+			assert.True(t, decimal.NewFromInt(40).Equal(getInvoice().AmountProcessing))
+			_, concurrentErr = svc.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: conn.ID})
+		}
+		fakeGateway.authorizeStatus = gateway.PaymentStatusFailed
+		defer func() { fakeGateway.authorizeStatus = gateway.PaymentStatusAuthorized }()
+
+		_, err := svc.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: conn.ID})
+		assert.ErrorIs(t, err, ErrPaymentDeclined)
+		require.Error(t, concurrentErr)
+		assert.Contains(t, concurrentErr.Error(), "fully paid")
+		assert.Len(t, fakeGateway.authorized, 3)
+		assert.True(t, decimal.NewFromInt(40).Equal(getInvoice().AmountRemaining))
+	})
+
+	t.Run("payment method of another customer", func(t *testing.T) {
+		require.NoError(t, paymentMethodStore.Create(ctx, &paymentmethod.PaymentMethod{
+			ID:                     "pm_other",
+			CustomerID:             "cust_2",
+			ConnectionID:           conn.ID,
+			GatewayPaymentMethodID: "other_card",
+			BaseModel:              types.GetDefaultBaseModel(ctx),
+		}))
+		_, err := svc.CollectInvoicePayment(ctx, inv.ID, dto.CollectInvoicePaymentRequest{ConnectionID: conn.ID, PaymentMethodID: "pm_other"})
+		assert.ErrorIs(t, err, ErrPaymentMethodNotFound)
+	})
+
+	t.Run("webhook with an invalid signature", func(t *testing.T) {
+		err := svc.HandleGatewayWebhook(ctx, conn.ID, []byte(`{}`), http.Header{})
+		assert.ErrorIs(t, err, gateway.ErrInvalidSignature)
+	})
+
+	t.Run("failed refund is taken back into the amount paid", func(t *testing.T) {
+		payments, err := invoiceStore.ListPayments(ctx, inv.ID)
+		require.NoError(t, err)
+		var payment *invoice.Payment
+		for _, p := range payments {
+			if p.PaymentStatus == types.PaymentStatusSucceeded {
+				payment = p
+			}
+		}
+		require.NotNil(t, payment)
+
+		// A refund of 20 pending at the gateway, already taken off the amount paid
+		payment.AmountRefunded = decimal.NewFromInt(20)
+		require.NoError(t, invoiceStore.UpdatePayment(ctx, payment))
+		require.NoError(t, invoiceStore.CreateRefund(ctx, &invoice.Refund{
+			ID:              "ref_1",
+			PaymentID:       payment.ID,
+			InvoiceID:       inv.ID,
+			CustomerID:      inv.CustomerID,
+			Amount:          decimal.NewFromInt(20),
+			Currency:        "eur",
+			RefundStatus:    types.RefundStatusPending,
+			GatewayRefundID: "psp_refund_1",
+			BaseModel:       types.GetDefaultBaseModel(ctx),
+		}))
+		refunded := getInvoice()
+		refunded.AmountPaid = decimal.NewFromInt(40)
+		refunded.RecalculateAmountRemaining()
+		require.NoError(t, invoiceStore.Update(ctx, refunded))
+
+		fakeGateway.events = []*gateway.WebhookEvent{{
+			ID:            "psp_refund_1:REFUND:false",
+			Type:          gateway.EventTypeRefundFailed,
+			PaymentID:     "psp_1",
+			RefundID:      "psp_refund_1",
+			FailureReason: "Transaction hasn't been captured",
+		}}
+		header := http.Header{"Signature": {"valid"}}
+		require.NoError(t, svc.HandleGatewayWebhook(ctx, conn.ID, []byte(`{}`), header))
+		// Delivered again, it is settled once
+		require.NoError(t, svc.HandleGatewayWebhook(ctx, conn.ID, []byte(`{}`), header))
+
+		refund, err := invoiceStore.GetRefundByGatewayID(ctx, "psp_refund_1")
+		require.NoError(t, err)
+		assert.Equal(t, types.RefundStatusFailed, refund.RefundStatus)
+		assert.Equal(t, "Transaction hasn't been captured", refund.FailureReason)
+
+		updatedPayment, err := invoiceStore.GetPayment(ctx, payment.ID)
+		require.NoError(t, err)
+		assert.True(t, updatedPayment.AmountRefunded.IsZero())
+		assert.True(t, decimal.NewFromInt(60).Equal(getInvoice().AmountPaid))
+		assert.True(t, decimal.NewFromInt(40).Equal(getInvoice().AmountRemaining))
+	})
+
+	deliveries, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	counts := make(map[types.WebhookEventType]int)
+	for _, d := range deliveries {
+		counts[d.EventType]++
+	}
+	assert.Equal(t, map[types.WebhookEventType]int{
+		types.WebhookEventInvoicePaymentFailed: 2,
+		types.WebhookEventRefundFailed:         1,
+	}, counts)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
 	"github.com/flexprice/flexprice/internal/domain/plan"
 	"github.com/flexprice/flexprice/internal/domain/price"
 	"github.com/flexprice/flexprice/internal/logger"
@@ -17,16 +18,19 @@ type PlanService interface {
 	GetPlans(ctx context.Context, filter types.Filter) (*dto.ListPlansResponse, error)
 	UpdatePlan(ctx context.Context, id string, req dto.UpdatePlanRequest) (*dto.PlanResponse, error)
 	DeletePlan(ctx context.Context, id string) error
+	// RestorePlan publishes a deleted plan again, its deleted prices stay deleted
+	RestorePlan(ctx context.Context, id string) (*dto.PlanResponse, error)
 }
 
 type planService struct {
-	planRepo  plan.Repository
-	priceRepo price.Repository
-	logger    *logger.Logger
+	planRepo       plan.Repository
+	priceRepo      price.Repository
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
 }
 
-func NewPlanService(planRepo plan.Repository, priceRepo price.Repository, logger *logger.Logger) PlanService {
-	return &planService{planRepo: planRepo, priceRepo: priceRepo, logger: logger}
+func NewPlanService(planRepo plan.Repository, priceRepo price.Repository, auditPublisher audit.Publisher, logger *logger.Logger) PlanService {
+	return &planService{planRepo: planRepo, priceRepo: priceRepo, auditPublisher: auditPublisher, logger: logger}
 }
 
 func (s *planService) CreatePlan(ctx context.Context, req dto.CreatePlanRequest) (*dto.CreatePlanResponse, error) {
@@ -38,6 +42,7 @@ func (s *planService) CreatePlan(ctx context.Context, req dto.CreatePlanRequest)
 	if err := s.planRepo.Create(ctx, plan); err != nil {
 		return nil, fmt.Errorf("failed to create plan: %w", err)
 	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePlan, plan.ID, types.AuditActionCreate, nil, plan)
 
 	// TODO: Create prices in bulk
 	for _, priceReq := range req.Prices {
@@ -49,6 +54,7 @@ func (s *planService) CreatePlan(ctx context.Context, req dto.CreatePlanRequest)
 		if err := s.priceRepo.Create(ctx, price); err != nil {
 			return nil, fmt.Errorf("failed to create price: %w", err)
 		}
+		recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePrice, price.ID, types.AuditActionCreate, nil, price)
 	}
 
 	return &dto.CreatePlanResponse{Plan: plan}, nil
@@ -105,6 +111,11 @@ func (s *planService) UpdatePlan(ctx context.Context, id string, req dto.UpdateP
 	}
 
 	plan := planResponse.Plan
+	if err := checkIfMatch(ctx, "plan", plan.ID, plan.Version); err != nil {
+		return nil, err
+	}
+
+	before := *plan
 	plan.Name = req.Name
 	plan.Description = req.Description
 	plan.LookupKey = req.LookupKey
@@ -112,6 +123,7 @@ func (s *planService) UpdatePlan(ctx context.Context, id string, req dto.UpdateP
 	if err := s.planRepo.Update(ctx, plan); err != nil {
 		return nil, fmt.Errorf("failed to update plan: %w", err)
 	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePlan, plan.ID, types.AuditActionUpdate, &before, plan)
 
 	reqPriceMap := make(map[string]*dto.UpdatePlanPriceRequest)
 	for _, reqPrice := range req.Prices {
@@ -132,17 +144,20 @@ func (s *planService) UpdatePlan(ctx context.Context, id string, req dto.UpdateP
 			finalPrices[price.ID] = price.Price
 
 			// Update the price but only the fields that are allowed to be updated
+			beforePrice := *price.Price
 			price.Description = reqPriceMap[price.ID].Description
 			price.Metadata = reqPriceMap[price.ID].Metadata
 			price.LookupKey = reqPriceMap[price.ID].LookupKey
 			if err := s.priceRepo.Update(ctx, price.Price); err != nil {
 				return nil, fmt.Errorf("failed to update price: %w", err)
 			}
+			recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePrice, price.ID, types.AuditActionUpdate, &beforePrice, price.Price)
 		} else {
 			// if existing price is not in the request, delete it
 			if err := s.priceRepo.Delete(ctx, price.ID); err != nil {
 				return nil, fmt.Errorf("failed to delete price: %w", err)
 			}
+			recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePrice, price.ID, types.AuditActionDelete, price.Price, nil)
 		}
 	}
 
@@ -162,6 +177,7 @@ func (s *planService) UpdatePlan(ctx context.Context, id string, req dto.UpdateP
 		if err := s.priceRepo.Create(ctx, newPrice); err != nil {
 			return nil, fmt.Errorf("failed to create price: %w", err)
 		}
+		recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePrice, newPrice.ID, types.AuditActionCreate, nil, newPrice)
 
 		finalPrices[newPrice.ID] = newPrice
 	}
@@ -179,9 +195,39 @@ func (s *planService) UpdatePlan(ctx context.Context, id string, req dto.UpdateP
 }
 
 func (s *planService) DeletePlan(ctx context.Context, id string) error {
-	err := s.planRepo.Delete(ctx, id)
+	plan, err := s.planRepo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get plan: %w", err)
+	}
+
+	err = s.planRepo.Delete(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete plan: %w", err)
 	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePlan, id, types.AuditActionDelete, plan, nil)
 	return nil
 }
+
+func (s *planService) RestorePlan(ctx context.Context, id string) (*dto.PlanResponse, error) {
+	plan, err := s.planRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+	if plan.Status != types.StatusDeleted {
+		return nil, ErrNotDeleted
+	}
+
+	before := *plan
+	if err := s.planRepo.Restore(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to restore plan: %w", err)
+	}
+
+	response, err := s.GetPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePlan, id, types.AuditActionRestore, &before, response.Plan)
+	return response, nil
+}
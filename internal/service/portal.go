@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/auth"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/google/uuid"
+)
+
+const defaultPortalSessionTTL = time.Hour
+
+// PortalService serves the customer portal. Except for CreateSession, all methods
+// act on the customer the portal session in the context is scoped to
+type PortalService interface {
+	CreateSession(ctx context.Context, req dto.CreatePortalSessionRequest) (*dto.PortalSessionResponse, error)
+	GetCustomer(ctx context.Context) (*dto.CustomerResponse, error)
+	GetUsage(ctx context.Context) (*dto.PortalUsageResponse, error)
+	ListInvoices(ctx context.Context, filter *types.InvoiceFilter) (*dto.ListInvoicesResponse, error)
+	GetInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error)
+}
+
+type portalService struct {
+	cfg                 *config.Configuration
+	customerRepo        customer.Repository
+	subscriptionService SubscriptionService
+	invoiceService      InvoiceService
+	logger              *logger.Logger
+}
+
+func NewPortalService(
+	cfg *config.Configuration,
+	customerRepo customer.Repository,
+	subscriptionService SubscriptionService,
+	invoiceService InvoiceService,
+	logger *logger.Logger,
+) PortalService {
+	return &portalService{
+		cfg:                 cfg,
+		customerRepo:        customerRepo,
+		subscriptionService: subscriptionService,
+		invoiceService:      invoiceService,
+		logger:              logger,
+	}
+}
+
+func (s *portalService) CreateSession(ctx context.Context, req dto.CreatePortalSessionRequest) (*dto.PortalSessionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if _, err := s.customerRepo.Get(ctx, req.CustomerID); err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	ttl := defaultPortalSessionTTL
+	if s.cfg.Auth.PortalSessionTTLMins > 0 {
+		ttl = time.Duration(s.cfg.Auth.PortalSessionTTLMins) * time.Minute
+	}
+	expiresAt := time.Now().UTC().Add(ttl)
+
+	token, err := auth.NewPortalToken(s.cfg, auth.PortalClaims{
+		SessionID:     uuid.New().String(),
+		TenantID:      types.GetTenantID(ctx),
+		CustomerID:    req.CustomerID,
+		EnvironmentID: types.GetEnvironmentID(ctx),
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create portal session: %w", err)
+	}
+
+	return &dto.PortalSessionResponse{
+		Token:      token,
+		CustomerID: req.CustomerID,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+func (s *portalService) GetCustomer(ctx context.Context) (*dto.CustomerResponse, error) {
+	customerID, err := portalCustomerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	return &dto.CustomerResponse{Customer: c}, nil
+}
+
+func (s *portalService) GetUsage(ctx context.Context) (*dto.PortalUsageResponse, error) {
+	customerID, err := portalCustomerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := s.subscriptionService.ListSubscriptions(ctx, &types.SubscriptionFilter{
+		Filter:             types.Filter{Limit: types.DefaultFilterLimit},
+		CustomerID:         customerID,
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		Status:             types.StatusPublished,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	response := &dto.PortalUsageResponse{
+		Subscriptions: make([]dto.PortalSubscriptionUsage, 0, len(subs.Subscriptions)),
+	}
+	for _, sub := range subs.Subscriptions {
+		usage, err := s.subscriptionService.GetUsageBySubscription(ctx, &dto.GetUsageBySubscriptionRequest{
+			SubscriptionID: sub.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get usage: %w", err)
+		}
+
+		response.Subscriptions = append(response.Subscriptions, dto.PortalSubscriptionUsage{
+			SubscriptionID:                 sub.ID,
+			PlanID:                         sub.PlanID,
+			GetUsageBySubscriptionResponse: usage,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *portalService) ListInvoices(ctx context.Context, filter *types.InvoiceFilter) (*dto.ListInvoicesResponse, error) {
+	customerID, err := portalCustomerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Draft invoices are internal and never shown to customers
+	filter.CustomerID = customerID
+	if filter.InvoiceStatus == "" || filter.InvoiceStatus == types.InvoiceStatusDraft {
+		filter.InvoiceStatus = types.InvoiceStatusFinalized
+	}
+
+	return s.invoiceService.ListInvoices(ctx, filter)
+}
+
+func (s *portalService) GetInvoice(ctx context.Context, id string) (*dto.InvoiceResponse, error) {
+	customerID, err := portalCustomerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.invoiceService.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if inv.CustomerID != customerID || inv.InvoiceStatus == types.InvoiceStatusDraft {
+		return nil, fmt.Errorf("failed to get invoice: invoice not found")
+	}
+
+	return inv, nil
+}
+
+func portalCustomerID(ctx context.Context) (string, error) {
+	customerID := types.GetCustomerID(ctx)
+	if customerID == "" {
+		return "", fmt.Errorf("no portal session")
+	}
+	return customerID, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/auth"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortalService(t *testing.T) {
+	ctx := testutil.SetupContext()
+	cfg := &config.Configuration{Auth: config.AuthConfig{Secret: "test-secret", PortalSessionTTLMins: 15}}
+
+	invoiceService, invoiceStore, _ := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:        "cust_123",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	for _, inv := range []*invoice.Invoice{
+		{ID: "inv_final", CustomerID: "cust_123", InvoiceStatus: types.InvoiceStatusFinalized},
+		{ID: "inv_draft", CustomerID: "cust_123", InvoiceStatus: types.InvoiceStatusDraft},
+		{ID: "inv_other", CustomerID: "cust_other", InvoiceStatus: types.InvoiceStatusFinalized},
+	} {
+		inv.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, invoiceStore.Create(ctx, inv))
+	}
+
+	svc := NewPortalService(cfg, customerStore, nil, invoiceService, logger.GetLogger())
+
+	t.Run("session token is scoped to the customer", func(t *testing.T) {
+		session, err := svc.CreateSession(ctx, dto.CreatePortalSessionRequest{CustomerID: "cust_123"})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), session.ExpiresAt, time.Minute)
+
+		claims, err := auth.ValidatePortalToken(cfg, session.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "cust_123", claims.CustomerID)
+		assert.Equal(t, types.DefaultTenantID, claims.TenantID)
+
+		// Portal tokens are not accepted as user tokens
+		_, err = auth.NewProvider(cfg).ValidateToken(ctx, session.Token)
+		assert.Error(t, err)
+
+		_, err = svc.CreateSession(ctx, dto.CreatePortalSessionRequest{CustomerID: "missing"})
+		assert.Error(t, err)
+	})
+
+	t.Run("invoices are limited to finalized invoices of the customer", func(t *testing.T) {
+		portalCtx := context.WithValue(ctx, types.CtxCustomerID, "cust_123")
+
+		list, err := svc.ListInvoices(portalCtx, &types.InvoiceFilter{})
+		require.NoError(t, err)
+		require.Len(t, list.Invoices, 1)
+		assert.Equal(t, "inv_final", list.Invoices[0].ID)
+
+		_, err = svc.GetInvoice(portalCtx, "inv_final")
+		assert.NoError(t, err)
+		_, err = svc.GetInvoice(portalCtx, "inv_draft")
+		assert.Error(t, err)
+		_, err = svc.GetInvoice(portalCtx, "inv_other")
+		assert.Error(t, err)
+
+		_, err = svc.ListInvoices(ctx, &types.InvoiceFilter{})
+		assert.Error(t, err, "requests without a portal session are rejected")
+	})
+}
@@ -3,9 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
-	"sort"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/billingengine"
 	"github.com/flexprice/flexprice/internal/domain/price"
 	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/types"
@@ -18,16 +19,18 @@ type PriceService interface {
 	GetPrices(ctx context.Context, filter types.Filter) (*dto.ListPricesResponse, error)
 	UpdatePrice(ctx context.Context, id string, req dto.UpdatePriceRequest) (*dto.PriceResponse, error)
 	DeletePrice(ctx context.Context, id string) error
+	RestorePrice(ctx context.Context, id string) (*dto.PriceResponse, error)
 	CalculateCost(ctx context.Context, price *price.Price, quantity decimal.Decimal) decimal.Decimal
 }
 
 type priceService struct {
-	repo   price.Repository
-	logger *logger.Logger
+	repo           price.Repository
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
 }
 
-func NewPriceService(repo price.Repository, logger *logger.Logger) PriceService {
-	return &priceService{repo: repo, logger: logger}
+func NewPriceService(repo price.Repository, auditPublisher audit.Publisher, logger *logger.Logger) PriceService {
+	return &priceService{repo: repo, auditPublisher: auditPublisher, logger: logger}
 }
 
 func (s *priceService) CreatePrice(ctx context.Context, req dto.CreatePriceRequest) (*dto.PriceResponse, error) {
@@ -48,6 +51,7 @@ func (s *priceService) CreatePrice(ctx context.Context, req dto.CreatePriceReque
 		return nil, fmt.Errorf("failed to create price: %w", err)
 	}
 
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePrice, price.ID, types.AuditActionCreate, nil, price)
 	return &dto.PriceResponse{Price: price}, nil
 }
 
@@ -88,134 +92,68 @@ func (s *priceService) UpdatePrice(ctx context.Context, id string, req dto.Updat
 		return nil, fmt.Errorf("failed to get price: %w", err)
 	}
 
+	unitCost, err := req.GetUnitCost()
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	before := *price
 	price.Description = req.Description
 	price.Metadata = req.Metadata
 	price.LookupKey = req.LookupKey
+	price.UnitCost = unitCost
 
 	if err := s.repo.Update(ctx, price); err != nil {
 		return nil, fmt.Errorf("failed to update price: %w", err)
 	}
 
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePrice, price.ID, types.AuditActionUpdate, &before, price)
 	return &dto.PriceResponse{Price: price}, nil
 }
 
 func (s *priceService) DeletePrice(ctx context.Context, id string) error {
+	price, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get price: %w", err)
+	}
+
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete price: %w", err)
 	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePrice, id, types.AuditActionDelete, price, nil)
 	return nil
 }
 
-// CalculateCost calculates the cost for a given price and usage
-// returns the cost in main currency units (e.g., 1.00 = $1.00)
-func (s *priceService) CalculateCost(ctx context.Context, price *price.Price, quantity decimal.Decimal) decimal.Decimal {
-	cost := decimal.Zero
-	if quantity.IsZero() {
-		return cost
+func (s *priceService) RestorePrice(ctx context.Context, id string) (*dto.PriceResponse, error) {
+	price, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price: %w", err)
+	}
+	if price.Status != types.StatusDeleted {
+		return nil, ErrNotDeleted
 	}
 
-	switch price.BillingModel {
-	case types.BILLING_MODEL_FLAT_FEE:
-		cost = price.CalculateAmount(quantity)
-
-	case types.BILLING_MODEL_PACKAGE:
-		if price.TransformQuantity.DivideBy <= 0 {
-			return decimal.Zero
-		}
-
-		transformedQuantity := quantity.Div(decimal.NewFromInt(int64(price.TransformQuantity.DivideBy)))
-
-		if price.TransformQuantity.Round == types.ROUND_UP {
-			transformedQuantity = transformedQuantity.Ceil()
-		} else if price.TransformQuantity.Round == types.ROUND_DOWN {
-			transformedQuantity = transformedQuantity.Floor()
-		}
-
-		cost = price.CalculateAmount(transformedQuantity)
+	before := *price
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to restore price: %w", err)
+	}
 
-	case types.BILLING_MODEL_TIERED:
-		cost = s.calculateTieredCost(ctx, price, quantity)
+	restored, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price: %w", err)
 	}
 
-	finalCost := cost.Round(types.GetCurrencyPrecision(price.Currency))
-	return finalCost
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePrice, id, types.AuditActionRestore, &before, restored)
+	return &dto.PriceResponse{Price: restored}, nil
 }
 
-// calculateTieredCost calculates cost for tiered pricing
-func (s *priceService) calculateTieredCost(ctx context.Context, price *price.Price, quantity decimal.Decimal) decimal.Decimal {
-	cost := decimal.Zero
-	if len(price.Tiers) == 0 {
+// CalculateCost calculates the cost for a given price and usage
+// returns the cost in main currency units (e.g., 1.00 = $1.00)
+func (s *priceService) CalculateCost(ctx context.Context, price *price.Price, quantity decimal.Decimal) decimal.Decimal {
+	if price.BillingModel == types.BILLING_MODEL_TIERED && len(price.Tiers) == 0 {
 		s.logger.WithContext(ctx).Errorf("no tiers found for price %s", price.ID)
-		return cost
-	}
-
-	// Sort price tiers by up_to value
-	sort.Slice(price.Tiers, func(i, j int) bool {
-		return price.Tiers[i].GetTierUpTo() < price.Tiers[j].GetTierUpTo()
-	})
-
-	switch price.TierMode {
-	case types.BILLING_TIER_VOLUME:
-		selectedTierIndex := len(price.Tiers) - 1
-		// Find the tier that the quantity falls into
-		for i, tier := range price.Tiers {
-			if tier.UpTo == nil {
-				selectedTierIndex = i
-				break
-			}
-			if quantity.LessThan(decimal.NewFromUint64(*tier.UpTo)) {
-				selectedTierIndex = i
-				break
-			}
-		}
-
-		selectedTier := price.Tiers[selectedTierIndex]
-
-		// Calculate tier cost with proper rounding and handling of flat amount
-		tierCost := selectedTier.CalculateTierAmount(quantity, price.Currency)
-
-		s.logger.WithContext(ctx).Debugf(
-			"volume tier total cost for quantity %s: %s price: %s tier : %+v",
-			quantity.String(),
-			tierCost.String(),
-			price.ID,
-			selectedTier,
-		)
-
-		cost = cost.Add(tierCost)
-
-	case types.BILLING_TIER_SLAB:
-		remainingQuantity := quantity
-		for _, tier := range price.Tiers {
-			var tierQuantity = remainingQuantity
-			if tier.UpTo != nil {
-				upTo := decimal.NewFromUint64(*tier.UpTo)
-				if remainingQuantity.GreaterThan(upTo) {
-					tierQuantity = upTo
-				}
-			}
-
-			// Calculate tier cost with proper rounding and handling of flat amount
-			tierCost := tier.CalculateTierAmount(tierQuantity, price.Currency)
-			cost = cost.Add(tierCost)
-			remainingQuantity = remainingQuantity.Sub(tierQuantity)
-
-			s.logger.WithContext(ctx).Debugf(
-				"slab tier total cost for quantity %s: %s price: %s tier : %+v",
-				quantity.String(),
-				tierCost.String(),
-				price.ID,
-				tier,
-			)
-
-			if remainingQuantity.LessThanOrEqual(decimal.Zero) {
-				break
-			}
-		}
-	default:
-		s.logger.WithContext(ctx).Errorf("invalid tier mode: %s", price.TierMode)
-		return decimal.Zero
-	}
-
-	return cost
+	}
+
+	return billingengine.PriceCost(price, quantity)
 }
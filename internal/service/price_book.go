@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+type PriceBookService interface {
+	CreatePriceBook(ctx context.Context, req dto.CreatePriceBookRequest) (*dto.PriceBookResponse, error)
+	GetPriceBook(ctx context.Context, id string) (*dto.PriceBookResponse, error)
+	ListPriceBooks(ctx context.Context, req dto.ListPriceBooksRequest) (*dto.ListPriceBooksResponse, error)
+	// DeletePriceBook stops assigning the price book to new subscriptions, the
+	// subscriptions created on it keep its prices
+	DeletePriceBook(ctx context.Context, id string) error
+
+	// GetPriceBookReport compares the conversion and revenue of the subscriptions
+	// of a plan created in the window by the price book they were created on
+	GetPriceBookReport(ctx context.Context, req dto.PriceBookReportRequest) (*dto.PriceBookReportResponse, error)
+}
+
+type priceBookService struct {
+	repo             pricebook.Repository
+	planRepo         plan.Repository
+	priceRepo        price.Repository
+	subscriptionRepo subscription.Repository
+	invoiceRepo      invoice.Repository
+	auditPublisher   audit.Publisher
+	logger           *logger.Logger
+}
+
+func NewPriceBookService(
+	repo pricebook.Repository,
+	planRepo plan.Repository,
+	priceRepo price.Repository,
+	subscriptionRepo subscription.Repository,
+	invoiceRepo invoice.Repository,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) PriceBookService {
+	return &priceBookService{
+		repo:             repo,
+		planRepo:         planRepo,
+		priceRepo:        priceRepo,
+		subscriptionRepo: subscriptionRepo,
+		invoiceRepo:      invoiceRepo,
+		auditPublisher:   auditPublisher,
+		logger:           logger,
+	}
+}
+
+func (s *priceBookService) CreatePriceBook(ctx context.Context, req dto.CreatePriceBookRequest) (*dto.PriceBookResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if _, err := s.planRepo.Get(ctx, req.PlanID); err != nil {
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+
+	prices, err := s.priceRepo.GetByPlanID(ctx, req.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+
+	// Overrides of prices or meters outside of the plan would never apply
+	priceIDs := make(map[string]bool, len(prices))
+	meterIDs := make(map[string]bool, len(prices))
+	for _, p := range prices {
+		priceIDs[p.ID] = true
+		if p.MeterID != "" {
+			meterIDs[p.MeterID] = true
+		}
+	}
+	for i, override := range req.Overrides {
+		if override.PriceID != "" && !priceIDs[override.PriceID] {
+			return nil, fmt.Errorf("invalid request: override %d price is not a price of the plan", i)
+		}
+		if override.MeterID != "" && !meterIDs[override.MeterID] {
+			return nil, fmt.Errorf("invalid request: override %d meter is not billed by the plan", i)
+		}
+	}
+
+	if req.Segment != "" {
+		books, err := s.repo.ListByPlan(ctx, req.PlanID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list price books: %w", err)
+		}
+		if existing := pricebook.ForSegment(books, req.Segment); existing != nil {
+			return nil, fmt.Errorf("invalid request: segment %s already has price book %s", req.Segment, existing.ID)
+		}
+	}
+
+	book := req.ToPriceBook(ctx)
+	if err := s.repo.Create(ctx, book); err != nil {
+		return nil, fmt.Errorf("failed to create price book: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePriceBook, book.ID, types.AuditActionCreate, nil, book)
+	return &dto.PriceBookResponse{PriceBook: book}, nil
+}
+
+func (s *priceBookService) GetPriceBook(ctx context.Context, id string) (*dto.PriceBookResponse, error) {
+	book, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price book: %w", err)
+	}
+
+	return &dto.PriceBookResponse{PriceBook: book}, nil
+}
+
+func (s *priceBookService) ListPriceBooks(ctx context.Context, req dto.ListPriceBooksRequest) (*dto.ListPriceBooksResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	books, err := s.repo.ListByPlan(ctx, req.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price books: %w", err)
+	}
+
+	response := &dto.ListPriceBooksResponse{PriceBooks: make([]dto.PriceBookResponse, len(books))}
+	for i, book := range books {
+		response.PriceBooks[i] = dto.PriceBookResponse{PriceBook: book}
+	}
+
+	return response, nil
+}
+
+func (s *priceBookService) DeletePriceBook(ctx context.Context, id string) error {
+	book, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get price book: %w", err)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete price book: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypePriceBook, id, types.AuditActionDelete, book, nil)
+	return nil
+}
+
+func (s *priceBookService) GetPriceBookReport(ctx context.Context, req dto.PriceBookReportRequest) (*dto.PriceBookReportResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	now := time.Now().UTC()
+	end := req.EndTime
+	if end.IsZero() {
+		end = now
+	}
+	start := req.StartTime
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -30)
+	}
+
+	books, err := s.repo.ListByPlan(ctx, req.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price books: %w", err)
+	}
+
+	// The catalog prices come first as the control group, then every price
+	// book of the plan even without subscriptions
+	stats := []*dto.PriceBookStats{{Name: "Catalog prices"}}
+	byBook := map[string]*dto.PriceBookStats{"": stats[0]}
+	for _, book := range books {
+		stats = append(stats, &dto.PriceBookStats{PriceBookID: book.ID, Name: book.Name})
+		byBook[book.ID] = stats[len(stats)-1]
+	}
+
+	subs, err := listAllPages(func(filter types.Filter) ([]*subscription.Subscription, error) {
+		return s.subscriptionRepo.List(ctx, &types.SubscriptionFilter{Filter: filter, PlanID: req.PlanID, Status: types.StatusPublished})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	subscriptionBooks := make(map[string]*dto.PriceBookStats)
+	for _, sub := range subs {
+		if sub.CreatedAt.Before(start) || !sub.CreatedAt.Before(end) {
+			continue
+		}
+
+		group, ok := byBook[sub.PriceBookID]
+		if !ok {
+			// Deleted price books are still reported for their subscriptions
+			book, err := s.repo.Get(ctx, sub.PriceBookID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get price book: %w", err)
+			}
+			group = &dto.PriceBookStats{PriceBookID: book.ID, Name: book.Name}
+			byBook[book.ID] = group
+			stats = append(stats, group)
+		}
+		subscriptionBooks[sub.ID] = group
+
+		group.Subscriptions++
+		if sub.SubscriptionStatus == types.SubscriptionStatusCancelled {
+			group.Cancelled++
+		}
+		if sub.TrialEnd != nil && !sub.TrialEnd.After(now) {
+			group.TrialsEnded++
+			if sub.CancelledAt == nil || sub.CancelledAt.After(*sub.TrialEnd) {
+				group.Converted++
+			}
+		}
+	}
+
+	items, err := s.invoiceRepo.ListFinalizedLineItems(ctx, start, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoice line items: %w", err)
+	}
+
+	revenue := make(map[*dto.PriceBookStats]map[string]decimal.Decimal)
+	for _, item := range items {
+		group, ok := subscriptionBooks[item.SubscriptionID]
+		if !ok {
+			continue
+		}
+		if revenue[group] == nil {
+			revenue[group] = make(map[string]decimal.Decimal)
+		}
+		revenue[group][item.Currency] = revenue[group][item.Currency].Add(item.Amount)
+	}
+
+	response := &dto.PriceBookReportResponse{
+		PlanID:     req.PlanID,
+		StartTime:  start,
+		EndTime:    end,
+		PriceBooks: make([]dto.PriceBookStats, len(stats)),
+	}
+	for i, group := range stats {
+		if group.TrialsEnded > 0 {
+			group.ConversionRate = decimal.NewFromInt(int64(group.Converted)).
+				Div(decimal.NewFromInt(int64(group.TrialsEnded))).Round(4)
+		}
+
+		group.Revenue = []dto.PriceBookRevenue{}
+		for currency, amount := range revenue[group] {
+			group.Revenue = append(group.Revenue, dto.PriceBookRevenue{
+				Currency:        currency,
+				Amount:          amount,
+				PerSubscription: amount.Div(decimal.NewFromInt(int64(group.Subscriptions))).Round(2),
+			})
+		}
+		sort.Slice(group.Revenue, func(i, j int) bool {
+			return group.Revenue[i].Currency < group.Revenue[j].Currency
+		})
+
+		response.PriceBooks[i] = *group
+	}
+
+	return response, nil
+}
+
+// assignPriceBook returns the price book a new subscription of the plan is put
+// on: the one it is targeted at, else the one of the segment of the customer.
+// It returns nil when the catalog prices apply
+func assignPriceBook(ctx context.Context, repo pricebook.Repository, id, planID string, cust *customer.Customer) (*pricebook.PriceBook, error) {
+	if id != "" {
+		if repo == nil {
+			return nil, fmt.Errorf("price books are not supported")
+		}
+		book, err := repo.Get(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get price book: %w", err)
+		}
+		if book.Status != types.StatusPublished || book.PlanID != planID {
+			return nil, fmt.Errorf("invalid request: price book %s is not a price book of the plan", id)
+		}
+		return book, nil
+	}
+
+	segment := cust.Metadata[types.CustomerSegmentMetadataKey]
+	if repo == nil || segment == "" {
+		return nil, nil
+	}
+
+	books, err := repo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price books: %w", err)
+	}
+	return pricebook.ForSegment(books, segment), nil
+}
+
+// subscriptionPriceBook returns the price book the subscription was created on,
+// nil when its catalog prices apply
+func subscriptionPriceBook(ctx context.Context, repo pricebook.Repository, sub *subscription.Subscription) (*pricebook.PriceBook, error) {
+	if repo == nil || sub.PriceBookID == "" {
+		return nil, nil
+	}
+
+	book, err := repo.Get(ctx, sub.PriceBookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price book: %w", err)
+	}
+	return book, nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionService_CreateSubscription_PriceBook(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	priceBookStore := testutil.NewInMemoryPriceBookStore()
+
+	for _, id := range []string{"plan_123", "plan_other"} {
+		require.NoError(t, planStore.Create(ctx, &plan.Plan{ID: id, Name: id, BaseModel: types.GetDefaultBaseModel(ctx)}))
+		require.NoError(t, priceStore.Create(ctx, &price.Price{
+			ID:                 "price_" + id,
+			PlanID:             id,
+			Type:               types.PRICE_TYPE_FIXED,
+			Amount:             decimal.NewFromInt(20),
+			Currency:           "usd",
+			BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+			BillingPeriodCount: 1,
+			BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+			BillingCadence:     types.BILLING_CADENCE_RECURRING,
+			BaseModel:          types.GetDefaultBaseModel(ctx),
+		}))
+	}
+	for id, segment := range map[string]string{"cust_smb": "smb", "cust_plain": ""} {
+		require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+			ID:        id,
+			Timezone:  types.DefaultTimezone,
+			Metadata:  types.Metadata{types.CustomerSegmentMetadataKey: segment},
+			BaseModel: types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	amount := decimal.NewFromInt(15)
+	books := []*pricebook.PriceBook{
+		{ID: "book_smb", PlanID: "plan_123", Segment: "smb"},
+		{ID: "book_targeted", PlanID: "plan_123"},
+		{ID: "book_other", PlanID: "plan_other"},
+	}
+	for _, book := range books {
+		book.Name = book.ID
+		book.Overrides = ratecard.Overrides{{PriceID: "price_" + book.PlanID, Amount: &amount}}
+		book.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, priceBookStore.Create(ctx, book))
+	}
+
+	invoiceService := NewInvoiceService(
+		testutil.NewInMemoryInvoiceStore(), testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), priceBookStore,
+		nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, nil,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(), testutil.NewInMemoryMeterStore(), customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+	svc := NewSubscriptionService(
+		subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, nil, nil, priceBookStore, nil, nil, nil,
+		invoiceService, testutil.NewInMemoryTxManager(), nil, nil, logger.GetLogger(),
+	)
+
+	tests := []struct {
+		name        string
+		customerID  string
+		priceBookID string
+		want        string
+		wantErr     bool
+	}{
+		{name: "segment price book", customerID: "cust_smb", want: "book_smb"},
+		{name: "catalog prices without segment", customerID: "cust_plain"},
+		{name: "targeted price book wins over segment", customerID: "cust_smb", priceBookID: "book_targeted", want: "book_targeted"},
+		{name: "price book of another plan", customerID: "cust_plain", priceBookID: "book_other", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := svc.CreateSubscription(ctx, dto.CreateSubscriptionRequest{
+				CustomerID:    tt.customerID,
+				PlanID:        "plan_123",
+				PriceBookID:   tt.priceBookID,
+				StartDate:     time.Now().UTC(),
+				BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.PriceBookID)
+		})
+	}
+}
+
+func TestInvoiceService_CreateSubscriptionInvoice_PriceBook(t *testing.T) {
+	amount := func(v int64) *decimal.Decimal {
+		d := decimal.NewFromInt(v)
+		return &d
+	}
+
+	tests := []struct {
+		name      string
+		book      ratecard.Overrides
+		card      ratecard.Overrides
+		wantTotal int64
+	}{
+		{
+			name:      "price book price",
+			book:      ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(15)}},
+			wantTotal: 15,
+		},
+		{
+			name:      "rate card takes precedence",
+			book:      ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(15)}},
+			card:      ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(12)}},
+			wantTotal: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.SetupContext()
+			svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+
+			book := &pricebook.PriceBook{
+				ID:        "book_123",
+				PlanID:    sub.PlanID,
+				Overrides: tt.book,
+				BaseModel: types.GetDefaultBaseModel(ctx),
+			}
+			require.NoError(t, svc.(*invoiceService).priceBookRepo.Create(ctx, book))
+			sub.PriceBookID = book.ID
+
+			// Deleted price books keep pricing their subscriptions
+			require.NoError(t, svc.(*invoiceService).priceBookRepo.Delete(ctx, book.ID))
+
+			if tt.card != nil {
+				require.NoError(t, svc.(*invoiceService).rateCardRepo.Create(ctx, &ratecard.RateCard{
+					ID:            types.GenerateUUID(),
+					CustomerID:    sub.CustomerID,
+					Version:       1,
+					EffectiveFrom: sub.StartDate,
+					Overrides:     tt.card,
+					BaseModel:     types.GetDefaultBaseModel(ctx),
+				}))
+			}
+
+			resp, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+			require.NoError(t, err)
+			assert.True(t, decimal.NewFromInt(tt.wantTotal).Equal(resp.Total), "total %s", resp.Total)
+		})
+	}
+}
+
+func TestPriceBookService_GetPriceBookReport(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	priceBookStore := testutil.NewInMemoryPriceBookStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	svc := NewPriceBookService(priceBookStore, testutil.NewInMemoryPlanStore(), testutil.NewInMemoryPriceStore(),
+		subscriptionStore, invoiceStore, nil, logger.GetLogger())
+
+	for _, id := range []string{"book_live", "book_deleted", "book_unused"} {
+		require.NoError(t, priceBookStore.Create(ctx, &pricebook.PriceBook{
+			ID:        id,
+			Name:      id,
+			PlanID:    "plan_123",
+			BaseModel: types.GetDefaultBaseModel(ctx),
+		}))
+	}
+	require.NoError(t, priceBookStore.Delete(ctx, "book_deleted"))
+
+	now := time.Now().UTC()
+	trialEnd := now.AddDate(0, 0, -10)
+	cancelledBeforeTrialEnd := trialEnd.AddDate(0, 0, -1)
+
+	subs := []*subscription.Subscription{
+		// Converted trial on the catalog prices
+		{ID: "sub_catalog", TrialEnd: &trialEnd},
+		// One converted and one cancelled trial on the price book
+		{ID: "sub_live_1", PriceBookID: "book_live", TrialEnd: &trialEnd},
+		{ID: "sub_live_2", PriceBookID: "book_live", TrialEnd: &trialEnd, CancelledAt: &cancelledBeforeTrialEnd, SubscriptionStatus: types.SubscriptionStatusCancelled},
+		{ID: "sub_deleted", PriceBookID: "book_deleted"},
+		// Created before the window
+		{ID: "sub_old", PriceBookID: "book_live"},
+	}
+	for _, sub := range subs {
+		sub.PlanID = "plan_123"
+		sub.BaseModel = types.GetDefaultBaseModel(ctx)
+		sub.CreatedAt = now.AddDate(0, 0, -20)
+		require.NoError(t, subscriptionStore.Create(ctx, sub))
+	}
+	subs[len(subs)-1].CreatedAt = now.AddDate(0, -3, 0)
+
+	periodStart := now.AddDate(0, 0, -5)
+	inv := &invoice.Invoice{
+		ID:            "inv_123",
+		InvoiceStatus: types.InvoiceStatusFinalized,
+		PeriodStart:   &periodStart,
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+	for i, sub := range []string{"sub_catalog", "sub_live_1", "sub_live_2", "sub_old"} {
+		inv.LineItems = append(inv.LineItems, &invoice.InvoiceLineItem{
+			ID:             types.GenerateUUID(),
+			InvoiceID:      inv.ID,
+			SubscriptionID: sub,
+			Amount:         decimal.NewFromInt(int64(10 * (i + 1))),
+			Currency:       "usd",
+			BaseModel:      types.GetDefaultBaseModel(ctx),
+		})
+	}
+	require.NoError(t, invoiceStore.Create(ctx, inv))
+
+	resp, err := svc.GetPriceBookReport(ctx, dto.PriceBookReportRequest{PlanID: "plan_123"})
+	require.NoError(t, err)
+
+	byBook := make(map[string]dto.PriceBookStats)
+	for _, stats := range resp.PriceBooks {
+		byBook[stats.PriceBookID] = stats
+	}
+	require.Len(t, byBook, 4)
+
+	catalog := byBook[""]
+	assert.Equal(t, 1, catalog.Subscriptions)
+	assert.Equal(t, 1, catalog.Converted)
+	require.Len(t, catalog.Revenue, 1)
+	assert.True(t, decimal.NewFromInt(10).Equal(catalog.Revenue[0].Amount))
+
+	live := byBook["book_live"]
+	assert.Equal(t, 2, live.Subscriptions)
+	assert.Equal(t, 2, live.TrialsEnded)
+	assert.Equal(t, 1, live.Converted)
+	assert.Equal(t, 1, live.Cancelled)
+	assert.True(t, decimal.NewFromFloat(0.5).Equal(live.ConversionRate))
+	require.Len(t, live.Revenue, 1)
+	assert.True(t, decimal.NewFromInt(50).Equal(live.Revenue[0].Amount), "revenue %s", live.Revenue[0].Amount)
+	assert.True(t, decimal.NewFromInt(25).Equal(live.Revenue[0].PerSubscription))
+
+	assert.Equal(t, 1, byBook["book_deleted"].Subscriptions)
+	assert.Equal(t, 0, byBook["book_unused"].Subscriptions)
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// PriceCatalogService exports and imports all the prices of a plan at once so
+// that large catalogs can be edited in bulk
+type PriceCatalogService interface {
+	ExportPlanPrices(ctx context.Context, planID string) (*dto.ExportPlanPricesResponse, error)
+
+	// ImportPlanPrices creates, updates and archives the prices of a plan in a
+	// single transaction. Nothing is applied when any row is invalid
+	ImportPlanPrices(ctx context.Context, planID string, req dto.ImportPlanPricesRequest) (*dto.ImportPlanPricesResponse, error)
+}
+
+type priceCatalogService struct {
+	planRepo  plan.Repository
+	priceRepo price.Repository
+	db        postgres.TxManager
+	logger    *logger.Logger
+}
+
+func NewPriceCatalogService(
+	planRepo plan.Repository,
+	priceRepo price.Repository,
+	db postgres.TxManager,
+	logger *logger.Logger,
+) PriceCatalogService {
+	return &priceCatalogService{
+		planRepo:  planRepo,
+		priceRepo: priceRepo,
+		db:        db,
+		logger:    logger,
+	}
+}
+
+func (s *priceCatalogService) ExportPlanPrices(ctx context.Context, planID string) (*dto.ExportPlanPricesResponse, error) {
+	prices, err := s.planPrices(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ExportPlanPricesResponse{
+		PlanID: planID,
+		Prices: make([]dto.PlanPriceRow, len(prices)),
+	}
+	for i, p := range prices {
+		resp.Prices[i] = dto.NewPlanPriceRow(p)
+	}
+
+	return resp, nil
+}
+
+// priceImportOp is the change planned for a row. It matched an existing
+// price unless it creates one, save is the matched price with its new fields
+// or archived when it is replaced
+type priceImportOp struct {
+	matched *price.Price
+	save    *price.Price
+	create  *price.Price
+}
+
+func (s *priceCatalogService) ImportPlanPrices(ctx context.Context, planID string, req dto.ImportPlanPricesRequest) (*dto.ImportPlanPricesResponse, error) {
+	existing, err := s.planPrices(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*price.Price, len(existing))
+	byLookupKey := make(map[string]*price.Price, len(existing))
+	for _, p := range existing {
+		byID[p.ID] = p
+		if p.LookupKey != "" {
+			byLookupKey[p.LookupKey] = p
+		}
+	}
+
+	resp := &dto.ImportPlanPricesResponse{DryRun: req.DryRun}
+	var ops []priceImportOp
+	matchedBy := make(map[string]int, len(existing))
+
+	for i, row := range req.Prices {
+		result := dto.PriceImportResult{Row: i + 1, LookupKey: row.LookupKey}
+
+		op, err := planPriceImport(ctx, planID, row, byID, byLookupKey)
+		if err == nil && op.matched != nil {
+			if previous, ok := matchedBy[op.matched.ID]; ok {
+				err = fmt.Errorf("price %s is also imported by row %d", op.matched.ID, previous)
+			} else {
+				matchedBy[op.matched.ID] = result.Row
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
+			resp.Failed++
+			resp.Results = append(resp.Results, result)
+			continue
+		}
+
+		switch {
+		case op.matched == nil:
+			result.Action = types.PriceImportActionCreate
+			if !req.DryRun {
+				result.PriceID = op.create.ID
+			}
+			resp.Created++
+		case op.create != nil:
+			result.Action = types.PriceImportActionReplace
+			if !req.DryRun {
+				result.PriceID = op.create.ID
+			}
+			result.ReplacedPriceID = op.matched.ID
+			resp.Replaced++
+		case op.save != nil:
+			result.Action = types.PriceImportActionUpdate
+			result.PriceID = op.matched.ID
+			resp.Updated++
+		default:
+			result.Action = types.PriceImportActionUnchanged
+			result.PriceID = op.matched.ID
+			resp.Unchanged++
+		}
+
+		resp.Results = append(resp.Results, result)
+		ops = append(ops, op)
+	}
+
+	if req.ArchiveMissing {
+		for _, p := range existing {
+			if _, ok := matchedBy[p.ID]; ok {
+				continue
+			}
+
+			archived := *p
+			archived.Status = types.StatusArchived
+			ops = append(ops, priceImportOp{matched: p, save: &archived})
+			resp.Results = append(resp.Results, dto.PriceImportResult{
+				Action:    types.PriceImportActionArchive,
+				PriceID:   p.ID,
+				LookupKey: p.LookupKey,
+			})
+			resp.Archived++
+		}
+	}
+
+	if resp.Failed > 0 || req.DryRun {
+		return resp, nil
+	}
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, op := range ops {
+			if op.save != nil {
+				if err := s.priceRepo.Update(ctx, op.save); err != nil {
+					return fmt.Errorf("failed to update price %s: %w", op.save.ID, err)
+				}
+			}
+			if op.create != nil {
+				if err := s.priceRepo.Create(ctx, op.create); err != nil {
+					return fmt.Errorf("failed to create price: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Applied = true
+	return resp, nil
+}
+
+func (s *priceCatalogService) planPrices(ctx context.Context, planID string) ([]*price.Price, error) {
+	if _, err := s.planRepo.Get(ctx, planID); err != nil {
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+
+	prices, err := s.priceRepo.GetByPlanID(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+
+	sort.SliceStable(prices, func(i, j int) bool {
+		if prices[i].CreatedAt.Equal(prices[j].CreatedAt) {
+			return prices[i].ID < prices[j].ID
+		}
+		return prices[i].CreatedAt.Before(prices[j].CreatedAt)
+	})
+
+	return prices, nil
+}
+
+// planPriceImport plans the change of a single row. A row matches the price
+// with its id, or else with its lookup key, and prices whose amount or billing
+// terms change are replaced rather than changed in place
+func planPriceImport(
+	ctx context.Context,
+	planID string,
+	row dto.PlanPriceRow,
+	byID map[string]*price.Price,
+	byLookupKey map[string]*price.Price,
+) (priceImportOp, error) {
+	row.PlanID = planID
+	if err := row.Validate(); err != nil {
+		return priceImportOp{}, fmt.Errorf("invalid price: %w", err)
+	}
+
+	imported, err := row.ToPrice(ctx)
+	if err != nil {
+		return priceImportOp{}, fmt.Errorf("invalid price: %w", err)
+	}
+
+	current := byLookupKey[row.LookupKey]
+	if row.ID != "" {
+		var ok bool
+		if current, ok = byID[row.ID]; !ok {
+			return priceImportOp{}, fmt.Errorf("price %s is not a price of the plan", row.ID)
+		}
+	}
+
+	if current == nil {
+		return priceImportOp{create: imported}, nil
+	}
+
+	if !samePricingTerms(current, imported) {
+		archived := *current
+		archived.Status = types.StatusArchived
+		return priceImportOp{matched: current, save: &archived, create: imported}, nil
+	}
+
+	if current.LookupKey == imported.LookupKey &&
+		current.Description == imported.Description &&
+		maps.Equal(current.Metadata, imported.Metadata) {
+		return priceImportOp{matched: current}, nil
+	}
+
+	updated := *current
+	updated.LookupKey = imported.LookupKey
+	updated.Description = imported.Description
+	updated.Metadata = imported.Metadata
+	return priceImportOp{matched: current, save: &updated}, nil
+}
+
+// samePricingTerms tells whether two prices bill the same quantity the same
+// amount, their lookup key, description and metadata aside
+func samePricingTerms(a, b *price.Price) bool {
+	if !a.Amount.Equal(b.Amount) ||
+		a.Currency != b.Currency ||
+		a.Type != b.Type ||
+		a.BillingPeriod != b.BillingPeriod ||
+		a.BillingPeriodCount != b.BillingPeriodCount ||
+		a.BillingModel != b.BillingModel ||
+		a.BillingCadence != b.BillingCadence ||
+		a.MeterID != b.MeterID ||
+		a.TierMode != b.TierMode ||
+		a.TransformQuantity != b.TransformQuantity {
+		return false
+	}
+
+	if !maps.EqualFunc(a.FilterValues, b.FilterValues, slices.Equal[[]string]) {
+		return false
+	}
+
+	return slices.EqualFunc(a.Tiers, b.Tiers, func(x, y price.PriceTier) bool {
+		if !x.UnitAmount.Equal(y.UnitAmount) {
+			return false
+		}
+		if (x.UpTo == nil) != (y.UpTo == nil) || (x.UpTo != nil && *x.UpTo != *y.UpTo) {
+			return false
+		}
+		if (x.FlatAmount == nil) != (y.FlatAmount == nil) || (x.FlatAmount != nil && !x.FlatAmount.Equal(*y.FlatAmount)) {
+			return false
+		}
+		return true
+	})
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceCatalogService_ImportPlanPrices(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	planStore := testutil.NewInMemoryPlanStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Usage Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	upTo := uint64(1000)
+	flat := decimal.NewFromInt(5)
+	priceStore := testutil.NewInMemoryPriceStore()
+	for _, p := range []*price.Price{
+		{
+			ID:                 "price_base",
+			LookupKey:          "base",
+			Amount:             decimal.NewFromInt(10),
+			Type:               types.PRICE_TYPE_FIXED,
+			BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+			Description:        "Base fee",
+			Metadata:           price.JSONBMetadata{},
+			FilterValues:       price.JSONBFilters{},
+			BillingCadence:     types.BILLING_CADENCE_RECURRING,
+			BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+			BillingPeriodCount: 1,
+			Currency:           "usd",
+			PlanID:             "plan_123",
+		},
+		{
+			ID:        "price_calls",
+			LookupKey: "calls",
+			Type:      types.PRICE_TYPE_USAGE,
+			MeterID:   "meter_calls",
+			TierMode:  types.BILLING_TIER_SLAB,
+			Tiers: price.JSONBTiers{
+				{UpTo: &upTo, UnitAmount: decimal.NewFromFloat(0.02)},
+				{UnitAmount: decimal.NewFromFloat(0.01), FlatAmount: &flat},
+			},
+			FilterValues:       price.JSONBFilters{"region": {"us"}},
+			BillingModel:       types.BILLING_MODEL_TIERED,
+			BillingCadence:     types.BILLING_CADENCE_RECURRING,
+			BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+			BillingPeriodCount: 1,
+			Currency:           "usd",
+			PlanID:             "plan_123",
+		},
+		{
+			ID:                 "price_setup",
+			Amount:             decimal.NewFromInt(100),
+			Type:               types.PRICE_TYPE_FIXED,
+			BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+			BillingCadence:     types.BILLING_CADENCE_ONETIME,
+			BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+			BillingPeriodCount: 1,
+			Currency:           "usd",
+			PlanID:             "plan_123",
+		},
+	} {
+		p.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, priceStore.Create(ctx, p))
+	}
+
+	svc := NewPriceCatalogService(planStore, priceStore, testutil.NewInMemoryTxManager(), logger.GetLogger())
+
+	exported, err := svc.ExportPlanPrices(ctx, "plan_123")
+	require.NoError(t, err)
+	require.Len(t, exported.Prices, 3)
+
+	// The CSV export imports back without any change
+	var buf bytes.Buffer
+	require.NoError(t, dto.WritePlanPricesCSV(&buf, exported.Prices))
+	rows, err := dto.ParsePlanPricesCSV(&buf)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	resp, err := svc.ImportPlanPrices(ctx, "plan_123", dto.ImportPlanPricesRequest{Prices: rows})
+	require.NoError(t, err)
+	assert.True(t, resp.Applied)
+	assert.Equal(t, 3, resp.Unchanged)
+
+	byLookupKey := make(map[string]dto.PlanPriceRow)
+	for _, row := range rows {
+		byLookupKey[row.LookupKey] = row
+	}
+
+	base := byLookupKey["base"]
+	base.ID = ""
+	base.Description = "Platform fee"
+
+	calls := byLookupKey["calls"]
+	calls.Tiers = append([]dto.CreatePriceTier(nil), calls.Tiers...)
+	calls.Tiers[0].UnitAmount = "0.03"
+
+	seats := dto.PlanPriceRow{CreatePriceRequest: dto.CreatePriceRequest{
+		LookupKey:          "seats",
+		Amount:             "7",
+		Currency:           "USD",
+		Type:               types.PRICE_TYPE_FIXED,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+	}}
+
+	invalid := seats
+	invalid.LookupKey = "invalid"
+	invalid.Amount = "-1"
+
+	// A single invalid row rejects the whole import
+	resp, err = svc.ImportPlanPrices(ctx, "plan_123", dto.ImportPlanPricesRequest{
+		Prices:         []dto.PlanPriceRow{base, calls, seats, invalid},
+		ArchiveMissing: true,
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Applied)
+	assert.Equal(t, 1, resp.Failed)
+	assert.NotEmpty(t, resp.Results[3].Error)
+
+	prices, err := priceStore.GetByPlanID(ctx, "plan_123")
+	require.NoError(t, err)
+	assert.Len(t, prices, 3)
+
+	req := dto.ImportPlanPricesRequest{
+		Prices:         []dto.PlanPriceRow{base, calls, seats},
+		ArchiveMissing: true,
+		DryRun:         true,
+	}
+	resp, err = svc.ImportPlanPrices(ctx, "plan_123", req)
+	require.NoError(t, err)
+	assert.False(t, resp.Applied)
+	assert.Equal(t, 1, resp.Updated)
+	assert.Equal(t, 1, resp.Replaced)
+	assert.Equal(t, 1, resp.Created)
+	assert.Equal(t, 1, resp.Archived)
+
+	prices, err = priceStore.GetByPlanID(ctx, "plan_123")
+	require.NoError(t, err)
+	assert.Len(t, prices, 3, "dry run")
+
+	req.DryRun = false
+	resp, err = svc.ImportPlanPrices(ctx, "plan_123", req)
+	require.NoError(t, err)
+	require.True(t, resp.Applied)
+
+	require.Len(t, resp.Results, 4)
+	assert.Equal(t, types.PriceImportActionUpdate, resp.Results[0].Action)
+	assert.Equal(t, "price_base", resp.Results[0].PriceID)
+	assert.Equal(t, types.PriceImportActionReplace, resp.Results[1].Action)
+	assert.Equal(t, "price_calls", resp.Results[1].ReplacedPriceID)
+	assert.Equal(t, types.PriceImportActionCreate, resp.Results[2].Action)
+	assert.Equal(t, types.PriceImportActionArchive, resp.Results[3].Action)
+	assert.Equal(t, "price_setup", resp.Results[3].PriceID)
+
+	prices, err = priceStore.GetByPlanID(ctx, "plan_123")
+	require.NoError(t, err)
+	require.Len(t, prices, 3)
+
+	current := make(map[string]*price.Price)
+	for _, p := range prices {
+		current[p.LookupKey] = p
+	}
+	assert.Equal(t, "price_base", current["base"].ID)
+	assert.Equal(t, "Platform fee", current["base"].Description)
+	assert.Equal(t, resp.Results[1].PriceID, current["calls"].ID)
+	assert.True(t, decimal.NewFromFloat(0.03).Equal(current["calls"].Tiers[0].UnitAmount))
+	assert.Equal(t, "usd", current["seats"].Currency)
+
+	replaced, err := priceStore.Get(ctx, "price_calls")
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusArchived, replaced.Status)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+type RateCardService interface {
+	// CreateRateCard creates the next version of the rate card of the customer or subscription
+	CreateRateCard(ctx context.Context, req dto.CreateRateCardRequest) (*dto.RateCardResponse, error)
+	GetRateCard(ctx context.Context, id string) (*dto.RateCardResponse, error)
+	ListRateCards(ctx context.Context, req dto.ListRateCardsRequest) (*dto.ListRateCardsResponse, error)
+	DeleteRateCard(ctx context.Context, id string) error
+
+	// GetCustomerRates compares the rates the ongoing subscriptions of the
+	// customer are billed at with the list prices of their plans
+	GetCustomerRates(ctx context.Context, req dto.CustomerRatesRequest) (*dto.CustomerRatesResponse, error)
+}
+
+type rateCardService struct {
+	repo             ratecard.Repository
+	customerRepo     customer.Repository
+	subscriptionRepo subscription.Repository
+	priceRepo        price.Repository
+	priceBookRepo    pricebook.Repository
+	auditPublisher   audit.Publisher
+	logger           *logger.Logger
+}
+
+func NewRateCardService(
+	repo ratecard.Repository,
+	customerRepo customer.Repository,
+	subscriptionRepo subscription.Repository,
+	priceRepo price.Repository,
+	priceBookRepo pricebook.Repository,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) RateCardService {
+	return &rateCardService{
+		repo:             repo,
+		customerRepo:     customerRepo,
+		subscriptionRepo: subscriptionRepo,
+		priceRepo:        priceRepo,
+		priceBookRepo:    priceBookRepo,
+		auditPublisher:   auditPublisher,
+		logger:           logger,
+	}
+}
+
+func (s *rateCardService) CreateRateCard(ctx context.Context, req dto.CreateRateCardRequest) (*dto.RateCardResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if _, err := s.customerRepo.Get(ctx, req.CustomerID); err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	if req.SubscriptionID != "" {
+		sub, err := s.subscriptionRepo.Get(ctx, req.SubscriptionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subscription: %w", err)
+		}
+		if sub.CustomerID != req.CustomerID {
+			return nil, fmt.Errorf("invalid request: subscription does not belong to the customer")
+		}
+	}
+
+	cards, err := s.repo.ListByCustomer(ctx, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rate cards: %w", err)
+	}
+
+	card := req.ToRateCard(ctx)
+
+	// Versions of a contract follow each other, a renewal can not start before
+	// the version it replaces
+	card.Version = 1
+	for _, existing := range cards {
+		if existing.SubscriptionID != card.SubscriptionID || existing.Version < card.Version {
+			continue
+		}
+		if !card.EffectiveFrom.After(existing.EffectiveFrom) {
+			return nil, fmt.Errorf("invalid request: effective_from must be after the one of version %d", existing.Version)
+		}
+		card.Version = existing.Version + 1
+	}
+
+	if err := s.repo.Create(ctx, card); err != nil {
+		return nil, fmt.Errorf("failed to create rate card: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRateCard, card.ID, types.AuditActionCreate, nil, card)
+	return &dto.RateCardResponse{RateCard: card}, nil
+}
+
+func (s *rateCardService) GetRateCard(ctx context.Context, id string) (*dto.RateCardResponse, error) {
+	card, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate card: %w", err)
+	}
+
+	return &dto.RateCardResponse{RateCard: card}, nil
+}
+
+func (s *rateCardService) ListRateCards(ctx context.Context, req dto.ListRateCardsRequest) (*dto.ListRateCardsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	cards, err := s.repo.ListByCustomer(ctx, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rate cards: %w", err)
+	}
+
+	response := &dto.ListRateCardsResponse{RateCards: make([]dto.RateCardResponse, len(cards))}
+	for i, card := range cards {
+		response.RateCards[i] = dto.RateCardResponse{RateCard: card}
+	}
+
+	return response, nil
+}
+
+func (s *rateCardService) DeleteRateCard(ctx context.Context, id string) error {
+	card, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get rate card: %w", err)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete rate card: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRateCard, id, types.AuditActionDelete, card, nil)
+	return nil
+}
+
+func (s *rateCardService) GetCustomerRates(ctx context.Context, req dto.CustomerRatesRequest) (*dto.CustomerRatesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	at := req.At
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	if _, err := s.customerRepo.Get(ctx, req.CustomerID); err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	cards, err := s.repo.ListByCustomer(ctx, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rate cards: %w", err)
+	}
+
+	subs, err := listAllPages(func(filter types.Filter) ([]*subscription.Subscription, error) {
+		return s.subscriptionRepo.List(ctx, &types.SubscriptionFilter{Filter: filter, CustomerID: req.CustomerID, Status: types.StatusPublished})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	response := &dto.CustomerRatesResponse{
+		CustomerID:    req.CustomerID,
+		At:            at,
+		Subscriptions: []dto.SubscriptionRates{},
+	}
+	for _, sub := range subs {
+		if sub.SubscriptionStatus == types.SubscriptionStatusCancelled {
+			continue
+		}
+
+		book, err := subscriptionPriceBook(ctx, s.priceBookRepo, sub)
+		if err != nil {
+			return nil, err
+		}
+		card := ratecard.Effective(cards, sub.ID, at)
+
+		prices, err := s.priceRepo.GetByPlanID(ctx, sub.PlanID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prices: %w", err)
+		}
+
+		rates := dto.SubscriptionRates{
+			SubscriptionID: sub.ID,
+			PlanID:         sub.PlanID,
+			PriceBookID:    sub.PriceBookID,
+			Rates:          []dto.Rate{},
+		}
+		if card != nil {
+			rates.RateCardID = card.ID
+			rates.RateCardVersion = card.Version
+		}
+
+		for _, p := range prices {
+			if p.Currency != sub.Currency || p.BillingPeriod != sub.BillingPeriod || p.BillingPeriodCount != sub.BillingPeriodCount {
+				continue
+			}
+			rates.Rates = append(rates.Rates, rateOf(p, book, card))
+		}
+		response.Subscriptions = append(response.Subscriptions, rates)
+	}
+
+	return response, nil
+}
+
+// rateOf is the list price next to the price the subscription is billed at,
+// overridden by its price book then by its rate card as on invoices
+func rateOf(p *price.Price, book *pricebook.PriceBook, card *ratecard.RateCard) dto.Rate {
+	effective, source := p, "catalog"
+	if book != nil {
+		if overridden := book.Apply(effective); overridden != effective {
+			effective, source = overridden, "price_book"
+		}
+	}
+	if card != nil {
+		if overridden := card.Apply(effective); overridden != effective {
+			effective, source = overridden, "rate_card"
+		}
+	}
+
+	rate := dto.Rate{
+		PriceID:         p.ID,
+		MeterID:         p.MeterID,
+		Currency:        p.Currency,
+		Source:          source,
+		ListAmount:      p.Amount,
+		EffectiveAmount: effective.Amount,
+		ListTiers:       p.Tiers,
+		EffectiveTiers:  effective.Tiers,
+		Difference:      effective.Amount.Sub(p.Amount),
+	}
+	if !p.Amount.IsZero() {
+		percent := rate.Difference.Div(p.Amount).Mul(decimal.NewFromInt(100)).Round(2)
+		rate.DifferencePercent = &percent
+	}
+	return rate
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateCardService_CreateRateCard_Versions(t *testing.T) {
+	ctx := testutil.SetupContext()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	svc := NewRateCardService(testutil.NewInMemoryRateCardStore(), customerStore, subscriptionStore, nil, nil, nil, logger.GetLogger())
+
+	for _, id := range []string{"cust_1", "cust_2"} {
+		require.NoError(t, customerStore.Create(ctx, &customer.Customer{ID: id, BaseModel: types.GetDefaultBaseModel(ctx)}))
+	}
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:         "sub_1",
+		CustomerID: "cust_1",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	amount := decimal.NewFromInt(10)
+	request := func(subscriptionID string, effectiveFrom time.Time) dto.CreateRateCardRequest {
+		return dto.CreateRateCardRequest{
+			Name:           "Contract",
+			CustomerID:     "cust_1",
+			SubscriptionID: subscriptionID,
+			EffectiveFrom:  effectiveFrom,
+			Overrides:      []ratecard.Override{{PriceID: "price_1", Amount: &amount}},
+		}
+	}
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first, err := svc.CreateRateCard(ctx, request("", jan))
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Version)
+
+	renewal, err := svc.CreateRateCard(ctx, request("", jan.AddDate(1, 0, 0)))
+	require.NoError(t, err)
+	assert.Equal(t, 2, renewal.Version)
+
+	// Subscription rate cards are versioned on their own
+	subscriptionCard, err := svc.CreateRateCard(ctx, request("sub_1", jan))
+	require.NoError(t, err)
+	assert.Equal(t, 1, subscriptionCard.Version)
+
+	// A renewal can not start before the version it replaces
+	_, err = svc.CreateRateCard(ctx, request("", jan.AddDate(0, 6, 0)))
+	assert.Error(t, err)
+
+	// The subscription must belong to the customer
+	req := request("sub_1", jan.AddDate(2, 0, 0))
+	req.CustomerID = "cust_2"
+	_, err = svc.CreateRateCard(ctx, req)
+	assert.Error(t, err)
+
+	// Overrides must target a price or a meter
+	req = request("", jan.AddDate(2, 0, 0))
+	req.Overrides = []ratecard.Override{{PriceID: "price_1", MeterID: "meter_1", Amount: &amount}}
+	_, err = svc.CreateRateCard(ctx, req)
+	assert.Error(t, err)
+
+	list, err := svc.ListRateCards(ctx, dto.ListRateCardsRequest{CustomerID: "cust_1"})
+	require.NoError(t, err)
+	assert.Len(t, list.RateCards, 3)
+}
+
+func TestInvoiceService_CreateSubscriptionInvoice_RateCard(t *testing.T) {
+	amount := func(v int64) *decimal.Decimal {
+		d := decimal.NewFromInt(v)
+		return &d
+	}
+
+	// The contracts end before the invoiced period starts
+	contractEnd := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		cards       []*ratecard.RateCard
+		wantTotal   int64
+		wantVersion string
+	}{
+		{
+			name:      "catalog price without rate card",
+			wantTotal: 20,
+		},
+		{
+			name: "customer rate card",
+			cards: []*ratecard.RateCard{
+				{Version: 1, Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(15)}}},
+			},
+			wantTotal:   15,
+			wantVersion: "1",
+		},
+		{
+			name: "latest version in effect",
+			cards: []*ratecard.RateCard{
+				{Version: 1, Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(15)}}},
+				{Version: 2, Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(12)}}},
+				{Version: 3, EffectiveFrom: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(5)}}},
+			},
+			wantTotal:   12,
+			wantVersion: "2",
+		},
+		{
+			name: "subscription rate card takes precedence",
+			cards: []*ratecard.RateCard{
+				{Version: 4, Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(15)}}},
+				{Version: 1, SubscriptionID: "sub_123", Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(18)}}},
+			},
+			wantTotal:   18,
+			wantVersion: "1",
+		},
+		{
+			name: "ended rate card",
+			cards: []*ratecard.RateCard{
+				{Version: 1, EffectiveTo: &contractEnd, Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(15)}}},
+			},
+			wantTotal: 20,
+		},
+		{
+			name: "ended subscription rate card falls back on the customer one",
+			cards: []*ratecard.RateCard{
+				{Version: 1, Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(15)}}},
+				{Version: 1, SubscriptionID: "sub_123", EffectiveTo: &contractEnd, Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(18)}}},
+			},
+			wantTotal:   15,
+			wantVersion: "1",
+		},
+		{
+			name: "rate card of another subscription",
+			cards: []*ratecard.RateCard{
+				{Version: 1, SubscriptionID: "sub_other", Overrides: ratecard.Overrides{{PriceID: "price_fixed", Amount: amount(18)}}},
+			},
+			wantTotal: 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.SetupContext()
+			svc, _, sub := setupInvoiceServiceTest(t, types.NegativeInvoiceBehaviorCreditInvoice)
+
+			rateCardStore := svc.(*invoiceService).rateCardRepo
+			for _, card := range tt.cards {
+				card.ID = types.GenerateUUID()
+				card.CustomerID = sub.CustomerID
+				if card.EffectiveFrom.IsZero() {
+					card.EffectiveFrom = sub.StartDate
+				}
+				card.BaseModel = types.GetDefaultBaseModel(ctx)
+				require.NoError(t, rateCardStore.Create(ctx, card))
+			}
+
+			resp, err := svc.CreateSubscriptionInvoice(ctx, sub.ID, dto.CreateSubscriptionInvoiceRequest{})
+			require.NoError(t, err)
+
+			assert.True(t, decimal.NewFromInt(tt.wantTotal).Equal(resp.Total), "total %s", resp.Total)
+			assert.Equal(t, tt.wantVersion, resp.Metadata["rate_card_version"])
+		})
+	}
+}
+
+func TestRateCard_Apply(t *testing.T) {
+	meterAmount := decimal.NewFromInt(2)
+	priceAmount := decimal.NewFromInt(1)
+	card := &ratecard.RateCard{Overrides: ratecard.Overrides{
+		{MeterID: "meter_api", Amount: &meterAmount},
+		{PriceID: "price_api_eu", Amount: &priceAmount},
+	}}
+
+	catalog := func(id string) *price.Price {
+		return &price.Price{ID: id, MeterID: "meter_api", Amount: decimal.NewFromInt(3)}
+	}
+
+	// A price override beats the override of its meter
+	assert.True(t, priceAmount.Equal(card.Apply(catalog("price_api_eu")).Amount))
+	assert.True(t, meterAmount.Equal(card.Apply(catalog("price_api_us")).Amount))
+
+	// Uncovered prices and the catalog are left untouched
+	other := &price.Price{ID: "price_other", MeterID: "meter_other", Amount: decimal.NewFromInt(3)}
+	assert.Same(t, other, card.Apply(other))
+
+	p := catalog("price_api_us")
+	card.Apply(p)
+	assert.True(t, decimal.NewFromInt(3).Equal(p.Amount))
+}
+
+func TestRateCardService_GetCustomerRates(t *testing.T) {
+	ctx := testutil.SetupContext()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	rateCardStore := testutil.NewInMemoryRateCardStore()
+	svc := NewRateCardService(rateCardStore, customerStore, subscriptionStore, priceStore, testutil.NewInMemoryPriceBookStore(), nil, logger.GetLogger())
+
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{ID: "cust_1", BaseModel: types.GetDefaultBaseModel(ctx)}))
+	for _, sub := range []*subscription.Subscription{
+		{ID: "sub_1", SubscriptionStatus: types.SubscriptionStatusActive},
+		{ID: "sub_cancelled", SubscriptionStatus: types.SubscriptionStatusCancelled},
+	} {
+		sub.CustomerID = "cust_1"
+		sub.PlanID = "plan_1"
+		sub.Currency = "usd"
+		sub.BillingPeriod = types.BILLING_PERIOD_MONTHLY
+		sub.BillingPeriodCount = 1
+		sub.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, subscriptionStore.Create(ctx, sub))
+	}
+	for _, p := range []*price.Price{
+		{ID: "price_fixed", Amount: decimal.NewFromInt(20), Currency: "usd"},
+		{ID: "price_api", MeterID: "meter_api", Amount: decimal.NewFromInt(4), Currency: "usd"},
+		{ID: "price_eur", Amount: decimal.NewFromInt(20), Currency: "eur"},
+	} {
+		p.PlanID = "plan_1"
+		p.BillingPeriod = types.BILLING_PERIOD_MONTHLY
+		p.BillingPeriodCount = 1
+		p.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, priceStore.Create(ctx, p))
+	}
+
+	amount := decimal.NewFromInt(3)
+	require.NoError(t, rateCardStore.Create(ctx, &ratecard.RateCard{
+		ID:            "card_1",
+		CustomerID:    "cust_1",
+		Version:       1,
+		EffectiveFrom: time.Now().UTC().AddDate(0, -1, 0),
+		Overrides:     ratecard.Overrides{{MeterID: "meter_api", Amount: &amount}},
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}))
+
+	resp, err := svc.GetCustomerRates(ctx, dto.CustomerRatesRequest{CustomerID: "cust_1"})
+	require.NoError(t, err)
+	require.Len(t, resp.Subscriptions, 1)
+
+	rates := resp.Subscriptions[0]
+	assert.Equal(t, "card_1", rates.RateCardID)
+	require.Len(t, rates.Rates, 2)
+
+	byPrice := make(map[string]dto.Rate)
+	for _, rate := range rates.Rates {
+		byPrice[rate.PriceID] = rate
+	}
+
+	assert.Equal(t, "catalog", byPrice["price_fixed"].Source)
+	assert.True(t, byPrice["price_fixed"].Difference.IsZero())
+
+	api := byPrice["price_api"]
+	assert.Equal(t, "rate_card", api.Source)
+	assert.True(t, decimal.NewFromInt(-1).Equal(api.Difference))
+	require.NotNil(t, api.DifferencePercent)
+	assert.True(t, decimal.NewFromInt(-25).Equal(*api.DifferencePercent))
+
+	// Before the contract the list prices apply
+	resp, err = svc.GetCustomerRates(ctx, dto.CustomerRatesRequest{CustomerID: "cust_1", At: time.Now().UTC().AddDate(0, -2, 0)})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Subscriptions[0].RateCardID)
+}
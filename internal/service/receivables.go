@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/analytics"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+var (
+	// ErrInvalidReceivablesDate is returned for a receivables report of a day to come
+	ErrInvalidReceivablesDate = errors.New("date must not be in the future")
+	// ErrReceivablesSnapshotNotFound is returned for a receivables report of a
+	// past day the snapshot job did not run on
+	ErrReceivablesSnapshotNotFound = errors.New("receivables snapshot not found")
+)
+
+type ReceivablesService interface {
+	// GetReceivablesReport ages the amounts remaining on the finalized invoices
+	// by the days since they were finalized, as invoices have no due date
+	GetReceivablesReport(ctx context.Context, filter *types.ReceivablesFilter, now time.Time) (*dto.ReceivablesReportResponse, error)
+
+	// SnapshotReceivables records the receivables of every tenant with
+	// finalized invoices for the day of now, replacing an earlier run that day
+	SnapshotReceivables(ctx context.Context, now time.Time) error
+
+	// GetWriteOffReport totals the amounts written off as uncollectible in the
+	// period of the filter by currency, they are left out of the aging
+	GetWriteOffReport(ctx context.Context, filter *types.WriteOffFilter) (*dto.WriteOffReportResponse, error)
+}
+
+type receivablesService struct {
+	snapshotRepo analytics.ReceivablesRepository
+	invoiceRepo  invoice.Repository
+	logger       *logger.Logger
+}
+
+func NewReceivablesService(
+	snapshotRepo analytics.ReceivablesRepository,
+	invoiceRepo invoice.Repository,
+	logger *logger.Logger,
+) ReceivablesService {
+	return &receivablesService{
+		snapshotRepo: snapshotRepo,
+		invoiceRepo:  invoiceRepo,
+		logger:       logger,
+	}
+}
+
+func (s *receivablesService) GetReceivablesReport(ctx context.Context, filter *types.ReceivablesFilter, now time.Time) (*dto.ReceivablesReportResponse, error) {
+	now = now.UTC()
+	today := now.Truncate(24 * time.Hour)
+
+	if filter.Date == nil || filter.Date.Equal(today) {
+		snapshots, err := s.ageReceivables(ctx, filter.CustomerID, today, now)
+		if err != nil {
+			return nil, err
+		}
+		return newReceivablesReport(now, false, snapshots), nil
+	}
+
+	day := filter.Date.UTC().Truncate(24 * time.Hour)
+	if day.After(today) {
+		return nil, ErrInvalidReceivablesDate
+	}
+
+	asOf, err := s.snapshotRepo.GetSnapshotAsOf(ctx, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receivables snapshot: %w", err)
+	}
+	if asOf == nil {
+		return nil, fmt.Errorf("%w for %s", ErrReceivablesSnapshotNotFound, day.Format("2006-01-02"))
+	}
+
+	snapshots, err := s.snapshotRepo.List(ctx, day, filter.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receivables snapshots: %w", err)
+	}
+
+	return newReceivablesReport(*asOf, true, snapshots), nil
+}
+
+func (s *receivablesService) SnapshotReceivables(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	today := now.Truncate(24 * time.Hour)
+
+	tenantIDs, err := s.invoiceRepo.ListInvoicingTenantIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	forEachJobItem(ctx, tenantIDs, func(tenantID string) {
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, tenantID)
+		tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+		// One failing tenant must not hold back the others
+		snapshots, err := s.ageReceivables(tenantCtx, "", today, now)
+		if err == nil {
+			err = s.snapshotRepo.ReplaceDay(tenantCtx, today, now, snapshots)
+		}
+		if err != nil {
+			s.logger.Errorw("failed to snapshot receivables",
+				"tenant_id", tenantID,
+				"error", err,
+			)
+		}
+	})
+
+	return nil
+}
+
+// ageReceivables sums the amounts remaining of the outstanding invoices by
+// customer and currency, in buckets by their age at asOf
+func (s *receivablesService) ageReceivables(ctx context.Context, customerID string, day, asOf time.Time) ([]*analytics.ReceivablesSnapshot, error) {
+	invoices, err := s.invoiceRepo.ListOutstanding(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outstanding invoices: %w", err)
+	}
+
+	type key struct {
+		customerID string
+		currency   string
+	}
+	byCustomer := make(map[key]*analytics.ReceivablesSnapshot)
+
+	for _, inv := range invoices {
+		k := key{customerID: inv.CustomerID, currency: inv.Currency}
+		snapshot, ok := byCustomer[k]
+		if !ok {
+			snapshot = &analytics.ReceivablesSnapshot{
+				ID:           types.GenerateUUID(),
+				SnapshotDate: day,
+				CustomerID:   inv.CustomerID,
+				Currency:     inv.Currency,
+				BaseModel:    types.GetDefaultBaseModel(ctx),
+			}
+			byCustomer[k] = snapshot
+		}
+
+		var ageDays int
+		if inv.FinalizedAt != nil && asOf.After(*inv.FinalizedAt) {
+			ageDays = int(asOf.Sub(*inv.FinalizedAt).Hours() / 24)
+		}
+
+		switch {
+		case ageDays < 30:
+			snapshot.Current = snapshot.Current.Add(inv.AmountRemaining)
+		case ageDays < 60:
+			snapshot.Days30 = snapshot.Days30.Add(inv.AmountRemaining)
+		case ageDays < 90:
+			snapshot.Days60 = snapshot.Days60.Add(inv.AmountRemaining)
+		default:
+			snapshot.Days90Plus = snapshot.Days90Plus.Add(inv.AmountRemaining)
+		}
+		snapshot.Total = snapshot.Total.Add(inv.AmountRemaining)
+		snapshot.Invoices++
+	}
+
+	snapshots := make([]*analytics.ReceivablesSnapshot, 0, len(byCustomer))
+	for _, snapshot := range byCustomer {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].CustomerID == snapshots[j].CustomerID {
+			return snapshots[i].Currency < snapshots[j].Currency
+		}
+		return snapshots[i].CustomerID < snapshots[j].CustomerID
+	})
+
+	return snapshots, nil
+}
+
+// newReceivablesReport lists the receivables of each customer and sums them by
+// currency
+func newReceivablesReport(asOf time.Time, snapshotted bool, snapshots []*analytics.ReceivablesSnapshot) *dto.ReceivablesReportResponse {
+	response := &dto.ReceivablesReportResponse{
+		AsOf:        asOf,
+		Snapshotted: snapshotted,
+		Currencies:  []dto.ReceivablesAging{},
+		Customers:   make([]dto.CustomerReceivables, 0, len(snapshots)),
+	}
+
+	totals := make(map[string]*dto.ReceivablesAging)
+	for _, snapshot := range snapshots {
+		aging := dto.ReceivablesAging{
+			Currency:   snapshot.Currency,
+			Current:    snapshot.Current,
+			Days30:     snapshot.Days30,
+			Days60:     snapshot.Days60,
+			Days90Plus: snapshot.Days90Plus,
+			Total:      snapshot.Total,
+			Invoices:   snapshot.Invoices,
+		}
+		response.Customers = append(response.Customers, dto.CustomerReceivables{
+			CustomerID:       snapshot.CustomerID,
+			ReceivablesAging: aging,
+		})
+
+		total, ok := totals[snapshot.Currency]
+		if !ok {
+			total = &dto.ReceivablesAging{Currency: snapshot.Currency}
+			totals[snapshot.Currency] = total
+		}
+		total.Current = total.Current.Add(aging.Current)
+		total.Days30 = total.Days30.Add(aging.Days30)
+		total.Days60 = total.Days60.Add(aging.Days60)
+		total.Days90Plus = total.Days90Plus.Add(aging.Days90Plus)
+		total.Total = total.Total.Add(aging.Total)
+		total.Invoices += aging.Invoices
+	}
+
+	for _, total := range totals {
+		response.Currencies = append(response.Currencies, *total)
+	}
+	sort.Slice(response.Currencies, func(i, j int) bool {
+		return response.Currencies[i].Currency < response.Currencies[j].Currency
+	})
+
+	return response
+}
+
+func (s *receivablesService) GetWriteOffReport(ctx context.Context, filter *types.WriteOffFilter) (*dto.WriteOffReportResponse, error) {
+	invoices, err := s.invoiceRepo.ListWrittenOff(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list written off invoices: %w", err)
+	}
+
+	response := &dto.WriteOffReportResponse{
+		Currencies: []dto.WriteOffTotal{},
+		Invoices:   make([]dto.WrittenOffInvoice, 0, len(invoices)),
+	}
+
+	totals := make(map[string]*dto.WriteOffTotal)
+	for _, inv := range invoices {
+		written := dto.WrittenOffInvoice{
+			InvoiceID:        inv.ID,
+			CustomerID:       inv.CustomerID,
+			Currency:         inv.Currency,
+			AmountWrittenOff: inv.AmountWrittenOff,
+			AmountRemaining:  inv.AmountRemaining,
+			Reason:           inv.WriteOffReason,
+			WrittenOffAt:     *inv.WrittenOffAt,
+		}
+		if inv.InvoiceNumber != nil {
+			written.InvoiceNumber = *inv.InvoiceNumber
+		}
+		response.Invoices = append(response.Invoices, written)
+
+		total, ok := totals[inv.Currency]
+		if !ok {
+			total = &dto.WriteOffTotal{Currency: inv.Currency}
+			totals[inv.Currency] = total
+		}
+		total.Amount = total.Amount.Add(inv.AmountWrittenOff)
+		total.Invoices++
+	}
+
+	for _, total := range totals {
+		response.Currencies = append(response.Currencies, *total)
+	}
+	sort.Slice(response.Currencies, func(i, j int) bool {
+		return response.Currencies[i].Currency < response.Currencies[j].Currency
+	})
+
+	return response, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceivablesService(t *testing.T) {
+	ctx := testutil.SetupContext()
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	newInvoice := func(id, customerID, currency string, status types.InvoiceStatus, ageDays int, remaining int64) {
+		finalizedAt := now.AddDate(0, 0, -ageDays)
+		require.NoError(t, invoiceStore.Create(ctx, &invoice.Invoice{
+			ID:              id,
+			CustomerID:      customerID,
+			InvoiceStatus:   status,
+			Currency:        currency,
+			AmountDue:       decimal.NewFromInt(remaining),
+			AmountRemaining: decimal.NewFromInt(remaining),
+			FinalizedAt:     &finalizedAt,
+			BaseModel:       types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	newInvoice("inv_1", "cust_a", "usd", types.InvoiceStatusFinalized, 10, 100)
+	newInvoice("inv_2", "cust_a", "usd", types.InvoiceStatusFinalized, 45, 50)
+	newInvoice("inv_3", "cust_a", "eur", types.InvoiceStatusFinalized, 70, 20)
+	newInvoice("inv_4", "cust_b", "usd", types.InvoiceStatusFinalized, 120, 30)
+	// Paid and draft invoices are not receivable
+	newInvoice("inv_5", "cust_b", "usd", types.InvoiceStatusFinalized, 5, 0)
+	newInvoice("inv_6", "cust_b", "usd", types.InvoiceStatusDraft, 5, 40)
+
+	svc := NewReceivablesService(testutil.NewInMemoryReceivablesSnapshotStore(), invoiceStore, logger.GetLogger())
+
+	aging := func(currency string, current, days30, days60, days90Plus int64, invoices int) dto.ReceivablesAging {
+		return dto.ReceivablesAging{
+			Currency:   currency,
+			Current:    decimal.NewFromInt(current),
+			Days30:     decimal.NewFromInt(days30),
+			Days60:     decimal.NewFromInt(days60),
+			Days90Plus: decimal.NewFromInt(days90Plus),
+			Total:      decimal.NewFromInt(current + days30 + days60 + days90Plus),
+			Invoices:   invoices,
+		}
+	}
+
+	assertAging := func(t *testing.T, want, got dto.ReceivablesAging) {
+		assert.Equal(t, want.Currency, got.Currency)
+		assert.True(t, want.Current.Equal(got.Current), "current: want %s, got %s", want.Current, got.Current)
+		assert.True(t, want.Days30.Equal(got.Days30), "days_30: want %s, got %s", want.Days30, got.Days30)
+		assert.True(t, want.Days60.Equal(got.Days60), "days_60: want %s, got %s", want.Days60, got.Days60)
+		assert.True(t, want.Days90Plus.Equal(got.Days90Plus), "days_90_plus: want %s, got %s", want.Days90Plus, got.Days90Plus)
+		assert.True(t, want.Total.Equal(got.Total), "total: want %s, got %s", want.Total, got.Total)
+		assert.Equal(t, want.Invoices, got.Invoices)
+	}
+
+	t.Run("ages the outstanding invoices", func(t *testing.T) {
+		resp, err := svc.GetReceivablesReport(ctx, &types.ReceivablesFilter{}, now)
+		require.NoError(t, err)
+		assert.False(t, resp.Snapshotted)
+		assert.True(t, resp.AsOf.Equal(now))
+
+		require.Len(t, resp.Currencies, 2)
+		assertAging(t, aging("eur", 0, 0, 20, 0, 1), resp.Currencies[0])
+		assertAging(t, aging("usd", 100, 50, 0, 30, 3), resp.Currencies[1])
+
+		require.Len(t, resp.Customers, 3)
+		assert.Equal(t, "cust_a", resp.Customers[0].CustomerID)
+		assertAging(t, aging("eur", 0, 0, 20, 0, 1), resp.Customers[0].ReceivablesAging)
+		assert.Equal(t, "cust_a", resp.Customers[1].CustomerID)
+		assertAging(t, aging("usd", 100, 50, 0, 0, 2), resp.Customers[1].ReceivablesAging)
+		assert.Equal(t, "cust_b", resp.Customers[2].CustomerID)
+		assertAging(t, aging("usd", 0, 0, 0, 30, 1), resp.Customers[2].ReceivablesAging)
+	})
+
+	t.Run("filters by customer", func(t *testing.T) {
+		resp, err := svc.GetReceivablesReport(ctx, &types.ReceivablesFilter{CustomerID: "cust_b"}, now)
+		require.NoError(t, err)
+		require.Len(t, resp.Customers, 1)
+		require.Len(t, resp.Currencies, 1)
+		assertAging(t, aging("usd", 0, 0, 0, 30, 1), resp.Currencies[0])
+	})
+
+	t.Run("serves past days from their snapshot", func(t *testing.T) {
+		require.NoError(t, svc.SnapshotReceivables(ctx, now))
+
+		// inv_1 is paid the day after the snapshot
+		inv, err := invoiceStore.Get(ctx, "inv_1")
+		require.NoError(t, err)
+		inv.AmountPaid = inv.AmountDue
+		inv.RecalculateAmountRemaining()
+		require.NoError(t, invoiceStore.Update(ctx, inv))
+
+		tomorrow := now.AddDate(0, 0, 1)
+		resp, err := svc.GetReceivablesReport(ctx, &types.ReceivablesFilter{Date: &today}, tomorrow)
+		require.NoError(t, err)
+		assert.True(t, resp.Snapshotted)
+		assert.True(t, resp.AsOf.Equal(now))
+		require.Len(t, resp.Currencies, 2)
+		assertAging(t, aging("usd", 100, 50, 0, 30, 3), resp.Currencies[1])
+
+		resp, err = svc.GetReceivablesReport(ctx, &types.ReceivablesFilter{}, tomorrow)
+		require.NoError(t, err)
+		assert.False(t, resp.Snapshotted)
+		require.Len(t, resp.Currencies, 2)
+		assertAging(t, aging("usd", 0, 50, 0, 30, 2), resp.Currencies[1])
+	})
+
+	t.Run("rejects days without a snapshot or to come", func(t *testing.T) {
+		yesterday := today.AddDate(0, 0, -1)
+		_, err := svc.GetReceivablesReport(ctx, &types.ReceivablesFilter{Date: &yesterday}, now)
+		assert.ErrorIs(t, err, ErrReceivablesSnapshotNotFound)
+
+		tomorrow := today.AddDate(0, 0, 1)
+		_, err = svc.GetReceivablesReport(ctx, &types.ReceivablesFilter{Date: &tomorrow}, now)
+		assert.ErrorIs(t, err, ErrInvalidReceivablesDate)
+	})
+}
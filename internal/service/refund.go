@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/domain/sequence"
+	"github.com/flexprice/flexprice/internal/gateway"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+)
+
+type RefundService interface {
+	// CreateRefund refunds part or all of an invoice payment, at the gateway for
+	// payments collected through a connection. The refunded amount is taken off
+	// the amount paid of the invoice unless a credit note is issued for it
+	CreateRefund(ctx context.Context, paymentID string, req dto.CreateRefundRequest) (*dto.RefundResponse, error)
+	ListRefunds(ctx context.Context, paymentID string) (*dto.ListRefundsResponse, error)
+}
+
+// refundGateway is implemented by gateway.Client
+type refundGateway interface {
+	Refund(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*gateway.Refund, error)
+}
+
+type refundService struct {
+	invoiceRepo       invoice.Repository
+	sequenceRepo      sequence.Repository
+	legalEntityRepo   legalentity.Repository
+	connectionRepo    connection.Repository
+	connectionService ConnectionService
+	db                postgres.TxManager
+	webhookPublisher  webhook.Publisher
+	auditPublisher    audit.Publisher
+	logger            *logger.Logger
+
+	// clock is the time refunds are made at, the wall clock when nil
+	clock *clock.Clock
+
+	// newGateway is replaced in tests
+	newGateway func(conn *connection.Connection) (refundGateway, error)
+}
+
+func NewRefundService(
+	invoiceRepo invoice.Repository,
+	sequenceRepo sequence.Repository,
+	legalEntityRepo legalentity.Repository,
+	connectionRepo connection.Repository,
+	connectionService ConnectionService,
+	db postgres.TxManager,
+	webhookPublisher webhook.Publisher,
+	auditPublisher audit.Publisher,
+	clock *clock.Clock,
+	logger *logger.Logger,
+) RefundService {
+	return &refundService{
+		invoiceRepo:       invoiceRepo,
+		sequenceRepo:      sequenceRepo,
+		legalEntityRepo:   legalEntityRepo,
+		connectionRepo:    connectionRepo,
+		connectionService: connectionService,
+		db:                db,
+		webhookPublisher:  webhookPublisher,
+		auditPublisher:    auditPublisher,
+		clock:             clock,
+		logger:            logger,
+		newGateway: func(conn *connection.Connection) (refundGateway, error) {
+			return gateway.NewClient(conn)
+		},
+	}
+}
+
+func (s *refundService) CreateRefund(ctx context.Context, paymentID string, req dto.CreateRefundRequest) (*dto.RefundResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	payment, err := s.invoiceRepo.GetPayment(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	amount := payment.Refundable()
+	if req.Amount != nil {
+		if req.Amount.GreaterThan(amount) {
+			return nil, fmt.Errorf("invalid request: amount exceeds the refundable amount of %s", amount.String())
+		}
+		amount = *req.Amount
+	}
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("invalid request: payment is fully refunded")
+	}
+
+	refund := &invoice.Refund{
+		ID:           types.GenerateUUID(),
+		PaymentID:    payment.ID,
+		InvoiceID:    payment.InvoiceID,
+		CustomerID:   payment.CustomerID,
+		Amount:       amount,
+		Currency:     payment.Currency,
+		Reason:       req.Reason,
+		RefundStatus: types.RefundStatusSucceeded,
+		BaseModel:    types.GetDefaultBaseModel(ctx),
+	}
+
+	// The gateway is called before the ledgers are written as it can not be rolled
+	// back. It rejects refunds above what is left of the payment at the gateway
+	if payment.GatewayPaymentID != "" {
+		if err := s.refundAtGateway(ctx, payment, refund); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		inv, err := s.invoiceRepo.GetForUpdate(ctx, payment.InvoiceID)
+		if err != nil {
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+
+		// Read again under the lock of the invoice, which serializes the payments
+		// and refunds of the invoice
+		payment, err := s.invoiceRepo.GetPayment(ctx, paymentID)
+		if err != nil {
+			return fmt.Errorf("failed to get payment: %w", err)
+		}
+		if amount.GreaterThan(payment.Refundable()) {
+			return fmt.Errorf("invalid request: amount exceeds the refundable amount of %s", payment.Refundable().String())
+		}
+
+		now := s.clock.Now()
+		if req.CreateCreditNote {
+			if err := s.issueCreditNote(ctx, inv, refund, now); err != nil {
+				return err
+			}
+			inv.AmountDue = decimal.Max(inv.AmountDue.Sub(amount), decimal.Zero)
+		}
+
+		if err := s.invoiceRepo.CreateRefund(ctx, refund); err != nil {
+			return fmt.Errorf("failed to create refund: %w", err)
+		}
+
+		payment.AmountRefunded = payment.AmountRefunded.Add(amount)
+		payment.UpdatedAt = now
+		payment.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.UpdatePayment(ctx, payment); err != nil {
+			return err
+		}
+
+		inv.AmountPaid = inv.AmountPaid.Sub(amount)
+		inv.RecalculateAmountRemaining()
+		inv.UpdatedAt = now
+		inv.UpdatedBy = types.GetUserID(ctx)
+		if err := s.invoiceRepo.Update(ctx, inv); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventRefundCreated, refund); err != nil {
+			return fmt.Errorf("failed to publish refund created webhook: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		// The money already left at the gateway, the refund has to be reconciled
+		if refund.GatewayRefundID != "" {
+			s.logger.Errorw("failed to record gateway refund",
+				"payment_id", payment.ID,
+				"gateway_refund_id", refund.GatewayRefundID,
+				"error", err,
+			)
+		}
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRefund, refund.ID, types.AuditActionCreate, nil, refund)
+
+	s.logger.Debugw("refunded payment",
+		"payment_id", refund.PaymentID,
+		"refund_id", refund.ID,
+		"amount", refund.Amount,
+		"credit_note_id", refund.CreditNoteID,
+	)
+
+	return &dto.RefundResponse{Refund: refund}, nil
+}
+
+// refundAtGateway executes the refund at the gateway of the payment. Failed
+// refunds are kept and notified so that they can be retried or settled manually
+func (s *refundService) refundAtGateway(ctx context.Context, payment *invoice.Payment, refund *invoice.Refund) error {
+	conn, err := s.connectionRepo.Get(ctx, payment.ConnectionID)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	if !conn.Provider.IsGateway() {
+		return fmt.Errorf("connection is not a payment gateway connection")
+	}
+	client, err := s.newGateway(conn)
+	if err != nil {
+		return err
+	}
+
+	// The refund ID is the idempotency key of the refund, the gateway returns the
+	// refund made by an attempt of the sync queue that timed out instead of
+	// refunding the payment again
+	result, err := runOnSyncQueue(ctx, s.connectionService, conn.ID, types.SyncEntityTypeRefund, payment.GatewayPaymentID,
+		func(ctx context.Context) (*gateway.Refund, error) {
+			return client.Refund(ctx, payment.GatewayPaymentID, refund.Amount, refund.Currency, refund.ID)
+		})
+
+	switch {
+	case err != nil:
+		refund.FailureReason = err.Error()
+	case result.Status == types.RefundStatusFailed:
+		refund.GatewayRefundID = result.ID
+		refund.FailureReason = result.FailureReason
+	default:
+		refund.GatewayRefundID = result.ID
+		refund.RefundStatus = result.Status
+		return nil
+	}
+
+	refund.RefundStatus = types.RefundStatusFailed
+	if err := s.invoiceRepo.CreateRefund(ctx, refund); err != nil {
+		return fmt.Errorf("failed to create refund: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRefund, refund.ID, types.AuditActionCreate, nil, refund)
+
+	if err := s.webhookPublisher.Publish(ctx, types.WebhookEventRefundFailed, refund); err != nil {
+		s.logger.Errorw("failed to publish refund failed webhook", "refund_id", refund.ID, "error", err)
+	}
+
+	return fmt.Errorf("failed to refund payment at the gateway: %s", refund.FailureReason)
+}
+
+// issueCreditNote issues a finalized credit note for the refunded amount against
+// the invoice. Its credit is allocated to the refund so that it can not be
+// allocated again
+func (s *refundService) issueCreditNote(ctx context.Context, inv *invoice.Invoice, refund *invoice.Refund, now time.Time) error {
+	number, err := nextInvoiceNumber(ctx, s.sequenceRepo, s.legalEntityRepo, inv.LegalEntityID, now)
+	if err != nil {
+		return err
+	}
+
+	creditNote := &invoice.Invoice{
+		ID:                types.GenerateUUID(),
+		InvoiceNumber:     &number,
+		CustomerID:        inv.CustomerID,
+		SubscriptionID:    inv.SubscriptionID,
+		LegalEntityID:     inv.LegalEntityID,
+		InvoiceType:       types.InvoiceTypeCredit,
+		InvoiceStatus:     types.InvoiceStatusFinalized,
+		Currency:          inv.Currency,
+		OriginalInvoiceID: inv.ID,
+		Description:       refund.Reason,
+		FinalizedAt:       &now,
+		BaseModel:         types.GetDefaultBaseModel(ctx),
+	}
+	creditNote.LineItems = []*invoice.InvoiceLineItem{{
+		ID:             types.GenerateUUID(),
+		InvoiceID:      creditNote.ID,
+		CustomerID:     creditNote.CustomerID,
+		SubscriptionID: creditNote.SubscriptionID,
+		DisplayName:    "Refund",
+		Amount:         refund.Amount.Neg(),
+		Quantity:       decimal.NewFromInt(1),
+		Currency:       creditNote.Currency,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}}
+	creditNote.RecalculateTotals()
+
+	if err := s.invoiceRepo.Create(ctx, creditNote); err != nil {
+		return fmt.Errorf("failed to create credit note: %w", err)
+	}
+
+	err = s.invoiceRepo.CreateCreditAllocation(ctx, &invoice.CreditAllocation{
+		ID:              types.GenerateUUID(),
+		CreditInvoiceID: creditNote.ID,
+		CustomerID:      creditNote.CustomerID,
+		TargetType:      types.CreditAllocationTargetRefund,
+		TargetID:        refund.ID,
+		Amount:          refund.Amount,
+		Currency:        creditNote.Currency,
+		BaseModel:       types.GetDefaultBaseModel(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to allocate credit note: %w", err)
+	}
+
+	refund.CreditNoteID = creditNote.ID
+	return nil
+}
+
+func (s *refundService) ListRefunds(ctx context.Context, paymentID string) (*dto.ListRefundsResponse, error) {
+	payment, err := s.invoiceRepo.GetPayment(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	refunds, err := s.invoiceRepo.ListRefunds(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ListRefundsResponse{
+		Refunds:    make([]dto.RefundResponse, len(refunds)),
+		Refundable: payment.Refundable(),
+	}
+	for i, refund := range refunds {
+		response.Refunds[i] = dto.RefundResponse{Refund: refund}
+	}
+
+	return response, nil
+}
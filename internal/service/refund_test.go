@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/gateway"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRefundGateway struct {
+	status   types.RefundStatus
+	refunded []string
+	keys     []string
+}
+
+func (g *fakeRefundGateway) Refund(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*gateway.Refund, error) {
+	g.refunded = append(g.refunded, paymentID+" "+amount.String())
+	g.keys = append(g.keys, idempotencyKey)
+	return &gateway.Refund{ID: "re_1", Status: g.status, FailureReason: "expired_or_canceled_card"}, nil
+}
+
+func TestRefundService(t *testing.T) {
+	ctx := testutil.SetupContext()
+	manager := syncqueue.NewManager(&config.Configuration{
+		Integration: config.IntegrationConfig{SyncRatePerSecond: 100},
+	}, logger.GetLogger())
+	defer manager.Stop()
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	connectionStore := testutil.NewInMemoryConnectionStore()
+	connectionService := NewConnectionService(connectionStore, manager, nil, logger.GetLogger())
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:         "ep_1",
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{string(types.WebhookEventRefundCreated), string(types.WebhookEventRefundFailed)},
+		Secret:     "whsec_test",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	fakeGateway := &fakeRefundGateway{status: types.RefundStatusSucceeded}
+	svc := NewRefundService(invoiceStore, testutil.NewInMemorySequenceStore(), nil, connectionStore, connectionService,
+		testutil.NewInMemoryTxManager(), webhook.NewPublisher(webhookStore, logger.GetLogger()), nil, nil, logger.GetLogger()).(*refundService)
+	svc.newGateway = func(*connection.Connection) (refundGateway, error) { return fakeGateway, nil }
+
+	conn, err := connectionService.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:        "Stripe",
+		Provider:    types.IntegrationProviderStripe,
+		Credentials: "sk_test",
+	})
+	require.NoError(t, err)
+
+	inv := &invoice.Invoice{
+		ID:            "inv_1",
+		CustomerID:    "cust_1",
+		InvoiceType:   types.InvoiceTypeSubscription,
+		InvoiceStatus: types.InvoiceStatusFinalized,
+		Currency:      "usd",
+		Total:         decimal.NewFromInt(100),
+		AmountDue:     decimal.NewFromInt(100),
+		AmountPaid:    decimal.NewFromInt(100),
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+	inv.RecalculateAmountRemaining()
+	require.NoError(t, invoiceStore.Create(ctx, inv))
+
+	newPayment := func(id string, amount int64, gatewayPaymentID string) {
+		payment := &invoice.Payment{
+			ID:            id,
+			InvoiceID:     inv.ID,
+			CustomerID:    inv.CustomerID,
+			Amount:        decimal.NewFromInt(amount),
+			Currency:      "usd",
+			PaymentStatus: types.PaymentStatusSucceeded,
+			BaseModel:     types.GetDefaultBaseModel(ctx),
+		}
+		if gatewayPaymentID != "" {
+			payment.ConnectionID = conn.ID
+			payment.GatewayPaymentID = gatewayPaymentID
+		}
+		require.NoError(t, invoiceStore.CreatePayment(ctx, payment))
+	}
+	newPayment("pay_manual", 40, "")
+	newPayment("pay_card", 60, "pi_1")
+
+	t.Run("partial refund without credit note is owed again", func(t *testing.T) {
+		amount := decimal.NewFromInt(15)
+		resp, err := svc.CreateRefund(ctx, "pay_manual", dto.CreateRefundRequest{Amount: &amount})
+		require.NoError(t, err)
+		assert.Equal(t, types.RefundStatusSucceeded, resp.RefundStatus)
+		assert.Empty(t, resp.GatewayRefundID)
+
+		updated, err := invoiceStore.Get(ctx, inv.ID)
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(85).Equal(updated.AmountPaid))
+		assert.True(t, decimal.NewFromInt(15).Equal(updated.AmountRemaining))
+		assert.Equal(t, types.InvoicePaymentStatusPartiallyPaid, updated.PaymentStatus)
+	})
+
+	t.Run("amount above the refundable amount", func(t *testing.T) {
+		amount := decimal.NewFromInt(26)
+		_, err := svc.CreateRefund(ctx, "pay_manual", dto.CreateRefundRequest{Amount: &amount})
+		assert.Error(t, err)
+	})
+
+	t.Run("full gateway refund with a credit note", func(t *testing.T) {
+		resp, err := svc.CreateRefund(ctx, "pay_card", dto.CreateRefundRequest{CreateCreditNote: true, Reason: "Duplicate charge"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pi_1 60"}, fakeGateway.refunded)
+		assert.Equal(t, []string{resp.ID}, fakeGateway.keys)
+		assert.Equal(t, "re_1", resp.GatewayRefundID)
+		require.NotEmpty(t, resp.CreditNoteID)
+
+		creditNote, err := invoiceStore.Get(ctx, resp.CreditNoteID)
+		require.NoError(t, err)
+		assert.Equal(t, types.InvoiceTypeCredit, creditNote.InvoiceType)
+		assert.Equal(t, inv.ID, creditNote.OriginalInvoiceID)
+		assert.True(t, decimal.NewFromInt(60).Equal(creditNote.Credit()))
+
+		allocations, err := invoiceStore.ListCreditAllocations(ctx, creditNote.ID)
+		require.NoError(t, err)
+		require.Len(t, allocations, 1)
+		assert.Equal(t, types.CreditAllocationTargetRefund, allocations[0].TargetType)
+
+		// The credit note settles the refunded amount, nothing more is owed
+		updated, err := invoiceStore.Get(ctx, inv.ID)
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(40).Equal(updated.AmountDue))
+		assert.True(t, decimal.NewFromInt(15).Equal(updated.AmountRemaining))
+
+		list, err := svc.ListRefunds(ctx, "pay_card")
+		require.NoError(t, err)
+		require.Len(t, list.Refunds, 1)
+		assert.True(t, list.Refundable.IsZero())
+	})
+
+	t.Run("failed gateway refund is kept", func(t *testing.T) {
+		newPayment("pay_declined", 10, "ch_1")
+		fakeGateway.status = types.RefundStatusFailed
+
+		_, err := svc.CreateRefund(ctx, "pay_declined", dto.CreateRefundRequest{})
+		assert.Error(t, err)
+
+		list, err := svc.ListRefunds(ctx, "pay_declined")
+		require.NoError(t, err)
+		require.Len(t, list.Refunds, 1)
+		assert.Equal(t, types.RefundStatusFailed, list.Refunds[0].RefundStatus)
+		assert.Equal(t, "expired_or_canceled_card", list.Refunds[0].FailureReason)
+		assert.True(t, decimal.NewFromInt(10).Equal(list.Refundable))
+	})
+
+	deliveries, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	counts := make(map[types.WebhookEventType]int)
+	for _, d := range deliveries {
+		counts[d.EventType]++
+	}
+	assert.Equal(t, map[types.WebhookEventType]int{
+		types.WebhookEventRefundCreated: 2,
+		types.WebhookEventRefundFailed:  1,
+	}, counts)
+}
@@ -0,0 +1,569 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/report"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/email"
+	"github.com/flexprice/flexprice/internal/logger"
+	reportEngine "github.com/flexprice/flexprice/internal/report"
+	"github.com/flexprice/flexprice/internal/storage"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+)
+
+const (
+	reportPageSize = 500
+	// maxReportRecords bounds the records a run reads, as they are evaluated in memory
+	maxReportRecords = 100000
+	// reportLinkExpiry is how long the links emailed and sent by webhook are
+	// valid, the longest presigned URLs last
+	reportLinkExpiry = 7 * 24 * time.Hour
+
+	reportClaimBatch = 20
+	reportClaimLease = time.Hour
+)
+
+// ErrInvalidReportTemplate is returned for report templates which cannot be
+// evaluated, ex with unknown fields or columns neither grouped nor aggregated
+var ErrInvalidReportTemplate = errors.New("invalid report template")
+
+type ReportService interface {
+	CreateTemplate(ctx context.Context, req dto.ReportTemplateRequest) (*dto.ReportTemplateResponse, error)
+	GetTemplate(ctx context.Context, id string) (*dto.ReportTemplateResponse, error)
+	ListTemplates(ctx context.Context, filter types.Filter) (*dto.ListReportTemplatesResponse, error)
+	UpdateTemplate(ctx context.Context, id string, req dto.ReportTemplateRequest) (*dto.ReportTemplateResponse, error)
+	DeleteTemplate(ctx context.Context, id string) error
+
+	// RunTemplate creates a pending run of the template, run by the
+	// run_scheduled_reports job. The caller polls the run for its status
+	RunTemplate(ctx context.Context, id string) (*dto.ReportRunResponse, error)
+	GetRun(ctx context.Context, id string) (*dto.ReportRunResponse, error)
+	ListRuns(ctx context.Context, filter *types.ReportRunFilter) (*dto.ListReportRunsResponse, error)
+	GetRunDownloadURL(ctx context.Context, id string) (*dto.ExportDownloadResponse, error)
+
+	// RunScheduledReports creates the runs of the templates of all tenants
+	// whose schedule is due at now and schedules their next run, then runs the
+	// pending runs and the runs which were interrupted
+	RunScheduledReports(ctx context.Context, now time.Time) error
+}
+
+type reportService struct {
+	repo             report.Repository
+	customerRepo     customer.Repository
+	subscriptionRepo subscription.Repository
+	invoiceRepo      invoice.Repository
+	emailService     EmailService
+	webhookPublisher webhook.Publisher
+	store            storage.Store
+	cfg              config.ExportConfig
+	logger           *logger.Logger
+}
+
+func NewReportService(
+	repo report.Repository,
+	customerRepo customer.Repository,
+	subscriptionRepo subscription.Repository,
+	invoiceRepo invoice.Repository,
+	emailService EmailService,
+	webhookPublisher webhook.Publisher,
+	store storage.Store,
+	cfg *config.Configuration,
+	logger *logger.Logger,
+) ReportService {
+	return &reportService{
+		repo:             repo,
+		customerRepo:     customerRepo,
+		subscriptionRepo: subscriptionRepo,
+		invoiceRepo:      invoiceRepo,
+		emailService:     emailService,
+		webhookPublisher: webhookPublisher,
+		store:            store,
+		cfg:              cfg.Export,
+		logger:           logger,
+	}
+}
+
+func (s *reportService) CreateTemplate(ctx context.Context, req dto.ReportTemplateRequest) (*dto.ReportTemplateResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReportTemplate, err)
+	}
+
+	t := req.ToTemplate(ctx, time.Now().UTC())
+	if err := reportEngine.Validate(t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReportTemplate, err)
+	}
+
+	if err := s.repo.CreateTemplate(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to create report template: %w", err)
+	}
+
+	return &dto.ReportTemplateResponse{Template: t}, nil
+}
+
+func (s *reportService) GetTemplate(ctx context.Context, id string) (*dto.ReportTemplateResponse, error) {
+	t, err := s.repo.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report template: %w", err)
+	}
+
+	return &dto.ReportTemplateResponse{Template: t}, nil
+}
+
+func (s *reportService) ListTemplates(ctx context.Context, filter types.Filter) (*dto.ListReportTemplatesResponse, error) {
+	if filter.Limit == 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	templates, err := s.repo.ListTemplates(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report templates: %w", err)
+	}
+
+	response := &dto.ListReportTemplatesResponse{
+		Templates: make([]dto.ReportTemplateResponse, len(templates)),
+		Total:     len(templates),
+		Offset:    filter.Offset,
+		Limit:     filter.Limit,
+	}
+	for i, t := range templates {
+		response.Templates[i] = dto.ReportTemplateResponse{Template: t}
+	}
+
+	return response, nil
+}
+
+func (s *reportService) UpdateTemplate(ctx context.Context, id string, req dto.ReportTemplateRequest) (*dto.ReportTemplateResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReportTemplate, err)
+	}
+
+	t, err := s.repo.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report template: %w", err)
+	}
+
+	now := time.Now().UTC()
+	req.ApplyTo(t, now)
+	if err := reportEngine.Validate(t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReportTemplate, err)
+	}
+
+	t.UpdatedAt = now
+	t.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.repo.UpdateTemplate(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to update report template: %w", err)
+	}
+
+	return &dto.ReportTemplateResponse{Template: t}, nil
+}
+
+func (s *reportService) DeleteTemplate(ctx context.Context, id string) error {
+	if err := s.repo.DeleteTemplate(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete report template: %w", err)
+	}
+	return nil
+}
+
+func (s *reportService) RunTemplate(ctx context.Context, id string) (*dto.ReportRunResponse, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("reports are not configured")
+	}
+
+	t, err := s.repo.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report template: %w", err)
+	}
+
+	run, err := s.createRun(ctx, t, types.ReportRunTriggerManual, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ReportRunResponse{Run: run}, nil
+}
+
+func (s *reportService) GetRun(ctx context.Context, id string) (*dto.ReportRunResponse, error) {
+	run, err := s.repo.GetRun(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report run: %w", err)
+	}
+
+	return &dto.ReportRunResponse{Run: run}, nil
+}
+
+func (s *reportService) ListRuns(ctx context.Context, filter *types.ReportRunFilter) (*dto.ListReportRunsResponse, error) {
+	if filter.Limit == 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	runs, err := s.repo.ListRuns(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report runs: %w", err)
+	}
+
+	response := &dto.ListReportRunsResponse{
+		Runs:   make([]dto.ReportRunResponse, len(runs)),
+		Total:  len(runs),
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+	}
+	for i, run := range runs {
+		response.Runs[i] = dto.ReportRunResponse{Run: run}
+	}
+
+	return response, nil
+}
+
+func (s *reportService) GetRunDownloadURL(ctx context.Context, id string) (*dto.ExportDownloadResponse, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("reports are not configured")
+	}
+
+	run, err := s.repo.GetRun(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report run: %w", err)
+	}
+
+	if run.RunStatus != types.ReportRunStatusCompleted {
+		return nil, fmt.Errorf("report run is not completed, current status: %s", run.RunStatus)
+	}
+
+	expiry := defaultExportURLExpiry
+	if s.cfg.URLExpiryMins > 0 {
+		expiry = time.Duration(s.cfg.URLExpiryMins) * time.Minute
+	}
+
+	url, err := s.store.PresignGetURL(ctx, run.ObjectKey, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get download url: %w", err)
+	}
+
+	return &dto.ExportDownloadResponse{
+		URL:       url,
+		ExpiresAt: time.Now().UTC().Add(expiry),
+	}, nil
+}
+
+func (s *reportService) RunScheduledReports(ctx context.Context, now time.Time) error {
+	if s.store == nil {
+		return nil
+	}
+
+	now = now.UTC()
+	for ctx.Err() == nil {
+		templates, err := s.repo.ClaimDueTemplates(ctx, now, reportClaimLease, reportClaimBatch)
+		if err != nil {
+			return fmt.Errorf("failed to claim report templates: %w", err)
+		}
+
+		forEachJobItem(ctx, templates, func(t *report.Template) {
+			tenantCtx := context.WithValue(ctx, types.CtxTenantID, t.TenantID)
+			tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+			// The next run is scheduled first so that a failing run is not
+			// retried before its next occurrence
+			next := t.Schedule.Next(now)
+			t.NextRunAt = &next
+			t.UpdatedAt = time.Now().UTC()
+			if err := s.repo.UpdateTemplate(tenantCtx, t); err != nil {
+				s.logger.Errorw("failed to schedule report template",
+					"tenant_id", t.TenantID,
+					"template_id", t.ID,
+					"error", err,
+				)
+				return
+			}
+
+			if _, err := s.createRun(tenantCtx, t, types.ReportRunTriggerSchedule, now); err != nil {
+				s.logger.Errorw("failed to create scheduled report run",
+					"tenant_id", t.TenantID,
+					"template_id", t.ID,
+					"error", err,
+				)
+			}
+		})
+
+		if len(templates) < reportClaimBatch {
+			break
+		}
+	}
+
+	for ctx.Err() == nil {
+		// Claimed runs are marked processing, so each run is executed once per
+		// claim and the loop ends when no pending run is left
+		runs, err := s.repo.ClaimPendingRuns(ctx, now, reportClaimLease, reportClaimBatch)
+		if err != nil {
+			return fmt.Errorf("failed to claim report runs: %w", err)
+		}
+
+		forEachJobItem(ctx, runs, func(run *report.Run) {
+			tenantCtx := context.WithValue(ctx, types.CtxTenantID, run.TenantID)
+			tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+			t, err := s.repo.GetTemplate(tenantCtx, run.TemplateID)
+			if err != nil {
+				// The template was deleted after the run was created
+				run.Error = err.Error()
+				s.setRunStatus(tenantCtx, run, types.ReportRunStatusFailed)
+				return
+			}
+			s.execute(tenantCtx, t, run)
+		})
+
+		if len(runs) < reportClaimBatch {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *reportService) createRun(ctx context.Context, t *report.Template, trigger types.ReportRunTrigger, at time.Time) (*report.Run, error) {
+	run := &report.Run{
+		ID:         types.GenerateUUID(),
+		TemplateID: t.ID,
+		Trigger:    trigger,
+		RunStatus:  types.ReportRunStatusPending,
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}
+	if start, end, ok := t.Period.Range(at); ok {
+		run.PeriodStart = &start
+		run.PeriodEnd = &end
+	}
+
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create report run: %w", err)
+	}
+	return run, nil
+}
+
+// execute evaluates the template into a CSV in the export bucket and delivers
+// it to the destinations of the template. Failed deliveries are recorded on
+// the completed run
+func (s *reportService) execute(ctx context.Context, t *report.Template, run *report.Run) {
+	data, err := s.evaluate(ctx, t, run)
+	if err != nil {
+		s.logger.Errorw("report run failed", "run_id", run.ID, "template_id", t.ID, "error", err)
+		run.Error = err.Error()
+		s.setRunStatus(ctx, run, types.ReportRunStatusFailed)
+		return
+	}
+
+	now := time.Now().UTC()
+	run.RunStatus = types.ReportRunStatusCompleted
+	run.CompletedAt = &now
+
+	var deliveryErrors []string
+	for _, d := range t.Destinations {
+		if err := s.deliver(ctx, t, run, d, data); err != nil {
+			s.logger.Errorw("failed to deliver report",
+				"run_id", run.ID,
+				"destination", d.Type,
+				"error", err,
+			)
+			deliveryErrors = append(deliveryErrors, fmt.Sprintf("%s: %v", d.Type, err))
+		}
+	}
+	run.Error = strings.Join(deliveryErrors, "; ")
+
+	s.setRunStatus(ctx, run, types.ReportRunStatusCompleted)
+}
+
+func (s *reportService) setRunStatus(ctx context.Context, run *report.Run, status types.ReportRunStatus) {
+	now := time.Now().UTC()
+	run.RunStatus = status
+	run.UpdatedAt = now
+	run.LeaseUntil = nil
+	if status == types.ReportRunStatusFailed {
+		run.CompletedAt = &now
+	}
+
+	if err := s.repo.UpdateRun(ctx, run); err != nil {
+		s.logger.Errorw("failed to update report run status", "run_id", run.ID, "status", status, "error", err)
+	}
+}
+
+// evaluate reads the records of the template, evaluates them and uploads the
+// CSV, returning its content
+func (s *reportService) evaluate(ctx context.Context, t *report.Template, run *report.Run) ([]byte, error) {
+	records, err := s.listRecords(ctx, t, run)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluated := *t
+	// Line items are already read for the period only
+	if run.PeriodStart != nil && t.Entity != types.ReportEntityInvoiceLineItems {
+		field := reportEngine.PeriodField(t.Entity)
+		evaluated.Filters = append(append(report.Filters{}, t.Filters...),
+			report.Filter{Field: field, Operator: types.ReportFilterGte, Value: reportEngine.FormatTime(*run.PeriodStart)},
+			report.Filter{Field: field, Operator: types.ReportFilterLt, Value: reportEngine.FormatTime(*run.PeriodEnd)},
+		)
+	}
+
+	table, err := reportEngine.Evaluate(&evaluated, records)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReportTemplate, err)
+	}
+
+	var buf bytes.Buffer
+	if err := table.WriteCSV(&buf); err != nil {
+		return nil, err
+	}
+
+	run.ObjectKey = path.Join(s.cfg.Prefix, run.TenantID, "reports", run.ID+".csv")
+	if err := s.store.Upload(ctx, run.ObjectKey, bytes.NewReader(buf.Bytes()), "text/csv"); err != nil {
+		return nil, fmt.Errorf("failed to upload report: %w", err)
+	}
+
+	run.RowCount = int64(len(table.Rows))
+	return buf.Bytes(), nil
+}
+
+func (s *reportService) listRecords(ctx context.Context, t *report.Template, run *report.Run) ([]reportEngine.Record, error) {
+	var records []reportEngine.Record
+	add := func(record reportEngine.Record) error {
+		if len(records) >= maxReportRecords {
+			return fmt.Errorf("report reads more than %d records, narrow its period", maxReportRecords)
+		}
+		records = append(records, record)
+		return nil
+	}
+
+	filter := types.Filter{Limit: reportPageSize}
+	switch t.Entity {
+	case types.ReportEntityCustomers:
+		for {
+			page, err := s.customerRepo.List(ctx, filter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list customers: %w", err)
+			}
+			for _, c := range page {
+				if err := add(reportEngine.CustomerRecord(c)); err != nil {
+					return nil, err
+				}
+			}
+			if len(page) < filter.Limit {
+				return records, nil
+			}
+			filter.Offset += filter.Limit
+		}
+
+	case types.ReportEntitySubscriptions:
+		subFilter := &types.SubscriptionFilter{Filter: filter}
+		for {
+			page, err := s.subscriptionRepo.List(ctx, subFilter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+			}
+			for _, sub := range page {
+				if err := add(reportEngine.SubscriptionRecord(sub)); err != nil {
+					return nil, err
+				}
+			}
+			if len(page) < subFilter.Limit {
+				return records, nil
+			}
+			subFilter.Offset += subFilter.Limit
+		}
+
+	case types.ReportEntityInvoices:
+		invFilter := &types.InvoiceFilter{Filter: filter}
+		for {
+			page, err := s.invoiceRepo.List(ctx, invFilter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list invoices: %w", err)
+			}
+			for _, inv := range page {
+				if err := add(reportEngine.InvoiceRecord(inv)); err != nil {
+					return nil, err
+				}
+			}
+			if len(page) < invFilter.Limit {
+				return records, nil
+			}
+			invFilter.Offset += invFilter.Limit
+		}
+
+	case types.ReportEntityInvoiceLineItems:
+		// Without a period the line items of the periods started by the run are read
+		start, end := time.Time{}, run.CreatedAt
+		if run.PeriodStart != nil {
+			start, end = *run.PeriodStart, *run.PeriodEnd
+		}
+		items, err := s.invoiceRepo.ListFinalizedLineItems(ctx, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list invoice line items: %w", err)
+		}
+		for _, item := range items {
+			if err := add(reportEngine.InvoiceLineItemRecord(item)); err != nil {
+				return nil, err
+			}
+		}
+		return records, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported entity: %s", t.Entity)
+	}
+}
+
+func (s *reportService) deliver(ctx context.Context, t *report.Template, run *report.Run, d report.Destination, data []byte) error {
+	switch d.Type {
+	case types.ReportDestinationS3:
+		// Destinations stay in the folder of the tenant in the export bucket
+		key := path.Join(s.cfg.Prefix, run.TenantID, d.Prefix, run.ID+".csv")
+		return s.store.Upload(ctx, key, bytes.NewReader(data), "text/csv")
+
+	case types.ReportDestinationEmail:
+		url, expiresAt, err := s.presignReport(ctx, run)
+		if err != nil {
+			return err
+		}
+		return s.emailService.QueueReportEmail(ctx, d.Recipients, &email.ReportData{
+			ID:          run.ID,
+			Name:        t.Name,
+			RowCount:    run.RowCount,
+			PeriodStart: run.PeriodStart,
+			PeriodEnd:   run.PeriodEnd,
+			URL:         url,
+			ExpiresAt:   expiresAt,
+		})
+
+	case types.ReportDestinationWebhook:
+		url, expiresAt, err := s.presignReport(ctx, run)
+		if err != nil {
+			return err
+		}
+		return s.webhookPublisher.Publish(ctx, types.WebhookEventReportCompleted, &dto.ReportCompletedEvent{
+			Run:          run,
+			TemplateName: t.Name,
+			URL:          url,
+			ExpiresAt:    expiresAt,
+		})
+
+	default:
+		return fmt.Errorf("unsupported destination: %s", d.Type)
+	}
+}
+
+// presignReport returns a link to the CSV of the run for the destinations
+// which are not read right away
+func (s *reportService) presignReport(ctx context.Context, run *report.Run) (string, time.Time, error) {
+	url, err := s.store.PresignGetURL(ctx, run.ObjectKey, reportLinkExpiry)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get download url: %w", err)
+	}
+	return url, time.Now().UTC().Add(reportLinkExpiry), nil
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/report"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportService_RunScheduledReports(t *testing.T) {
+	ctx := testutil.SetupContext()
+	now := time.Date(2024, 6, 1, 0, 5, 0, 0, time.UTC)
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	newInvoice := func(id, currency string, total int64, createdAt time.Time) {
+		base := types.GetDefaultBaseModel(ctx)
+		base.CreatedAt = createdAt
+		require.NoError(t, invoiceStore.Create(ctx, &invoice.Invoice{
+			ID:            id,
+			CustomerID:    "cust_1",
+			InvoiceStatus: types.InvoiceStatusFinalized,
+			Currency:      currency,
+			Total:         decimal.NewFromInt(total),
+			BaseModel:     base,
+		}))
+	}
+	newInvoice("inv_1", "usd", 100, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC))
+	newInvoice("inv_2", "usd", 50, time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC))
+	newInvoice("inv_3", "eur", 30, time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC))
+	// Outside of the previous month
+	newInvoice("inv_4", "usd", 70, time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC))
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:         "ep_1",
+		URL:        "https://example.com/hooks",
+		Secret:     "whsec_test",
+		EventTypes: []string{string(types.WebhookEventReportCompleted)},
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	reportStore := testutil.NewInMemoryReportStore()
+	objectStore := testutil.NewInMemoryObjectStore()
+	emailStore := testutil.NewInMemoryEmailStore()
+	svc := NewReportService(
+		reportStore,
+		testutil.NewInMemoryCustomerStore(),
+		testutil.NewInMemorySubscriptionStore(),
+		invoiceStore,
+		NewEmailService(emailStore, testutil.NewInMemoryCustomerStore(), nil, stubEmailProvider{}, logger.GetLogger()),
+		webhook.NewPublisher(webhookStore, logger.GetLogger()),
+		objectStore,
+		&config.Configuration{Export: config.ExportConfig{Prefix: "exports"}},
+		logger.GetLogger(),
+	)
+
+	created, err := svc.CreateTemplate(ctx, dto.ReportTemplateRequest{
+		Name:    "Monthly revenue",
+		Entity:  types.ReportEntityInvoices,
+		GroupBy: []string{"currency"},
+		Columns: []report.Column{
+			{Field: "currency"},
+			{Field: "total", Aggregate: types.ReportAggregateSum},
+			{Aggregate: types.ReportAggregateCount},
+		},
+		Filters:  []report.Filter{{Field: "invoice_status", Operator: types.ReportFilterEq, Value: string(types.InvoiceStatusFinalized)}},
+		Period:   types.ReportPeriodPreviousMonth,
+		Schedule: types.ReportScheduleMonthly,
+		Destinations: []report.Destination{
+			{Type: types.ReportDestinationEmail, Recipients: []string{"finance@example.com"}},
+			{Type: types.ReportDestinationS3, Prefix: "finance"},
+			{Type: types.ReportDestinationWebhook},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, created.NextRunAt)
+
+	// The template is due at the start of the month
+	due := created.Template
+	dueAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	due.NextRunAt = &dueAt
+	require.NoError(t, reportStore.UpdateTemplate(ctx, due))
+
+	require.NoError(t, svc.RunScheduledReports(context.Background(), now))
+
+	runs, err := svc.ListRuns(ctx, &types.ReportRunFilter{TemplateID: created.ID})
+	require.NoError(t, err)
+	require.Len(t, runs.Runs, 1)
+
+	run := runs.Runs[0]
+	assert.Equal(t, types.ReportRunStatusCompleted, run.RunStatus)
+	assert.Equal(t, types.ReportRunTriggerSchedule, run.Trigger)
+	assert.Empty(t, run.Error)
+	assert.Equal(t, int64(2), run.RowCount)
+	assert.True(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Equal(*run.PeriodStart))
+	assert.True(t, dueAt.Equal(*run.PeriodEnd))
+
+	csv, ok := objectStore.Object(run.ObjectKey)
+	require.True(t, ok)
+	assert.Equal(t, "currency,sum(total),count\neur,30,1\nusd,150,2\n", string(csv))
+
+	copied, ok := objectStore.Object("exports/" + types.DefaultTenantID + "/finance/" + run.ID + ".csv")
+	require.True(t, ok)
+	assert.Equal(t, csv, copied)
+
+	emails, err := emailStore.ListDeliveries(ctx, &types.EmailDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, emails, 1)
+	assert.Equal(t, "finance@example.com", emails[0].Recipient)
+	assert.Equal(t, types.EmailTemplateReportReady, emails[0].TemplateType)
+	assert.Contains(t, emails[0].BodyText, "memory://"+run.ObjectKey)
+
+	hooks, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, types.WebhookEventReportCompleted, hooks[0].EventType)
+
+	// The next run is a month later and nothing is due until then
+	scheduled, err := svc.GetTemplate(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC).Equal(*scheduled.NextRunAt))
+
+	require.NoError(t, svc.RunScheduledReports(context.Background(), now.Add(time.Hour)))
+	runs, err = svc.ListRuns(ctx, &types.ReportRunFilter{TemplateID: created.ID})
+	require.NoError(t, err)
+	assert.Len(t, runs.Runs, 1)
+}
+
+func TestReportService_CreateTemplateInvalid(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc := NewReportService(
+		testutil.NewInMemoryReportStore(),
+		testutil.NewInMemoryCustomerStore(),
+		testutil.NewInMemorySubscriptionStore(),
+		testutil.NewInMemoryInvoiceStore(),
+		nil,
+		nil,
+		testutil.NewInMemoryObjectStore(),
+		&config.Configuration{},
+		logger.GetLogger(),
+	)
+
+	tests := []struct {
+		name string
+		req  dto.ReportTemplateRequest
+	}{
+		{
+			name: "column neither grouped nor aggregated",
+			req: dto.ReportTemplateRequest{
+				Name:    "By currency",
+				Entity:  types.ReportEntityInvoices,
+				GroupBy: []string{"currency"},
+				Columns: []report.Column{{Field: "customer_id"}},
+			},
+		},
+		{
+			name: "invalid recipient",
+			req: dto.ReportTemplateRequest{
+				Name:         "Customers",
+				Entity:       types.ReportEntityCustomers,
+				Columns:      []report.Column{{Field: "id"}},
+				Destinations: []report.Destination{{Type: types.ReportDestinationEmail, Recipients: []string{"finance"}}},
+			},
+		},
+		{
+			name: "unknown schedule",
+			req: dto.ReportTemplateRequest{
+				Name:     "Customers",
+				Entity:   types.ReportEntityCustomers,
+				Columns:  []report.Column{{Field: "id"}},
+				Schedule: "hourly",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateTemplate(ctx, tt.req)
+			assert.ErrorIs(t, err, ErrInvalidReportTemplate)
+		})
+	}
+}
+
+func TestReportService_RunTemplate(t *testing.T) {
+	ctx := testutil.SetupContext()
+	now := time.Now().UTC()
+
+	reportStore := testutil.NewInMemoryReportStore()
+	objectStore := testutil.NewInMemoryObjectStore()
+	svc := NewReportService(
+		reportStore,
+		testutil.NewInMemoryCustomerStore(),
+		testutil.NewInMemorySubscriptionStore(),
+		testutil.NewInMemoryInvoiceStore(),
+		nil,
+		nil,
+		objectStore,
+		&config.Configuration{Export: config.ExportConfig{Prefix: "exports"}},
+		logger.GetLogger(),
+	)
+
+	newTemplate := func(name string) *dto.ReportTemplateResponse {
+		created, err := svc.CreateTemplate(ctx, dto.ReportTemplateRequest{
+			Name:    name,
+			Entity:  types.ReportEntityCustomers,
+			Columns: []report.Column{{Field: "id"}},
+		})
+		require.NoError(t, err)
+		return created
+	}
+	template := newTemplate("Customers")
+	deleted := newTemplate("Deleted")
+
+	// Manual runs are left pending for the job
+	manual, err := svc.RunTemplate(ctx, template.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.ReportRunStatusPending, manual.RunStatus)
+
+	orphan, err := svc.RunTemplate(ctx, deleted.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTemplate(ctx, deleted.ID))
+
+	// A run interrupted by a restart, and a run still being executed
+	newProcessing := func(id string, leaseUntil time.Time) {
+		require.NoError(t, reportStore.CreateRun(ctx, &report.Run{
+			ID:         id,
+			TemplateID: template.ID,
+			Trigger:    types.ReportRunTriggerManual,
+			RunStatus:  types.ReportRunStatusProcessing,
+			LeaseUntil: &leaseUntil,
+			BaseModel:  types.GetDefaultBaseModel(ctx),
+		}))
+	}
+	newProcessing("run_stuck", now.Add(-time.Minute))
+	newProcessing("run_running", now.Add(time.Minute))
+
+	require.NoError(t, svc.RunScheduledReports(context.Background(), now))
+
+	getRun := func(id string) *report.Run {
+		run, err := reportStore.GetRun(ctx, id)
+		require.NoError(t, err)
+		return run
+	}
+
+	completed := getRun(manual.ID)
+	assert.Equal(t, types.ReportRunStatusCompleted, completed.RunStatus)
+	assert.Nil(t, completed.LeaseUntil)
+	_, ok := objectStore.Object(completed.ObjectKey)
+	assert.True(t, ok)
+
+	assert.Equal(t, types.ReportRunStatusCompleted, getRun("run_stuck").RunStatus)
+	assert.Equal(t, types.ReportRunStatusProcessing, getRun("run_running").RunStatus)
+
+	failed := getRun(orphan.ID)
+	assert.Equal(t, types.ReportRunStatusFailed, failed.RunStatus)
+	assert.NotEmpty(t, failed.Error)
+}
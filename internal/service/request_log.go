@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/requestlog"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const (
+	redactedValue    = "[REDACTED]"
+	truncatedSuffix  = "...[TRUNCATED]"
+	omittedBodyValue = "[OMITTED: body is not valid JSON]"
+)
+
+// sensitiveKeys are matched as substrings of the lower cased JSON keys whose
+// values are redacted from the logged bodies
+var sensitiveKeys = []string{
+	"email", "phone", "address", "password", "secret", "token",
+	"api_key", "authorization", "card", "iban", "ssn", "tax_id",
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+type RequestLogService interface {
+	// RecordRequest redacts and truncates the bodies of the request log and stores it
+	RecordRequest(ctx context.Context, log *requestlog.RequestLog) error
+	ListRequestLogs(ctx context.Context, filter *types.RequestLogFilter) (*dto.ListRequestLogsResponse, error)
+}
+
+type requestLogService struct {
+	cfg            config.RequestLogConfig
+	requestLogRepo requestlog.Repository
+	logger         *logger.Logger
+}
+
+func NewRequestLogService(
+	cfg *config.Configuration,
+	requestLogRepo requestlog.Repository,
+	logger *logger.Logger,
+) RequestLogService {
+	return &requestLogService{
+		cfg:            cfg.RequestLog,
+		requestLogRepo: requestLogRepo,
+		logger:         logger,
+	}
+}
+
+func (s *requestLogService) RecordRequest(ctx context.Context, log *requestlog.RequestLog) error {
+	if log.ID == "" {
+		log.ID = types.GenerateUUID()
+	}
+
+	log.RequestBody = s.sanitizeBody(log.RequestBody)
+	log.ResponseBody = s.sanitizeBody(log.ResponseBody)
+
+	if err := s.requestLogRepo.Insert(ctx, log); err != nil {
+		return fmt.Errorf("failed to record request log: %w", err)
+	}
+
+	return nil
+}
+
+func (s *requestLogService) ListRequestLogs(ctx context.Context, filter *types.RequestLogFilter) (*dto.ListRequestLogsResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	logs, err := s.requestLogRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list request logs: %w", err)
+	}
+
+	response := &dto.ListRequestLogsResponse{
+		Logs:   make([]dto.RequestLogResponse, len(logs)),
+		Total:  len(logs),
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+	}
+
+	for i, l := range logs {
+		response.Logs[i] = dto.RequestLogResponse{RequestLog: l}
+	}
+
+	return response, nil
+}
+
+// sanitizeBody redacts the personal data of a JSON body and cuts it at the
+// configured size. Bodies that are not JSON can not be redacted and are omitted
+func (s *requestLogService) sanitizeBody(body string) string {
+	if strings.TrimSpace(body) == "" {
+		return ""
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return omittedBodyValue
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return omittedBodyValue
+	}
+
+	if s.cfg.MaxBodyBytes > 0 && len(redacted) > s.cfg.MaxBodyBytes {
+		cut := s.cfg.MaxBodyBytes
+		for cut > 0 && !utf8.RuneStart(redacted[cut]) {
+			cut--
+		}
+		return string(redacted[:cut]) + truncatedSuffix
+	}
+
+	return string(redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if isSensitiveKey(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(nested)
+		}
+		return v
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = redactValue(nested)
+		}
+		return v
+	case string:
+		return emailPattern.ReplaceAllString(v, redactedValue)
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
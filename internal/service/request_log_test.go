@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/requestlog"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRequestLogServiceTest(maxBodyBytes int) (RequestLogService, *testutil.InMemoryRequestLogStore) {
+	store := testutil.NewInMemoryRequestLogStore()
+	cfg := &config.Configuration{RequestLog: config.RequestLogConfig{Enabled: true, MaxBodyBytes: maxBodyBytes}}
+	return NewRequestLogService(cfg, store, logger.GetLogger()), store
+}
+
+func TestRequestLogService_RecordRequest_RedactsBodies(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, store := setupRequestLogServiceTest(4096)
+
+	log := &requestlog.RequestLog{
+		TenantID:     types.GetTenantID(ctx),
+		APIKeyID:     "key_123",
+		Method:       "POST",
+		Path:         "/v1/customers",
+		Endpoint:     "/v1/customers",
+		StatusCode:   201,
+		RequestBody:  `{"external_id":"cust_1","email":"jane@example.com","metadata":{"billing_address":"1 Main St","note":"contact jane@example.com"},"contacts":[{"phone_number":"555"}]}`,
+		ResponseBody: `{"id":"c_1","Email":"jane@example.com"}`,
+		Timestamp:    time.Now(),
+	}
+	require.NoError(t, svc.RecordRequest(ctx, log))
+
+	logs, err := store.List(ctx, &types.RequestLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.NotEmpty(t, logs[0].ID)
+
+	assert.JSONEq(t,
+		`{"external_id":"cust_1","email":"[REDACTED]","metadata":{"billing_address":"[REDACTED]","note":"contact [REDACTED]"},"contacts":[{"phone_number":"[REDACTED]"}]}`,
+		logs[0].RequestBody,
+	)
+	assert.JSONEq(t, `{"id":"c_1","Email":"[REDACTED]"}`, logs[0].ResponseBody)
+}
+
+func TestRequestLogService_RecordRequest_SanitizesBodies(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "empty", body: "", want: ""},
+		{name: "not json", body: "name=jane&email=jane@example.com", want: omittedBodyValue},
+		{name: "cut json", body: `{"external_id":"cust_`, want: omittedBodyValue},
+		{name: "truncated", body: `{"description":"` + strings.Repeat("a", 64) + `"}`, want: `{"description":"aaaa` + truncatedSuffix},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testutil.SetupContext()
+			svc, store := setupRequestLogServiceTest(20)
+
+			require.NoError(t, svc.RecordRequest(ctx, &requestlog.RequestLog{
+				TenantID:    types.GetTenantID(ctx),
+				RequestBody: tt.body,
+				Timestamp:   time.Now(),
+			}))
+
+			logs, err := store.List(ctx, &types.RequestLogFilter{})
+			require.NoError(t, err)
+			require.Len(t, logs, 1)
+			assert.Equal(t, tt.want, logs[0].RequestBody)
+		})
+	}
+}
+
+func TestRequestLogService_ListRequestLogs(t *testing.T) {
+	ctx := testutil.SetupContext()
+	svc, _ := setupRequestLogServiceTest(4096)
+
+	now := time.Now()
+	record := func(ctx context.Context, id, endpoint string, statusCode int, age time.Duration) {
+		require.NoError(t, svc.RecordRequest(ctx, &requestlog.RequestLog{
+			ID:         id,
+			TenantID:   types.GetTenantID(ctx),
+			APIKeyID:   "key_123",
+			Method:     "GET",
+			Endpoint:   endpoint,
+			StatusCode: statusCode,
+			Timestamp:  now.Add(-age),
+		}))
+	}
+
+	record(ctx, "log_1", "/v1/customers/:id", 200, 3*time.Minute)
+	record(ctx, "log_2", "/v1/customers/:id", 404, 2*time.Minute)
+	record(ctx, "log_3", "/v1/plans", 500, time.Minute)
+
+	otherTenantCtx := context.WithValue(ctx, types.CtxTenantID, "tenant_other")
+	record(otherTenantCtx, "log_other", "/v1/plans", 500, 0)
+
+	tests := []struct {
+		name   string
+		filter types.RequestLogFilter
+		want   []string
+	}{
+		{name: "all", filter: types.RequestLogFilter{}, want: []string{"log_3", "log_2", "log_1"}},
+		{name: "failed", filter: types.RequestLogFilter{Status: types.RequestLogStatusFailed}, want: []string{"log_3", "log_2"}},
+		{name: "succeeded", filter: types.RequestLogFilter{Status: types.RequestLogStatusSucceeded}, want: []string{"log_1"}},
+		{name: "status code", filter: types.RequestLogFilter{StatusCode: 404}, want: []string{"log_2"}},
+		{name: "endpoint", filter: types.RequestLogFilter{Endpoint: "/v1/customers/:id"}, want: []string{"log_2", "log_1"}},
+		{name: "paginated", filter: types.RequestLogFilter{Limit: 1, Offset: 1}, want: []string{"log_2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			resp, err := svc.ListRequestLogs(ctx, &filter)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, l := range resp.Logs {
+				ids = append(ids, l.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
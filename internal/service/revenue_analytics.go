@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/analytics"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidMonthRange is returned when the months of a revenue analytics
+// request are out of order or too many
+var ErrInvalidMonthRange = errors.New("invalid month range")
+
+type RevenueAnalyticsService interface {
+	// GetRevenueAnalytics reports the MRR, ARR, retention and churn of each month
+	// of the filter. The MRR of a subscription in a month is the revenue of the
+	// charges of its finalized invoices whose period covers the end of the
+	// month, per month of its billing period. Closed months are snapshotted the
+	// first time they are reported
+	GetRevenueAnalytics(ctx context.Context, filter *types.RevenueAnalyticsFilter, now time.Time) (*dto.RevenueAnalyticsResponse, error)
+
+	// RefreshSnapshots computes the snapshots of the closed months of the range
+	// from the invoices again
+	RefreshSnapshots(ctx context.Context, req *dto.RefreshRevenueSnapshotsRequest, now time.Time) (*dto.RefreshRevenueSnapshotsResponse, error)
+}
+
+type revenueAnalyticsService struct {
+	snapshotRepo     analytics.Repository
+	invoiceRepo      invoice.Repository
+	subscriptionRepo subscription.Repository
+	customerRepo     customer.Repository
+	logger           *logger.Logger
+}
+
+func NewRevenueAnalyticsService(
+	snapshotRepo analytics.Repository,
+	invoiceRepo invoice.Repository,
+	subscriptionRepo subscription.Repository,
+	customerRepo customer.Repository,
+	logger *logger.Logger,
+) RevenueAnalyticsService {
+	return &revenueAnalyticsService{
+		snapshotRepo:     snapshotRepo,
+		invoiceRepo:      invoiceRepo,
+		subscriptionRepo: subscriptionRepo,
+		customerRepo:     customerRepo,
+		logger:           logger,
+	}
+}
+
+// revenueGroupKey is a group of the revenue analytics in one currency
+type revenueGroupKey struct {
+	id       string
+	currency string
+}
+
+// snapshotKey is the revenue of a subscription in one currency
+type snapshotKey struct {
+	subscriptionID string
+	currency       string
+}
+
+func (s *revenueAnalyticsService) GetRevenueAnalytics(ctx context.Context, filter *types.RevenueAnalyticsFilter, now time.Time) (*dto.RevenueAnalyticsResponse, error) {
+	start, end, err := monthRange(filter.StartMonth, filter.EndMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	// The month before the first one is the baseline of its movements
+	revenue, snapshotted, err := s.getMonthlyRevenue(ctx, start.AddDate(0, -1, 0), end, now)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.RevenueAnalyticsResponse{
+		GroupBy: filter.GroupBy,
+		Months:  []dto.RevenueMonth{},
+	}
+	for month := start; month.Before(end); month = month.AddDate(0, 1, 0) {
+		response.Months = append(response.Months, dto.RevenueMonth{
+			Month:       month,
+			Snapshotted: snapshotted[month],
+			Groups:      reportRevenueMonth(filter.GroupBy, revenue[month.AddDate(0, -1, 0)], revenue[month]),
+		})
+	}
+
+	return response, nil
+}
+
+func (s *revenueAnalyticsService) RefreshSnapshots(ctx context.Context, req *dto.RefreshRevenueSnapshotsRequest, now time.Time) (*dto.RefreshRevenueSnapshotsResponse, error) {
+	start, end, err := monthRange(req.StartMonth, req.EndMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	var closed []time.Time
+	for month := start; month.Before(end) && isClosedMonth(month, now); month = month.AddDate(0, 1, 0) {
+		closed = append(closed, month)
+	}
+
+	response := &dto.RefreshRevenueSnapshotsResponse{Months: []time.Time{}}
+	if len(closed) == 0 {
+		return response, nil
+	}
+
+	revenue, err := s.computeMonthlyRevenue(ctx, closed)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, month := range closed {
+		if err := s.snapshotRepo.ReplaceMonth(ctx, month, revenue[month]); err != nil {
+			return nil, fmt.Errorf("failed to snapshot revenue of %s: %w", month.Format("2006-01"), err)
+		}
+		response.Months = append(response.Months, month)
+	}
+
+	return response, nil
+}
+
+// getMonthlyRevenue returns the revenue snapshots of the months in [start, end)
+// and whether each month was served from its snapshot. The months not
+// snapshotted yet are computed, and snapshotted once they are closed
+func (s *revenueAnalyticsService) getMonthlyRevenue(ctx context.Context, start, end, now time.Time) (map[time.Time][]*analytics.RevenueSnapshot, map[time.Time]bool, error) {
+	months, err := s.snapshotRepo.ListMonths(ctx, start, end)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list revenue snapshot months: %w", err)
+	}
+
+	snapshotted := make(map[time.Time]bool, len(months))
+	for _, month := range months {
+		snapshotted[month] = true
+	}
+
+	snapshots, err := s.snapshotRepo.List(ctx, start, end)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list revenue snapshots: %w", err)
+	}
+
+	revenue := make(map[time.Time][]*analytics.RevenueSnapshot)
+	for _, snapshot := range snapshots {
+		revenue[snapshot.Month] = append(revenue[snapshot.Month], snapshot)
+	}
+
+	var missing []time.Time
+	for month := start; month.Before(end); month = month.AddDate(0, 1, 0) {
+		if !snapshotted[month] {
+			missing = append(missing, month)
+		}
+	}
+	if len(missing) == 0 {
+		return revenue, snapshotted, nil
+	}
+
+	computed, err := s.computeMonthlyRevenue(ctx, missing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, month := range missing {
+		revenue[month] = computed[month]
+		if !isClosedMonth(month, now) {
+			continue
+		}
+		if err := s.snapshotRepo.ReplaceMonth(ctx, month, computed[month]); err != nil {
+			return nil, nil, fmt.Errorf("failed to snapshot revenue of %s: %w", month.Format("2006-01"), err)
+		}
+		snapshotted[month] = true
+	}
+
+	return revenue, snapshotted, nil
+}
+
+// computeMonthlyRevenue computes the MRR of every subscription in each of the
+// months from the charges of the finalized invoices. Charges are looked up to a
+// year before the first month, longer billing periods are not covered
+func (s *revenueAnalyticsService) computeMonthlyRevenue(ctx context.Context, months []time.Time) (map[time.Time][]*analytics.RevenueSnapshot, error) {
+	items, err := s.invoiceRepo.ListFinalizedLineItems(ctx, months[0].AddDate(-1, 0, 0), months[len(months)-1].AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoiced charges: %w", err)
+	}
+
+	subs := make(map[string]*subscription.Subscription)
+	customers := make(map[string]*customer.Customer)
+	revenue := make(map[time.Time][]*analytics.RevenueSnapshot, len(months))
+
+	for _, month := range months {
+		monthEnd := month.AddDate(0, 1, 0)
+		snapshots := make(map[snapshotKey]*analytics.RevenueSnapshot)
+
+		for _, item := range items {
+			// Only the charges of subscriptions recur, and only those of the
+			// period running at the end of the month count towards its MRR
+			if item.SubscriptionID == "" || item.PeriodStart == nil || item.PeriodEnd == nil {
+				continue
+			}
+			if !item.PeriodStart.Before(monthEnd) || item.PeriodEnd.Before(monthEnd) {
+				continue
+			}
+
+			sub := s.getSubscription(ctx, subs, item.SubscriptionID)
+			if sub == nil {
+				continue
+			}
+			periodMonths := billingPeriodMonths(sub)
+			if periodMonths.IsZero() {
+				continue
+			}
+
+			key := snapshotKey{subscriptionID: sub.ID, currency: item.Currency}
+			snapshot, ok := snapshots[key]
+			if !ok {
+				snapshot = &analytics.RevenueSnapshot{
+					ID:             types.GenerateUUID(),
+					Month:          month,
+					SubscriptionID: sub.ID,
+					CustomerID:     sub.CustomerID,
+					PlanID:         sub.PlanID,
+					Currency:       item.Currency,
+					BaseModel:      types.GetDefaultBaseModel(ctx),
+				}
+				if c := s.getCustomer(ctx, customers, sub.CustomerID); c != nil {
+					snapshot.Segment = c.Metadata[types.CustomerSegmentMetadataKey]
+				}
+				snapshots[key] = snapshot
+			}
+			snapshot.MRR = snapshot.MRR.Add(item.Amount.Div(periodMonths))
+		}
+
+		revenue[month] = make([]*analytics.RevenueSnapshot, 0, len(snapshots))
+		for _, snapshot := range snapshots {
+			revenue[month] = append(revenue[month], snapshot)
+		}
+		sort.Slice(revenue[month], func(i, j int) bool {
+			return revenue[month][i].SubscriptionID < revenue[month][j].SubscriptionID
+		})
+	}
+
+	return revenue, nil
+}
+
+// getSubscription returns the subscription of a charge, nil when it was deleted
+// since
+func (s *revenueAnalyticsService) getSubscription(ctx context.Context, subs map[string]*subscription.Subscription, id string) *subscription.Subscription {
+	if sub, ok := subs[id]; ok {
+		return sub
+	}
+
+	sub, err := s.subscriptionRepo.Get(ctx, id)
+	if err != nil {
+		s.logger.Debugw("subscription of invoiced charge not found, its revenue is not recurring",
+			"subscription_id", id,
+			"error", err,
+		)
+		sub = nil
+	}
+	subs[id] = sub
+	return sub
+}
+
+// getCustomer returns the customer of a subscription, nil when it was deleted
+// since. Its revenue is then reported without a segment
+func (s *revenueAnalyticsService) getCustomer(ctx context.Context, customers map[string]*customer.Customer, id string) *customer.Customer {
+	if c, ok := customers[id]; ok {
+		return c
+	}
+
+	c, err := s.customerRepo.Get(ctx, id)
+	if err != nil {
+		s.logger.Debugw("customer of subscription not found, its revenue has no segment",
+			"customer_id", id,
+			"error", err,
+		)
+		c = nil
+	}
+	customers[id] = c
+	return c
+}
+
+// reportRevenueMonth reports the MRR of a month by group, and its movements
+// from the month before customer by customer
+func reportRevenueMonth(groupBy types.RevenueGroupBy, previous, current []*analytics.RevenueSnapshot) []dto.RevenueGroup {
+	// The MRR of each customer in each group, at the end of the month before
+	// and of the month
+	type customerRevenue struct {
+		previous decimal.Decimal
+		current  decimal.Decimal
+	}
+	groups := make(map[revenueGroupKey]map[string]*customerRevenue)
+
+	add := func(snapshot *analytics.RevenueSnapshot, isCurrent bool) {
+		key := revenueGroupKey{id: revenueGroupID(groupBy, snapshot), currency: snapshot.Currency}
+		if groups[key] == nil {
+			groups[key] = make(map[string]*customerRevenue)
+		}
+		c, ok := groups[key][snapshot.CustomerID]
+		if !ok {
+			c = &customerRevenue{}
+			groups[key][snapshot.CustomerID] = c
+		}
+		if isCurrent {
+			c.current = c.current.Add(snapshot.MRR)
+		} else {
+			c.previous = c.previous.Add(snapshot.MRR)
+		}
+	}
+	for _, snapshot := range previous {
+		add(snapshot, false)
+	}
+	for _, snapshot := range current {
+		add(snapshot, true)
+	}
+
+	result := make([]dto.RevenueGroup, 0, len(groups))
+	for key, customers := range groups {
+		var startingMRR decimal.Decimal
+		var startingCustomers int
+		group := dto.RevenueGroup{ID: key.id, Currency: key.currency}
+
+		for _, c := range customers {
+			hadRevenue, hasRevenue := c.previous.IsPositive(), c.current.IsPositive()
+			if hadRevenue {
+				startingMRR = startingMRR.Add(c.previous)
+				startingCustomers++
+			}
+			if hasRevenue {
+				group.MRR = group.MRR.Add(c.current)
+				group.Customers++
+			}
+
+			switch {
+			case !hadRevenue && hasRevenue:
+				group.NewMRR = group.NewMRR.Add(c.current)
+			case hadRevenue && !hasRevenue:
+				group.ChurnedMRR = group.ChurnedMRR.Add(c.previous)
+				group.ChurnedCustomers++
+			case hadRevenue && c.current.GreaterThan(c.previous):
+				group.ExpansionMRR = group.ExpansionMRR.Add(c.current.Sub(c.previous))
+			case hadRevenue:
+				group.ContractionMRR = group.ContractionMRR.Add(c.previous.Sub(c.current))
+			}
+		}
+
+		retained := startingMRR.Add(group.ExpansionMRR).Sub(group.ContractionMRR).Sub(group.ChurnedMRR)
+		group.NetRevenueRetention = percentOf(retained, startingMRR)
+		group.RevenueChurnRate = percentOf(group.ChurnedMRR, startingMRR)
+		group.CustomerChurnRate = percentOf(decimal.NewFromInt(int64(group.ChurnedCustomers)), decimal.NewFromInt(int64(startingCustomers)))
+
+		precision := types.GetCurrencyConfig(key.currency).Precision
+		group.MRR = group.MRR.Round(precision)
+		group.ARR = group.MRR.Mul(decimal.NewFromInt(12))
+		group.NewMRR = group.NewMRR.Round(precision)
+		group.ExpansionMRR = group.ExpansionMRR.Round(precision)
+		group.ContractionMRR = group.ContractionMRR.Round(precision)
+		group.ChurnedMRR = group.ChurnedMRR.Round(precision)
+
+		result = append(result, group)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ID == result[j].ID {
+			return result[i].Currency < result[j].Currency
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+func revenueGroupID(groupBy types.RevenueGroupBy, snapshot *analytics.RevenueSnapshot) string {
+	switch groupBy {
+	case types.RevenueGroupByPlan:
+		return snapshot.PlanID
+	case types.RevenueGroupByCurrency:
+		return snapshot.Currency
+	case types.RevenueGroupBySegment:
+		return snapshot.Segment
+	default:
+		return ""
+	}
+}
+
+// percentOf returns part as a percentage of whole rounded to 2 places, nil when
+// whole is not positive
+func percentOf(part, whole decimal.Decimal) *decimal.Decimal {
+	if !whole.IsPositive() {
+		return nil
+	}
+	percent := part.Div(whole).Mul(decimal.NewFromInt(100)).Round(2)
+	return &percent
+}
+
+// billingPeriodMonths is the length of the billing period of the subscription
+// in months, zero for unknown periods
+func billingPeriodMonths(sub *subscription.Subscription) decimal.Decimal {
+	count := decimal.NewFromInt(int64(max(sub.BillingPeriodCount, 1)))
+	switch sub.BillingPeriod {
+	case types.BILLING_PERIOD_MONTHLY:
+		return count
+	case types.BILLING_PERIOD_ANNUAL:
+		return count.Mul(decimal.NewFromInt(12))
+	case types.BILLING_PERIOD_WEEKLY:
+		return count.Mul(decimal.NewFromInt(12)).Div(decimal.NewFromInt(52))
+	case types.BILLING_PERIOD_DAILY:
+		return count.Mul(decimal.NewFromInt(12)).Div(decimal.NewFromInt(365))
+	default:
+		return decimal.Zero
+	}
+}
+
+// monthRange returns the first instant of the month of start and of the month
+// after the month of end, in UTC
+func monthRange(start, end time.Time) (time.Time, time.Time, error) {
+	start = time.Date(start.UTC().Year(), start.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.UTC().Year(), end.UTC().Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: end_month must not be before start_month", ErrInvalidMonthRange)
+	}
+	if end.After(start.AddDate(0, types.MaxRevenueAnalyticsMonths, 0)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: at most %d months can be requested", ErrInvalidMonthRange, types.MaxRevenueAnalyticsMonths)
+	}
+	return start, end, nil
+}
+
+// isClosedMonth reports whether the month has ended at now
+func isClosedMonth(month, now time.Time) bool {
+	return !month.AddDate(0, 1, 0).After(now)
+}
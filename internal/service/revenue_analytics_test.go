@@ -0,0 +1,214 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevenueAnalyticsService_GetRevenueAnalytics(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	for id, segment := range map[string]string{"cust_a": "enterprise", "cust_b": "smb", "cust_c": "smb"} {
+		require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+			ID:         id,
+			ExternalID: id,
+			Metadata:   types.Metadata{types.CustomerSegmentMetadataKey: segment},
+			BaseModel:  types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	for _, sub := range []*subscription.Subscription{
+		{ID: "sub_a", CustomerID: "cust_a", PlanID: "plan_pro", BillingPeriod: types.BILLING_PERIOD_MONTHLY, BillingPeriodCount: 1},
+		{ID: "sub_b", CustomerID: "cust_b", PlanID: "plan_basic", BillingPeriod: types.BILLING_PERIOD_ANNUAL, BillingPeriodCount: 1},
+		{ID: "sub_c", CustomerID: "cust_c", PlanID: "plan_basic", BillingPeriod: types.BILLING_PERIOD_MONTHLY, BillingPeriodCount: 1},
+	} {
+		sub.Currency = "usd"
+		sub.BaseModel = types.GetDefaultBaseModel(ctx)
+		require.NoError(t, subscriptionStore.Create(ctx, sub))
+	}
+
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	invoiceCount := 0
+	newInvoice := func(sub *subscription.Subscription, status types.InvoiceStatus, start, end time.Time, amount int64) {
+		invoiceCount++
+		id := "inv_" + string(rune('a'+invoiceCount))
+		require.NoError(t, invoiceStore.Create(ctx, &invoice.Invoice{
+			ID:             id,
+			CustomerID:     sub.CustomerID,
+			SubscriptionID: sub.ID,
+			InvoiceStatus:  status,
+			Currency:       "usd",
+			PeriodStart:    &start,
+			PeriodEnd:      &end,
+			LineItems: []*invoice.InvoiceLineItem{{
+				ID:             id + "_item",
+				InvoiceID:      id,
+				CustomerID:     sub.CustomerID,
+				SubscriptionID: sub.ID,
+				Amount:         decimal.NewFromInt(amount),
+				Currency:       "usd",
+				PeriodStart:    &start,
+				PeriodEnd:      &end,
+				BaseModel:      types.GetDefaultBaseModel(ctx),
+			}},
+			BaseModel: types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	subA, _ := subscriptionStore.Get(ctx, "sub_a")
+	subB, _ := subscriptionStore.Get(ctx, "sub_b")
+	subC, _ := subscriptionStore.Get(ctx, "sub_c")
+
+	// sub_a is billed from the 15th and expands in February, sub_b is billed
+	// yearly from January and sub_c is not renewed after January
+	newInvoice(subA, types.InvoiceStatusFinalized, time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC), date(1, 15), 100)
+	newInvoice(subA, types.InvoiceStatusFinalized, date(1, 15), date(2, 15), 100)
+	newInvoice(subA, types.InvoiceStatusFinalized, date(2, 15), date(3, 15), 150)
+	newInvoice(subA, types.InvoiceStatusFinalized, date(3, 15), date(4, 15), 150)
+	newInvoice(subB, types.InvoiceStatusFinalized, date(1, 1), date(1, 1).AddDate(1, 0, 0), 1200)
+	newInvoice(subC, types.InvoiceStatusFinalized, time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), date(1, 1), 80)
+	newInvoice(subC, types.InvoiceStatusFinalized, date(1, 1), date(2, 1), 80)
+	newInvoice(subC, types.InvoiceStatusDraft, date(2, 1), date(3, 1), 80)
+
+	snapshotStore := testutil.NewInMemoryRevenueSnapshotStore()
+	svc := NewRevenueAnalyticsService(snapshotStore, invoiceStore, subscriptionStore, customerStore, logger.GetLogger())
+	now := date(3, 20)
+
+	percent := func(v string) *decimal.Decimal {
+		d := decimal.RequireFromString(v)
+		return &d
+	}
+
+	assertGroup := func(t *testing.T, want, got dto.RevenueGroup) {
+		assert.Equal(t, want.ID, got.ID)
+		assert.Equal(t, "usd", got.Currency)
+		for name, pair := range map[string][2]decimal.Decimal{
+			"mrr":             {want.MRR, got.MRR},
+			"arr":             {want.MRR.Mul(decimal.NewFromInt(12)), got.ARR},
+			"new_mrr":         {want.NewMRR, got.NewMRR},
+			"expansion_mrr":   {want.ExpansionMRR, got.ExpansionMRR},
+			"contraction_mrr": {want.ContractionMRR, got.ContractionMRR},
+			"churned_mrr":     {want.ChurnedMRR, got.ChurnedMRR},
+		} {
+			assert.True(t, pair[0].Equal(pair[1]), "%s %s: want %s, got %s", want.ID, name, pair[0], pair[1])
+		}
+		assert.Equal(t, want.Customers, got.Customers)
+		assert.Equal(t, want.ChurnedCustomers, got.ChurnedCustomers)
+		for name, pair := range map[string][2]*decimal.Decimal{
+			"net_revenue_retention": {want.NetRevenueRetention, got.NetRevenueRetention},
+			"revenue_churn_rate":    {want.RevenueChurnRate, got.RevenueChurnRate},
+			"customer_churn_rate":   {want.CustomerChurnRate, got.CustomerChurnRate},
+		} {
+			if pair[0] == nil {
+				assert.Nil(t, pair[1], "%s %s", want.ID, name)
+				continue
+			}
+			if assert.NotNil(t, pair[1], "%s %s", want.ID, name) {
+				assert.True(t, pair[0].Equal(*pair[1]), "%s %s: want %s, got %s", want.ID, name, pair[0], pair[1])
+			}
+		}
+	}
+
+	t.Run("ungrouped", func(t *testing.T) {
+		resp, err := svc.GetRevenueAnalytics(ctx, &types.RevenueAnalyticsFilter{
+			StartMonth: date(1, 1),
+			EndMonth:   date(3, 1),
+		}, now)
+		require.NoError(t, err)
+		require.Len(t, resp.Months, 3)
+
+		want := []dto.RevenueGroup{
+			{
+				MRR: decimal.NewFromInt(280), NewMRR: decimal.NewFromInt(100), Customers: 3,
+				NetRevenueRetention: percent("100"), RevenueChurnRate: percent("0"), CustomerChurnRate: percent("0"),
+			},
+			{
+				MRR: decimal.NewFromInt(250), ExpansionMRR: decimal.NewFromInt(50), ChurnedMRR: decimal.NewFromInt(80),
+				Customers: 2, ChurnedCustomers: 1,
+				NetRevenueRetention: percent("89.29"), RevenueChurnRate: percent("28.57"), CustomerChurnRate: percent("33.33"),
+			},
+			{
+				MRR: decimal.NewFromInt(250), Customers: 2,
+				NetRevenueRetention: percent("100"), RevenueChurnRate: percent("0"), CustomerChurnRate: percent("0"),
+			},
+		}
+		for i, month := range resp.Months {
+			assert.True(t, month.Month.Equal(date(time.Month(i+1), 1)))
+			require.Len(t, month.Groups, 1)
+			assertGroup(t, want[i], month.Groups[0])
+		}
+
+		// Only the closed months are snapshotted
+		assert.True(t, resp.Months[0].Snapshotted)
+		assert.True(t, resp.Months[1].Snapshotted)
+		assert.False(t, resp.Months[2].Snapshotted)
+	})
+
+	t.Run("by segment", func(t *testing.T) {
+		resp, err := svc.GetRevenueAnalytics(ctx, &types.RevenueAnalyticsFilter{
+			GroupBy:    types.RevenueGroupBySegment,
+			StartMonth: date(2, 1),
+			EndMonth:   date(2, 1),
+		}, now)
+		require.NoError(t, err)
+		require.Len(t, resp.Months, 1)
+		require.Len(t, resp.Months[0].Groups, 2)
+
+		assertGroup(t, dto.RevenueGroup{
+			ID: "enterprise", MRR: decimal.NewFromInt(150), ExpansionMRR: decimal.NewFromInt(50), Customers: 1,
+			NetRevenueRetention: percent("150"), RevenueChurnRate: percent("0"), CustomerChurnRate: percent("0"),
+		}, resp.Months[0].Groups[0])
+		assertGroup(t, dto.RevenueGroup{
+			ID: "smb", MRR: decimal.NewFromInt(100), ChurnedMRR: decimal.NewFromInt(80), Customers: 1, ChurnedCustomers: 1,
+			NetRevenueRetention: percent("55.56"), RevenueChurnRate: percent("44.44"), CustomerChurnRate: percent("50"),
+		}, resp.Months[0].Groups[1])
+	})
+
+	t.Run("snapshots are refreshed", func(t *testing.T) {
+		// sub_c's February invoice is finalized after February was snapshotted
+		newInvoice(subC, types.InvoiceStatusFinalized, date(2, 1), date(3, 1), 80)
+
+		filter := &types.RevenueAnalyticsFilter{GroupBy: types.RevenueGroupByPlan, StartMonth: date(2, 1), EndMonth: date(2, 1)}
+		resp, err := svc.GetRevenueAnalytics(ctx, filter, now)
+		require.NoError(t, err)
+		require.Len(t, resp.Months[0].Groups, 2)
+		assert.True(t, decimal.NewFromInt(100).Equal(resp.Months[0].Groups[0].MRR), "plan_basic mrr %s", resp.Months[0].Groups[0].MRR)
+
+		refreshed, err := svc.RefreshSnapshots(ctx, &dto.RefreshRevenueSnapshotsRequest{StartMonth: date(1, 1), EndMonth: date(3, 1)}, now)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{date(1, 1), date(2, 1)}, refreshed.Months)
+
+		resp, err = svc.GetRevenueAnalytics(ctx, filter, now)
+		require.NoError(t, err)
+		require.Len(t, resp.Months[0].Groups, 2)
+		assertGroup(t, dto.RevenueGroup{
+			ID: "plan_basic", MRR: decimal.NewFromInt(180), Customers: 2,
+			NetRevenueRetention: percent("100"), RevenueChurnRate: percent("0"), CustomerChurnRate: percent("0"),
+		}, resp.Months[0].Groups[0])
+	})
+
+	t.Run("invalid month range", func(t *testing.T) {
+		_, err := svc.GetRevenueAnalytics(ctx, &types.RevenueAnalyticsFilter{StartMonth: date(3, 1), EndMonth: date(1, 1)}, now)
+		assert.ErrorIs(t, err, ErrInvalidMonthRange)
+
+		_, err = svc.GetRevenueAnalytics(ctx, &types.RevenueAnalyticsFilter{StartMonth: date(1, 1), EndMonth: date(1, 1).AddDate(3, 0, 0)}, now)
+		assert.ErrorIs(t, err, ErrInvalidMonthRange)
+	})
+}
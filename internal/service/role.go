@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/flexprice/flexprice/internal/domain/role"
+	"github.com/flexprice/flexprice/internal/domain/user"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+var (
+	// ErrRoleAssignmentNotFound is returned when the role assignment is not one of the tenant
+	ErrRoleAssignmentNotFound = errors.New("role assignment not found")
+	// ErrLastAdmin is returned when a change would leave the tenant without a
+	// tenant wide admin to manage the roles
+	ErrLastAdmin = errors.New("the tenant must keep at least one admin")
+)
+
+type RoleService interface {
+	// ListRoles returns the roles with the permissions they grant
+	ListRoles(ctx context.Context) *dto.ListRolesResponse
+	ListRoleAssignments(ctx context.Context, filter *types.RoleAssignmentFilter) (*dto.ListRoleAssignmentsResponse, error)
+
+	// AssignRole sets the role of a user for an environment, or for all the
+	// environments when the request has no environment
+	AssignRole(ctx context.Context, req dto.AssignRoleRequest) (*dto.RoleAssignmentResponse, error)
+	DeleteRoleAssignment(ctx context.Context, id string) error
+
+	// GetPermissions returns the permissions of a user in an environment. The
+	// role assigned for the environment takes precedence over the tenant wide
+	// role, and users without any role have no permission
+	GetPermissions(ctx context.Context, userID, environmentID string) ([]types.Permission, error)
+}
+
+type roleService struct {
+	repo            role.Repository
+	userRepo        user.Repository
+	environmentRepo environment.Repository
+	auditPublisher  audit.Publisher
+	logger          *logger.Logger
+}
+
+func NewRoleService(
+	repo role.Repository,
+	userRepo user.Repository,
+	environmentRepo environment.Repository,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) RoleService {
+	return &roleService{
+		repo:            repo,
+		userRepo:        userRepo,
+		environmentRepo: environmentRepo,
+		auditPublisher:  auditPublisher,
+		logger:          logger,
+	}
+}
+
+func (s *roleService) ListRoles(ctx context.Context) *dto.ListRolesResponse {
+	response := &dto.ListRolesResponse{Roles: make([]dto.RoleResponse, len(types.Roles))}
+	for i, r := range types.Roles {
+		response.Roles[i] = dto.RoleResponse{Role: r, Permissions: r.Permissions()}
+	}
+	return response
+}
+
+func (s *roleService) ListRoleAssignments(ctx context.Context, filter *types.RoleAssignmentFilter) (*dto.ListRoleAssignmentsResponse, error) {
+	assignments, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+
+	response := &dto.ListRoleAssignmentsResponse{
+		Assignments: make([]dto.RoleAssignmentResponse, len(assignments)),
+	}
+	for i, assignment := range assignments {
+		response.Assignments[i] = dto.RoleAssignmentResponse{Assignment: assignment}
+	}
+	return response, nil
+}
+
+func (s *roleService) AssignRole(ctx context.Context, req dto.AssignRoleRequest) (*dto.RoleAssignmentResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, req.UserID); err != nil {
+		return nil, fmt.Errorf("invalid request: user %s not found: %w", req.UserID, err)
+	}
+	if req.EnvironmentID != "" {
+		if _, err := s.environmentRepo.Get(ctx, req.EnvironmentID); err != nil {
+			return nil, fmt.Errorf("invalid request: environment %s not found: %w", req.EnvironmentID, err)
+		}
+	}
+
+	assignments, err := s.repo.ListByUser(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+
+	var existing *role.Assignment
+	for _, assignment := range assignments {
+		if assignment.EnvironmentID == req.EnvironmentID {
+			existing = assignment
+			break
+		}
+	}
+
+	if existing == nil {
+		assignment := req.ToAssignment(ctx)
+		if err := s.repo.Create(ctx, assignment); err != nil {
+			return nil, fmt.Errorf("failed to create role assignment: %w", err)
+		}
+
+		recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRoleAssignment, assignment.ID, types.AuditActionCreate, nil, assignment)
+		return &dto.RoleAssignmentResponse{Assignment: assignment}, nil
+	}
+
+	if existing.Role == req.Role {
+		return &dto.RoleAssignmentResponse{Assignment: existing}, nil
+	}
+	if err := s.checkKeepsAdmin(ctx, existing); err != nil {
+		return nil, err
+	}
+
+	before := *existing
+	existing.Role = req.Role
+	existing.UpdatedAt = time.Now().UTC()
+	existing.UpdatedBy = types.GetUserID(ctx)
+	if err := s.repo.Update(ctx, existing); err != nil {
+		return nil, fmt.Errorf("failed to update role assignment: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRoleAssignment, existing.ID, types.AuditActionUpdate, &before, existing)
+	return &dto.RoleAssignmentResponse{Assignment: existing}, nil
+}
+
+func (s *roleService) DeleteRoleAssignment(ctx context.Context, id string) error {
+	assignment, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRoleAssignmentNotFound, err)
+	}
+
+	if err := s.checkKeepsAdmin(ctx, assignment); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete role assignment: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRoleAssignment, id, types.AuditActionDelete, assignment, nil)
+	return nil
+}
+
+// checkKeepsAdmin refuses to remove the role of the last tenant wide admin,
+// after which no one could assign roles anymore
+func (s *roleService) checkKeepsAdmin(ctx context.Context, assignment *role.Assignment) error {
+	if assignment.Role != types.RoleAdmin || assignment.EnvironmentID != "" {
+		return nil
+	}
+
+	assignments, err := s.repo.List(ctx, &types.RoleAssignmentFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to list role assignments: %w", err)
+	}
+
+	for _, other := range assignments {
+		if other.ID != assignment.ID && other.Role == types.RoleAdmin && other.EnvironmentID == "" {
+			return nil
+		}
+	}
+	return ErrLastAdmin
+}
+
+func (s *roleService) GetPermissions(ctx context.Context, userID, environmentID string) ([]types.Permission, error) {
+	assignments, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+
+	var tenantRole, environmentRole types.Role
+	for _, assignment := range assignments {
+		switch {
+		case assignment.EnvironmentID == "":
+			tenantRole = assignment.Role
+		case assignment.EnvironmentID == environmentID:
+			environmentRole = assignment.Role
+		}
+	}
+
+	if environmentRole != "" {
+		return environmentRole.Permissions(), nil
+	}
+	// Users without a role get an empty, but restricting, set of permissions
+	return append([]types.Permission{}, tenantRole.Permissions()...), nil
+}
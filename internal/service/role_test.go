@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/flexprice/flexprice/internal/domain/user"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleService(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	userStore := testutil.NewInMemoryUserRepository()
+	environmentStore := testutil.NewInMemoryEnvironmentStore()
+	admin := user.NewUser("admin@example.com", types.DefaultTenantID)
+	dev := user.NewUser("dev@example.com", types.DefaultTenantID)
+	require.NoError(t, userStore.Create(ctx, admin))
+	require.NoError(t, userStore.Create(ctx, dev))
+	require.NoError(t, environmentStore.Create(ctx, &environment.Environment{
+		ID:        "env_staging",
+		Name:      "Staging",
+		Type:      types.EnvironmentTypeDevelopment,
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	svc := NewRoleService(testutil.NewInMemoryRoleAssignmentStore(), userStore, environmentStore, nil, logger.GetLogger())
+
+	adminRole, err := svc.AssignRole(ctx, dto.AssignRoleRequest{UserID: admin.ID, Role: types.RoleAdmin})
+	require.NoError(t, err)
+
+	t.Run("users without a role have no permission", func(t *testing.T) {
+		permissions, err := svc.GetPermissions(ctx, dev.ID, "env_staging")
+		require.NoError(t, err)
+		assert.NotNil(t, permissions)
+		assert.Empty(t, permissions)
+	})
+
+	t.Run("environment roles take precedence over the tenant role", func(t *testing.T) {
+		_, err := svc.AssignRole(ctx, dto.AssignRoleRequest{UserID: dev.ID, Role: types.RoleReadOnly})
+		require.NoError(t, err)
+		_, err = svc.AssignRole(ctx, dto.AssignRoleRequest{UserID: dev.ID, Role: types.RoleDeveloper, EnvironmentID: "env_staging"})
+		require.NoError(t, err)
+
+		permissions, err := svc.GetPermissions(ctx, dev.ID, "env_staging")
+		require.NoError(t, err)
+		assert.Contains(t, permissions, types.PermissionIntegrationsWrite)
+
+		permissions, err = svc.GetPermissions(ctx, dev.ID, "env_production")
+		require.NoError(t, err)
+		assert.Equal(t, []types.Permission{types.PermissionRead}, permissions)
+	})
+
+	t.Run("assigning replaces the role of the scope", func(t *testing.T) {
+		resp, err := svc.AssignRole(ctx, dto.AssignRoleRequest{UserID: dev.ID, Role: types.RoleBillingManager})
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleBillingManager, resp.Role)
+
+		list, err := svc.ListRoleAssignments(ctx, &types.RoleAssignmentFilter{UserID: dev.ID})
+		require.NoError(t, err)
+		assert.Len(t, list.Assignments, 2)
+	})
+
+	t.Run("rejects unknown environments", func(t *testing.T) {
+		_, err := svc.AssignRole(ctx, dto.AssignRoleRequest{UserID: dev.ID, Role: types.RoleAdmin, EnvironmentID: "env_unknown"})
+		assert.Error(t, err)
+	})
+
+	t.Run("keeps the last admin", func(t *testing.T) {
+		_, err := svc.AssignRole(ctx, dto.AssignRoleRequest{UserID: admin.ID, Role: types.RoleReadOnly})
+		assert.ErrorIs(t, err, ErrLastAdmin)
+		assert.ErrorIs(t, svc.DeleteRoleAssignment(ctx, adminRole.ID), ErrLastAdmin)
+
+		_, err = svc.AssignRole(ctx, dto.AssignRoleRequest{UserID: dev.ID, Role: types.RoleAdmin})
+		require.NoError(t, err)
+		assert.NoError(t, svc.DeleteRoleAssignment(ctx, adminRole.ID))
+
+		permissions, err := svc.GetPermissions(ctx, admin.ID, "")
+		require.NoError(t, err)
+		assert.Empty(t, permissions)
+	})
+}
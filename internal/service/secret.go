@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/secret"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const (
+	apiKeyPrefix       = "sk_"
+	apiKeyBytes        = 32
+	apiKeyDisplayChars = 8
+)
+
+type SecretService interface {
+	CreateAPIKey(ctx context.Context, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, filter types.Filter) (*dto.ListAPIKeysResponse, error)
+	DeleteAPIKey(ctx context.Context, id string) error
+
+	// VerifyAPIKey returns the key matching the raw API key if it is valid
+	VerifyAPIKey(ctx context.Context, apiKey string) (*secret.Secret, error)
+}
+
+type secretService struct {
+	repo           secret.Repository
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
+}
+
+func NewSecretService(repo secret.Repository, auditPublisher audit.Publisher, logger *logger.Logger) SecretService {
+	return &secretService{
+		repo:           repo,
+		auditPublisher: auditPublisher,
+		logger:         logger,
+	}
+}
+
+func (s *secretService) CreateAPIKey(ctx context.Context, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	// A key must not grant more than the caller is allowed
+	for _, p := range types.PermissionsForScopes(scopesToStrings(req.Scopes)) {
+		if !types.HasPermission(ctx, p) {
+			return nil, fmt.Errorf("cannot grant permission %s", p)
+		}
+	}
+
+	raw := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	apiKey := apiKeyPrefix + hex.EncodeToString(raw)
+
+	sec := req.ToSecret(ctx, apiKey[:len(apiKeyPrefix)+apiKeyDisplayChars], hashAPIKey(apiKey))
+	if err := s.repo.Create(ctx, sec); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeAPIKey, sec.ID, types.AuditActionCreate, nil, sec)
+
+	return &dto.CreateAPIKeyResponse{Secret: sec, APIKey: apiKey}, nil
+}
+
+func (s *secretService) ListAPIKeys(ctx context.Context, filter types.Filter) (*dto.ListAPIKeysResponse, error) {
+	secrets, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	response := &dto.ListAPIKeysResponse{
+		APIKeys: make([]dto.APIKeyResponse, 0, len(secrets)),
+		Offset:  filter.Offset,
+		Limit:   filter.Limit,
+	}
+	for _, sec := range secrets {
+		response.APIKeys = append(response.APIKeys, dto.APIKeyResponse{Secret: sec})
+	}
+	response.Total = len(response.APIKeys)
+
+	return response, nil
+}
+
+func (s *secretService) DeleteAPIKey(ctx context.Context, id string) error {
+	sec, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete api key: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeAPIKey, id, types.AuditActionDelete, sec, nil)
+	return nil
+}
+
+func (s *secretService) VerifyAPIKey(ctx context.Context, apiKey string) (*secret.Secret, error) {
+	sec, err := s.repo.GetByHash(ctx, hashAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid api key")
+	}
+
+	if sec.IsExpired(time.Now().UTC()) {
+		return nil, fmt.Errorf("api key expired")
+	}
+
+	return sec, nil
+}
+
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func scopesToStrings(scopes []types.APIKeyScope) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		result = append(result, string(scope))
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type SecretServiceSuite struct {
+	suite.Suite
+	ctx     context.Context
+	service SecretService
+	store   *testutil.InMemorySecretStore
+}
+
+func TestSecretService(t *testing.T) {
+	suite.Run(t, new(SecretServiceSuite))
+}
+
+func (s *SecretServiceSuite) SetupTest() {
+	s.ctx = testutil.SetupContext()
+	s.store = testutil.NewInMemorySecretStore()
+	s.service = NewSecretService(s.store, nil, logger.GetLogger())
+}
+
+func (s *SecretServiceSuite) TestCreateAndVerifyAPIKey() {
+	resp, err := s.service.CreateAPIKey(s.ctx, dto.CreateAPIKeyRequest{
+		Name:   "Mobile app",
+		Scopes: []types.APIKeyScope{types.APIKeyScopeEvents},
+	})
+	s.Require().NoError(err)
+	s.True(strings.HasPrefix(resp.APIKey, resp.Prefix))
+	s.NotContains(resp.KeyHash, resp.APIKey)
+
+	key, err := s.service.VerifyAPIKey(context.Background(), resp.APIKey)
+	s.Require().NoError(err)
+	s.Equal(resp.ID, key.ID)
+	s.Equal(types.DefaultTenantID, key.TenantID)
+	s.Equal([]types.Permission{types.PermissionEventsWrite}, types.PermissionsForScopes(key.Scopes))
+
+	_, err = s.service.VerifyAPIKey(context.Background(), resp.APIKey+"x")
+	s.Error(err)
+}
+
+func (s *SecretServiceSuite) TestCreateAPIKeyValidation() {
+	_, err := s.service.CreateAPIKey(s.ctx, dto.CreateAPIKeyRequest{Name: "No scopes"})
+	s.Error(err)
+
+	_, err = s.service.CreateAPIKey(s.ctx, dto.CreateAPIKeyRequest{
+		Name:   "Unknown scope",
+		Scopes: []types.APIKeyScope{"superuser"},
+	})
+	s.Error(err)
+
+	// A scoped key cannot mint a key with more permissions than it has
+	restricted := context.WithValue(s.ctx, types.CtxPermissions, types.PermissionsForScopes([]string{string(types.APIKeyScopeReadOnly)}))
+	_, err = s.service.CreateAPIKey(restricted, dto.CreateAPIKeyRequest{
+		Name:   "Escalation",
+		Scopes: []types.APIKeyScope{types.APIKeyScopeBillingAdmin},
+	})
+	s.Error(err)
+}
+
+func (s *SecretServiceSuite) TestDeletedAndExpiredKeysAreRejected() {
+	resp, err := s.service.CreateAPIKey(s.ctx, dto.CreateAPIKeyRequest{
+		Name:   "Reporting",
+		Scopes: []types.APIKeyScope{types.APIKeyScopeReadOnly},
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.service.DeleteAPIKey(s.ctx, resp.ID))
+
+	_, err = s.service.VerifyAPIKey(s.ctx, resp.APIKey)
+	s.Error(err)
+
+	list, err := s.service.ListAPIKeys(s.ctx, types.Filter{Limit: 10})
+	s.Require().NoError(err)
+	s.Equal(0, list.Total)
+
+	expiring := time.Now().UTC().Add(time.Hour)
+	resp, err = s.service.CreateAPIKey(s.ctx, dto.CreateAPIKeyRequest{
+		Name:      "Temporary",
+		Scopes:    []types.APIKeyScope{types.APIKeyScopeReadOnly},
+		ExpiresAt: &expiring,
+	})
+	s.Require().NoError(err)
+
+	expired := time.Now().UTC().Add(-time.Minute)
+	resp.ExpiresAt = &expired
+	_, err = s.service.VerifyAPIKey(s.ctx, resp.APIKey)
+	s.Error(err)
+}
+
+func (s *SecretServiceSuite) TestPermissions() {
+	s.True(types.HasPermission(s.ctx, types.PermissionWrite), "user tokens are not restricted")
+
+	ctx := context.WithValue(s.ctx, types.CtxPermissions, types.PermissionsForScopes([]string{string(types.APIKeyScopeEvents)}))
+	s.True(types.HasPermission(ctx, types.PermissionEventsWrite))
+	s.False(types.HasPermission(ctx, types.PermissionRead))
+	s.False(types.HasPermission(ctx, types.PermissionWrite))
+
+	ctx = context.WithValue(s.ctx, types.CtxPermissions, types.PermissionsForScopes([]string{string(types.APIKeyScopeBillingAdmin)}))
+	s.True(types.HasPermission(ctx, types.PermissionWrite))
+}
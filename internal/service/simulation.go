@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/scheduler"
+)
+
+// simulatedJobs are the jobs processing the billing periods, run in this order
+// each time the simulated clock moves
+var simulatedJobs = []string{
+	"process_trials",
+	"process_billing_thresholds",
+	"renew_subscription_periods",
+	"expire_wallet_credits",
+	"process_auto_topups",
+}
+
+// SimulationService moves the simulated clock of sandbox deployments forward,
+// so integrators can go through months of renewals in minutes
+type SimulationService interface {
+	GetClock(ctx context.Context) *dto.SimulationClockResponse
+
+	// AdvanceClock moves the clock forward and runs the enabled billing jobs at
+	// the new time. It fails with clock.ErrNotSimulated unless simulation is
+	// enabled, and with clock.ErrNotSandbox unless the environment of the clock
+	// is a sandbox
+	AdvanceClock(ctx context.Context, req *dto.AdvanceClockRequest) (*dto.SimulationClockResponse, error)
+}
+
+type simulationService struct {
+	clock     *clock.Clock
+	scheduler *scheduler.Scheduler
+	logger    *logger.Logger
+}
+
+func NewSimulationService(clock *clock.Clock, scheduler *scheduler.Scheduler, logger *logger.Logger) SimulationService {
+	return &simulationService{
+		clock:     clock,
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+func (s *simulationService) GetClock(ctx context.Context) *dto.SimulationClockResponse {
+	return &dto.SimulationClockResponse{
+		Now:           s.clock.Now(),
+		Simulated:     s.clock.Simulated(),
+		EnvironmentID: s.clock.EnvironmentID(),
+	}
+}
+
+func (s *simulationService) AdvanceClock(ctx context.Context, req *dto.AdvanceClockRequest) (*dto.SimulationClockResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	to := req.Target(s.clock.Now())
+	if err := s.clock.AdvanceTo(ctx, to); err != nil {
+		return nil, err
+	}
+	s.logger.Infow("advanced simulated clock", "to", to)
+
+	enabled := make(map[string]bool)
+	for _, j := range s.scheduler.Jobs() {
+		enabled[j.Name] = j.Enabled
+	}
+
+	resp := s.GetClock(ctx)
+	for _, name := range simulatedJobs {
+		if !enabled[name] {
+			continue
+		}
+
+		run, err := s.scheduler.Run(ctx, name)
+		if errors.Is(err, scheduler.ErrJobRunning) {
+			// The scheduled run processes the same items at the same time
+			s.logger.Warnw("skipping simulated job run", "job", name, "error", err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to run job %s: %w", name, err)
+		}
+		resp.Runs = append(resp.Runs, dto.JobRunResponse{Run: run})
+	}
+
+	return resp, nil
+}
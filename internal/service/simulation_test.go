@@ -0,0 +1,140 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/scheduler"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulationService_AdvanceClock(t *testing.T) {
+	ctx := testutil.SetupContext()
+	simulatedClock := clock.NewSimulated()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_123",
+		ExternalID: "ext_cust_123",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	planStore := testutil.NewInMemoryPlanStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Monthly Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	priceStore := testutil.NewInMemoryPriceStore()
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_fixed",
+		PlanID:             "plan_123",
+		Type:               types.PRICE_TYPE_FIXED,
+		Amount:             decimal.NewFromInt(20),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	start := simulatedClock.Now()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+		ID:                 "sub_123",
+		PlanID:             "plan_123",
+		CustomerID:         "cust_123",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          start,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	featureFlagService := NewFeatureFlagService(testutil.NewInMemoryFeatureFlagStore(), logger.GetLogger())
+	_, err := featureFlagService.SetOverride(ctx, types.GetTenantID(ctx), types.FeatureFlagPeriodRenewal, &dto.SetFeatureFlagRequest{Enabled: true})
+	require.NoError(t, err)
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	invoiceService := NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+		nil, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, nil,
+		nil, featureFlagService,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(), testutil.NewInMemoryMeterStore(), customerStore,
+			simulatedClock, logger.GetLogger(),
+		), simulatedClock,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	jobScheduler := scheduler.New(config.GetDefaultConfig(), testutil.NewInMemoryJobRunStore(), simulatedClock, logger.GetLogger())
+	jobScheduler.Register(scheduler.Job{
+		Name:    "renew_subscription_periods",
+		Enabled: true,
+		Run:     invoiceService.ProcessPeriodEnds,
+	})
+
+	svc := NewSimulationService(simulatedClock, jobScheduler, logger.GetLogger())
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		_, err := svc.AdvanceClock(ctx, &dto.AdvanceClockRequest{})
+		assert.Error(t, err)
+
+		past := start.AddDate(0, 0, -1)
+		_, err = svc.AdvanceClock(ctx, &dto.AdvanceClockRequest{To: &past})
+		assert.ErrorIs(t, err, clock.ErrClockBackwards)
+	})
+
+	t.Run("renews the periods that ended", func(t *testing.T) {
+		resp, err := svc.AdvanceClock(ctx, &dto.AdvanceClockRequest{Days: 65})
+		require.NoError(t, err)
+		assert.True(t, resp.Simulated)
+		assert.WithinDuration(t, start.AddDate(0, 0, 65), resp.Now, time.Minute)
+		require.Len(t, resp.Runs, 1)
+		assert.Equal(t, types.JobRunStatusSucceeded, resp.Runs[0].Status)
+
+		sub, err := subscriptionStore.Get(ctx, "sub_123")
+		require.NoError(t, err)
+		assert.Equal(t, start.AddDate(0, 2, 0), sub.CurrentPeriodStart)
+		assert.Equal(t, start.AddDate(0, 3, 0), sub.CurrentPeriodEnd)
+
+		// Both months are invoiced, the subscription was renewed twice at once
+		invoices, err := invoiceService.ListInvoices(ctx, &types.InvoiceFilter{})
+		require.NoError(t, err)
+		require.Len(t, invoices.Invoices, 2)
+		for _, inv := range invoices.Invoices {
+			assert.True(t, decimal.NewFromInt(20).Equal(inv.Total), "total %s", inv.Total)
+		}
+	})
+
+	t.Run("is forbidden without simulation", func(t *testing.T) {
+		wallClock, err := clock.New(&config.Configuration{}, nil)
+		require.NoError(t, err)
+		wall := NewSimulationService(wallClock, jobScheduler, logger.GetLogger())
+		_, err = wall.AdvanceClock(ctx, &dto.AdvanceClockRequest{Days: 1})
+		assert.ErrorIs(t, err, clock.ErrNotSimulated)
+		assert.False(t, wall.GetClock(ctx).Simulated)
+	})
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/logger"
+)
+
+var (
+	// ErrNotDeleted is returned on the restore of a record which is not deleted
+	ErrNotDeleted = errors.New("only deleted records can be restored")
+	// ErrRestoreConflict is returned when a published record took the place of
+	// the record to restore, ex a customer with the same external id
+	ErrRestoreConflict = errors.New("a published record conflicts with the record to restore")
+)
+
+type SoftDeleteService interface {
+	// PurgeDeleted removes the plans, prices and customers deleted for longer
+	// than soft_delete.purge_after_days. The records the subscriptions, invoices
+	// or wallets refer to are kept so that the billing history stays complete
+	PurgeDeleted(ctx context.Context, now time.Time) error
+}
+
+type softDeleteService struct {
+	cfg          config.SoftDeleteConfig
+	planRepo     plan.Repository
+	priceRepo    price.Repository
+	customerRepo customer.Repository
+	logger       *logger.Logger
+}
+
+func NewSoftDeleteService(
+	cfg *config.Configuration,
+	planRepo plan.Repository,
+	priceRepo price.Repository,
+	customerRepo customer.Repository,
+	logger *logger.Logger,
+) SoftDeleteService {
+	return &softDeleteService{
+		cfg:          cfg.SoftDelete,
+		planRepo:     planRepo,
+		priceRepo:    priceRepo,
+		customerRepo: customerRepo,
+		logger:       logger,
+	}
+}
+
+func (s *softDeleteService) PurgeDeleted(ctx context.Context, now time.Time) error {
+	if s.cfg.PurgeAfterDays <= 0 {
+		return nil
+	}
+	before := now.UTC().AddDate(0, 0, -s.cfg.PurgeAfterDays)
+
+	// Prices go first so that the plans whose prices are all purged go in the same run
+	purges := []struct {
+		entity string
+		purge  func(ctx context.Context, before time.Time) (int64, error)
+	}{
+		{entity: "prices", purge: s.priceRepo.PurgeDeleted},
+		{entity: "plans", purge: s.planRepo.PurgeDeleted},
+		{entity: "customers", purge: s.customerRepo.PurgeDeleted},
+	}
+
+	var errs []error
+	for _, p := range purges {
+		purged, err := p.purge(ctx, before)
+		if err != nil {
+			s.logger.Errorw("failed to purge deleted records",
+				"entity", p.entity,
+				"before", before,
+				"error", err,
+			)
+			errs = append(errs, err)
+			continue
+		}
+		if purged > 0 {
+			s.logger.Infow("purged deleted records",
+				"entity", p.entity,
+				"before", before,
+				"count", purged,
+			)
+		}
+	}
+
+	return errors.Join(errs...)
+}
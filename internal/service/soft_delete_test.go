@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftDelete(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	auditStore := testutil.NewInMemoryAuditLogStore()
+	publisher := audit.NewPublisher(auditStore, logger.GetLogger())
+
+	planService := NewPlanService(planStore, priceStore, publisher, logger.GetLogger())
+	priceService := NewPriceService(priceStore, publisher, logger.GetLogger())
+	customerService := NewCustomerService(customerStore, nil, nil, publisher, logger.GetLogger())
+
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{ID: "plan_1", Name: "Team", BaseModel: types.GetDefaultBaseModel(ctx)}))
+	require.NoError(t, priceStore.Create(ctx, &price.Price{ID: "price_1", PlanID: "plan_1", BaseModel: types.GetDefaultBaseModel(ctx)}))
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{ID: "cust_1", ExternalID: "acme", BaseModel: types.GetDefaultBaseModel(ctx)}))
+
+	t.Run("restores a deleted plan", func(t *testing.T) {
+		require.NoError(t, planService.DeletePlan(ctx, "plan_1"))
+
+		plans, err := planStore.List(ctx, types.Filter{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, plans)
+		plans, err = planStore.List(ctx, types.Filter{Limit: 10, IncludeDeleted: true})
+		require.NoError(t, err)
+		assert.Len(t, plans, 1)
+
+		resp, err := planService.RestorePlan(ctx, "plan_1")
+		require.NoError(t, err)
+		assert.Equal(t, types.StatusPublished, resp.Status)
+		assert.Nil(t, resp.DeletedAt)
+	})
+
+	t.Run("rejects the restore of a published price", func(t *testing.T) {
+		_, err := priceService.RestorePrice(ctx, "price_1")
+		assert.ErrorIs(t, err, ErrNotDeleted)
+	})
+
+	t.Run("rejects the restore of a customer whose external id was taken", func(t *testing.T) {
+		require.NoError(t, customerService.DeleteCustomer(ctx, "cust_1"))
+		require.NoError(t, customerStore.Create(ctx, &customer.Customer{ID: "cust_2", ExternalID: "acme", BaseModel: types.GetDefaultBaseModel(ctx)}))
+
+		_, err := customerService.RestoreCustomer(ctx, "cust_1")
+		assert.ErrorIs(t, err, ErrRestoreConflict)
+
+		require.NoError(t, customerService.DeleteCustomer(ctx, "cust_2"))
+		resp, err := customerService.RestoreCustomer(ctx, "cust_1")
+		require.NoError(t, err)
+		assert.Equal(t, types.StatusPublished, resp.Status)
+	})
+
+	t.Run("purges the records deleted before the purge delay", func(t *testing.T) {
+		require.NoError(t, priceService.DeletePrice(ctx, "price_1"))
+
+		svc := NewSoftDeleteService(&config.Configuration{SoftDelete: config.SoftDeleteConfig{PurgeAfterDays: 30}},
+			planStore, priceStore, customerStore, logger.GetLogger())
+
+		// Nothing is old enough yet
+		require.NoError(t, svc.PurgeDeleted(ctx, time.Now()))
+		_, err := priceStore.Get(ctx, "price_1")
+		require.NoError(t, err)
+
+		require.NoError(t, svc.PurgeDeleted(ctx, time.Now().AddDate(0, 0, 31)))
+		_, err = priceStore.Get(ctx, "price_1")
+		assert.Error(t, err)
+		_, err = customerStore.Get(ctx, "cust_2")
+		assert.Error(t, err)
+
+		// Published records are never purged
+		_, err = planStore.Get(ctx, "plan_1")
+		assert.NoError(t, err)
+		_, err = customerStore.Get(ctx, "cust_1")
+		assert.NoError(t, err)
+	})
+
+	// Close waits for the logs to be written
+	publisher.Close()
+
+	logs, err := auditStore.List(ctx, &types.AuditLogFilter{Action: types.AuditActionRestore})
+	require.NoError(t, err)
+	assert.Len(t, logs, 2)
+}
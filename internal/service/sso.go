@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	authProvider "github.com/flexprice/flexprice/internal/auth"
+	"github.com/flexprice/flexprice/internal/domain/role"
+	ssoDomain "github.com/flexprice/flexprice/internal/domain/sso"
+	"github.com/flexprice/flexprice/internal/domain/user"
+	"github.com/flexprice/flexprice/internal/sso"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+var (
+	// ErrSSONotConfigured is returned when no tenant signs in the users of the
+	// email domain with an identity provider
+	ErrSSONotConfigured = errors.New("single sign on is not configured")
+	// ErrSSODomainInUse is returned when an email domain is already signed in
+	// with the identity provider of another tenant
+	ErrSSODomainInUse = errors.New("email domain is used by another tenant")
+	// ErrSSOLoginRejected is returned when the identity provider authenticated a
+	// user that can not sign in to the tenant
+	ErrSSOLoginRejected = errors.New("single sign on login rejected")
+	// ErrPasswordLoginDisabled is returned on the password login of the users of
+	// a tenant that only signs in with its identity provider
+	ErrPasswordLoginDisabled = errors.New("password login is disabled, sign in with single sign on")
+)
+
+const (
+	ssoOIDCCallbackPath = "/v1/auth/sso/oidc/callback"
+	ssoSAMLACSPath      = "/v1/auth/sso/saml/acs"
+)
+
+func (s *authService) GetSSOConfig(ctx context.Context) (*dto.SSOConfigResponse, error) {
+	config, err := s.ssoRepo.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sso config: %w", err)
+	}
+	if config == nil {
+		return nil, ErrSSONotConfigured
+	}
+	return s.ssoConfigResponse(config), nil
+}
+
+func (s *authService) SetSSOConfig(ctx context.Context, req dto.SetSSOConfigRequest) (*dto.SSOConfigResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	config := req.ToConfig(ctx)
+	if config.SAML != nil {
+		if _, err := sso.ParseCertificate(config.SAML.Certificate); err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+	}
+
+	for _, domain := range config.Domains {
+		other, err := s.ssoRepo.GetByDomain(ctx, domain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sso config: %w", err)
+		}
+		if other != nil && other.TenantID != config.TenantID {
+			return nil, fmt.Errorf("%w: %s", ErrSSODomainInUse, domain)
+		}
+	}
+
+	existing, err := s.ssoRepo.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sso config: %w", err)
+	}
+
+	if existing != nil {
+		config.CreatedAt = existing.CreatedAt
+		config.CreatedBy = existing.CreatedBy
+		if config.ClientSecret == "" {
+			config.ClientSecret = existing.ClientSecret
+		}
+	}
+	if config.Protocol == types.SSOProtocolOIDC && config.ClientSecret == "" {
+		return nil, fmt.Errorf("invalid request: client_secret is required for the oidc protocol")
+	}
+	if config.Protocol != types.SSOProtocolOIDC {
+		config.ClientSecret = ""
+	}
+
+	if err := s.ssoRepo.Upsert(ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to save sso config: %w", err)
+	}
+
+	action := types.AuditActionCreate
+	if existing != nil {
+		action = types.AuditActionUpdate
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeSSOConfig, config.TenantID, action, existing, config)
+
+	return s.ssoConfigResponse(config), nil
+}
+
+func (s *authService) DeleteSSOConfig(ctx context.Context) error {
+	existing, err := s.ssoRepo.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get sso config: %w", err)
+	}
+	if existing == nil {
+		return ErrSSONotConfigured
+	}
+
+	if err := s.ssoRepo.Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete sso config: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeSSOConfig, existing.TenantID, types.AuditActionDelete, existing, nil)
+	return nil
+}
+
+// StartSSO returns the identity provider page the user signs in on. The tenant is
+// found from the email domain, the user is only known once the provider answers
+func (s *authService) StartSSO(ctx context.Context, req *dto.StartSSORequest) (*dto.StartSSOResponse, error) {
+	config, err := s.ssoRepo.GetByDomain(ctx, ssoDomain.EmailDomain(req.Email))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sso config: %w", err)
+	}
+	if config == nil {
+		return nil, ErrSSONotConfigured
+	}
+
+	state := authProvider.SSOState{
+		TenantID: config.TenantID,
+		Protocol: config.Protocol,
+	}
+
+	var redirectURL string
+	switch config.Protocol {
+	case types.SSOProtocolOIDC:
+		provider, err := sso.DiscoverOIDC(ctx, config.OIDC.IssuerURL)
+		if err != nil {
+			return nil, err
+		}
+
+		state.Nonce = types.GenerateUUID()
+		stateToken, err := authProvider.NewSSOStateToken(s.cfg, state)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign sso state: %w", err)
+		}
+		redirectURL = provider.AuthCodeURL(config.OIDC.ClientID, s.ssoURL(ssoOIDCCallbackPath), stateToken, state.Nonce)
+	case types.SSOProtocolSAML:
+		provider, err := s.samlProvider(config)
+		if err != nil {
+			return nil, err
+		}
+
+		// XML IDs can not start with a digit
+		state.Nonce = "id-" + types.GenerateUUID()
+		stateToken, err := authProvider.NewSSOStateToken(s.cfg, state)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign sso state: %w", err)
+		}
+		redirectURL, err = provider.AuthnRequestURL(state.Nonce, stateToken, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sso protocol: %s", config.Protocol)
+	}
+
+	return &dto.StartSSOResponse{RedirectURL: redirectURL}, nil
+}
+
+// CompleteOIDCLogin signs in the user the OIDC provider authenticated
+func (s *authService) CompleteOIDCLogin(ctx context.Context, code, stateToken string) (*dto.AuthResponse, error) {
+	ctx, config, state, err := s.ssoLoginConfig(ctx, stateToken, types.SSOProtocolOIDC)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := sso.DiscoverOIDC(ctx, config.OIDC.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := provider.Exchange(ctx, config.OIDC.ClientID, config.ClientSecret, s.ssoURL(ssoOIDCCallbackPath),
+		code, state.Nonce, config.GroupsClaim)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSOLoginRejected, err)
+	}
+
+	return s.ssoLogin(ctx, config, identity)
+}
+
+// CompleteSAMLLogin signs in the user of the SAML response the identity provider posted
+func (s *authService) CompleteSAMLLogin(ctx context.Context, samlResponse, relayState string) (*dto.AuthResponse, error) {
+	ctx, config, state, err := s.ssoLoginConfig(ctx, relayState, types.SSOProtocolSAML)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.samlProvider(config)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := provider.ParseResponse(samlResponse, state.Nonce, config.SAML.EmailAttribute, config.GroupsClaim, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSOLoginRejected, err)
+	}
+
+	return s.ssoLogin(ctx, config, identity)
+}
+
+// ssoLoginConfig verifies the state of the login and returns the SSO config of
+// its tenant, with the tenant set in the context
+func (s *authService) ssoLoginConfig(ctx context.Context, stateToken string, protocol types.SSOProtocol) (context.Context, *ssoDomain.Config, *authProvider.SSOState, error) {
+	state, err := authProvider.ValidateSSOStateToken(s.cfg, stateToken)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: invalid state: %v", ErrSSOLoginRejected, err)
+	}
+
+	ctx = context.WithValue(ctx, types.CtxTenantID, state.TenantID)
+	config, err := s.ssoRepo.Get(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get sso config: %w", err)
+	}
+
+	// The config may have changed since the login started
+	if config == nil || config.Protocol != protocol || state.Protocol != protocol {
+		return nil, nil, nil, ErrSSONotConfigured
+	}
+
+	return ctx, config, state, nil
+}
+
+// ssoLogin signs in the user of the identity, creating the user when the tenant
+// provisions its users just in time, and syncs the tenant wide role of the user
+// with its groups
+func (s *authService) ssoLogin(ctx context.Context, config *ssoDomain.Config, identity *sso.Identity) (*dto.AuthResponse, error) {
+	email := strings.ToLower(identity.Email)
+	if !config.HasDomain(email) {
+		return nil, fmt.Errorf("%w: email %s is not of the domains of the tenant", ErrSSOLoginRejected, email)
+	}
+
+	roleFromGroups := config.RoleForGroups(identity.Groups)
+
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err == nil && existing != nil {
+		if existing.TenantID != config.TenantID {
+			return nil, fmt.Errorf("%w: user belongs to another tenant", ErrSSOLoginRejected)
+		}
+	} else {
+		if !config.JITProvisioning {
+			return nil, fmt.Errorf("%w: user %s does not exist", ErrSSOLoginRejected, email)
+		}
+
+		existing = user.NewUser(email, config.TenantID)
+		if err := s.userRepo.Create(ctx, existing); err != nil {
+			return nil, fmt.Errorf("unable to create user: %w", err)
+		}
+
+		s.logger.Infow("provisioned sso user", "user_id", existing.ID, "tenant_id", config.TenantID)
+		if roleFromGroups == "" {
+			roleFromGroups = config.DefaultRole
+		}
+	}
+
+	if roleFromGroups != "" {
+		if err := s.syncSSORole(ctx, existing.ID, roleFromGroups); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := authProvider.NewUserToken(s.cfg, existing.ID, config.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &dto.AuthResponse{Token: token}, nil
+}
+
+// syncSSORole sets the tenant wide role of the user. The identity provider is the
+// source of truth of the roles of the groups it maps, environment roles are kept
+func (s *authService) syncSSORole(ctx context.Context, userID string, r types.Role) error {
+	assignments, err := s.roleRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list role assignments: %w", err)
+	}
+
+	for _, assignment := range assignments {
+		if assignment.EnvironmentID != "" {
+			continue
+		}
+		if assignment.Role == r {
+			return nil
+		}
+
+		before := *assignment
+		assignment.Role = r
+		assignment.UpdatedAt = time.Now().UTC()
+		assignment.UpdatedBy = userID
+		if err := s.roleRepo.Update(ctx, assignment); err != nil {
+			return fmt.Errorf("failed to update role assignment: %w", err)
+		}
+
+		recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRoleAssignment, assignment.ID, types.AuditActionUpdate, &before, assignment)
+		return nil
+	}
+
+	baseModel := types.GetDefaultBaseModel(ctx)
+	baseModel.CreatedBy = userID
+	baseModel.UpdatedBy = userID
+	assignment := &role.Assignment{
+		ID:        types.GenerateUUID(),
+		UserID:    userID,
+		Role:      r,
+		BaseModel: baseModel,
+	}
+	if err := s.roleRepo.Create(ctx, assignment); err != nil {
+		return fmt.Errorf("failed to create role assignment: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeRoleAssignment, assignment.ID, types.AuditActionCreate, nil, assignment)
+	return nil
+}
+
+func (s *authService) samlProvider(config *ssoDomain.Config) (*sso.SAMLProvider, error) {
+	return sso.NewSAMLProvider(s.cfg.Auth.SSO.BaseURL, s.ssoURL(ssoSAMLACSPath),
+		config.SAML.EntityID, config.SAML.SSOURL, config.SAML.Certificate)
+}
+
+func (s *authService) ssoURL(path string) string {
+	return strings.TrimSuffix(s.cfg.Auth.SSO.BaseURL, "/") + path
+}
+
+func (s *authService) ssoConfigResponse(config *ssoDomain.Config) *dto.SSOConfigResponse {
+	response := &dto.SSOConfigResponse{Config: config}
+	switch config.Protocol {
+	case types.SSOProtocolOIDC:
+		response.OIDCRedirectURL = s.ssoURL(ssoOIDCCallbackPath)
+	case types.SSOProtocolSAML:
+		response.SAMLEntityID = s.cfg.Auth.SSO.BaseURL
+		response.SAMLACSURL = s.ssoURL(ssoSAMLACSPath)
+	}
+	return response
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	authProvider "github.com/flexprice/flexprice/internal/auth"
+	ssoDomain "github.com/flexprice/flexprice/internal/domain/sso"
+	"github.com/flexprice/flexprice/internal/domain/user"
+	"github.com/flexprice/flexprice/internal/sso"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+func (s *AuthServiceSuite) setSSOConfig(jit bool) {
+	_, err := s.authService.SetSSOConfig(s.ctx, dto.SetSSOConfigRequest{
+		Protocol:     types.SSOProtocolOIDC,
+		Domains:      []string{"Acme.com"},
+		OIDC:         &ssoDomain.OIDCSettings{IssuerURL: "https://acme.okta.com", ClientID: "client_1"},
+		ClientSecret: "secret_1",
+		GroupRoles: map[string]types.Role{
+			"finance":  types.RoleBillingManager,
+			"platform": types.RoleDeveloper,
+		},
+		JITProvisioning:       jit,
+		DefaultRole:           types.RoleReadOnly,
+		PasswordLoginDisabled: true,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *AuthServiceSuite) TestSetSSOConfig() {
+	s.setSSOConfig(true)
+
+	resp, err := s.authService.GetSSOConfig(s.ctx)
+	s.Require().NoError(err)
+	s.Equal([]string{"acme.com"}, []string(resp.Domains))
+	s.Equal("https://api.example.com/v1/auth/sso/oidc/callback", resp.OIDCRedirectURL)
+
+	s.Run("keeps the client secret when none is given", func() {
+		_, err := s.authService.SetSSOConfig(s.ctx, dto.SetSSOConfigRequest{
+			Protocol:    types.SSOProtocolOIDC,
+			Domains:     []string{"acme.com", "acme.io"},
+			OIDC:        &ssoDomain.OIDCSettings{IssuerURL: "https://acme.okta.com", ClientID: "client_1"},
+			DefaultRole: types.RoleReadOnly,
+		})
+		s.Require().NoError(err)
+
+		config, err := s.ssoRepo.Get(s.ctx)
+		s.Require().NoError(err)
+		s.Equal("secret_1", config.ClientSecret)
+	})
+
+	s.Run("rejects the domains of another tenant", func() {
+		otherCtx := context.WithValue(s.ctx, types.CtxTenantID, "tenant_other")
+		_, err := s.authService.SetSSOConfig(otherCtx, dto.SetSSOConfigRequest{
+			Protocol:     types.SSOProtocolOIDC,
+			Domains:      []string{"acme.com"},
+			OIDC:         &ssoDomain.OIDCSettings{IssuerURL: "https://other.okta.com", ClientID: "client_2"},
+			ClientSecret: "secret_2",
+		})
+		s.ErrorIs(err, ErrSSODomainInUse)
+	})
+
+	s.Run("rejects invalid saml certificates", func() {
+		_, err := s.authService.SetSSOConfig(s.ctx, dto.SetSSOConfigRequest{
+			Protocol: types.SSOProtocolSAML,
+			Domains:  []string{"acme.com"},
+			SAML: &ssoDomain.SAMLSettings{
+				EntityID:    "https://idp.acme.com",
+				SSOURL:      "https://idp.acme.com/sso",
+				Certificate: "not a certificate",
+			},
+		})
+		s.Error(err)
+	})
+}
+
+func (s *AuthServiceSuite) TestSSOLogin() {
+	s.setSSOConfig(true)
+	config, err := s.ssoRepo.Get(s.ctx)
+	s.Require().NoError(err)
+
+	s.Run("provisions new users with the role of their groups", func() {
+		resp, err := s.authService.ssoLogin(s.ctx, config, &sso.Identity{Email: "Ada@acme.com", Groups: []string{"platform", "finance"}})
+		s.Require().NoError(err)
+		s.NotEmpty(resp.Token)
+
+		created, err := s.userRepo.GetByEmail(s.ctx, "ada@acme.com")
+		s.Require().NoError(err)
+		s.Equal(types.DefaultTenantID, created.TenantID)
+
+		assignments, err := s.roleRepo.ListByUser(s.ctx, created.ID)
+		s.Require().NoError(err)
+		s.Require().Len(assignments, 1)
+		s.Equal(types.RoleBillingManager, assignments[0].Role)
+	})
+
+	s.Run("syncs the role of existing users", func() {
+		_, err := s.authService.ssoLogin(s.ctx, config, &sso.Identity{Email: "ada@acme.com", Groups: []string{"platform"}})
+		s.Require().NoError(err)
+
+		created, err := s.userRepo.GetByEmail(s.ctx, "ada@acme.com")
+		s.Require().NoError(err)
+		assignments, err := s.roleRepo.ListByUser(s.ctx, created.ID)
+		s.Require().NoError(err)
+		s.Require().Len(assignments, 1)
+		s.Equal(types.RoleDeveloper, assignments[0].Role)
+	})
+
+	s.Run("provisions users without mapped groups with the default role", func() {
+		_, err := s.authService.ssoLogin(s.ctx, config, &sso.Identity{Email: "bob@acme.com"})
+		s.Require().NoError(err)
+
+		created, err := s.userRepo.GetByEmail(s.ctx, "bob@acme.com")
+		s.Require().NoError(err)
+		assignments, err := s.roleRepo.ListByUser(s.ctx, created.ID)
+		s.Require().NoError(err)
+		s.Require().Len(assignments, 1)
+		s.Equal(types.RoleReadOnly, assignments[0].Role)
+	})
+
+	s.Run("rejects emails of other domains", func() {
+		_, err := s.authService.ssoLogin(s.ctx, config, &sso.Identity{Email: "eve@evil.com"})
+		s.ErrorIs(err, ErrSSOLoginRejected)
+	})
+
+	s.Run("rejects users of another tenant", func() {
+		s.Require().NoError(s.userRepo.Create(s.ctx, user.NewUser("carol@acme.com", "tenant_other")))
+		_, err := s.authService.ssoLogin(s.ctx, config, &sso.Identity{Email: "carol@acme.com"})
+		s.ErrorIs(err, ErrSSOLoginRejected)
+	})
+
+	s.Run("rejects unknown users without just in time provisioning", func() {
+		config.JITProvisioning = false
+		_, err := s.authService.ssoLogin(s.ctx, config, &sso.Identity{Email: "dan@acme.com"})
+		s.ErrorIs(err, ErrSSOLoginRejected)
+	})
+
+	s.Run("rejects a state of another protocol", func() {
+		state, err := authProvider.NewSSOStateToken(s.authService.cfg, authProvider.SSOState{
+			TenantID: types.DefaultTenantID,
+			Protocol: types.SSOProtocolSAML,
+			Nonce:    "id-request-1",
+		})
+		s.Require().NoError(err)
+
+		_, err = s.authService.CompleteSAMLLogin(s.ctx, "", state)
+		s.ErrorIs(err, ErrSSONotConfigured)
+	})
+}
+
+func (s *AuthServiceSuite) TestLoginPasswordDisabled() {
+	_, err := s.authService.SignUp(s.ctx, &dto.SignUpRequest{Email: "admin@acme.com", Password: "securepassword"})
+	s.Require().NoError(err)
+
+	_, err = s.authService.Login(s.ctx, &dto.LoginRequest{Email: "admin@acme.com", Password: "securepassword"})
+	s.Require().NoError(err)
+
+	s.setSSOConfig(false)
+	_, err = s.authService.Login(s.ctx, &dto.LoginRequest{Email: "admin@acme.com", Password: "securepassword"})
+	s.ErrorIs(err, ErrPasswordLoginDisabled)
+}
@@ -0,0 +1,540 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/stripe"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	stripeObjectProduct      = "product"
+	stripeObjectPrice        = "price"
+	stripeObjectCustomer     = "customer"
+	stripeObjectSubscription = "subscription"
+
+	// stripeImportPageSize is the number of existing records read at a time to
+	// match the Stripe objects against
+	stripeImportPageSize = 500
+)
+
+type StripeImportService interface {
+	// Import reads the Stripe account of a stripe connection and creates the
+	// plans, prices, customers and subscriptions it has not imported yet. The
+	// Stripe IDs are kept in the metadata of the records so that an import can
+	// be run again
+	Import(ctx context.Context, connectionID string, req dto.ImportStripeRequest) (*dto.ImportStripeResponse, error)
+}
+
+// stripeCatalogReader is implemented by *stripe.Client
+type stripeCatalogReader interface {
+	FetchCatalog(ctx context.Context) (*stripe.Catalog, error)
+}
+
+type stripeImportService struct {
+	connectionRepo    connection.Repository
+	planRepo          plan.Repository
+	priceRepo         price.Repository
+	customerRepo      customer.Repository
+	subscriptionRepo  subscription.Repository
+	db                postgres.TxManager
+	connectionService ConnectionService
+	logger            *logger.Logger
+
+	// newClient is replaced in tests
+	newClient func(secretKey string) stripeCatalogReader
+}
+
+func NewStripeImportService(
+	connectionRepo connection.Repository,
+	planRepo plan.Repository,
+	priceRepo price.Repository,
+	customerRepo customer.Repository,
+	subscriptionRepo subscription.Repository,
+	db postgres.TxManager,
+	connectionService ConnectionService,
+	logger *logger.Logger,
+) StripeImportService {
+	return &stripeImportService{
+		connectionRepo:    connectionRepo,
+		planRepo:          planRepo,
+		priceRepo:         priceRepo,
+		customerRepo:      customerRepo,
+		subscriptionRepo:  subscriptionRepo,
+		db:                db,
+		connectionService: connectionService,
+		logger:            logger,
+		newClient: func(secretKey string) stripeCatalogReader {
+			return stripe.NewClient(secretKey)
+		},
+	}
+}
+
+func (s *stripeImportService) Import(ctx context.Context, connectionID string, req dto.ImportStripeRequest) (*dto.ImportStripeResponse, error) {
+	conn, err := s.connectionRepo.Get(ctx, connectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	if conn.Provider != types.IntegrationProviderStripe {
+		return nil, fmt.Errorf("connection is not a stripe connection")
+	}
+	if conn.Credentials == "" {
+		return nil, fmt.Errorf("connection has no stripe secret key")
+	}
+
+	catalog, err := s.fetchCatalog(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	imp, err := s.newStripeImport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	imp.resp.DryRun = req.DryRun
+	imp.plan(ctx, catalog)
+
+	resp := imp.resp
+	if req.DryRun {
+		return resp, nil
+	}
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, p := range imp.plans {
+			if err := s.planRepo.Create(ctx, p); err != nil {
+				return fmt.Errorf("failed to create plan: %w", err)
+			}
+		}
+		for _, p := range imp.prices {
+			if err := s.priceRepo.Create(ctx, p); err != nil {
+				return fmt.Errorf("failed to create price: %w", err)
+			}
+		}
+		for _, c := range imp.customers {
+			if err := s.customerRepo.Create(ctx, c); err != nil {
+				return fmt.Errorf("failed to create customer: %w", err)
+			}
+		}
+		for _, sub := range imp.subscriptions {
+			if err := s.subscriptionRepo.Create(ctx, sub); err != nil {
+				return fmt.Errorf("failed to create subscription: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Applied = true
+	return resp, nil
+}
+
+// fetchCatalog reads the Stripe account on the sync queue of the connection
+// and waits for it, so that the reads share the rate limit of the connection
+// and are retried as any other call to Stripe
+func (s *stripeImportService) fetchCatalog(ctx context.Context, conn *connection.Connection) (*stripe.Catalog, error) {
+	catalog, err := runOnSyncQueue(ctx, s.connectionService, conn.ID, types.SyncEntityTypeCatalog, conn.ID,
+		func(ctx context.Context) (*stripe.Catalog, error) {
+			return s.newClient(conn.Credentials).FetchCatalog(ctx)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stripe account: %w", err)
+	}
+	return catalog, nil
+}
+
+// stripeImport matches the Stripe objects against the existing records and
+// collects the records to create
+type stripeImport struct {
+	resp *dto.ImportStripeResponse
+
+	plansByStripeID     map[string]*plan.Plan
+	plansByName         map[string]*plan.Plan
+	pricesByStripeID    map[string]*price.Price
+	customersByStripeID map[string]*customer.Customer
+	customersByEmail    map[string]*customer.Customer
+	subsByStripeID      map[string]*subscription.Subscription
+
+	// The records to create
+	plans         []*plan.Plan
+	prices        []*price.Price
+	customers     []*customer.Customer
+	subscriptions []*subscription.Subscription
+}
+
+func (s *stripeImportService) newStripeImport(ctx context.Context) (*stripeImport, error) {
+	imp := &stripeImport{
+		resp:                &dto.ImportStripeResponse{Results: []dto.StripeImportResult{}},
+		plansByStripeID:     make(map[string]*plan.Plan),
+		plansByName:         make(map[string]*plan.Plan),
+		pricesByStripeID:    make(map[string]*price.Price),
+		customersByStripeID: make(map[string]*customer.Customer),
+		customersByEmail:    make(map[string]*customer.Customer),
+		subsByStripeID:      make(map[string]*subscription.Subscription),
+	}
+
+	plans, err := listAllPages(func(filter types.Filter) ([]*plan.Plan, error) {
+		return s.planRepo.List(ctx, filter)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+	for _, p := range plans {
+		if id := p.Metadata[types.MetadataStripeProductID]; id != "" {
+			imp.plansByStripeID[id] = p
+		}
+		imp.plansByName[strings.ToLower(p.Name)] = p
+	}
+
+	prices, err := listAllPages(func(filter types.Filter) ([]*price.Price, error) {
+		return s.priceRepo.List(ctx, filter)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prices: %w", err)
+	}
+	for _, p := range prices {
+		if id := p.Metadata[types.MetadataStripePriceID]; id != "" {
+			imp.pricesByStripeID[id] = p
+		}
+	}
+
+	customers, err := listAllPages(func(filter types.Filter) ([]*customer.Customer, error) {
+		return s.customerRepo.List(ctx, filter)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
+	for _, c := range customers {
+		if c.Status != types.StatusPublished {
+			continue
+		}
+		// Customers imported from Stripe have the Stripe ID as external ID
+		// unless it was taken
+		if id := c.Metadata[types.MetadataStripeCustomerID]; id != "" {
+			imp.customersByStripeID[id] = c
+		} else if strings.HasPrefix(c.ExternalID, "cus_") {
+			imp.customersByStripeID[c.ExternalID] = c
+		}
+		if c.Email != "" {
+			imp.customersByEmail[strings.ToLower(c.Email)] = c
+		}
+	}
+
+	subs, err := listAllPages(func(filter types.Filter) ([]*subscription.Subscription, error) {
+		return s.subscriptionRepo.List(ctx, &types.SubscriptionFilter{Filter: filter})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	for _, sub := range subs {
+		if id := sub.Metadata[types.MetadataStripeSubscriptionID]; id != "" {
+			imp.subsByStripeID[id] = sub
+		}
+	}
+
+	return imp, nil
+}
+
+func listAllPages[T any](list func(filter types.Filter) ([]T, error)) ([]T, error) {
+	var all []T
+	filter := types.Filter{Limit: stripeImportPageSize}
+	for {
+		page, err := list(filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < filter.Limit {
+			return all, nil
+		}
+		filter.Offset += filter.Limit
+	}
+}
+
+// plan decides what to do with every Stripe object. Objects depending on an
+// object that is not imported, such as the prices of a conflicting product,
+// are skipped
+func (imp *stripeImport) plan(ctx context.Context, catalog *stripe.Catalog) {
+	for _, p := range catalog.Products {
+		imp.resp.Add(imp.planProduct(ctx, p))
+	}
+	for _, p := range catalog.Prices {
+		imp.resp.Add(imp.planPrice(ctx, p))
+	}
+	for _, c := range catalog.Customers {
+		imp.resp.Add(imp.planCustomer(ctx, c))
+	}
+	for _, sub := range catalog.Subscriptions {
+		imp.resp.Add(imp.planSubscription(ctx, sub))
+	}
+
+	// Created records have no id in a dry run
+	if imp.resp.DryRun {
+		for i := range imp.resp.Results {
+			if imp.resp.Results[i].Action == types.StripeImportActionCreate {
+				imp.resp.Results[i].FlexpriceID = ""
+			}
+		}
+	}
+}
+
+func (imp *stripeImport) planProduct(ctx context.Context, p *stripe.Product) dto.StripeImportResult {
+	result := dto.StripeImportResult{Object: stripeObjectProduct, StripeID: p.ID}
+
+	if existing, ok := imp.plansByStripeID[p.ID]; ok {
+		result.Action = types.StripeImportActionExists
+		result.FlexpriceID = existing.ID
+		return result
+	}
+	if existing, ok := imp.plansByName[strings.ToLower(p.Name)]; ok {
+		result.Action = types.StripeImportActionConflict
+		result.FlexpriceID = existing.ID
+		result.Reason = "a plan with the same name exists"
+		return result
+	}
+
+	// Licensed Stripe prices are charged at the start of the period
+	created := &plan.Plan{
+		ID:             types.GenerateUUID(),
+		Name:           p.Name,
+		Description:    p.Description,
+		InvoiceCadence: types.InvoiceCadenceAdvance,
+		Metadata:       types.Metadata{types.MetadataStripeProductID: p.ID},
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}
+	imp.plans = append(imp.plans, created)
+	imp.plansByStripeID[p.ID] = created
+	imp.plansByName[strings.ToLower(p.Name)] = created
+
+	result.Action = types.StripeImportActionCreate
+	result.FlexpriceID = created.ID
+	return result
+}
+
+var stripeBillingPeriods = map[string]types.BillingPeriod{
+	"day":   types.BILLING_PERIOD_DAILY,
+	"week":  types.BILLING_PERIOD_WEEKLY,
+	"month": types.BILLING_PERIOD_MONTHLY,
+	"year":  types.BILLING_PERIOD_ANNUAL,
+}
+
+func (imp *stripeImport) planPrice(ctx context.Context, p *stripe.Price) dto.StripeImportResult {
+	result := dto.StripeImportResult{Object: stripeObjectPrice, StripeID: p.ID}
+
+	if existing, ok := imp.pricesByStripeID[p.ID]; ok {
+		result.Action = types.StripeImportActionExists
+		result.FlexpriceID = existing.ID
+		return result
+	}
+
+	skip := func(reason string) dto.StripeImportResult {
+		result.Action = types.StripeImportActionSkip
+		result.Reason = reason
+		return result
+	}
+
+	pl, ok := imp.plansByStripeID[p.Product]
+	if !ok {
+		return skip(fmt.Sprintf("product %s is not imported", p.Product))
+	}
+	if p.Type != "recurring" || p.Recurring == nil {
+		return skip("one-time prices are not supported")
+	}
+	if p.Recurring.UsageType == "metered" {
+		return skip("metered prices need a meter and are not supported")
+	}
+	if p.BillingScheme == "tiered" || p.UnitAmountDecimal == nil {
+		return skip("tiered prices are not supported")
+	}
+	period, ok := stripeBillingPeriods[p.Recurring.Interval]
+	if !ok {
+		return skip(fmt.Sprintf("interval %s is not supported", p.Recurring.Interval))
+	}
+
+	created := &price.Price{
+		ID:                 types.GenerateUUID(),
+		Amount:             p.Amount(),
+		Currency:           strings.ToLower(p.Currency),
+		PlanID:             pl.ID,
+		Type:               types.PRICE_TYPE_FIXED,
+		BillingPeriod:      period,
+		BillingPeriodCount: max(p.Recurring.IntervalCount, 1),
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		Description:        p.Nickname,
+		Metadata:           price.JSONBMetadata{types.MetadataStripePriceID: p.ID},
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}
+	created.DisplayAmount = created.GetDisplayAmount()
+	imp.prices = append(imp.prices, created)
+	imp.pricesByStripeID[p.ID] = created
+
+	result.Action = types.StripeImportActionCreate
+	result.FlexpriceID = created.ID
+	return result
+}
+
+func (imp *stripeImport) planCustomer(ctx context.Context, c *stripe.Customer) dto.StripeImportResult {
+	result := dto.StripeImportResult{Object: stripeObjectCustomer, StripeID: c.ID}
+
+	if existing, ok := imp.customersByStripeID[c.ID]; ok {
+		result.Action = types.StripeImportActionExists
+		result.FlexpriceID = existing.ID
+		return result
+	}
+	if existing, ok := imp.customersByEmail[strings.ToLower(c.Email)]; ok && c.Email != "" {
+		result.Action = types.StripeImportActionConflict
+		result.FlexpriceID = existing.ID
+		result.Reason = "a customer with the same email exists"
+		return result
+	}
+
+	name := c.Name
+	if name == "" {
+		name = c.Email
+	}
+	created := &customer.Customer{
+		ID:             types.GenerateUUID(),
+		ExternalID:     c.ID,
+		Name:           name,
+		Email:          c.Email,
+		BillingAddress: stripeAddress(c.Address),
+		Timezone:       types.DefaultTimezone,
+		Metadata:       types.Metadata{types.MetadataStripeCustomerID: c.ID},
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}
+	if c.Shipping != nil {
+		created.ShippingAddress = stripeAddress(c.Shipping.Address)
+	}
+	imp.customers = append(imp.customers, created)
+	imp.customersByStripeID[c.ID] = created
+	if c.Email != "" {
+		imp.customersByEmail[strings.ToLower(c.Email)] = created
+	}
+
+	result.Action = types.StripeImportActionCreate
+	result.FlexpriceID = created.ID
+	return result
+}
+
+// stripeAddress converts a Stripe address, partial addresses are left out
+func stripeAddress(a *stripe.Address) *customer.Address {
+	if a == nil || a.Line1 == "" || a.City == "" || len(a.Country) != 2 {
+		return nil
+	}
+	return &customer.Address{
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		State:      a.State,
+		PostalCode: a.PostalCode,
+		Country:    strings.ToUpper(a.Country),
+	}
+}
+
+var stripeSubscriptionStatuses = map[string]types.SubscriptionStatus{
+	"active":   types.SubscriptionStatusActive,
+	"trialing": types.SubscriptionStatusTrialing,
+	"past_due": types.SubscriptionStatusPastDue,
+	"unpaid":   types.SubscriptionStatusUnpaid,
+	"paused":   types.SubscriptionStatusPaused,
+}
+
+func (imp *stripeImport) planSubscription(ctx context.Context, sub *stripe.Subscription) dto.StripeImportResult {
+	result := dto.StripeImportResult{Object: stripeObjectSubscription, StripeID: sub.ID}
+
+	if existing, ok := imp.subsByStripeID[sub.ID]; ok {
+		result.Action = types.StripeImportActionExists
+		result.FlexpriceID = existing.ID
+		return result
+	}
+
+	skip := func(reason string) dto.StripeImportResult {
+		result.Action = types.StripeImportActionSkip
+		result.Reason = reason
+		return result
+	}
+
+	status, ok := stripeSubscriptionStatuses[sub.Status]
+	if !ok {
+		return skip(fmt.Sprintf("%s subscriptions are not imported", sub.Status))
+	}
+	cust, ok := imp.customersByStripeID[sub.Customer]
+	if !ok {
+		return skip(fmt.Sprintf("customer %s is not imported", sub.Customer))
+	}
+	if len(sub.Items.Data) == 0 {
+		return skip("subscription has no items")
+	}
+
+	// A Flexprice subscription is to a single plan and charges each of its
+	// prices once per period
+	var first *price.Price
+	for _, item := range sub.Items.Data {
+		p, ok := imp.pricesByStripeID[item.Price.ID]
+		if !ok {
+			return skip(fmt.Sprintf("price %s is not imported", item.Price.ID))
+		}
+		if item.Quantity > 1 {
+			return skip("quantities above 1 are not supported")
+		}
+		if first == nil {
+			first = p
+		} else if p.PlanID != first.PlanID {
+			return skip("items of several products are not supported")
+		}
+	}
+
+	created := &subscription.Subscription{
+		ID:                 types.GenerateUUID(),
+		CustomerID:         cust.ID,
+		PlanID:             first.PlanID,
+		SubscriptionStatus: status,
+		Currency:           strings.ToLower(sub.Currency),
+		BillingAnchor:      stripe.Time(sub.BillingCycleAnchor),
+		StartDate:          stripe.Time(sub.StartDate),
+		CurrentPeriodStart: stripe.Time(sub.CurrentPeriodStart),
+		CurrentPeriodEnd:   stripe.Time(sub.CurrentPeriodEnd),
+		CancelAt:           stripe.TimePtr(sub.CancelAt),
+		CancelAtPeriodEnd:  sub.CancelAtPeriodEnd,
+		TrialStart:         stripe.TimePtr(sub.TrialStart),
+		TrialEnd:           stripe.TimePtr(sub.TrialEnd),
+		BillingCycle:       types.BillingCycleAnniversary,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BillingPeriod:      first.BillingPeriod,
+		BillingPeriodCount: first.BillingPeriodCount,
+		Timezone:           cust.Timezone,
+		InvoiceCadence:     types.InvoiceCadenceAdvance,
+		CommitmentAmount:   decimal.Zero,
+		BillingThreshold:   decimal.Zero,
+		Metadata:           types.Metadata{types.MetadataStripeSubscriptionID: sub.ID},
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}
+	for _, item := range sub.Items.Data {
+		created.LineItems = append(created.LineItems, &subscription.LineItem{
+			ID:             types.GenerateUUID(),
+			SubscriptionID: created.ID,
+			PriceID:        imp.pricesByStripeID[item.Price.ID].ID,
+			Quantity:       decimal.NewFromInt(int64(max(item.Quantity, 1))),
+			BaseModel:      types.GetDefaultBaseModel(ctx),
+		})
+	}
+	imp.subscriptions = append(imp.subscriptions, created)
+	imp.subsByStripeID[sub.ID] = created
+
+	result.Action = types.StripeImportActionCreate
+	result.FlexpriceID = created.ID
+	return result
+}
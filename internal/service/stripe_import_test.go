@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/stripe"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStripeReader struct {
+	catalog *stripe.Catalog
+}
+
+func (r *fakeStripeReader) FetchCatalog(ctx context.Context) (*stripe.Catalog, error) {
+	return r.catalog, nil
+}
+
+func stripeAmount(v int64) *decimal.Decimal {
+	d := decimal.NewFromInt(v)
+	return &d
+}
+
+func TestStripeImportService(t *testing.T) {
+	ctx := testutil.SetupContext()
+	manager := syncqueue.NewManager(&config.Configuration{
+		Integration: config.IntegrationConfig{SyncRatePerSecond: 100},
+	}, logger.GetLogger())
+	defer manager.Stop()
+
+	connectionStore := testutil.NewInMemoryConnectionStore()
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	connectionService := NewConnectionService(connectionStore, manager, nil, logger.GetLogger())
+
+	monthly := &stripe.Recurring{Interval: "month", IntervalCount: 1, UsageType: "licensed"}
+	reader := &fakeStripeReader{catalog: &stripe.Catalog{
+		Products: []*stripe.Product{{ID: "prod_1", Name: "Starter"}},
+		Prices: []*stripe.Price{
+			{ID: "price_1", Product: "prod_1", Currency: "USD", Type: "recurring", UnitAmountDecimal: stripeAmount(2900), Recurring: monthly},
+			{ID: "price_2", Product: "prod_1", Currency: "usd", Type: "recurring", UnitAmountDecimal: stripeAmount(1),
+				Recurring: &stripe.Recurring{Interval: "month", IntervalCount: 1, UsageType: "metered"}},
+		},
+		Customers: []*stripe.Customer{
+			{ID: "cus_1", Name: "Acme", Email: "billing@acme.com", Address: &stripe.Address{Line1: "1 Main St", City: "Berlin", Country: "de"}},
+			{ID: "cus_2", Name: "Globex", Email: "ap@globex.com"},
+		},
+		Subscriptions: []*stripe.Subscription{
+			{ID: "sub_1", Customer: "cus_1", Status: "active", Currency: "usd", StartDate: 1700000000,
+				BillingCycleAnchor: 1700000000, CurrentPeriodStart: 1700000000, CurrentPeriodEnd: 1702592000},
+		},
+	}}
+	reader.catalog.Subscriptions[0].Items.Data = []stripe.SubscriptionItem{{Price: stripe.Price{ID: "price_1"}, Quantity: 1}}
+
+	svc := NewStripeImportService(connectionStore, planStore, priceStore, customerStore, subscriptionStore,
+		testutil.NewInMemoryTxManager(), connectionService, logger.GetLogger()).(*stripeImportService)
+	svc.newClient = func(string) stripeCatalogReader { return reader }
+
+	conn, err := connectionService.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:        "Stripe",
+		Provider:    types.IntegrationProviderStripe,
+		Credentials: "sk_test",
+	})
+	require.NoError(t, err)
+
+	// An existing customer with the email of a Stripe customer is not merged
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_existing",
+		ExternalID: "globex",
+		Name:       "Globex",
+		Email:      "AP@globex.com",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	actions := func(resp *dto.ImportStripeResponse) map[string]types.StripeImportAction {
+		result := make(map[string]types.StripeImportAction)
+		for _, r := range resp.Results {
+			result[r.StripeID] = r.Action
+		}
+		return result
+	}
+
+	t.Run("dry run creates nothing", func(t *testing.T) {
+		resp, err := svc.Import(ctx, conn.ID, dto.ImportStripeRequest{DryRun: true})
+		require.NoError(t, err)
+		assert.False(t, resp.Applied)
+		assert.Equal(t, map[string]types.StripeImportAction{
+			"prod_1":  types.StripeImportActionCreate,
+			"price_1": types.StripeImportActionCreate,
+			"price_2": types.StripeImportActionSkip,
+			"cus_1":   types.StripeImportActionCreate,
+			"cus_2":   types.StripeImportActionConflict,
+			"sub_1":   types.StripeImportActionCreate,
+		}, actions(resp))
+		assert.Equal(t, 4, resp.Created)
+
+		plans, err := planStore.List(ctx, types.Filter{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, plans)
+	})
+
+	t.Run("import creates the records", func(t *testing.T) {
+		resp, err := svc.Import(ctx, conn.ID, dto.ImportStripeRequest{})
+		require.NoError(t, err)
+		assert.True(t, resp.Applied)
+		assert.Equal(t, 4, resp.Created)
+		assert.Equal(t, 1, resp.Conflicts)
+		assert.Equal(t, 1, resp.Skipped)
+
+		plans, err := planStore.List(ctx, types.Filter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, plans, 1)
+		assert.Equal(t, "prod_1", plans[0].Metadata[types.MetadataStripeProductID])
+
+		prices, err := priceStore.List(ctx, types.Filter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, prices, 1)
+		assert.True(t, decimal.NewFromInt(29).Equal(prices[0].Amount))
+		assert.Equal(t, "usd", prices[0].Currency)
+		assert.Equal(t, types.BILLING_PERIOD_MONTHLY, prices[0].BillingPeriod)
+
+		custs, err := customerStore.ListByExternalIDs(ctx, []string{"cus_1"})
+		require.NoError(t, err)
+		require.Len(t, custs, 1)
+		cust := custs[0]
+		require.NotNil(t, cust.BillingAddress)
+		assert.Equal(t, "DE", cust.BillingAddress.Country)
+
+		subs, err := subscriptionStore.List(ctx, &types.SubscriptionFilter{Filter: types.Filter{Limit: 10}})
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+		assert.Equal(t, cust.ID, subs[0].CustomerID)
+		assert.Equal(t, plans[0].ID, subs[0].PlanID)
+		assert.Equal(t, types.SubscriptionStatusActive, subs[0].SubscriptionStatus)
+	})
+
+	t.Run("import can be run again", func(t *testing.T) {
+		resp, err := svc.Import(ctx, conn.ID, dto.ImportStripeRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, resp.Created)
+		assert.Equal(t, 4, resp.Existing)
+	})
+}
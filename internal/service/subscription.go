@@ -2,40 +2,69 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/cancellationreason"
 	"github.com/flexprice/flexprice/internal/domain/customer"
 	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
 	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
 	"github.com/flexprice/flexprice/internal/domain/plan"
 	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
 	"github.com/flexprice/flexprice/internal/domain/subscription"
 	"github.com/flexprice/flexprice/internal/kafka"
 	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
 	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
 	"github.com/shopspring/decimal"
 )
 
+// ErrUnknownCancellationReason is returned when a cancellation reason is not part
+// of the tenant's taxonomy
+var ErrUnknownCancellationReason = errors.New("unknown cancellation reason")
+
 type SubscriptionService interface {
 	CreateSubscription(ctx context.Context, req dto.CreateSubscriptionRequest) (*dto.SubscriptionResponse, error)
 	GetSubscription(ctx context.Context, id string) (*dto.SubscriptionResponse, error)
-	CancelSubscription(ctx context.Context, id string, cancelAtPeriodEnd bool) error
+	// GetSubscriptionWithExpand returns the subscription with the expanded records
+	// embedded, GetSubscription expands the plan and its prices
+	GetSubscriptionWithExpand(ctx context.Context, id string, expand types.Expand) (*dto.SubscriptionResponse, error)
+	CancelSubscription(ctx context.Context, id string, req dto.CancelSubscriptionRequest) error
 	ListSubscriptions(ctx context.Context, filter *types.SubscriptionFilter) (*dto.ListSubscriptionsResponse, error)
 	GetUsageBySubscription(ctx context.Context, req *dto.GetUsageBySubscriptionRequest) (*dto.GetUsageBySubscriptionResponse, error)
 }
 
 type subscriptionService struct {
-	subscriptionRepo subscription.Repository
-	planRepo         plan.Repository
-	priceRepo        price.Repository
-	producer         kafka.MessageProducer
-	eventRepo        events.Repository
-	meterRepo        meter.Repository
-	customerRepo     customer.Repository
-	logger           *logger.Logger
+	subscriptionRepo  subscription.Repository
+	planRepo          plan.Repository
+	priceRepo         price.Repository
+	producer          kafka.MessageProducer
+	eventRepo         events.Repository
+	meterRepo         meter.Repository
+	customerRepo      customer.Repository
+	paymentMethodRepo paymentmethod.Repository
+	reasonRepo        cancellationreason.Repository
+	priceBookRepo     pricebook.Repository
+	legalEntityRepo   legalentity.Repository
+	preHooks          webhook.PreHookGate
+	crmSyncService    CRMSyncService
+	invoiceService    InvoiceService
+	db                postgres.TxManager
+	auditPublisher    audit.Publisher
+	// clock is the time subscriptions start and are cancelled at, the wall
+	// clock when nil
+	clock  *clock.Clock
+	logger *logger.Logger
 }
 
 func NewSubscriptionService(
@@ -46,8 +75,59 @@ func NewSubscriptionService(
 	eventRepo events.Repository,
 	meterRepo meter.Repository,
 	customerRepo customer.Repository,
+	paymentMethodRepo paymentmethod.Repository,
+	reasonRepo cancellationreason.Repository,
+	priceBookRepo pricebook.Repository,
+	legalEntityRepo legalentity.Repository,
+	preHooks webhook.PreHookGate,
+	crmSyncService CRMSyncService,
+	invoiceService InvoiceService,
+	db postgres.TxManager,
+	auditPublisher audit.Publisher,
+	clock *clock.Clock,
 	logger *logger.Logger,
 ) SubscriptionService {
+	return &subscriptionService{
+		subscriptionRepo:  subscriptionRepo,
+		planRepo:          planRepo,
+		priceRepo:         priceRepo,
+		producer:          producer,
+		eventRepo:         eventRepo,
+		meterRepo:         meterRepo,
+		customerRepo:      customerRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		reasonRepo:        reasonRepo,
+		priceBookRepo:     priceBookRepo,
+		legalEntityRepo:   legalEntityRepo,
+		preHooks:          preHooks,
+		crmSyncService:    crmSyncService,
+		invoiceService:    invoiceService,
+		db:                db,
+		auditPublisher:    auditPublisher,
+		clock:             clock,
+		logger:            logger,
+	}
+}
+
+// SubscriptionReader reads the subscriptions and their usage. The invoices depend
+// on it rather than on the SubscriptionService, which depends on the invoices
+type SubscriptionReader interface {
+	GetSubscription(ctx context.Context, id string) (*dto.SubscriptionResponse, error)
+	ListSubscriptions(ctx context.Context, filter *types.SubscriptionFilter) (*dto.ListSubscriptionsResponse, error)
+	GetUsageBySubscription(ctx context.Context, req *dto.GetUsageBySubscriptionRequest) (*dto.GetUsageBySubscriptionResponse, error)
+}
+
+func NewSubscriptionReader(
+	subscriptionRepo subscription.Repository,
+	planRepo plan.Repository,
+	priceRepo price.Repository,
+	producer kafka.MessageProducer,
+	eventRepo events.Repository,
+	meterRepo meter.Repository,
+	customerRepo customer.Repository,
+	clock *clock.Clock,
+	logger *logger.Logger,
+) SubscriptionReader {
 	return &subscriptionService{
 		subscriptionRepo: subscriptionRepo,
 		planRepo:         planRepo,
@@ -56,6 +136,7 @@ func NewSubscriptionService(
 		eventRepo:        eventRepo,
 		meterRepo:        meterRepo,
 		customerRepo:     customerRepo,
+		clock:            clock,
 		logger:           logger,
 	}
 }
@@ -83,8 +164,37 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req dto.Cr
 		return nil, fmt.Errorf("no prices found for plan")
 	}
 
+	customer, err := s.customerRepo.Get(ctx, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
 	subscription := req.ToSubscription(ctx)
-	now := time.Now().UTC()
+	subscription.Timezone = customer.Timezone
+
+	book, err := assignPriceBook(ctx, s.priceBookRepo, req.PriceBookID, plan.ID, customer)
+	if err != nil {
+		return nil, err
+	}
+	if book != nil {
+		subscription.PriceBookID = book.ID
+	}
+
+	if err := checkLegalEntity(ctx, s.legalEntityRepo, req.LegalEntityID); err != nil {
+		return nil, err
+	}
+
+	if req.PaymentMethodID != "" {
+		if s.paymentMethodRepo == nil {
+			return nil, fmt.Errorf("payment methods are not supported")
+		}
+		pm, err := s.paymentMethodRepo.Get(ctx, req.PaymentMethodID)
+		if err != nil || pm.CustomerID != customer.ID {
+			return nil, ErrPaymentMethodNotFound
+		}
+		subscription.GatewayPaymentMethodID = pm.GatewayPaymentMethodID
+	}
+	now := s.clock.Now()
 	if subscription.StartDate.IsZero() {
 		subscription.StartDate = now
 	}
@@ -97,85 +207,300 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req dto.Cr
 		subscription.BillingPeriodCount = 1
 	}
 
-	nextBillingDate, err := types.NextBillingDate(subscription.StartDate, subscription.BillingPeriodCount, subscription.BillingPeriod)
+	if subscription.BillingCycle == "" {
+		subscription.BillingCycle = types.BillingCycleAnniversary
+	}
+
+	anchored := subscription.BillingCycle == types.BillingCycleCalendar || subscription.BillingAnchorDay > 0
+	if anchored && subscription.PartialPeriodBehavior == "" {
+		subscription.PartialPeriodBehavior = types.PartialPeriodBehaviorProrate
+	}
+
+	nextBillingDate, err := periodEndFrom(subscription, subscription.StartDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate next billing date: %w", err)
 	}
 
 	subscription.CurrentPeriodStart = subscription.StartDate
 	subscription.CurrentPeriodEnd = nextBillingDate
+	if anchored {
+		subscription.BillingAnchor = nextBillingDate
+	}
+
+	// The trial is the first period of the subscription and billing is anchored
+	// on its end. The plan trial period in days applies unless a trial end is passed
+	trialEnd := req.TrialEnd
+	if trialEnd == nil && plan.TrialPeriod > 0 {
+		end := subscription.StartDate.AddDate(0, 0, plan.TrialPeriod)
+		trialEnd = &end
+	}
+
+	if trialEnd != nil {
+		if !trialEnd.After(subscription.StartDate) {
+			return nil, fmt.Errorf("trial_end must be after start_date")
+		}
+
+		trialStart := subscription.StartDate
+		subscription.SubscriptionStatus = types.SubscriptionStatusTrialing
+		subscription.TrialStart = &trialStart
+		subscription.TrialEnd = trialEnd
+		subscription.BillingAnchor = *trialEnd
+		subscription.CurrentPeriodEnd = *trialEnd
+	}
+	if err := advanceToCurrentPeriod(subscription, now); err != nil {
+		return nil, err
+	}
+
 	subscription.InvoiceCadence = plan.InvoiceCadence
 	subscription.Currency = prices[0].Currency
+	subscription.LineItems = newLineItems(ctx, subscription, prices)
+
+	if err := s.checkPreHooks(ctx, types.PreHookOperationSubscriptionCreate, subscription); err != nil {
+		return nil, err
+	}
+
+	if !req.GeneratePastInvoices {
+		if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+			return nil, fmt.Errorf("failed to create subscription: %w", err)
+		}
 
-	if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
-		return nil, fmt.Errorf("failed to create subscription: %w", err)
+		recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeSubscription, subscription.ID, types.AuditActionCreate, nil, subscription)
+		s.syncCRM(ctx, subscription.ID)
+		return &dto.SubscriptionResponse{Subscription: subscription}, nil
 	}
 
-	return &dto.SubscriptionResponse{Subscription: subscription}, nil
+	if s.invoiceService == nil || s.db == nil {
+		return nil, fmt.Errorf("past invoices are not supported")
+	}
+
+	// The subscription is only created along with the invoices of its past periods
+	var pastInvoices *dto.ListInvoicesResponse
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+			return fmt.Errorf("failed to create subscription: %w", err)
+		}
+
+		pastInvoices, err = s.invoiceService.CreatePastInvoices(ctx, subscription.ID, req.ConsolidatePastInvoices)
+		if err != nil {
+			return fmt.Errorf("failed to create past invoices: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeSubscription, subscription.ID, types.AuditActionCreate, nil, subscription)
+	s.syncCRM(ctx, subscription.ID)
+	return &dto.SubscriptionResponse{Subscription: subscription, PastInvoices: pastInvoices.Invoices}, nil
+}
+
+// advanceToCurrentPeriod moves a subscription starting in the past to the period
+// containing now. A trial that ended before now is taken as converted, the trial
+// job only ends the trials of subscriptions that were created before they ended
+func advanceToCurrentPeriod(sub *subscription.Subscription, now time.Time) error {
+	for !sub.CurrentPeriodEnd.After(now) {
+		start := sub.CurrentPeriodEnd
+		end, err := periodEndFrom(sub, start)
+		if err != nil {
+			return fmt.Errorf("failed to calculate next billing date: %w", err)
+		}
+
+		if sub.SubscriptionStatus == types.SubscriptionStatusTrialing {
+			sub.SubscriptionStatus = types.SubscriptionStatusActive
+		}
+		sub.CurrentPeriodStart = start
+		sub.CurrentPeriodEnd = end
+	}
+	return nil
 }
 
 func (s *subscriptionService) GetSubscription(ctx context.Context, id string) (*dto.SubscriptionResponse, error) {
-	subscription, err := s.subscriptionRepo.Get(ctx, id)
+	return s.GetSubscriptionWithExpand(ctx, id, types.DefaultSubscriptionExpand)
+}
+
+func (s *subscriptionService) GetSubscriptionWithExpand(ctx context.Context, id string, expand types.Expand) (*dto.SubscriptionResponse, error) {
+	subscription, err := types.LoadCached(ctx, "subscription:"+id, func() (*subscription.Subscription, error) {
+		return s.subscriptionRepo.Get(ctx, id)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
 
-	planService := NewPlanService(s.planRepo, s.priceRepo, s.logger)
-	plan, err := planService.GetPlan(ctx, subscription.PlanID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get plan: %w", err)
+	response := &dto.SubscriptionResponse{Subscription: subscription}
+	if err := s.expandSubscriptions(ctx, []*dto.SubscriptionResponse{response}, expand); err != nil {
+		return nil, err
 	}
-
-	return &dto.SubscriptionResponse{Subscription: subscription, Plan: plan}, nil
+	return response, nil
 }
 
-func (s *subscriptionService) CancelSubscription(ctx context.Context, id string, cancelAtPeriodEnd bool) error {
+func (s *subscriptionService) CancelSubscription(ctx context.Context, id string, req dto.CancelSubscriptionRequest) error {
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	reasons, _, err := listCancellationReasons(ctx, s.reasonRepo)
+	if err != nil {
+		return err
+	}
+	if !containsCancellationReason(reasons, req.Reason) {
+		return fmt.Errorf("%w: %s", ErrUnknownCancellationReason, req.Reason)
+	}
+
 	subscription, err := s.subscriptionRepo.Get(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get subscription: %w", err)
 	}
+	if err := checkIfMatch(ctx, "subscription", subscription.ID, subscription.Version); err != nil {
+		return err
+	}
 
-	now := time.Now().UTC()
+	before := *subscription
+	now := s.clock.Now()
 	subscription.SubscriptionStatus = types.SubscriptionStatusCancelled
 	subscription.CancelledAt = &now
-	subscription.CancelAtPeriodEnd = cancelAtPeriodEnd
+	subscription.CancelAtPeriodEnd = req.CancelAtPeriodEnd
+	subscription.CancellationReason = req.Reason
+	subscription.CancellationComment = req.Comment
+
+	if err := s.checkPreHooks(ctx, types.PreHookOperationSubscriptionCancel, subscription); err != nil {
+		return err
+	}
 
 	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
 		return fmt.Errorf("failed to cancel subscription: %w", err)
 	}
 
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeSubscription, subscription.ID, types.AuditActionUpdate, &before, subscription)
+	s.syncCRM(ctx, subscription.ID)
 	return nil
 }
 
+// periodEndFrom returns the end of the billing period of the subscription starting
+// at start, computed in the timezone of the subscription. Calendar and anchor day
+// billed periods starting between two boundaries are partial and end at the next
+// boundary
+func periodEndFrom(sub *subscription.Subscription, start time.Time) (time.Time, error) {
+	loc, err := types.LoadTimezone(sub.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	start = start.In(loc)
+
+	if sub.BillingAnchorDay > 0 {
+		return types.NextAnchoredBillingDate(start, sub.BillingAnchorDay, sub.BillingPeriodCount)
+	}
+
+	if sub.BillingCycle == types.BillingCycleCalendar {
+		calendarStart, calendarEnd, err := types.CalendarPeriod(start, sub.BillingPeriod)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !calendarStart.Equal(start) {
+			return calendarEnd, nil
+		}
+	}
+
+	return types.NextBillingDate(start, sub.BillingPeriodCount, sub.BillingPeriod)
+}
+
+// newLineItems returns a line item charged once per period for every recurring
+// fixed price of the plan valid for the subscription
+func newLineItems(ctx context.Context, sub *subscription.Subscription, prices []*price.Price) []*subscription.LineItem {
+	var items []*subscription.LineItem
+	for _, p := range prices {
+		if p.Type != types.PRICE_TYPE_FIXED || p.BillingCadence != types.BILLING_CADENCE_RECURRING {
+			continue
+		}
+		if p.Currency != sub.Currency || p.BillingPeriod != sub.BillingPeriod || p.BillingPeriodCount != sub.BillingPeriodCount {
+			continue
+		}
+
+		items = append(items, &subscription.LineItem{
+			ID:             types.GenerateUUID(),
+			SubscriptionID: sub.ID,
+			PriceID:        p.ID,
+			Quantity:       decimal.NewFromInt(1),
+			BaseModel:      types.GetDefaultBaseModel(ctx),
+		})
+	}
+	return items
+}
+
+func containsCancellationReason(reasons []*cancellationreason.CancellationReason, code string) bool {
+	for _, reason := range reasons {
+		if reason.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// syncCRM queues the subscription for the CRM connections. A failure to queue
+// does not fail the change
+func (s *subscriptionService) syncCRM(ctx context.Context, subscriptionID string) {
+	if s.crmSyncService == nil {
+		return
+	}
+	if err := s.crmSyncService.SyncSubscription(ctx, subscriptionID); err != nil {
+		s.logger.Errorw("failed to queue crm sync", "subscription_id", subscriptionID, "error", err)
+	}
+}
+
+// checkPreHooks lets the tenant veto the operation on the subscription, passed
+// as it would be saved. Services that only read subscriptions build this
+// service without a gate
+func (s *subscriptionService) checkPreHooks(ctx context.Context, op types.PreHookOperation, sub *subscription.Subscription) error {
+	if s.preHooks == nil {
+		return nil
+	}
+	return s.preHooks.Check(ctx, op, sub)
+}
+
 func (s *subscriptionService) ListSubscriptions(ctx context.Context, filter *types.SubscriptionFilter) (*dto.ListSubscriptionsResponse, error) {
 	if filter.Limit == 0 {
 		filter.Limit = 10
 	}
 
-	subscriptions, err := s.subscriptionRepo.List(ctx, filter)
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	expand, err := types.ParseExpand(filter.Expand, types.DefaultSubscriptionListExpand)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cursor page reads one subscription more to know whether another page follows
+	query := *filter
+	if filter.UsesCursor() {
+		query.Limit++
+	}
+
+	subscriptions, err := s.subscriptionRepo.List(ctx, &query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
 	}
 
+	var nextCursor string
+	if filter.UsesCursor() && len(subscriptions) > filter.Limit {
+		subscriptions = subscriptions[:filter.Limit]
+		last := subscriptions[len(subscriptions)-1]
+		nextCursor = types.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
 	response := &dto.ListSubscriptionsResponse{
 		Subscriptions: make([]*dto.SubscriptionResponse, len(subscriptions)),
 		Total:         len(subscriptions),
 		Offset:        filter.Offset,
 		Limit:         filter.Limit,
+		NextCursor:    nextCursor,
 	}
 
 	for i, sub := range subscriptions {
-		plan, err := s.planRepo.Get(ctx, sub.PlanID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get plan: %w", err)
-		}
-
-		response.Subscriptions[i] = &dto.SubscriptionResponse{
-			Subscription: sub,
-			Plan: &dto.PlanResponse{
-				Plan: plan,
-			},
-		}
+		response.Subscriptions[i] = &dto.SubscriptionResponse{Subscription: sub}
+	}
+	if err := s.expandSubscriptions(ctx, response.Subscriptions, expand); err != nil {
+		return nil, err
 	}
 
 	return response, nil
@@ -184,8 +509,8 @@ func (s *subscriptionService) ListSubscriptions(ctx context.Context, filter *typ
 func (s *subscriptionService) GetUsageBySubscription(ctx context.Context, req *dto.GetUsageBySubscriptionRequest) (*dto.GetUsageBySubscriptionResponse, error) {
 	response := &dto.GetUsageBySubscriptionResponse{}
 
-	eventService := NewEventService(s.producer, s.eventRepo, s.meterRepo, s.logger)
-	priceService := NewPriceService(s.priceRepo, s.logger)
+	eventService := NewEventService(config.GetDefaultConfig(), s.producer, s.eventRepo, s.meterRepo, nil, nil, nil, nil, s.clock, s.logger)
+	priceService := NewPriceService(s.priceRepo, nil, s.logger)
 
 	subscriptionResponse, err := s.GetSubscription(ctx, req.SubscriptionID)
 	if err != nil {
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// expandSubscriptions embeds the expanded records in the subscriptions. Every
+// plan, its prices and every customer are loaded once for all the
+// subscriptions referring to them, and through the request cache once for the
+// whole request. The loads run concurrently outside of a transaction
+func (s *subscriptionService) expandSubscriptions(ctx context.Context, subs []*dto.SubscriptionResponse, expand types.Expand) error {
+	plans := make(map[string]*dto.PlanResponse)
+	customers := make(map[string]*dto.CustomerResponse)
+	for _, sub := range subs {
+		if expand.Has(types.ExpandPlan) {
+			plans[sub.PlanID] = nil
+		}
+		if expand.Has(types.ExpandCustomer) {
+			customers[sub.CustomerID] = nil
+		}
+	}
+
+	var (
+		mu    sync.Mutex
+		loads []func() error
+	)
+	for planID := range plans {
+		planID := planID
+		loads = append(loads, func() error {
+			resp, err := s.loadPlan(ctx, planID, expand.Has(types.ExpandPrices))
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			plans[planID] = resp
+			mu.Unlock()
+			return nil
+		})
+	}
+	for customerID := range customers {
+		customerID := customerID
+		loads = append(loads, func() error {
+			cust, err := types.LoadCached(ctx, "customer:"+customerID, func() (*dto.CustomerResponse, error) {
+				cust, err := s.customerRepo.Get(ctx, customerID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get customer: %w", err)
+				}
+				return &dto.CustomerResponse{Customer: cust}, nil
+			})
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			customers[customerID] = cust
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := runLoads(ctx, loads); err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if expand.Has(types.ExpandPlan) {
+			sub.Plan = plans[sub.PlanID]
+		}
+		if expand.Has(types.ExpandCustomer) {
+			sub.Customer = customers[sub.CustomerID]
+		}
+	}
+	return nil
+}
+
+// loadPlan reads the plan and, when they are expanded, its prices concurrently
+func (s *subscriptionService) loadPlan(ctx context.Context, planID string, withPrices bool) (*dto.PlanResponse, error) {
+	var (
+		p      *plan.Plan
+		prices []*price.Price
+	)
+	loads := []func() error{func() error {
+		var err error
+		p, err = types.LoadCached(ctx, "plan:"+planID, func() (*plan.Plan, error) {
+			return s.planRepo.Get(ctx, planID)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get plan: %w", err)
+		}
+		return nil
+	}}
+	if withPrices {
+		loads = append(loads, func() error {
+			var err error
+			prices, err = types.LoadCached(ctx, "plan_prices:"+planID, func() ([]*price.Price, error) {
+				return s.priceRepo.GetByPlanID(ctx, planID)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get prices: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if err := runLoads(ctx, loads); err != nil {
+		return nil, err
+	}
+
+	response := &dto.PlanResponse{Plan: p}
+	for _, pr := range prices {
+		if pr.PlanID == p.ID {
+			response.Prices = append(response.Prices, dto.PriceResponse{Price: pr})
+		}
+	}
+	return response, nil
+}
+
+// runLoads runs the loads concurrently and returns the first error. A
+// transaction runs its queries one at a time on its connection, so the loads
+// of a context carrying one run in order
+func runLoads(ctx context.Context, loads []func() error) error {
+	if len(loads) == 1 || ctx.Value(types.CtxDBTransaction) != nil {
+		for _, load := range loads {
+			if err := load(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, len(loads))
+	var wg sync.WaitGroup
+	for i, load := range loads {
+		wg.Add(1)
+		go func(i int, load func() error) {
+			defer wg.Done()
+			errs[i] = load()
+		}(i, load)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
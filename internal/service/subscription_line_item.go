@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/billingengine"
+	"github.com/flexprice/flexprice/internal/clock"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+)
+
+// ErrSubscriptionLineItemNotFound is returned when the line item is not one of
+// the subscription
+var ErrSubscriptionLineItemNotFound = errors.New("subscription line item not found")
+
+type SubscriptionLineItemService interface {
+	// UpdateLineItem changes the quantity or the unit amount of a recurring fixed
+	// line item of an active or trialing subscription. A change in the middle of
+	// a paid period is prorated on the next invoice unless the proration behavior
+	// is none
+	UpdateLineItem(ctx context.Context, subscriptionID, lineItemID string, req dto.UpdateSubscriptionLineItemRequest) (*dto.SubscriptionLineItemResponse, error)
+}
+
+// SubscriptionUpdatedEvent is the payload of the subscription.updated webhook
+type SubscriptionUpdatedEvent struct {
+	Subscription *subscription.Subscription `json:"subscription"`
+	LineItem     *subscription.LineItem     `json:"line_item"`
+	Proration    *subscription.Proration    `json:"proration,omitempty"`
+}
+
+type subscriptionLineItemService struct {
+	subscriptionRepo subscription.Repository
+	priceRepo        price.Repository
+	rateCardRepo     ratecard.Repository
+	priceBookRepo    pricebook.Repository
+	db               postgres.TxManager
+	webhookPublisher webhook.Publisher
+	auditPublisher   audit.Publisher
+
+	// featureFlagService is optional, the flags keep their default without it
+	featureFlagService FeatureFlagService
+	// clock is the time line items change at, the wall clock when nil
+	clock  *clock.Clock
+	logger *logger.Logger
+}
+
+func NewSubscriptionLineItemService(
+	subscriptionRepo subscription.Repository,
+	priceRepo price.Repository,
+	rateCardRepo ratecard.Repository,
+	priceBookRepo pricebook.Repository,
+	db postgres.TxManager,
+	webhookPublisher webhook.Publisher,
+	auditPublisher audit.Publisher,
+	featureFlagService FeatureFlagService,
+	clock *clock.Clock,
+	logger *logger.Logger,
+) SubscriptionLineItemService {
+	return &subscriptionLineItemService{
+		subscriptionRepo:   subscriptionRepo,
+		priceRepo:          priceRepo,
+		rateCardRepo:       rateCardRepo,
+		priceBookRepo:      priceBookRepo,
+		db:                 db,
+		webhookPublisher:   webhookPublisher,
+		auditPublisher:     auditPublisher,
+		featureFlagService: featureFlagService,
+		clock:              clock,
+		logger:             logger,
+	}
+}
+
+func (s *subscriptionLineItemService) UpdateLineItem(ctx context.Context, subscriptionID, lineItemID string, req dto.UpdateSubscriptionLineItemRequest) (*dto.SubscriptionLineItemResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	sub, err := s.subscriptionRepo.Get(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if err := checkIfMatch(ctx, "subscription", sub.ID, sub.Version); err != nil {
+		return nil, err
+	}
+
+	if sub.SubscriptionStatus != types.SubscriptionStatusActive &&
+		sub.SubscriptionStatus != types.SubscriptionStatusTrialing {
+		return nil, fmt.Errorf("%s subscriptions can not be changed", sub.SubscriptionStatus)
+	}
+
+	var item *subscription.LineItem
+	for _, li := range sub.LineItems {
+		if li.ID == lineItemID {
+			item = li
+			break
+		}
+	}
+	if item == nil {
+		return nil, ErrSubscriptionLineItemNotFound
+	}
+
+	p, err := s.priceRepo.Get(ctx, item.PriceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price: %w", err)
+	}
+
+	book, err := subscriptionPriceBook(ctx, s.priceBookRepo, sub)
+	if err != nil {
+		return nil, err
+	}
+	if book != nil {
+		p = book.Apply(p)
+	}
+
+	card, err := s.effectiveRateCard(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	before := s.periodCharge(item, p, card)
+
+	updated := *item
+	if req.Quantity != nil {
+		updated.Quantity = *req.Quantity
+	}
+	if req.UnitAmount != nil {
+		updated.UnitAmount = req.UnitAmount
+	}
+	after := s.periodCharge(&updated, p, card)
+
+	now := s.clock.Now()
+	var proration *subscription.Proration
+	if req.ProrationBehavior != types.ProrationBehaviorNone {
+		proration, err = s.prorate(ctx, sub, &updated, p, before, after, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.subscriptionRepo.UpdateLineItem(ctx, &updated); err != nil {
+			return err
+		}
+
+		if proration != nil {
+			if err := s.subscriptionRepo.CreateProration(ctx, proration); err != nil {
+				return err
+			}
+		}
+
+		for i, li := range sub.LineItems {
+			if li.ID == updated.ID {
+				sub.LineItems[i] = &updated
+			}
+		}
+
+		// The line items are part of the subscription, changing one moves its version
+		// and fails the change when the subscription was updated since it was read
+		if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventSubscriptionUpdated, &SubscriptionUpdatedEvent{
+			Subscription: sub,
+			LineItem:     &updated,
+			Proration:    proration,
+		}); err != nil {
+			return fmt.Errorf("failed to publish subscription updated webhook: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeSubscriptionItem, updated.ID, types.AuditActionUpdate, item, &updated)
+	return &dto.SubscriptionLineItemResponse{LineItem: &updated, Proration: proration}, nil
+}
+
+// periodCharge returns the charge of a full period for the line item, at the
+// negotiated rate of the price unless the line item overrides it
+func (s *subscriptionLineItemService) periodCharge(item *subscription.LineItem, p *price.Price, card *ratecard.RateCard) decimal.Decimal {
+	if card != nil {
+		p = card.Apply(p)
+	}
+	return item.Apply(p).Amount.Mul(item.Quantity)
+}
+
+// prorate returns the proration of the change for the current period, nil when
+// the period is not billed, the change does not move its charge or proration
+// is turned off for the tenant
+func (s *subscriptionLineItemService) prorate(
+	ctx context.Context,
+	sub *subscription.Subscription,
+	item *subscription.LineItem,
+	p *price.Price,
+	before, after decimal.Decimal,
+	changedAt time.Time,
+) (*subscription.Proration, error) {
+	if !featureEnabled(ctx, s.featureFlagService, types.FeatureFlagMidPeriodProration) {
+		return nil, nil
+	}
+
+	// Periods within the trial are free
+	if sub.TrialEnd != nil && !sub.CurrentPeriodEnd.After(*sub.TrialEnd) {
+		return nil, nil
+	}
+
+	share, _, err := billingengine.PeriodShare(billingengine.Period{
+		Start:                 sub.CurrentPeriodStart,
+		FirstPaidStart:        firstPaidPeriodStart(sub),
+		BillingCycle:          sub.BillingCycle,
+		BillingPeriod:         sub.BillingPeriod,
+		BillingPeriodCount:    sub.BillingPeriodCount,
+		BillingAnchorDay:      sub.BillingAnchorDay,
+		Timezone:              sub.Timezone,
+		PartialPeriodBehavior: sub.PartialPeriodBehavior,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate partial period: %w", err)
+	}
+
+	amount := billingengine.Proration(billingengine.Change{
+		Currency:    sub.Currency,
+		PeriodStart: sub.CurrentPeriodStart,
+		PeriodEnd:   sub.CurrentPeriodEnd,
+		ChangedAt:   changedAt,
+		PeriodShare: share,
+		Before:      before,
+		After:       after,
+	})
+	if amount.IsZero() {
+		return nil, nil
+	}
+
+	name := p.Description
+	if name == "" {
+		name = p.LookupKey
+	}
+
+	return &subscription.Proration{
+		ID:             types.GenerateUUID(),
+		SubscriptionID: sub.ID,
+		LineItemID:     item.ID,
+		PriceID:        p.ID,
+		DisplayName:    fmt.Sprintf("Proration of %s", name),
+		Amount:         amount,
+		Currency:       sub.Currency,
+		PeriodStart:    sub.CurrentPeriodStart,
+		ChangedAt:      changedAt,
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}, nil
+}
+
+func (s *subscriptionLineItemService) effectiveRateCard(ctx context.Context, sub *subscription.Subscription) (*ratecard.RateCard, error) {
+	if s.rateCardRepo == nil {
+		return nil, nil
+	}
+
+	cards, err := s.rateCardRepo.ListByCustomer(ctx, sub.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rate cards: %w", err)
+	}
+
+	return ratecard.Effective(cards, sub.ID, sub.CurrentPeriodStart), nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionLineItemService_UpdateLineItem(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	publisher := webhook.NewPublisher(webhookStore, logger.GetLogger())
+
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:         "ep_1",
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{string(types.WebhookEventSubscriptionUpdated)},
+		Secret:     "whsec_test",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{ID: "cust_1", BaseModel: types.GetDefaultBaseModel(ctx)}))
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{ID: "plan_1", Name: "Team", BaseModel: types.GetDefaultBaseModel(ctx)}))
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_seat",
+		PlanID:             "plan_1",
+		Type:               types.PRICE_TYPE_FIXED,
+		Amount:             decimal.NewFromInt(30),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		Description:        "Seats",
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	// A third of the current period is elapsed
+	now := time.Now().UTC()
+	sub := &subscription.Subscription{
+		ID:                 "sub_1",
+		PlanID:             "plan_1",
+		CustomerID:         "cust_1",
+		SubscriptionStatus: types.SubscriptionStatusActive,
+		StartDate:          now.AddDate(0, 0, -10),
+		CurrentPeriodStart: now.AddDate(0, 0, -10),
+		CurrentPeriodEnd:   now.AddDate(0, 0, 20),
+		Currency:           "usd",
+		BillingCycle:       types.BillingCycleAnniversary,
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		LineItems: []*subscription.LineItem{{
+			ID:             "li_seat",
+			SubscriptionID: "sub_1",
+			PriceID:        "price_seat",
+			Quantity:       decimal.NewFromInt(1),
+			BaseModel:      types.GetDefaultBaseModel(ctx),
+		}},
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}
+	require.NoError(t, subscriptionStore.Create(ctx, sub))
+
+	svc := NewSubscriptionLineItemService(subscriptionStore, priceStore, testutil.NewInMemoryRateCardStore(), nil,
+		testutil.NewInMemoryTxManager(), publisher, nil, nil, nil, logger.GetLogger())
+	invoiceService := NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+		nil, testutil.NewInMemoryTxManager(), publisher, nil, nil, nil, nil,
+		nil, nil,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(), testutil.NewInMemoryMeterStore(), customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	t.Run("prorates a quantity change", func(t *testing.T) {
+		quantity := decimal.NewFromInt(4)
+		resp, err := svc.UpdateLineItem(ctx, "sub_1", "li_seat", dto.UpdateSubscriptionLineItemRequest{Quantity: &quantity})
+		require.NoError(t, err)
+		assert.True(t, quantity.Equal(resp.Quantity))
+
+		// The 10 days at 1 seat are credited the 3 seats added: -90 * 1/3
+		require.NotNil(t, resp.Proration)
+		assert.InDelta(t, -30, resp.Proration.Amount.InexactFloat64(), 0.01)
+
+		inv, err := invoiceService.CreateSubscriptionInvoice(ctx, "sub_1", dto.CreateSubscriptionInvoiceRequest{})
+		require.NoError(t, err)
+		require.Len(t, inv.LineItems, 2)
+		assert.True(t, decimal.NewFromInt(120).Equal(inv.LineItems[0].Amount))
+		assert.Equal(t, resp.Proration.ID, inv.LineItems[1].Metadata["proration_id"])
+		assert.InDelta(t, 90, inv.Total.InexactFloat64(), 0.01)
+
+		// Prorations are billed once
+		pending, err := subscriptionStore.ListPendingProrations(ctx, "sub_1")
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("overrides the price without proration", func(t *testing.T) {
+		unitAmount := decimal.NewFromInt(25)
+		resp, err := svc.UpdateLineItem(ctx, "sub_1", "li_seat", dto.UpdateSubscriptionLineItemRequest{
+			UnitAmount:        &unitAmount,
+			ProrationBehavior: types.ProrationBehaviorNone,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, resp.Proration)
+
+		upcoming, err := invoiceService.GetUpcomingInvoice(ctx, "sub_1", nil)
+		require.NoError(t, err)
+		require.Len(t, upcoming.Invoice.LineItems, 1)
+		assert.True(t, decimal.NewFromInt(100).Equal(upcoming.Invoice.Total))
+	})
+
+	t.Run("is conditional on the subscription version", func(t *testing.T) {
+		// The changes above moved the version of the subscription
+		sub, err := subscriptionStore.Get(ctx, "sub_1")
+		require.NoError(t, err)
+		require.Greater(t, sub.Version, 1)
+
+		quantity := decimal.NewFromInt(2)
+		_, err = svc.UpdateLineItem(context.WithValue(ctx, types.CtxIfMatch, 1), "sub_1", "li_seat", dto.UpdateSubscriptionLineItemRequest{Quantity: &quantity})
+		var conflict *domainErrors.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, sub.Version, conflict.CurrentVersion)
+	})
+
+	t.Run("rejects unknown line items", func(t *testing.T) {
+		quantity := decimal.NewFromInt(2)
+		_, err := svc.UpdateLineItem(ctx, "sub_1", "li_unknown", dto.UpdateSubscriptionLineItemRequest{Quantity: &quantity})
+		assert.ErrorIs(t, err, ErrSubscriptionLineItemNotFound)
+	})
+
+	deliveries, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 2)
+}
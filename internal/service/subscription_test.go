@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
 	"github.com/flexprice/flexprice/internal/domain/customer"
 	"github.com/flexprice/flexprice/internal/domain/events"
 	"github.com/flexprice/flexprice/internal/domain/meter"
@@ -15,6 +17,7 @@ import (
 	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/testutil"
 	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -214,6 +217,15 @@ func TestSubscriptionService_GetUsageBySubscription(t *testing.T) {
 		eventStore,
 		meterStore,
 		customerStore,
+		nil,
+		nil,
+		nil,
+		nil, nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		log,
 	)
 
@@ -335,3 +347,214 @@ func TestSubscriptionService_GetUsageBySubscription(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscriptionService_CreateSubscription_Backdated(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_123",
+		Name:      "Test Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_fixed",
+		PlanID:             "plan_123",
+		Type:               types.PRICE_TYPE_FIXED,
+		Amount:             decimal.NewFromInt(20),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:        "cust_123",
+		Timezone:  types.DefaultTimezone,
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	// Three monthly periods elapsed before the one containing now
+	now := time.Now().UTC()
+	currentStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := currentStart.AddDate(0, -3, 0)
+
+	newService := func() (SubscriptionService, *testutil.InMemoryInvoiceStore) {
+		subscriptionStore := testutil.NewInMemorySubscriptionStore()
+		invoiceStore := testutil.NewInMemoryInvoiceStore()
+		invoiceService := NewInvoiceService(
+			invoiceStore, testutil.NewInMemorySequenceStore(), subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+			testutil.NewInMemoryMeterStore(), customerStore, testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(), testutil.NewInMemoryRateCardStore(), nil,
+			nil, testutil.NewInMemoryTxManager(),
+			webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+			nil, nil, nil, nil,
+			nil, nil,
+			NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+				testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(), testutil.NewInMemoryMeterStore(), customerStore,
+				nil, logger.GetLogger(),
+			), nil,
+			&config.Configuration{}, logger.GetLogger(),
+		)
+		svc := NewSubscriptionService(
+			subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+			testutil.NewInMemoryMeterStore(), customerStore, nil, nil, nil, nil, nil, nil,
+			invoiceService, testutil.NewInMemoryTxManager(), nil, nil, logger.GetLogger(),
+		)
+		return svc, invoiceStore
+	}
+
+	req := dto.CreateSubscriptionRequest{
+		CustomerID:    "cust_123",
+		PlanID:        "plan_123",
+		StartDate:     start,
+		BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+	}
+
+	t.Run("starts in the current period", func(t *testing.T) {
+		svc, invoiceStore := newService()
+		resp, err := svc.CreateSubscription(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, start, resp.StartDate)
+		assert.True(t, currentStart.Equal(resp.CurrentPeriodStart), "period start %s", resp.CurrentPeriodStart)
+		assert.True(t, currentStart.AddDate(0, 1, 0).Equal(resp.CurrentPeriodEnd), "period end %s", resp.CurrentPeriodEnd)
+		assert.Empty(t, resp.PastInvoices)
+
+		invoices, err := invoiceStore.List(ctx, &types.InvoiceFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, invoices)
+	})
+
+	t.Run("one invoice per past period", func(t *testing.T) {
+		svc, _ := newService()
+		withInvoices := req
+		withInvoices.GeneratePastInvoices = true
+		resp, err := svc.CreateSubscription(ctx, withInvoices)
+		require.NoError(t, err)
+		require.Len(t, resp.PastInvoices, 3)
+
+		for i, inv := range resp.PastInvoices {
+			assert.Equal(t, resp.ID, inv.SubscriptionID)
+			assert.True(t, start.AddDate(0, i, 0).Equal(*inv.PeriodStart), "period start %s", inv.PeriodStart)
+			assert.True(t, start.AddDate(0, i+1, 0).Equal(*inv.PeriodEnd), "period end %s", inv.PeriodEnd)
+			assert.True(t, decimal.NewFromInt(20).Equal(inv.Total))
+		}
+	})
+
+	t.Run("single catch-up invoice", func(t *testing.T) {
+		svc, _ := newService()
+		catchUp := req
+		catchUp.GeneratePastInvoices = true
+		catchUp.ConsolidatePastInvoices = true
+		resp, err := svc.CreateSubscription(ctx, catchUp)
+		require.NoError(t, err)
+		require.Len(t, resp.PastInvoices, 1)
+
+		inv := resp.PastInvoices[0]
+		assert.Equal(t, resp.ID, inv.SubscriptionID)
+		assert.True(t, start.Equal(*inv.PeriodStart))
+		assert.True(t, currentStart.Equal(*inv.PeriodEnd))
+		assert.True(t, decimal.NewFromInt(60).Equal(inv.Total))
+		require.Len(t, inv.LineItems, 3)
+		assert.True(t, start.AddDate(0, 2, 0).Equal(*inv.LineItems[2].PeriodStart))
+	})
+
+	t.Run("consolidation needs past invoices", func(t *testing.T) {
+		svc, _ := newService()
+		invalid := req
+		invalid.ConsolidatePastInvoices = true
+		_, err := svc.CreateSubscription(ctx, invalid)
+		assert.Error(t, err)
+	})
+}
+
+// countingPlanStore counts the plans read from the store
+type countingPlanStore struct {
+	*testutil.InMemoryPlanStore
+	gets atomic.Int32
+}
+
+func (s *countingPlanStore) Get(ctx context.Context, id string) (*plan.Plan, error) {
+	s.gets.Add(1)
+	return s.InMemoryPlanStore.Get(ctx, id)
+}
+
+func TestSubscriptionService_Expand(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	planStore := &countingPlanStore{InMemoryPlanStore: testutil.NewInMemoryPlanStore()}
+	priceStore := testutil.NewInMemoryPriceStore()
+	customerStore := testutil.NewInMemoryCustomerStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{ID: "plan_123", Name: "Test Plan", BaseModel: types.GetDefaultBaseModel(ctx)}))
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:        "price_fixed",
+		PlanID:    "plan_123",
+		Type:      types.PRICE_TYPE_FIXED,
+		Amount:    decimal.NewFromInt(20),
+		Currency:  "usd",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+	for _, id := range []string{"cust_1", "cust_2"} {
+		require.NoError(t, customerStore.Create(ctx, &customer.Customer{ID: id, BaseModel: types.GetDefaultBaseModel(ctx)}))
+	}
+	for i, customerID := range []string{"cust_1", "cust_2", "cust_1"} {
+		require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+			ID:         "sub_" + string(rune('a'+i)),
+			PlanID:     "plan_123",
+			CustomerID: customerID,
+			BaseModel:  types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	svc := NewSubscriptionService(
+		subscriptionStore, planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, nil, nil, nil, nil, nil, nil,
+		nil, testutil.NewInMemoryTxManager(), nil, nil, logger.GetLogger(),
+	)
+
+	t.Run("list reads a shared plan once", func(t *testing.T) {
+		planStore.gets.Store(0)
+		resp, err := svc.ListSubscriptions(ctx, &types.SubscriptionFilter{Filter: types.Filter{Limit: 10}, Expand: "plan,customer"})
+		require.NoError(t, err)
+		require.Len(t, resp.Subscriptions, 3)
+		for _, sub := range resp.Subscriptions {
+			require.NotNil(t, sub.Plan)
+			assert.Equal(t, "plan_123", sub.Plan.ID)
+			assert.Empty(t, sub.Plan.Prices)
+			require.NotNil(t, sub.Customer)
+			assert.Equal(t, sub.CustomerID, sub.Customer.ID)
+		}
+		assert.Equal(t, int32(1), planStore.gets.Load())
+	})
+
+	t.Run("request cache is shared across calls", func(t *testing.T) {
+		planStore.gets.Store(0)
+		reqCtx := types.WithRequestCache(ctx)
+		for _, id := range []string{"sub_a", "sub_b", "sub_a"} {
+			resp, err := svc.GetSubscription(reqCtx, id)
+			require.NoError(t, err)
+			require.Len(t, resp.Plan.Prices, 1)
+			assert.Nil(t, resp.Customer)
+		}
+		assert.Equal(t, int32(1), planStore.gets.Load())
+	})
+
+	t.Run("only the expanded records are embedded", func(t *testing.T) {
+		resp, err := svc.GetSubscriptionWithExpand(ctx, "sub_b", types.Expand{types.ExpandCustomer})
+		require.NoError(t, err)
+		assert.Nil(t, resp.Plan)
+		require.NotNil(t, resp.Customer)
+		assert.Equal(t, "cust_2", resp.Customer.ID)
+	})
+
+	t.Run("invalid expand", func(t *testing.T) {
+		_, err := svc.ListSubscriptions(ctx, &types.SubscriptionFilter{Expand: "coupons"})
+		assert.Error(t, err)
+	})
+}
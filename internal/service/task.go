@@ -0,0 +1,512 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/task"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/storage"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const (
+	// customerImportBatchSize is the number of rows imported between two
+	// progress updates of the task
+	customerImportBatchSize = 100
+	maxTaskFileBytes        = 20 << 20
+	taskFileDownloadTimeout = 2 * time.Minute
+)
+
+type TaskService interface {
+	// CreateTask processes a file in the background. The file is read from the
+	// given reader when it is set and downloaded from the file url of the
+	// request otherwise
+	CreateTask(ctx context.Context, req dto.CreateTaskRequest, file io.Reader) (*dto.TaskResponse, error)
+	GetTask(ctx context.Context, id string) (*dto.TaskResponse, error)
+	ListTasks(ctx context.Context, filter types.Filter) (*dto.ListTasksResponse, error)
+
+	// GetErrorReportURL returns a download link to the CSV report of the rows
+	// of a finished task which failed
+	GetErrorReportURL(ctx context.Context, id string) (*dto.TaskErrorReportResponse, error)
+}
+
+type taskService struct {
+	repo         task.Repository
+	customerRepo customer.Repository
+	store        storage.Store
+	cfg          config.ExportConfig
+	client       *http.Client
+	logger       *logger.Logger
+}
+
+func NewTaskService(
+	repo task.Repository,
+	customerRepo customer.Repository,
+	store storage.Store,
+	cfg *config.Configuration,
+	logger *logger.Logger,
+) TaskService {
+	return &taskService{
+		repo:         repo,
+		customerRepo: customerRepo,
+		store:        store,
+		cfg:          cfg.Export,
+		client:       &http.Client{Timeout: taskFileDownloadTimeout},
+		logger:       logger,
+	}
+}
+
+func (s *taskService) CreateTask(ctx context.Context, req dto.CreateTaskRequest, file io.Reader) (*dto.TaskResponse, error) {
+	// The error reports are written to the export bucket
+	if s.store == nil {
+		return nil, fmt.Errorf("tasks are not configured")
+	}
+
+	if err := req.Validate(file != nil); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	// An uploaded file is read before returning since the request body is
+	// gone once the handler returns
+	var data []byte
+	if file != nil {
+		var err error
+		if data, err = readTaskFile(file); err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+	}
+
+	t := req.ToTask(ctx)
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	// The task is processed in the background and the caller polls for the status.
+	// The request context is detached so that the job outlives the request,
+	// and the job works on its own copy of the task since the response is
+	// written while it runs.
+	job := *t
+	go s.runTask(context.WithoutCancel(ctx), &job, data)
+
+	return &dto.TaskResponse{Task: t}, nil
+}
+
+func (s *taskService) GetTask(ctx context.Context, id string) (*dto.TaskResponse, error) {
+	t, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	return &dto.TaskResponse{Task: t}, nil
+}
+
+func (s *taskService) ListTasks(ctx context.Context, filter types.Filter) (*dto.ListTasksResponse, error) {
+	tasks, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	response := &dto.ListTasksResponse{
+		Tasks: make([]dto.TaskResponse, len(tasks)),
+	}
+
+	for i, t := range tasks {
+		response.Tasks[i] = dto.TaskResponse{Task: t}
+	}
+
+	response.Total = len(tasks)
+	response.Offset = filter.Offset
+	response.Limit = filter.Limit
+
+	return response, nil
+}
+
+func (s *taskService) GetErrorReportURL(ctx context.Context, id string) (*dto.TaskErrorReportResponse, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("tasks are not configured")
+	}
+
+	t, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	if t.ErrorReportKey == "" {
+		return nil, fmt.Errorf("task has no error report, current status: %s", t.TaskStatus)
+	}
+
+	expiry := defaultExportURLExpiry
+	if s.cfg.URLExpiryMins > 0 {
+		expiry = time.Duration(s.cfg.URLExpiryMins) * time.Minute
+	}
+
+	url, err := s.store.PresignGetURL(ctx, t.ErrorReportKey, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get error report url: %w", err)
+	}
+
+	return &dto.TaskErrorReportResponse{
+		URL:       url,
+		ExpiresAt: time.Now().UTC().Add(expiry),
+	}, nil
+}
+
+func (s *taskService) runTask(ctx context.Context, t *task.Task, data []byte) {
+	s.setStatus(ctx, t, types.TaskStatusProcessing, nil)
+
+	if err := s.processTask(ctx, t, data); err != nil {
+		s.logger.Errorw("task failed", "task_id", t.ID, "error", err)
+		s.setStatus(ctx, t, types.TaskStatusFailed, err)
+		return
+	}
+
+	s.setStatus(ctx, t, types.TaskStatusCompleted, nil)
+}
+
+func (s *taskService) processTask(ctx context.Context, t *task.Task, data []byte) error {
+	if data == nil {
+		var err error
+		if data, err = s.downloadFile(ctx, t.FileURL); err != nil {
+			return err
+		}
+	}
+
+	switch t.Type {
+	case types.TaskTypeCustomerImport:
+		return s.importCustomers(ctx, t, data)
+	default:
+		return fmt.Errorf("unsupported task type: %s", t.Type)
+	}
+}
+
+func (s *taskService) setStatus(ctx context.Context, t *task.Task, status types.TaskStatus, cause error) {
+	setTaskStatus(ctx, s.repo, s.logger, t, status, cause)
+}
+
+func (s *taskService) updateTask(ctx context.Context, t *task.Task) {
+	updateTask(ctx, s.repo, s.logger, t)
+}
+
+// setTaskStatus moves the task to the given status, cause is the failure
+// reason of a failed task
+func setTaskStatus(ctx context.Context, repo task.Repository, log *logger.Logger, t *task.Task, status types.TaskStatus, cause error) {
+	t.TaskStatus = status
+	if cause != nil {
+		t.Error = cause.Error()
+	}
+	if status == types.TaskStatusCompleted || status == types.TaskStatusFailed {
+		now := time.Now().UTC()
+		t.CompletedAt = &now
+	}
+
+	updateTask(ctx, repo, log, t)
+}
+
+// updateTask saves the progress of a running task, a failure is only logged so
+// that the task goes on
+func updateTask(ctx context.Context, repo task.Repository, log *logger.Logger, t *task.Task) {
+	t.UpdatedAt = time.Now().UTC()
+	if err := repo.Update(ctx, t); err != nil {
+		log.Errorw("failed to update task", "task_id", t.ID, "status", t.TaskStatus, "error", err)
+	}
+}
+
+func (s *taskService) downloadFile(ctx context.Context, fileURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	return readTaskFile(resp.Body)
+}
+
+// readTaskFile reads the whole file, the rows of a task are kept in memory so
+// its size is capped
+func readTaskFile(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxTaskFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxTaskFileBytes {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxTaskFileBytes)
+	}
+	return data, nil
+}
+
+// taskRowError is a row of the error report of a task, ID identifies the
+// record of the row such as the external id of a customer
+type taskRowError struct {
+	Row   int
+	ID    string
+	Error string
+}
+
+// customerImportRow is a row of a customer import file. The columns of CSV
+// files are matched by their header and the unknown ones are ignored
+type customerImportRow struct {
+	ExternalID      string `json:"external_id"`
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	PaymentMethodID string `json:"payment_method_id"`
+
+	// ConsolidateInvoices is left unchanged on existing customers when it is
+	// not set
+	ConsolidateInvoices *bool `json:"consolidate_invoices"`
+
+	// row is the position of the row in the file, the first one is 1
+	row int
+
+	// err is set when the row could not be parsed
+	err error
+}
+
+// importCustomers creates the customers of the rows which do not exist yet and
+// updates the others, a row failing does not stop the import
+func (s *taskService) importCustomers(ctx context.Context, t *task.Task, data []byte) error {
+	var rows []*customerImportRow
+	var err error
+	switch t.FileFormat {
+	case types.TaskFileFormatCSV:
+		rows, err = parseCustomerImportCSV(data)
+	case types.TaskFileFormatJSON:
+		rows, err = parseCustomerImportJSON(data)
+	default:
+		err = fmt.Errorf("unsupported file format: %s", t.FileFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	t.TotalRows = len(rows)
+	s.updateTask(ctx, t)
+
+	var failures []taskRowError
+	for start := 0; start < len(rows); start += customerImportBatchSize {
+		end := min(start+customerImportBatchSize, len(rows))
+		failures = append(failures, s.importCustomerBatch(ctx, rows[start:end])...)
+
+		t.ProcessedRows = end
+		t.FailedRows = len(failures)
+		t.SuccessfulRows = end - len(failures)
+		s.updateTask(ctx, t)
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	return uploadTaskErrorReport(ctx, s.store, s.cfg.Prefix, t, "external_id", failures)
+}
+
+func (s *taskService) importCustomerBatch(ctx context.Context, rows []*customerImportRow) []taskRowError {
+	externalIDs := make([]string, 0, len(rows))
+	for _, r := range rows {
+		if r.err == nil && r.ExternalID != "" {
+			externalIDs = append(externalIDs, r.ExternalID)
+		}
+	}
+
+	existing := make(map[string]*customer.Customer, len(externalIDs))
+	customers, err := s.customerRepo.ListByExternalIDs(ctx, externalIDs)
+	if err != nil {
+		failures := make([]taskRowError, len(rows))
+		for i, r := range rows {
+			failures[i] = taskRowError{Row: r.row, ID: r.ExternalID, Error: err.Error()}
+		}
+		return failures
+	}
+	for _, c := range customers {
+		if _, ok := existing[c.ExternalID]; !ok {
+			existing[c.ExternalID] = c
+		}
+	}
+
+	var failures []taskRowError
+	for _, r := range rows {
+		if err := s.importCustomer(ctx, r, existing); err != nil {
+			failures = append(failures, taskRowError{Row: r.row, ID: r.ExternalID, Error: err.Error()})
+		}
+	}
+	return failures
+}
+
+// importCustomer upserts the customer of a row by external id, the customers
+// created are added to existing so that later rows with the same external id
+// update them
+func (s *taskService) importCustomer(ctx context.Context, r *customerImportRow, existing map[string]*customer.Customer) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	req := dto.CreateCustomerRequest{
+		ExternalID:      r.ExternalID,
+		Name:            r.Name,
+		Email:           r.Email,
+		PaymentMethodID: r.PaymentMethodID,
+	}
+	if r.ConsolidateInvoices != nil {
+		req.ConsolidateInvoices = *r.ConsolidateInvoices
+	}
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid row: %w", err)
+	}
+
+	c, ok := existing[r.ExternalID]
+	if !ok {
+		c = req.ToCustomer(ctx)
+		if err := s.customerRepo.Create(ctx, c); err != nil {
+			return fmt.Errorf("failed to create customer: %w", err)
+		}
+		existing[c.ExternalID] = c
+		return nil
+	}
+
+	if r.Name != "" {
+		c.Name = r.Name
+	}
+	if r.Email != "" {
+		c.Email = r.Email
+	}
+	if r.PaymentMethodID != "" {
+		c.PaymentMethodID = r.PaymentMethodID
+	}
+	if r.ConsolidateInvoices != nil {
+		c.ConsolidateInvoices = *r.ConsolidateInvoices
+	}
+	c.UpdatedAt = time.Now().UTC()
+	c.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.customerRepo.Update(ctx, c); err != nil {
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+	return nil
+}
+
+// uploadTaskErrorReport writes the CSV report of the failed rows of a task to
+// the bucket, idColumn is the header of the IDs of the rows
+func uploadTaskErrorReport(ctx context.Context, store storage.Store, prefix string, t *task.Task, idColumn string, failures []taskRowError) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"row", idColumn, "error"}); err != nil {
+		return fmt.Errorf("failed to write error report: %w", err)
+	}
+	for _, f := range failures {
+		if err := w.Write([]string{strconv.Itoa(f.Row), f.ID, f.Error}); err != nil {
+			return fmt.Errorf("failed to write error report: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write error report: %w", err)
+	}
+
+	key := path.Join(prefix, t.TenantID, "tasks", fmt.Sprintf("%s_errors.csv", t.ID))
+	if err := store.Upload(ctx, key, &buf, "text/csv"); err != nil {
+		return fmt.Errorf("failed to upload error report: %w", err)
+	}
+
+	t.ErrorReportKey = key
+	return nil
+}
+
+func parseCustomerImportCSV(data []byte) ([]*customerImportRow, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["external_id"]; !ok {
+		return nil, fmt.Errorf("csv header has no external_id column")
+	}
+
+	var rows []*customerImportRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+
+		value := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row := &customerImportRow{
+			ExternalID:      value("external_id"),
+			Name:            value("name"),
+			Email:           value("email"),
+			PaymentMethodID: value("payment_method_id"),
+			row:             len(rows) + 1,
+		}
+		if v := value("consolidate_invoices"); v != "" {
+			consolidate, err := strconv.ParseBool(v)
+			if err != nil {
+				row.err = fmt.Errorf("invalid consolidate_invoices: %s", v)
+			}
+			row.ConsolidateInvoices = &consolidate
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseCustomerImportJSON(data []byte) ([]*customerImportRow, error) {
+	var records []json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("file must be a json array of customers: %w", err)
+	}
+
+	rows := make([]*customerImportRow, len(records))
+	for i, record := range records {
+		row := &customerImportRow{}
+		if err := json.Unmarshal(record, row); err != nil {
+			row = &customerImportRow{err: fmt.Errorf("invalid row: %w", err)}
+		}
+		row.ExternalID = strings.TrimSpace(row.ExternalID)
+		row.row = i + 1
+		rows[i] = row
+	}
+
+	return rows, nil
+}
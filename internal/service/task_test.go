@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskService_CustomerImport(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_1",
+		ExternalID: "ext_1",
+		Name:       "Old Name",
+		Email:      "old@example.com",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	objectStore := testutil.NewInMemoryObjectStore()
+	svc := NewTaskService(testutil.NewInMemoryTaskStore(), customerStore, objectStore,
+		&config.Configuration{Export: config.ExportConfig{Prefix: "exports"}}, logger.GetLogger())
+
+	waitForTask := func(id string) *dto.TaskResponse {
+		var resp *dto.TaskResponse
+		require.Eventually(t, func() bool {
+			var err error
+			resp, err = svc.GetTask(ctx, id)
+			require.NoError(t, err)
+			return resp.TaskStatus == types.TaskStatusCompleted || resp.TaskStatus == types.TaskStatusFailed
+		}, 5*time.Second, 10*time.Millisecond)
+		return resp
+	}
+
+	customersByExternalID := func() map[string]*customer.Customer {
+		customers, err := customerStore.List(ctx, types.Filter{Limit: 100})
+		require.NoError(t, err)
+
+		byExternalID := make(map[string]*customer.Customer, len(customers))
+		for _, c := range customers {
+			byExternalID[c.ExternalID] = c
+		}
+		return byExternalID
+	}
+
+	_, err := svc.CreateTask(ctx, dto.CreateTaskRequest{
+		Type:       types.TaskTypeCustomerImport,
+		FileFormat: types.TaskFileFormatCSV,
+	}, nil)
+	assert.Error(t, err, "neither a file nor a file url")
+
+	file := strings.Join([]string{
+		"external_id,name,email,consolidate_invoices",
+		"ext_1,New Name,,",
+		"ext_2,Second,second@example.com,true",
+		",No External ID,,",
+		"ext_3,Third,,maybe",
+		"ext_2,,updated@example.com,",
+	}, "\n")
+
+	created, err := svc.CreateTask(ctx, dto.CreateTaskRequest{
+		Type:       types.TaskTypeCustomerImport,
+		FileFormat: types.TaskFileFormatCSV,
+	}, strings.NewReader(file))
+	require.NoError(t, err)
+	assert.Equal(t, types.TaskStatusPending, created.TaskStatus)
+
+	task := waitForTask(created.ID)
+	require.Equal(t, types.TaskStatusCompleted, task.TaskStatus, task.Error)
+	assert.Equal(t, 5, task.TotalRows)
+	assert.Equal(t, 5, task.ProcessedRows)
+	assert.Equal(t, 3, task.SuccessfulRows)
+	assert.Equal(t, 2, task.FailedRows)
+
+	customers := customersByExternalID()
+	require.Len(t, customers, 2)
+
+	// Empty columns keep the existing values
+	assert.Equal(t, "cust_1", customers["ext_1"].ID)
+	assert.Equal(t, "New Name", customers["ext_1"].Name)
+	assert.Equal(t, "old@example.com", customers["ext_1"].Email)
+
+	// A later row updates the customer created by an earlier one
+	assert.Equal(t, "Second", customers["ext_2"].Name)
+	assert.Equal(t, "updated@example.com", customers["ext_2"].Email)
+	assert.True(t, customers["ext_2"].ConsolidateInvoices)
+
+	report, err := svc.GetErrorReportURL(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "memory://"+task.ErrorReportKey, report.URL)
+
+	content, ok := objectStore.Object(task.ErrorReportKey)
+	require.True(t, ok)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "row,external_id,error", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "3,,invalid row:"), lines[1])
+	assert.Equal(t, "4,ext_3,invalid consolidate_invoices: maybe", lines[2])
+
+	// JSON files are downloaded from the file url
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"external_id": "ext_4", "name": "Fourth"}, {"external_id": 5}]`))
+	}))
+	defer server.Close()
+
+	created, err = svc.CreateTask(ctx, dto.CreateTaskRequest{
+		Type:       types.TaskTypeCustomerImport,
+		FileFormat: types.TaskFileFormatJSON,
+		FileURL:    server.URL + "/customers.json",
+	}, nil)
+	require.NoError(t, err)
+
+	task = waitForTask(created.ID)
+	require.Equal(t, types.TaskStatusCompleted, task.TaskStatus, task.Error)
+	assert.Equal(t, 1, task.SuccessfulRows)
+	assert.Equal(t, 1, task.FailedRows)
+	assert.Equal(t, "Fourth", customersByExternalID()["ext_4"].Name)
+
+	// A file which cannot be parsed fails the whole task
+	created, err = svc.CreateTask(ctx, dto.CreateTaskRequest{
+		Type:       types.TaskTypeCustomerImport,
+		FileFormat: types.TaskFileFormatJSON,
+	}, strings.NewReader(`{"external_id": "ext_5"}`))
+	require.NoError(t, err)
+
+	task = waitForTask(created.ID)
+	assert.Equal(t, types.TaskStatusFailed, task.TaskStatus)
+	assert.NotEmpty(t, task.Error)
+
+	_, err = svc.GetErrorReportURL(context.WithValue(ctx, types.CtxTenantID, "other_tenant"), task.ID)
+	assert.Error(t, err)
+}
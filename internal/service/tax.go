@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/tax"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// ErrNoTaxConnection is returned when the tenant has no Avalara or TaxJar connection
+var ErrNoTaxConnection = errors.New("no tax connection")
+
+// InvoiceTax is the tax of a line item of an invoice
+type InvoiceTax struct {
+	LineItemID  string
+	DisplayName string
+	Amount      decimal.Decimal
+	RatePercent decimal.Decimal
+	Source      types.TaxSource
+}
+
+type TaxService interface {
+	// QuoteInvoice calculates the taxes of the charges of a draft invoice with
+	// the tax connection of the tenant, at the fallback rates of the connection
+	// when its provider is unavailable. Invoices are not taxed when the tenant
+	// has no tax connection, when they have manual taxes or when their customer
+	// has no billing address
+	QuoteInvoice(ctx context.Context, inv *invoice.Invoice) ([]InvoiceTax, error)
+
+	// CommitInvoice queues the commit of the tax transaction of a finalized
+	// invoice at the tax provider, with the taxes it was invoiced with
+	CommitInvoice(ctx context.Context, inv *invoice.Invoice) error
+
+	// VoidInvoice queues the void of the tax transaction of a voided invoice
+	VoidInvoice(ctx context.Context, inv *invoice.Invoice) error
+
+	// ValidateCustomerAddress resolves the billing address of the customer with
+	// the tax provider, and saves the resolved address when asked to
+	ValidateCustomerAddress(ctx context.Context, customerID string, req dto.ValidateAddressRequest) (*dto.ValidateAddressResponse, error)
+}
+
+type taxService struct {
+	connectionRepo    connection.Repository
+	customerRepo      customer.Repository
+	connectionService ConnectionService
+	auditPublisher    audit.Publisher
+	logger            *logger.Logger
+
+	// newClient is replaced in tests
+	newClient func(conn *connection.Connection) (tax.Client, error)
+}
+
+func NewTaxService(
+	connectionRepo connection.Repository,
+	customerRepo customer.Repository,
+	connectionService ConnectionService,
+	auditPublisher audit.Publisher,
+	logger *logger.Logger,
+) TaxService {
+	return &taxService{
+		connectionRepo:    connectionRepo,
+		customerRepo:      customerRepo,
+		connectionService: connectionService,
+		auditPublisher:    auditPublisher,
+		logger:            logger,
+		newClient:         tax.NewClient,
+	}
+}
+
+func (s *taxService) QuoteInvoice(ctx context.Context, inv *invoice.Invoice) ([]InvoiceTax, error) {
+	conn, doc, err := s.document(ctx, inv)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+
+	charged := decimal.Zero
+	for _, line := range doc.Lines {
+		charged = charged.Add(line.Amount)
+	}
+	if !charged.IsPositive() {
+		return nil, nil
+	}
+
+	client, err := s.newClient(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes, err := client.Quote(ctx, doc)
+	if tax.IsUnavailable(err) {
+		s.logger.Warnw("tax provider is unavailable, taxing the invoice at the fallback rates",
+			"invoice_id", inv.ID,
+			"connection_id", conn.ID,
+			"error", err,
+		)
+		return fallbackTaxes(*conn.TaxSettings, doc, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote taxes: %w", err)
+	}
+
+	descriptions := make(map[string]string, len(doc.Lines))
+	for _, line := range doc.Lines {
+		descriptions[line.ID] = line.Description
+	}
+
+	precision := types.GetCurrencyPrecision(doc.Currency)
+	taxes := make([]InvoiceTax, 0, len(quotes))
+	for _, quote := range quotes {
+		amount := quote.Amount.Round(precision)
+		if amount.IsZero() {
+			continue
+		}
+		taxes = append(taxes, InvoiceTax{
+			LineItemID:  quote.LineID,
+			DisplayName: "Tax on " + descriptions[quote.LineID],
+			Amount:      amount,
+			RatePercent: quote.RatePercent,
+			Source:      types.TaxSourceProvider,
+		})
+	}
+	return taxes, nil
+}
+
+// fallbackTaxes taxes the lines at the fallback rate of the address of the
+// customer, the provider being unavailable. Without one the invoice can not be
+// taxed and the unavailability is returned
+func fallbackTaxes(settings connection.TaxSettings, doc *tax.Document, unavailable error) ([]InvoiceTax, error) {
+	rate := settings.FallbackRate(&doc.ShipTo)
+	if rate == nil {
+		return nil, fmt.Errorf("no fallback tax rate for %s: %w", doc.ShipTo.Country, unavailable)
+	}
+
+	name := rate.DisplayName
+	if name == "" {
+		name = "Tax"
+	}
+
+	precision := types.GetCurrencyPrecision(doc.Currency)
+	hundred := decimal.NewFromInt(100)
+	var taxes []InvoiceTax
+	for _, line := range doc.Lines {
+		amount := line.Amount.Mul(rate.RatePercent).Div(hundred).Round(precision)
+		if amount.IsZero() {
+			continue
+		}
+		taxes = append(taxes, InvoiceTax{
+			LineItemID:  line.ID,
+			DisplayName: fmt.Sprintf("%s on %s", name, line.Description),
+			Amount:      amount,
+			RatePercent: rate.RatePercent,
+			Source:      types.TaxSourceFallback,
+		})
+	}
+	return taxes, nil
+}
+
+func (s *taxService) CommitInvoice(ctx context.Context, inv *invoice.Invoice) error {
+	if inv.InvoiceStatus != types.InvoiceStatusFinalized {
+		return fmt.Errorf("invoice is not finalized")
+	}
+
+	return s.enqueue(ctx, inv, func(ctx context.Context, client tax.Client, doc *tax.Document) error {
+		return client.Commit(ctx, doc)
+	})
+}
+
+func (s *taxService) VoidInvoice(ctx context.Context, inv *invoice.Invoice) error {
+	if inv.InvoiceStatus != types.InvoiceStatusVoided {
+		return fmt.Errorf("invoice is not voided")
+	}
+
+	return s.enqueue(ctx, inv, func(ctx context.Context, client tax.Client, doc *tax.Document) error {
+		return client.Void(ctx, doc.Code)
+	})
+}
+
+// enqueue queues a call to the tax provider with the tax transaction of the
+// invoice on the sync queue of the tax connection, which retries it
+func (s *taxService) enqueue(ctx context.Context, inv *invoice.Invoice, call func(ctx context.Context, client tax.Client, doc *tax.Document) error) error {
+	conn, doc, err := s.document(ctx, inv)
+	if err != nil || doc == nil {
+		return err
+	}
+
+	client, err := s.newClient(conn)
+	if err != nil {
+		return err
+	}
+
+	return s.connectionService.EnqueueSync(ctx, conn.ID, &syncqueue.Task{
+		EntityType: types.SyncEntityTypeTaxTransaction,
+		EntityID:   inv.ID,
+		Run: func(ctx context.Context) error {
+			return call(ctx, client, doc)
+		},
+		OnFailure: func(err error) {
+			s.logger.Errorw("failed to sync tax transaction",
+				"invoice_id", inv.ID,
+				"connection_id", conn.ID,
+				"error", err,
+			)
+		},
+	})
+}
+
+// document returns the tax connection of the tenant and the tax transaction of
+// the invoice, nil when the invoice is not taxed through the connection
+func (s *taxService) document(ctx context.Context, inv *invoice.Invoice) (*connection.Connection, *tax.Document, error) {
+	if inv.InvoiceType == types.InvoiceTypeCredit {
+		return nil, nil, nil
+	}
+	for _, item := range inv.LineItems {
+		if item.TaxSource() == types.TaxSourceManual {
+			return nil, nil, nil
+		}
+	}
+
+	conn, err := s.taxConnection(ctx)
+	if errors.Is(err, ErrNoTaxConnection) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cust, err := s.customerRepo.Get(ctx, inv.CustomerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if cust.BillingAddress == nil {
+		s.logger.Warnw("customer has no billing address, the invoice is not taxed",
+			"invoice_id", inv.ID,
+			"customer_id", cust.ID,
+		)
+		return nil, nil, nil
+	}
+
+	doc, err := tax.BuildDocument(inv, cust, *conn.TaxSettings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, doc, nil
+}
+
+// taxConnection returns the Avalara or TaxJar connection of the tenant, the
+// oldest when there are several
+func (s *taxService) taxConnection(ctx context.Context) (*connection.Connection, error) {
+	conns, err := s.connectionRepo.ListByProviders(ctx, types.TaxProviders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tax connections: %w", err)
+	}
+
+	var oldest *connection.Connection
+	for _, conn := range conns {
+		if conn.TaxSettings == nil {
+			continue
+		}
+		if oldest == nil || conn.CreatedAt.Before(oldest.CreatedAt) {
+			oldest = conn
+		}
+	}
+	if oldest == nil {
+		return nil, ErrNoTaxConnection
+	}
+	return oldest, nil
+}
+
+func (s *taxService) ValidateCustomerAddress(ctx context.Context, customerID string, req dto.ValidateAddressRequest) (*dto.ValidateAddressResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	address := req.Address
+	if address == nil {
+		address = cust.BillingAddress
+	}
+	if address == nil {
+		return nil, fmt.Errorf("invalid request: customer %s has no billing address", cust.ID)
+	}
+
+	conn, err := s.taxConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.newClient(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := client.ValidateAddress(ctx, *address)
+	if errors.Is(err, tax.ErrInvalidAddress) {
+		return &dto.ValidateAddressResponse{Valid: false, Message: err.Error()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate address: %w", err)
+	}
+
+	resp := &dto.ValidateAddressResponse{Valid: true, Address: resolved}
+	if !req.Update {
+		return resp, nil
+	}
+
+	before := *cust
+	cust.BillingAddress = resolved
+	cust.UpdatedAt = time.Now().UTC()
+	cust.UpdatedBy = types.GetUserID(ctx)
+	if err := s.customerRepo.Update(ctx, cust); err != nil {
+		return nil, fmt.Errorf("failed to update customer: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeCustomer, cust.ID, types.AuditActionUpdate, &before, cust)
+	resp.Updated = true
+	return resp, nil
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/tax"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTaxClient taxes every line at 10% and records the commits and voids
+type fakeTaxClient struct {
+	mu       sync.Mutex
+	quoteErr error
+	commits  []*tax.Document
+	voids    []string
+
+	// tx tells whether a quote is requested while a transaction is open
+	tx         *trackedTxManager
+	quotedInTx atomic.Bool
+}
+
+// trackedTxManager runs the function directly like the InMemoryTxManager and
+// tracks whether a transaction is open
+type trackedTxManager struct {
+	open atomic.Bool
+}
+
+func (m *trackedTxManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.open.Store(true)
+	defer m.open.Store(false)
+	return fn(ctx)
+}
+
+func (c *fakeTaxClient) ValidateAddress(ctx context.Context, address customer.Address) (*customer.Address, error) {
+	if address.Line1 == "nowhere" {
+		return nil, tax.ErrInvalidAddress
+	}
+	address.PostalCode = address.PostalCode + "-0110"
+	return &address, nil
+}
+
+func (c *fakeTaxClient) Quote(ctx context.Context, doc *tax.Document) ([]tax.LineTax, error) {
+	if c.tx != nil && c.tx.open.Load() {
+		c.quotedInTx.Store(true)
+	}
+	if c.quoteErr != nil {
+		return nil, c.quoteErr
+	}
+	var taxes []tax.LineTax
+	for _, line := range doc.Lines {
+		taxes = append(taxes, tax.LineTax{
+			LineID:      line.ID,
+			Amount:      line.Amount.Div(decimal.NewFromInt(10)),
+			RatePercent: decimal.NewFromInt(10),
+		})
+	}
+	return taxes, nil
+}
+
+func (c *fakeTaxClient) Commit(ctx context.Context, doc *tax.Document) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commits = append(c.commits, doc)
+	return nil
+}
+
+func (c *fakeTaxClient) Void(ctx context.Context, code string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.voids = append(c.voids, code)
+	return nil
+}
+
+func (c *fakeTaxClient) synced() ([]*tax.Document, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*tax.Document(nil), c.commits...), append([]string(nil), c.voids...)
+}
+
+func TestTaxService(t *testing.T) {
+	ctx := testutil.SetupContext()
+	manager := syncqueue.NewManager(&config.Configuration{
+		Integration: config.IntegrationConfig{SyncRatePerSecond: 100},
+	}, logger.GetLogger())
+	defer manager.Stop()
+
+	connectionStore := testutil.NewInMemoryConnectionStore()
+	connectionService := NewConnectionService(connectionStore, manager, nil, logger.GetLogger())
+	_, err := connectionService.CreateConnection(ctx, dto.CreateConnectionRequest{
+		Name:        "TaxJar",
+		Provider:    types.IntegrationProviderTaxJar,
+		Credentials: "token",
+		TaxSettings: &connection.TaxSettings{
+			ShipFrom: &customer.Address{Line1: "100 Market St", City: "San Francisco", State: "CA", Country: "US"},
+			FallbackRates: []connection.FallbackTaxRate{
+				{Country: "US", RatePercent: decimal.NewFromInt(5)},
+				{Country: "US", State: "NY", RatePercent: decimal.RequireFromString("8.875"), DisplayName: "NY sales tax"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:             "cust_1",
+		ExternalID:     "acme",
+		BillingAddress: &customer.Address{Line1: "350 5th Ave", City: "New York", State: "NY", PostalCode: "10118", Country: "US"},
+		BaseModel:      types.GetDefaultBaseModel(ctx),
+	}))
+
+	txManager := &trackedTxManager{}
+	fakeClient := &fakeTaxClient{tx: txManager}
+	taxService := NewTaxService(connectionStore, customerStore, connectionService, nil, logger.GetLogger()).(*taxService)
+	taxService.newClient = func(*connection.Connection) (tax.Client, error) { return fakeClient, nil }
+
+	invoiceStore := testutil.NewInMemoryInvoiceStore()
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	svc := NewInvoiceService(
+		invoiceStore, testutil.NewInMemorySequenceStore(), subscriptionStore,
+		planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, testutil.NewInMemoryWalletStore(), testutil.NewInMemoryWalletStore(),
+		testutil.NewInMemoryRateCardStore(), testutil.NewInMemoryPriceBookStore(),
+		nil, txManager,
+		webhook.NewPublisher(testutil.NewInMemoryWebhookStore(), logger.GetLogger()),
+		nil, nil, nil, taxService,
+		nil, nil,
+		NewSubscriptionReader(subscriptionStore, planStore, priceStore,
+			testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(), testutil.NewInMemoryMeterStore(), customerStore,
+			nil, logger.GetLogger(),
+		), nil,
+		&config.Configuration{}, logger.GetLogger(),
+	)
+
+	hundred := decimal.NewFromInt(100)
+	fifty := decimal.NewFromInt(50)
+	oneOff := func(taxes ...dto.InvoiceTaxRequest) dto.CreateOneOffInvoiceRequest {
+		return dto.CreateOneOffInvoiceRequest{
+			CustomerID: "cust_1",
+			Currency:   "usd",
+			LineItems: []dto.OneOffInvoiceLineItemRequest{
+				{DisplayName: "Onboarding", UnitAmount: &hundred},
+				{DisplayName: "Training", UnitAmount: &fifty},
+			},
+			Taxes: taxes,
+		}
+	}
+
+	t.Run("charges are taxed by the provider", func(t *testing.T) {
+		draft, err := svc.CreateOneOffInvoice(ctx, oneOff())
+		require.NoError(t, err)
+		require.Len(t, draft.LineItems, 4)
+
+		taxLine := draft.LineItems[2]
+		assert.Equal(t, "Tax on Onboarding", taxLine.DisplayName)
+		assert.True(t, decimal.NewFromInt(10).Equal(taxLine.Amount))
+		assert.Equal(t, types.TaxSourceProvider, taxLine.TaxSource())
+		assert.Equal(t, draft.LineItems[0].ID, taxLine.Metadata[invoice.MetadataTaxedLineItemID])
+		assert.True(t, decimal.NewFromInt(165).Equal(draft.Total))
+
+		// Edits are taxed again, the quoted taxes can not be edited
+		resp, err := svc.AddLineItem(ctx, draft.ID, dto.AddInvoiceLineItemRequest{DisplayName: "Discount", Amount: decimal.NewFromInt(-50)})
+		require.NoError(t, err)
+		require.Len(t, resp.LineItems, 6)
+		assert.True(t, decimal.NewFromInt(110).Equal(resp.Total))
+
+		requoted := resp.LineItems[len(resp.LineItems)-1]
+		assert.Equal(t, "Tax on Discount", requoted.DisplayName)
+		assert.True(t, decimal.NewFromInt(-5).Equal(requoted.Amount))
+
+		_, err = svc.UpdateLineItem(ctx, draft.ID, requoted.ID, dto.UpdateInvoiceLineItemRequest{Amount: &hundred})
+		assert.ErrorIs(t, err, ErrTaxLineItemNotEditable)
+	})
+
+	t.Run("provider down falls back to the internal rates", func(t *testing.T) {
+		fakeClient.quoteErr = fmt.Errorf("%w: taxjar responded with status 503", tax.ErrUnavailable)
+		defer func() { fakeClient.quoteErr = nil }()
+
+		draft, err := svc.CreateOneOffInvoice(ctx, oneOff())
+		require.NoError(t, err)
+		require.Len(t, draft.LineItems, 4)
+
+		taxLine := draft.LineItems[2]
+		assert.Equal(t, "NY sales tax on Onboarding", taxLine.DisplayName)
+		assert.True(t, decimal.RequireFromString("8.88").Equal(taxLine.Amount))
+		assert.Equal(t, types.TaxSourceFallback, taxLine.TaxSource())
+		assert.Equal(t, "8.875", taxLine.Metadata[invoice.MetadataTaxRatePercent])
+
+		// A rejected quote is not taxed at the fallback rates
+		fakeClient.quoteErr = fmt.Errorf("taxjar responded with status 400")
+		_, err = svc.CreateOneOffInvoice(ctx, oneOff())
+		assert.Error(t, err)
+	})
+
+	t.Run("finalized invoices are committed and voided", func(t *testing.T) {
+		req := oneOff()
+		req.Finalize = true
+		finalized, err := svc.CreateOneOffInvoice(ctx, req)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			commits, _ := fakeClient.synced()
+			return len(commits) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		commits, _ := fakeClient.synced()
+		assert.Equal(t, finalized.ID, commits[0].Code)
+		require.Len(t, commits[0].Lines, 2)
+		assert.True(t, decimal.NewFromInt(10).Equal(commits[0].Lines[0].Tax))
+		assert.True(t, decimal.NewFromInt(5).Equal(commits[0].Lines[1].Tax))
+
+		voided, err := svc.VoidInvoice(ctx, finalized.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.InvoiceStatusVoided, voided.InvoiceStatus)
+
+		require.Eventually(t, func() bool {
+			_, voids := fakeClient.synced()
+			return len(voids) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		_, voids := fakeClient.synced()
+		assert.Equal(t, finalized.ID, voids[0])
+
+		_, err = svc.VoidInvoice(ctx, finalized.ID)
+		assert.ErrorIs(t, err, ErrInvoiceNotVoidable)
+	})
+
+	t.Run("paid invoices can not be voided", func(t *testing.T) {
+		req := oneOff()
+		req.Finalize = true
+		finalized, err := svc.CreateOneOffInvoice(ctx, req)
+		require.NoError(t, err)
+
+		_, err = svc.RecordPayment(ctx, finalized.ID, dto.RecordInvoicePaymentRequest{Amount: decimal.NewFromInt(10)})
+		require.NoError(t, err)
+
+		_, err = svc.VoidInvoice(ctx, finalized.ID)
+		assert.ErrorIs(t, err, ErrInvoiceNotVoidable)
+	})
+
+	t.Run("manual taxes are not quoted", func(t *testing.T) {
+		draft, err := svc.CreateOneOffInvoice(ctx, oneOff(dto.InvoiceTaxRequest{DisplayName: "VAT", RatePercent: decimal.NewFromInt(20)}))
+		require.NoError(t, err)
+		require.Len(t, draft.LineItems, 3)
+		assert.Equal(t, types.TaxSourceManual, draft.LineItems[2].TaxSource())
+		assert.True(t, decimal.NewFromInt(180).Equal(draft.Total))
+	})
+
+	t.Run("upcoming invoice is taxed as issued", func(t *testing.T) {
+		require.NoError(t, planStore.Create(ctx, &plan.Plan{
+			ID:        "plan_1",
+			Name:      "Pro",
+			BaseModel: types.GetDefaultBaseModel(ctx),
+		}))
+		require.NoError(t, priceStore.Create(ctx, &price.Price{
+			ID:                 "price_fixed",
+			PlanID:             "plan_1",
+			Type:               types.PRICE_TYPE_FIXED,
+			Amount:             hundred,
+			Currency:           "usd",
+			BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+			BillingPeriodCount: 1,
+			BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+			BillingCadence:     types.BILLING_CADENCE_RECURRING,
+			Description:        "Platform fee",
+			BaseModel:          types.GetDefaultBaseModel(ctx),
+		}))
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+			ID:                 "sub_1",
+			PlanID:             "plan_1",
+			CustomerID:         "cust_1",
+			StartDate:          start,
+			CurrentPeriodStart: start,
+			CurrentPeriodEnd:   start.AddDate(0, 1, 0),
+			Currency:           "usd",
+			BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+			BillingPeriodCount: 1,
+			BaseModel:          types.GetDefaultBaseModel(ctx),
+		}))
+
+		fakeClient.quotedInTx.Store(false)
+		upcoming, err := svc.GetUpcomingInvoice(ctx, "sub_1", nil)
+		require.NoError(t, err)
+		issued, err := svc.CreateSubscriptionInvoice(ctx, "sub_1", dto.CreateSubscriptionInvoiceRequest{})
+		require.NoError(t, err)
+
+		assert.True(t, decimal.NewFromInt(110).Equal(issued.Total))
+		assert.True(t, issued.Total.Equal(upcoming.Total))
+		assert.True(t, issued.AmountDue.Equal(upcoming.AmountOutOfPocket))
+
+		// The taxes are quoted before the transaction issuing the invoice opens
+		assert.False(t, fakeClient.quotedInTx.Load())
+	})
+
+	t.Run("address validation", func(t *testing.T) {
+		resp, err := taxService.ValidateCustomerAddress(ctx, "cust_1", dto.ValidateAddressRequest{Update: true})
+		require.NoError(t, err)
+		assert.True(t, resp.Valid)
+		assert.True(t, resp.Updated)
+
+		cust, err := customerStore.Get(ctx, "cust_1")
+		require.NoError(t, err)
+		assert.Equal(t, "10118-0110", cust.BillingAddress.PostalCode)
+
+		resp, err = taxService.ValidateCustomerAddress(ctx, "cust_1", dto.ValidateAddressRequest{
+			Address: &customer.Address{Line1: "nowhere", City: "Nowhere", Country: "US"},
+			Update:  true,
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.Valid)
+		assert.False(t, resp.Updated)
+	})
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/postgres"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+)
+
+// TrialService runs the trial lifecycle of subscriptions from the billing cron
+type TrialService interface {
+	// ProcessTrials sends the trial_will_end webhook and email of the trials ending soon and
+	// converts or cancels the trials that ended at now
+	ProcessTrials(ctx context.Context, now time.Time) error
+}
+
+// TrialEndedEvent is the payload of the trial_ended webhook
+type TrialEndedEvent struct {
+	Subscription *subscription.Subscription `json:"subscription"`
+	Outcome      types.TrialOutcome         `json:"outcome"`
+}
+
+type trialService struct {
+	cfg              config.BillingConfig
+	subscriptionRepo subscription.Repository
+	customerRepo     customer.Repository
+	db               postgres.TxManager
+	webhookPublisher webhook.Publisher
+	emailService     EmailService
+	logger           *logger.Logger
+}
+
+func NewTrialService(
+	cfg *config.Configuration,
+	subscriptionRepo subscription.Repository,
+	customerRepo customer.Repository,
+	db postgres.TxManager,
+	webhookPublisher webhook.Publisher,
+	emailService EmailService,
+	logger *logger.Logger,
+) TrialService {
+	billingCfg := cfg.Billing
+	if billingCfg.TrialWillEndDays <= 0 {
+		billingCfg.TrialWillEndDays = 3
+	}
+
+	return &trialService{
+		cfg:              billingCfg,
+		subscriptionRepo: subscriptionRepo,
+		customerRepo:     customerRepo,
+		db:               db,
+		webhookPublisher: webhookPublisher,
+		emailService:     emailService,
+		logger:           logger,
+	}
+}
+
+func (s *trialService) ProcessTrials(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	notifyBefore := now.AddDate(0, 0, s.cfg.TrialWillEndDays)
+
+	subs, err := s.subscriptionRepo.ListTrialsEndingBefore(ctx, notifyBefore)
+	if err != nil {
+		return fmt.Errorf("failed to list trials: %w", err)
+	}
+
+	forEachJobItem(ctx, subs, func(sub *subscription.Subscription) {
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, sub.TenantID)
+		tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+		// One failing subscription must not hold back the others
+		var err error
+		if sub.TrialEnd.After(now) {
+			if sub.TrialWillEndSentAt != nil {
+				return
+			}
+			err = s.notifyTrialWillEnd(tenantCtx, sub, now)
+		} else {
+			err = s.endTrial(tenantCtx, sub, now)
+		}
+
+		if err != nil {
+			s.logger.Errorw("failed to process trial",
+				"tenant_id", sub.TenantID,
+				"subscription_id", sub.ID,
+				"error", err,
+			)
+		}
+	})
+
+	return nil
+}
+
+func (s *trialService) notifyTrialWillEnd(ctx context.Context, sub *subscription.Subscription, now time.Time) error {
+	return s.db.WithTx(ctx, func(ctx context.Context) error {
+		sub.TrialWillEndSentAt = &now
+		sub.UpdatedAt = now
+		sub.UpdatedBy = types.GetUserID(ctx)
+
+		if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventTrialWillEnd, sub); err != nil {
+			return fmt.Errorf("failed to publish trial will end webhook: %w", err)
+		}
+
+		if s.emailService != nil {
+			if err := s.emailService.QueueTrialWillEndEmail(ctx, sub); err != nil {
+				return fmt.Errorf("failed to queue trial will end email: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// endTrial starts the first paid period of the subscription at the end of the
+// trial, or cancels it when the customer has no payment method to charge
+func (s *trialService) endTrial(ctx context.Context, sub *subscription.Subscription, now time.Time) error {
+	cust, err := s.customerRepo.Get(ctx, sub.CustomerID)
+	if err != nil {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	trialEnd := *sub.TrialEnd
+	outcome := types.TrialOutcomeConverted
+
+	if cust.PaymentMethodID == "" && sub.GatewayPaymentMethodID == "" {
+		outcome = types.TrialOutcomeCancelled
+		sub.SubscriptionStatus = types.SubscriptionStatusCancelled
+		sub.CancelledAt = &now
+		sub.EndDate = &trialEnd
+	} else {
+		periodEnd, err := periodEndFrom(sub, trialEnd)
+		if err != nil {
+			return fmt.Errorf("failed to calculate next billing date: %w", err)
+		}
+
+		sub.SubscriptionStatus = types.SubscriptionStatusActive
+		sub.CurrentPeriodStart = trialEnd
+		sub.CurrentPeriodEnd = periodEnd
+	}
+
+	sub.UpdatedAt = now
+	sub.UpdatedBy = types.GetUserID(ctx)
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventTrialEnded, &TrialEndedEvent{
+			Subscription: sub,
+			Outcome:      outcome,
+		}); err != nil {
+			return fmt.Errorf("failed to publish trial ended webhook: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infow("ended subscription trial",
+		"tenant_id", sub.TenantID,
+		"subscription_id", sub.ID,
+		"outcome", outcome,
+	)
+
+	return nil
+}
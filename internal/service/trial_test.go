@@ -0,0 +1,358 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/plan"
+	"github.com/flexprice/flexprice/internal/domain/price"
+	"github.com/flexprice/flexprice/internal/domain/subscription"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionService_CreateSubscription_Trial(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:          "plan_trial",
+		Name:        "Trial Plan",
+		TrialPeriod: 14,
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_fixed",
+		PlanID:             "plan_trial",
+		Type:               types.PRICE_TYPE_FIXED,
+		Amount:             decimal.NewFromInt(20),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:        "cust_123",
+		Timezone:  types.DefaultTimezone,
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	svc := NewSubscriptionService(
+		testutil.NewInMemorySubscriptionStore(), planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger.GetLogger(),
+	)
+
+	// Starts in the future keep the subscription in its first period, backdated
+	// ones are moved to the period containing now
+	start := time.Date(2124, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("plan trial period", func(t *testing.T) {
+		resp, err := svc.CreateSubscription(ctx, dto.CreateSubscriptionRequest{
+			CustomerID:    "cust_123",
+			PlanID:        "plan_trial",
+			StartDate:     start,
+			BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+		})
+		require.NoError(t, err)
+
+		trialEnd := start.AddDate(0, 0, 14)
+		assert.Equal(t, types.SubscriptionStatusTrialing, resp.SubscriptionStatus)
+		require.NotNil(t, resp.TrialStart)
+		require.NotNil(t, resp.TrialEnd)
+		assert.Equal(t, start, *resp.TrialStart)
+		assert.Equal(t, trialEnd, *resp.TrialEnd)
+		assert.Equal(t, start, resp.CurrentPeriodStart)
+		assert.Equal(t, trialEnd, resp.CurrentPeriodEnd)
+		assert.Equal(t, trialEnd, resp.BillingAnchor)
+	})
+
+	t.Run("trial end overrides plan", func(t *testing.T) {
+		trialEnd := start.AddDate(0, 0, 3)
+		resp, err := svc.CreateSubscription(ctx, dto.CreateSubscriptionRequest{
+			CustomerID:    "cust_123",
+			PlanID:        "plan_trial",
+			StartDate:     start,
+			BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+			TrialEnd:      &trialEnd,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, trialEnd, *resp.TrialEnd)
+	})
+
+	t.Run("trial end before start", func(t *testing.T) {
+		trialEnd := start.AddDate(0, 0, -1)
+		_, err := svc.CreateSubscription(ctx, dto.CreateSubscriptionRequest{
+			CustomerID:    "cust_123",
+			PlanID:        "plan_trial",
+			StartDate:     start,
+			BillingPeriod: types.BILLING_PERIOD_MONTHLY,
+			TrialEnd:      &trialEnd,
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestTrialService_ProcessTrials(t *testing.T) {
+	ctx := testutil.SetupContext()
+	now := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:              "cust_card",
+		ExternalID:      "ext_card",
+		PaymentMethodID: "pm_123",
+		BaseModel:       types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_no_card",
+		ExternalID: "ext_no_card",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	subscriptionStore := testutil.NewInMemorySubscriptionStore()
+	newTrial := func(id, customerID string, trialEnd time.Time) {
+		start := trialEnd.AddDate(0, 0, -14)
+		require.NoError(t, subscriptionStore.Create(ctx, &subscription.Subscription{
+			ID:                 id,
+			CustomerID:         customerID,
+			PlanID:             "plan_123",
+			SubscriptionStatus: types.SubscriptionStatusTrialing,
+			StartDate:          start,
+			BillingAnchor:      trialEnd,
+			CurrentPeriodStart: start,
+			CurrentPeriodEnd:   trialEnd,
+			TrialStart:         &start,
+			TrialEnd:           &trialEnd,
+			BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+			BillingPeriodCount: 1,
+			BaseModel:          types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	newTrial("sub_converts", "cust_card", now.Add(-time.Hour))
+	newTrial("sub_cancels", "cust_no_card", now.Add(-time.Hour))
+	newTrial("sub_ending_soon", "cust_card", now.AddDate(0, 0, 2))
+	newTrial("sub_ending_later", "cust_card", now.AddDate(0, 0, 10))
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:  "ep_1",
+		URL: "https://example.com/hooks",
+		EventTypes: []string{
+			string(types.WebhookEventTrialWillEnd),
+			string(types.WebhookEventTrialEnded),
+		},
+		Secret:    "whsec_test",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	cfg := &config.Configuration{Billing: config.BillingConfig{TrialWillEndDays: 3}}
+	svc := NewTrialService(cfg, subscriptionStore, customerStore, testutil.NewInMemoryTxManager(),
+		webhook.NewPublisher(webhookStore, logger.GetLogger()), nil, logger.GetLogger())
+
+	require.NoError(t, svc.ProcessTrials(ctx, now))
+
+	converted, err := subscriptionStore.Get(ctx, "sub_converts")
+	require.NoError(t, err)
+	assert.Equal(t, types.SubscriptionStatusActive, converted.SubscriptionStatus)
+	assert.Equal(t, *converted.TrialEnd, converted.CurrentPeriodStart)
+	assert.Equal(t, converted.TrialEnd.AddDate(0, 1, 0), converted.CurrentPeriodEnd)
+
+	cancelled, err := subscriptionStore.Get(ctx, "sub_cancels")
+	require.NoError(t, err)
+	assert.Equal(t, types.SubscriptionStatusCancelled, cancelled.SubscriptionStatus)
+	assert.NotNil(t, cancelled.CancelledAt)
+	require.NotNil(t, cancelled.EndDate)
+	assert.Equal(t, *cancelled.TrialEnd, *cancelled.EndDate)
+
+	endingSoon, err := subscriptionStore.Get(ctx, "sub_ending_soon")
+	require.NoError(t, err)
+	assert.Equal(t, types.SubscriptionStatusTrialing, endingSoon.SubscriptionStatus)
+	assert.NotNil(t, endingSoon.TrialWillEndSentAt)
+
+	endingLater, err := subscriptionStore.Get(ctx, "sub_ending_later")
+	require.NoError(t, err)
+	assert.Nil(t, endingLater.TrialWillEndSentAt)
+
+	countEvents := func() map[types.WebhookEventType]int {
+		deliveries, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+		require.NoError(t, err)
+
+		counts := make(map[types.WebhookEventType]int)
+		for _, d := range deliveries {
+			counts[d.EventType]++
+		}
+		return counts
+	}
+
+	assert.Equal(t, map[types.WebhookEventType]int{
+		types.WebhookEventTrialEnded:   2,
+		types.WebhookEventTrialWillEnd: 1,
+	}, countEvents())
+
+	// The next run neither notifies twice nor ends the trials again
+	require.NoError(t, svc.ProcessTrials(ctx, now.Add(15*time.Minute)))
+	assert.Equal(t, map[types.WebhookEventType]int{
+		types.WebhookEventTrialEnded:   2,
+		types.WebhookEventTrialWillEnd: 1,
+	}, countEvents())
+}
+
+func TestSubscriptionService_CreateSubscription_CalendarBilling(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	planStore := testutil.NewInMemoryPlanStore()
+	priceStore := testutil.NewInMemoryPriceStore()
+	require.NoError(t, planStore.Create(ctx, &plan.Plan{
+		ID:        "plan_calendar",
+		Name:      "Calendar Plan",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, priceStore.Create(ctx, &price.Price{
+		ID:                 "price_fixed",
+		PlanID:             "plan_calendar",
+		Type:               types.PRICE_TYPE_FIXED,
+		Amount:             decimal.NewFromInt(20),
+		Currency:           "usd",
+		BillingPeriod:      types.BILLING_PERIOD_MONTHLY,
+		BillingPeriodCount: 1,
+		BillingModel:       types.BILLING_MODEL_FLAT_FEE,
+		BillingCadence:     types.BILLING_CADENCE_RECURRING,
+		BaseModel:          types.GetDefaultBaseModel(ctx),
+	}))
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:        "cust_123",
+		Timezone:  types.DefaultTimezone,
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:        "cust_new_york",
+		Timezone:  "America/New_York",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	svc := NewSubscriptionService(
+		testutil.NewInMemorySubscriptionStore(), planStore, priceStore,
+		testutil.NewInMemoryMessageBroker(), testutil.NewInMemoryEventStore(),
+		testutil.NewInMemoryMeterStore(), customerStore, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger.GetLogger(),
+	)
+
+	tests := []struct {
+		name         string
+		customerID   string
+		cycle        types.BillingCycle
+		anchorDay    int
+		behavior     types.PartialPeriodBehavior
+		start        time.Time
+		wantEnd      time.Time
+		wantBehavior types.PartialPeriodBehavior
+		wantErr      bool
+	}{
+		{
+			name:    "anniversary",
+			start:   time.Date(2124, 1, 10, 0, 0, 0, 0, time.UTC),
+			wantEnd: time.Date(2124, 2, 10, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "calendar mid month defaults to prorate",
+			cycle:        types.BillingCycleCalendar,
+			start:        time.Date(2124, 1, 10, 0, 0, 0, 0, time.UTC),
+			wantEnd:      time.Date(2124, 2, 1, 0, 0, 0, 0, time.UTC),
+			wantBehavior: types.PartialPeriodBehaviorProrate,
+		},
+		{
+			name:         "calendar on the 1st has no partial period",
+			cycle:        types.BillingCycleCalendar,
+			behavior:     types.PartialPeriodBehaviorFree,
+			start:        time.Date(2124, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:      time.Date(2124, 2, 1, 0, 0, 0, 0, time.UTC),
+			wantBehavior: types.PartialPeriodBehaviorFree,
+		},
+		{
+			name:         "anchor day mid month defaults to prorate",
+			anchorDay:    15,
+			start:        time.Date(2124, 1, 10, 0, 0, 0, 0, time.UTC),
+			wantEnd:      time.Date(2124, 1, 15, 0, 0, 0, 0, time.UTC),
+			wantBehavior: types.PartialPeriodBehaviorProrate,
+		},
+		{
+			name:         "anchor day past the end of a short month",
+			anchorDay:    31,
+			start:        time.Date(2124, 2, 10, 0, 0, 0, 0, time.UTC),
+			wantEnd:      time.Date(2124, 2, 29, 0, 0, 0, 0, time.UTC),
+			wantBehavior: types.PartialPeriodBehaviorProrate,
+		},
+		{
+			name:      "anchor day cannot be combined with calendar billing",
+			cycle:     types.BillingCycleCalendar,
+			anchorDay: 15,
+			start:     time.Date(2124, 1, 10, 0, 0, 0, 0, time.UTC),
+			wantErr:   true,
+		},
+		{
+			name:         "calendar periods start at midnight in the customer timezone",
+			customerID:   "cust_new_york",
+			cycle:        types.BillingCycleCalendar,
+			start:        time.Date(2124, 1, 10, 0, 0, 0, 0, time.UTC),
+			wantEnd:      time.Date(2124, 2, 1, 0, 0, 0, 0, newYork),
+			wantBehavior: types.PartialPeriodBehaviorProrate,
+		},
+		{
+			name:       "anniversary periods keep the local time across daylight saving",
+			customerID: "cust_new_york",
+			start:      time.Date(2124, 3, 1, 9, 0, 0, 0, newYork),
+			wantEnd:    time.Date(2124, 4, 1, 9, 0, 0, 0, newYork),
+		},
+		{
+			name:     "partial period behavior needs calendar billing",
+			behavior: types.PartialPeriodBehaviorFree,
+			start:    time.Date(2124, 1, 10, 0, 0, 0, 0, time.UTC),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customerID := tt.customerID
+			if customerID == "" {
+				customerID = "cust_123"
+			}
+			resp, err := svc.CreateSubscription(ctx, dto.CreateSubscriptionRequest{
+				CustomerID:            customerID,
+				PlanID:                "plan_calendar",
+				StartDate:             tt.start,
+				BillingPeriod:         types.BILLING_PERIOD_MONTHLY,
+				BillingCycle:          tt.cycle,
+				BillingAnchorDay:      tt.anchorDay,
+				PartialPeriodBehavior: tt.behavior,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.start.Equal(resp.CurrentPeriodStart), "period start %s", resp.CurrentPeriodStart)
+			assert.True(t, tt.wantEnd.Equal(resp.CurrentPeriodEnd), "period end %s", resp.CurrentPeriodEnd)
+			assert.Equal(t, tt.wantBehavior, resp.PartialPeriodBehavior)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// rollupDay is the size of the daily rollup windows, days are in UTC
+const rollupDay = 24 * time.Hour
+
+type UsageRollupService interface {
+	// RollUpUsage rolls up the hourly and daily usage of every meter of every
+	// tenant with events in the lookback, until the late arrival allowance
+	// before now
+	RollUpUsage(ctx context.Context, now time.Time) error
+}
+
+type usageRollupService struct {
+	cfg        config.UsageRollupConfig
+	rollupRepo events.RollupRepository
+	eventRepo  events.Repository
+	meterRepo  meter.Repository
+	logger     *logger.Logger
+}
+
+func NewUsageRollupService(
+	cfg *config.Configuration,
+	rollupRepo events.RollupRepository,
+	eventRepo events.Repository,
+	meterRepo meter.Repository,
+	logger *logger.Logger,
+) UsageRollupService {
+	rollupCfg := cfg.UsageRollup
+	if rollupCfg.LateArrivalMins < 0 {
+		rollupCfg.LateArrivalMins = 0
+	}
+	if rollupCfg.LookbackHours <= 0 {
+		rollupCfg.LookbackHours = 48
+	}
+
+	return &usageRollupService{
+		cfg:        rollupCfg,
+		rollupRepo: rollupRepo,
+		eventRepo:  eventRepo,
+		meterRepo:  meterRepo,
+		logger:     logger,
+	}
+}
+
+func (s *usageRollupService) RollUpUsage(ctx context.Context, now time.Time) error {
+	lookback := time.Duration(s.cfg.LookbackHours) * time.Hour
+	until := now.UTC().Add(-time.Duration(s.cfg.LateArrivalMins) * time.Minute).Truncate(time.Hour)
+
+	tenantIDs, err := s.eventRepo.GetTenantIDs(ctx, now.Add(-lookback))
+	if err != nil {
+		return fmt.Errorf("failed to get tenants: %w", err)
+	}
+
+	for _, tenantID := range tenantIDs {
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, tenantID)
+		tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+		meters, err := s.meterRepo.GetAllMeters(tenantCtx)
+		if err != nil {
+			s.logger.Errorw("failed to list meters for usage rollup",
+				"tenant_id", tenantID,
+				"error", err,
+			)
+			continue
+		}
+
+		for _, m := range meters {
+			// Derived meters are read from the rollups of their meters
+			if m.IsDerived() {
+				continue
+			}
+
+			if err := s.rollUpMeter(tenantCtx, m, until, lookback); err != nil {
+				s.logger.Errorw("failed to roll up meter usage",
+					"tenant_id", tenantID,
+					"meter_id", m.ID,
+					"error", err,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rollUpMeter recomputes the windows of the meter from the lookback before its
+// watermark until the given time, or all of them when it was never rolled up,
+// and moves the watermark only once both window sizes are written
+func (s *usageRollupService) rollUpMeter(ctx context.Context, m *meter.Meter, until time.Time, lookback time.Duration) error {
+	watermark, err := s.rollupRepo.GetRollupWatermark(ctx, m.ID)
+	if err != nil {
+		return err
+	}
+
+	var from time.Time
+	if !watermark.IsZero() {
+		from = watermark.Add(-lookback).Truncate(time.Hour)
+		if from.After(until) {
+			from = until
+		}
+	}
+
+	versions, err := s.meterRepo.ListVersions(ctx, m.ID)
+	if err != nil {
+		return err
+	}
+
+	ranges := []events.RollupRange{
+		{WindowSize: types.WindowSizeHour, StartTime: from, EndTime: until},
+		{WindowSize: types.WindowSizeDay, StartTime: from.Truncate(rollupDay), EndTime: until.Truncate(rollupDay)},
+	}
+	for _, r := range ranges {
+		if err := rollUpWindows(ctx, s.rollupRepo, m, versions, r); err != nil {
+			return err
+		}
+	}
+
+	return s.rollupRepo.SetRollupWatermark(ctx, m.ID, until)
+}
+
+// rollUpAgain recomputes the rolled up hourly and daily windows overlapping
+// [start, end) of the meters of the given event names, so that they include
+// the events written to them after they were rolled up. Windows past the
+// watermark of a meter are left to the rollup job. Failures are only logged
+// as the rollup job recomputes the windows of its lookback on every run
+func rollUpAgain(ctx context.Context, rollupRepo events.RollupRepository, meterRepo meter.Repository, log *logger.Logger, eventNames map[string]bool, start, end time.Time) {
+	meters, err := meterRepo.GetAllMeters(ctx)
+	if err != nil {
+		log.Errorw("failed to list meters to roll up usage again", "error", err)
+		return
+	}
+
+	for _, m := range meters {
+		if !eventNames[m.EventName] {
+			continue
+		}
+
+		if err := rollUpMeterAgain(ctx, rollupRepo, meterRepo, m, start, end); err != nil {
+			log.Errorw("failed to roll up usage again", "meter_id", m.ID, "error", err)
+		}
+	}
+}
+
+// rollUpMeterAgain recomputes the rolled up hourly and daily windows of the
+// meter overlapping [start, end), up to its watermark
+func rollUpMeterAgain(ctx context.Context, rollupRepo events.RollupRepository, meterRepo meter.Repository, m *meter.Meter, start, end time.Time) error {
+	watermark, err := rollupRepo.GetRollupWatermark(ctx, m.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get rollup watermark: %w", err)
+	}
+
+	versions, err := meterRepo.ListVersions(ctx, m.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list meter versions: %w", err)
+	}
+
+	start, end = start.UTC(), end.UTC()
+	ranges := []events.RollupRange{
+		{WindowSize: types.WindowSizeHour, StartTime: start.Truncate(time.Hour), EndTime: minTime(ceilTime(end, time.Hour), watermark)},
+		{WindowSize: types.WindowSizeDay, StartTime: start.Truncate(rollupDay), EndTime: minTime(ceilTime(end, rollupDay), watermark.Truncate(rollupDay))},
+	}
+	for _, r := range ranges {
+		if err := rollUpWindows(ctx, rollupRepo, m, versions, r); err != nil {
+			return fmt.Errorf("failed to roll up %s windows from %s: %w", r.WindowSize, r.StartTime.Format(time.RFC3339), err)
+		}
+	}
+
+	return nil
+}
+
+// rollUpWindows rolls up the windows of the range, each with the field of the
+// version of the meter effective at its start. Percentiles do not merge across
+// windows, the usage of percentile meters is always read from the raw events
+func rollUpWindows(ctx context.Context, rollupRepo events.RollupRepository, m *meter.Meter, versions []*meter.MeterVersion, r events.RollupRange) error {
+	if !r.StartTime.Before(r.EndTime) || m.Aggregation.Type == types.AggregationPercentile {
+		return nil
+	}
+
+	size := time.Hour
+	if r.WindowSize == types.WindowSizeDay {
+		size = rollupDay
+	}
+
+	for _, segment := range meter.Segments(m, versions, r.StartTime, r.EndTime) {
+		start, end := ceilTime(segment.Start, size), ceilTime(segment.End, size)
+		if !start.Before(end) {
+			continue
+		}
+
+		if err := rollupRepo.RollUpUsage(ctx, &events.RollupParams{
+			MeterID:         m.ID,
+			EventName:       m.EventName,
+			PropertyName:    segment.Aggregation.Field,
+			WindowSize:      r.WindowSize,
+			StartTime:       start,
+			EndTime:         end,
+			AggregationType: segment.Aggregation.Type,
+			Precision:       segment.Aggregation.Precision,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollupRead splits a usage query of [start, end) into the rolled up windows
+// and the edges read from the raw events
+type rollupRead struct {
+	ranges []events.RollupRange
+	raw    []timeRange
+}
+
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// planRollupRead reads whole days from the daily rollups, the whole hours
+// around them from the hourly rollups and the rest from the raw events. A zero
+// end is unbounded. It returns nil when no whole hour before the watermark is
+// requested
+func planRollupRead(start, end, watermark time.Time) *rollupRead {
+	if watermark.IsZero() {
+		return nil
+	}
+
+	rolledEnd := watermark
+	if !end.IsZero() && end.Before(rolledEnd) {
+		rolledEnd = end
+	}
+
+	h0 := ceilTime(start, time.Hour)
+	h1 := rolledEnd.Truncate(time.Hour)
+	if !h0.Before(h1) {
+		return nil
+	}
+
+	read := &rollupRead{}
+	d0, d1 := ceilTime(h0, rollupDay), h1.Truncate(rollupDay)
+	if d0.Before(d1) {
+		read.ranges = append(read.ranges, events.RollupRange{WindowSize: types.WindowSizeDay, StartTime: d0, EndTime: d1})
+		if h0.Before(d0) {
+			read.ranges = append(read.ranges, events.RollupRange{WindowSize: types.WindowSizeHour, StartTime: h0, EndTime: d0})
+		}
+		if d1.Before(h1) {
+			read.ranges = append(read.ranges, events.RollupRange{WindowSize: types.WindowSizeHour, StartTime: d1, EndTime: h1})
+		}
+	} else {
+		read.ranges = append(read.ranges, events.RollupRange{WindowSize: types.WindowSizeHour, StartTime: h0, EndTime: h1})
+	}
+
+	if start.Before(h0) {
+		read.raw = append(read.raw, timeRange{start: start, end: h0})
+	}
+	if end.IsZero() || h1.Before(end) {
+		read.raw = append(read.raw, timeRange{start: h1, end: end})
+	}
+
+	return read
+}
+
+// ceilTime rounds t up to a multiple of d
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func ceilTime(t time.Time, d time.Duration) time.Time {
+	truncated := t.Truncate(d)
+	if truncated.Before(t) {
+		return truncated.Add(d)
+	}
+	return truncated
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/domain/meter"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageRollupService_RollUpUsage(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	meterStore := testutil.NewInMemoryMeterStore()
+	require.NoError(t, meterStore.CreateMeter(ctx, &meter.Meter{
+		ID:          "meter_tokens",
+		Name:        "Tokens",
+		EventName:   "completion",
+		Aggregation: meter.Aggregation{Type: types.AggregationSum, Field: "tokens"},
+		ResetUsage:  types.ResetUsageBillingPeriod,
+		BaseModel:   types.GetDefaultBaseModel(ctx),
+	}))
+
+	eventStore := testutil.NewInMemoryEventStore()
+	insert := func(id string, at time.Time, tokens float64) {
+		require.NoError(t, eventStore.InsertEvent(ctx, &events.Event{
+			ID:                 id,
+			TenantID:           types.GetTenantID(ctx),
+			EventName:          "completion",
+			ExternalCustomerID: "ext_cust_123",
+			Timestamp:          at,
+			Properties:         map[string]interface{}{"tokens": tokens},
+		}))
+	}
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, time.March, day, hour, min, 0, 0, time.UTC)
+	}
+
+	// Read from the hourly, daily, hourly rollups and the raw events
+	insert("e1", at(1, 10, 15), 5)
+	insert("e2", at(9, 23, 20), 7)
+	insert("e3", at(10, 9, 10), 11)
+	insert("e4", at(10, 11, 40), 13)
+
+	rollupStore := testutil.NewInMemoryRollupStore(eventStore)
+	cfg := &config.Configuration{UsageRollup: config.UsageRollupConfig{
+		Enabled:         true,
+		LateArrivalMins: 60,
+		LookbackHours:   48,
+	}}
+	rollups := NewUsageRollupService(cfg, rollupStore, eventStore, meterStore, logger.GetLogger())
+	eventService := NewEventService(config.GetDefaultConfig(), nil, eventStore, meterStore, rollupStore, nil, nil, nil, nil, logger.GetLogger())
+
+	now := at(10, 12, 30)
+	require.NoError(t, rollups.RollUpUsage(ctx, now))
+
+	watermark, err := rollupStore.GetRollupWatermark(ctx, "meter_tokens")
+	require.NoError(t, err)
+	assert.Equal(t, at(10, 11, 0), watermark)
+
+	assertUsage := func(expected int64) {
+		result, err := eventService.GetUsageByMeter(ctx, &dto.GetUsageByMeterRequest{
+			MeterID:            "meter_tokens",
+			ExternalCustomerID: "ext_cust_123",
+			StartTime:          at(1, 9, 30),
+			EndTime:            at(10, 12, 0),
+		})
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(expected).Equal(result.Value), "usage %s", result.Value)
+	}
+	assertUsage(36)
+
+	// A late event in a rolled up window is only counted once rolled up again
+	insert("e5", at(9, 20, 10), 100)
+	assertUsage(36)
+
+	require.NoError(t, rollups.RollUpUsage(ctx, now))
+	assertUsage(136)
+}
+
+func TestPlanRollupRead(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, time.March, day, hour, min, 0, 0, time.UTC)
+	}
+
+	assert.Nil(t, planRollupRead(at(1, 0, 0), at(2, 0, 0), time.Time{}), "never rolled up")
+	assert.Nil(t, planRollupRead(at(1, 10, 15), at(1, 10, 45), at(5, 0, 0)), "no whole hour")
+
+	read := planRollupRead(at(1, 9, 30), at(10, 12, 0), at(10, 11, 0))
+	require.NotNil(t, read)
+	assert.Equal(t, []events.RollupRange{
+		{WindowSize: types.WindowSizeDay, StartTime: at(2, 0, 0), EndTime: at(10, 0, 0)},
+		{WindowSize: types.WindowSizeHour, StartTime: at(1, 10, 0), EndTime: at(2, 0, 0)},
+		{WindowSize: types.WindowSizeHour, StartTime: at(10, 0, 0), EndTime: at(10, 11, 0)},
+	}, read.ranges)
+	assert.Equal(t, []timeRange{
+		{start: at(1, 9, 30), end: at(1, 10, 0)},
+		{start: at(10, 11, 0), end: at(10, 12, 0)},
+	}, read.raw)
+
+	// Usage since the first event is read from the rollups up to the watermark
+	read = planRollupRead(time.Time{}, at(10, 0, 0), at(20, 0, 0))
+	require.NotNil(t, read)
+	assert.Equal(t, []events.RollupRange{
+		{WindowSize: types.WindowSizeDay, StartTime: time.Time{}, EndTime: at(10, 0, 0)},
+	}, read.ranges)
+	assert.Empty(t, read.raw)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/usagestream"
+)
+
+var ErrUsageStreamDisabled = errors.New("usage streaming is not enabled")
+
+type UsageStreamService interface {
+	// StreamCustomerUsage streams the events ingested for the customer of the
+	// tenant until the context is done
+	StreamCustomerUsage(ctx context.Context, customerID string) (<-chan usagestream.Update, error)
+}
+
+type usageStreamService struct {
+	broker       usagestream.Broker
+	customerRepo customer.Repository
+	logger       *logger.Logger
+}
+
+// NewUsageStreamService returns the service of the live usage streams. The broker
+// is nil when streaming is disabled
+func NewUsageStreamService(broker usagestream.Broker, customerRepo customer.Repository, logger *logger.Logger) UsageStreamService {
+	return &usageStreamService{
+		broker:       broker,
+		customerRepo: customerRepo,
+		logger:       logger,
+	}
+}
+
+func (s *usageStreamService) StreamCustomerUsage(ctx context.Context, customerID string) (<-chan usagestream.Update, error) {
+	if s.broker == nil {
+		return nil, ErrUsageStreamDisabled
+	}
+
+	// The lookup is scoped to the tenant, so a stream can only follow the
+	// customers of the caller
+	cust, err := s.customerRepo.Get(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	updates, err := s.broker.Subscribe(ctx, types.GetTenantID(ctx), cust.ExternalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream usage: %w", err)
+	}
+
+	s.logger.Debugw("streaming customer usage",
+		"customer_id", cust.ID,
+		"external_customer_id", cust.ExternalID,
+		"tenant_id", types.GetTenantID(ctx),
+	)
+
+	return updates, nil
+}
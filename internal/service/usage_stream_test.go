@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/usagestream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageStreamService_StreamCustomerUsage(t *testing.T) {
+	ctx, cancel := context.WithCancel(testutil.SetupContext())
+	defer cancel()
+
+	customerRepo := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerRepo.Create(ctx, &customer.Customer{
+		ID:         "cust_1",
+		ExternalID: "ext_1",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	log := logger.GetLogger()
+
+	t.Run("disabled", func(t *testing.T) {
+		svc := NewUsageStreamService(nil, customerRepo, log)
+		_, err := svc.StreamCustomerUsage(ctx, "cust_1")
+		assert.ErrorIs(t, err, ErrUsageStreamDisabled)
+	})
+
+	broker := usagestream.NewMemoryBroker()
+	svc := NewUsageStreamService(broker, customerRepo, log)
+
+	t.Run("unknown customer", func(t *testing.T) {
+		_, err := svc.StreamCustomerUsage(ctx, "cust_missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("streams the events of the customer", func(t *testing.T) {
+		updates, err := svc.StreamCustomerUsage(ctx, "cust_1")
+		require.NoError(t, err)
+
+		update := usagestream.Update{
+			TenantID:           types.GetTenantID(ctx),
+			EventID:            "evt_1",
+			EventName:          "api_call",
+			ExternalCustomerID: "ext_1",
+		}
+		require.NoError(t, broker.Publish(ctx, update))
+
+		select {
+		case got := <-updates:
+			assert.Equal(t, "evt_1", got.EventID)
+		case <-time.After(time.Second):
+			t.Fatal("update not delivered")
+		}
+	})
+}
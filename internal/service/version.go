@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// checkIfMatch returns a conflict when the request is conditional on a version of
+// the entity other than the one it has. The repositories catch the writes racing
+// between the read and the update, this catches the edits made since the client read
+func checkIfMatch(ctx context.Context, entity, id string, version int) error {
+	expected, ok := types.GetIfMatch(ctx)
+	if !ok || expected == version {
+		return nil
+	}
+	return domainErrors.NewVersionConflictError(entity, id, version)
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/clock"
 	"github.com/flexprice/flexprice/internal/domain/customer"
 	"github.com/flexprice/flexprice/internal/domain/events"
 	"github.com/flexprice/flexprice/internal/domain/meter"
@@ -17,6 +19,7 @@ import (
 	"github.com/flexprice/flexprice/internal/logger"
 	"github.com/flexprice/flexprice/internal/postgres"
 	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
 	"github.com/shopspring/decimal"
 )
 
@@ -34,9 +37,16 @@ type WalletService interface {
 	// GetWalletTransactions retrieves transactions for a wallet with pagination
 	GetWalletTransactions(ctx context.Context, walletID string, filter types.Filter) (*dto.WalletTransactionsResponse, error)
 
-	// TopUpWallet adds credits to a wallet
+	// TopUpWallet adds credits to a wallet, expiring at req.ExpiresAt when set
 	TopUpWallet(ctx context.Context, walletID string, req *dto.TopUpWalletRequest) (*dto.WalletResponse, error)
 
+	// DebitWallet consumes credits of a wallet, drawing from the oldest credits
+	// that have not expired first
+	DebitWallet(ctx context.Context, walletID string, req *dto.DebitWalletRequest) (*dto.WalletResponse, error)
+
+	// ExpireCredits debits the credits of all tenants that expired at now
+	ExpireCredits(ctx context.Context, now time.Time) error
+
 	// GetWalletBalance retrieves the real-time balance of a wallet
 	GetWalletBalance(ctx context.Context, walletID string) (*dto.WalletBalanceResponse, error)
 
@@ -44,8 +54,19 @@ type WalletService interface {
 	TerminateWallet(ctx context.Context, walletID string) error
 }
 
+// WalletCreditsExpiredEvent is the payload of the wallet.credits.expired webhook
+type WalletCreditsExpiredEvent struct {
+	WalletID   string          `json:"wallet_id"`
+	CustomerID string          `json:"customer_id"`
+	BucketID   string          `json:"bucket_id"`
+	Amount     decimal.Decimal `json:"amount"`
+	Currency   string          `json:"currency"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+}
+
 type walletService struct {
 	walletRepo       wallet.Repository
+	bucketRepo       wallet.CreditBucketRepository
 	logger           *logger.Logger
 	subscriptionRepo subscription.Repository
 	planRepo         plan.Repository
@@ -55,11 +76,21 @@ type walletService struct {
 	meterRepo        meter.Repository
 	customerRepo     customer.Repository
 	client           *postgres.Client
+	db               postgres.TxManager
+	webhookPublisher webhook.Publisher
+	auditPublisher   audit.Publisher
+
+	// subscriptionReader reads the usage pending on the balance of the wallets
+	subscriptionReader SubscriptionReader
+
+	// clock is the time credits are consumed at, the wall clock when nil
+	clock *clock.Clock
 }
 
 // NewWalletService creates a new instance of WalletService
 func NewWalletService(
 	walletRepo wallet.Repository,
+	bucketRepo wallet.CreditBucketRepository,
 	logger *logger.Logger,
 	subscriptionRepo subscription.Repository,
 	planRepo plan.Repository,
@@ -69,9 +100,15 @@ func NewWalletService(
 	meterRepo meter.Repository,
 	customerRepo customer.Repository,
 	client *postgres.Client,
+	db postgres.TxManager,
+	webhookPublisher webhook.Publisher,
+	auditPublisher audit.Publisher,
+	subscriptionReader SubscriptionReader,
+	clock *clock.Clock,
 ) WalletService {
 	return &walletService{
 		walletRepo:       walletRepo,
+		bucketRepo:       bucketRepo,
 		logger:           logger,
 		subscriptionRepo: subscriptionRepo,
 		planRepo:         planRepo,
@@ -81,6 +118,12 @@ func NewWalletService(
 		meterRepo:        meterRepo,
 		customerRepo:     customerRepo,
 		client:           client,
+		db:               db,
+		webhookPublisher: webhookPublisher,
+		auditPublisher:   auditPublisher,
+
+		subscriptionReader: subscriptionReader,
+		clock:              clock,
 	}
 }
 
@@ -111,6 +154,7 @@ func (s *walletService) CreateWallet(ctx context.Context, req *dto.CreateWalletR
 	if err := s.walletRepo.CreateWallet(ctx, w); err != nil {
 		return nil, fmt.Errorf("failed to create wallet: %w", err)
 	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeWallet, w.ID, types.AuditActionCreate, nil, w)
 
 	s.logger.Debugw("created wallet",
 		"wallet_id", w.ID,
@@ -205,6 +249,15 @@ func (s *walletService) GetWalletTransactions(ctx context.Context, walletID stri
 }
 
 func (s *walletService) TopUpWallet(ctx context.Context, walletID string, req *dto.TopUpWalletRequest) (*dto.WalletResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	before, err := s.walletRepo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
 	// Create a credit operation
 	creditReq := &wallet.WalletOperation{
 		WalletID:    walletID,
@@ -214,14 +267,200 @@ func (s *walletService) TopUpWallet(ctx context.Context, walletID string, req *d
 		Metadata:    req.Metadata,
 	}
 
-	if err := s.walletRepo.CreditWallet(ctx, creditReq); err != nil {
-		return nil, fmt.Errorf("failed to credit wallet: %w", err)
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		// Expiring credits are tracked in a bucket of their own
+		if req.ExpiresAt != nil {
+			bucket := &wallet.CreditBucket{
+				ID:        types.GenerateUUID(),
+				WalletID:  walletID,
+				Amount:    req.Amount,
+				Remaining: req.Amount,
+				ExpiresAt: req.ExpiresAt.UTC(),
+				BaseModel: types.GetDefaultBaseModel(ctx),
+			}
+			creditReq.ReferenceType = wallet.CreditBucketReferenceType
+			creditReq.ReferenceID = bucket.ID
+
+			if err := s.bucketRepo.CreateCreditBucket(ctx, bucket); err != nil {
+				return fmt.Errorf("failed to create credit bucket: %w", err)
+			}
+		}
+
+		if err := s.walletRepo.CreditWallet(ctx, creditReq); err != nil {
+			return fmt.Errorf("failed to credit wallet: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.recordWalletUpdate(ctx, before)
 
 	// Get updated wallet
 	return s.GetWalletByID(ctx, walletID)
 }
 
+func (s *walletService) DebitWallet(ctx context.Context, walletID string, req *dto.DebitWalletRequest) (*dto.WalletResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	before, err := s.walletRepo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	debitReq := &wallet.WalletOperation{
+		WalletID:    walletID,
+		Type:        types.TransactionTypeDebit,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Metadata:    req.Metadata,
+	}
+
+	err = s.db.WithTx(ctx, func(ctx context.Context) error {
+		return s.consumeCredits(ctx, debitReq, s.clock.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.recordWalletUpdate(ctx, before)
+
+	return s.GetWalletByID(ctx, walletID)
+}
+
+// consumeCredits debits the wallet, drawing from its credit buckets that have
+// not expired, oldest first, before the balance that never expires. Credits that
+// expired but were not debited yet by ExpireCredits cannot be consumed
+func (s *walletService) consumeCredits(ctx context.Context, req *wallet.WalletOperation, now time.Time) error {
+	w, err := s.walletRepo.GetWalletByID(ctx, req.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	buckets, err := s.bucketRepo.ListCreditBuckets(ctx, req.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to list credit buckets: %w", err)
+	}
+
+	available := w.Balance
+	for _, bucket := range buckets {
+		if !bucket.ExpiresAt.After(now) {
+			available = available.Sub(bucket.Remaining)
+		}
+	}
+
+	if req.Amount.GreaterThan(available) {
+		return fmt.Errorf("insufficient balance: available=%s, requested=%s", available, req.Amount)
+	}
+
+	left := req.Amount
+	for _, bucket := range buckets {
+		if !left.IsPositive() {
+			break
+		}
+		if !bucket.ExpiresAt.After(now) {
+			continue
+		}
+
+		consumed := decimal.Min(left, bucket.Remaining)
+		bucket.Remaining = bucket.Remaining.Sub(consumed)
+		bucket.UpdatedAt = now
+		bucket.UpdatedBy = types.GetUserID(ctx)
+		if err := s.bucketRepo.UpdateCreditBucket(ctx, bucket); err != nil {
+			return fmt.Errorf("failed to update credit bucket: %w", err)
+		}
+		left = left.Sub(consumed)
+	}
+
+	if err := s.walletRepo.DebitWallet(ctx, req); err != nil {
+		return fmt.Errorf("failed to debit wallet: %w", err)
+	}
+
+	return nil
+}
+
+func (s *walletService) ExpireCredits(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+
+	buckets, err := s.bucketRepo.ListExpiredCreditBuckets(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to list expired credit buckets: %w", err)
+	}
+
+	forEachJobItem(ctx, buckets, func(bucket *wallet.CreditBucket) {
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, bucket.TenantID)
+		tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+		// One failing bucket must not hold back the others
+		if err := s.expireCreditBucket(tenantCtx, bucket, now); err != nil {
+			s.logger.Errorw("failed to expire wallet credits",
+				"tenant_id", bucket.TenantID,
+				"wallet_id", bucket.WalletID,
+				"bucket_id", bucket.ID,
+				"error", err,
+			)
+		}
+	})
+
+	return nil
+}
+
+func (s *walletService) expireCreditBucket(ctx context.Context, bucket *wallet.CreditBucket, now time.Time) error {
+	return s.db.WithTx(ctx, func(ctx context.Context) error {
+		w, err := s.walletRepo.GetWalletByID(ctx, bucket.WalletID)
+		if err != nil {
+			return fmt.Errorf("failed to get wallet: %w", err)
+		}
+
+		amount := decimal.Min(bucket.Remaining, w.Balance)
+
+		bucket.Remaining = decimal.Zero
+		bucket.ExpiredAt = &now
+		bucket.UpdatedAt = now
+		bucket.UpdatedBy = types.GetUserID(ctx)
+		if err := s.bucketRepo.UpdateCreditBucket(ctx, bucket); err != nil {
+			return fmt.Errorf("failed to update credit bucket: %w", err)
+		}
+
+		if !amount.IsPositive() {
+			return nil
+		}
+
+		if err := s.walletRepo.DebitWallet(ctx, &wallet.WalletOperation{
+			WalletID:      w.ID,
+			Type:          types.TransactionTypeDebit,
+			Amount:        amount,
+			ReferenceType: wallet.CreditExpiryReferenceType,
+			ReferenceID:   bucket.ID,
+			Description:   "Expired credits",
+		}); err != nil {
+			return fmt.Errorf("failed to debit wallet: %w", err)
+		}
+
+		if err := s.webhookPublisher.Publish(ctx, types.WebhookEventWalletCreditsExpired, &WalletCreditsExpiredEvent{
+			WalletID:   w.ID,
+			CustomerID: w.CustomerID,
+			BucketID:   bucket.ID,
+			Amount:     amount,
+			Currency:   w.Currency,
+			ExpiresAt:  bucket.ExpiresAt,
+		}); err != nil {
+			return fmt.Errorf("failed to publish credits expired webhook: %w", err)
+		}
+
+		s.logger.Infow("expired wallet credits",
+			"tenant_id", bucket.TenantID,
+			"wallet_id", w.ID,
+			"bucket_id", bucket.ID,
+			"amount", amount,
+		)
+
+		return nil
+	})
+}
+
 func (s *walletService) GetWalletBalance(ctx context.Context, walletID string) (*dto.WalletBalanceResponse, error) {
 	w, err := s.walletRepo.GetWalletByID(ctx, walletID)
 	if err != nil {
@@ -232,34 +471,23 @@ func (s *walletService) GetWalletBalance(ctx context.Context, walletID string) (
 		return nil, fmt.Errorf("wallet is not active")
 	}
 
-	subscriptionService := NewSubscriptionService(
-		s.subscriptionRepo,
-		s.planRepo,
-		s.priceRepo,
-		s.producer,
-		s.eventRepo,
-		s.meterRepo,
-		s.customerRepo,
-		s.logger,
-	)
-
 	filter := &types.SubscriptionFilter{
 		CustomerID:         w.CustomerID,
 		Status:             types.StatusPublished,
 		SubscriptionStatus: types.SubscriptionStatusActive,
 	}
 
-	subscriptionsResp, err := subscriptionService.ListSubscriptions(ctx, filter)
+	subscriptionsResp, err := s.subscriptionReader.ListSubscriptions(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
 	}
 
 	totalPendingCharges := decimal.Zero
 	for _, sub := range subscriptionsResp.Subscriptions {
-		usageResp, err := subscriptionService.GetUsageBySubscription(ctx, &dto.GetUsageBySubscriptionRequest{
+		usageResp, err := s.subscriptionReader.GetUsageBySubscription(ctx, &dto.GetUsageBySubscriptionRequest{
 			SubscriptionID: sub.Subscription.ID,
 			StartTime:      sub.Subscription.CurrentPeriodStart,
-			EndTime:        time.Now().UTC(),
+			EndTime:        s.clock.Now(),
 		})
 		if err != nil {
 			s.logger.Errorw("failed to get subscription usage",
@@ -285,7 +513,7 @@ func (s *walletService) GetWalletBalance(ctx context.Context, walletID string) (
 
 	return &dto.WalletBalanceResponse{
 		RealTimeBalance:  realTimeBalance,
-		BalanceUpdatedAt: time.Now().UTC(),
+		BalanceUpdatedAt: s.clock.Now(),
 		Wallet:           w,
 	}, nil
 }
@@ -301,7 +529,7 @@ func (s *walletService) TerminateWallet(ctx context.Context, walletID string) er
 	}
 
 	// Use client's WithTx for atomic operations
-	return s.client.WithTx(ctx, func(ctx context.Context) error {
+	err = s.client.WithTx(ctx, func(ctx context.Context) error {
 		// Debit remaining balance if any
 		if w.Balance.GreaterThan(decimal.Zero) {
 			debitReq := &wallet.WalletOperation{
@@ -316,6 +544,20 @@ func (s *walletService) TerminateWallet(ctx context.Context, walletID string) er
 			}
 		}
 
+		// Nothing is left to consume or expire
+		buckets, err := s.bucketRepo.ListCreditBuckets(ctx, walletID)
+		if err != nil {
+			return fmt.Errorf("failed to list credit buckets: %w", err)
+		}
+		for _, bucket := range buckets {
+			bucket.Remaining = decimal.Zero
+			bucket.UpdatedAt = s.clock.Now()
+			bucket.UpdatedBy = types.GetUserID(ctx)
+			if err := s.bucketRepo.UpdateCreditBucket(ctx, bucket); err != nil {
+				return fmt.Errorf("failed to update credit bucket: %w", err)
+			}
+		}
+
 		// Update wallet status to closed
 		if err := s.walletRepo.UpdateWalletStatus(ctx, walletID, types.WalletStatusClosed); err != nil {
 			return fmt.Errorf("failed to close wallet: %w", err)
@@ -323,4 +565,26 @@ func (s *walletService) TerminateWallet(ctx context.Context, walletID string) er
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.recordWalletUpdate(ctx, w)
+	return nil
+}
+
+// recordWalletUpdate records the change of the balance or the status of a wallet
+// from before to its current state
+func (s *walletService) recordWalletUpdate(ctx context.Context, before *wallet.Wallet) {
+	if s.auditPublisher == nil {
+		return
+	}
+
+	after, err := s.walletRepo.GetWalletByID(ctx, before.ID)
+	if err != nil {
+		s.logger.Errorw("failed to get wallet for the audit log", "wallet_id", before.ID, "error", err)
+		return
+	}
+
+	s.auditPublisher.Publish(ctx, types.AuditEntityTypeWallet, before.ID, types.AuditActionUpdate, before, after)
 }
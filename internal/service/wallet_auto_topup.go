@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+)
+
+// AutoTopUpService manages the auto top-up rules of wallets and runs them from
+// the billing cron
+type AutoTopUpService interface {
+	SetAutoTopUp(ctx context.Context, walletID string, req *dto.UpdateAutoTopUpRequest) (*dto.AutoTopUpResponse, error)
+	GetAutoTopUp(ctx context.Context, walletID string) (*dto.AutoTopUpResponse, error)
+
+	// ProcessAutoTopUps tops up the wallets of all tenants whose balance dropped
+	// below the threshold of their rule, within the caps of the rule
+	ProcessAutoTopUps(ctx context.Context, now time.Time) error
+}
+
+// WalletAutoTopUpEvent is the payload of the wallet auto top-up webhooks. The
+// receiver of the succeeded webhook collects the amount from the payment method
+type WalletAutoTopUpEvent struct {
+	WalletID        string          `json:"wallet_id"`
+	CustomerID      string          `json:"customer_id"`
+	Amount          decimal.Decimal `json:"amount"`
+	Currency        string          `json:"currency"`
+	PaymentMethodID string          `json:"payment_method_id,omitempty"`
+	Reason          string          `json:"reason,omitempty"`
+}
+
+type autoTopUpService struct {
+	autoTopUpRepo    wallet.AutoTopUpRepository
+	walletRepo       wallet.Repository
+	customerRepo     customer.Repository
+	webhookPublisher webhook.Publisher
+	logger           *logger.Logger
+}
+
+func NewAutoTopUpService(
+	autoTopUpRepo wallet.AutoTopUpRepository,
+	walletRepo wallet.Repository,
+	customerRepo customer.Repository,
+	webhookPublisher webhook.Publisher,
+	logger *logger.Logger,
+) AutoTopUpService {
+	return &autoTopUpService{
+		autoTopUpRepo:    autoTopUpRepo,
+		walletRepo:       walletRepo,
+		customerRepo:     customerRepo,
+		webhookPublisher: webhookPublisher,
+		logger:           logger,
+	}
+}
+
+func (s *autoTopUpService) SetAutoTopUp(ctx context.Context, walletID string, req *dto.UpdateAutoTopUpRequest) (*dto.AutoTopUpResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	w, err := s.walletRepo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	if w.WalletStatus != types.WalletStatusActive {
+		return nil, fmt.Errorf("invalid request: wallet is not active")
+	}
+
+	rule := &wallet.AutoTopUp{
+		ID:         types.GenerateUUID(),
+		WalletID:   w.ID,
+		Enabled:    req.Enabled,
+		Threshold:  req.Threshold,
+		Amount:     req.Amount,
+		DailyCap:   req.DailyCap,
+		MonthlyCap: req.MonthlyCap,
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}
+
+	if err := s.autoTopUpRepo.UpsertAutoTopUp(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save auto top-up: %w", err)
+	}
+
+	return &dto.AutoTopUpResponse{AutoTopUp: rule}, nil
+}
+
+func (s *autoTopUpService) GetAutoTopUp(ctx context.Context, walletID string) (*dto.AutoTopUpResponse, error) {
+	rule, err := s.autoTopUpRepo.GetAutoTopUp(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auto top-up: %w", err)
+	}
+
+	return &dto.AutoTopUpResponse{AutoTopUp: rule}, nil
+}
+
+func (s *autoTopUpService) ProcessAutoTopUps(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+
+	rules, err := s.autoTopUpRepo.ListDueAutoTopUps(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list auto top-ups: %w", err)
+	}
+
+	forEachJobItem(ctx, rules, func(rule *wallet.AutoTopUp) {
+		tenantCtx := context.WithValue(ctx, types.CtxTenantID, rule.TenantID)
+		tenantCtx = context.WithValue(tenantCtx, types.CtxUserID, types.DefaultUserID)
+
+		// One failing wallet must not hold back the others
+		if err := s.topUp(tenantCtx, rule, now); err != nil {
+			s.logger.Errorw("failed to process wallet auto top-up",
+				"tenant_id", rule.TenantID,
+				"wallet_id", rule.WalletID,
+				"error", err,
+			)
+		}
+	})
+
+	return nil
+}
+
+func (s *autoTopUpService) topUp(ctx context.Context, rule *wallet.AutoTopUp, now time.Time) error {
+	w, err := s.walletRepo.GetWalletByID(ctx, rule.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	cust, err := s.customerRepo.Get(ctx, w.CustomerID)
+	if err != nil {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	event := &WalletAutoTopUpEvent{
+		WalletID:        w.ID,
+		CustomerID:      w.CustomerID,
+		Amount:          rule.Amount,
+		Currency:        w.Currency,
+		PaymentMethodID: cust.PaymentMethodID,
+	}
+
+	// Without a payment method the rule would fail on every run, so it is
+	// disabled until it is configured again
+	if cust.PaymentMethodID == "" {
+		rule.Enabled = false
+		rule.UpdatedAt = now
+		rule.UpdatedBy = types.GetUserID(ctx)
+		if err := s.autoTopUpRepo.UpsertAutoTopUp(ctx, rule); err != nil {
+			return fmt.Errorf("failed to disable auto top-up: %w", err)
+		}
+
+		event.Reason = "customer has no payment method"
+		return s.publish(ctx, types.WebhookEventWalletAutoTopUpFailed, event)
+	}
+
+	if reason, err := s.capExceeded(ctx, rule, now); err != nil {
+		return err
+	} else if reason != "" {
+		s.logger.Infow("skipped wallet auto top-up",
+			"tenant_id", rule.TenantID,
+			"wallet_id", rule.WalletID,
+			"reason", reason,
+		)
+		return nil
+	}
+
+	err = s.walletRepo.CreditWallet(ctx, &wallet.WalletOperation{
+		WalletID:      w.ID,
+		Type:          types.TransactionTypeCredit,
+		Amount:        rule.Amount,
+		ReferenceType: wallet.AutoTopUpReferenceType,
+		ReferenceID:   rule.ID,
+		Description:   "Auto top-up",
+		Metadata:      types.Metadata{"payment_method_id": cust.PaymentMethodID},
+	})
+	if err != nil {
+		event.Reason = err.Error()
+		if pubErr := s.publish(ctx, types.WebhookEventWalletAutoTopUpFailed, event); pubErr != nil {
+			s.logger.Errorw("failed to publish wallet auto top-up webhook", "wallet_id", w.ID, "error", pubErr)
+		}
+		return fmt.Errorf("failed to credit wallet: %w", err)
+	}
+
+	s.logger.Infow("topped up wallet",
+		"tenant_id", rule.TenantID,
+		"wallet_id", w.ID,
+		"amount", rule.Amount,
+	)
+
+	return s.publish(ctx, types.WebhookEventWalletAutoTopUpSucceeded, event)
+}
+
+// capExceeded returns why topping up now would exceed a cap of the rule, empty
+// when it would not
+func (s *autoTopUpService) capExceeded(ctx context.Context, rule *wallet.AutoTopUp, now time.Time) (string, error) {
+	caps := []struct {
+		name  string
+		limit decimal.Decimal
+		since time.Time
+	}{
+		{"daily", rule.DailyCap, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)},
+		{"monthly", rule.MonthlyCap, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range caps {
+		if !c.limit.IsPositive() {
+			continue
+		}
+
+		sum, err := s.autoTopUpRepo.SumAutoTopUps(ctx, rule.WalletID, c.since)
+		if err != nil {
+			return "", fmt.Errorf("failed to sum auto top-ups: %w", err)
+		}
+
+		if sum.Add(rule.Amount).GreaterThan(c.limit) {
+			return fmt.Sprintf("%s cap of %s reached", c.name, c.limit.String()), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (s *autoTopUpService) publish(ctx context.Context, eventType types.WebhookEventType, event *WalletAutoTopUpEvent) error {
+	if err := s.webhookPublisher.Publish(ctx, eventType, event); err != nil {
+		return fmt.Errorf("failed to publish %s webhook: %w", eventType, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoTopUpService_ProcessAutoTopUps(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	customerStore := testutil.NewInMemoryCustomerStore()
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:              "cust_card",
+		ExternalID:      "ext_card",
+		PaymentMethodID: "pm_123",
+		BaseModel:       types.GetDefaultBaseModel(ctx),
+	}))
+	require.NoError(t, customerStore.Create(ctx, &customer.Customer{
+		ID:         "cust_no_card",
+		ExternalID: "ext_no_card",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	walletStore := testutil.NewInMemoryWalletStore()
+	for id, customerID := range map[string]string{"wallet_card": "cust_card", "wallet_no_card": "cust_no_card"} {
+		require.NoError(t, walletStore.CreateWallet(ctx, &wallet.Wallet{
+			ID:           id,
+			CustomerID:   customerID,
+			Currency:     "usd",
+			Balance:      decimal.NewFromInt(5),
+			WalletStatus: types.WalletStatusActive,
+			BaseModel:    types.GetDefaultBaseModel(ctx),
+		}))
+	}
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:  "ep_1",
+		URL: "https://example.com/hooks",
+		EventTypes: []string{
+			string(types.WebhookEventWalletAutoTopUpSucceeded),
+			string(types.WebhookEventWalletAutoTopUpFailed),
+		},
+		Secret:    "whsec_test",
+		BaseModel: types.GetDefaultBaseModel(ctx),
+	}))
+
+	svc := NewAutoTopUpService(walletStore, walletStore, customerStore,
+		webhook.NewPublisher(webhookStore, logger.GetLogger()), logger.GetLogger())
+
+	rule := &dto.UpdateAutoTopUpRequest{
+		Enabled:   true,
+		Threshold: decimal.NewFromInt(10),
+		Amount:    decimal.NewFromInt(20),
+		DailyCap:  decimal.NewFromInt(30),
+	}
+	_, err := svc.SetAutoTopUp(ctx, "wallet_card", rule)
+	require.NoError(t, err)
+	_, err = svc.SetAutoTopUp(ctx, "wallet_no_card", rule)
+	require.NoError(t, err)
+
+	_, err = svc.SetAutoTopUp(ctx, "wallet_card", &dto.UpdateAutoTopUpRequest{
+		Enabled:  true,
+		Amount:   decimal.NewFromInt(20),
+		DailyCap: decimal.NewFromInt(10),
+	})
+	assert.Error(t, err, "daily cap below the amount")
+
+	countEvents := func() map[types.WebhookEventType]int {
+		deliveries, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+		require.NoError(t, err)
+
+		counts := make(map[types.WebhookEventType]int)
+		for _, d := range deliveries {
+			counts[d.EventType]++
+		}
+		return counts
+	}
+
+	require.NoError(t, svc.ProcessAutoTopUps(ctx, time.Now()))
+
+	topped, err := walletStore.GetWalletByID(ctx, "wallet_card")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(25).Equal(topped.Balance))
+
+	disabled, err := svc.GetAutoTopUp(ctx, "wallet_no_card")
+	require.NoError(t, err)
+	assert.False(t, disabled.Enabled)
+
+	assert.Equal(t, map[types.WebhookEventType]int{
+		types.WebhookEventWalletAutoTopUpSucceeded: 1,
+		types.WebhookEventWalletAutoTopUpFailed:    1,
+	}, countEvents())
+
+	// A second top-up the same day would exceed the daily cap
+	require.NoError(t, walletStore.DebitWallet(ctx, &wallet.WalletOperation{
+		WalletID: "wallet_card",
+		Type:     types.TransactionTypeDebit,
+		Amount:   decimal.NewFromInt(20),
+	}))
+	require.NoError(t, svc.ProcessAutoTopUps(ctx, time.Now()))
+
+	capped, err := walletStore.GetWalletByID(ctx, "wallet_card")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(5).Equal(capped.Balance))
+	assert.Equal(t, map[types.WebhookEventType]int{
+		types.WebhookEventWalletAutoTopUpSucceeded: 1,
+		types.WebhookEventWalletAutoTopUpFailed:    1,
+	}, countEvents())
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/flexprice/flexprice/internal/webhook"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletService_CreditExpiry(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	walletStore := testutil.NewInMemoryWalletStore()
+	require.NoError(t, walletStore.CreateWallet(ctx, &wallet.Wallet{
+		ID:           "wallet_123",
+		CustomerID:   "cust_123",
+		Currency:     "usd",
+		Balance:      decimal.Zero,
+		WalletStatus: types.WalletStatusActive,
+		BaseModel:    types.GetDefaultBaseModel(ctx),
+	}))
+
+	webhookStore := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, webhookStore.CreateEndpoint(ctx, &webhookDomain.Endpoint{
+		ID:         "ep_1",
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{string(types.WebhookEventWalletCreditsExpired)},
+		Secret:     "whsec_test",
+		BaseModel:  types.GetDefaultBaseModel(ctx),
+	}))
+
+	svc := NewWalletService(walletStore, walletStore, logger.GetLogger(), nil, nil, nil, nil, nil, nil, nil, nil,
+		testutil.NewInMemoryTxManager(), webhook.NewPublisher(webhookStore, logger.GetLogger()), nil, nil, nil)
+
+	now := time.Now().UTC()
+	inAnHour := now.Add(time.Hour)
+	inTwoHours := now.Add(2 * time.Hour)
+
+	topUp := func(amount int64, expiresAt *time.Time) {
+		_, err := svc.TopUpWallet(ctx, "wallet_123", &dto.TopUpWalletRequest{
+			Amount:    decimal.NewFromInt(amount),
+			ExpiresAt: expiresAt,
+		})
+		require.NoError(t, err)
+	}
+	debit := func(amount int64) (*dto.WalletResponse, error) {
+		return svc.DebitWallet(ctx, "wallet_123", &dto.DebitWalletRequest{Amount: decimal.NewFromInt(amount)})
+	}
+
+	_, err := svc.TopUpWallet(ctx, "wallet_123", &dto.TopUpWalletRequest{
+		Amount:    decimal.NewFromInt(10),
+		ExpiresAt: &now,
+	})
+	assert.Error(t, err, "expiry in the past")
+
+	topUp(10, nil)
+	topUp(20, &inAnHour)
+	topUp(30, &inTwoHours)
+
+	// The oldest bucket is drained before the next one
+	resp, err := debit(25)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(35).Equal(resp.Balance))
+
+	buckets, err := walletStore.ListCreditBuckets(ctx, "wallet_123")
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.True(t, decimal.NewFromInt(25).Equal(buckets[0].Remaining))
+	assert.Equal(t, inTwoHours, buckets[0].ExpiresAt)
+
+	// Nothing is left in the bucket expiring first
+	require.NoError(t, svc.ExpireCredits(ctx, inAnHour.Add(time.Minute)))
+	resp, err = svc.GetWalletByID(ctx, "wallet_123")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(35).Equal(resp.Balance))
+
+	require.NoError(t, svc.ExpireCredits(ctx, inTwoHours.Add(time.Minute)))
+	resp, err = svc.GetWalletByID(ctx, "wallet_123")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(10).Equal(resp.Balance))
+
+	deliveries, err := webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, types.WebhookEventWalletCreditsExpired, deliveries[0].EventType)
+
+	// Running again does not expire the credits twice
+	require.NoError(t, svc.ExpireCredits(ctx, inTwoHours.Add(2*time.Minute)))
+	deliveries, err = webhookStore.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+
+	_, err = debit(11)
+	assert.Error(t, err)
+
+	resp, err = debit(10)
+	require.NoError(t, err)
+	assert.True(t, resp.Balance.IsZero())
+}
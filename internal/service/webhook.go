@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/audit"
+	"github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+
+	defaultPreviousSecretTTLHours = 24
+)
+
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, req dto.CreateWebhookEndpointRequest) (*dto.WebhookEndpointSecretResponse, error)
+	GetEndpoint(ctx context.Context, id string) (*dto.WebhookEndpointResponse, error)
+	ListEndpoints(ctx context.Context, filter types.Filter) (*dto.ListWebhookEndpointsResponse, error)
+	UpdateEndpoint(ctx context.Context, id string, req dto.UpdateWebhookEndpointRequest) (*dto.WebhookEndpointResponse, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+
+	// RotateEndpointSecret replaces the signing secret of the endpoint. Deliveries
+	// are signed with both the new and the previous secret until the previous one
+	// expires, so receivers can switch over without dropping events
+	RotateEndpointSecret(ctx context.Context, id string, req dto.RotateWebhookSecretRequest) (*dto.WebhookEndpointSecretResponse, error)
+
+	ListDeliveries(ctx context.Context, filter *types.WebhookDeliveryFilter) (*dto.ListWebhookDeliveriesResponse, error)
+	GetDelivery(ctx context.Context, id string) (*dto.WebhookDeliveryResponse, error)
+
+	// ReplayDelivery schedules a delivery to be sent again right away with a fresh
+	// retry budget, whatever its current status
+	ReplayDelivery(ctx context.Context, id string) (*dto.WebhookDeliveryResponse, error)
+}
+
+type webhookService struct {
+	repo           webhook.Repository
+	auditPublisher audit.Publisher
+	logger         *logger.Logger
+}
+
+func NewWebhookService(repo webhook.Repository, auditPublisher audit.Publisher, logger *logger.Logger) WebhookService {
+	return &webhookService{
+		repo:           repo,
+		auditPublisher: auditPublisher,
+		logger:         logger,
+	}
+}
+
+func (s *webhookService) CreateEndpoint(ctx context.Context, req dto.CreateWebhookEndpointRequest) (*dto.WebhookEndpointSecretResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := req.ToEndpoint(ctx, secret)
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeWebhookEndpoint, endpoint.ID, types.AuditActionCreate, nil, endpoint)
+
+	return &dto.WebhookEndpointSecretResponse{
+		WebhookEndpointResponse: dto.WebhookEndpointResponse{Endpoint: endpoint},
+		Secret:                  secret,
+	}, nil
+}
+
+func (s *webhookService) GetEndpoint(ctx context.Context, id string) (*dto.WebhookEndpointResponse, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	return &dto.WebhookEndpointResponse{Endpoint: endpoint}, nil
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context, filter types.Filter) (*dto.ListWebhookEndpointsResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	endpoints, err := s.repo.ListEndpoints(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	response := &dto.ListWebhookEndpointsResponse{
+		Endpoints: make([]dto.WebhookEndpointResponse, len(endpoints)),
+		Total:     len(endpoints),
+		Offset:    filter.Offset,
+		Limit:     filter.Limit,
+	}
+
+	for i, endpoint := range endpoints {
+		response.Endpoints[i] = dto.WebhookEndpointResponse{Endpoint: endpoint}
+	}
+
+	return response, nil
+}
+
+func (s *webhookService) UpdateEndpoint(ctx context.Context, id string, req dto.UpdateWebhookEndpointRequest) (*dto.WebhookEndpointResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	before := *endpoint
+	req.ApplyTo(endpoint)
+	endpoint.UpdatedAt = time.Now().UTC()
+	endpoint.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeWebhookEndpoint, endpoint.ID, types.AuditActionUpdate, &before, endpoint)
+
+	return &dto.WebhookEndpointResponse{Endpoint: endpoint}, nil
+}
+
+func (s *webhookService) DeleteEndpoint(ctx context.Context, id string) error {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	if err := s.repo.DeleteEndpoint(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeWebhookEndpoint, id, types.AuditActionDelete, endpoint, nil)
+	return nil
+}
+
+func (s *webhookService) RotateEndpointSecret(ctx context.Context, id string, req dto.RotateWebhookSecretRequest) (*dto.WebhookEndpointSecretResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	ttlHours := defaultPreviousSecretTTLHours
+	if req.PreviousSecretTTLHours != nil {
+		ttlHours = *req.PreviousSecretTTLHours
+	}
+
+	before := *endpoint
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Duration(ttlHours) * time.Hour)
+	endpoint.PreviousSecret = endpoint.Secret
+	endpoint.PreviousSecretExpiresAt = &expiresAt
+	endpoint.Secret = secret
+	endpoint.UpdatedAt = now
+	endpoint.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("failed to rotate webhook endpoint secret: %w", err)
+	}
+	recordAudit(ctx, s.auditPublisher, types.AuditEntityTypeWebhookEndpoint, endpoint.ID, types.AuditActionUpdate, &before, endpoint)
+
+	s.logger.Infow("webhook endpoint secret rotated",
+		"endpoint_id", endpoint.ID,
+		"tenant_id", endpoint.TenantID,
+		"previous_secret_expires_at", expiresAt,
+	)
+
+	return &dto.WebhookEndpointSecretResponse{
+		WebhookEndpointResponse: dto.WebhookEndpointResponse{Endpoint: endpoint},
+		Secret:                  secret,
+	}, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(raw), nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, filter *types.WebhookDeliveryFilter) (*dto.ListWebhookDeliveriesResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = types.DefaultFilterLimit
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	response := &dto.ListWebhookDeliveriesResponse{
+		Deliveries: make([]dto.WebhookDeliveryResponse, len(deliveries)),
+		Total:      len(deliveries),
+		Offset:     filter.Offset,
+		Limit:      filter.Limit,
+	}
+
+	for i, delivery := range deliveries {
+		response.Deliveries[i] = dto.WebhookDeliveryResponse{Delivery: delivery}
+	}
+
+	return response, nil
+}
+
+func (s *webhookService) GetDelivery(ctx context.Context, id string) (*dto.WebhookDeliveryResponse, error) {
+	delivery, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return &dto.WebhookDeliveryResponse{Delivery: delivery}, nil
+}
+
+func (s *webhookService) ReplayDelivery(ctx context.Context, id string) (*dto.WebhookDeliveryResponse, error) {
+	delivery, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	now := time.Now().UTC()
+	delivery.DeliveryStatus = types.WebhookDeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	delivery.UpdatedAt = now
+	delivery.UpdatedBy = types.GetUserID(ctx)
+
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	s.logger.Infow("webhook delivery replayed",
+		"delivery_id", delivery.ID,
+		"tenant_id", delivery.TenantID,
+	)
+
+	return &dto.WebhookDeliveryResponse{Delivery: delivery}, nil
+}
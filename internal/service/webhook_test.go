@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/api/dto"
+	"github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookService_ReplayDelivery(t *testing.T) {
+	ctx := testutil.SetupContext()
+	store := testutil.NewInMemoryWebhookStore()
+	svc := NewWebhookService(store, nil, logger.GetLogger())
+
+	lastAttempt := time.Now().UTC().Add(-time.Hour)
+	require.NoError(t, store.CreateDelivery(ctx, &webhook.Delivery{
+		ID:               "whd_1",
+		EndpointID:       "ep_1",
+		EventType:        types.WebhookEventInvoiceFinalized,
+		Payload:          []byte(`{}`),
+		DeliveryStatus:   types.WebhookDeliveryStatusFailed,
+		Attempts:         8,
+		LastAttemptAt:    &lastAttempt,
+		LastResponseCode: 500,
+		LastError:        "endpoint responded with status 500",
+		BaseModel:        types.GetDefaultBaseModel(ctx),
+	}))
+
+	failed, err := svc.ListDeliveries(ctx, &types.WebhookDeliveryFilter{DeliveryStatus: types.WebhookDeliveryStatusFailed})
+	require.NoError(t, err)
+	require.Len(t, failed.Deliveries, 1)
+	assert.Equal(t, types.DefaultFilterLimit, failed.Limit)
+
+	resp, err := svc.ReplayDelivery(ctx, "whd_1")
+	require.NoError(t, err)
+	assert.Equal(t, types.WebhookDeliveryStatusPending, resp.DeliveryStatus)
+	assert.Equal(t, 0, resp.Attempts)
+	require.NotNil(t, resp.NextAttemptAt)
+
+	// The replayed delivery is due right away
+	due, err := store.ClaimDueDeliveries(ctx, time.Now().UTC(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "whd_1", due[0].ID)
+
+	_, err = svc.ReplayDelivery(ctx, "missing")
+	assert.Error(t, err)
+}
+
+func TestWebhookService_Endpoints(t *testing.T) {
+	ctx := context.WithValue(testutil.SetupContext(), types.CtxEnvironmentID, "env_live")
+	svc := NewWebhookService(testutil.NewInMemoryWebhookStore(), nil, logger.GetLogger())
+
+	_, err := svc.CreateEndpoint(ctx, dto.CreateWebhookEndpointRequest{URL: "ftp://example.com"})
+	assert.Error(t, err)
+
+	_, err = svc.CreateEndpoint(ctx, dto.CreateWebhookEndpointRequest{
+		URL:        "https://example.com/hooks",
+		EventTypes: []types.WebhookEventType{"unknown.event"},
+	})
+	assert.Error(t, err)
+
+	created, err := svc.CreateEndpoint(ctx, dto.CreateWebhookEndpointRequest{
+		URL:        "https://example.com/hooks",
+		EventTypes: []types.WebhookEventType{types.WebhookEventInvoiceFinalized},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+	assert.Equal(t, "env_live", created.EnvironmentID)
+
+	_, err = svc.CreateEndpoint(ctx, dto.CreateWebhookEndpointRequest{URL: "https://example.com/other"})
+	require.NoError(t, err)
+
+	list, err := svc.ListEndpoints(ctx, types.Filter{})
+	require.NoError(t, err)
+	assert.Len(t, list.Endpoints, 2)
+
+	// Endpoints of other environments are not visible
+	otherEnv := context.WithValue(testutil.SetupContext(), types.CtxEnvironmentID, "env_test")
+	_, err = svc.GetEndpoint(otherEnv, created.ID)
+	assert.Error(t, err)
+
+	updated, err := svc.UpdateEndpoint(ctx, created.ID, dto.UpdateWebhookEndpointRequest{URL: "https://example.com/v2"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/v2", updated.URL)
+	assert.Empty(t, updated.EventTypes)
+
+	rotated, err := svc.RotateEndpointSecret(ctx, created.ID, dto.RotateWebhookSecretRequest{})
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Secret, rotated.Secret)
+	require.NotNil(t, rotated.PreviousSecretExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *rotated.PreviousSecretExpiresAt, time.Minute)
+	assert.Equal(t, []string{rotated.Secret, created.Secret}, rotated.Endpoint.SigningSecrets(time.Now()))
+
+	zero := 0
+	rotated, err = svc.RotateEndpointSecret(ctx, created.ID, dto.RotateWebhookSecretRequest{PreviousSecretTTLHours: &zero})
+	require.NoError(t, err)
+	assert.Equal(t, []string{rotated.Secret}, rotated.Endpoint.SigningSecrets(time.Now()))
+
+	require.NoError(t, svc.DeleteEndpoint(ctx, created.ID))
+	_, err = svc.GetEndpoint(ctx, created.ID)
+	assert.Error(t, err)
+}
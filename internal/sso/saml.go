@@ -0,0 +1,310 @@
+package sso
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+const (
+	samlAssertionNS     = "urn:oasis:names:tc:SAML:2.0:assertion"
+	samlProtocolNS      = "urn:oasis:names:tc:SAML:2.0:protocol"
+	samlStatusSuccess   = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	samlBearer          = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	samlBindingHTTPPost = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	samlEmailFormat     = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+
+	// maxSAMLResponseSize bounds the responses parsed, real ones are a few KB
+	maxSAMLResponseSize = 256 << 10
+)
+
+// SAMLProvider is a SAML 2.0 identity provider, with flexprice as the service
+// provider. Requests use the HTTP-Redirect binding and responses the HTTP-POST
+// binding. Encrypted assertions and IdP initiated sign ins are not supported
+type SAMLProvider struct {
+	// EntityID and ACSURL identify flexprice to the identity provider
+	EntityID string
+	ACSURL   string
+
+	IdPEntityID string
+	IdPSSOURL   string
+
+	certificate *x509.Certificate
+}
+
+// NewSAMLProvider returns the provider of the identity provider signing its
+// responses with the PEM certificate
+func NewSAMLProvider(entityID, acsURL, idpEntityID, idpSSOURL, certificatePEM string) (*SAMLProvider, error) {
+	certificate, err := ParseCertificate(certificatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SAMLProvider{
+		EntityID:    entityID,
+		ACSURL:      acsURL,
+		IdPEntityID: idpEntityID,
+		IdPSSOURL:   idpSSOURL,
+		certificate: certificate,
+	}, nil
+}
+
+// ParseCertificate parses a PEM certificate. The base64 DER of the metadata
+// of the identity providers is accepted too
+func ParseCertificate(certificatePEM string) (*x509.Certificate, error) {
+	var der []byte
+	if block, _ := pem.Decode([]byte(certificatePEM)); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(certificatePEM), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: not a PEM or base64 certificate")
+		}
+		der = decoded
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	return certificate, nil
+}
+
+// AuthnRequestURL is where the user is sent to sign in. The request ID must be
+// checked against the InResponseTo of the response
+func (p *SAMLProvider) AuthnRequestURL(requestID, relayState string, now time.Time) (string, error) {
+	request := fmt.Sprintf(`<samlp:AuthnRequest xmlns:samlp="%s" xmlns:saml="%s" ID="%s" Version="2.0" IssueInstant="%s" Destination="%s" AssertionConsumerServiceURL="%s" ProtocolBinding="%s">`+
+		`<saml:Issuer>%s</saml:Issuer><samlp:NameIDPolicy Format="%s" AllowCreate="true"/></samlp:AuthnRequest>`,
+		samlProtocolNS, samlAssertionNS, escapeXML(requestID), now.UTC().Format(time.RFC3339),
+		escapeXML(p.IdPSSOURL), escapeXML(p.ACSURL), samlBindingHTTPPost,
+		escapeXML(p.EntityID), samlEmailFormat,
+	)
+
+	var deflated bytes.Buffer
+	writer, err := flate.NewWriter(&deflated, flate.BestCompression)
+	if err != nil {
+		return "", fmt.Errorf("failed to deflate authn request: %w", err)
+	}
+	if _, err := writer.Write([]byte(request)); err != nil {
+		return "", fmt.Errorf("failed to deflate authn request: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to deflate authn request: %w", err)
+	}
+
+	params := url.Values{
+		"SAMLRequest": {base64.StdEncoding.EncodeToString(deflated.Bytes())},
+		"RelayState":  {relayState},
+	}
+
+	separator := "?"
+	if strings.Contains(p.IdPSSOURL, "?") {
+		separator = "&"
+	}
+	return p.IdPSSOURL + separator + params.Encode(), nil
+}
+
+// samlAssertion is the part of an assertion the sign in relies on
+type samlAssertion struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Assertion"`
+	Issuer  string   `xml:"Issuer"`
+	Subject struct {
+		NameID        string `xml:"NameID"`
+		Confirmations []struct {
+			Method string `xml:"Method,attr"`
+			Data   struct {
+				InResponseTo string `xml:"InResponseTo,attr"`
+				Recipient    string `xml:"Recipient,attr"`
+				NotOnOrAfter string `xml:"NotOnOrAfter,attr"`
+			} `xml:"SubjectConfirmationData"`
+		} `xml:"SubjectConfirmation"`
+	} `xml:"Subject"`
+	Conditions struct {
+		NotBefore    string   `xml:"NotBefore,attr"`
+		NotOnOrAfter string   `xml:"NotOnOrAfter,attr"`
+		Audiences    []string `xml:"AudienceRestriction>Audience"`
+	} `xml:"Conditions"`
+	Attributes []struct {
+		Name   string   `xml:"Name,attr"`
+		Values []string `xml:"AttributeValue"`
+	} `xml:"AttributeStatement>Attribute"`
+}
+
+// ParseResponse verifies the base64 response posted to the ACS URL and returns
+// the identity it asserts. Either the response or its assertion must be signed
+// with the certificate of the identity provider, and only the signed XML is read
+func (p *SAMLProvider) ParseResponse(encoded, requestID, emailAttribute, groupsAttribute string, now time.Time) (*Identity, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml response encoding: %w", err)
+	}
+	if len(raw) > maxSAMLResponseSize {
+		return nil, fmt.Errorf("saml response is too large")
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(raw); err != nil {
+		return nil, fmt.Errorf("invalid saml response: %w", err)
+	}
+
+	response := doc.Root()
+	if response == nil || response.Tag != "Response" || response.NamespaceURI() != samlProtocolNS {
+		return nil, fmt.Errorf("invalid saml response: not a response")
+	}
+	if response.SelectElement("EncryptedAssertion") != nil {
+		return nil, fmt.Errorf("encrypted saml assertions are not supported")
+	}
+
+	validation := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+		Roots: []*x509.Certificate{p.certificate},
+	})
+
+	assertion, response, err := p.signedAssertion(validation, response)
+	if err != nil {
+		return nil, err
+	}
+
+	if status := response.FindElement("./Status/StatusCode"); status == nil || status.SelectAttrValue("Value", "") != samlStatusSuccess {
+		return nil, fmt.Errorf("saml sign in failed at the identity provider")
+	}
+
+	return p.verifyAssertion(assertion, requestID, emailAttribute, groupsAttribute, now)
+}
+
+// signedAssertion returns the single assertion of the response as covered by
+// its signature, so that no unsigned XML wrapped around it is ever read. The
+// response is returned as validated when it is the signed element
+func (p *SAMLProvider) signedAssertion(validation *dsig.ValidationContext, response *etree.Element) (*samlAssertion, *etree.Element, error) {
+	responseSigned := response.SelectElement("Signature") != nil
+	if responseSigned {
+		validated, err := validation.Validate(response)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid saml response signature: %w", err)
+		}
+		response = validated
+	}
+
+	assertions := response.SelectElements("Assertion")
+	if len(assertions) != 1 {
+		return nil, nil, fmt.Errorf("saml response must have exactly one assertion")
+	}
+
+	ctx, err := etreeutils.NewDefaultNSContext().SubContext(response)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid saml response: %w", err)
+	}
+	assertionEl, err := etreeutils.NSDetatch(ctx, assertions[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid saml assertion: %w", err)
+	}
+
+	if !responseSigned {
+		validated, err := validation.Validate(assertionEl)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid saml assertion signature: %w", err)
+		}
+		assertionEl = validated
+	}
+
+	doc := etree.NewDocument()
+	doc.SetRoot(assertionEl)
+	encoded, err := doc.WriteToBytes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid saml assertion: %w", err)
+	}
+
+	var assertion samlAssertion
+	if err := xml.Unmarshal(encoded, &assertion); err != nil {
+		return nil, nil, fmt.Errorf("invalid saml assertion: %w", err)
+	}
+	return &assertion, response, nil
+}
+
+func (p *SAMLProvider) verifyAssertion(assertion *samlAssertion, requestID, emailAttribute, groupsAttribute string, now time.Time) (*Identity, error) {
+	if strings.TrimSpace(assertion.Issuer) != p.IdPEntityID {
+		return nil, fmt.Errorf("saml assertion issuer is not %s", p.IdPEntityID)
+	}
+
+	if notBefore, ok := parseSAMLTime(assertion.Conditions.NotBefore); ok && now.Add(clockSkew).Before(notBefore) {
+		return nil, fmt.Errorf("saml assertion is not valid yet")
+	}
+	if notOnOrAfter, ok := parseSAMLTime(assertion.Conditions.NotOnOrAfter); ok && !now.Add(-clockSkew).Before(notOnOrAfter) {
+		return nil, fmt.Errorf("saml assertion is expired")
+	}
+
+	audience := false
+	for _, a := range assertion.Conditions.Audiences {
+		if strings.TrimSpace(a) == p.EntityID {
+			audience = true
+		}
+	}
+	if !audience {
+		return nil, fmt.Errorf("saml assertion audience is not %s", p.EntityID)
+	}
+
+	confirmed := false
+	for _, confirmation := range assertion.Subject.Confirmations {
+		data := confirmation.Data
+		if confirmation.Method != samlBearer || data.InResponseTo != requestID || data.Recipient != p.ACSURL {
+			continue
+		}
+		if notOnOrAfter, ok := parseSAMLTime(data.NotOnOrAfter); !ok || !now.Add(-clockSkew).Before(notOnOrAfter) {
+			continue
+		}
+		confirmed = true
+	}
+	if !confirmed {
+		return nil, fmt.Errorf("saml assertion is not a response to this sign in")
+	}
+
+	identity := &Identity{
+		Subject: strings.TrimSpace(assertion.Subject.NameID),
+		Email:   strings.TrimSpace(assertion.Subject.NameID),
+	}
+	if emailAttribute != "" {
+		identity.Email = ""
+	}
+	if groupsAttribute == "" {
+		groupsAttribute = DefaultGroupsClaim
+	}
+	for _, attribute := range assertion.Attributes {
+		if emailAttribute != "" && attribute.Name == emailAttribute && len(attribute.Values) > 0 {
+			identity.Email = strings.TrimSpace(attribute.Values[0])
+		}
+		if attribute.Name == groupsAttribute {
+			for _, value := range attribute.Values {
+				identity.Groups = append(identity.Groups, strings.TrimSpace(value))
+			}
+		}
+	}
+
+	if identity.Email == "" || !strings.Contains(identity.Email, "@") {
+		return nil, fmt.Errorf("saml assertion has no email")
+	}
+	return identity, nil
+}
+
+func parseSAMLTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	return t, err == nil
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
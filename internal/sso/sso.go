@@ -0,0 +1,25 @@
+// Package sso signs the users of a tenant in with the OpenID Connect or SAML
+// identity provider of the tenant
+package sso
+
+import (
+	"net/http"
+	"time"
+)
+
+// Identity is the user asserted by an identity provider
+type Identity struct {
+	// Subject is the ID of the user at the identity provider
+	Subject string
+	Email   string
+	Groups  []string
+}
+
+// DefaultGroupsClaim is the ID token claim or SAML attribute the groups of the
+// users are read from when the config of the tenant has none
+const DefaultGroupsClaim = "groups"
+
+// clockSkew is the leeway given to the clock of the identity providers
+const clockSkew = 3 * time.Minute
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
@@ -0,0 +1,211 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/golang-jwt/jwt"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var issuer string
+	idToken := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key_1"
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	claims := func(nonce string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    issuer,
+			"aud":    "client_1",
+			"sub":    "idp_user_1",
+			"email":  "ada@acme.com",
+			"groups": []string{"billing", "eng"},
+			"nonce":  nonce,
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	var tokenClaims jwt.MapClaims
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": issuer + "/authorize",
+			"token_endpoint":         issuer + "/token",
+			"jwks_uri":               issuer + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "key_1",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		if clientID != "client_1" || secret != "secret_1" || r.FormValue("code") != "code_1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idToken(tokenClaims)})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	ctx := context.Background()
+	provider, err := DiscoverOIDC(ctx, issuer)
+	require.NoError(t, err)
+
+	authURL := provider.AuthCodeURL("client_1", "https://api.example.com/callback", "state_1", "nonce_1")
+	assert.True(t, strings.HasPrefix(authURL, issuer+"/authorize?"))
+	assert.Contains(t, authURL, "nonce=nonce_1")
+
+	t.Run("exchanges the code for the identity", func(t *testing.T) {
+		tokenClaims = claims("nonce_1")
+		identity, err := provider.Exchange(ctx, "client_1", "secret_1", "https://api.example.com/callback", "code_1", "nonce_1", "")
+		require.NoError(t, err)
+		assert.Equal(t, "ada@acme.com", identity.Email)
+		assert.Equal(t, []string{"billing", "eng"}, identity.Groups)
+	})
+
+	t.Run("rejects a replayed nonce", func(t *testing.T) {
+		tokenClaims = claims("nonce_other")
+		_, err := provider.Exchange(ctx, "client_1", "secret_1", "https://api.example.com/callback", "code_1", "nonce_1", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects tokens of other clients", func(t *testing.T) {
+		c := claims("nonce_1")
+		c["aud"] = "client_2"
+		_, err := provider.VerifyIDToken(ctx, idToken(c), "client_1", "nonce_1", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects tokens signed with another key", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims("nonce_1"))
+		token.Header["kid"] = "key_1"
+		signed, err := token.SignedString(other)
+		require.NoError(t, err)
+
+		_, err = provider.VerifyIDToken(ctx, signed, "client_1", "nonce_1", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestSAMLProvider(t *testing.T) {
+	keyStore := dsig.RandomKeyStoreForTest()
+	_, cert, err := keyStore.GetKeyPair()
+	require.NoError(t, err)
+	certificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}))
+
+	provider, err := NewSAMLProvider("https://api.example.com", "https://api.example.com/v1/auth/sso/saml/acs",
+		"https://idp.example.com", "https://idp.example.com/sso", certificate)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+
+	authURL, err := provider.AuthnRequestURL("id-request-1", "state_1", now)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(authURL, "https://idp.example.com/sso?"))
+	assert.Contains(t, authURL, "RelayState=state_1")
+
+	assertion := func(email string) *etree.Element {
+		doc := etree.NewDocument()
+		require.NoError(t, doc.ReadFromString(fmt.Sprintf(`<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="id-assertion-1" Version="2.0" IssueInstant="%[1]s">`+
+			`<saml:Issuer>https://idp.example.com</saml:Issuer>`+
+			`<saml:Subject><saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">%[3]s</saml:NameID>`+
+			`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">`+
+			`<saml:SubjectConfirmationData InResponseTo="id-request-1" Recipient="https://api.example.com/v1/auth/sso/saml/acs" NotOnOrAfter="%[2]s"/>`+
+			`</saml:SubjectConfirmation></saml:Subject>`+
+			`<saml:Conditions NotBefore="%[1]s" NotOnOrAfter="%[2]s"><saml:AudienceRestriction><saml:Audience>https://api.example.com</saml:Audience></saml:AudienceRestriction></saml:Conditions>`+
+			`<saml:AttributeStatement><saml:Attribute Name="groups"><saml:AttributeValue>admins</saml:AttributeValue><saml:AttributeValue>eng</saml:AttributeValue></saml:Attribute></saml:AttributeStatement>`+
+			`</saml:Assertion>`,
+			now.Add(-time.Minute).Format(time.RFC3339), now.Add(5*time.Minute).Format(time.RFC3339), email)))
+		return doc.Root()
+	}
+
+	sign := func(el *etree.Element) *etree.Element {
+		// IdPs sign assertions with the exclusive canonicalization, which keeps the
+		// signature valid once the assertion is embedded in the response
+		signing := dsig.NewDefaultSigningContext(keyStore)
+		signing.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+		signed, err := signing.SignEnveloped(el)
+		require.NoError(t, err)
+		return signed
+	}
+
+	response := func(assertions ...*etree.Element) string {
+		doc := etree.NewDocument()
+		require.NoError(t, doc.ReadFromString(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="id-response-1" Version="2.0" InResponseTo="id-request-1">`+
+			`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status></samlp:Response>`))
+		for _, a := range assertions {
+			doc.Root().AddChild(a)
+		}
+		encoded, err := doc.WriteToBytes()
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(encoded)
+	}
+
+	t.Run("reads the identity of a signed assertion", func(t *testing.T) {
+		identity, err := provider.ParseResponse(response(sign(assertion("ada@acme.com"))), "id-request-1", "", "", now)
+		require.NoError(t, err)
+		assert.Equal(t, "ada@acme.com", identity.Email)
+		assert.Equal(t, []string{"admins", "eng"}, identity.Groups)
+	})
+
+	t.Run("rejects a response to another request", func(t *testing.T) {
+		_, err := provider.ParseResponse(response(sign(assertion("ada@acme.com"))), "id-request-2", "", "", now)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects an expired assertion", func(t *testing.T) {
+		_, err := provider.ParseResponse(response(sign(assertion("ada@acme.com"))), "id-request-1", "", "", now.Add(time.Hour))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects unsigned assertions", func(t *testing.T) {
+		_, err := provider.ParseResponse(response(assertion("ada@acme.com")), "id-request-1", "", "", now)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects tampered assertions", func(t *testing.T) {
+		signed := sign(assertion("ada@acme.com"))
+		signed.FindElement(".//NameID").SetText("eve@acme.com")
+		_, err := provider.ParseResponse(response(signed), "id-request-1", "", "", now)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects an unsigned assertion next to a signed one", func(t *testing.T) {
+		_, err := provider.ParseResponse(response(assertion("eve@acme.com"), sign(assertion("ada@acme.com"))), "id-request-1", "", "", now)
+		assert.Error(t, err)
+	})
+}
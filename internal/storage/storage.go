@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Store is a minimal object storage abstraction used to persist generated files
+// such as usage exports and hand out time limited download links for them.
+type Store interface {
+	// Upload writes the content of r to the given key
+	Upload(ctx context.Context, key string, r io.Reader, contentType string) error
+
+	// PresignGetURL returns a signed URL that can be used to download the object
+	PresignGetURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+
+	// Download opens the object with the given key in the given bucket, an
+	// empty bucket being the configured one. The caller closes the reader
+	Download(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+}
+
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// NewStore builds the object store from the export configuration.
+// It returns a nil Store when no bucket is configured so that exports
+// can be disabled without failing the application startup.
+func NewStore(cfg *config.Configuration) (Store, error) {
+	c := cfg.Export
+	if c.Bucket == "" {
+		return nil, nil
+	}
+
+	switch c.Provider {
+	case types.StorageProviderS3, "":
+		return newS3Store(c)
+	case types.StorageProviderGCS:
+		if c.Endpoint == "" {
+			c.Endpoint = gcsEndpoint
+		}
+		if c.Region == "" {
+			c.Region = "auto"
+		}
+		return newS3Store(c)
+	default:
+		return nil, fmt.Errorf("unsupported storage provider: %s", c.Provider)
+	}
+}
+
+// ParseS3Path splits a path of the form s3://bucket/key into its bucket and key
+func ParseS3Path(path string) (bucket, key string, err error) {
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid s3 path: %s", path)
+	}
+
+	key = strings.TrimPrefix(u.Path, "/")
+	if key == "" {
+		return "", "", fmt.Errorf("invalid s3 path: %s has no key", path)
+	}
+	return u.Host, key, nil
+}
@@ -0,0 +1,252 @@
+// Package stripe reads the catalog, customers and subscriptions of the Stripe
+// account of a connection, manages the payment methods of its customers,
+// charges and refunds their payments and verifies the webhooks of the account
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	apiURL = "https://api.stripe.com/v1"
+
+	// apiVersion is pinned so that the current period stays on the subscription
+	apiVersion = "2024-06-20"
+
+	pageSize = 100
+)
+
+// Client calls a Stripe account with its secret key
+type Client struct {
+	url       string
+	secretKey string
+	client    *http.Client
+}
+
+func NewClient(secretKey string) *Client {
+	return &Client{
+		url:       apiURL,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Catalog is everything an import reads from a Stripe account
+type Catalog struct {
+	Products      []*Product
+	Prices        []*Price
+	Customers     []*Customer
+	Subscriptions []*Subscription
+}
+
+// FetchCatalog reads the active products and prices, the customers and the
+// subscriptions that are not canceled
+func (c *Client) FetchCatalog(ctx context.Context) (*Catalog, error) {
+	catalog := &Catalog{}
+	active := url.Values{"active": {"true"}}
+
+	if err := list(ctx, c, "products", active, &catalog.Products); err != nil {
+		return nil, err
+	}
+	if err := list(ctx, c, "prices", active, &catalog.Prices); err != nil {
+		return nil, err
+	}
+	if err := list(ctx, c, "customers", nil, &catalog.Customers); err != nil {
+		return nil, err
+	}
+	if err := list(ctx, c, "subscriptions", nil, &catalog.Subscriptions); err != nil {
+		return nil, err
+	}
+
+	return catalog, nil
+}
+
+// AttachPaymentMethod attaches a payment method, usually collected with Stripe.js,
+// to a customer and returns it
+func (c *Client) AttachPaymentMethod(ctx context.Context, paymentMethodID, customerID string) (*PaymentMethod, error) {
+	var pm PaymentMethod
+	err := c.post(ctx, "payment_methods/"+url.PathEscape(paymentMethodID)+"/attach", url.Values{"customer": {customerID}}, &pm)
+	if err != nil {
+		return nil, err
+	}
+	return &pm, nil
+}
+
+// DetachPaymentMethod detaches a payment method from its customer, it can no
+// longer be charged
+func (c *Client) DetachPaymentMethod(ctx context.Context, paymentMethodID string) error {
+	return c.post(ctx, "payment_methods/"+url.PathEscape(paymentMethodID)+"/detach", nil, &PaymentMethod{})
+}
+
+// SetDefaultPaymentMethod sets the payment method the invoices of the customer
+// are charged to
+func (c *Client) SetDefaultPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	form := url.Values{"invoice_settings[default_payment_method]": {paymentMethodID}}
+	return c.post(ctx, "customers/"+url.PathEscape(customerID), form, &Customer{})
+}
+
+// CreateRefund refunds an amount in the major unit of the currency of a payment,
+// either a payment intent or a charge. Stripe returns the refund created first
+// for a request retried with the same idempotency key
+func (c *Client) CreateRefund(ctx context.Context, paymentID string, amount decimal.Decimal, currency, idempotencyKey string) (*Refund, error) {
+	form := url.Values{"amount": {strconv.FormatInt(MinorUnits(amount, currency), 10)}}
+	if strings.HasPrefix(paymentID, "ch_") {
+		form.Set("charge", paymentID)
+	} else {
+		form.Set("payment_intent", paymentID)
+	}
+
+	var refund Refund
+	if err := c.postIdempotent(ctx, "refunds", form, idempotencyKey, &refund); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// CreatePaymentIntent authorizes an amount in the major unit of the currency on
+// a payment method of the customer, off session. The amount is captured with
+// CapturePaymentIntent
+func (c *Client) CreatePaymentIntent(ctx context.Context, customerID, paymentMethodID string, amount decimal.Decimal, currency, invoiceID, idempotencyKey string) (*PaymentIntent, error) {
+	form := paymentIntentForm(customerID, paymentMethodID, amount, currency, invoiceID)
+	form.Set("capture_method", "manual")
+
+	var intent PaymentIntent
+	if err := c.postIdempotent(ctx, "payment_intents", form, idempotencyKey, &intent); err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+// CreateDebitPaymentIntent debits an amount in the major unit of the currency
+// from a bank account payment method of the customer under the mandate it was
+// set up with, off session. The payment intent is processing until the debit
+// clears
+func (c *Client) CreateDebitPaymentIntent(ctx context.Context, customerID, paymentMethodID string, amount decimal.Decimal, currency, invoiceID, idempotencyKey string) (*PaymentIntent, error) {
+	form := paymentIntentForm(customerID, paymentMethodID, amount, currency, invoiceID)
+
+	var intent PaymentIntent
+	if err := c.postIdempotent(ctx, "payment_intents", form, idempotencyKey, &intent); err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+func paymentIntentForm(customerID, paymentMethodID string, amount decimal.Decimal, currency, invoiceID string) url.Values {
+	return url.Values{
+		"amount":               {strconv.FormatInt(MinorUnits(amount, currency), 10)},
+		"currency":             {strings.ToLower(currency)},
+		"customer":             {customerID},
+		"payment_method":       {paymentMethodID},
+		"confirm":              {"true"},
+		"off_session":          {"true"},
+		"metadata[invoice_id]": {invoiceID},
+	}
+}
+
+// CapturePaymentIntent captures an amount, at most the amount authorized, of a
+// payment intent
+func (c *Client) CapturePaymentIntent(ctx context.Context, paymentIntentID string, amount decimal.Decimal, currency, idempotencyKey string) (*PaymentIntent, error) {
+	form := url.Values{"amount_to_capture": {strconv.FormatInt(MinorUnits(amount, currency), 10)}}
+
+	var intent PaymentIntent
+	if err := c.postIdempotent(ctx, "payment_intents/"+url.PathEscape(paymentIntentID)+"/capture", form, idempotencyKey, &intent); err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+type identified interface {
+	id() string
+}
+
+type listPage[T any] struct {
+	Data    []T  `json:"data"`
+	HasMore bool `json:"has_more"`
+}
+
+// list reads every page of a list endpoint
+func list[T identified](ctx context.Context, c *Client, resource string, params url.Values, out *[]T) error {
+	query := url.Values{"limit": {strconv.Itoa(pageSize)}}
+	for k, v := range params {
+		query[k] = v
+	}
+
+	for {
+		var page listPage[T]
+		if err := c.get(ctx, resource, query, &page); err != nil {
+			return err
+		}
+
+		*out = append(*out, page.Data...)
+		if !page.HasMore || len(page.Data) == 0 {
+			return nil
+		}
+		query.Set("starting_after", page.Data[len(page.Data)-1].id())
+	}
+}
+
+func (c *Client) get(ctx context.Context, resource string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/"+resource+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return c.do(req, resource, out)
+}
+
+func (c *Client) post(ctx context.Context, resource string, form url.Values, out interface{}) error {
+	return c.postIdempotent(ctx, resource, form, "", out)
+}
+
+// postIdempotent sends the Idempotency-Key header with the request when a key is
+// given, Stripe replays the response of the first request with the key instead
+// of moving the money again
+func (c *Client) postIdempotent(ctx context.Context, resource string, form url.Values, idempotencyKey string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/"+resource, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	return c.do(req, resource, out)
+}
+
+func (c *Client) do(req *http.Request, resource string, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	req.Header.Set("Stripe-Version", apiVersion)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call stripe %s: %w", resource, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return &syncqueue.RateLimitError{RetryAfter: retryAfter}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("stripe responded with status %d: %s", resp.StatusCode, body)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode stripe %s: %w", resource, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package stripe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_FetchCatalog(t *testing.T) {
+	var startingAfter []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk_test", r.Header.Get("Authorization"))
+		assert.Equal(t, apiVersion, r.Header.Get("Stripe-Version"))
+
+		switch r.URL.Path {
+		case "/products":
+			assert.Equal(t, "true", r.URL.Query().Get("active"))
+			startingAfter = append(startingAfter, r.URL.Query().Get("starting_after"))
+			if r.URL.Query().Get("starting_after") == "" {
+				_, _ = w.Write([]byte(`{"data":[{"id":"prod_1","name":"Starter"}],"has_more":true}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[{"id":"prod_2","name":"Pro"}],"has_more":false}`))
+		case "/prices":
+			_, _ = w.Write([]byte(`{"data":[{"id":"price_1","product":"prod_1","currency":"usd","type":"recurring","unit_amount_decimal":"1500","recurring":{"interval":"month","interval_count":1,"usage_type":"licensed"}}],"has_more":false}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":[],"has_more":false}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("sk_test")
+	client.url = server.URL
+
+	catalog, err := client.FetchCatalog(context.Background())
+	require.NoError(t, err)
+	require.Len(t, catalog.Products, 2)
+	assert.Equal(t, "prod_2", catalog.Products[1].ID)
+	assert.Equal(t, []string{"", "prod_1"}, startingAfter)
+	require.Len(t, catalog.Prices, 1)
+	assert.True(t, decimal.NewFromInt(15).Equal(catalog.Prices[0].Amount()))
+	assert.Empty(t, catalog.Customers)
+}
+
+func TestClient_PaymentMethods(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, r.ParseForm())
+		calls = append(calls, r.URL.Path+" "+r.PostForm.Encode())
+
+		switch r.URL.Path {
+		case "/payment_methods/pm_1/attach":
+			_, _ = w.Write([]byte(`{"id":"pm_1","customer":"cus_1","type":"card","card":{"brand":"visa","last4":"4242","exp_month":12,"exp_year":2030}}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"pm_1"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("sk_test")
+	client.url = server.URL
+
+	pm, err := client.AttachPaymentMethod(context.Background(), "pm_1", "cus_1")
+	require.NoError(t, err)
+	assert.Equal(t, "card", pm.Type)
+	require.NotNil(t, pm.Card)
+	assert.Equal(t, "4242", pm.Card.Last4)
+
+	require.NoError(t, client.SetDefaultPaymentMethod(context.Background(), "cus_1", "pm_1"))
+	require.NoError(t, client.DetachPaymentMethod(context.Background(), "pm_1"))
+
+	assert.Equal(t, []string{
+		"/payment_methods/pm_1/attach customer=cus_1",
+		"/customers/cus_1 invoice_settings%5Bdefault_payment_method%5D=pm_1",
+		"/payment_methods/pm_1/detach ",
+	}, calls)
+}
+
+func TestClient_CreateRefund(t *testing.T) {
+	var forms, keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/refunds", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		forms = append(forms, r.PostForm.Encode())
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		_, _ = w.Write([]byte(`{"id":"re_1","amount":1250,"status":"succeeded"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("sk_test")
+	client.url = server.URL
+
+	refund, err := client.CreateRefund(context.Background(), "pi_1", decimal.RequireFromString("12.50"), "usd", "ref_1")
+	require.NoError(t, err)
+	assert.Equal(t, "re_1", refund.ID)
+	assert.Equal(t, "succeeded", refund.Status)
+
+	_, err = client.CreateRefund(context.Background(), "ch_1", decimal.NewFromInt(500), "jpy", "ref_2")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"amount=1250&payment_intent=pi_1", "amount=500&charge=ch_1"}, forms)
+	assert.Equal(t, []string{"ref_1", "ref_2"}, keys)
+}
+
+func TestClient_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient("sk_test")
+	client.url = server.URL
+
+	_, err := client.FetchCatalog(context.Background())
+	rateLimitErr, ok := syncqueue.IsRateLimited(err)
+	require.True(t, ok)
+	assert.Equal(t, 2*time.Second, rateLimitErr.RetryAfter)
+}
+
+func TestPrice_Amount(t *testing.T) {
+	amount := decimal.NewFromInt(1999)
+	assert.Equal(t, "19.99", (&Price{Currency: "usd", UnitAmountDecimal: &amount}).Amount().String())
+	assert.Equal(t, "1999", (&Price{Currency: "JPY", UnitAmountDecimal: &amount}).Amount().String())
+	assert.True(t, (&Price{Currency: "usd"}).Amount().IsZero())
+}
@@ -0,0 +1,174 @@
+package stripe
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Product struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Active      bool   `json:"active"`
+}
+
+func (p *Product) id() string { return p.ID }
+
+type Recurring struct {
+	// Interval is day, week, month or year
+	Interval      string `json:"interval"`
+	IntervalCount int    `json:"interval_count"`
+	// UsageType is licensed or metered
+	UsageType string `json:"usage_type"`
+}
+
+type Price struct {
+	ID       string `json:"id"`
+	Product  string `json:"product"`
+	Active   bool   `json:"active"`
+	Currency string `json:"currency"`
+	Nickname string `json:"nickname"`
+	// Type is one_time or recurring
+	Type string `json:"type"`
+	// BillingScheme is per_unit or tiered
+	BillingScheme string `json:"billing_scheme"`
+	// UnitAmountDecimal is the amount in the minor unit of the currency
+	UnitAmountDecimal *decimal.Decimal `json:"unit_amount_decimal"`
+	Recurring         *Recurring       `json:"recurring"`
+}
+
+func (p *Price) id() string { return p.ID }
+
+// zeroDecimalCurrencies are the currencies Stripe amounts are not in cents for
+var zeroDecimalCurrencies = map[string]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true, "jpy": true, "kmf": true,
+	"krw": true, "mga": true, "pyg": true, "rwf": true, "ugx": true, "vnd": true,
+	"vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+// Amount returns the unit amount of the price in the major unit of its currency
+func (p *Price) Amount() decimal.Decimal {
+	if p.UnitAmountDecimal == nil {
+		return decimal.Zero
+	}
+	if zeroDecimalCurrencies[strings.ToLower(p.Currency)] {
+		return *p.UnitAmountDecimal
+	}
+	return p.UnitAmountDecimal.Shift(-2)
+}
+
+// MinorUnits converts an amount in the major unit of a currency to the integer
+// amount Stripe expects
+func MinorUnits(amount decimal.Decimal, currency string) int64 {
+	if zeroDecimalCurrencies[strings.ToLower(currency)] {
+		return amount.Round(0).IntPart()
+	}
+	return amount.Shift(2).Round(0).IntPart()
+}
+
+type Address struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+type Shipping struct {
+	Address *Address `json:"address"`
+}
+
+type Customer struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Address  *Address  `json:"address"`
+	Shipping *Shipping `json:"shipping"`
+}
+
+func (c *Customer) id() string { return c.ID }
+
+type Card struct {
+	Brand    string `json:"brand"`
+	Last4    string `json:"last4"`
+	ExpMonth int    `json:"exp_month"`
+	ExpYear  int    `json:"exp_year"`
+}
+
+type PaymentMethod struct {
+	ID       string `json:"id"`
+	Customer string `json:"customer"`
+	// Type is card, sepa_debit, us_bank_account...
+	Type string `json:"type"`
+	// Card is only set for card payment methods
+	Card *Card `json:"card"`
+}
+
+type PaymentError struct {
+	Code string `json:"code"`
+	// DeclineCode is the reason of the issuer or the bank ex insufficient_funds
+	DeclineCode string `json:"decline_code"`
+	Message     string `json:"message"`
+}
+
+type PaymentIntent struct {
+	ID       string `json:"id"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	// Status is requires_payment_method, requires_confirmation, requires_action,
+	// processing, requires_capture, canceled or succeeded
+	Status           string        `json:"status"`
+	LastPaymentError *PaymentError `json:"last_payment_error"`
+}
+
+type Refund struct {
+	ID            string `json:"id"`
+	Amount        int64  `json:"amount"`
+	PaymentIntent string `json:"payment_intent"`
+	// Status is pending, requires_action, succeeded, failed or canceled
+	Status        string `json:"status"`
+	FailureReason string `json:"failure_reason"`
+}
+
+type SubscriptionItem struct {
+	Price    Price `json:"price"`
+	Quantity int   `json:"quantity"`
+}
+
+type Subscription struct {
+	ID       string `json:"id"`
+	Customer string `json:"customer"`
+	// Status is trialing, active, past_due, unpaid, incomplete, incomplete_expired, paused or canceled
+	Status             string `json:"status"`
+	Currency           string `json:"currency"`
+	StartDate          int64  `json:"start_date"`
+	BillingCycleAnchor int64  `json:"billing_cycle_anchor"`
+	CurrentPeriodStart int64  `json:"current_period_start"`
+	CurrentPeriodEnd   int64  `json:"current_period_end"`
+	CancelAt           *int64 `json:"cancel_at"`
+	CancelAtPeriodEnd  bool   `json:"cancel_at_period_end"`
+	TrialStart         *int64 `json:"trial_start"`
+	TrialEnd           *int64 `json:"trial_end"`
+	Items              struct {
+		Data []SubscriptionItem `json:"data"`
+	} `json:"items"`
+}
+
+func (s *Subscription) id() string { return s.ID }
+
+// Time converts a Stripe timestamp
+func Time(ts int64) time.Time {
+	return time.Unix(ts, 0).UTC()
+}
+
+// TimePtr converts an optional Stripe timestamp
+func TimePtr(ts *int64) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := Time(*ts)
+	return &t
+}
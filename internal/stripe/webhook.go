@@ -0,0 +1,76 @@
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// webhookTolerance is how old the timestamp of a signature may be, older
+// webhooks are rejected as replays
+const webhookTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned for webhooks not signed with the endpoint secret
+var ErrInvalidSignature = errors.New("invalid stripe signature")
+
+// Event is a webhook event of the account. Object is the payment intent or the
+// refund the event is about
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// VerifyWebhook checks the Stripe-Signature header of a webhook against the
+// secret of the endpoint and decodes its event
+func VerifyWebhook(payload []byte, signatureHeader, secret string, now time.Time) (*Event, error) {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signatureHeader, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return nil, ErrInvalidSignature
+	}
+	if now.Sub(time.Unix(secs, 0)) > webhookTolerance {
+		return nil, fmt.Errorf("%w: timestamp is too old", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	valid := false
+	for _, signature := range signatures {
+		decoded, err := hex.DecodeString(signature)
+		if err == nil && hmac.Equal(decoded, expected) {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode stripe event: %w", err)
+	}
+	return &event, nil
+}
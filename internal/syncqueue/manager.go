@@ -0,0 +1,137 @@
+package syncqueue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Config of the queue of a connection
+type Config struct {
+	RatePerSecond float64
+	Burst         int
+	MaxAttempts   int
+	BaseBackoff   time.Duration
+	MaxBackoff    time.Duration
+}
+
+// backoff is the exponential delay before the given retry
+func (c Config) backoff(attempt int) time.Duration {
+	delay := c.BaseBackoff
+	for i := 1; i < attempt && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+	return delay
+}
+
+// Manager owns one queue per integration connection. All integration
+// workflows enqueue through it so that calls to a provider never stampede
+type Manager struct {
+	cfg    Config
+	logger *logger.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	queues map[string]*queue
+}
+
+func NewManager(cfg *config.Configuration, logger *logger.Logger) *Manager {
+	return newManager(Config{
+		RatePerSecond: cfg.Integration.SyncRatePerSecond,
+		Burst:         cfg.Integration.SyncBurst,
+		MaxAttempts:   cfg.Integration.SyncMaxAttempts,
+		BaseBackoff:   time.Duration(cfg.Integration.SyncBackoffMillis) * time.Millisecond,
+	}, logger)
+}
+
+func newManager(cfg Config, logger *logger.Logger) *Manager {
+	if cfg.RatePerSecond <= 0 {
+		cfg.RatePerSecond = 5
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 64 * cfg.BaseBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		cfg:    cfg,
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+		queues: make(map[string]*queue),
+	}
+}
+
+// Enqueue adds a task to the queue of the connection. The task runs with ctx
+// stripped of its cancellation so it outlives the request that enqueued it.
+// ratePerSecond overrides the default rate limit of the connection when positive
+func (m *Manager) Enqueue(ctx context.Context, connectionID string, ratePerSecond float64, task *Task) {
+	q := m.queue(connectionID)
+	q.setRate(ratePerSecond)
+
+	task.ctx = context.WithoutCancel(ctx)
+	task.attempts = 0
+	q.push(task)
+}
+
+// Status returns the state of the queue of the connection. A connection that
+// never had a task enqueued reports an empty queue
+func (m *Manager) Status(connectionID string) *Status {
+	m.mu.Lock()
+	q, ok := m.queues[connectionID]
+	m.mu.Unlock()
+
+	if !ok {
+		return &Status{
+			ConnectionID:  connectionID,
+			PendingByType: map[types.SyncEntityType]int{},
+			RatePerSecond: m.cfg.RatePerSecond,
+		}
+	}
+	return q.status()
+}
+
+// Stop stops all queues and waits for in flight tasks to return
+func (m *Manager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+func (m *Manager) queue(connectionID string) *queue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if q, ok := m.queues[connectionID]; ok {
+		return q
+	}
+
+	q := newQueue(connectionID, m.cfg, m.logger)
+	m.queues[connectionID] = q
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		q.run(m.ctx)
+	}()
+
+	return q
+}
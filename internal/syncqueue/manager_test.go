@@ -0,0 +1,147 @@
+package syncqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testManager() *Manager {
+	return newManager(Config{
+		RatePerSecond: 1000,
+		Burst:         1,
+		MaxAttempts:   3,
+		BaseBackoff:   5 * time.Millisecond,
+	}, logger.GetLogger())
+}
+
+func TestManager_Priority(t *testing.T) {
+	m := testManager()
+	defer m.Stop()
+
+	var mu sync.Mutex
+	var order []string
+	done := make(chan struct{})
+	release := make(chan struct{})
+
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			n := len(order)
+			mu.Unlock()
+			if n == 4 {
+				close(done)
+			}
+			return nil
+		}
+	}
+
+	// The first task blocks the queue so the others are ordered by priority
+	m.Enqueue(context.Background(), "conn", 0, &Task{
+		EntityType: types.SyncEntityTypeCRMNote,
+		Run: func(ctx context.Context) error {
+			<-release
+			return record("blocker")(ctx)
+		},
+	})
+	require.Eventually(t, func() bool { return m.Status("conn").InFlight }, time.Second, time.Millisecond)
+
+	m.Enqueue(context.Background(), "conn", 0, &Task{EntityType: types.SyncEntityTypeCRMNote, Run: record("note")})
+	m.Enqueue(context.Background(), "conn", 0, &Task{EntityType: types.SyncEntityTypeCustomer, Run: record("customer")})
+	m.Enqueue(context.Background(), "conn", 0, &Task{EntityType: types.SyncEntityTypeInvoice, Run: record("invoice")})
+
+	status := m.Status("conn")
+	assert.Equal(t, 3, status.Pending)
+	assert.Equal(t, 1, status.PendingByType[types.SyncEntityTypeInvoice])
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("tasks did not run")
+	}
+
+	assert.Equal(t, []string{"blocker", "invoice", "customer", "note"}, order)
+	assert.Equal(t, int64(4), m.Status("conn").Processed)
+}
+
+func TestManager_RateLimitPausesConnection(t *testing.T) {
+	m := testManager()
+	defer m.Stop()
+
+	var mu sync.Mutex
+	var calls []time.Time
+	m.Enqueue(context.Background(), "conn", 0, &Task{
+		EntityType: types.SyncEntityTypeInvoice,
+		Run: func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, time.Now())
+			if len(calls) == 1 {
+				return &RateLimitError{RetryAfter: 50 * time.Millisecond}
+			}
+			return nil
+		},
+	})
+
+	require.Eventually(t, func() bool { return m.Status("conn").Processed == 1 }, time.Second, time.Millisecond)
+
+	status := m.Status("conn")
+	assert.Equal(t, int64(1), status.RateLimited)
+	assert.Equal(t, int64(0), status.Failed)
+	require.Len(t, calls, 2)
+	assert.GreaterOrEqual(t, calls[1].Sub(calls[0]), 50*time.Millisecond)
+}
+
+func TestManager_RetriesThenFails(t *testing.T) {
+	m := testManager()
+	defer m.Stop()
+
+	var mu sync.Mutex
+	attempts := 0
+	failed := make(chan error, 1)
+	m.Enqueue(context.Background(), "conn", 0, &Task{
+		EntityType: types.SyncEntityTypeInvoice,
+		Run: func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts++
+			return errors.New("provider unavailable")
+		},
+		OnFailure: func(err error) { failed <- err },
+	})
+
+	require.Eventually(t, func() bool { return m.Status("conn").Failed == 1 }, time.Second, time.Millisecond)
+
+	select {
+	case err := <-failed:
+		assert.EqualError(t, err, "provider unavailable")
+	case <-time.After(time.Second):
+		t.Fatal("OnFailure was not called")
+	}
+
+	mu.Lock()
+	assert.Equal(t, 3, attempts)
+	mu.Unlock()
+
+	status := m.Status("conn")
+	assert.Equal(t, "provider unavailable", status.LastError)
+	assert.Equal(t, 0, status.Pending)
+}
+
+func TestManager_StatusOfUnknownConnection(t *testing.T) {
+	m := testManager()
+	defer m.Stop()
+
+	status := m.Status("unknown")
+	assert.Equal(t, 0, status.Pending)
+	assert.Equal(t, float64(1000), status.RatePerSecond)
+}
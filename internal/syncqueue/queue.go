@@ -0,0 +1,209 @@
+package syncqueue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+	"golang.org/x/time/rate"
+)
+
+// Status is a snapshot of the queue of one connection
+type Status struct {
+	ConnectionID  string                       `json:"connection_id"`
+	Pending       int                          `json:"pending"`
+	PendingByType map[types.SyncEntityType]int `json:"pending_by_type"`
+	InFlight      bool                         `json:"in_flight"`
+	Processed     int64                        `json:"processed"`
+	Failed        int64                        `json:"failed"`
+	RateLimited   int64                        `json:"rate_limited"`
+	RatePerSecond float64                      `json:"rate_per_second"`
+
+	// PausedUntil is set while the connection backs off after a 429
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// queue runs the tasks of one connection one at a time, highest priority first
+type queue struct {
+	connectionID string
+	cfg          Config
+	limiter      *rate.Limiter
+	logger       *logger.Logger
+
+	mu          sync.Mutex
+	tasks       taskHeap
+	seq         uint64
+	wake        chan struct{}
+	inFlight    bool
+	pausedUntil time.Time
+	processed   int64
+	failed      int64
+	rateLimited int64
+	lastError   string
+	lastErrorAt time.Time
+}
+
+func newQueue(connectionID string, cfg Config, logger *logger.Logger) *queue {
+	return &queue{
+		connectionID: connectionID,
+		cfg:          cfg,
+		limiter:      rate.NewLimiter(rate.Limit(cfg.RatePerSecond), cfg.Burst),
+		logger:       logger,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+func (q *queue) push(task *Task) {
+	q.mu.Lock()
+	q.seq++
+	task.seq = q.seq
+	heap.Push(&q.tasks, task)
+	q.mu.Unlock()
+
+	q.signal()
+}
+
+func (q *queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *queue) setRate(ratePerSecond float64) {
+	if ratePerSecond > 0 {
+		q.limiter.SetLimit(rate.Limit(ratePerSecond))
+	}
+}
+
+// next pops the next task to run. When none can run it returns how long to
+// wait before trying again, zero meaning until a task is pushed
+func (q *queue) next(now time.Time) (*Task, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if now.Before(q.pausedUntil) {
+		return nil, q.pausedUntil.Sub(now)
+	}
+
+	if q.tasks.Len() == 0 {
+		return nil, 0
+	}
+
+	q.inFlight = true
+	return heap.Pop(&q.tasks).(*Task), 0
+}
+
+func (q *queue) run(ctx context.Context) {
+	for {
+		task, wait := q.next(time.Now())
+		if task == nil {
+			var timer <-chan time.Time
+			if wait > 0 {
+				timer = time.After(wait)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wake:
+			case <-timer:
+			}
+			continue
+		}
+
+		if err := q.limiter.Wait(ctx); err != nil {
+			return
+		}
+
+		task.attempts++
+		q.complete(task, task.Run(task.ctx))
+	}
+}
+
+func (q *queue) complete(task *Task, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.inFlight = false
+	if err == nil {
+		q.processed++
+		return
+	}
+
+	q.lastError = err.Error()
+	q.lastErrorAt = time.Now().UTC()
+
+	if rateLimitErr, ok := IsRateLimited(err); ok {
+		// A 429 pauses the whole connection and the task keeps its place.
+		// Rate limited attempts do not count towards MaxAttempts
+		q.rateLimited++
+		task.attempts--
+
+		delay := rateLimitErr.RetryAfter
+		if delay <= 0 {
+			delay = q.cfg.backoff(int(q.rateLimited))
+		}
+		q.pausedUntil = time.Now().Add(delay)
+		heap.Push(&q.tasks, task)
+
+		q.logger.Infow("integration rate limited, pausing sync queue",
+			"connection_id", q.connectionID,
+			"retry_after", delay)
+		return
+	}
+
+	if task.attempts >= q.cfg.MaxAttempts {
+		q.failed++
+		q.logger.Errorw("integration sync task failed",
+			"connection_id", q.connectionID,
+			"entity_type", task.EntityType,
+			"entity_id", task.EntityID,
+			"attempts", task.attempts,
+			"error", err)
+		if task.OnFailure != nil {
+			go task.OnFailure(err)
+		}
+		return
+	}
+
+	time.AfterFunc(q.cfg.backoff(task.attempts), func() { q.push(task) })
+}
+
+func (q *queue) status() *Status {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status := &Status{
+		ConnectionID:  q.connectionID,
+		Pending:       q.tasks.Len(),
+		PendingByType: make(map[types.SyncEntityType]int),
+		InFlight:      q.inFlight,
+		Processed:     q.processed,
+		Failed:        q.failed,
+		RateLimited:   q.rateLimited,
+		RatePerSecond: float64(q.limiter.Limit()),
+		LastError:     q.lastError,
+	}
+
+	for _, task := range q.tasks {
+		status.PendingByType[task.EntityType]++
+	}
+
+	if time.Now().Before(q.pausedUntil) {
+		pausedUntil := q.pausedUntil.UTC()
+		status.PausedUntil = &pausedUntil
+	}
+
+	if !q.lastErrorAt.IsZero() {
+		lastErrorAt := q.lastErrorAt
+		status.LastErrorAt = &lastErrorAt
+	}
+
+	return status
+}
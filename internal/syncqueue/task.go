@@ -0,0 +1,77 @@
+package syncqueue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Task is one outbound call to an integration
+type Task struct {
+	// EntityType decides the priority of the task
+	EntityType types.SyncEntityType
+	EntityID   string
+
+	// Run performs the call. It should return a *RateLimitError when the
+	// provider answered with 429 so the whole connection backs off
+	Run func(ctx context.Context) error
+
+	// OnFailure, if set, is called with the last error once the task runs out
+	// of attempts
+	OnFailure func(err error)
+
+	ctx      context.Context
+	attempts int
+	seq      uint64
+}
+
+// RateLimitError signals that the provider rate limited the connection
+type RateLimitError struct {
+	// RetryAfter is the delay requested by the provider, zero if unknown
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+}
+
+// IsRateLimited reports whether err is or wraps a *RateLimitError
+func IsRateLimited(err error) (*RateLimitError, bool) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr, true
+	}
+	return nil, false
+}
+
+// taskHeap orders tasks by priority, then by the order they were enqueued
+type taskHeap []*Task
+
+var _ heap.Interface = (*taskHeap)(nil)
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	pi, pj := h[i].EntityType.Priority(), h[j].EntityType.Priority()
+	if pi != pj {
+		return pi < pj
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *taskHeap) Push(x interface{}) { *h = append(*h, x.(*Task)) }
+
+func (h *taskHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	task := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return task
+}
@@ -0,0 +1,248 @@
+package tax
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	avalaraURL        = "https://rest.avatax.com"
+	avalaraSandboxURL = "https://sandbox-rest.avatax.com"
+)
+
+type avalaraClient struct {
+	baseURL     string
+	accountID   string
+	licenseKey  string
+	companyCode string
+	client      *http.Client
+}
+
+func newAvalaraClient(settings connection.TaxSettings, licenseKey string) *avalaraClient {
+	baseURL := avalaraURL
+	if settings.Sandbox {
+		baseURL = avalaraSandboxURL
+	}
+	return &avalaraClient{
+		baseURL:     baseURL,
+		accountID:   settings.AccountID,
+		licenseKey:  licenseKey,
+		companyCode: settings.CompanyCode,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type avalaraAddress struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+	Country    string `json:"country"`
+}
+
+func avalaraAddressOf(address customer.Address) avalaraAddress {
+	return avalaraAddress{
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
+
+type avalaraLine struct {
+	Number      string              `json:"number"`
+	Quantity    float64             `json:"quantity"`
+	Amount      float64             `json:"amount"`
+	TaxCode     string              `json:"taxCode,omitempty"`
+	Description string              `json:"description,omitempty"`
+	TaxOverride *avalaraTaxOverride `json:"taxOverride,omitempty"`
+}
+
+// avalaraTaxOverride records a line with the tax it was invoiced with instead
+// of the tax Avalara calculates
+type avalaraTaxOverride struct {
+	Type      string  `json:"type"`
+	TaxAmount float64 `json:"taxAmount"`
+	Reason    string  `json:"reason"`
+}
+
+type avalaraTransaction struct {
+	// Type is SalesOrder for quotes, which are not recorded, and SalesInvoice
+	Type         string `json:"type"`
+	Code         string `json:"code"`
+	CompanyCode  string `json:"companyCode"`
+	Date         string `json:"date"`
+	CustomerCode string `json:"customerCode"`
+	CurrencyCode string `json:"currencyCode"`
+	Commit       bool   `json:"commit"`
+	Addresses    struct {
+		ShipFrom avalaraAddress `json:"shipFrom"`
+		ShipTo   avalaraAddress `json:"shipTo"`
+	} `json:"addresses"`
+	Lines []avalaraLine `json:"lines"`
+}
+
+func (c *avalaraClient) transaction(doc *Document, transactionType string) *avalaraTransaction {
+	txn := &avalaraTransaction{
+		Type:         transactionType,
+		Code:         doc.Code,
+		CompanyCode:  c.companyCode,
+		Date:         doc.Date.Format("2006-01-02"),
+		CustomerCode: doc.CustomerCode,
+		CurrencyCode: strings.ToUpper(doc.Currency),
+	}
+	txn.Addresses.ShipFrom = avalaraAddressOf(doc.ShipFrom)
+	txn.Addresses.ShipTo = avalaraAddressOf(doc.ShipTo)
+
+	for _, line := range doc.Lines {
+		txn.Lines = append(txn.Lines, avalaraLine{
+			Number:      line.ID,
+			Quantity:    float(line.Quantity),
+			Amount:      float(line.Amount),
+			TaxCode:     line.TaxCode,
+			Description: line.Description,
+		})
+	}
+	return txn
+}
+
+func (c *avalaraClient) ValidateAddress(ctx context.Context, address customer.Address) (*customer.Address, error) {
+	var result struct {
+		ValidatedAddresses []avalaraAddress `json:"validatedAddresses"`
+		Messages           []struct {
+			Severity string `json:"severity"`
+			Summary  string `json:"summary"`
+		} `json:"messages"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v2/addresses/resolve", avalaraAddressOf(address), &result); err != nil {
+		return nil, err
+	}
+
+	for _, message := range result.Messages {
+		if message.Severity == "Error" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, message.Summary)
+		}
+	}
+	if len(result.ValidatedAddresses) == 0 {
+		return nil, ErrInvalidAddress
+	}
+
+	resolved := result.ValidatedAddresses[0]
+	return &customer.Address{
+		Line1:      resolved.Line1,
+		Line2:      resolved.Line2,
+		City:       resolved.City,
+		State:      resolved.Region,
+		PostalCode: resolved.PostalCode,
+		Country:    resolved.Country,
+	}, nil
+}
+
+// Quote creates a SalesOrder transaction, which Avalara calculates without
+// recording it
+func (c *avalaraClient) Quote(ctx context.Context, doc *Document) ([]LineTax, error) {
+	var result struct {
+		Lines []struct {
+			LineNumber string  `json:"lineNumber"`
+			Tax        float64 `json:"tax"`
+			Details    []struct {
+				Rate float64 `json:"rate"`
+			} `json:"details"`
+		} `json:"lines"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v2/transactions/create", c.transaction(doc, "SalesOrder"), &result); err != nil {
+		return nil, err
+	}
+
+	taxes := make([]LineTax, 0, len(result.Lines))
+	for _, line := range result.Lines {
+		rate := decimal.Zero
+		for _, detail := range line.Details {
+			rate = rate.Add(decimal.NewFromFloat(detail.Rate))
+		}
+		taxes = append(taxes, LineTax{
+			LineID:      line.LineNumber,
+			Amount:      decimal.NewFromFloat(line.Tax),
+			RatePercent: rate.Shift(2),
+		})
+	}
+	return taxes, nil
+}
+
+// Commit creates or adjusts a committed SalesInvoice transaction whose lines
+// override the tax with the tax they were invoiced with
+func (c *avalaraClient) Commit(ctx context.Context, doc *Document) error {
+	txn := c.transaction(doc, "SalesInvoice")
+	txn.Commit = true
+	for i, line := range doc.Lines {
+		txn.Lines[i].TaxOverride = &avalaraTaxOverride{
+			Type:      "TaxAmount",
+			TaxAmount: float(line.Tax),
+			Reason:    "Tax invoiced",
+		}
+	}
+
+	body := map[string]interface{}{"createTransactionModel": txn}
+	return c.do(ctx, http.MethodPost, "/api/v2/transactions/createoradjust", body, nil)
+}
+
+func (c *avalaraClient) Void(ctx context.Context, code string) error {
+	resource := fmt.Sprintf("/api/v2/companies/%s/transactions/%s/void", url.PathEscape(c.companyCode), url.PathEscape(code))
+	err := c.do(ctx, http.MethodPost, resource, map[string]string{"code": "DocVoided"}, nil)
+	if hasStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (c *avalaraClient) do(ctx context.Context, method, resource string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode avalara request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+resource, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.accountID, c.licenseKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: failed to call avalara: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if err := checkResponse(types.IntegrationProviderAvalara, resp, respBody); err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode avalara response: %w", err)
+	}
+	return nil
+}
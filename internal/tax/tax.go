@@ -0,0 +1,203 @@
+// Package tax calculates the taxes of the invoices with the tax providers,
+// Avalara and TaxJar, of the integration connections
+package tax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// ErrUnavailable is returned when the provider could not be reached or failed
+// to answer. The invoices are then taxed at the fallback rates of the connection
+var ErrUnavailable = errors.New("tax provider is unavailable")
+
+// ErrInvalidAddress is returned for addresses the provider could not resolve
+var ErrInvalidAddress = errors.New("address could not be resolved")
+
+// IsUnavailable reports whether the provider was unavailable or rate limited
+// the request, as opposed to rejecting it
+func IsUnavailable(err error) bool {
+	var rateLimited *syncqueue.RateLimitError
+	return errors.Is(err, ErrUnavailable) || errors.As(err, &rateLimited)
+}
+
+// Document is the tax transaction of an invoice
+type Document struct {
+	// Code identifies the transaction at the provider, it is the invoice ID
+	Code         string
+	CustomerCode string
+	Date         time.Time
+	Currency     string
+	ShipFrom     customer.Address
+	ShipTo       customer.Address
+	Lines        []Line
+}
+
+// Line is a charge of an invoice, discounts are lines with a negative amount
+type Line struct {
+	// ID is the ID of the line item
+	ID          string
+	Description string
+	Amount      decimal.Decimal
+	Quantity    decimal.Decimal
+	TaxCode     string
+	// Tax is the tax the line was invoiced with, it is only committed
+	Tax decimal.Decimal
+}
+
+// LineTax is the tax quoted for a line
+type LineTax struct {
+	LineID string
+	Amount decimal.Decimal
+	// RatePercent is the combined rate of the jurisdictions taxing the line
+	RatePercent decimal.Decimal
+}
+
+// Client calculates and records taxes with the tax provider of a connection
+type Client interface {
+	// ValidateAddress resolves an address to its standard form, or returns
+	// ErrInvalidAddress
+	ValidateAddress(ctx context.Context, address customer.Address) (*customer.Address, error)
+
+	// Quote calculates the taxes of the lines of the document without
+	// recording a transaction
+	Quote(ctx context.Context, doc *Document) ([]LineTax, error)
+
+	// Commit records the transaction of a finalized invoice with the taxes it
+	// was invoiced with, so that they are reported. Committing it again updates it
+	Commit(ctx context.Context, doc *Document) error
+
+	// Void voids the committed transaction of a voided invoice. Transactions
+	// that were never committed are left as is
+	Void(ctx context.Context, code string) error
+}
+
+// NewClient returns the client of the tax provider of the connection
+func NewClient(conn *connection.Connection) (Client, error) {
+	if conn.TaxSettings == nil {
+		return nil, fmt.Errorf("connection %s has no tax settings", conn.ID)
+	}
+	if conn.Credentials == "" {
+		return nil, fmt.Errorf("connection %s has no credentials", conn.ID)
+	}
+
+	switch conn.Provider {
+	case types.IntegrationProviderAvalara:
+		if conn.TaxSettings.AccountID == "" || conn.TaxSettings.CompanyCode == "" {
+			return nil, fmt.Errorf("connection %s has no account id or company code", conn.ID)
+		}
+		return newAvalaraClient(*conn.TaxSettings, conn.Credentials), nil
+	case types.IntegrationProviderTaxJar:
+		return newTaxJarClient(*conn.TaxSettings, conn.Credentials), nil
+	default:
+		return nil, fmt.Errorf("provider %s is not a tax provider", conn.Provider)
+	}
+}
+
+// BuildDocument maps an invoice to its tax transaction. Its charges are the
+// lines, with the taxes quoted for them on the invoice. Invoices with manual
+// taxes are not taxed by the providers
+func BuildDocument(inv *invoice.Invoice, cust *customer.Customer, settings connection.TaxSettings) (*Document, error) {
+	if settings.ShipFrom == nil {
+		return nil, fmt.Errorf("tax settings have no ship from address")
+	}
+	if cust.BillingAddress == nil {
+		return nil, fmt.Errorf("customer %s has no billing address", cust.ID)
+	}
+
+	customerCode := cust.ExternalID
+	if customerCode == "" {
+		customerCode = cust.ID
+	}
+
+	date := inv.CreatedAt
+	if inv.FinalizedAt != nil {
+		date = *inv.FinalizedAt
+	}
+
+	doc := &Document{
+		Code:         inv.ID,
+		CustomerCode: customerCode,
+		Date:         date,
+		Currency:     inv.Currency,
+		ShipFrom:     *settings.ShipFrom,
+		ShipTo:       *cust.BillingAddress,
+	}
+
+	taxes := make(map[string]decimal.Decimal)
+	for _, item := range inv.LineItems {
+		switch source := item.TaxSource(); {
+		case source.IsQuoted():
+			id := item.Metadata[invoice.MetadataTaxedLineItemID]
+			taxes[id] = taxes[id].Add(item.Amount)
+			continue
+		case source != "":
+			return nil, fmt.Errorf("invoice %s has %s taxes", inv.ID, source)
+		}
+
+		doc.Lines = append(doc.Lines, Line{
+			ID:          item.ID,
+			Description: item.DisplayName,
+			Amount:      item.Amount,
+			Quantity:    item.Quantity,
+			TaxCode:     settings.TaxCode(item.PriceID),
+		})
+	}
+	for i := range doc.Lines {
+		doc.Lines[i].Tax = taxes[doc.Lines[i].ID]
+	}
+
+	return doc, nil
+}
+
+// responseError is a response of a provider rejecting a request
+type responseError struct {
+	provider types.IntegrationProvider
+	status   int
+	body     []byte
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("%s responded with status %d: %s", e.provider, e.status, e.body)
+}
+
+// checkResponse maps the non 2xx responses of a provider to errors: rate
+// limits make the sync queue of the connection back off, server errors make
+// the provider unavailable
+func checkResponse(provider types.IntegrationProvider, resp *http.Response, body []byte) error {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return &syncqueue.RateLimitError{RetryAfter: retryAfter}
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("%w: %s responded with status %d: %s", ErrUnavailable, provider, resp.StatusCode, body)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return &responseError{provider: provider, status: resp.StatusCode, body: body}
+	}
+	return nil
+}
+
+// hasStatus reports whether the error is a response of the provider with the status
+func hasStatus(err error, status int) bool {
+	var rejected *responseError
+	return errors.As(err, &rejected) && rejected.status == status
+}
+
+func float(d decimal.Decimal) float64 {
+	f, _ := d.Float64()
+	return f
+}
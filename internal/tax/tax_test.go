@@ -0,0 +1,279 @@
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/syncqueue"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	shipFrom = customer.Address{Line1: "100 Market St", City: "San Francisco", State: "CA", PostalCode: "94105", Country: "US"}
+	shipTo   = customer.Address{Line1: "350 5th Ave", City: "New York", State: "NY", PostalCode: "10118", Country: "US"}
+)
+
+func testDocument() *Document {
+	return &Document{
+		Code:         "inv_1",
+		CustomerCode: "acme",
+		Date:         time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Currency:     "usd",
+		ShipFrom:     shipFrom,
+		ShipTo:       shipTo,
+		Lines: []Line{
+			{ID: "li_1", Description: "Seats", Amount: decimal.NewFromInt(300), Quantity: decimal.NewFromInt(3), TaxCode: "SW054000", Tax: decimal.RequireFromString("26.63")},
+			{ID: "li_2", Description: "Support", Amount: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1), Tax: decimal.RequireFromString("8.87")},
+			{ID: "li_3", Description: "Discount", Amount: decimal.NewFromInt(-100), Quantity: decimal.NewFromInt(1)},
+		},
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	settings := &connection.TaxSettings{AccountID: "1100012345", CompanyCode: "ACME", Sandbox: true, ShipFrom: &shipFrom}
+	client, err := NewClient(&connection.Connection{Provider: types.IntegrationProviderAvalara, TaxSettings: settings, Credentials: "license"})
+	require.NoError(t, err)
+	require.IsType(t, &avalaraClient{}, client)
+	assert.Equal(t, avalaraSandboxURL, client.(*avalaraClient).baseURL)
+
+	client, err = NewClient(&connection.Connection{Provider: types.IntegrationProviderTaxJar, TaxSettings: &connection.TaxSettings{ShipFrom: &shipFrom}, Credentials: "token"})
+	require.NoError(t, err)
+	require.IsType(t, &taxJarClient{}, client)
+	assert.Equal(t, taxJarURL, client.(*taxJarClient).baseURL)
+
+	_, err = NewClient(&connection.Connection{Provider: types.IntegrationProviderAvalara, TaxSettings: &connection.TaxSettings{}, Credentials: "license"})
+	assert.Error(t, err)
+	_, err = NewClient(&connection.Connection{Provider: types.IntegrationProviderStripe, TaxSettings: settings, Credentials: "sk_test"})
+	assert.Error(t, err)
+}
+
+func TestBuildDocument(t *testing.T) {
+	finalizedAt := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	inv := &invoice.Invoice{
+		ID:          "inv_1",
+		Currency:    "usd",
+		FinalizedAt: &finalizedAt,
+		LineItems: []*invoice.InvoiceLineItem{
+			{ID: "li_1", PriceID: "price_seats", DisplayName: "Seats", Amount: decimal.NewFromInt(300), Quantity: decimal.NewFromInt(3)},
+			{ID: "li_2", DisplayName: "Support", Amount: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(1)},
+			{ID: "li_3", DisplayName: "Tax on Seats", Amount: decimal.RequireFromString("26.63"), Metadata: types.Metadata{
+				invoice.MetadataTaxSource:       string(types.TaxSourceProvider),
+				invoice.MetadataTaxedLineItemID: "li_1",
+			}},
+		},
+	}
+	cust := &customer.Customer{ID: "cust_1", ExternalID: "acme", BillingAddress: &shipTo}
+	settings := connection.TaxSettings{ShipFrom: &shipFrom, DefaultTaxCode: "P0000000", TaxCodes: map[string]string{"price_seats": "SW054000"}}
+
+	doc, err := BuildDocument(inv, cust, settings)
+	require.NoError(t, err)
+	assert.Equal(t, "inv_1", doc.Code)
+	assert.Equal(t, "acme", doc.CustomerCode)
+	assert.Equal(t, finalizedAt, doc.Date)
+	assert.Equal(t, shipTo, doc.ShipTo)
+	require.Len(t, doc.Lines, 2)
+	assert.Equal(t, "SW054000", doc.Lines[0].TaxCode)
+	assert.True(t, decimal.RequireFromString("26.63").Equal(doc.Lines[0].Tax))
+	assert.Equal(t, "P0000000", doc.Lines[1].TaxCode)
+	assert.True(t, doc.Lines[1].Tax.IsZero())
+
+	_, err = BuildDocument(inv, &customer.Customer{ID: "cust_2"}, settings)
+	assert.Error(t, err)
+
+	inv.LineItems[2].Metadata[invoice.MetadataTaxSource] = string(types.TaxSourceManual)
+	_, err = BuildDocument(inv, cust, settings)
+	assert.Error(t, err)
+}
+
+func TestAvalaraClient(t *testing.T) {
+	var paths []string
+	var transactions []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		accountID, licenseKey, _ := r.BasicAuth()
+		assert.Equal(t, "1100012345", accountID)
+		assert.Equal(t, "license", licenseKey)
+
+		switch r.URL.Path {
+		case "/api/v2/addresses/resolve":
+			var address map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&address))
+			if address["line1"] == "nowhere" {
+				_, _ = w.Write([]byte(`{"messages":[{"severity":"Error","summary":"The address is not deliverable."}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"validatedAddresses":[{"line1":"350 5TH AVE","city":"NEW YORK","region":"NY","postalCode":"10118-0110","country":"US"}]}`))
+		case "/api/v2/transactions/create", "/api/v2/transactions/createoradjust":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			transactions = append(transactions, body)
+			_, _ = w.Write([]byte(`{"lines":[
+				{"lineNumber":"li_1","tax":26.63,"details":[{"rate":0.04},{"rate":0.04875}]},
+				{"lineNumber":"li_2","tax":8.88,"details":[{"rate":0.04},{"rate":0.04875}]},
+				{"lineNumber":"li_3","tax":-8.88,"details":[{"rate":0.04},{"rate":0.04875}]}]}`))
+		case "/api/v2/companies/ACME/transactions/inv_1/void":
+			_, _ = w.Write([]byte(`{}`))
+		case "/api/v2/companies/ACME/transactions/inv_2/void":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := newAvalaraClient(connection.TaxSettings{AccountID: "1100012345", CompanyCode: "ACME"}, "license")
+	assert.Equal(t, avalaraURL, client.baseURL)
+	client.baseURL = server.URL
+	ctx := context.Background()
+
+	address, err := client.ValidateAddress(ctx, shipTo)
+	require.NoError(t, err)
+	assert.Equal(t, &customer.Address{Line1: "350 5TH AVE", City: "NEW YORK", State: "NY", PostalCode: "10118-0110", Country: "US"}, address)
+
+	_, err = client.ValidateAddress(ctx, customer.Address{Line1: "nowhere", City: "Nowhere", Country: "US"})
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+
+	taxes, err := client.Quote(ctx, testDocument())
+	require.NoError(t, err)
+	require.Len(t, taxes, 3)
+	assert.Equal(t, "li_1", taxes[0].LineID)
+	assert.True(t, decimal.RequireFromString("26.63").Equal(taxes[0].Amount))
+	assert.True(t, decimal.RequireFromString("8.875").Equal(taxes[0].RatePercent))
+	assert.Equal(t, "SalesOrder", transactions[0]["type"])
+	assert.Equal(t, false, transactions[0]["commit"])
+
+	require.NoError(t, client.Commit(ctx, testDocument()))
+	committed := transactions[1]["createTransactionModel"].(map[string]interface{})
+	assert.Equal(t, "SalesInvoice", committed["type"])
+	assert.Equal(t, true, committed["commit"])
+	assert.Equal(t, "inv_1", committed["code"])
+	assert.Equal(t, "2026-03-01", committed["date"])
+	line := committed["lines"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "TaxAmount", "taxAmount": 26.63, "reason": "Tax invoiced"}, line["taxOverride"])
+
+	require.NoError(t, client.Void(ctx, "inv_1"))
+	require.NoError(t, client.Void(ctx, "inv_2"))
+
+	assert.Equal(t, []string{
+		"POST /api/v2/addresses/resolve", "POST /api/v2/addresses/resolve",
+		"POST /api/v2/transactions/create", "POST /api/v2/transactions/createoradjust",
+		"POST /api/v2/companies/ACME/transactions/inv_1/void", "POST /api/v2/companies/ACME/transactions/inv_2/void",
+	}, paths)
+}
+
+func TestTaxJarClient(t *testing.T) {
+	var paths []string
+	var orders []taxJarOrder
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /v2/addresses/validate":
+			_, _ = w.Write([]byte(`{"addresses":[{"zip":"10118-0110","street":"350 5th Ave","state":"NY","country":"US","city":"New York"}]}`))
+		case "POST /v2/taxes":
+			var order taxJarOrder
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&order))
+			orders = append(orders, order)
+			_, _ = w.Write([]byte(`{"tax":{"amount_to_collect":26.63,"breakdown":{"line_items":[
+				{"id":"li_1","tax_collectable":19.97,"combined_tax_rate":0.08875},
+				{"id":"li_2","tax_collectable":6.66,"combined_tax_rate":0.08875}]}}}`))
+		case "POST /v2/transactions/orders":
+			var order taxJarOrder
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&order))
+			orders = append(orders, order)
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"error":"Unprocessable Entity","detail":"Provided transaction_id already exists"}`))
+		case "PUT /v2/transactions/orders/inv_1":
+			_, _ = w.Write([]byte(`{}`))
+		case "DELETE /v2/transactions/orders/inv_1":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	client := newTaxJarClient(connection.TaxSettings{Sandbox: true}, "token")
+	assert.Equal(t, taxJarSandboxURL, client.baseURL)
+	client.baseURL = server.URL
+	ctx := context.Background()
+
+	address, err := client.ValidateAddress(ctx, shipTo)
+	require.NoError(t, err)
+	assert.Equal(t, "10118-0110", address.PostalCode)
+
+	// Only US addresses are validated
+	berlin := customer.Address{Line1: "Unter den Linden 1", City: "Berlin", PostalCode: "10117", Country: "DE"}
+	address, err = client.ValidateAddress(ctx, berlin)
+	require.NoError(t, err)
+	assert.Equal(t, &berlin, address)
+
+	taxes, err := client.Quote(ctx, testDocument())
+	require.NoError(t, err)
+	require.Len(t, taxes, 2)
+	assert.True(t, decimal.RequireFromString("19.97").Equal(taxes[0].Amount))
+	assert.True(t, decimal.RequireFromString("8.875").Equal(taxes[1].RatePercent))
+
+	// The discount is spread over the charges
+	quoted := orders[0]
+	assert.Equal(t, float64(300), quoted.Amount)
+	require.Len(t, quoted.LineItems, 2)
+	assert.Equal(t, float64(75), quoted.LineItems[0].Discount)
+	assert.Equal(t, float64(25), quoted.LineItems[1].Discount)
+	assert.Zero(t, quoted.LineItems[0].SalesTax)
+
+	require.NoError(t, client.Commit(ctx, testDocument()))
+	committed := orders[1]
+	assert.Equal(t, "inv_1", committed.TransactionID)
+	assert.Equal(t, "2026-03-01", committed.TransactionDate)
+	require.NotNil(t, committed.SalesTax)
+	assert.Equal(t, 35.5, *committed.SalesTax)
+	assert.Equal(t, 26.63, committed.LineItems[0].SalesTax)
+
+	require.NoError(t, client.Void(ctx, "inv_1"))
+
+	err = client.Void(ctx, "inv_2")
+	assert.True(t, IsUnavailable(err))
+	var rateLimited *syncqueue.RateLimitError
+	assert.ErrorAs(t, err, &rateLimited)
+
+	assert.Equal(t, []string{
+		"POST /v2/addresses/validate", "POST /v2/taxes",
+		"POST /v2/transactions/orders", "PUT /v2/transactions/orders/inv_1",
+		"DELETE /v2/transactions/orders/inv_1", "DELETE /v2/transactions/orders/inv_2",
+	}, paths)
+}
+
+func TestIsUnavailable(t *testing.T) {
+	client := newTaxJarClient(connection.TaxSettings{}, "token")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/taxes" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	client.baseURL = server.URL
+
+	_, err := client.Quote(context.Background(), testDocument())
+	assert.True(t, IsUnavailable(err))
+
+	err = client.Void(context.Background(), "inv_1")
+	assert.Error(t, err)
+	assert.False(t, IsUnavailable(err))
+
+	server.Close()
+	_, err = client.Quote(context.Background(), testDocument())
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
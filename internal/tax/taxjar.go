@@ -0,0 +1,278 @@
+package tax
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/domain/customer"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	taxJarURL        = "https://api.taxjar.com"
+	taxJarSandboxURL = "https://api.sandbox.taxjar.com"
+)
+
+type taxJarClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func newTaxJarClient(settings connection.TaxSettings, token string) *taxJarClient {
+	baseURL := taxJarURL
+	if settings.Sandbox {
+		baseURL = taxJarSandboxURL
+	}
+	return &taxJarClient{
+		baseURL: baseURL,
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type taxJarLineItem struct {
+	ID             string  `json:"id"`
+	Quantity       float64 `json:"quantity"`
+	UnitPrice      float64 `json:"unit_price"`
+	Discount       float64 `json:"discount"`
+	ProductTaxCode string  `json:"product_tax_code,omitempty"`
+	Description    string  `json:"description,omitempty"`
+	SalesTax       float64 `json:"sales_tax,omitempty"`
+}
+
+type taxJarOrder struct {
+	TransactionID   string           `json:"transaction_id,omitempty"`
+	TransactionDate string           `json:"transaction_date,omitempty"`
+	CustomerID      string           `json:"customer_id,omitempty"`
+	FromCountry     string           `json:"from_country"`
+	FromZip         string           `json:"from_zip"`
+	FromState       string           `json:"from_state"`
+	FromCity        string           `json:"from_city"`
+	FromStreet      string           `json:"from_street"`
+	ToCountry       string           `json:"to_country"`
+	ToZip           string           `json:"to_zip"`
+	ToState         string           `json:"to_state"`
+	ToCity          string           `json:"to_city"`
+	ToStreet        string           `json:"to_street"`
+	Amount          float64          `json:"amount"`
+	Shipping        float64          `json:"shipping"`
+	SalesTax        *float64         `json:"sales_tax,omitempty"`
+	LineItems       []taxJarLineItem `json:"line_items"`
+}
+
+// order maps the document to a TaxJar order. TaxJar has no negative line
+// items, the discounts are spread over the charges pro rata to their amount.
+// The charges are sent as a quantity of one so that their amount is not rounded
+func (c *taxJarClient) order(doc *Document) *taxJarOrder {
+	order := &taxJarOrder{
+		FromCountry: doc.ShipFrom.Country,
+		FromZip:     doc.ShipFrom.PostalCode,
+		FromState:   doc.ShipFrom.State,
+		FromCity:    doc.ShipFrom.City,
+		FromStreet:  doc.ShipFrom.Line1,
+		ToCountry:   doc.ShipTo.Country,
+		ToZip:       doc.ShipTo.PostalCode,
+		ToState:     doc.ShipTo.State,
+		ToCity:      doc.ShipTo.City,
+		ToStreet:    doc.ShipTo.Line1,
+	}
+
+	charged, discounted := decimal.Zero, decimal.Zero
+	for _, line := range doc.Lines {
+		if line.Amount.IsNegative() {
+			discounted = discounted.Sub(line.Amount)
+		} else {
+			charged = charged.Add(line.Amount)
+		}
+	}
+	discounted = decimal.Min(discounted, charged)
+
+	remaining := discounted
+	last := -1
+	for _, line := range doc.Lines {
+		if line.Amount.IsNegative() {
+			continue
+		}
+
+		discount := decimal.Zero
+		if charged.IsPositive() {
+			discount = discounted.Mul(line.Amount).Div(charged).Round(2)
+		}
+		remaining = remaining.Sub(discount)
+
+		order.LineItems = append(order.LineItems, taxJarLineItem{
+			ID:             line.ID,
+			Quantity:       1,
+			UnitPrice:      float(line.Amount),
+			Discount:       float(discount),
+			ProductTaxCode: line.TaxCode,
+			Description:    line.Description,
+			SalesTax:       float(line.Tax),
+		})
+		last = len(order.LineItems) - 1
+	}
+	if last >= 0 && !remaining.IsZero() {
+		order.LineItems[last].Discount = float(decimal.NewFromFloat(order.LineItems[last].Discount).Add(remaining))
+	}
+
+	order.Amount = float(charged.Sub(discounted))
+	return order
+}
+
+// ValidateAddress validates US addresses, TaxJar does not validate the
+// addresses of other countries which are returned as is
+func (c *taxJarClient) ValidateAddress(ctx context.Context, address customer.Address) (*customer.Address, error) {
+	if !strings.EqualFold(address.Country, "US") {
+		return &address, nil
+	}
+
+	body := map[string]string{
+		"country": address.Country,
+		"state":   address.State,
+		"zip":     address.PostalCode,
+		"city":    address.City,
+		"street":  strings.TrimSpace(address.Line1 + " " + address.Line2),
+	}
+	var result struct {
+		Addresses []struct {
+			Zip     string `json:"zip"`
+			Street  string `json:"street"`
+			State   string `json:"state"`
+			Country string `json:"country"`
+			City    string `json:"city"`
+		} `json:"addresses"`
+	}
+	err := c.do(ctx, http.MethodPost, "/v2/addresses/validate", body, &result)
+	if hasStatus(err, http.StatusNotFound) {
+		return nil, ErrInvalidAddress
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Addresses) == 0 {
+		return nil, ErrInvalidAddress
+	}
+
+	resolved := result.Addresses[0]
+	return &customer.Address{
+		Line1:      resolved.Street,
+		City:       resolved.City,
+		State:      resolved.State,
+		PostalCode: resolved.Zip,
+		Country:    resolved.Country,
+	}, nil
+}
+
+// Quote calculates the sales tax of the order with the taxes endpoint. Lines
+// without a breakdown, such as discounts, are not taxed
+func (c *taxJarClient) Quote(ctx context.Context, doc *Document) ([]LineTax, error) {
+	order := c.order(doc)
+	for i := range order.LineItems {
+		order.LineItems[i].SalesTax = 0
+	}
+
+	var result struct {
+		Tax struct {
+			Breakdown *struct {
+				LineItems []struct {
+					ID              string  `json:"id"`
+					TaxCollectable  float64 `json:"tax_collectable"`
+					CombinedTaxRate float64 `json:"combined_tax_rate"`
+				} `json:"line_items"`
+			} `json:"breakdown"`
+		} `json:"tax"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v2/taxes", order, &result); err != nil {
+		return nil, err
+	}
+
+	var taxes []LineTax
+	if result.Tax.Breakdown == nil {
+		return taxes, nil
+	}
+	for _, line := range result.Tax.Breakdown.LineItems {
+		taxes = append(taxes, LineTax{
+			LineID:      line.ID,
+			Amount:      decimal.NewFromFloat(line.TaxCollectable),
+			RatePercent: decimal.NewFromFloat(line.CombinedTaxRate).Shift(2),
+		})
+	}
+	return taxes, nil
+}
+
+// Commit creates the order transaction, or updates it when it was created
+// before
+func (c *taxJarClient) Commit(ctx context.Context, doc *Document) error {
+	order := c.order(doc)
+	order.TransactionID = doc.Code
+	order.TransactionDate = doc.Date.Format("2006-01-02")
+	order.CustomerID = doc.CustomerCode
+
+	salesTax := decimal.Zero
+	for _, line := range doc.Lines {
+		salesTax = salesTax.Add(line.Tax)
+	}
+	total := float(salesTax)
+	order.SalesTax = &total
+
+	err := c.do(ctx, http.MethodPost, "/v2/transactions/orders", order, nil)
+	if hasStatus(err, http.StatusUnprocessableEntity) {
+		return c.do(ctx, http.MethodPut, "/v2/transactions/orders/"+url.PathEscape(doc.Code), order, nil)
+	}
+	return err
+}
+
+func (c *taxJarClient) Void(ctx context.Context, code string) error {
+	err := c.do(ctx, http.MethodDelete, "/v2/transactions/orders/"+url.PathEscape(code), nil, nil)
+	if hasStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (c *taxJarClient) do(ctx context.Context, method, resource string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode taxjar request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+resource, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: failed to call taxjar: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if err := checkResponse(types.IntegrationProviderTaxJar, resp, respBody); err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode taxjar response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package testutil
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/anomaly"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryAnomalyStore implements anomaly.Repository
+type InMemoryAnomalyStore struct {
+	mu        sync.RWMutex
+	anomalies []*anomaly.Anomaly
+}
+
+func NewInMemoryAnomalyStore() *InMemoryAnomalyStore {
+	return &InMemoryAnomalyStore{}
+}
+
+func (s *InMemoryAnomalyStore) Create(ctx context.Context, a *anomaly.Anomaly) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.anomalies {
+		if existing.TenantID == a.TenantID && existing.MeterID == a.MeterID &&
+			existing.ExternalCustomerID == a.ExternalCustomerID && existing.WindowStart.Equal(a.WindowStart) {
+			return nil
+		}
+	}
+	s.anomalies = append(s.anomalies, a)
+	return nil
+}
+
+func (s *InMemoryAnomalyStore) Exists(ctx context.Context, meterID, externalCustomerID string, windowStart time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, a := range s.anomalies {
+		if a.TenantID == types.GetTenantID(ctx) && a.MeterID == meterID &&
+			a.ExternalCustomerID == externalCustomerID && a.WindowStart.Equal(windowStart) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *InMemoryAnomalyStore) List(ctx context.Context, filter *types.AnomalyFilter) ([]*anomaly.Anomaly, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*anomaly.Anomaly
+	for _, a := range s.anomalies {
+		if a.TenantID != types.GetTenantID(ctx) {
+			continue
+		}
+		if filter.MeterID != "" && a.MeterID != filter.MeterID {
+			continue
+		}
+		if filter.ExternalCustomerID != "" && a.ExternalCustomerID != filter.ExternalCustomerID {
+			continue
+		}
+		if filter.AnomalyType != "" && a.AnomalyType != filter.AnomalyType {
+			continue
+		}
+		if filter.StartTime != nil && a.WindowStart.Before(*filter.StartTime) {
+			continue
+		}
+		if filter.EndTime != nil && !a.WindowStart.Before(*filter.EndTime) {
+			continue
+		}
+		result = append(result, a)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].WindowStart.After(result[j].WindowStart)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/audit"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryAuditLogStore implements audit.Repository
+type InMemoryAuditLogStore struct {
+	mu   sync.RWMutex
+	logs []*audit.Log
+}
+
+func NewInMemoryAuditLogStore() *InMemoryAuditLogStore {
+	return &InMemoryAuditLogStore{}
+}
+
+func (s *InMemoryAuditLogStore) Insert(ctx context.Context, log *audit.Log) error {
+	if log == nil {
+		return fmt.Errorf("audit log cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logs = append(s.logs, log)
+	return nil
+}
+
+func (s *InMemoryAuditLogStore) List(ctx context.Context, filter *types.AuditLogFilter) ([]*audit.Log, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	environmentID := types.GetEnvironmentID(ctx)
+
+	var result []*audit.Log
+	for _, log := range s.logs {
+		if log.TenantID != types.GetTenantID(ctx) {
+			continue
+		}
+		if environmentID != "" && log.EnvironmentID != environmentID {
+			continue
+		}
+		if filter.EntityType != "" && log.EntityType != filter.EntityType {
+			continue
+		}
+		if filter.EntityID != "" && log.EntityID != filter.EntityID {
+			continue
+		}
+		if filter.Action != "" && log.Action != filter.Action {
+			continue
+		}
+		if filter.ActorID != "" && log.ActorID != filter.ActorID {
+			continue
+		}
+		if filter.StartTime != nil && log.CreatedAt.Before(*filter.StartTime) {
+			continue
+		}
+		if filter.EndTime != nil && !log.CreatedAt.Before(*filter.EndTime) {
+			continue
+		}
+		result = append(result, log)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	if filter.Limit > 0 {
+		start := filter.Offset
+		if start >= len(result) {
+			return []*audit.Log{}, nil
+		}
+
+		end := start + filter.Limit
+		if end > len(result) {
+			end = len(result)
+		}
+
+		result = result[start:end]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/budget"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryBudgetStore implements budget.Repository
+type InMemoryBudgetStore struct {
+	mu      sync.RWMutex
+	budgets map[string]*budget.Budget
+}
+
+func NewInMemoryBudgetStore() *InMemoryBudgetStore {
+	return &InMemoryBudgetStore{
+		budgets: make(map[string]*budget.Budget),
+	}
+}
+
+func (s *InMemoryBudgetStore) Create(ctx context.Context, b *budget.Budget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.budgets[b.ID]; exists {
+		return fmt.Errorf("budget already exists")
+	}
+	copied := *b
+	s.budgets[b.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryBudgetStore) Get(ctx context.Context, id string) (*budget.Budget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if b, exists := s.budgets[id]; exists && b.TenantID == types.GetTenantID(ctx) && b.Status == types.StatusPublished {
+		copied := *b
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("budget not found")
+}
+
+func (s *InMemoryBudgetStore) ListByCustomer(ctx context.Context, customerID string) ([]*budget.Budget, error) {
+	return s.list(ctx, func(b *budget.Budget) bool { return b.CustomerID == customerID })
+}
+
+func (s *InMemoryBudgetStore) List(ctx context.Context) ([]*budget.Budget, error) {
+	return s.list(ctx, func(b *budget.Budget) bool { return true })
+}
+
+func (s *InMemoryBudgetStore) list(ctx context.Context, match func(*budget.Budget) bool) ([]*budget.Budget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*budget.Budget
+	for _, b := range s.budgets {
+		if b.TenantID == types.GetTenantID(ctx) && b.Status == types.StatusPublished && match(b) {
+			copied := *b
+			result = append(result, &copied)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryBudgetStore) Update(ctx context.Context, b *budget.Budget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.budgets[b.ID]
+	if !exists || existing.TenantID != types.GetTenantID(ctx) || existing.Status != types.StatusPublished {
+		return fmt.Errorf("budget not found")
+	}
+	copied := *b
+	s.budgets[b.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryBudgetStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.budgets[id]
+	if !exists || b.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("budget not found")
+	}
+	b.Status = types.StatusDeleted
+	return nil
+}
@@ -0,0 +1,79 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/cancellationreason"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryCancellationReasonStore implements cancellationreason.Repository
+type InMemoryCancellationReasonStore struct {
+	mu      sync.RWMutex
+	reasons map[string]*cancellationreason.CancellationReason
+}
+
+func NewInMemoryCancellationReasonStore() *InMemoryCancellationReasonStore {
+	return &InMemoryCancellationReasonStore{
+		reasons: make(map[string]*cancellationreason.CancellationReason),
+	}
+}
+
+func (s *InMemoryCancellationReasonStore) Create(ctx context.Context, reason *cancellationreason.CancellationReason) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.reasons[reason.ID]; exists {
+		return fmt.Errorf("cancellation reason already exists")
+	}
+	for _, existing := range s.reasons {
+		if existing.TenantID == reason.TenantID && existing.Status == types.StatusPublished && existing.Code == reason.Code {
+			return fmt.Errorf("cancellation reason %s already exists", reason.Code)
+		}
+	}
+	s.reasons[reason.ID] = reason
+	return nil
+}
+
+func (s *InMemoryCancellationReasonStore) Get(ctx context.Context, id string) (*cancellationreason.CancellationReason, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if reason, exists := s.reasons[id]; exists && reason.TenantID == types.GetTenantID(ctx) && reason.Status == types.StatusPublished {
+		return reason, nil
+	}
+	return nil, fmt.Errorf("cancellation reason not found")
+}
+
+func (s *InMemoryCancellationReasonStore) List(ctx context.Context) ([]*cancellationreason.CancellationReason, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*cancellationreason.CancellationReason
+	for _, reason := range s.reasons {
+		if reason.TenantID == types.GetTenantID(ctx) && reason.Status == types.StatusPublished {
+			result = append(result, reason)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryCancellationReasonStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reason, exists := s.reasons[id]
+	if !exists || reason.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("cancellation reason not found")
+	}
+	reason.Status = types.StatusDeleted
+	return nil
+}
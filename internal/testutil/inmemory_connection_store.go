@@ -0,0 +1,86 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/connection"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryConnectionStore implements connection.Repository
+type InMemoryConnectionStore struct {
+	mu          sync.RWMutex
+	connections map[string]*connection.Connection
+}
+
+func NewInMemoryConnectionStore() *InMemoryConnectionStore {
+	return &InMemoryConnectionStore{
+		connections: make(map[string]*connection.Connection),
+	}
+}
+
+func (s *InMemoryConnectionStore) Create(ctx context.Context, conn *connection.Connection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.connections[conn.ID]; exists {
+		return fmt.Errorf("connection already exists")
+	}
+	s.connections[conn.ID] = conn
+	return nil
+}
+
+func (s *InMemoryConnectionStore) Get(ctx context.Context, id string) (*connection.Connection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if conn, exists := s.connections[id]; exists && conn.TenantID == types.GetTenantID(ctx) {
+		return conn, nil
+	}
+	return nil, fmt.Errorf("connection not found")
+}
+
+func (s *InMemoryConnectionStore) List(ctx context.Context, filter types.Filter) ([]*connection.Connection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*connection.Connection
+	for _, conn := range s.connections {
+		if conn.TenantID == types.GetTenantID(ctx) {
+			result = append(result, conn)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryConnectionStore) ListByProviders(ctx context.Context, providers []types.IntegrationProvider) ([]*connection.Connection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*connection.Connection
+	for _, conn := range s.connections {
+		if conn.TenantID != types.GetTenantID(ctx) {
+			continue
+		}
+		for _, p := range providers {
+			if conn.Provider == p {
+				result = append(result, conn)
+				break
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/flexprice/flexprice/internal/domain/customer"
 	"github.com/flexprice/flexprice/internal/types"
@@ -49,12 +50,33 @@ func (s *InMemoryCustomerStore) Get(ctx context.Context, id string) (*customer.C
 	return nil, fmt.Errorf("customer not found")
 }
 
+func (s *InMemoryCustomerStore) ListByExternalIDs(ctx context.Context, externalIDs []string) ([]*customer.Customer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(externalIDs))
+	for _, id := range externalIDs {
+		wanted[id] = true
+	}
+
+	var result []*customer.Customer
+	for _, c := range s.customers {
+		if wanted[c.ExternalID] && c.TenantID == types.GetTenantID(ctx) && c.Status == types.StatusPublished {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
 func (s *InMemoryCustomerStore) List(ctx context.Context, filter types.Filter) ([]*customer.Customer, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var result []*customer.Customer
 	for _, c := range s.customers {
+		if c.Status == types.StatusDeleted && !filter.IncludeDeleted {
+			continue
+		}
 		result = append(result, c)
 	}
 
@@ -97,10 +119,47 @@ func (s *InMemoryCustomerStore) Delete(ctx context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.customers[id]; !exists {
+	c, exists := s.customers[id]
+	if !exists {
+		return fmt.Errorf("customer not found")
+	}
+
+	now := time.Now().UTC()
+	c.Status = types.StatusDeleted
+	c.DeletedAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+func (s *InMemoryCustomerStore) Restore(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, exists := s.customers[id]
+	if !exists {
 		return fmt.Errorf("customer not found")
 	}
 
-	delete(s.customers, id)
+	if c.Status == types.StatusDeleted {
+		c.Status = types.StatusPublished
+		c.DeletedAt = nil
+		c.UpdatedAt = time.Now().UTC()
+	}
 	return nil
 }
+
+// PurgeDeleted removes the customers deleted before the given time. The store does
+// not know the records referring to them, so none is kept
+func (s *InMemoryCustomerStore) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, c := range s.customers {
+		if c.Status == types.StatusDeleted && c.DeletedAt != nil && c.DeletedAt.Before(before) {
+			delete(s.customers, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,103 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/deadletter"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryDeadLetterStore implements deadletter.Repository
+type InMemoryDeadLetterStore struct {
+	mu          sync.RWMutex
+	deadLetters map[string]*deadletter.DeadLetter
+}
+
+func NewInMemoryDeadLetterStore() *InMemoryDeadLetterStore {
+	return &InMemoryDeadLetterStore{
+		deadLetters: make(map[string]*deadletter.DeadLetter),
+	}
+}
+
+func (s *InMemoryDeadLetterStore) Record(ctx context.Context, deadLetter *deadletter.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.deadLetters {
+		if existing.TenantID == deadLetter.TenantID && existing.Topic == deadLetter.Topic && existing.MessageID == deadLetter.MessageID {
+			existing.Payload = deadLetter.Payload
+			existing.Error = deadLetter.Error
+			existing.Attempts++
+			existing.DeadLetterStatus = deadLetter.DeadLetterStatus
+			existing.LastFailedAt = deadLetter.LastFailedAt
+			existing.ReplayedAt = nil
+			existing.UpdatedAt = deadLetter.UpdatedAt
+			existing.UpdatedBy = deadLetter.UpdatedBy
+			return nil
+		}
+	}
+
+	copied := *deadLetter
+	s.deadLetters[deadLetter.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryDeadLetterStore) Get(ctx context.Context, id string) (*deadletter.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if deadLetter, exists := s.deadLetters[id]; exists && deadLetter.TenantID == types.GetTenantID(ctx) && deadLetter.Status == types.StatusPublished {
+		copied := *deadLetter
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("dead letter not found")
+}
+
+func (s *InMemoryDeadLetterStore) List(ctx context.Context, filter *types.DeadLetterFilter) ([]*deadletter.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*deadletter.DeadLetter
+	for _, d := range s.deadLetters {
+		if d.TenantID != types.GetTenantID(ctx) || d.Status != types.StatusPublished {
+			continue
+		}
+		if filter.DeadLetterStatus != "" && d.DeadLetterStatus != filter.DeadLetterStatus {
+			continue
+		}
+		if filter.Topic != "" && d.Topic != filter.Topic {
+			continue
+		}
+		deadLetter := *d
+		result = append(result, &deadLetter)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastFailedAt.After(result[j].LastFailedAt)
+	})
+
+	if filter.Offset >= len(result) {
+		return []*deadletter.DeadLetter{}, nil
+	}
+	end := len(result)
+	if filter.Limit > 0 && filter.Offset+filter.Limit < end {
+		end = filter.Offset + filter.Limit
+	}
+	return result[filter.Offset:end], nil
+}
+
+func (s *InMemoryDeadLetterStore) Update(ctx context.Context, deadLetter *deadletter.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.deadLetters[deadLetter.ID]
+	if !exists || existing.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("dead letter not found")
+	}
+	copied := *deadLetter
+	s.deadLetters[deadLetter.ID] = &copied
+	return nil
+}
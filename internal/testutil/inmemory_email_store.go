@@ -0,0 +1,189 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/email"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryEmailStore implements email.Repository
+type InMemoryEmailStore struct {
+	mu         sync.RWMutex
+	templates  map[string]*email.Template
+	deliveries map[string]*email.Delivery
+}
+
+func NewInMemoryEmailStore() *InMemoryEmailStore {
+	return &InMemoryEmailStore{
+		templates:  make(map[string]*email.Template),
+		deliveries: make(map[string]*email.Delivery),
+	}
+}
+
+func templateKey(tenantID string, templateType types.EmailTemplateType) string {
+	return tenantID + "/" + string(templateType)
+}
+
+func (s *InMemoryEmailStore) UpsertTemplate(ctx context.Context, template *email.Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := templateKey(template.TenantID, template.TemplateType)
+	if existing, exists := s.templates[key]; exists {
+		template.ID = existing.ID
+		template.CreatedAt = existing.CreatedAt
+		template.CreatedBy = existing.CreatedBy
+	}
+	t := *template
+	s.templates[key] = &t
+	return nil
+}
+
+func (s *InMemoryEmailStore) GetTemplate(ctx context.Context, templateType types.EmailTemplateType) (*email.Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if t, exists := s.templates[templateKey(types.GetTenantID(ctx), templateType)]; exists {
+		template := *t
+		return &template, nil
+	}
+	return nil, fmt.Errorf("email template not found")
+}
+
+func (s *InMemoryEmailStore) ListTemplates(ctx context.Context) ([]*email.Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*email.Template
+	for _, t := range s.templates {
+		if t.TenantID == types.GetTenantID(ctx) {
+			template := *t
+			result = append(result, &template)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TemplateType < result[j].TemplateType
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryEmailStore) DeleteTemplate(ctx context.Context, templateType types.EmailTemplateType) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := templateKey(types.GetTenantID(ctx), templateType)
+	if _, exists := s.templates[key]; !exists {
+		return fmt.Errorf("email template not found")
+	}
+	delete(s.templates, key)
+	return nil
+}
+
+func (s *InMemoryEmailStore) CreateDelivery(ctx context.Context, delivery *email.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.deliveries[delivery.ID]; exists {
+		return fmt.Errorf("email delivery already exists")
+	}
+	d := *delivery
+	s.deliveries[delivery.ID] = &d
+	return nil
+}
+
+func (s *InMemoryEmailStore) GetDelivery(ctx context.Context, id string) (*email.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if d, exists := s.deliveries[id]; exists && d.TenantID == types.GetTenantID(ctx) {
+		delivery := *d
+		return &delivery, nil
+	}
+	return nil, fmt.Errorf("email delivery not found")
+}
+
+func (s *InMemoryEmailStore) ListDeliveries(ctx context.Context, filter *types.EmailDeliveryFilter) ([]*email.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*email.Delivery
+	for _, d := range s.deliveries {
+		if d.TenantID != types.GetTenantID(ctx) {
+			continue
+		}
+		if filter.DeliveryStatus != "" && d.DeliveryStatus != filter.DeliveryStatus {
+			continue
+		}
+		if filter.TemplateType != "" && d.TemplateType != filter.TemplateType {
+			continue
+		}
+		if filter.EntityID != "" && d.EntityID != filter.EntityID {
+			continue
+		}
+		delivery := *d
+		result = append(result, &delivery)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	if filter.Offset >= len(result) {
+		return []*email.Delivery{}, nil
+	}
+	end := len(result)
+	if filter.Limit > 0 && filter.Offset+filter.Limit < end {
+		end = filter.Offset + filter.Limit
+	}
+
+	return result[filter.Offset:end], nil
+}
+
+func (s *InMemoryEmailStore) UpdateDelivery(ctx context.Context, delivery *email.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.deliveries[delivery.ID]; !exists {
+		return fmt.Errorf("email delivery not found")
+	}
+	d := *delivery
+	s.deliveries[delivery.ID] = &d
+	return nil
+}
+
+func (s *InMemoryEmailStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*email.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*email.Delivery
+	for _, d := range s.deliveries {
+		if d.DeliveryStatus == types.EmailDeliveryStatusPending &&
+			d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leaseUntil := now.Add(lease)
+	result := make([]*email.Delivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = &leaseUntil
+		delivery := *d
+		result = append(result, &delivery)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,130 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/environment"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryEnvironmentStore implements environment.Repository
+type InMemoryEnvironmentStore struct {
+	mu           sync.RWMutex
+	environments map[string]*environment.Environment
+	resets       map[string]*environment.Reset
+	// DeletedSteps records the reset steps executed, in order
+	DeletedSteps []string
+}
+
+func NewInMemoryEnvironmentStore() *InMemoryEnvironmentStore {
+	return &InMemoryEnvironmentStore{
+		environments: make(map[string]*environment.Environment),
+		resets:       make(map[string]*environment.Reset),
+	}
+}
+
+func (s *InMemoryEnvironmentStore) Create(ctx context.Context, env *environment.Environment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.environments[env.ID]; exists {
+		return fmt.Errorf("environment already exists")
+	}
+	s.environments[env.ID] = env
+	return nil
+}
+
+func (s *InMemoryEnvironmentStore) Get(ctx context.Context, id string) (*environment.Environment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if env, exists := s.environments[id]; exists && env.TenantID == types.GetTenantID(ctx) {
+		return env, nil
+	}
+	return nil, fmt.Errorf("environment not found")
+}
+
+func (s *InMemoryEnvironmentStore) List(ctx context.Context, filter types.Filter) ([]*environment.Environment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*environment.Environment
+	for _, env := range s.environments {
+		if env.TenantID == types.GetTenantID(ctx) {
+			result = append(result, env)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryEnvironmentStore) UpdateClockOffset(ctx context.Context, id string, offsetMs int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	env, exists := s.environments[id]
+	if !exists || env.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("environment not found")
+	}
+
+	copied := *env
+	copied.ClockOffsetMs = offsetMs
+	s.environments[id] = &copied
+	return nil
+}
+
+func (s *InMemoryEnvironmentStore) CreateReset(ctx context.Context, reset *environment.Reset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *reset
+	s.resets[reset.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryEnvironmentStore) GetReset(ctx context.Context, id string) (*environment.Reset, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if reset, exists := s.resets[id]; exists {
+		copied := *reset
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("environment reset not found")
+}
+
+func (s *InMemoryEnvironmentStore) UpdateReset(ctx context.Context, reset *environment.Reset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.resets[reset.ID]; !exists {
+		return fmt.Errorf("environment reset not found")
+	}
+
+	copied := *reset
+	s.resets[reset.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryEnvironmentStore) DeleteResetStep(ctx context.Context, step string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.DeletedSteps = append(s.DeletedSteps, step)
+	return 0, nil
+}
+
+// GetDeletedSteps returns a copy of the executed reset steps
+func (s *InMemoryEnvironmentStore) GetDeletedSteps() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.DeletedSteps...)
+}
@@ -0,0 +1,182 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/eventschema"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryEventSchemaStore implements eventschema.Repository
+type InMemoryEventSchemaStore struct {
+	mu          sync.RWMutex
+	schemas     map[string]*eventschema.EventSchema
+	quarantined map[string]*eventschema.QuarantinedEvent
+}
+
+func NewInMemoryEventSchemaStore() *InMemoryEventSchemaStore {
+	return &InMemoryEventSchemaStore{
+		schemas:     make(map[string]*eventschema.EventSchema),
+		quarantined: make(map[string]*eventschema.QuarantinedEvent),
+	}
+}
+
+func (s *InMemoryEventSchemaStore) Create(ctx context.Context, schema *eventschema.EventSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.schemas[schema.ID]; exists {
+		return fmt.Errorf("event schema already exists")
+	}
+	for _, existing := range s.schemas {
+		if existing.TenantID == schema.TenantID && existing.Status == types.StatusPublished && existing.EventName == schema.EventName {
+			return fmt.Errorf("event schema of %s already exists", schema.EventName)
+		}
+	}
+	copied := *schema
+	s.schemas[schema.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryEventSchemaStore) Get(ctx context.Context, id string) (*eventschema.EventSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if schema, exists := s.schemas[id]; exists && schema.TenantID == types.GetTenantID(ctx) && schema.Status == types.StatusPublished {
+		copied := *schema
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("event schema not found")
+}
+
+func (s *InMemoryEventSchemaStore) GetByEventName(ctx context.Context, eventName string) (*eventschema.EventSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, schema := range s.schemas {
+		if schema.TenantID == types.GetTenantID(ctx) && schema.Status == types.StatusPublished && schema.EventName == eventName {
+			copied := *schema
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *InMemoryEventSchemaStore) List(ctx context.Context) ([]*eventschema.EventSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*eventschema.EventSchema
+	for _, schema := range s.schemas {
+		if schema.TenantID == types.GetTenantID(ctx) && schema.Status == types.StatusPublished {
+			copied := *schema
+			result = append(result, &copied)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].EventName < result[j].EventName
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryEventSchemaStore) Update(ctx context.Context, schema *eventschema.EventSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.schemas[schema.ID]
+	if !exists || existing.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("event schema not found")
+	}
+	copied := *schema
+	s.schemas[schema.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryEventSchemaStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schema, exists := s.schemas[id]
+	if !exists || schema.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("event schema not found")
+	}
+	schema.Status = types.StatusDeleted
+	return nil
+}
+
+func (s *InMemoryEventSchemaStore) Quarantine(ctx context.Context, event *eventschema.QuarantinedEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := event.TenantID + "/" + event.ID
+	copied := *event
+	if existing, exists := s.quarantined[key]; exists {
+		copied.CreatedAt = existing.CreatedAt
+		copied.CreatedBy = existing.CreatedBy
+		copied.ResubmittedAt = nil
+	}
+	s.quarantined[key] = &copied
+	return nil
+}
+
+func (s *InMemoryEventSchemaStore) GetQuarantinedEvent(ctx context.Context, id string) (*eventschema.QuarantinedEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if event, exists := s.quarantined[types.GetTenantID(ctx)+"/"+id]; exists && event.Status == types.StatusPublished {
+		copied := *event
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("quarantined event not found")
+}
+
+func (s *InMemoryEventSchemaStore) ListQuarantinedEvents(ctx context.Context, filter *types.QuarantinedEventFilter) ([]*eventschema.QuarantinedEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*eventschema.QuarantinedEvent
+	for _, e := range s.quarantined {
+		if e.TenantID != types.GetTenantID(ctx) || e.Status != types.StatusPublished {
+			continue
+		}
+		if filter.EventName != "" && e.EventName != filter.EventName {
+			continue
+		}
+		if filter.QuarantineStatus != "" && e.QuarantineStatus != filter.QuarantineStatus {
+			continue
+		}
+		event := *e
+		result = append(result, &event)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	if filter.Offset >= len(result) {
+		return []*eventschema.QuarantinedEvent{}, nil
+	}
+	end := len(result)
+	if filter.Limit > 0 && filter.Offset+filter.Limit < end {
+		end = filter.Offset + filter.Limit
+	}
+	return result[filter.Offset:end], nil
+}
+
+func (s *InMemoryEventSchemaStore) UpdateQuarantinedEvent(ctx context.Context, event *eventschema.QuarantinedEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := types.GetTenantID(ctx) + "/" + event.ID
+	if _, exists := s.quarantined[key]; !exists {
+		return fmt.Errorf("quarantined event not found")
+	}
+	copied := *event
+	s.quarantined[key] = &copied
+	return nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"sort"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/flexprice/flexprice/internal/domain/events"
 	"github.com/flexprice/flexprice/internal/types"
@@ -16,6 +18,8 @@ import (
 type InMemoryEventStore struct {
 	mu     sync.RWMutex
 	events map[string]*events.Event
+	// written is the order the events were inserted in
+	written []string
 }
 
 func NewInMemoryEventStore() *InMemoryEventStore {
@@ -25,16 +29,45 @@ func NewInMemoryEventStore() *InMemoryEventStore {
 }
 
 func (s *InMemoryEventStore) InsertEvent(ctx context.Context, event *events.Event) error {
-	if event == nil {
-		return fmt.Errorf("event cannot be nil")
+	return s.InsertEvents(ctx, []*events.Event{event})
+}
+
+func (s *InMemoryEventStore) InsertEvents(ctx context.Context, eventsList []*events.Event) error {
+	for _, event := range eventsList {
+		if event == nil {
+			return fmt.Errorf("event cannot be nil")
+		}
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.events[event.ID] = event
+	for _, event := range eventsList {
+		if _, exists := s.events[event.ID]; !exists {
+			s.written = append(s.written, event.ID)
+		}
+		s.events[event.ID] = event
+	}
 	return nil
 }
 
+func (s *InMemoryEventStore) GetEventHistory(ctx context.Context, id string) ([]*events.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	original, exists := s.events[id]
+	if !exists || original.TenantID != types.GetTenantID(ctx) || original.CorrectionOf != "" {
+		return nil, nil
+	}
+
+	history := []*events.Event{original}
+	for _, writtenID := range s.written {
+		if event, ok := s.events[writtenID]; ok && event.CorrectionOf == id {
+			history = append(history, event)
+		}
+	}
+	return history, nil
+}
+
 func (s *InMemoryEventStore) GetUsage(ctx context.Context, params *events.UsageParams) (*events.AggregationResult, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -90,17 +123,21 @@ func (s *InMemoryEventStore) GetUsage(ctx context.Context, params *events.UsageP
 
 	switch params.AggregationType {
 	case types.AggregationCount:
-		result.Value = decimal.NewFromInt(int64(len(filteredEvents)))
+		result.Value = signedCount(filteredEvents)
 	case types.AggregationSum:
 		var sum decimal.Decimal
 		for _, event := range filteredEvents {
 			if val, ok := event.Properties[params.PropertyName]; ok {
 				if floatVal, ok := val.(float64); ok {
-					sum = sum.Add(decimal.NewFromFloat(floatVal))
+					sum = sum.Add(decimal.NewFromFloat(floatVal).Mul(decimal.NewFromInt(int64(event.GetSign()))))
 				}
 			}
 		}
 		result.Value = sum
+	case types.AggregationCountUnique:
+		result.Value = decimal.NewFromInt(int64(len(uniqueValues(filteredEvents, params.PropertyName))))
+	case types.AggregationPercentile:
+		result.Value = percentileValue(filteredEvents, params.PropertyName, params.Percentile)
 	}
 
 	return result, nil
@@ -114,6 +151,9 @@ func (s *InMemoryEventStore) GetEvents(ctx context.Context, params *events.GetEv
 	var eventsList []*events.Event
 	for _, event := range s.events {
 		// Apply filters
+		if event.CorrectionOf != "" {
+			continue
+		}
 		if params.ExternalCustomerID != "" && event.ExternalCustomerID != params.ExternalCustomerID {
 			continue
 		}
@@ -169,6 +209,50 @@ func (s *InMemoryEventStore) GetEvents(ctx context.Context, params *events.GetEv
 	return eventsList, nil
 }
 
+func (s *InMemoryEventStore) ExportEvents(ctx context.Context, params *events.ExportEventsParams) ([]*events.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantID := types.GetTenantID(ctx)
+	var eventsList []*events.Event
+	for _, event := range s.events {
+		if event.TenantID != tenantID || event.CorrectionOf != "" {
+			continue
+		}
+		if params.ExternalCustomerID != "" && event.ExternalCustomerID != params.ExternalCustomerID {
+			continue
+		}
+		if params.EventName != "" && event.EventName != params.EventName {
+			continue
+		}
+		if event.Timestamp.Before(params.StartTime) || !event.Timestamp.Before(params.EndTime) {
+			continue
+		}
+		if params.After != nil {
+			if event.Timestamp.Before(params.After.Timestamp) ||
+				(event.Timestamp.Equal(params.After.Timestamp) && event.ID <= params.After.ID) {
+				continue
+			}
+		}
+
+		eventsList = append(eventsList, event)
+	}
+
+	// Sort by timestamp ASC, id ASC
+	sort.Slice(eventsList, func(i, j int) bool {
+		if eventsList[i].Timestamp.Equal(eventsList[j].Timestamp) {
+			return eventsList[i].ID < eventsList[j].ID
+		}
+		return eventsList[i].Timestamp.Before(eventsList[j].Timestamp)
+	})
+
+	if len(eventsList) > params.Limit {
+		eventsList = eventsList[:params.Limit]
+	}
+
+	return eventsList, nil
+}
+
 func (s *InMemoryEventStore) GetUsageWithFilters(ctx context.Context, params *events.UsageWithFiltersParams) ([]*events.AggregationResult, error) {
 	if params == nil || params.UsageParams == nil {
 		return nil, fmt.Errorf("params cannot be nil")
@@ -198,7 +282,7 @@ func (s *InMemoryEventStore) GetUsageWithFilters(ctx context.Context, params *ev
 		var value decimal.Decimal
 		switch params.AggregationType {
 		case types.AggregationCount:
-			value = decimal.NewFromInt(int64(len(filteredEvents)))
+			value = signedCount(filteredEvents)
 		case types.AggregationSum, types.AggregationAvg:
 			var sum decimal.Decimal
 			count := 0
@@ -222,8 +306,9 @@ func (s *InMemoryEventStore) GetUsageWithFilters(ctx context.Context, params *ev
 					default:
 						continue
 					}
-					sum = sum.Add(decimal.NewFromFloat(floatVal))
-					count++
+					sign := event.GetSign()
+					sum = sum.Add(decimal.NewFromFloat(floatVal).Mul(decimal.NewFromInt(int64(sign))))
+					count += int(sign)
 				}
 			}
 			if count > 0 {
@@ -235,6 +320,10 @@ func (s *InMemoryEventStore) GetUsageWithFilters(ctx context.Context, params *ev
 			}
 			log.Printf("Calculated %s: sum=%v, count=%d, value=%v",
 				params.AggregationType, sum, count, value)
+		case types.AggregationCountUnique:
+			value = decimal.NewFromInt(int64(len(uniqueValues(filteredEvents, params.PropertyName))))
+		case types.AggregationPercentile:
+			value = percentileValue(filteredEvents, params.PropertyName, params.Percentile)
 		}
 		result := &events.AggregationResult{
 			EventName: params.EventName,
@@ -328,9 +417,316 @@ func (s *InMemoryEventStore) matchesFilterGroup(event *events.Event, group event
 	return true
 }
 
+func (s *InMemoryEventStore) GetUsageByCustomer(ctx context.Context, params *events.UsageParams) ([]*events.CustomerUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type key struct {
+		customerID string
+		window     time.Time
+	}
+	values := make(map[key][]signedValue)
+	keyEvents := make(map[key][]*events.Event)
+
+	base := *params
+	base.ExternalCustomerID = ""
+	for _, event := range s.events {
+		if !s.matchesBaseFilters(ctx, event, &base) {
+			continue
+		}
+
+		var value decimal.Decimal
+		if v, ok := event.Properties[params.PropertyName].(float64); ok {
+			value = decimal.NewFromFloat(v)
+		}
+		k := key{customerID: event.ExternalCustomerID, window: truncateToWindow(event.Timestamp, params.WindowSize)}
+		values[k] = append(values[k], signedValue{value: value, sign: event.GetSign()})
+		keyEvents[k] = append(keyEvents[k], event)
+	}
+
+	byCustomer := make(map[string]*events.CustomerUsage)
+	for k, vs := range values {
+		var total decimal.Decimal
+		count := int64(0)
+		for _, v := range vs {
+			total = total.Add(v.value.Mul(decimal.NewFromInt(int64(v.sign))))
+			count += int64(v.sign)
+		}
+		switch params.AggregationType {
+		case types.AggregationCount:
+			total = decimal.NewFromInt(max(count, 0))
+		case types.AggregationSum, types.AggregationAvg:
+			if params.AggregationType == types.AggregationAvg {
+				if count > 0 {
+					total = total.Div(decimal.NewFromInt(count))
+				} else {
+					total = decimal.Zero
+				}
+			}
+		case types.AggregationCountUnique:
+			total = decimal.NewFromInt(int64(len(uniqueValues(keyEvents[k], params.PropertyName))))
+		case types.AggregationPercentile:
+			total = percentileValue(keyEvents[k], params.PropertyName, params.Percentile)
+		default:
+			return nil, fmt.Errorf("unsupported aggregation type: %s", params.AggregationType)
+		}
+
+		usage, ok := byCustomer[k.customerID]
+		if !ok {
+			usage = &events.CustomerUsage{ExternalCustomerID: k.customerID}
+			byCustomer[k.customerID] = usage
+		}
+		usage.Results = append(usage.Results, events.UsageResult{WindowSize: k.window, Value: total})
+	}
+
+	results := make([]*events.CustomerUsage, 0, len(byCustomer))
+	for _, usage := range byCustomer {
+		sort.Slice(usage.Results, func(i, j int) bool {
+			return usage.Results[i].WindowSize.Before(usage.Results[j].WindowSize)
+		})
+		results = append(results, usage)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ExternalCustomerID < results[j].ExternalCustomerID
+	})
+
+	return results, nil
+}
+
+// signedValue is the aggregated field of an event and the sign it counts with
+type signedValue struct {
+	value decimal.Decimal
+	sign  int8
+}
+
+// signedCount counts the events for their sign, the way the aggregations
+// subtract the reversed events
+func signedCount(eventsList []*events.Event) decimal.Decimal {
+	count := int64(0)
+	for _, event := range eventsList {
+		count += int64(event.GetSign())
+	}
+	return decimal.NewFromInt(max(count, 0))
+}
+
+// nettedValues returns the values of the field of the events that are not
+// reversed, netting each ingested event out with its correction rows, once per
+// event
+func nettedValues(eventsList []*events.Event, field string) []string {
+	type key struct {
+		eventID string
+		value   string
+	}
+	var order []key
+	net := make(map[key]int)
+	for _, event := range eventsList {
+		val, ok := event.Properties[field]
+		if !ok {
+			continue
+		}
+		eventID := event.ID
+		if event.CorrectionOf != "" {
+			eventID = event.CorrectionOf
+		}
+		k := key{eventID: eventID, value: fmt.Sprintf("%v", val)}
+		if _, seen := net[k]; !seen {
+			order = append(order, k)
+		}
+		net[k] += int(event.GetSign())
+	}
+
+	var values []string
+	for _, k := range order {
+		if net[k] > 0 {
+			values = append(values, k.value)
+		}
+	}
+	return values
+}
+
+// uniqueValues returns the distinct values of the field counted by a unique count
+func uniqueValues(eventsList []*events.Event, field string) map[string]bool {
+	values := make(map[string]bool)
+	for _, value := range nettedValues(eventsList, field) {
+		values[value] = true
+	}
+	return values
+}
+
+// percentileValue returns the percentile of the values of the field the way
+// quantileExact does, the value at the position of the level in the sorted values
+func percentileValue(eventsList []*events.Event, field string, percentile float64) decimal.Decimal {
+	var values []float64
+	for _, value := range nettedValues(eventsList, field) {
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return decimal.Zero
+	}
+
+	sort.Float64s(values)
+	i := int(percentile / 100 * float64(len(values)))
+	if i >= len(values) {
+		i = len(values) - 1
+	}
+	return decimal.NewFromFloat(values[i])
+}
+
+func truncateToWindow(t time.Time, windowSize types.WindowSize) time.Time {
+	t = t.UTC()
+	switch windowSize {
+	case types.WindowSizeMinute:
+		return t.Truncate(time.Minute)
+	case types.WindowSizeHour:
+		return t.Truncate(time.Hour)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func (s *InMemoryEventStore) CountLateEvents(ctx context.Context, params *events.LateEventsParams) (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count uint64
+	for _, event := range s.events {
+		if event.TenantID != types.GetTenantID(ctx) || event.ExternalCustomerID != params.ExternalCustomerID {
+			continue
+		}
+		if len(params.EventNames) > 0 && !slices.Contains(params.EventNames, event.EventName) {
+			continue
+		}
+		if event.Timestamp.Before(params.StartTime) || !event.Timestamp.Before(params.EndTime) {
+			continue
+		}
+		if !event.IngestedAt.After(params.IngestedAfter) || event.IngestedAt.After(params.IngestedBefore) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (s *InMemoryEventStore) GetTenantIDs(ctx context.Context, since time.Time) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var tenantIDs []string
+	for _, event := range s.events {
+		if event.Timestamp.Before(since) || seen[event.TenantID] {
+			continue
+		}
+		seen[event.TenantID] = true
+		tenantIDs = append(tenantIDs, event.TenantID)
+	}
+	sort.Strings(tenantIDs)
+
+	return tenantIDs, nil
+}
+
 func (s *InMemoryEventStore) HasEvent(id string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	_, exists := s.events[id]
 	return exists
 }
+
+func (s *InMemoryEventStore) DeleteEvents(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := types.GetTenantID(ctx)
+	for id, event := range s.events {
+		if event.TenantID == tenantID {
+			delete(s.events, id)
+		}
+	}
+	return nil
+}
+
+func (s *InMemoryEventStore) DeleteEventsBefore(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := types.GetTenantID(ctx)
+	for id, event := range s.events {
+		if event.TenantID == tenantID && event.Timestamp.Before(before) {
+			delete(s.events, id)
+		}
+	}
+	return nil
+}
+
+func (s *InMemoryEventStore) GetStorageByTenant(ctx context.Context) ([]*events.TenantStorage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byTenant := make(map[string]*events.TenantStorage)
+	for _, event := range s.events {
+		storage, ok := byTenant[event.TenantID]
+		if !ok {
+			storage = &events.TenantStorage{
+				TenantID:    event.TenantID,
+				OldestEvent: event.Timestamp,
+				NewestEvent: event.Timestamp,
+			}
+			byTenant[event.TenantID] = storage
+		}
+		storage.Rows++
+		if event.Timestamp.Before(storage.OldestEvent) {
+			storage.OldestEvent = event.Timestamp
+		}
+		if event.Timestamp.After(storage.NewestEvent) {
+			storage.NewestEvent = event.Timestamp
+		}
+	}
+
+	storage := make([]*events.TenantStorage, 0, len(byTenant))
+	for _, s := range byTenant {
+		storage = append(storage, s)
+	}
+	sort.Slice(storage, func(i, j int) bool {
+		return storage[i].TenantID < storage[j].TenantID
+	})
+	return storage, nil
+}
+
+func (s *InMemoryEventStore) ListPartitions(ctx context.Context) ([]*events.Partition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byID := make(map[string]*events.Partition)
+	for _, event := range s.events {
+		id := event.Timestamp.UTC().Format("200601")
+		partition, ok := byID[id]
+		if !ok {
+			partition = &events.Partition{PartitionID: id}
+			byID[id] = partition
+		}
+		partition.Rows++
+	}
+
+	partitions := make([]*events.Partition, 0, len(byID))
+	for _, p := range byID {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i].PartitionID < partitions[j].PartitionID
+	})
+	return partitions, nil
+}
+
+func (s *InMemoryEventStore) DropPartition(ctx context.Context, partitionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, event := range s.events {
+		if event.Timestamp.UTC().Format("200601") == partitionID {
+			delete(s.events, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/export"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryExportStore implements export.Repository
+type InMemoryExportStore struct {
+	mu        sync.RWMutex
+	exports   map[string]*export.Export
+	schedules map[string]*export.Schedule
+}
+
+func NewInMemoryExportStore() *InMemoryExportStore {
+	return &InMemoryExportStore{
+		exports:   make(map[string]*export.Export),
+		schedules: make(map[string]*export.Schedule),
+	}
+}
+
+func (s *InMemoryExportStore) Create(ctx context.Context, e *export.Export) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.exports[e.ID]; exists {
+		return fmt.Errorf("export already exists")
+	}
+	copied := *e
+	s.exports[e.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryExportStore) Get(ctx context.Context, id string) (*export.Export, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if e, exists := s.exports[id]; exists && e.TenantID == types.GetTenantID(ctx) {
+		copied := *e
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("export not found")
+}
+
+func (s *InMemoryExportStore) List(ctx context.Context, filter types.Filter) ([]*export.Export, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*export.Export
+	for _, e := range s.exports {
+		if e.TenantID == types.GetTenantID(ctx) {
+			copied := *e
+			result = append(result, &copied)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryExportStore) Update(ctx context.Context, e *export.Export) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, exists := s.exports[e.ID]; !exists || existing.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("export not found")
+	}
+	copied := *e
+	s.exports[e.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryExportStore) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*export.Export, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimable []*export.Export
+	for _, e := range s.exports {
+		if e.ExportStatus == types.ExportStatusPending ||
+			(e.ExportStatus == types.ExportStatusProcessing && e.LeaseUntil != nil && e.LeaseUntil.Before(now)) {
+			claimable = append(claimable, e)
+		}
+	}
+
+	sort.Slice(claimable, func(i, j int) bool {
+		return claimable[i].CreatedAt.Before(claimable[j].CreatedAt)
+	})
+	if len(claimable) > limit {
+		claimable = claimable[:limit]
+	}
+
+	leaseUntil := now.Add(lease)
+	result := make([]*export.Export, 0, len(claimable))
+	for _, e := range claimable {
+		e.ExportStatus = types.ExportStatusProcessing
+		e.LeaseUntil = &leaseUntil
+		e.UpdatedAt = now
+		copied := *e
+		result = append(result, &copied)
+	}
+
+	return result, nil
+}
+
+func (s *InMemoryExportStore) CreateSchedule(ctx context.Context, schedule *export.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.schedules[schedule.ID]; exists {
+		return fmt.Errorf("export schedule already exists")
+	}
+	copied := *schedule
+	s.schedules[schedule.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryExportStore) GetSchedule(ctx context.Context, id string) (*export.Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if schedule, exists := s.schedules[id]; exists && inTenant(ctx, schedule.BaseModel) {
+		copied := *schedule
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("export schedule not found")
+}
+
+func (s *InMemoryExportStore) ListSchedules(ctx context.Context, filter types.Filter) ([]*export.Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*export.Schedule
+	for _, schedule := range s.schedules {
+		if inTenant(ctx, schedule.BaseModel) {
+			copied := *schedule
+			result = append(result, &copied)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryExportStore) UpdateSchedule(ctx context.Context, schedule *export.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, exists := s.schedules[schedule.ID]; !exists || !inTenant(ctx, existing.BaseModel) {
+		return fmt.Errorf("export schedule not found")
+	}
+	copied := *schedule
+	s.schedules[schedule.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryExportStore) DeleteSchedule(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, exists := s.schedules[id]
+	if !exists || !inTenant(ctx, schedule.BaseModel) {
+		return fmt.Errorf("export schedule not found")
+	}
+	schedule.Status = types.StatusDeleted
+	return nil
+}
+
+func (s *InMemoryExportStore) ClaimDueSchedules(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*export.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*export.Schedule
+	for _, schedule := range s.schedules {
+		if schedule.Status == types.StatusPublished && schedule.NextRunAt != nil && !schedule.NextRunAt.After(now) {
+			due = append(due, schedule)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextRunAt.Before(*due[j].NextRunAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leaseUntil := now.Add(lease)
+	result := make([]*export.Schedule, 0, len(due))
+	for _, schedule := range due {
+		schedule.NextRunAt = &leaseUntil
+		copied := *schedule
+		result = append(result, &copied)
+	}
+
+	return result, nil
+}
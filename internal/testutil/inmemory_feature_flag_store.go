@@ -0,0 +1,75 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/featureflag"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryFeatureFlagStore implements featureflag.Repository
+type InMemoryFeatureFlagStore struct {
+	mu        sync.RWMutex
+	overrides map[string]*featureflag.Override
+}
+
+func NewInMemoryFeatureFlagStore() *InMemoryFeatureFlagStore {
+	return &InMemoryFeatureFlagStore{
+		overrides: make(map[string]*featureflag.Override),
+	}
+}
+
+func featureFlagKey(tenantID string, flag types.FeatureFlag, environmentID string) string {
+	return tenantID + "/" + environmentID + "/" + string(flag)
+}
+
+func (s *InMemoryFeatureFlagStore) Upsert(ctx context.Context, override *featureflag.Override) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := featureFlagKey(override.TenantID, override.Flag, override.EnvironmentID)
+	if existing, ok := s.overrides[key]; ok {
+		override.ID = existing.ID
+		override.CreatedAt = existing.CreatedAt
+		override.CreatedBy = existing.CreatedBy
+	}
+	copied := *override
+	s.overrides[key] = &copied
+	return nil
+}
+
+func (s *InMemoryFeatureFlagStore) List(ctx context.Context, tenantID string) ([]*featureflag.Override, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var overrides []*featureflag.Override
+	for _, override := range s.overrides {
+		if override.TenantID != tenantID {
+			continue
+		}
+		copied := *override
+		overrides = append(overrides, &copied)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].Flag != overrides[j].Flag {
+			return overrides[i].Flag < overrides[j].Flag
+		}
+		return overrides[i].EnvironmentID < overrides[j].EnvironmentID
+	})
+	return overrides, nil
+}
+
+func (s *InMemoryFeatureFlagStore) Delete(ctx context.Context, tenantID string, flag types.FeatureFlag, environmentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := featureFlagKey(tenantID, flag, environmentID)
+	if _, ok := s.overrides[key]; !ok {
+		return fmt.Errorf("feature flag override not found")
+	}
+	delete(s.overrides, key)
+	return nil
+}
@@ -0,0 +1,597 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
+	"github.com/flexprice/flexprice/internal/domain/invoice"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryInvoiceStore implements invoice.Repository
+type InMemoryInvoiceStore struct {
+	mu          sync.RWMutex
+	invoices    map[string]*invoice.Invoice
+	allocations []*invoice.CreditAllocation
+	payments    []*invoice.Payment
+	refunds     []*invoice.Refund
+	disputes    []*invoice.Dispute
+}
+
+func NewInMemoryInvoiceStore() *InMemoryInvoiceStore {
+	return &InMemoryInvoiceStore{
+		invoices: make(map[string]*invoice.Invoice),
+	}
+}
+
+func (s *InMemoryInvoiceStore) Create(ctx context.Context, inv *invoice.Invoice) error {
+	if inv == nil {
+		return fmt.Errorf("invoice cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.invoices[inv.ID]; exists {
+		return fmt.Errorf("invoice already exists")
+	}
+
+	inv.Version = 1
+	s.invoices[inv.ID] = inv
+	return nil
+}
+
+func (s *InMemoryInvoiceStore) Get(ctx context.Context, id string) (*invoice.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if inv, exists := s.invoices[id]; exists {
+		return inv, nil
+	}
+	return nil, fmt.Errorf("invoice not found")
+}
+
+func (s *InMemoryInvoiceStore) List(ctx context.Context, filter *types.InvoiceFilter) ([]*invoice.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*invoice.Invoice
+	for _, inv := range s.invoices {
+		if filter == nil {
+			result = append(result, inv)
+			continue
+		}
+
+		if filter.CustomerID != "" && inv.CustomerID != filter.CustomerID {
+			continue
+		}
+
+		if filter.SubscriptionID != "" && inv.SubscriptionID != filter.SubscriptionID {
+			continue
+		}
+
+		if filter.InvoiceType != "" && inv.InvoiceType != filter.InvoiceType {
+			continue
+		}
+
+		if filter.InvoiceStatus != "" && inv.InvoiceStatus != filter.InvoiceStatus {
+			continue
+		}
+
+		if filter.OriginalInvoiceID != "" && inv.OriginalInvoiceID != filter.OriginalInvoiceID {
+			continue
+		}
+
+		result = append(result, inv)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+
+	if filter != nil && filter.UsesCursor() {
+		cursor, err := types.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor != nil {
+			page := result[:0]
+			for _, inv := range result {
+				if inv.CreatedAt.Before(cursor.Time) || (inv.CreatedAt.Equal(cursor.Time) && inv.ID < cursor.ID) {
+					page = append(page, inv)
+				}
+			}
+			result = page
+		}
+		if filter.Limit > 0 && len(result) > filter.Limit {
+			result = result[:filter.Limit]
+		}
+		return result, nil
+	}
+
+	if filter != nil && filter.Limit > 0 {
+		start := filter.Offset
+		if start >= len(result) {
+			return []*invoice.Invoice{}, nil
+		}
+
+		end := start + filter.Limit
+		if end > len(result) {
+			end = len(result)
+		}
+
+		result = result[start:end]
+	}
+
+	return result, nil
+}
+
+func (s *InMemoryInvoiceStore) ListOutstanding(ctx context.Context, customerID string) ([]*invoice.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*invoice.Invoice
+	for _, inv := range s.invoices {
+		if inv.TenantID != types.GetTenantID(ctx) || inv.InvoiceStatus != types.InvoiceStatusFinalized ||
+			!inv.AmountRemaining.IsPositive() || inv.WrittenOffAt != nil {
+			continue
+		}
+		if customerID != "" && inv.CustomerID != customerID {
+			continue
+		}
+		result = append(result, inv)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FinalizedAt == nil || result[j].FinalizedAt == nil || result[i].FinalizedAt.Equal(*result[j].FinalizedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].FinalizedAt.Before(*result[j].FinalizedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryInvoiceStore) ListWrittenOff(ctx context.Context, filter *types.WriteOffFilter) ([]*invoice.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*invoice.Invoice
+	for _, inv := range s.invoices {
+		if inv.TenantID != types.GetTenantID(ctx) || inv.WrittenOffAt == nil {
+			continue
+		}
+		if filter.CustomerID != "" && inv.CustomerID != filter.CustomerID {
+			continue
+		}
+		if filter.StartTime != nil && inv.WrittenOffAt.Before(*filter.StartTime) {
+			continue
+		}
+		if filter.EndTime != nil && !inv.WrittenOffAt.Before(*filter.EndTime) {
+			continue
+		}
+		result = append(result, inv)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].WrittenOffAt.Equal(*result[j].WrittenOffAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].WrittenOffAt.Before(*result[j].WrittenOffAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryInvoiceStore) ListInvoicingTenantIDs(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var tenantIDs []string
+	for _, inv := range s.invoices {
+		if inv.InvoiceStatus == types.InvoiceStatusFinalized && !seen[inv.TenantID] {
+			seen[inv.TenantID] = true
+			tenantIDs = append(tenantIDs, inv.TenantID)
+		}
+	}
+
+	sort.Strings(tenantIDs)
+	return tenantIDs, nil
+}
+
+func (s *InMemoryInvoiceStore) ListForReconciliation(ctx context.Context, since time.Time) ([]*invoice.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*invoice.Invoice
+	for _, inv := range s.invoices {
+		if inv.Status != types.StatusPublished || inv.InvoiceType != types.InvoiceTypeSubscription ||
+			inv.PeriodStart == nil || inv.PeriodEnd == nil {
+			continue
+		}
+		switch inv.InvoiceStatus {
+		case types.InvoiceStatusDraft, types.InvoiceStatusHeld:
+			result = append(result, inv)
+		case types.InvoiceStatusFinalized:
+			if inv.PeriodEnd.After(since) {
+				result = append(result, inv)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].PeriodEnd.Before(*result[j].PeriodEnd)
+	})
+	return result, nil
+}
+
+func (s *InMemoryInvoiceStore) ListFinalizedLineItems(ctx context.Context, start, end time.Time) ([]*invoice.InvoiceLineItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var items []*invoice.InvoiceLineItem
+	for _, inv := range s.invoices {
+		if inv.InvoiceStatus != types.InvoiceStatusFinalized {
+			continue
+		}
+
+		for _, item := range inv.LineItems {
+			at := item.PeriodStart
+			if at == nil {
+				at = inv.PeriodStart
+			}
+			if at == nil {
+				at = inv.FinalizedAt
+			}
+			if at != nil && !at.Before(start) && at.Before(end) {
+				items = append(items, item)
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	return items, nil
+}
+
+func (s *InMemoryInvoiceStore) Update(ctx context.Context, inv *invoice.Invoice) error {
+	if inv == nil {
+		return fmt.Errorf("invoice cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.invoices[inv.ID]
+	if !exists {
+		return fmt.Errorf("invoice not found")
+	}
+	if existing.Version != inv.Version {
+		return domainErrors.NewVersionConflictError("invoice", inv.ID, existing.Version)
+	}
+
+	inv.Version++
+	s.invoices[inv.ID] = inv
+	return nil
+}
+
+func (s *InMemoryInvoiceStore) GetForUpdate(ctx context.Context, id string) (*invoice.Invoice, error) {
+	return s.Get(ctx, id)
+}
+
+// CreateLineItem adds the line item to its invoice unless the caller already
+// appended it to the stored invoice
+func (s *InMemoryInvoiceStore) CreateLineItem(ctx context.Context, item *invoice.InvoiceLineItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, exists := s.invoices[item.InvoiceID]
+	if !exists {
+		return fmt.Errorf("invoice not found")
+	}
+
+	for _, existing := range inv.LineItems {
+		if existing.ID == item.ID {
+			return nil
+		}
+	}
+	inv.LineItems = append(inv.LineItems, item)
+	return nil
+}
+
+func (s *InMemoryInvoiceStore) UpdateLineItem(ctx context.Context, item *invoice.InvoiceLineItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, exists := s.invoices[item.InvoiceID]
+	if !exists {
+		return fmt.Errorf("invoice not found")
+	}
+
+	for i, existing := range inv.LineItems {
+		if existing.ID == item.ID {
+			inv.LineItems[i] = item
+			return nil
+		}
+	}
+	return fmt.Errorf("invoice line item not found")
+}
+
+// DeleteLineItem removes the line item from its invoice, it is a no-op when the
+// caller already removed it from the stored invoice
+func (s *InMemoryInvoiceStore) DeleteLineItem(ctx context.Context, item *invoice.InvoiceLineItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, exists := s.invoices[item.InvoiceID]
+	if !exists {
+		return fmt.Errorf("invoice not found")
+	}
+
+	items := inv.LineItems[:0:0]
+	for _, existing := range inv.LineItems {
+		if existing.ID != item.ID {
+			items = append(items, existing)
+		}
+	}
+	inv.LineItems = items
+	return nil
+}
+
+func (s *InMemoryInvoiceStore) CreateCreditAllocation(ctx context.Context, allocation *invoice.CreditAllocation) error {
+	if allocation == nil {
+		return fmt.Errorf("credit allocation cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.allocations = append(s.allocations, allocation)
+	return nil
+}
+
+func (s *InMemoryInvoiceStore) ListCreditAllocations(ctx context.Context, creditInvoiceID string) ([]*invoice.CreditAllocation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*invoice.CreditAllocation
+	for _, allocation := range s.allocations {
+		if allocation.CreditInvoiceID == creditInvoiceID && allocation.Status == types.StatusPublished {
+			result = append(result, allocation)
+		}
+	}
+	return result, nil
+}
+
+func (s *InMemoryInvoiceStore) CreatePayment(ctx context.Context, payment *invoice.Payment) error {
+	if payment == nil {
+		return fmt.Errorf("payment cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.payments = append(s.payments, payment)
+	return nil
+}
+
+func (s *InMemoryInvoiceStore) GetPayment(ctx context.Context, id string) (*invoice.Payment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, payment := range s.payments {
+		if payment.ID == id && payment.Status == types.StatusPublished {
+			return payment, nil
+		}
+	}
+	return nil, fmt.Errorf("invoice payment not found")
+}
+
+func (s *InMemoryInvoiceStore) UpdatePayment(ctx context.Context, payment *invoice.Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.payments {
+		if existing.ID == payment.ID {
+			s.payments[i] = payment
+			return nil
+		}
+	}
+	return fmt.Errorf("invoice payment not found")
+}
+
+func (s *InMemoryInvoiceStore) GetPaymentByGatewayID(ctx context.Context, gatewayPaymentID string) (*invoice.Payment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, payment := range s.payments {
+		if payment.GatewayPaymentID == gatewayPaymentID && payment.TenantID == types.GetTenantID(ctx) && payment.Status == types.StatusPublished {
+			return payment, nil
+		}
+	}
+	return nil, fmt.Errorf("invoice payment not found")
+}
+
+func (s *InMemoryInvoiceStore) ClaimDueDebits(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*invoice.Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimable []*invoice.Payment
+	for _, payment := range s.payments {
+		if payment.Status != types.StatusPublished || payment.MandateID == "" ||
+			payment.ChargeDate == nil || payment.ChargeDate.After(now) {
+			continue
+		}
+		if payment.PaymentStatus != types.PaymentStatusScheduled &&
+			(payment.PaymentStatus != types.PaymentStatusProcessing || payment.GatewayPaymentID != "") {
+			continue
+		}
+		if payment.LeaseUntil != nil && !payment.LeaseUntil.Before(now) {
+			continue
+		}
+		claimable = append(claimable, payment)
+	}
+
+	sort.Slice(claimable, func(i, j int) bool {
+		return claimable[i].ChargeDate.Before(*claimable[j].ChargeDate)
+	})
+	if len(claimable) > limit {
+		claimable = claimable[:limit]
+	}
+
+	leaseUntil := now.Add(lease)
+	result := make([]*invoice.Payment, 0, len(claimable))
+	for _, payment := range claimable {
+		payment.LeaseUntil = &leaseUntil
+		copied := *payment
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (s *InMemoryInvoiceStore) ListPayments(ctx context.Context, invoiceID string) ([]*invoice.Payment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*invoice.Payment
+	for _, payment := range s.payments {
+		if payment.InvoiceID == invoiceID && payment.Status == types.StatusPublished {
+			result = append(result, payment)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].PaidAt.Before(result[j].PaidAt)
+	})
+	return result, nil
+}
+
+func (s *InMemoryInvoiceStore) CreateRefund(ctx context.Context, refund *invoice.Refund) error {
+	if refund == nil {
+		return fmt.Errorf("refund cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refunds = append(s.refunds, refund)
+	return nil
+}
+
+func (s *InMemoryInvoiceStore) ListRefunds(ctx context.Context, paymentID string) ([]*invoice.Refund, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*invoice.Refund
+	for _, refund := range s.refunds {
+		if refund.PaymentID == paymentID && refund.Status == types.StatusPublished {
+			result = append(result, refund)
+		}
+	}
+	return result, nil
+}
+
+func (s *InMemoryInvoiceStore) GetRefundByGatewayID(ctx context.Context, gatewayRefundID string) (*invoice.Refund, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, refund := range s.refunds {
+		if refund.GatewayRefundID == gatewayRefundID && refund.TenantID == types.GetTenantID(ctx) && refund.Status == types.StatusPublished {
+			copied := *refund
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("refund not found")
+}
+
+func (s *InMemoryInvoiceStore) UpdateRefund(ctx context.Context, refund *invoice.Refund) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.refunds {
+		if existing.ID == refund.ID {
+			copied := *refund
+			s.refunds[i] = &copied
+			return nil
+		}
+	}
+	return fmt.Errorf("refund not found")
+}
+
+func (s *InMemoryInvoiceStore) CreateDispute(ctx context.Context, dispute *invoice.Dispute) error {
+	if dispute == nil {
+		return fmt.Errorf("dispute cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *dispute
+	s.disputes = append(s.disputes, &copied)
+	return nil
+}
+
+func (s *InMemoryInvoiceStore) GetDispute(ctx context.Context, id string) (*invoice.Dispute, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, dispute := range s.disputes {
+		if dispute.ID == id && dispute.TenantID == types.GetTenantID(ctx) && dispute.Status == types.StatusPublished {
+			copied := *dispute
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("invoice dispute not found")
+}
+
+func (s *InMemoryInvoiceStore) GetActiveDispute(ctx context.Context, invoiceID string) (*invoice.Dispute, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, dispute := range s.disputes {
+		if dispute.InvoiceID == invoiceID && dispute.TenantID == types.GetTenantID(ctx) &&
+			dispute.Status == types.StatusPublished && dispute.IsActive() {
+			copied := *dispute
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *InMemoryInvoiceStore) ListDisputes(ctx context.Context, invoiceID string) ([]*invoice.Dispute, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*invoice.Dispute
+	for _, dispute := range s.disputes {
+		if dispute.InvoiceID == invoiceID && dispute.TenantID == types.GetTenantID(ctx) && dispute.Status == types.StatusPublished {
+			copied := *dispute
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (s *InMemoryInvoiceStore) UpdateDispute(ctx context.Context, dispute *invoice.Dispute) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.disputes {
+		if existing.ID == dispute.ID {
+			copied := *dispute
+			s.disputes[i] = &copied
+			return nil
+		}
+	}
+	return fmt.Errorf("invoice dispute not found")
+}
@@ -0,0 +1,67 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/job"
+)
+
+// InMemoryJobRunStore implements job.RunRepository
+type InMemoryJobRunStore struct {
+	mu   sync.RWMutex
+	runs map[string]*job.Run
+}
+
+func NewInMemoryJobRunStore() *InMemoryJobRunStore {
+	return &InMemoryJobRunStore{
+		runs: make(map[string]*job.Run),
+	}
+}
+
+func (s *InMemoryJobRunStore) Create(ctx context.Context, run *job.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.runs[run.ID]; ok {
+		return fmt.Errorf("job run already exists")
+	}
+	copied := *run
+	s.runs[run.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryJobRunStore) Update(ctx context.Context, run *job.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.runs[run.ID]; !ok {
+		return fmt.Errorf("job run not found")
+	}
+	copied := *run
+	s.runs[run.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryJobRunStore) ListRuns(ctx context.Context, jobName string, limit int) ([]*job.Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var runs []*job.Run
+	for _, run := range s.runs {
+		if run.JobName == jobName {
+			copied := *run
+			runs = append(runs, &copied)
+		}
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
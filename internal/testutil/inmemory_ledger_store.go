@@ -0,0 +1,96 @@
+package testutil
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/ledger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryLedgerStore implements ledger.Repository
+type InMemoryLedgerStore struct {
+	mu    sync.RWMutex
+	syncs map[string]*ledger.Sync
+}
+
+func NewInMemoryLedgerStore() *InMemoryLedgerStore {
+	return &InMemoryLedgerStore{
+		syncs: make(map[string]*ledger.Sync),
+	}
+}
+
+func (s *InMemoryLedgerStore) Upsert(ctx context.Context, sync *ledger.Sync) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.syncs {
+		if existing.TenantID == sync.TenantID && existing.ConnectionID == sync.ConnectionID &&
+			existing.EntityType == sync.EntityType && existing.EntityID == sync.EntityID {
+			existing.SyncStatus = sync.SyncStatus
+			existing.Attempts = sync.Attempts
+			existing.LastError = sync.LastError
+			existing.UpdatedAt = sync.UpdatedAt
+			existing.UpdatedBy = sync.UpdatedBy
+			*sync = *existing
+			return nil
+		}
+	}
+
+	if sync.ID == "" {
+		sync.ID = types.GenerateUUID()
+	}
+	stored := *sync
+	s.syncs[sync.ID] = &stored
+	return nil
+}
+
+func (s *InMemoryLedgerStore) Update(ctx context.Context, sync *ledger.Sync) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *sync
+	s.syncs[sync.ID] = &stored
+	return nil
+}
+
+func (s *InMemoryLedgerStore) ListByEntity(ctx context.Context, entityID string) ([]*ledger.Sync, error) {
+	return s.list(ctx, func(sync *ledger.Sync) bool {
+		return sync.EntityID == entityID
+	}), nil
+}
+
+func (s *InMemoryLedgerStore) List(ctx context.Context, filter *types.LedgerSyncFilter) ([]*ledger.Sync, error) {
+	result := s.list(ctx, func(sync *ledger.Sync) bool {
+		return (filter.ConnectionID == "" || sync.ConnectionID == filter.ConnectionID) &&
+			(filter.SyncStatus == "" || sync.SyncStatus == filter.SyncStatus)
+	})
+
+	if filter.Offset >= len(result) {
+		return []*ledger.Sync{}, nil
+	}
+	result = result[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(result) {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (s *InMemoryLedgerStore) list(ctx context.Context, match func(*ledger.Sync) bool) []*ledger.Sync {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*ledger.Sync
+	for _, sync := range s.syncs {
+		if sync.TenantID == types.GetTenantID(ctx) && match(sync) {
+			copy := *sync
+			result = append(result, &copy)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
@@ -0,0 +1,109 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/legalentity"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryLegalEntityStore implements legalentity.Repository
+type InMemoryLegalEntityStore struct {
+	mu       sync.RWMutex
+	entities map[string]*legalentity.LegalEntity
+}
+
+func NewInMemoryLegalEntityStore() *InMemoryLegalEntityStore {
+	return &InMemoryLegalEntityStore{
+		entities: make(map[string]*legalentity.LegalEntity),
+	}
+}
+
+func (s *InMemoryLegalEntityStore) Create(ctx context.Context, entity *legalentity.LegalEntity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.entities[entity.ID]; exists {
+		return fmt.Errorf("legal entity already exists")
+	}
+	copied := *entity
+	s.entities[entity.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryLegalEntityStore) Get(ctx context.Context, id string) (*legalentity.LegalEntity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if e, exists := s.entities[id]; exists && e.TenantID == types.GetTenantID(ctx) && e.Status == types.StatusPublished {
+		copied := *e
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("legal entity not found")
+}
+
+func (s *InMemoryLegalEntityStore) GetDefault(ctx context.Context) (*legalentity.LegalEntity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.entities {
+		if e.TenantID == types.GetTenantID(ctx) && e.Status == types.StatusPublished && e.IsDefault {
+			copied := *e
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *InMemoryLegalEntityStore) List(ctx context.Context) ([]*legalentity.LegalEntity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*legalentity.LegalEntity
+	for _, e := range s.entities {
+		if e.TenantID == types.GetTenantID(ctx) && e.Status == types.StatusPublished {
+			copied := *e
+			result = append(result, &copied)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryLegalEntityStore) Update(ctx context.Context, entity *legalentity.LegalEntity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.entities[entity.ID]
+	if !exists || existing.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("legal entity not found")
+	}
+	copied := *entity
+	s.entities[entity.ID] = &copied
+	return nil
+}
+
+// InUse is always false, the store does not see the customers and subscriptions
+func (s *InMemoryLegalEntityStore) InUse(ctx context.Context, id string) (bool, error) {
+	return false, nil
+}
+
+func (s *InMemoryLegalEntityStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.entities[id]
+	if !exists || existing.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("legal entity not found")
+	}
+	existing.Status = types.StatusDeleted
+	existing.IsDefault = false
+	return nil
+}
@@ -0,0 +1,78 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/mandate"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryMandateStore implements mandate.Repository
+type InMemoryMandateStore struct {
+	mu       sync.RWMutex
+	mandates map[string]*mandate.Mandate
+}
+
+func NewInMemoryMandateStore() *InMemoryMandateStore {
+	return &InMemoryMandateStore{
+		mandates: make(map[string]*mandate.Mandate),
+	}
+}
+
+func (s *InMemoryMandateStore) Create(ctx context.Context, m *mandate.Mandate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.mandates[m.ID]; exists {
+		return fmt.Errorf("mandate already exists")
+	}
+	copied := *m
+	s.mandates[m.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryMandateStore) Get(ctx context.Context, id string) (*mandate.Mandate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if m, exists := s.mandates[id]; exists && m.TenantID == types.GetTenantID(ctx) && m.Status == types.StatusPublished {
+		copied := *m
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("mandate not found")
+}
+
+func (s *InMemoryMandateStore) ListByCustomer(ctx context.Context, customerID string) ([]*mandate.Mandate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*mandate.Mandate
+	for _, m := range s.mandates {
+		if m.TenantID == types.GetTenantID(ctx) && m.CustomerID == customerID && m.Status == types.StatusPublished {
+			copied := *m
+			result = append(result, &copied)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryMandateStore) Update(ctx context.Context, m *mandate.Mandate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.mandates[m.ID]
+	if !exists || existing.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("mandate not found")
+	}
+	copied := *m
+	s.mandates[m.ID] = &copied
+	return nil
+}
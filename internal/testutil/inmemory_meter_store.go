@@ -4,19 +4,22 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/flexprice/flexprice/internal/domain/meter"
 	"github.com/flexprice/flexprice/internal/types"
 )
 
 type InMemoryMeterStore struct {
-	mu     sync.RWMutex
-	meters map[string]*meter.Meter
+	mu       sync.RWMutex
+	meters   map[string]*meter.Meter
+	versions map[string][]*meter.MeterVersion
 }
 
 func NewInMemoryMeterStore() *InMemoryMeterStore {
 	return &InMemoryMeterStore{
-		meters: make(map[string]*meter.Meter),
+		meters:   make(map[string]*meter.Meter),
+		versions: make(map[string][]*meter.MeterVersion),
 	}
 }
 
@@ -37,6 +40,9 @@ func (s *InMemoryMeterStore) CreateMeter(ctx context.Context, m *meter.Meter) er
 	}
 
 	s.meters[m.ID] = m
+	if !m.IsDerived() {
+		s.versions[m.ID] = []*meter.MeterVersion{meter.NewVersion(m)}
+	}
 	return nil
 }
 
@@ -74,3 +80,54 @@ func (s *InMemoryMeterStore) DisableMeter(ctx context.Context, id string) error
 	m.Status = types.StatusDeleted
 	return nil
 }
+
+func (s *InMemoryMeterStore) CreateVersion(ctx context.Context, v *meter.MeterVersion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, exists := s.meters[v.MeterID]
+	if !exists {
+		return fmt.Errorf("meter not found")
+	}
+	for _, existing := range s.versions[v.MeterID] {
+		if existing.Version == v.Version {
+			return fmt.Errorf("meter version %d already exists", v.Version)
+		}
+	}
+
+	copied := *v
+	s.versions[v.MeterID] = append(s.versions[v.MeterID], &copied)
+	if v.EffectiveTo == nil {
+		m.Aggregation = v.Aggregation
+		m.Filters = v.Filters
+		m.UpdatedAt = v.CreatedAt
+	}
+	return nil
+}
+
+func (s *InMemoryMeterStore) UpdateVersion(ctx context.Context, v *meter.MeterVersion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.versions[v.MeterID] {
+		if existing.ID == v.ID {
+			existing.ReaggregationStatus = v.ReaggregationStatus
+			existing.ReaggregationError = v.ReaggregationError
+			existing.UpdatedAt = time.Now().UTC()
+			return nil
+		}
+	}
+	return fmt.Errorf("meter version not found")
+}
+
+func (s *InMemoryMeterStore) ListVersions(ctx context.Context, meterID string) ([]*meter.MeterVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := make([]*meter.MeterVersion, 0, len(s.versions[meterID]))
+	for _, v := range s.versions[meterID] {
+		copied := *v
+		versions = append(versions, &copied)
+	}
+	return versions, nil
+}
@@ -0,0 +1,67 @@
+// In-memory object store for testing
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// InMemoryObjectStore implements storage.Store
+type InMemoryObjectStore struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+func NewInMemoryObjectStore() *InMemoryObjectStore {
+	return &InMemoryObjectStore{
+		objects: make(map[string][]byte),
+	}
+}
+
+func (s *InMemoryObjectStore) Upload(ctx context.Context, key string, r io.Reader, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = data
+	return nil
+}
+
+func (s *InMemoryObjectStore) PresignGetURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.objects[key]; !exists {
+		return "", fmt.Errorf("object not found")
+	}
+	return "memory://" + key, nil
+}
+
+// Download ignores the bucket, the objects of all buckets share the store
+func (s *InMemoryObjectStore) Download(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, exists := s.objects[key]
+	if !exists {
+		return nil, fmt.Errorf("object not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Object returns the content of an uploaded object
+func (s *InMemoryObjectStore) Object(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, exists := s.objects[key]
+	return data, exists
+}
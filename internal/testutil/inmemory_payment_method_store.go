@@ -0,0 +1,74 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/paymentmethod"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryPaymentMethodStore implements paymentmethod.Repository
+type InMemoryPaymentMethodStore struct {
+	mu  sync.RWMutex
+	pms map[string]*paymentmethod.PaymentMethod
+}
+
+func NewInMemoryPaymentMethodStore() *InMemoryPaymentMethodStore {
+	return &InMemoryPaymentMethodStore{
+		pms: make(map[string]*paymentmethod.PaymentMethod),
+	}
+}
+
+func (s *InMemoryPaymentMethodStore) Create(ctx context.Context, pm *paymentmethod.PaymentMethod) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.pms[pm.ID]; exists {
+		return fmt.Errorf("payment method already exists")
+	}
+	s.pms[pm.ID] = pm
+	return nil
+}
+
+func (s *InMemoryPaymentMethodStore) Get(ctx context.Context, id string) (*paymentmethod.PaymentMethod, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if pm, exists := s.pms[id]; exists && pm.TenantID == types.GetTenantID(ctx) && pm.Status == types.StatusPublished {
+		return pm, nil
+	}
+	return nil, fmt.Errorf("payment method not found")
+}
+
+func (s *InMemoryPaymentMethodStore) ListByCustomer(ctx context.Context, customerID string) ([]*paymentmethod.PaymentMethod, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*paymentmethod.PaymentMethod
+	for _, pm := range s.pms {
+		if pm.TenantID == types.GetTenantID(ctx) && pm.CustomerID == customerID && pm.Status == types.StatusPublished {
+			result = append(result, pm)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryPaymentMethodStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pm, exists := s.pms[id]
+	if !exists || pm.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("payment method not found")
+	}
+	pm.Status = types.StatusDeleted
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
 	"github.com/flexprice/flexprice/internal/domain/plan"
 	"github.com/flexprice/flexprice/internal/types"
 )
@@ -34,6 +36,7 @@ func (s *InMemoryPlanStore) Create(ctx context.Context, p *plan.Plan) error {
 		return fmt.Errorf("plan already exists")
 	}
 
+	p.Version = 1
 	s.plans[p.ID] = p
 	return nil
 }
@@ -54,6 +57,9 @@ func (s *InMemoryPlanStore) List(ctx context.Context, filter types.Filter) ([]*p
 
 	var result []*plan.Plan
 	for _, p := range s.plans {
+		if p.Status == types.StatusDeleted && !filter.IncludeDeleted {
+			continue
+		}
 		result = append(result, p)
 	}
 
@@ -84,10 +90,15 @@ func (s *InMemoryPlanStore) Update(ctx context.Context, p *plan.Plan) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.plans[p.ID]; !exists {
+	existing, exists := s.plans[p.ID]
+	if !exists {
 		return fmt.Errorf("plan not found")
 	}
+	if existing.Version != p.Version {
+		return domainErrors.NewVersionConflictError("plan", p.ID, existing.Version)
+	}
 
+	p.Version++
 	s.plans[p.ID] = p
 	return nil
 }
@@ -96,10 +107,49 @@ func (s *InMemoryPlanStore) Delete(ctx context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.plans[id]; !exists {
+	p, exists := s.plans[id]
+	if !exists {
 		return fmt.Errorf("plan not found")
 	}
 
-	delete(s.plans, id)
+	now := time.Now().UTC()
+	p.Status = types.StatusDeleted
+	p.DeletedAt = &now
+	p.UpdatedAt = now
+	p.Version++
 	return nil
 }
+
+func (s *InMemoryPlanStore) Restore(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, exists := s.plans[id]
+	if !exists {
+		return fmt.Errorf("plan not found")
+	}
+
+	if p.Status == types.StatusDeleted {
+		p.Status = types.StatusPublished
+		p.DeletedAt = nil
+		p.UpdatedAt = time.Now().UTC()
+		p.Version++
+	}
+	return nil
+}
+
+// PurgeDeleted removes the plans deleted before the given time. The store does
+// not know the records referring to them, so none is kept
+func (s *InMemoryPlanStore) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, p := range s.plans {
+		if p.Status == types.StatusDeleted && p.DeletedAt != nil && p.DeletedAt.Before(before) {
+			delete(s.plans, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
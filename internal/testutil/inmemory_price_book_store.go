@@ -0,0 +1,74 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/pricebook"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryPriceBookStore implements pricebook.Repository
+type InMemoryPriceBookStore struct {
+	mu    sync.RWMutex
+	books map[string]*pricebook.PriceBook
+}
+
+func NewInMemoryPriceBookStore() *InMemoryPriceBookStore {
+	return &InMemoryPriceBookStore{
+		books: make(map[string]*pricebook.PriceBook),
+	}
+}
+
+func (s *InMemoryPriceBookStore) Create(ctx context.Context, book *pricebook.PriceBook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.books[book.ID]; exists {
+		return fmt.Errorf("price book already exists")
+	}
+	s.books[book.ID] = book
+	return nil
+}
+
+func (s *InMemoryPriceBookStore) Get(ctx context.Context, id string) (*pricebook.PriceBook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if book, exists := s.books[id]; exists && book.TenantID == types.GetTenantID(ctx) {
+		return book, nil
+	}
+	return nil, fmt.Errorf("price book not found")
+}
+
+func (s *InMemoryPriceBookStore) ListByPlan(ctx context.Context, planID string) ([]*pricebook.PriceBook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*pricebook.PriceBook
+	for _, book := range s.books {
+		if book.TenantID == types.GetTenantID(ctx) && book.Status == types.StatusPublished && book.PlanID == planID {
+			result = append(result, book)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryPriceBookStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	book, exists := s.books[id]
+	if !exists || book.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("price book not found")
+	}
+	book.Status = types.StatusDeleted
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/flexprice/flexprice/internal/domain/price"
 	"github.com/flexprice/flexprice/internal/types"
@@ -59,7 +60,7 @@ func (s *InMemoryPriceStore) GetByPlanID(ctx context.Context, planID string) ([]
 	tenantID, _ := ctx.Value(types.CtxTenantID).(string)
 	var result []*price.Price
 	for _, p := range s.prices {
-		if p.PlanID == planID && p.TenantID == tenantID {
+		if p.PlanID == planID && p.TenantID == tenantID && p.Status == types.StatusPublished {
 			result = append(result, p)
 		}
 	}
@@ -78,6 +79,9 @@ func (s *InMemoryPriceStore) List(ctx context.Context, filter types.Filter) ([]*
 
 	var result []*price.Price
 	for _, p := range s.prices {
+		if p.Status == types.StatusDeleted && !filter.IncludeDeleted {
+			continue
+		}
 		result = append(result, p)
 	}
 
@@ -120,10 +124,47 @@ func (s *InMemoryPriceStore) Delete(ctx context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.prices[id]; !exists {
+	p, exists := s.prices[id]
+	if !exists {
+		return fmt.Errorf("price not found")
+	}
+
+	now := time.Now().UTC()
+	p.Status = types.StatusDeleted
+	p.DeletedAt = &now
+	p.UpdatedAt = now
+	return nil
+}
+
+func (s *InMemoryPriceStore) Restore(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, exists := s.prices[id]
+	if !exists {
 		return fmt.Errorf("price not found")
 	}
 
-	delete(s.prices, id)
+	if p.Status == types.StatusDeleted {
+		p.Status = types.StatusPublished
+		p.DeletedAt = nil
+		p.UpdatedAt = time.Now().UTC()
+	}
 	return nil
 }
+
+// PurgeDeleted removes the prices deleted before the given time. The store does
+// not know the records referring to them, so none is kept
+func (s *InMemoryPriceStore) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, p := range s.prices {
+		if p.Status == types.StatusDeleted && p.DeletedAt != nil && p.DeletedAt.Before(before) {
+			delete(s.prices, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
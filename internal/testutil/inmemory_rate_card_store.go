@@ -0,0 +1,81 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/ratecard"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryRateCardStore implements ratecard.Repository
+type InMemoryRateCardStore struct {
+	mu    sync.RWMutex
+	cards map[string]*ratecard.RateCard
+}
+
+func NewInMemoryRateCardStore() *InMemoryRateCardStore {
+	return &InMemoryRateCardStore{
+		cards: make(map[string]*ratecard.RateCard),
+	}
+}
+
+func (s *InMemoryRateCardStore) Create(ctx context.Context, card *ratecard.RateCard) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.cards[card.ID]; exists {
+		return fmt.Errorf("rate card already exists")
+	}
+	for _, existing := range s.cards {
+		if existing.TenantID == card.TenantID && existing.Status == types.StatusPublished &&
+			existing.CustomerID == card.CustomerID && existing.SubscriptionID == card.SubscriptionID &&
+			existing.Version == card.Version {
+			return fmt.Errorf("rate card version %d already exists", card.Version)
+		}
+	}
+	s.cards[card.ID] = card
+	return nil
+}
+
+func (s *InMemoryRateCardStore) Get(ctx context.Context, id string) (*ratecard.RateCard, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if card, exists := s.cards[id]; exists && card.TenantID == types.GetTenantID(ctx) && card.Status == types.StatusPublished {
+		return card, nil
+	}
+	return nil, fmt.Errorf("rate card not found")
+}
+
+func (s *InMemoryRateCardStore) ListByCustomer(ctx context.Context, customerID string) ([]*ratecard.RateCard, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*ratecard.RateCard
+	for _, card := range s.cards {
+		if card.TenantID == types.GetTenantID(ctx) && card.Status == types.StatusPublished && card.CustomerID == customerID {
+			result = append(result, card)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryRateCardStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card, exists := s.cards[id]
+	if !exists || card.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("rate card not found")
+	}
+	card.Status = types.StatusDeleted
+	return nil
+}
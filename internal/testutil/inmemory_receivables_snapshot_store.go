@@ -0,0 +1,70 @@
+package testutil
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/analytics"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryReceivablesSnapshotStore implements analytics.ReceivablesRepository
+type InMemoryReceivablesSnapshotStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]map[time.Time][]*analytics.ReceivablesSnapshot
+	asOf      map[string]map[time.Time]time.Time
+}
+
+func NewInMemoryReceivablesSnapshotStore() *InMemoryReceivablesSnapshotStore {
+	return &InMemoryReceivablesSnapshotStore{
+		snapshots: make(map[string]map[time.Time][]*analytics.ReceivablesSnapshot),
+		asOf:      make(map[string]map[time.Time]time.Time),
+	}
+}
+
+func (s *InMemoryReceivablesSnapshotStore) ReplaceDay(ctx context.Context, day, asOf time.Time, snapshots []*analytics.ReceivablesSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := types.GetTenantID(ctx)
+	if s.snapshots[tenantID] == nil {
+		s.snapshots[tenantID] = make(map[time.Time][]*analytics.ReceivablesSnapshot)
+		s.asOf[tenantID] = make(map[time.Time]time.Time)
+	}
+	s.snapshots[tenantID][day.UTC()] = append([]*analytics.ReceivablesSnapshot{}, snapshots...)
+	s.asOf[tenantID][day.UTC()] = asOf
+	return nil
+}
+
+func (s *InMemoryReceivablesSnapshotStore) GetSnapshotAsOf(ctx context.Context, day time.Time) (*time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	asOf, ok := s.asOf[types.GetTenantID(ctx)][day.UTC()]
+	if !ok {
+		return nil, nil
+	}
+	return &asOf, nil
+}
+
+func (s *InMemoryReceivablesSnapshotStore) List(ctx context.Context, day time.Time, customerID string) ([]*analytics.ReceivablesSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*analytics.ReceivablesSnapshot
+	for _, snapshot := range s.snapshots[types.GetTenantID(ctx)][day.UTC()] {
+		if customerID == "" || snapshot.CustomerID == customerID {
+			result = append(result, snapshot)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CustomerID == result[j].CustomerID {
+			return result[i].Currency < result[j].Currency
+		}
+		return result[i].CustomerID < result[j].CustomerID
+	})
+	return result, nil
+}
@@ -0,0 +1,212 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/report"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryReportStore implements report.Repository
+type InMemoryReportStore struct {
+	mu        sync.RWMutex
+	templates map[string]*report.Template
+	runs      map[string]*report.Run
+}
+
+func NewInMemoryReportStore() *InMemoryReportStore {
+	return &InMemoryReportStore{
+		templates: make(map[string]*report.Template),
+		runs:      make(map[string]*report.Run),
+	}
+}
+
+func inTenant(ctx context.Context, m types.BaseModel) bool {
+	return m.TenantID == types.GetTenantID(ctx) && m.Status == types.StatusPublished
+}
+
+func (s *InMemoryReportStore) CreateTemplate(ctx context.Context, template *report.Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.templates[template.ID]; exists {
+		return fmt.Errorf("report template already exists")
+	}
+	t := *template
+	s.templates[template.ID] = &t
+	return nil
+}
+
+func (s *InMemoryReportStore) GetTemplate(ctx context.Context, id string) (*report.Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if t, exists := s.templates[id]; exists && inTenant(ctx, t.BaseModel) {
+		template := *t
+		return &template, nil
+	}
+	return nil, fmt.Errorf("report template not found")
+}
+
+func (s *InMemoryReportStore) ListTemplates(ctx context.Context, filter types.Filter) ([]*report.Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*report.Template
+	for _, t := range s.templates {
+		if inTenant(ctx, t.BaseModel) {
+			template := *t
+			result = append(result, &template)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryReportStore) UpdateTemplate(ctx context.Context, template *report.Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, exists := s.templates[template.ID]; !exists || !inTenant(ctx, t.BaseModel) {
+		return fmt.Errorf("report template not found")
+	}
+	t := *template
+	s.templates[template.ID] = &t
+	return nil
+}
+
+func (s *InMemoryReportStore) DeleteTemplate(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, exists := s.templates[id]
+	if !exists || !inTenant(ctx, t.BaseModel) {
+		return fmt.Errorf("report template not found")
+	}
+	t.Status = types.StatusDeleted
+	return nil
+}
+
+func (s *InMemoryReportStore) ClaimDueTemplates(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*report.Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*report.Template
+	for _, t := range s.templates {
+		if t.Schedule != types.ReportScheduleNone && t.Status == types.StatusPublished &&
+			t.NextRunAt != nil && !t.NextRunAt.After(now) {
+			due = append(due, t)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextRunAt.Before(*due[j].NextRunAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leaseUntil := now.Add(lease)
+	result := make([]*report.Template, 0, len(due))
+	for _, t := range due {
+		t.NextRunAt = &leaseUntil
+		template := *t
+		result = append(result, &template)
+	}
+
+	return result, nil
+}
+
+func (s *InMemoryReportStore) CreateRun(ctx context.Context, run *report.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.runs[run.ID]; exists {
+		return fmt.Errorf("report run already exists")
+	}
+	r := *run
+	s.runs[run.ID] = &r
+	return nil
+}
+
+func (s *InMemoryReportStore) GetRun(ctx context.Context, id string) (*report.Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if r, exists := s.runs[id]; exists && inTenant(ctx, r.BaseModel) {
+		run := *r
+		return &run, nil
+	}
+	return nil, fmt.Errorf("report run not found")
+}
+
+func (s *InMemoryReportStore) ListRuns(ctx context.Context, filter *types.ReportRunFilter) ([]*report.Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*report.Run
+	for _, r := range s.runs {
+		if inTenant(ctx, r.BaseModel) && (filter.TemplateID == "" || r.TemplateID == filter.TemplateID) {
+			run := *r
+			result = append(result, &run)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryReportStore) UpdateRun(ctx context.Context, run *report.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, exists := s.runs[run.ID]; !exists || !inTenant(ctx, r.BaseModel) {
+		return fmt.Errorf("report run not found")
+	}
+	r := *run
+	s.runs[run.ID] = &r
+	return nil
+}
+
+func (s *InMemoryReportStore) ClaimPendingRuns(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*report.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimable []*report.Run
+	for _, r := range s.runs {
+		if r.Status == types.StatusPublished && (r.RunStatus == types.ReportRunStatusPending ||
+			(r.RunStatus == types.ReportRunStatusProcessing && r.LeaseUntil != nil && r.LeaseUntil.Before(now))) {
+			claimable = append(claimable, r)
+		}
+	}
+
+	sort.Slice(claimable, func(i, j int) bool {
+		return claimable[i].CreatedAt.Before(claimable[j].CreatedAt)
+	})
+	if len(claimable) > limit {
+		claimable = claimable[:limit]
+	}
+
+	leaseUntil := now.Add(lease)
+	result := make([]*report.Run, 0, len(claimable))
+	for _, r := range claimable {
+		r.RunStatus = types.ReportRunStatusProcessing
+		r.LeaseUntil = &leaseUntil
+		r.UpdatedAt = now
+		run := *r
+		result = append(result, &run)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package testutil
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/requestlog"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryRequestLogStore implements requestlog.Repository
+type InMemoryRequestLogStore struct {
+	mu   sync.RWMutex
+	logs []*requestlog.RequestLog
+}
+
+func NewInMemoryRequestLogStore() *InMemoryRequestLogStore {
+	return &InMemoryRequestLogStore{}
+}
+
+func (s *InMemoryRequestLogStore) Insert(ctx context.Context, log *requestlog.RequestLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logs = append(s.logs, log)
+	return nil
+}
+
+func (s *InMemoryRequestLogStore) List(ctx context.Context, filter *types.RequestLogFilter) ([]*requestlog.RequestLog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	environmentID := types.GetEnvironmentID(ctx)
+
+	var result []*requestlog.RequestLog
+	for _, l := range s.logs {
+		if l.TenantID != types.GetTenantID(ctx) {
+			continue
+		}
+		if environmentID != "" && l.EnvironmentID != environmentID {
+			continue
+		}
+		if filter.APIKeyID != "" && l.APIKeyID != filter.APIKeyID {
+			continue
+		}
+		if filter.Method != "" && l.Method != filter.Method {
+			continue
+		}
+		if filter.Endpoint != "" && l.Endpoint != filter.Endpoint {
+			continue
+		}
+		if filter.Status == types.RequestLogStatusSucceeded && l.StatusCode >= 400 {
+			continue
+		}
+		if filter.Status == types.RequestLogStatusFailed && l.StatusCode < 400 {
+			continue
+		}
+		if filter.StatusCode != 0 && l.StatusCode != filter.StatusCode {
+			continue
+		}
+		if filter.StartTime != nil && l.Timestamp.Before(*filter.StartTime) {
+			continue
+		}
+		if filter.EndTime != nil && l.Timestamp.After(*filter.EndTime) {
+			continue
+		}
+		result = append(result, l)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp.After(result[j].Timestamp)
+	})
+
+	if filter.Offset >= len(result) {
+		return nil, nil
+	}
+	result = result[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(result) {
+		result = result[:filter.Limit]
+	}
+
+	return result, nil
+}
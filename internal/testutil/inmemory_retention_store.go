@@ -0,0 +1,74 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/retention"
+)
+
+// InMemoryRetentionStore implements retention.Repository
+type InMemoryRetentionStore struct {
+	mu       sync.RWMutex
+	policies map[string]*retention.Policy
+}
+
+func NewInMemoryRetentionStore() *InMemoryRetentionStore {
+	return &InMemoryRetentionStore{
+		policies: make(map[string]*retention.Policy),
+	}
+}
+
+func (s *InMemoryRetentionStore) Upsert(ctx context.Context, policy *retention.Policy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.policies[policy.TenantID]; ok {
+		policy.ID = existing.ID
+		policy.CreatedAt = existing.CreatedAt
+		policy.CreatedBy = existing.CreatedBy
+	}
+	copied := *policy
+	s.policies[policy.TenantID] = &copied
+	return nil
+}
+
+func (s *InMemoryRetentionStore) Get(ctx context.Context, tenantID string) (*retention.Policy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policy, ok := s.policies[tenantID]
+	if !ok {
+		return nil, fmt.Errorf("event retention policy not found")
+	}
+	copied := *policy
+	return &copied, nil
+}
+
+func (s *InMemoryRetentionStore) List(ctx context.Context) ([]*retention.Policy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policies := make([]*retention.Policy, 0, len(s.policies))
+	for _, policy := range s.policies {
+		copied := *policy
+		policies = append(policies, &copied)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].TenantID < policies[j].TenantID
+	})
+	return policies, nil
+}
+
+func (s *InMemoryRetentionStore) Delete(ctx context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.policies[tenantID]; !ok {
+		return fmt.Errorf("event retention policy not found")
+	}
+	delete(s.policies, tenantID)
+	return nil
+}
@@ -0,0 +1,70 @@
+package testutil
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/analytics"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryRevenueSnapshotStore implements analytics.Repository
+type InMemoryRevenueSnapshotStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]map[time.Time][]*analytics.RevenueSnapshot
+}
+
+func NewInMemoryRevenueSnapshotStore() *InMemoryRevenueSnapshotStore {
+	return &InMemoryRevenueSnapshotStore{
+		snapshots: make(map[string]map[time.Time][]*analytics.RevenueSnapshot),
+	}
+}
+
+func (s *InMemoryRevenueSnapshotStore) ReplaceMonth(ctx context.Context, month time.Time, snapshots []*analytics.RevenueSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := types.GetTenantID(ctx)
+	if s.snapshots[tenantID] == nil {
+		s.snapshots[tenantID] = make(map[time.Time][]*analytics.RevenueSnapshot)
+	}
+	s.snapshots[tenantID][month.UTC()] = append([]*analytics.RevenueSnapshot{}, snapshots...)
+	return nil
+}
+
+func (s *InMemoryRevenueSnapshotStore) ListMonths(ctx context.Context, start, end time.Time) ([]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var months []time.Time
+	for month := range s.snapshots[types.GetTenantID(ctx)] {
+		if !month.Before(start) && month.Before(end) {
+			months = append(months, month)
+		}
+	}
+
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+	return months, nil
+}
+
+func (s *InMemoryRevenueSnapshotStore) List(ctx context.Context, start, end time.Time) ([]*analytics.RevenueSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*analytics.RevenueSnapshot
+	for month, snapshots := range s.snapshots[types.GetTenantID(ctx)] {
+		if !month.Before(start) && month.Before(end) {
+			result = append(result, snapshots...)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Month.Equal(result[j].Month) {
+			return result[i].Month.Before(result[j].Month)
+		}
+		return result[i].SubscriptionID < result[j].SubscriptionID
+	})
+	return result, nil
+}
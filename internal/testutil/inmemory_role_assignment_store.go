@@ -0,0 +1,116 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/role"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryRoleAssignmentStore implements role.Repository
+type InMemoryRoleAssignmentStore struct {
+	mu          sync.RWMutex
+	assignments map[string]*role.Assignment
+}
+
+func NewInMemoryRoleAssignmentStore() *InMemoryRoleAssignmentStore {
+	return &InMemoryRoleAssignmentStore{
+		assignments: make(map[string]*role.Assignment),
+	}
+}
+
+func (s *InMemoryRoleAssignmentStore) Create(ctx context.Context, assignment *role.Assignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.assignments[assignment.ID]; exists {
+		return fmt.Errorf("role assignment already exists")
+	}
+	for _, existing := range s.assignments {
+		if existing.TenantID == assignment.TenantID && existing.Status == types.StatusPublished &&
+			existing.UserID == assignment.UserID && existing.EnvironmentID == assignment.EnvironmentID {
+			return fmt.Errorf("role assignment already exists")
+		}
+	}
+	copied := *assignment
+	s.assignments[assignment.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryRoleAssignmentStore) Get(ctx context.Context, id string) (*role.Assignment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if assignment, exists := s.assignments[id]; exists && assignment.TenantID == types.GetTenantID(ctx) && assignment.Status == types.StatusPublished {
+		copied := *assignment
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("role assignment not found")
+}
+
+func (s *InMemoryRoleAssignmentStore) List(ctx context.Context, filter *types.RoleAssignmentFilter) ([]*role.Assignment, error) {
+	return s.list(ctx, func(assignment *role.Assignment) bool {
+		if filter == nil {
+			return true
+		}
+		if filter.UserID != "" && assignment.UserID != filter.UserID {
+			return false
+		}
+		return filter.EnvironmentID == "" || assignment.EnvironmentID == filter.EnvironmentID
+	}), nil
+}
+
+func (s *InMemoryRoleAssignmentStore) ListByUser(ctx context.Context, userID string) ([]*role.Assignment, error) {
+	return s.list(ctx, func(assignment *role.Assignment) bool {
+		return assignment.UserID == userID
+	}), nil
+}
+
+func (s *InMemoryRoleAssignmentStore) list(ctx context.Context, match func(*role.Assignment) bool) []*role.Assignment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*role.Assignment
+	for _, assignment := range s.assignments {
+		if assignment.TenantID == types.GetTenantID(ctx) && assignment.Status == types.StatusPublished && match(assignment) {
+			copied := *assignment
+			result = append(result, &copied)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].UserID != result[j].UserID {
+			return result[i].UserID < result[j].UserID
+		}
+		return result[i].EnvironmentID < result[j].EnvironmentID
+	})
+	return result
+}
+
+func (s *InMemoryRoleAssignmentStore) Update(ctx context.Context, assignment *role.Assignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.assignments[assignment.ID]
+	if !exists || existing.TenantID != types.GetTenantID(ctx) || existing.Status != types.StatusPublished {
+		return fmt.Errorf("role assignment not found")
+	}
+	copied := *assignment
+	s.assignments[assignment.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryRoleAssignmentStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assignment, exists := s.assignments[id]
+	if !exists || assignment.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("role assignment not found")
+	}
+	assignment.Status = types.StatusDeleted
+	return nil
+}
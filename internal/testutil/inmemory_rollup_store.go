@@ -0,0 +1,250 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/events"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+type rollupKey struct {
+	tenantID           string
+	meterID            string
+	externalCustomerID string
+	customerID         string
+	windowSize         types.WindowSize
+	windowStart        time.Time
+}
+
+// InMemoryRollupStore implements events.RollupRepository over the events of an
+// InMemoryEventStore. Rollups are snapshots taken by RollUpUsage
+type InMemoryRollupStore struct {
+	mu         sync.RWMutex
+	eventStore *InMemoryEventStore
+	rollups    map[rollupKey]*events.RolledUpUsage
+	uniques    map[rollupKey]map[string]bool
+	watermarks map[string]time.Time
+}
+
+func NewInMemoryRollupStore(eventStore *InMemoryEventStore) *InMemoryRollupStore {
+	return &InMemoryRollupStore{
+		eventStore: eventStore,
+		rollups:    make(map[rollupKey]*events.RolledUpUsage),
+		uniques:    make(map[rollupKey]map[string]bool),
+		watermarks: make(map[string]time.Time),
+	}
+}
+
+func (s *InMemoryRollupStore) RollUpUsage(ctx context.Context, params *events.RollupParams) error {
+	var size time.Duration
+	switch params.WindowSize {
+	case types.WindowSizeHour:
+		size = time.Hour
+	case types.WindowSizeDay:
+		size = 24 * time.Hour
+	default:
+		return fmt.Errorf("unsupported rollup window size: %s", params.WindowSize)
+	}
+
+	s.eventStore.mu.RLock()
+	defer s.eventStore.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Like the ReplacingMergeTree, the windows computed replace the previous rollups
+	computed := make(map[rollupKey]*events.RolledUpUsage)
+	windowEvents := make(map[rollupKey][]*events.Event)
+	tenantID := types.GetTenantID(ctx)
+	for _, event := range s.eventStore.events {
+		if event.TenantID != tenantID || event.EventName != params.EventName {
+			continue
+		}
+		if event.Timestamp.Before(params.StartTime) || !event.Timestamp.Before(params.EndTime) {
+			continue
+		}
+
+		key := rollupKey{
+			tenantID:           tenantID,
+			meterID:            params.MeterID,
+			externalCustomerID: event.ExternalCustomerID,
+			customerID:         event.CustomerID,
+			windowSize:         params.WindowSize,
+			windowStart:        event.Timestamp.UTC().Truncate(size),
+		}
+		windowEvents[key] = append(windowEvents[key], event)
+
+		rollup, ok := computed[key]
+		if !ok {
+			rollup = &events.RolledUpUsage{ValueSum: decimal.Zero}
+			computed[key] = rollup
+		}
+		rollup.EventCount++
+		if val, ok := event.Properties[params.PropertyName].(float64); ok {
+			rollup.ValueSum = rollup.ValueSum.Add(decimal.NewFromFloat(val))
+		}
+	}
+
+	if params.AggregationType == types.AggregationCountUnique {
+		// The distinct values stand in for the sketch states, counts are exact
+		for key, windowEvents := range windowEvents {
+			s.uniques[key] = uniqueValues(windowEvents, params.PropertyName)
+		}
+		return nil
+	}
+
+	for key, rollup := range computed {
+		s.rollups[key] = rollup
+	}
+
+	return nil
+}
+
+func (s *InMemoryRollupStore) GetRollupWatermark(ctx context.Context, meterID string) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.watermarks[types.GetTenantID(ctx)+"/"+meterID], nil
+}
+
+func (s *InMemoryRollupStore) SetRollupWatermark(ctx context.Context, meterID string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watermarks[types.GetTenantID(ctx)+"/"+meterID] = until
+	return nil
+}
+
+func (s *InMemoryRollupStore) GetRolledUpUsage(ctx context.Context, params *events.RolledUpUsageParams) (*events.RolledUpUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := &events.RolledUpUsage{ValueSum: decimal.Zero}
+	tenantID := types.GetTenantID(ctx)
+	for key, rollup := range s.rollups {
+		if key.tenantID != tenantID || key.meterID != params.MeterID {
+			continue
+		}
+		if params.ExternalCustomerID != "" && key.externalCustomerID != params.ExternalCustomerID {
+			continue
+		}
+		if params.CustomerID != "" && key.customerID != params.CustomerID {
+			continue
+		}
+
+		for _, r := range params.Ranges {
+			if key.windowSize == r.WindowSize && !key.windowStart.Before(r.StartTime) && key.windowStart.Before(r.EndTime) {
+				result.EventCount += rollup.EventCount
+				result.ValueSum = result.ValueSum.Add(rollup.ValueSum)
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (s *InMemoryRollupStore) GetRolledUpWindows(ctx context.Context, params *events.RolledUpUsageParams) ([]*events.RolledUpWindow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	merged := make(map[rollupKey]*events.RolledUpWindow)
+	tenantID := types.GetTenantID(ctx)
+	for key, rollup := range s.rollups {
+		if key.tenantID != tenantID || key.meterID != params.MeterID {
+			continue
+		}
+		if params.ExternalCustomerID != "" && key.externalCustomerID != params.ExternalCustomerID {
+			continue
+		}
+		if params.CustomerID != "" && key.customerID != params.CustomerID {
+			continue
+		}
+
+		for _, r := range params.Ranges {
+			if key.windowSize == r.WindowSize && !key.windowStart.Before(r.StartTime) && key.windowStart.Before(r.EndTime) {
+				window := rollupKey{windowSize: key.windowSize, windowStart: key.windowStart}
+				if _, ok := merged[window]; !ok {
+					merged[window] = &events.RolledUpWindow{
+						RolledUpUsage: events.RolledUpUsage{ValueSum: decimal.Zero},
+						WindowSize:    key.windowSize,
+						WindowStart:   key.windowStart,
+					}
+				}
+				merged[window].EventCount += rollup.EventCount
+				merged[window].ValueSum = merged[window].ValueSum.Add(rollup.ValueSum)
+				break
+			}
+		}
+	}
+
+	windows := make([]*events.RolledUpWindow, 0, len(merged))
+	for _, window := range merged {
+		windows = append(windows, window)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if windows[i].WindowStart.Equal(windows[j].WindowStart) {
+			return windows[i].WindowSize < windows[j].WindowSize
+		}
+		return windows[i].WindowStart.Before(windows[j].WindowStart)
+	})
+
+	return windows, nil
+}
+
+func (s *InMemoryRollupStore) GetUniqueCount(ctx context.Context, params *events.UniqueCountParams) (uint64, error) {
+	s.eventStore.mu.RLock()
+	defer s.eventStore.mu.RUnlock()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantID := types.GetTenantID(ctx)
+	counted := make(map[string]bool)
+	for key, values := range s.uniques {
+		if key.tenantID != tenantID || key.meterID != params.MeterID {
+			continue
+		}
+		if params.ExternalCustomerID != "" && key.externalCustomerID != params.ExternalCustomerID {
+			continue
+		}
+		if params.CustomerID != "" && key.customerID != params.CustomerID {
+			continue
+		}
+
+		for _, r := range params.Ranges {
+			if key.windowSize == r.WindowSize && !key.windowStart.Before(r.StartTime) && key.windowStart.Before(r.EndTime) {
+				for value := range values {
+					counted[value] = true
+				}
+				break
+			}
+		}
+	}
+
+	var raw []*events.Event
+	for _, event := range s.eventStore.events {
+		if event.TenantID != tenantID || event.EventName != params.EventName {
+			continue
+		}
+		if params.ExternalCustomerID != "" && event.ExternalCustomerID != params.ExternalCustomerID {
+			continue
+		}
+		if params.CustomerID != "" && event.CustomerID != params.CustomerID {
+			continue
+		}
+
+		for _, r := range params.Raw {
+			if !event.Timestamp.Before(r.StartTime) && (r.EndTime.IsZero() || event.Timestamp.Before(r.EndTime)) {
+				raw = append(raw, event)
+				break
+			}
+		}
+	}
+	for value := range uniqueValues(raw, params.PropertyName) {
+		counted[value] = true
+	}
+
+	return uint64(len(counted)), nil
+}
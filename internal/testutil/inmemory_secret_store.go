@@ -0,0 +1,87 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/secret"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemorySecretStore implements secret.Repository
+type InMemorySecretStore struct {
+	mu      sync.RWMutex
+	secrets map[string]*secret.Secret
+}
+
+func NewInMemorySecretStore() *InMemorySecretStore {
+	return &InMemorySecretStore{
+		secrets: make(map[string]*secret.Secret),
+	}
+}
+
+func (s *InMemorySecretStore) Create(ctx context.Context, sec *secret.Secret) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.secrets[sec.ID]; exists {
+		return fmt.Errorf("secret already exists")
+	}
+	s.secrets[sec.ID] = sec
+	return nil
+}
+
+func (s *InMemorySecretStore) Get(ctx context.Context, id string) (*secret.Secret, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if sec, exists := s.secrets[id]; exists &&
+		sec.TenantID == types.GetTenantID(ctx) && sec.Status == types.StatusPublished {
+		return sec, nil
+	}
+	return nil, fmt.Errorf("secret not found")
+}
+
+func (s *InMemorySecretStore) GetByHash(ctx context.Context, keyHash string) (*secret.Secret, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, sec := range s.secrets {
+		if sec.KeyHash == keyHash && sec.Status == types.StatusPublished {
+			return sec, nil
+		}
+	}
+	return nil, fmt.Errorf("secret not found")
+}
+
+func (s *InMemorySecretStore) List(ctx context.Context, filter types.Filter) ([]*secret.Secret, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*secret.Secret
+	for _, sec := range s.secrets {
+		if sec.TenantID == types.GetTenantID(ctx) && sec.Status == types.StatusPublished {
+			result = append(result, sec)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemorySecretStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sec, exists := s.secrets[id]
+	if !exists || sec.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("secret not found")
+	}
+	sec.Status = types.StatusDeleted
+	return nil
+}
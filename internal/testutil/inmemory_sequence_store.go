@@ -0,0 +1,66 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
+	"github.com/flexprice/flexprice/internal/domain/sequence"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemorySequenceStore implements sequence.Repository
+type InMemorySequenceStore struct {
+	mu       sync.Mutex
+	configs  map[string]*sequence.InvoiceNumberingConfig
+	counters map[string]int64
+}
+
+func NewInMemorySequenceStore() *InMemorySequenceStore {
+	return &InMemorySequenceStore{
+		configs:  make(map[string]*sequence.InvoiceNumberingConfig),
+		counters: make(map[string]int64),
+	}
+}
+
+func scopeKey(ctx context.Context) string {
+	return types.GetTenantID(ctx) + "/" + types.GetEnvironmentID(ctx)
+}
+
+func (s *InMemorySequenceStore) GetInvoiceNumberingConfig(ctx context.Context) (*sequence.InvoiceNumberingConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.configs[scopeKey(ctx)]; ok {
+		copied := *c
+		return &copied, nil
+	}
+	return sequence.DefaultInvoiceNumberingConfig(types.GetTenantID(ctx), types.GetEnvironmentID(ctx)), nil
+}
+
+func (s *InMemorySequenceStore) UpsertInvoiceNumberingConfig(ctx context.Context, c *sequence.InvoiceNumberingConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var current int
+	if existing, ok := s.configs[scopeKey(ctx)]; ok {
+		current = existing.Version
+	}
+	if current != c.Version {
+		return domainErrors.NewVersionConflictError("invoice numbering config", c.TenantID, current)
+	}
+
+	c.Version++
+	copied := *c
+	s.configs[scopeKey(ctx)] = &copied
+	return nil
+}
+
+func (s *InMemorySequenceStore) NextValue(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := scopeKey(ctx) + "/" + key
+	s.counters[k]++
+	return s.counters[k], nil
+}
@@ -0,0 +1,64 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/sso"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemorySSOStore implements sso.Repository
+type InMemorySSOStore struct {
+	mu      sync.RWMutex
+	configs map[string]*sso.Config
+}
+
+func NewInMemorySSOStore() *InMemorySSOStore {
+	return &InMemorySSOStore{
+		configs: make(map[string]*sso.Config),
+	}
+}
+
+func (s *InMemorySSOStore) Get(ctx context.Context) (*sso.Config, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if c, ok := s.configs[types.GetTenantID(ctx)]; ok {
+		copied := *c
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (s *InMemorySSOStore) GetByDomain(ctx context.Context, domain string) (*sso.Config, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, c := range s.configs {
+		for _, d := range c.Domains {
+			if d == domain {
+				copied := *c
+				return &copied, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *InMemorySSOStore) Upsert(ctx context.Context, c *sso.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *c
+	s.configs[c.TenantID] = &copied
+	return nil
+}
+
+func (s *InMemorySSOStore) Delete(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.configs, types.GetTenantID(ctx))
+	return nil
+}
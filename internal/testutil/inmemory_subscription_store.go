@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
+	domainErrors "github.com/flexprice/flexprice/internal/domain/errors"
 	"github.com/flexprice/flexprice/internal/domain/subscription"
 	"github.com/flexprice/flexprice/internal/types"
 )
@@ -14,6 +16,7 @@ import (
 type InMemorySubscriptionStore struct {
 	mu            sync.RWMutex
 	subscriptions map[string]*subscription.Subscription
+	prorations    []*subscription.Proration
 }
 
 func NewInMemorySubscriptionStore() *InMemorySubscriptionStore {
@@ -34,6 +37,7 @@ func (s *InMemorySubscriptionStore) Create(ctx context.Context, sub *subscriptio
 		return fmt.Errorf("subscription already exists")
 	}
 
+	sub.Version = 1
 	s.subscriptions[sub.ID] = sub
 	return nil
 }
@@ -73,9 +77,32 @@ func (s *InMemorySubscriptionStore) List(ctx context.Context, filter *types.Subs
 
 	// Sort by created date desc (default)
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].CreatedAt.After(result[j].CreatedAt)
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID > result[j].ID
 	})
 
+	if filter != nil && filter.UsesCursor() {
+		cursor, err := types.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor != nil {
+			page := result[:0]
+			for _, sub := range result {
+				if sub.CreatedAt.Before(cursor.Time) || (sub.CreatedAt.Equal(cursor.Time) && sub.ID < cursor.ID) {
+					page = append(page, sub)
+				}
+			}
+			result = page
+		}
+		if filter.Limit > 0 && len(result) > filter.Limit {
+			result = result[:filter.Limit]
+		}
+		return result, nil
+	}
+
 	// Apply pagination if filter has limit/offset
 	if filter != nil {
 		start := filter.Offset
@@ -102,10 +129,15 @@ func (s *InMemorySubscriptionStore) Update(ctx context.Context, sub *subscriptio
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.subscriptions[sub.ID]; !exists {
+	existing, exists := s.subscriptions[sub.ID]
+	if !exists {
 		return fmt.Errorf("subscription not found")
 	}
+	if existing.Version != sub.Version {
+		return domainErrors.NewVersionConflictError("subscription", sub.ID, existing.Version)
+	}
 
+	sub.Version++
 	s.subscriptions[sub.ID] = sub
 	return nil
 }
@@ -121,3 +153,141 @@ func (s *InMemorySubscriptionStore) Delete(ctx context.Context, id string) error
 	delete(s.subscriptions, id)
 	return nil
 }
+
+func (s *InMemorySubscriptionStore) ListTrialsEndingBefore(ctx context.Context, before time.Time) ([]*subscription.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*subscription.Subscription
+	for _, sub := range s.subscriptions {
+		if sub.SubscriptionStatus != types.SubscriptionStatusTrialing || sub.Status != types.StatusPublished {
+			continue
+		}
+		if sub.TrialEnd == nil || sub.TrialEnd.After(before) {
+			continue
+		}
+		result = append(result, sub)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TrialEnd.Before(*result[j].TrialEnd)
+	})
+
+	return result, nil
+}
+
+func (s *InMemorySubscriptionStore) ListWithBillingThreshold(ctx context.Context) ([]*subscription.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*subscription.Subscription
+	for _, sub := range s.subscriptions {
+		if sub.SubscriptionStatus != types.SubscriptionStatusActive || sub.Status != types.StatusPublished {
+			continue
+		}
+		if sub.BillingThreshold.IsPositive() {
+			result = append(result, sub)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemorySubscriptionStore) ListPeriodsEndedBefore(ctx context.Context, before time.Time) ([]*subscription.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*subscription.Subscription
+	for _, sub := range s.subscriptions {
+		if sub.SubscriptionStatus != types.SubscriptionStatusActive || sub.Status != types.StatusPublished {
+			continue
+		}
+		if !sub.CurrentPeriodEnd.After(before) {
+			result = append(result, sub)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CurrentPeriodEnd.Before(result[j].CurrentPeriodEnd)
+	})
+
+	return result, nil
+}
+
+func (s *InMemorySubscriptionStore) ListCancelledBetween(ctx context.Context, start, end time.Time) ([]*subscription.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*subscription.Subscription
+	for _, sub := range s.subscriptions {
+		if sub.TenantID != types.GetTenantID(ctx) || sub.Status != types.StatusPublished {
+			continue
+		}
+		if sub.CancelledAt == nil || sub.CancelledAt.Before(start) || !sub.CancelledAt.Before(end) {
+			continue
+		}
+		result = append(result, sub)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CancelledAt.Before(*result[j].CancelledAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemorySubscriptionStore) UpdateLineItem(ctx context.Context, item *subscription.LineItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, exists := s.subscriptions[item.SubscriptionID]
+	if !exists {
+		return fmt.Errorf("subscription not found")
+	}
+
+	for i, existing := range sub.LineItems {
+		if existing.ID == item.ID {
+			sub.LineItems[i] = item
+			return nil
+		}
+	}
+	return fmt.Errorf("subscription line item not found")
+}
+
+func (s *InMemorySubscriptionStore) CreateProration(ctx context.Context, proration *subscription.Proration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prorations = append(s.prorations, proration)
+	return nil
+}
+
+func (s *InMemorySubscriptionStore) ListPendingProrations(ctx context.Context, subscriptionID string) ([]*subscription.Proration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*subscription.Proration
+	for _, proration := range s.prorations {
+		if proration.SubscriptionID == subscriptionID && proration.InvoiceID == "" {
+			result = append(result, proration)
+		}
+	}
+	return result, nil
+}
+
+func (s *InMemorySubscriptionStore) MarkProrationInvoiced(ctx context.Context, id, invoiceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, proration := range s.prorations {
+		if proration.ID == id {
+			proration.InvoiceID = invoiceID
+			return nil
+		}
+	}
+	return fmt.Errorf("proration not found")
+}
@@ -0,0 +1,100 @@
+// In-memory task repository for testing
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flexprice/flexprice/internal/domain/task"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryTaskStore implements task.Repository
+type InMemoryTaskStore struct {
+	mu    sync.RWMutex
+	tasks map[string]*task.Task
+}
+
+func NewInMemoryTaskStore() *InMemoryTaskStore {
+	return &InMemoryTaskStore{
+		tasks: make(map[string]*task.Task),
+	}
+}
+
+func (s *InMemoryTaskStore) Create(ctx context.Context, t *task.Task) error {
+	if t == nil {
+		return fmt.Errorf("task cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tasks[t.ID]; exists {
+		return fmt.Errorf("task already exists")
+	}
+
+	copied := *t
+	s.tasks[t.ID] = &copied
+	return nil
+}
+
+func (s *InMemoryTaskStore) Get(ctx context.Context, id string) (*task.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, exists := s.tasks[id]
+	if !exists || t.TenantID != types.GetTenantID(ctx) {
+		return nil, fmt.Errorf("task not found")
+	}
+
+	copied := *t
+	return &copied, nil
+}
+
+func (s *InMemoryTaskStore) List(ctx context.Context, filter types.Filter) ([]*task.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*task.Task
+	for _, t := range s.tasks {
+		if t.TenantID == types.GetTenantID(ctx) {
+			copied := *t
+			result = append(result, &copied)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	start := filter.Offset
+	if start >= len(result) {
+		return []*task.Task{}, nil
+	}
+
+	end := start + filter.Limit
+	if filter.Limit == 0 || end > len(result) {
+		end = len(result)
+	}
+
+	return result[start:end], nil
+}
+
+func (s *InMemoryTaskStore) Update(ctx context.Context, t *task.Task) error {
+	if t == nil {
+		return fmt.Errorf("task cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tasks[t.ID]; !exists {
+		return fmt.Errorf("task not found")
+	}
+
+	copied := *t
+	s.tasks[t.ID] = &copied
+	return nil
+}
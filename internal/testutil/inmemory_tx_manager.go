@@ -0,0 +1,15 @@
+package testutil
+
+import "context"
+
+// InMemoryTxManager implements postgres.TxManager by running the function
+// directly as the in-memory stores have no transactional semantics
+type InMemoryTxManager struct{}
+
+func NewInMemoryTxManager() *InMemoryTxManager {
+	return &InMemoryTxManager{}
+}
+
+func (m *InMemoryTxManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
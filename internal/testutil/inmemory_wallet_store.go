@@ -0,0 +1,300 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/wallet"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// InMemoryWalletStore implements wallet.Repository, wallet.AutoTopUpRepository and
+// wallet.CreditBucketRepository
+type InMemoryWalletStore struct {
+	mu           sync.RWMutex
+	wallets      map[string]*wallet.Wallet
+	transactions map[string]*wallet.Transaction
+	autoTopUps   map[string]*wallet.AutoTopUp
+	buckets      map[string]*wallet.CreditBucket
+}
+
+func NewInMemoryWalletStore() *InMemoryWalletStore {
+	return &InMemoryWalletStore{
+		wallets:      make(map[string]*wallet.Wallet),
+		transactions: make(map[string]*wallet.Transaction),
+		autoTopUps:   make(map[string]*wallet.AutoTopUp),
+		buckets:      make(map[string]*wallet.CreditBucket),
+	}
+}
+
+func (s *InMemoryWalletStore) CreateWallet(ctx context.Context, w *wallet.Wallet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.wallets[w.ID]; exists {
+		return fmt.Errorf("wallet already exists")
+	}
+	s.wallets[w.ID] = w
+	return nil
+}
+
+func (s *InMemoryWalletStore) GetWalletByID(ctx context.Context, id string) (*wallet.Wallet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if w, exists := s.wallets[id]; exists && w.TenantID == types.GetTenantID(ctx) {
+		return w, nil
+	}
+	return nil, fmt.Errorf("wallet not found")
+}
+
+func (s *InMemoryWalletStore) GetWalletsByCustomerID(ctx context.Context, customerID string) ([]*wallet.Wallet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*wallet.Wallet
+	for _, w := range s.wallets {
+		if w.CustomerID == customerID && w.TenantID == types.GetTenantID(ctx) && w.Status == types.StatusPublished {
+			result = append(result, w)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryWalletStore) UpdateWalletStatus(ctx context.Context, id string, status types.WalletStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, exists := s.wallets[id]
+	if !exists || w.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("wallet not found")
+	}
+	w.WalletStatus = status
+	return nil
+}
+
+func (s *InMemoryWalletStore) CreditWallet(ctx context.Context, req *wallet.WalletOperation) error {
+	if req.Type != types.TransactionTypeCredit {
+		return fmt.Errorf("invalid transaction type")
+	}
+	return s.processWalletOperation(ctx, req, req.Amount)
+}
+
+func (s *InMemoryWalletStore) DebitWallet(ctx context.Context, req *wallet.WalletOperation) error {
+	if req.Type != types.TransactionTypeDebit {
+		return fmt.Errorf("invalid transaction type")
+	}
+	return s.processWalletOperation(ctx, req, req.Amount.Neg())
+}
+
+func (s *InMemoryWalletStore) processWalletOperation(ctx context.Context, req *wallet.WalletOperation, delta decimal.Decimal) error {
+	if req.Amount.LessThanOrEqual(decimal.Zero) {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, exists := s.wallets[req.WalletID]
+	if !exists || w.TenantID != types.GetTenantID(ctx) || w.WalletStatus != types.WalletStatusActive {
+		return fmt.Errorf("no active wallet found")
+	}
+
+	newBalance := w.Balance.Add(delta)
+	if newBalance.LessThan(decimal.Zero) {
+		return fmt.Errorf("insufficient balance")
+	}
+
+	tx := &wallet.Transaction{
+		ID:            types.GenerateUUID(),
+		WalletID:      req.WalletID,
+		Type:          req.Type,
+		Amount:        req.Amount,
+		BalanceBefore: w.Balance,
+		BalanceAfter:  newBalance,
+		TxStatus:      types.TransactionStatusCompleted,
+		ReferenceType: req.ReferenceType,
+		ReferenceID:   req.ReferenceID,
+		Description:   req.Description,
+		Metadata:      req.Metadata,
+		BaseModel:     types.GetDefaultBaseModel(ctx),
+	}
+	// Keep transactions strictly ordered even when created within the same instant
+	tx.CreatedAt = time.Now().UTC().Add(time.Duration(len(s.transactions)) * time.Nanosecond)
+
+	w.Balance = newBalance
+	s.transactions[tx.ID] = tx
+	return nil
+}
+
+func (s *InMemoryWalletStore) GetTransactionByID(ctx context.Context, id string) (*wallet.Transaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if tx, exists := s.transactions[id]; exists && tx.TenantID == types.GetTenantID(ctx) {
+		return tx, nil
+	}
+	return nil, fmt.Errorf("transaction not found")
+}
+
+func (s *InMemoryWalletStore) GetTransactionsByWalletID(ctx context.Context, walletID string, limit, offset int) ([]*wallet.Transaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*wallet.Transaction
+	for _, tx := range s.transactions {
+		if tx.WalletID == walletID && tx.TenantID == types.GetTenantID(ctx) {
+			result = append(result, tx)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	if offset >= len(result) {
+		return []*wallet.Transaction{}, nil
+	}
+	end := offset + limit
+	if limit <= 0 || end > len(result) {
+		end = len(result)
+	}
+
+	return result[offset:end], nil
+}
+
+func (s *InMemoryWalletStore) UpdateTransactionStatus(ctx context.Context, id string, status types.TransactionStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, exists := s.transactions[id]
+	if !exists || tx.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("transaction not found")
+	}
+	tx.TxStatus = status
+	return nil
+}
+
+func (s *InMemoryWalletStore) UpsertAutoTopUp(ctx context.Context, rule *wallet.AutoTopUp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, exists := s.autoTopUps[rule.WalletID]; exists {
+		rule.ID = existing.ID
+		rule.CreatedAt = existing.CreatedAt
+		rule.CreatedBy = existing.CreatedBy
+	}
+	s.autoTopUps[rule.WalletID] = rule
+	return nil
+}
+
+func (s *InMemoryWalletStore) GetAutoTopUp(ctx context.Context, walletID string) (*wallet.AutoTopUp, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if rule, exists := s.autoTopUps[walletID]; exists && rule.TenantID == types.GetTenantID(ctx) {
+		return rule, nil
+	}
+	return nil, fmt.Errorf("wallet auto top-up not found")
+}
+
+func (s *InMemoryWalletStore) ListDueAutoTopUps(ctx context.Context) ([]*wallet.AutoTopUp, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*wallet.AutoTopUp
+	for _, rule := range s.autoTopUps {
+		w, exists := s.wallets[rule.WalletID]
+		if !rule.Enabled || !exists || w.WalletStatus != types.WalletStatusActive {
+			continue
+		}
+		if w.Balance.LessThan(rule.Threshold) {
+			result = append(result, rule)
+		}
+	}
+	return result, nil
+}
+
+func (s *InMemoryWalletStore) SumAutoTopUps(ctx context.Context, walletID string, since time.Time) (decimal.Decimal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sum := decimal.Zero
+	for _, tx := range s.transactions {
+		if tx.WalletID != walletID || tx.TenantID != types.GetTenantID(ctx) || tx.Type != types.TransactionTypeCredit {
+			continue
+		}
+		if tx.ReferenceType == wallet.AutoTopUpReferenceType && !tx.CreatedAt.Before(since) {
+			sum = sum.Add(tx.Amount)
+		}
+	}
+	return sum, nil
+}
+
+func (s *InMemoryWalletStore) CreateCreditBucket(ctx context.Context, bucket *wallet.CreditBucket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.buckets[bucket.ID]; exists {
+		return fmt.Errorf("wallet credit bucket already exists")
+	}
+	s.buckets[bucket.ID] = bucket
+	return nil
+}
+
+func (s *InMemoryWalletStore) UpdateCreditBucket(ctx context.Context, bucket *wallet.CreditBucket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, exists := s.buckets[bucket.ID]; !exists || existing.TenantID != types.GetTenantID(ctx) {
+		return fmt.Errorf("wallet credit bucket not found")
+	}
+	s.buckets[bucket.ID] = bucket
+	return nil
+}
+
+func (s *InMemoryWalletStore) ListCreditBuckets(ctx context.Context, walletID string) ([]*wallet.CreditBucket, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*wallet.CreditBucket
+	for _, bucket := range s.buckets {
+		if bucket.WalletID == walletID && bucket.TenantID == types.GetTenantID(ctx) && bucket.Remaining.IsPositive() {
+			result = append(result, bucket)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (s *InMemoryWalletStore) ListExpiredCreditBuckets(ctx context.Context, now time.Time) ([]*wallet.CreditBucket, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*wallet.CreditBucket
+	for _, bucket := range s.buckets {
+		if bucket.Remaining.IsPositive() && !bucket.ExpiresAt.After(now) {
+			result = append(result, bucket)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ExpiresAt.Before(result[j].ExpiresAt)
+	})
+	return result, nil
+}
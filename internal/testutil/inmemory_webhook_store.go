@@ -0,0 +1,222 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// InMemoryWebhookStore implements webhook.Repository
+type InMemoryWebhookStore struct {
+	mu         sync.RWMutex
+	endpoints  map[string]*webhook.Endpoint
+	deliveries map[string]*webhook.Delivery
+}
+
+func NewInMemoryWebhookStore() *InMemoryWebhookStore {
+	return &InMemoryWebhookStore{
+		endpoints:  make(map[string]*webhook.Endpoint),
+		deliveries: make(map[string]*webhook.Delivery),
+	}
+}
+
+func inEnvironment(ctx context.Context, e *webhook.Endpoint) bool {
+	return e.TenantID == types.GetTenantID(ctx) &&
+		e.EnvironmentID == types.GetEnvironmentID(ctx) &&
+		e.Status == types.StatusPublished
+}
+
+func (s *InMemoryWebhookStore) CreateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.endpoints[endpoint.ID]; exists {
+		return fmt.Errorf("webhook endpoint already exists")
+	}
+	e := *endpoint
+	s.endpoints[endpoint.ID] = &e
+	return nil
+}
+
+func (s *InMemoryWebhookStore) GetEndpoint(ctx context.Context, id string) (*webhook.Endpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if e, exists := s.endpoints[id]; exists && inEnvironment(ctx, e) {
+		endpoint := *e
+		return &endpoint, nil
+	}
+	return nil, fmt.Errorf("webhook endpoint not found")
+}
+
+func (s *InMemoryWebhookStore) ListEndpoints(ctx context.Context, filter types.Filter) ([]*webhook.Endpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*webhook.Endpoint
+	for _, e := range s.endpoints {
+		if inEnvironment(ctx, e) {
+			endpoint := *e
+			result = append(result, &endpoint)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryWebhookStore) ListSubscribedEndpoints(ctx context.Context, eventType types.WebhookEventType) ([]*webhook.Endpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*webhook.Endpoint
+	for _, e := range s.endpoints {
+		if e.TenantID != types.GetTenantID(ctx) || e.Status != types.StatusPublished || !e.Subscribes(eventType) {
+			continue
+		}
+		if envID := types.GetEnvironmentID(ctx); envID == "" || e.EnvironmentID == envID {
+			endpoint := *e
+			result = append(result, &endpoint)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (s *InMemoryWebhookStore) UpdateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, exists := s.endpoints[endpoint.ID]; !exists || !inEnvironment(ctx, e) {
+		return fmt.Errorf("webhook endpoint not found")
+	}
+	e := *endpoint
+	s.endpoints[endpoint.ID] = &e
+	return nil
+}
+
+func (s *InMemoryWebhookStore) DeleteEndpoint(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, exists := s.endpoints[id]
+	if !exists || !inEnvironment(ctx, e) {
+		return fmt.Errorf("webhook endpoint not found")
+	}
+	e.Status = types.StatusDeleted
+	return nil
+}
+
+func (s *InMemoryWebhookStore) CreateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.deliveries[delivery.ID]; exists {
+		return fmt.Errorf("webhook delivery already exists")
+	}
+	d := *delivery
+	s.deliveries[delivery.ID] = &d
+	return nil
+}
+
+func (s *InMemoryWebhookStore) GetDelivery(ctx context.Context, id string) (*webhook.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if d, exists := s.deliveries[id]; exists && d.TenantID == types.GetTenantID(ctx) {
+		delivery := *d
+		return &delivery, nil
+	}
+	return nil, fmt.Errorf("webhook delivery not found")
+}
+
+func (s *InMemoryWebhookStore) ListDeliveries(ctx context.Context, filter *types.WebhookDeliveryFilter) ([]*webhook.Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*webhook.Delivery
+	for _, d := range s.deliveries {
+		if d.TenantID != types.GetTenantID(ctx) {
+			continue
+		}
+		if filter.DeliveryStatus != "" && d.DeliveryStatus != filter.DeliveryStatus {
+			continue
+		}
+		if filter.EventType != "" && d.EventType != filter.EventType {
+			continue
+		}
+		if filter.EndpointID != "" && d.EndpointID != filter.EndpointID {
+			continue
+		}
+		delivery := *d
+		result = append(result, &delivery)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	if filter.Offset >= len(result) {
+		return []*webhook.Delivery{}, nil
+	}
+	end := len(result)
+	if filter.Limit > 0 && filter.Offset+filter.Limit < end {
+		end = filter.Offset + filter.Limit
+	}
+
+	return result[filter.Offset:end], nil
+}
+
+func (s *InMemoryWebhookStore) UpdateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.deliveries[delivery.ID]; !exists {
+		return fmt.Errorf("webhook delivery not found")
+	}
+	d := *delivery
+	s.deliveries[delivery.ID] = &d
+	return nil
+}
+
+func (s *InMemoryWebhookStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*webhook.Delivery
+	for _, d := range s.deliveries {
+		if d.DeliveryStatus == types.WebhookDeliveryStatusPending &&
+			d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leaseUntil := now.Add(lease)
+	result := make([]*webhook.Delivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = &leaseUntil
+		delivery := *d
+		result = append(result, &delivery)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package types
+
+import "time"
+
+// ActivityType is the kind of entry in a customer activity feed
+type ActivityType string
+
+const (
+	ActivityTypeSubscriptionCreated   ActivityType = "subscription.created"
+	ActivityTypeSubscriptionCancelled ActivityType = "subscription.cancelled"
+	ActivityTypeInvoiceCreated        ActivityType = "invoice.created"
+	ActivityTypeInvoiceFinalized      ActivityType = "invoice.finalized"
+	ActivityTypeWalletCreated         ActivityType = "wallet.created"
+	ActivityTypeWalletCredited        ActivityType = "wallet.credited"
+	ActivityTypeWalletDebited         ActivityType = "wallet.debited"
+)
+
+// ActivityFilter filters a customer activity feed
+type ActivityFilter struct {
+	Filter
+	ActivityTypes []ActivityType `form:"activity_type"`
+	StartTime     *time.Time     `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime       *time.Time     `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// Matches reports whether an activity of the given type at the given time passes the filter
+func (f *ActivityFilter) Matches(activityType ActivityType, at time.Time) bool {
+	if f.StartTime != nil && at.Before(*f.StartTime) {
+		return false
+	}
+
+	if f.EndTime != nil && !at.Before(*f.EndTime) {
+		return false
+	}
+
+	if len(f.ActivityTypes) == 0 {
+		return true
+	}
+
+	for _, t := range f.ActivityTypes {
+		if t == activityType {
+			return true
+		}
+	}
+	return false
+}
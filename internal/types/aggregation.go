@@ -8,11 +8,25 @@ const (
 	AggregationCount AggregationType = "COUNT"
 	AggregationSum   AggregationType = "SUM"
 	AggregationAvg   AggregationType = "AVG"
+
+	// AggregationCountUnique counts the distinct values of the field, exactly
+	// or approximately with a HyperLogLog sketch depending on the precision of
+	// the meter
+	AggregationCountUnique AggregationType = "COUNT_UNIQUE"
+
+	// AggregationPercentile is the value of the field below which the given
+	// percentile of the events fall, for ex the p99 latency of requests
+	AggregationPercentile AggregationType = "PERCENTILE"
+
+	// AggregationFormula is the aggregation of derived meters, whose usage is a
+	// formula over the usage of other meters. It aggregates no events itself so
+	// it is not a valid aggregation of event meters
+	AggregationFormula AggregationType = "FORMULA"
 )
 
 func (t AggregationType) Validate() bool {
 	switch t {
-	case AggregationCount, AggregationSum, AggregationAvg:
+	case AggregationCount, AggregationSum, AggregationAvg, AggregationCountUnique, AggregationPercentile:
 		return true
 	default:
 		return false
@@ -28,3 +42,23 @@ func (t AggregationType) RequiresField() bool {
 		return true
 	}
 }
+
+// IsDecomposable reports whether the usage over a period is computed from the
+// usage over its parts. Unique counts and percentiles are not, they are only
+// computed over all the events of the period at once
+func (t AggregationType) IsDecomposable() bool {
+	switch t {
+	case AggregationCountUnique, AggregationPercentile:
+		return false
+	default:
+		return true
+	}
+}
+
+// Precisions of the HyperLogLog sketches of approximate COUNT_UNIQUE meters.
+// A sketch of precision p has 2^p registers and a relative error of about
+// 1.04 / sqrt(2^p), from 1.6% at 12 to 0.1% at 20
+const (
+	MinUniquePrecision = 12
+	MaxUniquePrecision = 20
+)
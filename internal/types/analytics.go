@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// MaxRevenueAnalyticsMonths caps the number of months of a revenue analytics
+// request
+const MaxRevenueAnalyticsMonths = 36
+
+// CustomerSegmentMetadataKey is the key of the metadata of a customer holding
+// the segment its revenue is reported in
+const CustomerSegmentMetadataKey = "segment"
+
+// RevenueGroupBy is the dimension the revenue analytics are reported by. The
+// revenue of each currency is always reported on its own
+type RevenueGroupBy string
+
+const (
+	RevenueGroupByPlan     RevenueGroupBy = "plan"
+	RevenueGroupByCurrency RevenueGroupBy = "currency"
+	// RevenueGroupBySegment reports by the segment metadata of the customers
+	RevenueGroupBySegment RevenueGroupBy = "segment"
+)
+
+// RevenueAnalyticsFilter selects the calendar months, in UTC, from start_month
+// to end_month inclusive
+type RevenueAnalyticsFilter struct {
+	GroupBy    RevenueGroupBy `form:"group_by" binding:"omitempty,oneof=plan currency segment"`
+	StartMonth time.Time      `form:"start_month" binding:"required" time_format:"2006-01" time_utc:"1"`
+	EndMonth   time.Time      `form:"end_month" binding:"required" time_format:"2006-01" time_utc:"1"`
+}
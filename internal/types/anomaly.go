@@ -0,0 +1,51 @@
+package types
+
+import "time"
+
+// AnomalyType is the direction of a usage anomaly
+type AnomalyType string
+
+const (
+	// AnomalyTypeSpike is usage above the baseline, e.g. runaway usage
+	AnomalyTypeSpike AnomalyType = "spike"
+	// AnomalyTypeDrop is usage below the baseline, e.g. broken instrumentation
+	AnomalyTypeDrop AnomalyType = "drop"
+)
+
+type AnomalyFilter struct {
+	Filter
+	MeterID            string      `form:"meter_id"`
+	ExternalCustomerID string      `form:"external_customer_id"`
+	AnomalyType        AnomalyType `form:"anomaly_type"`
+	StartTime          *time.Time  `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime            *time.Time  `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (f *AnomalyFilter) ToMap() map[string]interface{} {
+	params := map[string]interface{}{
+		"offset": f.Offset,
+		"limit":  f.Limit,
+	}
+
+	if f.MeterID != "" {
+		params["meter_id"] = f.MeterID
+	}
+
+	if f.ExternalCustomerID != "" {
+		params["external_customer_id"] = f.ExternalCustomerID
+	}
+
+	if f.AnomalyType != "" {
+		params["anomaly_type"] = f.AnomalyType
+	}
+
+	if f.StartTime != nil {
+		params["start_time"] = *f.StartTime
+	}
+
+	if f.EndTime != nil {
+		params["end_time"] = *f.EndTime
+	}
+
+	return params
+}
@@ -0,0 +1,99 @@
+package types
+
+import "time"
+
+// AuditAction is the kind of change recorded by an audit log
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+)
+
+// AuditActorType is who made an audited change
+type AuditActorType string
+
+const (
+	AuditActorTypeUser   AuditActorType = "user"
+	AuditActorTypeAPIKey AuditActorType = "api_key"
+	// AuditActorTypeSystem changes are made by background jobs ex billing runs
+	AuditActorTypeSystem AuditActorType = "system"
+)
+
+// AuditEntityType is the kind of entity an audit log is about
+type AuditEntityType string
+
+const (
+	AuditEntityTypeCustomer           AuditEntityType = "customer"
+	AuditEntityTypePlan               AuditEntityType = "plan"
+	AuditEntityTypePrice              AuditEntityType = "price"
+	AuditEntityTypeMeter              AuditEntityType = "meter"
+	AuditEntityTypeRateCard           AuditEntityType = "rate_card"
+	AuditEntityTypePriceBook          AuditEntityType = "price_book"
+	AuditEntityTypeSubscription       AuditEntityType = "subscription"
+	AuditEntityTypeSubscriptionItem   AuditEntityType = "subscription_line_item"
+	AuditEntityTypeInvoice            AuditEntityType = "invoice"
+	AuditEntityTypeInvoiceLineItem    AuditEntityType = "invoice_line_item"
+	AuditEntityTypeInvoiceNumbering   AuditEntityType = "invoice_numbering"
+	AuditEntityTypeInvoicePayment     AuditEntityType = "invoice_payment"
+	AuditEntityTypeRefund             AuditEntityType = "refund"
+	AuditEntityTypeInvoiceDispute     AuditEntityType = "invoice_dispute"
+	AuditEntityTypeCreditAllocation   AuditEntityType = "credit_allocation"
+	AuditEntityTypeWallet             AuditEntityType = "wallet"
+	AuditEntityTypePaymentMethod      AuditEntityType = "payment_method"
+	AuditEntityTypeMandate            AuditEntityType = "mandate"
+	AuditEntityTypeAPIKey             AuditEntityType = "api_key"
+	AuditEntityTypeConnection         AuditEntityType = "connection"
+	AuditEntityTypeWebhookEndpoint    AuditEntityType = "webhook_endpoint"
+	AuditEntityTypeCancellationReason AuditEntityType = "cancellation_reason"
+	AuditEntityTypeRoleAssignment     AuditEntityType = "role_assignment"
+	AuditEntityTypeSSOConfig          AuditEntityType = "sso_config"
+	AuditEntityTypeEvent              AuditEntityType = "event"
+	AuditEntityTypeEventSchema        AuditEntityType = "event_schema"
+	AuditEntityTypeBudget             AuditEntityType = "budget"
+	AuditEntityTypeLegalEntity        AuditEntityType = "legal_entity"
+)
+
+// AuditLogFilter filters the audit logs, newest first. It does not embed Filter
+// as audit logs are never deleted and have no status
+type AuditLogFilter struct {
+	Limit      int             `form:"limit,default=50"`
+	Offset     int             `form:"offset,default=0"`
+	EntityType AuditEntityType `form:"entity_type"`
+	EntityID   string          `form:"entity_id"`
+	Action     AuditAction     `form:"action" binding:"omitempty,oneof=create update delete restore"`
+	// ActorID is a user ID or an API key ID
+	ActorID   string     `form:"actor_id"`
+	StartTime *time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime   *time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (f *AuditLogFilter) ToMap() map[string]interface{} {
+	params := map[string]interface{}{
+		"offset": f.Offset,
+		"limit":  f.Limit,
+	}
+
+	if f.EntityType != "" {
+		params["entity_type"] = f.EntityType
+	}
+	if f.EntityID != "" {
+		params["entity_id"] = f.EntityID
+	}
+	if f.Action != "" {
+		params["action"] = f.Action
+	}
+	if f.ActorID != "" {
+		params["actor_id"] = f.ActorID
+	}
+	if f.StartTime != nil {
+		params["start_time"] = *f.StartTime
+	}
+	if f.EndTime != nil {
+		params["end_time"] = *f.EndTime
+	}
+
+	return params
+}
@@ -0,0 +1,43 @@
+package types
+
+// BudgetPeriod is the window the spend of a budget is measured over
+type BudgetPeriod string
+
+const (
+	// BudgetPeriodMonthly measures the spend since the start of the calendar month
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+	// BudgetPeriodBillingPeriod measures the spend since the start of the current
+	// period of each subscription
+	BudgetPeriodBillingPeriod BudgetPeriod = "billing_period"
+)
+
+func (p BudgetPeriod) Validate() bool {
+	switch p {
+	case BudgetPeriodMonthly, BudgetPeriodBillingPeriod:
+		return true
+	}
+	return false
+}
+
+// BudgetAction is what happens once the spend of a budget exceeds its amount.
+// All actions send the budget.exceeded webhook
+type BudgetAction string
+
+const (
+	// BudgetActionAlert only sends the webhook
+	BudgetActionAlert BudgetAction = "alert"
+	// BudgetActionBlockUsage rejects the events of the meters billed to the
+	// subscriptions of the budget until its window resets
+	BudgetActionBlockUsage BudgetAction = "block_usage"
+	// BudgetActionPauseSubscription pauses the subscriptions of the budget until
+	// its window resets
+	BudgetActionPauseSubscription BudgetAction = "pause_subscription"
+)
+
+func (a BudgetAction) Validate() bool {
+	switch a {
+	case BudgetActionAlert, BudgetActionBlockUsage, BudgetActionPauseSubscription:
+		return true
+	}
+	return false
+}
@@ -9,6 +9,10 @@ const (
 	ModeAPI RunMode = "api"
 	// ModeConsumer is the mode for running just the consumer
 	ModeConsumer RunMode = "consumer"
+	// ModeWorker is the mode for running just the background jobs
+	ModeWorker RunMode = "worker"
+	// ModeGRPC is the mode for running just the gRPC server of the event APIs
+	ModeGRPC RunMode = "grpc"
 	// ModeAWSLambdaAPI is the mode for running the API server in AWS Lambda
 	ModeAWSLambdaAPI RunMode = "aws_lambda_api"
 	// ModeAWSLambdaConsumer is the mode for running the consumer in AWS Lambda
@@ -12,6 +12,13 @@ const (
 	CtxJWT           ContextKey = "ctx_jwt"
 	CtxEnvironmentID ContextKey = "ctx_environment_id"
 	CtxDBTransaction ContextKey = "ctx_db_transaction"
+	CtxAPIKeyID      ContextKey = "ctx_api_key_id"
+	CtxPermissions   ContextKey = "ctx_permissions"
+	CtxCustomerID    ContextKey = "ctx_customer_id"
+	CtxJobRunOptions ContextKey = "ctx_job_run_options"
+	CtxIfMatch       ContextKey = "ctx_if_match"
+	CtxConsistency   ContextKey = "ctx_consistency"
+	CtxRequestCache  ContextKey = "ctx_request_cache"
 
 	// Default values
 	DefaultTenantID = "00000000-0000-0000-0000-000000000000"
@@ -32,6 +39,14 @@ func GetTenantID(ctx context.Context) string {
 	return ""
 }
 
+// GetCustomerID returns the customer a portal session is scoped to
+func GetCustomerID(ctx context.Context) string {
+	if customerID, ok := ctx.Value(CtxCustomerID).(string); ok {
+		return customerID
+	}
+	return ""
+}
+
 func GetRequestID(ctx context.Context) string {
 	if requestID, ok := ctx.Value(CtxRequestID).(string); ok {
 		return requestID
@@ -46,9 +61,37 @@ func GetJWT(ctx context.Context) string {
 	return ""
 }
 
+// GetAPIKeyID returns the API key the request was authenticated with
+func GetAPIKeyID(ctx context.Context) string {
+	if apiKeyID, ok := ctx.Value(CtxAPIKeyID).(string); ok {
+		return apiKeyID
+	}
+	return ""
+}
+
 func GetEnvironmentID(ctx context.Context) string {
 	if environmentID, ok := ctx.Value(CtxEnvironmentID).(string); ok {
 		return environmentID
 	}
 	return ""
 }
+
+// ReadConsistency is whether the reads of a request may be served by a read
+// replica lagging behind the primary
+type ReadConsistency string
+
+const (
+	// ReadConsistencyStrong reads from the primary
+	ReadConsistencyStrong ReadConsistency = "strong"
+	// ReadConsistencyEventual reads from a replica when there is one
+	ReadConsistencyEventual ReadConsistency = "eventual"
+)
+
+// GetReadConsistency returns the read consistency of the request, strong unless
+// the request asked for eventual reads
+func GetReadConsistency(ctx context.Context) ReadConsistency {
+	if consistency, ok := ctx.Value(CtxConsistency).(ReadConsistency); ok {
+		return consistency
+	}
+	return ReadConsistencyStrong
+}
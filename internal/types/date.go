@@ -5,6 +5,22 @@ import (
 	"time"
 )
 
+// DefaultTimezone is the timezone billing periods are computed in when the
+// customer has none
+const DefaultTimezone = "UTC"
+
+// LoadTimezone returns the location of an IANA timezone, UTC when empty
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %s: %w", name, err)
+	}
+	return loc, nil
+}
+
 // NextBillingDate calculates the next billing date based on the given start time,
 // billing period, and billing period unit (the frequency multiplier).
 // For example:
@@ -70,3 +86,93 @@ func AddClampedDate(t time.Time, years, months, days int) time.Time {
 
 	return time.Date(newY, newM, newD, h, min, sec, t.Nanosecond(), t.Location())
 }
+
+// CalendarPeriodStart returns the start of the calendar billing period containing t.
+// Daily periods start at midnight, weekly periods on Monday, monthly periods on the
+// 1st of the month and annual periods on January 1st, in the location of t
+func CalendarPeriodStart(t time.Time, period BillingPeriod) (time.Time, error) {
+	y, m, d := t.Date()
+	switch period {
+	case BILLING_PERIOD_DAILY:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location()), nil
+	case BILLING_PERIOD_WEEKLY:
+		// Weekday counts from Sunday, weeks start on Monday
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location()), nil
+	case BILLING_PERIOD_MONTHLY:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location()), nil
+	case BILLING_PERIOD_ANNUAL:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location()), nil
+	default:
+		return t, fmt.Errorf("invalid billing period type: %s", period)
+	}
+}
+
+// CalendarPeriod returns the calendar billing period [start, end) containing t
+func CalendarPeriod(t time.Time, period BillingPeriod) (time.Time, time.Time, error) {
+	start, err := CalendarPeriodStart(t, period)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	end, err := NextBillingDate(start, 1, period)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return start, end, nil
+}
+
+// NextAnchoredBillingDate returns the end of the monthly billing period starting
+// at t of a subscription billed on a fixed day of the month. Periods start at
+// midnight on the anchor day, or on the last day of the months shorter than it,
+// so a subscription billed on the 31st is billed on February 28th and then on
+// March 31st. When t is not on the anchor day the period is the partial first
+// one and ends on the next anchor day. A subscription starting at any time of
+// its anchor day has a full first period
+func NextAnchoredBillingDate(t time.Time, anchorDay, months int) (time.Time, error) {
+	if anchorDay < 1 || anchorDay > 31 {
+		return t, fmt.Errorf("billing anchor day must be between 1 and 31, got %d", anchorDay)
+	}
+	if months <= 0 {
+		return t, fmt.Errorf("billing period unit must be a positive integer, got %d", months)
+	}
+
+	y, m, _ := t.Date()
+	anchor := anchorDate(y, m, anchorDay, t.Location())
+	if IsAnchorDay(t, anchorDay) {
+		return anchorDate(y, m+time.Month(months), anchorDay, t.Location()), nil
+	}
+	if anchor.After(t) {
+		return anchor, nil
+	}
+	return anchorDate(y, m+1, anchorDay, t.Location()), nil
+}
+
+// AnchoredPeriod returns the full billing period [start, end) ending at the next
+// anchored billing date of t, which is the period the partial first period of
+// an anchored subscription is prorated against
+func AnchoredPeriod(t time.Time, anchorDay, months int) (time.Time, time.Time, error) {
+	end, err := NextAnchoredBillingDate(t, anchorDay, months)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	y, m, _ := end.Date()
+	return anchorDate(y, m-time.Month(months), anchorDay, t.Location()), end, nil
+}
+
+// IsAnchorDay reports whether t falls on the anchor day of its month, or on the
+// last day of the months shorter than it, in the location of t
+func IsAnchorDay(t time.Time, anchorDay int) bool {
+	y, m, d := t.Date()
+	return anchorDate(y, m, anchorDay, t.Location()).Day() == d
+}
+
+// anchorDate returns midnight on the anchor day of the month, clamped to the
+// last day of the month
+func anchorDate(y int, m time.Month, anchorDay int, loc *time.Location) time.Time {
+	first := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(anchorDay, lastDay), 0, 0, 0, 0, loc)
+}
@@ -143,3 +143,94 @@ func TestAddClampedDate_MultipleYears(t *testing.T) {
 		t.Errorf("AddClampedDate Leap Year: got %v, want %v", got, want)
 	}
 }
+
+func TestCalendarPeriod(t *testing.T) {
+	at := time.Date(2024, time.February, 14, 15, 30, 0, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		period    BillingPeriod
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{BILLING_PERIOD_DAILY, time.Date(2024, time.February, 14, 0, 0, 0, 0, time.UTC), time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC)},
+		{BILLING_PERIOD_WEEKLY, time.Date(2024, time.February, 12, 0, 0, 0, 0, time.UTC), time.Date(2024, time.February, 19, 0, 0, 0, 0, time.UTC)},
+		{BILLING_PERIOD_MONTHLY, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{BILLING_PERIOD_ANNUAL, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		start, end, err := CalendarPeriod(at, tt.period)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+			t.Errorf("CalendarPeriod(%s): got [%v, %v), want [%v, %v)", tt.period, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+
+	// Sundays belong to the week started on the previous Monday
+	start, _, err := CalendarPeriod(time.Date(2024, time.February, 18, 0, 0, 0, 0, time.UTC), BILLING_PERIOD_WEEKLY)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2024, time.February, 12, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("CalendarPeriod(Sunday): got %v, want %v", start, want)
+	}
+
+	if _, _, err := CalendarPeriod(at, "HOURLY"); err == nil {
+		t.Error("expected an error for an invalid period")
+	}
+}
+
+func TestNextAnchoredBillingDate(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		anchorDay int
+		months    int
+		want      time.Time
+	}{
+		{"partial first period ends on the anchor day", time.Date(2024, time.March, 3, 10, 0, 0, 0, time.UTC), 15, 1, day(2024, time.March, 15)},
+		{"partial first period after the anchor day", day(2024, time.March, 20), 15, 1, day(2024, time.April, 15)},
+		{"full period", day(2024, time.March, 15), 15, 1, day(2024, time.April, 15)},
+		{"quarterly period", day(2024, time.March, 15), 15, 3, day(2024, time.June, 15)},
+		{"short month is clamped", day(2025, time.January, 31), 31, 1, day(2025, time.February, 28)},
+		{"month after a short month is back on the anchor day", day(2025, time.February, 28), 31, 1, day(2025, time.March, 31)},
+		{"leap year", day(2024, time.January, 30), 30, 1, day(2024, time.February, 29)},
+		{"year roll over", day(2024, time.December, 15), 15, 1, day(2025, time.January, 15)},
+		{"full period starting later on the anchor day", time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC), 15, 3, day(2024, time.June, 15)},
+		{"full period starting later on a clamped anchor day", time.Date(2025, time.February, 28, 15, 0, 0, 0, time.UTC), 31, 3, day(2025, time.May, 31)},
+		{"anchor day in the timezone of the subscription", time.Date(2024, time.March, 15, 23, 0, 0, 0, newYork), 15, 3, time.Date(2024, time.June, 15, 0, 0, 0, 0, newYork)},
+		{"partial period the day before in the timezone of the subscription", time.Date(2024, time.March, 14, 23, 0, 0, 0, newYork), 15, 3, time.Date(2024, time.March, 15, 0, 0, 0, 0, newYork)},
+	}
+
+	for _, tt := range tests {
+		got, err := NextAnchoredBillingDate(tt.at, tt.anchorDay, tt.months)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	start, end, err := AnchoredPeriod(day(2024, time.March, 3), 15, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !start.Equal(day(2024, time.February, 15)) || !end.Equal(day(2024, time.March, 15)) {
+		t.Errorf("AnchoredPeriod: got [%v, %v)", start, end)
+	}
+
+	if _, err := NextAnchoredBillingDate(day(2024, time.March, 3), 32, 1); err == nil {
+		t.Error("expected an error for an invalid anchor day")
+	}
+}
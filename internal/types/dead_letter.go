@@ -0,0 +1,35 @@
+package types
+
+// DeadLetterStatus is the state of a message that the consumer failed to process
+type DeadLetterStatus string
+
+const (
+	// DeadLetterStatusFailed messages are waiting to be replayed
+	DeadLetterStatusFailed DeadLetterStatus = "failed"
+	// DeadLetterStatusReplayed messages were published again to their topic. A
+	// replayed message that fails again is back to failed
+	DeadLetterStatusReplayed DeadLetterStatus = "replayed"
+)
+
+type DeadLetterFilter struct {
+	Filter
+	DeadLetterStatus DeadLetterStatus `form:"dead_letter_status"`
+	Topic            string           `form:"topic"`
+}
+
+func (f *DeadLetterFilter) ToMap() map[string]interface{} {
+	params := map[string]interface{}{
+		"offset": f.Offset,
+		"limit":  f.Limit,
+	}
+
+	if f.DeadLetterStatus != "" {
+		params["dead_letter_status"] = f.DeadLetterStatus
+	}
+
+	if f.Topic != "" {
+		params["topic"] = f.Topic
+	}
+
+	return params
+}
@@ -0,0 +1,76 @@
+package types
+
+// EmailTemplateType is the kind of email sent to customers. Each type has a
+// default template which tenants can replace
+type EmailTemplateType string
+
+const (
+	// EmailTemplateInvoiceFinalized is sent with the invoice once it is finalized
+	EmailTemplateInvoiceFinalized EmailTemplateType = "invoice_finalized"
+	// EmailTemplateTrialWillEnd reminds the customer that their trial ends soon
+	EmailTemplateTrialWillEnd EmailTemplateType = "trial_will_end"
+	// EmailTemplateReportReady links the CSV of a report run to its recipients
+	EmailTemplateReportReady EmailTemplateType = "report_ready"
+	// EmailTemplateDebitPreNotification announces the amount and the date of a
+	// bank debit as the scheme of its mandate requires
+	EmailTemplateDebitPreNotification EmailTemplateType = "debit_pre_notification"
+)
+
+func (t EmailTemplateType) Validate() bool {
+	switch t {
+	case EmailTemplateInvoiceFinalized, EmailTemplateTrialWillEnd, EmailTemplateReportReady,
+		EmailTemplateDebitPreNotification:
+		return true
+	}
+	return false
+}
+
+// EmailProvider is the service emails are sent with
+type EmailProvider string
+
+const (
+	EmailProviderSMTP     EmailProvider = "smtp"
+	EmailProviderSES      EmailProvider = "ses"
+	EmailProviderSendGrid EmailProvider = "sendgrid"
+)
+
+// EmailDeliveryStatus is the state of the delivery of one email
+type EmailDeliveryStatus string
+
+const (
+	// EmailDeliveryStatusPending deliveries are waiting for their next attempt
+	EmailDeliveryStatusPending EmailDeliveryStatus = "pending"
+	// EmailDeliveryStatusSent deliveries were accepted by the provider
+	EmailDeliveryStatusSent EmailDeliveryStatus = "sent"
+	// EmailDeliveryStatusFailed deliveries exhausted their attempts and stay
+	// there until resent
+	EmailDeliveryStatusFailed EmailDeliveryStatus = "failed"
+)
+
+type EmailDeliveryFilter struct {
+	Filter
+	DeliveryStatus EmailDeliveryStatus `form:"delivery_status"`
+	TemplateType   EmailTemplateType   `form:"template_type"`
+	EntityID       string              `form:"entity_id"`
+}
+
+func (f *EmailDeliveryFilter) ToMap() map[string]interface{} {
+	params := map[string]interface{}{
+		"offset": f.Offset,
+		"limit":  f.Limit,
+	}
+
+	if f.DeliveryStatus != "" {
+		params["delivery_status"] = f.DeliveryStatus
+	}
+
+	if f.TemplateType != "" {
+		params["template_type"] = f.TemplateType
+	}
+
+	if f.EntityID != "" {
+		params["entity_id"] = f.EntityID
+	}
+
+	return params
+}
@@ -0,0 +1,29 @@
+package types
+
+// EnvironmentType is the type of a tenant environment
+type EnvironmentType string
+
+const (
+	EnvironmentTypeProduction  EnvironmentType = "PRODUCTION"
+	EnvironmentTypeSandbox     EnvironmentType = "SANDBOX"
+	EnvironmentTypeDevelopment EnvironmentType = "DEVELOPMENT"
+)
+
+func (t EnvironmentType) Validate() bool {
+	switch t {
+	case EnvironmentTypeProduction, EnvironmentTypeSandbox, EnvironmentTypeDevelopment:
+		return true
+	default:
+		return false
+	}
+}
+
+// ResetStatus is the lifecycle status of an environment reset job
+type ResetStatus string
+
+const (
+	ResetStatusPending    ResetStatus = "pending"
+	ResetStatusProcessing ResetStatus = "processing"
+	ResetStatusCompleted  ResetStatus = "completed"
+	ResetStatusFailed     ResetStatus = "failed"
+)
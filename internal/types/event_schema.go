@@ -0,0 +1,70 @@
+package types
+
+// EventPropertyType is the JSON type that a property of an event must have
+type EventPropertyType string
+
+const (
+	EventPropertyTypeString  EventPropertyType = "string"
+	EventPropertyTypeNumber  EventPropertyType = "number"
+	EventPropertyTypeBoolean EventPropertyType = "boolean"
+	EventPropertyTypeObject  EventPropertyType = "object"
+	EventPropertyTypeArray   EventPropertyType = "array"
+)
+
+func (t EventPropertyType) Validate() bool {
+	switch t {
+	case EventPropertyTypeString, EventPropertyTypeNumber, EventPropertyTypeBoolean,
+		EventPropertyTypeObject, EventPropertyTypeArray:
+		return true
+	}
+	return false
+}
+
+// InvalidEventAction is what happens to an ingested event that does not match
+// the schema of its event name
+type InvalidEventAction string
+
+const (
+	// InvalidEventActionReject fails the ingestion of the event
+	InvalidEventActionReject InvalidEventAction = "reject"
+	// InvalidEventActionQuarantine keeps the event aside until it is fixed and
+	// re-submitted
+	InvalidEventActionQuarantine InvalidEventAction = "quarantine"
+)
+
+func (a InvalidEventAction) Validate() bool {
+	return a == InvalidEventActionReject || a == InvalidEventActionQuarantine
+}
+
+// QuarantineStatus is the state of a quarantined event
+type QuarantineStatus string
+
+const (
+	// QuarantineStatusQuarantined events are waiting to be fixed
+	QuarantineStatusQuarantined QuarantineStatus = "quarantined"
+	// QuarantineStatusResubmitted events were fixed and ingested
+	QuarantineStatusResubmitted QuarantineStatus = "resubmitted"
+)
+
+type QuarantinedEventFilter struct {
+	Filter
+	EventName        string           `form:"event_name"`
+	QuarantineStatus QuarantineStatus `form:"quarantine_status"`
+}
+
+func (f *QuarantinedEventFilter) ToMap() map[string]interface{} {
+	params := map[string]interface{}{
+		"offset": f.Offset,
+		"limit":  f.Limit,
+	}
+
+	if f.EventName != "" {
+		params["event_name"] = f.EventName
+	}
+
+	if f.QuarantineStatus != "" {
+		params["quarantine_status"] = f.QuarantineStatus
+	}
+
+	return params
+}
@@ -0,0 +1,13 @@
+package types
+
+// EventStoreBackend is the database the ingested events are stored in and
+// aggregated from
+type EventStoreBackend string
+
+const (
+	// EventStoreBackendClickHouse stores the events in ClickHouse
+	EventStoreBackendClickHouse EventStoreBackend = "clickhouse"
+	// EventStoreBackendTimescaleDB stores the events in the events table of the
+	// Postgres database, a hypertable when the TimescaleDB extension is installed
+	EventStoreBackendTimescaleDB EventStoreBackend = "timescaledb"
+)
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExpandField is a related record a response can embed
+type ExpandField string
+
+const (
+	ExpandPlan     ExpandField = "plan"
+	ExpandPrices   ExpandField = "prices"
+	ExpandCustomer ExpandField = "customer"
+)
+
+// Expand is the set of related records a response embeds
+type Expand []ExpandField
+
+var (
+	// DefaultSubscriptionExpand is what a subscription embeds without expand
+	DefaultSubscriptionExpand = Expand{ExpandPlan, ExpandPrices}
+	// DefaultSubscriptionListExpand is what the subscriptions of a list embed
+	// without expand
+	DefaultSubscriptionListExpand = Expand{ExpandPlan}
+)
+
+// ParseExpand parses the comma separated expand query parameter, an empty value
+// returns the defaults
+func ParseExpand(value string, defaults Expand) (Expand, error) {
+	if strings.TrimSpace(value) == "" {
+		return defaults, nil
+	}
+
+	var expand Expand
+	for _, field := range strings.Split(value, ",") {
+		switch f := ExpandField(strings.TrimSpace(field)); f {
+		case ExpandPlan, ExpandPrices, ExpandCustomer:
+			expand = append(expand, f)
+		default:
+			return nil, fmt.Errorf("invalid expand field %q", field)
+		}
+	}
+	return expand, nil
+}
+
+// Has reports whether the field is expanded. The prices are embedded in the
+// plan, expanding them expands the plan as well
+func (e Expand) Has(field ExpandField) bool {
+	for _, f := range e {
+		if f == field || (field == ExpandPlan && f == ExpandPrices) {
+			return true
+		}
+	}
+	return false
+}
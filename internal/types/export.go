@@ -0,0 +1,75 @@
+package types
+
+// ExportType is the kind of data being exported
+type ExportType string
+
+const (
+	// ExportTypeEvents exports the raw events as ingested
+	ExportTypeEvents ExportType = "EVENTS"
+	// ExportTypeUsage exports the aggregated usage of a meter in fixed windows
+	ExportTypeUsage ExportType = "USAGE"
+)
+
+func (t ExportType) Validate() bool {
+	switch t {
+	case ExportTypeEvents, ExportTypeUsage:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExportFormat is the file format of the exported object
+type ExportFormat string
+
+const (
+	ExportFormatParquet ExportFormat = "PARQUET"
+	ExportFormatCSV     ExportFormat = "CSV"
+)
+
+func (f ExportFormat) Validate() bool {
+	switch f {
+	case ExportFormatParquet, ExportFormatCSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// Extension returns the file extension for the export format
+func (f ExportFormat) Extension() string {
+	switch f {
+	case ExportFormatCSV:
+		return "csv"
+	default:
+		return "parquet"
+	}
+}
+
+// ContentType returns the MIME type for the export format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatCSV:
+		return "text/csv"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// ExportStatus is the lifecycle status of an export job
+type ExportStatus string
+
+const (
+	ExportStatusPending    ExportStatus = "pending"
+	ExportStatusProcessing ExportStatus = "processing"
+	ExportStatusCompleted  ExportStatus = "completed"
+	ExportStatusFailed     ExportStatus = "failed"
+)
+
+// StorageProvider is the object storage backend used for exports
+type StorageProvider string
+
+const (
+	StorageProviderS3  StorageProvider = "s3"
+	StorageProviderGCS StorageProvider = "gcs"
+)
@@ -0,0 +1,39 @@
+package types
+
+// FeatureFlag is a billing behavior that can be turned on or off per tenant
+// and environment, so risky changes are rolled out a tenant at a time
+type FeatureFlag string
+
+const (
+	// FeatureFlagThresholdBilling invoices subscriptions early once their usage
+	// crosses their billing threshold
+	FeatureFlagThresholdBilling FeatureFlag = "threshold_billing"
+	// FeatureFlagMidPeriodProration charges or credits the rest of the period
+	// when line items are added to or removed from subscriptions
+	FeatureFlagMidPeriodProration FeatureFlag = "mid_period_proration"
+	// FeatureFlagRollupUsageQueries reads the usage of meters from the hourly
+	// rollups instead of the raw events
+	FeatureFlagRollupUsageQueries FeatureFlag = "rollup_usage_queries"
+	// FeatureFlagPeriodRenewal moves the subscriptions to their next period once
+	// their current period is over and invoices the period that ended
+	FeatureFlagPeriodRenewal FeatureFlag = "period_renewal"
+)
+
+// FeatureFlags lists the flags with their default, which applies to the tenants
+// without an override
+var FeatureFlags = map[FeatureFlag]bool{
+	FeatureFlagThresholdBilling:   true,
+	FeatureFlagMidPeriodProration: true,
+	FeatureFlagRollupUsageQueries: true,
+	FeatureFlagPeriodRenewal:      false,
+}
+
+func (f FeatureFlag) Validate() bool {
+	_, ok := FeatureFlags[f]
+	return ok
+}
+
+// Default is whether the flag is on for the tenants without an override
+func (f FeatureFlag) Default() bool {
+	return FeatureFlags[f]
+}
@@ -1,16 +1,47 @@
 package types
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
 const (
 	DefaultFilterLimit  = 50
 	DefaultFilterOffset = 0
 )
 
+// PaginationMode selects how a list is paged
+type PaginationMode string
+
+const (
+	// PaginationModeOffset pages with limit and offset, it is the default so that
+	// existing clients keep working
+	PaginationModeOffset PaginationMode = "offset"
+	// PaginationModeCursor pages with the next_cursor of the previous page, the
+	// query seeks past its sort keys instead of skipping the rows before the page
+	PaginationModeCursor PaginationMode = "cursor"
+)
+
+// ErrInvalidCursor is returned when a list is requested with a cursor which was
+// not returned by a previous page
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type Filter struct {
 	Limit  int    `form:"limit,default=50"`
 	Offset int    `form:"offset,default=0"`
 	Status Status `form:"status,default=published"`
 	Sort   string `form:"sort,default=created_at"`
 	Order  string `form:"order,default=desc"`
+	// IncludeDeleted lists the deleted records along with the published ones,
+	// for the resources which can be restored
+	IncludeDeleted bool `form:"include_deleted"`
+	// Pagination opts into cursor pagination, offset is ignored in that mode
+	Pagination PaginationMode `form:"pagination"`
+	// Cursor is the next_cursor of the previous page and implies cursor pagination
+	Cursor string `form:"cursor"`
 }
 
 func GetDefaultFilter() Filter {
@@ -19,3 +50,56 @@ func GetDefaultFilter() Filter {
 		Offset: DefaultFilterOffset,
 	}
 }
+
+// UsesCursor reports whether the list is paged with cursors
+func (f Filter) UsesCursor() bool {
+	return f.Cursor != "" || f.Pagination == PaginationModeCursor
+}
+
+// Validate checks the pagination mode and the cursor of the filter
+func (f Filter) Validate() error {
+	switch f.Pagination {
+	case "", PaginationModeOffset, PaginationModeCursor:
+	default:
+		return fmt.Errorf("invalid pagination mode %q", f.Pagination)
+	}
+	if f.Cursor != "" && f.Pagination == PaginationModeOffset {
+		return fmt.Errorf("cursor cannot be used with offset pagination")
+	}
+	if _, err := DecodeCursor(f.Cursor); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PageCursor holds the sort keys of the last record of a page, the records
+// listed newest first are ordered on their creation time and then on their ID
+type PageCursor struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+// EncodeCursor returns the opaque token of the page following the record
+func EncodeCursor(t time.Time, id string) string {
+	data, _ := json.Marshal(PageCursor{Time: t.UTC(), ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the sort keys of a token made by EncodeCursor, or nil
+// for an empty token
+func DecodeCursor(token string) (*PageCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor PageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" || cursor.Time.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 11, 9, 10, 30, 0, 123456789, time.FixedZone("IST", 5*3600+1800))
+
+	cursor, err := DecodeCursor(EncodeCursor(created, "inv_123"))
+	require.NoError(t, err)
+	assert.True(t, cursor.Time.Equal(created))
+	assert.Equal(t, "inv_123", cursor.ID)
+
+	cursor, err = DecodeCursor("")
+	require.NoError(t, err)
+	assert.Nil(t, cursor)
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, token := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		_, err := DecodeCursor(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor, token)
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	cursor := EncodeCursor(time.Now(), "sub_1")
+
+	tests := []struct {
+		name       string
+		filter     Filter
+		wantErr    bool
+		usesCursor bool
+	}{
+		{name: "offset by default", filter: Filter{Offset: 20}},
+		{name: "first cursor page", filter: Filter{Pagination: PaginationModeCursor}, usesCursor: true},
+		{name: "cursor implies cursor mode", filter: Filter{Cursor: cursor}, usesCursor: true},
+		{name: "cursor with offset mode", filter: Filter{Pagination: PaginationModeOffset, Cursor: cursor}, wantErr: true},
+		{name: "unknown mode", filter: Filter{Pagination: "keyset"}, wantErr: true},
+		{name: "invalid cursor", filter: Filter{Cursor: "abc"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.usesCursor, tt.filter.UsesCursor())
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package types
+
+// ForecastModel is how the usage to come in a period is projected from the
+// usage to date
+type ForecastModel string
+
+const (
+	// ForecastModelLinear projects the usage at the run-rate of the period to date
+	ForecastModelLinear ForecastModel = "linear"
+	// ForecastModelSeasonal weighs the days left in the period by the usage of
+	// their day of the week over the last weeks
+	ForecastModelSeasonal ForecastModel = "seasonal"
+)
+
+// ForecastFilter selects the model of a spend forecast, linear by default
+type ForecastFilter struct {
+	Model ForecastModel `form:"model"`
+}
@@ -1,7 +1,13 @@
 package types
 
 const (
-	HeaderEnvironment   = "X-Environment-ID"
-	HeaderRequestID     = "X-Request-ID"
-	HeaderAuthorization = "Authorization"
+	HeaderEnvironment    = "X-Environment-ID"
+	HeaderRequestID      = "X-Request-ID"
+	HeaderAuthorization  = "Authorization"
+	HeaderAPIKey         = "X-API-Key"
+	HeaderAdminKey       = "X-Admin-Key"
+	HeaderIfMatch        = "If-Match"
+	HeaderETag           = "ETag"
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderConsistency    = "X-Consistency"
 )
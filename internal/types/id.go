@@ -0,0 +1,54 @@
+package types
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid"
+)
+
+// IDStrategy is how the IDs of new entities are generated. Every strategy yields
+// IDs in the UUID format as the primary keys of the entities are uuid columns
+type IDStrategy string
+
+const (
+	// IDStrategyUUID generates random version 4 UUIDs. It is the default
+	IDStrategyUUID IDStrategy = "uuid"
+	// IDStrategyULID generates ULIDs written in the UUID format. They sort by
+	// creation time, which keeps the inserts of downstream warehouses append only
+	IDStrategyULID IDStrategy = "ulid"
+)
+
+var idStrategy atomic.Value
+
+func init() {
+	idStrategy.Store(IDStrategyUUID)
+}
+
+// SetIDStrategy sets the strategy of GenerateUUID for the deployment. Existing
+// records keep their IDs. An empty strategy falls back to IDStrategyUUID
+func SetIDStrategy(strategy IDStrategy) error {
+	switch strategy {
+	case "":
+		strategy = IDStrategyUUID
+	case IDStrategyUUID, IDStrategyULID:
+	default:
+		return fmt.Errorf("unsupported id strategy: %s", strategy)
+	}
+
+	idStrategy.Store(strategy)
+	return nil
+}
+
+// GenerateUUID returns a new entity ID using the configured strategy
+func GenerateUUID() string {
+	if idStrategy.Load().(IDStrategy) == IDStrategyULID {
+		id := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader)
+		return uuid.UUID(id).String()
+	}
+
+	return uuid.New().String()
+}
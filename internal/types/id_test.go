@@ -0,0 +1,45 @@
+package types
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateUUID(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, SetIDStrategy(IDStrategyUUID))
+	})
+
+	t.Run("uuid", func(t *testing.T) {
+		require.NoError(t, SetIDStrategy(""))
+
+		id, err := uuid.Parse(GenerateUUID())
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(4), id.Version())
+	})
+
+	t.Run("ulid is time ordered", func(t *testing.T) {
+		require.NoError(t, SetIDStrategy(IDStrategyULID))
+
+		var ids []string
+		for i := 0; i < 3; i++ {
+			id := GenerateUUID()
+			_, err := uuid.Parse(id)
+			require.NoError(t, err)
+
+			ids = append(ids, id)
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		assert.True(t, sort.StringsAreSorted(ids), "ids %v", ids)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		assert.Error(t, SetIDStrategy("snowflake"))
+	})
+}
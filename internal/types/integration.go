@@ -0,0 +1,171 @@
+package types
+
+// IntegrationProvider is the external system a connection syncs to
+type IntegrationProvider string
+
+const (
+	IntegrationProviderStripe     IntegrationProvider = "stripe"
+	IntegrationProviderHubSpot    IntegrationProvider = "hubspot"
+	IntegrationProviderNetSuite   IntegrationProvider = "netsuite"
+	IntegrationProviderQuickBooks IntegrationProvider = "quickbooks"
+	IntegrationProviderSalesforce IntegrationProvider = "salesforce"
+	IntegrationProviderAdyen      IntegrationProvider = "adyen"
+	IntegrationProviderRazorpay   IntegrationProvider = "razorpay"
+	IntegrationProviderAvalara    IntegrationProvider = "avalara"
+	IntegrationProviderTaxJar     IntegrationProvider = "taxjar"
+)
+
+func (p IntegrationProvider) Validate() bool {
+	switch p {
+	case IntegrationProviderStripe, IntegrationProviderHubSpot,
+		IntegrationProviderNetSuite, IntegrationProviderQuickBooks,
+		IntegrationProviderSalesforce, IntegrationProviderAdyen,
+		IntegrationProviderRazorpay, IntegrationProviderAvalara,
+		IntegrationProviderTaxJar:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsLedger reports whether the provider is an accounting system that finalized
+// invoices and credit notes are pushed to
+func (p IntegrationProvider) IsLedger() bool {
+	return p == IntegrationProviderNetSuite || p == IntegrationProviderQuickBooks
+}
+
+// IsGateway reports whether the provider is a payment gateway the payments of
+// the customers are collected through
+func (p IntegrationProvider) IsGateway() bool {
+	switch p {
+	case IntegrationProviderStripe, IntegrationProviderAdyen, IntegrationProviderRazorpay:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTax reports whether the provider calculates the taxes of the invoices
+func (p IntegrationProvider) IsTax() bool {
+	return p == IntegrationProviderAvalara || p == IntegrationProviderTaxJar
+}
+
+// LedgerProviders are the providers for which IsLedger is true
+var LedgerProviders = []IntegrationProvider{IntegrationProviderNetSuite, IntegrationProviderQuickBooks}
+
+// TaxProviders are the providers for which IsTax is true
+var TaxProviders = []IntegrationProvider{IntegrationProviderAvalara, IntegrationProviderTaxJar}
+
+// CRMSubscriptionObject is the Salesforce object subscriptions are synced to
+type CRMSubscriptionObject string
+
+const (
+	CRMSubscriptionObjectOpportunity CRMSubscriptionObject = "Opportunity"
+	CRMSubscriptionObjectContract    CRMSubscriptionObject = "Contract"
+)
+
+// SyncEntityType is the kind of record pushed to an integration
+type SyncEntityType string
+
+const (
+	SyncEntityTypeInvoice      SyncEntityType = "invoice"
+	SyncEntityTypeCreditNote   SyncEntityType = "credit_note"
+	SyncEntityTypePayment      SyncEntityType = "payment"
+	SyncEntityTypeSubscription SyncEntityType = "subscription"
+	SyncEntityTypeCustomer     SyncEntityType = "customer"
+	SyncEntityTypeCRMNote      SyncEntityType = "crm_note"
+	// SyncEntityTypeCatalog reads the catalog of a provider for an import
+	SyncEntityTypeCatalog SyncEntityType = "catalog"
+	// SyncEntityTypePaymentMethod attaches, detaches or sets the default payment
+	// method of a customer at a payment gateway
+	SyncEntityTypePaymentMethod SyncEntityType = "payment_method"
+	// SyncEntityTypeRefund refunds a payment at a payment gateway
+	SyncEntityTypeRefund SyncEntityType = "refund"
+	// SyncEntityTypeTaxTransaction commits or voids the tax transaction of an
+	// invoice at a tax provider
+	SyncEntityTypeTaxTransaction SyncEntityType = "tax_transaction"
+)
+
+// Priority orders outbound sync tasks, lower runs first. Financial records are
+// synced before CRM activity so rate limits are spent where they matter most
+func (t SyncEntityType) Priority() int {
+	switch t {
+	case SyncEntityTypeInvoice, SyncEntityTypeCreditNote, SyncEntityTypeTaxTransaction:
+		return 0
+	case SyncEntityTypePayment, SyncEntityTypePaymentMethod, SyncEntityTypeRefund:
+		return 1
+	case SyncEntityTypeSubscription:
+		return 2
+	case SyncEntityTypeCustomer:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// LedgerSyncStatus is the reconciliation status of a document with a ledger
+type LedgerSyncStatus string
+
+const (
+	// LedgerSyncStatusPending documents are queued to be pushed
+	LedgerSyncStatusPending LedgerSyncStatus = "pending"
+	// LedgerSyncStatusSynced documents were created in the ledger
+	LedgerSyncStatusSynced LedgerSyncStatus = "synced"
+	// LedgerSyncStatusFailed documents failed their last push. The sync queue
+	// retries them until it runs out of attempts, after which they are retried
+	// through the API
+	LedgerSyncStatusFailed LedgerSyncStatus = "failed"
+)
+
+type LedgerSyncFilter struct {
+	Filter
+	ConnectionID string           `form:"connection_id"`
+	SyncStatus   LedgerSyncStatus `form:"sync_status"`
+}
+
+func (f *LedgerSyncFilter) ToMap() map[string]interface{} {
+	params := map[string]interface{}{
+		"offset": f.Offset,
+		"limit":  f.Limit,
+	}
+
+	if f.ConnectionID != "" {
+		params["connection_id"] = f.ConnectionID
+	}
+
+	if f.SyncStatus != "" {
+		params["sync_status"] = f.SyncStatus
+	}
+
+	return params
+}
+
+// StripeImportAction is what an import from Stripe does with a Stripe object
+type StripeImportAction string
+
+const (
+	StripeImportActionCreate StripeImportAction = "create"
+	// StripeImportActionExists objects were imported before and are left as is
+	StripeImportActionExists StripeImportAction = "exists"
+	// StripeImportActionConflict objects match a Flexprice record they were not
+	// imported into, such as a customer with the same email, and are not imported
+	StripeImportActionConflict StripeImportAction = "conflict"
+	// StripeImportActionSkip objects cannot be represented in Flexprice, such as
+	// metered prices, or depend on an object that is not imported
+	StripeImportActionSkip StripeImportAction = "skip"
+)
+
+// Metadata keys of the records imported from Stripe
+const (
+	MetadataStripeProductID      = "stripe_product_id"
+	MetadataStripePriceID        = "stripe_price_id"
+	MetadataStripeCustomerID     = "stripe_customer_id"
+	MetadataStripeSubscriptionID = "stripe_subscription_id"
+)
+
+// Metadata keys of the customers holding their ID at the Adyen and Razorpay
+// gateways. The Stripe one is MetadataStripeCustomerID
+const (
+	MetadataAdyenShopperReference = "adyen_shopper_reference"
+	MetadataRazorpayCustomerID    = "razorpay_customer_id"
+)
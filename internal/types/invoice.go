@@ -8,3 +8,183 @@ const (
 	// InvoiceCadenceAdvance raises an invoice at the beginning of each billing period (in advance)
 	InvoiceCadenceAdvance InvoiceCadence = "ADVANCE"
 )
+
+// InvoiceType is the type of the invoice document
+type InvoiceType string
+
+const (
+	// InvoiceTypeSubscription is an invoice raised by a subscription billing run
+	InvoiceTypeSubscription InvoiceType = "SUBSCRIPTION"
+	// InvoiceTypeOneOff is an invoice raised manually outside of a billing run
+	InvoiceTypeOneOff InvoiceType = "ONE_OFF"
+	// InvoiceTypeCredit is a credit invoice issued when the billing run total is negative
+	InvoiceTypeCredit InvoiceType = "CREDIT"
+)
+
+// InvoiceFlow is the billing flow that raised a subscription invoice
+type InvoiceFlow string
+
+const (
+	// InvoiceFlowPeriodEnd is the regular invoice raised at the end of the period
+	InvoiceFlowPeriodEnd InvoiceFlow = "PERIOD_END"
+	// InvoiceFlowThreshold is an interim invoice raised within the period when the
+	// usage charges exceed the billing threshold of the subscription
+	InvoiceFlowThreshold InvoiceFlow = "THRESHOLD"
+)
+
+// InvoiceStatus is the lifecycle status of the invoice
+type InvoiceStatus string
+
+const (
+	InvoiceStatusDraft     InvoiceStatus = "DRAFT"
+	InvoiceStatusFinalized InvoiceStatus = "FINALIZED"
+	InvoiceStatusVoided    InvoiceStatus = "VOIDED"
+	// InvoiceStatusHeld invoices tripped a billing guardrail and must be approved
+	// back to draft before they can be finalized
+	InvoiceStatusHeld InvoiceStatus = "HELD"
+)
+
+// InvoicePaymentStatus is how much of the amount due of an invoice was paid
+type InvoicePaymentStatus string
+
+const (
+	InvoicePaymentStatusPending       InvoicePaymentStatus = "PENDING"
+	InvoicePaymentStatusPartiallyPaid InvoicePaymentStatus = "PARTIALLY_PAID"
+	InvoicePaymentStatusPaid          InvoicePaymentStatus = "PAID"
+	// InvoicePaymentStatusProcessing invoices are covered by bank debits that
+	// have not cleared yet
+	InvoicePaymentStatusProcessing InvoicePaymentStatus = "PROCESSING"
+	// InvoicePaymentStatusUncollectible invoices were written off with an amount
+	// remaining, which is no longer collected
+	InvoicePaymentStatusUncollectible InvoicePaymentStatus = "UNCOLLECTIBLE"
+)
+
+// RefundStatus is the outcome of a refund at the gateway
+type RefundStatus string
+
+const (
+	// RefundStatusPending is accepted by the gateway but not settled yet
+	RefundStatusPending   RefundStatus = "PENDING"
+	RefundStatusSucceeded RefundStatus = "SUCCEEDED"
+	RefundStatusFailed    RefundStatus = "FAILED"
+)
+
+// PaymentStatus is the settlement of an invoice payment. Card and manual
+// payments are settled when they are recorded, bank debits clear days after
+// they are collected
+type PaymentStatus string
+
+const (
+	// PaymentStatusScheduled debits are waiting for the charge date announced
+	// in their pre-notification
+	PaymentStatusScheduled PaymentStatus = "SCHEDULED"
+	// PaymentStatusProcessing debits were collected and are waiting to clear
+	PaymentStatusProcessing PaymentStatus = "PROCESSING"
+	PaymentStatusSucceeded  PaymentStatus = "SUCCEEDED"
+	PaymentStatusFailed     PaymentStatus = "FAILED"
+	// PaymentStatusCancelled debits were cancelled before their charge date, as
+	// the amount of their invoice was adjusted to resolve a dispute
+	PaymentStatusCancelled PaymentStatus = "CANCELLED"
+)
+
+// CreditAllocationTarget is where the amount of a credit invoice is allocated
+type CreditAllocationTarget string
+
+const (
+	// CreditAllocationTargetInvoice reduces the amount due of an invoice of the customer
+	CreditAllocationTargetInvoice CreditAllocationTarget = "invoice"
+	// CreditAllocationTargetWallet credits a wallet of the customer
+	CreditAllocationTargetWallet CreditAllocationTarget = "wallet"
+	// CreditAllocationTargetRefund is paid out by a refund. It is only allocated by
+	// the refunds issuing a credit note and can not be requested
+	CreditAllocationTargetRefund CreditAllocationTarget = "refund"
+)
+
+func (t CreditAllocationTarget) Validate() bool {
+	switch t {
+	case CreditAllocationTargetInvoice, CreditAllocationTargetWallet:
+		return true
+	}
+	return false
+}
+
+// DisputeStatus is the stage of the dispute of an invoice
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen        DisputeStatus = "open"
+	DisputeStatusUnderReview DisputeStatus = "under_review"
+	DisputeStatusResolved    DisputeStatus = "resolved"
+)
+
+// DisputeResolution is how the dispute of an invoice was settled
+type DisputeResolution string
+
+const (
+	// DisputeResolutionCreditNote credits the disputed amount to the invoice
+	// with a credit note
+	DisputeResolutionCreditNote DisputeResolution = "credit_note"
+	// DisputeResolutionReissue voids the invoice and raises a corrected draft
+	// without the disputed amounts in its place
+	DisputeResolutionReissue DisputeResolution = "reissue"
+	// DisputeResolutionUphold keeps the invoice as issued, its collection resumes
+	DisputeResolutionUphold DisputeResolution = "uphold"
+)
+
+func (r DisputeResolution) Validate() bool {
+	switch r {
+	case DisputeResolutionCreditNote, DisputeResolutionReissue, DisputeResolutionUphold:
+		return true
+	}
+	return false
+}
+
+// NegativeInvoiceBehavior defines how a billing run with a negative total is issued.
+// Some locales do not allow negative invoices and require a separate credit document.
+type NegativeInvoiceBehavior string
+
+const (
+	// NegativeInvoiceBehaviorCreditInvoice issues a CREDIT invoice linked to the
+	// last invoice of the subscription
+	NegativeInvoiceBehaviorCreditInvoice NegativeInvoiceBehavior = "credit_invoice"
+	// NegativeInvoiceBehaviorNegativeInvoice issues a regular invoice with a negative total
+	NegativeInvoiceBehaviorNegativeInvoice NegativeInvoiceBehavior = "negative_invoice"
+)
+
+type InvoiceFilter struct {
+	Filter
+	CustomerID        string        `form:"customer_id"`
+	SubscriptionID    string        `form:"subscription_id"`
+	InvoiceType       InvoiceType   `form:"invoice_type"`
+	InvoiceStatus     InvoiceStatus `form:"invoice_status"`
+	OriginalInvoiceID string        `form:"original_invoice_id"`
+}
+
+func (f *InvoiceFilter) ToMap() map[string]interface{} {
+	params := map[string]interface{}{
+		"offset": f.Offset,
+		"limit":  f.Limit,
+	}
+
+	if f.CustomerID != "" {
+		params["customer_id"] = f.CustomerID
+	}
+
+	if f.SubscriptionID != "" {
+		params["subscription_id"] = f.SubscriptionID
+	}
+
+	if f.InvoiceType != "" {
+		params["invoice_type"] = f.InvoiceType
+	}
+
+	if f.InvoiceStatus != "" {
+		params["invoice_status"] = f.InvoiceStatus
+	}
+
+	if f.OriginalInvoiceID != "" {
+		params["original_invoice_id"] = f.OriginalInvoiceID
+	}
+
+	return params
+}
@@ -0,0 +1,38 @@
+package types
+
+import "context"
+
+// JobTrigger is what started a run of a scheduled job
+type JobTrigger string
+
+const (
+	JobTriggerSchedule JobTrigger = "schedule"
+	JobTriggerManual   JobTrigger = "manual"
+)
+
+// JobRunStatus is the lifecycle status of a run of a scheduled job
+type JobRunStatus string
+
+const (
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusSucceeded JobRunStatus = "succeeded"
+	JobRunStatusFailed    JobRunStatus = "failed"
+)
+
+// JobRunOptions is how a run of a scheduled job processes its items. BatchSize
+// items are handed out at a time, the run stops between batches when it is
+// canceled, and up to Concurrency of them are processed at once
+type JobRunOptions struct {
+	BatchSize   int
+	Concurrency int
+}
+
+// GetJobRunOptions returns the options of the job running in the context,
+// defaulting to a single batch processed one item at a time
+func GetJobRunOptions(ctx context.Context) JobRunOptions {
+	opts, _ := ctx.Value(CtxJobRunOptions).(JobRunOptions)
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	return opts
+}
@@ -0,0 +1,124 @@
+package types
+
+import "strings"
+
+// MandateScheme is the bank debit scheme a mandate authorizes debits under
+type MandateScheme string
+
+const (
+	MandateSchemeSEPACore MandateScheme = "sepa_core"
+	MandateSchemeSEPAB2B  MandateScheme = "sepa_b2b"
+	MandateSchemeACH      MandateScheme = "ach"
+)
+
+func (s MandateScheme) Validate() bool {
+	switch s {
+	case MandateSchemeSEPACore, MandateSchemeSEPAB2B, MandateSchemeACH:
+		return true
+	}
+	return false
+}
+
+// PreNotificationDays is how many days before a debit the customer must be
+// notified of its amount and date under the rules of the scheme: 14 days for
+// SEPA unless the mandate agrees otherwise, 10 days for ACH debits whose
+// amount varies
+func (s MandateScheme) PreNotificationDays() int {
+	if s == MandateSchemeACH {
+		return 10
+	}
+	return 14
+}
+
+// MandateStatus is whether the debits of a mandate can be collected
+type MandateStatus string
+
+const (
+	MandateStatusActive MandateStatus = "active"
+	// MandateStatusRevoked mandates were cancelled by the customer, their bank or
+	// the tenant
+	MandateStatusRevoked MandateStatus = "revoked"
+	// MandateStatusFailed mandates are on a bank account that can no longer be
+	// debited ex a closed account
+	MandateStatusFailed MandateStatus = "failed"
+)
+
+// DunningAction is what to do after a bank debit failed, from its failure code
+type DunningAction string
+
+const (
+	// DunningActionRetry debits failed for a reason that can go away ex
+	// insufficient funds, they can be collected again
+	DunningActionRetry DunningAction = "retry"
+	// DunningActionUpdatePaymentMethod debits failed on a bank account that can
+	// no longer be debited, the customer must provide another one
+	DunningActionUpdatePaymentMethod DunningAction = "update_payment_method"
+	// DunningActionNewMandate debits failed as the mandate is missing or was
+	// revoked, the customer must sign a new one
+	DunningActionNewMandate DunningAction = "new_mandate"
+	// DunningActionContactCustomer debits were refused or disputed by the customer
+	DunningActionContactCustomer DunningAction = "contact_customer"
+	// DunningActionManualReview debits failed with a code that is not mapped
+	DunningActionManualReview DunningAction = "manual_review"
+)
+
+// dunningActions maps the failure codes of bank debits to the dunning actions:
+// the ISO 20022 reason codes of SEPA returns, the NACHA return codes of ACH
+// and the failure codes of the gateways
+var dunningActions = map[string]DunningAction{
+	// SEPA
+	"AM04": DunningActionRetry,
+	"AC01": DunningActionUpdatePaymentMethod,
+	"AC04": DunningActionUpdatePaymentMethod,
+	"AC06": DunningActionUpdatePaymentMethod,
+	"AC13": DunningActionUpdatePaymentMethod,
+	"AG01": DunningActionUpdatePaymentMethod,
+	"MD07": DunningActionUpdatePaymentMethod,
+	"MD01": DunningActionNewMandate,
+	"SL01": DunningActionNewMandate,
+	"MS02": DunningActionContactCustomer,
+	"MD06": DunningActionContactCustomer,
+	// ACH
+	"R01": DunningActionRetry,
+	"R09": DunningActionRetry,
+	"R02": DunningActionUpdatePaymentMethod,
+	"R03": DunningActionUpdatePaymentMethod,
+	"R04": DunningActionUpdatePaymentMethod,
+	"R16": DunningActionUpdatePaymentMethod,
+	"R20": DunningActionUpdatePaymentMethod,
+	"R05": DunningActionNewMandate,
+	"R07": DunningActionNewMandate,
+	"R10": DunningActionNewMandate,
+	"R29": DunningActionNewMandate,
+	"R08": DunningActionContactCustomer,
+	// Gateways
+	"INSUFFICIENT_FUNDS":      DunningActionRetry,
+	"INSUFFICIENT_BALANCE":    DunningActionRetry,
+	"ACCOUNT_CLOSED":          DunningActionUpdatePaymentMethod,
+	"NO_ACCOUNT":              DunningActionUpdatePaymentMethod,
+	"INVALID_ACCOUNT_NUMBER":  DunningActionUpdatePaymentMethod,
+	"BANK_ACCOUNT_RESTRICTED": DunningActionUpdatePaymentMethod,
+	"DEBIT_NOT_AUTHORIZED":    DunningActionNewMandate,
+	"MANDATE_REVOKED":         DunningActionNewMandate,
+}
+
+// DunningActionFor returns the dunning action of the failure code of a bank
+// debit, DunningActionManualReview for unknown codes
+func DunningActionFor(failureCode string) DunningAction {
+	if action, ok := dunningActions[strings.ToUpper(strings.TrimSpace(failureCode))]; ok {
+		return action
+	}
+	return DunningActionManualReview
+}
+
+// MandateStatusAfter returns the status of the mandate of a debit that failed
+// with the dunning action, empty when the mandate stays active
+func MandateStatusAfter(action DunningAction) MandateStatus {
+	switch action {
+	case DunningActionNewMandate:
+		return MandateStatusRevoked
+	case DunningActionUpdatePaymentMethod:
+		return MandateStatusFailed
+	}
+	return ""
+}
@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// MarginGroupBy is the dimension the gross margin is reported by
+type MarginGroupBy string
+
+const (
+	MarginGroupByCustomer MarginGroupBy = "customer"
+	MarginGroupByPlan     MarginGroupBy = "plan"
+	// MarginGroupByMeter reports the margin of each feature, the meter its usage
+	// is billed by. Charges not billed by usage are left out
+	MarginGroupByMeter MarginGroupBy = "meter"
+)
+
+// MarginFilter selects the invoiced charges of a margin report, those whose
+// service period starts in [start_time, end_time)
+type MarginFilter struct {
+	GroupBy   MarginGroupBy `form:"group_by" binding:"required,oneof=customer plan meter"`
+	StartTime time.Time     `form:"start_time" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime   time.Time     `form:"end_time" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -57,3 +57,17 @@ const (
 	// DEFAULT_FLOATING_PRECISION is the default floating point precision
 	DEFAULT_FLOATING_PRECISION = 2
 )
+
+// PriceImportAction is what a bulk import of the prices of a plan does with a price
+type PriceImportAction string
+
+const (
+	PriceImportActionCreate PriceImportAction = "create"
+	// PriceImportActionUpdate changes the lookup key, description or metadata of a price
+	PriceImportActionUpdate PriceImportAction = "update"
+	// PriceImportActionReplace archives a price whose amount or billing terms
+	// changed and creates a new one in its place, prices are never repriced in place
+	PriceImportActionReplace   PriceImportAction = "replace"
+	PriceImportActionArchive   PriceImportAction = "archive"
+	PriceImportActionUnchanged PriceImportAction = "unchanged"
+)
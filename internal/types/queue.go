@@ -0,0 +1,12 @@
+package types
+
+// QueueBackend is what carries the ingested events from the API to the consumer
+type QueueBackend string
+
+const (
+	// QueueBackendKafka publishes the events to the topics of the kafka brokers
+	QueueBackendKafka QueueBackend = "kafka"
+	// QueueBackendPostgres keeps the events in a table of the Postgres database
+	// until they are consumed, for deployments without kafka
+	QueueBackendPostgres QueueBackend = "postgres"
+)
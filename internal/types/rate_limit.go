@@ -0,0 +1,10 @@
+package types
+
+// RateLimitClass separates the request quotas of event ingestion from the
+// quotas of the management APIs
+type RateLimitClass string
+
+const (
+	RateLimitClassIngest     RateLimitClass = "ingest"
+	RateLimitClassManagement RateLimitClass = "management"
+)
@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// ReceivablesFilter selects the receivables report of a day in UTC, today when
+// date is not set. Past days are served from the daily snapshots
+type ReceivablesFilter struct {
+	Date       *time.Time `form:"date" time_format:"2006-01-02" time_utc:"1"`
+	CustomerID string     `form:"customer_id"`
+}
+
+// WriteOffFilter selects the invoices written off in [StartTime, EndTime), all
+// of them when the times are not set
+type WriteOffFilter struct {
+	StartTime  *time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime    *time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	CustomerID string     `form:"customer_id"`
+}
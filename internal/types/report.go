@@ -0,0 +1,130 @@
+package types
+
+import "time"
+
+// ReportEntity is the kind of record a report template reports on
+type ReportEntity string
+
+const (
+	ReportEntityCustomers     ReportEntity = "customers"
+	ReportEntitySubscriptions ReportEntity = "subscriptions"
+	ReportEntityInvoices      ReportEntity = "invoices"
+	// ReportEntityInvoiceLineItems reports on the charges of the finalized invoices
+	ReportEntityInvoiceLineItems ReportEntity = "invoice_line_items"
+)
+
+// ReportAggregate aggregates a column over the records of a group
+type ReportAggregate string
+
+const (
+	ReportAggregateSum   ReportAggregate = "sum"
+	ReportAggregateAvg   ReportAggregate = "avg"
+	ReportAggregateMin   ReportAggregate = "min"
+	ReportAggregateMax   ReportAggregate = "max"
+	ReportAggregateCount ReportAggregate = "count"
+)
+
+// ReportFilterOperator compares a field of the records with the value of a filter
+type ReportFilterOperator string
+
+const (
+	ReportFilterEq  ReportFilterOperator = "eq"
+	ReportFilterNeq ReportFilterOperator = "neq"
+	ReportFilterGt  ReportFilterOperator = "gt"
+	ReportFilterGte ReportFilterOperator = "gte"
+	ReportFilterLt  ReportFilterOperator = "lt"
+	ReportFilterLte ReportFilterOperator = "lte"
+	// ReportFilterIn matches any of the comma separated values of the filter
+	ReportFilterIn ReportFilterOperator = "in"
+)
+
+// ReportSchedule is how often a report template is run, at midnight UTC
+type ReportSchedule string
+
+const (
+	ReportScheduleNone    ReportSchedule = ""
+	ReportScheduleDaily   ReportSchedule = "daily"
+	ReportScheduleWeekly  ReportSchedule = "weekly"
+	ReportScheduleMonthly ReportSchedule = "monthly"
+)
+
+// Next returns the first run of the schedule after t: the next midnight UTC,
+// Monday or first day of the month. It is zero when nothing is scheduled
+func (s ReportSchedule) Next(t time.Time) time.Time {
+	day := t.UTC().Truncate(24 * time.Hour)
+	switch s {
+	case ReportScheduleDaily:
+		return day.AddDate(0, 0, 1)
+	case ReportScheduleWeekly:
+		return day.AddDate(0, 0, 7-(int(day.Weekday())+6)%7)
+	case ReportScheduleMonthly:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	default:
+		return time.Time{}
+	}
+}
+
+// ReportPeriod restricts a run to the records whose time field falls in the
+// day, week or month before the run
+type ReportPeriod string
+
+const (
+	ReportPeriodAll           ReportPeriod = ""
+	ReportPeriodPreviousDay   ReportPeriod = "previous_day"
+	ReportPeriodPreviousWeek  ReportPeriod = "previous_week"
+	ReportPeriodPreviousMonth ReportPeriod = "previous_month"
+)
+
+// Range returns the period before at, weeks starting on Monday, in UTC. It is
+// not defined for ReportPeriodAll
+func (p ReportPeriod) Range(at time.Time) (time.Time, time.Time, bool) {
+	day := at.UTC().Truncate(24 * time.Hour)
+	switch p {
+	case ReportPeriodPreviousDay:
+		return day.AddDate(0, 0, -1), day, true
+	case ReportPeriodPreviousWeek:
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, -7), monday, true
+	case ReportPeriodPreviousMonth:
+		month := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return month.AddDate(0, -1, 0), month, true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+}
+
+// ReportDestinationType is where the CSV of a completed run is delivered
+type ReportDestinationType string
+
+const (
+	// ReportDestinationEmail emails a download link of the CSV to the recipients
+	ReportDestinationEmail ReportDestinationType = "email"
+	// ReportDestinationS3 copies the CSV under a prefix of the export bucket
+	ReportDestinationS3 ReportDestinationType = "s3"
+	// ReportDestinationWebhook sends a report.completed event to the webhook endpoints
+	ReportDestinationWebhook ReportDestinationType = "webhook"
+)
+
+// ReportRunStatus tracks the progress of a report run
+type ReportRunStatus string
+
+const (
+	ReportRunStatusPending    ReportRunStatus = "pending"
+	ReportRunStatusProcessing ReportRunStatus = "processing"
+	ReportRunStatusCompleted  ReportRunStatus = "completed"
+	ReportRunStatusFailed     ReportRunStatus = "failed"
+)
+
+// ReportRunTrigger is what started a report run
+type ReportRunTrigger string
+
+const (
+	ReportRunTriggerManual   ReportRunTrigger = "manual"
+	ReportRunTriggerSchedule ReportRunTrigger = "schedule"
+)
+
+// ReportRunFilter lists the runs of a report template, most recent first
+type ReportRunFilter struct {
+	Filter
+	TemplateID string `form:"-"`
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestCache holds the records read while serving a request so that every
+// record is loaded once however many times the request refers to it. It is
+// only put in the context of read only requests, a write would leave it stale
+type RequestCache struct {
+	mu      sync.Mutex
+	entries map[string]*requestCacheEntry
+}
+
+type requestCacheEntry struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+// WithRequestCache returns a context caching the records read with LoadCached
+func WithRequestCache(ctx context.Context) context.Context {
+	if GetRequestCache(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, CtxRequestCache, &RequestCache{entries: make(map[string]*requestCacheEntry)})
+}
+
+// GetRequestCache returns the cache of the request, nil when it has none
+func GetRequestCache(ctx context.Context) *RequestCache {
+	if cache, ok := ctx.Value(CtxRequestCache).(*RequestCache); ok {
+		return cache
+	}
+	return nil
+}
+
+// LoadCached returns the value cached under the key in the request cache, loading
+// it on first use. Concurrent loads of a key wait for the first one. Without a
+// request cache the value is loaded every time
+func LoadCached[T any](ctx context.Context, key string, load func() (T, error)) (T, error) {
+	cache := GetRequestCache(ctx)
+	if cache == nil {
+		return load()
+	}
+
+	cache.mu.Lock()
+	entry, ok := cache.entries[key]
+	if !ok {
+		entry = &requestCacheEntry{}
+		cache.entries[key] = entry
+	}
+	cache.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.value, entry.err = load()
+	})
+	if entry.err != nil {
+		var zero T
+		return zero, entry.err
+	}
+	return entry.value.(T), nil
+}
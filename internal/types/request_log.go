@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// RequestLogStatus groups logged API requests by outcome
+type RequestLogStatus string
+
+const (
+	// RequestLogStatusSucceeded matches requests answered with a 1xx-3xx status code
+	RequestLogStatusSucceeded RequestLogStatus = "succeeded"
+	// RequestLogStatusFailed matches requests answered with a 4xx or 5xx status code
+	RequestLogStatusFailed RequestLogStatus = "failed"
+)
+
+// RequestLogFilter filters the developer request logs. It does not embed Filter
+// as status filters on the outcome of the request rather than the entity status
+type RequestLogFilter struct {
+	Limit      int              `form:"limit,default=50"`
+	Offset     int              `form:"offset,default=0"`
+	APIKeyID   string           `form:"api_key_id"`
+	Method     string           `form:"method"`
+	Endpoint   string           `form:"endpoint"`
+	Status     RequestLogStatus `form:"status" binding:"omitempty,oneof=succeeded failed"`
+	StatusCode int              `form:"status_code"`
+	StartTime  *time.Time       `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime    *time.Time       `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+}
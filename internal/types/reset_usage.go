@@ -1,9 +1,107 @@
 package types
 
+import (
+	"fmt"
+	"time"
+)
+
 type ResetUsage string
 
 const (
 	ResetUsageBillingPeriod ResetUsage = "BILLING_PERIOD"
 	ResetUsageNever         ResetUsage = "NEVER"
-	// TODO: support more reset values like MONTHLY, WEEKLY, DAILY in future
+
+	// Calendar resets are independent of the billing period. They are aligned to
+	// an anchor date and computed in a timezone, see ResetPeriod
+	ResetUsageDaily   ResetUsage = "DAILY"
+	ResetUsageWeekly  ResetUsage = "WEEKLY"
+	ResetUsageMonthly ResetUsage = "MONTHLY"
 )
+
+func (r ResetUsage) Validate() bool {
+	switch r {
+	case ResetUsageBillingPeriod, ResetUsageNever, ResetUsageDaily, ResetUsageWeekly, ResetUsageMonthly:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCalendar reports whether usage resets on its own calendar period rather
+// than with the billing period
+func (r ResetUsage) IsCalendar() bool {
+	switch r {
+	case ResetUsageDaily, ResetUsageWeekly, ResetUsageMonthly:
+		return true
+	default:
+		return false
+	}
+}
+
+// ResetPeriod returns the calendar reset period [start, end) containing at.
+// Periods start at midnight in loc so that a daily reset happens at the
+// customer's midnight across DST changes. Weekly periods start on the weekday
+// of the anchor and monthly periods on the day of month of the anchor,
+// clamped to the last day of shorter months.
+func ResetPeriod(r ResetUsage, at, anchor time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	at = at.In(loc)
+	anchor = anchor.In(loc)
+	day := func(t time.Time, offset int) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, loc)
+	}
+
+	switch r {
+	case ResetUsageDaily:
+		return day(at, 0), day(at, 1), nil
+
+	case ResetUsageWeekly:
+		weeks := floorDiv(civilDaysBetween(anchor, at), 7)
+		start := day(anchor, weeks*7)
+		return start, day(start, 7), nil
+
+	case ResetUsageMonthly:
+		months := (at.Year()-anchor.Year())*12 + int(at.Month()) - int(anchor.Month())
+		start := monthlyResetStart(anchor, months, loc)
+		if start.After(at) {
+			months--
+			start = monthlyResetStart(anchor, months, loc)
+		}
+		return start, monthlyResetStart(anchor, months+1, loc), nil
+
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("reset usage %s has no calendar period", r)
+	}
+}
+
+// monthlyResetStart is the midnight of the anchor's day of month, months after
+// the anchor month, clamped to the length of that month
+func monthlyResetStart(anchor time.Time, months int, loc *time.Location) time.Time {
+	firstOfMonth := time.Date(anchor.Year(), anchor.Month()+time.Month(months), 1, 0, 0, 0, 0, loc)
+	lastDay := time.Date(firstOfMonth.Year(), firstOfMonth.Month()+1, 0, 0, 0, 0, 0, loc).Day()
+
+	d := anchor.Day()
+	if d > lastDay {
+		d = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), d, 0, 0, 0, 0, loc)
+}
+
+// civilDaysBetween counts calendar days from a to b ignoring the time of day
+// and DST shifts
+func civilDaysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetPeriod(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	utc := func(y int, m time.Month, d, h int) time.Time { return time.Date(y, m, d, h, 0, 0, 0, time.UTC) }
+	ny := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, newYork) }
+
+	tests := []struct {
+		name      string
+		reset     ResetUsage
+		at        time.Time
+		anchor    time.Time
+		loc       *time.Location
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "daily in UTC",
+			reset:     ResetUsageDaily,
+			at:        utc(2024, time.March, 10, 15),
+			anchor:    utc(2024, time.January, 1, 0),
+			loc:       time.UTC,
+			wantStart: utc(2024, time.March, 10, 0),
+			wantEnd:   utc(2024, time.March, 11, 0),
+		},
+		{
+			name:  "daily uses the local date",
+			reset: ResetUsageDaily,
+			// 02:00 UTC is still the previous evening in New York
+			at:        utc(2024, time.March, 10, 2),
+			anchor:    utc(2024, time.January, 1, 0),
+			loc:       newYork,
+			wantStart: ny(2024, time.March, 9),
+			wantEnd:   ny(2024, time.March, 10),
+		},
+		{
+			name:      "daily across the DST change is 23 hours long",
+			reset:     ResetUsageDaily,
+			at:        utc(2024, time.March, 10, 12),
+			anchor:    utc(2024, time.January, 1, 0),
+			loc:       newYork,
+			wantStart: ny(2024, time.March, 10),
+			wantEnd:   ny(2024, time.March, 11),
+		},
+		{
+			name:      "weekly aligned to the anchor weekday",
+			reset:     ResetUsageWeekly,
+			at:        utc(2024, time.March, 14, 9),
+			anchor:    utc(2024, time.March, 1, 0), // a Friday
+			loc:       time.UTC,
+			wantStart: utc(2024, time.March, 8, 0),
+			wantEnd:   utc(2024, time.March, 15, 0),
+		},
+		{
+			name:      "weekly before the anchor",
+			reset:     ResetUsageWeekly,
+			at:        utc(2024, time.February, 27, 9),
+			anchor:    utc(2024, time.March, 1, 0),
+			loc:       time.UTC,
+			wantStart: utc(2024, time.February, 23, 0),
+			wantEnd:   utc(2024, time.March, 1, 0),
+		},
+		{
+			name:      "monthly on the anchor day",
+			reset:     ResetUsageMonthly,
+			at:        utc(2024, time.March, 20, 0),
+			anchor:    utc(2024, time.January, 15, 10),
+			loc:       time.UTC,
+			wantStart: utc(2024, time.March, 15, 0),
+			wantEnd:   utc(2024, time.April, 15, 0),
+		},
+		{
+			name:      "monthly before the anchor day in the month",
+			reset:     ResetUsageMonthly,
+			at:        utc(2024, time.March, 10, 0),
+			anchor:    utc(2024, time.January, 15, 10),
+			loc:       time.UTC,
+			wantStart: utc(2024, time.February, 15, 0),
+			wantEnd:   utc(2024, time.March, 15, 0),
+		},
+		{
+			name:      "monthly clamps to short months without drifting",
+			reset:     ResetUsageMonthly,
+			at:        utc(2024, time.March, 5, 0),
+			anchor:    utc(2024, time.January, 31, 0),
+			loc:       time.UTC,
+			wantStart: utc(2024, time.February, 29, 0),
+			wantEnd:   utc(2024, time.March, 31, 0),
+		},
+		{
+			name:  "monthly in a timezone",
+			reset: ResetUsageMonthly,
+			// 03:00 UTC on April 1st is still March 31st in New York
+			at:        utc(2024, time.April, 1, 3),
+			anchor:    ny(2024, time.January, 1),
+			loc:       newYork,
+			wantStart: ny(2024, time.March, 1),
+			wantEnd:   ny(2024, time.April, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := ResetPeriod(tt.reset, tt.at, tt.anchor, tt.loc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("ResetPeriod() = [%v, %v), want [%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+			if tt.at.Before(start) || !tt.at.Before(end) {
+				t.Errorf("period [%v, %v) does not contain %v", start, end, tt.at)
+			}
+		})
+	}
+
+	if _, _, err := ResetPeriod(ResetUsageBillingPeriod, time.Now(), time.Now(), time.UTC); err == nil {
+		t.Error("expected an error for a non calendar reset")
+	}
+}
@@ -0,0 +1,46 @@
+package types
+
+// Role is the set of permissions granted to a dashboard user
+type Role string
+
+const (
+	// RoleAdmin allows everything, including assigning the roles of the users
+	RoleAdmin Role = "admin"
+	// RoleBillingManager manages customers, plans, subscriptions and invoices
+	RoleBillingManager Role = "billing_manager"
+	// RoleDeveloper manages the integration and ingests events but cannot
+	// change the billing data
+	RoleDeveloper Role = "developer"
+	// RoleReadOnly allows reading all resources
+	RoleReadOnly Role = "read_only"
+)
+
+// Roles are all the roles, most privileged first
+var Roles = []Role{RoleAdmin, RoleBillingManager, RoleDeveloper, RoleReadOnly}
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionRead, PermissionWrite, PermissionEventsWrite,
+		PermissionIntegrationsWrite, PermissionMembersWrite,
+	},
+	RoleBillingManager: {PermissionRead, PermissionWrite},
+	RoleDeveloper:      {PermissionRead, PermissionEventsWrite, PermissionIntegrationsWrite},
+	RoleReadOnly:       {PermissionRead},
+}
+
+func (r Role) Validate() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permissions returns the permissions granted by the role
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// RoleAssignmentFilter filters the role assignments of the tenant
+type RoleAssignmentFilter struct {
+	UserID string `form:"user_id"`
+	// EnvironmentID only returns the assignments scoped to the environment
+	EnvironmentID string `form:"environment_id"`
+}
@@ -0,0 +1,78 @@
+package types
+
+import "context"
+
+// APIKeyScope is a preset of permissions granted to an API key
+type APIKeyScope string
+
+const (
+	// APIKeyScopeReadOnly allows reading all resources
+	APIKeyScopeReadOnly APIKeyScope = "read_only"
+	// APIKeyScopeEvents only allows ingesting events. It is safe to embed in client apps
+	APIKeyScopeEvents APIKeyScope = "events"
+	// APIKeyScopeBillingAdmin allows everything
+	APIKeyScopeBillingAdmin APIKeyScope = "billing_admin"
+)
+
+func (s APIKeyScope) Validate() bool {
+	_, ok := scopePermissions[s]
+	return ok
+}
+
+// Permission is checked by the router for each route
+type Permission string
+
+const (
+	PermissionRead        Permission = "read"
+	PermissionWrite       Permission = "write"
+	PermissionEventsWrite Permission = "events:write"
+	// PermissionIntegrationsWrite allows managing the integration of flexprice
+	// ex meters, API keys, webhooks, connections and environments
+	PermissionIntegrationsWrite Permission = "integrations:write"
+	// PermissionMembersWrite allows assigning the roles of the dashboard users
+	PermissionMembersWrite Permission = "members:write"
+)
+
+var scopePermissions = map[APIKeyScope][]Permission{
+	APIKeyScopeReadOnly:     {PermissionRead},
+	APIKeyScopeEvents:       {PermissionEventsWrite},
+	APIKeyScopeBillingAdmin: {PermissionRead, PermissionWrite, PermissionEventsWrite, PermissionIntegrationsWrite},
+}
+
+// PermissionsForScopes returns the union of the permissions granted by the scopes
+func PermissionsForScopes(scopes []string) []Permission {
+	seen := make(map[Permission]bool)
+	var permissions []Permission
+	for _, scope := range scopes {
+		for _, p := range scopePermissions[APIKeyScope(scope)] {
+			if !seen[p] {
+				seen[p] = true
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	return permissions
+}
+
+// GetPermissions returns the permissions of the API key or of the user roles
+// of the request. It returns false for internal calls such as background jobs,
+// in which case no permission restriction applies
+func GetPermissions(ctx context.Context) ([]Permission, bool) {
+	permissions, ok := ctx.Value(CtxPermissions).([]Permission)
+	return permissions, ok
+}
+
+// HasPermission reports whether the request is allowed the given permission
+func HasPermission(ctx context.Context, permission Permission) bool {
+	permissions, restricted := GetPermissions(ctx)
+	if !restricted {
+		return true
+	}
+
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package types
+
+// SSOProtocol is the protocol of the identity provider of a tenant
+type SSOProtocol string
+
+const (
+	SSOProtocolOIDC SSOProtocol = "oidc"
+	SSOProtocolSAML SSOProtocol = "saml"
+)
+
+func (p SSOProtocol) Validate() bool {
+	return p == SSOProtocolOIDC || p == SSOProtocolSAML
+}
@@ -16,12 +16,77 @@ const (
 	SubscriptionStatusUnpaid            SubscriptionStatus = "unpaid"
 )
 
+// TrialOutcome is what happened to a subscription at the end of its trial
+type TrialOutcome string
+
+const (
+	// TrialOutcomeConverted subscriptions became active and started billing
+	TrialOutcomeConverted TrialOutcome = "converted"
+	// TrialOutcomeCancelled subscriptions were cancelled as the customer had no payment method
+	TrialOutcomeCancelled TrialOutcome = "cancelled"
+)
+
+// BillingCycle decides where the billing periods of a subscription start
+type BillingCycle string
+
+const (
+	// BillingCycleAnniversary periods start on the anniversary of the subscription start
+	BillingCycleAnniversary BillingCycle = "anniversary"
+	// BillingCycleCalendar periods are aligned on calendar boundaries ex the 1st of the
+	// month. Subscriptions starting between two boundaries get a partial first period
+	BillingCycleCalendar BillingCycle = "calendar"
+)
+
+func (c BillingCycle) Validate() bool {
+	return c == BillingCycleAnniversary || c == BillingCycleCalendar
+}
+
+// PartialPeriodBehavior is how the fixed charges and the commitment of a partial
+// first period are billed
+type PartialPeriodBehavior string
+
+const (
+	// PartialPeriodBehaviorProrate bills the share of the calendar period that is used
+	PartialPeriodBehaviorProrate PartialPeriodBehavior = "prorate"
+	// PartialPeriodBehaviorFree only bills usage for the partial period
+	PartialPeriodBehaviorFree PartialPeriodBehavior = "free"
+	// PartialPeriodBehaviorFull bills the partial period as a full one
+	PartialPeriodBehaviorFull PartialPeriodBehavior = "full"
+)
+
+func (b PartialPeriodBehavior) Validate() bool {
+	switch b {
+	case PartialPeriodBehaviorProrate, PartialPeriodBehaviorFree, PartialPeriodBehaviorFull:
+		return true
+	}
+	return false
+}
+
+// ProrationBehavior is how a change to a subscription line item in the middle
+// of a period is billed
+type ProrationBehavior string
+
+const (
+	// ProrationBehaviorCreateProrations bills the part of the period before the
+	// change at the previous quantity and price on the next invoice
+	ProrationBehaviorCreateProrations ProrationBehavior = "create_prorations"
+	// ProrationBehaviorNone bills the whole period at the new quantity and price
+	ProrationBehaviorNone ProrationBehavior = "none"
+)
+
+func (b ProrationBehavior) Validate() bool {
+	return b == ProrationBehaviorCreateProrations || b == ProrationBehaviorNone
+}
+
 type SubscriptionFilter struct {
 	Filter
 	CustomerID         string             `form:"customer_id"`
 	SubscriptionStatus SubscriptionStatus `form:"subscription_status"`
 	Status             Status             `form:"status"`
 	PlanID             string             `form:"plan_id"`
+	// Expand is the comma separated records each subscription embeds, the plan
+	// by default
+	Expand string `form:"expand"`
 }
 
 func (f *SubscriptionFilter) ToMap() map[string]interface{} {
@@ -48,3 +113,18 @@ func (f *SubscriptionFilter) ToMap() map[string]interface{} {
 
 	return params
 }
+
+// UsageLockStatus is where a billing period stands with respect to its usage
+// lock, the time after which its usage is aggregated into its invoice
+type UsageLockStatus string
+
+const (
+	// UsageLockStatusOpen periods have not ended yet
+	UsageLockStatusOpen UsageLockStatus = "open"
+	// UsageLockStatusGrace periods ended but are not locked yet, late events with
+	// a timestamp inside the period are still billed with it
+	UsageLockStatusGrace UsageLockStatus = "grace"
+	// UsageLockStatusLocked periods are invoiced, or about to be on the next run
+	// of the renewal job
+	UsageLockStatusLocked UsageLockStatus = "locked"
+)
@@ -0,0 +1,50 @@
+package types
+
+// TaskType is the kind of work done by an asynchronous task
+type TaskType string
+
+const (
+	// TaskTypeCustomerImport creates or updates customers in bulk from a file
+	TaskTypeCustomerImport TaskType = "CUSTOMER_IMPORT"
+	// TaskTypeEventBackfill ingests historical events with their original
+	// timestamps, it is started with the events backfill API
+	TaskTypeEventBackfill TaskType = "EVENT_BACKFILL"
+)
+
+// Validate reports whether tasks of the type can be created from a file with
+// the tasks API
+func (t TaskType) Validate() bool {
+	switch t {
+	case TaskTypeCustomerImport:
+		return true
+	default:
+		return false
+	}
+}
+
+// TaskFileFormat is the format of the file processed by a task
+type TaskFileFormat string
+
+const (
+	TaskFileFormatCSV  TaskFileFormat = "CSV"
+	TaskFileFormatJSON TaskFileFormat = "JSON"
+)
+
+func (f TaskFileFormat) Validate() bool {
+	switch f {
+	case TaskFileFormatCSV, TaskFileFormatJSON:
+		return true
+	default:
+		return false
+	}
+}
+
+// TaskStatus is the lifecycle status of a task
+type TaskStatus string
+
+const (
+	TaskStatusPending    TaskStatus = "pending"
+	TaskStatusProcessing TaskStatus = "processing"
+	TaskStatusCompleted  TaskStatus = "completed"
+	TaskStatusFailed     TaskStatus = "failed"
+)
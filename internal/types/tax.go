@@ -0,0 +1,147 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TaxIDType is the scheme of a tax registration number of a customer
+type TaxIDType string
+
+const (
+	// TaxIDTypeEUVAT is a VAT number of an EU member state, prefixed with its country code
+	TaxIDTypeEUVAT TaxIDType = "eu_vat"
+	// TaxIDTypeGBVAT is a UK VAT number, prefixed with GB
+	TaxIDTypeGBVAT TaxIDType = "gb_vat"
+	// TaxIDTypeCHVAT is a Swiss VAT number ex CHE123456789MWST
+	TaxIDTypeCHVAT TaxIDType = "ch_vat"
+	// TaxIDTypeINGST is an Indian GSTIN
+	TaxIDTypeINGST TaxIDType = "in_gst"
+	// TaxIDTypeAUABN is an Australian Business Number, used for GST
+	TaxIDTypeAUABN TaxIDType = "au_abn"
+	// TaxIDTypeNZGST is a New Zealand GST number
+	TaxIDTypeNZGST TaxIDType = "nz_gst"
+	// TaxIDTypeCAGST is a Canadian GST/HST number ex 123456789RT0001
+	TaxIDTypeCAGST TaxIDType = "ca_gst_hst"
+	// TaxIDTypeSGGST is a Singapore GST registration number
+	TaxIDTypeSGGST TaxIDType = "sg_gst"
+)
+
+// taxIDCountries is the country of the tax ID types issued by a single country
+var taxIDCountries = map[TaxIDType]string{
+	TaxIDTypeGBVAT: "GB",
+	TaxIDTypeCHVAT: "CH",
+	TaxIDTypeINGST: "IN",
+	TaxIDTypeAUABN: "AU",
+	TaxIDTypeNZGST: "NZ",
+	TaxIDTypeCAGST: "CA",
+	TaxIDTypeSGGST: "SG",
+}
+
+var taxIDFormats = map[TaxIDType]*regexp.Regexp{
+	TaxIDTypeGBVAT: regexp.MustCompile(`^GB([0-9]{9}|[0-9]{12}|GD[0-9]{3}|HA[0-9]{3})$`),
+	TaxIDTypeCHVAT: regexp.MustCompile(`^CHE[0-9]{9}(MWST|TVA|IVA)$`),
+	TaxIDTypeINGST: regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`),
+	TaxIDTypeAUABN: regexp.MustCompile(`^[0-9]{11}$`),
+	TaxIDTypeNZGST: regexp.MustCompile(`^[0-9]{8,9}$`),
+	TaxIDTypeCAGST: regexp.MustCompile(`^[0-9]{9}RT[0-9]{4}$`),
+	TaxIDTypeSGGST: regexp.MustCompile(`^(M[0-9A-Z][0-9]{7}[0-9A-Z]|[0-9]{8,9}[A-Z])$`),
+}
+
+// euVATFormats is the VAT number format of each EU member state by the prefix
+// of its numbers, which is EL rather than GR for Greece
+var euVATFormats = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^ATU[0-9]{8}$`),
+	"BE": regexp.MustCompile(`^BE[01][0-9]{9}$`),
+	"BG": regexp.MustCompile(`^BG[0-9]{9,10}$`),
+	"CY": regexp.MustCompile(`^CY[0-9]{8}[A-Z]$`),
+	"CZ": regexp.MustCompile(`^CZ[0-9]{8,10}$`),
+	"DE": regexp.MustCompile(`^DE[0-9]{9}$`),
+	"DK": regexp.MustCompile(`^DK[0-9]{8}$`),
+	"EE": regexp.MustCompile(`^EE[0-9]{9}$`),
+	"EL": regexp.MustCompile(`^EL[0-9]{9}$`),
+	"ES": regexp.MustCompile(`^ES[0-9A-Z][0-9]{7}[0-9A-Z]$`),
+	"FI": regexp.MustCompile(`^FI[0-9]{8}$`),
+	"FR": regexp.MustCompile(`^FR[0-9A-Z]{2}[0-9]{9}$`),
+	"HR": regexp.MustCompile(`^HR[0-9]{11}$`),
+	"HU": regexp.MustCompile(`^HU[0-9]{8}$`),
+	"IE": regexp.MustCompile(`^IE([0-9]{7}[A-Z]{1,2}|[0-9][A-Z][0-9]{5}[A-Z])$`),
+	"IT": regexp.MustCompile(`^IT[0-9]{11}$`),
+	"LT": regexp.MustCompile(`^LT([0-9]{9}|[0-9]{12})$`),
+	"LU": regexp.MustCompile(`^LU[0-9]{8}$`),
+	"LV": regexp.MustCompile(`^LV[0-9]{11}$`),
+	"MT": regexp.MustCompile(`^MT[0-9]{8}$`),
+	"NL": regexp.MustCompile(`^NL[0-9]{9}B[0-9]{2}$`),
+	"PL": regexp.MustCompile(`^PL[0-9]{10}$`),
+	"PT": regexp.MustCompile(`^PT[0-9]{9}$`),
+	"RO": regexp.MustCompile(`^RO[0-9]{2,10}$`),
+	"SE": regexp.MustCompile(`^SE[0-9]{12}$`),
+	"SI": regexp.MustCompile(`^SI[0-9]{8}$`),
+	"SK": regexp.MustCompile(`^SK[0-9]{10}$`),
+}
+
+func (t TaxIDType) Validate() bool {
+	if t == TaxIDTypeEUVAT {
+		return true
+	}
+	_, ok := taxIDFormats[t]
+	return ok
+}
+
+// NormalizeTaxID checks the format of a tax ID of the type and returns it in
+// upper case without spaces, dots and dashes, along with the ISO 3166-1 alpha-2
+// code of the issuing country
+func NormalizeTaxID(t TaxIDType, value string) (string, string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(value))
+
+	if t == TaxIDTypeEUVAT {
+		if len(normalized) < 2 {
+			return "", "", fmt.Errorf("invalid %s tax id: %s", t, value)
+		}
+		prefix := normalized[:2]
+		format, ok := euVATFormats[prefix]
+		if !ok || !format.MatchString(normalized) {
+			return "", "", fmt.Errorf("invalid %s tax id: %s", t, value)
+		}
+		if prefix == "EL" {
+			return normalized, "GR", nil
+		}
+		return normalized, prefix, nil
+	}
+
+	format, ok := taxIDFormats[t]
+	if !ok {
+		return "", "", fmt.Errorf("invalid tax id type: %s", t)
+	}
+	if !format.MatchString(normalized) {
+		return "", "", fmt.Errorf("invalid %s tax id: %s", t, value)
+	}
+	return normalized, taxIDCountries[t], nil
+}
+
+// TaxSource is how the tax line items of an invoice were calculated
+type TaxSource string
+
+const (
+	// TaxSourceManual taxes were calculated from the rates the invoice was
+	// created with
+	TaxSourceManual TaxSource = "manual"
+	// TaxSourceProvider taxes were quoted by the tax provider of the tenant
+	TaxSourceProvider TaxSource = "provider"
+	// TaxSourceFallback taxes were calculated from the fallback rates of the tax
+	// connection as its provider was unavailable
+	TaxSourceFallback TaxSource = "fallback"
+)
+
+// IsQuoted reports whether the taxes were calculated through the tax connection
+// of the tenant, they are quoted again whenever the draft invoice changes
+func (s TaxSource) IsQuoted() bool {
+	return s == TaxSourceProvider || s == TaxSourceFallback
+}
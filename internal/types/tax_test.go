@@ -0,0 +1,45 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTaxID(t *testing.T) {
+	tests := []struct {
+		name       string
+		taxIDType  TaxIDType
+		value      string
+		normalized string
+		country    string
+		wantErr    bool
+	}{
+		{name: "german vat", taxIDType: TaxIDTypeEUVAT, value: "de 123 456 789", normalized: "DE123456789", country: "DE"},
+		{name: "greek vat uses EL", taxIDType: TaxIDTypeEUVAT, value: "EL123456789", normalized: "EL123456789", country: "GR"},
+		{name: "dutch vat", taxIDType: TaxIDTypeEUVAT, value: "NL123456789B01", normalized: "NL123456789B01", country: "NL"},
+		{name: "vat with the wrong length", taxIDType: TaxIDTypeEUVAT, value: "DE12345678", wantErr: true},
+		{name: "vat of a non member state", taxIDType: TaxIDTypeEUVAT, value: "GB123456789", wantErr: true},
+		{name: "uk vat", taxIDType: TaxIDTypeGBVAT, value: "GB123456789", normalized: "GB123456789", country: "GB"},
+		{name: "swiss vat", taxIDType: TaxIDTypeCHVAT, value: "CHE-123.456.789 MWST", normalized: "CHE123456789MWST", country: "CH"},
+		{name: "indian gstin", taxIDType: TaxIDTypeINGST, value: "27aapfu0939f1zv", normalized: "27AAPFU0939F1ZV", country: "IN"},
+		{name: "invalid gstin", taxIDType: TaxIDTypeINGST, value: "27AAPFU0939F1Z", wantErr: true},
+		{name: "australian abn", taxIDType: TaxIDTypeAUABN, value: "51 824 753 556", normalized: "51824753556", country: "AU"},
+		{name: "canadian gst", taxIDType: TaxIDTypeCAGST, value: "123456789RT0001", normalized: "123456789RT0001", country: "CA"},
+		{name: "unknown type", taxIDType: TaxIDType("us_ein"), value: "12-3456789", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, country, err := NormalizeTaxID(tt.taxIDType, tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.normalized, normalized)
+			assert.Equal(t, tt.country, country)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package types
+
+// UsageStreamBackend is how the ingested events reach the live usage streams
+type UsageStreamBackend string
+
+const (
+	// UsageStreamBackendMemory delivers within the process, when the consumer and
+	// the API server run together
+	UsageStreamBackendMemory UsageStreamBackend = "memory"
+	// UsageStreamBackendRedis delivers through Redis pub/sub across processes
+	UsageStreamBackendRedis UsageStreamBackend = "redis"
+)
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// ETag returns the entity tag of a version, sent in the ETag header of the
+// responses of versioned entities ex "3"
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ParseETag returns the version of an entity tag sent in an If-Match header.
+// Weak tags are accepted as the version does not depend on the representation
+func ParseETag(tag string) (int, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// GetIfMatch returns the version the request expects the entity it writes to
+// have, and false when the request is not conditional
+func GetIfMatch(ctx context.Context) (int, bool) {
+	version, ok := ctx.Value(CtxIfMatch).(int)
+	return version, ok
+}
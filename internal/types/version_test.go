@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseETag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		version int
+		ok      bool
+	}{
+		{name: "strong", tag: `"3"`, version: 3, ok: true},
+		{name: "weak", tag: `W/"12"`, version: 12, ok: true},
+		{name: "surrounding spaces", tag: ` "1" `, version: 1, ok: true},
+		{name: "unquoted", tag: `3`},
+		{name: "not a version", tag: `"abc"`},
+		{name: "negative", tag: `"-1"`},
+		{name: "several tags", tag: `"1", "2"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, ok := ParseETag(tt.tag)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+
+	version, ok := ParseETag(ETag(42))
+	assert.True(t, ok)
+	assert.Equal(t, 42, version)
+}
@@ -0,0 +1,89 @@
+package types
+
+// WebhookEventType is the type of an event sent to webhook endpoints
+type WebhookEventType string
+
+const (
+	WebhookEventInvoiceFinalized         WebhookEventType = "invoice.finalized"
+	WebhookEventInvoiceHeld              WebhookEventType = "invoice.held"
+	WebhookEventUsageAnomalyDetected     WebhookEventType = "usage.anomaly_detected"
+	WebhookEventTrialWillEnd             WebhookEventType = "subscription.trial_will_end"
+	WebhookEventTrialEnded               WebhookEventType = "subscription.trial_ended"
+	WebhookEventSubscriptionUpdated      WebhookEventType = "subscription.updated"
+	WebhookEventWalletAutoTopUpSucceeded WebhookEventType = "wallet.auto_topup.succeeded"
+	WebhookEventWalletAutoTopUpFailed    WebhookEventType = "wallet.auto_topup.failed"
+	WebhookEventWalletCreditsExpired     WebhookEventType = "wallet.credits.expired"
+	WebhookEventRefundCreated            WebhookEventType = "refund.created"
+	WebhookEventRefundFailed             WebhookEventType = "refund.failed"
+	WebhookEventReportCompleted          WebhookEventType = "report.completed"
+	WebhookEventBudgetExceeded           WebhookEventType = "budget.exceeded"
+	WebhookEventInvoiceLateUsage         WebhookEventType = "invoice.late_usage"
+	WebhookEventInvoicePaymentFailed     WebhookEventType = "invoice.payment_failed"
+	WebhookEventDisputeOpened            WebhookEventType = "invoice.dispute.opened"
+	WebhookEventDisputeUnderReview       WebhookEventType = "invoice.dispute.under_review"
+	WebhookEventDisputeResolved          WebhookEventType = "invoice.dispute.resolved"
+)
+
+func (t WebhookEventType) Validate() bool {
+	switch t {
+	case WebhookEventInvoiceFinalized, WebhookEventInvoiceHeld, WebhookEventUsageAnomalyDetected,
+		WebhookEventTrialWillEnd, WebhookEventTrialEnded, WebhookEventSubscriptionUpdated,
+		WebhookEventWalletAutoTopUpSucceeded, WebhookEventWalletAutoTopUpFailed,
+		WebhookEventWalletCreditsExpired, WebhookEventRefundCreated, WebhookEventRefundFailed,
+		WebhookEventReportCompleted, WebhookEventBudgetExceeded, WebhookEventInvoiceLateUsage,
+		WebhookEventInvoicePaymentFailed, WebhookEventDisputeOpened, WebhookEventDisputeUnderReview,
+		WebhookEventDisputeResolved:
+		return true
+	}
+	return false
+}
+
+// PreHookOperation is an operation that tenants can veto with a synchronous
+// pre-operation hook
+type PreHookOperation string
+
+const (
+	PreHookOperationSubscriptionCreate PreHookOperation = "subscription.create"
+	PreHookOperationSubscriptionCancel PreHookOperation = "subscription.cancel"
+)
+
+// WebhookDeliveryStatus is the state of the delivery of one event to one endpoint
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryStatusPending deliveries are waiting for their next attempt
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryStatusSucceeded deliveries got a 2xx response
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryStatusFailed deliveries exhausted their attempts and are dead-lettered
+	// until replayed
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDeliveryFilter struct {
+	Filter
+	DeliveryStatus WebhookDeliveryStatus `form:"delivery_status"`
+	EventType      WebhookEventType      `form:"event_type"`
+	EndpointID     string                `form:"endpoint_id"`
+}
+
+func (f *WebhookDeliveryFilter) ToMap() map[string]interface{} {
+	params := map[string]interface{}{
+		"offset": f.Offset,
+		"limit":  f.Limit,
+	}
+
+	if f.DeliveryStatus != "" {
+		params["delivery_status"] = f.DeliveryStatus
+	}
+
+	if f.EventType != "" {
+		params["event_type"] = f.EventType
+	}
+
+	if f.EndpointID != "" {
+		params["endpoint_id"] = f.EndpointID
+	}
+
+	return params
+}
@@ -0,0 +1,56 @@
+package usagestream
+
+import (
+	"context"
+	"sync"
+)
+
+type memoryBroker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Update]struct{}
+}
+
+// NewMemoryBroker returns a broker delivering the updates within the process,
+// for deployments running the consumer and the API server together
+func NewMemoryBroker() Broker {
+	return &memoryBroker{subscribers: make(map[string]map[chan Update]struct{})}
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, update Update) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[channel(update.TenantID, update.ExternalCustomerID)] {
+		select {
+		case ch <- update:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(ctx context.Context, tenantID, externalCustomerID string) (<-chan Update, error) {
+	key := channel(tenantID, externalCustomerID)
+	ch := make(chan Update, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[key] == nil {
+		b.subscribers[key] = make(map[chan Update]struct{})
+	}
+	b.subscribers[key][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subscribers[key], ch)
+		if len(b.subscribers[key]) == 0 {
+			delete(b.subscribers, key)
+		}
+		b.mu.Unlock()
+		close(ch)
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,80 @@
+package usagestream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisBroker struct {
+	client *redis.Client
+}
+
+// NewRedisBroker returns a broker delivering the updates through Redis pub/sub,
+// for deployments running the consumer and the API servers apart
+func NewRedisBroker(client *redis.Client) Broker {
+	return &redisBroker{client: client}
+}
+
+// redisUpdate carries the tenant which is left out of the update sent to clients
+type redisUpdate struct {
+	Update
+	TenantID string `json:"tenant_id"`
+}
+
+func (b *redisBroker) Publish(ctx context.Context, update Update) error {
+	payload, err := json.Marshal(redisUpdate{Update: update, TenantID: update.TenantID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage update: %w", err)
+	}
+
+	if err := b.client.Publish(ctx, channel(update.TenantID, update.ExternalCustomerID), payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish usage update: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBroker) Subscribe(ctx context.Context, tenantID, externalCustomerID string) (<-chan Update, error) {
+	pubsub := b.client.Subscribe(ctx, channel(tenantID, externalCustomerID))
+
+	// Wait for the subscription to be confirmed so no update published after
+	// Subscribe returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to usage updates: %w", err)
+	}
+
+	ch := make(chan Update, subscriberBuffer)
+	messages := pubsub.Channel()
+
+	go func() {
+		defer close(ch)
+		defer pubsub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var update redisUpdate
+				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+					continue
+				}
+				update.Update.TenantID = update.TenantID
+
+				select {
+				case ch <- update.Update:
+				default:
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,57 @@
+// Package usagestream fans the ingested events out to the clients following the
+// live usage of a customer. Updates are published by the event consumer and
+// delivered to the API servers holding the streams of the customer
+package usagestream
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/domain/events"
+)
+
+// subscriberBuffer is the number of updates queued for a slow subscriber before
+// newer updates are dropped for it
+const subscriberBuffer = 64
+
+// Update is an event ingested for a customer
+type Update struct {
+	TenantID           string                 `json:"-"`
+	EventID            string                 `json:"event_id"`
+	EventName          string                 `json:"event_name"`
+	CustomerID         string                 `json:"customer_id,omitempty"`
+	ExternalCustomerID string                 `json:"external_customer_id"`
+	Timestamp          time.Time              `json:"timestamp"`
+	Properties         map[string]interface{} `json:"properties,omitempty"`
+}
+
+// NewUpdate returns the update of an ingested event
+func NewUpdate(event *events.Event) Update {
+	return Update{
+		TenantID:           event.TenantID,
+		EventID:            event.ID,
+		EventName:          event.EventName,
+		CustomerID:         event.CustomerID,
+		ExternalCustomerID: event.ExternalCustomerID,
+		Timestamp:          event.Timestamp,
+		Properties:         event.Properties,
+	}
+}
+
+// Broker delivers the published updates to the subscribers of the customer
+// within the tenant. Delivery is best effort: updates published while nobody
+// listens, or beyond the buffer of a slow subscriber, are dropped
+type Broker interface {
+	Publish(ctx context.Context, update Update) error
+
+	// Subscribe streams the updates of the customer until the context is done,
+	// the channel is then closed
+	Subscribe(ctx context.Context, tenantID, externalCustomerID string) (<-chan Update, error)
+}
+
+// channel is the topic of the updates of a customer. Customers are keyed by
+// their external id, the one events are ingested with
+func channel(tenantID, externalCustomerID string) string {
+	return fmt.Sprintf("usage:%s:%s", tenantID, externalCustomerID)
+}
@@ -0,0 +1,69 @@
+package usagestream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokers(t *testing.T) {
+	brokers := map[string]func(t *testing.T) Broker{
+		"memory": func(t *testing.T) Broker {
+			return NewMemoryBroker()
+		},
+		"redis": func(t *testing.T) Broker {
+			server := miniredis.RunT(t)
+			return NewRedisBroker(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+		},
+	}
+
+	for name, newBroker := range brokers {
+		t.Run(name, func(t *testing.T) {
+			broker := newBroker(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			updates, err := broker.Subscribe(ctx, "tenant_a", "cust_1")
+			require.NoError(t, err)
+
+			// Updates of other customers and tenants are not delivered
+			other := Update{TenantID: "tenant_b", EventID: "evt_0", ExternalCustomerID: "cust_1"}
+			require.NoError(t, broker.Publish(ctx, other))
+			other = Update{TenantID: "tenant_a", EventID: "evt_0", ExternalCustomerID: "cust_2"}
+			require.NoError(t, broker.Publish(ctx, other))
+
+			update := Update{
+				TenantID:           "tenant_a",
+				EventID:            "evt_1",
+				EventName:          "api_call",
+				ExternalCustomerID: "cust_1",
+				Timestamp:          time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Properties:         map[string]interface{}{"region": "eu"},
+			}
+			require.NoError(t, broker.Publish(ctx, update))
+
+			select {
+			case got := <-updates:
+				assert.Equal(t, update, got)
+			case <-time.After(2 * time.Second):
+				t.Fatal("update not delivered")
+			}
+
+			// The channel is closed once the subscriber leaves
+			cancel()
+			select {
+			case _, ok := <-updates:
+				for ok {
+					_, ok = <-updates
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("channel not closed")
+			}
+		})
+	}
+}
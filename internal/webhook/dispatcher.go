@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Config of the delivery retry policy
+type Config struct {
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// backoff is the delay before the retry following the given attempt: exponential
+// in the number of attempts, capped at MaxBackoff, with the upper half jittered
+// so that deliveries failing together do not retry together
+func (c Config) backoff(attempt int) time.Duration {
+	delay := c.BaseBackoff
+	for i := 1; i < attempt && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// Dispatcher sends pending deliveries of all tenants to their endpoints and
+// schedules retries. Deliveries that exhaust their attempts are marked failed
+// and stay there until replayed
+type Dispatcher struct {
+	repo   webhookDomain.Repository
+	client *http.Client
+	cfg    Config
+	logger *logger.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(cfg *config.Configuration, repo webhookDomain.Repository, logger *logger.Logger) *Dispatcher {
+	return newDispatcher(Config{
+		MaxAttempts:  cfg.Webhook.MaxAttempts,
+		BaseBackoff:  time.Duration(cfg.Webhook.BackoffBaseSecs) * time.Second,
+		MaxBackoff:   time.Duration(cfg.Webhook.BackoffMaxSecs) * time.Second,
+		Timeout:      time.Duration(cfg.Webhook.TimeoutSecs) * time.Second,
+		PollInterval: time.Duration(cfg.Webhook.PollIntervalMillis) * time.Millisecond,
+	}, repo, logger)
+}
+
+func newDispatcher(cfg Config, repo webhookDomain.Repository, logger *logger.Logger) *Dispatcher {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 30 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 6 * time.Hour
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+
+	return &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Start polls for due deliveries until Stop is called
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := d.dispatchDue(ctx); err != nil {
+				d.logger.Errorw("failed to dispatch webhook deliveries", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops polling and waits for in flight deliveries to finish
+func (d *Dispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+}
+
+// dispatchDue sends one batch of due deliveries. The claim lease outlasts the
+// batch so that a dispatcher crashing mid batch only delays its deliveries
+func (d *Dispatcher) dispatchDue(ctx context.Context) error {
+	lease := time.Duration(d.cfg.BatchSize+1) * d.cfg.Timeout
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, time.Now().UTC(), lease, d.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return nil
+		}
+		d.deliver(ctx, delivery)
+	}
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *webhookDomain.Delivery) {
+	ctx = context.WithValue(ctx, types.CtxTenantID, delivery.TenantID)
+	ctx = context.WithValue(ctx, types.CtxEnvironmentID, delivery.EnvironmentID)
+
+	statusCode, err := d.send(ctx, delivery)
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastResponseCode = statusCode
+	delivery.UpdatedAt = now
+
+	switch {
+	case err == nil:
+		delivery.DeliveryStatus = types.WebhookDeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.DeliveryStatus = types.WebhookDeliveryStatusFailed
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(d.cfg.backoff(delivery.Attempts))
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = &next
+	}
+
+	if err != nil {
+		d.logger.Debugw("webhook delivery attempt failed",
+			"delivery_id", delivery.ID,
+			"tenant_id", delivery.TenantID,
+			"attempts", delivery.Attempts,
+			"delivery_status", delivery.DeliveryStatus,
+			"error", err,
+		)
+	}
+
+	// The attempt happened, so record it even if the dispatcher is stopping
+	if err := d.repo.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		d.logger.Errorw("failed to update webhook delivery",
+			"delivery_id", delivery.ID,
+			"tenant_id", delivery.TenantID,
+			"error", err,
+		)
+	}
+}
+
+// send POSTs the delivery payload to its endpoint and returns the response status
+// code, or zero when no response was received
+func (d *Dispatcher) send(ctx context.Context, delivery *webhookDomain.Delivery) (int, error) {
+	endpoint, err := d.repo.GetEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventType, string(delivery.EventType))
+	req.Header.Set(HeaderDeliveryID, delivery.ID)
+	now := time.Now()
+	req.Header.Set(HeaderSignature, Sign(now.Unix(), delivery.Payload, endpoint.SigningSecrets(now)...))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
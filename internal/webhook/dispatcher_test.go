@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testReceiver struct {
+	mu        sync.Mutex
+	status    int
+	bodies    [][]byte
+	headers   []http.Header
+	responses int
+}
+
+func (r *testReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+	r.responses++
+	w.WriteHeader(r.status)
+}
+
+func setupDispatcherTest(t *testing.T, status int, eventTypes []string) (*testReceiver, Publisher, *Dispatcher, *testutil.InMemoryWebhookStore) {
+	receiver := &testReceiver{status: status}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	store := testutil.NewInMemoryWebhookStore()
+	require.NoError(t, store.CreateEndpoint(testutil.SetupContext(), &webhookDomain.Endpoint{
+		ID:         "ep_1",
+		URL:        server.URL,
+		Secret:     "whsec_test",
+		EventTypes: eventTypes,
+		BaseModel:  types.GetDefaultBaseModel(testutil.SetupContext()),
+	}))
+
+	dispatcher := newDispatcher(Config{
+		MaxAttempts: 2,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+		Timeout:     time.Second,
+	}, store, logger.GetLogger())
+
+	return receiver, NewPublisher(store, logger.GetLogger()), dispatcher, store
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	ctx := testutil.SetupContext()
+	receiver, publisher, dispatcher, store := setupDispatcherTest(t, http.StatusOK, nil)
+
+	require.NoError(t, publisher.Publish(ctx, types.WebhookEventInvoiceFinalized, map[string]string{"id": "inv_1"}))
+	require.NoError(t, dispatcher.dispatchDue(ctx))
+
+	require.Equal(t, 1, receiver.responses)
+	header := receiver.headers[0]
+	assert.Equal(t, string(types.WebhookEventInvoiceFinalized), header.Get(HeaderEventType))
+
+	// The signature covers the timestamp and the exact body received
+	parts := strings.Split(header.Get(HeaderSignature), ",")
+	require.Len(t, parts, 2)
+	timestamp, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign(timestamp, receiver.bodies[0], "whsec_test"), header.Get(HeaderSignature))
+
+	delivery, err := store.GetDelivery(ctx, header.Get(HeaderDeliveryID))
+	require.NoError(t, err)
+	assert.Equal(t, types.WebhookDeliveryStatusSucceeded, delivery.DeliveryStatus)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.LastResponseCode)
+	assert.NotNil(t, delivery.DeliveredAt)
+	assert.Nil(t, delivery.NextAttemptAt)
+}
+
+func TestDispatcher_SignsWithPreviousSecretDuringRotation(t *testing.T) {
+	ctx := testutil.SetupContext()
+	receiver, publisher, dispatcher, store := setupDispatcherTest(t, http.StatusOK, nil)
+
+	endpoint, err := store.GetEndpoint(ctx, "ep_1")
+	require.NoError(t, err)
+	expiresAt := time.Now().Add(time.Hour)
+	endpoint.PreviousSecret = endpoint.Secret
+	endpoint.PreviousSecretExpiresAt = &expiresAt
+	endpoint.Secret = "whsec_new"
+	require.NoError(t, store.UpdateEndpoint(ctx, endpoint))
+
+	require.NoError(t, publisher.Publish(ctx, types.WebhookEventInvoiceFinalized, map[string]string{"id": "inv_1"}))
+	require.NoError(t, dispatcher.dispatchDue(ctx))
+
+	require.Equal(t, 1, receiver.responses)
+	parts := strings.Split(receiver.headers[0].Get(HeaderSignature), ",")
+	require.Len(t, parts, 3)
+	timestamp, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, "v1="+computeSignature("whsec_new", timestamp, receiver.bodies[0]), parts[1])
+	assert.Equal(t, "v1="+computeSignature("whsec_test", timestamp, receiver.bodies[0]), parts[2])
+
+	// Once the previous secret expires only the new one signs
+	expired := time.Now().Add(-time.Minute)
+	endpoint.PreviousSecretExpiresAt = &expired
+	assert.Equal(t, []string{"whsec_new"}, endpoint.SigningSecrets(time.Now()))
+}
+
+func TestDispatcher_RetriesThenDeadLetters(t *testing.T) {
+	ctx := testutil.SetupContext()
+	receiver, publisher, dispatcher, store := setupDispatcherTest(t, http.StatusInternalServerError, nil)
+
+	require.NoError(t, publisher.Publish(ctx, types.WebhookEventInvoiceFinalized, map[string]string{"id": "inv_1"}))
+	require.NoError(t, dispatcher.dispatchDue(ctx))
+
+	deliveries, err := store.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, types.WebhookDeliveryStatusPending, deliveries[0].DeliveryStatus)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, deliveries[0].LastResponseCode)
+	require.NotNil(t, deliveries[0].NextAttemptAt)
+
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, dispatcher.dispatchDue(ctx))
+
+	failed, err := store.ListDeliveries(ctx, &types.WebhookDeliveryFilter{
+		DeliveryStatus: types.WebhookDeliveryStatusFailed,
+	})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, 2, failed[0].Attempts)
+	assert.Nil(t, failed[0].NextAttemptAt)
+	assert.Contains(t, failed[0].LastError, "500")
+
+	// Dead-lettered deliveries are not picked up again
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, dispatcher.dispatchDue(ctx))
+	assert.Equal(t, 2, receiver.responses)
+}
+
+func TestPublisher_SkipsUnsubscribedEndpoints(t *testing.T) {
+	ctx := testutil.SetupContext()
+	_, publisher, _, store := setupDispatcherTest(t, http.StatusOK, []string{"subscription.created"})
+
+	require.NoError(t, publisher.Publish(ctx, types.WebhookEventInvoiceFinalized, map[string]string{"id": "inv_1"}))
+
+	deliveries, err := store.ListDeliveries(ctx, &types.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestConfig_Backoff(t *testing.T) {
+	cfg := Config{BaseBackoff: 10 * time.Second, MaxBackoff: time.Minute}
+
+	for attempt, expected := range map[int]time.Duration{
+		1: 10 * time.Second,
+		2: 20 * time.Second,
+		3: 40 * time.Second,
+		5: time.Minute,
+	} {
+		delay := cfg.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, expected/2, "attempt %d", attempt)
+		assert.LessOrEqual(t, delay, expected, "attempt %d", attempt)
+	}
+}
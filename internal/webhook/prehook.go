@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/config"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// ErrOperationDenied is wrapped by the errors of PreHookGate.Check when a hook
+// vetoes the operation
+var ErrOperationDenied = errors.New("operation denied by pre-operation hook")
+
+const defaultPreHookTimeout = 5 * time.Second
+
+// PreHook is an HTTPS callback of a tenant called before an operation
+type PreHook struct {
+	ID         string
+	TenantID   string
+	URL        string
+	Secret     string
+	Operations []types.PreHookOperation
+	Timeout    time.Duration
+	FailOpen   bool
+}
+
+func (h *PreHook) gates(op types.PreHookOperation) bool {
+	for _, o := range h.Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// PreHookRequest is the body POSTed to pre-operation hooks
+type PreHookRequest struct {
+	ID        string                 `json:"id"`
+	Operation types.PreHookOperation `json:"operation"`
+	TenantID  string                 `json:"tenant_id"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      interface{}            `json:"data"`
+}
+
+// PreHookResponse is the body pre-operation hooks reply with
+type PreHookResponse struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PreHookGate calls the pre-operation hooks of the tenant
+type PreHookGate interface {
+	// Check calls the hooks of the tenant in ctx registered for op one after the
+	// other and returns an error wrapping ErrOperationDenied as soon as one denies
+	// it. A hook that fails or times out denies the operation unless it fails open
+	Check(ctx context.Context, op types.PreHookOperation, data interface{}) error
+}
+
+type preHookGate struct {
+	hooks  []*PreHook
+	client *http.Client
+	logger *logger.Logger
+}
+
+func NewPreHookGate(cfg *config.Configuration, logger *logger.Logger) PreHookGate {
+	hooks := make([]*PreHook, 0, len(cfg.Webhook.PreHooks))
+	for _, h := range cfg.Webhook.PreHooks {
+		operations := make([]types.PreHookOperation, 0, len(h.Operations))
+		for _, op := range h.Operations {
+			operations = append(operations, types.PreHookOperation(op))
+		}
+
+		hooks = append(hooks, &PreHook{
+			ID:         h.ID,
+			TenantID:   h.TenantID,
+			URL:        h.URL,
+			Secret:     h.Secret,
+			Operations: operations,
+			Timeout:    time.Duration(h.TimeoutMillis) * time.Millisecond,
+			FailOpen:   h.FailOpen,
+		})
+	}
+	return newPreHookGate(hooks, &http.Client{}, logger)
+}
+
+func newPreHookGate(hooks []*PreHook, client *http.Client, logger *logger.Logger) *preHookGate {
+	return &preHookGate{
+		hooks:  hooks,
+		client: client,
+		logger: logger,
+	}
+}
+
+func (g *preHookGate) Check(ctx context.Context, op types.PreHookOperation, data interface{}) error {
+	tenantID := types.GetTenantID(ctx)
+
+	for _, hook := range g.hooks {
+		if hook.TenantID != tenantID || !hook.gates(op) {
+			continue
+		}
+
+		resp, err := g.call(ctx, hook, op, data)
+		if err != nil {
+			if hook.FailOpen {
+				g.logger.Warnw("pre-operation hook failed, allowing operation",
+					"hook_id", hook.ID,
+					"operation", op,
+					"error", err,
+				)
+				continue
+			}
+			return fmt.Errorf("%w: hook %s failed: %v", ErrOperationDenied, hook.ID, err)
+		}
+
+		if !resp.Allow {
+			reason := resp.Reason
+			if reason == "" {
+				reason = "no reason given"
+			}
+			return fmt.Errorf("%w: %s", ErrOperationDenied, reason)
+		}
+	}
+
+	return nil
+}
+
+func (g *preHookGate) call(ctx context.Context, hook *PreHook, op types.PreHookOperation, data interface{}) (*PreHookResponse, error) {
+	u, err := url.Parse(hook.URL)
+	if err != nil || u.Scheme != "https" {
+		return nil, fmt.Errorf("hook url must be an https url")
+	}
+
+	payload, err := json.Marshal(PreHookRequest{
+		ID:        types.GenerateUUID(),
+		Operation: op,
+		TenantID:  hook.TenantID,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = defaultPreHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignature, Sign(time.Now().Unix(), payload, hook.Secret))
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("hook responded with status %d", resp.StatusCode)
+	}
+
+	var hookResp PreHookResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&hookResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &hookResp, nil
+}
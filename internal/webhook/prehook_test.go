@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/testutil"
+	"github.com/flexprice/flexprice/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPreHookServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func respondWith(resp PreHookResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func testPreHook(server *httptest.Server, failOpen bool) *PreHook {
+	return &PreHook{
+		ID:         "hook_1",
+		TenantID:   types.DefaultTenantID,
+		URL:        server.URL,
+		Secret:     "whsec_test",
+		Operations: []types.PreHookOperation{types.PreHookOperationSubscriptionCreate},
+		Timeout:    50 * time.Millisecond,
+		FailOpen:   failOpen,
+	}
+}
+
+func TestPreHookGate_Check(t *testing.T) {
+	ctx := testutil.SetupContext()
+
+	allow := newTestPreHookServer(t, respondWith(PreHookResponse{Allow: true}))
+	deny := newTestPreHookServer(t, respondWith(PreHookResponse{Allow: false, Reason: "customer flagged"}))
+	slow := newTestPreHookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+	broken := newTestPreHookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	tests := []struct {
+		name       string
+		hook       *PreHook
+		op         types.PreHookOperation
+		wantDenied bool
+		wantReason string
+	}{
+		{name: "allowed", hook: testPreHook(allow, false), op: types.PreHookOperationSubscriptionCreate},
+		{name: "denied", hook: testPreHook(deny, false), op: types.PreHookOperationSubscriptionCreate, wantDenied: true, wantReason: "customer flagged"},
+		{name: "other operation not gated", hook: testPreHook(deny, false), op: types.PreHookOperationSubscriptionCancel},
+		{name: "timeout fails closed", hook: testPreHook(slow, false), op: types.PreHookOperationSubscriptionCreate, wantDenied: true, wantReason: "hook_1 failed"},
+		{name: "timeout fails open", hook: testPreHook(slow, true), op: types.PreHookOperationSubscriptionCreate},
+		{name: "error status fails closed", hook: testPreHook(broken, false), op: types.PreHookOperationSubscriptionCreate, wantDenied: true, wantReason: "status 500"},
+		{name: "error status fails open", hook: testPreHook(broken, true), op: types.PreHookOperationSubscriptionCreate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := newPreHookGate([]*PreHook{tt.hook}, allow.Client(), logger.GetLogger())
+
+			err := gate.Check(ctx, tt.op, map[string]string{"customer_id": "cust_1"})
+			if !tt.wantDenied {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrOperationDenied))
+			assert.Contains(t, err.Error(), tt.wantReason)
+		})
+	}
+}
+
+func TestPreHookGate_ScopedToTenant(t *testing.T) {
+	deny := newTestPreHookServer(t, respondWith(PreHookResponse{Allow: false}))
+	gate := newPreHookGate([]*PreHook{testPreHook(deny, false)}, deny.Client(), logger.GetLogger())
+
+	otherTenant := context.WithValue(context.Background(), types.CtxTenantID, "tenant_other")
+	assert.NoError(t, gate.Check(otherTenant, types.PreHookOperationSubscriptionCreate, nil))
+}
+
+func TestPreHookGate_RequiresHTTPS(t *testing.T) {
+	ctx := testutil.SetupContext()
+	plain := httptest.NewServer(respondWith(PreHookResponse{Allow: true}))
+	defer plain.Close()
+
+	hook := &PreHook{
+		ID:         "hook_1",
+		TenantID:   types.DefaultTenantID,
+		URL:        plain.URL,
+		Operations: []types.PreHookOperation{types.PreHookOperationSubscriptionCreate},
+	}
+	gate := newPreHookGate([]*PreHook{hook}, plain.Client(), logger.GetLogger())
+
+	err := gate.Check(ctx, types.PreHookOperationSubscriptionCreate, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "https")
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	webhookDomain "github.com/flexprice/flexprice/internal/domain/webhook"
+	"github.com/flexprice/flexprice/internal/logger"
+	"github.com/flexprice/flexprice/internal/types"
+)
+
+// Publisher records an event for every endpoint of the environment subscribed to
+// it, or of every environment of the tenant when ctx has none.
+// Events are delivered asynchronously by the Dispatcher
+type Publisher interface {
+	Publish(ctx context.Context, eventType types.WebhookEventType, data interface{}) error
+}
+
+// Event is the body POSTed to webhook endpoints
+type Event struct {
+	ID        string                 `json:"id"`
+	EventType types.WebhookEventType `json:"event_type"`
+	TenantID  string                 `json:"tenant_id"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      interface{}            `json:"data"`
+}
+
+type publisher struct {
+	repo   webhookDomain.Repository
+	logger *logger.Logger
+}
+
+func NewPublisher(repo webhookDomain.Repository, logger *logger.Logger) Publisher {
+	return &publisher{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (p *publisher) Publish(ctx context.Context, eventType types.WebhookEventType, data interface{}) error {
+	endpoints, err := p.repo.ListSubscribedEndpoints(ctx, eventType)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	event := Event{
+		ID:        types.GenerateUUID(),
+		EventType: eventType,
+		TenantID:  types.GetTenantID(ctx),
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	for _, endpoint := range endpoints {
+		now := time.Now().UTC()
+		delivery := &webhookDomain.Delivery{
+			ID:             types.GenerateUUID(),
+			EnvironmentID:  endpoint.EnvironmentID,
+			EndpointID:     endpoint.ID,
+			EndpointURL:    endpoint.URL,
+			EventType:      eventType,
+			Payload:        payload,
+			DeliveryStatus: types.WebhookDeliveryStatusPending,
+			NextAttemptAt:  &now,
+			BaseModel:      types.GetDefaultBaseModel(ctx),
+		}
+
+		if err := p.repo.CreateDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", err)
+		}
+
+		p.logger.Debugw("webhook delivery queued",
+			"delivery_id", delivery.ID,
+			"endpoint_id", endpoint.ID,
+			"event_type", eventType,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderSignature  = "X-Flexprice-Signature"
+	HeaderEventType  = "X-Flexprice-Event"
+	HeaderDeliveryID = "X-Flexprice-Delivery"
+)
+
+// Sign returns the signature header value of a payload sent at the given unix
+// timestamp, in the form t=<timestamp>,v1=<hex hmac-sha256 of "timestamp.payload">.
+// With several secrets, as while a rotated secret is still valid, there is one
+// v1 entry per secret and receivers accept the payload if any of them matches
+func Sign(timestamp int64, payload []byte, secrets ...string) string {
+	parts := make([]string, 0, len(secrets)+1)
+	parts = append(parts, "t="+strconv.FormatInt(timestamp, 10))
+	for _, secret := range secrets {
+		parts = append(parts, "v1="+computeSignature(secret, timestamp, payload))
+	}
+	return strings.Join(parts, ",")
+}
+
+func computeSignature(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS request_logs (
+    id String,
+    tenant_id String,
+    environment_id String,
+    api_key_id String,
+    request_id String,
+
+    -- Request
+    method LowCardinality(String),
+    path String,
+    endpoint String,
+    status_code Int32,
+    latency_ms Int64,
+    request_body String,
+    response_body String,
+    timestamp DateTime64(3),
+
+    CONSTRAINT check_tenant_id CHECK (tenant_id != ''),
+    CONSTRAINT check_request_log_id CHECK (id != '')
+) ENGINE = MergeTree()
+PARTITION BY toYYYYMMDD(timestamp)
+ORDER BY (tenant_id, timestamp, id)
+TTL toDateTime(timestamp) + INTERVAL 7 DAY
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS usage_rollup_watermarks;
+DROP TABLE IF EXISTS usage_rollups;
@@ -0,0 +1,27 @@
+-- Hourly and daily usage of every customer on every meter, recomputed by the
+-- rollup job. Rows of a window replace the previous computation of the window
+CREATE TABLE IF NOT EXISTS usage_rollups (
+    tenant_id String,
+    meter_id String,
+    external_customer_id String,
+    customer_id String,
+    window_size LowCardinality(String),
+    window_start DateTime('UTC'),
+
+    -- Deduplicated events in the window and the sum of the aggregated field
+    event_count UInt64,
+    value_sum Float64,
+    computed_at DateTime64(3) DEFAULT now64(3)
+) ENGINE = ReplacingMergeTree(computed_at)
+PARTITION BY toYYYYMM(window_start)
+ORDER BY (tenant_id, meter_id, window_size, window_start, external_customer_id, customer_id)
+SETTINGS index_granularity = 8192;
+
+-- The windows of a meter before rolled_up_until are served from usage_rollups
+CREATE TABLE IF NOT EXISTS usage_rollup_watermarks (
+    tenant_id String,
+    meter_id String,
+    rolled_up_until DateTime('UTC'),
+    updated_at DateTime64(3) DEFAULT now64(3)
+) ENGINE = ReplacingMergeTree(updated_at)
+ORDER BY (tenant_id, meter_id);
@@ -0,0 +1,3 @@
+ALTER TABLE events
+    DROP COLUMN IF EXISTS sign,
+    DROP COLUMN IF EXISTS correction_of;
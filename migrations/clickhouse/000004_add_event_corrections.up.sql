@@ -0,0 +1,6 @@
+-- Events are voided or corrected by appending correction rows. A row with sign
+-- -1 reverses the usage of the event it copies and a row with sign 1 replaces
+-- it, correction_of is the ID of the ingested event both refer to
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS correction_of String DEFAULT '',
+    ADD COLUMN IF NOT EXISTS sign Int8 DEFAULT 1;
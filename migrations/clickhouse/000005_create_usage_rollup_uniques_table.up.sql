@@ -0,0 +1,19 @@
+-- Hourly and daily distinct values of the field of every COUNT_UNIQUE meter per
+-- customer, recomputed by the rollup job like usage_rollups. Unique counts do
+-- not add up across windows, so each window keeps the state of its count,
+-- which is merged with the states of the other windows when read. States are
+-- stored serialized as their aggregate function depends on the precision of
+-- the meter
+CREATE TABLE IF NOT EXISTS usage_rollup_uniques (
+    tenant_id String,
+    meter_id String,
+    external_customer_id String,
+    customer_id String,
+    window_size LowCardinality(String),
+    window_start DateTime('UTC'),
+    state String,
+    computed_at DateTime64(3) DEFAULT now64(3)
+) ENGINE = ReplacingMergeTree(computed_at)
+PARTITION BY toYYYYMM(window_start)
+ORDER BY (tenant_id, meter_id, window_size, window_start, external_customer_id, customer_id)
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,6 @@
+-- Inserts carry a deduplication token derived from the IDs of their events. A
+-- block whose token is among the last ones inserted is dropped, so a consumer
+-- redelivered messages it already stored does not insert them twice. Copies
+-- inserted outside the window are merged by the ReplacingMergeTree and read
+-- once by the queries
+ALTER TABLE events MODIFY SETTING non_replicated_deduplication_window = 10000;
@@ -0,0 +1,29 @@
+-- Invoice number format per tenant and environment
+CREATE TABLE invoice_numbering_configs (
+    tenant_id VARCHAR(255) NOT NULL,
+    environment_id VARCHAR(255) NOT NULL DEFAULT '',
+    prefix VARCHAR(20) NOT NULL DEFAULT 'INV',
+    separator VARCHAR(5) NOT NULL DEFAULT '-',
+    padding INTEGER NOT NULL DEFAULT 5,
+    reset_yearly BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    PRIMARY KEY (tenant_id, environment_id)
+);
+
+-- Gap free counters, incremented with a row lock held until the transaction commits
+CREATE TABLE sequences (
+    tenant_id VARCHAR(255) NOT NULL,
+    environment_id VARCHAR(255) NOT NULL DEFAULT '',
+    sequence_key VARCHAR(255) NOT NULL,
+    last_value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, environment_id, sequence_key)
+);
+
+ALTER TABLE invoices ADD COLUMN invoice_number VARCHAR(100);
+
+CREATE UNIQUE INDEX idx_invoices_tenant_invoice_number ON invoices(tenant_id, invoice_number)
+    WHERE invoice_number IS NOT NULL;
@@ -0,0 +1,23 @@
+-- Create webhook_endpoints table, replacing the endpoints from the static config
+CREATE TABLE webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    environment_id VARCHAR(255) NOT NULL DEFAULT '',
+    url TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(255) NOT NULL,
+    previous_secret VARCHAR(255) NOT NULL DEFAULT '',
+    previous_secret_expires_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_webhook_endpoints_tenant_environment ON webhook_endpoints(tenant_id, environment_id);
+
+-- Deliveries are sent in the environment of their endpoint
+ALTER TABLE webhook_deliveries ADD COLUMN environment_id VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,24 @@
+-- Create usage_anomalies table for the anomaly detection job
+CREATE TABLE usage_anomalies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    meter_id VARCHAR(255) NOT NULL,
+    event_name VARCHAR(255) NOT NULL,
+    external_customer_id VARCHAR(255) NOT NULL,
+    anomaly_type VARCHAR(20) NOT NULL,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    window_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    value NUMERIC(20,8) NOT NULL,
+    baseline NUMERIC(20,8) NOT NULL,
+    std_dev NUMERIC(20,8) NOT NULL,
+    sigma NUMERIC(20,8) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_usage_anomalies_window ON usage_anomalies(tenant_id, meter_id, external_customer_id, window_start);
+CREATE INDEX idx_usage_anomalies_tenant ON usage_anomalies(tenant_id, window_start DESC);
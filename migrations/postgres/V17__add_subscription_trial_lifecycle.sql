@@ -0,0 +1,8 @@
+-- Default payment method of the customer at the payment provider. Trials of
+-- customers without one are cancelled instead of converted when they end
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS payment_method_id VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS trial_will_end_sent_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_trialing ON subscriptions(trial_end)
+    WHERE subscription_status = 'trialing' AND status = 'published';
@@ -0,0 +1,2 @@
+-- Minimum amount billed per period. Periods billed below it get a true-up line item
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS commitment_amount DECIMAL(20,9) NOT NULL DEFAULT 0;
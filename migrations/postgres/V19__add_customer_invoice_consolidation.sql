@@ -0,0 +1,2 @@
+-- Customers that consolidate get one invoice per billing date and currency for all their subscriptions
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS consolidate_invoices BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,23 @@
+-- Cancellation reason taxonomy configured per tenant. Tenants without any
+-- reasons use the built in defaults
+CREATE TABLE cancellation_reasons (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    code VARCHAR(100) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_cancellation_reasons_tenant_code ON cancellation_reasons(tenant_id, code)
+    WHERE status = 'published';
+
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS cancellation_reason VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS cancellation_comment TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_cancelled_at ON subscriptions(tenant_id, cancelled_at)
+    WHERE cancelled_at IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Calendar billing aligns periods on calendar boundaries. The partial first period
+-- of subscriptions starting between two boundaries is billed per partial_period_behavior
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS billing_cycle VARCHAR(20) NOT NULL DEFAULT 'anniversary';
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS partial_period_behavior VARCHAR(20) NOT NULL DEFAULT '';
+
+-- Set on the invoices of partial periods only
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS partial_period_behavior VARCHAR(20) NOT NULL DEFAULT '';
@@ -0,0 +1,21 @@
+-- Negotiated rate sheets overriding catalog prices for a customer or one of its
+-- subscriptions. A new version is created for each contract renewal
+CREATE TABLE rate_cards (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    subscription_id VARCHAR(255) NOT NULL DEFAULT '',
+    version INTEGER NOT NULL,
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    overrides JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_rate_cards_tenant_customer_version
+    ON rate_cards(tenant_id, customer_id, subscription_id, version)
+    WHERE status = 'published';
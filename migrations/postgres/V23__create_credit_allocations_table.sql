@@ -0,0 +1,20 @@
+-- Ledger of where the amount of credit invoices went: the amount due of another
+-- invoice of the customer or one of its wallets
+CREATE TABLE credit_allocations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    credit_invoice_id UUID NOT NULL REFERENCES invoices(id),
+    customer_id VARCHAR(255) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id VARCHAR(255) NOT NULL,
+    amount DECIMAL(20,4) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_credit_allocations_tenant_credit_invoice ON credit_allocations(tenant_id, credit_invoice_id);
+CREATE INDEX idx_credit_allocations_tenant_target ON credit_allocations(tenant_id, target_type, target_id);
@@ -0,0 +1,19 @@
+-- Auto top-up rules of the wallets, at most one per wallet
+CREATE TABLE wallet_auto_topups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    wallet_id UUID NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    threshold DECIMAL(20,4) NOT NULL,
+    amount DECIMAL(20,4) NOT NULL,
+    daily_cap DECIMAL(20,4) NOT NULL DEFAULT 0,
+    monthly_cap DECIMAL(20,4) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_wallet_auto_topups_tenant_wallet ON wallet_auto_topups(tenant_id, wallet_id);
+CREATE INDEX idx_wallet_auto_topups_enabled ON wallet_auto_topups(enabled) WHERE enabled;
@@ -0,0 +1,18 @@
+-- Wallet credits topped up with an expiry date, consumed oldest first
+CREATE TABLE wallet_credit_buckets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    wallet_id UUID NOT NULL,
+    amount DECIMAL(20,4) NOT NULL,
+    remaining DECIMAL(20,4) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expired_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_wallet_credit_buckets_tenant_wallet ON wallet_credit_buckets(tenant_id, wallet_id, created_at) WHERE remaining > 0;
+CREATE INDEX idx_wallet_credit_buckets_expires_at ON wallet_credit_buckets(expires_at) WHERE remaining > 0;
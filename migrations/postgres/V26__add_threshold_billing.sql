@@ -0,0 +1,8 @@
+-- Threshold billing raises interim invoices when the usage of the period exceeds
+-- billing_threshold. threshold_billed_until is where the next accumulation starts
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS billing_threshold DECIMAL(20,9) NOT NULL DEFAULT 0;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS threshold_billed_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_billing_threshold ON subscriptions(billing_threshold) WHERE billing_threshold > 0;
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS invoice_flow VARCHAR(20) NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- How long the events of a tenant are kept in ClickHouse, at most one per tenant
+CREATE TABLE event_retention_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    ttl_days INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_event_retention_policies_tenant ON event_retention_policies(tenant_id);
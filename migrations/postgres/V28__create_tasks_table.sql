@@ -0,0 +1,27 @@
+-- Create tasks table
+CREATE TABLE tasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    task_type VARCHAR(50) NOT NULL,
+    file_format VARCHAR(20) NOT NULL,
+    file_url TEXT NOT NULL DEFAULT '',
+    task_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    successful_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    error_report_key TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_tasks_tenant_created_at ON tasks(tenant_id, created_at DESC);
+
+-- Imports look customers up by external id within a tenant
+CREATE INDEX IF NOT EXISTS idx_customers_tenant_external_id ON customers(tenant_id, external_id);
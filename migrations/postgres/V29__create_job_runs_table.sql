@@ -0,0 +1,13 @@
+-- Run history of the scheduled jobs, across all the tenants
+CREATE TABLE job_runs (
+    id UUID PRIMARY KEY,
+    job_name VARCHAR(100) NOT NULL,
+    trigger_type VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    triggered_by VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_job_runs_job_started ON job_runs(job_name, started_at DESC);
@@ -0,0 +1,47 @@
+-- Templates of the tenants replacing the default email templates, at most one per type
+CREATE TABLE email_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    template_type VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    body_html TEXT NOT NULL DEFAULT '',
+    body_text TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_email_templates_tenant_type ON email_templates(tenant_id, template_type);
+
+-- Emails sent to customers with their delivery attempts
+CREATE TABLE email_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    template_type VARCHAR(50) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL,
+    body_html TEXT NOT NULL DEFAULT '',
+    body_text TEXT NOT NULL DEFAULT '',
+    entity_type VARCHAR(50) NOT NULL DEFAULT '',
+    entity_id VARCHAR(255) NOT NULL DEFAULT '',
+    resent_from VARCHAR(255) NOT NULL DEFAULT '',
+    delivery_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    provider VARCHAR(20) NOT NULL DEFAULT '',
+    provider_message_id VARCHAR(255) NOT NULL DEFAULT '',
+    sent_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_email_deliveries_tenant ON email_deliveries(tenant_id, created_at DESC);
+CREATE INDEX idx_email_deliveries_entity ON email_deliveries(tenant_id, entity_id);
+CREATE INDEX idx_email_deliveries_due ON email_deliveries(next_attempt_at) WHERE delivery_status = 'pending';
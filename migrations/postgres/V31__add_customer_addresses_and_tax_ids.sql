@@ -0,0 +1,5 @@
+-- Structured addresses and tax registration numbers of the customers
+ALTER TABLE customers
+    ADD COLUMN billing_address JSONB,
+    ADD COLUMN shipping_address JSONB,
+    ADD COLUMN tax_ids JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,27 @@
+-- Settings and OAuth access token of the NetSuite and QuickBooks connections
+ALTER TABLE connections
+    ADD COLUMN ledger_settings JSONB,
+    ADD COLUMN credentials TEXT NOT NULL DEFAULT '';
+
+-- Reconciliation status of the invoices and credit notes pushed to a ledger
+CREATE TABLE ledger_syncs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    connection_id VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    sync_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    external_id VARCHAR(255) NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    synced_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_ledger_syncs_entity ON ledger_syncs(tenant_id, connection_id, entity_type, entity_id);
+CREATE INDEX idx_ledger_syncs_entity_id ON ledger_syncs(tenant_id, entity_id);
+CREATE INDEX idx_ledger_syncs_status ON ledger_syncs(tenant_id, connection_id, sync_status);
@@ -0,0 +1,2 @@
+-- Settings of the Salesforce connections
+ALTER TABLE connections ADD COLUMN crm_settings JSONB;
@@ -0,0 +1,5 @@
+-- Cross references to other systems, such as the IDs of the Stripe objects
+-- a record was imported from
+ALTER TABLE customers ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE plans ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE subscriptions ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
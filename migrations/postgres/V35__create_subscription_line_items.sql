@@ -0,0 +1,51 @@
+-- Quantity and price overrides of the recurring fixed prices of a subscription
+CREATE TABLE subscription_line_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    subscription_id VARCHAR(255) NOT NULL,
+    price_id VARCHAR(255) NOT NULL,
+    quantity DECIMAL(20,4) NOT NULL DEFAULT 1,
+    unit_amount DECIMAL(20,9),
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_subscription_line_items_subscription ON subscription_line_items(tenant_id, subscription_id);
+
+-- Line items of the existing subscriptions, charged once per period as before
+INSERT INTO subscription_line_items (tenant_id, subscription_id, price_id, created_by, updated_by)
+SELECT s.tenant_id, s.id, p.id, s.created_by, s.created_by
+FROM subscriptions s
+JOIN prices p ON p.tenant_id = s.tenant_id
+    AND p.plan_id = s.plan_id
+    AND p.type = 'FIXED'
+    AND p.billing_cadence = 'RECURRING'
+    AND p.currency = s.currency
+    AND p.billing_period = s.billing_period
+    AND p.billing_period_count = s.billing_period_count
+    AND p.status = 'published';
+
+-- Prorations of line item changes waiting to be billed on the next invoice
+CREATE TABLE subscription_prorations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    subscription_id VARCHAR(255) NOT NULL,
+    line_item_id VARCHAR(255) NOT NULL,
+    price_id VARCHAR(255) NOT NULL,
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    amount DECIMAL(20,4) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    invoice_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_subscription_prorations_pending ON subscription_prorations(tenant_id, subscription_id, invoice_id);
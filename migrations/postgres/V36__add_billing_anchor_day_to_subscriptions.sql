@@ -0,0 +1,2 @@
+-- Day of the month monthly subscriptions are billed on, zero bills on the anniversary of the start date
+ALTER TABLE subscriptions ADD COLUMN billing_anchor_day INTEGER NOT NULL DEFAULT 0;
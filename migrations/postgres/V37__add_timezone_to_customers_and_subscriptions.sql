@@ -0,0 +1,3 @@
+-- Timezone billing periods are computed in. Existing subscriptions keep their UTC period boundaries
+ALTER TABLE customers ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE subscriptions ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,24 @@
+-- Payment methods of the customers vaulted at a payment gateway
+CREATE TABLE payment_methods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    connection_id VARCHAR(255) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    gateway_payment_method_id VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL DEFAULT '',
+    brand VARCHAR(50) NOT NULL DEFAULT '',
+    last4 VARCHAR(4) NOT NULL DEFAULT '',
+    exp_month INTEGER NOT NULL DEFAULT 0,
+    exp_year INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_payment_methods_customer ON payment_methods(tenant_id, customer_id);
+
+-- Payment method at the gateway the subscription is charged to instead of the default of the customer
+ALTER TABLE subscriptions ADD COLUMN gateway_payment_method_id VARCHAR(255) NOT NULL DEFAULT '';
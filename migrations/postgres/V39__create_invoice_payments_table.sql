@@ -0,0 +1,26 @@
+-- Payments recorded against invoices, their sum is the amount paid of the invoice
+CREATE TABLE invoice_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    customer_id VARCHAR(255) NOT NULL,
+    amount DECIMAL(20,4) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    payment_method_type VARCHAR(50) NOT NULL DEFAULT '',
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    paid_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_invoice_payments_tenant_invoice ON invoice_payments(tenant_id, invoice_id);
+
+ALTER TABLE invoices ADD COLUMN amount_paid DECIMAL(20,4) NOT NULL DEFAULT 0;
+ALTER TABLE invoices ADD COLUMN amount_remaining DECIMAL(20,4) NOT NULL DEFAULT 0;
+ALTER TABLE invoices ADD COLUMN payment_status VARCHAR(20) NOT NULL DEFAULT 'PENDING';
+
+UPDATE invoices SET amount_remaining = amount_due;
+UPDATE invoices SET payment_status = 'PAID' WHERE amount_due = 0;
@@ -0,0 +1,27 @@
+-- Payments collected at a gateway are refunded there
+ALTER TABLE invoice_payments ADD COLUMN connection_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE invoice_payments ADD COLUMN gateway_payment_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE invoice_payments ADD COLUMN amount_refunded DECIMAL(20,4) NOT NULL DEFAULT 0;
+
+-- Refunds of invoice payments, failed gateway refunds are kept for reconciliation
+CREATE TABLE refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    payment_id UUID NOT NULL REFERENCES invoice_payments(id),
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    customer_id VARCHAR(255) NOT NULL,
+    amount DECIMAL(20,4) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    refund_status VARCHAR(20) NOT NULL,
+    gateway_refund_id VARCHAR(255) NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    credit_note_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_refunds_tenant_payment ON refunds(tenant_id, payment_id);
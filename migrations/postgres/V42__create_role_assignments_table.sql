@@ -0,0 +1,24 @@
+-- Roles of the dashboard users. An empty environment_id applies to the
+-- environments without an assignment of their own
+CREATE TABLE role_assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    environment_id VARCHAR(255) NOT NULL DEFAULT '',
+    role VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_role_assignments_tenant_user_environment
+    ON role_assignments(tenant_id, user_id, environment_id)
+    WHERE status = 'published';
+
+-- Existing users keep their full access
+INSERT INTO role_assignments (tenant_id, user_id, environment_id, role, created_by, updated_by)
+SELECT tenant_id, id::text, '', 'admin', 'system', 'system'
+FROM users
+WHERE status = 'published';
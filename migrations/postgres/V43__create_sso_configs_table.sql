@@ -0,0 +1,22 @@
+-- Identity provider of each tenant for the sign in of its users
+CREATE TABLE sso_configs (
+    tenant_id VARCHAR(255) PRIMARY KEY,
+    protocol VARCHAR(20) NOT NULL,
+    domains TEXT[] NOT NULL DEFAULT '{}',
+    oidc_settings JSONB,
+    saml_settings JSONB,
+    client_secret TEXT NOT NULL DEFAULT '',
+    groups_claim VARCHAR(255) NOT NULL DEFAULT 'groups',
+    group_roles JSONB NOT NULL DEFAULT '{}',
+    jit_provisioning BOOLEAN NOT NULL DEFAULT FALSE,
+    default_role VARCHAR(50) NOT NULL DEFAULT '',
+    password_login_disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Users are routed to the identity provider of their email domain
+CREATE INDEX idx_sso_configs_domains ON sso_configs USING GIN (domains);
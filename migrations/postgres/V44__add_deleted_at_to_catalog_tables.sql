@@ -0,0 +1,13 @@
+-- Deleted plans, prices and customers keep their rows with the time of the
+-- deletion. They can be restored until the purge job removes them
+ALTER TABLE plans ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE prices ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE customers ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE plans SET deleted_at = updated_at WHERE status = 'deleted';
+UPDATE prices SET deleted_at = updated_at WHERE status = 'deleted';
+UPDATE customers SET deleted_at = updated_at WHERE status = 'deleted';
+
+CREATE INDEX idx_plans_deleted_at ON plans(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_prices_deleted_at ON prices(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_customers_deleted_at ON customers(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Every update of these rows increments the version and is only applied when
+-- the row still has the version the writer read, so concurrent writers can not
+-- silently overwrite each other
+ALTER TABLE subscriptions ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE invoices ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE plans ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE invoice_numbering_configs ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
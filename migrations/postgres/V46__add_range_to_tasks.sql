@@ -0,0 +1,4 @@
+-- Backfill tasks ingest historical events whose timestamps must fall within
+-- the range of the task
+ALTER TABLE tasks ADD COLUMN range_start TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tasks ADD COLUMN range_end TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,32 @@
+-- Definitions of meters over time, usage is aggregated with the newest
+-- version whose effective range holds it
+CREATE TABLE meter_versions (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    meter_id UUID NOT NULL REFERENCES meters(id),
+    version INTEGER NOT NULL,
+    aggregation JSONB NOT NULL,
+    filters JSONB NOT NULL DEFAULT '[]',
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    effective_to TIMESTAMP WITH TIME ZONE,
+    reaggregation_status VARCHAR(20) NOT NULL DEFAULT '',
+    reaggregation_error TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    UNIQUE (meter_id, version)
+);
+
+CREATE INDEX idx_meter_versions_tenant_meter ON meter_versions (tenant_id, meter_id);
+
+-- The current definition of existing meters has always been in effect
+INSERT INTO meter_versions (
+    id, tenant_id, meter_id, version, aggregation, filters, effective_from,
+    status, created_at, updated_at, created_by, updated_by
+)
+SELECT
+    uuid_generate_v4(), tenant_id, id, 1, aggregation, filters, '0001-01-01 00:00:00+00',
+    status, created_at, updated_at, created_by, updated_by
+FROM meters;
@@ -0,0 +1,3 @@
+-- Derived meters compute their usage from a formula over the usage of other
+-- meters and have no event name
+ALTER TABLE meters ADD COLUMN formula JSONB;
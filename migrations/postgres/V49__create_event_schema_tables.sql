@@ -0,0 +1,41 @@
+-- Schemas of the properties of the events of an event name, checked on ingestion
+CREATE TABLE event_schemas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    event_name VARCHAR(255) NOT NULL,
+    rules JSONB NOT NULL DEFAULT '[]',
+    on_invalid VARCHAR(20) NOT NULL DEFAULT 'reject',
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_event_schemas_tenant_event_name ON event_schemas(tenant_id, event_name)
+    WHERE status = 'published';
+
+-- Events that did not match the schema of their event name, kept by event id
+-- until they are fixed and re-submitted
+CREATE TABLE quarantined_events (
+    id VARCHAR(255) NOT NULL,
+    tenant_id VARCHAR(255) NOT NULL,
+    event_name VARCHAR(255) NOT NULL,
+    external_customer_id VARCHAR(255) NOT NULL DEFAULT '',
+    customer_id VARCHAR(255) NOT NULL DEFAULT '',
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    properties JSONB NOT NULL DEFAULT '{}',
+    schema_id VARCHAR(255) NOT NULL,
+    violations JSONB NOT NULL DEFAULT '[]',
+    quarantine_status VARCHAR(20) NOT NULL DEFAULT 'quarantined',
+    resubmitted_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE INDEX idx_quarantined_events_tenant_status ON quarantined_events(tenant_id, quarantine_status, created_at DESC);
@@ -0,0 +1,22 @@
+-- Messages the consumer failed to process, kept until they are replayed. Messages
+-- whose tenant can not be read from the payload are stored with an empty tenant
+CREATE TABLE event_dead_letters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 1,
+    dead_letter_status VARCHAR(20) NOT NULL DEFAULT 'failed',
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    replayed_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_event_dead_letters_message ON event_dead_letters(tenant_id, topic, message_id);
+CREATE INDEX idx_event_dead_letters_tenant_status ON event_dead_letters(tenant_id, dead_letter_status, last_failed_at DESC);
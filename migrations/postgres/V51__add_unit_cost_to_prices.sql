@@ -0,0 +1,3 @@
+-- Cost of goods sold of one unit billed by the price, in the currency of the
+-- price. Margin reports leave the revenue of prices without one uncosted
+ALTER TABLE prices ADD COLUMN unit_cost DECIMAL(20,9);
@@ -0,0 +1,30 @@
+-- Create revenue_snapshots table holding the monthly recurring revenue of each
+-- subscription in the closed months
+CREATE TABLE revenue_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    month TIMESTAMP WITH TIME ZONE NOT NULL,
+    subscription_id VARCHAR(255) NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    plan_id VARCHAR(255) NOT NULL,
+    segment VARCHAR(255) NOT NULL DEFAULT '',
+    currency VARCHAR(10) NOT NULL,
+    mrr NUMERIC(20,8) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create revenue_snapshot_months table recording the months snapshotted, also
+-- those without recurring revenue
+CREATE TABLE revenue_snapshot_months (
+    tenant_id VARCHAR(255) NOT NULL,
+    month TIMESTAMP WITH TIME ZONE NOT NULL,
+    snapshotted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, month)
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_revenue_snapshots_subscription ON revenue_snapshots(tenant_id, month, subscription_id, currency);
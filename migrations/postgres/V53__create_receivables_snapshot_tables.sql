@@ -0,0 +1,32 @@
+-- Create receivables_snapshots table holding the amounts outstanding of each
+-- customer by age, recorded daily
+CREATE TABLE receivables_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    snapshot_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    current NUMERIC(20,8) NOT NULL,
+    days_30 NUMERIC(20,8) NOT NULL,
+    days_60 NUMERIC(20,8) NOT NULL,
+    days_90_plus NUMERIC(20,8) NOT NULL,
+    total NUMERIC(20,8) NOT NULL,
+    invoices INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create receivables_snapshot_days table recording the days snapshotted, also
+-- those when nothing was outstanding
+CREATE TABLE receivables_snapshot_days (
+    tenant_id VARCHAR(255) NOT NULL,
+    snapshot_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    as_of TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (tenant_id, snapshot_date)
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_receivables_snapshots_customer ON receivables_snapshots(tenant_id, snapshot_date, customer_id, currency);
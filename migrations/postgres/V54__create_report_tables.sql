@@ -0,0 +1,44 @@
+-- Create report_templates table holding the reports defined by the tenants
+CREATE TABLE report_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    entity VARCHAR(50) NOT NULL,
+    columns JSONB NOT NULL DEFAULT '[]',
+    filters JSONB NOT NULL DEFAULT '[]',
+    group_by TEXT[] NOT NULL DEFAULT '{}',
+    period VARCHAR(20) NOT NULL DEFAULT '',
+    schedule VARCHAR(20) NOT NULL DEFAULT '',
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    destinations JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create report_runs table tracking the runs of the templates
+CREATE TABLE report_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    template_id VARCHAR(255) NOT NULL,
+    trigger VARCHAR(20) NOT NULL,
+    run_status VARCHAR(20) NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE,
+    period_end TIMESTAMP WITH TIME ZONE,
+    object_key TEXT NOT NULL DEFAULT '',
+    row_count BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_report_templates_tenant ON report_templates(tenant_id, created_at);
+CREATE INDEX idx_report_templates_next_run ON report_templates(next_run_at) WHERE schedule <> '' AND status = 'published';
+CREATE INDEX idx_report_runs_template ON report_runs(tenant_id, template_id, created_at);
@@ -0,0 +1,17 @@
+-- Feature flags turned on or off for a tenant, at most one per flag and
+-- environment of the tenant. An empty environment_id applies to all of them
+CREATE TABLE feature_flag_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    environment_id VARCHAR(255) NOT NULL DEFAULT '',
+    flag VARCHAR(100) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_feature_flag_overrides_tenant_environment_flag
+    ON feature_flag_overrides(tenant_id, environment_id, flag);
@@ -0,0 +1,22 @@
+-- Spending budgets of the customers, or of single subscriptions of them
+CREATE TABLE budgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    subscription_id VARCHAR(255) NOT NULL DEFAULT '',
+    amount NUMERIC(20,8) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    period VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    spend NUMERIC(20,8) NOT NULL DEFAULT 0,
+    evaluated_at TIMESTAMP WITH TIME ZONE,
+    exceeded_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_budgets_tenant_customer ON budgets(tenant_id, customer_id) WHERE status = 'published';
@@ -0,0 +1,19 @@
+-- Price books overriding the prices of a plan for the subscriptions put on them
+CREATE TABLE price_books (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    plan_id VARCHAR(255) NOT NULL,
+    segment VARCHAR(255) NOT NULL DEFAULT '',
+    overrides JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_price_books_tenant_plan ON price_books(tenant_id, plan_id) WHERE status = 'published';
+
+-- Price book the subscription was created on, empty for the catalog prices
+ALTER TABLE subscriptions ADD COLUMN price_book_id VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- End of the contract of the rate card version, open ended when null
+ALTER TABLE rate_cards ADD COLUMN IF NOT EXISTS effective_to TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,11 @@
+-- Messages of the Postgres queue, used instead of kafka when queue.backend is
+-- postgres. A message is deleted once consumed
+CREATE TABLE queue_messages (
+    id BIGSERIAL PRIMARY KEY,
+    message_id VARCHAR(255) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    payload BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_queue_messages_topic ON queue_messages(topic, id);
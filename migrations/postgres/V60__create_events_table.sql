@@ -0,0 +1,31 @@
+-- Events of the deployments with the timescaledb event store, kept in ClickHouse
+-- otherwise. The table is a hypertable of weekly chunks when the TimescaleDB
+-- extension is available, a plain table otherwise
+CREATE TABLE IF NOT EXISTS events (
+    id VARCHAR(255) NOT NULL,
+    tenant_id VARCHAR(255) NOT NULL,
+    external_customer_id VARCHAR(255) NOT NULL DEFAULT '',
+    customer_id VARCHAR(255) NOT NULL DEFAULT '',
+    event_name VARCHAR(255) NOT NULL,
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    ingested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    properties JSONB NOT NULL DEFAULT '{}',
+    correction_of VARCHAR(255) NOT NULL DEFAULT '',
+    sign SMALLINT NOT NULL DEFAULT 1,
+    PRIMARY KEY (tenant_id, id, timestamp)
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_tenant_event_name_timestamp ON events(tenant_id, event_name, timestamp);
+CREATE INDEX IF NOT EXISTS idx_events_tenant_correction_of ON events(tenant_id, correction_of) WHERE correction_of != '';
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'timescaledb')
+        AND current_setting('shared_preload_libraries', true) LIKE '%timescaledb%' THEN
+        CREATE EXTENSION IF NOT EXISTS timescaledb;
+        PERFORM create_hypertable('events', 'timestamp',
+            chunk_time_interval => INTERVAL '7 days', if_not_exists => TRUE, migrate_data => TRUE);
+    END IF;
+END
+$$;
@@ -0,0 +1,3 @@
+-- Cursor pagination seeks on (created_at, id) within a tenant, newest first
+CREATE INDEX IF NOT EXISTS idx_invoices_tenant_created_id ON invoices(tenant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_tenant_created_id ON subscriptions(tenant_id, created_at DESC, id DESC);
@@ -0,0 +1,3 @@
+-- Settings and webhook secret of the payment gateway connections
+ALTER TABLE connections ADD COLUMN gateway_settings JSONB;
+ALTER TABLE connections ADD COLUMN webhook_secret TEXT NOT NULL DEFAULT '';
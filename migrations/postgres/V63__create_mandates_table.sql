@@ -0,0 +1,36 @@
+-- Direct debit mandates of the customers on their bank account payment methods
+CREATE TABLE mandates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    payment_method_id VARCHAR(255) NOT NULL,
+    scheme VARCHAR(20) NOT NULL,
+    reference VARCHAR(255) NOT NULL,
+    mandate_status VARCHAR(20) NOT NULL DEFAULT 'active',
+    pre_notification_days INTEGER NOT NULL,
+    signed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revocation_reason VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_mandates_customer ON mandates(tenant_id, customer_id);
+
+-- Settlement of the invoice payments, bank debits are scheduled then clear days
+-- after they are collected
+ALTER TABLE invoice_payments ADD COLUMN payment_status VARCHAR(20) NOT NULL DEFAULT 'SUCCEEDED';
+ALTER TABLE invoice_payments ADD COLUMN mandate_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE invoice_payments ADD COLUMN charge_date TIMESTAMP WITH TIME ZONE;
+ALTER TABLE invoice_payments ADD COLUMN failure_code VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE invoice_payments ADD COLUMN failure_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE invoice_payments ADD COLUMN dunning_action VARCHAR(50) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_invoice_payments_due_debits ON invoice_payments(charge_date) WHERE payment_status = 'SCHEDULED';
+CREATE INDEX idx_invoice_payments_gateway_payment ON invoice_payments(tenant_id, gateway_payment_id) WHERE gateway_payment_id <> '';
+
+-- Amount of the debits of the invoice that are scheduled or not cleared yet
+ALTER TABLE invoices ADD COLUMN amount_processing DECIMAL(20,4) NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Settings of the tax provider connections
+ALTER TABLE connections ADD COLUMN tax_settings JSONB;
@@ -0,0 +1,30 @@
+-- Companies of the tenant the invoices are issued from, each with its own
+-- invoice numbering
+CREATE TABLE legal_entities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address JSONB,
+    tax_ids JSONB NOT NULL DEFAULT '[]',
+    invoice_prefix VARCHAR(20) NOT NULL DEFAULT '',
+    invoice_separator VARCHAR(5) NOT NULL DEFAULT '',
+    invoice_padding INTEGER NOT NULL,
+    invoice_reset_yearly BOOLEAN NOT NULL DEFAULT FALSE,
+    bank_details JSONB,
+    pdf_template VARCHAR(255) NOT NULL DEFAULT '',
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_legal_entities_tenant ON legal_entities(tenant_id) WHERE status = 'published';
+CREATE UNIQUE INDEX idx_legal_entities_default ON legal_entities(tenant_id) WHERE is_default AND status = 'published';
+
+-- Legal entity the customers and subscriptions are invoiced from, and the
+-- invoices were issued from
+ALTER TABLE customers ADD COLUMN legal_entity_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE subscriptions ADD COLUMN legal_entity_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE invoices ADD COLUMN legal_entity_id VARCHAR(255) NOT NULL DEFAULT '';
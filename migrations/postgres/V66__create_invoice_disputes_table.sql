@@ -0,0 +1,26 @@
+-- Disputes of customers with the charges of finalized invoices, the collection
+-- of an invoice is paused while it has an unresolved dispute
+CREATE TABLE invoice_disputes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    customer_id VARCHAR(255) NOT NULL,
+    dispute_status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    lines JSONB NOT NULL DEFAULT '[]',
+    amount DECIMAL(20,4) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    resolution VARCHAR(20) NOT NULL DEFAULT '',
+    resolution_note TEXT NOT NULL DEFAULT '',
+    credit_note_id VARCHAR(255) NOT NULL DEFAULT '',
+    reissued_invoice_id VARCHAR(255) NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_invoice_disputes_tenant_invoice ON invoice_disputes(tenant_id, invoice_id);
+CREATE UNIQUE INDEX idx_invoice_disputes_active ON invoice_disputes(tenant_id, invoice_id) WHERE dispute_status <> 'resolved' AND status = 'published';
@@ -0,0 +1,11 @@
+-- Invoices written off as uncollectible are left out of the receivables and
+-- reported apart
+ALTER TABLE invoices ADD COLUMN written_off_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE invoices ADD COLUMN write_off_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE invoices ADD COLUMN amount_written_off DECIMAL(20,4) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_invoices_tenant_written_off ON invoices(tenant_id, written_off_at) WHERE written_off_at IS NOT NULL;
+
+-- Collections of a customer can be paused until a date
+ALTER TABLE customers ADD COLUMN collections_paused_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE customers ADD COLUMN collections_pause_reason TEXT NOT NULL DEFAULT '';
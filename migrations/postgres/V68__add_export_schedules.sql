@@ -0,0 +1,30 @@
+-- Exports are run by the process_exports job, which claims them with a lease.
+-- A processing export whose lease expired is claimed and run again
+ALTER TABLE exports ADD COLUMN lease_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE exports ADD COLUMN schedule_id VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_exports_claim ON exports(created_at) WHERE export_status IN ('pending', 'processing');
+
+-- Create export_schedules table holding the exports run daily, weekly or monthly
+CREATE TABLE export_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    export_type VARCHAR(20) NOT NULL,
+    export_format VARCHAR(20) NOT NULL,
+    event_name VARCHAR(255) NOT NULL DEFAULT '',
+    meter_id VARCHAR(255) NOT NULL DEFAULT '',
+    external_customer_id VARCHAR(255) NOT NULL DEFAULT '',
+    window_size VARCHAR(20) NOT NULL DEFAULT '',
+    schedule VARCHAR(20) NOT NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_export_schedules_tenant ON export_schedules(tenant_id, created_at);
+CREATE INDEX idx_export_schedules_next_run ON export_schedules(next_run_at) WHERE status = 'published';
@@ -0,0 +1,5 @@
+-- Report runs are run by the run_scheduled_reports job, which claims them with
+-- a lease. A processing run whose lease expired is claimed and run again
+ALTER TABLE report_runs ADD COLUMN lease_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_report_runs_claim ON report_runs(created_at) WHERE run_status IN ('pending', 'processing');
@@ -0,0 +1,3 @@
+-- The simulated clock of a sandbox environment is persisted as its offset from
+-- the wall clock, so it survives restarts of the deployment simulating it
+ALTER TABLE environments ADD COLUMN clock_offset_ms BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Due bank debits are claimed with a lease by the collect_direct_debits job, so
+-- concurrent schedulers do not submit a debit twice. A processing debit without
+-- a gateway payment whose lease expired is claimed and submitted again with the
+-- same idempotency key
+ALTER TABLE invoice_payments ADD COLUMN lease_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_invoice_payments_claim_debits ON invoice_payments(charge_date) WHERE payment_status IN ('SCHEDULED', 'PROCESSING') AND mandate_id <> '';
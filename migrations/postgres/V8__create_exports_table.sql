@@ -0,0 +1,26 @@
+-- Create exports table
+CREATE TABLE exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    export_type VARCHAR(20) NOT NULL,
+    export_format VARCHAR(20) NOT NULL,
+    export_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    event_name VARCHAR(255) NOT NULL DEFAULT '',
+    meter_id VARCHAR(255) NOT NULL DEFAULT '',
+    external_customer_id VARCHAR(255) NOT NULL DEFAULT '',
+    window_size VARCHAR(20) NOT NULL DEFAULT '',
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    object_key TEXT NOT NULL DEFAULT '',
+    row_count BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_exports_tenant_created_at ON exports(tenant_id, created_at DESC);
@@ -0,0 +1,52 @@
+-- Create invoices table
+CREATE TABLE invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    customer_id UUID NOT NULL,
+    subscription_id VARCHAR(255) NOT NULL DEFAULT '',
+    invoice_type VARCHAR(20) NOT NULL,
+    invoice_status VARCHAR(20) NOT NULL DEFAULT 'DRAFT',
+    currency VARCHAR(3) NOT NULL,
+    total DECIMAL(20,4) NOT NULL DEFAULT 0,
+    amount_due DECIMAL(20,4) NOT NULL DEFAULT 0,
+    original_invoice_id VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    period_start TIMESTAMP WITH TIME ZONE,
+    period_end TIMESTAMP WITH TIME ZONE,
+    finalized_at TIMESTAMP WITH TIME ZONE,
+    metadata JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create invoice line items table
+CREATE TABLE invoice_line_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(255) NOT NULL,
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    customer_id UUID NOT NULL,
+    subscription_id VARCHAR(255) NOT NULL DEFAULT '',
+    price_id VARCHAR(255) NOT NULL DEFAULT '',
+    meter_id VARCHAR(255) NOT NULL DEFAULT '',
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    amount DECIMAL(20,4) NOT NULL DEFAULT 0,
+    quantity DECIMAL(20,4) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE,
+    period_end TIMESTAMP WITH TIME ZONE,
+    metadata JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    updated_by VARCHAR(255) NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_invoices_tenant_customer ON invoices(tenant_id, customer_id);
+CREATE INDEX idx_invoices_tenant_subscription ON invoices(tenant_id, subscription_id);
+CREATE INDEX idx_invoices_original_invoice ON invoices(tenant_id, original_invoice_id);
+CREATE INDEX idx_invoice_line_items_invoice ON invoice_line_items(invoice_id);